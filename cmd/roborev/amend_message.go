@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// errNoCommitMessage means no review of the commit proposed a message
+var errNoCommitMessage = errors.New("no commit message suggested")
+
+func amendMessageCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "amend-message [sha]",
+		Short: "Apply a review's suggested commit message",
+		Long: `Replace a commit's message with the one its review suggested.
+
+Reviews suggest commit messages when suggest_commit_message is enabled in
+.roborev.toml or the global config. The commit (default HEAD) must still be
+HEAD, since amending an older commit would rewrite the commits after it.
+Only the message changes; staged changes are not added to the commit.
+
+Examples:
+  roborev amend-message
+  roborev amend-message abc1234
+  roborev amend-message --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ref := "HEAD"
+			if len(args) > 0 {
+				ref = args[0]
+			}
+			root, err := git.GetRepoRoot(".")
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			sha, err := git.ResolveSHA(root, ref)
+			if err != nil {
+				return fmt.Errorf("invalid commit %q: %w", ref, err)
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			suggestion, err := getCommitMessage(getDaemonAddr(), sha)
+			if errors.Is(err, errNoCommitMessage) {
+				return fmt.Errorf("no review of %s suggested a commit message", shortSHA(sha))
+			}
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Suggested message for %s (job %d, %s):\n\n", shortSHA(sha), suggestion.JobID, suggestion.Agent)
+			for _, line := range strings.Split(suggestion.Message, "\n") {
+				fmt.Fprintf(out, "    %s\n", line)
+			}
+			fmt.Fprintln(out)
+			if dryRun {
+				return nil
+			}
+
+			head, err := git.ResolveSHA(root, "HEAD")
+			if err != nil {
+				return fmt.Errorf("resolve HEAD: %w", err)
+			}
+			if head != sha {
+				return fmt.Errorf("commit %s is not HEAD; amend-message only rewrites the current commit", shortSHA(sha))
+			}
+			if err := git.AmendCommitMessage(root, suggestion.Message); err != nil {
+				return err
+			}
+			amended, err := git.ResolveSHA(root, "HEAD")
+			if err != nil {
+				return fmt.Errorf("resolve amended HEAD: %w", err)
+			}
+			fmt.Fprintf(out, "Amended %s -> %s\n", shortSHA(sha), shortSHA(amended))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the suggested message without amending")
+
+	return cmd
+}
+
+// getCommitMessage fetches the latest commit message suggested for sha.
+// Returns errNoCommitMessage if there is none.
+func getCommitMessage(addr, sha string) (*storage.CommitMessageSuggestion, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(addr + "/api/commit-message?" + url.Values{"sha": {sha}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNoCommitMessage
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var suggestion storage.CommitMessageSuggestion
+	if err := json.NewDecoder(resp.Body).Decode(&suggestion); err != nil {
+		return nil, fmt.Errorf("decode commit message: %w", err)
+	}
+	return &suggestion, nil
+}
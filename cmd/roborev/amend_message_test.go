@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestAmendMessageCmd(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	repo := newTestGitRepo(t)
+	first := repo.CommitFile("a.txt", "a", "wip")
+	head := repo.CommitFile("b.txt", "b", "more wip")
+	chdir(t, repo.Dir)
+
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/commit-message" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("sha") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(storage.CommitMessageSuggestion{
+			JobID:   7,
+			SHA:     r.URL.Query().Get("sha"),
+			Agent:   "codex",
+			Message: "Add b.txt\n\nExplain why b is needed.",
+		})
+	}))
+	defer cleanup()
+
+	run := func(args ...string) (string, error) {
+		cmd := amendMessageCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(out, "    Add b.txt") {
+		t.Errorf("expected the suggestion in the output, got:\n%s", out)
+	}
+	if got := repo.Run("rev-parse", "HEAD"); got != head {
+		t.Fatalf("dry run rewrote HEAD")
+	}
+
+	if _, err := run(first); err == nil || !strings.Contains(err.Error(), "is not HEAD") {
+		t.Errorf("expected an error amending a commit below HEAD, got %v", err)
+	}
+
+	if _, err := run(); err != nil {
+		t.Fatalf("amend-message: %v", err)
+	}
+	if msg := repo.Run("log", "-1", "--format=%B"); msg != "Add b.txt\n\nExplain why b is needed." {
+		t.Errorf("unexpected amended message %q", msg)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/cliprof"
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/prompt/analyze"
@@ -186,6 +187,7 @@ type analyzeOptions struct {
 	jsonOutput bool
 	branch     string
 	baseBranch string
+	group      string // Job group to add the jobs to
 }
 
 // AnalyzeResult is the JSON output format for analyze command
@@ -193,6 +195,7 @@ type AnalyzeResult struct {
 	Jobs         []AnalyzeJobInfo `json:"jobs"`
 	AnalysisType string           `json:"analysis_type"`
 	Files        []string         `json:"files"`
+	Group        string           `json:"group,omitempty"` // Job group of a per-file analysis
 }
 
 // AnalyzeJobInfo contains job details for JSON output
@@ -369,6 +372,8 @@ func runPerFileAnalysis(cmd *cobra.Command, repoRoot string, analysisType *analy
 	if !opts.quiet && !opts.jsonOutput {
 		cmd.Printf("Creating %d analysis jobs (%q, one per file)...\n", len(files), analysisType.Name)
 	}
+	// Track the jobs as one group, to follow and cancel them together
+	opts.group = fmt.Sprintf("analyze-%s-%s", analysisType.Name, time.Now().Format("20060102-150405"))
 
 	var jobInfos []AnalyzeJobInfo
 	for i, fileName := range fileNames {
@@ -412,6 +417,7 @@ func runPerFileAnalysis(cmd *cobra.Command, repoRoot string, analysisType *analy
 			Jobs:         jobInfos,
 			AnalysisType: analysisType.Name,
 			Files:        fileNames,
+			Group:        opts.group,
 		}
 		enc := json.NewEncoder(cmd.OutOrStdout())
 		return enc.Encode(result)
@@ -423,6 +429,7 @@ func runPerFileAnalysis(cmd *cobra.Command, repoRoot string, analysisType *analy
 			jobIDs[i] = info.ID
 		}
 		cmd.Printf("\nCreated %d jobs: %v\n", len(jobIDs), jobIDs)
+		cmd.Printf("Use 'roborev group status %s' to follow their progress.\n", opts.group)
 		cmd.Println("Use 'roborev fix <job_id>' to apply fixes for individual jobs.")
 	}
 
@@ -485,7 +492,7 @@ func enqueueAnalysisJob(repoRoot, prompt, outputPrefix, label string, opts analy
 	if opts.branch != "" && opts.branch != "HEAD" {
 		branch = opts.branch
 	}
-	reqBody, _ := json.Marshal(map[string]interface{}{
+	reqFields := map[string]interface{}{
 		"repo_path":     repoRoot,
 		"git_ref":       label, // Use analysis type name as the TUI label
 		"branch":        branch,
@@ -495,7 +502,12 @@ func enqueueAnalysisJob(repoRoot, prompt, outputPrefix, label string, opts analy
 		"custom_prompt": prompt,
 		"output_prefix": outputPrefix,
 		"agentic":       true, // Agentic mode needed for reading files when prompt exceeds size limit
-	})
+	}
+	if opts.group != "" {
+		reqFields["group"] = opts.group
+		reqFields["group_kind"] = storage.JobGroupAnalyze
+	}
+	reqBody, _ := json.Marshal(reqFields)
 
 	resp, err := http.Post(serverAddr+"/api/enqueue", "application/json", bytes.NewReader(reqBody))
 	if err != nil {
@@ -648,6 +660,7 @@ func runAnalyzeAndFix(cmd *cobra.Command, serverAddr, repoRoot string, jobID int
 // waitForAnalysisJob polls until the job completes and returns the review.
 // The context controls the maximum wait time.
 func waitForAnalysisJob(ctx context.Context, serverAddr string, jobID int64) (*storage.Review, error) {
+	defer cliprof.Span(cliprof.PhaseAgent)()
 	client := &http.Client{Timeout: 30 * time.Second}
 	pollInterval := 1 * time.Second
 	maxInterval := 5 * time.Second
@@ -817,7 +830,7 @@ func runFixAgent(cmd *cobra.Command, repoPath, agentName, model, reasoning, prom
 		ctx = context.Background()
 	}
 
-	_, err = a.Review(ctx, repoPath, "fix", prompt, out)
+	_, err = runAgent(ctx, a, repoPath, "fix", prompt, out)
 	if fmtr != nil {
 		fmtr.Flush()
 	}
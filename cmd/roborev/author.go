@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func authorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "author",
+		Short: "Manage aliases of commit authors",
+		Long: `Manage aliases that make several names or emails one commit author in
+author filters ('roborev list --author') and statistics ('roborev stats
+--by-author').
+
+Commit authors are stored as the repo's .mailmap maps them, so a .mailmap
+entry covers the clones of that repo. Aliases cover everything else, such
+as a name used across many repos without a .mailmap, and match
+case-insensitively.
+
+Subcommands:
+  alias   - Make a name or email stand for an author
+  unalias - Remove an alias
+  list    - List aliases
+`,
+	}
+
+	cmd.AddCommand(authorAliasCmd())
+	cmd.AddCommand(authorUnaliasCmd())
+	cmd.AddCommand(authorListCmd())
+	return cmd
+}
+
+func authorAliasCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "alias <alias> <author>",
+		Short: "Make a name or email stand for an author",
+		Long: `Make a name or email that commits were authored under stand for an
+author. If the author is itself an alias, the alias stands for the author
+behind it, and aliases of the alias move over too, so aliases never chain.
+
+Examples:
+  roborev author alias "Jane D" "Jane Doe"
+  roborev author alias jane.doe@corp "Jane Doe"
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			if err := db.SetAuthorAlias(args[0], args[1]); err != nil {
+				return fmt.Errorf("set alias: %w", err)
+			}
+			author, err := db.CanonicalAuthor(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is now an alias of %s\n", args[0], author)
+			return nil
+		},
+	}
+}
+
+func authorUnaliasCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unalias <alias>",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			deleted, err := db.DeleteAuthorAlias(args[0])
+			if err != nil {
+				return fmt.Errorf("remove alias: %w", err)
+			}
+			if !deleted {
+				return fmt.Errorf("no alias %q (see 'roborev author list')", args[0])
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed alias %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func authorListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			aliases, err := db.ListAuthorAliases()
+			if err != nil {
+				return fmt.Errorf("list aliases: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			if len(aliases) == 0 {
+				fmt.Fprintln(out, "No author aliases. Add one with 'roborev author alias <alias> <author>'.")
+				return nil
+			}
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "AUTHOR\tALIAS")
+			for _, a := range aliases {
+				fmt.Fprintf(tw, "%s\t%s\n", a.Author, a.Alias)
+			}
+			return tw.Flush()
+		},
+	}
+}
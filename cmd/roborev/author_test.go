@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func TestAuthorAliasAndStats(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ sha, author string }{{"aaa", "Jane Doe"}, {"bbb", "Jane D"}, {"ccc", "Bob"}} {
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, c.sha, c.author, "Subject", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: c.sha, Agent: "codex"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	execute := func(cmd *cobra.Command, args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if got := execute(authorCmd(), "alias", "Jane D", "Jane Doe"); !strings.Contains(got, "Jane D is now an alias of Jane Doe") {
+		t.Errorf("unexpected alias output: %s", got)
+	}
+	if got := execute(authorCmd(), "list"); !strings.Contains(got, "Jane Doe") || !strings.Contains(got, "Jane D") {
+		t.Errorf("unexpected list output: %s", got)
+	}
+
+	got := execute(statsCmd(), "--by-author", "--since", "1h")
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "Jane Doe") || !strings.Contains(lines[1], "2") || !strings.HasPrefix(lines[2], "Bob") {
+		t.Errorf("unexpected stats output:\n%s", got)
+	}
+
+	execute(authorCmd(), "unalias", "jane d")
+	cmd := authorCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"unalias", "Jane D"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected an error removing a missing alias")
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func autofixCmd() *cobra.Command {
+	var (
+		repoPath   string
+		severity   string
+		since      string
+		limit      int
+		dryRun     bool
+		branchName string
+		testCmd    string
+		openPR     bool
+		opts       fixOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "autofix",
+		Short: "Fix trivial findings from recent reviews in one batch",
+		Long: `Collect the open findings of recent unaddressed reviews that are no more
+severe than --severity and name a file, and fix them all in one pass on a
+new branch.
+
+The fix agent applies the findings and commits on the new branch, then the
+repo's tests are run: --test-cmd, or "go test ./...", "cargo test",
+"npm test" or "make test" depending on the files in the repo. When they
+pass, reviews whose findings were all fixed are marked addressed, the rest
+get a comment listing what was fixed, and with --pr the branch is pushed
+and opened as a draft pull request with gh. When they fail the branch is
+kept for inspection and nothing is marked addressed. Either way you are
+returned to the branch you started on.
+
+Findings dismissed or assigned in 'roborev triage' are left out. The
+working tree must be clean.
+
+Examples:
+  roborev autofix --dry-run               # List what would be fixed
+  roborev autofix --repo . --severity low
+  roborev autofix --severity medium --since 30d --pr
+  roborev autofix --test-cmd "make check"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxSeverity, err := config.NormalizeMinSeverity(severity)
+			if err != nil || maxSeverity == "" {
+				return fmt.Errorf("invalid --severity %q (valid: critical, high, medium, low)", severity)
+			}
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			workDir, err := git.GetRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			mainRoot, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			findings, err := loadFixableFindings(mainRoot, sinceTime, maxSeverity, limit)
+			if err != nil {
+				return err
+			}
+			if len(findings) == 0 {
+				cmd.Printf("No open findings at %s severity or below since %s.\n", maxSeverity, sinceTime.Format("2006-01-02"))
+				return nil
+			}
+			printFixableFindings(cmd.OutOrStdout(), findings)
+			if dryRun {
+				cmd.Printf("\nDry run: would fix %d findings from %d reviews on a new branch.\n",
+					len(findings), len(reviewsOf(findings)))
+				return nil
+			}
+
+			if branchName == "" {
+				branchName = "roborev/autofix-" + time.Now().Format("20060102-150405")
+			}
+			if testCmd == "" {
+				testCmd = detectTestCommand(workDir)
+			}
+			return runAutofix(cmd, autofixParams{
+				workDir:  workDir,
+				branch:   branchName,
+				testCmd:  testCmd,
+				openPR:   openPR,
+				findings: findings,
+				opts:     opts,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&severity, "severity", "low", "most severe findings to fix: low, medium, high or critical")
+	cmd.Flags().StringVar(&since, "since", "14d", "only reviews created since, e.g. 7d, 2w, 36h or 2026-01-31")
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of findings to fix at once (0 for no limit)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the findings that would be fixed and exit")
+	cmd.Flags().StringVar(&branchName, "branch", "", "branch to create for the fixes (default: roborev/autofix-<timestamp>)")
+	cmd.Flags().StringVar(&testCmd, "test-cmd", "", "command that runs the repo's tests (default: detected)")
+	cmd.Flags().BoolVar(&openPR, "pr", false, "push the branch and open a draft pull request with gh")
+	cmd.Flags().StringVar(&opts.agentName, "agent", "", "agent to use for fixes (default: from config)")
+	cmd.Flags().StringVar(&opts.model, "model", "", "model for agent")
+	cmd.Flags().StringVar(&opts.reasoning, "reasoning", "", "reasoning level: fast, standard, or thorough")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "suppress agent and test output")
+
+	return cmd
+}
+
+// findingFile matches a file name or path in finding text, e.g. main.go,
+// src/app.ts:42. The two-character minimum skips abbreviations like "e.g".
+var findingFile = regexp.MustCompile(`\b[\w-]{2,}(?:/[\w.-]+)*\.[a-z][a-z0-9]{0,4}\b`)
+
+// loadFixableFindings returns the open findings of the repo's unaddressed
+// reviews since the given time that are at most maxSeverity and name a
+// file, up to limit (0 for no limit), oldest review first
+func loadFixableFindings(repoRoot string, since time.Time, maxSeverity string, limit int) ([]storage.TriageItem, error) {
+	if dbStamp(storage.DefaultDBPath()) == "" {
+		return nil, nil
+	}
+	db, err := storage.OpenReadOnly(storage.DefaultDBPath())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	repo, err := db.GetRepoByPath(context.Background(), repoRoot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("look up repo: %w", err)
+	}
+	items, err := db.ListOpenFindings(repo.ID, since)
+	if err != nil {
+		return nil, fmt.Errorf("list findings: %w", err)
+	}
+	return filterFixable(items, maxSeverity, limit), nil
+}
+
+// filterFixable keeps the findings at most maxSeverity that name a file,
+// up to limit (0 for no limit)
+func filterFixable(items []storage.TriageItem, maxSeverity string, limit int) []storage.TriageItem {
+	var fixable []storage.TriageItem
+	for _, item := range items {
+		if limit > 0 && len(fixable) >= limit {
+			break
+		}
+		if storage.SeverityAtLeast(maxSeverity, item.Finding.Severity) && findingFile.MatchString(item.Finding.Text) {
+			fixable = append(fixable, item)
+		}
+	}
+	return fixable
+}
+
+// reviewsOf returns the IDs of the jobs findings come from, in order
+func reviewsOf(findings []storage.TriageItem) []int64 {
+	var jobIDs []int64
+	seen := make(map[int64]bool)
+	for _, f := range findings {
+		if !seen[f.JobID] {
+			seen[f.JobID] = true
+			jobIDs = append(jobIDs, f.JobID)
+		}
+	}
+	return jobIDs
+}
+
+func printFixableFindings(w io.Writer, findings []storage.TriageItem) {
+	jobID := int64(0)
+	for _, f := range findings {
+		if f.JobID != jobID {
+			jobID = f.JobID
+			fmt.Fprintf(w, "\nJob %d (%s, %s):\n", f.JobID, shortRef(f.GitRef), f.Agent)
+		}
+		fmt.Fprintf(w, "  [%s] %s\n", f.Finding.Severity, firstLine(f.Finding.Text))
+	}
+}
+
+// detectTestCommand guesses the command that runs a repo's tests from the
+// files at its root, or returns "" if there is no telling
+func detectTestCommand(repoRoot string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(repoRoot, name))
+		return err == nil
+	}
+	switch {
+	case exists("go.mod"):
+		return "go test ./..."
+	case exists("Cargo.toml"):
+		return "cargo test"
+	case exists("package.json"):
+		return "npm test"
+	case exists("Makefile"):
+		if data, err := os.ReadFile(filepath.Join(repoRoot, "Makefile")); err == nil &&
+			regexp.MustCompile(`(?m)^test:`).Match(data) {
+			return "make test"
+		}
+	}
+	return ""
+}
+
+type autofixParams struct {
+	workDir  string
+	branch   string
+	testCmd  string
+	openPR   bool
+	findings []storage.TriageItem
+	opts     fixOptions
+}
+
+func runAutofix(cmd *cobra.Command, p autofixParams) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ensureDaemon(); err != nil {
+		return err
+	}
+	if dirty, err := git.HasUncommittedChanges(p.workDir); err != nil {
+		return fmt.Errorf("check working tree: %w", err)
+	} else if dirty {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them first")
+	}
+
+	// Return to where we started, by branch name or by commit if detached
+	startRef := git.GetCurrentBranch(p.workDir)
+	if startRef == "" {
+		sha, err := git.ResolveSHA(p.workDir, "HEAD")
+		if err != nil {
+			return fmt.Errorf("resolve HEAD: %w", err)
+		}
+		startRef = sha
+	}
+	if err := runGit(p.workDir, "checkout", "-q", "-b", p.branch); err != nil {
+		return fmt.Errorf("create branch %s: %w", p.branch, err)
+	}
+	restore := func() {
+		if err := runGit(p.workDir, "checkout", "-q", startRef); err != nil {
+			cmd.Printf("Warning: could not switch back to %s: %v\n", startRef, err)
+		}
+	}
+
+	fixAgent, err := resolveFixAgent(p.workDir, p.opts)
+	if err != nil {
+		restore()
+		return err
+	}
+	var out io.Writer = io.Discard
+	var fmtr *streamFormatter
+	if !p.opts.quiet {
+		cmd.Printf("\nRunning fix agent (%s) on branch %s...\n\n", fixAgent.Name(), p.branch)
+		fmtr = newStreamFormatter(cmd.OutOrStdout(), writerIsTerminal(cmd.OutOrStdout()))
+		out = fmtr
+	}
+	result, err := fixJobDirect(ctx, fixJobParams{RepoRoot: p.workDir, Agent: fixAgent, Output: out},
+		buildAutofixPrompt(p.findings))
+	if fmtr != nil {
+		fmtr.Flush()
+	}
+	if err != nil {
+		_ = git.ResetWorkingTree(p.workDir)
+		restore()
+		_ = runGit(p.workDir, "branch", "-D", p.branch)
+		return err
+	}
+	if !result.CommitCreated {
+		_ = git.ResetWorkingTree(p.workDir)
+		restore()
+		_ = runGit(p.workDir, "branch", "-D", p.branch)
+		cmd.Println("\nThe fix agent made no commit; nothing to do.")
+		return nil
+	}
+	commit := shortSHA(result.NewCommitSHA)
+
+	if p.testCmd == "" {
+		cmd.Println("\nNo test command detected; skipping tests (set one with --test-cmd).")
+	} else {
+		cmd.Printf("\nRunning tests: %s\n", p.testCmd)
+		if err := runTestCommand(ctx, p.workDir, p.testCmd, cmd.OutOrStdout(), p.opts.quiet); err != nil {
+			restore()
+			return fmt.Errorf("tests failed on %s (commit %s); the branch was kept for inspection: %w", p.branch, commit, err)
+		}
+	}
+
+	prURL := ""
+	if p.openPR {
+		if prURL, err = openDraftPR(p.workDir, p.branch, p.findings); err != nil {
+			cmd.Printf("Warning: could not open a pull request: %v\n", err)
+		}
+	}
+	restore()
+
+	if err := enqueueIfNeeded(serverAddr, p.workDir, result.NewCommitSHA); err != nil {
+		cmd.Printf("Warning: could not enqueue review for fix commit: %v\n", err)
+	}
+	addressed := resolveAutofixedReviews(cmd, p.findings, p.branch, commit)
+
+	cmd.Printf("\nFixed %d findings from %d reviews on branch %s (commit %s); %d reviews marked addressed.\n",
+		len(p.findings), len(reviewsOf(p.findings)), p.branch, commit, addressed)
+	if prURL != "" {
+		cmd.Printf("Draft pull request: %s\n", prURL)
+	}
+	return nil
+}
+
+// resolveAutofixedReviews comments on each review the fixes came from, and
+// marks those whose findings were all fixed addressed. Returns the number
+// marked addressed.
+func resolveAutofixedReviews(cmd *cobra.Command, findings []storage.TriageItem, branch, commit string) int {
+	fixed := make(map[int64][]storage.TriageItem)
+	for _, f := range findings {
+		fixed[f.JobID] = append(fixed[f.JobID], f)
+	}
+
+	addressed := 0
+	for _, jobID := range reviewsOf(findings) {
+		items := fixed[jobID]
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Fixed by `roborev autofix` on branch %s (commit %s):\n", branch, commit)
+		for _, f := range items {
+			fmt.Fprintf(&sb, "- [%s] %s\n", f.Finding.Severity, firstLine(f.Finding.Text))
+		}
+		if err := addJobResponse(serverAddr, jobID, "roborev-autofix", sb.String()); err != nil {
+			cmd.Printf("Warning: could not comment on job %d: %v\n", jobID, err)
+		}
+
+		review, err := fetchReview(context.Background(), serverAddr, jobID)
+		if err != nil || len(storage.ExtractFindings(review.Output)) != len(items) {
+			continue
+		}
+		if err := markJobAddressed(serverAddr, jobID); err != nil {
+			cmd.Printf("Warning: could not mark job %d as addressed: %v\n", jobID, err)
+			continue
+		}
+		addressed++
+	}
+	return addressed
+}
+
+// buildAutofixPrompt asks the fix agent to fix the findings and commit
+func buildAutofixPrompt(findings []storage.TriageItem) string {
+	var sb strings.Builder
+	sb.WriteString("# Fix Request\n\n")
+	sb.WriteString("Code reviews of recent commits reported the following minor findings. ")
+	sb.WriteString("Each is expected to be a small, local fix.\n\n")
+	sb.WriteString("## Findings\n\n")
+	for i, f := range findings {
+		fmt.Fprintf(&sb, "### %d. From the review of %s\n\n%s\n\n", i+1, shortRef(f.GitRef), f.Finding.Text)
+	}
+	sb.WriteString("## Instructions\n\n")
+	sb.WriteString("Fix each finding with the smallest change that resolves it. ")
+	sb.WriteString("Skip any finding that no longer applies or would need a larger redesign; do not refactor beyond what the findings ask.\n\n")
+	sb.WriteString("After making changes:\n")
+	sb.WriteString("1. Verify the code still compiles/passes linting\n")
+	sb.WriteString("2. Create a single git commit with a message listing the findings fixed\n")
+	return sb.String()
+}
+
+// runTestCommand runs a shell command in dir, streaming its output to w
+// unless quiet
+func runTestCommand(ctx context.Context, dir, command string, w io.Writer, quiet bool) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	c.Dir = dir
+	if !quiet {
+		c.Stdout, c.Stderr = w, w
+	}
+	return c.Run()
+}
+
+// openDraftPR pushes branch to origin and opens a draft pull request for it
+// with gh, returning its URL
+func openDraftPR(dir, branch string, findings []storage.TriageItem) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("gh is not installed")
+	}
+	if err := runGit(dir, "push", "-q", "-u", "origin", branch); err != nil {
+		return "", fmt.Errorf("push %s: %w", branch, err)
+	}
+
+	var body strings.Builder
+	body.WriteString("Fixes for minor review findings, applied by `roborev autofix`:\n\n")
+	for _, f := range findings {
+		fmt.Fprintf(&body, "- [%s] %s (job %d)\n", f.Finding.Severity, firstLine(f.Finding.Text), f.JobID)
+	}
+	title := fmt.Sprintf("Fix %d minor review findings", len(findings))
+	c := exec.Command("gh", "pr", "create", "--draft", "--head", branch, "--title", title, "--body", body.String())
+	c.Dir = dir
+	out, err := c.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("gh pr create: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runGit runs a git command in dir, returning its stderr on failure
+func runGit(dir string, args ...string) error {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func TestFilterFixable(t *testing.T) {
+	item := func(jobID int64, severity, text string) storage.TriageItem {
+		return storage.TriageItem{JobID: jobID, Finding: storage.Finding{Severity: severity, Text: text}}
+	}
+	items := []storage.TriageItem{
+		item(1, "low", "- Low: typo in cmd/main.go:12"),
+		item(1, "high", "- High: SQL injection in db.go"),
+		item(2, "low", "- Low: consider better naming, e.g. clearer names"),
+		item(2, "medium", "- Medium: unchecked error in server.go"),
+		item(3, "low", "- Low: unused variable in util.ts"),
+	}
+
+	got := filterFixable(items, "low", 0)
+	if len(got) != 2 || got[0].JobID != 1 || got[1].JobID != 3 {
+		t.Errorf("expected the low findings naming a file, got %+v", got)
+	}
+	if got := filterFixable(items, "medium", 0); len(got) != 3 {
+		t.Errorf("expected 3 findings at medium or below, got %+v", got)
+	}
+	if got := filterFixable(items, "medium", 2); len(got) != 2 {
+		t.Errorf("expected the limit to apply, got %+v", got)
+	}
+	if ids := reviewsOf(filterFixable(items, "critical", 0)); len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("unexpected reviews %v", ids)
+	}
+}
+
+func TestDetectTestCommand(t *testing.T) {
+	tests := []struct {
+		file, content, want string
+	}{
+		{"go.mod", "module x\n", "go test ./..."},
+		{"Cargo.toml", "[package]\n", "cargo test"},
+		{"package.json", "{}\n", "npm test"},
+		{"Makefile", "build:\n\tcc x.c\ntest:\n\t./run-tests\n", "make test"},
+		{"Makefile", "build:\n\tcc x.c\n", ""},
+		{"README", "hello\n", ""},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := detectTestCommand(dir); got != tt.want {
+			t.Errorf("detectTestCommand with %s = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestBuildAutofixPrompt(t *testing.T) {
+	prompt := buildAutofixPrompt([]storage.TriageItem{
+		{GitRef: "abc1234def", Finding: storage.Finding{Severity: "low", Text: "- Low: typo in main.go"}},
+	})
+	for _, want := range []string{"### 1. From the review of abc1234", "- Low: typo in main.go", "single git commit"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestRunAutofix(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	findings := []storage.TriageItem{
+		{JobID: 10, GitRef: "aaa", Finding: storage.Finding{Index: 0, Severity: "low", Text: "- Low: typo in main.go"}},
+		{JobID: 20, GitRef: "bbb", Finding: storage.Finding{Index: 1, Severity: "low", Text: "- Low: stray print in util.go"}},
+	}
+
+	agent.Register(&functionalMockAgent{nameVal: "test", reviewFunc: func(ctx context.Context, repoPath, commitSHA, prompt string, output io.Writer) (string, error) {
+		if err := os.WriteFile(filepath.Join(repoPath, "main.go"), []byte("package main // fixed\n"), 0644); err != nil {
+			return "", err
+		}
+		for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "Fix typo"}} {
+			c := exec.Command("git", args...)
+			c.Dir = repoPath
+			if out, err := c.CombinedOutput(); err != nil {
+				t.Errorf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		return "fixed", nil
+	}})
+	defer agent.Register(agent.NewTestAgent())
+
+	run := func(t *testing.T, testCmd string) (*TestGitRepo, string, int32, int32, error) {
+		t.Helper()
+		repo := newTestGitRepo(t)
+		repo.CommitFile("main.go", "package main\n", "initial")
+		startBranch := repo.Run("rev-parse", "--abbrev-ref", "HEAD")
+
+		var comments, addressed atomic.Int32
+		_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/comment":
+				comments.Add(1)
+				w.WriteHeader(http.StatusCreated)
+			case "/api/review/address":
+				addressed.Add(1)
+			case "/api/review":
+				// Job 10 had only the fixed finding; job 20 had another
+				output := "- Low: typo in main.go"
+				if r.URL.Query().Get("job_id") == "20" {
+					output = "- High: race in db.go\n- Low: stray print in util.go"
+				}
+				json.NewEncoder(w).Encode(storage.Review{Output: output})
+			case "/api/jobs":
+				json.NewEncoder(w).Encode(map[string]any{"jobs": []storage.ReviewJob{{ID: 1}}})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer cleanup()
+
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		err := runAutofix(cmd, autofixParams{
+			workDir:  repo.Dir,
+			branch:   "roborev/autofix-test",
+			testCmd:  testCmd,
+			findings: findings,
+			opts:     fixOptions{agentName: "test", reasoning: "fast", quiet: true},
+		})
+		if branch := repo.Run("rev-parse", "--abbrev-ref", "HEAD"); branch != startBranch {
+			t.Errorf("expected to be returned to %s, on %s", startBranch, branch)
+		}
+		return repo, out.String(), comments.Load(), addressed.Load(), err
+	}
+
+	t.Run("tests pass", func(t *testing.T) {
+		repo, out, comments, addressed, err := run(t, "git --version")
+		if err != nil {
+			t.Fatalf("runAutofix: %v", err)
+		}
+		if got := repo.Run("log", "-1", "--format=%s", "roborev/autofix-test"); got != "Fix typo" {
+			t.Errorf("expected the fix committed on the branch, got %q", got)
+		}
+		if comments != 2 || addressed != 1 {
+			t.Errorf("expected 2 comments and only the fully fixed review addressed, got %d and %d", comments, addressed)
+		}
+		if !strings.Contains(out, "Fixed 2 findings from 2 reviews on branch roborev/autofix-test") {
+			t.Errorf("unexpected output:\n%s", out)
+		}
+	})
+
+	t.Run("tests fail", func(t *testing.T) {
+		repo, _, comments, addressed, err := run(t, "git no-such-command")
+		if err == nil || !strings.Contains(err.Error(), "tests failed") {
+			t.Fatalf("expected a test failure, got %v", err)
+		}
+		if got := repo.Run("log", "-1", "--format=%s", "roborev/autofix-test"); got != "Fix typo" {
+			t.Errorf("expected the branch kept for inspection, got %q", got)
+		}
+		if comments != 0 || addressed != 0 {
+			t.Errorf("expected no reviews touched, got %d comments and %d addressed", comments, addressed)
+		}
+	})
+}
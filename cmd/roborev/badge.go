@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func badgeCmd() *cobra.Command {
+	var (
+		repoPath string
+		branch   string
+		metric   string
+		output   string
+		printURL bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "badge",
+		Short: "Generate an SVG review status badge",
+		Long: `Generate an SVG badge showing review health for a branch.
+
+Metrics:
+  verdict   verdict of the latest reviewed commit (pass, fail, addressed)
+  coverage  percentage of the last 50 commits that have been reviewed;
+            commits skipped on purpose ([skip roborev] or a bot author
+            rule) are not counted
+
+The branch defaults to the repository's default branch. The daemon also
+serves badges at /api/badge, so dashboards on this machine can embed the
+URL printed by --url. To show a badge in a README, write it to a file and
+publish it (e.g. from CI).
+
+Examples:
+  roborev badge > roborev.svg
+  roborev badge --metric coverage -o docs/review-coverage.svg
+  roborev badge --url`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if metric != "verdict" && metric != "coverage" {
+				return fmt.Errorf("invalid --metric %q (valid: verdict, coverage)", metric)
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+
+			params := url.Values{"repo": {root}, "metric": {metric}}
+			if branch != "" {
+				params.Set("branch", branch)
+			}
+			badgeURL := getDaemonAddr() + "/api/badge?" + params.Encode()
+			if printURL {
+				cmd.Println(badgeURL)
+				return nil
+			}
+
+			client := &http.Client{Timeout: 30 * time.Second}
+			resp, err := client.Get(badgeURL)
+			if err != nil {
+				return fmt.Errorf("fetch badge: %w", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("read badge: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("fetch badge: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+			}
+
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(body)
+				return err
+			}
+			if err := os.WriteFile(output, body, 0644); err != nil {
+				return fmt.Errorf("write badge: %w", err)
+			}
+			cmd.Printf("Wrote %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&branch, "branch", "", "branch to summarize (default: repository default branch)")
+	cmd.Flags().StringVar(&metric, "metric", "verdict", "badge metric: verdict or coverage")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the SVG to a file instead of stdout")
+	cmd.Flags().BoolVar(&printURL, "url", false, "print the daemon badge URL instead of the SVG")
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBadgeCmd(t *testing.T) {
+	repo := newTestGitRepo(t)
+	repo.CommitFile("a.txt", "a", "initial")
+
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg"></svg>`
+	var gotQuery map[string][]string
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/badge" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(svg))
+	}))
+	defer cleanup()
+
+	out := filepath.Join(t.TempDir(), "badge.svg")
+	cmd := badgeCmd()
+	cmd.SetArgs([]string{"--repo", repo.Dir, "--metric", "coverage", "--branch", "main", "-o", out})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("badge: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read badge: %v", err)
+	}
+	if string(data) != svg {
+		t.Errorf("unexpected badge content %q", data)
+	}
+	if gotQuery["repo"][0] != repo.Dir || gotQuery["metric"][0] != "coverage" || gotQuery["branch"][0] != "main" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+}
+
+func TestBadgeCmdRejectsUnknownMetric(t *testing.T) {
+	cmd := badgeCmd()
+	cmd.SetArgs([]string{"--metric", "bogus"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for unknown metric")
+	}
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// benchCase is one diff of a benchmark suite with the findings a review of
+// it should report. A case without findings is clean and should pass.
+type benchCase struct {
+	Name        string             `toml:"-"`
+	Patch       string             `toml:"-"`
+	Description string             `toml:"description"`
+	Findings    []benchExpectation `toml:"findings"`
+}
+
+// benchExpectation describes a finding a review must report. A reported
+// finding matches when it mentions the file and any of the keywords.
+type benchExpectation struct {
+	File     string   `toml:"file"`
+	Keywords []string `toml:"keywords"`
+}
+
+func benchCmd() *cobra.Command {
+	var (
+		suite     string
+		agents    []string
+		model     string
+		reasoning string
+		timeout   time.Duration
+		noSave    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Score agents against a suite of known-buggy diffs",
+		Long: `Run a benchmark suite of diffs with known bugs through review agents and
+score how many of the bugs each agent reports, so prompt and model changes
+can be compared over time.
+
+A suite is a directory of cases. Each case is a patch, <name>.patch, and
+its expected findings, <name>.toml:
+
+  description = "Pagination drops the last page"
+
+  [[findings]]
+  file = "pager.go"
+  keywords = ["off-by-one", "off by one", "last page"]
+
+A reported finding detects an expected one when it mentions the file and
+any of the keywords (case-insensitive). A case with no [[findings]] is
+clean: an agent that fails it raises a false alarm. Findings that match
+nothing expected are counted as extra.
+
+Each case is reviewed as a patch in an empty scratch repository, so agents
+cannot see the expected findings. Runs are stored with the agent, model,
+roborev version and system prompt hash; list them with 'roborev bench
+history'. Set ROBOREV_VCR to record agent responses and replay them.
+
+Examples:
+  roborev bench --suite fixtures/
+  roborev bench --suite fixtures/ --agent codex --agent claude-code
+  roborev bench history --suite fixtures/
+  roborev bench show 12`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if suite == "" {
+				return fmt.Errorf("--suite is required")
+			}
+			suiteDir, err := filepath.Abs(suite)
+			if err != nil {
+				return err
+			}
+			cases, err := loadBenchSuite(suiteDir)
+			if err != nil {
+				return err
+			}
+
+			if len(agents) == 0 {
+				cfg, err := config.LoadGlobal()
+				if err != nil {
+					cfg = config.DefaultConfig()
+				}
+				agents = []string{config.ResolveAgent("", "", cfg)}
+			}
+
+			var db *storage.DB
+			if !noSave {
+				if db, err = storage.Open(storage.DefaultDBPath()); err != nil {
+					return fmt.Errorf("open database: %w", err)
+				}
+				defer db.Close()
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			out := cmd.OutOrStdout()
+			var runs []storage.BenchRun
+			for _, name := range agents {
+				a, err := agent.GetAvailable(name)
+				if err != nil {
+					return fmt.Errorf("get agent: %w", err)
+				}
+				a = a.WithReasoning(agent.ParseReasoningLevel(reasoning))
+				if model != "" {
+					a = a.WithModel(model)
+				}
+
+				run := runBench(ctx, out, a, cases, timeout)
+				run.Suite = suiteDir
+				run.Model = model
+				if db != nil {
+					if err := db.SaveBenchRun(&run); err != nil {
+						return fmt.Errorf("save run: %w", err)
+					}
+				}
+				runs = append(runs, run)
+			}
+
+			fmt.Fprintln(out)
+			printBenchRuns(out, runs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&suite, "suite", "", "benchmark suite directory (required)")
+	cmd.Flags().StringArrayVar(&agents, "agent", nil, "agent to benchmark (repeatable; default: the configured agent)")
+	cmd.Flags().StringVar(&model, "model", "", "model for the agents")
+	cmd.Flags().StringVar(&reasoning, "reasoning", "thorough", "reasoning level: thorough, standard or fast")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "time limit for each case")
+	cmd.Flags().BoolVar(&noSave, "no-save", false, "don't store the run")
+
+	cmd.AddCommand(benchHistoryCmd())
+	cmd.AddCommand(benchShowCmd())
+	return cmd
+}
+
+func benchHistoryCmd() *cobra.Command {
+	var (
+		suite string
+		limit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List stored benchmark runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if suite != "" {
+				abs, err := filepath.Abs(suite)
+				if err != nil {
+					return err
+				}
+				suite = abs
+			}
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			runs, err := db.ListBenchRuns(suite, limit)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				cmd.Println("No benchmark runs. Run one with 'roborev bench --suite <dir>'.")
+				return nil
+			}
+			printBenchRuns(cmd.OutOrStdout(), runs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&suite, "suite", "", "only show runs of this suite directory")
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of runs to show (0 for all)")
+	return cmd
+}
+
+func benchShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <run_id>",
+		Short: "Show the per-case results of a benchmark run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid run_id: %s", args[0])
+			}
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			run, err := db.GetBenchRun(id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no benchmark run %d", id)
+			}
+			if err != nil {
+				return err
+			}
+			printBenchRun(cmd.OutOrStdout(), run)
+			return nil
+		},
+	}
+}
+
+// loadBenchSuite reads the cases of the suite in dir, in name order
+func loadBenchSuite(dir string) ([]benchCase, error) {
+	patches, err := filepath.Glob(filepath.Join(dir, "*.patch"))
+	if err != nil {
+		return nil, err
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no *.patch cases in %s", dir)
+	}
+	sort.Strings(patches)
+
+	cases := make([]benchCase, 0, len(patches))
+	for _, path := range patches {
+		name := strings.TrimSuffix(filepath.Base(path), ".patch")
+		patch, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c benchCase
+		expectPath := strings.TrimSuffix(path, ".patch") + ".toml"
+		if _, err := toml.DecodeFile(expectPath, &c); err != nil {
+			return nil, fmt.Errorf("case %s: %w", name, err)
+		}
+		for i, f := range c.Findings {
+			if f.File == "" && len(f.Keywords) == 0 {
+				return nil, fmt.Errorf("case %s: finding %d needs a file or keywords", name, i+1)
+			}
+		}
+		c.Name = name
+		c.Patch = string(patch)
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// runBench reviews every case with a and scores the reviews
+func runBench(ctx context.Context, w io.Writer, a agent.Agent, cases []benchCase, timeout time.Duration) storage.BenchRun {
+	run := storage.BenchRun{
+		Agent:          a.Name(),
+		PromptHash:     prompt.TemplateHash(a.Name(), prompt.SystemPromptType("dirty", "")),
+		RoborevVersion: version.Version,
+		Cases:          len(cases),
+	}
+	start := time.Now()
+	builder := prompt.NewBuilder(nil)
+
+	for i, c := range cases {
+		fmt.Fprintf(w, "[%s %d/%d] %s... ", a.Name(), i+1, len(cases), c.Name)
+		caseStart := time.Now()
+		output, err := reviewBenchCase(ctx, builder, a, c, timeout)
+		var result storage.BenchResult
+		if err != nil {
+			result = storage.BenchResult{Case: c.Name, Expected: len(c.Findings), Error: err.Error()}
+			fmt.Fprintf(w, "error: %v\n", err)
+		} else {
+			result = scoreBenchCase(c, output)
+			fmt.Fprintf(w, "%s\n", formatBenchResult(result))
+		}
+		result.DurationMs = time.Since(caseStart).Milliseconds()
+
+		run.Expected += result.Expected
+		run.Detected += result.Detected
+		run.Extra += result.Extra
+		if result.Error != "" {
+			run.Errors++
+		} else if len(c.Findings) == 0 && result.Verdict == "F" {
+			run.FalseAlarms++
+		}
+		run.Results = append(run.Results, result)
+	}
+	run.DurationMs = time.Since(start).Milliseconds()
+	return run
+}
+
+// reviewBenchCase reviews a case's patch in an empty scratch repository
+func reviewBenchCase(ctx context.Context, builder *prompt.Builder, a agent.Agent, c benchCase, timeout time.Duration) (string, error) {
+	dir, err := os.MkdirTemp("", "roborev-bench-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git init: %v: %s", err, out)
+	}
+
+	reviewPrompt, err := builder.BuildPatch(dir, c.Patch, c.Name, 0, 0, a.Name(), "")
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return runAgent(ctx, a, dir, "", reviewPrompt, io.Discard)
+}
+
+// scoreBenchCase counts the expected findings of c that a review reported.
+// Each reported finding detects at most one expected finding. Reported
+// findings that detect nothing are extra.
+func scoreBenchCase(c benchCase, output string) storage.BenchResult {
+	result := storage.BenchResult{
+		Case:     c.Name,
+		Expected: len(c.Findings),
+		Verdict:  storage.ParseVerdict(output),
+	}
+
+	var reported []string
+	for _, f := range storage.ExtractFindings(output) {
+		reported = append(reported, f.Text)
+	}
+	unlabeled := len(reported) == 0 && result.Verdict == "F"
+	if unlabeled {
+		// No severity labels to split on; judge the review as a whole
+		reported = []string{output}
+	}
+
+	used := make([]bool, len(reported))
+	for _, exp := range c.Findings {
+		for i, text := range reported {
+			if (!used[i] || unlabeled) && exp.matches(text) {
+				used[i] = true
+				result.Detected++
+				break
+			}
+		}
+	}
+	if !unlabeled {
+		for _, u := range used {
+			if !u {
+				result.Extra++
+			}
+		}
+	}
+	return result
+}
+
+// matches reports whether a reported finding describes e
+func (e benchExpectation) matches(text string) bool {
+	lower := strings.ToLower(text)
+	if e.File != "" {
+		file := strings.ToLower(e.File)
+		if !strings.Contains(lower, file) && !strings.Contains(lower, filepath.Base(file)) {
+			return false
+		}
+	}
+	if len(e.Keywords) == 0 {
+		return true
+	}
+	for _, kw := range e.Keywords {
+		if strings.Contains(lower, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBenchResult summarizes a case result on one line
+func formatBenchResult(r storage.BenchResult) string {
+	if r.Error != "" {
+		return "error: " + r.Error
+	}
+	var s string
+	if r.Expected == 0 {
+		s = "clean, passed"
+		if r.Verdict == "F" {
+			s = "clean, false alarm"
+		}
+	} else {
+		s = fmt.Sprintf("%d/%d found", r.Detected, r.Expected)
+	}
+	if r.Extra > 0 {
+		s += fmt.Sprintf(", %d extra", r.Extra)
+	}
+	return s
+}
+
+func printBenchRuns(w io.Writer, runs []storage.BenchRun) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tDATE\tSUITE\tAGENT\tMODEL\tPROMPT\tRECALL\tEXTRA\tFALSE ALARMS\tERRORS\tTIME")
+	for _, r := range runs {
+		id, date := "-", "-"
+		if r.ID != 0 {
+			id = strconv.FormatInt(r.ID, 10)
+			date = r.CreatedAt.Local().Format("2006-01-02 15:04")
+		}
+		model := r.Model
+		if model == "" {
+			model = "default"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d/%d (%.0f%%)\t%d\t%d\t%d\t%s\n",
+			id, date, filepath.Base(r.Suite), r.Agent, model, orDash(r.PromptHash),
+			r.Detected, r.Expected, 100*r.Recall(), r.Extra, r.FalseAlarms, r.Errors,
+			formatPlanDuration(float64(r.DurationMs)/1000))
+	}
+	tw.Flush()
+}
+
+func printBenchRun(w io.Writer, run *storage.BenchRun) {
+	model := run.Model
+	if model == "" {
+		model = "default"
+	}
+	fmt.Fprintf(w, "Run %d of %s\n", run.ID, run.Suite)
+	fmt.Fprintf(w, "Agent %s, model %s, prompt %s, roborev %s, %s\n\n",
+		run.Agent, model, orDash(run.PromptHash), run.RoborevVersion, run.CreatedAt.Local().Format("2006-01-02 15:04"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tRESULT\tTIME")
+	for _, r := range run.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Case, formatBenchResult(r), formatPlanDuration(float64(r.DurationMs)/1000))
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nRecall %d/%d (%.0f%%), %d extra, %d false alarms, %d errors\n",
+		run.Detected, run.Expected, 100*run.Recall(), run.Extra, run.FalseAlarms, run.Errors)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+)
+
+func writeBenchCase(t *testing.T, dir, name, patch, expect string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".patch"), []byte(patch), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if expect != "" {
+		if err := os.WriteFile(filepath.Join(dir, name+".toml"), []byte(expect), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadBenchSuite(t *testing.T) {
+	dir := t.TempDir()
+	writeBenchCase(t, dir, "b-clean", "diff --git a/x b/x\n", `description = "Harmless rename"`)
+	writeBenchCase(t, dir, "a-offbyone", "diff --git a/pager.go b/pager.go\n", `
+description = "Pagination drops the last page"
+
+[[findings]]
+file = "pager.go"
+keywords = ["off-by-one", "last page"]
+`)
+
+	cases, err := loadBenchSuite(dir)
+	if err != nil {
+		t.Fatalf("loadBenchSuite: %v", err)
+	}
+	if len(cases) != 2 || cases[0].Name != "a-offbyone" || cases[1].Name != "b-clean" {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+	if len(cases[0].Findings) != 1 || cases[0].Findings[0].File != "pager.go" || !strings.Contains(cases[0].Patch, "pager.go") {
+		t.Errorf("unexpected case: %+v", cases[0])
+	}
+	if len(cases[1].Findings) != 0 {
+		t.Errorf("clean case has findings: %+v", cases[1].Findings)
+	}
+
+	t.Run("missing expectations", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBenchCase(t, dir, "orphan", "diff\n", "")
+		if _, err := loadBenchSuite(dir); err == nil {
+			t.Error("expected error for a patch without a .toml")
+		}
+	})
+	t.Run("empty expectation", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBenchCase(t, dir, "empty", "diff\n", "[[findings]]\n")
+		if _, err := loadBenchSuite(dir); err == nil {
+			t.Error("expected error for a finding without file or keywords")
+		}
+	})
+	t.Run("empty suite", func(t *testing.T) {
+		if _, err := loadBenchSuite(t.TempDir()); err == nil {
+			t.Error("expected error for a suite without cases")
+		}
+	})
+}
+
+func TestScoreBenchCase(t *testing.T) {
+	buggy := benchCase{Name: "buggy", Findings: []benchExpectation{
+		{File: "internal/pager.go", Keywords: []string{"off-by-one", "last page"}},
+		{Keywords: []string{"nil map"}},
+	}}
+	clean := benchCase{Name: "clean"}
+
+	tests := []struct {
+		name         string
+		c            benchCase
+		output       string
+		wantDetected int
+		wantExtra    int
+		wantVerdict  string
+	}{
+		{
+			name: "both found plus an extra",
+			c:    buggy,
+			output: "Summary.\n\n- **High:** pager.go:42 - off-by-one drops the last page\n\n" +
+				"- **Medium:** cache.go:10 - writes to a nil map\n\n- **Low:** util.go:3 - unclear name\n",
+			wantDetected: 2, wantExtra: 1, wantVerdict: "F",
+		},
+		{
+			name:         "wrong file",
+			c:            buggy,
+			output:       "- High: other.go:42 - off-by-one in loop\n",
+			wantDetected: 0, wantExtra: 1, wantVerdict: "F",
+		},
+		{
+			name:         "one finding detects one expectation",
+			c:            benchCase{Findings: []benchExpectation{{Keywords: []string{"race"}}, {Keywords: []string{"race"}}}},
+			output:       "- High: main.go:1 - data race on counter\n",
+			wantDetected: 1, wantVerdict: "F",
+		},
+		{
+			name:         "missed",
+			c:            buggy,
+			output:       "No issues found.",
+			wantDetected: 0, wantVerdict: "P",
+		},
+		{
+			name:        "clean passes",
+			c:           clean,
+			output:      "No issues found.",
+			wantVerdict: "P",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreBenchCase(tt.c, tt.output)
+			if got.Detected != tt.wantDetected || got.Extra != tt.wantExtra || got.Verdict != tt.wantVerdict ||
+				got.Expected != len(tt.c.Findings) {
+				t.Errorf("scoreBenchCase() = %+v, want detected %d, extra %d, verdict %q",
+					got, tt.wantDetected, tt.wantExtra, tt.wantVerdict)
+			}
+		})
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	cases := []benchCase{
+		{Name: "buggy", Patch: "diff --git a/pager.go b/pager.go\n", Findings: []benchExpectation{{File: "pager.go"}}},
+		{Name: "clean", Patch: "diff --git a/x b/x\n"},
+	}
+	a := &agent.TestAgent{Output: "No issues found."}
+
+	var out bytes.Buffer
+	run := runBench(context.Background(), &out, a, cases, time.Minute)
+	if run.Agent != "test" || run.Cases != 2 || run.Expected != 1 || run.Errors != 0 || len(run.Results) != 2 {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	if run.Detected != 0 || run.FalseAlarms != 0 {
+		t.Errorf("agent passes everything: detected %d, false alarms %d", run.Detected, run.FalseAlarms)
+	}
+	for _, want := range []string{"[test 1/2] buggy... 0/1 found", "[test 2/2] clean... clean, passed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
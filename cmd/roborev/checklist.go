@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func checklistCmd() *cobra.Command {
+	var (
+		repoPath string
+		since    string
+		jobID    int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "checklist",
+		Short: "Show reviewer checklist results",
+		Long: `Show how reviews answered the reviewer checklist.
+
+Set the checklist in .roborev.toml or ~/.roborev/config.toml; a repository's
+list replaces the global one:
+
+  checklist = [
+    "Are new code paths covered by tests?",
+    "Are user-facing changes documented?",
+  ]
+
+Every review of a commit, range, patch or uncommitted changes then answers
+each item with pass, fail or n/a. Items a review skipped are unanswered.
+
+Without --job, shows per-item totals for the repository's reviews.
+
+Examples:
+  roborev checklist
+  roborev checklist --since 7d
+  roborev checklist --job 42`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jobID > 0 {
+				if err := ensureDaemon(); err != nil {
+					return fmt.Errorf("daemon not running: %w", err)
+				}
+				results, err := getJobChecklist(getDaemonAddr(), jobID)
+				if err != nil {
+					return err
+				}
+				printJobChecklist(cmd.OutOrStdout(), jobID, results)
+				return nil
+			}
+
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			resp, err := getChecklistSummary(getDaemonAddr(), root, sinceTime)
+			if err != nil {
+				return err
+			}
+			printChecklistSummary(cmd.OutOrStdout(), resp)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&since, "since", "30d", "period to summarize, e.g. 7d, 2w, 36h or 2026-01-31")
+	cmd.Flags().Int64Var(&jobID, "job", 0, "show the answers of one review job")
+
+	return cmd
+}
+
+func getChecklist(addr string, params url.Values, out any) (bool, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(addr + "/api/checklist?" + params.Encode())
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode checklist: %w", err)
+	}
+	return true, nil
+}
+
+func getJobChecklist(addr string, jobID int64) ([]storage.ChecklistResult, error) {
+	var result struct {
+		Results []storage.ChecklistResult `json:"results"`
+	}
+	_, err := getChecklist(addr, url.Values{"job_id": {strconv.FormatInt(jobID, 10)}}, &result)
+	return result.Results, err
+}
+
+func getChecklistSummary(addr, repo string, since time.Time) (daemon.ChecklistSummaryResponse, error) {
+	var result daemon.ChecklistSummaryResponse
+	found, err := getChecklist(addr, url.Values{"repo": {repo}, "since": {since.Format(time.RFC3339)}}, &result)
+	if err == nil && !found {
+		// Repo has never been reviewed
+		result = daemon.ChecklistSummaryResponse{Repo: repo, Since: since}
+	}
+	return result, err
+}
+
+func printJobChecklist(w io.Writer, jobID int64, results []storage.ChecklistResult) {
+	if len(results) == 0 {
+		fmt.Fprintf(w, "Job %d has no checklist results\n", jobID)
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tRESULT\tITEM\tNOTE")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", r.Index+1, strings.ToUpper(r.Result), r.Item, r.Note)
+	}
+	tw.Flush()
+}
+
+func printChecklistSummary(w io.Writer, resp daemon.ChecklistSummaryResponse) {
+	since := resp.Since.Local().Format("2006-01-02")
+	if len(resp.Items) == 0 {
+		fmt.Fprintf(w, "No checklist results since %s\n", since)
+		return
+	}
+
+	fmt.Fprintf(w, "Checklist results since %s\n\n", since)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PASS\tFAIL\tN/A\tUNANSWERED\tITEM")
+	for _, s := range resp.Items {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\n", s.Pass, s.Fail, s.NA, s.Unanswered, s.Item)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestGetChecklist(t *testing.T) {
+	since := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	var gotQuery string
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/checklist" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		if r.URL.Query().Get("job_id") != "" {
+			json.NewEncoder(w).Encode(map[string]any{"results": []storage.ChecklistResult{
+				{Index: 0, Item: "Tests added?", Result: storage.ChecklistFail, Note: "no parser test"},
+			}})
+			return
+		}
+		json.NewEncoder(w).Encode(daemon.ChecklistSummaryResponse{
+			Repo:  "/src/repo",
+			Since: since,
+			Items: []storage.ChecklistItemSummary{{Item: "Tests added?", Pass: 4, Fail: 2, Unanswered: 1}},
+		})
+	}))
+	defer cleanup()
+
+	t.Run("summary", func(t *testing.T) {
+		resp, err := getChecklistSummary(ts.URL, "/src/repo", since)
+		if err != nil {
+			t.Fatalf("getChecklistSummary: %v", err)
+		}
+		for _, want := range []string{"repo=%2Fsrc%2Frepo", "since=2026-01-31T00%3A00%3A00Z"} {
+			if !strings.Contains(gotQuery, want) {
+				t.Errorf("expected %q in query %q", want, gotQuery)
+			}
+		}
+		var out bytes.Buffer
+		printChecklistSummary(&out, resp)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 4 || !strings.HasPrefix(lines[2], "PASS") {
+			t.Fatalf("unexpected output:\n%s", out.String())
+		}
+		if fields := strings.Fields(lines[3]); strings.Join(fields, " ") != "4 2 0 1 Tests added?" {
+			t.Errorf("unexpected row %q", lines[3])
+		}
+	})
+
+	t.Run("job", func(t *testing.T) {
+		results, err := getJobChecklist(ts.URL, 42)
+		if err != nil {
+			t.Fatalf("getJobChecklist: %v", err)
+		}
+		if gotQuery != "job_id=42" {
+			t.Errorf("unexpected query %q", gotQuery)
+		}
+		var out bytes.Buffer
+		printJobChecklist(&out, 42, results)
+		if !strings.Contains(out.String(), "FAIL") || !strings.Contains(out.String(), "no parser test") {
+			t.Errorf("unexpected output:\n%s", out.String())
+		}
+	})
+}
+
+func TestPrintChecklistEmpty(t *testing.T) {
+	var out bytes.Buffer
+	printChecklistSummary(&out, daemon.ChecklistSummaryResponse{Since: time.Now()})
+	if !strings.HasPrefix(out.String(), "No checklist results since ") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	out.Reset()
+	printJobChecklist(&out, 7, nil)
+	if out.String() != "Job 7 has no checklist results\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
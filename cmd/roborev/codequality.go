@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// codeQualityIssue is one issue in the Code Climate report format, which
+// GitLab reads as a Code Quality report artifact
+type codeQualityIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	EngineName  string              `json:"engine_name"`
+	Description string              `json:"description"`
+	Content     codeQualityContent  `json:"content"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityContent struct {
+	Body string `json:"body"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// codeQualitySeverities maps finding severities to Code Climate severities
+var codeQualitySeverities = map[string]string{
+	"critical": "critical",
+	"high":     "major",
+	"medium":   "minor",
+	"low":      "info",
+}
+
+// codeQualityIssues converts open findings into Code Climate issues located
+// at the first file the finding cites. Findings citing no file cannot be
+// placed and are counted in skipped. A finding repeated by later reviews is
+// reported once, from the newest review.
+func codeQualityIssues(items []storage.TriageItem, repoRoot string) (issues []codeQualityIssue, skipped int) {
+	index := make(map[string]int)
+	for _, item := range items {
+		refs := storage.ExtractFileRefs(item.Finding.Text, repoRoot)
+		if len(refs) == 0 {
+			skipped++
+			continue
+		}
+		ref := refs[0]
+		// Paths inside the repo are shown relative, as in the report's locations
+		text := item.Finding.Text
+		if repoRoot != "" {
+			text = strings.ReplaceAll(text, filepath.ToSlash(repoRoot)+"/", "")
+		}
+		sum := md5.Sum([]byte(ref.Path + "\n" + storage.FindingFingerprint(text)))
+		issue := codeQualityIssue{
+			Type:        "issue",
+			CheckName:   "roborev/" + item.Finding.Severity,
+			EngineName:  "roborev",
+			Description: strings.Join(strings.Fields(strings.ReplaceAll(text, "**", "")), " "),
+			Content:     codeQualityContent{Body: text},
+			Categories:  []string{"Bug Risk"},
+			Severity:    codeQualitySeverities[item.Finding.Severity],
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Location: codeQualityLocation{
+				Path:  ref.Path,
+				Lines: codeQualityLines{Begin: max(ref.StartLine, 1), End: ref.EndLine},
+			},
+		}
+		if i, ok := index[issue.Fingerprint]; ok {
+			issues[i] = issue
+			continue
+		}
+		index[issue.Fingerprint] = len(issues)
+		issues = append(issues, issue)
+	}
+	return issues, skipped
+}
+
+// writeCodeQualityReport writes issues as a Code Climate JSON array
+func writeCodeQualityReport(w io.Writer, issues []codeQualityIssue) error {
+	if issues == nil {
+		issues = []codeQualityIssue{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
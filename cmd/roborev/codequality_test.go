@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestCodeQualityIssues(t *testing.T) {
+	item := func(reviewID int64, severity, text string) storage.TriageItem {
+		return storage.TriageItem{ReviewID: reviewID, Finding: storage.Finding{Severity: severity, Text: text}}
+	}
+	items := []storage.TriageItem{
+		item(1, "high", "- **High**: nil deref in /repo/internal/db.go:42"),
+		item(1, "low", "- Low: naming is inconsistent"),
+		item(2, "medium", "- Medium: unchecked error in main.go:10-12"),
+		// The same finding reported again after the line moved
+		item(3, "high", "- **High**: nil deref in internal/db.go:45"),
+	}
+
+	issues, skipped := codeQualityIssues(items, "/repo")
+	if skipped != 1 {
+		t.Errorf("expected the finding without a file to be skipped, got %d", skipped)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", issues)
+	}
+
+	db := issues[0]
+	if db.Location.Path != "internal/db.go" || db.Location.Lines.Begin != 45 || db.Severity != "major" {
+		t.Errorf("expected the newest report of the db.go finding, got %+v", db)
+	}
+	if db.Description != "- High: nil deref in internal/db.go:45" || db.Type != "issue" || db.CheckName != "roborev/high" {
+		t.Errorf("unexpected issue fields %+v", db)
+	}
+	if len(db.Fingerprint) != 32 || db.Fingerprint == issues[1].Fingerprint {
+		t.Errorf("expected distinct md5 fingerprints, got %q and %q", db.Fingerprint, issues[1].Fingerprint)
+	}
+
+	main := issues[1]
+	if main.Location.Lines != (codeQualityLines{Begin: 10, End: 12}) || main.Severity != "minor" {
+		t.Errorf("unexpected main.go issue %+v", main)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// completionReviewLimit is how many recent reviews are offered as job IDs
+// and SHAs
+const completionReviewLimit = 50
+
+// registerCompletions teaches the shell completion scripts generated by
+// 'roborev completion <shell>' to complete job IDs, reviewed SHAs, repos
+// and agents, read from the database without starting the daemon
+func registerCompletions(root *cobra.Command) {
+	walkCommands(root, func(cmd *cobra.Command) {
+		if cmd.Flags().Lookup("agent") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("agent", completeAgents)
+		}
+		if f := cmd.Flags().Lookup("repo"); f != nil && f.Value.Type() == "string" {
+			_ = cmd.RegisterFlagCompletionFunc("repo", completeRepoPaths)
+		}
+
+		switch strings.TrimPrefix(cmd.CommandPath(), root.Name()+" ") {
+		case "show":
+			cmd.ValidArgsFunction = firstArg(completeReviews(true))
+		case "comment":
+			cmd.ValidArgsFunction = firstArg(completeReviews(true))
+		case "address", "share", "draft-reply":
+			cmd.ValidArgsFunction = firstArg(completeReviews(false))
+		case "fix":
+			cmd.ValidArgsFunction = completeReviews(false)
+		case "amend-message", "reconcile":
+			cmd.ValidArgsFunction = firstArg(completeReviewedSHAs)
+		case "repo show", "repo rename", "repo delete", "repo relocate":
+			cmd.ValidArgsFunction = firstArg(completeRepoNames)
+		case "repo merge":
+			cmd.ValidArgsFunction = completeRepoNames
+		}
+	})
+}
+
+func walkCommands(cmd *cobra.Command, fn func(*cobra.Command)) {
+	fn(cmd)
+	for _, sub := range cmd.Commands() {
+		walkCommands(sub, fn)
+	}
+}
+
+// firstArg limits a completion to a command's first argument
+func firstArg(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// withCompletionDB runs fn against the database opened read-only, so
+// completion never creates, migrates or locks it
+func withCompletionDB(fn func(db *storage.DB) []cobra.Completion) ([]cobra.Completion, cobra.ShellCompDirective) {
+	db, err := storage.OpenReadOnly(storage.DefaultDBPath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer db.Close()
+	return fn(db), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeReviews offers the job IDs of recent reviews, and their commit
+// SHAs too when withSHAs is set
+func completeReviews(withSHAs bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return withCompletionDB(func(db *storage.DB) []cobra.Completion {
+			reviews, err := db.ListRecentReviews(completionReviewLimit)
+			if err != nil {
+				return nil
+			}
+			var out []cobra.Completion
+			for _, rr := range reviews {
+				desc := reviewCompletionDesc(rr)
+				if id := strconv.FormatInt(rr.JobID, 10); strings.HasPrefix(id, toComplete) {
+					out = append(out, cobra.CompletionWithDesc(id, desc))
+				}
+				if withSHAs && rr.CommitSHA != "" && strings.HasPrefix(rr.CommitSHA, toComplete) {
+					out = append(out, cobra.CompletionWithDesc(shortSHA(rr.CommitSHA), desc))
+				}
+			}
+			return out
+		})
+	}
+}
+
+// completeReviewedSHAs offers the commit SHAs of recent reviews
+func completeReviewedSHAs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return withCompletionDB(func(db *storage.DB) []cobra.Completion {
+		reviews, err := db.ListRecentReviews(completionReviewLimit)
+		if err != nil {
+			return nil
+		}
+		var out []cobra.Completion
+		seen := make(map[string]bool)
+		for _, rr := range reviews {
+			if rr.CommitSHA == "" || seen[rr.CommitSHA] || !strings.HasPrefix(rr.CommitSHA, toComplete) {
+				continue
+			}
+			seen[rr.CommitSHA] = true
+			out = append(out, cobra.CompletionWithDesc(shortSHA(rr.CommitSHA), reviewCompletionDesc(rr)))
+		}
+		return out
+	})
+}
+
+func reviewCompletionDesc(rr storage.RecentReview) string {
+	desc := fmt.Sprintf("%s %s", rr.RepoName, shortRef(rr.GitRef))
+	if rr.Subject != "" {
+		desc += ": " + rr.Subject
+	}
+	return desc
+}
+
+// completeRepoNames offers the names of registered repos
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return withCompletionDB(func(db *storage.DB) []cobra.Completion {
+		repos, err := db.ListRepos(context.Background())
+		if err != nil {
+			return nil
+		}
+		var out []cobra.Completion
+		for _, r := range repos {
+			if strings.HasPrefix(r.Name, toComplete) {
+				out = append(out, cobra.CompletionWithDesc(r.Name, r.RootPath))
+			}
+		}
+		return out
+	})
+}
+
+// completeRepoPaths offers the paths of registered repos, and falls back to
+// directories when none match
+func completeRepoPaths(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	out, _ := withCompletionDB(func(db *storage.DB) []cobra.Completion {
+		repos, err := db.ListRepos(context.Background())
+		if err != nil {
+			return nil
+		}
+		var out []cobra.Completion
+		for _, r := range repos {
+			if strings.HasPrefix(r.RootPath, toComplete) {
+				out = append(out, cobra.CompletionWithDesc(r.RootPath, r.Name))
+			}
+		}
+		return out
+	})
+	if len(out) == 0 {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAgents offers the names of the supported agents
+func completeAgents(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	names := agent.Available()
+	sort.Strings(names)
+	var out []cobra.Completion
+	for _, name := range names {
+		if name != "test" && strings.HasPrefix(name, toComplete) {
+			out = append(out, name)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
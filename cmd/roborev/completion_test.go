@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// runCompletion returns the candidates cobra's completion scripts would
+// receive for args
+func runCompletion(t *testing.T, args ...string) []string {
+	t.Helper()
+	root := &cobra.Command{Use: "roborev"}
+	root.AddCommand(showCmd(), fixCmd(), repoCmd(), reviewCmd())
+	registerCompletions(root)
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("complete %v: %v", args, err)
+	}
+	var candidates []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		value, _, _ := strings.Cut(line, "\t")
+		candidates = append(candidates, value)
+	}
+	return candidates
+}
+
+func TestCompletionDynamicValues(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+
+	// Without a database completion offers nothing rather than failing
+	if got := runCompletion(t, "show", ""); len(got) != 0 {
+		t.Errorf("expected no candidates without a database, got %v", got)
+	}
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repoPath := t.TempDir()
+	repo, _ := db.GetOrCreateRepo(t.Context(), repoPath)
+	sha := "c0ffee1234567890"
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "A", "Add widget", time.Now())
+	job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"})
+	db.ClaimJob(t.Context(), "w")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	id := strconv.FormatInt(job.ID, 10)
+	if got := runCompletion(t, "show", ""); !contains(got, id) || !contains(got, shortSHA(sha)) {
+		t.Errorf("show candidates = %v, want job %s and %s", got, id, shortSHA(sha))
+	}
+	if got := runCompletion(t, "show", "c0f"); len(got) != 1 || got[0] != shortSHA(sha) {
+		t.Errorf("show c0f candidates = %v, want only the SHA", got)
+	}
+	if got := runCompletion(t, "fix", id, ""); !contains(got, id) || contains(got, shortSHA(sha)) {
+		t.Errorf("fix candidates = %v, want job IDs only", got)
+	}
+	if got := runCompletion(t, "repo", "show", ""); !contains(got, repo.Name) {
+		t.Errorf("repo show candidates = %v, want %s", got, repo.Name)
+	}
+	if got := runCompletion(t, "review", "--repo", ""); !contains(got, repo.RootPath) {
+		t.Errorf("--repo candidates = %v, want %s", got, repo.RootPath)
+	}
+	if got := runCompletion(t, "review", "--agent", "cod"); !contains(got, "codex") || contains(got, "test") {
+		t.Errorf("--agent candidates = %v, want codex", got)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// contextDocFetchTimeout bounds fetching a design doc given by URL
+const contextDocFetchTimeout = 30 * time.Second
+
+// loadContextDocs reads the design docs given with --context: http(s) URLs,
+// fetched now, or local files. Each is cut to storage.MaxContextDocSize.
+func loadContextDocs(sources []string) ([]storage.ContextDoc, error) {
+	var docs []storage.ContextDoc
+	for _, source := range sources {
+		var content []byte
+		var err error
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			content, err = fetchContextDoc(source)
+		} else {
+			content, err = readContextDoc(source)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("--context %s: %w", source, err)
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			return nil, fmt.Errorf("--context %s: not a text document", source)
+		}
+		doc := storage.ContextDoc{Source: source, Content: string(content)}
+		if len(content) > storage.MaxContextDocSize {
+			doc.Content = strings.ToValidUTF8(doc.Content[:storage.MaxContextDocSize], "")
+			doc.Truncated = true
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// readContextDoc reads up to one byte past storage.MaxContextDocSize of a
+// local file, so a longer file shows as truncated
+func readContextDoc(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, storage.MaxContextDocSize+1))
+}
+
+// fetchContextDoc downloads up to one byte past storage.MaxContextDocSize
+// of a document
+func fetchContextDoc(url string) ([]byte, error) {
+	client := &http.Client{Timeout: contextDocFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, storage.MaxContextDocSize+1))
+}
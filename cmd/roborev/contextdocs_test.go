@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestLoadContextDocs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rfc":
+			w.Write([]byte("The RFC."))
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", storage.MaxContextDocSize+10)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	design := filepath.Join(dir, "design.md")
+	if err := os.WriteFile(design, []byte("# Design\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := loadContextDocs([]string{design, ts.URL + "/rfc", ts.URL + "/big"})
+	if err != nil {
+		t.Fatalf("loadContextDocs: %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("got %d docs, want 3", len(docs))
+	}
+	if docs[0].Source != design || docs[0].Content != "# Design\n" {
+		t.Errorf("file doc = %+v", docs[0])
+	}
+	if docs[1].Content != "The RFC." || docs[1].Truncated {
+		t.Errorf("URL doc = %+v", docs[1])
+	}
+	if len(docs[2].Content) != storage.MaxContextDocSize || !docs[2].Truncated {
+		t.Errorf("large doc: %d bytes, truncated %v", len(docs[2].Content), docs[2].Truncated)
+	}
+
+	binary := filepath.Join(dir, "image.png")
+	os.WriteFile(binary, []byte{0x89, 'P', 'N', 'G', 0}, 0644)
+	for _, source := range []string{filepath.Join(dir, "missing.md"), ts.URL + "/missing", binary} {
+		if _, err := loadContextDocs([]string{source}); err == nil {
+			t.Errorf("expected error for %s", source)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/update"
+	"github.com/roborev-dev/roborev/internal/version"
+)
+
+// daemonAction is what ensureDaemon does with a daemon that is already running
+type daemonAction int
+
+const (
+	daemonUse     daemonAction = iota // Compatible, keep using it
+	daemonRestart                     // Older or unreadable, replace it with this binary
+)
+
+// negotiateDaemon compares a running daemon's /api/status with this CLI.
+// Older daemons (and ones that don't report versions) are restarted, as
+// before. A daemon newer than the CLI is never downgraded: it is used if it
+// still serves this CLI's API revision, otherwise the CLI has to be updated.
+func negotiateDaemon(status storage.DaemonStatus) (daemonAction, error) {
+	if status.Version == "" {
+		return daemonRestart, nil
+	}
+	if status.Version == version.Version {
+		return daemonUse, nil
+	}
+	if status.APIVersion > daemon.APIVersion || status.SchemaVersion > storage.SchemaVersion {
+		if daemon.APIVersion < status.MinAPIVersion {
+			return daemonUse, fmt.Errorf("daemon %s requires a newer roborev CLI (API v%d, this CLI %s speaks v%d); run 'roborev update'",
+				status.Version, status.MinAPIVersion, version.Version, daemon.APIVersion)
+		}
+		return daemonUse, nil
+	}
+	return daemonRestart, nil
+}
+
+// daemonStartError explains why a freshly started daemon never came up. The
+// usual cause is a database migrated by a newer roborev, which the daemon
+// refuses to write to.
+func daemonStartError() error {
+	db, err := storage.OpenReadOnly(storage.DefaultDBPath())
+	if err != nil {
+		return fmt.Errorf("daemon failed to start")
+	}
+	defer db.Close()
+	if err := db.CheckSchemaCompatible(); errors.Is(err, storage.ErrSchemaTooNew) {
+		return fmt.Errorf("daemon failed to start: %w; run 'roborev update'", err)
+	}
+	return fmt.Errorf("daemon failed to start")
+}
+
+// upgradeDaemon drains the running daemon, updates the roborev binary and
+// starts the new daemon, which migrates the database when it opens it.
+// Queued jobs are kept and picked up by the new daemon.
+func upgradeDaemon(w io.Writer, drainTimeout time.Duration) error {
+	return swapDaemonBinary(w, drainTimeout, true, func() error { return updateBinary(w) })
+}
+
+// swapDaemonBinary runs install with the daemon drained, then restarts the
+// daemon from the (possibly replaced) binary. If install fails the drain is
+// undone and the current daemon keeps running. A daemon that wasn't running
+// is only started when start is set.
+func swapDaemonBinary(w io.Writer, drainTimeout time.Duration, start bool, install func() error) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("find executable: %w", err)
+	}
+	// The update replaces the file behind the symlink; start that one
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	running := true
+	if _, err := daemon.GetAnyRunningDaemon(); err != nil {
+		running = false
+	}
+	addr := getDaemonAddr()
+	if running {
+		if err := drainQueue(w, addr, drainTimeout, time.Second); err != nil {
+			if errors.Is(err, errDrainTimeout) {
+				resumeQueue(addr)
+				return fmt.Errorf("%w; queue resumed, retry with a longer --drain-timeout", err)
+			}
+			return err
+		}
+	}
+
+	if err := install(); err != nil {
+		if running {
+			resumeQueue(addr)
+			fmt.Fprintln(w, "Queue resumed on the current daemon")
+		}
+		return err
+	}
+	if !running && !start {
+		return nil
+	}
+
+	fmt.Fprint(w, "Restarting daemon... ")
+	if running {
+		_ = stopDaemon()
+		killAllDaemons()
+	}
+	if err := startDaemonExe(exe); err != nil {
+		fmt.Fprintln(w, "failed")
+		return err
+	}
+	fmt.Fprintln(w, "OK")
+
+	status, err := getQueueStatus(getDaemonAddr())
+	if err != nil {
+		return fmt.Errorf("check new daemon: %w", err)
+	}
+	if status.DBSchemaVersion == 0 {
+		// Daemons on a shared database have no local schema to report
+		fmt.Fprintf(w, "Daemon %s running (shared database, %d queued)\n", status.Version, status.QueuedJobs)
+		return nil
+	}
+	if status.DBSchemaVersion < status.SchemaVersion {
+		return fmt.Errorf("daemon %s did not migrate the database (schema v%d, expected v%d)",
+			status.Version, status.DBSchemaVersion, status.SchemaVersion)
+	}
+	fmt.Fprintf(w, "Daemon %s running (database schema v%d, %d queued)\n",
+		status.Version, status.DBSchemaVersion, status.QueuedJobs)
+	return nil
+}
+
+// updateBinary installs the latest release over the running binary. Dev
+// builds are left alone so a local build isn't replaced by surprise.
+func updateBinary(w io.Writer) error {
+	info, err := update.CheckForUpdate(true)
+	if err != nil {
+		return fmt.Errorf("check for updates: %w", err)
+	}
+	if info == nil {
+		fmt.Fprintf(w, "Already running latest version (%s)\n", version.Version)
+		return nil
+	}
+	if info.IsDevBuild {
+		fmt.Fprintf(w, "Dev build %s not replaced (use 'roborev self-update --force' to install %s)\n",
+			info.CurrentVersion, info.LatestVersion)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Updating %s -> %s\n", info.CurrentVersion, info.LatestVersion)
+	if err := update.PerformUpdate(info, nil); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	return nil
+}
+
+// resumeQueue undoes a drain on a best-effort basis
+func resumeQueue(addr string) {
+	_, _ = setQueueDraining(addr, false)
+}
+
+// startDaemonExe is startDaemon with an explicit binary
+func startDaemonExe(exe string) error {
+	cmd := exec.Command(exe, "daemon", "run")
+	cmd.Env = filterGitEnv(os.Environ())
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	return waitForDaemonStart()
+}
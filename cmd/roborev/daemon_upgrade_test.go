@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/version"
+)
+
+func TestNegotiateDaemon(t *testing.T) {
+	current := storage.DaemonStatus{
+		APIVersion:    daemon.APIVersion,
+		MinAPIVersion: daemon.MinAPIVersion,
+		SchemaVersion: storage.SchemaVersion,
+	}
+	with := func(f func(*storage.DaemonStatus)) storage.DaemonStatus {
+		s := current
+		f(&s)
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		status  storage.DaemonStatus
+		want    daemonAction
+		wantErr bool
+	}{
+		{"unreadable version", storage.DaemonStatus{}, daemonRestart, false},
+		{"same version", with(func(s *storage.DaemonStatus) { s.Version = version.Version }), daemonUse, false},
+		{"older daemon without versions", storage.DaemonStatus{Version: "v0.1.0"}, daemonRestart, false},
+		{"other build, same API", with(func(s *storage.DaemonStatus) { s.Version = "v0.0.0-other" }), daemonRestart, false},
+		{"newer API still serving this CLI", with(func(s *storage.DaemonStatus) {
+			s.Version = "v99.0.0"
+			s.APIVersion = daemon.APIVersion + 1
+		}), daemonUse, false},
+		{"newer schema", with(func(s *storage.DaemonStatus) {
+			s.Version = "v99.0.0"
+			s.SchemaVersion = storage.SchemaVersion + 1
+		}), daemonUse, false},
+		{"newer API dropping this CLI", with(func(s *storage.DaemonStatus) {
+			s.Version = "v99.0.0"
+			s.APIVersion = daemon.APIVersion + 1
+			s.MinAPIVersion = daemon.APIVersion + 1
+		}), daemonUse, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := negotiateDaemon(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("negotiateDaemon() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("negotiateDaemon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
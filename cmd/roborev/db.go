@@ -0,0 +1,477 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/backup"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Back up, restore, analyze, check and prune the review database",
+		Long: `Back up, restore, analyze, check and prune the review database.
+
+The daemon can also back up the database on a schedule. Configure it in
+~/.roborev/config.toml:
+
+  [backup]
+  enabled = true
+  dir = "/mnt/backups/roborev"    # default: ~/.roborev/artifacts/backups
+  interval = "24h"                # default: 24h
+  keep = 7                        # backups to retain (default: 7)
+  compress = true                 # gzip backups
+  key_file = "/etc/roborev/key"   # encrypt with a key derived from this file
+
+It also deletes old reviews hourly under a retention policy:
+
+  [retention]
+  max_age_days = 90               # delete jobs finished more than 90 days ago
+  keep_per_repo = 1000            # and all but the newest 1000 jobs per repo`,
+	}
+	cmd.AddCommand(dbBackupCmd())
+	cmd.AddCommand(dbRestoreCmd())
+	cmd.AddCommand(dbAnalyzeCmd())
+	cmd.AddCommand(dbPruneCmd())
+	cmd.AddCommand(dbFsckCmd())
+	return cmd
+}
+
+func dbBackupCmd() *cobra.Command {
+	var (
+		dir      string
+		keep     int
+		compress bool
+		keyFile  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup [file]",
+		Short: "Write a backup of the review database now",
+		Long: `Write a backup of the review database using SQLite's online backup API.
+It is safe to run while the daemon is running.
+
+With a file, a plain snapshot is written there, replacing any file at that
+path once it is complete. Otherwise the backup goes to the backup directory
+like scheduled backups, and flags override the [backup] settings in the
+global config.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				for _, flag := range []string{"dir", "keep", "compress", "key-file"} {
+					if cmd.Flags().Changed(flag) {
+						return fmt.Errorf("--%s applies to the backup directory, not a snapshot file", flag)
+					}
+				}
+				db, err := storage.Open(storage.DefaultDBPath())
+				if err != nil {
+					return fmt.Errorf("open database: %w", err)
+				}
+				defer db.Close()
+				if err := backup.Snapshot(cmd.Context(), db, args[0]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Backed up database to %s\n", args[0])
+				return nil
+			}
+
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			bcfg := cfg.Backup
+			if dir != "" {
+				bcfg.Dir = dir
+			}
+			if cmd.Flags().Changed("keep") {
+				bcfg.Keep = keep
+			}
+			if cmd.Flags().Changed("compress") {
+				bcfg.Compress = compress
+			}
+			if keyFile != "" {
+				bcfg.KeyFile = keyFile
+			}
+			opts, err := backup.OptionsFromConfig(bcfg)
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			file, err := backup.Create(cmd.Context(), db, opts)
+			if file != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Backed up database to %s\n", file)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "directory to write the backup to")
+	cmd.Flags().IntVar(&keep, "keep", 0, "backups to retain in the directory (default: config or 7)")
+	cmd.Flags().BoolVar(&compress, "compress", false, "gzip the backup")
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "encrypt the backup with a key derived from this file")
+	return cmd
+}
+
+// backupCmd is 'roborev db backup' at the top level, for snapshots taken
+// by hand or from cron
+func backupCmd() *cobra.Command {
+	cmd := dbBackupCmd()
+	cmd.Short = "Write a backup of the review database now (same as 'db backup')"
+	return cmd
+}
+
+func dbRestoreCmd() *cobra.Command {
+	var keyFile string
+
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Replace the review database with a backup",
+		Long: `Replace the review database with a backup written by 'roborev db backup'
+or the daemon's scheduled backups. A bare file name is looked up in the
+backup directory. The daemon must be stopped first.
+
+The current database is kept next to it as reviews.db.pre-restore.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if info, err := daemon.GetAnyRunningDaemon(); err == nil {
+				return fmt.Errorf("daemon is running (pid %d); stop it first with 'roborev daemon stop'", info.PID)
+			}
+
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			file := args[0]
+			if _, err := os.Stat(file); os.IsNotExist(err) && filepath.Base(file) == file {
+				file = filepath.Join(cfg.Backup.BackupDir(), file)
+			}
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("backup: %w", err)
+			}
+
+			if keyFile == "" {
+				keyFile = cfg.Backup.KeyFile
+			}
+			var key []byte
+			if keyFile != "" {
+				if key, err = backup.LoadKey(keyFile); err != nil {
+					return err
+				}
+			}
+
+			dbPath := storage.DefaultDBPath()
+			if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+				return err
+			}
+			if _, err := os.Stat(dbPath); err == nil {
+				db, err := storage.Open(dbPath)
+				if err != nil {
+					return fmt.Errorf("open database: %w", err)
+				}
+				err = db.BackupTo(cmd.Context(), dbPath+".pre-restore")
+				db.Close()
+				if err != nil {
+					return fmt.Errorf("save current database: %w", err)
+				}
+			}
+
+			if err := backup.Restore(dbPath, file, key); err != nil {
+				return fmt.Errorf("restore %s: %w", file, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored database from %s\n", file)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "key file for encrypted backups (default: [backup] key_file)")
+	return cmd
+}
+
+func dbAnalyzeCmd() *cobra.Command {
+	var (
+		updateStats bool
+		showPlans   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Check the query plans of frequent queries and suggest indexes",
+		Long: `Explain the queries the daemon and TUI run most often against the review
+database and report any that read a whole table, which slows roborev down
+as the database grows. For each, suggest the index to create, or refreshing
+the planner's statistics with ANALYZE when the index exists but is unused.
+
+Nothing is changed unless --update-stats is given.
+
+Examples:
+  roborev db analyze
+  roborev db analyze --update-stats --plans`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			if updateStats {
+				if _, err := db.Exec(`ANALYZE`); err != nil {
+					return fmt.Errorf("update statistics: %w", err)
+				}
+			}
+			reports, err := db.CheckHotQueries(cmd.Context())
+			if err != nil {
+				return err
+			}
+			advice, err := db.AdviseIndexes(cmd.Context(), reports)
+			if err != nil {
+				return err
+			}
+			printQueryPlanReports(cmd.OutOrStdout(), reports, advice, showPlans)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&updateStats, "update-stats", false, "refresh the planner's statistics (ANALYZE) before checking")
+	cmd.Flags().BoolVar(&showPlans, "plans", false, "show each query's full plan")
+	return cmd
+}
+
+func dbPruneCmd() *cobra.Command {
+	var (
+		maxAgeDays  int
+		keepPerRepo int
+		dryRun      bool
+		vacuum      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old jobs and their reviews now",
+		Long: `Delete finished jobs past the retention policy, with their reviews and
+comments. Queued and running jobs are never deleted. The daemon applies the
+[retention] policy hourly; this applies it at once.
+
+Deleted rows are kept in the trash for the trash_retention window (default
+30 days); 'roborev undo <operation-id>' restores them.
+
+The database file keeps its size, reusing the freed space for new reviews;
+--vacuum rebuilds it to return the space to the disk, which needs free
+space of about the database's size. Trashed rows keep their space until
+the trash expires.
+
+Flags override the [retention] settings in the global config.
+
+Examples:
+  roborev db prune --max-age-days 90 --dry-run
+  roborev db prune --keep-per-repo 500 --vacuum`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			rcfg := cfg.Retention
+			if cmd.Flags().Changed("max-age-days") {
+				rcfg.MaxAgeDays = maxAgeDays
+			}
+			if cmd.Flags().Changed("keep-per-repo") {
+				rcfg.KeepPerRepo = keepPerRepo
+			}
+			if !rcfg.Enabled() && !vacuum {
+				return fmt.Errorf("no retention policy: set [retention] in the config or pass --max-age-days or --keep-per-repo")
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			w := cmd.OutOrStdout()
+			if rcfg.Enabled() {
+				opts := storage.PruneOptions{KeepPerRepo: rcfg.KeepPerRepo, DryRun: dryRun, Trash: cfg.TrashRetentionDuration()}
+				if maxAge := rcfg.MaxAge(); maxAge > 0 {
+					opts.Before = time.Now().Add(-maxAge)
+				}
+				result, err := db.Prune(cmd.Context(), opts)
+				if err != nil {
+					return fmt.Errorf("prune: %w", err)
+				}
+				if dryRun {
+					fmt.Fprintf(w, "Would delete %d job(s) (%d rows)\n", result.Jobs, result.Rows)
+					return nil
+				}
+				fmt.Fprintf(w, "Deleted %d job(s) (%d rows)\n", result.Jobs, result.Rows)
+				if op := result.Trash; op != nil {
+					fmt.Fprintf(w, "Undo with 'roborev undo %s' until %s\n", op.ID, op.ExpiresAt.Local().Format("2006-01-02"))
+				}
+			}
+			if vacuum && !dryRun {
+				if err := db.Vacuum(cmd.Context()); err != nil {
+					return fmt.Errorf("vacuum: %w", err)
+				}
+				fmt.Fprintln(w, "Vacuumed the database")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&maxAgeDays, "max-age-days", 0, "delete jobs finished more than this many days ago")
+	cmd.Flags().IntVar(&keepPerRepo, "keep-per-repo", 0, "delete all but this many newest jobs per repo")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only count the jobs that would be deleted")
+	cmd.Flags().BoolVar(&vacuum, "vacuum", false, "rebuild the database file afterwards to reclaim disk space")
+	return cmd
+}
+
+func dbFsckCmd() *cobra.Command {
+	var (
+		repair bool
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Find rows referencing deleted jobs, reviews, commits or repos",
+		Long: `Check every reference between the database's tables and report the rows
+left orphaned by databases written before roborev enforced them: reviews
+without jobs, jobs of missing commits or repos, comments on deleted jobs,
+and so on.
+
+With --repair, a job whose commit row is gone keeps its review and loses
+only the link; every other orphaned row is deleted, along with the rows
+that depend on it. Nothing is changed without --repair.
+
+Examples:
+  roborev db fsck
+  roborev db fsck --repair`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			problems, err := db.Fsck(cmd.Context(), repair)
+			if err != nil {
+				return fmt.Errorf("fsck: %w", err)
+			}
+			w := cmd.OutOrStdout()
+			if asJSON {
+				if problems == nil {
+					problems = []storage.IntegrityProblem{}
+				}
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(problems)
+			}
+			printIntegrityProblems(w, problems, repair)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&repair, "repair", false, "delete or unlink the orphaned rows")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output the problems as JSON")
+	return cmd
+}
+
+// printIntegrityProblems lists the orphaned rows fsck found and what
+// repairing them does, or did
+func printIntegrityProblems(w io.Writer, problems []storage.IntegrityProblem, repaired bool) {
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROW\tPROBLEM\tREPAIR")
+	for _, p := range problems {
+		fmt.Fprintf(tw, "%s\t%d\t%s %d not in %s\t%s\n", p.Table, p.RowID, p.Column, p.Ref, p.Parent, p.Repair)
+	}
+	tw.Flush()
+	if repaired {
+		fmt.Fprintf(w, "\nRepaired %d problem(s).\n", len(problems))
+		return
+	}
+	fmt.Fprintf(w, "\nFound %d problem(s); run 'roborev db fsck --repair' to repair them.\n", len(problems))
+}
+
+// printQueryPlanReports prints whether each hot query scans a table in
+// full, followed by the suggested fixes
+func printQueryPlanReports(w io.Writer, reports []storage.QueryPlanReport, advice []storage.IndexAdvice, showPlans bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUERY\tSOURCE\tPLAN")
+	for _, r := range reports {
+		status := "ok"
+		if len(r.Scans) > 0 {
+			status = "scans " + strings.Join(r.Scans, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Query.Name, r.Query.Source, status)
+		if showPlans {
+			for _, step := range r.Plan {
+				fmt.Fprintf(tw, "\t\t  %s\n", step.Detail)
+			}
+		}
+	}
+	tw.Flush()
+
+	if len(advice) == 0 {
+		fmt.Fprintf(w, "\nAll %d queries use indexes.\n", len(reports))
+		return
+	}
+	fmt.Fprintln(w, "\nSuggestions:")
+	for _, a := range advice {
+		fmt.Fprintf(w, "  %s: %s\n", a.Query, a.Reason)
+		if a.Statement != "" {
+			fmt.Fprintf(w, "    %s;\n", a.Statement)
+		}
+	}
+}
+
+// openReviewStore opens the review database commands built on
+// storage.Storage read: the shared PostgreSQL database when [database]
+// postgres_url is set, otherwise the local one
+func openReviewStore(ctx context.Context) (storage.Storage, error) {
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	url := cfg.Database.PostgresURLExpanded()
+	if url == "" {
+		return db, nil
+	}
+
+	// The local database still identifies this machine
+	machineID, err := db.GetMachineID()
+	db.Close()
+	if err != nil {
+		return nil, fmt.Errorf("get machine ID: %w", err)
+	}
+	store, err := storage.OpenPgStore(ctx, url, machineID)
+	if err != nil {
+		return nil, fmt.Errorf("open shared database: %w", err)
+	}
+	return store, nil
+}
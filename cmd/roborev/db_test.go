@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestDBBackupAndRestore(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("ROBOREV_DATA_DIR", dataDir)
+	dbPath := storage.DefaultDBPath()
+
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "kept")); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := dbCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"backup", "--compress", "--key-file", keyFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("db backup: %v", err)
+	}
+	file := strings.TrimSpace(strings.TrimPrefix(out.String(), "Backed up database to "))
+	if filepath.Dir(file) != filepath.Join(dataDir, "artifacts", "backups") || !strings.HasSuffix(file, ".db.gz.enc") {
+		t.Fatalf("unexpected backup path %q", file)
+	}
+
+	db, err = storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "dropped")); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Encrypted backups need the key
+	cmd = dbCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"restore", filepath.Base(file)})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error restoring an encrypted backup without a key")
+	}
+
+	out.Reset()
+	cmd = dbCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"restore", filepath.Base(file), "--key-file", keyFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("db restore: %v", err)
+	}
+	if !strings.Contains(out.String(), "Restored database from") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	for path, want := range map[string]int{dbPath: 1, dbPath + ".pre-restore": 2} {
+		db, err := storage.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repos, err := db.ListRepos(t.Context())
+		db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(repos) != want {
+			t.Errorf("%s: expected %d repos, got %d", filepath.Base(path), want, len(repos))
+		}
+	}
+}
+
+func TestDBAnalyze(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+
+	var out bytes.Buffer
+	cmd := dbCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"analyze", "--update-stats"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("db analyze: %v", err)
+	}
+	if !strings.Contains(out.String(), "claim next job") || !strings.Contains(out.String(), "queries use indexes") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	printQueryPlanReports(&out, []storage.QueryPlanReport{{
+		Query: storage.HotQuery{Name: "comments on a commit", Source: "GetCommentsForCommit"},
+		Scans: []string{"responses"},
+	}}, []storage.IndexAdvice{{
+		Query:     "comments on a commit",
+		Table:     "responses",
+		Reason:    "index idx_responses_commit_id is missing",
+		Statement: "CREATE INDEX IF NOT EXISTS idx_responses_commit_id ON responses(commit_id)",
+	}}, false)
+	for _, want := range []string{"scans responses", "index idx_responses_commit_id is missing", "ON responses(commit_id);"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDBPrune(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sha := range []string{"aaa111", "bbb222", "ccc333"} {
+		testutil.CreateCompletedReview(t, db, repo.ID, sha, "codex", "No issues found.")
+	}
+	db.Close()
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := dbCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{"prune"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "no retention policy") {
+		t.Errorf("expected an error without a policy, got %v", err)
+	}
+	out, err := run("--keep-per-repo", "1", "--dry-run")
+	if err != nil {
+		t.Fatalf("db prune --dry-run: %v", err)
+	}
+	if !strings.Contains(out, "Would delete 2 job(s)") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	out, err = run("--keep-per-repo", "1", "--vacuum")
+	if err != nil {
+		t.Fatalf("db prune: %v", err)
+	}
+	if !strings.Contains(out, "Deleted 2 job(s)") || !strings.Contains(out, "Vacuumed") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	db, err = storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	jobs, err := db.ListJobs(t.Context(), "", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].GitRef != "ccc333" {
+		t.Errorf("expected only the newest job kept, got %d jobs", len(jobs))
+	}
+}
+
+func TestDBFsck(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "aaa111", "codex", "No issues found.")
+	// Orphan the review the way a database written without foreign keys could
+	conn, err := db.Conn(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{`PRAGMA foreign_keys = OFF`, `DELETE FROM review_jobs`, `PRAGMA foreign_keys = ON`} {
+		if _, err := conn.ExecContext(t.Context(), stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Close()
+	db.Close()
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := dbCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"fsck"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("db fsck %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	out := run()
+	if !strings.Contains(out, fmt.Sprintf("job_id %d not in review_jobs", job.ID)) || !strings.Contains(out, "Found 1 problem(s)") {
+		t.Errorf("unexpected check output:\n%s", out)
+	}
+	if out := run("--repair"); !strings.Contains(out, "Repaired 1 problem(s)") {
+		t.Errorf("unexpected repair output:\n%s", out)
+	}
+	if out := run(); !strings.Contains(out, "No problems found") {
+		t.Errorf("expected no problems after repair:\n%s", out)
+	}
+}
+
+func TestBackupSnapshotFile(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "repo")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The daemon keeps the database open while the snapshot is taken
+	snapshot := filepath.Join(t.TempDir(), "snapshot.db")
+	var out bytes.Buffer
+	cmd := backupCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{snapshot})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if !strings.Contains(out.String(), "Backed up database to "+snapshot) {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	copied, err := storage.Open(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err := copied.ListRepos(t.Context())
+	copied.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 {
+		t.Errorf("expected 1 repo in the snapshot, got %d", len(repos))
+	}
+
+	cmd = backupCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{snapshot, "--compress"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for --compress with a snapshot file")
+	}
+}
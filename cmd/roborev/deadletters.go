@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// maxDeadLetterErrorLen is the most of a dead letter's error shown in lists
+const maxDeadLetterErrorLen = 80
+
+func deadLettersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dead-letters",
+		Short: "List, inspect and requeue jobs that failed for good",
+		Long: `List, inspect and requeue jobs that failed for good.
+
+A job becomes a dead letter when its retries run out, or when it fails in
+a way retrying cannot fix, such as an agent that is not logged in. It stays
+failed in the queue, and the error of every attempt is kept, until it is
+requeued here or rerun.
+
+Examples:
+  roborev dead-letters list --code agent_auth
+  roborev dead-letters show 42
+  roborev dead-letters requeue 42 43
+  roborev dead-letters requeue --all --code agent_rate_limit`,
+	}
+
+	cmd.AddCommand(deadLettersListCmd())
+	cmd.AddCommand(deadLettersShowCmd())
+	cmd.AddCommand(deadLettersRequeueCmd())
+
+	return cmd
+}
+
+func deadLettersListCmd() *cobra.Command {
+	var (
+		repoPath string
+		code     string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the dead letters, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			params := url.Values{"limit": {strconv.Itoa(limit)}}
+			if repoPath != "" {
+				root, err := git.GetMainRepoRoot(repoPath)
+				if err != nil {
+					return fmt.Errorf("not a git repository: %w", err)
+				}
+				params.Set("repo", root)
+			}
+			if code != "" {
+				params.Set("error_code", code)
+			}
+			var resp daemon.DeadLettersResponse
+			if err := getDeadLetters(getDaemonAddr()+"/api/dead-letters", params, &resp); err != nil {
+				return err
+			}
+			printDeadLetters(cmd.OutOrStdout(), resp.DeadLetters)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "only dead letters of this repository")
+	cmd.Flags().StringVar(&code, "code", "", "only dead letters whose last error has this code")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum number of dead letters (0 for all)")
+	return cmd
+}
+
+func deadLettersShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <job-id>",
+		Short: "Show a dead letter with the error of every attempt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := strconv.ParseInt(args[0], 10, 64); err != nil {
+				return fmt.Errorf("invalid job ID %q", args[0])
+			}
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var letter storage.DeadLetter
+			if err := getDeadLetters(getDaemonAddr()+"/api/dead-letters/show", url.Values{"job_id": {args[0]}}, &letter); err != nil {
+				return err
+			}
+			printDeadLetter(cmd.OutOrStdout(), letter)
+			return nil
+		},
+	}
+}
+
+func deadLettersRequeueCmd() *cobra.Command {
+	var (
+		all      bool
+		repoPath string
+		code     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "requeue [job-id...]",
+		Short: "Queue dead letters to run again",
+		Long: `Queue dead letters to run again from scratch, with their retries reset.
+Name the jobs, or pass --all to requeue every dead letter, narrowed down
+with --repo and --code.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("give job IDs or --all")
+			}
+			if !all && (repoPath != "" || code != "") {
+				return fmt.Errorf("--repo and --code apply only with --all")
+			}
+			req := daemon.RequeueDeadLettersRequest{All: all, ErrorCode: storage.ErrorCode(code)}
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid job ID %q", arg)
+				}
+				req.JobIDs = append(req.JobIDs, id)
+			}
+			if repoPath != "" {
+				root, err := git.GetMainRepoRoot(repoPath)
+				if err != nil {
+					return fmt.Errorf("not a git repository: %w", err)
+				}
+				req.Repo = root
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			resp, err := requeueDeadLetters(getDaemonAddr(), req)
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if len(resp.Skipped) > 0 {
+				fmt.Fprintf(w, "Not dead letters, skipped: %v\n", resp.Skipped)
+			}
+			if len(resp.Requeued) == 0 {
+				fmt.Fprintln(w, "No dead letters requeued")
+				return nil
+			}
+			fmt.Fprintf(w, "Requeued %d job(s): %v\n", len(resp.Requeued), resp.Requeued)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "requeue every dead letter")
+	cmd.Flags().StringVar(&repoPath, "repo", "", "with --all, only dead letters of this repository")
+	cmd.Flags().StringVar(&code, "code", "", "with --all, only dead letters whose last error has this code")
+	return cmd
+}
+
+// getDeadLetters decodes the response of a daemon dead letters endpoint
+// into result
+func getDeadLetters(endpoint string, params url.Values, result any) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && params.Get("job_id") != "" {
+		return fmt.Errorf("job %s is not a dead letter", params.Get("job_id"))
+	}
+	return decodeDeadLetterResponse(resp, result)
+}
+
+func requeueDeadLetters(addr string, req daemon.RequeueDeadLettersRequest) (daemon.RequeueDeadLettersResponse, error) {
+	var result daemon.RequeueDeadLettersResponse
+	body, err := json.Marshal(req)
+	if err != nil {
+		return result, err
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(addr+"/api/dead-letters/requeue", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	err = decodeDeadLetterResponse(resp, &result)
+	return result, err
+}
+
+func decodeDeadLetterResponse(resp *http.Response, result any) error {
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func printDeadLetters(w io.Writer, letters []storage.DeadLetter) {
+	if len(letters) == 0 {
+		fmt.Fprintln(w, "No dead letters")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tREPO\tREF\tAGENT\tATTEMPTS\tCODE\tDIED\tERROR")
+	for _, d := range letters {
+		code := string(d.ErrorCode)
+		if code == "" {
+			code = "-"
+		}
+		errLine, _, _ := strings.Cut(d.Error, "\n")
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", d.JobID, d.RepoName, shortRef(d.GitRef), d.Agent,
+			d.Attempts, code, d.DeadAt.Local().Format("2006-01-02 15:04"), truncateString(errLine, maxDeadLetterErrorLen))
+	}
+	tw.Flush()
+}
+
+func printDeadLetter(w io.Writer, d storage.DeadLetter) {
+	fmt.Fprintf(w, "Job %d: %s %s (%s, %s)\n", d.JobID, d.RepoName, shortRef(d.GitRef), d.JobType, d.Agent)
+	fmt.Fprintf(w, "Dead since: %s\n", d.DeadAt.Local().Format("2006-01-02 15:04"))
+	for _, f := range d.Failures {
+		code := ""
+		if f.ErrorCode != "" {
+			code = " (" + string(f.ErrorCode) + ")"
+		}
+		fmt.Fprintf(w, "\nAttempt %d%s at %s:\n", f.Attempt, code, f.FailedAt.Local().Format("2006-01-02 15:04:05"))
+		for line := range strings.SplitSeq(strings.TrimRight(f.Error, "\n"), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	fmt.Fprintf(w, "\nRequeue with: roborev dead-letters requeue %d\n", d.JobID)
+}
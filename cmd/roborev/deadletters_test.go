@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestDeadLetterCommands(t *testing.T) {
+	letter := storage.DeadLetter{
+		JobID: 42, RepoName: "api", GitRef: "abc1234def", JobType: storage.JobTypeReview, Agent: "codex",
+		ErrorCode: storage.ErrorCodeAgentAuth, Error: "401 Unauthorized\nrun codex login", Attempts: 2, DeadAt: time.Now(),
+		Failures: []storage.JobFailure{
+			{Attempt: 1, ErrorCode: storage.ErrorCodeTimeout, Error: "timed out", FailedAt: time.Now()},
+			{Attempt: 2, ErrorCode: storage.ErrorCodeAgentAuth, Error: "401 Unauthorized\nrun codex login", FailedAt: time.Now()},
+		},
+	}
+	var requeued daemon.RequeueDeadLettersRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dead-letters":
+			writeJSON(w, daemon.DeadLettersResponse{DeadLetters: []storage.DeadLetter{letter}})
+		case "/api/dead-letters/show":
+			if r.URL.Query().Get("job_id") != "42" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeJSON(w, letter)
+		case "/api/dead-letters/requeue":
+			json.NewDecoder(r.Body).Decode(&requeued)
+			writeJSON(w, daemon.RequeueDeadLettersResponse{Requeued: []int64{42}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var list daemon.DeadLettersResponse
+	if err := getDeadLetters(ts.URL+"/api/dead-letters", url.Values{}, &list); err != nil {
+		t.Fatalf("getDeadLetters: %v", err)
+	}
+	var out bytes.Buffer
+	printDeadLetters(&out, list.DeadLetters)
+	for _, want := range []string{"42", "abc1234", "agent_auth", "401 Unauthorized"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "run codex login") {
+		t.Errorf("list shows more than the first line of the error:\n%s", out.String())
+	}
+
+	var got storage.DeadLetter
+	if err := getDeadLetters(ts.URL+"/api/dead-letters/show", url.Values{"job_id": {"42"}}, &got); err != nil {
+		t.Fatalf("getDeadLetters: %v", err)
+	}
+	out.Reset()
+	printDeadLetter(&out, got)
+	for _, want := range []string{"Attempt 1 (timeout)", "Attempt 2 (agent_auth)", "  run codex login", "requeue 42"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("show output missing %q:\n%s", want, out.String())
+		}
+	}
+	err := getDeadLetters(ts.URL+"/api/dead-letters/show", url.Values{"job_id": {"7"}}, &got)
+	if err == nil || !strings.Contains(err.Error(), "job 7 is not a dead letter") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	resp, err := requeueDeadLetters(ts.URL, daemon.RequeueDeadLettersRequest{JobIDs: []int64{42}})
+	if err != nil {
+		t.Fatalf("requeueDeadLetters: %v", err)
+	}
+	if !slices.Equal(requeued.JobIDs, []int64{42}) || !slices.Equal(resp.Requeued, []int64{42}) {
+		t.Errorf("unexpected requeue of %+v: %+v", requeued, resp)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func diffReviewsCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "diff-reviews <job-a> <job-b>",
+		Short: "Compare the findings and summaries of two reviews",
+		Long: `Compare two reviews, usually of the same commit, to see what a template
+change, an agent upgrade or a rerun changed in the outcome.
+
+Reviews are given by job ID. Findings of the second review are matched
+against the first: findings only in the second are added, findings only in
+the first are removed, and matched findings whose severity moved are
+changed. The verdicts, the summaries (the first paragraph of each review)
+and any differences in the environment the reviews ran in are shown too.
+
+Examples:
+  roborev diff-reviews 41 57
+  roborev diff-reviews 41 57 --json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var ids [2]int64
+			for i, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil || id <= 0 {
+					return fmt.Errorf("invalid job ID: %s", arg)
+				}
+				ids[i] = id
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var reviews [2]*storage.Review
+			for i, id := range ids {
+				review, err := fetchReview(context.Background(), getDaemonAddr(), id)
+				if err != nil {
+					return fmt.Errorf("fetch review of job %d: %w", id, err)
+				}
+				reviews[i] = review
+			}
+
+			diff := diffReviews(reviews[0], reviews[1])
+			if jsonOutput {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(diff)
+			}
+			printReviewDiff(cmd.OutOrStdout(), diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	return cmd
+}
+
+// reviewSide is what a review diff reports about each of its reviews
+type reviewSide struct {
+	JobID        int64  `json:"job_id"`
+	GitRef       string `json:"git_ref,omitempty"`
+	Agent        string `json:"agent"`
+	Model        string `json:"model,omitempty"`
+	AgentVersion string `json:"agent_version,omitempty"`
+	PromptHash   string `json:"prompt_hash,omitempty"`
+	Verdict      string `json:"verdict"` // "P" or "F"
+	Summary      string `json:"summary"`
+}
+
+// severityChange is a finding present in both reviews at different
+// severities
+type severityChange struct {
+	Before storage.Finding `json:"before"`
+	After  storage.Finding `json:"after"`
+}
+
+// reviewDiff is the difference between the findings and summaries of two
+// reviews
+type reviewDiff struct {
+	A         reviewSide        `json:"a"`
+	B         reviewSide        `json:"b"`
+	Added     []storage.Finding `json:"added"`
+	Removed   []storage.Finding `json:"removed"`
+	Changed   []severityChange  `json:"changed"`
+	Unchanged int               `json:"unchanged"`
+}
+
+// severityWordPattern matches the words that label a finding's severity,
+// which are left out when matching findings across severities
+var severityWordPattern = regexp.MustCompile(`(?i)\b(critical|high|medium|low|severity)\b`)
+
+// diffReviews compares the findings of review b with those of review a.
+// Findings are matched by their normalized text first, then by their text
+// without severity labels, so a finding whose severity moved is reported as
+// changed rather than as removed and added.
+func diffReviews(a, b *storage.Review) reviewDiff {
+	diff := reviewDiff{A: newReviewSide(a), B: newReviewSide(b)}
+	before := storage.ExtractFindings(a.Output)
+	after := storage.ExtractFindings(b.Output)
+	matchedBefore := make([]bool, len(before))
+	matchedAfter := make([]bool, len(after))
+
+	match := func(key func(storage.Finding) string, onMatch func(x, y storage.Finding)) {
+		unmatched := make(map[string][]int)
+		for i, f := range before {
+			if !matchedBefore[i] {
+				k := key(f)
+				unmatched[k] = append(unmatched[k], i)
+			}
+		}
+		for j, f := range after {
+			if matchedAfter[j] {
+				continue
+			}
+			k := key(f)
+			if len(unmatched[k]) == 0 {
+				continue
+			}
+			i := unmatched[k][0]
+			unmatched[k] = unmatched[k][1:]
+			matchedBefore[i], matchedAfter[j] = true, true
+			onMatch(before[i], f)
+		}
+	}
+	match(func(f storage.Finding) string {
+		return f.Severity + " " + storage.FindingFingerprint(f.Text)
+	}, func(x, y storage.Finding) { diff.Unchanged++ })
+	match(func(f storage.Finding) string {
+		return storage.FindingFingerprint(severityWordPattern.ReplaceAllString(f.Text, " "))
+	}, func(x, y storage.Finding) {
+		if x.Severity == y.Severity {
+			diff.Unchanged++
+			return
+		}
+		diff.Changed = append(diff.Changed, severityChange{Before: x, After: y})
+	})
+
+	for i, f := range before {
+		if !matchedBefore[i] {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	for j, f := range after {
+		if !matchedAfter[j] {
+			diff.Added = append(diff.Added, f)
+		}
+	}
+	return diff
+}
+
+func newReviewSide(r *storage.Review) reviewSide {
+	side := reviewSide{
+		JobID:   r.JobID,
+		Agent:   r.Agent,
+		Verdict: storage.ParseVerdict(r.Output),
+		Summary: reviewSummary(r.Output),
+	}
+	if r.Job != nil {
+		side.GitRef = r.Job.GitRef
+	}
+	if r.Env != nil {
+		side.Model = r.Env.Model
+		side.AgentVersion = r.Env.AgentVersion
+		side.PromptHash = r.Env.PromptHash
+	}
+	return side
+}
+
+// reviewSummary returns the first paragraph of a review's output, where
+// the review prompts ask for a summary of the change
+func reviewSummary(output string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.Join(lines, " ")
+}
+
+// printReviewDiff writes a review diff for people, in the style of a
+// unified diff: "-" for removed findings, "+" for added ones and "~" for
+// changed severities
+func printReviewDiff(w io.Writer, d reviewDiff) {
+	fmt.Fprintf(w, "a: job %d (%s)\n", d.A.JobID, d.A.Agent)
+	fmt.Fprintf(w, "b: job %d (%s)\n", d.B.JobID, d.B.Agent)
+	if d.A.GitRef != d.B.GitRef {
+		fmt.Fprintf(w, "Note: the reviews are of different refs (%s, %s)\n", shortRef(d.A.GitRef), shortRef(d.B.GitRef))
+	}
+	for _, f := range []struct{ name, a, b string }{
+		{"Model", d.A.Model, d.B.Model},
+		{"Agent version", d.A.AgentVersion, d.B.AgentVersion},
+		{"Prompt hash", d.A.PromptHash, d.B.PromptHash},
+	} {
+		if f.a != f.b {
+			fmt.Fprintf(w, "%s: %s -> %s\n", f.name, orUnknown(f.a), orUnknown(f.b))
+		}
+	}
+
+	if d.A.Verdict == d.B.Verdict {
+		fmt.Fprintf(w, "Verdict: %s (unchanged)\n", verdictLabel(d.A.Verdict))
+	} else {
+		fmt.Fprintf(w, "Verdict: %s -> %s\n", verdictLabel(d.A.Verdict), verdictLabel(d.B.Verdict))
+	}
+	if storage.FindingFingerprint(d.A.Summary) == storage.FindingFingerprint(d.B.Summary) {
+		fmt.Fprintln(w, "Summary: unchanged")
+	} else {
+		fmt.Fprintln(w, "Summary:")
+		fmt.Fprintf(w, "- %s\n", d.A.Summary)
+		fmt.Fprintf(w, "+ %s\n", d.B.Summary)
+	}
+
+	fmt.Fprintf(w, "Findings: %d unchanged, %d added, %d removed, %d changed severity\n",
+		d.Unchanged, len(d.Added), len(d.Removed), len(d.Changed))
+	for _, f := range d.Removed {
+		fmt.Fprintf(w, "- %s\n", findingLine(f))
+	}
+	for _, f := range d.Added {
+		fmt.Fprintf(w, "+ %s\n", findingLine(f))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ %s (was %s)\n", findingLine(c.After), c.Before.Severity)
+	}
+}
+
+// listMarkerPattern matches the list marker a finding's line starts with
+var listMarkerPattern = regexp.MustCompile(`^([-*•]|\d+[.)])\s+`)
+
+// findingLine returns the first line of a finding without its list marker
+func findingLine(f storage.Finding) string {
+	return listMarkerPattern.ReplaceAllString(firstLine(f.Text), "")
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "(unknown)"
+	}
+	return s
+}
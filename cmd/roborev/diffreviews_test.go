@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+const diffReviewBefore = `Adds a user lookup endpoint.
+
+- **High**: SQL injection in internal/db.go:42, the user ID is concatenated into the query.
+
+- **Low**: Missing doc comment on LookupUser.
+
+- **Medium**: The handler ignores the error from Close.`
+
+const diffReviewAfter = `Adds a user lookup endpoint backed by the database.
+
+- **Critical**: SQL injection in internal/db.go:42, the user ID is concatenated into the query.
+
+- **Medium**: The handler ignores the error from Close.
+
+- **Medium**: No test covers an unknown user.`
+
+func TestDiffReviews(t *testing.T) {
+	a := &storage.Review{JobID: 1, Agent: "codex", Output: diffReviewBefore,
+		Env: &storage.JobEnv{PromptHash: "aaa"}, Job: &storage.ReviewJob{GitRef: "abc123"}}
+	b := &storage.Review{JobID: 2, Agent: "codex", Output: diffReviewAfter,
+		Env: &storage.JobEnv{PromptHash: "bbb"}, Job: &storage.ReviewJob{GitRef: "abc123"}}
+
+	d := diffReviews(a, b)
+	if d.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged finding, got %d", d.Unchanged)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Before.Severity != "high" || d.Changed[0].After.Severity != "critical" {
+		t.Errorf("expected the SQL injection to move from high to critical, got %+v", d.Changed)
+	}
+	if len(d.Removed) != 1 || !strings.Contains(d.Removed[0].Text, "doc comment") {
+		t.Errorf("expected the doc comment finding to be removed, got %+v", d.Removed)
+	}
+	if len(d.Added) != 1 || !strings.Contains(d.Added[0].Text, "unknown user") {
+		t.Errorf("expected the test finding to be added, got %+v", d.Added)
+	}
+	if d.A.Summary != "Adds a user lookup endpoint." || d.A.Verdict != "F" {
+		t.Errorf("unexpected first side %+v", d.A)
+	}
+
+	var out bytes.Buffer
+	printReviewDiff(&out, d)
+	for _, want := range []string{
+		"Prompt hash: aaa -> bbb",
+		"Verdict: FAIL (unchanged)",
+		"+ Adds a user lookup endpoint backed by the database.",
+		"Findings: 1 unchanged, 1 added, 1 removed, 1 changed severity",
+		"- **Low**: Missing doc comment on LookupUser.",
+		"+ **Medium**: No test covers an unknown user.",
+		"~ **Critical**: SQL injection in internal/db.go:42",
+		"(was high)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "different refs") {
+		t.Errorf("reviews of the same ref should not be flagged, got:\n%s", out.String())
+	}
+}
+
+func TestDiffReviewsCmd(t *testing.T) {
+	outputs := map[string]string{"1": diffReviewBefore, "2": diffReviewAfter}
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/review" {
+			return
+		}
+		id := r.URL.Query().Get("job_id")
+		output, ok := outputs[id]
+		if !ok {
+			http.Error(w, `{"error":"review not found"}`, http.StatusNotFound)
+			return
+		}
+		var jobID int64
+		fmt.Sscanf(id, "%d", &jobID)
+		json.NewEncoder(w).Encode(storage.Review{JobID: jobID, Agent: "codex", Output: output})
+	}))
+	defer cleanup()
+
+	var stdout bytes.Buffer
+	cmd := diffReviewsCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"1", "2", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("diff-reviews failed: %v", err)
+	}
+	var d reviewDiff
+	if err := json.Unmarshal(stdout.Bytes(), &d); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	if d.A.JobID != 1 || d.B.JobID != 2 || len(d.Added) != 1 || len(d.Removed) != 1 || len(d.Changed) != 1 {
+		t.Errorf("unexpected diff %+v", d)
+	}
+
+	cmd = diffReviewsCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"1", "3"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "job 3") {
+		t.Errorf("expected a missing review to fail, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func doctorCmd() *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the roborev data directory for problems",
+		Long: `Check that the data directory (~/.roborev, or ROBOREV_DATA_DIR) matches
+the layout this roborev expects, that config.toml parses, and that the
+review database opens and is not from a newer roborev.
+
+The daemon upgrades older layouts when it starts. Use --fix to upgrade
+now; the daemon must be stopped first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			dir := config.DataDir()
+
+			if fix {
+				if info, err := daemon.GetAnyRunningDaemon(); err == nil {
+					return fmt.Errorf("daemon is running (pid %d); stop it first with 'roborev daemon stop'", info.PID)
+				}
+				moved, err := config.UpgradeLayout(dir)
+				for _, m := range moved {
+					fmt.Fprintf(out, "Moved %s\n", m)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			version, _ := config.ReadLayoutVersion(dir)
+			fmt.Fprintf(out, "Data directory: %s (layout v%d, current v%d)\n", dir, version, config.LayoutVersion)
+
+			var problems []string
+			for _, p := range config.CheckLayout(dir) {
+				problems = append(problems, fmt.Sprintf("%s: %s", p.Path, p.Problem))
+			}
+			if _, err := os.Stat(config.GlobalConfigPath()); err == nil {
+				if _, err := config.LoadGlobal(); err != nil {
+					problems = append(problems, fmt.Sprintf("config.toml: %v", err))
+				}
+			}
+			if _, err := os.Stat(storage.DefaultDBPath()); err == nil {
+				if problem := checkDatabase(storage.DefaultDBPath()); problem != "" {
+					problems = append(problems, "reviews.db: "+problem)
+				}
+			}
+
+			if len(problems) == 0 {
+				fmt.Fprintln(out, "No problems found")
+				return nil
+			}
+			fmt.Fprintf(out, "Problems (%d):\n", len(problems))
+			for _, p := range problems {
+				fmt.Fprintf(out, "  %s\n", p)
+			}
+			if !fix {
+				fmt.Fprintln(out, "Run 'roborev doctor --fix' to upgrade the layout")
+			}
+			return fmt.Errorf("found %d problem(s)", len(problems))
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "upgrade the data directory to the current layout")
+	return cmd
+}
+
+// checkDatabase opens the review database read-only and returns what is
+// wrong with it, or "" if nothing is
+func checkDatabase(path string) string {
+	db, err := storage.OpenReadOnly(path)
+	if err != nil {
+		return err.Error()
+	}
+	defer db.Close()
+	if err := db.CheckSchemaCompatible(); err != nil {
+		return err.Error()
+	}
+	var result string
+	if err := db.QueryRow(`PRAGMA quick_check`).Scan(&result); err != nil {
+		return err.Error()
+	}
+	if result != "ok" {
+		return "integrity check: " + result
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestDoctorFixUpgradesLayout(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("ROBOREV_DATA_DIR", dataDir)
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err := os.WriteFile(filepath.Join(dataDir, "errors.log"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := doctorCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected doctor to report the old layout")
+	}
+	if !strings.Contains(out.String(), "errors.log: left from an older layout") {
+		t.Errorf("output missing leftover errors.log:\n%s", out.String())
+	}
+
+	out.Reset()
+	cmd = doctorCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--fix"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("doctor --fix: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Moved errors.log -> logs/errors.log") || !strings.Contains(out.String(), "No problems found") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
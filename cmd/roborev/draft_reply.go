@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func draftReplyCmd() *cobra.Command {
+	var (
+		agentName string
+		model     string
+		commenter string
+		noEdit    bool
+		printOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "draft-reply <job_id>",
+		Short: "Draft a reply to a review with an agent",
+		Long: `Ask an agent to draft the developer's reply to a review: acknowledging the
+findings that hold up and pushing back, with rationale, on those that do not.
+The draft opens in $EDITOR and what you save is added to the review as a
+comment. Save an empty file to discard it.
+
+The review's own agent drafts the reply unless --agent is given. It reads the
+repository but never edits it.
+
+Examples:
+  roborev draft-reply 42
+  roborev draft-reply 42 --agent claude-code
+  roborev draft-reply 42 --print     # show the draft without saving it
+  roborev draft-reply 42 --no-edit   # save the draft as is`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || jobID <= 0 {
+				return fmt.Errorf("invalid job_id: %s", args[0])
+			}
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			addr := getDaemonAddr()
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			review, err := fetchReview(ctx, addr, jobID)
+			if err != nil {
+				return fmt.Errorf("fetch review: %w", err)
+			}
+			comments, err := getCommentsForJob(jobID)
+			if err != nil {
+				return err
+			}
+
+			if agentName == "" {
+				agentName = review.Agent
+			}
+			a, err := agent.GetAvailable(agentName)
+			if err != nil {
+				return fmt.Errorf("get agent: %w", err)
+			}
+			a = a.WithReasoning(agent.ReasoningStandard)
+			if model != "" {
+				a = a.WithModel(model)
+			}
+
+			repoPath, gitRef := ".", ""
+			if review.Job != nil {
+				if review.Job.RepoPath != "" {
+					repoPath = review.Job.RepoPath
+				}
+				gitRef = review.Job.GitRef
+			}
+			if !printOnly {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Drafting reply to job %d with %s...\n", jobID, a.Name())
+			}
+			draft, err := runAgent(ctx, a, repoPath, gitRef, buildDraftReplyPrompt(review, comments), io.Discard)
+			if err != nil {
+				return fmt.Errorf("draft reply: %w", err)
+			}
+			draft = strings.TrimSpace(draft)
+			if draft == "" {
+				return fmt.Errorf("%s returned an empty draft", a.Name())
+			}
+
+			if printOnly {
+				cmd.Println(draft)
+				return nil
+			}
+			message := draft
+			if !noEdit {
+				if message, err = editComment(draft + "\n"); err != nil {
+					return err
+				}
+			}
+			if message == "" {
+				return fmt.Errorf("empty reply, aborting")
+			}
+
+			if commenter == "" {
+				commenter = os.Getenv("USER")
+				if commenter == "" {
+					commenter = "anonymous"
+				}
+			}
+			if err := addJobResponse(addr, jobID, commenter, message); err != nil {
+				return err
+			}
+			cmd.Printf("Reply added to job %d\n", jobID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&agentName, "agent", "", "agent that drafts the reply (default: the review's agent)")
+	cmd.Flags().StringVar(&model, "model", "", "model for the agent")
+	cmd.Flags().StringVar(&commenter, "commenter", "", "name to store the reply under (default: $USER)")
+	cmd.Flags().BoolVar(&noEdit, "no-edit", false, "save the draft without opening an editor")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "print the draft without saving it")
+	cmd.MarkFlagsMutuallyExclusive("no-edit", "print")
+
+	return cmd
+}
+
+// buildDraftReplyPrompt asks an agent to reply to a review as the author of
+// the reviewed code
+func buildDraftReplyPrompt(review *storage.Review, comments []storage.Response) string {
+	var sb strings.Builder
+	sb.WriteString("# Draft a Reply to a Code Review\n\n")
+	sb.WriteString("You are the developer who wrote the reviewed code. ")
+	if review.Job != nil && review.Job.GitRef != "" {
+		fmt.Fprintf(&sb, "The review below is of %s in this repository; read the code it refers to. ", shortRef(review.Job.GitRef))
+	}
+	sb.WriteString("Draft your reply to the review.\n\n")
+	sb.WriteString("## Review\n\n")
+	sb.WriteString(strings.TrimSpace(review.Output))
+	sb.WriteString("\n\n")
+	if len(comments) > 0 {
+		sb.WriteString("## Discussion So Far\n\n")
+		for _, c := range comments {
+			fmt.Fprintf(&sb, "**%s:** %s\n\n", c.Responder, strings.TrimSpace(c.Response))
+		}
+	}
+	sb.WriteString("## Instructions\n\n")
+	sb.WriteString("- Address each finding in turn, briefly\n")
+	sb.WriteString("- Acknowledge findings that are correct and say how they will be fixed\n")
+	sb.WriteString("- Push back on findings that are wrong or not worth fixing, with the reason (point at the code)\n")
+	sb.WriteString("- Do not modify any files\n")
+	sb.WriteString("- Output only the reply, in the first person, with no preamble\n")
+	return sb.String()
+}
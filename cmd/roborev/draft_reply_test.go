@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestBuildDraftReplyPrompt(t *testing.T) {
+	jobID := int64(42)
+	review := &storage.Review{
+		JobID:  jobID,
+		Output: "1. **High** — nil map write in cache.go",
+		Job:    &storage.ReviewJob{ID: jobID, GitRef: "abcdef1234567890"},
+	}
+	comments := []storage.Response{{JobID: &jobID, Responder: "alice", Response: "The map is initialized in New"}}
+
+	prompt := buildDraftReplyPrompt(review, comments)
+	for _, want := range []string{"abcdef1", "nil map write in cache.go", "**alice:** The map is initialized in New", "Push back"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	if prompt := buildDraftReplyPrompt(review, nil); strings.Contains(prompt, "Discussion So Far") {
+		t.Errorf("expected no discussion section without comments:\n%s", prompt)
+	}
+}
+
+func TestDraftReplyCmd(t *testing.T) {
+	var stored string
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/review":
+			json.NewEncoder(w).Encode(storage.Review{
+				ID: 1, JobID: 7, Agent: "test", Output: "Missing error check",
+				Job: &storage.ReviewJob{ID: 7, GitRef: "abc123", RepoPath: t.TempDir()},
+			})
+		case "/api/comments":
+			json.NewEncoder(w).Encode(map[string]any{"responses": []storage.Response{}})
+		case "/api/comment":
+			var req struct {
+				Comment string `json:"comment"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			stored = req.Comment
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(storage.Response{ID: 1})
+		}
+	}))
+	defer cleanup()
+
+	t.Run("print does not store", func(t *testing.T) {
+		stored = ""
+		cmd, out := newTestCmd(t)
+		cmd.AddCommand(draftReplyCmd())
+		cmd.SetArgs([]string{"draft-reply", "7", "--print"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("draft-reply: %v", err)
+		}
+		if out.Len() == 0 {
+			t.Error("expected the draft to be printed")
+		}
+		if stored != "" {
+			t.Errorf("expected nothing stored, got %q", stored)
+		}
+	})
+
+	t.Run("no-edit stores the draft", func(t *testing.T) {
+		stored = ""
+		cmd, out := newTestCmd(t)
+		cmd.AddCommand(draftReplyCmd())
+		cmd.SetArgs([]string{"draft-reply", "7", "--no-edit"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("draft-reply: %v", err)
+		}
+		if stored == "" {
+			t.Error("expected the draft to be stored")
+		}
+		if !strings.Contains(out.String(), "Reply added to job 7") {
+			t.Errorf("unexpected output %q", out.String())
+		}
+	})
+
+	t.Run("invalid job ID", func(t *testing.T) {
+		cmd := draftReplyCmd()
+		cmd.SetArgs([]string{"abc"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid job_id") {
+			t.Errorf("expected invalid job_id error, got %v", err)
+		}
+	})
+}
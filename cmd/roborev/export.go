@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// exportReviewsDir is where 'roborev export --git-dir' writes reviews,
+// relative to the repo root
+var exportReviewsDir = filepath.Join(".roborev", "reviews")
+
+// exportGitignore keeps exported reviews out of git unless --commit is used
+const exportGitignore = "# Written by 'roborev export --git-dir'. Delete this file to commit reviews.\n*\n"
+
+func exportCmd() *cobra.Command {
+	var (
+		repoPath    string
+		gitDir      bool
+		commit      bool
+		since       string
+		codeQuality string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export reviews to files",
+		Long: `Export reviews to files that can be read without the roborev database.
+
+With --git-dir, the reviews of each commit are rendered as markdown into
+.roborev/reviews/<sha>.md inside the repository, so they can be viewed in
+any editor or on a code host. Files are only rewritten when a review
+changed. Range, uncommitted-changes and task jobs are not exported.
+
+By default the directory gets a .gitignore that keeps the files out of git.
+Use --commit to leave them visible to git so they can be committed.
+
+With --code-quality, the open findings of the repository's reviews (not
+addressed, dismissed or assigned) are written as a Code Climate JSON report,
+which GitLab shows in merge requests when uploaded as a codequality report
+artifact. Each finding is placed at the first file and line it cites;
+findings citing no file are left out. Use "-" to write to stdout.
+
+Examples:
+  roborev export --git-dir
+  roborev export --git-dir --since 30d
+  roborev export --git-dir --commit
+  roborev export --code-quality gl-code-quality-report.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !gitDir && codeQuality == "" {
+				return fmt.Errorf("choose an export mode (available: --git-dir, --code-quality)")
+			}
+
+			var sinceTime time.Time
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				sinceTime = t
+			}
+
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			mainRoot, err := git.GetMainRepoRoot(root)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			store, err := openReviewStore(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if codeQuality != "" {
+				// Findings are triaged in the local database only
+				db, ok := store.(*storage.DB)
+				if !ok {
+					return fmt.Errorf("--code-quality is not supported with a shared database")
+				}
+				if err := exportCodeQuality(cmd, db, mainRoot, sinceTime, codeQuality); err != nil {
+					return err
+				}
+				if !gitDir {
+					return nil
+				}
+			}
+
+			reviews, err := loadCommitReviews(cmd.Context(), store, mainRoot, sinceTime)
+			if err != nil {
+				return err
+			}
+
+			dir := filepath.Join(root, exportReviewsDir)
+			written, unchanged, err := writeReviewFiles(cmd.Context(), store, dir, reviews, !commit)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d commit(s) to %s (%d written, %d unchanged)\n",
+				written+unchanged, dir, written, unchanged)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().BoolVar(&gitDir, "git-dir", false, "write reviews to .roborev/reviews/<sha>.md in the repository")
+	cmd.Flags().BoolVar(&commit, "commit", false, "do not gitignore exported reviews, so they can be committed")
+	cmd.Flags().StringVar(&since, "since", "", "only export reviews finished since, e.g. 30d, 2w, 36h or 2026-01-31")
+	cmd.Flags().StringVar(&codeQuality, "code-quality", "", "write open findings to this file as a Code Climate / GitLab Code Quality report")
+	return cmd
+}
+
+// exportCodeQuality writes the open findings of a repo's reviews created
+// since the given time to path ("-" for stdout) as a Code Quality report
+func exportCodeQuality(cmd *cobra.Command, db *storage.DB, repoRoot string, since time.Time, path string) error {
+	var items []storage.TriageItem
+	repo, err := db.GetRepoByPath(cmd.Context(), repoRoot)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("look up repo: %w", err)
+	}
+	if repo != nil {
+		if items, err = db.ListOpenFindings(repo.ID, since); err != nil {
+			return fmt.Errorf("list findings: %w", err)
+		}
+	}
+	issues, skipped := codeQualityIssues(items, repoRoot)
+
+	var buf bytes.Buffer
+	if err := writeCodeQualityReport(&buf, issues); err != nil {
+		return err
+	}
+	summary := cmd.OutOrStdout()
+	if path == "-" {
+		if _, err := cmd.OutOrStdout().Write(buf.Bytes()); err != nil {
+			return err
+		}
+		summary = cmd.ErrOrStderr()
+	} else if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(summary, "Exported %d finding(s) to %s", len(issues), path)
+	if skipped > 0 {
+		fmt.Fprintf(summary, " (%d without a file reference left out)", skipped)
+	}
+	fmt.Fprintln(summary)
+	return nil
+}
+
+// loadCommitReviews returns the completed single-commit reviews of a repo
+// finished since the given time, grouped by commit SHA, oldest first
+func loadCommitReviews(ctx context.Context, db storage.Storage, repoRoot string, since time.Time) (map[string][]*storage.Review, error) {
+	jobs, err := db.ListJobs(ctx, string(storage.JobStatusDone), repoRoot, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+
+	bySHA := make(map[string][]*storage.Review)
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := jobs[i]
+		if job.JobType != storage.JobTypeReview || job.CommitID == nil {
+			continue
+		}
+		if !since.IsZero() && (job.FinishedAt == nil || job.FinishedAt.Before(since)) {
+			continue
+		}
+		review, err := db.GetReviewByJobID(ctx, job.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load review for job %d: %w", job.ID, err)
+		}
+		review.Job.Branch = job.Branch
+		bySHA[job.GitRef] = append(bySHA[job.GitRef], review)
+	}
+	return bySHA, nil
+}
+
+// writeReviewFiles renders each commit's reviews into dir/<sha>.md, leaving
+// files whose content is unchanged alone. With ignore, dir gets a .gitignore
+// that ignores everything in it; otherwise one written by roborev is removed.
+func writeReviewFiles(ctx context.Context, db storage.Storage, dir string, reviews map[string][]*storage.Review, ignore bool) (written, unchanged int, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, 0, err
+	}
+
+	ignorePath := filepath.Join(dir, ".gitignore")
+	if ignore {
+		if _, err := writeIfChanged(ignorePath, []byte(exportGitignore)); err != nil {
+			return 0, 0, err
+		}
+	} else if data, err := os.ReadFile(ignorePath); err == nil && string(data) == exportGitignore {
+		if err := os.Remove(ignorePath); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	shas := make([]string, 0, len(reviews))
+	for sha := range reviews {
+		shas = append(shas, sha)
+	}
+	sort.Strings(shas)
+
+	for _, sha := range shas {
+		var buf bytes.Buffer
+		renderCommitReviews(&buf, sha, reviews[sha], func(jobID int64) []storage.Response {
+			comments, _ := db.GetCommentsForJob(ctx, jobID)
+			return comments
+		})
+		changed, err := writeIfChanged(filepath.Join(dir, sha+".md"), buf.Bytes())
+		if err != nil {
+			return written, unchanged, err
+		}
+		if changed {
+			written++
+		} else {
+			unchanged++
+		}
+	}
+	return written, unchanged, nil
+}
+
+// writeIfChanged writes data to path unless the file already holds it
+func writeIfChanged(path string, data []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, os.WriteFile(path, data, 0644)
+}
+
+// renderCommitReviews writes the reviews of one commit as markdown, newest
+// review first, with the comments on each
+func renderCommitReviews(w io.Writer, sha string, reviews []*storage.Review, comments func(jobID int64) []storage.Response) {
+	subject := ""
+	if len(reviews) > 0 && reviews[0].Job != nil {
+		subject = reviews[0].Job.CommitSubject
+	}
+	if subject != "" {
+		fmt.Fprintf(w, "# Review of %s: %s\n\n", shortSHA(sha), subject)
+	} else {
+		fmt.Fprintf(w, "# Review of %s\n\n", shortSHA(sha))
+	}
+	fmt.Fprintf(w, "Commit: `%s`\n", sha)
+
+	for i := len(reviews) - 1; i >= 0; i-- {
+		r := reviews[i]
+		job := r.Job
+		verdict := storage.ParseVerdict(r.Output)
+		if job.Verdict != nil {
+			verdict = *job.Verdict
+		}
+
+		fmt.Fprintf(w, "\n## %s - %s", verdictLabel(verdict), formatAgentLabel(job.Agent, job.Model))
+		if job.ReviewType != "" && job.ReviewType != "default" {
+			fmt.Fprintf(w, " (%s review)", job.ReviewType)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Job %d", job.ID)
+		if job.FinishedAt != nil {
+			fmt.Fprintf(w, ", finished %s", job.FinishedAt.UTC().Format("2006-01-02 15:04 UTC"))
+		}
+		if job.Branch != "" {
+			fmt.Fprintf(w, " on branch `%s`", job.Branch)
+		}
+		if r.Addressed {
+			fmt.Fprint(w, ", addressed")
+		}
+		fmt.Fprintf(w, "\n\n%s\n", strings.TrimSpace(r.Output))
+
+		if cs := comments(job.ID); len(cs) > 0 {
+			fmt.Fprint(w, "\n### Comments\n\n")
+			for _, c := range cs {
+				fmt.Fprintf(w, "- **%s** (%s): %s\n", c.Responder, c.CreatedAt.UTC().Format("2006-01-02 15:04 UTC"),
+					strings.ReplaceAll(strings.TrimSpace(c.Response), "\n", "\n  "))
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestExportGitDir(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	repo := newTestGitRepo(t)
+	sha := repo.CommitFile("main.go", "package main\n", "add main")
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbRepo, err := db.GetOrCreateRepo(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := testutil.CreateCompletedReview(t, db, dbRepo.ID, sha, "codex", "- High: missing error check")
+	testutil.CreateCompletedReview(t, db, dbRepo.ID, sha, "gemini", "No issues found.")
+	if _, err := db.AddCommentToJob(t.Context(), first.ID, "alice", "fixed in next commit"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	export := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := exportCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"--git-dir", "--repo", repo.Dir}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		return out.String()
+	}
+
+	if out := export(); !strings.Contains(out, "1 written, 0 unchanged") {
+		t.Errorf("unexpected output %q", out)
+	}
+	dir := filepath.Join(repo.Dir, ".roborev", "reviews")
+	data, err := os.ReadFile(filepath.Join(dir, sha+".md"))
+	if err != nil {
+		t.Fatalf("read exported review: %v", err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"# Review of " + sha[:7] + ": test commit",
+		"## PASS - gemini",
+		"## FAIL - codex",
+		"- High: missing error check",
+		"- **alice**",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in exported review:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "gemini") > strings.Index(md, "codex") {
+		t.Error("expected newest review first")
+	}
+
+	// Exported files are ignored by git by default
+	if status := repo.Run("status", "--porcelain"); strings.Contains(status, ".roborev") {
+		t.Errorf("expected exported reviews to be gitignored, got status %q", status)
+	}
+
+	if out := export(); !strings.Contains(out, "0 written, 1 unchanged") {
+		t.Errorf("expected unchanged file on second export, got %q", out)
+	}
+
+	// --commit drops the .gitignore so reviews can be committed
+	export("--commit")
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); !os.IsNotExist(err) {
+		t.Errorf("expected .gitignore to be removed with --commit, got %v", err)
+	}
+	if status := repo.Run("status", "--porcelain", "--untracked-files=all"); !strings.Contains(status, sha+".md") {
+		t.Errorf("expected exported review to be visible to git, got status %q", status)
+	}
+}
+
+func TestExportRequiresMode(t *testing.T) {
+	cmd := exportCmd()
+	cmd.SetArgs(nil)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--git-dir, --code-quality") {
+		t.Errorf("expected export mode error, got %v", err)
+	}
+}
+
+func TestExportCodeQuality(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	repo := newTestGitRepo(t)
+	sha := repo.CommitFile("main.go", "package main\n", "add main")
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbRepo, err := db.GetOrCreateRepo(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CreateCompletedReview(t, db, dbRepo.ID, sha, "codex",
+		"- Critical: SQL injection in main.go:1\n\n- Low: consider more tests")
+	db.Close()
+
+	path := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+	var out bytes.Buffer
+	cmd := exportCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--code-quality", path, "--repo", repo.Dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(out.String(), "Exported 1 finding(s)") || !strings.Contains(out.String(), "1 without a file reference") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []map[string]any
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatalf("report is not a JSON array: %v\n%s", err, data)
+	}
+	if len(issues) != 1 || issues[0]["severity"] != "critical" {
+		t.Fatalf("unexpected report %s", data)
+	}
+	location := issues[0]["location"].(map[string]any)
+	if location["path"] != "main.go" || location["lines"].(map[string]any)["begin"] != float64(1) {
+		t.Errorf("unexpected location %v", location)
+	}
+}
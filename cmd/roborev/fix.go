@@ -237,7 +237,7 @@ func fixJobDirect(ctx context.Context, params fixJobParams, prompt string) (*fix
 			return nil, fmt.Errorf("resolve HEAD: %w", err)
 		}
 		// Unborn HEAD (empty repo) - run agent and check outcome
-		agentOutput, agentErr := params.Agent.Review(ctx, params.RepoRoot, "HEAD", prompt, out)
+		agentOutput, agentErr := runAgent(ctx, params.Agent, params.RepoRoot, "HEAD", prompt, out)
 		if agentErr != nil {
 			return nil, fmt.Errorf("fix agent failed: %w", agentErr)
 		}
@@ -253,7 +253,7 @@ func fixJobDirect(ctx context.Context, params fixJobParams, prompt string) (*fix
 		return &fixJobResult{NoChanges: !hasChanges, AgentOutput: agentOutput}, nil
 	}
 
-	agentOutput, agentErr := params.Agent.Review(ctx, params.RepoRoot, "HEAD", prompt, out)
+	agentOutput, agentErr := runAgent(ctx, params.Agent, params.RepoRoot, "HEAD", prompt, out)
 	if agentErr != nil {
 		return nil, fmt.Errorf("fix agent failed: %w", agentErr)
 	}
@@ -269,7 +269,7 @@ func fixJobDirect(ctx context.Context, params fixJobParams, prompt string) (*fix
 	}
 
 	fmt.Fprint(out, "\nNo commit was created. Re-running agent with commit instructions...\n\n")
-	if _, retryErr := params.Agent.Review(ctx, params.RepoRoot, "HEAD", buildGenericCommitPrompt(), out); retryErr != nil {
+	if _, retryErr := runAgent(ctx, params.Agent, params.RepoRoot, "HEAD", buildGenericCommitPrompt(), out); retryErr != nil {
 		fmt.Fprintf(out, "Warning: commit agent failed: %v\n", retryErr)
 	}
 	if sha, ok := detectNewCommit(params.RepoRoot, headBefore); ok {
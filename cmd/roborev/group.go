@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func groupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "Follow and cancel groups of jobs enqueued together",
+		Long: `Follow and cancel groups of jobs enqueued together.
+
+A job group tracks jobs as one unit: reviews enqueued with
+'roborev review --group <name>', the jobs of 'roborev analyze --per-file',
+and the parts of a fanned-out review, which are grouped automatically.
+Groups are named by ID or by name.
+
+Examples:
+  roborev group list
+  roborev group status nightly
+  roborev group cancel 12`,
+	}
+
+	cmd.AddCommand(groupStatusCmd())
+	cmd.AddCommand(groupListCmd())
+	cmd.AddCommand(groupCancelCmd())
+
+	return cmd
+}
+
+func groupStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <id|name>",
+		Short: "Show the progress of a job group",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var group storage.JobGroup
+			if err := getGroups(getDaemonAddr()+"/api/groups/status", url.Values{"id": {args[0]}}, &group); err != nil {
+				return err
+			}
+			printGroupStatus(cmd.OutOrStdout(), group)
+			return nil
+		},
+	}
+}
+
+func groupListCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent job groups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var resp daemon.JobGroupsResponse
+			if err := getGroups(getDaemonAddr()+"/api/groups", url.Values{"limit": {fmt.Sprint(limit)}}, &resp); err != nil {
+				return err
+			}
+			printGroups(cmd.OutOrStdout(), resp.Groups)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of groups")
+
+	return cmd
+}
+
+func groupCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id|name>",
+		Short: "Cancel the unfinished jobs of a group",
+		Long: `Cancel every queued, running or blocked job of a group. Finished jobs and
+their reviews are kept.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			resp, err := cancelGroup(getDaemonAddr(), args[0])
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if len(resp.Canceled) == 0 {
+				fmt.Fprintf(w, "No unfinished jobs in group %s\n", args[0])
+				return nil
+			}
+			fmt.Fprintf(w, "Canceled %d job(s) in group %s: %v\n", len(resp.Canceled), args[0], resp.Canceled)
+			return nil
+		},
+	}
+}
+
+// getGroups decodes the response of a daemon groups endpoint into result
+func getGroups(endpoint string, params url.Values, result any) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeGroupResponse(resp, params.Get("id"), result)
+}
+
+func cancelGroup(addr, ref string) (daemon.CancelGroupResponse, error) {
+	var result daemon.CancelGroupResponse
+	body, err := json.Marshal(daemon.CancelGroupRequest{Group: ref})
+	if err != nil {
+		return result, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(addr+"/api/groups/cancel", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	err = decodeGroupResponse(resp, ref, &result)
+	return result, err
+}
+
+func decodeGroupResponse(resp *http.Response, ref string, result any) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("no job group %s", ref)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func printGroupStatus(w io.Writer, g storage.JobGroup) {
+	fmt.Fprintf(w, "Group %d: %s (%s)\n", g.ID, g.Name, g.Kind)
+	fmt.Fprintf(w, "Created: %s\n", g.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(w, "Progress: %d/%d finished\n", g.Finished(), g.Total)
+	for _, status := range []storage.JobStatus{
+		storage.JobStatusQueued, storage.JobStatusRunning, storage.JobStatusBlocked,
+		storage.JobStatusDone, storage.JobStatusFailed, storage.JobStatusCanceled, storage.JobStatusSkipped,
+	} {
+		if n := g.ByStatus[status]; n > 0 {
+			fmt.Fprintf(w, "  %-9s %d\n", status, n)
+		}
+	}
+	if g.Passed+g.Failed > 0 {
+		fmt.Fprintf(w, "Reviews: %d passed, %d with findings\n", g.Passed, g.Failed)
+	}
+}
+
+func printGroups(w io.Writer, groups []storage.JobGroup) {
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "No job groups")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tKIND\tPROGRESS\tPASSED\tFAILED\tCREATED")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d/%d\t%d\t%d\t%s\n", g.ID, g.Name, g.Kind, g.Finished(), g.Total,
+			g.Passed, g.Failed, g.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestGroupCommands(t *testing.T) {
+	group := storage.JobGroup{
+		ID: 7, Name: "nightly", Kind: storage.JobGroupReview, CreatedAt: time.Now(), Total: 5,
+		ByStatus: map[storage.JobStatus]int{storage.JobStatusDone: 3, storage.JobStatusQueued: 2},
+		Passed:   2, Failed: 1,
+	}
+	var canceledRef string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/groups/status":
+			if id := r.URL.Query().Get("id"); id != "7" && id != "nightly" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeJSON(w, group)
+		case "/api/groups":
+			writeJSON(w, daemon.JobGroupsResponse{Groups: []storage.JobGroup{group}})
+		case "/api/groups/cancel":
+			var req daemon.CancelGroupRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			canceledRef = req.Group
+			writeJSON(w, daemon.CancelGroupResponse{Canceled: []int64{4, 5}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var got storage.JobGroup
+	if err := getGroups(ts.URL+"/api/groups/status", url.Values{"id": {"nightly"}}, &got); err != nil {
+		t.Fatalf("getGroups: %v", err)
+	}
+	var out bytes.Buffer
+	printGroupStatus(&out, got)
+	for _, want := range []string{"Group 7: nightly (review)", "Progress: 3/5 finished", "queued    2", "Reviews: 2 passed, 1 with findings"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("status output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err := getGroups(ts.URL+"/api/groups/status", url.Values{"id": {"missing"}}, &got)
+	if err == nil || !strings.Contains(err.Error(), "no job group missing") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	var list daemon.JobGroupsResponse
+	if err := getGroups(ts.URL+"/api/groups", url.Values{}, &list); err != nil {
+		t.Fatalf("getGroups: %v", err)
+	}
+	out.Reset()
+	printGroups(&out, list.Groups)
+	if !strings.Contains(out.String(), "nightly") || !strings.Contains(out.String(), "3/5") {
+		t.Errorf("unexpected list output:\n%s", out.String())
+	}
+
+	resp, err := cancelGroup(ts.URL, "7")
+	if err != nil {
+		t.Fatalf("cancelGroup: %v", err)
+	}
+	if canceledRef != "7" || len(resp.Canceled) != 2 {
+		t.Errorf("unexpected cancel of %q: %+v", canceledRef, resp)
+	}
+}
@@ -158,7 +158,7 @@ func TestInstallHookCmdCreatesHooksDirectory(t *testing.T) {
 }
 
 func TestGenerateHookContent(t *testing.T) {
-	content := generateHookContent()
+	content := generateHookContent(false)
 	lines := strings.Split(content, "\n")
 
 	t.Run("has shebang", func(t *testing.T) {
@@ -211,6 +211,15 @@ func TestGenerateHookContent(t *testing.T) {
 		}
 	})
 
+	t.Run("pre-review is opt-in", func(t *testing.T) {
+		if strings.Contains(content, "--pre-review") {
+			t.Error("default hook should not run a pre-review")
+		}
+		if !strings.Contains(generateHookContent(true), `enqueue --quiet --pre-review 2>/dev/null`) {
+			t.Error("pre-review hook should pass --pre-review to enqueue")
+		}
+	})
+
 	t.Run("has version marker", func(t *testing.T) {
 		if !strings.Contains(content, "hook v2") {
 			t.Error("hook should contain version marker 'hook v2'")
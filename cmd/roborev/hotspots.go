@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func hotspotsCmd() *cobra.Command {
+	var (
+		repoPath string
+		since    string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "hotspots",
+		Short: "Rank files by churn times review findings",
+		Long: `Rank the files of a repository by how often they change times how many
+review findings cite them, to show where refactoring pays off most.
+
+Churn is the number of non-merge commits on the current branch that touched
+a file in the period. A finding counts for each file it names (for example
+"internal/db.go:42"); findings triaged as dismissed are not counted.
+
+Examples:
+  roborev hotspots
+  roborev hotspots --since 30d --limit 10
+  roborev hotspots --repo ~/src/myproject`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			resp, err := getHotspots(getDaemonAddr(), root, sinceTime, limit)
+			if err != nil {
+				return err
+			}
+			printHotspots(cmd.OutOrStdout(), resp)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&since, "since", "90d", "period to rank, e.g. 30d, 2w, 36h or 2026-01-31")
+	cmd.Flags().IntVar(&limit, "limit", 20, "number of files to show (0 for all)")
+
+	return cmd
+}
+
+func getHotspots(addr, repo string, since time.Time, limit int) (daemon.HotspotsResponse, error) {
+	var result daemon.HotspotsResponse
+	params := url.Values{
+		"repo":  {repo},
+		"since": {since.Format(time.RFC3339)},
+		"limit": {strconv.Itoa(limit)},
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Get(addr + "/api/hotspots?" + params.Encode())
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// Repo has never been reviewed
+		return daemon.HotspotsResponse{Repo: repo, Since: since}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return result, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return result, fmt.Errorf("decode hotspots: %w", err)
+	}
+	return result, nil
+}
+
+func printHotspots(w io.Writer, resp daemon.HotspotsResponse) {
+	since := resp.Since.Local().Format("2006-01-02")
+	if len(resp.Hotspots) == 0 {
+		fmt.Fprintf(w, "No hotspots since %s: no changed file is cited by a review finding\n", since)
+		return
+	}
+
+	fmt.Fprintf(w, "Hotspots since %s (commits x findings)\n\n", since)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCORE\tCOMMITS\tLINES\tFINDINGS\tFILE")
+	for _, h := range resp.Hotspots {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\n", h.Score, h.Commits, h.LinesChanged, h.Findings, h.Path)
+	}
+	tw.Flush()
+}
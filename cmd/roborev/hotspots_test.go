@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+)
+
+func TestGetHotspots(t *testing.T) {
+	since := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	var gotQuery string
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/hotspots" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode(daemon.HotspotsResponse{
+			Repo:  "/src/repo",
+			Since: since,
+			Hotspots: []daemon.Hotspot{
+				{Path: "internal/db.go", Commits: 5, LinesChanged: 120, Findings: 3, Score: 15},
+				{Path: "main.go", Commits: 2, LinesChanged: 8, Findings: 1, Score: 2},
+			},
+		})
+	}))
+	defer cleanup()
+
+	resp, err := getHotspots(ts.URL, "/src/repo", since, 5)
+	if err != nil {
+		t.Fatalf("getHotspots: %v", err)
+	}
+	for _, want := range []string{"repo=%2Fsrc%2Frepo", "since=2026-01-31T00%3A00%3A00Z", "limit=5"} {
+		if !strings.Contains(gotQuery, want) {
+			t.Errorf("expected %q in query %q", want, gotQuery)
+		}
+	}
+
+	var out bytes.Buffer
+	printHotspots(&out, resp)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[2], "SCORE") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[3]); strings.Join(fields, " ") != "15 5 120 3 internal/db.go" {
+		t.Errorf("unexpected first row %q", lines[3])
+	}
+}
+
+func TestPrintHotspotsEmpty(t *testing.T) {
+	var out bytes.Buffer
+	printHotspots(&out, daemon.HotspotsResponse{Since: time.Now()})
+	if !strings.HasPrefix(out.String(), "No hotspots since ") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
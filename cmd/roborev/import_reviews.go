@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/ingest"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func importReviewsCmd() *cobra.Command {
+	var (
+		repoPath   string
+		github     bool
+		prs        []int
+		gerritURL  string
+		project    string
+		changes    []string
+		gerritUser string
+		since      string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import-reviews",
+		Short: "Import human reviews from GitHub pull requests or Gerrit",
+		Long: `Import the reviews people left on GitHub pull requests or Gerrit changes of
+this repo, so human and agent reviews can be searched, counted and learned
+from together.
+
+Each review is recorded as a completed review by the agent "human" of the
+commit it was left on. Its line comments become findings (medium severity
+when changes were requested, low otherwise) and replies to them become
+comments. Reviews already imported are skipped, apart from new replies.
+
+GitHub reviews are fetched with the gh CLI. Gerrit is queried over its REST
+API; with --gerrit-user, the HTTP password is read from GERRIT_HTTP_PASSWORD.
+
+Examples:
+  roborev import-reviews --github                  # 50 most recently updated PRs
+  roborev import-reviews --github --pr 120 --pr 121
+  roborev import-reviews --github --since 90d --limit 500
+  roborev import-reviews --gerrit https://review.example.com --project tools`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if github == (gerritURL != "") {
+				return fmt.Errorf("choose one source: --github or --gerrit")
+			}
+			var sinceTime time.Time
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				sinceTime = t
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Minute)
+			defer cancel()
+			var reviews []storage.HumanReview
+			if github {
+				gh := &ingest.GitHub{Dir: root}
+				reviews, err = gh.Reviews(ctx, ingest.GitHubOptions{PRs: prs, Limit: limit, Since: sinceTime})
+			} else {
+				g := &ingest.Gerrit{
+					BaseURL:  gerritURL,
+					Project:  project,
+					User:     gerritUser,
+					Password: os.Getenv("GERRIT_HTTP_PASSWORD"),
+					Dir:      root,
+				}
+				reviews, err = g.Reviews(ctx, ingest.GerritOptions{Changes: changes, Limit: limit, Since: sinceTime})
+			}
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+			rootCommit, _ := git.GetRootCommit(root)
+			repo, err := db.GetOrCreateRepoFollowMoves(root, config.ResolveRepoIdentity(root, nil), rootCommit)
+			if err != nil {
+				return err
+			}
+
+			var imported, findings, skipped int
+			for _, r := range reviews {
+				_, isNew, err := db.ImportHumanReview(repo.ID, r)
+				if err != nil {
+					return fmt.Errorf("import review %s by %s: %w", r.ExternalID, r.Reviewer, err)
+				}
+				if !isNew {
+					skipped++
+					continue
+				}
+				imported++
+				findings += len(r.Comments)
+			}
+			fmt.Printf("Imported %d reviews with %d findings", imported, findings)
+			if skipped > 0 {
+				fmt.Printf(" (%d already imported)", skipped)
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to repository (default: current directory)")
+	cmd.Flags().BoolVar(&github, "github", false, "import GitHub pull request reviews")
+	cmd.Flags().IntSliceVar(&prs, "pr", nil, "with --github, pull request to import (repeatable; default the most recently updated)")
+	cmd.Flags().StringVar(&gerritURL, "gerrit", "", "import reviews from the Gerrit server at this URL")
+	cmd.Flags().StringVar(&project, "project", "", "with --gerrit, the project whose changes are imported")
+	cmd.Flags().StringSliceVar(&changes, "change", nil, "with --gerrit, change number or ID to import (repeatable)")
+	cmd.Flags().StringVar(&gerritUser, "gerrit-user", "", "with --gerrit, user to authenticate as")
+	cmd.Flags().StringVar(&since, "since", "", "skip reviews submitted before this (e.g. 90d, 12w or 2026-01-31)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "how many recently updated pull requests or changes to import")
+
+	return cmd
+}
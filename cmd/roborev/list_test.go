@@ -3,12 +3,14 @@ package main
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/storage"
 )
 
@@ -73,6 +75,37 @@ func TestListCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("tabular output shows quick takes", func(t *testing.T) {
+		withQuickTake := append([]storage.ReviewJob(nil), testJobs...)
+		withQuickTake[0].QuickTake = "Passes; the retry loop now backs off."
+		_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/jobs" {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"jobs":     withQuickTake,
+					"has_more": false,
+				})
+				return
+			}
+		}))
+		t.Cleanup(cleanup)
+
+		repo := newTestGitRepo(t)
+		repo.CommitFile("file.txt", "content", "initial")
+		chdir(t, repo.Dir)
+
+		output := captureStdout(t, func() {
+			cmd := listCmd()
+			cmd.SetArgs([]string{})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		if !strings.Contains(output, "Quick Take") || !strings.Contains(output, "the retry loop now backs off") {
+			t.Errorf("expected quick take column, got: %s", output)
+		}
+	})
+
 	t.Run("json output passes through raw response", func(t *testing.T) {
 		_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == "/api/jobs" {
@@ -248,6 +281,55 @@ func TestListCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("agent and date range pass through", func(t *testing.T) {
+		var receivedQuery url.Values
+		_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/jobs" {
+				receivedQuery = r.URL.Query()
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"jobs":     []storage.ReviewJob{},
+					"has_more": false,
+				})
+				return
+			}
+		}))
+		t.Cleanup(cleanup)
+
+		repo := newTestGitRepo(t)
+		repo.CommitFile("file.txt", "content", "initial")
+		chdir(t, repo.Dir)
+
+		captureStdout(t, func() {
+			cmd := listCmd()
+			cmd.SetArgs([]string{"--agent", "codex", "--since", "2026-01-01", "--until", "2026-02-01"})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		if got := receivedQuery.Get("agent"); got != "codex" {
+			t.Errorf("agent = %q, want codex", got)
+		}
+		wantSince := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local).UTC().Format(time.RFC3339)
+		if got := receivedQuery.Get("since"); got != wantSince {
+			t.Errorf("since = %q, want %q", got, wantSince)
+		}
+		wantUntil := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local).UTC().Format(time.RFC3339)
+		if got := receivedQuery.Get("until"); got != wantUntil {
+			t.Errorf("until = %q, want %q", got, wantUntil)
+		}
+	})
+
+	t.Run("invalid --until returns error", func(t *testing.T) {
+		cmd := listCmd()
+		cmd.SetArgs([]string{"--until", "someday"})
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--until") {
+			t.Errorf("expected --until error, got %v", err)
+		}
+	})
+
 	t.Run("explicit --repo to non-git path sends no branch", func(t *testing.T) {
 		var receivedQuery string
 		_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -468,3 +550,159 @@ func TestShowJSONOutput(t *testing.T) {
 		}
 	})
 }
+
+func TestListCommandSources(t *testing.T) {
+	now := time.Now()
+	localJobs := []storage.ReviewJob{
+		{ID: 7, GitRef: "aaa1111111111", RepoName: "myrepo", Agent: "codex", Status: storage.JobStatusDone, EnqueuedAt: now.Add(-time.Minute)},
+		{ID: 6, GitRef: "ccc3333333333", RepoName: "myrepo", Agent: "codex", Status: storage.JobStatusDone, EnqueuedAt: now.Add(-time.Hour)},
+	}
+	remoteJobs := []storage.ReviewJob{
+		{ID: 950, GitRef: "bbb2222222222", RepoName: "myrepo", Agent: "claude-code", Status: storage.JobStatusQueued, EnqueuedAt: now.Add(-10 * time.Minute)},
+	}
+
+	var localQuery url.Values
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/jobs" {
+			localQuery = r.URL.Query()
+			json.NewEncoder(w).Encode(map[string]interface{}{"jobs": localJobs, "has_more": false})
+		}
+	}))
+	t.Cleanup(cleanup)
+
+	var remoteQuery url.Values
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/jobs" {
+			remoteQuery = r.URL.Query()
+			json.NewEncoder(w).Encode(map[string]interface{}{"jobs": remoteJobs, "has_more": false})
+		}
+	}))
+	t.Cleanup(remote.Close)
+
+	repo := newTestGitRepo(t)
+	repo.CommitFile("file.txt", "content", "initial")
+	repo.Run("remote", "add", "origin", "https://github.com/acme/myrepo.git")
+	chdir(t, repo.Dir)
+
+	t.Run("merges sources newest first", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			cmd := listCmd()
+			cmd.SetArgs([]string{"--source", "local", "--source", "team=" + remote.URL, "--limit", "2"})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected header, 2 jobs and more hint, got:\n%s", output)
+		}
+		if !strings.HasPrefix(lines[0], "Source") {
+			t.Errorf("expected Source column, got: %s", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], "local") || !strings.Contains(lines[1], "aaa1111") {
+			t.Errorf("expected newest local job first, got: %s", lines[1])
+		}
+		if !strings.HasPrefix(lines[2], "team") || !strings.Contains(lines[2], "bbb2222") {
+			t.Errorf("expected remote job second, got: %s", lines[2])
+		}
+		if !strings.Contains(lines[3], "more results available") {
+			t.Errorf("expected more results hint after truncation, got: %s", lines[3])
+		}
+
+		if localQuery.Get("repo") == "" || localQuery.Get("repo_identity") != "" {
+			t.Errorf("local daemon should be filtered by path, got: %v", localQuery)
+		}
+		if remoteQuery.Get("repo") != "" || remoteQuery.Get("repo_identity") != "https://github.com/acme/myrepo.git" {
+			t.Errorf("remote daemon should be filtered by identity, got: %v", remoteQuery)
+		}
+	})
+
+	t.Run("json output includes source", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			cmd := listCmd()
+			cmd.SetArgs([]string{"--source", "team=" + remote.URL, "--json"})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		var parsed []sourcedJob
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("json output not valid JSON: %v\noutput: %s", err, output)
+		}
+		if len(parsed) != 1 || parsed[0].Source != "team" || parsed[0].ID != 950 {
+			t.Errorf("unexpected jobs: %+v", parsed)
+		}
+	})
+
+	t.Run("failing source is skipped with a warning", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			cmd := listCmd()
+			cmd.SetArgs([]string{"--source", "local", "--source", "down=http://127.0.0.1:1"})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if !strings.Contains(output, "aaa1111") {
+			t.Errorf("expected local jobs despite failing source, got: %s", output)
+		}
+	})
+
+	t.Run("all sources failing returns error", func(t *testing.T) {
+		cmd := listCmd()
+		cmd.SetArgs([]string{"--source", "down=http://127.0.0.1:1"})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("expected error when no source could be listed")
+		}
+	})
+}
+
+func TestResolveJobSources(t *testing.T) {
+	cfg := &config.Config{Sources: []config.SourceConfig{
+		{Name: "team", Addr: "http://team:7373/"},
+		{Name: "ci", Addr: "http://ci:7373"},
+	}}
+
+	t.Run("all sources", func(t *testing.T) {
+		sources, err := resolveJobSources([]string{"team", "LOCAL"}, true, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []jobSource{
+			{Name: "local", Local: true},
+			{Name: "team", Addr: "http://team:7373"},
+			{Name: "ci", Addr: "http://ci:7373"},
+		}
+		if len(sources) != len(want) {
+			t.Fatalf("got %+v, want %+v", sources, want)
+		}
+		for i := range want {
+			if sources[i] != want[i] {
+				t.Errorf("source %d: got %+v, want %+v", i, sources[i], want[i])
+			}
+		}
+	})
+
+	t.Run("named and ad hoc sources", func(t *testing.T) {
+		sources, err := resolveJobSources([]string{"Team", "other=http://other:7373"}, false, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sources) != 2 || sources[0].Addr != "http://team:7373" || sources[1].Name != "other" {
+			t.Errorf("unexpected sources: %+v", sources)
+		}
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		if _, err := resolveJobSources([]string{"nope"}, false, cfg); err == nil || !strings.Contains(err.Error(), "unknown source") {
+			t.Errorf("expected unknown source error, got %v", err)
+		}
+	})
+
+	t.Run("invalid ad hoc source", func(t *testing.T) {
+		if _, err := resolveJobSources([]string{"=http://x"}, false, cfg); err == nil {
+			t.Error("expected error for empty name")
+		}
+	})
+}
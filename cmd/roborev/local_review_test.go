@@ -56,7 +56,7 @@ func (h *reviewHarness) run(opts runOpts) error {
 	if opts.Revision == "" {
 		opts.Revision = "HEAD"
 	}
-	return runLocalReview(h.Cmd, h.Dir, opts.Revision, opts.Diff, opts.Agent, opts.Model, opts.Reasoning, opts.ReviewType, opts.Quiet)
+	return runLocalReview(h.Cmd, h.Dir, opts.Revision, opts.Diff, opts.Agent, opts.Model, opts.Reasoning, opts.ReviewType, false, opts.Quiet, nil)
 }
 
 func TestLocalReviewFlag(t *testing.T) {
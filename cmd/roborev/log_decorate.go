@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func logDecorateCmd() *cobra.Command {
+	var (
+		repoPath string
+		color    string
+		all      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "log-decorate [-- <git log args>]",
+		Short: "Annotate git log output with review verdicts",
+		Long: `Append each commit's review state to git log output, so reviews are
+visible while browsing history.
+
+Pipe git log into it, or pass git log arguments after -- and it runs git log
+itself (with --oneline when no arguments are given). The commit hash is
+taken from the start of each line, after any --graph characters, in both
+--oneline and full formats; abbreviated hashes are resolved with git.
+Other lines are passed through unchanged.
+
+The marker is the same state "roborev statusline" prints, e.g. [pass] or
+[fail 3]. Unreviewed commits are left unmarked unless --all is given.
+
+The database is opened read-only, so the command does not need the daemon.
+
+Examples:
+  git log --oneline --graph --color=always | roborev log-decorate | less -R
+  roborev log-decorate -- --oneline main..HEAD
+  git config --global alias.rlog '!f() { git log --oneline --color=always "$@" | roborev log-decorate | less -RFX; }; f'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			var useColor bool
+			switch color {
+			case "always":
+				useColor = true
+			case "never":
+			case "auto":
+				useColor = isTerminal(os.Stdout.Fd())
+			default:
+				return fmt.Errorf("invalid --color %q: must be auto, always or never", color)
+			}
+
+			in := cmd.InOrStdin()
+			if len(args) > 0 || isTerminal(os.Stdin.Fd()) {
+				if len(args) == 0 {
+					args = []string{"--oneline"}
+				}
+				gitLog := exec.Command("git", append([]string{"log"}, args...)...)
+				gitLog.Dir = repoPath
+				gitLog.Stderr = cmd.ErrOrStderr()
+				out, err := gitLog.StdoutPipe()
+				if err != nil {
+					return err
+				}
+				if err := gitLog.Start(); err != nil {
+					return fmt.Errorf("git log: %w", err)
+				}
+				defer gitLog.Wait() //nolint:errcheck
+				in = out
+			}
+
+			resolver := newCommitResolver(root)
+			defer resolver.Close()
+			// The local database is only read, and not created when missing
+			var store storage.Storage
+			if cfg, err := config.LoadGlobal(); err == nil && cfg.Database.PostgresURLExpanded() != "" {
+				if store, err = openReviewStore(cmd.Context()); err != nil {
+					return err
+				}
+				defer store.Close()
+			} else if dbStamp(storage.DefaultDBPath()) != "" {
+				db, err := storage.OpenReadOnly(storage.DefaultDBPath())
+				if err != nil {
+					return fmt.Errorf("open database: %w", err)
+				}
+				defer db.Close()
+				store = db
+			}
+
+			statuses := make(map[string]string)
+			lookup := func(ref string) string {
+				sha := resolver.Resolve(ref)
+				if sha == "" {
+					return ""
+				}
+				if marker, ok := statuses[sha]; ok {
+					return marker
+				}
+				var st storage.CommitStatus
+				if store != nil {
+					st, _ = store.GetCommitStatus(cmd.Context(), root, sha)
+				}
+				marker := ""
+				if st.Status != "" || all {
+					marker = formatStatusLine(sha, st, false)
+				}
+				statuses[sha] = marker
+				return marker
+			}
+			return decorateLog(in, cmd.OutOrStdout(), lookup, useColor)
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&color, "color", "auto", "color markers: auto, always or never (auto also colors when the input is colored)")
+	cmd.Flags().BoolVar(&all, "all", false, "mark unreviewed commits too")
+	return cmd
+}
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	// A hash at the start of a log line, after --graph characters and the
+	// "commit " of the full format
+	logLineRef = regexp.MustCompile(`^[*|/\\_ .-]*(?:commit )?([0-9a-f]{7,40})\b`)
+)
+
+// logLineCommit returns the commit hash a git log line starts with
+func logLineCommit(line string) (string, bool) {
+	m := logLineRef.FindStringSubmatch(ansiEscape.ReplaceAllString(line, ""))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// decorateLog copies git log output from r to w, appending the marker lookup
+// returns for each line that starts with a commit hash. Lines are written as
+// they are read so the output can be paged while git log is still running.
+// Markers are colored when color is set or the line itself is colored.
+func decorateLog(r io.Reader, w io.Writer, lookup func(ref string) string, color bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if ref, ok := logLineCommit(line); ok {
+			if marker := lookup(ref); marker != "" {
+				line += " " + formatLogMarker(marker, color || strings.Contains(line, "\x1b["))
+			}
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// formatLogMarker brackets a status line, colored by outcome
+func formatLogMarker(marker string, color bool) string {
+	text := "[" + marker + "]"
+	if !color {
+		return text
+	}
+	code := "2" // dim
+	switch strings.Fields(marker)[0] {
+	case "pass", "addressed":
+		code = "32"
+	case "fail", "error":
+		code = "31"
+	case "queued", "running":
+		code = "33"
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// commitResolver expands abbreviated hashes to full commit SHAs through a
+// single long-running git cat-file, so large logs don't spawn a git process
+// per line
+type commitResolver struct {
+	repoPath string
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   *bufio.Reader
+	failed   bool
+}
+
+func newCommitResolver(repoPath string) *commitResolver {
+	return &commitResolver{repoPath: repoPath}
+}
+
+// Resolve returns the full SHA of the commit ref names, or "" if it doesn't
+// name exactly one commit
+func (c *commitResolver) Resolve(ref string) string {
+	if c.failed {
+		return ""
+	}
+	if c.cmd == nil {
+		c.cmd = exec.Command("git", "cat-file", "--batch-check=%(objectname) %(objecttype)")
+		c.cmd.Dir = c.repoPath
+		stdin, err := c.cmd.StdinPipe()
+		if err != nil {
+			c.failed = true
+			return ""
+		}
+		stdout, err := c.cmd.StdoutPipe()
+		if err != nil {
+			c.failed = true
+			return ""
+		}
+		if err := c.cmd.Start(); err != nil {
+			c.failed = true
+			return ""
+		}
+		c.stdin, c.stdout = stdin, bufio.NewReader(stdout)
+	}
+
+	if _, err := fmt.Fprintln(c.stdin, ref); err != nil {
+		c.failed = true
+		return ""
+	}
+	reply, err := c.stdout.ReadString('\n')
+	if err != nil {
+		c.failed = true
+		return ""
+	}
+	// "<sha> commit", or "<ref> missing" / "<ref> ambiguous"
+	fields := strings.Fields(reply)
+	if len(fields) != 2 || fields[1] != "commit" {
+		return ""
+	}
+	return fields[0]
+}
+
+// Close stops the git process
+func (c *commitResolver) Close() {
+	if c.cmd == nil {
+		return
+	}
+	c.stdin.Close()
+	_ = c.cmd.Wait()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogLineCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"oneline", "abc1234 Fix the thing", "abc1234"},
+		{"full format", "commit 0123456789abcdef0123456789abcdef01234567 (HEAD -> main)", "0123456789abcdef0123456789abcdef01234567"},
+		{"graph", "| * abc1234 Merge branch", "abc1234"},
+		{"colored", "\x1b[33mabc1234\x1b[m Fix", "abc1234"},
+		{"colored graph", "\x1b[31m|\x1b[m * \x1b[33mcommit abc1234def\x1b[m", "abc1234def"},
+		{"message body", "    Fix the thing", ""},
+		{"author line", "Author: Test <test@test.com>", ""},
+		{"too short", "abc12 Fix", ""},
+		{"longer than a sha", strings.Repeat("a", 64) + " x", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := logLineCommit(tt.line)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("logLineCommit(%q) = %q, %v; want %q", tt.line, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecorateLog(t *testing.T) {
+	markers := map[string]string{"abc1234": "pass", "def5678": "fail 2"}
+	var looked []string
+	lookup := func(ref string) string {
+		looked = append(looked, ref)
+		return markers[ref]
+	}
+
+	input := "abc1234 First\ndef5678 Second\n0000000 Unreviewed\n  not a commit\n"
+	var out bytes.Buffer
+	if err := decorateLog(strings.NewReader(input), &out, lookup, false); err != nil {
+		t.Fatalf("decorateLog: %v", err)
+	}
+	want := "abc1234 First [pass]\ndef5678 Second [fail 2]\n0000000 Unreviewed\n  not a commit\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if len(looked) != 3 {
+		t.Errorf("looked up %v, want the three commit lines", looked)
+	}
+
+	t.Run("colored input gets colored markers", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := decorateLog(strings.NewReader("\x1b[33mdef5678\x1b[m Second\n"), &out, lookup, false); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "\x1b[31m[fail 2]\x1b[0m") {
+			t.Errorf("expected red marker, got %q", out.String())
+		}
+	})
+}
+
+func TestCommitResolver(t *testing.T) {
+	repo := newTestGitRepo(t)
+	sha := repo.CommitFile("a.txt", "a", "first")
+	r := newCommitResolver(repo.Dir)
+	defer r.Close()
+
+	if got := r.Resolve(sha[:7]); got != sha {
+		t.Errorf("Resolve(abbrev) = %q, want %q", got, sha)
+	}
+	if got := r.Resolve(sha); got != sha {
+		t.Errorf("Resolve(full) = %q, want %q", got, sha)
+	}
+	if got := r.Resolve("0000000"); got != "" {
+		t.Errorf("Resolve(missing) = %q, want empty", got)
+	}
+	tree := repo.Run("rev-parse", "HEAD^{tree}")
+	if got := r.Resolve(tree); got != "" {
+		t.Errorf("Resolve(tree) = %q, want empty for non-commits", got)
+	}
+}
@@ -25,13 +25,17 @@ import (
 	"time"
 
 	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/blobstore"
+	"github.com/roborev-dev/roborev/internal/cliprof"
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/daemon"
 	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/i18n"
+	"github.com/roborev-dev/roborev/internal/jj"
 	"github.com/roborev-dev/roborev/internal/prompt"
 	"github.com/roborev-dev/roborev/internal/skills"
 	"github.com/roborev-dev/roborev/internal/storage"
-	"github.com/roborev-dev/roborev/internal/update"
+	"github.com/roborev-dev/roborev/internal/vcs"
 	"github.com/roborev-dev/roborev/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -39,6 +43,7 @@ import (
 var (
 	serverAddr string
 	verbose    bool
+	language   string
 
 	// Polling intervals for waitForJob - exposed for testing
 	pollStartInterval = 1 * time.Second
@@ -46,22 +51,32 @@ var (
 )
 
 func main() {
+	start := time.Now()
 	rootCmd := &cobra.Command{
 		Use:   "roborev",
 		Short: "Automatic code review for git commits",
 		Long:  "roborev automatically reviews git commits using AI agents (Codex, Claude Code, Gemini, Copilot, OpenCode, Cursor)",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			startProfile(cmd)
+		},
 	}
 
 	rootCmd.PersistentFlags().StringVar(&serverAddr, "server", "http://127.0.0.1:7373", "daemon server address")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&profileCLI, "profile-cli", false, "report where the command's time went: database, git, HTTP requests and agents")
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "language of messages, such as de or es (default: $ROBOREV_LANG, the language config setting, or the locale)")
+	cobra.OnInitialize(initLanguage)
 
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(reviewCmd())
+	rootCmd.AddCommand(reviewPatchCmd())
 	rootCmd.AddCommand(statusCmd())
 	rootCmd.AddCommand(listCmd())
 	rootCmd.AddCommand(showCmd())
+	rootCmd.AddCommand(diffReviewsCmd())
 	rootCmd.AddCommand(commentCmd())
 	rootCmd.AddCommand(respondCmd()) // hidden alias for backward compatibility
+	rootCmd.AddCommand(draftReplyCmd())
 	rootCmd.AddCommand(addressCmd())
 	rootCmd.AddCommand(installHookCmd())
 	rootCmd.AddCommand(uninstallHookCmd())
@@ -72,16 +87,73 @@ func main() {
 	rootCmd.AddCommand(runCmd())
 	rootCmd.AddCommand(analyzeCmd())
 	rootCmd.AddCommand(fixCmd())
-	rootCmd.AddCommand(promptCmd()) // hidden alias for backward compatibility
+	rootCmd.AddCommand(autofixCmd())
+	rootCmd.AddCommand(amendMessageCmd())
+	rootCmd.AddCommand(promptCmd())
 	rootCmd.AddCommand(repoCmd())
+	rootCmd.AddCommand(undoCmd())
 	rootCmd.AddCommand(skillsCmd())
 	rootCmd.AddCommand(syncCmd())
+	rootCmd.AddCommand(pushCmd())
+	rootCmd.AddCommand(pullCmd())
+	rootCmd.AddCommand(importReviewsCmd())
+	rootCmd.AddCommand(queueCmd())
+	rootCmd.AddCommand(groupCmd())
+	rootCmd.AddCommand(deadLettersCmd())
+	rootCmd.AddCommand(badgeCmd())
+	rootCmd.AddCommand(triageCmd())
+	rootCmd.AddCommand(reconcileCmd())
+	rootCmd.AddCommand(remapCmd())
+	rootCmd.AddCommand(searchCmd())
+	rootCmd.AddCommand(hotspotsCmd())
+	rootCmd.AddCommand(checklistCmd())
+	rootCmd.AddCommand(planCmd())
+	rootCmd.AddCommand(serverHookCmd())
+	rootCmd.AddCommand(statuslineCmd())
+	rootCmd.AddCommand(logDecorateCmd())
+	rootCmd.AddCommand(benchCmd())
+	rootCmd.AddCommand(dbCmd())
+	rootCmd.AddCommand(backupCmd())
+	rootCmd.AddCommand(doctorCmd())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(reportCmd())
+	rootCmd.AddCommand(authorCmd())
 	rootCmd.AddCommand(checkAgentsCmd())
 	rootCmd.AddCommand(configCmd())
-	rootCmd.AddCommand(updateCmd())
+	rootCmd.AddCommand(selfUpdateCmd())
 	rootCmd.AddCommand(versionCmd())
+	rootCmd.AddCommand(verifyCmd())
+	rootCmd.AddCommand(verifyReleaseCmd())
+	rootCmd.AddCommand(telemetryCmd())
+	rootCmd.AddCommand(tokenCmd())
+	rootCmd.AddCommand(shareCmd())
+	registerCompletions(rootCmd)
+
+	// Record or replay agent responses (ROBOREV_VCR) for end-to-end tests
+	vcr, err := agent.VCRFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	agent.SetVCR(vcr)
+	installTokenTransport()
+
+	// Agents that call model APIs directly take their settings from the
+	// global config; the daemon keeps its own copy current on reload
+	if cfg, err := config.LoadGlobal(); err == nil {
+		agent.SetOpenAIConfig(agent.OpenAIConfig{
+			BaseURL: cfg.OpenAI.BaseURL,
+			APIKey:  cfg.OpenAI.APIKeyExpanded(),
+			Model:   cfg.OpenAI.Model,
+		})
+	}
 
-	if err := rootCmd.Execute(); err != nil {
+	executed, err := rootCmd.ExecuteC()
+	if executed != nil {
+		finishProfile(executed, time.Since(start))
+	}
+	if err != nil {
 		// Check for exitError to exit with specific code without extra output
 		if exitErr, ok := err.(*exitError); ok {
 			os.Exit(exitErr.code)
@@ -90,6 +162,20 @@ func main() {
 	}
 }
 
+// initLanguage sets the language of messages from --lang, $ROBOREV_LANG,
+// the language config setting or the locale, in that order
+func initLanguage() {
+	if language != "" && i18n.Supported(language) == "" {
+		fmt.Fprintf(os.Stderr, "Warning: no messages in language %q (available: %s)\n",
+			language, strings.Join(i18n.Languages(), ", "))
+	}
+	var configured string
+	if cfg, err := config.LoadGlobal(); err == nil {
+		configured = cfg.Language
+	}
+	i18n.SetLanguage(i18n.Detect(language, configured))
+}
+
 // getDaemonAddr returns the daemon address from runtime file or default
 func getDaemonAddr() string {
 	if info, err := daemon.GetAnyRunningDaemon(); err == nil {
@@ -149,54 +235,52 @@ func registerRepo(repoPath string) error {
 	return nil
 }
 
-// ensureDaemon checks if daemon is running, starts it if not
-// If daemon is running but has different version, restart it
+// ensureDaemon checks if daemon is running, starts it if not.
+// An older daemon, or one whose version can't be read, is restarted with this
+// binary; a newer one is kept (see negotiateDaemon).
 func ensureDaemon() error {
 	client := &http.Client{Timeout: 500 * time.Millisecond}
 
-	// First check runtime files for any running daemon
-	if info, err := daemon.GetAnyRunningDaemon(); err == nil {
-		addr := fmt.Sprintf("http://%s/api/status", info.Addr)
-		resp, err := client.Get(addr)
-		if err == nil {
-			defer resp.Body.Close()
+	// checkRunning negotiates with the daemon at addr, reporting whether it
+	// answered at all
+	checkRunning := func(addr string) (bool, error) {
+		resp, err := client.Get(addr + "/api/status")
+		if err != nil {
+			return false, nil
+		}
+		defer resp.Body.Close()
 
-			// Parse response to get actual daemon version
-			var status struct {
-				Version string `json:"version"`
+		// Fail closed: restart if the status can't be decoded
+		var status storage.DaemonStatus
+		action := daemonRestart
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&status); decodeErr == nil {
+			if action, err = negotiateDaemon(status); err != nil {
+				return true, err
 			}
-			decodeErr := json.NewDecoder(resp.Body).Decode(&status)
-
-			// Fail closed: restart if decode fails, version empty, or mismatch
-			if decodeErr != nil || status.Version == "" || status.Version != version.Version {
-				if verbose {
-					fmt.Printf("Daemon version mismatch or unreadable (daemon: %s, cli: %s), restarting...\n", status.Version, version.Version)
-				}
-				return restartDaemon()
+		}
+		if action == daemonRestart {
+			if verbose {
+				fmt.Printf("Daemon version mismatch or unreadable (daemon: %s, cli: %s), restarting...\n", status.Version, version.Version)
 			}
-
-			serverAddr = fmt.Sprintf("http://%s", info.Addr)
-			return nil
+			return true, restartDaemon()
+		}
+		if verbose && status.Version != version.Version {
+			fmt.Printf("Using newer daemon %s (cli: %s)\n", status.Version, version.Version)
 		}
+		serverAddr = addr
+		return true, nil
 	}
 
-	// Try default address - also check version from response
-	resp, err := client.Get(serverAddr + "/api/status")
-	if err == nil {
-		defer resp.Body.Close()
-		var status struct {
-			Version string `json:"version"`
+	// First check runtime files for any running daemon
+	if info, err := daemon.GetAnyRunningDaemon(); err == nil {
+		if ok, err := checkRunning(fmt.Sprintf("http://%s", info.Addr)); ok {
+			return err
 		}
-		decodeErr := json.NewDecoder(resp.Body).Decode(&status)
+	}
 
-		// Fail closed: restart if decode fails, version empty, or mismatch
-		if decodeErr != nil || status.Version == "" || status.Version != version.Version {
-			if verbose {
-				fmt.Printf("Daemon version mismatch or unreadable (daemon: %s, cli: %s), restarting...\n", status.Version, version.Version)
-			}
-			return restartDaemon()
-		}
-		return nil
+	// Try default address - also check version from response
+	if ok, err := checkRunning(serverAddr); ok {
+		return err
 	}
 
 	// Start daemon in background
@@ -280,16 +364,12 @@ func startDaemon() error {
 	if err != nil {
 		return fmt.Errorf("failed to find executable: %w", err)
 	}
+	return startDaemonExe(exe)
+}
 
-	cmd := exec.Command(exe, "daemon", "run")
-	cmd.Env = filterGitEnv(os.Environ())
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start daemon: %w", err)
-	}
-
-	// Wait for daemon to be ready and update serverAddr from runtime file
+// waitForDaemonStart waits for a just-started daemon to answer and updates
+// serverAddr from its runtime file
+func waitForDaemonStart() error {
 	client := &http.Client{Timeout: 500 * time.Millisecond}
 	for i := 0; i < 30; i++ {
 		time.Sleep(100 * time.Millisecond)
@@ -304,7 +384,7 @@ func startDaemon() error {
 		}
 	}
 
-	return fmt.Errorf("daemon failed to start")
+	return daemonStartError()
 }
 
 // ErrDaemonNotRunning indicates no daemon runtime file was found
@@ -451,7 +531,7 @@ func initCmd() *cobra.Command {
 				return fmt.Errorf("get hooks path: %w", err)
 			}
 			hookPath := filepath.Join(hooksDir, "post-commit")
-			hookContent := generateHookContent()
+			hookContent := generateHookContent(false)
 
 			// Ensure hooks directory exists
 			if err := os.MkdirAll(hooksDir, 0755); err != nil {
@@ -579,10 +659,40 @@ func daemonCmd() *cobra.Command {
 		},
 	})
 
-	cmd.AddCommand(&cobra.Command{
+	cmd.AddCommand(daemonRestartCmd())
+
+	cmd.AddCommand(daemonRunCmd())
+
+	return cmd
+}
+
+func daemonRestartCmd() *cobra.Command {
+	var (
+		upgrade      bool
+		drainTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
 		Use:   "restart",
 		Short: "Restart the daemon",
+		Long: `Restart the daemon.
+
+With --upgrade, the daemon first stops claiming jobs and waits for running
+ones to finish, then roborev is updated to the latest release and the new
+daemon is started, which migrates the database. Queued jobs are kept. If
+jobs are still running after --drain-timeout, or the update fails, the queue
+is resumed and the current daemon keeps running.
+
+Examples:
+  roborev daemon restart
+  roborev daemon restart --upgrade
+  roborev daemon restart --upgrade --drain-timeout 30m`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if upgrade {
+				return upgradeDaemon(cmd.OutOrStdout(), drainTimeout)
+			}
+
 			wasRunning := true
 			if err := stopDaemon(); err == ErrDaemonNotRunning {
 				wasRunning = false
@@ -599,10 +709,10 @@ func daemonCmd() *cobra.Command {
 			}
 			return nil
 		},
-	})
-
-	cmd.AddCommand(daemonRunCmd())
+	}
 
+	cmd.Flags().BoolVar(&upgrade, "upgrade", false, "drain the queue, update roborev and start the new daemon")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 10*time.Minute, "with --upgrade, how long to wait for running jobs")
 	return cmd
 }
 
@@ -613,12 +723,29 @@ func daemonRunCmd() *cobra.Command {
 		configPath string
 		addr       string
 		workers    int
+		idleMins   int
 	)
 
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run the daemon in foreground",
-		Long:  "Run the daemon in the foreground. Usually invoked by 'daemon start' in the background.",
+		Long: `Run the daemon in the foreground. Usually invoked by 'daemon start' in the background.
+
+When started by systemd socket activation (LISTEN_FDS), the daemon serves on
+the passed socket instead of picking a port. Combine with --idle-shutdown
+(or idle_shutdown_minutes in config.toml) so the daemon exits when idle and
+is relaunched on the next connection:
+
+  # ~/.config/systemd/user/roborev.socket
+  [Socket]
+  ListenStream=127.0.0.1:7373
+
+  [Install]
+  WantedBy=sockets.target
+
+  # ~/.config/systemd/user/roborev.service
+  [Service]
+  ExecStart=%h/go/bin/roborev daemon run --idle-shutdown 30`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Defense-in-depth: clear git repo-context env vars that hooks may set.
 			// The spawn sites (startDaemon, upgrade) filter these out, but
@@ -656,6 +783,19 @@ func daemonRunCmd() *cobra.Command {
 			if workers > 0 {
 				cfg.MaxWorkers = workers
 			}
+			if cmd.Flags().Changed("idle-shutdown") {
+				cfg.IdleShutdownMinutes = idleMins
+			}
+
+			// Move files of an older data directory layout to their
+			// current homes before anything opens them
+			if moved, err := config.UpgradeLayout(config.DataDir()); err != nil {
+				log.Printf("Warning: data directory layout: %v", err)
+			} else {
+				for _, m := range moved {
+					log.Printf("Data directory: moved %s", m)
+				}
+			}
 
 			// Open database
 			db, err := storage.Open(dbPath)
@@ -665,9 +805,44 @@ func daemonRunCmd() *cobra.Command {
 			defer db.Close()
 			log.Printf("Database: %s", dbPath)
 
+			// A newer roborev migrated the database; writing to it could
+			// break data this binary doesn't know about
+			if err := db.CheckSchemaCompatible(); err != nil {
+				return err
+			}
+
+			if blobs, err := blobstore.New(cfg.BlobStore); err != nil {
+				log.Printf("Blob store disabled: %v", err)
+			} else if blobs != nil {
+				db.SetBlobStore(blobs, cfg.BlobStore.Threshold())
+				log.Printf("Blob store: %s (offloading values over %d bytes)", cfg.BlobStore.Backend, cfg.BlobStore.Threshold())
+			}
+
+			// With [database] postgres_url set, reviews and the job queue
+			// live in the shared database; the local one still identifies
+			// this machine
+			var store storage.Storage = db
+			pgURL := cfg.Database.PostgresURLExpanded()
+			shared := pgURL != ""
+			if shared {
+				machineID, err := db.GetMachineID()
+				if err != nil {
+					return fmt.Errorf("get machine ID: %w", err)
+				}
+				pg, err := storage.OpenPgStore(cmd.Context(), pgURL, machineID)
+				if err != nil {
+					return fmt.Errorf("open shared database: %w", err)
+				}
+				defer pg.Close()
+				store = pg
+				log.Printf("Shared database: PostgreSQL ([database] postgres_url)")
+			}
+
 			// Start sync worker if enabled
 			var syncWorker *storage.SyncWorker
-			if cfg.Sync.Enabled {
+			if cfg.Sync.Enabled && shared {
+				log.Printf("Sync disabled: reviews are kept in the shared database")
+			} else if cfg.Sync.Enabled {
 				// Validate sync config
 				warnings := cfg.Sync.Validate()
 				for _, w := range warnings {
@@ -675,12 +850,12 @@ func daemonRunCmd() *cobra.Command {
 				}
 
 				// Backfill machine IDs on existing rows
-				if err := db.BackfillSourceMachineID(); err != nil {
+				if err := db.BackfillSourceMachineID(cmd.Context()); err != nil {
 					log.Printf("Warning: failed to backfill source_machine_id: %v", err)
 				}
 
 				// Backfill repo identities from git remotes
-				if count, err := db.BackfillRepoIdentities(); err != nil {
+				if count, err := db.BackfillRepoIdentities(cmd.Context()); err != nil {
 					log.Printf("Warning: failed to backfill repo identities: %v", err)
 				} else if count > 0 {
 					log.Printf("Backfilled %d repo identities from git remotes", count)
@@ -699,14 +874,16 @@ func daemonRunCmd() *cobra.Command {
 			defer cancel()
 
 			// Create and start server
-			server := daemon.NewServer(db, cfg, configPath)
+			server := daemon.NewServer(store, cfg, configPath)
 			if syncWorker != nil {
 				server.SetSyncWorker(syncWorker)
 			}
 
 			// Start CI poller if enabled
 			var ciPoller *daemon.CIPoller
-			if cfg.CI.Enabled {
+			if cfg.CI.Enabled && shared {
+				log.Printf("CI poller disabled: it needs the local database")
+			} else if cfg.CI.Enabled {
 				ciPoller = daemon.NewCIPoller(db, server.ConfigWatcher(), server.Broadcaster())
 				server.SetCIPoller(ciPoller) // wire callbacks before Start to avoid race
 				if err := ciPoller.Start(); err != nil {
@@ -729,8 +906,12 @@ func daemonRunCmd() *cobra.Command {
 			}
 
 			go func() {
-				sig := <-sigCh
-				log.Printf("Received signal %v, shutting down...", sig)
+				select {
+				case sig := <-sigCh:
+					log.Printf("Received signal %v, shutting down...", sig)
+				case <-server.IdleShutdown():
+					log.Printf("Idle for %d minutes with an empty queue, shutting down...", cfg.IdleShutdownMinutes)
+				}
 				cancel() // Cancel context to stop config watcher
 				if ciPoller != nil {
 					ciPoller.Stop()
@@ -758,6 +939,7 @@ func daemonRunCmd() *cobra.Command {
 	cmd.Flags().StringVar(&configPath, "config", config.GlobalConfigPath(), "path to config file")
 	cmd.Flags().StringVar(&addr, "addr", "", "server address (overrides config)")
 	cmd.Flags().IntVar(&workers, "workers", 0, "number of workers (overrides config)")
+	cmd.Flags().IntVar(&idleMins, "idle-shutdown", 0, "exit after this many idle minutes with an empty queue, 0 disables (overrides config)")
 
 	return cmd
 }
@@ -781,6 +963,10 @@ func reviewCmd() *cobra.Command {
 		baseBranch string
 		since      string
 		local      bool
+		preReview  bool
+		thorough   bool
+		group      string
+		contextSrc []string
 	)
 
 	cmd := &cobra.Command{
@@ -803,6 +989,10 @@ Examples:
   roborev review --since abc123  # Review commits since abc123 (exclusive)
   roborev review --type security   # Security-focused review of HEAD
   roborev review --branch --type security  # Security review of branch
+  roborev review --pre-review  # Quick inline review, then queue the full one
+  roborev review --thorough    # Focus review of a high-stakes commit
+  roborev review --group nightly abc123  # Track the job in the "nightly" group
+  roborev review --context docs/design.md  # Check HEAD against its design doc
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// In quiet mode, suppress cobra's error output (hook uses &, so exit code doesn't matter)
@@ -814,18 +1004,30 @@ Examples:
 			// --fast is shorthand for --reasoning fast (explicit --reasoning takes precedence)
 			reasoning = resolveReasoningWithFast(reasoning, fast, cmd.Flags().Changed("reasoning"))
 
+			// --thorough always reviews at thorough reasoning
+			if thorough {
+				if reasoning != "" && reasoning != "thorough" {
+					return fmt.Errorf("cannot use --thorough with --fast or --reasoning %s", reasoning)
+				}
+				reasoning = "thorough"
+			}
+
 			// Default to current directory
 			if repoPath == "" {
 				repoPath = "."
 			}
 
 			// Get repo root
-			root, err := git.GetRepoRoot(repoPath)
+			repoVCS := vcs.For(repoPath)
+			root, err := repoVCS.RepoRoot(repoPath)
 			if err != nil {
 				if quiet {
 					return nil // Not a repo - silent exit for hooks
 				}
-				return fmt.Errorf("not a git repository: %w", err)
+				if jjRoot := jj.Root(repoPath); jjRoot != "" {
+					return jj.NotColocatedError(jjRoot)
+				}
+				return fmt.Errorf("not a %s repository: %w", repoVCS.Name(), err)
 			}
 
 			// Skip during rebase to avoid reviewing every replayed commit
@@ -853,18 +1055,33 @@ Examples:
 			if since != "" && dirty {
 				return fmt.Errorf("cannot use --since with --dirty")
 			}
+			if group != "" && local {
+				return fmt.Errorf("cannot use --group with --local")
+			}
 			if branch != "" && len(args) > 0 {
 				return fmt.Errorf("cannot specify commits with --branch (to review a specific branch, use --branch=<name>)")
 			}
 			if since != "" && len(args) > 0 {
 				return fmt.Errorf("cannot specify commits with --since")
 			}
+			if preReview && local {
+				return fmt.Errorf("cannot use --pre-review with --local")
+			}
+			if repoVCS.Name() != "git" && (branch != "" || dirty || local || preReview) {
+				return fmt.Errorf("--branch, --dirty, --local and --pre-review need a git repository")
+			}
 
 			// Validate --type flag
 			if reviewType != "" && reviewType != "security" && reviewType != "design" {
 				return fmt.Errorf("invalid --type %q (valid: security, design)", reviewType)
 			}
 
+			// Design docs are read now, relative to the working directory
+			contextDocs, err := loadContextDocs(contextSrc)
+			if err != nil {
+				return err
+			}
+
 			var gitRef string
 			var diffContent string
 
@@ -921,13 +1138,13 @@ Examples:
 				}
 			} else if since != "" {
 				// Review commits since a specific commit (exclusive)
-				sinceCommit, err := git.ResolveSHA(root, since)
+				sinceCommit, err := repoVCS.ResolveRef(root, since)
 				if err != nil {
 					return fmt.Errorf("invalid --since commit %q: %w", since, err)
 				}
 
 				// Validate has commits
-				commits, err := git.GetCommitsSince(root, sinceCommit)
+				commits, err := repoVCS.NewCommits(root, sinceCommit, "HEAD")
 				if err != nil {
 					return fmt.Errorf("cannot get commits: %w", err)
 				}
@@ -980,14 +1197,20 @@ Examples:
 
 			// Get branch name for tracking. When --branch=<name> targets
 			// a different branch, use that name instead of the checked-out branch.
-			branchName := git.GetCurrentBranch(root)
+			branchName := repoVCS.CurrentBranch(root)
 			if branch != "" && branch != "HEAD" {
 				branchName = branch
 			}
 
 			// Handle --local mode: run agent directly without daemon
 			if local {
-				return runLocalReview(cmd, root, gitRef, diffContent, agent, model, reasoning, reviewType, quiet)
+				// The daemon resolves jj change IDs itself
+				if gitRef != "dirty" {
+					if gitRef, err = jj.ResolveRef(root, gitRef); err != nil {
+						return err
+					}
+				}
+				return runLocalReview(cmd, root, gitRef, diffContent, agent, model, reasoning, reviewType, thorough, quiet, contextDocs)
 			}
 
 			// Build request body
@@ -1001,6 +1224,24 @@ Examples:
 				"review_type":  reviewType,
 				"diff_content": diffContent,
 			}
+			// Quiet enqueues come from the post-commit hook, whose commits
+			// the repo's sampling policy may leave unreviewed
+			if quiet {
+				reqFields["hook"] = true
+			}
+			if thorough {
+				reqFields["focus"] = true
+			}
+			// Someone is waiting on the result
+			if wait {
+				reqFields["interactive"] = true
+			}
+			if group != "" {
+				reqFields["group"] = group
+			}
+			if len(contextDocs) > 0 {
+				reqFields["context_docs"] = contextDocs
+			}
 
 			reqBody, _ := json.Marshal(reqFields)
 
@@ -1020,7 +1261,7 @@ Examples:
 				}
 				if err := json.Unmarshal(body, &skipResp); err == nil && skipResp.Skipped {
 					if !quiet {
-						cmd.Printf("Skipped: %s\n", skipResp.Reason)
+						cmd.Println(i18n.T("Skipped: %s", skipResp.Reason))
 					}
 					return nil
 				}
@@ -1033,11 +1274,39 @@ Examples:
 			var job storage.ReviewJob
 			json.Unmarshal(body, &job)
 
+			// Commits marked [skip roborev] are recorded but never reviewed
+			if job.Status == storage.JobStatusSkipped {
+				if !quiet {
+					cmd.Println(i18n.T("Skipped job %d for %s: %s", job.ID, shortRef(job.GitRef), job.Error))
+				}
+				return nil
+			}
+
 			if !quiet {
-				if dirty {
-					cmd.Printf("Enqueued dirty review job %d (agent: %s)\n", job.ID, job.Agent)
-				} else {
-					cmd.Printf("Enqueued job %d for %s (agent: %s)\n", job.ID, shortRef(job.GitRef), job.Agent)
+				switch {
+				case dirty && job.Focus:
+					cmd.Println(i18n.T("Enqueued dirty thorough review job %d (agent: %s)", job.ID, job.Agent))
+				case dirty:
+					cmd.Println(i18n.T("Enqueued dirty review job %d (agent: %s)", job.ID, job.Agent))
+				case job.Focus:
+					cmd.Println(i18n.T("Enqueued thorough job %d for %s (agent: %s)", job.ID, shortRef(job.GitRef), job.Agent))
+				default:
+					cmd.Println(i18n.T("Enqueued job %d for %s (agent: %s)", job.ID, shortRef(job.GitRef), job.Agent))
+				}
+			}
+
+			// The pre-review is shown even when quiet: the hook runs it
+			// for this output
+			if preReview {
+				cfg, err := config.LoadGlobal()
+				if err != nil {
+					return fmt.Errorf("load config: %w", err)
+				}
+				pre := runPreReview(cmd.Context(), root, job.GitRef, diffContent, cfg)
+				pre.JobID = job.ID
+				printPreReview(cmd.OutOrStdout(), pre)
+				if err := linkPreReview(serverAddr, pre); err != nil && !quiet {
+					cmd.PrintErrf("Warning: %v\n", err)
 				}
 			}
 
@@ -1072,12 +1341,16 @@ Examples:
 	cmd.Flags().StringVar(&since, "since", "", "review commits since this commit (exclusive, like git's .. range)")
 	cmd.Flags().BoolVar(&local, "local", false, "run review locally without daemon (streams output to console)")
 	cmd.Flags().StringVar(&reviewType, "type", "", "review type (security, design) — changes system prompt")
+	cmd.Flags().BoolVar(&preReview, "pre-review", false, "run a quick review inline within the [pre_review] time budget, then queue the full review")
+	cmd.Flags().BoolVar(&thorough, "thorough", false, "focus review for high-stakes commits: the [focus] agent and model, larger prompt budget, full file context and blame, never sampled or skipped")
+	cmd.Flags().StringVar(&group, "group", "", "add the job to this job group, created if needed (see 'roborev group')")
+	cmd.Flags().StringArrayVar(&contextSrc, "context", nil, "design doc (file or http(s) URL) to check the change against; repeatable")
 
 	return cmd
 }
 
 // runLocalReview runs a review directly without the daemon
-func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName, model, reasoning, reviewType string, quiet bool) error {
+func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName, model, reasoning, reviewType string, focus, quiet bool, contextDocs []storage.ContextDoc) error {
 	// Load config
 	cfg, err := config.LoadGlobal()
 	if err != nil {
@@ -1096,6 +1369,19 @@ func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName
 		workflow = reviewType
 	}
 
+	// Focus reviews prefer the [focus] agent and model (matches daemon behavior)
+	builder := prompt.NewBuilder(nil).WithContextDocs(contextDocs)
+	if focus {
+		focusCfg := config.ResolveFocus(repoPath, cfg)
+		if agentName == "" {
+			agentName = focusCfg.Agent
+		}
+		if model == "" {
+			model = focusCfg.Model
+		}
+		builder = builder.Focus()
+	}
+
 	// Resolve agent using workflow-specific resolution (matches daemon behavior)
 	agentName = config.ResolveAgentForWorkflow(agentName, repoPath, cfg, workflow, reasoning)
 
@@ -1126,9 +1412,9 @@ func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName
 	var reviewPrompt string
 	if diffContent != "" {
 		// Dirty review
-		reviewPrompt, err = prompt.NewBuilder(nil).BuildDirty(repoPath, diffContent, 0, cfg.ReviewContextCount, a.Name(), reviewType)
+		reviewPrompt, err = builder.BuildDirty(repoPath, diffContent, 0, cfg.ReviewContextCount, a.Name(), reviewType)
 	} else {
-		reviewPrompt, err = prompt.NewBuilder(nil).Build(repoPath, gitRef, 0, cfg.ReviewContextCount, a.Name(), reviewType)
+		reviewPrompt, err = builder.Build(repoPath, gitRef, 0, cfg.ReviewContextCount, a.Name(), reviewType)
 	}
 	if err != nil {
 		return fmt.Errorf("build prompt: %w", err)
@@ -1136,7 +1422,7 @@ func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName
 
 	// Run review with output writer
 	ctx := context.Background()
-	_, err = a.Review(ctx, repoPath, gitRef, reviewPrompt, out)
+	_, err = runAgent(ctx, a, repoPath, gitRef, reviewPrompt, out)
 	if err != nil {
 		return fmt.Errorf("review failed: %w", err)
 	}
@@ -1150,6 +1436,7 @@ func runLocalReview(cmd *cobra.Command, repoPath, gitRef, diffContent, agentName
 // waitForJob polls until a job completes and displays the review
 // Uses the provided serverAddr to ensure we poll the same daemon that received the job.
 func waitForJob(cmd *cobra.Command, serverAddr string, jobID int64, quiet bool) error {
+	defer cliprof.Span(cliprof.PhaseAgent)()
 	client := &http.Client{Timeout: 5 * time.Second}
 
 	if !quiet {
@@ -1210,6 +1497,18 @@ func waitForJob(cmd *cobra.Command, serverAddr string, jobID int64, quiet bool)
 			}
 			return fmt.Errorf("review was canceled")
 
+		case storage.JobStatusSkipped:
+			if !quiet {
+				cmd.Printf(" skipped: %s\n", job.Error)
+			}
+			return nil
+
+		case storage.JobStatusBlocked:
+			if !quiet {
+				cmd.Printf(" blocked!\n")
+			}
+			return fmt.Errorf("review is blocked: %s", job.Error)
+
 		case storage.JobStatusQueued, storage.JobStatusRunning:
 			// Still in progress, continue polling
 			unknownStatusCount = 0 // Reset counter on known status
@@ -1291,25 +1590,45 @@ func (e *exitError) Error() string {
 }
 
 func statusCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		watch    bool
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show daemon and queue status",
+		Long: `Show daemon and queue status.
+
+--watch redraws a live view every --interval until Ctrl+C: queue counts,
+each worker's current job and heartbeat, running jobs with elapsed time,
+and recent completions. Idle workers beat every few seconds, so an idle
+worker with an old heartbeat is flagged STALE. A busy worker beats when
+its agent writes output, so its heartbeat shows how long the agent has
+been silent.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch && interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
 			// Ensure daemon is running (and restart if version mismatch)
 			if err := ensureDaemon(); err != nil {
-				fmt.Println("Daemon: not running")
+				fmt.Println(i18n.T("Daemon: not running"))
 				fmt.Println()
-				fmt.Println("Start with: roborev daemon start")
+				fmt.Println(i18n.T("Start with: roborev daemon start"))
 				return nil
 			}
 
 			addr := getDaemonAddr()
+			if watch {
+				return watchStatus(cmd.OutOrStdout(), addr, interval)
+			}
 			client := &http.Client{Timeout: 2 * time.Second}
 			resp, err := client.Get(addr + "/api/status")
 			if err != nil {
-				fmt.Println("Daemon: not running")
+				fmt.Println(i18n.T("Daemon: not running"))
 				fmt.Println()
-				fmt.Println("Start with: roborev daemon start")
+				fmt.Println(i18n.T("Start with: roborev daemon start"))
 				return nil
 			}
 			defer resp.Body.Close()
@@ -1328,25 +1647,48 @@ func statusCmd() *cobra.Command {
 			}
 
 			// Display daemon info with uptime and version
-			daemonLine := "Daemon: running"
+			daemonLine := i18n.T("Daemon: running")
 			if health.Uptime != "" {
-				daemonLine += fmt.Sprintf(" (uptime: %s)", health.Uptime)
+				daemonLine += " " + i18n.T("(uptime: %s)", health.Uptime)
 			}
 			if status.Version != "" {
 				daemonLine += fmt.Sprintf(" [%s]", status.Version)
 			}
 			fmt.Println(daemonLine)
-			fmt.Printf("Workers: %d/%d active\n", status.ActiveWorkers, status.MaxWorkers)
-			fmt.Printf("Jobs:    %d queued, %d running, %d completed, %d failed\n",
-				status.QueuedJobs, status.RunningJobs, status.CompletedJobs, status.FailedJobs)
+			fmt.Println(i18n.T("Workers: %d/%d active", status.ActiveWorkers, status.MaxWorkers))
+			fmt.Println(i18n.T("Jobs:    %d queued, %d running, %d completed, %d failed",
+				status.QueuedJobs, status.RunningJobs, status.CompletedJobs, status.FailedJobs))
+			if status.OverdueJobs > 0 {
+				fmt.Println(i18n.T("Overdue: %d job(s) past their repo's review SLA", status.OverdueJobs))
+			}
+			if status.Starvation != nil {
+				fmt.Println(starvationWarning(status.Starvation, time.Now()))
+			}
+			if len(status.BrokenRepos) > 0 {
+				fmt.Println(i18n.T("Blocked jobs (they run once the cause is fixed):"))
+				for _, r := range status.BrokenRepos {
+					fmt.Println("  " + i18n.T("%s: %d job(s) blocked: %s", r.Name, r.BlockedJobs, r.Reason))
+				}
+				fmt.Println("  " + i18n.T("Fix the cause, or run 'roborev repo delete --cascade' on a deleted repo to drop its jobs"))
+			}
+			if untriaged, err := getUntriagedCount(addr, ""); err == nil && untriaged > 0 {
+				fmt.Println(i18n.T("Triage:  %d untriaged finding(s) (run 'roborev triage --all')", untriaged))
+			}
+			if len(status.FindingAccuracy) > 0 {
+				fmt.Println("Invalid file/line references in findings (30 days):")
+				for _, a := range status.FindingAccuracy {
+					fmt.Printf("  %s: %.1f%% (%d of %d findings, %d reviews)\n",
+						a.Agent, 100*a.InvalidRate(), a.Invalid, a.Findings, a.Reviews)
+				}
+			}
 			fmt.Println()
 
 			// Display health status
 			if health.Version != "" {
 				if health.Healthy {
-					fmt.Println("Health: OK")
+					fmt.Println(i18n.T("Health: OK"))
 				} else {
-					fmt.Println("Health: DEGRADED")
+					fmt.Println(i18n.T("Health: DEGRADED"))
 				}
 				for _, comp := range health.Components {
 					checkmark := "+"
@@ -1363,7 +1705,7 @@ func statusCmd() *cobra.Command {
 
 				// Display recent errors if any
 				if health.ErrorCount > 0 {
-					fmt.Printf("Recent Errors (last 24h): %d\n", health.ErrorCount)
+					fmt.Println(i18n.T("Recent Errors (last 24h): %d", health.ErrorCount))
 					for _, e := range health.RecentErrors {
 						ago := time.Since(e.Timestamp).Round(time.Minute)
 						if e.JobID > 0 {
@@ -1391,7 +1733,7 @@ func statusCmd() *cobra.Command {
 			}
 
 			if len(jobsResp.Jobs) > 0 {
-				fmt.Println("Recent Jobs:")
+				fmt.Println(i18n.T("Recent Jobs:"))
 				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 				fmt.Fprintf(w, "  ID\tSHA\tRepo\tAgent\tStatus\tTime\n")
 				for _, j := range jobsResp.Jobs {
@@ -1408,8 +1750,12 @@ func statusCmd() *cobra.Command {
 					if status.MachineID != "" && j.SourceMachineID != "" && j.SourceMachineID != status.MachineID {
 						repoDisplay += " [remote]"
 					}
+					jobStatus := string(j.Status)
+					if j.Overdue && (j.Status == storage.JobStatusQueued || j.Status == storage.JobStatusRunning) {
+						jobStatus += " (overdue)"
+					}
 					fmt.Fprintf(w, "  %d\t%s\t%s\t%s\t%s\t%s\n",
-						j.ID, shortRef(j.GitRef), repoDisplay, j.Agent, j.Status, elapsed)
+						j.ID, shortRef(j.GitRef), repoDisplay, j.Agent, jobStatus, elapsed)
 				}
 				w.Flush()
 			}
@@ -1418,13 +1764,18 @@ func statusCmd() *cobra.Command {
 			if root, err := git.GetRepoRoot("."); err == nil {
 				if hookNeedsUpgrade(root) {
 					fmt.Println()
-					fmt.Println("Warning: post-commit hook is outdated -- run 'roborev init' to upgrade")
+					fmt.Println(i18n.T("Warning: post-commit hook is outdated -- run 'roborev init' to upgrade"))
 				}
 			}
 
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "refresh a live view until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "refresh interval for --watch")
+
+	return cmd
 }
 
 func listCmd() *cobra.Command {
@@ -1433,7 +1784,13 @@ func listCmd() *cobra.Command {
 		repoPath   string
 		limit      int
 		status     string
+		author     string
+		agent      string
+		since      string
+		until      string
 		jsonOutput bool
+		sources    []string
+		allSources bool
 	)
 
 	cmd := &cobra.Command{
@@ -1443,18 +1800,63 @@ func listCmd() *cobra.Command {
 
 By default, lists jobs for the current repo and branch.
 
+--source lists jobs from other roborev daemons, such as a team server,
+merged with a SOURCE column. A source is "local" (this machine's daemon),
+the name of a [[sources]] entry in ~/.roborev/config.toml, or name=URL.
+Other daemons are matched on the repo's identity (its remote URL) rather
+than its path. --all-sources queries the local daemon and every configured
+source.
+
 Examples:
   roborev list                        # Jobs for current repo/branch
   roborev list --json                 # Output as JSON
   roborev list --branch main          # Jobs for main branch
   roborev list --status done          # Only completed jobs
-  roborev list --limit 5              # Show at most 5 jobs`,
+  roborev list --author "Jane Doe"    # Commits by Jane under any alias
+  roborev list --agent codex --since 30d    # Codex jobs of the last 30 days
+  roborev list --since 2026-01-01 --until 2026-02-01
+  roborev list --limit 5              # Show at most 5 jobs
+  roborev list --all-sources          # Local daemon plus configured sources
+  roborev list --source local --source team=http://roborev.internal:7373`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureDaemon(); err != nil {
-				return fmt.Errorf("daemon not running: %w", err)
+			now := time.Now()
+			var sinceTime, untilTime time.Time
+			if since != "" {
+				t, err := parseSince(since, now)
+				if err != nil {
+					return err
+				}
+				sinceTime = t
+			}
+			if until != "" {
+				t, err := parseSince(until, now)
+				if err != nil {
+					return fmt.Errorf("invalid --until %q (use e.g. 30d, 12w, 36h or 2026-01-31)", until)
+				}
+				untilTime = t
 			}
 
-			addr := getDaemonAddr()
+			var jobSources []jobSource
+			if len(sources) > 0 || allSources {
+				cfg, err := config.LoadGlobal()
+				if err != nil {
+					return fmt.Errorf("load config: %w", err)
+				}
+				if jobSources, err = resolveJobSources(sources, allSources, cfg); err != nil {
+					return err
+				}
+			}
+			needLocal := len(jobSources) == 0
+			for _, src := range jobSources {
+				needLocal = needLocal || src.Local
+			}
+			var addr string
+			if needLocal {
+				if err := ensureDaemon(); err != nil {
+					return fmt.Errorf("daemon not running: %w", err)
+				}
+				addr = getDaemonAddr()
+			}
 
 			// Auto-resolve repo from cwd when not specified.
 			// Use worktree root for branch detection, main repo root for API queries
@@ -1493,26 +1895,47 @@ Examples:
 			if status != "" {
 				params.Set("status", status)
 			}
-			params.Set("limit", strconv.Itoa(limit))
-
-			client := &http.Client{Timeout: 5 * time.Second}
-			resp, err := client.Get(addr + "/api/jobs?" + params.Encode())
-			if err != nil {
-				return fmt.Errorf("failed to connect to daemon (is it running?)")
+			if author != "" {
+				params.Set("author", author)
 			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				body, _ := io.ReadAll(resp.Body)
-				return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+			if agent != "" {
+				params.Set("agent", agent)
 			}
+			if !sinceTime.IsZero() {
+				params.Set("since", sinceTime.UTC().Format(time.RFC3339))
+			}
+			if !untilTime.IsZero() {
+				params.Set("until", untilTime.UTC().Format(time.RFC3339))
+			}
+			params.Set("limit", strconv.Itoa(limit))
 
-			var jobsResp struct {
-				Jobs    []storage.ReviewJob `json:"jobs"`
-				HasMore bool                `json:"has_more"`
+			if len(jobSources) > 0 {
+				for i := range jobSources {
+					if jobSources[i].Local {
+						jobSources[i].Addr = addr
+					}
+				}
+				var identity string
+				if localRepoPath != "" {
+					identity = config.ResolveRepoIdentity(localRepoPath, nil)
+				}
+				jobs, hasMore, errs := fetchSourcedJobs(jobSources, params, identity, limit)
+				for _, err := range errs {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+				if len(errs) == len(jobSources) {
+					return fmt.Errorf("no source could be listed")
+				}
+				return printSourcedJobs(jobs, hasMore, jsonOutput)
 			}
-			if err := json.NewDecoder(resp.Body).Decode(&jobsResp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+
+			client := &http.Client{Timeout: 5 * time.Second}
+			jobsResp, err := fetchJobs(client, addr, params)
+			if err != nil {
+				if isTransportError(err) {
+					return fmt.Errorf("failed to connect to daemon (is it running?)")
+				}
+				return err
 			}
 
 			if jsonOutput {
@@ -1526,19 +1949,15 @@ Examples:
 				return nil
 			}
 
+			quickTakes := false
+			for _, j := range jobsResp.Jobs {
+				quickTakes = quickTakes || j.QuickTake != ""
+			}
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintf(w, "ID\tSHA\tRepo\tAgent\tStatus\tTime\n")
+			fmt.Fprintf(w, "ID\tSHA\tRepo\tAgent\tStatus\tTime%s\n", quickTakeColumn(quickTakes, "Quick Take"))
 			for _, j := range jobsResp.Jobs {
-				elapsed := ""
-				if j.StartedAt != nil {
-					if j.FinishedAt != nil {
-						elapsed = j.FinishedAt.Sub(*j.StartedAt).Round(time.Second).String()
-					} else {
-						elapsed = time.Since(*j.StartedAt).Round(time.Second).String() + "..."
-					}
-				}
-				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
-					j.ID, shortRef(j.GitRef), j.RepoName, j.Agent, j.Status, elapsed)
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s%s\n",
+					j.ID, shortRef(j.GitRef), j.RepoName, j.Agent, j.Status, jobElapsed(j), quickTakeColumn(quickTakes, j.QuickTake))
 			}
 			w.Flush()
 
@@ -1554,13 +1973,79 @@ Examples:
 	cmd.Flags().StringVar(&repoPath, "repo", "", "filter by repo path (default: current repo)")
 	cmd.Flags().IntVar(&limit, "limit", 50, "max number of jobs to return")
 	cmd.Flags().StringVar(&status, "status", "", "filter by status (queued, running, done, failed)")
+	cmd.Flags().StringVar(&author, "author", "", "filter by commit author, matching their aliases ('roborev author alias')")
+	cmd.Flags().StringVar(&agent, "agent", "", "filter by the agent jobs were enqueued for")
+	cmd.Flags().StringVar(&since, "since", "", "only jobs enqueued since, e.g. 30d, 12w or 2026-01-31")
+	cmd.Flags().StringVar(&until, "until", "", "only jobs enqueued before, e.g. 7d or 2026-02-01")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	cmd.Flags().StringArrayVar(&sources, "source", nil, "daemon to list jobs from: local, a configured source name, or name=URL (repeatable)")
+	cmd.Flags().BoolVar(&allSources, "all-sources", false, "list jobs from the local daemon and every configured source")
 	return cmd
 }
 
+// jobElapsed returns how long a job ran, or has been running so far
+func jobElapsed(j storage.ReviewJob) string {
+	if j.StartedAt == nil {
+		return ""
+	}
+	if j.FinishedAt != nil {
+		return j.FinishedAt.Sub(*j.StartedAt).Round(time.Second).String()
+	}
+	return time.Since(*j.StartedAt).Round(time.Second).String() + "..."
+}
+
+// maxListQuickTakeLen is the most of a quick take 'roborev list' shows
+const maxListQuickTakeLen = 100
+
+// quickTakeColumn returns the last cell of a job listing row: the quick
+// take, cut to maxListQuickTakeLen, when any listed job has one
+func quickTakeColumn(show bool, quickTake string) string {
+	if !show {
+		return ""
+	}
+	return "\t" + truncateString(quickTake, maxListQuickTakeLen)
+}
+
+// printSourcedJobs prints jobs merged from several sources, with the
+// source of each
+func printSourcedJobs(jobs []sourcedJob, hasMore, jsonOutput bool) error {
+	if jsonOutput {
+		if jobs == nil {
+			jobs = []sourcedJob{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jobs)
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No jobs found.")
+		return nil
+	}
+
+	quickTakes := false
+	for _, j := range jobs {
+		quickTakes = quickTakes || j.QuickTake != ""
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Source\tID\tSHA\tRepo\tAgent\tStatus\tTime%s\n", quickTakeColumn(quickTakes, "Quick Take"))
+	for _, j := range jobs {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s%s\n",
+			j.Source, j.ID, shortRef(j.GitRef), j.RepoName, j.Agent, j.Status, jobElapsed(j.ReviewJob), quickTakeColumn(quickTakes, j.QuickTake))
+	}
+	w.Flush()
+
+	if hasMore {
+		fmt.Println("(more results available, use --limit to increase)")
+	}
+	return nil
+}
+
 func showCmd() *cobra.Command {
 	var forceJobID bool
 	var showPrompt bool
+	var showEnv bool
+	var showPreReview bool
 	var jsonOutput bool
 
 	cmd := &cobra.Command{
@@ -1579,7 +2064,9 @@ Examples:
   roborev show abc123       # Show review for commit
   roborev show 42           # Job ID (if "42" is not a valid git ref)
   roborev show --job 42     # Force as job ID even if "42" is a valid ref
-  roborev show --prompt 42  # Show the prompt sent to the agent`,
+  roborev show --prompt 42  # Show the prompt sent to the agent
+  roborev show --env 42     # Show roborev/agent versions, model, OS and template hash
+  roborev show --pre-review 42  # Show the quick review run at commit time`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Ensure daemon is running (and restart if version mismatch)
@@ -1614,10 +2101,15 @@ Examples:
 				if forceJobID {
 					isJobID = true
 				} else {
-					// Try to resolve as SHA first (handles numeric SHAs like "123456")
+					// Try to resolve as SHA first (handles numeric SHAs like "123456"),
+					// then as a jj change ID
 					if root, err := git.GetRepoRoot("."); err == nil {
 						if resolved, err := git.ResolveSHA(root, arg); err == nil {
 							resolvedSHA = resolved
+						} else if jj.Root(root) != "" {
+							if rev, err := jj.Resolve(root, arg); err == nil {
+								resolvedSHA = rev.CommitID
+							}
 						}
 					}
 					// If not resolvable as SHA and is numeric, treat as job ID
@@ -1668,8 +2160,40 @@ Examples:
 			} else {
 				fmt.Printf("Review for %s (job %d, by %s)\n", displayRef, review.JobID, review.Agent)
 			}
+			if review.Job != nil && review.Job.Focus {
+				fmt.Println("Thorough review (--thorough)")
+			}
+			if review.Job != nil && review.Job.Protected {
+				fmt.Println("Commit is on a protected branch")
+			}
+			if review.Job != nil && review.Job.TruncationLevel > 0 {
+				fmt.Printf("Prompt truncated to fit the agent's context (level %d)\n", review.Job.TruncationLevel)
+			}
+			if review.CanonicalSHA != "" {
+				fmt.Printf("Canonical review of %s, which has the same patch\n", shortSHA(review.CanonicalSHA))
+			}
+			if risk := review.InjectionRisk; risk != nil {
+				fmt.Printf("Warning: the diff may contain a prompt injection (%s)\n", strings.Join(risk.Patterns, ", "))
+				if risk.OutputNote != "" {
+					fmt.Printf("  %s; verify this review by hand\n", risk.OutputNote)
+				}
+			}
+			if pre := review.PreReview; pre != nil && !showPreReview {
+				fmt.Printf("Pre-review at commit time by %s: %s (--pre-review to show it)\n", pre.Agent, pre.Status)
+			}
 			fmt.Println(strings.Repeat("-", 60))
-			if showPrompt {
+			if showPreReview {
+				if pre := review.PreReview; pre == nil {
+					fmt.Println("No pre-review was run for this job")
+				} else {
+					printPreReview(cmd.OutOrStdout(), *pre)
+				}
+			} else if showEnv {
+				printJobEnv(cmd.OutOrStdout(), review.Env)
+				if u := review.Usage; u != nil {
+					fmt.Printf("Tokens:        %d prompt, %d completion\n", u.PromptTokens, u.CompletionTokens)
+				}
+			} else if showPrompt {
 				fmt.Println(review.Prompt)
 			} else {
 				fmt.Println(review.Output)
@@ -1681,10 +2205,37 @@ Examples:
 
 	cmd.Flags().BoolVar(&forceJobID, "job", false, "force argument to be treated as job ID")
 	cmd.Flags().BoolVar(&showPrompt, "prompt", false, "show the prompt sent to the agent instead of the review output")
+	cmd.Flags().BoolVar(&showEnv, "env", false, "show the environment the review ran in instead of the review output")
+	cmd.Flags().BoolVar(&showPreReview, "pre-review", false, "show the pre-review run at commit time instead of the review output")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
 	return cmd
 }
 
+// printJobEnv writes the environment a review ran in, one field per line
+func printJobEnv(w io.Writer, env *storage.JobEnv) {
+	if env == nil {
+		fmt.Fprintln(w, "No environment recorded (job ran before environment capture was added)")
+		return
+	}
+	orNone := func(s string) string {
+		if s == "" {
+			return "(unknown)"
+		}
+		return s
+	}
+	model := env.Model
+	if model == "" {
+		model = "(agent default)"
+	}
+	fmt.Fprintf(w, "roborev:       %s\n", env.RoborevVersion)
+	fmt.Fprintf(w, "Agent:         %s\n", env.Agent)
+	fmt.Fprintf(w, "Agent version: %s\n", orNone(env.AgentVersion))
+	fmt.Fprintf(w, "Model:         %s\n", model)
+	fmt.Fprintf(w, "OS:            %s\n", env.OS)
+	fmt.Fprintf(w, "Prompt hash:   %s\n", orNone(env.PromptHash))
+	fmt.Fprintf(w, "Captured:      %s\n", env.CapturedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
 func commentCmd() *cobra.Command {
 	var (
 		commenter  string
@@ -1753,31 +2304,11 @@ Examples:
 
 			// If no message provided, open editor
 			if message == "" {
-				editor := os.Getenv("EDITOR")
-				if editor == "" {
-					editor = "vim"
-				}
-
-				tmpfile, err := os.CreateTemp("", "roborev-comment-*.md")
-				if err != nil {
-					return fmt.Errorf("create temp file: %w", err)
-				}
-				tmpfile.Close()
-				defer os.Remove(tmpfile.Name())
-
-				editorCmd := exec.Command(editor, tmpfile.Name())
-				editorCmd.Stdin = os.Stdin
-				editorCmd.Stdout = os.Stdout
-				editorCmd.Stderr = os.Stderr
-				if err := editorCmd.Run(); err != nil {
-					return fmt.Errorf("editor failed: %w", err)
-				}
-
-				content, err := os.ReadFile(tmpfile.Name())
+				edited, err := editComment("")
 				if err != nil {
-					return fmt.Errorf("read comment: %w", err)
+					return err
 				}
-				message = strings.TrimSpace(string(content))
+				message = edited
 			}
 
 			if message == "" {
@@ -1829,6 +2360,40 @@ Examples:
 }
 
 // respondCmd returns an alias for commentCmd
+// editComment opens $EDITOR (default vim) on initial and returns the
+// edited text, trimmed
+func editComment(initial string) (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+
+	tmpfile, err := os.CreateTemp("", "roborev-comment-*.md")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	_, err = tmpfile.WriteString(initial)
+	tmpfile.Close()
+	defer os.Remove(tmpfile.Name())
+	if err != nil {
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+
+	editorCmd := exec.Command(editor, tmpfile.Name())
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	content, err := os.ReadFile(tmpfile.Name())
+	if err != nil {
+		return "", fmt.Errorf("read comment: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
 func respondCmd() *cobra.Command {
 	cmd := commentCmd()
 	cmd.Use = "respond <job_id|sha> [message]"
@@ -1972,6 +2537,7 @@ func waitForReview(jobID int64) (*storage.Review, error) {
 }
 
 func waitForReviewWithInterval(jobID int64, pollInterval time.Duration) (*storage.Review, error) {
+	defer cliprof.Span(cliprof.PhaseAgent)()
 	addr := getDaemonAddr()
 	client := &http.Client{Timeout: 10 * time.Second}
 
@@ -2020,6 +2586,12 @@ func waitForReviewWithInterval(jobID int64, pollInterval time.Duration) (*storag
 
 		case storage.JobStatusCanceled:
 			return nil, fmt.Errorf("job was canceled")
+
+		case storage.JobStatusBlocked:
+			return nil, fmt.Errorf("job is blocked: %s", job.Error)
+
+		case storage.JobStatusSkipped:
+			return nil, fmt.Errorf("job was skipped: %s", job.Error)
 		}
 
 		time.Sleep(pollInterval)
@@ -2170,6 +2742,7 @@ Examples:
 
 func installHookCmd() *cobra.Command {
 	var force bool
+	var preReview bool
 
 	cmd := &cobra.Command{
 		Use:   "install-hook",
@@ -2196,7 +2769,7 @@ func installHookCmd() *cobra.Command {
 				return fmt.Errorf("create hooks directory: %w", err)
 			}
 
-			hookContent := generateHookContent()
+			hookContent := generateHookContent(preReview)
 
 			if err := os.WriteFile(hookPath, []byte(hookContent), 0755); err != nil {
 				return fmt.Errorf("write hook: %w", err)
@@ -2208,6 +2781,7 @@ func installHookCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing hook")
+	cmd.Flags().BoolVar(&preReview, "pre-review", false, "run a quick inline pre-review on each commit (see [pre_review])")
 
 	return cmd
 }
@@ -2453,7 +3027,7 @@ This command is idempotent - running it multiple times is safe.`,
 		Long: `Update roborev skills only for agents that already have them installed.
 
 Unlike 'install', this command does NOT install skills for new agents -
-it only updates existing installations. Used by 'roborev update'.`,
+it only updates existing installations. Used by 'roborev self-update'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			results, err := skills.Update()
 			if err != nil {
@@ -2484,183 +3058,40 @@ it only updates existing installations. Used by 'roborev update'.`,
 	return cmd
 }
 
-func updateCmd() *cobra.Command {
-	var checkOnly bool
-	var yes bool
-	var force bool
+func syncCmd() *cobra.Command {
+	var (
+		from string
+		full bool
+	)
 
 	cmd := &cobra.Command{
-		Use:   "update",
-		Short: "Update roborev to the latest version",
-		Long: `Check for and install roborev updates.
+		Use:   "sync",
+		Short: "Manage PostgreSQL sync, or merge from another roborev instance",
+		Long: `Commands for managing synchronization with a PostgreSQL database.
 
-Shows exactly what will be downloaded and where it will be installed.
-Requires confirmation before making changes (use --yes to skip).
+With --from, merge finished jobs, reviews, and comments from another roborev
+instance into this one. The source is either a roborev database file or the
+URL of a running daemon (which must be reachable, e.g. via server_addr or an
+SSH tunnel). Only changes since the previous merge from the same source are
+transferred. When a review exists on both sides, the most recently updated
+copy wins; originating machine IDs are preserved. Run it in both directions
+to converge two machines.
 
-Dev builds are not replaced by default. Use --force to install the latest
-official release over a dev build.`,
+Examples:
+  roborev sync --from /mnt/desktop/.roborev/reviews.db
+  roborev sync --from http://desktop.local:7373
+  roborev sync --from http://localhost:17373 --full`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Checking for updates...")
-
-			info, err := update.CheckForUpdate(true) // Force check, ignore cache
-			if err != nil {
-				return fmt.Errorf("check for updates: %w", err)
-			}
-
-			if info == nil {
-				fmt.Printf("Already running latest version (%s)\n", version.Version)
-				return nil
-			}
-
-			fmt.Printf("\n  Current version: %s\n", info.CurrentVersion)
-			fmt.Printf("  Latest version:  %s\n", info.LatestVersion)
-			if info.IsDevBuild {
-				fmt.Println("\nYou're running a dev build. Latest official release available.")
-			} else {
-				fmt.Println("\nUpdate available!")
-			}
-			fmt.Println("\nDownload:")
-			fmt.Printf("  URL:  %s\n", info.DownloadURL)
-			fmt.Printf("  Size: %s\n", update.FormatSize(info.Size))
-			if info.Checksum != "" {
-				fmt.Printf("  SHA256: %s\n", info.Checksum)
-			}
-
-			// Show install location
-			currentExe, err := os.Executable()
-			if err != nil {
-				return fmt.Errorf("find executable: %w", err)
-			}
-			currentExe, _ = filepath.EvalSymlinks(currentExe)
-			binDir := filepath.Dir(currentExe)
-
-			fmt.Println("\nInstall location:")
-			fmt.Printf("  %s\n", binDir)
-
-			if checkOnly {
-				if info.IsDevBuild {
-					fmt.Println("\nUse --force to install the latest official release.")
-				}
-				return nil
-			}
-
-			// Dev builds require --force to update
-			if info.IsDevBuild && !force {
-				fmt.Println("\nUse --force to install the latest official release.")
-				return nil
-			}
-
-			// Confirm
-			if !yes {
-				fmt.Print("\nProceed with update? [y/N] ")
-				var response string
-				fmt.Scanln(&response)
-				if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
-					fmt.Println("Update cancelled")
-					return nil
-				}
-			}
-
-			fmt.Println()
-
-			// Progress display
-			var lastPercent int
-			progressFn := func(downloaded, total int64) {
-				if total > 0 {
-					percent := int(downloaded * 100 / total)
-					if percent != lastPercent {
-						fmt.Printf("\rDownloading... %d%% (%s / %s)",
-							percent, update.FormatSize(downloaded), update.FormatSize(total))
-						lastPercent = percent
-					}
-				}
-			}
-
-			// Perform update
-			if err := update.PerformUpdate(info, progressFn); err != nil {
-				return fmt.Errorf("update failed: %w", err)
+			if from == "" {
+				return cmd.Help()
 			}
-
-			fmt.Printf("\nUpdated to %s\n", info.LatestVersion)
-
-			// Clean up old roborevd binary if it exists (consolidated into roborev)
-			oldDaemonPath := filepath.Join(binDir, "roborevd")
-			if runtime.GOOS == "windows" {
-				oldDaemonPath += ".exe"
-			}
-			if _, err := os.Stat(oldDaemonPath); err == nil {
-				fmt.Print("Removing old roborevd binary... ")
-				if err := os.Remove(oldDaemonPath); err != nil {
-					fmt.Printf("warning: %v\n", err)
-				} else {
-					fmt.Println("OK")
-				}
-			}
-
-			// Restart daemon if any are running
-			if runtimes, err := daemon.ListAllRuntimes(); err == nil && len(runtimes) > 0 {
-				fmt.Print("Restarting daemon... ")
-				// Stop all running daemons
-				_ = stopDaemon()
-				// Kill any orphaned daemon processes
-				killAllDaemons()
-
-				// Start new daemon using "roborev daemon run"
-				newBinary := filepath.Join(binDir, "roborev")
-				if runtime.GOOS == "windows" {
-					newBinary += ".exe"
-				}
-				startCmd := exec.Command(newBinary, "daemon", "run")
-				startCmd.Env = filterGitEnv(os.Environ())
-				if err := startCmd.Start(); err != nil {
-					fmt.Printf("warning: failed to start daemon: %v\n", err)
-				} else {
-					fmt.Println("OK")
-				}
-			}
-
-			// Update skills using the NEW binary (current process has old embedded skills)
-			// Use "skills update" to only update agents that already have skills installed
-			if skills.IsInstalled(skills.AgentClaude) || skills.IsInstalled(skills.AgentCodex) {
-				fmt.Print("Updating skills... ")
-				newBinary := filepath.Join(binDir, "roborev")
-				if runtime.GOOS == "windows" {
-					newBinary += ".exe"
-				}
-				skillsCmd := exec.Command(newBinary, "skills", "update")
-				if output, err := skillsCmd.CombinedOutput(); err != nil {
-					fmt.Printf("warning: %v\n", err)
-				} else {
-					// Parse output to show what was updated
-					lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-					for _, line := range lines {
-						if strings.Contains(line, "updated") {
-							fmt.Println(line)
-						}
-					}
-					if !strings.Contains(string(output), "updated") {
-						fmt.Println("OK")
-					}
-				}
-			}
-
-			return nil
+			return syncFromPeer(cmd.Context(), from, full)
 		},
 	}
 
-	cmd.Flags().BoolVar(&checkOnly, "check", false, "only check for updates, don't install")
-	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip confirmation prompt")
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "replace dev build with latest official release")
-
-	return cmd
-}
-
-func syncCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "sync",
-		Short: "Manage PostgreSQL sync",
-		Long:  "Commands for managing synchronization with a PostgreSQL database.",
-	}
+	cmd.Flags().StringVar(&from, "from", "", "merge from another roborev database file or daemon URL")
+	cmd.Flags().BoolVar(&full, "full", false, "with --from, ignore the saved cursor and merge everything")
 
 	cmd.AddCommand(syncStatusCmd())
 	cmd.AddCommand(syncNowCmd())
@@ -2716,9 +3147,9 @@ func syncStatusCmd() *cobra.Command {
 
 			// Count pending items
 			const maxPending = 1000
-			jobs, jobsErr := db.GetJobsToSync(machineID, maxPending)
-			reviews, reviewsErr := db.GetReviewsToSync(machineID, maxPending)
-			responses, responsesErr := db.GetCommentsToSync(machineID, maxPending)
+			jobs, jobsErr := db.GetJobsToSync(cmd.Context(), machineID, maxPending)
+			reviews, reviewsErr := db.GetReviewsToSync(cmd.Context(), machineID, maxPending)
+			responses, responsesErr := db.GetCommentsToSync(cmd.Context(), machineID, maxPending)
 
 			fmt.Println()
 			if jobsErr != nil || reviewsErr != nil || responsesErr != nil {
@@ -2930,16 +3361,24 @@ Examples:
 				}
 
 				if !agent.IsAvailable(name) {
-					fmt.Printf("  - %-14s %s (not found in PATH)\n", name, cmdName)
+					if cmdName == "" {
+						fmt.Printf("  - %-14s (not configured)\n", name)
+					} else {
+						fmt.Printf("  - %-14s %s (not found in PATH)\n", name, cmdName)
+					}
 					skipped++
 					continue
 				}
 
-				path, _ := exec.LookPath(cmdName)
-				fmt.Printf("  ? %-14s %s (%s) ... ", name, cmdName, path)
+				if cmdName == "" {
+					fmt.Printf("  ? %-14s %s ... ", name, a.CommandLine())
+				} else {
+					path, _ := exec.LookPath(cmdName)
+					fmt.Printf("  ? %-14s %s (%s) ... ", name, cmdName, path)
+				}
 
 				ctx, cancel := context.WithTimeout(context.Background(), timeout)
-				result, err := a.Review(ctx, repoPath, "HEAD", smokePrompt, nil)
+				result, err := runAgent(ctx, a, repoPath, "HEAD", smokePrompt, nil)
 				cancel()
 
 				if err != nil {
@@ -2994,6 +3433,13 @@ func shortSHA(sha string) string {
 }
 
 func shortRef(ref string) string {
+	// For patch files, show "patch:<name>" without the content hash
+	if storage.IsPatchRef(ref) {
+		if i := strings.LastIndex(ref, "@"); i > 0 {
+			return ref[:i]
+		}
+		return ref
+	}
 	// For ranges like "abc123..def456", show as "abc123..def456" (up to 17 chars)
 	// For single SHAs, truncate to 7 chars
 	if strings.Contains(ref, "..") {
@@ -3059,7 +3505,9 @@ func hookNeedsUpgrade(repoPath string) bool {
 	return strings.Contains(strings.ToLower(s), "roborev") && !strings.Contains(s, hookVersionMarker)
 }
 
-func generateHookContent() string {
+// generateHookContent returns the post-commit hook script. With preReview,
+// the hook runs a pre-review inline before the full review is queued.
+func generateHookContent(preReview bool) string {
 	// Get path to the currently running binary (not just first in PATH)
 	roborevPath, err := os.Executable()
 	if err == nil {
@@ -3075,6 +3523,11 @@ func generateHookContent() string {
 		}
 	}
 
+	preReviewArg := ""
+	if preReview {
+		preReviewArg = " --pre-review"
+	}
+
 	// Prefer baked path (security), fall back to PATH only if baked is missing
 	return fmt.Sprintf(`#!/bin/sh
 # roborev post-commit hook v2 - auto-reviews every commit
@@ -3083,6 +3536,6 @@ if [ ! -x "$ROBOREV" ]; then
     ROBOREV=$(command -v roborev 2>/dev/null)
     [ -z "$ROBOREV" ] || [ ! -x "$ROBOREV" ] && exit 0
 fi
-"$ROBOREV" enqueue --quiet 2>/dev/null
-`, roborevPath)
+"$ROBOREV" enqueue --quiet%s 2>/dev/null
+`, roborevPath, preReviewArg)
 }
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// planMinRepoHistory is how many completed reviews of an agent a repo needs
+// before its own history is used instead of the history of all repos
+const planMinRepoHistory = 5
+
+// planBytesPerToken approximates prompt tokens from prompt bytes
+const planBytesPerToken = 4
+
+// planEstimate is the projected effort of reviewing a set of commits with
+// one agent
+type planEstimate struct {
+	Agent    string
+	Basis    string // "this repo" or "all repos"
+	History  int    // Completed reviews the estimate is based on
+	Seconds  float64
+	Tokens   int64
+	Cost     float64
+	HasPrice bool
+}
+
+func planCmd() *cobra.Command {
+	var (
+		repoPath        string
+		branch          string
+		since           string
+		agents          []string
+		prices          map[string]string
+		includeReviewed bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Estimate the time and cost of reviewing past commits",
+		Long: `Estimate how long a backfill review of past commits would take with each
+agent, without enqueueing anything.
+
+Commits are the non-merge commits on the branch made within --since.
+Commits that already have a review, or were skipped by a skip marker or
+bot author rule, are left out unless --include-reviewed is set. Durations are projected from each agent's completed reviews,
+scaled by the size of each commit's diff; the repo's own history is used
+once it has enough reviews, otherwise the history of all repos. Token
+counts are estimated from prompt size. roborev does not record agent
+billing, so costs are only shown for agents given a --price in USD per
+million input tokens.
+
+--since accepts days (90d), weeks (12w), hours (36h) or a date (2026-01-31).
+
+Examples:
+  roborev plan --since 90d
+  roborev plan --since 2w --agent codex --agent claude-code
+  roborev plan --since 30d --price claude-code=3 --price codex=1.25`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			priceMap := make(map[string]float64, len(prices))
+			for name, v := range prices {
+				p, err := strconv.ParseFloat(v, 64)
+				if err != nil || p < 0 {
+					return fmt.Errorf("invalid --price for %s: %q", name, v)
+				}
+				priceMap[name] = p
+			}
+
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			if branch == "" {
+				branch = "HEAD"
+			}
+			commits, err := git.GetCommitsAfter(root, branch, sinceTime)
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			var repoStats []storage.AgentReviewStats
+			var reviewed, skipped int
+			repo, err := db.GetRepoByPath(cmd.Context(), root)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				repo = nil
+			case err != nil:
+				return fmt.Errorf("look up repo: %w", err)
+			}
+			if repo != nil {
+				if !includeReviewed {
+					verdicts, err := db.GetCommitVerdicts(repo.ID, commits)
+					if err != nil {
+						return fmt.Errorf("check reviewed commits: %w", err)
+					}
+					var pending []string
+					for _, sha := range commits {
+						v, ok := verdicts[sha]
+						switch {
+						case !ok:
+							pending = append(pending, sha)
+						case v.Skipped:
+							skipped++
+						default:
+							reviewed++
+						}
+					}
+					commits = pending
+				}
+				if repoStats, err = db.GetAgentReviewStats(repo.ID); err != nil {
+					return fmt.Errorf("load review history: %w", err)
+				}
+			}
+			allStats, err := db.GetAgentReviewStats(0)
+			if err != nil {
+				return fmt.Errorf("load review history: %w", err)
+			}
+
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				cfg = config.DefaultConfig()
+			}
+			maxPromptSize := config.ResolveMaxPromptSize(root, cfg)
+
+			diffSizes := make([]int, 0, len(commits))
+			for _, sha := range commits {
+				diff, err := git.GetDiff(root, sha)
+				if err != nil {
+					return err
+				}
+				diffSizes = append(diffSizes, min(len(diff), maxPromptSize))
+			}
+
+			if len(agents) == 0 {
+				for _, st := range allStats {
+					agents = append(agents, st.Agent)
+				}
+			}
+			var estimates []planEstimate
+			for _, name := range agents {
+				overhead := len(prompt.GetSystemPrompt(name, prompt.SystemPromptType("review", "")))
+				price, hasPrice := priceMap[name]
+				est, ok := estimatePlan(name, repoStats, allStats, diffSizes, overhead, price, hasPrice)
+				if !ok {
+					est = planEstimate{Agent: name}
+				}
+				estimates = append(estimates, est)
+			}
+
+			printPlan(cmd.OutOrStdout(), sinceTime, diffSizes, reviewed, skipped, estimates, cfg.MaxWorkers)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&branch, "branch", "", "branch to plan for (default: current HEAD)")
+	cmd.Flags().StringVar(&since, "since", "", "how far back to go, e.g. 90d, 12w or 2026-01-31 (required)")
+	cmd.Flags().StringArrayVar(&agents, "agent", nil, "agent to estimate (repeatable; default: every agent with history)")
+	cmd.Flags().StringToStringVar(&prices, "price", nil, "agent=USD per million input tokens, used to estimate cost")
+	cmd.Flags().BoolVar(&includeReviewed, "include-reviewed", false, "include commits that already have a review")
+	_ = cmd.MarkFlagRequired("since")
+	return cmd
+}
+
+// parseSince converts a --since value to a point in time. Accepts a number
+// of days or weeks (90d, 2w), a Go duration (36h) or a date (2006-01-02).
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v > 0 {
+				return now.Add(-time.Duration(v) * unit), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 90d, 12w, 36h or 2026-01-31)", s)
+}
+
+// estimatePlan projects the time, tokens and cost of reviewing commits with
+// the given diff sizes. Each prompt is estimated as the diff plus overhead
+// bytes of instructions. Durations scale with prompt size at the agent's
+// historical rate, falling back to its average duration per review when no
+// prompt sizes are known. Returns false if the agent has no history.
+func estimatePlan(agentName string, repoStats, allStats []storage.AgentReviewStats, diffSizes []int, overhead int, price float64, hasPrice bool) (planEstimate, bool) {
+	st, basis := findAgentStats(repoStats, agentName), "this repo"
+	if st == nil || st.Jobs < planMinRepoHistory {
+		st, basis = findAgentStats(allStats, agentName), "all repos"
+	}
+	if st == nil || st.Jobs == 0 {
+		return planEstimate{}, false
+	}
+
+	var promptBytes int64
+	for _, size := range diffSizes {
+		promptBytes += int64(size + overhead)
+	}
+
+	est := planEstimate{Agent: agentName, Basis: basis, History: st.Jobs, HasPrice: hasPrice}
+	if st.SizedJobs > 0 && st.PromptBytes > 0 {
+		est.Seconds = float64(promptBytes) * st.SizedSeconds / float64(st.PromptBytes)
+	} else {
+		est.Seconds = float64(len(diffSizes)) * st.TotalSeconds / float64(st.Jobs)
+	}
+	est.Tokens = promptBytes / planBytesPerToken
+	if hasPrice {
+		est.Cost = float64(est.Tokens) / 1e6 * price
+	}
+	return est, true
+}
+
+func findAgentStats(stats []storage.AgentReviewStats, agentName string) *storage.AgentReviewStats {
+	for i := range stats {
+		if stats[i].Agent == agentName {
+			return &stats[i]
+		}
+	}
+	return nil
+}
+
+func printPlan(w io.Writer, since time.Time, diffSizes []int, reviewed, skipped int, estimates []planEstimate, workers int) {
+	total := 0
+	for _, size := range diffSizes {
+		total += size
+	}
+	fmt.Fprintf(w, "%d commit(s) to review since %s, %s of diffs", len(diffSizes), since.Format("2006-01-02"), formatPlanBytes(total))
+	switch {
+	case reviewed > 0 && skipped > 0:
+		fmt.Fprintf(w, " (%d already reviewed, %d skipped)", reviewed, skipped)
+	case reviewed > 0:
+		fmt.Fprintf(w, " (%d already reviewed)", reviewed)
+	case skipped > 0:
+		fmt.Fprintf(w, " (%d skipped)", skipped)
+	}
+	fmt.Fprintln(w)
+	if len(diffSizes) == 0 {
+		return
+	}
+	if len(estimates) == 0 {
+		fmt.Fprintln(w, "\nNo completed reviews yet, so durations cannot be estimated.")
+		return
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "AGENT\tHISTORY\tAGENT TIME\tWALL TIME (%d workers)\tINPUT TOKENS\tCOST\n", workers)
+	for _, est := range estimates {
+		if est.History == 0 {
+			fmt.Fprintf(tw, "%s\tnone\t-\t-\t-\t-\n", est.Agent)
+			continue
+		}
+		cost := "-"
+		if est.HasPrice {
+			cost = fmt.Sprintf("$%.2f", est.Cost)
+		}
+		wall := est.Seconds / float64(min(workers, len(diffSizes)))
+		fmt.Fprintf(tw, "%s\t%d reviews (%s)\t%s\t%s\t%s\t%s\n", est.Agent, est.History, est.Basis,
+			formatPlanDuration(est.Seconds), formatPlanDuration(wall), formatPlanTokens(est.Tokens), cost)
+	}
+	tw.Flush()
+}
+
+func formatPlanDuration(seconds float64) string {
+	d := time.Duration(math.Round(seconds)) * time.Second
+	if d >= time.Hour {
+		d = d.Round(time.Minute)
+	}
+	return d.String()
+}
+
+func formatPlanBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func formatPlanTokens(n int64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.0fk", float64(n)/1e3)
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
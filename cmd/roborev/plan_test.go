@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "90d", want: now.AddDate(0, 0, -90)},
+		{in: "2w", want: now.AddDate(0, 0, -14)},
+		{in: "36h", want: now.Add(-36 * time.Hour)},
+		{in: "2026-01-31", want: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "0d", wantErr: true},
+		{in: "-5d", wantErr: true},
+		{in: "soon", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSince(tt.in, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimatePlan(t *testing.T) {
+	allStats := []storage.AgentReviewStats{
+		// 1 second per 100 prompt bytes
+		{Agent: "codex", Jobs: 20, TotalSeconds: 2000, SizedJobs: 10, PromptBytes: 100000, SizedSeconds: 1000},
+		// No prompt sizes known: 60 seconds per review
+		{Agent: "gemini", Jobs: 3, TotalSeconds: 180},
+	}
+	repoStats := []storage.AgentReviewStats{
+		// Too little history in this repo to be used
+		{Agent: "codex", Jobs: 2, TotalSeconds: 10, SizedJobs: 2, PromptBytes: 100000, SizedSeconds: 10},
+	}
+	diffSizes := []int{1500, 3500}
+
+	est, ok := estimatePlan("codex", repoStats, allStats, diffSizes, 500, 2, true)
+	if !ok {
+		t.Fatal("expected estimate for codex")
+	}
+	if est.Basis != "all repos" || est.History != 20 {
+		t.Errorf("unexpected basis: %+v", est)
+	}
+	if est.Seconds != 60 {
+		t.Errorf("Seconds = %v, want 60", est.Seconds)
+	}
+	if est.Tokens != 1500 || est.Cost != 0.003 {
+		t.Errorf("Tokens = %d, Cost = %v", est.Tokens, est.Cost)
+	}
+
+	est, ok = estimatePlan("gemini", nil, allStats, diffSizes, 500, 0, false)
+	if !ok || est.Seconds != 120 || est.HasPrice {
+		t.Errorf("gemini: got %+v, %v", est, ok)
+	}
+
+	repoStats[0].Jobs = planMinRepoHistory
+	est, _ = estimatePlan("codex", repoStats, allStats, diffSizes, 500, 0, false)
+	if est.Basis != "this repo" || est.Seconds != 0.6 {
+		t.Errorf("repo history: got %+v", est)
+	}
+
+	if _, ok := estimatePlan("claude-code", repoStats, allStats, diffSizes, 500, 0, false); ok {
+		t.Error("expected no estimate without history")
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	estimates := []planEstimate{
+		{Agent: "codex", Basis: "this repo", History: 12, Seconds: 7200, Tokens: 250000, Cost: 0.5, HasPrice: true},
+		{Agent: "claude-code"},
+	}
+
+	var buf bytes.Buffer
+	printPlan(&buf, since, []int{2048, 2048, 2048, 2048}, 3, 2, estimates, 4)
+	out := buf.String()
+	for _, want := range []string{
+		"4 commit(s) to review since 2026-01-01, 8.0 KB of diffs (3 already reviewed, 2 skipped)",
+		"WALL TIME (4 workers)",
+		"12 reviews (this repo)",
+		"2h0m0s",
+		"30m0s",
+		"250k",
+		"$0.50",
+		"claude-code  none",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printPlan(&buf, since, []int{100}, 0, 0, nil, 4)
+	if !strings.Contains(buf.String(), "cannot be estimated") {
+		t.Errorf("expected no-history message:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// runPreReview runs the quick inline review of a commit (or of diffContent
+// for dirty reviews) configured by [pre_review], abandoning it when its
+// time budget runs out. The outcome is returned, never an error, so the
+// hook carries on with the full review whatever happens.
+func runPreReview(ctx context.Context, repoPath, gitRef, diffContent string, cfg *config.Config) storage.PreReview {
+	pc := config.ResolvePreReview(repoPath, cfg)
+	budget := pc.TimeoutDuration()
+	agentName := config.ResolveAgentForWorkflow(pc.Agent, repoPath, cfg, "review", "fast")
+	model := config.ResolveModelForWorkflow(pc.Model, repoPath, cfg, "review", "fast")
+	pre := storage.PreReview{Agent: agentName, Model: model}
+
+	start := time.Now()
+	fail := func(err error) storage.PreReview {
+		pre.Status = storage.PreReviewFailed
+		pre.Error = err.Error()
+		pre.ElapsedMS = time.Since(start).Milliseconds()
+		return pre
+	}
+
+	a, err := agent.GetAvailable(agentName)
+	if err != nil {
+		return fail(fmt.Errorf("get agent: %w", err))
+	}
+	a = a.WithReasoning(agent.ReasoningFast).WithModel(model)
+	pre.Agent = a.Name()
+
+	// Previous reviews are left out of the prompt to keep it small
+	var reviewPrompt string
+	if diffContent != "" {
+		reviewPrompt, err = prompt.NewBuilder(nil).BuildDirty(repoPath, diffContent, 0, 0, a.Name(), "")
+	} else {
+		reviewPrompt, err = prompt.NewBuilder(nil).Build(repoPath, gitRef, 0, 0, a.Name(), "")
+	}
+	if err != nil {
+		return fail(fmt.Errorf("build prompt: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	output, err := runAgent(ctx, a, repoPath, gitRef, reviewPrompt, nil)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		pre.Status = storage.PreReviewTimeout
+		pre.Error = fmt.Sprintf("no result within %s", budget)
+		pre.ElapsedMS = time.Since(start).Milliseconds()
+		return pre
+	}
+	if err != nil {
+		return fail(err)
+	}
+	pre.Status = storage.PreReviewDone
+	pre.Output = strings.TrimSpace(output)
+	pre.ElapsedMS = time.Since(start).Milliseconds()
+	return pre
+}
+
+// printPreReview writes the outcome of a pre-review, pointing at the job
+// doing the full review
+func printPreReview(w io.Writer, pre storage.PreReview) {
+	elapsed := (time.Duration(pre.ElapsedMS) * time.Millisecond).Round(100 * time.Millisecond)
+	switch pre.Status {
+	case storage.PreReviewDone:
+		fmt.Fprintf(w, "roborev pre-review (%s, %s):\n%s\n", pre.Agent, elapsed, pre.Output)
+	case storage.PreReviewTimeout:
+		fmt.Fprintf(w, "roborev pre-review: %s\n", pre.Error)
+	default:
+		fmt.Fprintf(w, "roborev pre-review failed: %s\n", pre.Error)
+	}
+	fmt.Fprintf(w, "Full review: job %d (roborev show --job %d)\n", pre.JobID, pre.JobID)
+}
+
+// linkPreReview stores a pre-review with the job doing the full review
+func linkPreReview(serverAddr string, pre storage.PreReview) error {
+	reqBody, _ := json.Marshal(pre)
+	resp, err := http.Post(serverAddr+"/api/job/pre-review", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("link pre-review failed: %s", body)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestRunPreReview(t *testing.T) {
+	repo := newTestGitRepo(t)
+	sha := repo.CommitFile("main.go", "package main\n", "initial commit")
+
+	cfg := config.DefaultConfig()
+	cfg.PreReview = config.PreReviewConfig{Agent: "test"}
+	pre := runPreReview(t.Context(), repo.Dir, sha, "", cfg)
+	if pre.Status != storage.PreReviewDone || pre.Agent != "test" || !strings.Contains(pre.Output, "This commit looks good") {
+		t.Errorf("unexpected pre-review %+v", pre)
+	}
+
+	// The test agent takes 100ms, past this budget
+	cfg.PreReview.Timeout = "10ms"
+	pre = runPreReview(t.Context(), repo.Dir, sha, "", cfg)
+	if pre.Status != storage.PreReviewTimeout || pre.Error != "no result within 10ms" || pre.Output != "" {
+		t.Errorf("expected the pre-review to time out, got %+v", pre)
+	}
+
+	cfg.PreReview = config.PreReviewConfig{Agent: "no-such-agent"}
+	if pre = runPreReview(t.Context(), repo.Dir, sha, "", cfg); pre.Status != storage.PreReviewFailed || pre.Error == "" {
+		t.Errorf("expected an unknown agent to fail the pre-review, got %+v", pre)
+	}
+}
+
+func TestReviewPreReviewFlag(t *testing.T) {
+	var linked storage.PreReview
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/enqueue":
+			var req struct {
+				GitRef string `json:"git_ref"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(storage.ReviewJob{ID: 42, GitRef: req.GitRef, Agent: "codex"})
+		case "/api/job/pre-review":
+			json.NewDecoder(r.Body).Decode(&linked)
+			json.NewEncoder(w).Encode(map[string]any{"success": true})
+		}
+	}))
+	defer cleanup()
+
+	configPath := filepath.Join(os.Getenv("ROBOREV_DATA_DIR"), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[pre_review]\nagent = \"test\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := newTestGitRepo(t)
+	repo.CommitFile("main.go", "package main\n", "initial commit")
+
+	// Quiet hook runs still show the pre-review
+	var stdout bytes.Buffer
+	cmd := reviewCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--repo", repo.Dir, "--quiet", "--pre-review"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("review --pre-review failed: %v", err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "roborev pre-review (test,") || !strings.Contains(out, "Full review: job 42") {
+		t.Errorf("expected the pre-review and its full review job, got %q", out)
+	}
+	if linked.JobID != 42 || linked.Status != storage.PreReviewDone || linked.Output == "" {
+		t.Errorf("expected the pre-review to be linked to job 42, got %+v", linked)
+	}
+
+	cmd = reviewCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--repo", repo.Dir, "--local", "--pre-review"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--local") {
+		t.Errorf("expected --pre-review to be refused with --local, got %v", err)
+	}
+}
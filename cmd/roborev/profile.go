@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/cliprof"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// profileCLI prints where a command's time went once it finishes
+var profileCLI bool
+
+// unprofiledCommands run until stopped, as do live views (--watch), so
+// their time says nothing about the CLI's speed
+var unprofiledCommands = map[string]bool{
+	"roborev daemon run": true,
+	"roborev tui":        true,
+	"roborev stream":     true,
+}
+
+// slowCommandsPath returns the log of commands whose own work took over
+// cliprof.SlowThreshold
+func slowCommandsPath() string {
+	return filepath.Join(config.DataDir(), "slow-commands.jsonl")
+}
+
+// startProfile starts timing the phases of a command
+func startProfile(cmd *cobra.Command) {
+	if unprofiledCommands[cmd.CommandPath()] {
+		return
+	}
+	if watch := cmd.Flags().Lookup("watch"); watch != nil && watch.Value.String() == "true" {
+		return
+	}
+	cliprof.Enable()
+	http.DefaultTransport = cliprof.Transport(http.DefaultTransport)
+}
+
+// finishProfile prints the phases of a command that took total with
+// --profile-cli, and logs the command locally if it was slow
+func finishProfile(cmd *cobra.Command, total time.Duration) {
+	if !cliprof.Enabled() {
+		return
+	}
+	if profileCLI {
+		printProfile(os.Stderr, cmd.CommandPath(), total, cliprof.Stats())
+	}
+	if cliprof.HotPath() < cliprof.SlowThreshold {
+		return
+	}
+	if err := cliprof.AppendSlowCommand(slowCommandsPath(), cliprof.NewSlowCommand(cmd.CommandPath(), total)); err != nil && verbose {
+		log.Printf("Record slow command: %v", err)
+	}
+}
+
+// printProfile prints the time of each phase of a command and its share of
+// the total. Time outside the phases, such as rendering output or waiting
+// on the user, is shown as other.
+func printProfile(w io.Writer, command string, total time.Duration, stats []cliprof.Stat) {
+	fmt.Fprintf(w, "\nProfile of %s: %s\n", command, formatProfileDuration(total))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tCALLS\tTIME\tSHARE")
+	other := total
+	for _, st := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", st.Phase, st.Calls, formatProfileDuration(st.Time), profileShare(st.Time, total))
+		other -= st.Time
+	}
+	if other < 0 {
+		other = 0
+	}
+	fmt.Fprintf(tw, "other\t\t%s\t%s\n", formatProfileDuration(other), profileShare(other, total))
+	tw.Flush()
+}
+
+func profileShare(d, total time.Duration) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(d)/float64(total))
+}
+
+// formatProfileDuration rounds a duration to a precision readable at its
+// scale
+func formatProfileDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// printSlowCommands prints the slow commands logged since a time, per
+// command: how often it was slow, its median and longest run, and the
+// phase it spent the most time in
+func printSlowCommands(w io.Writer, cmds []cliprof.SlowCommand, since time.Time) {
+	byCommand := make(map[string][]cliprof.SlowCommand)
+	for _, c := range cmds {
+		if !c.At.Before(since) {
+			byCommand[c.Command] = append(byCommand[c.Command], c)
+		}
+	}
+	if len(byCommand) == 0 {
+		fmt.Fprintf(w, "No slow commands since %s. Commands are logged when their own work (not agent time) takes over %s.\n",
+			since.Format("2006-01-02 15:04"), cliprof.SlowThreshold)
+		return
+	}
+
+	names := make([]string, 0, len(byCommand))
+	for name := range byCommand {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(byCommand[names[i]]) != len(byCommand[names[j]]) {
+			return len(byCommand[names[i]]) > len(byCommand[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMMAND\tSLOW RUNS\tMEDIAN\tMAX\tTOP PHASE")
+	for _, name := range names {
+		runs := byCommand[name]
+		totals := make([]int64, len(runs))
+		phases := make(map[string]int64)
+		for i, c := range runs {
+			totals[i] = c.TotalMs
+			for phase, ms := range c.Phases {
+				phases[phase] += ms
+			}
+		}
+		sort.Slice(totals, func(i, j int) bool { return totals[i] < totals[j] })
+
+		top := ""
+		for phase, ms := range phases {
+			if phase != cliprof.PhaseAgent && (top == "" || ms > phases[top] || (ms == phases[top] && phase < top)) {
+				top = phase
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", name, len(runs),
+			formatProfileDuration(time.Duration(totals[len(totals)/2])*time.Millisecond),
+			formatProfileDuration(time.Duration(totals[len(totals)-1])*time.Millisecond), top)
+	}
+	tw.Flush()
+}
+
+// runAgent runs an agent in the CLI, timing it for --profile-cli
+func runAgent(ctx context.Context, a agent.Agent, repoPath, gitRef, prompt string, out io.Writer) (string, error) {
+	defer cliprof.Span(cliprof.PhaseAgent)()
+	return a.Review(ctx, repoPath, gitRef, prompt, out)
+}
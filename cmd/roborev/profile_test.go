@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/cliprof"
+)
+
+func TestPrintProfile(t *testing.T) {
+	var out bytes.Buffer
+	printProfile(&out, "roborev list", time.Second, []cliprof.Stat{
+		{Phase: cliprof.PhaseDBOpen, Calls: 1, Time: 100 * time.Millisecond},
+		{Phase: cliprof.PhaseQueries, Calls: 12, Time: 400 * time.Millisecond},
+	})
+	for _, want := range []string{"Profile of roborev list: 1s", "db open", "100ms", "10%", "queries", "12", "40%", "other", "500ms", "50%"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestStatsSlowCommands(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	now := time.Now()
+	for _, c := range []cliprof.SlowCommand{
+		{Command: "roborev list", At: now.Add(-48 * time.Hour), TotalMs: 9000}, // Before --since
+		{Command: "roborev list", At: now, TotalMs: 1500, Phases: map[string]int64{cliprof.PhaseHTTP: 1400}},
+		{Command: "roborev list", At: now, TotalMs: 3000, Phases: map[string]int64{cliprof.PhaseHTTP: 2900}},
+		{Command: "roborev show", At: now, TotalMs: 1200, Phases: map[string]int64{cliprof.PhaseGit: 1100, cliprof.PhaseAgent: 5000}},
+	} {
+		if err := cliprof.AppendSlowCommand(slowCommandsPath(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	cmd := statsCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--slow-commands", "--since", "1d"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("stats --slow-commands: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 commands, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "roborev list 2 3s 3s http requests" {
+		t.Errorf("unexpected list row %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "roborev show 1 1.2s 1.2s git" {
+		t.Errorf("unexpected show row %q", lines[2])
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+func promptCmd() *cobra.Command {
+	var (
+		repoPath   string
+		agent      string
+		reviewType string
+		thorough   bool
+		dirty      bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prompt [commit] or prompt [start] [end]",
+		Short: "Print the prompt a review would send, without enqueueing it",
+		Long: `Print the exact prompt a review of a commit, range or uncommitted changes
+would send to the agent, built by the daemon as a worker builds it: with
+the repo's guidelines, previous and related reviews, file context, checklist
+and size budget applied. Nothing is enqueued, so this costs no tokens and
+shows what context an agent was given.
+
+A review large enough to fan out prints the prompt of each part. Details
+such as the agent and prompt size go to stderr.
+
+To run a free-form task prompt, use 'roborev run'.
+
+Examples:
+  roborev prompt                 # Prompt for reviewing HEAD
+  roborev prompt abc123 --type security
+  roborev prompt abc123 def456   # Prompt for the range from abc123 to def456
+  roborev prompt --dirty --thorough
+  roborev prompt HEAD --json`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reviewType != "" && reviewType != "security" && reviewType != "design" {
+				return fmt.Errorf("invalid --type %q (valid: security, design)", reviewType)
+			}
+			if dirty && len(args) > 0 {
+				return fmt.Errorf("cannot specify commits with --dirty")
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := vcs.For(repoPath).RepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a repository: %w", err)
+			}
+
+			req := daemon.PromptPreviewRequest{RepoPath: root, GitRef: "HEAD", Agent: agent, ReviewType: reviewType, Focus: thorough}
+			switch {
+			case dirty:
+				diff, err := git.GetDirtyDiff(root)
+				if err != nil {
+					return fmt.Errorf("get dirty diff: %w", err)
+				}
+				if diff == "" {
+					return fmt.Errorf("no uncommitted changes to review")
+				}
+				req.GitRef, req.DiffContent = "dirty", diff
+			case len(args) == 2:
+				req.GitRef = args[0] + "^.." + args[1]
+			case len(args) == 1:
+				req.GitRef = args[0]
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			preview, err := fetchPromptPreview(getDaemonAddr(), req)
+			if err != nil {
+				if len(args) == 1 && strings.ContainsAny(args[0], " \n") {
+					err = fmt.Errorf("%w (to run a task prompt, use 'roborev run')", err)
+				}
+				return err
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(preview)
+			}
+			printPromptPreview(cmd.OutOrStdout(), cmd.ErrOrStderr(), preview)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to repository (default: current directory)")
+	cmd.Flags().StringVar(&agent, "agent", "", "agent the prompt is for (default: the agent a review would use)")
+	cmd.Flags().StringVar(&reviewType, "type", "", "review type (security, design) — changes system prompt")
+	cmd.Flags().BoolVar(&thorough, "thorough", false, "prompt of a focus review, with its larger budget, file context and blame")
+	cmd.Flags().BoolVar(&dirty, "dirty", false, "prompt for reviewing uncommitted changes")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output the preview as JSON")
+
+	return cmd
+}
+
+func fetchPromptPreview(addr string, req daemon.PromptPreviewRequest) (*daemon.PromptPreview, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(addr+"/api/prompt/preview", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp daemon.ErrorResponse
+		if json.Unmarshal(msg, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("preview failed: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("preview failed: %s", strings.TrimSpace(string(msg)))
+	}
+	var preview daemon.PromptPreview
+	if err := json.NewDecoder(resp.Body).Decode(&preview); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &preview, nil
+}
+
+// printPromptPreview prints the prompt to out and its details to info, so
+// the prompt can be piped on its own
+func printPromptPreview(out, info io.Writer, p *daemon.PromptPreview) {
+	ref := shortRef(p.GitRef)
+	if len(p.Parts) == 0 {
+		fmt.Fprintf(info, "Prompt for %s (agent: %s, type: %s, %d bytes)\n", ref, p.Agent, p.ReviewType, len(p.Prompt))
+		fmt.Fprint(out, p.Prompt)
+	} else {
+		fmt.Fprintf(info, "Review of %s fans out into %d parts (agent: %s, type: %s)\n", ref, len(p.Parts), p.Agent, p.ReviewType)
+		for i, part := range p.Parts {
+			fmt.Fprintf(out, "===== Part %d/%d: %s (%d bytes) =====\n\n", i+1, len(p.Parts), strings.Join(part.Paths, ", "), len(part.Prompt))
+			fmt.Fprint(out, part.Prompt)
+			fmt.Fprintln(out)
+		}
+	}
+	if len(p.Injection) > 0 {
+		fmt.Fprintf(info, "Warning: the diff matches prompt injection patterns: %s\n", strings.Join(p.Injection, ", "))
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+)
+
+func TestFetchPromptPreview(t *testing.T) {
+	var got daemon.PromptPreviewRequest
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/prompt/preview" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.GitRef == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(daemon.ErrorResponse{Error: "invalid commit: bad"})
+			return
+		}
+		json.NewEncoder(w).Encode(daemon.PromptPreview{
+			GitRef: "abc1234def5678", Agent: "codex", ReviewType: "security",
+			Prompt: "You are a security code reviewer.\n", Injection: []string{"ignore-instructions"},
+		})
+	}))
+	defer cleanup()
+
+	preview, err := fetchPromptPreview(ts.URL, daemon.PromptPreviewRequest{RepoPath: "/src/app", GitRef: "HEAD", ReviewType: "security"})
+	if err != nil {
+		t.Fatalf("fetchPromptPreview: %v", err)
+	}
+	if got.RepoPath != "/src/app" || got.ReviewType != "security" {
+		t.Errorf("unexpected request %+v", got)
+	}
+
+	var out, info bytes.Buffer
+	printPromptPreview(&out, &info, preview)
+	if out.String() != "You are a security code reviewer.\n" {
+		t.Errorf("only the prompt should go to stdout, got %q", out.String())
+	}
+	for _, want := range []string{"abc1234", "agent: codex", "type: security", "ignore-instructions"} {
+		if !strings.Contains(info.String(), want) {
+			t.Errorf("expected %q in details:\n%s", want, info.String())
+		}
+	}
+
+	if _, err := fetchPromptPreview(ts.URL, daemon.PromptPreviewRequest{GitRef: "bad"}); err == nil || !strings.Contains(err.Error(), "invalid commit: bad") {
+		t.Errorf("expected the daemon's error, got %v", err)
+	}
+}
+
+func TestPrintPromptPreviewParts(t *testing.T) {
+	var out, info bytes.Buffer
+	printPromptPreview(&out, &info, &daemon.PromptPreview{
+		GitRef: "abc1234..def5678", Agent: "codex", ReviewType: "default",
+		Parts: []daemon.PromptPreviewPart{{Paths: []string{"a.go"}, Prompt: "part a"}, {Paths: []string{"b.go", "c.go"}, Prompt: "part b"}},
+	})
+	if !strings.Contains(info.String(), "fans out into 2 parts") {
+		t.Errorf("expected the fan-out to be noted, got %q", info.String())
+	}
+	if !strings.Contains(out.String(), "Part 1/2: a.go") || !strings.Contains(out.String(), "Part 2/2: b.go, c.go") {
+		t.Errorf("expected each part's prompt, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func queueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Control job processing in the daemon",
+	}
+
+	cmd.AddCommand(queueDrainCmd())
+	cmd.AddCommand(queueResumeCmd())
+
+	return cmd
+}
+
+func queueDrainCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "drain",
+		Short: "Stop claiming jobs and wait for running jobs to finish",
+		Long: `Stop the daemon from claiming queued jobs, then wait for running jobs to
+finish. Use before upgrades or database maintenance.
+
+Queued jobs stay queued. Claiming remains paused until 'roborev queue resume'
+is run or the daemon restarts.
+
+Exits with a non-zero status if jobs are still running when the timeout
+expires.
+
+Examples:
+  roborev queue drain
+  roborev queue drain --timeout 30m`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := drainQueue(cmd.OutOrStdout(), getDaemonAddr(), timeout, time.Second)
+			if errors.Is(err, errDrainTimeout) {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return &exitError{code: 1}
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "how long to wait for running jobs")
+
+	return cmd
+}
+
+func queueResumeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume",
+		Short: "Resume claiming jobs after a drain",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := setQueueDraining(getDaemonAddr(), false)
+			if err != nil {
+				if isTransportError(err) {
+					return fmt.Errorf("daemon not running")
+				}
+				return err
+			}
+			cmd.Printf("Queue resumed (%d queued)\n", status.QueuedJobs)
+			return nil
+		},
+	}
+}
+
+var errDrainTimeout = errors.New("timed out waiting for running jobs")
+
+// drainQueue pauses claiming on the daemon at addr and polls until no jobs
+// are running, printing progress to w whenever the counts change.
+func drainQueue(w io.Writer, addr string, timeout, poll time.Duration) error {
+	status, err := setQueueDraining(addr, true)
+	if err != nil {
+		if isTransportError(err) {
+			fmt.Fprintln(w, "Daemon not running; nothing to drain")
+			return nil
+		}
+		return err
+	}
+	fmt.Fprintln(w, "Stopped claiming new jobs")
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	lastRunning := -1
+	for !drained(status) {
+		if status.RunningJobs != lastRunning {
+			fmt.Fprintf(w, "[%s] Waiting for %d running job(s) (%d queued)\n",
+				time.Since(start).Round(time.Second), status.RunningJobs, status.QueuedJobs)
+			lastRunning = status.RunningJobs
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintf(w, "Timed out after %s with %d job(s) still running\n", timeout, status.RunningJobs)
+			fmt.Fprintln(w, "Claiming remains paused; run 'roborev queue resume' to continue")
+			return errDrainTimeout
+		}
+		time.Sleep(poll)
+		if status, err = getQueueStatus(addr); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Queue drained in %s: no jobs running, %d queued, %d failed\n",
+		time.Since(start).Round(time.Second), status.QueuedJobs, status.FailedJobs)
+	fmt.Fprintln(w, "Claiming stays paused until 'roborev queue resume' or a daemon restart")
+	return nil
+}
+
+// drained reports whether no worker is busy and no job is marked running.
+// Both are checked because a worker claims a job before counting itself active.
+func drained(status storage.DaemonStatus) bool {
+	return status.RunningJobs == 0 && status.ActiveWorkers == 0
+}
+
+func setQueueDraining(addr string, draining bool) (storage.DaemonStatus, error) {
+	body, err := json.Marshal(map[string]bool{"draining": draining})
+	if err != nil {
+		return storage.DaemonStatus{}, err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(addr+"/api/queue/drain", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return storage.DaemonStatus{}, err
+	}
+	defer resp.Body.Close()
+	return decodeQueueStatus(resp)
+}
+
+// wakeQueue has a running daemon's idle workers look for jobs now, after a
+// command wrote jobs to the database behind its back. Best-effort: without
+// the wakeup the workers find the jobs on their next poll.
+func wakeQueue() {
+	if _, err := daemon.GetAnyRunningDaemon(); err != nil {
+		return
+	}
+	client := &http.Client{Timeout: 2 * time.Second}
+	if resp, err := client.Post(getDaemonAddr()+"/api/queue/wake", "application/json", nil); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func getQueueStatus(addr string) (storage.DaemonStatus, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(addr + "/api/status")
+	if err != nil {
+		return storage.DaemonStatus{}, err
+	}
+	defer resp.Body.Close()
+	return decodeQueueStatus(resp)
+}
+
+func decodeQueueStatus(resp *http.Response) (storage.DaemonStatus, error) {
+	var status storage.DaemonStatus
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return status, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return status, fmt.Errorf("decode status: %w", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// mockDrainDaemon serves /api/queue/drain and /api/status, reporting one
+// running job until the given number of status polls have been made.
+func mockDrainDaemon(t *testing.T, busyPolls int32) (*httptest.Server, *atomic.Bool) {
+	t.Helper()
+	var draining atomic.Bool
+	var polls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/queue/drain":
+			var req struct {
+				Draining bool `json:"draining"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			draining.Store(req.Draining)
+		case "/api/status":
+			polls.Add(1)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status := storage.DaemonStatus{QueuedJobs: 3, Draining: draining.Load()}
+		if busyPolls < 0 || polls.Load() < busyPolls {
+			status.RunningJobs = 1
+			status.ActiveWorkers = 1
+		}
+		writeJSON(w, status)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &draining
+}
+
+func TestDrainQueue(t *testing.T) {
+	t.Run("waits for running jobs", func(t *testing.T) {
+		ts, draining := mockDrainDaemon(t, 2)
+		var out bytes.Buffer
+		if err := drainQueue(&out, ts.URL, time.Minute, time.Millisecond); err != nil {
+			t.Fatalf("drainQueue: %v", err)
+		}
+		if !draining.Load() {
+			t.Error("expected daemon to be left draining")
+		}
+		if !strings.Contains(out.String(), "Waiting for 1 running job(s)") {
+			t.Errorf("expected progress output, got:\n%s", out.String())
+		}
+		if !strings.Contains(out.String(), "Queue drained") {
+			t.Errorf("expected drained report, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		ts, _ := mockDrainDaemon(t, -1)
+		var out bytes.Buffer
+		err := drainQueue(&out, ts.URL, 20*time.Millisecond, time.Millisecond)
+		if !errors.Is(err, errDrainTimeout) {
+			t.Fatalf("expected timeout error, got %v", err)
+		}
+		if !strings.Contains(out.String(), "queue resume") {
+			t.Errorf("expected resume hint, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("daemon not running", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		addr := ts.URL
+		ts.Close()
+		var out bytes.Buffer
+		if err := drainQueue(&out, addr, time.Minute, time.Millisecond); err != nil {
+			t.Fatalf("expected no error when daemon is down, got %v", err)
+		}
+		if !strings.Contains(out.String(), "nothing to drain") {
+			t.Errorf("unexpected output:\n%s", out.String())
+		}
+	})
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// maxTiebreakerDiff caps the commit diff included in a tie-breaker prompt
+const maxTiebreakerDiff = 100 * 1024
+
+func reconcileCmd() *cobra.Command {
+	var (
+		repoPath   string
+		verdict    string
+		tiebreaker string
+		note       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reconcile <commit>",
+		Short: "Settle conflicting verdicts from several agents on a commit",
+		Long: `Show each agent's latest verdict on a commit and record a final,
+consolidated verdict when they disagree. The consolidated verdict takes
+precedence over individual reviews wherever roborev reports a commit's
+verdict.
+
+Keys (followed by Enter):
+  p  record PASS
+  f  record FAIL
+  t  ask a tie-breaker agent to decide
+  q  quit without recording
+
+Use --verdict to record a decision without prompting, or --tiebreaker to
+ask a specific agent right away.
+
+Examples:
+  roborev reconcile abc123
+  roborev reconcile HEAD --tiebreaker gemini
+  roborev reconcile abc123 --verdict pass --note "finding is a false positive"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			sha, err := git.ResolveSHA(root, args[0])
+			if err != nil {
+				return fmt.Errorf("resolve %s: %w", args[0], err)
+			}
+
+			var forced string
+			switch strings.ToLower(verdict) {
+			case "":
+			case "pass", "p":
+				forced = "P"
+			case "fail", "f":
+				forced = "F"
+			default:
+				return fmt.Errorf("invalid --verdict %q (valid: pass, fail)", verdict)
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			addr := getDaemonAddr()
+
+			state, err := getReconcileState(addr, root, sha)
+			if err != nil {
+				return err
+			}
+
+			r := reconciler{
+				in:         bufio.NewReader(cmd.InOrStdin()),
+				out:        cmd.OutOrStdout(),
+				state:      state,
+				tiebreaker: tiebreaker,
+				ask: func(agentName string) (tiebreakerResult, error) {
+					return askTiebreaker(addr, root, sha, agentName, state.Verdicts)
+				},
+				record: func(req daemon.ReconcileRequest) error {
+					req.RepoPath, req.GitRef, req.Note = root, sha, note
+					return postReconcileDecision(addr, req)
+				},
+			}
+			return r.run(shortSHA(sha), forced)
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&verdict, "verdict", "", "record this verdict without prompting (pass or fail)")
+	cmd.Flags().StringVar(&tiebreaker, "tiebreaker", "", "ask this agent to break the tie (default agent when chosen interactively)")
+	cmd.Flags().StringVar(&note, "note", "", "note to record with the verdict")
+	return cmd
+}
+
+// tiebreakerResult is the decision of a tie-breaker agent
+type tiebreakerResult struct {
+	Agent   string
+	JobID   int64
+	Verdict string // "P", "F", or "" when the agent gave no clear verdict
+	Output  string
+}
+
+// reconciler walks through a disagreement and records the final verdict
+type reconciler struct {
+	in         *bufio.Reader
+	out        io.Writer
+	state      daemon.ReconcileResponse
+	tiebreaker string // Agent to ask right away; "" waits for the t key
+	ask        func(agentName string) (tiebreakerResult, error)
+	record     func(daemon.ReconcileRequest) error
+}
+
+func (r *reconciler) run(ref, forced string) error {
+	verdicts := r.state.Verdicts
+	if len(verdicts) == 0 {
+		return fmt.Errorf("no completed reviews of %s", ref)
+	}
+
+	agree := true
+	for _, v := range verdicts[1:] {
+		if v.Verdict != verdicts[0].Verdict {
+			agree = false
+		}
+	}
+
+	if agree {
+		fmt.Fprintf(r.out, "%d agent(s) reviewed %s and agree: %s\n", len(verdicts), ref, verdictLabel(verdicts[0].Verdict))
+	} else {
+		fmt.Fprintf(r.out, "Agents disagree on %s:\n", ref)
+	}
+	for _, v := range verdicts {
+		printAgentVerdict(r.out, v)
+	}
+	if rec := r.state.Reconciliation; rec != nil {
+		fmt.Fprintf(r.out, "\nRecorded verdict: %s (%s", verdictLabel(rec.Verdict), rec.Method)
+		if rec.TiebreakerAgent != "" {
+			fmt.Fprintf(r.out, " by %s", rec.TiebreakerAgent)
+		}
+		fmt.Fprintf(r.out, ", %s)\n", rec.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	if forced != "" {
+		return r.save(daemon.ReconcileRequest{Verdict: forced, Method: storage.ReconcileManual})
+	}
+	if agree && r.tiebreaker == "" {
+		return nil
+	}
+	if r.tiebreaker != "" {
+		return r.breakTie(r.tiebreaker)
+	}
+	return r.prompt()
+}
+
+// prompt asks for the final verdict until one is recorded or the user quits
+func (r *reconciler) prompt() error {
+	for {
+		fmt.Fprint(r.out, "\nFinal verdict: [p]ass [f]ail [t]ie-breaker [q]uit: ")
+		line, err := r.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "p":
+			return r.save(daemon.ReconcileRequest{Verdict: "P", Method: storage.ReconcileManual})
+		case "f":
+			return r.save(daemon.ReconcileRequest{Verdict: "F", Method: storage.ReconcileManual})
+		case "t":
+			return r.breakTie("")
+		case "q":
+			return nil
+		}
+	}
+}
+
+// breakTie asks a tie-breaker agent and records its verdict once accepted
+func (r *reconciler) breakTie(agentName string) error {
+	label := agentName
+	if label == "" {
+		label = "the default agent"
+	}
+	fmt.Fprintf(r.out, "\nAsking %s to break the tie...\n", label)
+	res, err := r.ask(agentName)
+	if err != nil {
+		return fmt.Errorf("tie-breaker: %w", err)
+	}
+
+	fmt.Fprintf(r.out, "\nTie-breaker (%s, job %d):\n%s\n", res.Agent, res.JobID, strings.TrimSpace(res.Output))
+	if res.Verdict == "" {
+		fmt.Fprintln(r.out, "\nThe tie-breaker gave no clear verdict.")
+		return r.prompt()
+	}
+
+	fmt.Fprintf(r.out, "\nRecord %s from %s? [Y/n]: ", verdictLabel(res.Verdict), res.Agent)
+	line, err := r.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(r.out)
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "", "y", "yes":
+		return r.save(daemon.ReconcileRequest{
+			Verdict:         res.Verdict,
+			Method:          storage.ReconcileTiebreaker,
+			TiebreakerAgent: res.Agent,
+			TiebreakerJobID: res.JobID,
+		})
+	}
+	return r.prompt()
+}
+
+func (r *reconciler) save(req daemon.ReconcileRequest) error {
+	if err := r.record(req); err != nil {
+		return fmt.Errorf("record verdict: %w", err)
+	}
+	fmt.Fprintf(r.out, "Recorded verdict: %s\n", verdictLabel(req.Verdict))
+	return nil
+}
+
+func printAgentVerdict(w io.Writer, v storage.AgentVerdict) {
+	findings := storage.ExtractFindings(v.Output)
+	fmt.Fprintf(w, "\n  %s  %s (job %d)", verdictLabel(v.Verdict), formatAgentLabel(v.Agent, v.Model), v.JobID)
+	if len(findings) > 0 {
+		fmt.Fprintf(w, ", %d finding(s)", len(findings))
+	}
+	fmt.Fprintln(w)
+	for _, f := range findings {
+		first, _, _ := strings.Cut(f.Text, "\n")
+		fmt.Fprintf(w, "    %s\n", strings.TrimSpace(first))
+	}
+}
+
+func verdictLabel(verdict string) string {
+	switch verdict {
+	case "P":
+		return "PASS"
+	case "F":
+		return "FAIL"
+	}
+	return "?"
+}
+
+// buildTiebreakerPrompt asks an agent to decide between conflicting reviews
+// of a commit
+func buildTiebreakerPrompt(sha, diff string, verdicts []storage.AgentVerdict) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Several code reviewers disagree about commit %s. ", sha)
+	sb.WriteString("Decide whether the commit should pass review. Check each finding against the code ")
+	sb.WriteString("and discard findings that are wrong or do not matter.\n\n")
+	sb.WriteString("Start your answer with a line that is exactly \"VERDICT: PASS\" or \"VERDICT: FAIL\", ")
+	sb.WriteString("then explain which findings hold up and why.\n\n")
+
+	for _, v := range verdicts {
+		fmt.Fprintf(&sb, "## Review by %s (%s)\n\n%s\n\n", v.Agent, verdictLabel(v.Verdict), strings.TrimSpace(v.Output))
+	}
+
+	sb.WriteString("## Commit diff\n\n```diff\n")
+	if len(diff) > maxTiebreakerDiff {
+		sb.WriteString(diff[:maxTiebreakerDiff])
+		sb.WriteString("\n... (truncated)\n")
+	} else {
+		sb.WriteString(diff)
+	}
+	if !strings.HasSuffix(diff, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// parseTiebreakerVerdict reads the VERDICT line a tie-breaker was asked to
+// start with. Returns "" if there is none.
+func parseTiebreakerVerdict(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.ToUpper(strings.Trim(strings.TrimSpace(line), "*#_ "))
+		rest, ok := strings.CutPrefix(line, "VERDICT:")
+		if !ok {
+			continue
+		}
+		switch strings.Trim(strings.TrimSpace(rest), "*_ .") {
+		case "PASS":
+			return "P"
+		case "FAIL":
+			return "F"
+		}
+	}
+	return ""
+}
+
+// askTiebreaker enqueues a tie-breaker prompt, waits for it and parses the
+// agent's verdict
+func askTiebreaker(addr, root, sha, agentName string, verdicts []storage.AgentVerdict) (tiebreakerResult, error) {
+	diff, err := git.GetDiff(root, sha)
+	if err != nil {
+		return tiebreakerResult{}, err
+	}
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"repo_path":     root,
+		"git_ref":       "reconcile",
+		"agent":         agentName,
+		"custom_prompt": buildTiebreakerPrompt(sha, diff, verdicts),
+	})
+	resp, err := http.Post(addr+"/api/enqueue", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return tiebreakerResult{}, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return tiebreakerResult{}, fmt.Errorf("enqueue failed: %s", body)
+	}
+	var job storage.ReviewJob
+	if err := json.Unmarshal(body, &job); err != nil {
+		return tiebreakerResult{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	review, err := waitForReview(job.ID)
+	if err != nil {
+		return tiebreakerResult{}, err
+	}
+	return tiebreakerResult{
+		Agent:   job.Agent,
+		JobID:   job.ID,
+		Verdict: parseTiebreakerVerdict(review.Output),
+		Output:  review.Output,
+	}, nil
+}
+
+func getReconcileState(addr, repo, sha string) (daemon.ReconcileResponse, error) {
+	var state daemon.ReconcileResponse
+	params := url.Values{"repo": {repo}, "git_ref": {sha}}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(addr + "/api/reconcile?" + params.Encode())
+	if err != nil {
+		return state, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// Repo has never been reviewed
+		return state, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return state, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return state, fmt.Errorf("decode verdicts: %w", err)
+	}
+	return state, nil
+}
+
+func postReconcileDecision(addr string, req daemon.ReconcileRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(addr+"/api/reconcile/decide", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func conflictingVerdicts() daemon.ReconcileResponse {
+	return daemon.ReconcileResponse{Verdicts: []storage.AgentVerdict{
+		{JobID: 1, Agent: "codex", Verdict: "F", Output: "- High: race in cache\n  details"},
+		{JobID: 2, Agent: "claude-code", Verdict: "P", Output: "No issues found."},
+	}}
+}
+
+func runTestReconciler(t *testing.T, state daemon.ReconcileResponse, input, tiebreaker, forced string, tb tiebreakerResult) (string, []daemon.ReconcileRequest, []string) {
+	t.Helper()
+	var out bytes.Buffer
+	var recorded []daemon.ReconcileRequest
+	var asked []string
+	r := reconciler{
+		in:         bufio.NewReader(strings.NewReader(input)),
+		out:        &out,
+		state:      state,
+		tiebreaker: tiebreaker,
+		ask: func(agentName string) (tiebreakerResult, error) {
+			asked = append(asked, agentName)
+			return tb, nil
+		},
+		record: func(req daemon.ReconcileRequest) error {
+			recorded = append(recorded, req)
+			return nil
+		},
+	}
+	if err := r.run("abc1234", forced); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return out.String(), recorded, asked
+}
+
+func TestReconcilerManual(t *testing.T) {
+	out, recorded, asked := runTestReconciler(t, conflictingVerdicts(), "x\np\n", "", "", tiebreakerResult{})
+	if !strings.Contains(out, "Agents disagree on abc1234") || !strings.Contains(out, "FAIL  codex (job 1), 1 finding(s)") ||
+		!strings.Contains(out, "- High: race in cache") {
+		t.Errorf("expected disagreement to be presented, got:\n%s", out)
+	}
+	if len(asked) != 0 {
+		t.Errorf("did not expect a tie-breaker, asked %v", asked)
+	}
+	if len(recorded) != 1 || recorded[0].Verdict != "P" || recorded[0].Method != storage.ReconcileManual {
+		t.Errorf("expected manual PASS, got %+v", recorded)
+	}
+
+	_, recorded, _ = runTestReconciler(t, conflictingVerdicts(), "q\n", "", "", tiebreakerResult{})
+	if len(recorded) != 0 {
+		t.Errorf("expected nothing recorded after quit, got %+v", recorded)
+	}
+}
+
+func TestReconcilerTiebreaker(t *testing.T) {
+	tb := tiebreakerResult{Agent: "gemini", JobID: 9, Verdict: "F", Output: "VERDICT: FAIL\nThe race is real."}
+
+	// Chosen interactively and accepted with Enter
+	out, recorded, asked := runTestReconciler(t, conflictingVerdicts(), "t\n\n", "", "", tb)
+	if len(asked) != 1 || asked[0] != "" {
+		t.Errorf("expected the default agent to be asked, got %v", asked)
+	}
+	if !strings.Contains(out, "The race is real.") {
+		t.Errorf("expected tie-breaker output, got:\n%s", out)
+	}
+	want := daemon.ReconcileRequest{Verdict: "F", Method: storage.ReconcileTiebreaker, TiebreakerAgent: "gemini", TiebreakerJobID: 9}
+	if len(recorded) != 1 || recorded[0] != want {
+		t.Errorf("expected %+v, got %+v", want, recorded)
+	}
+
+	// Requested with --tiebreaker, then overruled
+	_, recorded, asked = runTestReconciler(t, conflictingVerdicts(), "n\np\n", "gemini", "", tb)
+	if len(asked) != 1 || asked[0] != "gemini" {
+		t.Errorf("expected gemini to be asked, got %v", asked)
+	}
+	if len(recorded) != 1 || recorded[0].Verdict != "P" || recorded[0].Method != storage.ReconcileManual {
+		t.Errorf("expected manual PASS after overruling, got %+v", recorded)
+	}
+}
+
+func TestReconcilerAgreementAndForced(t *testing.T) {
+	agree := daemon.ReconcileResponse{Verdicts: []storage.AgentVerdict{
+		{JobID: 1, Agent: "codex", Verdict: "P"},
+		{JobID: 2, Agent: "gemini", Verdict: "P"},
+	}}
+	out, recorded, _ := runTestReconciler(t, agree, "", "", "", tiebreakerResult{})
+	if !strings.Contains(out, "2 agent(s) reviewed abc1234 and agree: PASS") || len(recorded) != 0 {
+		t.Errorf("expected agreement note and nothing recorded, got %+v:\n%s", recorded, out)
+	}
+
+	_, recorded, _ = runTestReconciler(t, conflictingVerdicts(), "", "", "F", tiebreakerResult{})
+	if len(recorded) != 1 || recorded[0].Verdict != "F" || recorded[0].Method != storage.ReconcileManual {
+		t.Errorf("expected forced FAIL, got %+v", recorded)
+	}
+}
+
+func TestParseTiebreakerVerdict(t *testing.T) {
+	tests := []struct {
+		output, want string
+	}{
+		{"VERDICT: PASS\nall good", "P"},
+		{"**Verdict: fail**\n", "F"},
+		{"Some preamble\n## VERDICT: FAIL.\n", "F"},
+		{"I think it passes", ""},
+		{"VERDICT: maybe", ""},
+	}
+	for _, tt := range tests {
+		if got := parseTiebreakerVerdict(tt.output); got != tt.want {
+			t.Errorf("parseTiebreakerVerdict(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTiebreakerPrompt(t *testing.T) {
+	p := buildTiebreakerPrompt("abc123", "diff --git a/x b/x\n+x", conflictingVerdicts().Verdicts)
+	for _, want := range []string{"commit abc123", "VERDICT: PASS", "## Review by codex (FAIL)", "## Review by claude-code (PASS)", "+x\n```"} {
+		if !strings.Contains(p, want) {
+			t.Errorf("expected %q in prompt:\n%s", want, p)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/prompt"
 	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
@@ -384,8 +385,8 @@ func runRefine(agentName, modelStr, reasoningStr string, maxIterations int, quie
 		}
 		branchBefore := git.GetCurrentBranch(repoPath)
 
-		// Create temp worktree to isolate agent from user's working tree
-		worktreePath, cleanupWorktree, err := createTempWorktree(repoPath)
+		// Use a separate worktree to isolate agent from user's working tree
+		worktreePath, cleanupWorktree, err := acquireWorktree(repoPath, cfg)
 		if err != nil {
 			return fmt.Errorf("create worktree: %w", err)
 		}
@@ -409,7 +410,7 @@ func runRefine(agentName, modelStr, reasoningStr string, maxIterations int, quie
 			timer.startLive(fmt.Sprintf("Addressing review (job %d)...", currentFailedReview.JobID))
 		}
 		fixCtx, fixCancel := context.WithTimeout(context.Background(), 1*time.Hour)
-		output, agentErr := addressAgent.Review(fixCtx, worktreePath, "HEAD", addressPrompt, agentOutput)
+		output, agentErr := runAgent(fixCtx, addressAgent, worktreePath, "HEAD", addressPrompt, agentOutput)
 		fixCancel()
 		if fmtr != nil {
 			fmtr.Flush()
@@ -611,6 +612,27 @@ func summarizeAgentOutput(output string) string {
 	return strings.Join(summary, "\n")
 }
 
+// acquireWorktree returns a worktree of the repo at HEAD to isolate agent
+// work, and the function that releases it. The worktree comes from the
+// pool when [worktree_pool] is enabled, and is temporary otherwise.
+func acquireWorktree(repoPath string, cfg *config.Config) (string, func(), error) {
+	if cfg == nil || !cfg.WorktreePool.Enabled {
+		return createTempWorktree(repoPath)
+	}
+	perRepo, total := cfg.WorktreePool.Limits()
+	pool := &worktree.Pool{
+		Dir:     filepath.Join(config.DataDir(), "worktrees"),
+		PerRepo: perRepo,
+		Total:   total,
+		Setup:   prepareWorktree,
+	}
+	wt, err := pool.Acquire(repoPath, "HEAD")
+	if err != nil {
+		return "", nil, err
+	}
+	return wt.Path, wt.Release, nil
+}
+
 // createTempWorktree creates a temporary git worktree for isolated agent work
 func createTempWorktree(repoPath string) (string, func(), error) {
 	worktreeDir, err := os.MkdirTemp("", "roborev-refine-")
@@ -625,17 +647,30 @@ func createTempWorktree(repoPath string) (string, func(), error) {
 		return "", nil, fmt.Errorf("git worktree add: %w: %s", err, out)
 	}
 
+	cleanup := func() {
+		exec.Command("git", "-C", repoPath, "worktree", "remove", "--force", worktreeDir).Run()
+		os.RemoveAll(worktreeDir)
+	}
+
+	if err := prepareWorktree(worktreeDir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return worktreeDir, cleanup, nil
+}
+
+// prepareWorktree checks out the submodules and LFS files of a worktree
+func prepareWorktree(worktreeDir string) error {
 	// Initialize and update submodules in the worktree
 	initArgs := []string{"-C", worktreeDir}
 	if submoduleRequiresFileProtocol(worktreeDir) {
 		initArgs = append(initArgs, "-c", "protocol.file.allow=always")
 	}
 	initArgs = append(initArgs, "submodule", "update", "--init")
-	cmd = exec.Command("git", initArgs...)
+	cmd := exec.Command("git", initArgs...)
 	if out, err := cmd.CombinedOutput(); err != nil {
-		exec.Command("git", "-C", repoPath, "worktree", "remove", "--force", worktreeDir).Run()
-		os.RemoveAll(worktreeDir)
-		return "", nil, fmt.Errorf("git submodule update: %w: %s", err, out)
+		return fmt.Errorf("git submodule update: %w: %s", err, out)
 	}
 
 	updateArgs := []string{"-C", worktreeDir}
@@ -645,9 +680,7 @@ func createTempWorktree(repoPath string) (string, func(), error) {
 	updateArgs = append(updateArgs, "submodule", "update", "--init", "--recursive")
 	cmd = exec.Command("git", updateArgs...)
 	if out, err := cmd.CombinedOutput(); err != nil {
-		exec.Command("git", "-C", repoPath, "worktree", "remove", "--force", worktreeDir).Run()
-		os.RemoveAll(worktreeDir)
-		return "", nil, fmt.Errorf("git submodule update: %w: %s", err, out)
+		return fmt.Errorf("git submodule update: %w: %s", err, out)
 	}
 
 	lfsCmd := exec.Command("git", "-C", worktreeDir, "lfs", "env")
@@ -655,13 +688,7 @@ func createTempWorktree(repoPath string) (string, func(), error) {
 		cmd = exec.Command("git", "-C", worktreeDir, "lfs", "pull")
 		cmd.Run()
 	}
-
-	cleanup := func() {
-		exec.Command("git", "-C", repoPath, "worktree", "remove", "--force", worktreeDir).Run()
-		os.RemoveAll(worktreeDir)
-	}
-
-	return worktreeDir, cleanup, nil
+	return nil
 }
 
 func submoduleRequiresFileProtocol(repoPath string) bool {
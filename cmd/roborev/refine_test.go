@@ -1080,6 +1080,51 @@ func TestWorktreeCleanupBetweenIterations(t *testing.T) {
 	}
 }
 
+func TestAcquireWorktreePooled(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dataDir := t.TempDir()
+	t.Setenv("ROBOREV_DATA_DIR", dataDir)
+	repoDir, _, _ := setupTestGitRepo(t)
+
+	cfg := &config.Config{WorktreePool: config.WorktreePoolConfig{Enabled: true}}
+	worktreePath, release, err := acquireWorktree(repoDir, cfg)
+	if err != nil {
+		t.Fatalf("acquireWorktree: %v", err)
+	}
+	if !strings.HasPrefix(worktreePath, filepath.Join(dataDir, "worktrees")) {
+		t.Errorf("expected a pooled worktree under the data dir, got %s", worktreePath)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "agent-change.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// The next fix reuses the worktree without the last fix's changes
+	again, release, err := acquireWorktree(repoDir, cfg)
+	if err != nil {
+		t.Fatalf("acquireWorktree: %v", err)
+	}
+	defer release()
+	if again != worktreePath {
+		t.Errorf("expected worktree %s to be reused, got %s", worktreePath, again)
+	}
+	if _, err := os.Stat(filepath.Join(again, "agent-change.txt")); !os.IsNotExist(err) {
+		t.Errorf("change from the last fix survived: %v", err)
+	}
+
+	// Without the pool each fix gets a temporary worktree
+	temp, cleanup, err := acquireWorktree(repoDir, &config.Config{})
+	if err != nil {
+		t.Fatalf("acquireWorktree: %v", err)
+	}
+	cleanup()
+	if strings.HasPrefix(temp, dataDir) {
+		t.Errorf("expected a temporary worktree, got %s", temp)
+	}
+}
+
 func TestResolveReasoningWithFast(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func remapCmd() *cobra.Command {
+	var (
+		repoPath string
+		oldRef   string
+		newRef   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "remap --old <sha> --new <sha>",
+		Short: "Move reviews of a rewritten commit onto its new version",
+		Long: `Move the reviews of a commit that history rewriting, such as an
+interactive rebase or an amend, replaced onto its rewritten equivalent:
+its jobs with their findings, triage and responses, so they are not left
+behind on a commit that no longer exists.
+
+The daemon does this by itself for rewrites that keep a commit's patch:
+when a repo's HEAD moves, new commits are matched by patch-id against
+reviewed commits that are no longer on any branch. Use remap for the rest,
+such as commits whose changes were edited or squashed. The old commit may
+be given in full when it no longer exists in the repo.
+
+Examples:
+  roborev remap --old abc123 --new HEAD
+  roborev remap --old abc123 --new def456`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if oldRef == "" || newRef == "" {
+				return fmt.Errorf("--old and --new are required")
+			}
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetMainRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+			newSHA, err := git.ResolveSHA(repoPath, newRef)
+			if err != nil {
+				return fmt.Errorf("resolve %s: %w", newRef, err)
+			}
+			oldSHA := oldRef
+			if resolved, err := git.ResolveSHA(repoPath, oldRef); err == nil {
+				oldSHA = resolved
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			result, err := postRemap(getDaemonAddr(), daemon.RemapRequest{RepoPath: root, OldSHA: oldSHA, NewSHA: newSHA})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %d job(s) and %d response(s) of %s onto %s\n",
+				result.Jobs, result.Responses, shortSHA(result.OldSHA), shortSHA(result.NewSHA))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().StringVar(&oldRef, "old", "", "commit the reviews were made on, before the rewrite")
+	cmd.Flags().StringVar(&newRef, "new", "", "rewritten commit to move the reviews onto")
+	return cmd
+}
+
+func postRemap(addr string, req daemon.RemapRequest) (*storage.RemapResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(addr+"/api/remap", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("remap failed: %s", strings.TrimSpace(string(msg)))
+	}
+	var result storage.RemapResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &result, nil
+}
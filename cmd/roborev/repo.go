@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/storage"
 	"github.com/spf13/cobra"
@@ -77,6 +78,7 @@ Subcommands:
   rename  - Rename a repository's display name
   delete  - Remove a repository from tracking
   merge   - Merge reviews from one repository into another
+  relocate - Point a repository at its new directory after a move
 `,
 	}
 
@@ -85,6 +87,7 @@ Subcommands:
 	cmd.AddCommand(repoRenameCmd())
 	cmd.AddCommand(repoDeleteCmd())
 	cmd.AddCommand(repoMergeCmd())
+	cmd.AddCommand(repoRelocateCmd())
 
 	return cmd
 }
@@ -97,18 +100,13 @@ func repoListCmd() *cobra.Command {
 
 Shows the display name, path, and number of reviews for each repository.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			dbPath := storage.DefaultDBPath()
-			if dbPath == "" {
-				return fmt.Errorf("cannot determine database path")
-			}
-
-			db, err := storage.Open(dbPath)
+			store, err := openReviewStore(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("open database: %w", err)
+				return err
 			}
-			defer db.Close()
+			defer store.Close()
 
-			repos, total, err := db.ListReposWithReviewCounts()
+			repos, total, err := storage.ListReposWithJobCounts(cmd.Context(), store)
 			if err != nil {
 				return fmt.Errorf("list repos: %w", err)
 			}
@@ -149,23 +147,18 @@ Examples:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			identifier := resolveRepoIdentifier(args[0])
 
-			dbPath := storage.DefaultDBPath()
-			if dbPath == "" {
-				return fmt.Errorf("cannot determine database path")
-			}
-
-			db, err := storage.Open(dbPath)
+			store, err := openReviewStore(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("open database: %w", err)
+				return err
 			}
-			defer db.Close()
+			defer store.Close()
 
-			repo, err := db.FindRepo(identifier)
+			repo, err := store.FindRepo(cmd.Context(), identifier)
 			if err != nil {
 				return fmt.Errorf("repository not found: %s", identifier)
 			}
 
-			stats, err := db.GetRepoStats(repo.ID)
+			stats, err := store.GetRepoStats(cmd.Context(), repo.ID)
 			if err != nil {
 				return fmt.Errorf("get stats: %w", err)
 			}
@@ -173,6 +166,15 @@ Examples:
 			fmt.Printf("Repository: %s\n", stats.Repo.Name)
 			fmt.Printf("Path:       %s\n", stats.Repo.RootPath)
 			fmt.Printf("Created:    %s\n", stats.Repo.CreatedAt.Format("2006-01-02 15:04:05"))
+			// Branches are only recorded in the local database
+			if db, ok := store.(*storage.DB); ok {
+				if b, err := db.GetRepoBranches(repo.ID); err == nil && b != nil {
+					fmt.Printf("Default branch: %s (from %s)\n", b.DefaultBranch, b.Source)
+					if len(b.Protected) > 0 {
+						fmt.Printf("Protected:  %s\n", strings.Join(b.Protected, ", "))
+					}
+				}
+			}
 			fmt.Println()
 			fmt.Printf("Jobs:       %d total\n", stats.TotalJobs)
 			if stats.QueuedJobs > 0 {
@@ -185,6 +187,13 @@ Examples:
 			if stats.FailedJobs > 0 {
 				fmt.Printf("  Failed:   %d\n", stats.FailedJobs)
 			}
+			if stats.SkippedJobs > 0 {
+				fmt.Printf("  Skipped:  %d (intentionally not reviewed)\n", stats.SkippedJobs)
+			}
+			if stats.SampledOutJobs > 0 {
+				reviewed := stats.TotalJobs - stats.SampledOutJobs
+				fmt.Printf("  Sampled out: %d (coverage %.0f%%)\n", stats.SampledOutJobs, 100*float64(reviewed)/float64(stats.TotalJobs))
+			}
 			fmt.Println()
 			fmt.Printf("Reviews:    %d total\n", stats.AddressedReviews+stats.UnaddressedReviews)
 			fmt.Printf("  Passed:      %d\n", stats.PassedReviews)
@@ -238,18 +247,13 @@ Examples:
 				return fmt.Errorf("new name cannot be empty")
 			}
 
-			dbPath := storage.DefaultDBPath()
-			if dbPath == "" {
-				return fmt.Errorf("cannot determine database path")
-			}
-
-			db, err := storage.Open(dbPath)
+			store, err := openReviewStore(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("open database: %w", err)
+				return err
 			}
-			defer db.Close()
+			defer store.Close()
 
-			affected, err := db.RenameRepo(identifier, newName)
+			affected, err := store.RenameRepo(cmd.Context(), identifier, newName)
 			if err != nil {
 				return fmt.Errorf("rename repo: %w", err)
 			}
@@ -276,6 +280,9 @@ func repoDeleteCmd() *cobra.Command {
 By default, this only removes the repository entry. Use --cascade to also
 delete all associated jobs, reviews, and responses.
 
+Deleted rows are kept in the trash for the trash_retention window (default
+30 days); 'roborev undo <operation-id>' restores them.
+
 The argument can be either the repository path or its display name.
 When given a path inside a repository, it resolves to the repo root.
 
@@ -299,13 +306,13 @@ Examples:
 			}
 			defer db.Close()
 
-			repo, err := db.FindRepo(identifier)
+			repo, err := db.FindRepo(cmd.Context(), identifier)
 			if err != nil {
 				return fmt.Errorf("repository not found: %s", identifier)
 			}
 
 			// Get stats to show what will be deleted
-			stats, err := db.GetRepoStats(repo.ID)
+			stats, err := db.GetRepoStats(cmd.Context(), repo.ID)
 			if err != nil {
 				return fmt.Errorf("get stats: %w", err)
 			}
@@ -329,7 +336,12 @@ Examples:
 				}
 			}
 
-			if err := db.DeleteRepo(repo.ID, cascade); err != nil {
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			op, err := db.TrashRepo(cmd.Context(), repo.ID, cascade, cfg.TrashRetentionDuration())
+			if err != nil {
 				if errors.Is(err, storage.ErrRepoHasJobs) {
 					return fmt.Errorf("cannot delete repository with existing jobs (use --cascade)")
 				}
@@ -341,6 +353,7 @@ Examples:
 			} else {
 				fmt.Printf("Deleted repository %q\n", repo.Name)
 			}
+			fmt.Printf("Undo with 'roborev undo %s' until %s\n", op.ID, op.ExpiresAt.Local().Format("2006-01-02"))
 			return nil
 		},
 	}
@@ -407,12 +420,12 @@ Examples:
 			}
 			defer db.Close()
 
-			source, err := db.FindRepo(sourceIdent)
+			source, err := db.FindRepo(cmd.Context(), sourceIdent)
 			if err != nil {
 				return fmt.Errorf("source repository not found: %s", sourceIdent)
 			}
 
-			target, err := db.FindRepo(targetIdent)
+			target, err := db.FindRepo(cmd.Context(), targetIdent)
 			if err != nil {
 				return fmt.Errorf("target repository not found: %s", targetIdent)
 			}
@@ -422,7 +435,7 @@ Examples:
 			}
 
 			// Get stats
-			sourceStats, err := db.GetRepoStats(source.ID)
+			sourceStats, err := db.GetRepoStats(cmd.Context(), source.ID)
 			if err != nil {
 				return fmt.Errorf("get source stats: %w", err)
 			}
@@ -442,7 +455,7 @@ Examples:
 				}
 			}
 
-			moved, err := db.MergeRepos(source.ID, target.ID)
+			moved, err := db.MergeRepos(cmd.Context(), source.ID, target.ID)
 			if err != nil {
 				return fmt.Errorf("merge repos: %w", err)
 			}
@@ -456,3 +469,68 @@ Examples:
 
 	return cmd
 }
+
+func repoRelocateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "relocate <old> <new-path>",
+		Short: "Point a repository at its new directory after a move",
+		Long: `Update a tracked repository's path after its directory was moved.
+
+The daemon normally detects moves on its own by matching the origin remote
+URL or the repository's root commit against repos whose directory no longer
+exists. Use relocate when detection isn't possible, e.g. when several stale
+entries match or the old directory still exists.
+
+If roborev already created a separate entry for the new path, its reviews
+are merged into the relocated repository.
+
+The first argument can be the old path or the repository's display name.
+The new path must be an existing git repository.
+
+Examples:
+  roborev repo relocate ~/src/old-project ~/code/new-project
+  roborev repo relocate old-project .
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldIdent := resolveRepoIdentifier(args[0])
+			newRoot, err := git.GetMainRepoRoot(args[1])
+			if err != nil {
+				return fmt.Errorf("new path is not a git repository: %s", args[1])
+			}
+
+			dbPath := storage.DefaultDBPath()
+			if dbPath == "" {
+				return fmt.Errorf("cannot determine database path")
+			}
+
+			db, err := storage.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			repo, err := db.FindRepo(cmd.Context(), oldIdent)
+			if err != nil {
+				return fmt.Errorf("repository not found: %s", oldIdent)
+			}
+			if repo.RootPath == newRoot {
+				fmt.Printf("%s already points at %s\n", repo.Name, newRoot)
+				return nil
+			}
+
+			oldPath := repo.RootPath
+			if err := db.RelocateRepo(repo.ID, newRoot); err != nil {
+				return err
+			}
+			if rootCommit, err := git.GetRootCommit(newRoot); err == nil {
+				if err := db.SetRepoRootCommit(repo.ID, rootCommit); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("Relocated %s -> %s\n", oldPath, newRoot)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func reportCmd() *cobra.Command {
+	var (
+		author string
+		since  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show a report card of the findings in your commits",
+		Long: `Show a report card of the findings in the reviews of your own commits:
+how many commits were reviewed and passed, and their findings by category
+(security, error handling, testing and so on) and severity. Findings
+dismissed with 'roborev triage' or suppressed by .roborev-ignore are left
+out.
+
+The report is meant for self-improvement, so it is opt-in and only covers
+your own commits. Enable it with author_reports = true in
+~/.roborev/config.toml. You are the git identity of the current repo, as its
+.mailmap maps it, under any of your aliases ('roborev author'); other
+authors cannot be reported on.
+
+Examples:
+  roborev report
+  roborev report --author me --since 90d
+  roborev report --since 2026-01-01`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			if !cfg.AuthorReports {
+				return errors.New("report cards are opt-in: set author_reports = true in " + config.GlobalConfigPath())
+			}
+
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			self, err := resolveReportAuthor(db, wd, author)
+			if err != nil {
+				return err
+			}
+
+			card, err := db.GetReportCard(self, sinceTime)
+			if err != nil {
+				return fmt.Errorf("load report card: %w", err)
+			}
+			printReportCard(cmd.OutOrStdout(), card)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&author, "author", "me", "author to report on, which must be you")
+	cmd.Flags().StringVar(&since, "since", "90d", "history to report on, e.g. 90d, 12w or 2026-01-31")
+	return cmd
+}
+
+// resolveReportAuthor returns the author a report card covers: the user's
+// own git identity in repoPath after aliasing. author is "me" or a name or
+// email of the user; any other author is refused.
+func resolveReportAuthor(db *storage.DB, repoPath, author string) (string, error) {
+	name, email, err := git.GetUserIdentity(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("get your git identity: %w", err)
+	}
+	self, err := db.CanonicalAuthor(name)
+	if err != nil {
+		return "", err
+	}
+
+	author = strings.TrimSpace(author)
+	if author == "" || author == "me" || strings.EqualFold(author, email) {
+		return self, nil
+	}
+	requested, err := db.CanonicalAuthor(author)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(requested, self) {
+		return "", fmt.Errorf("report cards only cover your own commits, and you are %s <%s>", self, email)
+	}
+	return self, nil
+}
+
+// printReportCard prints the commit counts of a report card and its finding
+// categories, most findings first
+func printReportCard(w io.Writer, card *storage.ReportCard) {
+	since := card.Since.Format("2006-01-02")
+	if card.Commits == 0 {
+		fmt.Fprintf(w, "No reviewed commits by %s since %s.\n", card.Author, since)
+		return
+	}
+	fmt.Fprintf(w, "Report card for %s since %s\n", card.Author, since)
+	fmt.Fprintf(w, "%d commits reviewed, %d passed\n", card.Commits, card.Passed)
+	if len(card.Categories) == 0 {
+		fmt.Fprintln(w, "No findings.")
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CATEGORY\tFINDINGS\tCRITICAL\tHIGH\tMEDIUM\tLOW")
+	for _, c := range card.Categories {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", c.Category, c.Findings,
+			c.Severities["critical"], c.Severities["high"], c.Severities["medium"], c.Severities["low"])
+	}
+	tw.Flush()
+}
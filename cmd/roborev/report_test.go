@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestReportCmd(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("ROBOREV_DATA_DIR", dataDir)
+	repo := newTestGitRepo(t) // Commits as Test <test@test.com>
+	chdir(t, repo.Dir)
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := db.GetOrCreateRepo(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, author := range []string{"Test", "Someone Else"} {
+		commit, err := db.GetOrCreateCommit(t.Context(), r.ID, "sha-"+author, author, "Subject", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: r.ID, CommitID: commit.ID, GitRef: commit.SHA, Agent: "test"}); err != nil {
+			t.Fatal(err)
+		}
+		job, err := db.ClaimJob(t.Context(), "worker-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.CompleteJob(t.Context(), job.ID, "test", "prompt", "- High: SQL injection in the query"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := reportCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "opt-in") {
+		t.Fatalf("expected an opt-in error before enabling reports, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, "config.toml"), []byte("author_reports = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := run("--since", "7d")
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	for _, want := range []string{"Report card for Test", "1 commits reviewed, 0 passed", "security"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	if _, err := run("--author", "test@test.com"); err != nil {
+		t.Errorf("expected reporting on your own email to work, got %v", err)
+	}
+	if _, err := run("--author", "Someone Else"); err == nil || !strings.Contains(err.Error(), "your own commits") {
+		t.Errorf("expected reporting on another author to be refused, got %v", err)
+	}
+}
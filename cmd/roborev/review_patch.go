@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func reviewPatchCmd() *cobra.Command {
+	var (
+		repoPath   string
+		agent      string
+		model      string
+		reasoning  string
+		reviewType string
+		fast       bool
+		quiet      bool
+		wait       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "review-patch <file|->",
+		Short: "Review a patch file that is not in git",
+		Long: `Review a patch or diff file that has not been applied or committed, such
+as an emailed patch or the output of 'git format-patch'. Use - to read the
+patch from stdin.
+
+The patch is reviewed against the repository as it is now and stored with
+a synthetic ref of the form patch:<name>@<hash>.
+
+Examples:
+  roborev review-patch fix.patch --repo .
+  roborev review-patch 0001-add-cache.patch --wait
+  git format-patch -3 --stdout | roborev review-patch -`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reasoning = resolveReasoningWithFast(reasoning, fast, cmd.Flags().Changed("reasoning"))
+
+			if reviewType != "" && reviewType != "security" && reviewType != "design" {
+				return fmt.Errorf("invalid --type %q (valid: security, design)", reviewType)
+			}
+
+			if repoPath == "" {
+				repoPath = "."
+			}
+			root, err := git.GetRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			var data []byte
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("read patch: %w", err)
+			}
+			patch := string(data)
+			if !looksLikePatch(patch) {
+				return fmt.Errorf("%s does not contain a diff", args[0])
+			}
+			if len(patch) > MaxDirtyDiffSize {
+				return fmt.Errorf("patch too large (%d bytes, max %d bytes)", len(patch), MaxDirtyDiffSize)
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return err
+			}
+
+			reqFields := map[string]interface{}{
+				"repo_path":    root,
+				"git_ref":      patchRef(args[0], data),
+				"branch":       git.GetCurrentBranch(root),
+				"agent":        agent,
+				"model":        model,
+				"reasoning":    reasoning,
+				"review_type":  reviewType,
+				"diff_content": patch,
+			}
+			reqBody, _ := json.Marshal(reqFields)
+
+			resp, err := http.Post(serverAddr+"/api/enqueue", "application/json", bytes.NewReader(reqBody))
+			if err != nil {
+				return fmt.Errorf("failed to connect to daemon: %w", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			// Handle skipped response (200 OK with skipped flag)
+			if resp.StatusCode == http.StatusOK {
+				var skipResp struct {
+					Skipped bool   `json:"skipped"`
+					Reason  string `json:"reason"`
+				}
+				if err := json.Unmarshal(body, &skipResp); err == nil && skipResp.Skipped {
+					if !quiet {
+						cmd.Printf("Skipped: %s\n", skipResp.Reason)
+					}
+					return nil
+				}
+			}
+
+			if resp.StatusCode != http.StatusCreated {
+				return fmt.Errorf("review failed: %s", body)
+			}
+
+			var job storage.ReviewJob
+			json.Unmarshal(body, &job)
+
+			if !quiet {
+				cmd.Printf("Enqueued job %d for %s (agent: %s)\n", job.ID, shortRef(job.GitRef), job.Agent)
+			}
+
+			if wait {
+				err := waitForJob(cmd, serverAddr, job.ID, quiet)
+				if _, isExitErr := err.(*exitError); isExitErr {
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+				}
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository the patch applies to (default: current directory)")
+	cmd.Flags().StringVar(&agent, "agent", "", "agent to use (codex, claude-code, gemini, copilot, opencode, cursor)")
+	cmd.Flags().StringVar(&model, "model", "", "model for agent (format varies: opencode uses provider/model, others use model name)")
+	cmd.Flags().StringVar(&reasoning, "reasoning", "", "reasoning level: thorough (default), standard, or fast")
+	cmd.Flags().BoolVar(&fast, "fast", false, "shorthand for --reasoning fast")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
+	cmd.Flags().BoolVar(&wait, "wait", false, "wait for review to complete and show result")
+	cmd.Flags().StringVar(&reviewType, "type", "", "review type (security, design) — changes system prompt")
+
+	return cmd
+}
+
+// looksLikePatch reports whether content contains a unified or git diff
+func looksLikePatch(content string) bool {
+	if strings.Contains(content, "diff --git ") {
+		return true
+	}
+	return (strings.HasPrefix(content, "--- ") || strings.Contains(content, "\n--- ")) &&
+		strings.Contains(content, "\n+++ ")
+}
+
+// patchRef returns the synthetic git ref for a patch read from path. The
+// content hash keeps different versions of a patch with the same name apart.
+func patchRef(path string, content []byte) string {
+	name := "stdin"
+	if path != "-" {
+		name = filepath.Base(path)
+	}
+	sum := sha256.Sum256(content)
+	return storage.PatchRefPrefix + name + "@" + hex.EncodeToString(sum[:])[:8]
+}
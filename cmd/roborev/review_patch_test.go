@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestReviewPatchCmd(t *testing.T) {
+	var received map[string]string
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/enqueue" {
+			json.NewDecoder(r.Body).Decode(&received)
+			job := storage.ReviewJob{ID: 1, GitRef: received["git_ref"], Agent: "test"}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(job)
+		}
+	}))
+	defer cleanup()
+
+	repo := newTestGitRepo(t)
+	repo.CommitFile("foo.go", "package foo\n", "initial")
+
+	patch := "From 1234 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] add bar\n\n" +
+		"diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n@@ -1 +1,2 @@\n package foo\n+func bar() {}\n"
+	patchFile := filepath.Join(t.TempDir(), "0001-add-bar.patch")
+	if err := os.WriteFile(patchFile, []byte(patch), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("enqueues patch with synthetic ref", func(t *testing.T) {
+		received = nil
+		var out bytes.Buffer
+		cmd := reviewPatchCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{patchFile, "--repo", repo.Dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("review-patch failed: %v", err)
+		}
+
+		ref := received["git_ref"]
+		if !strings.HasPrefix(ref, "patch:0001-add-bar.patch@") || len(ref) != len("patch:0001-add-bar.patch@")+8 {
+			t.Errorf("unexpected git_ref %q", ref)
+		}
+		if received["diff_content"] != patch {
+			t.Error("expected full patch content in diff_content")
+		}
+		if received["repo_path"] != repo.Dir {
+			t.Errorf("expected repo_path %q, got %q", repo.Dir, received["repo_path"])
+		}
+		if !strings.Contains(out.String(), "patch:0001-add-bar.patch ") {
+			t.Errorf("expected patch name in output, got %q", out.String())
+		}
+	})
+
+	t.Run("reads stdin", func(t *testing.T) {
+		received = nil
+		cmd := reviewPatchCmd()
+		cmd.SetIn(strings.NewReader(patch))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"-", "--repo", repo.Dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("review-patch failed: %v", err)
+		}
+		if !strings.HasPrefix(received["git_ref"], "patch:stdin@") {
+			t.Errorf("unexpected git_ref %q", received["git_ref"])
+		}
+	})
+
+	t.Run("rejects non-diff file", func(t *testing.T) {
+		received = nil
+		notes := filepath.Join(t.TempDir(), "notes.txt")
+		os.WriteFile(notes, []byte("just some notes\n"), 0644)
+		cmd := reviewPatchCmd()
+		cmd.SetArgs([]string{notes, "--repo", repo.Dir})
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "does not contain a diff") {
+			t.Errorf("expected not-a-diff error, got %v", err)
+		}
+		if received != nil {
+			t.Error("should not enqueue a non-diff file")
+		}
+	})
+}
+
+func TestShortRefPatch(t *testing.T) {
+	if got := shortRef("patch:fix@v2.patch@0123abcd"); got != "patch:fix@v2.patch" {
+		t.Errorf("shortRef = %q", got)
+	}
+}
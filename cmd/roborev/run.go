@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/roborev-dev/roborev/internal/cliprof"
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/storage"
@@ -78,14 +79,6 @@ Examples:
 	return cmd
 }
 
-// promptCmd returns a hidden alias for backward compatibility
-func promptCmd() *cobra.Command {
-	cmd := runCmd()
-	cmd.Use = "prompt [task]"
-	cmd.Hidden = true
-	return cmd
-}
-
 func runPrompt(cmd *cobra.Command, args []string, agentName, modelStr, reasoningStr string, wait, quiet, includeContext, agentic bool, label string) error {
 	// Get prompt from args or stdin
 	var promptText string
@@ -149,6 +142,7 @@ func runPrompt(cmd *cobra.Command, args []string, agentName, modelStr, reasoning
 		"reasoning":     reasoningStr,
 		"custom_prompt": fullPrompt,
 		"agentic":       agentic,
+		"interactive":   wait,
 	})
 
 	resp, err := http.Post(serverAddr+"/api/enqueue", "application/json", bytes.NewReader(reqBody))
@@ -191,6 +185,7 @@ var promptPollInterval = 500 * time.Millisecond
 // Unlike waitForJob, this doesn't apply verdict-based exit codes since prompt
 // jobs don't have PASS/FAIL verdicts.
 func waitForPromptJob(cmd *cobra.Command, serverAddr string, jobID int64, quiet bool) error {
+	defer cliprof.Span(cliprof.PhaseAgent)()
 	client := &http.Client{Timeout: 5 * time.Second}
 
 	if !quiet {
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func searchCmd() *cobra.Command {
+	var (
+		symbol   string
+		text     string
+		agent    string
+		since    string
+		repoPath string
+		allRepos bool
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Find reviews by what they changed or said",
+		Long: `Find reviews of commits and ranges that changed a function, method or type
+(--symbol), or whose output or prompt mention some text (--text).
+
+Changed symbols are recorded when a review runs: Go files are parsed, and
+definitions in Python, Rust, JavaScript, TypeScript, Ruby and similar
+languages are recognized by keyword. Methods are recorded as Type.Method; a
+plain name also matches methods of that name.
+
+Text search matches reviews containing every word, ignoring case; quote a
+phrase to match it exactly, and end a word with * to match a prefix.
+
+Examples:
+  roborev search --symbol ParseConfig
+  roborev search --symbol Config.Load
+  roborev search --symbol Load --all
+  roborev search --text 'ParseConfig "nil map"'
+  roborev search --text 'Parse*' --agent codex --since 30d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if symbol == "" && text == "" {
+				return fmt.Errorf("choose what to search for (available: --symbol, --text)")
+			}
+			if symbol != "" && text != "" {
+				return fmt.Errorf("--symbol and --text are mutually exclusive")
+			}
+			var sinceTime time.Time
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				sinceTime = t
+			}
+
+			repo := ""
+			if !allRepos {
+				if repoPath == "" {
+					repoPath = "."
+				}
+				root, err := git.GetMainRepoRoot(repoPath)
+				if err != nil {
+					return fmt.Errorf("not a git repository (use --all for every repo): %w", err)
+				}
+				repo = root
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+
+			if text != "" {
+				resp, err := searchReviews(getDaemonAddr(), text, repo, agent, sinceTime, limit)
+				if err != nil {
+					return err
+				}
+				printReviewMatches(cmd.OutOrStdout(), text, resp, allRepos)
+				return nil
+			}
+
+			resp, err := searchSymbol(getDaemonAddr(), symbol, repo, limit)
+			if err != nil {
+				return err
+			}
+			printSymbolMatches(cmd.OutOrStdout(), symbol, resp, allRepos)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&symbol, "symbol", "", "function, method or type name, e.g. ParseConfig or Config.Load")
+	cmd.Flags().StringVar(&text, "text", "", "words or \"phrases\" in review outputs and prompts")
+	cmd.Flags().StringVar(&agent, "agent", "", "only reviews by this agent (with --text)")
+	cmd.Flags().StringVar(&since, "since", "", "only reviews since, e.g. 30d, 12w or 2026-01-31 (with --text)")
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().BoolVar(&allRepos, "all", false, "search all repos")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum number of results")
+
+	return cmd
+}
+
+func searchSymbol(addr, symbol, repo string, limit int) (daemon.SymbolSearchResponse, error) {
+	var result daemon.SymbolSearchResponse
+	params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+	err := getSearch(addr+"/api/search", params, repo, &result)
+	return result, err
+}
+
+func searchReviews(addr, text, repo, agent string, since time.Time, limit int) (daemon.ReviewSearchResponse, error) {
+	var result daemon.ReviewSearchResponse
+	params := url.Values{"q": {text}, "limit": {strconv.Itoa(limit)}}
+	if agent != "" {
+		params.Set("agent", agent)
+	}
+	if !since.IsZero() {
+		params.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	err := getSearch(addr+"/api/search/reviews", params, repo, &result)
+	return result, err
+}
+
+// getSearch decodes the results of a daemon search endpoint into result,
+// leaving it empty when the repo has never been reviewed
+func getSearch(endpoint string, params url.Values, repo string, result any) error {
+	if repo != "" {
+		params.Set("repo", repo)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && repo != "" {
+		// Repo has never been reviewed
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode results: %w", err)
+	}
+	return nil
+}
+
+func printSymbolMatches(w io.Writer, symbol string, resp daemon.SymbolSearchResponse, showRepo bool) {
+	if len(resp.Matches) == 0 {
+		fmt.Fprintf(w, "No reviews changed %s\n", symbol)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "JOB\tREF\tRESULT\tAGENT\tSYMBOL\tFILE\tSUBJECT"
+	if showRepo {
+		header = "JOB\tREPO\tREF\tRESULT\tAGENT\tSYMBOL\tFILE\tSUBJECT"
+	}
+	fmt.Fprintln(tw, header)
+	for _, m := range resp.Matches {
+		result := string(m.Status)
+		if m.Verdict != "" {
+			result = verdictLabel(m.Verdict)
+		}
+		fmt.Fprintf(tw, "%d\t", m.JobID)
+		if showRepo {
+			fmt.Fprintf(tw, "%s\t", m.RepoName)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", shortRef(m.GitRef), result, m.Agent, m.Name, m.File, m.CommitSubject)
+	}
+	tw.Flush()
+}
+
+func printReviewMatches(w io.Writer, text string, resp daemon.ReviewSearchResponse, showRepo bool) {
+	if len(resp.Matches) == 0 {
+		fmt.Fprintf(w, "No reviews mention %s\n", text)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "JOB\tREF\tRESULT\tAGENT\tDATE\tMATCH"
+	if showRepo {
+		header = "JOB\tREPO\tREF\tRESULT\tAGENT\tDATE\tMATCH"
+	}
+	fmt.Fprintln(tw, header)
+	for _, m := range resp.Matches {
+		result := "-"
+		if m.Verdict != "" {
+			result = verdictLabel(m.Verdict)
+		}
+		fmt.Fprintf(tw, "%d\t", m.JobID)
+		if showRepo {
+			fmt.Fprintf(tw, "%s\t", m.RepoName)
+		}
+		// Snippets span lines of the review; keep each match on one row
+		snippet := strings.Join(strings.Fields(m.Snippet), " ")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", shortRef(m.GitRef), result, m.Agent,
+			m.CreatedAt.Local().Format("2006-01-02"), snippet)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestSearchSymbol(t *testing.T) {
+	var gotQuery string
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode(daemon.SymbolSearchResponse{Matches: []storage.SymbolMatch{{
+			ChangedSymbol: storage.ChangedSymbol{Name: "Config.Load", File: "config.go"},
+			JobID:         7,
+			RepoName:      "myrepo",
+			GitRef:        "abc1234def5678",
+			Agent:         "codex",
+			Status:        storage.JobStatusDone,
+			CommitSubject: "fix loading",
+			Verdict:       "F",
+		}}})
+	}))
+	defer cleanup()
+
+	resp, err := searchSymbol(ts.URL, "Load", "/src/myrepo", 10)
+	if err != nil {
+		t.Fatalf("searchSymbol: %v", err)
+	}
+	for _, want := range []string{"symbol=Load", "repo=%2Fsrc%2Fmyrepo", "limit=10"} {
+		if !strings.Contains(gotQuery, want) {
+			t.Errorf("expected %q in query %q", want, gotQuery)
+		}
+	}
+
+	var out bytes.Buffer
+	printSymbolMatches(&out, "Load", resp, true)
+	for _, want := range []string{"REPO", "myrepo", "abc1234", "FAIL", "Config.Load", "config.go", "fix loading"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPrintSymbolMatchesEmpty(t *testing.T) {
+	var out bytes.Buffer
+	printSymbolMatches(&out, "ParseConfig", daemon.SymbolSearchResponse{}, false)
+	if got := out.String(); got != "No reviews changed ParseConfig\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestSearchReviews(t *testing.T) {
+	var gotQuery string
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search/reviews" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode(daemon.ReviewSearchResponse{Matches: []storage.ReviewSearchMatch{{
+			ReviewID:  3,
+			JobID:     7,
+			RepoName:  "myrepo",
+			GitRef:    "abc1234def5678",
+			Agent:     "codex",
+			CreatedAt: time.Date(2026, 3, 4, 12, 0, 0, 0, time.Local),
+			Verdict:   "F",
+			Snippet:   "...- High: [ParseConfig]\nwrites to a nil map",
+		}}})
+	}))
+	defer cleanup()
+
+	since := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	resp, err := searchReviews(ts.URL, "ParseConfig", "/src/myrepo", "codex", since, 10)
+	if err != nil {
+		t.Fatalf("searchReviews: %v", err)
+	}
+	for _, want := range []string{"q=ParseConfig", "repo=%2Fsrc%2Fmyrepo", "agent=codex", "since=2026-01-31T00%3A00%3A00Z", "limit=10"} {
+		if !strings.Contains(gotQuery, want) {
+			t.Errorf("expected %q in query %q", want, gotQuery)
+		}
+	}
+
+	var out bytes.Buffer
+	printReviewMatches(&out, "ParseConfig", resp, true)
+	for _, want := range []string{"REPO", "myrepo", "abc1234", "FAIL", "2026-03-04", "[ParseConfig] writes to a nil map"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	printReviewMatches(&out, "nothing", daemon.ReviewSearchResponse{}, false)
+	if got := out.String(); got != "No reviews mention nothing\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
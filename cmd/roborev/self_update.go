@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/skills"
+	"github.com/roborev-dev/roborev/internal/update"
+	"github.com/roborev-dev/roborev/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func selfUpdateCmd() *cobra.Command {
+	var checkOnly bool
+	var yes bool
+	var force bool
+	var drainTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "self-update",
+		Aliases: []string{"update"},
+		Short:   "Update roborev to the latest version",
+		Long: `Check for and install roborev updates.
+
+Shows exactly what will be downloaded and where it will be installed.
+Requires confirmation before making changes (use --yes to skip).
+
+The download is verified against the release's SHA256 checksum (and, for
+builds with a release signing key, the checksum file's signature) and then
+swapped in for the current binary in a single rename, so an interrupted
+update never leaves a broken binary behind.
+
+A running daemon is drained first: it stops claiming new jobs and waits up
+to --drain-timeout for running ones. It is then restarted from the new
+binary and picks up the queued jobs. If the update fails, the queue is
+resumed on the current daemon.
+
+Dev builds are not replaced by default. Use --force to install the latest
+official release over a dev build.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Println("Checking for updates...")
+
+			info, err := update.CheckForUpdate(true) // Force check, ignore cache
+			if err != nil {
+				return fmt.Errorf("check for updates: %w", err)
+			}
+
+			if info == nil {
+				fmt.Printf("Already running latest version (%s)\n", version.Version)
+				return nil
+			}
+
+			fmt.Printf("\n  Current version: %s\n", info.CurrentVersion)
+			fmt.Printf("  Latest version:  %s\n", info.LatestVersion)
+			if info.IsDevBuild {
+				fmt.Println("\nYou're running a dev build. Latest official release available.")
+			} else {
+				fmt.Println("\nUpdate available!")
+			}
+			fmt.Println("\nDownload:")
+			fmt.Printf("  URL:  %s\n", info.DownloadURL)
+			fmt.Printf("  Size: %s\n", update.FormatSize(info.Size))
+			if info.Checksum != "" {
+				fmt.Printf("  SHA256: %s\n", info.Checksum)
+			}
+			if info.Signed {
+				fmt.Println("  Signature: verified")
+			}
+
+			// Show install location
+			currentExe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("find executable: %w", err)
+			}
+			currentExe, _ = filepath.EvalSymlinks(currentExe)
+			binDir := filepath.Dir(currentExe)
+
+			fmt.Println("\nInstall location:")
+			fmt.Printf("  %s\n", binDir)
+
+			if checkOnly {
+				if info.IsDevBuild {
+					fmt.Println("\nUse --force to install the latest official release.")
+				}
+				return nil
+			}
+
+			// Dev builds require --force to update
+			if info.IsDevBuild && !force {
+				fmt.Println("\nUse --force to install the latest official release.")
+				return nil
+			}
+
+			// Confirm
+			if !yes {
+				fmt.Print("\nProceed with update? [y/N] ")
+				var response string
+				fmt.Scanln(&response)
+				if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+					fmt.Println("Update cancelled")
+					return nil
+				}
+			}
+
+			fmt.Println()
+
+			// Progress display
+			var lastPercent int
+			progressFn := func(downloaded, total int64) {
+				if total > 0 {
+					percent := int(downloaded * 100 / total)
+					if percent != lastPercent {
+						fmt.Printf("\rDownloading... %d%% (%s / %s)",
+							percent, update.FormatSize(downloaded), update.FormatSize(total))
+						lastPercent = percent
+					}
+				}
+			}
+
+			// Perform update with the daemon drained, then restart it
+			err = swapDaemonBinary(os.Stdout, drainTimeout, false, func() error {
+				if err := update.PerformUpdate(info, progressFn); err != nil {
+					return fmt.Errorf("update failed: %w", err)
+				}
+				fmt.Printf("\nUpdated to %s\n", info.LatestVersion)
+				removeOldDaemonBinary(binDir)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			// Update skills using the NEW binary (current process has old embedded skills)
+			// Use "skills update" to only update agents that already have skills installed
+			if skills.IsInstalled(skills.AgentClaude) || skills.IsInstalled(skills.AgentCodex) {
+				fmt.Print("Updating skills... ")
+				newBinary := filepath.Join(binDir, "roborev")
+				if runtime.GOOS == "windows" {
+					newBinary += ".exe"
+				}
+				skillsCmd := exec.Command(newBinary, "skills", "update")
+				if output, err := skillsCmd.CombinedOutput(); err != nil {
+					fmt.Printf("warning: %v\n", err)
+				} else {
+					// Parse output to show what was updated
+					lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+					for _, line := range lines {
+						if strings.Contains(line, "updated") {
+							fmt.Println(line)
+						}
+					}
+					if !strings.Contains(string(output), "updated") {
+						fmt.Println("OK")
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "only check for updates, don't install")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip confirmation prompt")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "replace dev build with latest official release")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 10*time.Minute, "how long to wait for the daemon's running jobs")
+
+	return cmd
+}
+
+// removeOldDaemonBinary cleans up the roborevd binary left over from before
+// the daemon was consolidated into roborev
+func removeOldDaemonBinary(binDir string) {
+	oldDaemonPath := filepath.Join(binDir, "roborevd")
+	if runtime.GOOS == "windows" {
+		oldDaemonPath += ".exe"
+	}
+	if _, err := os.Stat(oldDaemonPath); err == nil {
+		fmt.Print("Removing old roborevd binary... ")
+		if err := os.Remove(oldDaemonPath); err != nil {
+			fmt.Printf("warning: %v\n", err)
+		} else {
+			fmt.Println("OK")
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSelfUpdateCmdKeepsUpdateAlias(t *testing.T) {
+	cmd := selfUpdateCmd()
+	if cmd.Name() != "self-update" {
+		t.Errorf("Name() = %q, want self-update", cmd.Name())
+	}
+	if !cmd.HasAlias("update") {
+		t.Error("self-update should keep the update alias")
+	}
+	if f := cmd.Flags().Lookup("drain-timeout"); f == nil || f.DefValue != "10m0s" {
+		t.Errorf("drain-timeout flag = %v, want default 10m0s", f)
+	}
+}
+
+func TestSwapDaemonBinaryWithoutDaemon(t *testing.T) {
+	t.Run("install error is returned", func(t *testing.T) {
+		var out bytes.Buffer
+		installErr := errors.New("checksum mismatch")
+		err := swapDaemonBinary(&out, time.Second, false, func() error { return installErr })
+		if !errors.Is(err, installErr) {
+			t.Fatalf("err = %v, want %v", err, installErr)
+		}
+		if out.Len() != 0 {
+			t.Errorf("unexpected output with no daemon running: %q", out.String())
+		}
+	})
+
+	t.Run("no daemon is started", func(t *testing.T) {
+		var out bytes.Buffer
+		installed := false
+		err := swapDaemonBinary(&out, time.Second, false, func() error {
+			installed = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("swapDaemonBinary: %v", err)
+		}
+		if !installed {
+			t.Error("install was not run")
+		}
+		if out.Len() != 0 {
+			t.Errorf("unexpected output with no daemon running: %q", out.String())
+		}
+	})
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// skipGateEnv bypasses the pre-receive review gate when set to 1 or true
+const skipGateEnv = "ROBOREV_SKIP_GATE"
+
+// skipGatePushOption bypasses the gate when sent with "git push -o"
+const skipGatePushOption = "roborev.skip-gate"
+
+// severityRank orders finding severities for gating
+var severityRank = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// refUpdate is one line of pre-receive/post-receive hook input
+type refUpdate struct {
+	OldSHA string
+	NewSHA string
+	Ref    string
+}
+
+func isZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// parseRefUpdates reads "<old> <new> <ref>" lines from hook stdin and
+// returns the branch updates, skipping deletions and non-branch refs.
+func parseRefUpdates(r io.Reader) ([]refUpdate, error) {
+	var updates []refUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid hook input line: %q", scanner.Text())
+		}
+		u := refUpdate{OldSHA: fields[0], NewSHA: fields[1], Ref: fields[2]}
+		if isZeroSHA(u.NewSHA) || !strings.HasPrefix(u.Ref, "refs/heads/") {
+			continue
+		}
+		updates = append(updates, u)
+	}
+	return updates, scanner.Err()
+}
+
+// pushedCommits returns the commits a ref update introduces (oldest first)
+// and the commit they build on, which is empty when the push starts from a
+// root commit.
+func pushedCommits(repoPath string, u refUpdate) (base string, commits []string, err error) {
+	if !isZeroSHA(u.OldSHA) {
+		commits, err = git.GetRangeCommits(repoPath, u.OldSHA+".."+u.NewSHA)
+		return u.OldSHA, commits, err
+	}
+	commits, err = git.GetNewBranchCommits(repoPath, u.NewSHA, u.Ref)
+	if err != nil || len(commits) == 0 {
+		return "", commits, err
+	}
+	if parent, err := git.ResolveSHA(repoPath, commits[0]+"^"); err == nil {
+		base = parent
+	}
+	return base, commits, nil
+}
+
+// pushReviewRef returns the git ref to review for a push: the commit itself
+// for a single commit, otherwise the range from base to the new tip.
+func pushReviewRef(base string, commits []string, newSHA string) string {
+	if len(commits) == 1 || base == "" {
+		return newSHA
+	}
+	return base + ".." + newSHA
+}
+
+// gateBypassed reports whether the pusher or server asked to skip the gate,
+// through the environment or a push option.
+func gateBypassed(getenv func(string) string) bool {
+	if v := strings.ToLower(getenv(skipGateEnv)); v == "1" || v == "true" {
+		return true
+	}
+	n, _ := strconv.Atoi(getenv("GIT_PUSH_OPTION_COUNT"))
+	for i := 0; i < n; i++ {
+		if getenv(fmt.Sprintf("GIT_PUSH_OPTION_%d", i)) == skipGatePushOption {
+			return true
+		}
+	}
+	return false
+}
+
+// blockingFindings returns the findings in a review output at or above
+// minSeverity
+func blockingFindings(output, minSeverity string) []storage.Finding {
+	var blocking []storage.Finding
+	for _, f := range storage.ExtractFindings(output) {
+		if severityRank[f.Severity] >= severityRank[minSeverity] {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+func serverHookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "server-hook",
+		Short: "Run reviews from a git server's receive hooks",
+		Long: `Run reviews from the pre-receive and post-receive hooks of a bare repository
+on a self-hosted git server. The roborev daemon must run on the same host.
+
+The daemon keeps its own clone of each hosted repository (a mirror under the
+roborev data directory) and reviews pushed commits there, so the bare
+repository itself is never modified.
+
+post-receive queues a review for every pushed branch and never rejects a
+push. pre-receive reviews the pushed changes before they are accepted and
+rejects the push when a review reports findings at or above --min-severity.
+The gate is skipped when ROBOREV_SKIP_GATE=1 is set for the hook or when the
+pusher runs "git push -o roborev.skip-gate" (the server needs
+receive.advertisePushOptions=true). If the daemon is unreachable or a review
+fails or times out, the push is allowed.
+
+Example hooks/post-receive:
+  #!/bin/sh
+  exec roborev server-hook post-receive
+
+Example hooks/pre-receive:
+  #!/bin/sh
+  exec roborev server-hook pre-receive --min-severity high`,
+	}
+	cmd.AddCommand(postReceiveHookCmd())
+	cmd.AddCommand(preReceiveHookCmd())
+	return cmd
+}
+
+func postReceiveHookCmd() *cobra.Command {
+	var repoPath, agentName string
+
+	cmd := &cobra.Command{
+		Use:   "post-receive",
+		Short: "Queue reviews for pushed branches (reads hook input from stdin)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			updates, err := parseRefUpdates(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(updates) == 0 {
+				return nil
+			}
+			source, err := hookRepoPath(repoPath)
+			if err != nil {
+				return err
+			}
+			// The push is already accepted, so report problems without failing
+			if err := ensureDaemon(); err != nil {
+				fmt.Fprintf(out, "roborev: daemon not available, skipping reviews: %v\n", err)
+				return nil
+			}
+			addr := getDaemonAddr()
+			mirror, err := syncMirror(addr, source)
+			if err != nil {
+				fmt.Fprintf(out, "roborev: %v\n", err)
+				return nil
+			}
+
+			for _, u := range updates {
+				branch := strings.TrimPrefix(u.Ref, "refs/heads/")
+				base, commits, err := pushedCommits(source, u)
+				if err != nil {
+					fmt.Fprintf(out, "roborev: %s: %v\n", branch, err)
+					continue
+				}
+				if len(commits) == 0 {
+					continue
+				}
+				gitRef := pushReviewRef(base, commits, u.NewSHA)
+				job, err := enqueueHookJob(addr, daemon.EnqueueRequest{
+					RepoPath: mirror,
+					GitRef:   gitRef,
+					Branch:   branch,
+					Agent:    agentName,
+				})
+				if err != nil {
+					fmt.Fprintf(out, "roborev: %s: %v\n", branch, err)
+					continue
+				}
+				fmt.Fprintf(out, "roborev: queued review of %s on %s (job %d)\n", shortRef(gitRef), branch, job.ID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to the hosted repository (default: current git directory)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "agent to use (default: from config)")
+	return cmd
+}
+
+func preReceiveHookCmd() *cobra.Command {
+	var (
+		repoPath    string
+		agentName   string
+		minSeverity string
+		timeout     time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pre-receive",
+		Short: "Reject pushes whose review reports severe findings (reads hook input from stdin)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if _, ok := severityRank[strings.ToLower(minSeverity)]; !ok {
+				return fmt.Errorf("invalid --min-severity %q (valid: critical, high, medium, low)", minSeverity)
+			}
+			minSeverity = strings.ToLower(minSeverity)
+
+			updates, err := parseRefUpdates(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(updates) == 0 {
+				return nil
+			}
+			if gateBypassed(os.Getenv) {
+				fmt.Fprintln(out, "roborev: review gate bypassed")
+				return nil
+			}
+			source, err := hookRepoPath(repoPath)
+			if err != nil {
+				return err
+			}
+			if err := ensureDaemon(); err != nil {
+				fmt.Fprintf(out, "roborev: daemon not available, allowing push: %v\n", err)
+				return nil
+			}
+			addr := getDaemonAddr()
+			mirror, err := syncMirror(addr, source)
+			if err != nil {
+				fmt.Fprintf(out, "roborev: %v, allowing push\n", err)
+				return nil
+			}
+
+			rejected := false
+			for _, u := range updates {
+				branch := strings.TrimPrefix(u.Ref, "refs/heads/")
+				blocking, err := gatePush(addr, source, mirror, agentName, minSeverity, timeout, u)
+				if err != nil {
+					fmt.Fprintf(out, "roborev: %s: %v, allowing push\n", branch, err)
+					continue
+				}
+				if len(blocking) == 0 {
+					continue
+				}
+				rejected = true
+				fmt.Fprintf(out, "roborev: %s has %d finding(s) at or above %s severity:\n", branch, len(blocking), minSeverity)
+				for _, f := range blocking {
+					fmt.Fprintf(out, "\n%s\n", f.Text)
+				}
+				fmt.Fprintln(out)
+			}
+			if rejected {
+				fmt.Fprintf(out, "roborev: push rejected; to bypass, push with -o %s\n", skipGatePushOption)
+				return &exitError{code: 1}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to the hosted repository (default: current git directory)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "agent to use (default: from config)")
+	cmd.Flags().StringVar(&minSeverity, "min-severity", "high", "reject pushes with findings at or above this severity: critical, high, medium, low")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time to wait for each review")
+	return cmd
+}
+
+// gatePush reviews the diff a ref update introduces and returns the findings
+// that block it. Pushed objects are still quarantined during pre-receive, so
+// the diff is computed here and reviewed as a pre-captured diff.
+func gatePush(addr, source, mirror, agentName, minSeverity string, timeout time.Duration, u refUpdate) ([]storage.Finding, error) {
+	base, commits, err := pushedCommits(source, u)
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+	if base == "" {
+		base = git.EmptyTreeSHA
+	}
+	diff, err := git.GetRangeDiff(source, base+".."+u.NewSHA)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, nil
+	}
+	if len(diff) > MaxDirtyDiffSize {
+		return nil, fmt.Errorf("diff too large to review (%d bytes)", len(diff))
+	}
+
+	job, err := enqueueHookJob(addr, daemon.EnqueueRequest{
+		RepoPath:    mirror,
+		GitRef:      "dirty",
+		Branch:      strings.TrimPrefix(u.Ref, "refs/heads/"),
+		Agent:       agentName,
+		DiffContent: diff,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		review *storage.Review
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		review, err := waitForReview(job.ID)
+		done <- result{review, err}
+	}()
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return blockingFindings(res.review.Output, minSeverity), nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("review job %d timed out after %s", job.ID, timeout)
+	}
+}
+
+// hookRepoPath returns the hosted repository a hook runs for. Git starts
+// hooks in the repository's git directory.
+func hookRepoPath(repoPath string) (string, error) {
+	if repoPath == "" {
+		repoPath = "."
+	}
+	dir, err := git.GetAbsoluteGitDir(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return dir, nil
+}
+
+// syncMirror asks the daemon to update its clone of source and returns the
+// clone's path
+func syncMirror(addr, source string) (string, error) {
+	reqBody, _ := json.Marshal(daemon.MirrorRequest{Source: source})
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Post(addr+"/api/mirror", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("sync mirror: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("sync mirror: %s", strings.TrimSpace(string(body)))
+	}
+	var result daemon.MirrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("sync mirror: %w", err)
+	}
+	return result.Path, nil
+}
+
+func enqueueHookJob(addr string, req daemon.EnqueueRequest) (*storage.ReviewJob, error) {
+	reqBody, _ := json.Marshal(req)
+	resp, err := http.Post(addr+"/api/enqueue", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("enqueue failed: %s", strings.TrimSpace(string(body)))
+	}
+	var job storage.ReviewJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+func TestParseRefUpdates(t *testing.T) {
+	input := strings.Join([]string{
+		"aaa bbb refs/heads/main",
+		zeroSHA + " ccc refs/heads/feature",
+		"ddd " + zeroSHA + " refs/heads/gone",
+		zeroSHA + " eee refs/tags/v1.0",
+		"",
+	}, "\n")
+
+	updates, err := parseRefUpdates(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseRefUpdates: %v", err)
+	}
+	want := []refUpdate{
+		{OldSHA: "aaa", NewSHA: "bbb", Ref: "refs/heads/main"},
+		{OldSHA: zeroSHA, NewSHA: "ccc", Ref: "refs/heads/feature"},
+	}
+	if fmt.Sprint(updates) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", updates, want)
+	}
+
+	if _, err := parseRefUpdates(strings.NewReader("aaa bbb\n")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+}
+
+func TestPushReviewRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		commits []string
+		want    string
+	}{
+		{"single commit", "base", []string{"new"}, "new"},
+		{"several commits", "base", []string{"a", "new"}, "base..new"},
+		{"from root", "", []string{"root", "new"}, "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pushReviewRef(tt.base, tt.commits, "new"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGateBypassed(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"none", nil, false},
+		{"env var", map[string]string{skipGateEnv: "1"}, true},
+		{"env var false", map[string]string{skipGateEnv: "0"}, false},
+		{"push option", map[string]string{
+			"GIT_PUSH_OPTION_COUNT": "2",
+			"GIT_PUSH_OPTION_0":     "ci.skip",
+			"GIT_PUSH_OPTION_1":     skipGatePushOption,
+		}, true},
+		{"other push option", map[string]string{
+			"GIT_PUSH_OPTION_COUNT": "1",
+			"GIT_PUSH_OPTION_0":     "ci.skip",
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			if got := gateBypassed(getenv); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockingFindings(t *testing.T) {
+	output := "Findings:\n\n- High: SQL injection in query builder\n\n- Low: Typo in comment\n"
+
+	if got := blockingFindings(output, "high"); len(got) != 1 || got[0].Severity != "high" {
+		t.Errorf("min high: got %+v", got)
+	}
+	if got := blockingFindings(output, "low"); len(got) != 2 {
+		t.Errorf("min low: got %d findings, want 2", len(got))
+	}
+	if got := blockingFindings(output, "critical"); len(got) != 0 {
+		t.Errorf("min critical: got %+v", got)
+	}
+}
+
+func TestPushedCommitsBareRepo(t *testing.T) {
+	work := newTestGitRepo(t)
+	first := work.CommitFile("a.txt", "a", "first")
+	bareDir := t.TempDir() + "/hosted.git"
+	work.Run("clone", "--bare", work.Dir, bareDir)
+
+	second := work.CommitFile("a.txt", "b", "second")
+	third := work.CommitFile("a.txt", "c", "third")
+	work.Run("push", bareDir, "HEAD:refs/heads/feature")
+
+	t.Run("new branch", func(t *testing.T) {
+		u := refUpdate{OldSHA: zeroSHA, NewSHA: third, Ref: "refs/heads/feature"}
+		base, commits, err := pushedCommits(bareDir, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base != first || len(commits) != 2 || commits[0] != second {
+			t.Errorf("got base %s commits %v", base, commits)
+		}
+		if ref := pushReviewRef(base, commits, third); ref != first+".."+third {
+			t.Errorf("review ref = %s", ref)
+		}
+	})
+
+	t.Run("branch update", func(t *testing.T) {
+		u := refUpdate{OldSHA: second, NewSHA: third, Ref: "refs/heads/feature"}
+		base, commits, err := pushedCommits(bareDir, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base != second || len(commits) != 1 || commits[0] != third {
+			t.Errorf("got base %s commits %v", base, commits)
+		}
+	})
+
+	t.Run("diff in bare repo", func(t *testing.T) {
+		diff, err := git.GetRangeDiff(bareDir, first+".."+third)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(diff, "+c") {
+			t.Errorf("unexpected diff:\n%s", diff)
+		}
+	})
+}
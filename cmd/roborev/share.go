@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+func shareCmd() *cobra.Command {
+	var (
+		expires string
+		baseURL string
+		list    bool
+		revoke  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "share <job-id>",
+		Short: "Create a link to a review for people without roborev",
+		Long: `Create a short link (/r/<token>) that shows a review as a web page. Anyone
+with the link can read the review without an API token, so share it only
+with people who may see the code. Links can expire and can be revoked.
+
+On a team server, pass the address people reach it at with --base-url.
+
+Examples:
+  roborev share 42                          # link that never expires
+  roborev share 42 --expires 72h            # link valid for three days
+  roborev share 42 --base-url https://roborev.example.com
+  roborev share 42 --list                   # list the review's links
+  roborev share --revoke <token>            # revoke a link`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			addr := getDaemonAddr()
+			if baseURL == "" {
+				baseURL = addr
+			}
+			baseURL = strings.TrimRight(baseURL, "/")
+
+			if revoke {
+				if err := tokenRequest(addr, http.MethodPost, "/api/share/revoke",
+					map[string]string{"token": args[0]}, http.StatusOK, nil); err != nil {
+					return err
+				}
+				cmd.Printf("Revoked share link %s\n", args[0])
+				return nil
+			}
+
+			jobID, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || jobID <= 0 {
+				return fmt.Errorf("invalid job ID %q", args[0])
+			}
+			if list {
+				var resp struct {
+					Links []daemon.ShareLinkResponse `json:"links"`
+				}
+				if err := tokenRequest(addr, http.MethodGet, fmt.Sprintf("/api/share?job_id=%d", jobID), nil, http.StatusOK, &resp); err != nil {
+					return err
+				}
+				printShareLinks(cmd.OutOrStdout(), baseURL, resp.Links)
+				return nil
+			}
+
+			var link daemon.ShareLinkResponse
+			if err := tokenRequest(addr, http.MethodPost, "/api/share",
+				daemon.CreateShareRequest{JobID: jobID, ExpiresIn: expires}, http.StatusCreated, &link); err != nil {
+				return err
+			}
+			cmd.Println(baseURL + link.Path)
+			if link.ExpiresAt != nil {
+				cmd.Printf("Expires %s\n", link.ExpiresAt.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&expires, "expires", "", "expire the link after this duration (e.g. 24h)")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "address the link is opened at (default: the daemon address)")
+	cmd.Flags().BoolVar(&list, "list", false, "list the review's unexpired links")
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "revoke the link with the given token")
+	cmd.MarkFlagsMutuallyExclusive("list", "revoke")
+
+	return cmd
+}
+
+func printShareLinks(w io.Writer, baseURL string, links []daemon.ShareLinkResponse) {
+	if len(links) == 0 {
+		fmt.Fprintln(w, "No share links")
+		return
+	}
+	for _, l := range links {
+		expiry := "never expires"
+		if l.ExpiresAt != nil {
+			expiry = "expires " + l.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s  (created %s, %s)\n", baseURL+l.Path, l.CreatedAt.Local().Format("2006-01-02"), expiry)
+	}
+}
@@ -136,4 +136,20 @@ func TestShowOutputFormat(t *testing.T) {
 			t.Errorf("expected '%s' in output, got: %s", expectedPattern, output)
 		}
 	})
+	t.Run("cherry-pick names the canonical review", func(t *testing.T) {
+		repo := newTestGitRepo(t)
+		commitSHA := repo.CommitFile("file.txt", "content", "initial commit")
+
+		mockReviewDaemon(t, storage.Review{
+			ID: 1, JobID: 42, Output: "Test review output", Agent: "codex",
+			CanonicalSHA: "0123456789abcdef",
+		})
+
+		chdir(t, repo.Dir)
+		output := runShowCmd(t, commitSHA)
+
+		if !strings.Contains(output, "Canonical review of 0123456") {
+			t.Errorf("expected canonical commit in output, got: %s", output)
+		}
+	})
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// localSourceName refers to the daemon on this machine in --source
+const localSourceName = "local"
+
+// jobSource is a daemon whose jobs are listed
+type jobSource struct {
+	Name  string
+	Addr  string // Base URL; empty for the local daemon until resolved
+	Local bool
+}
+
+// sourcedJob is a job tagged with the source it was listed from
+type sourcedJob struct {
+	Source string `json:"source"`
+	storage.ReviewJob
+}
+
+// jobsPage is the response of GET /api/jobs
+type jobsPage struct {
+	Jobs    []storage.ReviewJob `json:"jobs"`
+	HasMore bool                `json:"has_more"`
+}
+
+// resolveJobSources turns --source values into sources. Each value is
+// "local", the name of a [[sources]] entry in the global config, or
+// name=URL for a daemon that is not configured. With all set, the local
+// daemon and every configured source are included.
+func resolveJobSources(values []string, all bool, cfg *config.Config) ([]jobSource, error) {
+	var sources []jobSource
+	seen := map[string]bool{}
+	add := func(src jobSource) {
+		if key := strings.ToLower(src.Name); !seen[key] {
+			seen[key] = true
+			sources = append(sources, src)
+		}
+	}
+
+	if all {
+		add(jobSource{Name: localSourceName, Local: true})
+		for _, src := range cfg.Sources {
+			add(jobSource{Name: src.Name, Addr: strings.TrimRight(src.Addr, "/")})
+		}
+	}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if name, addr, ok := strings.Cut(v, "="); ok {
+			if name == "" || addr == "" {
+				return nil, fmt.Errorf("invalid --source %q (use name=URL)", v)
+			}
+			add(jobSource{Name: name, Addr: strings.TrimRight(addr, "/")})
+			continue
+		}
+		if strings.EqualFold(v, localSourceName) {
+			add(jobSource{Name: localSourceName, Local: true})
+			continue
+		}
+		src, ok := cfg.FindSource(v)
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q (add it under [[sources]] in config.toml, or pass name=URL)", v)
+		}
+		add(jobSource{Name: src.Name, Addr: strings.TrimRight(src.Addr, "/")})
+	}
+	return sources, nil
+}
+
+// fetchJobs requests one page of jobs from a daemon
+func fetchJobs(client *http.Client, addr string, params url.Values) (jobsPage, error) {
+	var page jobsPage
+	resp, err := client.Get(addr + "/api/jobs?" + params.Encode())
+	if err != nil {
+		return page, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return page, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return page, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return page, nil
+}
+
+// fetchSourcedJobs lists jobs from every source concurrently and merges
+// them newest first, keeping at most limit jobs (0 for no limit). The local
+// daemon is queried with params as is. Other daemons store repos under
+// their own paths, so the repo filter is sent to them as the repo's
+// identity instead. Sources that fail are reported in errs; the merged
+// list is still returned.
+func fetchSourcedJobs(sources []jobSource, params url.Values, repoIdentity string, limit int) (jobs []sourcedJob, hasMore bool, errs []error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	pages := make([]jobsPage, len(sources))
+	fetchErrs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		p := url.Values{}
+		for k, v := range params {
+			p[k] = v
+		}
+		if !src.Local {
+			p.Del("repo")
+			if repoIdentity != "" {
+				p.Set("repo_identity", repoIdentity)
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pages[i], fetchErrs[i] = fetchJobs(client, src.Addr, p)
+		}()
+	}
+	wg.Wait()
+
+	for i, src := range sources {
+		if fetchErrs[i] != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", src.Name, fetchErrs[i]))
+			continue
+		}
+		hasMore = hasMore || pages[i].HasMore
+		for _, j := range pages[i].Jobs {
+			jobs = append(jobs, sourcedJob{Source: src.Name, ReviewJob: j})
+		}
+	}
+
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return jobs[a].EnqueuedAt.After(jobs[b].EnqueuedAt)
+	})
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+		hasMore = true
+	}
+	return jobs, hasMore, errs
+}
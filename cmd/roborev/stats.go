@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/cliprof"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// sparkWidth is the number of columns of a queue history sparkline
+const sparkWidth = 60
+
+func statsCmd() *cobra.Command {
+	var (
+		queue    bool
+		sla      bool
+		byAuthor bool
+		slowCmds bool
+		since    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show review and queue statistics",
+		Long: `Show job totals, findings and review durations per agent. Findings
+suppressed by a repo's .roborev-ignore file are counted separately.
+
+With --queue, show the history of queue depth, throughput and latency
+recorded by the daemon every minute, to help size max_workers. Samples are
+kept for 90 days.
+
+With --sla, show how the reviews of repos with a review_sla_minutes setting
+met it: reviews finished, SLA breaches and the mean wait from enqueue.
+
+With --by-author, show jobs per commit author. Authors are counted as the
+repo's .mailmap maps them, and under one name across their aliases (see
+'roborev author').
+
+With --slow-commands, show the CLI commands whose own work (database, git
+and HTTP requests, not waiting on agents) took over a second, logged
+locally to find performance regressions. See where a single command's time
+goes with 'roborev --profile-cli <command>'.
+
+Examples:
+  roborev stats
+  roborev stats --queue
+  roborev stats --queue --since 30d
+  roborev stats --sla --since 7d
+  roborev stats --by-author --since 30d
+  roborev stats --slow-commands --since 7d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			sinceTime, err := parseSince(since, now)
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+
+			if queue {
+				samples, err := db.ListQueueSamples(sinceTime)
+				if err != nil {
+					return fmt.Errorf("load queue metrics: %w", err)
+				}
+				printQueueStats(cmd.OutOrStdout(), samples, sinceTime, now)
+				return nil
+			}
+
+			if sla {
+				repoStats, err := db.GetSLAStats(sinceTime)
+				if err != nil {
+					return fmt.Errorf("load SLA stats: %w", err)
+				}
+				printSLAStats(cmd.OutOrStdout(), repoStats, config.ResolveReviewSLA, sinceTime)
+				return nil
+			}
+
+			if slowCmds {
+				cmds, err := cliprof.ReadSlowCommands(slowCommandsPath())
+				if err != nil {
+					return fmt.Errorf("read slow command log: %w", err)
+				}
+				printSlowCommands(cmd.OutOrStdout(), cmds, sinceTime)
+				return nil
+			}
+
+			if byAuthor {
+				var counts [3]storage.JobCounts
+				for i, status := range []storage.JobStatus{"", storage.JobStatusDone, storage.JobStatusFailed} {
+					counts[i], err = db.CountJobs(cmd.Context(), storage.CountByAuthor, storage.JobCountFilter{Status: status, Since: sinceTime})
+					if err != nil {
+						return fmt.Errorf("count jobs by author: %w", err)
+					}
+				}
+				printAuthorStats(cmd.OutOrStdout(), counts[0], counts[1], counts[2], sinceTime)
+				return nil
+			}
+
+			counts, err := db.CountJobs(cmd.Context(), storage.CountByStatus, storage.JobCountFilter{})
+			if err != nil {
+				return fmt.Errorf("count jobs: %w", err)
+			}
+			findings, err := db.CountFindings()
+			if err != nil {
+				return fmt.Errorf("count findings: %w", err)
+			}
+			failures, err := db.CountJobs(cmd.Context(), storage.CountByErrorCode, storage.JobCountFilter{Status: storage.JobStatusFailed})
+			if err != nil {
+				return fmt.Errorf("count failures: %w", err)
+			}
+			agentStats, err := db.GetAgentReviewStats(0)
+			if err != nil {
+				return fmt.Errorf("load review history: %w", err)
+			}
+			printJobStats(cmd.OutOrStdout(), counts, failures, findings, agentStats)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&queue, "queue", false, "show queue depth, throughput and latency history")
+	cmd.Flags().BoolVar(&sla, "sla", false, "show review SLA breaches per repo")
+	cmd.Flags().BoolVar(&byAuthor, "by-author", false, "show jobs per commit author")
+	cmd.Flags().BoolVar(&slowCmds, "slow-commands", false, "show CLI commands that were slow")
+	cmd.Flags().StringVar(&since, "since", "24h", "history to show with --queue, --sla, --by-author or --slow-commands, e.g. 30d, 2w, 36h or 2026-01-31")
+	cmd.MarkFlagsMutuallyExclusive("queue", "sla", "by-author", "slow-commands")
+	return cmd
+}
+
+// printJobStats prints job counts by status, failed jobs by error code, and
+// the review history of each agent
+func printJobStats(w io.Writer, counts, failures storage.JobCounts, findings storage.FindingCounts, agentStats []storage.AgentReviewStats) {
+	fmt.Fprintf(w, "Jobs: %d queued, %d running, %d done, %d failed, %d canceled\n",
+		counts.Status(storage.JobStatusQueued), counts.Status(storage.JobStatusRunning),
+		counts.Status(storage.JobStatusDone), counts.Status(storage.JobStatusFailed),
+		counts.Status(storage.JobStatusCanceled))
+	if len(failures) > 0 {
+		fmt.Fprintf(w, "Failures: %s\n", formatErrorCodeCounts(failures))
+	}
+	fmt.Fprintf(w, "Findings: %d reported, %d suppressed by .roborev-ignore\n", findings.Reported, findings.Suppressed)
+	if len(agentStats) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tREVIEWS\tAVG DURATION\tTOTAL")
+	for _, st := range agentStats {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", st.Agent, st.Jobs,
+			formatPlanDuration(st.TotalSeconds/float64(st.Jobs)), formatPlanDuration(st.TotalSeconds))
+	}
+	tw.Flush()
+}
+
+// printAuthorStats prints the jobs, done and failed counts of each commit
+// author, most jobs first
+func printAuthorStats(w io.Writer, jobs, done, failed storage.JobCounts, since time.Time) {
+	if len(jobs) == 0 {
+		fmt.Fprintf(w, "No jobs since %s.\n", since.Format("2006-01-02 15:04"))
+		return
+	}
+	authors := make([]string, 0, len(jobs))
+	for author := range jobs {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if jobs[authors[i]] != jobs[authors[j]] {
+			return jobs[authors[i]] > jobs[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AUTHOR\tJOBS\tDONE\tFAILED")
+	for _, author := range authors {
+		name := author
+		if name == "" {
+			name = "(no commit)" // Dirty, range and task jobs
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", name, jobs[author], done[author], failed[author])
+	}
+	tw.Flush()
+}
+
+// printSLAStats prints the SLA stats of the repos that set a review SLA or
+// breached one, using slaFor to look up each repo's SLA
+func printSLAStats(w io.Writer, stats []storage.RepoSLAStats, slaFor func(repoPath string) time.Duration, since time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	rows := 0
+	for _, st := range stats {
+		sla := slaFor(st.RepoPath)
+		if sla == 0 && st.Breaches == 0 {
+			continue
+		}
+		if rows == 0 {
+			fmt.Fprintln(tw, "REPO\tSLA\tREVIEWS\tBREACHES\tMET\tAVG WAIT")
+		}
+		rows++
+		slaText := "none"
+		if sla > 0 {
+			slaText = formatPlanDuration(sla.Seconds())
+		}
+		met := 100 * float64(st.Reviews-st.Breaches) / float64(st.Reviews)
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.0f%%\t%s\n", st.RepoName, slaText, st.Reviews, st.Breaches,
+			met, formatPlanDuration(st.AvgWaitSeconds))
+	}
+	if rows == 0 {
+		fmt.Fprintf(w, "No reviews of repos with a review SLA since %s. Set review_sla_minutes in .roborev.toml.\n",
+			since.Format("2006-01-02 15:04"))
+		return
+	}
+	tw.Flush()
+}
+
+// queueBucket aggregates the queue samples that fall into one sparkline column
+type queueBucket struct {
+	samples        int
+	maxQueued      int
+	maxRunning     int
+	finished       int
+	latencySeconds float64 // Summed over finished jobs
+}
+
+func printQueueStats(w io.Writer, samples []storage.QueueSample, since, now time.Time) {
+	if len(samples) == 0 {
+		fmt.Fprintf(w, "No queue samples since %s. The daemon records one every minute.\n", since.Format("2006-01-02 15:04"))
+		return
+	}
+
+	span := now.Sub(since)
+	if span <= 0 {
+		span = time.Minute
+	}
+	buckets := make([]queueBucket, sparkWidth)
+	var (
+		totalQueued, totalRunning, totalFinished int
+		latencySum, peakLatency                  float64
+		peakQueued, peakRunning, saturated       int
+		workers                                  int
+	)
+	for _, s := range samples {
+		i := int(float64(s.SampledAt.Sub(since)) / float64(span) * sparkWidth)
+		i = max(0, min(i, sparkWidth-1))
+		b := &buckets[i]
+		b.samples++
+		b.maxQueued = max(b.maxQueued, s.Queued)
+		b.maxRunning = max(b.maxRunning, s.Running)
+		b.finished += s.Finished
+		b.latencySeconds += s.AvgLatencySeconds * float64(s.Finished)
+
+		totalQueued += s.Queued
+		totalRunning += s.Running
+		totalFinished += s.Finished
+		latencySum += s.AvgLatencySeconds * float64(s.Finished)
+		peakQueued = max(peakQueued, s.Queued)
+		peakRunning = max(peakRunning, s.Running)
+		peakLatency = max(peakLatency, s.AvgLatencySeconds)
+		if s.Saturated() {
+			saturated++
+		}
+		workers = s.Workers
+	}
+
+	bucketHours := span.Hours() / sparkWidth
+	queuedLine := make([]float64, sparkWidth)
+	runningLine := make([]float64, sparkWidth)
+	throughputLine := make([]float64, sparkWidth)
+	latencyLine := make([]float64, sparkWidth)
+	var peakThroughput float64
+	for i, b := range buckets {
+		if b.samples == 0 {
+			queuedLine[i], runningLine[i], throughputLine[i], latencyLine[i] = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+			continue
+		}
+		queuedLine[i] = float64(b.maxQueued)
+		runningLine[i] = float64(b.maxRunning)
+		throughputLine[i] = float64(b.finished) / bucketHours
+		peakThroughput = max(peakThroughput, throughputLine[i])
+		if b.finished > 0 {
+			latencyLine[i] = b.latencySeconds / float64(b.finished)
+		}
+	}
+
+	n := float64(len(samples))
+	avgLatency := "-"
+	if totalFinished > 0 {
+		avgLatency = formatPlanDuration(latencySum / float64(totalFinished))
+	}
+
+	fmt.Fprintf(w, "Queue since %s (%d samples, %d workers)\n\n", since.Format("2006-01-02 15:04"), len(samples), workers)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\tAVG\tPEAK\n", strings.Repeat(" ", sparkWidth))
+	fmt.Fprintf(tw, "Queued\t%s\t%.1f\t%d\n", sparkline(queuedLine), float64(totalQueued)/n, peakQueued)
+	fmt.Fprintf(tw, "Running\t%s\t%.1f\t%d\n", sparkline(runningLine), float64(totalRunning)/n, peakRunning)
+	fmt.Fprintf(tw, "Jobs/hour\t%s\t%.1f\t%.1f\n", sparkline(throughputLine), float64(totalFinished)/(n/60), peakThroughput)
+	fmt.Fprintf(tw, "Latency\t%s\t%s\t%s\n", sparkline(latencyLine), avgLatency, formatPlanDuration(peakLatency))
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nAll workers busy with jobs waiting: %.1f%% of samples\n", float64(saturated)/n*100)
+	if workers > 0 && peakRunning < workers {
+		fmt.Fprintf(w, "At most %d of %d workers were busy at once\n", peakRunning, workers)
+	}
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values scaled to the largest one. NaN values, for
+// periods without samples, are left blank.
+func sparkline(values []float64) string {
+	var top float64
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			top = max(top, v)
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			sb.WriteRune(' ')
+		case top == 0:
+			sb.WriteRune(sparkBlocks[0])
+		default:
+			sb.WriteRune(sparkBlocks[int(math.Round(v/top*float64(len(sparkBlocks)-1)))])
+		}
+	}
+	return sb.String()
+}
+
+// formatErrorCodeCounts lists failure counts by error code, most first,
+// with failures of no known kind as "other"
+func formatErrorCodeCounts(failures storage.JobCounts) string {
+	codes := make([]string, 0, len(failures))
+	for code := range failures {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if failures[codes[i]] != failures[codes[j]] {
+			return failures[codes[i]] > failures[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		name := code
+		if name == "" {
+			name = "other"
+		}
+		parts[i] = fmt.Sprintf("%d %s", failures[code], name)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestSparkline(t *testing.T) {
+	got := sparkline([]float64{0, 1, 2, 4, 8, math.NaN(), 8})
+	if want := "▁▂▃▅█ █"; got != want {
+		t.Errorf("sparkline = %q, want %q", got, want)
+	}
+	if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Errorf("sparkline of zeros = %q", got)
+	}
+}
+
+func TestStatsQueue(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := range 3 {
+		if _, err := db.RecordQueueSample(now.Add(time.Duration(i-3)*time.Minute), time.Minute, 4); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	var out bytes.Buffer
+	cmd := statsCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--queue", "--since", "1h"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("stats --queue: %v", err)
+	}
+	for _, want := range []string{"3 samples, 4 workers", "Queued", "Jobs/hour", "Latency", "At most 0 of 4 workers"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	cmd = statsCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--queue", "--since", "2026-01-01"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("stats --queue: %v", err)
+	}
+	if !strings.Contains(out.String(), "3 samples") {
+		t.Errorf("expected samples since a date, got:\n%s", out.String())
+	}
+}
+
+func TestPrintQueueStats(t *testing.T) {
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	now := since.Add(time.Hour)
+	samples := []storage.QueueSample{
+		{SampledAt: since.Add(time.Minute), Queued: 0, Running: 1, Workers: 2, Finished: 1, AvgLatencySeconds: 60},
+		{SampledAt: since.Add(30 * time.Minute), Queued: 5, Running: 2, Workers: 2, Finished: 3, AvgLatencySeconds: 120},
+	}
+
+	var out bytes.Buffer
+	printQueueStats(&out, samples, since, now)
+	got := out.String()
+	for _, want := range []string{
+		"2 samples, 2 workers",
+		"1m45s", // latency weighted by finished jobs
+		"All workers busy with jobs waiting: 50.0% of samples",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "At most") {
+		t.Errorf("did not expect idle-worker note when all workers were busy:\n%s", got)
+	}
+
+	out.Reset()
+	printQueueStats(&out, nil, since, now)
+	if !strings.Contains(out.String(), "No queue samples") {
+		t.Errorf("expected empty note, got %q", out.String())
+	}
+}
+
+func TestPrintSLAStats(t *testing.T) {
+	stats := []storage.RepoSLAStats{
+		{RepoPath: "/repos/api", RepoName: "api", Reviews: 4, Breaches: 1, AvgWaitSeconds: 600},
+		{RepoPath: "/repos/docs", RepoName: "docs", Reviews: 9},
+		{RepoPath: "/repos/old", RepoName: "old", Reviews: 2, Breaches: 2, AvgWaitSeconds: 3600},
+	}
+	slaFor := func(repoPath string) time.Duration {
+		if repoPath == "/repos/api" {
+			return 30 * time.Minute
+		}
+		return 0
+	}
+
+	var buf bytes.Buffer
+	printSLAStats(&buf, stats, slaFor, time.Now())
+	out := buf.String()
+	for _, want := range []string{"api", "30m0s", "75%", "10m0s", "old", "none"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "docs") {
+		t.Errorf("repo without an SLA or breaches should be left out:\n%s", out)
+	}
+
+	buf.Reset()
+	printSLAStats(&buf, stats[1:2], slaFor, time.Now())
+	if !strings.Contains(buf.String(), "No reviews of repos with a review SLA") {
+		t.Errorf("expected empty message, got %q", buf.String())
+	}
+}
+
+func TestPrintJobStatsFailures(t *testing.T) {
+	counts := storage.JobCounts{"done": 5, "failed": 4}
+	failures := storage.JobCounts{"agent_rate_limit": 2, "": 1, "timeout": 1}
+
+	var buf bytes.Buffer
+	printJobStats(&buf, counts, failures, storage.FindingCounts{}, nil)
+	if want := "Failures: 2 agent_rate_limit, 1 other, 1 timeout\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	printJobStats(&buf, storage.JobCounts{"done": 5}, storage.JobCounts{}, storage.FindingCounts{}, nil)
+	if strings.Contains(buf.String(), "Failures:") {
+		t.Errorf("failures shown without failed jobs:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// statusLine is the summary printed by "roborev statusline --json"
+type statusLine struct {
+	Head         string `json:"head"`
+	Status       string `json:"status"`
+	Verdict      string `json:"verdict,omitempty"`
+	OpenFindings int    `json:"open_findings"`
+	Protected    bool   `json:"protected,omitempty"`  // HEAD is on a protected branch
+	QuickTake    string `json:"quick_take,omitempty"` // The review's short summary, when quick_take is enabled
+}
+
+func statuslineCmd() *cobra.Command {
+	var (
+		repoPath  string
+		jsonOut   bool
+		quickTake bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "statusline",
+		Short: "Print a one-line review summary of HEAD for shell prompts",
+		Long: `Print a one-line summary of the review state of the current repo's HEAD,
+for embedding in a shell prompt or tmux status line.
+
+The status is one of:
+  unreviewed  HEAD has no review
+  queued      review is waiting to run
+  running     review is in progress
+  pass        review passed
+  fail        review found issues
+  addressed   failed review was marked addressed
+  skipped     commit was skipped on purpose
+  error       review failed to run
+  blocked     review is waiting for the deleted repo to be restored
+  canceled    review was canceled
+
+It is followed by the number of open (untriaged) findings when there are
+any, e.g. "fail 3". --quick-take appends the quick take the review opened
+with, when quick_take is enabled. --json prints the same information, with
+the quick take, as JSON.
+
+The database is opened read-only and results are cached until HEAD or the
+database changes, so the command does not need the daemon. Outside a git
+repository, or on any error, it prints nothing and exits 0.
+
+Examples:
+  PS1='$(roborev statusline) \$ '
+  set -g status-right '#(cd #{pane_current_path}; roborev statusline)'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repoPath == "" {
+				repoPath = "."
+			}
+			line, ok := buildStatusLine(repoPath, storage.DefaultDBPath(), jsonOut, quickTake)
+			if ok {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print JSON instead of text")
+	cmd.Flags().BoolVar(&quickTake, "quick-take", false, "append the review's quick take to the text line")
+	return cmd
+}
+
+// buildStatusLine returns the status line for the repo at repoPath, using
+// the cached line when neither HEAD nor the database has changed. With
+// quickTake, the text line ends with the review's quick take. Returns false
+// if there is nothing to show.
+func buildStatusLine(repoPath, dbPath string, jsonOut, quickTake bool) (string, bool) {
+	root, head, err := git.GetMainRepoRootAndHead(repoPath)
+	if err != nil || head == "" {
+		return "", false
+	}
+
+	format := "text"
+	if jsonOut {
+		format = "json"
+	} else if quickTake {
+		format = "text-quick-take"
+	}
+	stamp := dbStamp(dbPath)
+	key := strings.Join([]string{head, stamp, format}, " ")
+	cachePath := statusLineCachePath(root)
+	if cached, err := os.ReadFile(cachePath); err == nil {
+		if k, line, ok := strings.Cut(string(cached), "\n"); ok && k == key {
+			return line, true
+		}
+	}
+
+	st := storage.CommitStatus{}
+	if stamp != "" {
+		db, err := storage.OpenReadOnly(dbPath)
+		if err != nil {
+			return "", false
+		}
+		st, err = db.GetCommitStatus(context.Background(), root, head)
+		db.Close()
+		if err != nil {
+			return "", false
+		}
+	}
+
+	line := formatStatusLine(head, st, jsonOut)
+	if quickTake && !jsonOut && st.QuickTake != "" {
+		line += " - " + st.QuickTake
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		_ = os.WriteFile(cachePath, []byte(key+"\n"+line), 0644)
+	}
+	return line, true
+}
+
+// formatStatusLine renders a commit's review state as text or JSON
+func formatStatusLine(head string, st storage.CommitStatus, jsonOut bool) string {
+	status := statusLineState(st)
+	if jsonOut {
+		data, _ := json.Marshal(statusLine{Head: head, Status: status, Verdict: st.Verdict,
+			OpenFindings: st.OpenFindings, Protected: st.Protected, QuickTake: st.QuickTake})
+		return string(data)
+	}
+	if st.OpenFindings > 0 {
+		return fmt.Sprintf("%s %d", status, st.OpenFindings)
+	}
+	return status
+}
+
+func statusLineState(st storage.CommitStatus) string {
+	switch st.Status {
+	case "":
+		return "unreviewed"
+	case storage.JobStatusQueued:
+		return "queued"
+	case storage.JobStatusRunning:
+		return "running"
+	case storage.JobStatusFailed:
+		return "error"
+	case storage.JobStatusCanceled:
+		return "canceled"
+	case storage.JobStatusSkipped:
+		return "skipped"
+	case storage.JobStatusBlocked:
+		return "blocked"
+	}
+	switch {
+	case st.Verdict == "P":
+		return "pass"
+	case st.Addressed:
+		return "addressed"
+	default:
+		return "fail"
+	}
+}
+
+// dbStamp identifies the current contents of the database by the size and
+// modification time of its files. Writes in WAL mode touch the -wal file.
+// Returns "" if the database does not exist.
+func dbStamp(dbPath string) string {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return ""
+	}
+	stamp := fmt.Sprintf("%d.%d", info.Size(), info.ModTime().UnixNano())
+	if wal, err := os.Stat(dbPath + "-wal"); err == nil {
+		stamp += fmt.Sprintf("-%d.%d", wal.Size(), wal.ModTime().UnixNano())
+	}
+	return stamp
+}
+
+// statusLineCachePath returns the per-repo cache file for status lines
+func statusLineCachePath(repoRoot string) string {
+	sum := sha256.Sum256([]byte(repoRoot))
+	return filepath.Join(config.CacheDir(), "statusline", hex.EncodeToString(sum[:8]))
+}
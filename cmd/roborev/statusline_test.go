@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestFormatStatusLine(t *testing.T) {
+	tests := []struct {
+		name string
+		st   storage.CommitStatus
+		json bool
+		want string
+	}{
+		{"unreviewed", storage.CommitStatus{}, false, "unreviewed"},
+		{"queued", storage.CommitStatus{Status: storage.JobStatusQueued}, false, "queued"},
+		{"pass", storage.CommitStatus{Status: storage.JobStatusDone, Verdict: "P"}, false, "pass"},
+		{"fail with findings", storage.CommitStatus{Status: storage.JobStatusDone, Verdict: "F", OpenFindings: 3}, false, "fail 3"},
+		{"addressed", storage.CommitStatus{Status: storage.JobStatusDone, Verdict: "F", Addressed: true}, false, "addressed"},
+		{"skipped", storage.CommitStatus{Status: storage.JobStatusSkipped}, false, "skipped"},
+		{"error", storage.CommitStatus{Status: storage.JobStatusFailed}, false, "error"},
+		{"json", storage.CommitStatus{Status: storage.JobStatusDone, Verdict: "F", OpenFindings: 1}, true,
+			`{"head":"abc","status":"fail","verdict":"F","open_findings":1}`},
+		{"json with quick take", storage.CommitStatus{Status: storage.JobStatusDone, Verdict: "P", QuickTake: "Passes."}, true,
+			`{"head":"abc","status":"pass","verdict":"P","open_findings":0,"quick_take":"Passes."}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatStatusLine("abc", tt.st, tt.json); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStatusLine(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	repo := newTestGitRepo(t)
+	sha := repo.CommitFile("a.txt", "a", "first")
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+
+	if line, ok := buildStatusLine(repo.Dir, dbPath, false, false); !ok || line != "unreviewed" {
+		t.Fatalf("without database: got %q, %v", line, ok)
+	}
+
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	r, err := db.GetOrCreateRepo(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := db.GetOrCreateCommit(t.Context(), r.ID, sha, "Test", "first", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: r.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ClaimJob(t.Context(), "worker"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CompleteJob(t.Context(), job.ID, "test", "prompt", "- High: first\n- Low: second"); err != nil {
+		t.Fatal(err)
+	}
+
+	if line, ok := buildStatusLine(repo.Dir, dbPath, false, false); !ok || line != "fail 2" {
+		t.Errorf("after review: got %q, %v", line, ok)
+	}
+	if err := db.SetReviewQuickTake(job.ID, "Fails: two issues."); err != nil {
+		t.Fatal(err)
+	}
+	if line, _ := buildStatusLine(repo.Dir, dbPath, false, true); line != "fail 2 - Fails: two issues." {
+		t.Errorf("with quick take: got %q", line)
+	}
+	if _, err := os.Stat(statusLineCachePath(repo.Dir)); err != nil {
+		t.Errorf("expected cache file: %v", err)
+	}
+	if line, _ := buildStatusLine(repo.Dir, dbPath, false, false); line != "fail 2" {
+		t.Errorf("cached: got %q", line)
+	}
+
+	// A new commit changes HEAD, so the cached line is not reused
+	repo.CommitFile("a.txt", "b", "second")
+	if line, _ := buildStatusLine(repo.Dir, dbPath, false, false); line != "unreviewed" {
+		t.Errorf("new HEAD: got %q", line)
+	}
+
+	if _, ok := buildStatusLine(t.TempDir(), dbPath, false, false); ok {
+		t.Error("expected no status line outside a repository")
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// idleWorkerStaleAfter is how long an idle worker can go without a heartbeat
+// before it is flagged. Idle workers beat at least every 15 seconds, so a
+// longer gap means the worker goroutine is stuck.
+const idleWorkerStaleAfter = 30 * time.Second
+
+// recentCompletions is how many finished jobs the watch view lists
+const recentCompletions = 5
+
+// statusSnapshot is one refresh of the data shown by `status --watch`
+type statusSnapshot struct {
+	status   storage.DaemonStatus
+	running  []storage.ReviewJob
+	finished []storage.ReviewJob // Most recently finished first
+	err      error               // Set when the daemon could not be reached
+}
+
+// fetchStatusSnapshot reads the daemon status, running jobs and recently
+// finished jobs
+func fetchStatusSnapshot(client *http.Client, addr string) statusSnapshot {
+	var snap statusSnapshot
+	getJSON := func(path string, v any) error {
+		resp, err := client.Get(addr + path)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s: %s", path, resp.Status)
+		}
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	if snap.err = getJSON("/api/status", &snap.status); snap.err != nil {
+		return snap
+	}
+	var running, recent struct {
+		Jobs []storage.ReviewJob `json:"jobs"`
+	}
+	if snap.err = getJSON("/api/jobs?status=running&limit=0", &running); snap.err != nil {
+		return snap
+	}
+	if snap.err = getJSON("/api/jobs?limit=50", &recent); snap.err != nil {
+		return snap
+	}
+	snap.running = running.Jobs
+
+	for _, j := range recent.Jobs {
+		if j.FinishedAt != nil && j.Status != storage.JobStatusRunning {
+			snap.finished = append(snap.finished, j)
+		}
+	}
+	sort.SliceStable(snap.finished, func(a, b int) bool {
+		return snap.finished[a].FinishedAt.After(*snap.finished[b].FinishedAt)
+	})
+	if len(snap.finished) > recentCompletions {
+		snap.finished = snap.finished[:recentCompletions]
+	}
+	return snap
+}
+
+// renderStatusWatch writes one frame of the watch view
+func renderStatusWatch(w io.Writer, snap statusSnapshot, interval time.Duration, now time.Time) {
+	fmt.Fprintf(w, "Every %s: roborev status (Ctrl+C to quit)    %s\n\n", interval, now.Format("15:04:05"))
+	if snap.err != nil {
+		fmt.Fprintf(w, "Daemon: not reachable (%v)\n", snap.err)
+		return
+	}
+
+	st := snap.status
+	daemonLine := "Daemon:  running"
+	if st.Version != "" {
+		daemonLine += fmt.Sprintf(" [%s]", st.Version)
+	}
+	if st.Draining {
+		daemonLine += " (draining: not claiming new jobs)"
+	}
+	fmt.Fprintln(w, daemonLine)
+	fmt.Fprintf(w, "Queue:   %d queued, %d running, %d completed, %d failed\n",
+		st.QueuedJobs, st.RunningJobs, st.CompletedJobs, st.FailedJobs)
+	if st.OverdueJobs > 0 {
+		fmt.Fprintf(w, "Overdue: %d job(s) past their repo's review SLA\n", st.OverdueJobs)
+	}
+	if st.Starvation != nil {
+		fmt.Fprintln(w, starvationWarning(st.Starvation, now))
+	}
+	fmt.Fprintf(w, "Workers: %d/%d active\n", st.ActiveWorkers, st.MaxWorkers)
+
+	started := make(map[int64]time.Time, len(snap.running))
+	for _, j := range snap.running {
+		if j.StartedAt != nil {
+			started[j.ID] = *j.StartedAt
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, wk := range st.Workers {
+		state := "idle"
+		if wk.JobID != 0 {
+			state = fmt.Sprintf("job %d", wk.JobID)
+			if t, ok := started[wk.JobID]; ok {
+				state += fmt.Sprintf(" (%s)", now.Sub(t).Round(time.Second))
+			}
+		}
+		age := now.Sub(wk.LastHeartbeat)
+		if age < 0 {
+			age = 0
+		}
+		beat := fmt.Sprintf("heartbeat %s ago", age.Round(time.Second))
+		if wk.JobID == 0 && age > idleWorkerStaleAfter {
+			beat += "  STALE"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", wk.ID, state, beat)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if len(snap.running) > 0 {
+		fmt.Fprintln(w, "Running:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  ID\tSHA\tRepo\tAgent\tElapsed\n")
+		for _, j := range snap.running {
+			elapsed := ""
+			if j.StartedAt != nil {
+				elapsed = now.Sub(*j.StartedAt).Round(time.Second).String()
+			}
+			fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\n", j.ID, shortRef(j.GitRef), j.RepoName, j.Agent, elapsed)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	if len(snap.finished) > 0 {
+		fmt.Fprintln(w, "Recent completions:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  ID\tSHA\tRepo\tAgent\tStatus\tTook\tFinished\n")
+		for _, j := range snap.finished {
+			took := ""
+			if j.StartedAt != nil {
+				took = j.FinishedAt.Sub(*j.StartedAt).Round(time.Second).String()
+			}
+			ago := now.Sub(*j.FinishedAt).Round(time.Second).String() + " ago"
+			fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				j.ID, shortRef(j.GitRef), j.RepoName, j.Agent, j.Status, took, ago)
+		}
+		tw.Flush()
+	}
+}
+
+// watchStatus redraws the status view every interval until interrupted.
+// On a terminal each frame replaces the last; otherwise frames are appended.
+func watchStatus(out io.Writer, addr string, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	redraw := writerIsTerminal(out)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var frame strings.Builder
+		renderStatusWatch(&frame, fetchStatusSnapshot(client, addr), interval, time.Now())
+		if redraw {
+			// Home the cursor and clear the screen, then draw in one write
+			// so the view does not flicker
+			io.WriteString(out, "\033[H\033[2J"+frame.String())
+		} else {
+			io.WriteString(out, frame.String()+"\n")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// starvationWarning describes queued jobs that idle workers are not claiming
+func starvationWarning(st *storage.QueueStarvation, now time.Time) string {
+	return fmt.Sprintf("WARNING: queue starved, %d job(s) unclaimed for %s (oldest: job %d) with %d idle worker(s)\n  Cause: %s",
+		st.Waiting, now.Sub(st.Since).Round(time.Minute), st.OldestJobID, st.IdleWorkers, st.Cause)
+}
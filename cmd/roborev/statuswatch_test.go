@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestFetchStatusSnapshot(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/status":
+			json.NewEncoder(w).Encode(storage.DaemonStatus{QueuedJobs: 3})
+		case r.URL.Query().Get("status") == "running":
+			json.NewEncoder(w).Encode(map[string]any{"jobs": []storage.ReviewJob{{ID: 9, Status: storage.JobStatusRunning}}})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{"jobs": []storage.ReviewJob{
+				{ID: 9, Status: storage.JobStatusRunning},
+				{ID: 8, Status: storage.JobStatusDone, FinishedAt: at(-time.Hour)},
+				{ID: 7, Status: storage.JobStatusFailed, FinishedAt: at(-time.Minute)},
+				{ID: 6, Status: storage.JobStatusQueued},
+			}})
+		}
+	}))
+	defer ts.Close()
+
+	snap := fetchStatusSnapshot(ts.Client(), ts.URL)
+	if snap.err != nil {
+		t.Fatalf("fetchStatusSnapshot: %v", snap.err)
+	}
+	if snap.status.QueuedJobs != 3 || len(snap.running) != 1 {
+		t.Errorf("unexpected snapshot %+v", snap)
+	}
+	if len(snap.finished) != 2 || snap.finished[0].ID != 7 || snap.finished[1].ID != 8 {
+		t.Errorf("expected finished jobs newest first, got %+v", snap.finished)
+	}
+
+	ts.Close()
+	if snap := fetchStatusSnapshot(ts.Client(), ts.URL); snap.err == nil {
+		t.Error("expected an error once the daemon is gone")
+	}
+}
+
+func TestRenderStatusWatch(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time { t := now.Add(-d); return &t }
+
+	snap := statusSnapshot{
+		status: storage.DaemonStatus{
+			QueuedJobs: 4, RunningJobs: 1, CompletedJobs: 10, FailedJobs: 2,
+			ActiveWorkers: 1, MaxWorkers: 3,
+			Workers: []storage.WorkerStatus{
+				{ID: "worker-0", JobID: 42, LastHeartbeat: now.Add(-5 * time.Minute)},
+				{ID: "worker-1", LastHeartbeat: now.Add(-2 * time.Second)},
+				{ID: "worker-2", LastHeartbeat: now.Add(-time.Minute)},
+			},
+		},
+		running: []storage.ReviewJob{
+			{ID: 42, GitRef: "abcdef1234567", RepoName: "app", Agent: "codex", StartedAt: ago(90 * time.Second)},
+		},
+		finished: []storage.ReviewJob{
+			{ID: 41, GitRef: "1234567abcdef", RepoName: "app", Agent: "codex", Status: storage.JobStatusDone,
+				StartedAt: ago(3 * time.Minute), FinishedAt: ago(time.Minute)},
+		},
+	}
+
+	var out strings.Builder
+	renderStatusWatch(&out, snap, 2*time.Second, now)
+	got := out.String()
+	for _, want := range []string{
+		"Every 2s: roborev status",
+		"Queue:   4 queued, 1 running, 10 completed, 2 failed",
+		"Workers: 1/3 active",
+		"worker-0  job 42 (1m30s)  heartbeat 5m0s ago\n",
+		"worker-1  idle            heartbeat 2s ago\n",
+		"worker-2  idle            heartbeat 1m0s ago  STALE",
+		"Running:",
+		"Recent completions:",
+		"2m0s",
+		"1m0s ago",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "WARNING") {
+		t.Errorf("unexpected starvation warning:\n%s", got)
+	}
+
+	out.Reset()
+	snap.status.Starvation = &storage.QueueStarvation{
+		OldestJobID: 40, Since: now.Add(-25 * time.Minute), Waiting: 4, IdleWorkers: 2,
+		Cause: "the queue is paused; run 'roborev queue resume'",
+	}
+	renderStatusWatch(&out, snap, 2*time.Second, now)
+	if want := "WARNING: queue starved, 4 job(s) unclaimed for 25m0s (oldest: job 40) with 2 idle worker(s)\n  Cause: the queue is paused"; !strings.Contains(out.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	renderStatusWatch(&out, statusSnapshot{err: errors.New("daemon gone")}, time.Second, now)
+	if !strings.Contains(out.String(), "Daemon: not reachable (daemon gone)") {
+		t.Errorf("unexpected output for an unreachable daemon:\n%s", out.String())
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// isPeerURL reports whether a --from source is a daemon URL rather than a
+// database path.
+func isPeerURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// fetchPeerBundle exports the delta since the given time from source, which
+// is either another roborev database file or the base URL of a running daemon.
+func fetchPeerBundle(ctx context.Context, source string, since time.Time) (*storage.PeerBundle, error) {
+	if isPeerURL(source) {
+		u := strings.TrimSuffix(source, "/") + "/api/sync/export"
+		if !since.IsZero() {
+			u += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339Nano))
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := &http.Client{Timeout: 2 * time.Minute}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch from %s: %w", source, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return nil, fmt.Errorf("fetch from %s: %s: %s", source, resp.Status, strings.TrimSpace(string(body)))
+		}
+		var bundle storage.PeerBundle
+		if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+			return nil, fmt.Errorf("decode sync bundle: %w", err)
+		}
+		return &bundle, nil
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return nil, fmt.Errorf("source database: %w", err)
+	}
+	peer, err := storage.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("open source database: %w", err)
+	}
+	defer peer.Close()
+	return peer.ExportPeerBundle(ctx, since)
+}
+
+// syncFromPeer merges reviews from another roborev instance into the local
+// database, resuming from the cursor recorded by the previous merge.
+func syncFromPeer(ctx context.Context, source string, full bool) error {
+	if !isPeerURL(source) {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return err
+		}
+		source = abs
+		if local, err := filepath.Abs(storage.DefaultDBPath()); err == nil && local == source {
+			return fmt.Errorf("%s is the local database", source)
+		}
+	}
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	var since time.Time
+	if !full {
+		if since, err = db.GetPeerCursor(ctx, source); err != nil {
+			return err
+		}
+	}
+
+	bundle, err := fetchPeerBundle(ctx, source, since)
+	if err != nil {
+		return err
+	}
+	localID, err := db.GetMachineID()
+	if err != nil {
+		return err
+	}
+	if bundle.MachineID != "" && bundle.MachineID == localID {
+		return fmt.Errorf("%s has the same machine ID as this instance; refusing to merge a database into itself", source)
+	}
+
+	stats, err := db.ImportPeerBundle(ctx, bundle)
+	if err != nil {
+		return fmt.Errorf("merge from %s: %w", source, err)
+	}
+	wakeQueue() // Jobs finished elsewhere may release jobs waiting on them
+	if err := db.SetPeerCursor(ctx, source, bundle.Cursor); err != nil {
+		return err
+	}
+
+	if since.IsZero() {
+		fmt.Printf("Merged from %s (full sync)\n", source)
+	} else {
+		fmt.Printf("Merged from %s (changes since %s)\n", source, since.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("  Jobs:     %d new, %d updated\n", stats.JobsInserted, stats.JobsUpdated)
+	fmt.Printf("  Reviews:  %d new, %d updated\n", stats.ReviewsInserted, stats.ReviewsUpdated)
+	fmt.Printf("  Comments: %d new\n", stats.ResponsesInserted)
+	if stats.Skipped > 0 {
+		fmt.Printf("  Unchanged: %d\n", stats.Skipped)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/teamsync"
+	"github.com/spf13/cobra"
+)
+
+// openSyncRemote opens the team sync remote from the global config and the
+// local database
+func openSyncRemote() (*teamsync.Remote, *storage.DB, error) {
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	remote, err := teamsync.New(cfg.Sync.Remote)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("open database: %w", err)
+	}
+	return remote, db, nil
+}
+
+func pushCmd() *cobra.Command {
+	var full bool
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Push review history to the team's sync remote",
+		Long: `Upload the finished jobs, reviews and comments that changed since the last
+push to the team's sync remote, a shared directory or S3/GCS bucket prefix
+configured in [sync.remote]. The bundle is encrypted with the team's sync
+key before it leaves this machine. Teammates merge it with 'roborev pull'.
+
+Examples:
+  roborev push
+  roborev push --full   # Push the whole history again`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, db, err := openSyncRemote()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+			defer cancel()
+			res, err := remote.Push(ctx, db, full)
+			if err != nil {
+				return fmt.Errorf("push to %s: %w", remote.Name(), err)
+			}
+			if res.Key == "" {
+				fmt.Printf("Nothing new to push to %s\n", remote.Name())
+				return nil
+			}
+			fmt.Printf("Pushed to %s: %d jobs, %d reviews, %d comments\n", remote.Name(), res.Jobs, res.Reviews, res.Responses)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&full, "full", false, "ignore the saved cursor and push everything")
+
+	return cmd
+}
+
+func pullCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull",
+		Short: "Merge review history teammates pushed to the sync remote",
+		Long: `Download and merge the bundles teammates pushed to the team's sync remote
+since the last pull. When a review exists on both sides, the most recently
+updated copy wins; originating machine IDs are preserved.
+
+Bundles that can't be decrypted with this machine's sync key stop the pull.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, db, err := openSyncRemote()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+			defer cancel()
+			res, err := remote.Pull(ctx, db)
+			if err != nil {
+				return fmt.Errorf("pull from %s: %w", remote.Name(), err)
+			}
+			if res.Bundles == 0 {
+				fmt.Printf("Already up to date with %s\n", remote.Name())
+				return nil
+			}
+			wakeQueue() // Jobs finished elsewhere may release jobs waiting on them
+			stats := res.Stats
+			fmt.Printf("Merged %d bundles from %s\n", res.Bundles, remote.Name())
+			fmt.Printf("  Jobs:     %d new, %d updated\n", stats.JobsInserted, stats.JobsUpdated)
+			fmt.Printf("  Reviews:  %d new, %d updated\n", stats.ReviewsInserted, stats.ReviewsUpdated)
+			fmt.Printf("  Comments: %d new\n", stats.ResponsesInserted)
+			if stats.Skipped > 0 {
+				fmt.Printf("  Unchanged: %d\n", stats.Skipped)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+func telemetryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "Manage opt-in anonymized usage telemetry",
+		Long: `Manage opt-in anonymized usage telemetry.
+
+Telemetry is disabled by default. When enabled, the daemon records one event
+per finished job containing only the agent name, job type, duration, and a
+coarse error class (e.g. "timeout", "agent_rate_limit"). Code, prompts, review
+output, repo names, paths, commit SHAs, and error messages are never recorded.
+
+Events are queued locally in the roborev data directory and are only uploaded
+if telemetry.endpoint is configured. Use 'roborev telemetry show --json' to
+inspect exactly what is queued.
+
+Subcommands:
+  enable   - Opt in to telemetry
+  disable  - Opt out and delete any queued events
+  show     - Show telemetry status and a summary of queued events
+`,
+	}
+
+	cmd.AddCommand(telemetryEnableCmd())
+	cmd.AddCommand(telemetryDisableCmd())
+	cmd.AddCommand(telemetryShowCmd())
+
+	return cmd
+}
+
+func telemetryEnableCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "enable",
+		Short: "Opt in to anonymized usage telemetry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setConfigKey(config.GlobalConfigPath(), "telemetry.enabled", "true", true); err != nil {
+				return fmt.Errorf("enable telemetry: %w", err)
+			}
+			fmt.Println("Telemetry enabled. Thank you!")
+			fmt.Println("Run 'roborev telemetry show' at any time to see what has been recorded.")
+			return nil
+		},
+	}
+}
+
+func telemetryDisableCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable",
+		Short: "Opt out of telemetry and delete queued events",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setConfigKey(config.GlobalConfigPath(), "telemetry.enabled", "false", true); err != nil {
+				return fmt.Errorf("disable telemetry: %w", err)
+			}
+			if err := telemetry.NewQueue(telemetry.DefaultQueuePath()).Clear(); err != nil {
+				return fmt.Errorf("clear telemetry queue: %w", err)
+			}
+			fmt.Println("Telemetry disabled and queued events deleted.")
+			return nil
+		},
+	}
+}
+
+func telemetryShowCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show telemetry status and queued events",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadGlobal()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			events, err := telemetry.NewQueue(telemetry.DefaultQueuePath()).Pending()
+			if err != nil {
+				return fmt.Errorf("read telemetry queue: %w", err)
+			}
+
+			if jsonOutput {
+				if events == nil {
+					events = []telemetry.Event{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(events)
+			}
+
+			status := "disabled"
+			if cfg.Telemetry.Enabled {
+				status = "enabled"
+			}
+			fmt.Printf("Telemetry: %s\n", status)
+			if cfg.Telemetry.Endpoint != "" {
+				fmt.Printf("Endpoint:  %s\n", cfg.Telemetry.Endpoint)
+			} else {
+				fmt.Println("Endpoint:  none (events are kept locally)")
+			}
+			fmt.Printf("Queued:    %d event(s) in %s\n", len(events), telemetry.DefaultQueuePath())
+
+			if len(events) == 0 {
+				return nil
+			}
+
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "AGENT\tCOMPLETED\tFAILED\tAVG DURATION\tERRORS\n")
+			for _, s := range telemetry.Summarize(events) {
+				avg := "-"
+				if s.Completed > 0 {
+					avg = (time.Duration(s.AvgDurationMs) * time.Millisecond).Round(time.Second).String()
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", s.Agent, s.Completed, s.Failed, avg, formatErrorClasses(s.ErrorClasses))
+			}
+			w.Flush()
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the raw queued events as JSON")
+
+	return cmd
+}
+
+// formatErrorClasses renders error class counts as "timeout=2, auth=1",
+// most frequent first.
+func formatErrorClasses(classes map[string]int) string {
+	if len(classes) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(classes))
+	for name := range classes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if classes[names[i]] != classes[names[j]] {
+			return classes[names[i]] > classes[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, classes[name])
+	}
+	return strings.Join(parts, ", ")
+}
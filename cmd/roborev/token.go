@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func tokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens for remote access to the daemon",
+		Long: `Manage API tokens for clients on other machines.
+
+Once any token exists, requests to the daemon from other machines must send
+one ("Authorization: Bearer <token>"); requests from this machine are always
+allowed. Each token has a role:
+
+  viewer     read reviews, jobs and status
+  reviewer   also comment on, address and triage reviews
+  admin      also manage the queue, repos, sync and tokens
+  enqueue    only enqueue reviews of one repo (for CI pipelines)
+
+Remote roborev commands send the token in ROBOREV_TOKEN to the daemon named
+by --server.`,
+	}
+
+	cmd.AddCommand(tokenCreateCmd())
+	cmd.AddCommand(tokenListCmd())
+	cmd.AddCommand(tokenRevokeCmd())
+
+	return cmd
+}
+
+func tokenCreateCmd() *cobra.Command {
+	var (
+		role     string
+		repoPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API token",
+		Long: `Create an API token. The token is printed once and cannot be shown again.
+
+An enqueue token can only enqueue reviews (not custom prompts or agentic
+jobs) of the repo given by --repo, the current directory by default, and
+cannot read anything back. Give it to CI pipelines that run
+"roborev review --server".
+
+Examples:
+  roborev token create dashboard
+  roborev token create alice --role reviewer
+  roborev token create ci-backend --role enqueue --repo ~/src/backend`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := daemon.CreateTokenRequest{Name: args[0], Role: role}
+			if role == storage.RoleEnqueue {
+				if repoPath == "" {
+					repoPath = "."
+				}
+				root, err := git.GetMainRepoRoot(repoPath)
+				if err != nil {
+					return fmt.Errorf("not a git repository: %w", err)
+				}
+				req.Repo = root
+			} else if repoPath != "" {
+				return fmt.Errorf("--repo only applies to enqueue tokens")
+			}
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var resp daemon.CreateTokenResponse
+			if err := tokenRequest(getDaemonAddr(), http.MethodPost, "/api/tokens",
+				req, http.StatusCreated, &resp); err != nil {
+				return err
+			}
+			if resp.Info.Repo != "" {
+				cmd.Printf("Created %s token %q for %s:\n\n  %s\n\n", resp.Info.Role, resp.Info.Name, resp.Info.Repo, resp.Token)
+			} else {
+				cmd.Printf("Created %s token %q:\n\n  %s\n\n", resp.Info.Role, resp.Info.Name, resp.Token)
+			}
+			cmd.Println("Store it now; it cannot be shown again.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", storage.RoleViewer, "token role: viewer, reviewer, admin or enqueue")
+	cmd.Flags().StringVar(&repoPath, "repo", "", "repo an enqueue token is limited to (default: current directory)")
+
+	return cmd
+}
+
+func tokenListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			var resp struct {
+				Tokens []storage.APIToken `json:"tokens"`
+			}
+			if err := tokenRequest(getDaemonAddr(), http.MethodGet, "/api/tokens", nil, http.StatusOK, &resp); err != nil {
+				return err
+			}
+			printTokens(cmd.OutOrStdout(), resp.Tokens)
+			return nil
+		},
+	}
+}
+
+func tokenRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <name>",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			if err := tokenRequest(getDaemonAddr(), http.MethodPost, "/api/tokens/revoke",
+				map[string]string{"name": args[0]}, http.StatusOK, nil); err != nil {
+				return err
+			}
+			cmd.Printf("Revoked token %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+// tokenRequest sends body (if any) as JSON and decodes the response into
+// out (if any), failing unless the daemon answers with status want
+func tokenRequest(addr, method, path string, body any, want int, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, addr+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if json.Unmarshal(msg, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("%s", errResp.Error)
+		}
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+func printTokens(w io.Writer, tokens []storage.APIToken) {
+	if len(tokens) == 0 {
+		fmt.Fprintln(w, "No API tokens; the daemon accepts requests from other machines without one")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tROLE\tCREATED\tLAST USED\tREPO")
+	for _, t := range tokens {
+		lastUsed := "never"
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.Name, t.Role, t.CreatedAt.Local().Format("2006-01-02"), lastUsed, t.Repo)
+	}
+	tw.Flush()
+}
+
+// tokenTransport sends ROBOREV_TOKEN to the daemon named by --server, and to
+// no other host
+type tokenTransport struct {
+	base  http.RoundTripper
+	token string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if u, err := url.Parse(serverAddr); err == nil && u.Host != "" && req.URL.Host == u.Host && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// installTokenTransport makes every default-transport HTTP client send
+// ROBOREV_TOKEN to the daemon, if it is set
+func installTokenTransport() {
+	token := strings.TrimSpace(os.Getenv("ROBOREV_TOKEN"))
+	if token == "" {
+		return
+	}
+	http.DefaultTransport = &tokenTransport{base: http.DefaultTransport, token: token}
+}
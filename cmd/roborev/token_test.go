@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestTokenRequest(t *testing.T) {
+	var got daemon.CreateTokenRequest
+	ts, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tokens":
+			json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(daemon.CreateTokenResponse{
+				Token: "rbv_abc",
+				Info:  storage.APIToken{Name: got.Name, Role: got.Role},
+			})
+		case "/api/tokens/revoke":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": `no token named "ghost"`})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer cleanup()
+
+	var resp daemon.CreateTokenResponse
+	err := tokenRequest(ts.URL, http.MethodPost, "/api/tokens",
+		daemon.CreateTokenRequest{Name: "alice", Role: storage.RoleReviewer}, http.StatusCreated, &resp)
+	if err != nil {
+		t.Fatalf("tokenRequest: %v", err)
+	}
+	if got.Name != "alice" || got.Role != storage.RoleReviewer || resp.Token != "rbv_abc" {
+		t.Errorf("unexpected request %+v or response %+v", got, resp)
+	}
+
+	err = tokenRequest(ts.URL, http.MethodPost, "/api/tokens/revoke", map[string]string{"name": "ghost"}, http.StatusOK, nil)
+	if err == nil || err.Error() != `no token named "ghost"` {
+		t.Errorf("expected the daemon's error, got %v", err)
+	}
+}
+
+func TestPrintTokens(t *testing.T) {
+	var out bytes.Buffer
+	printTokens(&out, nil)
+	if !strings.HasPrefix(out.String(), "No API tokens") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	used := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	printTokens(&out, []storage.APIToken{
+		{Name: "alice", Role: storage.RoleReviewer, CreatedAt: used, LastUsedAt: &used},
+		{Name: "ci", Role: storage.RoleEnqueue, Repo: "/src/app", CreatedAt: used},
+		{Name: "dashboard", Role: storage.RoleViewer, CreatedAt: used},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || strings.Join(strings.Fields(lines[2]), " ") != "ci enqueue 2026-03-01 never /src/app" ||
+		strings.Join(strings.Fields(lines[3]), " ") != "dashboard viewer 2026-03-01 never" {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestTokenTransport(t *testing.T) {
+	var daemonAuth, otherAuth string
+	daemonSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		daemonAuth = r.Header.Get("Authorization")
+	}))
+	defer daemonSrv.Close()
+	otherSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = r.Header.Get("Authorization")
+	}))
+	defer otherSrv.Close()
+	patchServerAddr(t, daemonSrv.URL)
+
+	client := &http.Client{Transport: &tokenTransport{base: http.DefaultTransport, token: "rbv_secret"}}
+	for _, u := range []string{daemonSrv.URL + "/api/jobs", otherSrv.URL + "/elsewhere"} {
+		resp, err := client.Get(u)
+		if err != nil {
+			t.Fatalf("GET %s: %v", u, err)
+		}
+		resp.Body.Close()
+	}
+	if daemonAuth != "Bearer rbv_secret" {
+		t.Errorf("expected the token sent to the daemon, got %q", daemonAuth)
+	}
+	if otherAuth != "" {
+		t.Errorf("expected no token sent to other hosts, got %q", otherAuth)
+	}
+}
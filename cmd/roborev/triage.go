@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func triageCmd() *cobra.Command {
+	var (
+		repoPath   string
+		allRepos   bool
+		limit      int
+		suppressed bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "triage",
+		Short: "Walk through unresolved review findings one by one",
+		Long: `Walk through findings from unaddressed reviews, newest first, and record a
+decision for each.
+
+Each severity-labeled finding in a review is triaged separately. Decisions
+are stored in the database; triaged findings are not shown again. Reviews
+marked addressed drop out of the queue.
+
+Findings suppressed by the repo's .roborev-ignore file are not shown; list
+them with --suppressed. Each finding shows its fingerprint, which is how
+.roborev-ignore names a single finding:
+
+  # False positive: the token is a public test fixture
+  fingerprint:3fa2b1c4d5e6f708
+
+Keys (followed by Enter):
+  a  accept the finding
+  d  dismiss the finding
+  s  assign the finding to someone (prompts for a name)
+  n  skip for now
+  q  quit
+
+'roborev status' shows how many findings are still untriaged.
+
+Examples:
+  roborev triage
+  roborev triage --all
+  roborev triage --repo ~/src/myproject
+  roborev triage --suppressed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo := ""
+			if !allRepos {
+				if repoPath == "" {
+					repoPath = "."
+				}
+				root, err := git.GetMainRepoRoot(repoPath)
+				if err != nil {
+					return fmt.Errorf("not a git repository (use --all for every repo): %w", err)
+				}
+				repo = root
+			}
+
+			if err := ensureDaemon(); err != nil {
+				return fmt.Errorf("daemon not running: %w", err)
+			}
+			addr := getDaemonAddr()
+
+			if suppressed {
+				list, err := listFindings(addr, repo, limit, true)
+				if err != nil {
+					return err
+				}
+				printSuppressed(cmd.OutOrStdout(), list)
+				return nil
+			}
+
+			list, err := getUntriaged(addr, repo, limit)
+			if err != nil {
+				return err
+			}
+			return runTriage(cmd.InOrStdin(), cmd.OutOrStdout(), list, func(req daemon.TriageDecisionRequest) error {
+				return postTriageDecision(addr, req)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", "", "path to git repository (default: current directory)")
+	cmd.Flags().BoolVar(&allRepos, "all", false, "triage findings from all repos")
+	cmd.Flags().IntVar(&limit, "limit", 100, "maximum number of findings to walk through")
+	cmd.Flags().BoolVar(&suppressed, "suppressed", false, "list findings suppressed by .roborev-ignore instead")
+
+	return cmd
+}
+
+// runTriage prompts for a decision on each item, reading keys from in and
+// recording decisions with decide.
+func runTriage(in io.Reader, out io.Writer, list daemon.TriageListResponse, decide func(daemon.TriageDecisionRequest) error) error {
+	if len(list.Items) == 0 {
+		fmt.Fprintln(out, "No untriaged findings")
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	counts := make(map[string]int)
+	for i, item := range list.Items {
+		fmt.Fprintf(out, "\n[%d/%d] %s %s  review #%d (job %d, %s, %s)\n",
+			i+1, list.Total, item.RepoName, shortRef(item.GitRef), item.ReviewID, item.JobID,
+			item.Agent, item.CreatedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(out, "%s\n\n", item.Finding.Text)
+		fmt.Fprintf(out, "Fingerprint: %s\n", storage.FindingFingerprint(item.Finding.Text))
+		if item.Finding.IssueURL != "" {
+			fmt.Fprintf(out, "Issue: %s\n\n", item.Finding.IssueURL)
+		}
+
+		req := daemon.TriageDecisionRequest{ReviewID: item.ReviewID, FindingIndex: item.Finding.Index}
+	prompt:
+		for {
+			fmt.Fprint(out, "[a]ccept [d]ismiss a[s]sign [n]ext [q]uit: ")
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Fprintln(out)
+				return triageSummary(out, counts)
+			}
+
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "a":
+				req.Decision = storage.TriageAccepted
+			case "d":
+				req.Decision = storage.TriageDismissed
+			case "s":
+				fmt.Fprint(out, "Assign to: ")
+				name, _ := reader.ReadString('\n')
+				if req.Assignee = strings.TrimSpace(name); req.Assignee == "" {
+					continue
+				}
+				req.Decision = storage.TriageAssigned
+			case "n":
+				counts["skipped"]++
+				break prompt
+			case "q":
+				return triageSummary(out, counts)
+			default:
+				continue
+			}
+
+			if err := decide(req); err != nil {
+				return fmt.Errorf("record decision: %w", err)
+			}
+			counts[req.Decision]++
+			break prompt
+		}
+	}
+	return triageSummary(out, counts)
+}
+
+func triageSummary(out io.Writer, counts map[string]int) error {
+	fmt.Fprintf(out, "Triaged: %d accepted, %d dismissed, %d assigned, %d skipped\n",
+		counts[storage.TriageAccepted], counts[storage.TriageDismissed],
+		counts[storage.TriageAssigned], counts["skipped"])
+	return nil
+}
+
+// printSuppressed lists suppressed findings with the .roborev-ignore
+// entries that matched them
+func printSuppressed(out io.Writer, list daemon.TriageListResponse) {
+	if len(list.Items) == 0 {
+		fmt.Fprintln(out, "No suppressed findings")
+		return
+	}
+	for i, item := range list.Items {
+		fmt.Fprintf(out, "\n[%d/%d] %s %s  review #%d (job %d, %s, %s)\n",
+			i+1, list.Total, item.RepoName, shortRef(item.GitRef), item.ReviewID, item.JobID,
+			item.Agent, item.CreatedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(out, "%s\n\n", item.Finding.Text)
+		if s := item.Suppression; s != nil {
+			fmt.Fprintf(out, "Suppressed by %s: %s\n", s.Entry, s.Justification)
+		}
+	}
+}
+
+func getUntriaged(addr, repo string, limit int) (daemon.TriageListResponse, error) {
+	return listFindings(addr, repo, limit, false)
+}
+
+// listFindings lists untriaged findings, or suppressed ones, from the daemon
+func listFindings(addr, repo string, limit int, suppressed bool) (daemon.TriageListResponse, error) {
+	var list daemon.TriageListResponse
+	params := url.Values{"limit": {strconv.Itoa(limit)}}
+	if repo != "" {
+		params.Set("repo", repo)
+	}
+	if suppressed {
+		params.Set("suppressed", "1")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(addr + "/api/triage?" + params.Encode())
+	if err != nil {
+		return list, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && repo != "" {
+		// Repo has never been reviewed
+		return list, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return list, fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return list, fmt.Errorf("decode findings: %w", err)
+	}
+	return list, nil
+}
+
+// getUntriagedCount returns the number of untriaged findings in repo, or
+// in all repos when repo is empty.
+func getUntriagedCount(addr, repo string) (int, error) {
+	list, err := getUntriaged(addr, repo, 0)
+	return list.Total, err
+}
+
+func postTriageDecision(addr string, req daemon.TriageDecisionRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(addr+"/api/triage/decide", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/daemon"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestRunTriage(t *testing.T) {
+	item := func(reviewID int64, index int) storage.TriageItem {
+		return storage.TriageItem{
+			ReviewID: reviewID,
+			JobID:    reviewID,
+			GitRef:   "abc1234def",
+			RepoName: "repo",
+			Finding:  storage.Finding{Index: index, Severity: "high", Text: "- High: bug"},
+		}
+	}
+	list := daemon.TriageListResponse{
+		Items: []storage.TriageItem{item(1, 0), item(1, 1), item(2, 0), item(3, 0), item(4, 0)},
+		Total: 5,
+	}
+
+	var got []daemon.TriageDecisionRequest
+	decide := func(req daemon.TriageDecisionRequest) error {
+		got = append(got, req)
+		return nil
+	}
+
+	// Unknown keys and an empty assignee re-prompt for the same finding
+	in := strings.NewReader("a\nx\nd\ns\n\ns\n alice \nn\nq\n")
+	var out bytes.Buffer
+	if err := runTriage(in, &out, list, decide); err != nil {
+		t.Fatalf("runTriage: %v", err)
+	}
+
+	want := []daemon.TriageDecisionRequest{
+		{ReviewID: 1, FindingIndex: 0, Decision: storage.TriageAccepted},
+		{ReviewID: 1, FindingIndex: 1, Decision: storage.TriageDismissed},
+		{ReviewID: 2, FindingIndex: 0, Decision: storage.TriageAssigned, Assignee: "alice"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d decisions, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("decision %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if !strings.Contains(out.String(), "Fingerprint: "+storage.FindingFingerprint("- High: bug")) {
+		t.Errorf("expected the finding's fingerprint in output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Triaged: 1 accepted, 1 dismissed, 1 assigned, 1 skipped") {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+}
+
+func TestRunTriageEmpty(t *testing.T) {
+	var out bytes.Buffer
+	err := runTriage(strings.NewReader(""), &out, daemon.TriageListResponse{}, func(daemon.TriageDecisionRequest) error {
+		t.Fatal("decide should not be called")
+		return nil
+	})
+	if err != nil || !strings.Contains(out.String(), "No untriaged findings") {
+		t.Errorf("runTriage() = %v, output %q", err, out.String())
+	}
+}
+
+func TestPrintSuppressed(t *testing.T) {
+	var out bytes.Buffer
+	printSuppressed(&out, daemon.TriageListResponse{})
+	if !strings.Contains(out.String(), "No suppressed findings") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	printSuppressed(&out, daemon.TriageListResponse{Total: 1, Items: []storage.TriageItem{{
+		ReviewID:    1,
+		JobID:       1,
+		GitRef:      "abc1234def",
+		RepoName:    "repo",
+		Finding:     storage.Finding{Index: 0, Severity: "low", Text: "- Low: nit in vendor/x.go"},
+		Suppression: &storage.FindingSuppression{Entry: "path:vendor/", Justification: "Vendored"},
+	}}})
+	if !strings.Contains(out.String(), "- Low: nit in vendor/x.go") || !strings.Contains(out.String(), "Suppressed by path:vendor/: Vendored") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/daemon"
 	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/i18n"
 	"github.com/roborev-dev/roborev/internal/storage"
 	"github.com/roborev-dev/roborev/internal/update"
 	"github.com/roborev-dev/roborev/internal/version"
@@ -1438,7 +1439,7 @@ func (m tuiModel) isJobVisible(job storage.ReviewJob) bool {
 		return false
 	}
 	if m.hideAddressed {
-		// Hide addressed reviews, failed jobs, and canceled jobs
+		// Hide addressed reviews and failed, canceled, or skipped jobs
 		// Check pendingAddressed first for optimistic updates (avoids flash on filter)
 		if pending, ok := m.pendingAddressed[job.ID]; ok {
 			if pending.newState {
@@ -1447,7 +1448,7 @@ func (m tuiModel) isJobVisible(job storage.ReviewJob) bool {
 		} else if job.Addressed != nil && *job.Addressed {
 			return false
 		}
-		if job.Status == storage.JobStatusFailed || job.Status == storage.JobStatusCanceled {
+		if job.Status == storage.JobStatusFailed || job.Status == storage.JobStatusCanceled || job.Status == storage.JobStatusSkipped {
 			return false
 		}
 	}
@@ -1857,7 +1858,7 @@ func (m tuiModel) renderQueueView() string {
 	var b strings.Builder
 
 	// Title with version, optional update notification, and filter indicators (in stack order)
-	title := fmt.Sprintf("roborev queue (%s)", version.Version)
+	title := i18n.T("roborev queue (%s)", version.Version)
 	for _, filterType := range m.filterStack {
 		switch filterType {
 		case filterTypeRepo:
@@ -1872,7 +1873,7 @@ func (m tuiModel) renderQueueView() string {
 		}
 	}
 	if m.hideAddressed {
-		title += " [hiding addressed]"
+		title += " " + i18n.T("[hiding addressed]")
 	}
 	b.WriteString(tuiTitleStyle.Render(title))
 	b.WriteString("\x1b[K\n") // Clear to end of line
@@ -1907,10 +1908,10 @@ func (m tuiModel) renderQueueView() string {
 		unaddressed = m.jobStats.Unaddressed
 	}
 	if len(m.activeRepoFilter) > 0 || m.activeBranchFilter != "" {
-		statusLine = fmt.Sprintf("Daemon: %s | Done: %d | Addressed: %d | Unaddressed: %d",
+		statusLine = i18n.T("Daemon: %s | Done: %d | Addressed: %d | Unaddressed: %d",
 			m.daemonVersion, done, addressed, unaddressed)
 	} else {
-		statusLine = fmt.Sprintf("Daemon: %s | Workers: %d/%d | Done: %d | Addressed: %d | Unaddressed: %d",
+		statusLine = i18n.T("Daemon: %s | Workers: %d/%d | Done: %d | Addressed: %d | Unaddressed: %d",
 			m.daemonVersion,
 			m.status.ActiveWorkers, m.status.MaxWorkers,
 			done, addressed, unaddressed)
@@ -1923,9 +1924,9 @@ func (m tuiModel) renderQueueView() string {
 		updateStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "136", Dark: "226"}).Bold(true)
 		var updateMsg string
 		if m.updateIsDevBuild {
-			updateMsg = fmt.Sprintf("Dev build - latest release: %s - run 'roborev update --force'", m.updateAvailable)
+			updateMsg = i18n.T("Dev build - latest release: %s - run 'roborev update --force'", m.updateAvailable)
 		} else {
-			updateMsg = fmt.Sprintf("Update available: %s - run 'roborev update'", m.updateAvailable)
+			updateMsg = i18n.T("Update available: %s - run 'roborev update'", m.updateAvailable)
 		}
 		b.WriteString(updateStyle.Render(updateMsg))
 	}
@@ -1949,13 +1950,13 @@ func (m tuiModel) renderQueueView() string {
 
 	if len(visibleJobList) == 0 {
 		if m.loadingJobs || m.loadingMore {
-			b.WriteString("Loading...")
+			b.WriteString(i18n.T("Loading..."))
 			b.WriteString("\x1b[K\n")
 		} else if len(m.activeRepoFilter) > 0 || m.hideAddressed {
-			b.WriteString("No jobs matching filters")
+			b.WriteString(i18n.T("No jobs matching filters"))
 			b.WriteString("\x1b[K\n")
 		} else {
-			b.WriteString("No jobs in queue")
+			b.WriteString(i18n.T("No jobs in queue"))
 			b.WriteString("\x1b[K\n")
 		}
 		// Pad empty queue to fill visibleRows (minus 1 for the message we just wrote)
@@ -2039,11 +2040,11 @@ func (m tuiModel) renderQueueView() string {
 		// Build scroll indicator if needed
 		if len(visibleJobList) > visibleRows || m.hasMore || m.loadingMore {
 			if m.loadingMore {
-				scrollInfo = fmt.Sprintf("[showing %d-%d of %d] Loading more...", start+1, end, len(visibleJobList))
+				scrollInfo = i18n.T("[showing %d-%d of %d] Loading more...", start+1, end, len(visibleJobList))
 			} else if m.hasMore && len(m.activeRepoFilter) <= 1 {
-				scrollInfo = fmt.Sprintf("[showing %d-%d of %d+] scroll down to load more", start+1, end, len(visibleJobList))
+				scrollInfo = i18n.T("[showing %d-%d of %d+] scroll down to load more", start+1, end, len(visibleJobList))
 			} else if len(visibleJobList) > visibleRows {
-				scrollInfo = fmt.Sprintf("[showing %d-%d of %d]", start+1, end, len(visibleJobList))
+				scrollInfo = i18n.T("[showing %d-%d of %d]", start+1, end, len(visibleJobList))
 			}
 		}
 	}
@@ -2054,19 +2055,22 @@ func (m tuiModel) renderQueueView() string {
 	}
 	b.WriteString("\x1b[K\n") // Clear scroll indicator line
 
-	// Status line: flash message (temporary)
+	// Status line: flash message (temporary), else the selected job's quick take
 	// Version mismatch takes priority over flash messages (it's persistent and important)
 	if m.versionMismatch {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "124", Dark: "196"}).Bold(true) // Red
-		b.WriteString(errorStyle.Render(fmt.Sprintf("VERSION MISMATCH: TUI %s != Daemon %s - restart TUI or daemon", version.Version, m.daemonVersion)))
+		b.WriteString(errorStyle.Render(i18n.T("VERSION MISMATCH: TUI %s != Daemon %s - restart TUI or daemon", version.Version, m.daemonVersion)))
 	} else if m.flashMessage != "" && time.Now().Before(m.flashExpiresAt) && m.flashView == tuiViewQueue {
 		flashStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "46"}) // Green
 		b.WriteString(flashStyle.Render(m.flashMessage))
+	} else if visibleSelectedIdx >= 0 && visibleSelectedIdx < len(visibleJobList) && visibleJobList[visibleSelectedIdx].QuickTake != "" {
+		quickTake := visibleJobList[visibleSelectedIdx].QuickTake
+		b.WriteString(tuiStatusStyle.Render(truncateString(quickTake, max(m.width-1, 1))))
 	}
 	b.WriteString("\x1b[K\n") // Clear to end of line
 
 	// Help
-	helpLine := "↑/↓: navigate | enter: review | a: addressed | f: filter | h: hide | ?: help | q: quit"
+	helpLine := i18n.T("↑/↓: navigate | enter: review | a: addressed | f: filter | h: hide | ?: help | q: quit")
 	b.WriteString(tuiHelpStyle.Render(helpLine))
 	b.WriteString("\x1b[K") // Clear to end of line (no newline at end)
 	b.WriteString("\x1b[J") // Clear to end of screen to prevent artifacts
@@ -2173,11 +2177,18 @@ func (m tuiModel) renderJobLine(job storage.ReviewJob, selected bool, idWidth in
 		}
 	}
 
-	// Color the status only when not selected (selection style should be uniform)
+	// Color the status only when not selected (selection style should be uniform).
+	// Unfinished jobs past their repo's review SLA are marked with "!".
 	status := string(job.Status)
+	overdue := job.Overdue && (job.Status == storage.JobStatusQueued || job.Status == storage.JobStatusRunning)
+	if overdue {
+		status += "!"
+	}
 	var styledStatus string
 	if selected {
 		styledStatus = status
+	} else if overdue {
+		styledStatus = tuiFailedStyle.Render(status)
 	} else {
 		switch job.Status {
 		case storage.JobStatusQueued:
@@ -2186,9 +2197,9 @@ func (m tuiModel) renderJobLine(job storage.ReviewJob, selected bool, idWidth in
 			styledStatus = tuiRunningStyle.Render(status)
 		case storage.JobStatusDone:
 			styledStatus = tuiDoneStyle.Render(status)
-		case storage.JobStatusFailed:
+		case storage.JobStatusFailed, storage.JobStatusBlocked:
 			styledStatus = tuiFailedStyle.Render(status)
-		case storage.JobStatusCanceled:
+		case storage.JobStatusCanceled, storage.JobStatusSkipped:
 			styledStatus = tuiCanceledStyle.Render(status)
 		default:
 			styledStatus = status
@@ -2389,7 +2400,7 @@ func (m tuiModel) renderReviewView() string {
 	}
 
 	// Help text wraps at narrow terminals
-	const helpText = "↑/↓: scroll | ←/→: prev/next | a: addressed | y: copy | ?: help | esc: back"
+	helpText := i18n.T("↑/↓: scroll | ←/→: prev/next | a: addressed | y: copy | ?: help | esc: back")
 	helpLines := 1
 	if m.width > 0 && m.width < len(helpText) {
 		helpLines = (len(helpText) + m.width - 1) / m.width
@@ -2452,7 +2463,7 @@ func (m tuiModel) renderReviewView() string {
 	// Status line: version mismatch (persistent) takes priority, then flash message, then scroll indicator
 	if m.versionMismatch {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "124", Dark: "196"}).Bold(true) // Red
-		b.WriteString(errorStyle.Render(fmt.Sprintf("VERSION MISMATCH: TUI %s != Daemon %s - restart TUI or daemon", version.Version, m.daemonVersion)))
+		b.WriteString(errorStyle.Render(i18n.T("VERSION MISMATCH: TUI %s != Daemon %s - restart TUI or daemon", version.Version, m.daemonVersion)))
 	} else if m.flashMessage != "" && time.Now().Before(m.flashExpiresAt) && m.flashView == tuiViewReview {
 		flashStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "46"}) // Green
 		b.WriteString(flashStyle.Render(m.flashMessage))
@@ -3090,75 +3101,75 @@ func helpLines() []string {
 		keys  []struct{ key, desc string }
 	}{
 		{
-			group: "Queue View",
+			group: i18n.T("Queue View"),
 			keys: []struct{ key, desc string }{
-				{"↑/k, ↓/j", "Navigate jobs"},
-				{"g/Home", "Jump to top"},
-				{"PgUp/PgDn", "Page through list"},
-				{"enter", "View review"},
-				{"p", "View prompt"},
-				{"t", "Tail running job output"},
-				{"m", "View commit message"},
+				{"↑/k, ↓/j", i18n.T("Navigate jobs")},
+				{"g/Home", i18n.T("Jump to top")},
+				{"PgUp/PgDn", i18n.T("Page through list")},
+				{"enter", i18n.T("View review")},
+				{"p", i18n.T("View prompt")},
+				{"t", i18n.T("Tail running job output")},
+				{"m", i18n.T("View commit message")},
 			},
 		},
 		{
-			group: "Actions",
+			group: i18n.T("Actions"),
 			keys: []struct{ key, desc string }{
-				{"a", "Toggle addressed"},
-				{"c", "Add comment"},
-				{"y", "Copy review to clipboard"},
-				{"x", "Cancel running/queued job"},
-				{"r", "Re-run completed/failed job"},
+				{"a", i18n.T("Toggle addressed")},
+				{"c", i18n.T("Add comment")},
+				{"y", i18n.T("Copy review to clipboard")},
+				{"x", i18n.T("Cancel running/queued job")},
+				{"r", i18n.T("Re-run completed/failed job")},
 			},
 		},
 		{
-			group: "Filtering",
+			group: i18n.T("Filtering"),
 			keys: []struct{ key, desc string }{
-				{"f", "Filter by repository"},
-				{"b", "Filter by branch"},
-				{"h", "Toggle hide addressed/failed"},
-				{"esc", "Clear filters (one at a time)"},
+				{"f", i18n.T("Filter by repository")},
+				{"b", i18n.T("Filter by branch")},
+				{"h", i18n.T("Toggle hide addressed/failed")},
+				{"esc", i18n.T("Clear filters (one at a time)")},
 			},
 		},
 		{
-			group: "Review View",
+			group: i18n.T("Review View"),
 			keys: []struct{ key, desc string }{
-				{"↑/↓", "Scroll content"},
-				{"←/→", "Previous / next review"},
-				{"PgUp/PgDn", "Page through content"},
-				{"p", "Switch to prompt view"},
-				{"a", "Toggle addressed"},
-				{"c", "Add comment"},
-				{"y", "Copy review to clipboard"},
-				{"m", "View commit message"},
-				{"esc/q", "Back to queue"},
+				{"↑/↓", i18n.T("Scroll content")},
+				{"←/→", i18n.T("Previous / next review")},
+				{"PgUp/PgDn", i18n.T("Page through content")},
+				{"p", i18n.T("Switch to prompt view")},
+				{"a", i18n.T("Toggle addressed")},
+				{"c", i18n.T("Add comment")},
+				{"y", i18n.T("Copy review to clipboard")},
+				{"m", i18n.T("View commit message")},
+				{"esc/q", i18n.T("Back to queue")},
 			},
 		},
 		{
-			group: "Prompt View",
+			group: i18n.T("Prompt View"),
 			keys: []struct{ key, desc string }{
-				{"↑/↓", "Scroll content"},
-				{"←/→", "Previous / next prompt"},
-				{"PgUp/PgDn", "Page through content"},
-				{"p", "Switch to review / back to queue"},
-				{"esc/q", "Back to queue"},
+				{"↑/↓", i18n.T("Scroll content")},
+				{"←/→", i18n.T("Previous / next prompt")},
+				{"PgUp/PgDn", i18n.T("Page through content")},
+				{"p", i18n.T("Switch to review / back to queue")},
+				{"esc/q", i18n.T("Back to queue")},
 			},
 		},
 		{
-			group: "Tail View",
+			group: i18n.T("Tail View"),
 			keys: []struct{ key, desc string }{
-				{"↑/↓", "Scroll output"},
-				{"PgUp/PgDn", "Page through output"},
-				{"g", "Toggle follow mode / jump to top"},
-				{"x", "Cancel job"},
-				{"esc/q", "Back to queue"},
+				{"↑/↓", i18n.T("Scroll output")},
+				{"PgUp/PgDn", i18n.T("Page through output")},
+				{"g", i18n.T("Toggle follow mode / jump to top")},
+				{"x", i18n.T("Cancel job")},
+				{"esc/q", i18n.T("Back to queue")},
 			},
 		},
 		{
-			group: "General",
+			group: i18n.T("General"),
 			keys: []struct{ key, desc string }{
-				{"?", "Toggle this help"},
-				{"q", "Quit (from queue view)"},
+				{"?", i18n.T("Toggle this help")},
+				{"q", i18n.T("Quit (from queue view)")},
 			},
 		},
 	}
@@ -3193,7 +3204,7 @@ func (m tuiModel) helpMaxScroll() int {
 func (m tuiModel) renderHelpView() string {
 	var b strings.Builder
 
-	b.WriteString(tuiTitleStyle.Render("Keyboard Shortcuts"))
+	b.WriteString(tuiTitleStyle.Render(i18n.T("Keyboard Shortcuts")))
 	b.WriteString("\x1b[K\n\x1b[K\n")
 
 	allLines := helpLines()
@@ -3237,7 +3248,7 @@ func (m tuiModel) renderHelpView() string {
 		linesWritten++
 	}
 
-	helpHint := "↑/↓: scroll | esc/q/?: close"
+	helpHint := i18n.T("↑/↓: scroll | esc/q/?: close")
 	b.WriteString(tuiHelpStyle.Render(helpHint))
 	b.WriteString("\x1b[K")
 	b.WriteString("\x1b[J") // Clear to end of screen
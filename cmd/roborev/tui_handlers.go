@@ -637,11 +637,18 @@ func (m tuiModel) handleEnterKey() (tea.Model, tea.Cmd) {
 	job := m.jobs[m.selectedIdx]
 	if job.Status == storage.JobStatusDone {
 		return m, m.fetchReview(job.ID)
-	} else if job.Status == storage.JobStatusFailed {
+	} else if job.Status == storage.JobStatusFailed || job.Status == storage.JobStatusSkipped || job.Status == storage.JobStatusBlocked {
+		heading := "Job failed:"
+		switch job.Status {
+		case storage.JobStatusSkipped:
+			heading = "Review skipped by commit message:"
+		case storage.JobStatusBlocked:
+			heading = "Job blocked:"
+		}
 		m.currentBranch = ""
 		m.currentReview = &storage.Review{
 			Agent:  job.Agent,
-			Output: "Job failed:\n\n" + job.Error,
+			Output: heading + "\n\n" + job.Error,
 			Job:    &job,
 		}
 		m.currentView = tuiViewReview
@@ -764,7 +771,7 @@ func (m tuiModel) handleRerunKey() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	job := &m.jobs[m.selectedIdx]
-	if job.Status == storage.JobStatusDone || job.Status == storage.JobStatusFailed || job.Status == storage.JobStatusCanceled {
+	if job.Status == storage.JobStatusDone || job.Status == storage.JobStatusFailed || job.Status == storage.JobStatusCanceled || job.Status == storage.JobStatusSkipped || job.Status == storage.JobStatusBlocked {
 		oldStatus := job.Status
 		oldStartedAt := job.StartedAt
 		oldFinishedAt := job.FinishedAt
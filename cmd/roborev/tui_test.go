@@ -975,6 +975,35 @@ func TestTUIFlashMessageAppearsInQueueView(t *testing.T) {
 	}
 }
 
+func TestTUIQueueViewShowsSelectedQuickTake(t *testing.T) {
+	m := newTuiModel("http://localhost")
+	m.currentView = tuiViewQueue
+	m.width = 80
+	m.height = 24
+	m.jobs = []storage.ReviewJob{
+		makeJob(2, func(j *storage.ReviewJob) { j.QuickTake = "Fails: the cache is never invalidated." }),
+		makeJob(1, func(j *storage.ReviewJob) { j.QuickTake = "Passes." }),
+	}
+	m.selectedIdx = 0
+	m.selectedJobID = 2
+
+	output := m.renderQueueView()
+	if !strings.Contains(output, "the cache is never invalidated") {
+		t.Error("Expected the selected job's quick take in queue view")
+	}
+	if strings.Contains(output, "Passes.") {
+		t.Error("Expected only the selected job's quick take in queue view")
+	}
+
+	// A flash message takes the line while it lasts
+	m.flashMessage = "Copied to clipboard"
+	m.flashExpiresAt = time.Now().Add(2 * time.Second)
+	m.flashView = tuiViewQueue
+	if output := m.renderQueueView(); strings.Contains(output, "the cache is never invalidated") {
+		t.Error("Expected flash message to replace the quick take")
+	}
+}
+
 func TestTUIFlashMessageNotShownInDifferentView(t *testing.T) {
 	m := newTuiModel("http://localhost")
 	m.currentView = tuiViewReview
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func undoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo [operation-id]",
+		Short: "Restore data removed by a destructive command",
+		Long: `Restore the rows removed by a destructive command such as
+'roborev repo delete' or 'roborev db prune'. Those commands move what they
+delete to the trash and print an operation ID; the trash keeps it for
+trash_retention (default 30 days).
+
+Without an argument, lists the operations that can still be undone.
+
+Examples:
+  roborev undo
+  roborev undo 3f9a1c0e
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := storage.Open(storage.DefaultDBPath())
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer db.Close()
+			out := cmd.OutOrStdout()
+
+			if len(args) == 0 {
+				ops, err := db.ListTrash()
+				if err != nil {
+					return fmt.Errorf("list trash: %w", err)
+				}
+				if len(ops) == 0 {
+					fmt.Fprintln(out, "Nothing to undo")
+					return nil
+				}
+				tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "ID\tOPERATION\tWHEN\tROWS\tEXPIRES\tDELETED")
+				for _, op := range ops {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", op.ID, op.Operation,
+						op.CreatedAt.Local().Format("2006-01-02 15:04"), op.Rows,
+						op.ExpiresAt.Local().Format("2006-01-02"), op.Summary)
+				}
+				return tw.Flush()
+			}
+
+			op, err := db.UndoTrash(cmd.Context(), args[0])
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no operation %q in the trash (it may have expired; see 'roborev undo')", args[0])
+			}
+			if errors.Is(err, storage.ErrUndoConflict) {
+				return fmt.Errorf("cannot undo %s: %w\nRemove what was recreated (e.g. a repository added again) and retry", args[0], err)
+			}
+			if err != nil {
+				return fmt.Errorf("undo: %w", err)
+			}
+			wakeQueue() // Restored jobs may be queued
+			fmt.Fprintf(out, "Restored %s (%d rows) removed by %s\n", op.Summary, op.Rows, op.Operation)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestUndoCmd(t *testing.T) {
+	var wakes atomic.Int32
+	_, cleanup := setupMockDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/queue/wake" && r.Method == http.MethodPost {
+			wakes.Add(1)
+		}
+	}))
+	defer cleanup()
+
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "gone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := db.TrashRepo(t.Context(), repo.ID, true, time.Hour)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := undoCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run()
+	if err != nil || !strings.Contains(out, op.ID) || !strings.Contains(out, `repository "gone"`) {
+		t.Fatalf("expected the operation listed, got %q, %v", out, err)
+	}
+	if out, err = run(op.ID); err != nil || !strings.Contains(out, "Restored repository") {
+		t.Fatalf("expected the repo restored, got %q, %v", out, err)
+	}
+	if n := wakes.Load(); n != 1 {
+		t.Errorf("expected the daemon's queue woken once, got %d", n)
+	}
+	if _, err = run(op.ID); err == nil || !strings.Contains(err.Error(), "no operation") {
+		t.Errorf("expected a second undo to fail, got %v", err)
+	}
+	if out, _ = run(); !strings.Contains(out, "Nothing to undo") {
+		t.Errorf("expected an empty trash, got %q", out)
+	}
+}
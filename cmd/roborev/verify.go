@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/blobstore"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/signing"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func verifyCmd() *cobra.Command {
+	var trustedKeys []string
+
+	cmd := &cobra.Command{
+		Use:   "verify <review-id>",
+		Short: "Verify that a signed review has not been altered",
+		Long: `Verify the signature of a review record.
+
+When sign_reviews = true is set in ~/.roborev/config.toml, the daemon signs
+each completed review with a local ed25519 key. The signature covers the
+review and job IDs, the git ref, the agent, and SHA-256 digests of the full
+prompt and output, so any later edit to the stored review is detected.
+
+The public key is stored with the signature, so whoever can edit the review
+can also re-sign it with a key of their own. A review only verifies when it
+was signed by a trusted key: this machine's, one listed in
+trusted_review_keys in ~/.roborev/config.toml, or one given with --key.
+Keys are given as base64 public keys or as fingerprints.
+
+Exits with a non-zero status if the review is unsigned, the signature
+does not match, or the signing key is not trusted.
+
+Examples:
+  roborev verify 42
+  roborev verify 42 --key 3f2a9c1d0e8b7a65`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reviewID, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid review ID: %s", args[0])
+			}
+
+			store, err := openReviewStore(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			cfg, cfgErr := config.LoadGlobal()
+			if cfgErr == nil {
+				trustedKeys = append(trustedKeys, cfg.TrustedReviewKeys...)
+			}
+
+			// Offloaded prompts and outputs must be loaded to check digests
+			if db, local := store.(*storage.DB); cfgErr == nil && local {
+				blobs, err := blobstore.New(cfg.BlobStore)
+				if err != nil {
+					return fmt.Errorf("blob store: %w", err)
+				}
+				db.SetBlobStore(blobs, cfg.BlobStore.Threshold())
+			}
+
+			fail := func(format string, a ...any) error {
+				cmd.Printf(format+"\n", a...)
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return &exitError{code: 1}
+			}
+
+			publicKey, err := verifyReviewSignature(cmd.Context(), store, reviewID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return fmt.Errorf("review %d not found", reviewID)
+			case errors.Is(err, errReviewUnsigned):
+				return fail("Review %d is not signed", reviewID)
+			case err != nil:
+				return fail("Review %d FAILED verification: %v", reviewID, err)
+			}
+
+			fingerprint := signing.Fingerprint(publicKey)
+			owner := "a trusted key"
+			if publicKey == localSigningKey() {
+				owner = "this machine's key"
+			} else if !reviewKeyTrusted(publicKey, trustedKeys) {
+				return fail("Review %d FAILED verification: signed by untrusted key %s "+
+					"(trust it with --key or trusted_review_keys)", reviewID, fingerprint)
+			}
+			cmd.Printf("Review %d signature is valid (key %s, %s)\n", reviewID, fingerprint, owner)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&trustedKeys, "key", nil, "trusted signing key, as a base64 public key or fingerprint (repeatable)")
+
+	return cmd
+}
+
+var errReviewUnsigned = errors.New("review is not signed")
+
+// localSigningKey returns this machine's base64 public signing key, or ""
+// if it has none. Verifying must not create one.
+func localSigningKey() string {
+	if _, err := os.Stat(signing.DefaultKeyPath()); err != nil {
+		return ""
+	}
+	key, err := signing.LoadOrCreateKey(signing.DefaultKeyPath())
+	if err != nil {
+		return ""
+	}
+	return signing.EncodePublicKey(key)
+}
+
+// reviewKeyTrusted reports whether publicKey is one of trusted, given as
+// base64 public keys or fingerprints
+func reviewKeyTrusted(publicKey string, trusted []string) bool {
+	fingerprint := signing.Fingerprint(publicKey)
+	for _, key := range trusted {
+		key = strings.TrimSpace(key)
+		if key == publicKey || (fingerprint != "unknown" && strings.EqualFold(key, fingerprint)) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyReviewSignature checks the stored signature of a review against its
+// current contents and returns the base64 public key it was signed with.
+// The key comes from the review's own row, so the caller must check that it
+// is trusted.
+func verifyReviewSignature(ctx context.Context, db storage.Storage, reviewID int64) (string, error) {
+	review, err := db.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return "", err
+	}
+	if review.Signature == "" {
+		return "", errReviewUnsigned
+	}
+	job, err := db.GetJobByID(ctx, review.JobID)
+	if err != nil {
+		return "", fmt.Errorf("load job %d: %w", review.JobID, err)
+	}
+
+	rec := signing.ReviewRecord{
+		ReviewID:   review.ID,
+		ReviewUUID: review.UUID,
+		JobID:      review.JobID,
+		GitRef:     job.GitRef,
+		Agent:      review.Agent,
+		Prompt:     review.Prompt,
+		Output:     review.Output,
+	}
+	if err := signing.Verify(review.SigningKey, review.Signature, rec.Payload()); err != nil {
+		return "", err
+	}
+	return review.SigningKey, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/signing"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// releaseCommitStatus is the review state of one commit included in a release.
+type releaseCommitStatus struct {
+	SHA      string `json:"sha"`
+	Subject  string `json:"subject,omitempty"`
+	JobID    int64  `json:"job_id,omitempty"`
+	ReviewID int64  `json:"review_id,omitempty"`
+	Verdict  string `json:"verdict,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+}
+
+// releaseAttestation is the payload written by verify-release.
+// The signature covers the JSON encoding of the payload with Signature empty.
+type releaseAttestation struct {
+	Tag         string                `json:"tag"`
+	TagSHA      string                `json:"tag_sha"`
+	PreviousTag string                `json:"previous_tag,omitempty"`
+	Repo        string                `json:"repo"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Passed      bool                  `json:"passed"`
+	Commits     []releaseCommitStatus `json:"commits"`
+	PublicKey   string                `json:"public_key"`
+	Signature   string                `json:"signature,omitempty"`
+}
+
+func verifyReleaseCmd() *cobra.Command {
+	var (
+		repoPath   string
+		outputPath string
+		keyPath    string
+		checkPath  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify-release <tag>",
+		Short: "Check that every commit in a release has a clean review",
+		Long: `Verify that every commit since the previous tag has a completed review
+with no unresolved critical findings.
+
+A review counts as resolved when it has been marked addressed. On success,
+a signed attestation is written (default: roborev-attestation-<tag>.json)
+that can be attached to the release. The attestation is signed with an
+ed25519 key stored in the roborev data directory, created on first use;
+its public key is embedded in the attestation.
+
+Exits with a non-zero status if any commit fails the policy.
+
+Examples:
+  roborev verify-release v1.2.3
+  roborev verify-release v1.2.3 -o dist/attestation.json
+  roborev verify-release --check roborev-attestation-v1.2.3.json`,
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkPath != "" {
+				data, err := os.ReadFile(checkPath)
+				if err != nil {
+					return err
+				}
+				att, err := verifyReleaseAttestation(data)
+				if err != nil {
+					return fmt.Errorf("attestation %s is not valid: %w", checkPath, err)
+				}
+				cmd.Printf("Attestation for %s (%s) is valid, signed by key %s\n",
+					att.Tag, shortSHA(att.TagSHA), att.KeyFingerprint())
+				return nil
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("a tag argument is required")
+			}
+
+			tag := args[0]
+			root, err := git.GetRepoRoot(repoPath)
+			if err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			db, err := openReviewStore(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			att, err := buildReleaseAttestation(db, root, tag)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range att.Commits {
+				if c.Problem != "" {
+					cmd.Printf("FAIL %s %s: %s\n", shortSHA(c.SHA), c.Subject, c.Problem)
+				}
+			}
+			if !att.Passed {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return &exitError{code: 1}
+			}
+
+			if keyPath == "" {
+				keyPath = signing.DefaultKeyPath()
+			}
+			key, err := signing.LoadOrCreateKey(keyPath)
+			if err != nil {
+				return fmt.Errorf("signing key: %w", err)
+			}
+			data, err := signReleaseAttestation(att, key)
+			if err != nil {
+				return err
+			}
+
+			if outputPath == "" {
+				outputPath = "roborev-attestation-" + strings.ReplaceAll(tag, "/", "-") + ".json"
+			}
+			if err := os.WriteFile(outputPath, data, 0644); err != nil {
+				return fmt.Errorf("write attestation: %w", err)
+			}
+
+			cmd.Printf("All %d commit(s) in %s passed review\n", len(att.Commits), tag)
+			cmd.Printf("Attestation written to %s (key %s)\n", outputPath, att.KeyFingerprint())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repoPath, "repo", ".", "path to the git repository")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "attestation output path")
+	cmd.Flags().StringVar(&keyPath, "key", "", "ed25519 signing key path (default: <data dir>/attestation_ed25519)")
+	cmd.Flags().StringVar(&checkPath, "check", "", "verify the signature of an existing attestation file")
+
+	return cmd
+}
+
+// buildReleaseAttestation evaluates the review policy for every commit
+// between the tag preceding tag and tag itself.
+func buildReleaseAttestation(db storage.ReviewStore, repoRoot, tag string) (*releaseAttestation, error) {
+	tagSHA, err := git.ResolveSHA(repoRoot, tag+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", tag, err)
+	}
+	prevTag, err := git.GetPreviousTag(repoRoot, tag)
+	if err != nil {
+		return nil, fmt.Errorf("find previous tag: %w", err)
+	}
+
+	rangeRef := tagSHA
+	if prevTag != "" {
+		rangeRef = prevTag + ".." + tagSHA
+	}
+	shas, err := git.GetRangeCommits(repoRoot, rangeRef)
+	if err != nil {
+		return nil, err
+	}
+
+	att := &releaseAttestation{
+		Tag:         tag,
+		TagSHA:      tagSHA,
+		PreviousTag: prevTag,
+		Repo:        repoRoot,
+		GeneratedAt: time.Now().UTC(),
+		Passed:      true,
+	}
+	for _, sha := range shas {
+		status := releaseCommitStatus{SHA: sha}
+		if info, err := git.GetCommitInfo(repoRoot, sha); err == nil {
+			status.Subject = info.Subject
+		}
+
+		review, err := db.GetReviewByCommitSHA(context.Background(), sha)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status.Problem = "no completed review"
+		case err != nil:
+			return nil, fmt.Errorf("look up review for %s: %w", shortSHA(sha), err)
+		default:
+			status.JobID = review.JobID
+			status.ReviewID = review.ID
+			if review.Job != nil && review.Job.Verdict != nil {
+				status.Verdict = *review.Job.Verdict
+			}
+			if review.Job != nil && review.Job.Status != storage.JobStatusDone {
+				status.Problem = fmt.Sprintf("review job is %s", review.Job.Status)
+			} else if !review.Addressed && storage.HasCriticalFinding(review.Output) {
+				status.Problem = "unresolved critical finding"
+			}
+		}
+
+		if status.Problem != "" {
+			att.Passed = false
+		}
+		att.Commits = append(att.Commits, status)
+	}
+	return att, nil
+}
+
+// signReleaseAttestation signs att with key and returns the indented JSON
+// document to write to disk.
+func signReleaseAttestation(att *releaseAttestation, key ed25519.PrivateKey) ([]byte, error) {
+	att.PublicKey = signing.EncodePublicKey(key)
+	att.Signature = ""
+	payload, err := json.Marshal(att)
+	if err != nil {
+		return nil, fmt.Errorf("encode attestation: %w", err)
+	}
+	att.Signature = signing.Sign(key, payload)
+	return json.MarshalIndent(att, "", "  ")
+}
+
+// verifyReleaseAttestation checks the signature of an attestation document
+// against its embedded public key.
+func verifyReleaseAttestation(data []byte) (*releaseAttestation, error) {
+	var att releaseAttestation
+	if err := json.Unmarshal(data, &att); err != nil {
+		return nil, fmt.Errorf("parse attestation: %w", err)
+	}
+	unsigned := att
+	unsigned.Signature = ""
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	if err := signing.Verify(att.PublicKey, att.Signature, payload); err != nil {
+		return nil, err
+	}
+	return &att, nil
+}
+
+// KeyFingerprint returns a short, stable identifier for the signing key.
+func (a *releaseAttestation) KeyFingerprint() string {
+	return signing.Fingerprint(a.PublicKey)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/signing"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestBuildReleaseAttestation(t *testing.T) {
+	repo := newTestGitRepo(t)
+	repo.CommitFile("a.txt", "a", "initial")
+	repo.Run("tag", "v1.0.0")
+	clean := repo.CommitFile("b.txt", "b", "clean change")
+	critical := repo.CommitFile("c.txt", "c", "risky change")
+	unreviewed := repo.CommitFile("d.txt", "d", "unreviewed change")
+	repo.Run("tag", "v1.1.0")
+
+	db := testutil.OpenTestDB(t)
+	dbRepo, err := db.GetOrCreateRepo(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, dbRepo.ID, clean, "test", "No issues found.")
+	criticalJob := testutil.CreateCompletedReview(t, db, dbRepo.ID, critical, "test", "- Critical: deletes user data")
+
+	att, err := buildReleaseAttestation(db, repo.Dir, "v1.1.0")
+	if err != nil {
+		t.Fatalf("buildReleaseAttestation: %v", err)
+	}
+	if att.PreviousTag != "v1.0.0" {
+		t.Errorf("expected previous tag v1.0.0, got %q", att.PreviousTag)
+	}
+	if len(att.Commits) != 3 {
+		t.Fatalf("expected 3 commits since v1.0.0, got %d", len(att.Commits))
+	}
+	if att.Passed {
+		t.Error("expected policy failure")
+	}
+
+	problems := map[string]string{}
+	for _, c := range att.Commits {
+		problems[c.SHA] = c.Problem
+	}
+	if problems[clean] != "" {
+		t.Errorf("clean commit flagged: %q", problems[clean])
+	}
+	if !strings.Contains(problems[critical], "critical") {
+		t.Errorf("expected critical finding problem, got %q", problems[critical])
+	}
+	if !strings.Contains(problems[unreviewed], "no completed review") {
+		t.Errorf("expected missing review problem, got %q", problems[unreviewed])
+	}
+
+	// Addressing the critical review and reviewing the last commit clears the policy
+	if err := db.MarkReviewAddressedByJobID(t.Context(), criticalJob.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressedByJobID: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, dbRepo.ID, unreviewed, "test", "No issues found.")
+
+	att, err = buildReleaseAttestation(db, repo.Dir, "v1.1.0")
+	if err != nil {
+		t.Fatalf("buildReleaseAttestation: %v", err)
+	}
+	if !att.Passed {
+		t.Errorf("expected policy to pass, got %+v", att.Commits)
+	}
+}
+
+func TestReleaseAttestationSignature(t *testing.T) {
+	key, err := signing.LoadOrCreateKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+
+	att := &releaseAttestation{
+		Tag:     "v1.0.0",
+		TagSHA:  "abc123",
+		Passed:  true,
+		Commits: []releaseCommitStatus{{SHA: "abc123", JobID: 1, ReviewID: 1, Verdict: "P"}},
+	}
+	data, err := signReleaseAttestation(att, key)
+	if err != nil {
+		t.Fatalf("signReleaseAttestation: %v", err)
+	}
+
+	got, err := verifyReleaseAttestation(data)
+	if err != nil {
+		t.Fatalf("verifyReleaseAttestation: %v", err)
+	}
+	if got.Tag != "v1.0.0" {
+		t.Errorf("unexpected tag %q", got.Tag)
+	}
+
+	tampered := strings.Replace(string(data), `"verdict": "P"`, `"verdict": "F"`, 1)
+	if _, err := verifyReleaseAttestation([]byte(tampered)); err == nil {
+		t.Error("expected tampered attestation to fail verification")
+	}
+}
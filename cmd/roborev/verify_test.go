@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/signing"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestVerifyReviewSignature(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := testutil.CreateTestRepo(t, db)
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "test", "No issues found.")
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+
+	if _, err := verifyReviewSignature(t.Context(), db, review.ID); !errors.Is(err, errReviewUnsigned) {
+		t.Fatalf("expected unsigned error, got %v", err)
+	}
+
+	key, err := signing.LoadOrCreateKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+	rec := signing.ReviewRecord{
+		ReviewID:   review.ID,
+		ReviewUUID: review.UUID,
+		JobID:      review.JobID,
+		GitRef:     review.Job.GitRef,
+		Agent:      review.Agent,
+		Prompt:     review.Prompt,
+		Output:     review.Output,
+	}
+	if err := db.SetReviewSignature(review.ID, signing.Sign(key, rec.Payload()), signing.EncodePublicKey(key)); err != nil {
+		t.Fatalf("SetReviewSignature: %v", err)
+	}
+
+	publicKey, err := verifyReviewSignature(t.Context(), db, review.ID)
+	if err != nil {
+		t.Fatalf("expected valid signature: %v", err)
+	}
+	if publicKey != signing.EncodePublicKey(key) {
+		t.Errorf("unexpected signing key %s", publicKey)
+	}
+
+	if _, err := db.Exec(`UPDATE reviews SET output = 'No issues found!' WHERE id = ?`, review.ID); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if _, err := verifyReviewSignature(t.Context(), db, review.ID); err == nil {
+		t.Error("expected altered review to fail verification")
+	}
+}
+
+func TestVerifyCmdTrust(t *testing.T) {
+	t.Setenv("ROBOREV_DATA_DIR", t.TempDir())
+	db, err := storage.Open(storage.DefaultDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := testutil.CreateTestRepo(t, db)
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "test", "No issues found.")
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	sign := func(keyPath string) string {
+		t.Helper()
+		key, err := signing.LoadOrCreateKey(keyPath)
+		if err != nil {
+			t.Fatalf("LoadOrCreateKey: %v", err)
+		}
+		rec := signing.ReviewRecord{
+			ReviewID:   review.ID,
+			ReviewUUID: review.UUID,
+			JobID:      review.JobID,
+			GitRef:     review.Job.GitRef,
+			Agent:      review.Agent,
+			Prompt:     review.Prompt,
+			Output:     review.Output,
+		}
+		if err := db.SetReviewSignature(review.ID, signing.Sign(key, rec.Payload()), signing.EncodePublicKey(key)); err != nil {
+			t.Fatalf("SetReviewSignature: %v", err)
+		}
+		return signing.Fingerprint(signing.EncodePublicKey(key))
+	}
+	verify := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := verifyCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{strconv.FormatInt(review.ID, 10)}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// A valid signature by a key nobody trusts proves nothing
+	fingerprint := sign(filepath.Join(t.TempDir(), "key"))
+	out, err := verify()
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != 1 || !strings.Contains(out, "untrusted key "+fingerprint) {
+		t.Errorf("verify with untrusted key = %q, %v; want exit 1", out, err)
+	}
+	if out, err := verify("--key", fingerprint); err != nil || !strings.Contains(out, "a trusted key") {
+		t.Errorf("verify --key = %q, %v; want success", out, err)
+	}
+
+	sign(signing.DefaultKeyPath())
+	if out, err := verify(); err != nil || !strings.Contains(out, "this machine's key") {
+		t.Errorf("verify with this machine's key = %q, %v; want success", out, err)
+	}
+}
@@ -42,13 +42,13 @@ func TestE2EEnqueueAndReview(t *testing.T) {
 
 	// Add handlers manually (simulating the server)
 	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
-		queued, running, done, failed, canceled, _ := db.GetJobCounts()
+		counts, _ := db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
 		status := storage.DaemonStatus{
-			QueuedJobs:    queued,
-			RunningJobs:   running,
-			CompletedJobs: done,
-			FailedJobs:    failed,
-			CanceledJobs:  canceled,
+			QueuedJobs:    counts.Status(storage.JobStatusQueued),
+			RunningJobs:   counts.Status(storage.JobStatusRunning),
+			CompletedJobs: counts.Status(storage.JobStatusDone),
+			FailedJobs:    counts.Status(storage.JobStatusFailed),
+			CanceledJobs:  counts.Status(storage.JobStatusCanceled),
 			MaxWorkers:    4,
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -93,31 +93,29 @@ func TestDatabaseIntegration(t *testing.T) {
 	defer db.Close()
 
 	// Simulate full workflow
-	repo, err := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Test Author", "Test commit message", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Test Author", "Test commit message", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
 
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "codex"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "codex"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
 
 	// Verify initial state
-	queued, running, done, _, _, _ := db.GetJobCounts()
-	if queued != 1 {
+	counts, _ := db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
+	if queued := counts.Status(storage.JobStatusQueued); queued != 1 {
 		t.Errorf("Expected 1 queued job, got %d", queued)
 	}
-	_ = running
-	_ = done
 
 	// Claim the job
-	claimed, err := db.ClaimJob("test-worker")
+	claimed, err := db.ClaimJob(t.Context(), "test-worker")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
@@ -129,27 +127,25 @@ func TestDatabaseIntegration(t *testing.T) {
 	}
 
 	// Verify running state
-	_, running, _, _, _, _ = db.GetJobCounts()
-	if running != 1 {
+	counts, _ = db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
+	if running := counts.Status(storage.JobStatusRunning); running != 1 {
 		t.Errorf("Expected 1 running job, got %d", running)
 	}
 
 	// Complete the job
-	err = db.CompleteJob(job.ID, "codex", "test prompt", "This commit looks good!")
+	err = db.CompleteJob(t.Context(), job.ID, "codex", "test prompt", "This commit looks good!")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Verify completed state
-	queued, running, done, _, _, _ = db.GetJobCounts()
-	if done != 1 {
+	counts, _ = db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
+	if done := counts.Status(storage.JobStatusDone); done != 1 {
 		t.Errorf("Expected 1 completed job, got %d", done)
 	}
-	_ = queued
-	_ = running
 
 	// Fetch the review
-	review, err := db.GetReviewByCommitSHA("abc123")
+	review, err := db.GetReviewByCommitSHA(t.Context(), "abc123")
 	if err != nil {
 		t.Fatalf("GetReviewByCommitSHA failed: %v", err)
 	}
@@ -158,7 +154,7 @@ func TestDatabaseIntegration(t *testing.T) {
 	}
 
 	// Add a comment
-	resp, err := db.AddComment(commit.ID, "human-reviewer", "Agreed, LGTM!")
+	resp, err := db.AddComment(t.Context(), commit.ID, "human-reviewer", "Agreed, LGTM!")
 	if err != nil {
 		t.Fatalf("AddComment failed: %v", err)
 	}
@@ -167,7 +163,7 @@ func TestDatabaseIntegration(t *testing.T) {
 	}
 
 	// Verify comment can be fetched
-	comments, err := db.GetCommentsForCommitSHA("abc123")
+	comments, err := db.GetCommentsForCommitSHA(t.Context(), "abc123")
 	if err != nil {
 		t.Fatalf("GetCommentsForCommitSHA failed: %v", err)
 	}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
 	"sync"
 	"sync/atomic"
 )
@@ -72,6 +71,14 @@ type CommandAgent interface {
 	CommandName() string
 }
 
+// ConfiguredAgent is an agent that needs settings, such as an API
+// endpoint, rather than an installed command
+type ConfiguredAgent interface {
+	Agent
+	// Configured reports whether the agent has the settings it needs
+	Configured() bool
+}
+
 // Registry holds available agents
 var registry = make(map[string]Agent)
 var allowUnsafeAgents atomic.Bool
@@ -146,28 +153,48 @@ func IsAvailable(name string) bool {
 
 	// Check if agent implements CommandAgent interface
 	if ca, ok := a.(CommandAgent); ok {
-		_, err := exec.LookPath(ca.CommandName())
-		return err == nil
+		return CurrentExecutor().Available(ca.CommandName())
 	}
 
-	// Non-command agents (like test) are always available
+	if ca, ok := a.(ConfiguredAgent); ok {
+		return ca.Configured()
+	}
+
+	// Other agents (like test) are always available
 	return true
 }
 
 // GetAvailable returns an available agent, trying the requested one first,
 // then falling back to alternatives. Returns error only if no agents available.
-// Supports aliases like "claude" for "claude-code"
+// Supports aliases like "claude" for "claude-code". When a VCR is set (SetVCR)
+// the agent records or replays its responses.
 func GetAvailable(preferred string) (Agent, error) {
+	a, err := getAvailable(preferred)
+	if err != nil {
+		return nil, err
+	}
+	if v := vcr.Load(); v != nil {
+		return v.Wrap(a), nil
+	}
+	return a, nil
+}
+
+func getAvailable(preferred string) (Agent, error) {
 	// Resolve alias upfront for consistent comparisons
 	preferred = resolveAlias(preferred)
 
-	// Try preferred agent first
+	// Try preferred agent first. Replayed agents need not be installed.
 	if preferred != "" && IsAvailable(preferred) {
 		return Get(preferred)
 	}
+	if v := vcr.Load(); v != nil && v.Mode == VCRReplay {
+		if a, err := Get(preferred); err == nil {
+			return a, nil
+		}
+	}
 
-	// Fallback order: codex, claude-code, gemini, copilot, opencode, cursor, droid
-	fallbacks := []string{"codex", "claude-code", "gemini", "copilot", "opencode", "cursor", "droid"}
+	// Fallback order: codex, claude-code, gemini, copilot, opencode, cursor, droid, openai
+	fallbacks := []string{"codex", "claude-code", "gemini", "copilot", "opencode", "cursor", "droid", "openai"}
 	for _, name := range fallbacks {
 		if name != preferred && IsAvailable(name) {
 			return Get(name)
@@ -183,7 +210,7 @@ func GetAvailable(preferred string) (Agent, error) {
 	}
 
 	if len(available) == 0 {
-		return nil, fmt.Errorf("no agents available (install one of: codex, claude-code, gemini, copilot, opencode, cursor, droid)\nYou may need to run 'roborev daemon restart' from a shell that has access to your agents")
+		return nil, fmt.Errorf("no agents available (install one of: codex, claude-code, gemini, copilot, opencode, cursor, droid, or configure [openai])\nYou may need to run 'roborev daemon restart' from a shell that has access to your agents")
 	}
 
 	return Get(available[0])
@@ -402,3 +402,23 @@ func TestAgentReviewPassesModelFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestIsContextLengthError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("claude failed: exit status 1\nstderr: prompt is too long: 212000 tokens > 200000 maximum"), true},
+		{errors.New("openai: 400 Bad Request: This model's maximum context length is 128000 tokens (context_length_exceeded)"), true},
+		{errors.New("gemini: the input token count (1200000) exceeds the maximum number of tokens allowed"), true},
+		{errors.New("codex: Your input exceeds the context window of this model"), true},
+		{errors.New("openai: 429 Too Many Requests: rate limit reached"), false},
+		{errors.New("start claude: executable file not found"), false},
+	}
+	for _, tt := range tests {
+		if got := IsContextLengthError(tt.err); got != tt.want {
+			t.Errorf("IsContextLengthError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
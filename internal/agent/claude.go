@@ -2,13 +2,11 @@ package agent
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 	"sync"
 )
@@ -104,8 +102,7 @@ func claudeSupportsDangerousFlag(ctx context.Context, command string) (bool, err
 	if cached, ok := claudeDangerousSupport.Load(command); ok {
 		return cached.(bool), nil
 	}
-	cmd := exec.CommandContext(ctx, command, "--help")
-	output, err := cmd.CombinedOutput()
+	output, err := agentOutput(ctx, command, "--help")
 	supported := strings.Contains(string(output), claudeDangerousFlag)
 	if err != nil && !supported {
 		return false, fmt.Errorf("check %s --help: %w: %s", command, err, output)
@@ -131,22 +128,25 @@ func (a *ClaudeAgent) Review(ctx context.Context, repoPath, commitSHA, prompt st
 	// Build args - always uses stdin piping + stream-json for non-interactive execution
 	args := a.buildArgs(agenticMode)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
-
 	// Handle API key: use configured key if set, otherwise filter out env var
 	// to ensure Claude uses subscription auth instead of unexpected API charges
+	var env []string
 	if apiKey := AnthropicAPIKey(); apiKey != "" {
 		// Use explicitly configured API key from roborev config
-		cmd.Env = append(filterEnv(os.Environ(), "ANTHROPIC_API_KEY"), "ANTHROPIC_API_KEY="+apiKey)
+		env = append(filterEnv(os.Environ(), "ANTHROPIC_API_KEY"), "ANTHROPIC_API_KEY="+apiKey)
 	} else {
 		// Clear env var so Claude uses subscription auth
-		cmd.Env = filterEnv(os.Environ(), "ANTHROPIC_API_KEY")
+		env = filterEnv(os.Environ(), "ANTHROPIC_API_KEY")
 	}
 	// Suppress sounds from Claude Code (notification/completion sounds)
-	cmd.Env = append(cmd.Env, "CLAUDE_NO_SOUND=1")
+	env = append(env, "CLAUDE_NO_SOUND=1")
+
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath, Env: env})
+	if err != nil {
+		return "", err
+	}
 
-	var stderr bytes.Buffer
+	var stderr tailBuffer
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", fmt.Errorf("create stdout pipe: %w", err)
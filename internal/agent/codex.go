@@ -2,13 +2,11 @@ package agent
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
 	"sync"
 )
@@ -137,8 +135,7 @@ func codexSupportsDangerousFlag(ctx context.Context, command string) (bool, erro
 	if cached, ok := codexDangerousSupport.Load(command); ok {
 		return cached.(bool), nil
 	}
-	cmd := exec.CommandContext(ctx, command, "--help")
-	output, err := cmd.CombinedOutput()
+	output, err := agentOutput(ctx, command, "--help")
 	supported := strings.Contains(string(output), codexDangerousFlag)
 	if err != nil && !supported {
 		return false, fmt.Errorf("check %s --help: %w: %s", command, err, output)
@@ -151,8 +148,7 @@ func codexSupportsAutoApproveFlag(ctx context.Context, command string) (bool, er
 	if cached, ok := codexAutoApproveSupport.Load(command); ok {
 		return cached.(bool), nil
 	}
-	cmd := exec.CommandContext(ctx, command, "--help")
-	output, err := cmd.CombinedOutput()
+	output, err := agentOutput(ctx, command, "--help")
 	supported := strings.Contains(string(output), codexAutoApproveFlag)
 	if err != nil && !supported {
 		return false, fmt.Errorf("check %s --help: %w: %s", command, err, output)
@@ -193,8 +189,10 @@ func (a *CodexAgent) Review(ctx context.Context, repoPath, commitSHA, prompt str
 	// The prompt is piped via stdin using "-" to avoid command line length limits on Windows
 	args := a.buildArgs(repoPath, agenticMode, autoApprove)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath})
+	if err != nil {
+		return "", err
+	}
 
 	// Pipe prompt via stdin to avoid command line length limits on Windows.
 	// Windows has a ~32KB limit on command line arguments, which large diffs easily exceed.
@@ -203,7 +201,7 @@ func (a *CodexAgent) Review(ctx context.Context, repoPath, commitSHA, prompt str
 	// Create one shared sync writer for thread-safe output
 	sw := newSyncWriter(output)
 
-	var stderr bytes.Buffer
+	var stderr tailBuffer
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", fmt.Errorf("create stdout pipe: %w", err)
@@ -1,11 +1,9 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
 )
 
@@ -85,10 +83,14 @@ func (a *CopilotAgent) Review(ctx context.Context, repoPath, commitSHA, prompt s
 	}
 	args = append(args, "--prompt", prompt)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath})
+	if err != nil {
+		return "", err
+	}
 
-	var stdout, stderr bytes.Buffer
+	var stdout spillBuffer
+	defer stdout.Close()
+	var stderr tailBuffer
 	if sw := newSyncWriter(output); sw != nil {
 		cmd.Stdout = io.MultiWriter(&stdout, sw)
 		cmd.Stderr = io.MultiWriter(&stderr, sw)
@@ -1,12 +1,9 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -111,11 +108,12 @@ func (a *CursorAgent) Review(ctx context.Context, repoPath, commitSHA, prompt st
 
 	args := a.buildArgs(agenticMode, prompt)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
-	cmd.Env = os.Environ()
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath})
+	if err != nil {
+		return "", err
+	}
 
-	var stderr bytes.Buffer
+	var stderr tailBuffer
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", fmt.Errorf("create stdout pipe: %w", err)
@@ -1,11 +1,9 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
 )
 
@@ -105,10 +103,14 @@ func (a *DroidAgent) Review(ctx context.Context, repoPath, commitSHA, prompt str
 
 	args := a.buildArgs(prompt, agenticMode)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath})
+	if err != nil {
+		return "", err
+	}
 
-	var stdout, stderr bytes.Buffer
+	var stdout spillBuffer
+	defer stdout.Close()
+	var stderr tailBuffer
 	cmd.Stdout = &stdout
 	if sw := newSyncWriter(output); sw != nil {
 		cmd.Stderr = io.MultiWriter(&stderr, sw)
@@ -0,0 +1,29 @@
+package agent
+
+import "strings"
+
+// contextLengthMarkers are phrases agent CLIs and model APIs use when a
+// prompt does not fit the model's context window
+var contextLengthMarkers = []string{
+	"context_length_exceeded",
+	"context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"exceeds the maximum number of tokens",
+}
+
+// IsContextLengthError reports whether an agent failed because its prompt
+// was too long for the model, so a smaller prompt may succeed
+func IsContextLengthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range contextLengthMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,347 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Executor types
+const (
+	ExecutorLocal      = "local"
+	ExecutorDocker     = "docker"
+	ExecutorKubernetes = "kubernetes"
+)
+
+// CommandSpec describes an agent command to run
+type CommandSpec struct {
+	Name string   // Executable, as configured for the agent
+	Args []string // Arguments
+	Dir  string   // Working directory: the repo under review (empty: none)
+	Env  []string // Environment (nil: the daemon's own)
+}
+
+// Executor runs agent commands. The command it returns is started, fed and
+// waited on like a local process, whatever backend actually runs the agent.
+type Executor interface {
+	// Name returns the executor type
+	Name() string
+
+	// Available reports whether commands named command can be run
+	Available(command string) bool
+
+	// Command returns the command running spec. It is canceled with ctx.
+	Command(ctx context.Context, spec CommandSpec) (*exec.Cmd, error)
+}
+
+var executor atomic.Value // holds executorBox
+
+// executorBox lets executors of different types share an atomic.Value
+type executorBox struct{ Executor }
+
+// SetExecutor sets where agent commands run. nil restores the local
+// executor.
+func SetExecutor(e Executor) {
+	if e == nil {
+		e = LocalExecutor{}
+	}
+	executor.Store(executorBox{e})
+}
+
+// CurrentExecutor returns where agent commands run
+func CurrentExecutor() Executor {
+	if v := executor.Load(); v != nil {
+		return v.(executorBox).Executor
+	}
+	return LocalExecutor{}
+}
+
+// agentCommand returns the command running an agent through the current
+// executor
+func agentCommand(ctx context.Context, spec CommandSpec) (*exec.Cmd, error) {
+	cmd, err := CurrentExecutor().Command(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s executor: %w", CurrentExecutor().Name(), err)
+	}
+	return cmd, nil
+}
+
+// agentOutput runs an agent command without a repo, such as --help, and
+// returns its combined output
+func agentOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd, err := agentCommand(ctx, CommandSpec{Name: name, Args: args})
+	if err != nil {
+		return nil, err
+	}
+	return cmd.CombinedOutput()
+}
+
+// LocalExecutor runs agents as processes on this machine
+type LocalExecutor struct{}
+
+func (LocalExecutor) Name() string { return ExecutorLocal }
+
+func (LocalExecutor) Available(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+func (LocalExecutor) Command(ctx context.Context, spec CommandSpec) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, spec.Name, spec.Args...)
+	cmd.Dir = spec.Dir
+	cmd.Env = spec.Env
+	return cmd, nil
+}
+
+// DockerExecutor runs each agent command in a fresh container of a pinned
+// image, with the repo under review mounted at its path on this machine
+type DockerExecutor struct {
+	Image   string   // Image holding the agent CLIs
+	Env     []string // Names of the environment variables passed into the container
+	Network string   // Network the container joins (empty: docker's default)
+	Docker  string   // docker CLI (default "docker")
+}
+
+func (e *DockerExecutor) Name() string { return ExecutorDocker }
+
+func (e *DockerExecutor) docker() string {
+	if e.Docker != "" {
+		return e.Docker
+	}
+	return "docker"
+}
+
+// Available reports whether the docker CLI is installed. The agent itself
+// only has to be in the image.
+func (e *DockerExecutor) Available(command string) bool {
+	_, err := exec.LookPath(e.docker())
+	return err == nil
+}
+
+// runArgs returns the arguments of the docker run command for spec
+func (e *DockerExecutor) runArgs(name string, spec CommandSpec) []string {
+	args := []string{"run", "--rm", "-i", "--init", "--name", name}
+	if spec.Dir != "" {
+		args = append(args, "-v", spec.Dir+":"+spec.Dir, "-w", spec.Dir)
+	}
+	// Run as the daemon's user so files the agent writes are not owned by root
+	if runtime.GOOS != "windows" {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+	if e.Network != "" {
+		args = append(args, "--network", e.Network)
+	}
+	// Passing names only makes docker read the values from its own
+	// environment, keeping secrets off the command line
+	for _, env := range e.Env {
+		args = append(args, "-e", env)
+	}
+	args = append(args, e.Image, spec.Name)
+	return append(args, spec.Args...)
+}
+
+func (e *DockerExecutor) Command(ctx context.Context, spec CommandSpec) (*exec.Cmd, error) {
+	if e.Image == "" {
+		return nil, fmt.Errorf("no image configured")
+	}
+	name := "roborev-" + randomSuffix()
+	cmd := exec.CommandContext(ctx, e.docker(), e.runArgs(name, spec)...)
+	cmd.Env = spec.Env
+	// Killing the docker CLI leaves the container running
+	cmd.Cancel = func() error {
+		_ = exec.Command(e.docker(), "kill", name).Run()
+		return cmd.Process.Kill()
+	}
+	return cmd, nil
+}
+
+// KubernetesExecutor runs each agent command as a Kubernetes Job. The
+// prompt is streamed to the Job's pod with kubectl attach, and its output
+// streamed back. The repo under review must be reachable from the pod at
+// its path on this machine: on a volume claim mounted at the same path on
+// the daemon's host and in the pod, or else on the node itself.
+type KubernetesExecutor struct {
+	Image          string   // Image holding the agent CLIs
+	Env            []string // Names of the environment variables passed into the pod
+	Namespace      string   // Namespace of the Jobs (default "default")
+	VolumeClaim    string   // Claim holding the repos (empty: a hostPath volume of the repo)
+	MountPath      string   // Where the claim is mounted
+	ServiceAccount string   // Service account of the pods
+	Secret         string   // Secret whose keys become environment variables of the pods
+	StartTimeout   string   // How long to wait for a pod to start (default "10m")
+	Kubectl        string   // kubectl CLI (default "kubectl")
+}
+
+func (e *KubernetesExecutor) Name() string { return ExecutorKubernetes }
+
+func (e *KubernetesExecutor) kubectl() string {
+	if e.Kubectl != "" {
+		return e.Kubectl
+	}
+	return "kubectl"
+}
+
+func (e *KubernetesExecutor) namespace() string {
+	if e.Namespace != "" {
+		return e.Namespace
+	}
+	return "default"
+}
+
+// Available reports whether kubectl is installed. The agent itself only
+// has to be in the image.
+func (e *KubernetesExecutor) Available(command string) bool {
+	if _, err := exec.LookPath("sh"); err != nil {
+		return false
+	}
+	_, err := exec.LookPath(e.kubectl())
+	return err == nil
+}
+
+// kubernetesStdinWrapper holds the agent's stdin until kubectl attach has
+// delivered all of it, so no output is written before the attach
+const kubernetesStdinWrapper = `cat > /tmp/roborev-stdin && exec "$0" "$@" < /tmp/roborev-stdin`
+
+// kubernetesRunScript creates the Job, streams stdin and stdout through
+// kubectl attach, and exits with the agent's exit code. The Job is deleted
+// when the script exits.
+const kubernetesRunScript = `set -e
+kubectl="$1"; ns="$2"; job="$3"; timeout="$4"
+printf '%s' "$ROBOREV_JOB_MANIFEST" | "$kubectl" create -n "$ns" -f - >/dev/null
+trap '"$kubectl" delete job -n "$ns" "$job" --ignore-not-found --wait=false >/dev/null 2>&1' EXIT
+for i in $(seq 60); do
+	[ -n "$("$kubectl" get pod -n "$ns" -l job-name="$job" -o name)" ] && break
+	sleep 1
+done
+"$kubectl" wait -n "$ns" --for=condition=Ready pod -l job-name="$job" --timeout="$timeout" >/dev/null
+"$kubectl" attach -n "$ns" -i -q "job/$job"
+for i in $(seq 30); do
+	code=$("$kubectl" get pod -n "$ns" -l job-name="$job" -o jsonpath='{.items[0].status.containerStatuses[0].state.terminated.exitCode}')
+	[ -n "$code" ] && exit "$code"
+	sleep 1
+done
+echo "roborev: job $job did not finish after its output ended" >&2
+exit 1
+`
+
+// jobManifest returns the Job running spec, with the values of the
+// forwarded environment variables taken from env
+func (e *KubernetesExecutor) jobManifest(name string, spec CommandSpec, env []string) map[string]any {
+	container := map[string]any{
+		"name":      "agent",
+		"image":     e.Image,
+		"command":   append([]string{"sh", "-c", kubernetesStdinWrapper, spec.Name}, spec.Args...),
+		"stdin":     true,
+		"stdinOnce": true,
+	}
+	var vars []map[string]string
+	for _, key := range e.Env {
+		if v, ok := lookupEnv(env, key); ok {
+			vars = append(vars, map[string]string{"name": key, "value": v})
+		}
+	}
+	if len(vars) > 0 {
+		container["env"] = vars
+	}
+	if e.Secret != "" {
+		container["envFrom"] = []map[string]any{{"secretRef": map[string]string{"name": e.Secret}}}
+	}
+
+	pod := map[string]any{"restartPolicy": "Never", "containers": []map[string]any{container}}
+	if e.ServiceAccount != "" {
+		pod["serviceAccountName"] = e.ServiceAccount
+	}
+	if spec.Dir != "" {
+		container["workingDir"] = spec.Dir
+		if e.VolumeClaim != "" {
+			pod["volumes"] = []map[string]any{{"name": "repos",
+				"persistentVolumeClaim": map[string]string{"claimName": e.VolumeClaim}}}
+			container["volumeMounts"] = []map[string]string{{"name": "repos", "mountPath": e.MountPath}}
+		} else {
+			pod["volumes"] = []map[string]any{{"name": "repo",
+				"hostPath": map[string]string{"path": spec.Dir, "type": "Directory"}}}
+			container["volumeMounts"] = []map[string]string{{"name": "repo", "mountPath": spec.Dir}}
+		}
+	}
+
+	return map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]any{"name": name, "labels": map[string]string{"app.kubernetes.io/managed-by": "roborev"}},
+		"spec": map[string]any{
+			"backoffLimit":            0,
+			"ttlSecondsAfterFinished": 300,
+			"template":                map[string]any{"spec": pod},
+		},
+	}
+}
+
+func (e *KubernetesExecutor) Command(ctx context.Context, spec CommandSpec) (*exec.Cmd, error) {
+	if e.Image == "" {
+		return nil, fmt.Errorf("no image configured")
+	}
+	if e.VolumeClaim != "" && spec.Dir != "" && !pathWithin(spec.Dir, e.MountPath) {
+		return nil, fmt.Errorf("repo %s is not under the volume claim's mount path %s", spec.Dir, e.MountPath)
+	}
+	env := spec.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	name := "roborev-" + randomSuffix()
+	manifest, err := json.Marshal(e.jobManifest(name, spec, env))
+	if err != nil {
+		return nil, err
+	}
+	timeout := e.StartTimeout
+	if timeout == "" {
+		timeout = "10m"
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", kubernetesRunScript, "roborev-job",
+		e.kubectl(), e.namespace(), name, timeout)
+	// The manifest holds the forwarded variables' values, so it goes
+	// through the environment rather than the command line
+	cmd.Env = append(slices.Clip(env), "ROBOREV_JOB_MANIFEST="+string(manifest))
+	// Killing the script skips its cleanup
+	cmd.Cancel = func() error {
+		_ = exec.Command(e.kubectl(), "delete", "job", "-n", e.namespace(), name,
+			"--ignore-not-found", "--wait=false").Run()
+		return cmd.Process.Kill()
+	}
+	return cmd, nil
+}
+
+// lookupEnv returns the value of key in env, the last one winning as with
+// exec.Cmd
+func lookupEnv(env []string, key string) (string, bool) {
+	var value string
+	found := false
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			value, found = v, true
+		}
+	}
+	return value, found
+}
+
+// pathWithin reports whether path is dir or inside it
+func pathWithin(path, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// randomSuffix returns a short random name suffix for containers and Jobs
+func randomSuffix() string {
+	b := make([]byte, 5)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useExecutor runs agent commands through e for the rest of the test
+func useExecutor(t *testing.T, e Executor) {
+	t.Helper()
+	SetExecutor(e)
+	t.Cleanup(func() { SetExecutor(nil) })
+}
+
+func TestDockerExecutorRunArgs(t *testing.T) {
+	e := &DockerExecutor{Image: "ghcr.io/acme/agents:1.2", Env: []string{"ANTHROPIC_API_KEY"}, Network: "none"}
+	args := e.runArgs("roborev-abc", CommandSpec{Name: "claude", Args: []string{"-p", "--verbose"}, Dir: "/src/repo"})
+
+	for _, pair := range [][2]string{
+		{"--name", "roborev-abc"},
+		{"-v", "/src/repo:/src/repo"},
+		{"-w", "/src/repo"},
+		{"--network", "none"},
+		{"-e", "ANTHROPIC_API_KEY"},
+	} {
+		if !containsSequence(args, pair[0], pair[1]) {
+			t.Errorf("args should contain %s %s: %v", pair[0], pair[1], args)
+		}
+	}
+	i := slices.Index(args, "ghcr.io/acme/agents:1.2")
+	if i < 0 || !slices.Equal(args[i+1:], []string{"claude", "-p", "--verbose"}) {
+		t.Errorf("image should be followed by the agent command, got %v", args)
+	}
+}
+
+func TestDockerExecutorRunsAgent(t *testing.T) {
+	// The fake docker prints its arguments and echoes stdin
+	docker := writeTempCommand(t, "#!/bin/sh\necho \"$@\"\ncat\n")
+	useExecutor(t, &DockerExecutor{Image: "agents:1", Docker: docker})
+
+	if !IsAvailable("claude-code") {
+		t.Error("agents should be available when docker is, whether or not they are installed locally")
+	}
+
+	repo := t.TempDir()
+	cmd, err := agentCommand(context.Background(), CommandSpec{Name: "gemini", Args: []string{"-o", "json"}, Dir: repo})
+	if err != nil {
+		t.Fatalf("agentCommand: %v", err)
+	}
+	cmd.Stdin = strings.NewReader("the prompt")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(string(out), "agents:1 gemini -o json") || !strings.Contains(string(out), "-w "+repo) {
+		t.Errorf("unexpected docker invocation: %s", out)
+	}
+	if !strings.HasSuffix(string(out), "the prompt") {
+		t.Errorf("stdin should reach the container, got %q", out)
+	}
+}
+
+func TestKubernetesJobManifest(t *testing.T) {
+	e := &KubernetesExecutor{Image: "agents:1", Env: []string{"OPENAI_API_KEY", "UNSET_KEY"}, Secret: "agent-keys"}
+	spec := CommandSpec{Name: "codex", Args: []string{"exec", "-"}, Dir: "/src/repo"}
+	env := []string{"OPENAI_API_KEY=old", "HOME=/root", "OPENAI_API_KEY=sk-1"}
+
+	manifest, err := json.Marshal(e.jobManifest("roborev-abc", spec, env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job struct {
+		Metadata struct{ Name string }
+		Spec     struct {
+			BackoffLimit int `json:"backoffLimit"`
+			Template     struct {
+				Spec struct {
+					Containers []struct {
+						Image      string
+						Command    []string
+						WorkingDir string `json:"workingDir"`
+						Stdin      bool
+						Env        []struct{ Name, Value string }
+						EnvFrom    []struct {
+							SecretRef struct{ Name string } `json:"secretRef"`
+						} `json:"envFrom"`
+					}
+					Volumes []struct {
+						HostPath *struct{ Path string } `json:"hostPath"`
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(manifest, &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Metadata.Name != "roborev-abc" || job.Spec.BackoffLimit != 0 {
+		t.Errorf("unexpected job: %s", manifest)
+	}
+	pod := job.Spec.Template.Spec
+	c := pod.Containers[0]
+	if c.Image != "agents:1" || c.WorkingDir != "/src/repo" || !c.Stdin {
+		t.Errorf("unexpected container: %s", manifest)
+	}
+	if !slices.Equal(c.Command[3:], []string{"codex", "exec", "-"}) {
+		t.Errorf("container should run the agent through the stdin wrapper, got %v", c.Command)
+	}
+	if len(c.Env) != 1 || c.Env[0].Name != "OPENAI_API_KEY" || c.Env[0].Value != "sk-1" {
+		t.Errorf("only set variables should be forwarded, with their last value: %+v", c.Env)
+	}
+	if len(c.EnvFrom) != 1 || c.EnvFrom[0].SecretRef.Name != "agent-keys" {
+		t.Errorf("secret should be mounted as environment: %+v", c.EnvFrom)
+	}
+	if len(pod.Volumes) != 1 || pod.Volumes[0].HostPath == nil || pod.Volumes[0].HostPath.Path != "/src/repo" {
+		t.Errorf("repo should be mounted from the node without a claim: %s", manifest)
+	}
+}
+
+func TestKubernetesExecutorVolumeClaim(t *testing.T) {
+	e := &KubernetesExecutor{Image: "agents:1", VolumeClaim: "repos", MountPath: "/srv/repos"}
+	if _, err := e.Command(context.Background(), CommandSpec{Name: "codex", Dir: "/home/me/repo"}); err == nil {
+		t.Error("repos outside the mount path should be refused")
+	}
+
+	manifest, _ := json.Marshal(e.jobManifest("roborev-abc", CommandSpec{Name: "codex", Dir: "/srv/repos/app"}, nil))
+	for _, want := range []string{`"claimName":"repos"`, `"mountPath":"/srv/repos"`, `"workingDir":"/srv/repos/app"`} {
+		if !strings.Contains(string(manifest), want) {
+			t.Errorf("manifest should contain %s: %s", want, manifest)
+		}
+	}
+}
+
+func TestKubernetesExecutorRunsJob(t *testing.T) {
+	dir := t.TempDir()
+	// The fake kubectl saves the manifest, echoes stdin on attach and
+	// reports the exit code in FAKE_EXIT_CODE
+	kubectl := writeTempCommand(t, `#!/bin/sh
+case "$1" in
+create) cat > "`+dir+`/manifest.json" ;;
+get)
+	case "$*" in
+	*exitCode*) echo "$FAKE_EXIT_CODE" ;;
+	*) echo pod/roborev-abc ;;
+	esac ;;
+attach) echo "attached $6"; cat ;;
+delete) touch "`+dir+`/deleted" ;;
+esac
+`)
+	useExecutor(t, &KubernetesExecutor{Image: "agents:1", Namespace: "review", Kubectl: kubectl})
+
+	run := func(exitCode string) (string, error) {
+		cmd, err := agentCommand(context.Background(), CommandSpec{
+			Name: "claude", Args: []string{"-p"}, Dir: dir,
+			Env: append(os.Environ(), "FAKE_EXIT_CODE="+exitCode),
+		})
+		if err != nil {
+			t.Fatalf("agentCommand: %v", err)
+		}
+		cmd.Stdin = strings.NewReader("the prompt")
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+		return stdout.String(), err
+	}
+
+	out, err := run("0")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.HasPrefix(out, "attached job/roborev-") || !strings.HasSuffix(out, "the prompt") {
+		t.Errorf("output should come from the attached job, got %q", out)
+	}
+	manifest, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil || !strings.Contains(string(manifest), `"kind":"Job"`) {
+		t.Errorf("job manifest should be created, got %s (%v)", manifest, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "deleted")); err != nil {
+		t.Error("job should be deleted when the command finishes")
+	}
+
+	_, err = run("3")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("the agent's exit code should be returned, got %v", err)
+	}
+}
+
+func TestExecutorRequiresImage(t *testing.T) {
+	useExecutor(t, &DockerExecutor{})
+	_, err := NewClaudeAgent("claude").Review(context.Background(), t.TempDir(), "HEAD", "prompt", nil)
+	if err == nil || !strings.Contains(err.Error(), "docker executor: no image configured") {
+		t.Errorf("expected missing image error, got %v", err)
+	}
+}
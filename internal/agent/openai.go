@@ -0,0 +1,292 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultOpenAIBaseURL is the API root used when none is configured
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	// DefaultOpenAIModel is the model used when none is configured
+	DefaultOpenAIModel = "gpt-4.1"
+)
+
+// OpenAIConfig holds the settings of the openai agent. Any server that
+// speaks the chat-completions API works, including local ones.
+type OpenAIConfig struct {
+	BaseURL string // API root (default DefaultOpenAIBaseURL, or OPENAI_BASE_URL)
+	APIKey  string // Bearer token (default OPENAI_API_KEY); local servers may need none
+	Model   string // Default model (DefaultOpenAIModel when empty)
+}
+
+var openAIConfig atomic.Pointer[OpenAIConfig]
+
+// SetOpenAIConfig sets the settings of the openai agent
+func SetOpenAIConfig(cfg OpenAIConfig) {
+	openAIConfig.Store(&cfg)
+}
+
+// currentOpenAIConfig returns the configured settings with environment
+// fallbacks applied. The model is left empty when unset.
+func currentOpenAIConfig() OpenAIConfig {
+	var cfg OpenAIConfig
+	if c := openAIConfig.Load(); c != nil {
+		cfg = *c
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	return cfg
+}
+
+// openAIMaxRetries is how many times a request is retried after a rate
+// limit, server error or connection failure
+const openAIMaxRetries = 3
+
+// openAIRetryBase is the first retry delay, doubled on each retry
+var openAIRetryBase = time.Second
+
+// OpenAIAgent runs code reviews by calling a chat-completions API directly,
+// so no agent CLI needs to be installed. It only sees the prompt: it cannot
+// read the repo or edit files.
+type OpenAIAgent struct {
+	Model     string         // Overrides the configured model
+	Reasoning ReasoningLevel // Recorded only: servers disagree on reasoning parameters
+	Agentic   bool           // Agentic jobs are refused
+	Client    *http.Client   // HTTP client (nil: no timeout beyond the job's)
+}
+
+// NewOpenAIAgent creates a new openai agent
+func NewOpenAIAgent() *OpenAIAgent {
+	return &OpenAIAgent{Reasoning: ReasoningStandard}
+}
+
+// WithReasoning returns a copy of the agent with the specified reasoning level
+func (a *OpenAIAgent) WithReasoning(level ReasoningLevel) Agent {
+	return &OpenAIAgent{Model: a.Model, Reasoning: level, Agentic: a.Agentic, Client: a.Client}
+}
+
+// WithAgentic returns a copy of the agent configured for agentic mode.
+func (a *OpenAIAgent) WithAgentic(agentic bool) Agent {
+	return &OpenAIAgent{Model: a.Model, Reasoning: a.Reasoning, Agentic: agentic, Client: a.Client}
+}
+
+// WithModel returns a copy of the agent configured to use the specified model.
+func (a *OpenAIAgent) WithModel(model string) Agent {
+	if model == "" {
+		return a
+	}
+	return &OpenAIAgent{Model: model, Reasoning: a.Reasoning, Agentic: a.Agentic, Client: a.Client}
+}
+
+func (a *OpenAIAgent) Name() string {
+	return "openai"
+}
+
+// Configured reports whether an API key or a base URL is set. Without a
+// base URL the agent would call api.openai.com, which needs a key.
+func (a *OpenAIAgent) Configured() bool {
+	cfg := currentOpenAIConfig()
+	return cfg.APIKey != "" || cfg.BaseURL != ""
+}
+
+func (a *OpenAIAgent) CommandLine() string {
+	cfg := currentOpenAIConfig()
+	return "POST " + a.endpoint(cfg) + " model=" + a.model(cfg)
+}
+
+func (a *OpenAIAgent) endpoint(cfg OpenAIConfig) string {
+	base := cfg.BaseURL
+	if base == "" {
+		base = DefaultOpenAIBaseURL
+	}
+	return strings.TrimSuffix(base, "/") + "/chat/completions"
+}
+
+func (a *OpenAIAgent) model(cfg OpenAIConfig) string {
+	switch {
+	case a.Model != "":
+		return a.Model
+	case cfg.Model != "":
+		return cfg.Model
+	default:
+		return DefaultOpenAIModel
+	}
+}
+
+// openAIChunk is one server-sent event of a streamed chat completion
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (a *OpenAIAgent) Review(ctx context.Context, repoPath, commitSHA, prompt string, output io.Writer) (string, error) {
+	if a.Agentic {
+		return "", errors.New("the openai agent cannot edit files or run commands; use a CLI agent for agentic jobs")
+	}
+
+	cfg := currentOpenAIConfig()
+	body, err := json.Marshal(map[string]any{
+		"model":          a.model(cfg),
+		"messages":       []map[string]string{{"role": "user", "content": prompt}},
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.post(ctx, a.endpoint(cfg), cfg.APIKey, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Long reviews spill to disk, as the CLI agents' stdout does
+	var result spillBuffer
+	defer result.Close()
+	sw := newSyncWriter(output)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("openai: parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("openai: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			recordUsage(ctx, Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+			})
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			if _, err := result.Write([]byte(c.Delta.Content)); err != nil {
+				return "", fmt.Errorf("openai: collect output: %w", err)
+			}
+			if sw != nil {
+				sw.Write([]byte(c.Delta.Content))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("openai: read stream: %w", err)
+	}
+
+	review := result.String()
+	if len(review) == 0 {
+		return "No review output generated", nil
+	}
+	return review, nil
+}
+
+// post sends a completion request, retrying connection failures, rate
+// limits and server errors with exponential backoff. Returns the response
+// once the server accepts the request and starts streaming.
+func (a *OpenAIAgent) post(ctx context.Context, url, apiKey string, body []byte) (*http.Response, error) {
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	delay := openAIRetryBase
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := client.Do(req)
+		var lastErr error
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = fmt.Errorf("openai: %w", err)
+		case resp.StatusCode == http.StatusOK:
+			return resp, nil
+		default:
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			lastErr = fmt.Errorf("openai: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+			if !retryableStatus(resp.StatusCode) {
+				return nil, lastErr
+			}
+			if after := retryAfter(resp.Header.Get("Retry-After")); after > 0 {
+				delay = after
+			}
+		}
+
+		if attempt == openAIMaxRetries {
+			return nil, lastErr
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter parses a Retry-After header in seconds, capped at a minute.
+// Returns 0 when absent or not a number of seconds.
+func retryAfter(header string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, time.Minute)
+}
+
+func init() {
+	Register(NewOpenAIAgent())
+}
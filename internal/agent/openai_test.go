@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useOpenAIConfig points the openai agent at cfg for the rest of the test
+func useOpenAIConfig(t *testing.T, cfg OpenAIConfig) {
+	t.Helper()
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "")
+	SetOpenAIConfig(cfg)
+	t.Cleanup(func() { SetOpenAIConfig(OpenAIConfig{}) })
+
+	base := openAIRetryBase
+	openAIRetryBase = time.Millisecond
+	t.Cleanup(func() { openAIRetryBase = base })
+}
+
+func writeOpenAIStream(w http.ResponseWriter, chunks ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, c := range chunks {
+		fmt.Fprintf(w, "data: %s\n\n", c)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func TestOpenAIAgentReview(t *testing.T) {
+	var request map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		writeOpenAIStream(w,
+			`{"choices":[{"delta":{"content":"No issues"}}]}`,
+			`{"choices":[{"delta":{"content":" found."}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":120,"completion_tokens":4}}`,
+		)
+	}))
+	defer ts.Close()
+	useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL + "/v1/", APIKey: "sk-test", Model: "local-model"})
+
+	var usage Usage
+	ctx := WithUsageRecorder(context.Background(), func(u Usage) { usage = u })
+	var out bytes.Buffer
+	a := NewOpenAIAgent().WithModel("review-model")
+	result, err := a.Review(ctx, t.TempDir(), "HEAD", "Review this diff", &out)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if result != "No issues found." || out.String() != result {
+		t.Errorf("unexpected result %q, streamed %q", result, out.String())
+	}
+	if usage != (Usage{PromptTokens: 120, CompletionTokens: 4}) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if request["model"] != "review-model" || request["stream"] != true {
+		t.Errorf("unexpected request %v", request)
+	}
+	if got := a.CommandLine(); got != "POST "+ts.URL+"/v1/chat/completions model=review-model" {
+		t.Errorf("unexpected command line %q", got)
+	}
+}
+
+func TestOpenAIAgentReviewSpills(t *testing.T) {
+	content := strings.Repeat("x", 64*1024)
+	n := spillThreshold/len(content) + 2
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]string{"content": content}}}})
+		chunks := make([]string, n)
+		for i := range chunks {
+			chunks[i] = string(chunk)
+		}
+		writeOpenAIStream(w, chunks...)
+	}))
+	defer ts.Close()
+	useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL, APIKey: "sk-test"})
+
+	result, err := NewOpenAIAgent().Review(context.Background(), t.TempDir(), "HEAD", "Review this diff", nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if want := strings.Repeat(content, n); result != want {
+		t.Errorf("Review returned %d bytes, want %d", len(result), len(want))
+	}
+}
+
+func TestOpenAIAgentRetries(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		default:
+			writeOpenAIStream(w, `{"choices":[{"delta":{"content":"ok"}}]}`)
+		}
+	}))
+	defer ts.Close()
+	useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL})
+
+	result, err := NewOpenAIAgent().Review(context.Background(), t.TempDir(), "HEAD", "p", nil)
+	if err != nil || result != "ok" {
+		t.Fatalf("expected success after retries, got %q, %v", result, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestOpenAIAgentErrors(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		calls.Store(0)
+		useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL})
+		_, err := NewOpenAIAgent().Review(context.Background(), t.TempDir(), "HEAD", "p", nil)
+		if err == nil || !strings.Contains(err.Error(), "model not found") || calls.Load() != 1 {
+			t.Errorf("expected one failed call, got %v after %d calls", err, calls.Load())
+		}
+	})
+
+	t.Run("server errors give up after the retries", func(t *testing.T) {
+		calls.Store(0)
+		useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL, APIKey: "sk-test"})
+		_, err := NewOpenAIAgent().Review(context.Background(), t.TempDir(), "HEAD", "p", nil)
+		if err == nil || !strings.Contains(err.Error(), "502") || calls.Load() != openAIMaxRetries+1 {
+			t.Errorf("expected %d failed calls, got %v after %d calls", openAIMaxRetries+1, err, calls.Load())
+		}
+	})
+
+	t.Run("agentic jobs are refused", func(t *testing.T) {
+		calls.Store(0)
+		useOpenAIConfig(t, OpenAIConfig{BaseURL: ts.URL})
+		if _, err := NewOpenAIAgent().WithAgentic(true).Review(context.Background(), t.TempDir(), "HEAD", "p", nil); err == nil || calls.Load() != 0 {
+			t.Errorf("expected an agentic review to fail without calling the API, got %v", err)
+		}
+	})
+}
+
+func TestOpenAIAgentConfigured(t *testing.T) {
+	useOpenAIConfig(t, OpenAIConfig{})
+	if IsAvailable("openai") {
+		t.Error("expected the openai agent to be unavailable without a key or base URL")
+	}
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	if !IsAvailable("openai") {
+		t.Error("expected OPENAI_API_KEY to make the openai agent available")
+	}
+	if got := NewOpenAIAgent().CommandLine(); got != "POST "+DefaultOpenAIBaseURL+"/chat/completions model="+DefaultOpenAIModel {
+		t.Errorf("unexpected default command line %q", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{"": 0, "3": 3 * time.Second, "600": time.Minute, "soon": 0, "-1": 0}
+	for header, want := range tests {
+		if got := retryAfter(header); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
 )
 
@@ -133,10 +131,14 @@ func (a *OpenCodeAgent) Review(ctx context.Context, repoPath, commitSHA, prompt
 	args = append(args, "--model", model)
 	args = append(args, prompt)
 
-	cmd := exec.CommandContext(ctx, a.Command, args...)
-	cmd.Dir = repoPath
+	cmd, err := agentCommand(ctx, CommandSpec{Name: a.Command, Args: args, Dir: repoPath})
+	if err != nil {
+		return "", err
+	}
 
-	var stdout, stderr bytes.Buffer
+	var stdout spillBuffer
+	defer stdout.Close()
+	var stderr tailBuffer
 	if sw := newSyncWriter(output); sw != nil {
 		cmd.Stdout = io.MultiWriter(&stdout, sw)
 		cmd.Stderr = io.MultiWriter(&stderr, sw)
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// spillThreshold is how much of an agent's stdout is buffered in memory
+// before the rest is written to a temp file
+const spillThreshold = 1 << 20
+
+// stderrLimit is how much of an agent's stderr is kept for error messages
+const stderrLimit = 64 * 1024
+
+// spillBuffer collects an agent's stdout in memory up to spillThreshold
+// bytes and in a temp file beyond that, so agents that print megabytes do
+// not hold it all in memory while they run. If the temp file cannot be
+// created the output stays in memory. Close removes the temp file.
+type spillBuffer struct {
+	mem  bytes.Buffer
+	file *os.File
+}
+
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && b.mem.Len()+len(p) > spillThreshold {
+		if f, err := os.CreateTemp("", "roborev-output-*"); err == nil {
+			if _, err := f.Write(b.mem.Bytes()); err != nil {
+				f.Close()
+				os.Remove(f.Name())
+			} else {
+				b.file = f
+				b.mem = bytes.Buffer{}
+			}
+		}
+	}
+	if b.file != nil {
+		return b.file.Write(p)
+	}
+	return b.mem.Write(p)
+}
+
+// String returns everything written, reading it back from the temp file
+// if the output spilled
+func (b *spillBuffer) String() string {
+	if b.file == nil {
+		return b.mem.String()
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+	// Reading to EOF leaves the offset at the end for further writes
+	data, _ := io.ReadAll(b.file)
+	return string(data)
+}
+
+// Close removes the temp file, if any
+func (b *spillBuffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	name := b.file.Name()
+	b.file.Close()
+	b.file = nil
+	return os.Remove(name)
+}
+
+// tailBuffer keeps the last stderrLimit bytes written to it, which is where
+// agents report why they failed
+type tailBuffer struct {
+	buf     []byte
+	dropped bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - stderrLimit; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.dropped = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept bytes, marking where earlier output was dropped
+func (b *tailBuffer) String() string {
+	if b.dropped {
+		return "... (truncated)\n" + string(b.buf)
+	}
+	return string(b.buf)
+}
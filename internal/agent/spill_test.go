@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	t.Run("small output stays in memory", func(t *testing.T) {
+		var b spillBuffer
+		defer b.Close()
+		b.Write([]byte("hello "))
+		b.Write([]byte("world"))
+		if b.file != nil {
+			t.Error("expected no temp file")
+		}
+		if got := b.String(); got != "hello world" {
+			t.Errorf("String() = %q", got)
+		}
+	})
+
+	t.Run("large output spills to disk", func(t *testing.T) {
+		var b spillBuffer
+		chunk := strings.Repeat("x", 64*1024)
+		for i := 0; i < spillThreshold/len(chunk)+2; i++ {
+			b.Write([]byte(chunk))
+		}
+		if b.file == nil {
+			t.Fatal("expected output to spill to a temp file")
+		}
+		if b.mem.Len() != 0 {
+			t.Errorf("expected memory buffer to be released, has %d bytes", b.mem.Len())
+		}
+		name := b.file.Name()
+
+		want := strings.Repeat(chunk, spillThreshold/len(chunk)+2)
+		if got := b.String(); got != want {
+			t.Errorf("String() returned %d bytes, want %d", len(got), len(want))
+		}
+		// Writes after reading back are appended
+		b.Write([]byte("tail"))
+		if got := b.String(); !strings.HasSuffix(got, chunk+"tail") {
+			t.Error("expected later write to be appended")
+		}
+
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("expected temp file to be removed, stat err = %v", err)
+		}
+	})
+}
+
+func TestTailBuffer(t *testing.T) {
+	var b tailBuffer
+	b.Write([]byte("short"))
+	if got := b.String(); got != "short" {
+		t.Errorf("String() = %q", got)
+	}
+
+	b.Write([]byte(strings.Repeat("a", stderrLimit)))
+	b.Write([]byte("the error"))
+	got := b.String()
+	if !strings.HasPrefix(got, "... (truncated)\n") || !strings.HasSuffix(got, "the error") {
+		t.Errorf("unexpected tail %q...", got[:40])
+	}
+	if len(b.buf) != stderrLimit {
+		t.Errorf("kept %d bytes, want %d", len(b.buf), stderrLimit)
+	}
+}
@@ -0,0 +1,25 @@
+package agent
+
+import "context"
+
+// Usage is the token usage an agent reported for one review. Only agents
+// that call model APIs directly report it.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type usageRecorderKey struct{}
+
+// WithUsageRecorder returns a context whose reviews pass the token usage
+// they report to record
+func WithUsageRecorder(ctx context.Context, record func(Usage)) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, record)
+}
+
+// recordUsage passes usage to the context's recorder, if any
+func recordUsage(ctx context.Context, u Usage) {
+	if record, ok := ctx.Value(usageRecorderKey{}).(func(Usage)); ok {
+		record(u)
+	}
+}
@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// VCRMode selects whether agent invocations are recorded or replayed
+type VCRMode string
+
+const (
+	// VCRRecord runs agents normally and saves each response as a fixture
+	VCRRecord VCRMode = "record"
+	// VCRReplay answers from recorded fixtures without running agents
+	VCRReplay VCRMode = "replay"
+)
+
+// VCR records agent responses to fixture files and replays them, so the
+// full review pipeline can run in tests and CI without agents or network.
+// Fixtures are keyed by agent name and a hash of the prompt: a prompt change
+// misses its fixture and fails replay until the fixture is recorded again.
+type VCR struct {
+	Mode VCRMode
+	Dir  string // Fixture directory
+}
+
+// Fixture is a recorded agent response
+type Fixture struct {
+	Agent      string    `json:"agent"`
+	PromptHash string    `json:"prompt_hash"`
+	Prompt     string    `json:"prompt"` // Kept to diff against when re-recording
+	Output     string    `json:"output"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+var vcr atomic.Pointer[VCR]
+
+// SetVCR wraps every agent returned by GetAvailable in v, or stops wrapping
+// if v is nil. In replay mode registered agents need not be installed.
+func SetVCR(v *VCR) {
+	vcr.Store(v)
+}
+
+// VCRFromEnv reads the VCR settings from ROBOREV_VCR ("record" or
+// "replay") and ROBOREV_VCR_DIR. Returns nil if ROBOREV_VCR is unset.
+func VCRFromEnv() (*VCR, error) {
+	mode := VCRMode(os.Getenv("ROBOREV_VCR"))
+	if mode == "" {
+		return nil, nil
+	}
+	if mode != VCRRecord && mode != VCRReplay {
+		return nil, fmt.Errorf("invalid ROBOREV_VCR %q (valid: record, replay)", mode)
+	}
+	dir := os.Getenv("ROBOREV_VCR_DIR")
+	if dir == "" {
+		return nil, fmt.Errorf("ROBOREV_VCR_DIR is required when ROBOREV_VCR is set")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve ROBOREV_VCR_DIR: %w", err)
+	}
+	return &VCR{Mode: mode, Dir: abs}, nil
+}
+
+// PromptHash returns the key of an agent's response to prompt
+func PromptHash(agentName, prompt string) string {
+	sum := sha256.Sum256([]byte(agentName + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// FixturePath returns the file holding an agent's recorded response to prompt
+func (v *VCR) FixturePath(agentName, prompt string) string {
+	return filepath.Join(v.Dir, agentName, PromptHash(agentName, prompt)+".json")
+}
+
+// Wrap returns a, recording or replaying its responses
+func (v *VCR) Wrap(a Agent) Agent {
+	if _, ok := a.(*vcrAgent); ok {
+		return a
+	}
+	return &vcrAgent{Agent: a, vcr: v}
+}
+
+// Load reads the recorded response to prompt
+func (v *VCR) Load(agentName, prompt string) (*Fixture, error) {
+	path := v.FixturePath(agentName, prompt)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded %s response for this prompt (%s); record it with ROBOREV_VCR=record", agentName, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Save records an agent's response to prompt, replacing an earlier one
+func (v *VCR) Save(agentName, prompt, output string) error {
+	path := v.FixturePath(agentName, prompt)
+	data, err := json.MarshalIndent(Fixture{
+		Agent:      agentName,
+		PromptHash: PromptHash(agentName, prompt),
+		Prompt:     prompt,
+		Output:     output,
+		RecordedAt: time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write via a temp file so concurrent replays never read a partial fixture
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// vcrAgent records or replays the responses of the agent it wraps
+type vcrAgent struct {
+	Agent
+	vcr *VCR
+}
+
+func (a *vcrAgent) Review(ctx context.Context, repoPath, commitSHA, prompt string, output io.Writer) (string, error) {
+	if a.vcr.Mode == VCRReplay {
+		f, err := a.vcr.Load(a.Name(), prompt)
+		if err != nil {
+			return "", err
+		}
+		if output != nil {
+			if _, err := io.WriteString(output, f.Output); err != nil {
+				return "", fmt.Errorf("write output: %w", err)
+			}
+		}
+		return f.Output, nil
+	}
+
+	result, err := a.Agent.Review(ctx, repoPath, commitSHA, prompt, output)
+	if err != nil {
+		return result, err
+	}
+	if err := a.vcr.Save(a.Name(), prompt, result); err != nil {
+		return "", fmt.Errorf("record response: %w", err)
+	}
+	return result, nil
+}
+
+func (a *vcrAgent) WithReasoning(level ReasoningLevel) Agent {
+	return a.vcr.Wrap(a.Agent.WithReasoning(level))
+}
+
+func (a *vcrAgent) WithAgentic(agentic bool) Agent {
+	return a.vcr.Wrap(a.Agent.WithAgentic(agentic))
+}
+
+func (a *vcrAgent) WithModel(model string) Agent {
+	return a.vcr.Wrap(a.Agent.WithModel(model))
+}
+
+// Unwrap returns the wrapped agent
+func (a *vcrAgent) Unwrap() Agent {
+	return a.Agent
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withVCR sets the package VCR for the duration of a test
+func withVCR(t *testing.T, v *VCR) {
+	t.Helper()
+	SetVCR(v)
+	t.Cleanup(func() { SetVCR(nil) })
+}
+
+func TestVCRRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	inner := &TestAgent{Output: "recorded review"}
+
+	recorder := (&VCR{Mode: VCRRecord, Dir: dir}).Wrap(inner)
+	got, err := recorder.WithReasoning(ReasoningFast).Review(context.Background(), "/repo", "abc1234", "the prompt", nil)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if !strings.HasPrefix(got, "recorded review") {
+		t.Fatalf("expected the agent's output while recording, got %q", got)
+	}
+
+	// Replay answers from the fixture, even though the agent now fails
+	inner.Fail = true
+	replayer := (&VCR{Mode: VCRReplay, Dir: dir}).Wrap(inner)
+	var streamed bytes.Buffer
+	replayed, err := replayer.WithModel("other").Review(context.Background(), "/elsewhere", "def5678", "the prompt", &streamed)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed != got || streamed.String() != got {
+		t.Errorf("replayed %q (streamed %q), want %q", replayed, streamed.String(), got)
+	}
+
+	// A changed prompt misses the fixture
+	if _, err := replayer.Review(context.Background(), "/repo", "abc1234", "the prompt, edited", nil); err == nil ||
+		!strings.Contains(err.Error(), "ROBOREV_VCR=record") {
+		t.Errorf("expected a missing fixture error, got %v", err)
+	}
+}
+
+func TestVCRFromEnv(t *testing.T) {
+	t.Setenv("ROBOREV_VCR", "")
+	if v, err := VCRFromEnv(); v != nil || err != nil {
+		t.Errorf("expected no VCR when unset, got %+v, %v", v, err)
+	}
+	t.Setenv("ROBOREV_VCR", "replay")
+	t.Setenv("ROBOREV_VCR_DIR", "")
+	if _, err := VCRFromEnv(); err == nil {
+		t.Error("expected an error without ROBOREV_VCR_DIR")
+	}
+	t.Setenv("ROBOREV_VCR_DIR", "fixtures")
+	v, err := VCRFromEnv()
+	if err != nil || v.Mode != VCRReplay || !strings.HasSuffix(v.Dir, "fixtures") || !strings.HasPrefix(v.Dir, "/") {
+		t.Errorf("unexpected VCR %+v, %v", v, err)
+	}
+	t.Setenv("ROBOREV_VCR", "rewind")
+	if _, err := VCRFromEnv(); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+func TestGetAvailableReplaysUninstalledAgents(t *testing.T) {
+	skipIfWindows(t)
+	t.Setenv("PATH", t.TempDir())
+	dir := t.TempDir()
+
+	if _, err := GetAvailable("droid"); err == nil {
+		t.Fatal("expected no agents available on an empty PATH")
+	}
+
+	withVCR(t, &VCR{Mode: VCRReplay, Dir: dir})
+	a, err := GetAvailable("droid")
+	if err != nil {
+		t.Fatalf("GetAvailable in replay: %v", err)
+	}
+	if a.Name() != "droid" {
+		t.Errorf("expected droid, got %s", a.Name())
+	}
+	if err := (&VCR{Dir: dir}).Save("droid", "prompt", "replayed"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if out, err := a.Review(ctx, t.TempDir(), "HEAD", "prompt", nil); err != nil || out != "replayed" {
+		t.Errorf("Review = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionCacheTTL bounds how long a detected CLI version is reused, so an
+// upgraded agent CLI is noticed without restarting the daemon.
+const versionCacheTTL = 10 * time.Minute
+
+type cachedVersion struct {
+	version   string
+	fetchedAt time.Time
+}
+
+var (
+	versionMu    sync.Mutex
+	versionCache = make(map[string]cachedVersion)
+)
+
+// CommandVersion returns the first line printed by "<command> --version" for
+// agents that wrap an external CLI. Returns "" for other agents or if the
+// command fails. Results are cached per command.
+func CommandVersion(a Agent) string {
+	if w, ok := a.(interface{ Unwrap() Agent }); ok {
+		a = w.Unwrap()
+	}
+	ca, ok := a.(CommandAgent)
+	if !ok {
+		return ""
+	}
+	command := ca.CommandName()
+
+	versionMu.Lock()
+	cached, ok := versionCache[command]
+	versionMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < versionCacheTTL {
+		return cached.version
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var version string
+	if cmd, err := agentCommand(ctx, CommandSpec{Name: command, Args: []string{"--version"}}); err == nil {
+		if out, err := cmd.Output(); err == nil {
+			version, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+			version = strings.TrimSpace(version)
+		}
+	}
+
+	versionMu.Lock()
+	versionCache[command] = cachedVersion{version: version, fetchedAt: time.Now()}
+	versionMu.Unlock()
+	return version
+}
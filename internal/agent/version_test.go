@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCommandVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the agent command")
+	}
+
+	script := filepath.Join(t.TempDir(), "fake-codex")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"fake-codex 1.2.3\"\necho extra\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := CommandVersion(NewCodexAgent(script)); got != "fake-codex 1.2.3" {
+		t.Errorf("CommandVersion() = %q, want first line of --version output", got)
+	}
+	if got := CommandVersion(NewCodexAgent(filepath.Join(t.TempDir(), "missing"))); got != "" {
+		t.Errorf("CommandVersion() for missing command = %q, want empty", got)
+	}
+	if got := CommandVersion(NewTestAgent()); got != "" {
+		t.Errorf("CommandVersion() for non-command agent = %q, want empty", got)
+	}
+}
@@ -0,0 +1,272 @@
+// Package backup writes, rotates and restores backups of the review
+// database. Backups are SQLite copies made with the online backup API,
+// optionally gzip-compressed and encrypted with AES-256-GCM. The file
+// extension records the encoding: .db, .db.gz, .db.enc or .db.gz.enc.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// filePrefix starts the name of every backup file
+const filePrefix = "roborev-"
+
+// encMagic starts every encrypted backup
+var encMagic = []byte("roborev-backup-v1\n")
+
+// ErrKeyRequired is returned when restoring an encrypted backup without a key
+var ErrKeyRequired = errors.New("backup is encrypted; a key file is required")
+
+// Options controls how a backup is written
+type Options struct {
+	Dir      string
+	Keep     int    // Backups to retain after writing; 0 keeps all
+	Compress bool   // gzip the backup
+	Key      []byte // AES-256 key; nil leaves the backup unencrypted
+}
+
+// OptionsFromConfig returns the backup options of the global config,
+// loading the encryption key if one is configured
+func OptionsFromConfig(cfg config.BackupConfig) (Options, error) {
+	opts := Options{Dir: cfg.BackupDir(), Keep: cfg.KeepCount(), Compress: cfg.Compress}
+	if cfg.KeyFile != "" {
+		key, err := LoadKey(cfg.KeyFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Key = key
+	}
+	return opts, nil
+}
+
+// LoadKey derives an AES-256 key from the contents of a key file. Leading
+// and trailing whitespace is ignored so the file can hold a passphrase.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read backup key: %w", err)
+	}
+	secret := bytes.TrimSpace(data)
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("backup key file %s is empty", path)
+	}
+	sum := sha256.Sum256(secret)
+	return sum[:], nil
+}
+
+// Create backs up db into opts.Dir, then deletes the oldest backups beyond
+// opts.Keep. Returns the path of the new backup.
+func Create(ctx context.Context, db *storage.DB, opts Options) (string, error) {
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+
+	name := filePrefix + time.Now().UTC().Format("20060102-150405") + ".db"
+	raw := filepath.Join(opts.Dir, "."+name+".tmp")
+	defer os.Remove(raw)
+	if err := db.BackupTo(ctx, raw); err != nil {
+		return "", fmt.Errorf("back up database: %w", err)
+	}
+
+	if opts.Compress {
+		name += ".gz"
+	}
+	if opts.Key != nil {
+		name += ".enc"
+	}
+	dest := filepath.Join(opts.Dir, name)
+	if opts.Compress || opts.Key != nil {
+		if err := encodeFile(raw, dest, opts.Compress, opts.Key); err != nil {
+			os.Remove(dest)
+			return "", err
+		}
+	} else if err := os.Rename(raw, dest); err != nil {
+		return "", fmt.Errorf("save backup: %w", err)
+	}
+
+	if opts.Keep > 0 {
+		if err := Prune(opts.Dir, opts.Keep); err != nil {
+			return dest, fmt.Errorf("prune backups: %w", err)
+		}
+	}
+	return dest, nil
+}
+
+// Snapshot writes a plain copy of the database to path, for a one-off
+// snapshot outside the backup directory. A file already at path is only
+// replaced once the copy is complete.
+func Snapshot(ctx context.Context, db *storage.DB, path string) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	defer os.Remove(tmp)
+	if err := db.BackupTo(ctx, tmp); err != nil {
+		return fmt.Errorf("back up database: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("save backup: %w", err)
+	}
+	return nil
+}
+
+// List returns the backups in dir, newest first
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.Contains(name, ".db") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	// Names embed the UTC timestamp, so they sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+// Latest returns the modification time of the newest backup in dir, or
+// the zero time if there is none
+func Latest(dir string) time.Time {
+	files, err := List(dir)
+	if err != nil || len(files) == 0 {
+		return time.Time{}
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Prune deletes all but the newest keep backups in dir
+func Prune(dir string, keep int) error {
+	files, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(files); i++ {
+		if err := os.Remove(files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore replaces the database at dbPath with the backup in file,
+// decrypting it with key and decompressing it as its extension says.
+// Nothing else may have the database open.
+func Restore(dbPath, file string, key []byte) error {
+	encrypted := strings.HasSuffix(file, ".enc")
+	compressed := strings.HasSuffix(strings.TrimSuffix(file, ".enc"), ".gz")
+	if encrypted && key == nil {
+		return ErrKeyRequired
+	}
+
+	src := file
+	if encrypted || compressed {
+		tmp, err := os.CreateTemp(filepath.Dir(dbPath), ".restore-*.db")
+		if err != nil {
+			return err
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+		if err := decodeFile(file, tmp.Name(), compressed, key); err != nil {
+			return err
+		}
+		src = tmp.Name()
+	}
+	return storage.RestoreFrom(dbPath, src)
+}
+
+// encodeFile writes src to dest, gzipped and/or encrypted
+func encodeFile(src, dest string, compress bool, key []byte) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	if key != nil {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		sealed := append(append([]byte{}, encMagic...), nonce...)
+		data = gcm.Seal(sealed, nonce, data, encMagic)
+	}
+	return os.WriteFile(dest, data, 0600)
+}
+
+// decodeFile reverses encodeFile
+func decodeFile(src, dest string, compressed bool, key []byte) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return err
+		}
+		rest, ok := bytes.CutPrefix(data, encMagic)
+		if !ok || len(rest) < gcm.NonceSize() {
+			return fmt.Errorf("%s is not an encrypted roborev backup", src)
+		}
+		nonce, sealed := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+		if data, err = gcm.Open(nil, nonce, sealed, encMagic); err != nil {
+			return fmt.Errorf("decrypt backup (wrong key?): %w", err)
+		}
+	}
+	if compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("decompress backup: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return fmt.Errorf("decompress backup: %w", err)
+		}
+	}
+	return os.WriteFile(dest, data, 0600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
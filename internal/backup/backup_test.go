@@ -0,0 +1,153 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func openTestDB(t *testing.T) (*storage.DB, string) {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "original")); err != nil {
+		t.Fatalf("create repo: %v", err)
+	}
+	return db, dbPath
+}
+
+func repoCount(t *testing.T, dbPath string) int {
+	t.Helper()
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	repos, err := db.ListRepos(t.Context())
+	if err != nil {
+		t.Fatalf("list repos: %v", err)
+	}
+	return len(repos)
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	key := make([]byte, 32)
+	tests := []struct {
+		name     string
+		compress bool
+		key      []byte
+		suffix   string
+	}{
+		{"plain", false, nil, ".db"},
+		{"compressed", true, nil, ".db.gz"},
+		{"encrypted", false, key, ".db.enc"},
+		{"compressed and encrypted", true, key, ".db.gz.enc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbPath := openTestDB(t)
+			opts := Options{Dir: t.TempDir(), Compress: tt.compress, Key: tt.key}
+			file, err := Create(t.Context(), db, opts)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if !strings.HasSuffix(file, tt.suffix) {
+				t.Errorf("expected %s backup, got %s", tt.suffix, file)
+			}
+
+			// Added after the backup, so gone after restoring
+			if _, err := db.GetOrCreateRepo(t.Context(), filepath.Join(t.TempDir(), "later")); err != nil {
+				t.Fatal(err)
+			}
+			db.Close()
+
+			if err := Restore(dbPath, file, tt.key); err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+			if n := repoCount(t, dbPath); n != 1 {
+				t.Errorf("expected 1 repo after restore, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRestoreEncryptedKeyErrors(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	key := make([]byte, 32)
+	file, err := Create(t.Context(), db, Options{Dir: t.TempDir(), Key: key})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	db.Close()
+
+	if err := Restore(dbPath, file, nil); !errors.Is(err, ErrKeyRequired) {
+		t.Errorf("expected ErrKeyRequired, got %v", err)
+	}
+	wrong := make([]byte, 32)
+	wrong[0] = 1
+	if err := Restore(dbPath, file, wrong); err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Errorf("expected decryption error, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"roborev-20260101-000000.db",
+		"roborev-20260102-000000.db.gz",
+		"roborev-20260103-000000.db.gz.enc",
+		"notes.txt",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Prune(dir, 2); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	files, err := List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(files) != 2 || filepath.Base(files[0]) != names[2] || filepath.Base(files[1]) != names[1] {
+		t.Errorf("expected two newest backups, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("unrelated file was removed: %v", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	os.WriteFile(a, []byte("correct horse\n"), 0600)
+	os.WriteFile(b, []byte("  correct horse"), 0600)
+
+	ka, err := LoadKey(a)
+	if err != nil {
+		t.Fatalf("LoadKey: %v", err)
+	}
+	kb, err := LoadKey(b)
+	if err != nil {
+		t.Fatalf("LoadKey: %v", err)
+	}
+	if len(ka) != 32 || string(ka) != string(kb) {
+		t.Error("expected the same 32-byte key regardless of surrounding whitespace")
+	}
+
+	empty := filepath.Join(dir, "empty")
+	os.WriteFile(empty, []byte("\n"), 0600)
+	if _, err := LoadKey(empty); err == nil {
+		t.Error("expected error for empty key file")
+	}
+}
@@ -0,0 +1,170 @@
+// Package blobstore stores large review prompts and outputs outside the
+// database, in a local directory or an S3-compatible object store.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+)
+
+// ErrNotFound is returned by Get when no blob exists for a key
+var ErrNotFound = errors.New("blob not found")
+
+// Store saves and loads blobs by key. Keys are slash-separated paths.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Lister is implemented by stores that can enumerate their keys
+type Lister interface {
+	// List returns the keys starting with prefix, in lexical order
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// New creates the store configured by cfg, or returns nil if offloading is
+// disabled.
+func New(cfg config.BlobStoreConfig) (Store, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "":
+		return nil, nil
+	case "filesystem", "file":
+		dir := cfg.Path
+		if dir == "" {
+			dir = filepath.Join(config.ArtifactsDir(), "blobs")
+		}
+		return NewFileStore(dir), nil
+	case "s3":
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+		return newS3FromConfig(cfg, endpoint, region,
+			os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN"))
+	case "gcs":
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "https://storage.googleapis.com"
+		}
+		region := cfg.Region
+		if region == "" {
+			region = "auto"
+		}
+		return newS3FromConfig(cfg, endpoint, region, "", "", "")
+	default:
+		return nil, fmt.Errorf("unknown blob_store backend %q (valid: filesystem, s3, gcs)", cfg.Backend)
+	}
+}
+
+// newS3FromConfig builds an S3 store, using the given credentials when the
+// config does not set its own.
+func newS3FromConfig(cfg config.BlobStoreConfig, endpoint, region, envKeyID, envSecret, envToken string) (Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("blob_store.bucket is required for the %s backend", cfg.Backend)
+	}
+	creds := Credentials{
+		AccessKeyID:     os.ExpandEnv(cfg.AccessKeyID),
+		SecretAccessKey: os.ExpandEnv(cfg.SecretAccessKey),
+	}
+	if creds.AccessKeyID == "" && creds.SecretAccessKey == "" {
+		creds = Credentials{AccessKeyID: envKeyID, SecretAccessKey: envSecret, SessionToken: envToken}
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("blob_store.access_key_id and secret_access_key are required for the %s backend", cfg.Backend)
+	}
+	return NewS3Store(endpoint, region, cfg.Bucket, cfg.Prefix, creds), nil
+}
+
+// FileStore keeps blobs as files under a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// Put writes the blob atomically, so readers never see a partial file
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads the blob for key
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// List returns the keys of the blobs under the store directory starting
+// with prefix
+func (s *FileStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".blob-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
@@ -0,0 +1,265 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Credentials authenticate requests to an S3-compatible store
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Optional, for temporary AWS credentials
+}
+
+// S3Store keeps blobs in a bucket of an S3-compatible object store (AWS S3,
+// Google Cloud Storage with HMAC keys, MinIO). Requests use path-style URLs
+// and AWS Signature Version 4.
+type S3Store struct {
+	endpoint string
+	region   string
+	bucket   string
+	prefix   string
+	creds    Credentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store creates a store for bucket at endpoint. prefix is prepended to
+// every key.
+func NewS3Store(endpoint, region, bucket, prefix string, creds Credentials) *S3Store {
+	return &S3Store{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		region:   region,
+		bucket:   bucket,
+		prefix:   strings.Trim(prefix, "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: 60 * time.Second},
+		now:      time.Now,
+	}
+}
+
+func (s *S3Store) objectURL(key string) string {
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	return s.endpoint + "/" + s.bucket + "/" + key
+}
+
+// Put uploads the blob for key
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", key, resp)
+	}
+	return nil
+}
+
+// Get downloads the blob for key
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, responseError("get", key, resp)
+	}
+}
+
+// listResult is the part of a ListObjectsV2 response List reads
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the keys starting with prefix, following continuation
+// tokens until the listing is complete
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	fullPrefix := prefix
+	if s.prefix != "" {
+		fullPrefix = s.prefix + "/" + prefix
+	}
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {fullPrefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+"/"+s.bucket+"?"+query.Encode(), http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		signV4(req, nil, s.creds, s.region, s.now())
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := responseError("list", prefix, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode listing of %s: %w", prefix, err)
+		}
+		for _, c := range result.Contents {
+			key := c.Key
+			if s.prefix != "" {
+				key = strings.TrimPrefix(key, s.prefix+"/")
+			}
+			keys = append(keys, key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	signV4(req, body, s.creds, s.region, s.now())
+	return s.client.Do(req)
+}
+
+func responseError(op, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s blob %s: %s: %s", op, key, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. All headers already
+// set on req are signed, along with Host, X-Amz-Date and
+// X-Amz-Content-Sha256.
+func signV4(req *http.Request, body []byte, creds Credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalPath URI-encodes each segment of path as SigV4 requires for S3
+func canonicalPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(values url.Values) string {
+	var pairs []string
+	for name, vals := range values {
+		for _, v := range vals {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything except RFC 3986 unreserved characters
+func uriEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
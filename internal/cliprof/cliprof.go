@@ -0,0 +1,122 @@
+// Package cliprof times the phases of a CLI command: opening the database,
+// checking its migrations, queries, git subprocesses, HTTP requests and
+// waiting on agents. Recording is off until Enable is called, so the
+// daemon and tests pay nothing for the spans in shared code.
+//
+// Spans do not nest: a span started while another is open counts toward
+// the open one, so waiting on an agent covers the polls made meanwhile and
+// the migration check covers its own queries.
+package cliprof
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phases of a command
+const (
+	PhaseDBOpen  = "db open"
+	PhaseMigrate = "migration check"
+	PhaseQueries = "queries"
+	PhaseGit     = "git"
+	PhaseHTTP    = "http requests"
+	PhaseAgent   = "agent wait"
+)
+
+// phaseOrder is the order phases are reported in
+var phaseOrder = []string{PhaseDBOpen, PhaseMigrate, PhaseQueries, PhaseGit, PhaseHTTP, PhaseAgent}
+
+var (
+	enabled atomic.Bool
+	depth   atomic.Int32 // Open spans
+
+	mu     sync.Mutex
+	phases = make(map[string]*Stat)
+)
+
+// Stat is the time spent in a phase
+type Stat struct {
+	Phase string
+	Calls int           // Spans of the phase, including those inside other spans
+	Time  time.Duration // Time of the spans not inside other spans
+}
+
+// Enable starts recording spans
+func Enable() {
+	enabled.Store(true)
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Span starts timing a call of a phase and returns the function that ends
+// it
+func Span(phase string) func() {
+	return span(phase, 1)
+}
+
+// span is Span counting the span as the given number of calls, 0 for the
+// parts of a call timed separately, such as reading the rows of a query
+func span(phase string, calls int) func() {
+	if !enabled.Load() {
+		return func() {}
+	}
+	if depth.Add(1) > 1 {
+		add(phase, calls, 0)
+		return func() { depth.Add(-1) }
+	}
+	start := time.Now()
+	return func() {
+		depth.Add(-1)
+		add(phase, calls, time.Since(start))
+	}
+}
+
+func add(phase string, calls int, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	st := phases[phase]
+	if st == nil {
+		st = &Stat{Phase: phase}
+		phases[phase] = st
+	}
+	st.Calls += calls
+	st.Time += d
+}
+
+// Stats returns the phases recorded so far, in a fixed order
+func Stats() []Stat {
+	mu.Lock()
+	defer mu.Unlock()
+	var stats []Stat
+	for _, phase := range phaseOrder {
+		if st := phases[phase]; st != nil {
+			stats = append(stats, *st)
+		}
+	}
+	return stats
+}
+
+// HotPath returns the time spent in phases other than waiting on agents:
+// the CLI's own cost, which should stay small whatever the agent does
+func HotPath() time.Duration {
+	var total time.Duration
+	for _, st := range Stats() {
+		if st.Phase != PhaseAgent {
+			total += st.Time
+		}
+	}
+	return total
+}
+
+// Reset forgets the recorded phases and stops recording. For tests.
+func Reset() {
+	enabled.Store(false)
+	depth.Store(0)
+	mu.Lock()
+	phases = make(map[string]*Stat)
+	mu.Unlock()
+}
@@ -0,0 +1,93 @@
+package cliprof
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+func TestSpan(t *testing.T) {
+	t.Cleanup(Reset)
+
+	Span(PhaseGit)() // Not recorded before Enable
+	if stats := Stats(); len(stats) != 0 {
+		t.Fatalf("expected no stats while disabled, got %+v", stats)
+	}
+
+	Enable()
+	endAgent := Span(PhaseAgent)
+	time.Sleep(5 * time.Millisecond)
+	Span(PhaseHTTP)() // Inside the agent span, so its time is the agent's
+	endAgent()
+	endGit := Span(PhaseGit)
+	time.Sleep(5 * time.Millisecond)
+	endGit()
+
+	stats := Stats()
+	if len(stats) != 3 || stats[0].Phase != PhaseGit || stats[1].Phase != PhaseHTTP || stats[2].Phase != PhaseAgent {
+		t.Fatalf("expected git, http and agent stats in order, got %+v", stats)
+	}
+	if stats[1].Calls != 1 || stats[1].Time != 0 {
+		t.Errorf("expected a nested http call without time, got %+v", stats[1])
+	}
+	if stats[0].Time < 5*time.Millisecond || stats[2].Time < 5*time.Millisecond {
+		t.Errorf("expected git and agent times of at least 5ms, got %+v", stats)
+	}
+	if hot := HotPath(); hot != stats[0].Time {
+		t.Errorf("HotPath = %s, want the git time %s", hot, stats[0].Time)
+	}
+}
+
+func TestDriver(t *testing.T) {
+	t.Cleanup(Reset)
+	Enable()
+
+	name := fmt.Sprintf("sqlite-profiled-%s", t.Name())
+	sql.Register(name, Driver(&sqlite.Driver{}))
+	db, err := sql.Open(name, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE t (n INTEGER)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t VALUES (?), (?)`, 1, 2); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	var sum int
+	if err := db.QueryRow(`SELECT SUM(n) FROM t`).Scan(&sum); err != nil || sum != 3 {
+		t.Fatalf("sum = %d, %v; want 3", sum, err)
+	}
+
+	stats := Stats()
+	if len(stats) != 1 || stats[0].Phase != PhaseQueries || stats[0].Calls != 3 {
+		t.Errorf("expected 3 query calls, got %+v", stats)
+	}
+}
+
+func TestSlowCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow-commands.jsonl")
+	if cmds, err := ReadSlowCommands(path); err != nil || len(cmds) != 0 {
+		t.Fatalf("expected an empty log before any command, got %v, %v", cmds, err)
+	}
+
+	for i := range maxSlowCommands + 2 {
+		c := SlowCommand{Command: "roborev list", TotalMs: int64(i), Phases: map[string]int64{PhaseQueries: int64(i)}}
+		if err := AppendSlowCommand(path, c); err != nil {
+			t.Fatalf("AppendSlowCommand: %v", err)
+		}
+	}
+	cmds, err := ReadSlowCommands(path)
+	if err != nil {
+		t.Fatalf("ReadSlowCommands: %v", err)
+	}
+	if len(cmds) != maxSlowCommands || cmds[0].TotalMs != 2 || cmds[len(cmds)-1].Phases[PhaseQueries] != maxSlowCommands+1 {
+		t.Errorf("expected the newest %d commands, got %d from %d", maxSlowCommands, len(cmds), cmds[0].TotalMs)
+	}
+}
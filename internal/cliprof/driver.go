@@ -0,0 +1,121 @@
+package cliprof
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+)
+
+// Driver wraps a database driver so the statements of its connections,
+// including reading their rows, count as PhaseQueries
+func Driver(d driver.Driver) driver.Driver {
+	return profDriver{d}
+}
+
+type profDriver struct {
+	driver.Driver
+}
+
+func (d profDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &profConn{conn}, nil
+}
+
+// profConn times statements run directly on the connection. Optional
+// interfaces the wrapped connection lacks report driver.ErrSkip, so
+// database/sql falls back as it would without the wrapper.
+type profConn struct {
+	driver.Conn
+}
+
+func (c *profConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer Span(PhaseQueries)()
+	return e.ExecContext(ctx, query, args)
+}
+
+func (c *profConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	end := Span(PhaseQueries)
+	rows, err := q.QueryContext(ctx, query, args)
+	end()
+	if err != nil {
+		return nil, err
+	}
+	return profRows{rows}, nil
+}
+
+func (c *profConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *profConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // Fallback for drivers without BeginTx
+}
+
+func (c *profConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *profConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *profConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *profConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// profRows times reading rows, where SQLite does most of a query's work
+type profRows struct {
+	driver.Rows
+}
+
+func (r profRows) Next(dest []driver.Value) error {
+	defer span(PhaseQueries, 0)() // Part of the query's call
+	return r.Rows.Next(dest)
+}
+
+// Transport wraps an HTTP transport so its requests count as PhaseHTTP
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return profTransport{base}
+}
+
+type profTransport struct {
+	base http.RoundTripper
+}
+
+func (t profTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer Span(PhaseHTTP)()
+	return t.base.RoundTrip(req)
+}
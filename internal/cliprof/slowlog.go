@@ -0,0 +1,107 @@
+package cliprof
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SlowThreshold is the HotPath time above which a command is slow
+const SlowThreshold = time.Second
+
+// maxSlowCommands caps the slow command log. Oldest entries are dropped
+// first.
+const maxSlowCommands = 1000
+
+// SlowCommand is a command whose HotPath time exceeded SlowThreshold. Only
+// the command path is kept, never its arguments.
+type SlowCommand struct {
+	Command string           `json:"command"` // e.g. "roborev list"
+	At      time.Time        `json:"at"`
+	TotalMs int64            `json:"total_ms"`
+	Phases  map[string]int64 `json:"phases"` // Milliseconds per phase
+}
+
+// NewSlowCommand returns the log entry of a command that took total,
+// with the phases recorded so far
+func NewSlowCommand(command string, total time.Duration) SlowCommand {
+	phases := make(map[string]int64)
+	for _, st := range Stats() {
+		phases[st.Phase] = st.Time.Milliseconds()
+	}
+	return SlowCommand{Command: command, At: time.Now(), TotalMs: total.Milliseconds(), Phases: phases}
+}
+
+// AppendSlowCommand adds an entry to the slow command log at path
+func AppendSlowCommand(path string, c SlowCommand) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return trimSlowCommands(path)
+}
+
+// ReadSlowCommands returns the entries of the slow command log at path,
+// oldest first. A missing log has no entries.
+func ReadSlowCommands(path string) ([]SlowCommand, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cmds []SlowCommand
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var c SlowCommand
+		if json.Unmarshal(scanner.Bytes(), &c) == nil {
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds, scanner.Err()
+}
+
+// trimSlowCommands rewrites the log keeping only the newest
+// maxSlowCommands entries
+func trimSlowCommands(path string) error {
+	cmds, err := ReadSlowCommands(path)
+	if err != nil || len(cmds) <= maxSlowCommands {
+		return err
+	}
+	cmds = cmds[len(cmds)-maxSlowCommands:]
+
+	var buf bytes.Buffer
+	for _, c := range cmds {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
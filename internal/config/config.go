@@ -2,12 +2,16 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/roborev-dev/roborev/internal/git"
@@ -17,7 +21,34 @@ import (
 type HookConfig struct {
 	Event   string `toml:"event"`   // "review.failed", "review.completed", "review.*"
 	Command string `toml:"command"` // shell command with {var} templates
-	Type    string `toml:"type"`    // "beads" for built-in, empty for command
+	Type    string `toml:"type"`    // "beads" or "desktop" for built-in, empty for command
+}
+
+// Stages of the review pipeline that run pipeline hooks
+const (
+	PipelineStagePrePrompt  = "pre_prompt"  // Output is added to the review prompt as context
+	PipelineStagePostReview = "post_review" // Receives the finished review
+)
+
+// DefaultPipelineHookTimeout is how long a pipeline hook may run when it
+// sets no timeout
+const DefaultPipelineHookTimeout = 60 * time.Second
+
+// PipelineHookConfig defines a command run inside the review pipeline. It
+// receives the job, and for post_review the review, as JSON on stdin.
+type PipelineHookConfig struct {
+	Stage   string `toml:"stage"`   // "pre_prompt" or "post_review"
+	Command string `toml:"command"` // shell command, run in the repo
+	Timeout string `toml:"timeout"` // e.g. "30s" (default 60s)
+}
+
+// TimeoutDuration returns how long the hook may run, applying the default
+// for unset or invalid values
+func (h PipelineHookConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultPipelineHookTimeout
 }
 
 // Config holds the daemon configuration
@@ -29,6 +60,36 @@ type Config struct {
 	DefaultModel       string `toml:"default_model"` // Default model for agents (format varies by agent)
 	JobTimeoutMinutes  int    `toml:"job_timeout_minutes"`
 
+	// RelatedReviewsCount is how many recent reviews with findings in the
+	// files a change touches are summarized in its prompt (0 disables)
+	RelatedReviewsCount int `toml:"related_reviews_count"`
+
+	// InteractiveWorkerFraction reserves this fraction of the workers
+	// (rounded up, leaving at least one for everything else) for interactive
+	// jobs, such as reviews enqueued with --wait, so they never queue behind
+	// backfill (0 = none reserved; interactive jobs are still claimed first)
+	InteractiveWorkerFraction float64 `toml:"interactive_worker_fraction"`
+
+	// IdleShutdownMinutes makes the daemon exit after this many minutes with
+	// an empty queue and no API activity (0 = never). Pairs with systemd
+	// socket activation, which relaunches the daemon on the next connection.
+	IdleShutdownMinutes int `toml:"idle_shutdown_minutes"`
+
+	// QueueStarvationMinutes is how long a queued job may wait while workers
+	// sit idle before the daemon warns that the queue is starved (default
+	// 10, negative = never)
+	QueueStarvationMinutes int `toml:"queue_starvation_minutes"`
+
+	// MinFreeDiskMB is the free disk space workers need in the temp and data
+	// directories to start a job; jobs are blocked below it (default 100,
+	// negative = never check)
+	MinFreeDiskMB int `toml:"min_free_disk_mb"`
+
+	// Language is the language of roborev's own messages in the CLI, the
+	// TUI and shared review pages, such as "de" (default: from the locale).
+	// The language of reviews is up to the review prompts.
+	Language string `toml:"language"`
+
 	// Workflow-specific agent/model configuration
 	ReviewAgent           string `toml:"review_agent"`
 	ReviewAgentFast       string `toml:"review_agent_fast"`
@@ -80,18 +141,114 @@ type Config struct {
 	// API keys (optional - agents use subscription auth by default)
 	AnthropicAPIKey string `toml:"anthropic_api_key" sensitive:"true"`
 
+	// Chat-completions API for the openai agent (no CLI needed)
+	OpenAI OpenAIConfig `toml:"openai"`
+
+	// Where the daemon runs agent commands
+	Executor ExecutorConfig `toml:"executor"`
+
 	// Hooks configuration
 	Hooks []HookConfig `toml:"hooks"`
 
+	// Commands run inside the review pipeline: pre_prompt hooks add context
+	// to review prompts, post_review hooks receive finished reviews
+	PipelineHooks []PipelineHookConfig `toml:"pipeline_hooks"`
+
 	// Sync configuration for PostgreSQL
 	Sync SyncConfig `toml:"sync"`
 
+	// Shared PostgreSQL review database
+	Database DatabaseConfig `toml:"database"`
+
 	// CI poller configuration
 	CI CIConfig `toml:"ci"`
 
 	// Analysis settings
 	DefaultMaxPromptSize int `toml:"default_max_prompt_size"` // Max prompt size in bytes before falling back to paths (default: 200KB)
 
+	// FanOutDiffSize splits reviews of diffs larger than this many bytes into
+	// per-file parts run in parallel and merged by a join job (0 = never)
+	FanOutDiffSize int `toml:"fanout_diff_size"`
+
+	// Reviewer rotation across multiple agents
+	ReviewRotation RotationConfig `toml:"review_rotation"`
+
+	// Branches commit hooks review (empty: all). Repos can override it.
+	AutoReviewBranches []string `toml:"auto_review_branches"`
+
+	// Opt-in anonymized usage telemetry
+	Telemetry TelemetryConfig `toml:"telemetry"`
+
+	// Offload large review prompts and outputs to an object store
+	BlobStore BlobStoreConfig `toml:"blob_store"`
+
+	// Skip or downgrade reviews of commits by bots (repos can override)
+	BotAuthors BotAuthorsConfig `toml:"bot_authors"`
+
+	// Review only a sample of hook-enqueued commits (repos can override)
+	Sampling SamplingConfig `toml:"sampling"`
+
+	// Fold runs of small hook-enqueued commits into one review (repos can override)
+	CommitGrouping CommitGroupingConfig `toml:"commit_grouping"`
+
+	// Worktrees 'roborev refine' keeps between fixes
+	WorktreePool WorktreePoolConfig `toml:"worktree_pool"`
+
+	// Quick inline review run by 'roborev review --pre-review' in the
+	// post-commit hook (repos can override)
+	PreReview PreReviewConfig `toml:"pre_review"`
+
+	// Premium agent for 'roborev review --thorough' (repos can override)
+	Focus FocusConfig `toml:"focus"`
+
+	// What to do with review findings that cite files or lines missing from
+	// the reviewed code: "keep" (default, only record), "flag" or "drop"
+	FindingValidation string `toml:"finding_validation"`
+
+	// Ask single-commit reviews to also propose an improved commit message
+	// ('roborev amend-message' applies it). Repos can override.
+	SuggestCommitMessage *bool `toml:"suggest_commit_message"`
+
+	// Ask reviews to open with a quick take of at most three sentences,
+	// shown in 'roborev list', the TUI queue, notifications and the
+	// statusline. Repos can override.
+	QuickTake *bool `toml:"quick_take"`
+
+	// Questions every review must answer pass/fail/n-a, item by item
+	// (e.g. "Tests added?"). A repo's checklist replaces this one.
+	Checklist []string `toml:"checklist"`
+
+	// Reuse the review of a commit for later commits with the same patch-id,
+	// such as cherry-picks onto release branches (default true). Repos can
+	// override.
+	ReusePatchReviews *bool `toml:"reuse_patch_reviews"`
+
+	// Scheduled backups of the review database
+	Backup BackupConfig `toml:"backup"`
+
+	// How long the daemon keeps finished jobs and their reviews
+	Retention RetentionConfig `toml:"retention"`
+
+	// How long 'roborev undo' can restore the rows removed by destructive
+	// commands such as 'roborev repo delete' (e.g., "168h"). Default: 720h
+	TrashRetention string `toml:"trash_retention"`
+
+	// Other roborev daemons, such as a team server, that 'roborev list
+	// --source' can query alongside the local one
+	Sources []SourceConfig `toml:"sources"`
+
+	// SignReviews signs each completed review with the local ed25519 key so
+	// it can later be checked with 'roborev verify <review-id>'
+	SignReviews bool `toml:"sign_reviews"`
+
+	// TrustedReviewKeys are the signing keys, as base64 public keys or
+	// fingerprints, that 'roborev verify' accepts besides this machine's own
+	TrustedReviewKeys []string `toml:"trusted_review_keys"`
+
+	// AuthorReports enables 'roborev report', which summarizes the findings
+	// in the user's own commits. Off by default.
+	AuthorReports bool `toml:"author_reports"`
+
 	// UI preferences
 	HideAddressedByDefault bool `toml:"hide_addressed_by_default"`
 	AutoFilterRepo         bool `toml:"auto_filter_repo"`
@@ -232,6 +389,180 @@ func (c *CIConfig) ResolvedAgents() []string {
 	return []string{""}
 }
 
+// TelemetryConfig holds opt-in usage telemetry settings.
+// Telemetry is off unless explicitly enabled with 'roborev telemetry enable'.
+type TelemetryConfig struct {
+	// Enabled turns on recording of anonymized job counters to the local queue
+	Enabled bool `toml:"enabled"`
+
+	// Endpoint is where queued events are periodically uploaded.
+	// When empty, events are only kept locally.
+	Endpoint string `toml:"endpoint"`
+}
+
+// DefaultBlobThresholdBytes is the size above which prompts and outputs are
+// offloaded when a blob store is configured.
+const DefaultBlobThresholdBytes = 64 * 1024
+
+// BlobStoreConfig configures offloading of large review prompts and outputs.
+// Offloaded values are replaced in the database by a reference to the blob.
+type BlobStoreConfig struct {
+	// Backend is "filesystem", "s3" or "gcs". Empty disables offloading.
+	Backend string `toml:"backend"`
+
+	// ThresholdBytes is the size above which a value is offloaded.
+	// Default: 64KB
+	ThresholdBytes int `toml:"threshold_bytes"`
+
+	// Path is the directory for the filesystem backend. Default: ~/.roborev/artifacts/blobs
+	Path string `toml:"path"`
+
+	// Bucket, Prefix, Region and Endpoint locate blobs in S3 or GCS. GCS is
+	// accessed through its S3-compatible XML API with HMAC keys. Endpoint
+	// defaults to AWS or Google Cloud Storage; set it for MinIO and similar.
+	Bucket   string `toml:"bucket"`
+	Prefix   string `toml:"prefix"`
+	Region   string `toml:"region"`
+	Endpoint string `toml:"endpoint"`
+
+	// Access keys for S3 or GCS HMAC authentication. Support ${VAR}
+	// expansion; for S3 they default to AWS_ACCESS_KEY_ID and
+	// AWS_SECRET_ACCESS_KEY.
+	AccessKeyID     string `toml:"access_key_id" sensitive:"true"`
+	SecretAccessKey string `toml:"secret_access_key" sensitive:"true"`
+}
+
+// Threshold returns the offload threshold, applying the default
+func (c *BlobStoreConfig) Threshold() int {
+	if c.ThresholdBytes > 0 {
+		return c.ThresholdBytes
+	}
+	return DefaultBlobThresholdBytes
+}
+
+// BackupConfig controls scheduled backups of the review database
+type BackupConfig struct {
+	// Enabled makes the daemon back up the database every Interval
+	Enabled bool `toml:"enabled"`
+
+	// Dir is where backups are written. Default: ~/.roborev/artifacts/backups
+	Dir string `toml:"dir"`
+
+	// Interval between backups (e.g., "6h", "24h"). Default: 24h
+	Interval string `toml:"interval"`
+
+	// Keep is how many backups to retain; older ones are deleted. Default: 7
+	Keep int `toml:"keep"`
+
+	// Compress gzips each backup
+	Compress bool `toml:"compress"`
+
+	// KeyFile is a file whose contents are hashed into an AES-256 key used
+	// to encrypt backups. Empty leaves backups unencrypted.
+	KeyFile string `toml:"key_file"`
+}
+
+// Backup defaults
+const (
+	DefaultBackupInterval = 24 * time.Hour
+	DefaultBackupKeep     = 7
+)
+
+// RetentionConfig is a retention policy for the review database: the daemon
+// periodically deletes finished jobs past either limit, with their reviews.
+// Both limits are unset by default, keeping everything.
+type RetentionConfig struct {
+	// MaxAgeDays deletes jobs that finished more than this many days ago
+	MaxAgeDays int `toml:"max_age_days"`
+
+	// KeepPerRepo deletes all but this many newest jobs of each repo
+	KeepPerRepo int `toml:"keep_per_repo"`
+}
+
+// Enabled reports whether the policy sets any limit
+func (c RetentionConfig) Enabled() bool {
+	return c.MaxAgeDays > 0 || c.KeepPerRepo > 0
+}
+
+// MaxAge returns how long finished jobs are kept, or 0 for no age limit
+func (c RetentionConfig) MaxAge() time.Duration {
+	if c.MaxAgeDays <= 0 {
+		return 0
+	}
+	return time.Duration(c.MaxAgeDays) * 24 * time.Hour
+}
+
+// DefaultTrashRetention is how long deleted rows stay restorable when
+// trash_retention is unset
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashRetentionDuration returns how long deleted rows stay restorable,
+// applying the default for unset or invalid values
+func (c *Config) TrashRetentionDuration() time.Duration {
+	if d, err := time.ParseDuration(c.TrashRetention); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTrashRetention
+}
+
+// DefaultQueueStarvation is how long a queued job may wait while workers
+// are idle before the queue is reported as starved
+const DefaultQueueStarvation = 10 * time.Minute
+
+// QueueStarvationThreshold returns how long a queued job may wait while
+// workers are idle before the queue is reported as starved, or 0 when the
+// check is disabled
+func (c *Config) QueueStarvationThreshold() time.Duration {
+	switch {
+	case c.QueueStarvationMinutes < 0:
+		return 0
+	case c.QueueStarvationMinutes == 0:
+		return DefaultQueueStarvation
+	}
+	return time.Duration(c.QueueStarvationMinutes) * time.Minute
+}
+
+// DefaultMinFreeDisk is the free disk space, in bytes, workers need to start
+// a job
+const DefaultMinFreeDisk = 100 << 20
+
+// MinFreeDisk returns the free disk space, in bytes, workers need to start a
+// job, or 0 when the check is disabled
+func (c *Config) MinFreeDisk() uint64 {
+	switch {
+	case c.MinFreeDiskMB < 0:
+		return 0
+	case c.MinFreeDiskMB == 0:
+		return DefaultMinFreeDisk
+	}
+	return uint64(c.MinFreeDiskMB) << 20
+}
+
+// BackupDir returns the backup directory, applying the default
+func (c *BackupConfig) BackupDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return filepath.Join(ArtifactsDir(), "backups")
+}
+
+// IntervalDuration returns the time between backups, applying the default
+// for unset or invalid values
+func (c *BackupConfig) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultBackupInterval
+}
+
+// KeepCount returns how many backups to retain, applying the default
+func (c *BackupConfig) KeepCount() int {
+	if c.Keep > 0 {
+		return c.Keep
+	}
+	return DefaultBackupKeep
+}
+
 // SyncConfig holds configuration for PostgreSQL sync
 type SyncConfig struct {
 	// Enabled enables sync to PostgreSQL
@@ -253,6 +584,153 @@ type SyncConfig struct {
 	// RepoNames provides custom display names for synced repos by identity.
 	// Example: {"git@github.com:org/repo.git": "my-project"}
 	RepoNames map[string]string `toml:"repo_names"`
+
+	// Remote is a shared store that roborev push and pull exchange
+	// encrypted review history through, with no server of its own
+	Remote SyncRemoteConfig `toml:"remote"`
+}
+
+// SyncRemoteConfig locates a team's shared sync remote: a directory (such
+// as a network share) or a prefix of an S3 or GCS bucket. Bundles pushed
+// there are encrypted with Key, which every member of the team sets.
+type SyncRemoteConfig struct {
+	// Backend is "filesystem", "s3" or "gcs"
+	Backend string `toml:"backend"`
+
+	// Path is the directory for the filesystem backend
+	Path string `toml:"path"`
+
+	// Bucket, Prefix, Region, Endpoint and the access keys are as for
+	// blob_store
+	Bucket          string `toml:"bucket"`
+	Prefix          string `toml:"prefix"`
+	Region          string `toml:"region"`
+	Endpoint        string `toml:"endpoint"`
+	AccessKeyID     string `toml:"access_key_id" sensitive:"true"`
+	SecretAccessKey string `toml:"secret_access_key" sensitive:"true"`
+
+	// Key is the team's passphrase bundles are encrypted with. Supports
+	// ${VAR} expansion; defaults to ROBOREV_SYNC_KEY.
+	Key string `toml:"key" sensitive:"true"`
+}
+
+// StoreConfig returns the remote as a blob store configuration
+func (c *SyncRemoteConfig) StoreConfig() BlobStoreConfig {
+	return BlobStoreConfig{
+		Backend:         c.Backend,
+		Path:            c.Path,
+		Bucket:          c.Bucket,
+		Prefix:          c.Prefix,
+		Region:          c.Region,
+		Endpoint:        c.Endpoint,
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+	}
+}
+
+// KeyExpanded returns the encryption passphrase with environment variables
+// expanded, falling back to ROBOREV_SYNC_KEY
+func (c *SyncRemoteConfig) KeyExpanded() string {
+	if key := os.ExpandEnv(c.Key); key != "" {
+		return key
+	}
+	return os.Getenv("ROBOREV_SYNC_KEY")
+}
+
+// Name identifies the remote, for remembering how far it was synced
+func (c *SyncRemoteConfig) Name() string {
+	if c.Backend == "filesystem" || c.Backend == "file" {
+		return "file:" + c.Path
+	}
+	name := c.Backend + "://" + c.Bucket
+	if prefix := strings.Trim(c.Prefix, "/"); prefix != "" {
+		name += "/" + prefix
+	}
+	if c.Endpoint != "" {
+		name += "@" + c.Endpoint
+	}
+	return name
+}
+
+// OpenAIConfig configures the openai agent, which calls a chat-completions
+// API directly. Any OpenAI-compatible server works, including local ones.
+type OpenAIConfig struct {
+	// BaseURL is the API root (default https://api.openai.com/v1, or
+	// OPENAI_BASE_URL)
+	BaseURL string `toml:"base_url"`
+
+	// APIKey is sent as a bearer token. Supports ${VAR} expansion; defaults
+	// to OPENAI_API_KEY. Local servers may need none.
+	APIKey string `toml:"api_key" sensitive:"true"`
+
+	// Model is used when no model is configured for the workflow
+	Model string `toml:"model"`
+}
+
+// APIKeyExpanded returns the API key with environment variables expanded
+func (c *OpenAIConfig) APIKeyExpanded() string {
+	return os.ExpandEnv(c.APIKey)
+}
+
+// ExecutorConfig selects where the daemon runs agent commands: as local
+// processes (the default), or in a container of a pinned image per review,
+// with Docker or as a Kubernetes Job
+type ExecutorConfig struct {
+	// Type is "local", "docker" or "kubernetes"
+	Type string `toml:"type"`
+
+	// Image holds the agent CLIs and the toolchain reviews need
+	Image string `toml:"image"`
+
+	// Env names the environment variables passed into the container, such
+	// as API keys
+	Env []string `toml:"env"`
+
+	// Network is the Docker network containers join
+	Network string `toml:"network"`
+
+	// Namespace of the Kubernetes Jobs (default "default")
+	Namespace string `toml:"namespace"`
+
+	// VolumeClaim is a Kubernetes persistent volume claim holding the repos,
+	// mounted at MountPath both on the daemon's host and in the pods. Without
+	// one, the repo is mounted from the node with a hostPath volume.
+	VolumeClaim string `toml:"volume_claim"`
+	MountPath   string `toml:"mount_path"`
+
+	// ServiceAccount runs the Kubernetes pods
+	ServiceAccount string `toml:"service_account"`
+
+	// Secret is a Kubernetes secret whose keys become environment variables
+	// of the pods, an alternative to Env that keeps keys off the daemon
+	Secret string `toml:"secret"`
+
+	// StartTimeout bounds how long a pod may take to start, image pull
+	// included (default "10m")
+	StartTimeout string `toml:"start_timeout"`
+}
+
+// Validate checks the executor settings
+func (c *ExecutorConfig) Validate() error {
+	switch c.Type {
+	case "", "local":
+		return nil
+	case "docker", "kubernetes":
+	default:
+		return fmt.Errorf("executor: invalid type %q (valid: local, docker, kubernetes)", c.Type)
+	}
+	if c.Image == "" {
+		return fmt.Errorf("executor: %s needs an image", c.Type)
+	}
+	if c.VolumeClaim != "" && !filepath.IsAbs(c.MountPath) {
+		return fmt.Errorf("executor: volume_claim needs an absolute mount_path")
+	}
+	if c.StartTimeout != "" {
+		if _, err := time.ParseDuration(c.StartTimeout); err != nil {
+			return fmt.Errorf("executor: invalid start_timeout %q", c.StartTimeout)
+		}
+	}
+	return nil
 }
 
 // PostgresURLExpanded returns the PostgreSQL URL with environment variables expanded.
@@ -264,6 +742,23 @@ func (c *SyncConfig) PostgresURLExpanded() string {
 	return os.ExpandEnv(c.PostgresURL)
 }
 
+// DatabaseConfig points at a PostgreSQL database that several machines
+// share as their review database, instead of syncing local ones
+type DatabaseConfig struct {
+	// PostgresURL is the connection string for PostgreSQL.
+	// Supports environment variable expansion via ${VAR} syntax.
+	PostgresURL string `toml:"postgres_url" sensitive:"true"`
+}
+
+// PostgresURLExpanded returns the PostgreSQL URL with environment variables expanded.
+// Returns empty string if URL is not set.
+func (c *DatabaseConfig) PostgresURLExpanded() string {
+	if c.PostgresURL == "" {
+		return ""
+	}
+	return os.ExpandEnv(c.PostgresURL)
+}
+
 // GetRepoDisplayName returns the configured display name for a repo identity,
 // or empty string if no override is configured.
 func (c *SyncConfig) GetRepoDisplayName(identity string) string {
@@ -306,6 +801,538 @@ func (c *SyncConfig) Validate() []string {
 	return warnings
 }
 
+// SourceConfig names another roborev daemon whose jobs can be listed
+// together with the local daemon's
+type SourceConfig struct {
+	Name string `toml:"name"`
+	Addr string `toml:"addr"` // Base URL, e.g. "http://roborev.internal:7373"
+}
+
+// FindSource returns the configured source with the given name
+func (c *Config) FindSource(name string) (SourceConfig, bool) {
+	for _, src := range c.Sources {
+		if strings.EqualFold(src.Name, name) {
+			return src, true
+		}
+	}
+	return SourceConfig{}, false
+}
+
+// ContextFilesConfig controls adding the content of files touched by a change
+// to commit and range review prompts, so the reviewer sees the code around
+// each hunk rather than the diff alone.
+type ContextFilesConfig struct {
+	// Mode is "full" (whole files, falling back to the changed blocks when a
+	// file does not fit the budget), "symbols" (only the top-level blocks
+	// containing changes), or empty/"off" to review the diff alone.
+	Mode string `toml:"mode"`
+
+	// MaxTokens caps the added content, estimated at 4 bytes per token.
+	MaxTokens int `toml:"max_tokens"`
+}
+
+// DefaultContextFilesMaxTokens is the context file budget when max_tokens is unset.
+const DefaultContextFilesMaxTokens = 8000
+
+// ResolveContextFiles returns the repo's context file settings with the mode
+// normalized ("" when disabled or unrecognized) and the budget defaulted.
+func ResolveContextFiles(repoPath string) ContextFilesConfig {
+	repoCfg, err := LoadRepoConfig(repoPath)
+	if err != nil || repoCfg == nil {
+		return ContextFilesConfig{}
+	}
+	cfg := repoCfg.ContextFiles
+	cfg.Mode = strings.ToLower(strings.TrimSpace(cfg.Mode))
+	if cfg.Mode != "full" && cfg.Mode != "symbols" {
+		cfg.Mode = ""
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultContextFilesMaxTokens
+	}
+	return cfg
+}
+
+// ProjectDocsConfig lists documents describing a project's conventions
+// (architecture notes, contributing and style guides) that are included in
+// every review prompt for the repo.
+type ProjectDocsConfig struct {
+	// Paths of the documents, relative to the repo root
+	Paths []string `toml:"paths"`
+
+	// MaxTokens caps the included content, estimated at 4 bytes per token.
+	// Documents that do not fit are truncated, in the order listed.
+	MaxTokens int `toml:"max_tokens"`
+}
+
+// DefaultProjectDocsMaxTokens is the project docs budget when max_tokens is unset.
+const DefaultProjectDocsMaxTokens = 4000
+
+// ResolveProjectDocs returns the repo's project docs settings with blank
+// paths dropped and the budget defaulted.
+func ResolveProjectDocs(repoPath string) ProjectDocsConfig {
+	repoCfg, err := LoadRepoConfig(repoPath)
+	if err != nil || repoCfg == nil {
+		return ProjectDocsConfig{}
+	}
+	cfg := ProjectDocsConfig{MaxTokens: repoCfg.ProjectDocs.MaxTokens}
+	for _, p := range repoCfg.ProjectDocs.Paths {
+		if p = strings.TrimSpace(p); p != "" {
+			cfg.Paths = append(cfg.Paths, p)
+		}
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultProjectDocsMaxTokens
+	}
+	return cfg
+}
+
+// AutoIssuesConfig files an issue in a tracker for each review finding at
+// or above a severity. Findings with the same fingerprint are filed once.
+type AutoIssuesConfig struct {
+	// Tracker is "github", "gitlab" or "jira"; empty disables auto issues
+	Tracker string `toml:"tracker"`
+
+	// MinSeverity is the lowest severity filed. Default: critical
+	MinSeverity string `toml:"min_severity"`
+
+	// Project is the GitHub "owner/repo" or GitLab project path, by default
+	// the one gh or glab infers from the repo's remotes. For Jira it is the
+	// project key and is required.
+	Project string `toml:"project"`
+
+	// Labels are added to every issue
+	Labels []string `toml:"labels"`
+
+	// JiraURL is the Jira site, e.g. https://example.atlassian.net.
+	// Credentials are read from JIRA_EMAIL and JIRA_API_TOKEN.
+	JiraURL string `toml:"jira_url"`
+
+	// JiraIssueType is the type of created Jira issues. Default: Bug
+	JiraIssueType string `toml:"jira_issue_type"`
+}
+
+// ResolveAutoIssues returns the repo's auto issue settings with defaults
+// applied. Tracker is empty when auto issues are off.
+func ResolveAutoIssues(repoPath string) (AutoIssuesConfig, error) {
+	repoCfg, err := LoadRepoConfig(repoPath)
+	if err != nil || repoCfg == nil {
+		return AutoIssuesConfig{}, err
+	}
+	cfg := repoCfg.AutoIssues
+	cfg.Tracker = strings.ToLower(strings.TrimSpace(cfg.Tracker))
+	switch cfg.Tracker {
+	case "":
+		return AutoIssuesConfig{}, nil
+	case "github", "gitlab":
+	case "jira":
+		if strings.TrimSpace(cfg.JiraURL) == "" || strings.TrimSpace(cfg.Project) == "" {
+			return AutoIssuesConfig{}, fmt.Errorf("auto_issues: jira needs jira_url and project")
+		}
+		cfg.JiraURL = strings.TrimRight(strings.TrimSpace(cfg.JiraURL), "/")
+		if strings.TrimSpace(cfg.JiraIssueType) == "" {
+			cfg.JiraIssueType = "Bug"
+		}
+	default:
+		return AutoIssuesConfig{}, fmt.Errorf("auto_issues: invalid tracker %q (valid: github, gitlab, jira)", cfg.Tracker)
+	}
+	if cfg.MinSeverity, err = NormalizeMinSeverity(cfg.MinSeverity); err != nil {
+		return AutoIssuesConfig{}, fmt.Errorf("auto_issues: %w", err)
+	}
+	if cfg.MinSeverity == "" {
+		cfg.MinSeverity = "critical"
+	}
+	cfg.Project = strings.TrimSpace(cfg.Project)
+	return cfg, nil
+}
+
+// BotAuthorsConfig controls reviews of commits made by bots such as
+// dependabot, renovate or release tooling.
+type BotAuthorsConfig struct {
+	// Action is "skip" (record the commit as skipped, with the reason),
+	// "downgrade" (review at the fast reasoning level), or empty/"off".
+	Action string `toml:"action"`
+
+	// Patterns are matched case-insensitively against the commit author's
+	// name and email. "*" matches any run of characters; nothing else is
+	// special. DefaultBotAuthorPatterns is used when empty.
+	Patterns []string `toml:"patterns"`
+}
+
+// DefaultBotAuthorPatterns match common dependency and release bots
+var DefaultBotAuthorPatterns = []string{
+	"*[bot]", "*[bot]@*", "dependabot*", "renovate*", "*release-bot*", "semantic-release*",
+}
+
+// ResolveBotAuthors returns the bot author settings for a repo: the repo's
+// [bot_authors] when it sets an action, otherwise the global one. Action is
+// normalized ("" when disabled or unrecognized) and patterns defaulted.
+func ResolveBotAuthors(repoPath string, globalCfg *Config) BotAuthorsConfig {
+	var cfg BotAuthorsConfig
+	if globalCfg != nil {
+		cfg = globalCfg.BotAuthors
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && strings.TrimSpace(repoCfg.BotAuthors.Action) != "" {
+		cfg = repoCfg.BotAuthors
+	}
+	cfg.Action = strings.ToLower(strings.TrimSpace(cfg.Action))
+	if cfg.Action != "skip" && cfg.Action != "downgrade" {
+		return BotAuthorsConfig{}
+	}
+	if len(cfg.Patterns) == 0 {
+		cfg.Patterns = DefaultBotAuthorPatterns
+	}
+	return cfg
+}
+
+// MatchAuthor returns the first pattern matching the author's name or
+// email, or "" if the author is not a bot (or handling is disabled).
+func (c BotAuthorsConfig) MatchAuthor(name, email string) string {
+	if c.Action == "" {
+		return ""
+	}
+	for _, pattern := range c.Patterns {
+		p := strings.ToLower(strings.TrimSpace(pattern))
+		if p == "" {
+			continue
+		}
+		if matchWildcard(p, strings.ToLower(name)) || (email != "" && matchWildcard(p, strings.ToLower(email))) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// matchWildcard reports whether s matches pattern, where "*" matches any
+// run of characters
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+// SamplingConfig lets high-volume repos review only some of the commits
+// their hooks enqueue. Sampled-out commits are recorded as skipped jobs so
+// coverage stays visible.
+type SamplingConfig struct {
+	// Mode is "every" (review every Nth commit), "risk" (review commits with
+	// a probability raised by their risk score), or empty/"off".
+	Mode string `toml:"mode"`
+
+	// Every is N for the "every" mode (default 10)
+	Every int `toml:"every"`
+
+	// Rate is the probability of reviewing a commit with no risk for the
+	// "risk" mode, between 0 and 1 (default 0.2). Riskier commits are
+	// reviewed more often, and the riskiest always.
+	Rate float64 `toml:"rate"`
+}
+
+// ResolveSampling returns the sampling settings for a repo: the repo's
+// [sampling] when it sets a mode, otherwise the global one. Mode is
+// normalized ("" when disabled or unrecognized) and Every and Rate
+// defaulted.
+func ResolveSampling(repoPath string, globalCfg *Config) SamplingConfig {
+	var cfg SamplingConfig
+	if globalCfg != nil {
+		cfg = globalCfg.Sampling
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && strings.TrimSpace(repoCfg.Sampling.Mode) != "" {
+		cfg = repoCfg.Sampling
+	}
+	cfg.Mode = strings.ToLower(strings.TrimSpace(cfg.Mode))
+	switch cfg.Mode {
+	case "every":
+		if cfg.Every <= 0 {
+			cfg.Every = 10
+		}
+	case "risk":
+		if cfg.Rate <= 0 || cfg.Rate > 1 {
+			cfg.Rate = 0.2
+		}
+	default:
+		return SamplingConfig{}
+	}
+	return cfg
+}
+
+// Default limits of the worktree pool
+const (
+	DefaultWorktreePoolPerRepo = 2
+	DefaultWorktreePoolTotal   = 8
+)
+
+// WorktreePoolConfig configures the worktrees 'roborev refine' runs its
+// fixer agent in. Pooled worktrees are kept in the data directory and reset
+// to the next commit, instead of being created and removed for every fix.
+type WorktreePoolConfig struct {
+	// Enabled keeps worktrees between fixes and refine runs
+	Enabled bool `toml:"enabled"`
+
+	// PerRepo is the most worktrees kept for one repo (default 2)
+	PerRepo int `toml:"per_repo"`
+
+	// Total is the most idle worktrees kept across repos; the least
+	// recently used are removed first (default 8)
+	Total int `toml:"total"`
+}
+
+// Limits returns the per-repo and total limits of the pool, applying the
+// defaults for unset values
+func (c WorktreePoolConfig) Limits() (perRepo, total int) {
+	perRepo, total = c.PerRepo, c.Total
+	if perRepo <= 0 {
+		perRepo = DefaultWorktreePoolPerRepo
+	}
+	if total <= 0 {
+		total = DefaultWorktreePoolTotal
+	}
+	return perRepo, total
+}
+
+// DefaultCommitGroupingWindow is how long a commit group waits for the
+// next commit when no window is configured
+const DefaultCommitGroupingWindow = 10 * time.Minute
+
+// CommitGroupingConfig folds consecutive hook-enqueued commits on a branch
+// into one range review, for teams that commit in many small increments.
+// A grouped review is held until no commit has joined it for the window.
+type CommitGroupingConfig struct {
+	// Mode is "author" (group consecutive commits by the same author),
+	// "branch" (group all consecutive commits), or empty/"off".
+	Mode string `toml:"mode"`
+
+	// Window is the longest gap between grouped commits, and how long a
+	// group waits for another commit before it is reviewed (default "10m")
+	Window string `toml:"window"`
+}
+
+// WindowDuration returns the grouping window, applying the default for
+// unset or invalid values
+func (c CommitGroupingConfig) WindowDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Window); err == nil && d > 0 {
+		return d
+	}
+	return DefaultCommitGroupingWindow
+}
+
+// ResolveCommitGrouping returns the commit grouping settings for a repo:
+// the repo's [commit_grouping] when it sets a mode, otherwise the global
+// one. Mode is normalized ("" when disabled or unrecognized).
+func ResolveCommitGrouping(repoPath string, globalCfg *Config) CommitGroupingConfig {
+	var cfg CommitGroupingConfig
+	if globalCfg != nil {
+		cfg = globalCfg.CommitGrouping
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && strings.TrimSpace(repoCfg.CommitGrouping.Mode) != "" {
+		cfg = repoCfg.CommitGrouping
+	}
+	cfg.Mode = strings.ToLower(strings.TrimSpace(cfg.Mode))
+	if cfg.Mode != "author" && cfg.Mode != "branch" {
+		return CommitGroupingConfig{}
+	}
+	return cfg
+}
+
+// DefaultPreReviewTimeout is the time budget of a pre-review when none is
+// configured
+const DefaultPreReviewTimeout = 5 * time.Second
+
+// PreReviewConfig configures the pre-review: a quick review the post-commit
+// hook runs inline, within a strict time budget, while the full review is
+// queued on the daemon as usual. It is meant for a small, fast model, such
+// as a local one served through the openai agent.
+type PreReviewConfig struct {
+	// Agent runs the pre-review (default: the fast review agent)
+	Agent string `toml:"agent"`
+
+	// Model for the agent (default: the fast review model)
+	Model string `toml:"model"`
+
+	// Timeout is the time budget, after which the pre-review is abandoned
+	// (default "5s")
+	Timeout string `toml:"timeout"`
+}
+
+// TimeoutDuration returns the pre-review time budget, applying the default
+// for unset or invalid values
+func (c PreReviewConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultPreReviewTimeout
+}
+
+// ResolvePreReview returns the pre-review settings for a repo: the global
+// [pre_review] with each field the repo's [pre_review] sets overriding it
+func ResolvePreReview(repoPath string, globalCfg *Config) PreReviewConfig {
+	var cfg PreReviewConfig
+	if globalCfg != nil {
+		cfg = globalCfg.PreReview
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		if v := strings.TrimSpace(repoCfg.PreReview.Agent); v != "" {
+			cfg.Agent = v
+		}
+		if v := strings.TrimSpace(repoCfg.PreReview.Model); v != "" {
+			cfg.Model = v
+		}
+		if v := strings.TrimSpace(repoCfg.PreReview.Timeout); v != "" {
+			cfg.Timeout = v
+		}
+	}
+	return cfg
+}
+
+// FocusConfig selects the agent of thorough reviews, which 'roborev review
+// --thorough' requests for high-stakes commits. They run at the thorough
+// reasoning level and are never sampled out, grouped or downgraded.
+type FocusConfig struct {
+	// Agent runs thorough reviews (default: the thorough review agent)
+	Agent string `toml:"agent"`
+
+	// Model for the agent (default: the thorough review model)
+	Model string `toml:"model"`
+}
+
+// ResolveFocus returns the thorough review settings for a repo: the global
+// [focus] with each field the repo's [focus] sets overriding it
+func ResolveFocus(repoPath string, globalCfg *Config) FocusConfig {
+	var cfg FocusConfig
+	if globalCfg != nil {
+		cfg = globalCfg.Focus
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		if v := strings.TrimSpace(repoCfg.Focus.Agent); v != "" {
+			cfg.Agent = v
+		}
+		if v := strings.TrimSpace(repoCfg.Focus.Model); v != "" {
+			cfg.Model = v
+		}
+	}
+	cfg.Agent = strings.TrimSpace(cfg.Agent)
+	cfg.Model = strings.TrimSpace(cfg.Model)
+	return cfg
+}
+
+// RouteConfig routes reviews of changes under some paths of a repo, such
+// as one service of a monorepo, to their own review settings, owners and
+// hooks. Routes are matched against the files each commit or range touches
+// when it is enqueued.
+type RouteConfig struct {
+	// Name identifies the route in job details and hook templates
+	// (default: its first path)
+	Name string `toml:"name"`
+
+	// Paths are directory prefixes ("services/payments/") or globs where
+	// "**" matches any number of directories ("services/payments/**")
+	Paths []string `toml:"paths"`
+
+	// Review settings applied unless the request sets them; the first
+	// matching route that sets each one wins. Model applies with Agent.
+	ReviewType string `toml:"review_type"`
+	Agent      string `toml:"agent"`
+	Model      string `toml:"model"`
+	Reasoning  string `toml:"reasoning"`
+
+	// Owners are the people or teams responsible for these paths, passed
+	// to hooks as {owners}
+	Owners []string `toml:"owners"`
+
+	// Hooks fire only for reviews routed here, e.g. to notify the owning
+	// team's channel
+	Hooks []HookConfig `toml:"hooks"`
+}
+
+// ID returns the route's name, or its first path when unnamed
+func (r RouteConfig) ID() string {
+	if r.Name != "" || len(r.Paths) == 0 {
+		return r.Name
+	}
+	return r.Paths[0]
+}
+
+// Matches reports whether file, a slash-separated path relative to the
+// repo root, falls under one of the route's paths
+func (r RouteConfig) Matches(file string) bool {
+	for _, p := range r.Paths {
+		if matchRepoPath(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRepoPath reports whether file, a slash-separated path relative to
+// the repo root, falls under p: a directory prefix or a glob where "**"
+// matches any number of directories
+func matchRepoPath(p, file string) bool {
+	p = strings.TrimPrefix(strings.TrimSpace(p), "/")
+	if p == "" {
+		return false
+	}
+	if !strings.ContainsAny(p, "*?[") {
+		dir := strings.TrimSuffix(p, "/")
+		return file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	return matchPathGlob(strings.Split(p, "/"), strings.Split(file, "/"))
+}
+
+// matchPathGlob matches path segments against pattern segments, where a
+// "**" segment matches zero or more segments
+func matchPathGlob(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchPathGlob(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// ResolveRoutes returns the repo's routes that match any of files, in
+// configuration order
+func ResolveRoutes(repoPath string, files []string) []RouteConfig {
+	repoCfg, err := LoadRepoConfig(repoPath)
+	if err != nil || repoCfg == nil {
+		return nil
+	}
+	var matched []RouteConfig
+	for _, route := range repoCfg.Routes {
+		for _, f := range files {
+			if route.Matches(f) {
+				matched = append(matched, route)
+				break
+			}
+		}
+	}
+	return matched
+}
+
 // RepoCIConfig holds per-repo CI overrides (used by the CI poller for this repo).
 // These override the global [ci] settings when reviewing this specific repo.
 type RepoCIConfig struct {
@@ -322,6 +1349,76 @@ type RepoCIConfig struct {
 	MinSeverity string `toml:"min_severity"`
 }
 
+// RotationConfig spreads reviews across several agents, e.g. to send most
+// commits to a cheap agent and a sample to a premium one while collecting
+// comparative quality data.
+type RotationConfig struct {
+	// Mode selects how agents are picked: "weighted" (random, proportional
+	// to each agent's weight; the default) or "alternate" (round-robin).
+	Mode string `toml:"mode"`
+
+	// Agents lists the agents to rotate between as "name" or "name:weight"
+	// (e.g., ["codex:80", "claude-code:20"]). Weight defaults to 1.
+	Agents []string `toml:"agents"`
+}
+
+// RotationEntry is a parsed element of RotationConfig.Agents.
+type RotationEntry struct {
+	Agent  string
+	Weight int
+}
+
+// Enabled returns true if rotation is configured with at least one agent.
+func (c RotationConfig) Enabled() bool {
+	return len(c.Agents) > 0
+}
+
+// Entries parses Agents into name/weight pairs.
+func (c RotationConfig) Entries() ([]RotationEntry, error) {
+	entries := make([]RotationEntry, 0, len(c.Agents))
+	for _, raw := range c.Agents {
+		name, weightStr, hasWeight := strings.Cut(strings.TrimSpace(raw), ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("review_rotation: empty agent name in %q", raw)
+		}
+		weight := 1
+		if hasWeight {
+			n, err := strconv.Atoi(strings.TrimSpace(weightStr))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("review_rotation: invalid weight in %q (must be a positive integer)", raw)
+			}
+			weight = n
+		}
+		entries = append(entries, RotationEntry{Agent: name, Weight: weight})
+	}
+	return entries, nil
+}
+
+// NormalizedMode returns the rotation mode, defaulting to "weighted".
+func (c RotationConfig) NormalizedMode() (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(c.Mode)); mode {
+	case "", "weighted", "sample":
+		return "weighted", nil
+	case "alternate", "round-robin":
+		return "alternate", nil
+	default:
+		return "", fmt.Errorf("review_rotation: invalid mode %q (valid: weighted, alternate)", c.Mode)
+	}
+}
+
+// ResolveReviewRotation returns the rotation policy for a repo.
+// A per-repo [review_rotation] with agents replaces the global one entirely.
+func ResolveReviewRotation(repoPath string, globalCfg *Config) RotationConfig {
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && repoCfg.ReviewRotation.Enabled() {
+		return repoCfg.ReviewRotation
+	}
+	if globalCfg != nil {
+		return globalCfg.ReviewRotation
+	}
+	return RotationConfig{}
+}
+
 // RepoConfig holds per-repo overrides
 type RepoConfig struct {
 	Agent              string   `toml:"agent"`
@@ -329,7 +1426,11 @@ type RepoConfig struct {
 	ReviewContextCount int      `toml:"review_context_count"`
 	ReviewGuidelines   string   `toml:"review_guidelines"`
 	JobTimeoutMinutes  int      `toml:"job_timeout_minutes"`
+	ReviewSLAMinutes   int      `toml:"review_sla_minutes"` // Reviews should finish this long after enqueue (0 = no SLA)
 	ExcludedBranches   []string `toml:"excluded_branches"`
+	DefaultBranch      string   `toml:"default_branch"`       // Default: detected, or from GitHub
+	ProtectedBranches  []string `toml:"protected_branches"`   // Names or globs (default: from GitHub, or the default branch)
+	AutoReviewBranches []string `toml:"auto_review_branches"` // Branches commit hooks review (empty: all)
 	DisplayName        string   `toml:"display_name"`
 	ReviewReasoning    string   `toml:"review_reasoning"` // Reasoning level for reviews: thorough, standard, fast
 	RefineReasoning    string   `toml:"refine_reasoning"` // Reasoning level for refine: thorough, standard, fast
@@ -338,6 +1439,51 @@ type RepoConfig struct {
 	// CI-specific overrides (used by CI poller for this repo)
 	CI RepoCIConfig `toml:"ci"`
 
+	// Reviewer rotation (overrides the global [review_rotation] when agents are set)
+	ReviewRotation RotationConfig `toml:"review_rotation"`
+
+	// Include touched files' content in review prompts
+	ContextFiles ContextFilesConfig `toml:"context_files"`
+
+	// Convention documents included in every review prompt
+	ProjectDocs ProjectDocsConfig `toml:"project_docs"`
+
+	// Bot author handling (overrides the global [bot_authors] when action is set)
+	BotAuthors BotAuthorsConfig `toml:"bot_authors"`
+
+	// Commit sampling (overrides the global [sampling] when mode is set)
+	Sampling SamplingConfig `toml:"sampling"`
+
+	// Commit grouping (overrides the global [commit_grouping] when mode is set)
+	CommitGrouping CommitGroupingConfig `toml:"commit_grouping"`
+
+	// Inline pre-review settings (each set field overrides the global one)
+	PreReview PreReviewConfig `toml:"pre_review"`
+
+	// Thorough review settings (each set field overrides the global one)
+	Focus FocusConfig `toml:"focus"`
+
+	// Handling of findings with invalid file or line references (overrides global)
+	FindingValidation string `toml:"finding_validation"`
+
+	// Propose improved commit messages in reviews (overrides global)
+	SuggestCommitMessage *bool `toml:"suggest_commit_message"`
+
+	// Open reviews with a quick take (overrides global)
+	QuickTake *bool `toml:"quick_take"`
+
+	// Review checklist items (replaces the global checklist when set)
+	Checklist []string `toml:"checklist"`
+
+	// Reuse reviews for cherry-picked commits (overrides global)
+	ReusePatchReviews *bool `toml:"reuse_patch_reviews"`
+
+	// File tracker issues for severe findings
+	AutoIssues AutoIssuesConfig `toml:"auto_issues"`
+
+	// Review settings, owners and hooks for paths within the repo
+	Routes []RouteConfig `toml:"routes"`
+
 	// Workflow-specific agent/model configuration
 	ReviewAgent           string `toml:"review_agent"`
 	ReviewAgentFast       string `toml:"review_agent_fast"`
@@ -383,21 +1529,36 @@ type RepoConfig struct {
 	// Hooks configuration (per-repo)
 	Hooks []HookConfig `toml:"hooks"`
 
+	// Pipeline hooks, run after the global ones
+	PipelineHooks []PipelineHookConfig `toml:"pipeline_hooks"`
+
 	// Analysis settings
-	MaxPromptSize int `toml:"max_prompt_size"` // Max prompt size in bytes before falling back to paths (overrides global default)
+	MaxPromptSize  int `toml:"max_prompt_size"`  // Max prompt size in bytes before falling back to paths (overrides global default)
+	FanOutDiffSize int `toml:"fanout_diff_size"` // Diff size in bytes above which reviews fan out per file (overrides global)
+}
+
+// InteractiveWorkers returns how many of numWorkers are reserved for
+// interactive jobs
+func (c *Config) InteractiveWorkers(numWorkers int) int {
+	if c.InteractiveWorkerFraction <= 0 || numWorkers < 2 {
+		return 0
+	}
+	n := int(math.Ceil(c.InteractiveWorkerFraction * float64(numWorkers)))
+	return min(n, numWorkers-1)
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		ServerAddr:         "127.0.0.1:7373",
-		MaxWorkers:         4,
-		ReviewContextCount: 3,
-		DefaultAgent:       "codex",
-		JobTimeoutMinutes:  30,
-		CodexCmd:           "codex",
-		ClaudeCodeCmd:      "claude",
-		CursorCmd:          "agent",
+		ServerAddr:          "127.0.0.1:7373",
+		MaxWorkers:          4,
+		ReviewContextCount:  3,
+		RelatedReviewsCount: 3,
+		DefaultAgent:        "codex",
+		JobTimeoutMinutes:   30,
+		CodexCmd:            "codex",
+		ClaudeCodeCmd:       "claude",
+		CursorCmd:           "agent",
 	}
 }
 
@@ -436,6 +1597,9 @@ func LoadGlobalFrom(path string) (*Config, error) {
 	if err := cfg.CI.NormalizeInstallations(); err != nil {
 		return nil, fmt.Errorf("config: %w", err)
 	}
+	if err := cfg.Executor.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
 
 	return cfg, nil
 }
@@ -509,6 +1673,42 @@ func ResolveJobTimeout(repoPath string, globalCfg *Config) int {
 	return resolve(30, repoVal, globalVal)
 }
 
+// ResolveReviewSLA returns how soon after being enqueued the repo's reviews
+// should finish, or 0 when the repo sets no SLA
+func ResolveReviewSLA(repoPath string) time.Duration {
+	repoCfg, err := LoadRepoConfig(repoPath)
+	if err != nil || repoCfg == nil {
+		return 0
+	}
+	return time.Duration(clampPositive(repoCfg.ReviewSLAMinutes)) * time.Minute
+}
+
+// Finding validation modes
+const (
+	FindingValidationKeep = "keep"
+	FindingValidationFlag = "flag"
+	FindingValidationDrop = "drop"
+)
+
+// ResolveFindingValidation returns how findings that cite nonexistent files
+// or lines are handled: per-repo config, then global config, then "keep".
+// Unrecognized values are treated as "keep".
+func ResolveFindingValidation(repoPath string, globalCfg *Config) string {
+	var repoVal, globalVal string
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		repoVal = strings.ToLower(strings.TrimSpace(repoCfg.FindingValidation))
+	}
+	if globalCfg != nil {
+		globalVal = strings.ToLower(strings.TrimSpace(globalCfg.FindingValidation))
+	}
+	switch mode := resolve(FindingValidationKeep, repoVal, globalVal); mode {
+	case FindingValidationFlag, FindingValidationDrop:
+		return mode
+	default:
+		return FindingValidationKeep
+	}
+}
+
 // IsBranchExcluded checks if a branch should be excluded from reviews
 func IsBranchExcluded(repoPath, branch string) bool {
 	repoCfg, err := LoadRepoConfig(repoPath)
@@ -524,6 +1724,39 @@ func IsBranchExcluded(repoPath, branch string) bool {
 	return false
 }
 
+// Branch keywords of auto_review_branches, standing for the repo's tracked
+// branches
+const (
+	BranchKeywordDefault   = "@default"
+	BranchKeywordProtected = "@protected"
+)
+
+// BranchMatches reports whether branch is one of patterns, which are branch
+// names or globs such as "release/*"
+func BranchMatches(patterns []string, branch string) bool {
+	for _, p := range patterns {
+		if p == branch {
+			return true
+		}
+		if ok, _ := path.Match(p, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveAutoReviewBranches returns the branches commit hooks review in a
+// repo, or nil if they review all. The repo's list replaces the global one.
+func ResolveAutoReviewBranches(repoPath string, globalCfg *Config) []string {
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && len(repoCfg.AutoReviewBranches) > 0 {
+		return repoCfg.AutoReviewBranches
+	}
+	if globalCfg != nil {
+		return globalCfg.AutoReviewBranches
+	}
+	return nil
+}
+
 // GetDisplayName returns the display name for a repo, or empty if not set
 func GetDisplayName(repoPath string) string {
 	repoCfg, err := LoadRepoConfig(repoPath)
@@ -656,6 +1889,96 @@ func ResolveMaxPromptSize(repoPath string, globalCfg *Config) int {
 	return resolve(DefaultMaxPromptSize, repoVal, globalVal)
 }
 
+// ResolveFanOutDiffSize returns the diff size in bytes above which a commit
+// or range review is split into per-file parts: per-repo config, then global
+// config. 0 (the default) disables fan-out.
+func ResolveFanOutDiffSize(repoPath string, globalCfg *Config) int {
+	var repoVal int
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		repoVal = clampPositive(repoCfg.FanOutDiffSize)
+	}
+	var globalVal int
+	if globalCfg != nil {
+		globalVal = clampPositive(globalCfg.FanOutDiffSize)
+	}
+	return resolve(0, repoVal, globalVal)
+}
+
+// ResolveSuggestCommitMessage reports whether single-commit reviews propose
+// an improved commit message: per-repo config, then global config, then off.
+func ResolveSuggestCommitMessage(repoPath string, globalCfg *Config) bool {
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && repoCfg.SuggestCommitMessage != nil {
+		return *repoCfg.SuggestCommitMessage
+	}
+	if globalCfg != nil && globalCfg.SuggestCommitMessage != nil {
+		return *globalCfg.SuggestCommitMessage
+	}
+	return false
+}
+
+// ResolvePipelineHooks returns the pipeline hooks of a stage for a repo:
+// the global ones, then the repo's
+func ResolvePipelineHooks(repoPath string, globalCfg *Config, stage string) []PipelineHookConfig {
+	var all []PipelineHookConfig
+	if globalCfg != nil {
+		all = append(all, globalCfg.PipelineHooks...)
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		all = append(all, repoCfg.PipelineHooks...)
+	}
+	var hooks []PipelineHookConfig
+	for _, h := range all {
+		if h.Stage == stage && strings.TrimSpace(h.Command) != "" {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// ResolveQuickTake reports whether reviews open with a quick take of at
+// most three sentences: per-repo config, then global config, then off.
+func ResolveQuickTake(repoPath string, globalCfg *Config) bool {
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && repoCfg.QuickTake != nil {
+		return *repoCfg.QuickTake
+	}
+	if globalCfg != nil && globalCfg.QuickTake != nil {
+		return *globalCfg.QuickTake
+	}
+	return false
+}
+
+// ResolveReusePatchReviews reports whether a commit whose patch-id matches
+// an already reviewed commit reuses that review: per-repo config, then
+// global config, then on.
+func ResolveReusePatchReviews(repoPath string, globalCfg *Config) bool {
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && repoCfg.ReusePatchReviews != nil {
+		return *repoCfg.ReusePatchReviews
+	}
+	if globalCfg != nil && globalCfg.ReusePatchReviews != nil {
+		return *globalCfg.ReusePatchReviews
+	}
+	return true
+}
+
+// ResolveChecklist returns the checklist reviews must answer: the repo's
+// when it has one, otherwise the global one. Blank items are dropped.
+func ResolveChecklist(repoPath string, globalCfg *Config) []string {
+	var items []string
+	if globalCfg != nil {
+		items = globalCfg.Checklist
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg != nil && len(repoCfg.Checklist) > 0 {
+		items = repoCfg.Checklist
+	}
+	var checklist []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			checklist = append(checklist, item)
+		}
+	}
+	return checklist
+}
+
 // ResolveAgentForWorkflow determines which agent to use based on workflow and level.
 // Priority (Option A - layer wins first, then specificity):
 // 1. CLI explicit
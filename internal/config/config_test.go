@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/roborev-dev/roborev/internal/testenv"
 )
@@ -177,6 +179,68 @@ func TestLoadRepoConfigMissing(t *testing.T) {
 	}
 }
 
+func TestResolveContextFiles(t *testing.T) {
+	if got := ResolveContextFiles(t.TempDir()); got.Mode != "" {
+		t.Errorf("expected context files off without config, got %+v", got)
+	}
+
+	tmpDir := newTempRepo(t, "[context_files]\nmode = \"Symbols\"\n")
+	got := ResolveContextFiles(tmpDir)
+	if got.Mode != "symbols" || got.MaxTokens != DefaultContextFilesMaxTokens {
+		t.Errorf("expected symbols mode with default budget, got %+v", got)
+	}
+
+	tmpDir = newTempRepo(t, "[context_files]\nmode = \"everything\"\nmax_tokens = 100\n")
+	if got := ResolveContextFiles(tmpDir); got.Mode != "" {
+		t.Errorf("expected unknown mode to disable context files, got %+v", got)
+	}
+}
+
+func TestResolveProjectDocs(t *testing.T) {
+	if got := ResolveProjectDocs(t.TempDir()); len(got.Paths) != 0 {
+		t.Errorf("expected no project docs without config, got %+v", got)
+	}
+
+	tmpDir := newTempRepo(t, "[project_docs]\npaths = [\"ARCHITECTURE.md\", \" \", \"docs/style.md\"]\n")
+	got := ResolveProjectDocs(tmpDir)
+	if len(got.Paths) != 2 || got.Paths[1] != "docs/style.md" || got.MaxTokens != DefaultProjectDocsMaxTokens {
+		t.Errorf("expected two docs with default budget, got %+v", got)
+	}
+
+	tmpDir = newTempRepo(t, "[project_docs]\npaths = [\"CONTRIBUTING.md\"]\nmax_tokens = 100\n")
+	if got := ResolveProjectDocs(tmpDir); got.MaxTokens != 100 {
+		t.Errorf("expected configured budget, got %+v", got)
+	}
+}
+
+func TestResolveAutoIssues(t *testing.T) {
+	if got, err := ResolveAutoIssues(t.TempDir()); err != nil || got.Tracker != "" {
+		t.Errorf("expected auto issues off without config, got %+v, %v", got, err)
+	}
+
+	tmpDir := newTempRepo(t, "[auto_issues]\ntracker = \"GitHub\"\n")
+	got, err := ResolveAutoIssues(tmpDir)
+	if err != nil || got.Tracker != "github" || got.MinSeverity != "critical" {
+		t.Errorf("expected github at critical, got %+v, %v", got, err)
+	}
+
+	tmpDir = newTempRepo(t, "[auto_issues]\ntracker = \"jira\"\nproject = \"SEC\"\njira_url = \"https://example.atlassian.net/\"\nmin_severity = \"High\"\n")
+	got, err = ResolveAutoIssues(tmpDir)
+	if err != nil || got.JiraURL != "https://example.atlassian.net" || got.JiraIssueType != "Bug" || got.MinSeverity != "high" {
+		t.Errorf("unexpected jira settings %+v, %v", got, err)
+	}
+
+	for _, toml := range []string{
+		"[auto_issues]\ntracker = \"trello\"\n",
+		"[auto_issues]\ntracker = \"jira\"\nproject = \"SEC\"\n",
+		"[auto_issues]\ntracker = \"github\"\nmin_severity = \"severe\"\n",
+	} {
+		if _, err := ResolveAutoIssues(newTempRepo(t, toml)); err == nil {
+			t.Errorf("expected an error for %q", toml)
+		}
+	}
+}
+
 func TestResolveJobTimeout(t *testing.T) {
 	t.Run("default when no config", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -1512,6 +1576,35 @@ RoboRev-Dev = 222222
 	}
 }
 
+func TestExecutorConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ExecutorConfig
+		wantErr string
+	}{
+		{"default", ExecutorConfig{}, ""},
+		{"local", ExecutorConfig{Type: "local"}, ""},
+		{"docker", ExecutorConfig{Type: "docker", Image: "agents:1"}, ""},
+		{"kubernetes with claim", ExecutorConfig{Type: "kubernetes", Image: "agents:1", VolumeClaim: "repos", MountPath: "/srv/repos"}, ""},
+		{"unknown type", ExecutorConfig{Type: "podman"}, "invalid type"},
+		{"no image", ExecutorConfig{Type: "docker"}, "needs an image"},
+		{"claim without mount path", ExecutorConfig{Type: "kubernetes", Image: "agents:1", VolumeClaim: "repos"}, "mount_path"},
+		{"bad start timeout", ExecutorConfig{Type: "kubernetes", Image: "agents:1", StartTimeout: "soon"}, "start_timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestGitHubAppConfigured_MultiInstall(t *testing.T) {
 	t.Run("configured with map only", func(t *testing.T) {
 		ci := CIConfig{GitHubAppConfig: GitHubAppConfig{
@@ -1727,3 +1820,504 @@ func TestIsDefaultReviewType(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveReviewRotation(t *testing.T) {
+	global := DefaultConfig()
+	global.ReviewRotation = RotationConfig{Agents: []string{"codex:80", "claude-code:20"}}
+
+	t.Run("global used without repo override", func(t *testing.T) {
+		dir := newTempRepo(t, `agent = "codex"`)
+		got := ResolveReviewRotation(dir, global)
+		entries, err := got.Entries()
+		if err != nil {
+			t.Fatalf("Entries: %v", err)
+		}
+		want := []RotationEntry{{"codex", 80}, {"claude-code", 20}}
+		if !reflect.DeepEqual(entries, want) {
+			t.Errorf("got %+v, want %+v", entries, want)
+		}
+	})
+
+	t.Run("repo override replaces global", func(t *testing.T) {
+		dir := newTempRepo(t, "[review_rotation]\nmode = \"alternate\"\nagents = [\"gemini\", \"droid\"]\n")
+		got := ResolveReviewRotation(dir, global)
+		mode, err := got.NormalizedMode()
+		if err != nil || mode != "alternate" {
+			t.Errorf("mode = %q, %v; want alternate", mode, err)
+		}
+		if len(got.Agents) != 2 || got.Agents[0] != "gemini" {
+			t.Errorf("unexpected agents %v", got.Agents)
+		}
+	})
+
+	t.Run("disabled when unset", func(t *testing.T) {
+		if ResolveReviewRotation(t.TempDir(), DefaultConfig()).Enabled() {
+			t.Error("expected rotation disabled by default")
+		}
+	})
+}
+
+func TestResolveBotAuthors(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		cfg := ResolveBotAuthors(t.TempDir(), DefaultConfig())
+		if cfg.Action != "" || cfg.MatchAuthor("dependabot[bot]", "") != "" {
+			t.Errorf("expected disabled, got %+v", cfg)
+		}
+	})
+
+	t.Run("global action uses default patterns", func(t *testing.T) {
+		global := DefaultConfig()
+		global.BotAuthors.Action = "Skip"
+		cfg := ResolveBotAuthors(t.TempDir(), global)
+		if cfg.Action != "skip" || len(cfg.Patterns) != len(DefaultBotAuthorPatterns) {
+			t.Errorf("unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("repo overrides global", func(t *testing.T) {
+		global := DefaultConfig()
+		global.BotAuthors.Action = "skip"
+		dir := newTempRepo(t, `
+[bot_authors]
+action = "downgrade"
+patterns = ["release-*"]
+`)
+		cfg := ResolveBotAuthors(dir, global)
+		if cfg.Action != "downgrade" || len(cfg.Patterns) != 1 || cfg.Patterns[0] != "release-*" {
+			t.Errorf("unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("unknown action disables", func(t *testing.T) {
+		global := DefaultConfig()
+		global.BotAuthors.Action = "ignore"
+		if cfg := ResolveBotAuthors(t.TempDir(), global); cfg.Action != "" {
+			t.Errorf("expected disabled, got %+v", cfg)
+		}
+	})
+}
+
+func TestResolveReviewSLA(t *testing.T) {
+	if got := ResolveReviewSLA(t.TempDir()); got != 0 {
+		t.Errorf("expected no SLA without config, got %v", got)
+	}
+	if got := ResolveReviewSLA(newTempRepo(t, "review_sla_minutes = 30\n")); got != 30*time.Minute {
+		t.Errorf("expected 30m SLA, got %v", got)
+	}
+	if got := ResolveReviewSLA(newTempRepo(t, "review_sla_minutes = -5\n")); got != 0 {
+		t.Errorf("expected negative SLA to be ignored, got %v", got)
+	}
+}
+
+func TestQueueStarvationThreshold(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.QueueStarvationThreshold(); got != DefaultQueueStarvation {
+		t.Errorf("expected default threshold, got %v", got)
+	}
+	cfg.QueueStarvationMinutes = 3
+	if got := cfg.QueueStarvationThreshold(); got != 3*time.Minute {
+		t.Errorf("expected 3m threshold, got %v", got)
+	}
+	cfg.QueueStarvationMinutes = -1
+	if got := cfg.QueueStarvationThreshold(); got != 0 {
+		t.Errorf("expected negative threshold to disable detection, got %v", got)
+	}
+}
+
+func TestMinFreeDisk(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.MinFreeDisk(); got != DefaultMinFreeDisk {
+		t.Errorf("expected default minimum, got %d", got)
+	}
+	cfg.MinFreeDiskMB = 2
+	if got := cfg.MinFreeDisk(); got != 2<<20 {
+		t.Errorf("expected 2 MiB minimum, got %d", got)
+	}
+	cfg.MinFreeDiskMB = -1
+	if got := cfg.MinFreeDisk(); got != 0 {
+		t.Errorf("expected negative minimum to disable the check, got %d", got)
+	}
+}
+
+func TestResolveSampling(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		if cfg := ResolveSampling(t.TempDir(), DefaultConfig()); cfg.Mode != "" {
+			t.Errorf("expected disabled, got %+v", cfg)
+		}
+	})
+
+	t.Run("global every mode defaults N", func(t *testing.T) {
+		global := DefaultConfig()
+		global.Sampling.Mode = "Every"
+		cfg := ResolveSampling(t.TempDir(), global)
+		if cfg.Mode != "every" || cfg.Every != 10 {
+			t.Errorf("unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("repo overrides global", func(t *testing.T) {
+		global := DefaultConfig()
+		global.Sampling = SamplingConfig{Mode: "every", Every: 5}
+		dir := newTempRepo(t, `
+[sampling]
+mode = "risk"
+rate = 0.5
+`)
+		cfg := ResolveSampling(dir, global)
+		if cfg.Mode != "risk" || cfg.Rate != 0.5 {
+			t.Errorf("unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("out of range rate defaults", func(t *testing.T) {
+		global := DefaultConfig()
+		global.Sampling = SamplingConfig{Mode: "risk", Rate: 3}
+		if cfg := ResolveSampling(t.TempDir(), global); cfg.Rate != 0.2 {
+			t.Errorf("expected default rate, got %+v", cfg)
+		}
+	})
+
+	t.Run("unknown mode disables", func(t *testing.T) {
+		global := DefaultConfig()
+		global.Sampling.Mode = "random"
+		if cfg := ResolveSampling(t.TempDir(), global); cfg.Mode != "" {
+			t.Errorf("expected disabled, got %+v", cfg)
+		}
+	})
+}
+
+func TestWorktreePoolLimits(t *testing.T) {
+	if perRepo, total := (WorktreePoolConfig{}).Limits(); perRepo != DefaultWorktreePoolPerRepo || total != DefaultWorktreePoolTotal {
+		t.Errorf("defaults = %d, %d", perRepo, total)
+	}
+	if perRepo, total := (WorktreePoolConfig{PerRepo: 1, Total: 3}).Limits(); perRepo != 1 || total != 3 {
+		t.Errorf("limits = %d, %d, want 1, 3", perRepo, total)
+	}
+}
+
+func TestRetentionConfig(t *testing.T) {
+	if (RetentionConfig{}).Enabled() {
+		t.Error("expected retention to be disabled by default")
+	}
+	cfg := RetentionConfig{MaxAgeDays: 90}
+	if !cfg.Enabled() || cfg.MaxAge() != 90*24*time.Hour {
+		t.Errorf("unexpected policy %+v (max age %v)", cfg, cfg.MaxAge())
+	}
+	cfg = RetentionConfig{KeepPerRepo: 500, MaxAgeDays: -1}
+	if !cfg.Enabled() || cfg.MaxAge() != 0 {
+		t.Errorf("unexpected policy %+v (max age %v)", cfg, cfg.MaxAge())
+	}
+}
+
+func TestResolveCommitGrouping(t *testing.T) {
+	if cfg := ResolveCommitGrouping(t.TempDir(), DefaultConfig()); cfg.Mode != "" {
+		t.Errorf("expected disabled by default, got %+v", cfg)
+	}
+
+	global := DefaultConfig()
+	global.CommitGrouping = CommitGroupingConfig{Mode: "Author", Window: "bogus"}
+	cfg := ResolveCommitGrouping(t.TempDir(), global)
+	if cfg.Mode != "author" || cfg.WindowDuration() != DefaultCommitGroupingWindow {
+		t.Errorf("unexpected global config %+v (window %v)", cfg, cfg.WindowDuration())
+	}
+
+	dir := newTempRepo(t, `
+[commit_grouping]
+mode = "branch"
+window = "30m"
+`)
+	cfg = ResolveCommitGrouping(dir, global)
+	if cfg.Mode != "branch" || cfg.WindowDuration() != 30*time.Minute {
+		t.Errorf("expected the repo to override global, got %+v", cfg)
+	}
+
+	global.CommitGrouping.Mode = "squash"
+	if cfg := ResolveCommitGrouping(t.TempDir(), global); cfg.Mode != "" {
+		t.Errorf("expected an unknown mode to disable grouping, got %+v", cfg)
+	}
+}
+
+func TestResolvePreReview(t *testing.T) {
+	cfg := ResolvePreReview(t.TempDir(), DefaultConfig())
+	if cfg.Agent != "" || cfg.TimeoutDuration() != DefaultPreReviewTimeout {
+		t.Errorf("unexpected default config %+v (timeout %v)", cfg, cfg.TimeoutDuration())
+	}
+
+	global := DefaultConfig()
+	global.PreReview = PreReviewConfig{Agent: "openai", Model: "qwen2.5-coder:7b", Timeout: "3s"}
+	dir := newTempRepo(t, `
+[pre_review]
+model = "llama3.2:3b"
+timeout = "bogus"
+`)
+	cfg = ResolvePreReview(dir, global)
+	if cfg.Agent != "openai" || cfg.Model != "llama3.2:3b" {
+		t.Errorf("expected the repo model to override global, got %+v", cfg)
+	}
+	if cfg.TimeoutDuration() != DefaultPreReviewTimeout {
+		t.Errorf("expected an invalid timeout to fall back to the default, got %v", cfg.TimeoutDuration())
+	}
+}
+
+func TestResolveFocus(t *testing.T) {
+	if cfg := ResolveFocus(t.TempDir(), DefaultConfig()); cfg != (FocusConfig{}) {
+		t.Errorf("unexpected default config %+v", cfg)
+	}
+
+	global := DefaultConfig()
+	global.Focus = FocusConfig{Agent: "claude-code", Model: "sonnet"}
+	dir := newTempRepo(t, `
+[focus]
+model = "opus"
+`)
+	if cfg := ResolveFocus(dir, global); cfg.Agent != "claude-code" || cfg.Model != "opus" {
+		t.Errorf("expected the repo model to override global, got %+v", cfg)
+	}
+}
+
+func TestBotAuthorsMatchAuthor(t *testing.T) {
+	cfg := BotAuthorsConfig{Action: "skip", Patterns: DefaultBotAuthorPatterns}
+	tests := []struct {
+		name, email string
+		want        string
+	}{
+		{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", "*[bot]"},
+		{"Renovate Bot", "bot@renovateapp.com", "renovate*"},
+		{"acme-release-bot", "", "*release-bot*"},
+		{"CI", "41898282+github-actions[bot]@users.noreply.github.com", "*[bot]@*"},
+		{"Jane Doe", "jane@example.com", ""},
+		{"Bob Renovate", "bob@example.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.MatchAuthor(tt.name, tt.email); got != tt.want {
+				t.Errorf("MatchAuthor(%q, %q) = %q, want %q", tt.name, tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*b*c", "axbyc", true},
+		{"a*b*c", "acb", false},
+		{"*x*", "abc", false},
+		{"ab*bc", "abc", false},
+	}
+	for _, tt := range tests {
+		if got := matchWildcard(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFindingValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   string
+		global string
+		want   string
+	}{
+		{"default", "", "", FindingValidationKeep},
+		{"global", "", "flag", FindingValidationFlag},
+		{"repo overrides global", `finding_validation = "drop"`, "flag", FindingValidationDrop},
+		{"case insensitive", `finding_validation = " Flag "`, "", FindingValidationFlag},
+		{"unknown value", `finding_validation = "remove"`, "", FindingValidationKeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTempRepo(t, tt.repo)
+			global := DefaultConfig()
+			global.FindingValidation = tt.global
+			if got := ResolveFindingValidation(dir, global); got != tt.want {
+				t.Errorf("ResolveFindingValidation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveFanOutDiffSize(t *testing.T) {
+	if got := ResolveFanOutDiffSize(t.TempDir(), nil); got != 0 {
+		t.Errorf("expected fan-out disabled by default, got %d", got)
+	}
+	if got := ResolveFanOutDiffSize(t.TempDir(), &Config{FanOutDiffSize: 100000}); got != 100000 {
+		t.Errorf("expected 100000 from global config, got %d", got)
+	}
+	tmpDir := newTempRepo(t, `fanout_diff_size = 50000`)
+	if got := ResolveFanOutDiffSize(tmpDir, &Config{FanOutDiffSize: 100000}); got != 50000 {
+		t.Errorf("expected 50000 from repo config, got %d", got)
+	}
+	tmpDir = newTempRepo(t, `fanout_diff_size = -1`)
+	if got := ResolveFanOutDiffSize(tmpDir, &Config{FanOutDiffSize: 100000}); got != 100000 {
+		t.Errorf("expected negative repo value to fall through to global, got %d", got)
+	}
+}
+
+func TestResolveSuggestCommitMessage(t *testing.T) {
+	on, off := true, false
+	if ResolveSuggestCommitMessage(t.TempDir(), nil) {
+		t.Error("expected commit message suggestions off by default")
+	}
+	if !ResolveSuggestCommitMessage(t.TempDir(), &Config{SuggestCommitMessage: &on}) {
+		t.Error("expected global config to enable suggestions")
+	}
+	tmpDir := newTempRepo(t, `suggest_commit_message = false`)
+	if ResolveSuggestCommitMessage(tmpDir, &Config{SuggestCommitMessage: &on}) {
+		t.Error("expected repo config to disable suggestions over global")
+	}
+	tmpDir = newTempRepo(t, `suggest_commit_message = true`)
+	if !ResolveSuggestCommitMessage(tmpDir, &Config{SuggestCommitMessage: &off}) {
+		t.Error("expected repo config to enable suggestions over global")
+	}
+}
+
+func TestResolveQuickTake(t *testing.T) {
+	on, off := true, false
+	if ResolveQuickTake(t.TempDir(), nil) {
+		t.Error("expected quick takes off by default")
+	}
+	if !ResolveQuickTake(t.TempDir(), &Config{QuickTake: &on}) {
+		t.Error("expected global config to enable quick takes")
+	}
+	tmpDir := newTempRepo(t, `quick_take = false`)
+	if ResolveQuickTake(tmpDir, &Config{QuickTake: &on}) {
+		t.Error("expected repo config to disable quick takes over global")
+	}
+	tmpDir = newTempRepo(t, `quick_take = true`)
+	if !ResolveQuickTake(tmpDir, &Config{QuickTake: &off}) {
+		t.Error("expected repo config to enable quick takes over global")
+	}
+}
+
+func TestResolveReusePatchReviews(t *testing.T) {
+	on, off := true, false
+	if !ResolveReusePatchReviews(t.TempDir(), nil) {
+		t.Error("expected patch review reuse on by default")
+	}
+	if ResolveReusePatchReviews(t.TempDir(), &Config{ReusePatchReviews: &off}) {
+		t.Error("expected global config to disable reuse")
+	}
+	tmpDir := newTempRepo(t, `reuse_patch_reviews = true`)
+	if !ResolveReusePatchReviews(tmpDir, &Config{ReusePatchReviews: &off}) {
+		t.Error("expected repo config to enable reuse over global")
+	}
+	tmpDir = newTempRepo(t, `reuse_patch_reviews = false`)
+	if ResolveReusePatchReviews(tmpDir, &Config{ReusePatchReviews: &on}) {
+		t.Error("expected repo config to disable reuse over global")
+	}
+}
+
+func TestResolveChecklist(t *testing.T) {
+	if got := ResolveChecklist(t.TempDir(), nil); got != nil {
+		t.Errorf("expected no checklist by default, got %v", got)
+	}
+	global := &Config{Checklist: []string{"Tests added?", "  ", "Docs updated?"}}
+	if got := ResolveChecklist(t.TempDir(), global); strings.Join(got, "|") != "Tests added?|Docs updated?" {
+		t.Errorf("expected the global checklist without blanks, got %v", got)
+	}
+	tmpDir := newTempRepo(t, `checklist = ["Migrations backward compatible?"]`)
+	if got := ResolveChecklist(tmpDir, global); strings.Join(got, "|") != "Migrations backward compatible?" {
+		t.Errorf("expected the repo checklist to replace the global one, got %v", got)
+	}
+}
+
+func TestRouteConfigMatches(t *testing.T) {
+	route := RouteConfig{Paths: []string{"services/payments/", "libs/**/billing/*.go", "/Makefile"}}
+	tests := map[string]bool{
+		"services/payments/charge.go":          true,
+		"services/payments/api/v1/refund.go":   true,
+		"services/payments":                    true,
+		"services/payments-legacy/charge.go":   false,
+		"libs/billing/invoice.go":              true,
+		"libs/core/money/billing/invoice.go":   true,
+		"libs/core/money/billing/invoice_test": false,
+		"Makefile":                             true,
+		"docs/Makefile":                        false,
+	}
+	for file, want := range tests {
+		if got := route.Matches(file); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", file, got, want)
+		}
+	}
+	if got := route.ID(); got != "services/payments/" {
+		t.Errorf("expected an unnamed route to be identified by its first path, got %q", got)
+	}
+}
+
+func TestResolveRoutes(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRepoConfigStr(t, repoDir, `
+[[routes]]
+name = "payments"
+paths = ["services/payments/**"]
+
+[[routes]]
+name = "docs"
+paths = ["docs/"]
+`)
+	routes := ResolveRoutes(repoDir, []string{"README.md", "services/payments/charge.go"})
+	if len(routes) != 1 || routes[0].Name != "payments" {
+		t.Errorf("expected only the payments route, got %+v", routes)
+	}
+	if routes := ResolveRoutes(t.TempDir(), []string{"services/payments/charge.go"}); routes != nil {
+		t.Errorf("expected no routes without a repo config, got %+v", routes)
+	}
+}
+
+func TestBranchMatches(t *testing.T) {
+	patterns := []string{"main", "release/*"}
+	tests := map[string]bool{
+		"main":          true,
+		"release/1.0":   true,
+		"release/1.0/x": false,
+		"feature/main":  false,
+	}
+	for branch, want := range tests {
+		if got := BranchMatches(patterns, branch); got != want {
+			t.Errorf("BranchMatches(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestResolveAutoReviewBranches(t *testing.T) {
+	if got := ResolveAutoReviewBranches(t.TempDir(), nil); got != nil {
+		t.Errorf("expected every branch to be reviewed by default, got %v", got)
+	}
+	global := &Config{AutoReviewBranches: []string{"@default"}}
+	if got := ResolveAutoReviewBranches(t.TempDir(), global); strings.Join(got, "|") != "@default" {
+		t.Errorf("expected the global branches, got %v", got)
+	}
+	tmpDir := newTempRepo(t, `auto_review_branches = ["@protected", "feature/*"]`)
+	if got := ResolveAutoReviewBranches(tmpDir, global); strings.Join(got, "|") != "@protected|feature/*" {
+		t.Errorf("expected the repo branches to replace the global ones, got %v", got)
+	}
+}
+
+func TestInteractiveWorkers(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		workers  int
+		want     int
+	}{
+		{0, 4, 0},
+		{0.25, 4, 1},
+		{0.3, 4, 2}, // Rounded up
+		{1, 4, 3},   // At least one worker is left for background jobs
+		{0.5, 1, 0}, // A single worker can't be reserved
+		{-0.5, 4, 0},
+	}
+	for _, tt := range tests {
+		cfg := Config{InteractiveWorkerFraction: tt.fraction}
+		if got := cfg.InteractiveWorkers(tt.workers); got != tt.want {
+			t.Errorf("InteractiveWorkers(%d) with fraction %v = %d, want %d", tt.workers, tt.fraction, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file in a repo's root that suppresses findings
+const IgnoreFileName = ".roborev-ignore"
+
+// ignoreSeverities are the rules that match a finding's severity rather
+// than its text
+var ignoreSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+
+// IgnoreRule is one entry of a .roborev-ignore file. It matches either a
+// finding's fingerprint, or its cited files and rule: a severity, or else
+// a phrase its text contains.
+type IgnoreRule struct {
+	Line          int    `json:"line"`
+	Fingerprint   string `json:"fingerprint,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Rule          string `json:"rule,omitempty"`
+	Justification string `json:"justification"`
+}
+
+// String returns the entry as written in the file
+func (r IgnoreRule) String() string {
+	if r.Fingerprint != "" {
+		return "fingerprint:" + r.Fingerprint
+	}
+	var parts []string
+	if r.Path != "" {
+		parts = append(parts, "path:"+r.Path)
+	}
+	if r.Rule != "" {
+		parts = append(parts, "rule:"+r.Rule)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matches reports whether a finding is suppressed by the rule. A path
+// matches only when every file the finding cites falls under it, so a
+// finding that also touches other code is kept.
+func (r IgnoreRule) Matches(fingerprint, severity, text string, files []string) bool {
+	if r.Fingerprint != "" {
+		return strings.EqualFold(r.Fingerprint, fingerprint)
+	}
+	if r.Path != "" {
+		if len(files) == 0 {
+			return false
+		}
+		for _, f := range files {
+			if !matchRepoPath(r.Path, f) {
+				return false
+			}
+		}
+	}
+	if r.Rule != "" {
+		rule := strings.ToLower(r.Rule)
+		if ignoreSeverities[rule] {
+			return strings.EqualFold(severity, rule)
+		}
+		return strings.Contains(strings.ToLower(text), rule)
+	}
+	return true
+}
+
+// IgnoreFile is a parsed .roborev-ignore file
+type IgnoreFile struct {
+	Rules []IgnoreRule
+}
+
+// Match returns the first rule suppressing a finding, or nil if none does
+func (f *IgnoreFile) Match(fingerprint, severity, text string, files []string) *IgnoreRule {
+	if f == nil {
+		return nil
+	}
+	for i := range f.Rules {
+		if f.Rules[i].Matches(fingerprint, severity, text, files) {
+			return &f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// LoadIgnoreFile reads the .roborev-ignore file in a repo's root. It
+// returns nil without an error when the repo has none. Entries that are
+// invalid or lack a justification are left out and reported in the error,
+// alongside the valid ones.
+func LoadIgnoreFile(repoPath string) (*IgnoreFile, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, IgnoreFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseIgnoreFile(string(data))
+}
+
+// ParseIgnoreFile parses the contents of a .roborev-ignore file. Each entry
+// is a line of the form
+//
+//	fingerprint:<fingerprint>
+//	path:<path> [rule:<rule>]
+//	rule:<rule>
+//
+// and must be justified by a comment on the lines right above it. A comment
+// justifies the entries that follow it up to the next blank line.
+func ParseIgnoreFile(content string) (*IgnoreFile, error) {
+	f := &IgnoreFile{}
+	var errs []error
+	var justification []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			justification = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			if text := strings.TrimSpace(strings.TrimPrefix(line, "#")); text != "" {
+				justification = append(justification, text)
+			}
+			continue
+		}
+
+		rule, err := parseIgnoreEntry(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", IgnoreFileName, n, err))
+			continue
+		}
+		if len(justification) == 0 {
+			errs = append(errs, fmt.Errorf("%s:%d: %q has no justification comment above it", IgnoreFileName, n, line))
+			continue
+		}
+		rule.Line = n
+		rule.Justification = strings.Join(justification, " ")
+		f.Rules = append(f.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return f, errors.Join(errs...)
+}
+
+// parseIgnoreEntry parses one entry line. A rule may contain spaces, so it
+// runs to the end of the line.
+func parseIgnoreEntry(line string) (IgnoreRule, error) {
+	var r IgnoreRule
+	if fp, ok := strings.CutPrefix(line, "fingerprint:"); ok {
+		r.Fingerprint = strings.ToLower(strings.TrimSpace(fp))
+		if r.Fingerprint == "" || strings.Trim(r.Fingerprint, "0123456789abcdef") != "" {
+			return r, fmt.Errorf("invalid fingerprint %q", fp)
+		}
+		return r, nil
+	}
+
+	rest := line
+	if p, ok := strings.CutPrefix(rest, "path:"); ok {
+		r.Path, rest, _ = strings.Cut(strings.TrimSpace(p), " ")
+		rest = strings.TrimSpace(rest)
+		if r.Path == "" {
+			return r, errors.New("empty path")
+		}
+	}
+	if rule, ok := strings.CutPrefix(rest, "rule:"); ok {
+		if r.Rule = strings.TrimSpace(rule); r.Rule == "" {
+			return r, errors.New("empty rule")
+		}
+		rest = ""
+	}
+	if rest != "" {
+		return r, fmt.Errorf("unrecognized entry %q (expected fingerprint:, path: or rule:)", line)
+	}
+	return r, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreFile(t *testing.T) {
+	content := `# Generated code is not ours to fix
+path:internal/gen/**
+
+# Migrations only format constant table names into SQL,
+# checked with the security team
+path:migrations/ rule:SQL injection
+rule:low
+
+fingerprint:3FA2B1C4D5E6F708
+
+# Bad entries
+fingerprint:not-hex
+path:a.go extra
+`
+	f, err := ParseIgnoreFile(content)
+	if err == nil {
+		t.Fatal("expected errors for the invalid entries")
+	}
+	for _, want := range []string{":9: \"fingerprint:3FA2B1C4D5E6F708\" has no justification", ":12: invalid fingerprint", ":13: unrecognized entry"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if len(f.Rules) != 3 {
+		t.Fatalf("expected 3 valid rules, got %+v", f.Rules)
+	}
+	if r := f.Rules[1]; r.Path != "migrations/" || r.Rule != "SQL injection" || r.Line != 6 ||
+		r.Justification != "Migrations only format constant table names into SQL, checked with the security team" {
+		t.Errorf("unexpected rule: %+v", r)
+	}
+	if r := f.Rules[2]; r.String() != "rule:low" || r.Justification != f.Rules[1].Justification {
+		t.Errorf("a comment should justify every entry up to the next blank line, got %+v", r)
+	}
+}
+
+func TestIgnoreFileMatch(t *testing.T) {
+	f, err := ParseIgnoreFile(`# Reviewed
+fingerprint:3fa2b1c4d5e6f708
+path:vendor/
+path:migrations/*.sql rule:injection
+rule:low
+`)
+	if err != nil {
+		t.Fatalf("ParseIgnoreFile: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		severity    string
+		text        string
+		files       []string
+		want        string
+	}{
+		{"fingerprint", "3FA2B1C4D5E6F708", "high", "anything", nil, "fingerprint:3fa2b1c4d5e6f708"},
+		{"path", "", "high", "bug", []string{"vendor/lib/x.go"}, "path:vendor/"},
+		{"path and other code", "", "high", "bug", []string{"vendor/lib/x.go", "main.go"}, ""},
+		{"no files", "", "high", "bug", nil, ""},
+		{"path and rule", "", "high", "SQL Injection risk", []string{"migrations/001.sql"}, "path:migrations/*.sql rule:injection"},
+		{"path without rule", "", "high", "Slow query", []string{"migrations/001.sql"}, ""},
+		{"severity rule", "", "low", "Nit", []string{"main.go"}, "rule:low"},
+		{"no match", "", "medium", "Nit", []string{"main.go"}, ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if r := f.Match(tt.fingerprint, tt.severity, tt.text, tt.files); r != nil {
+			got = r.String()
+		}
+		if got != tt.want {
+			t.Errorf("%s: matched %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	var none *IgnoreFile
+	if none.Match("x", "low", "x", nil) != nil {
+		t.Error("a missing file should match nothing")
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	if f, err := LoadIgnoreFile(dir); f != nil || err != nil {
+		t.Fatalf("expected nothing for a repo without the file, got %+v, %v", f, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("# Vendored\npath:vendor/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadIgnoreFile(dir)
+	if err != nil || len(f.Rules) != 1 || f.Rules[0].Justification != "Vendored" {
+		t.Fatalf("LoadIgnoreFile = %+v, %v", f, err)
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LayoutVersion is the data directory layout this binary uses. Version 1 is
+// the original flat layout, which had no version file. Bump it and add the
+// moves to layoutMoves whenever a file or directory gets a new home.
+//
+// Version 2 layout of DataDir:
+//
+//	config.toml, reviews.db    global config and review database
+//	daemon*.json               runtime info of running daemons
+//	logs/                      daemon logs (errors.log)
+//	artifacts/                 blob store and database backups
+//	mirrors/                   bare clones for remote reviews
+//	prompts/                   user prompt overrides
+//	cache/                     disposable caches (statusline)
+const LayoutVersion = 2
+
+// layoutFile records the layout version in DataDir
+const layoutFile = "layout"
+
+// ErrLayoutTooNew is returned when the data directory was reorganized by a
+// newer roborev than the one running
+var ErrLayoutTooNew = errors.New("data directory layout is newer than this roborev supports")
+
+// layoutDirs are the subdirectories of DataDir in the current layout
+var layoutDirs = []string{"logs", "artifacts", "mirrors", "prompts", "cache"}
+
+// layoutMove relocates a file or directory of an older layout
+type layoutMove struct {
+	from, to string
+}
+
+// layoutMoves lists, for each layout version, the paths moved when
+// upgrading to it from the version before
+var layoutMoves = map[int][]layoutMove{
+	2: {
+		{"errors.log", "logs/errors.log"},
+		{"blobs", "artifacts/blobs"},
+		{"backups", "artifacts/backups"},
+		{"statusline", "cache/statusline"},
+	},
+}
+
+// LogsDir returns the directory for daemon logs
+func LogsDir() string {
+	return filepath.Join(DataDir(), "logs")
+}
+
+// ArtifactsDir returns the directory for stored blobs and backups
+func ArtifactsDir() string {
+	return filepath.Join(DataDir(), "artifacts")
+}
+
+// MirrorsDir returns the directory for bare clones of remote repos
+func MirrorsDir() string {
+	return filepath.Join(DataDir(), "mirrors")
+}
+
+// PromptsDir returns the directory for user prompt overrides
+func PromptsDir() string {
+	return filepath.Join(DataDir(), "prompts")
+}
+
+// CacheDir returns the directory for caches that can be deleted at any time
+func CacheDir() string {
+	return filepath.Join(DataDir(), "cache")
+}
+
+// ReadLayoutVersion returns the layout version of the data directory dir.
+// A directory with no version file is version 1, unless it is missing or
+// empty, in which case it is 0.
+func ReadLayoutVersion(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, layoutFile))
+	if err == nil {
+		v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil || v < 1 {
+			return 0, fmt.Errorf("invalid layout version %q in %s", strings.TrimSpace(string(data)), filepath.Join(dir, layoutFile))
+		}
+		return v, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// UpgradeLayout reorganizes the data directory dir into the current layout
+// and returns the paths it moved, relative to dir. A path whose new home
+// already exists is left where it is for CheckLayout to report. Running it
+// on a current directory only creates missing subdirectories.
+func UpgradeLayout(dir string) ([]string, error) {
+	version, err := ReadLayoutVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	if version > LayoutVersion {
+		return nil, fmt.Errorf("%w (layout v%d, supported v%d)", ErrLayoutTooNew, version, LayoutVersion)
+	}
+
+	for _, sub := range layoutDirs {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every move is retried, not only those of newer versions, so files an
+	// older binary recreated after the upgrade are moved too
+	var moved []string
+	for v := 2; v <= LayoutVersion; v++ {
+		for _, m := range layoutMoves[v] {
+			from := filepath.Join(dir, filepath.FromSlash(m.from))
+			to := filepath.Join(dir, filepath.FromSlash(m.to))
+			if _, err := os.Lstat(from); err != nil {
+				continue
+			}
+			if _, err := os.Lstat(to); err == nil {
+				continue
+			}
+			if err := os.Rename(from, to); err != nil {
+				return moved, fmt.Errorf("move %s to %s: %w", m.from, m.to, err)
+			}
+			moved = append(moved, m.from+" -> "+m.to)
+		}
+	}
+
+	if version != LayoutVersion {
+		data := []byte(strconv.Itoa(LayoutVersion) + "\n")
+		if err := os.WriteFile(filepath.Join(dir, layoutFile), data, 0644); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// LayoutProblem is something in the data directory that does not match the
+// current layout
+type LayoutProblem struct {
+	Path    string // relative to the data directory
+	Problem string
+}
+
+// CheckLayout validates the data directory dir against the current layout
+func CheckLayout(dir string) []LayoutProblem {
+	version, err := ReadLayoutVersion(dir)
+	if err != nil {
+		return []LayoutProblem{{Path: layoutFile, Problem: err.Error()}}
+	}
+	switch {
+	case version == 0:
+		return []LayoutProblem{{Path: ".", Problem: "data directory has not been created"}}
+	case version > LayoutVersion:
+		return []LayoutProblem{{Path: layoutFile, Problem: fmt.Sprintf("layout v%d is newer than this roborev supports (v%d)", version, LayoutVersion)}}
+	}
+
+	var problems []LayoutProblem
+	if version < LayoutVersion {
+		problems = append(problems, LayoutProblem{Path: layoutFile, Problem: fmt.Sprintf("layout v%d needs upgrading to v%d", version, LayoutVersion)})
+	}
+	for _, sub := range layoutDirs {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, LayoutProblem{Path: sub + "/", Problem: "missing"})
+		case err != nil:
+			problems = append(problems, LayoutProblem{Path: sub + "/", Problem: err.Error()})
+		case !info.IsDir():
+			problems = append(problems, LayoutProblem{Path: sub + "/", Problem: "not a directory"})
+		}
+	}
+	for v := 2; v <= LayoutVersion; v++ {
+		for _, m := range layoutMoves[v] {
+			if _, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(m.from))); err == nil {
+				problems = append(problems, LayoutProblem{Path: m.from, Problem: "left from an older layout; belongs in " + m.to})
+			}
+		}
+	}
+	return problems
+}
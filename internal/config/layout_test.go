@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpgradeLayoutMovesFlatLayout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "reviews.db"), []byte("db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "errors.log"), []byte("log"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blobs", "prompts", "1"), []byte("blob"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := ReadLayoutVersion(dir); err != nil || v != 1 {
+		t.Fatalf("ReadLayoutVersion = %d, %v; want 1", v, err)
+	}
+	if problems := CheckLayout(dir); len(problems) == 0 {
+		t.Fatal("expected problems before upgrade")
+	}
+
+	moved, err := UpgradeLayout(dir)
+	if err != nil {
+		t.Fatalf("UpgradeLayout: %v", err)
+	}
+	if len(moved) != 2 {
+		t.Errorf("moved %v, want errors.log and blobs", moved)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "logs", "errors.log")); err != nil || string(data) != "log" {
+		t.Errorf("errors.log not moved: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "artifacts", "blobs", "prompts", "1")); err != nil {
+		t.Errorf("blobs not moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reviews.db")); err != nil {
+		t.Errorf("reviews.db should stay at the root: %v", err)
+	}
+	if v, _ := ReadLayoutVersion(dir); v != LayoutVersion {
+		t.Errorf("layout version = %d, want %d", v, LayoutVersion)
+	}
+	if problems := CheckLayout(dir); len(problems) != 0 {
+		t.Errorf("problems after upgrade: %v", problems)
+	}
+
+	// Upgrading again is a no-op
+	if moved, err := UpgradeLayout(dir); err != nil || len(moved) != 0 {
+		t.Errorf("second UpgradeLayout = %v, %v", moved, err)
+	}
+}
+
+func TestUpgradeLayoutKeepsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := UpgradeLayout(dir); err != nil {
+		t.Fatal(err)
+	}
+	// An older binary recreated errors.log after the upgrade, and the new
+	// log already exists
+	for _, name := range []string{"errors.log", filepath.Join("logs", "errors.log")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if moved, err := UpgradeLayout(dir); err != nil || len(moved) != 0 {
+		t.Fatalf("UpgradeLayout = %v, %v; want nothing moved", moved, err)
+	}
+	problems := CheckLayout(dir)
+	if len(problems) != 1 || problems[0].Path != "errors.log" {
+		t.Errorf("problems = %v, want leftover errors.log", problems)
+	}
+}
+
+func TestUpgradeLayoutRejectsNewer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, layoutFile), []byte("99\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpgradeLayout(dir); err == nil {
+		t.Fatal("expected error for a newer layout")
+	}
+	if problems := CheckLayout(dir); len(problems) != 1 {
+		t.Errorf("problems = %v, want one", problems)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// socketActivationListener returns the listener passed by systemd socket
+// activation (LISTEN_PID/LISTEN_FDS), or nil if the daemon was not
+// socket-activated. Only the first passed socket is used.
+func socketActivationListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds < 1 {
+		return nil, nil
+	}
+
+	// Unset so child processes (agents) don't try to inherit the sockets
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(listenFDsStart), "LISTEN_FD_3")
+	if f == nil {
+		return nil, fmt.Errorf("socket activation: invalid file descriptor %d", listenFDsStart)
+	}
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("socket activation: %w", err)
+	}
+	return ln, nil
+}
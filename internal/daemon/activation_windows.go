@@ -0,0 +1,11 @@
+//go:build windows
+
+package daemon
+
+import "net"
+
+// socketActivationListener always returns nil on Windows, which has no
+// systemd-style socket activation.
+func socketActivationListener() (net.Listener, error) {
+	return nil, nil
+}
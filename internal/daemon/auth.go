@@ -0,0 +1,236 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// reviewerRoutes are the write endpoints open to reviewers: responding to,
+// addressing and triaging reviews. Other writes need an admin.
+var reviewerRoutes = map[string]bool{
+	"/api/comment":          true,
+	"/api/review/address":   true,
+	"/api/triage/decide":    true,
+	"/api/reconcile/decide": true,
+	"/api/share":            true,
+	"/api/share/revoke":     true,
+}
+
+// adminReadRoutes are the read endpoints that need an admin
+var adminReadRoutes = map[string]bool{
+	"/api/tokens": true,
+}
+
+// requiredRole returns the role a request needs: viewer for reads, reviewer
+// for responding to reviews, admin for everything else
+func requiredRole(r *http.Request) string {
+	switch {
+	case adminReadRoutes[r.URL.Path]:
+		return storage.RoleAdmin
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return storage.RoleViewer
+	case reviewerRoutes[r.URL.Path]:
+		return storage.RoleReviewer
+	default:
+		return storage.RoleAdmin
+	}
+}
+
+// isLoopback reports whether a request came from this machine
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// withAuth enforces API token roles on requests from other machines once
+// any token exists ('roborev token create'). Local clients (the CLI, TUI
+// and hooks) are trusted as admins, so a daemon behind a reverse proxy on
+// the same host must not be exposed without its own authentication. Share
+// links carry their own token and are open to everyone. Tokens are kept in
+// the local database, so a daemon on another backend cannot authenticate
+// remote clients and refuses them.
+func (s *Server) withAuth(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isLoopback(r) || strings.HasPrefix(r.URL.Path, sharePathPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if s.db == nil {
+			writeError(w, http.StatusServiceUnavailable, "remote access needs API tokens, which the shared database does not support")
+			return
+		}
+		enforced, err := s.db.HasAPITokens()
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("check tokens: %v", err))
+			return
+		}
+		if !enforced {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || value == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "API token required")
+			return
+		}
+		token, err := s.db.AuthenticateAPIToken(strings.TrimSpace(value))
+		if errors.Is(err, sql.ErrNoRows) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		}
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("authenticate token: %v", err))
+			return
+		}
+		if token.Role == storage.RoleEnqueue {
+			// handleEnqueue checks the repo once it has resolved it
+			if r.Method != http.MethodPost || r.URL.Path != "/api/enqueue" {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("token %q can only enqueue reviews of %s", token.Name, token.Repo))
+				return
+			}
+		} else if required := requiredRole(r); !storage.RoleAllows(token.Role, required) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("token %q has role %s; this needs %s", token.Name, token.Role, required))
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authTokenKey{}, token)))
+	})
+}
+
+// authTokenKey is the context key withAuth stores the request's token under
+type authTokenKey struct{}
+
+// requestToken returns the API token a request was authenticated with, or
+// nil for trusted requests (local clients, or no tokens configured)
+func requestToken(r *http.Request) *storage.APIToken {
+	token, _ := r.Context().Value(authTokenKey{}).(*storage.APIToken)
+	return token
+}
+
+// tokenAllows reports whether a request may do what needs role required.
+// Trusted requests may do anything.
+func tokenAllows(token *storage.APIToken, required string) bool {
+	return token == nil || storage.RoleAllows(token.Role, required)
+}
+
+// enqueueTokenError returns why the enqueue token a request was
+// authenticated with, if any, may not enqueue req for the repo at
+// repoRoot, or "" if it may. Enqueue tokens are limited to their repo and
+// to reviews: custom prompts and agentic jobs need an admin.
+func enqueueTokenError(r *http.Request, req EnqueueRequest, repoRoot string) string {
+	token := requestToken(r)
+	if token == nil || token.Role != storage.RoleEnqueue {
+		return ""
+	}
+	if filepath.Clean(token.Repo) != filepath.Clean(repoRoot) {
+		return fmt.Sprintf("token %q can only enqueue reviews of %s", token.Name, token.Repo)
+	}
+	if req.CustomPrompt != "" || req.Agentic {
+		return fmt.Sprintf("token %q cannot enqueue custom prompts or agentic jobs", token.Name)
+	}
+	return ""
+}
+
+// CreateTokenRequest is the body of POST /api/tokens
+type CreateTokenRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Repo string `json:"repo,omitempty"` // Repo path an enqueue token is limited to
+}
+
+// CreateTokenResponse returns a new token's value, which is shown only once
+type CreateTokenResponse struct {
+	Token string           `json:"token"`
+	Info  storage.APIToken `json:"info"`
+}
+
+// handleTokens lists API tokens (GET) or creates one (POST)
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tokens, err := s.db.ListAPITokens()
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("list tokens: %v", err))
+			return
+		}
+		if tokens == nil {
+			tokens = []storage.APIToken{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+
+	case http.MethodPost:
+		var req CreateTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		var token string
+		var info *storage.APIToken
+		var err error
+		switch {
+		case req.Role == storage.RoleEnqueue:
+			if req.Repo == "" {
+				writeError(w, http.StatusBadRequest, "enqueue tokens need a repo")
+				return
+			}
+			repoRoot, rootErr := git.GetMainRepoRoot(req.Repo)
+			if rootErr != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("not a git repository: %v", rootErr))
+				return
+			}
+			token, info, err = s.db.CreateEnqueueToken(req.Name, repoRoot)
+		case req.Repo != "":
+			writeError(w, http.StatusBadRequest, "only enqueue tokens are limited to a repo")
+			return
+		default:
+			token, info, err = s.db.CreateAPIToken(req.Name, req.Role)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, CreateTokenResponse{Token: token, Info: *info})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleRevokeToken deletes the API token named in the request body
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if err := s.db.DeleteAPIToken(req.Name); errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no token named %q", req.Name))
+		return
+	} else if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("revoke token: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"revoked": req.Name})
+}
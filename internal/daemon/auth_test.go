@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/api/jobs", storage.RoleViewer},
+		{http.MethodGet, "/api/review", storage.RoleViewer},
+		{http.MethodPost, "/api/comment", storage.RoleReviewer},
+		{http.MethodPost, "/api/review/address", storage.RoleReviewer},
+		{http.MethodPost, "/api/share", storage.RoleReviewer},
+		{http.MethodPost, "/api/enqueue", storage.RoleAdmin},
+		{http.MethodPost, "/api/job/cancel", storage.RoleAdmin},
+		{http.MethodPost, "/api/job/bump", storage.RoleAdmin},
+		{http.MethodGet, "/api/ws", storage.RoleViewer},
+		{http.MethodPost, "/api/queue/drain", storage.RoleAdmin},
+		{http.MethodGet, "/api/tokens", storage.RoleAdmin},
+	}
+	for _, tt := range tests {
+		if got := requiredRole(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("requiredRole(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWithAuth(t *testing.T) {
+	server, db, _ := newTestServer(t)
+	handler := server.httpServer.Handler
+
+	do := func(method, path, token, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	const remote = "192.0.2.10:40000"
+
+	// Without tokens the API stays open, as before
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", "", remote), http.StatusOK)
+
+	tokens := map[string]string{}
+	for _, role := range []string{storage.RoleViewer, storage.RoleReviewer, storage.RoleAdmin} {
+		token, _, err := db.CreateAPIToken(role+"-token", role)
+		if err != nil {
+			t.Fatalf("CreateAPIToken: %v", err)
+		}
+		tokens[role] = token
+	}
+
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", "", remote), http.StatusUnauthorized)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", "rbv_bogus", remote), http.StatusUnauthorized)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", tokens[storage.RoleViewer], remote), http.StatusOK)
+
+	// Local clients are trusted
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/tokens", "", "127.0.0.1:50000"), http.StatusOK)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/tokens", "", "[::1]:50000"), http.StatusOK)
+
+	// Viewers cannot respond; reviewers can but cannot manage the queue
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/comment", tokens[storage.RoleViewer], remote), http.StatusForbidden)
+	if w := do(http.MethodPost, "/api/comment", tokens[storage.RoleReviewer], remote); w.Code == http.StatusForbidden || w.Code == http.StatusUnauthorized {
+		t.Errorf("expected reviewer to reach /api/comment, got %d", w.Code)
+	}
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/job/cancel", tokens[storage.RoleReviewer], remote), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/tokens", tokens[storage.RoleReviewer], remote), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/tokens", tokens[storage.RoleAdmin], remote), http.StatusOK)
+	if w := do(http.MethodPost, "/api/job/cancel", tokens[storage.RoleAdmin], remote); w.Code == http.StatusForbidden || w.Code == http.StatusUnauthorized {
+		t.Errorf("expected admin to reach /api/job/cancel, got %d", w.Code)
+	}
+}
+
+func TestWithAuthOtherBackend(t *testing.T) {
+	db, _ := testutil.OpenTestDBWithDir(t)
+	// Tokens in the local database must not be taken as disabling auth,
+	// nor be skipped because the shared database has none
+	if _, _, err := db.CreateAPIToken("admin-token", storage.RoleAdmin); err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	server := NewServer(storeOnly{db}, config.DefaultConfig(), "")
+
+	do := func(method, path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", "192.0.2.10:40000"), http.StatusServiceUnavailable)
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/job/cancel", "192.0.2.10:40000"), http.StatusServiceUnavailable)
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", "127.0.0.1:50000"), http.StatusOK)
+}
+
+func TestWithAuthEnqueueToken(t *testing.T) {
+	server, _, tmpDir := newTestServer(t)
+	handler := server.httpServer.Handler
+	repoA := filepath.Join(tmpDir, "a")
+	repoB := filepath.Join(tmpDir, "b")
+	testutil.InitTestGitRepo(t, repoA)
+	testutil.InitTestGitRepo(t, repoB)
+
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/tokens", CreateTokenRequest{Name: "ci", Role: storage.RoleEnqueue, Repo: repoA})
+	w := httptest.NewRecorder()
+	server.handleTokens(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+	var created CreateTokenResponse
+	testutil.DecodeJSON(t, w, &created)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, strings.NewReader(string(data)))
+		req.RemoteAddr = "192.0.2.10:40000"
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	enqueue := func(repo string, agentic bool) map[string]any {
+		return map[string]any{"repo_path": repo, "git_ref": "HEAD", "agent": "test", "agentic": agentic}
+	}
+
+	testutil.AssertStatusCode(t, do(http.MethodGet, "/api/jobs", nil), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/job/cancel", map[string]int{"job_id": 1}), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/enqueue", enqueue(repoB, false)), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/enqueue", enqueue(repoA, true)), http.StatusForbidden)
+	testutil.AssertStatusCode(t, do(http.MethodPost, "/api/enqueue", enqueue(repoA, false)), http.StatusCreated)
+}
+
+func TestHandleTokens(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/tokens", CreateTokenRequest{Name: "ci", Role: storage.RoleViewer})
+	w := httptest.NewRecorder()
+	server.handleTokens(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+	var created CreateTokenResponse
+	testutil.DecodeJSON(t, w, &created)
+	if !strings.HasPrefix(created.Token, "rbv_") || created.Info.Role != storage.RoleViewer {
+		t.Errorf("unexpected created token %+v", created)
+	}
+
+	for _, bad := range []CreateTokenRequest{
+		{Name: "bad", Role: "owner"},
+		{Name: "bad", Role: storage.RoleEnqueue},
+		{Name: "bad", Role: storage.RoleEnqueue, Repo: t.TempDir()},
+		{Name: "bad", Role: storage.RoleViewer, Repo: t.TempDir()},
+	} {
+		req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/tokens", bad)
+		w = httptest.NewRecorder()
+		server.handleTokens(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	}
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/tokens/revoke", map[string]string{"name": "ci"})
+	w = httptest.NewRecorder()
+	server.handleRevokeToken(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/tokens/revoke", map[string]string{"name": "ci"})
+	w = httptest.NewRecorder()
+	server.handleRevokeToken(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/backup"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// backupCheckInterval is how often the scheduler checks whether a backup
+// is due
+const backupCheckInterval = 5 * time.Minute
+
+// backupScheduler backs up the database whenever the newest backup is
+// older than the configured interval. Settings are read on every check, so
+// enabling backups or changing them takes effect without a restart.
+type backupScheduler struct {
+	db        *storage.DB
+	cfgGetter ConfigGetter
+
+	mu         sync.Mutex
+	started    bool
+	stopCtx    context.Context
+	stopCancel context.CancelFunc
+	doneCh     chan struct{}
+}
+
+func newBackupScheduler(db *storage.DB, cfgGetter ConfigGetter) *backupScheduler {
+	stopCtx, stopCancel := context.WithCancel(context.Background())
+	return &backupScheduler{
+		db:         db,
+		cfgGetter:  cfgGetter,
+		stopCtx:    stopCtx,
+		stopCancel: stopCancel,
+		doneCh:     make(chan struct{}),
+	}
+}
+
+// Start checks for a due backup immediately and then periodically
+func (b *backupScheduler) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		return
+	}
+	b.started = true
+
+	go func() {
+		defer close(b.doneCh)
+		b.runDue(time.Now())
+
+		ticker := time.NewTicker(backupCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stopCtx.Done():
+				return
+			case now := <-ticker.C:
+				b.runDue(now)
+			}
+		}
+	}()
+}
+
+// Stop ends the schedule, waiting for a backup in progress. Safe to call
+// more than once or without Start.
+func (b *backupScheduler) Stop() {
+	b.mu.Lock()
+	started := b.started
+	b.stopCancel()
+	b.mu.Unlock()
+	if started {
+		<-b.doneCh
+	}
+}
+
+// runDue writes a backup if backups are enabled and one is due. Returns the
+// path of the new backup, or "" if none was written.
+func (b *backupScheduler) runDue(now time.Time) string {
+	cfg := b.cfgGetter.Config().Backup
+	if !cfg.Enabled {
+		return ""
+	}
+	if latest := backup.Latest(cfg.BackupDir()); !latest.IsZero() && now.Sub(latest) < cfg.IntervalDuration() {
+		return ""
+	}
+
+	opts, err := backup.OptionsFromConfig(cfg)
+	if err != nil {
+		log.Printf("Backup: %v", err)
+		return ""
+	}
+	file, err := backup.Create(b.stopCtx, b.db, opts)
+	if err != nil {
+		log.Printf("Backup failed: %v", err)
+		if file == "" {
+			return ""
+		}
+	}
+	log.Printf("Backed up database to %s", file)
+	return file
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/backup"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestBackupSchedulerRunDue(t *testing.T) {
+	db, tmpDir := testutil.OpenTestDBWithDir(t)
+	cfg := config.DefaultConfig()
+	cfg.Backup.Dir = filepath.Join(tmpDir, "backups")
+	cfg.Backup.Interval = "1h"
+	cfg.Backup.Keep = 2
+	b := newBackupScheduler(db, NewStaticConfig(cfg))
+
+	if file := b.runDue(time.Now()); file != "" {
+		t.Fatalf("expected no backup while disabled, got %s", file)
+	}
+
+	cfg.Backup.Enabled = true
+	first := b.runDue(time.Now())
+	if first == "" {
+		t.Fatal("expected a backup when none exists")
+	}
+	if file := b.runDue(time.Now().Add(30 * time.Minute)); file != "" {
+		t.Errorf("expected no backup before the interval, got %s", file)
+	}
+
+	// Later checks write new backups once the interval passes, keeping
+	// only the newest two
+	for i := range 3 {
+		// Backup names have one-second resolution
+		time.Sleep(1100 * time.Millisecond)
+		if file := b.runDue(time.Now().Add(time.Duration(i+1) * 2 * time.Hour)); file == "" {
+			t.Fatalf("expected a backup after the interval (check %d)", i)
+		}
+	}
+	files, err := backup.List(cfg.Backup.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 backups after rotation, got %v", files)
+	}
+	for _, f := range files {
+		if f == first {
+			t.Errorf("oldest backup %s should have been pruned", first)
+		}
+	}
+}
+
+func TestBackupSchedulerStopWithoutStart(t *testing.T) {
+	db, _ := testutil.OpenTestDBWithDir(t)
+	b := newBackupScheduler(db, NewStaticConfig(config.DefaultConfig()))
+	b.Stop()
+	b.Stop()
+}
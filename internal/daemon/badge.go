@@ -0,0 +1,167 @@
+package daemon
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// badgeCommitWindow is how many recent branch commits a badge considers.
+const badgeCommitWindow = 50
+
+// Badge colors (shields.io palette)
+const (
+	badgeGreen  = "#4c1"
+	badgeLime   = "#97ca00"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+)
+
+// handleBadge serves an SVG badge summarizing review health for a branch:
+// the verdict of its latest reviewed commit, or the share of recent commits
+// that have been reviewed.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	metric := q.Get("metric")
+	if metric == "" {
+		metric = "verdict"
+	}
+	if metric != "verdict" && metric != "coverage" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid metric %q (valid: verdict, coverage)", metric))
+		return
+	}
+	if q.Get("repo") == "" {
+		writeError(w, http.StatusBadRequest, "repo parameter required")
+		return
+	}
+	repo, err := s.db.FindRepo(r.Context(), q.Get("repo"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+
+	branch := q.Get("branch")
+	if branch == "" {
+		if branch, err = git.GetDefaultBranch(repo.RootPath); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	shas, err := git.GetRecentCommits(repo.RootPath, branch, badgeCommitWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("branch %q: %v", branch, err))
+		return
+	}
+	verdicts, err := s.db.GetCommitVerdicts(repo.ID, shas)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get verdicts: %v", err))
+		return
+	}
+
+	var label, message, color string
+	if metric == "coverage" {
+		label = "reviewed"
+		message, color = coverageBadge(shas, verdicts)
+	} else {
+		label = "roborev"
+		message, color = verdictBadge(shas, verdicts)
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(renderBadge(label, message, color)))
+}
+
+// verdictBadge reports the verdict of the most recent reviewed commit.
+// shas are ordered newest first.
+func verdictBadge(shas []string, verdicts map[string]storage.CommitVerdict) (string, string) {
+	for _, sha := range shas {
+		v, ok := verdicts[sha]
+		if !ok || v.Skipped {
+			continue
+		}
+		switch {
+		case v.Verdict == "P":
+			return "pass", badgeGreen
+		case v.Addressed:
+			return "addressed", badgeLime
+		default:
+			return "fail", badgeRed
+		}
+	}
+	return "unknown", badgeGrey
+}
+
+// coverageBadge reports the percentage of commits that were reviewed.
+// Commits skipped on purpose (skip marker or bot author) are not counted.
+func coverageBadge(shas []string, verdicts map[string]storage.CommitVerdict) (string, string) {
+	var reviewed, eligible int
+	for _, sha := range shas {
+		v, ok := verdicts[sha]
+		if ok && v.Skipped {
+			continue
+		}
+		eligible++
+		if ok {
+			reviewed++
+		}
+	}
+	if eligible == 0 {
+		return "n/a", badgeGrey
+	}
+
+	pct := reviewed * 100 / eligible
+	color := badgeRed
+	switch {
+	case pct >= 90:
+		color = badgeGreen
+	case pct >= 75:
+		color = badgeLime
+	case pct >= 50:
+		color = badgeYellow
+	}
+	return fmt.Sprintf("%d%%", pct), color
+}
+
+// renderBadge draws a flat shields-style badge. Text widths are estimated
+// from character counts, which is close enough for short labels.
+func renderBadge(label, message, color string) string {
+	labelWidth := textWidth(label)
+	messageWidth := textWidth(message)
+	total := labelWidth + messageWidth
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<title>%s: %s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+</g>
+</svg>
+`,
+		total, label, message,
+		label, message,
+		total,
+		labelWidth, labelWidth, messageWidth, color, total,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message)
+}
+
+// textWidth estimates the rendered width of s in 11px Verdana plus padding.
+func textWidth(s string) int {
+	return len(s)*7 + 10
+}
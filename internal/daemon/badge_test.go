@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestVerdictBadge(t *testing.T) {
+	shas := []string{"c3", "c2", "c1"}
+	tests := []struct {
+		name     string
+		verdicts map[string]storage.CommitVerdict
+		want     string
+	}{
+		{"no reviews", nil, "unknown"},
+		{"latest reviewed pass", map[string]storage.CommitVerdict{"c2": {Verdict: "P"}, "c1": {Verdict: "F"}}, "pass"},
+		{"skipped commits ignored", map[string]storage.CommitVerdict{"c3": {Skipped: true}, "c2": {Verdict: "F"}}, "fail"},
+		{"addressed failure", map[string]storage.CommitVerdict{"c3": {Verdict: "F", Addressed: true}}, "addressed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := verdictBadge(shas, tt.verdicts); got != tt.want {
+				t.Errorf("verdictBadge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverageBadge(t *testing.T) {
+	shas := []string{"c4", "c3", "c2", "c1"}
+	verdicts := map[string]storage.CommitVerdict{
+		"c4": {Verdict: "P"},
+		"c3": {Skipped: true},
+		"c2": {Verdict: "F"},
+	}
+	if got, color := coverageBadge(shas, verdicts); got != "66%" || color != badgeYellow {
+		t.Errorf("coverageBadge() = %q %s, want 66%% %s", got, color, badgeYellow)
+	}
+	if got, _ := coverageBadge(nil, nil); got != "n/a" {
+		t.Errorf("coverageBadge(nil) = %q, want n/a", got)
+	}
+}
+
+func TestHandleBadge(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "badgerepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	commitCmd := exec.Command("git", "-C", repoDir, "commit", "--allow-empty", "-m", "second")
+	if out, err := commitCmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, testutil.GetHeadSHA(t, repoDir), "test", "No issues found.")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/badge?"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleBadge(w, req)
+		return w
+	}
+	repoParam := "repo=" + url.QueryEscape(repoDir)
+
+	w := get(repoParam)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("expected SVG content type, got %q", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "roborev: pass") {
+		t.Errorf("expected pass verdict badge, got:\n%s", body)
+	}
+
+	w = get(repoParam + "&metric=coverage")
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	if body := w.Body.String(); !strings.Contains(body, "reviewed: 50%") {
+		t.Errorf("expected 50%% coverage badge, got:\n%s", body)
+	}
+
+	testutil.AssertStatusCode(t, get(repoParam+"&metric=bogus"), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get(repoParam+"&branch=no-such-branch"), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get("repo="+url.QueryEscape(filepath.Join(tmpDir, "missing"))), http.StatusNotFound)
+}
@@ -0,0 +1,223 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// forgeBranchesTTL is how long branches fetched from a forge are trusted
+// before they are fetched again
+const forgeBranchesTTL = 6 * time.Hour
+
+// fetchForgeBranches fetches a repo's default and protected branches from
+// its forge. A variable so tests can stub the forge.
+var fetchForgeBranches = fetchGitHubBranches
+
+// repoBranches returns a repo's default and protected branches: from its
+// .roborev.toml when it sets them, else as last fetched from GitHub, else
+// detected from the clone with the default branch taken as protected.
+// Missing or stale forge data is fetched in the background so enqueues
+// never wait on the API.
+func (s *Server) repoBranches(repo *storage.Repo) storage.RepoBranches {
+	repoCfg, _ := config.LoadRepoConfig(repo.RootPath)
+	if repoCfg != nil && (repoCfg.DefaultBranch != "" || len(repoCfg.ProtectedBranches) > 0) {
+		b := storage.RepoBranches{
+			DefaultBranch: repoCfg.DefaultBranch,
+			Protected:     repoCfg.ProtectedBranches,
+			Source:        storage.BranchSourceConfig,
+		}
+		if b.DefaultBranch == "" {
+			b.DefaultBranch = detectDefaultBranch(repo.RootPath)
+		}
+		if len(b.Protected) == 0 && b.DefaultBranch != "" {
+			b.Protected = []string{b.DefaultBranch}
+		}
+		s.saveRepoBranches(repo, b)
+		return b
+	}
+
+	var stored *storage.RepoBranches
+	if s.db != nil {
+		var err error
+		if stored, err = s.db.GetRepoBranches(repo.ID); err != nil {
+			log.Printf("Warning: load branches of %s: %v", repo.Name, err)
+		}
+	}
+	if stored != nil && stored.Source == storage.BranchSourceGitHub {
+		if time.Since(stored.UpdatedAt) > forgeBranchesTTL {
+			s.refreshRepoBranches(repo)
+		}
+		return *stored
+	}
+
+	b := storage.RepoBranches{DefaultBranch: detectDefaultBranch(repo.RootPath), Source: storage.BranchSourceGit}
+	if b.DefaultBranch != "" {
+		b.Protected = []string{b.DefaultBranch}
+	}
+	s.saveRepoBranches(repo, b)
+	s.refreshRepoBranches(repo)
+	return b
+}
+
+// saveRepoBranches records branches unless they are already recorded.
+// Only the local database records them.
+func (s *Server) saveRepoBranches(repo *storage.Repo, b storage.RepoBranches) {
+	if s.db == nil {
+		return
+	}
+	if stored, _ := s.db.GetRepoBranches(repo.ID); stored != nil && stored.Source == b.Source &&
+		stored.DefaultBranch == b.DefaultBranch && slices.Equal(stored.Protected, b.Protected) {
+		return
+	}
+	if err := s.db.SetRepoBranches(repo.ID, b); err != nil {
+		log.Printf("Warning: save branches of %s: %v", repo.Name, err)
+	}
+}
+
+// refreshRepoBranches fetches a repo's branches from its forge in the
+// background, unless a fetch is already running or there is no local
+// database to record them in
+func (s *Server) refreshRepoBranches(repo *storage.Repo) {
+	if s.db == nil || !isGitHubRemote(git.GetRemoteURL(repo.RootPath, "origin")) {
+		return
+	}
+	if _, running := s.branchRefreshes.LoadOrStore(repo.ID, true); running {
+		return
+	}
+	go func() {
+		defer s.branchRefreshes.Delete(repo.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		b, err := fetchForgeBranches(ctx, repo.RootPath)
+		if err != nil {
+			log.Printf("Warning: fetch branches of %s from GitHub: %v", repo.Name, err)
+			return
+		}
+		if err := s.db.SetRepoBranches(repo.ID, b); err != nil {
+			log.Printf("Warning: save branches of %s: %v", repo.Name, err)
+		}
+	}()
+}
+
+// detectDefaultBranch returns the clone's default branch without its
+// remote prefix, or "" if it cannot be told
+func detectDefaultBranch(repoPath string) string {
+	branch, err := git.GetDefaultBranch(repoPath)
+	if err != nil {
+		return ""
+	}
+	return git.LocalBranchName(branch)
+}
+
+// isGitHubRemote reports whether a remote URL points at github.com
+func isGitHubRemote(url string) bool {
+	return strings.Contains(url, "github.com:") || strings.Contains(url, "github.com/")
+}
+
+// fetchGitHubBranches fetches a repo's default and protected branches with
+// the gh CLI, which resolves the GitHub repo from the clone's remote
+func fetchGitHubBranches(ctx context.Context, repoPath string) (storage.RepoBranches, error) {
+	b := storage.RepoBranches{Source: storage.BranchSourceGitHub}
+	out, err := ghAPI(ctx, repoPath, "repos/{owner}/{repo}", "--jq", ".default_branch")
+	if err != nil {
+		return b, err
+	}
+	b.DefaultBranch = strings.TrimSpace(out)
+
+	out, err = ghAPI(ctx, repoPath, "repos/{owner}/{repo}/branches?protected=true&per_page=100",
+		"--paginate", "--jq", ".[].name")
+	if err != nil {
+		return b, err
+	}
+	b.Protected = strings.Fields(out)
+	return b, nil
+}
+
+// ghAPI runs gh api in a repo and returns its output
+func ghAPI(ctx context.Context, repoPath, endpoint string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gh", append([]string{"api", endpoint}, args...)...)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("gh api %s: %s", endpoint, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("gh api %s: %w", endpoint, err)
+	}
+	return string(out), nil
+}
+
+// autoReviewed reports whether commit hooks review a branch, given the
+// branches configured for auto review. The keywords @default and
+// @protected stand for the repo's tracked branches.
+func autoReviewed(patterns []string, branch string, b storage.RepoBranches) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		switch p {
+		case config.BranchKeywordDefault:
+			if branch == b.DefaultBranch {
+				return true
+			}
+		case config.BranchKeywordProtected:
+			if config.BranchMatches(b.Protected, branch) {
+				return true
+			}
+		default:
+			if config.BranchMatches([]string{p}, branch) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// onProtectedBranch reports whether a commit is on one of the repo's
+// protected branches: enqueued from one, or already contained in one (for
+// branches named literally rather than by a glob)
+func onProtectedBranch(repoPath, branch, sha string, b storage.RepoBranches) bool {
+	if branch != "" && config.BranchMatches(b.Protected, branch) {
+		return true
+	}
+	for _, p := range b.Protected {
+		if strings.ContainsAny(p, "*?[") {
+			continue
+		}
+		for _, ref := range []string{"refs/heads/" + p, "refs/remotes/origin/" + p} {
+			if ok, err := git.IsAncestor(repoPath, sha, ref); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commitPriority returns the queue priority of a single-commit review: the
+// commit at the head of its branch (HEAD when the branch is unknown) is
+// reviewed before other work, and a commit behind it, such as backfilled
+// history, after. Commits whose branch cannot be resolved get normal
+// priority.
+func commitPriority(repoPath, branch, sha string) int {
+	ref := "HEAD"
+	if branch != "" && branch != "HEAD" {
+		ref = "refs/heads/" + branch
+	}
+	head, err := git.ResolveSHA(repoPath, ref)
+	if err != nil {
+		return storage.PriorityNormal
+	}
+	if head == sha {
+		return storage.PriorityHigh
+	}
+	return storage.PriorityLow
+}
@@ -0,0 +1,180 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestAutoReviewed(t *testing.T) {
+	b := storage.RepoBranches{DefaultBranch: "main", Protected: []string{"main", "release/*"}}
+	tests := []struct {
+		patterns []string
+		branch   string
+		want     bool
+	}{
+		{nil, "feature/x", true},
+		{[]string{"@default"}, "main", true},
+		{[]string{"@default"}, "release/1.0", false},
+		{[]string{"@protected"}, "release/1.0", true},
+		{[]string{"@protected"}, "feature/x", false},
+		{[]string{"@default", "feature/*"}, "feature/x", true},
+		{[]string{"develop"}, "main", false},
+	}
+	for _, tt := range tests {
+		if got := autoReviewed(tt.patterns, tt.branch, b); got != tt.want {
+			t.Errorf("autoReviewed(%v, %q) = %v, want %v", tt.patterns, tt.branch, got, tt.want)
+		}
+	}
+}
+
+// initBranchRepo creates a git repo whose default branch is main
+func initBranchRepo(t *testing.T, dir string) func(args ...string) {
+	t.Helper()
+	testutil.InitTestGitRepo(t, dir)
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("branch", "-M", "main")
+	return git
+}
+
+func TestHandleEnqueueAutoReviewBranches(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	git := initBranchRepo(t, repoDir)
+	toml := "auto_review_branches = [\"@protected\", \"feature/*\"]\nprotected_branches = [\"main\", \"release/*\"]\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	enqueue := func(branch string, hook bool) map[string]any {
+		t.Helper()
+		git("commit", "--allow-empty", "-m", "change on "+branch)
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]any{
+			"repo_path": repoDir, "git_ref": "HEAD", "branch": branch, "agent": "test", "hook": hook,
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		var resp map[string]any
+		testutil.DecodeJSON(t, w, &resp)
+		return resp
+	}
+
+	if resp := enqueue("main", true); resp["protected"] != true {
+		t.Errorf("commit on main should be queued and marked protected, got %v", resp)
+	}
+	git("checkout", "-q", "-b", "feature/login")
+	if resp := enqueue("feature/login", true); resp["skipped"] == true || resp["protected"] == true {
+		t.Errorf("feature commit should be queued unprotected, got %v", resp)
+	}
+	git("checkout", "-q", "-b", "scratch")
+	if resp := enqueue("scratch", true); resp["skipped"] != true {
+		t.Errorf("hook commit on an unselected branch should be skipped, got %v", resp)
+	}
+	// Explicit reviews are not limited to the auto-reviewed branches
+	if resp := enqueue("scratch", false); resp["skipped"] == true || resp["id"] == nil {
+		t.Errorf("explicit review should be queued, got %v", resp)
+	}
+
+	repo, err := db.GetRepoByPath(t.Context(), repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := db.GetRepoBranches(repo.ID)
+	if err != nil || b == nil {
+		t.Fatalf("branches should be recorded: %v, %v", b, err)
+	}
+	if b.Source != storage.BranchSourceConfig || b.DefaultBranch != "main" || len(b.Protected) != 2 {
+		t.Errorf("unexpected recorded branches %+v", b)
+	}
+}
+
+func TestOnProtectedBranchContainedCommit(t *testing.T) {
+	dir := t.TempDir()
+	initBranchRepo(t, dir)
+	sha := testutil.GetHeadSHA(t, dir)
+	b := storage.RepoBranches{DefaultBranch: "main", Protected: []string{"main"}}
+
+	// Reviewed from a feature branch, but already merged into main
+	if !onProtectedBranch(dir, "feature/x", sha, b) {
+		t.Error("commit contained in main should be on a protected branch")
+	}
+	if onProtectedBranch(dir, "feature/x", sha, storage.RepoBranches{Protected: []string{"release/*"}}) {
+		t.Error("globs only match the branch a commit was enqueued from")
+	}
+}
+
+func TestCommitPriority(t *testing.T) {
+	dir := t.TempDir()
+	git := initBranchRepo(t, dir)
+	old := testutil.GetHeadSHA(t, dir)
+	git("commit", "--allow-empty", "-m", "second")
+	head := testutil.GetHeadSHA(t, dir)
+
+	if got := commitPriority(dir, "main", head); got != storage.PriorityHigh {
+		t.Errorf("head of main: priority %d, want %d", got, storage.PriorityHigh)
+	}
+	if got := commitPriority(dir, "", head); got != storage.PriorityHigh {
+		t.Errorf("HEAD without a branch: priority %d, want %d", got, storage.PriorityHigh)
+	}
+	if got := commitPriority(dir, "main", old); got != storage.PriorityLow {
+		t.Errorf("history of main: priority %d, want %d", got, storage.PriorityLow)
+	}
+	if got := commitPriority(dir, "gone", head); got != storage.PriorityNormal {
+		t.Errorf("unknown branch: priority %d, want %d", got, storage.PriorityNormal)
+	}
+}
+
+func TestRepoBranchesFromGitHub(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	git := initBranchRepo(t, repoDir)
+	git("remote", "add", "origin", "https://github.com/acme/app.git")
+
+	fetched := make(chan struct{}, 1)
+	orig := fetchForgeBranches
+	fetchForgeBranches = func(ctx context.Context, repoPath string) (storage.RepoBranches, error) {
+		defer func() { fetched <- struct{}{} }()
+		return storage.RepoBranches{DefaultBranch: "trunk", Protected: []string{"trunk", "stable"}, Source: storage.BranchSourceGitHub}, nil
+	}
+	t.Cleanup(func() { fetchForgeBranches = orig })
+
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Until GitHub answers, branches are detected from the clone
+	if b := server.repoBranches(repo); b.Source != storage.BranchSourceGit || b.DefaultBranch != "main" {
+		t.Errorf("expected detected branches, got %+v", b)
+	}
+	testutil.ReceiveWithTimeout(t, fetched, 5*time.Second)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b := server.repoBranches(repo)
+		if b.Source == storage.BranchSourceGitHub {
+			if b.DefaultBranch != "trunk" || len(b.Protected) != 2 {
+				t.Errorf("unexpected GitHub branches %+v", b)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("GitHub branches were not recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
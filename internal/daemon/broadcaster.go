@@ -8,16 +8,19 @@ import (
 
 // Event represents a review event that can be broadcast
 type Event struct {
-	Type     string    `json:"type"`
-	TS       time.Time `json:"ts"`
-	JobID    int64     `json:"job_id"`
-	Repo     string    `json:"repo"`
-	RepoName string    `json:"repo_name"`
-	SHA      string    `json:"sha"`
-	Agent    string    `json:"agent,omitempty"`
-	Verdict  string    `json:"verdict,omitempty"`
-	Findings string    `json:"findings,omitempty"`
-	Error    string    `json:"error,omitempty"`
+	Type      string    `json:"type"`
+	TS        time.Time `json:"ts"`
+	JobID     int64     `json:"job_id"`
+	Repo      string    `json:"repo"`
+	RepoName  string    `json:"repo_name"`
+	SHA       string    `json:"sha"`
+	Agent     string    `json:"agent,omitempty"`
+	Verdict   string    `json:"verdict,omitempty"`
+	Findings  string    `json:"findings,omitempty"`
+	QuickTake string    `json:"quick_take,omitempty"` // Review's short summary of itself, when quick_take is enabled
+	Error     string    `json:"error,omitempty"`
+	Routes    []string  `json:"routes,omitempty"` // Monorepo routes the job's changes matched
+	Owners    []string  `json:"owners,omitempty"` // Owners of those routes
 }
 
 // Subscriber represents a client subscribed to events
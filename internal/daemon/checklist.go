@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// defaultChecklistPeriod is how far back checklist summaries look by default
+const defaultChecklistPeriod = 30 * 24 * time.Hour
+
+// ChecklistSummaryResponse is returned by GET /api/checklist?repo=
+type ChecklistSummaryResponse struct {
+	Repo  string                         `json:"repo"`
+	Since time.Time                      `json:"since"`
+	Items []storage.ChecklistItemSummary `json:"items"`
+}
+
+// handleChecklist returns a job's checklist answers (job_id parameter) or
+// the tally of a repo's answers per item (repo parameter, with since as
+// RFC3339, default 30 days ago)
+func (s *Server) handleChecklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	if v := q.Get("job_id"); v != "" {
+		jobID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid job_id")
+			return
+		}
+		results, err := s.db.GetChecklistResults(jobID)
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("get checklist results: %v", err))
+			return
+		}
+		if results == nil {
+			results = []storage.ChecklistResult{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"job_id": jobID, "results": results})
+		return
+	}
+
+	repoPath := q.Get("repo")
+	if repoPath == "" {
+		writeError(w, http.StatusBadRequest, "job_id or repo is required")
+		return
+	}
+	since := time.Now().Add(-defaultChecklistPeriod)
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since (want RFC3339)")
+			return
+		}
+		since = t
+	}
+	repo, err := s.db.FindRepo(r.Context(), repoPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+	items, err := s.db.GetChecklistSummary(repo.ID, since)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get checklist summary: %v", err))
+		return
+	}
+	if items == nil {
+		items = []storage.ChecklistItemSummary{}
+	}
+	writeJSON(w, http.StatusOK, ChecklistSummaryResponse{Repo: repo.RootPath, Since: since, Items: items})
+}
@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleChecklist(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/checklist-repo")
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "clsha", "Author", "wip", time.Now())
+	if err != nil {
+		t.Fatalf("GetOrCreateCommit: %v", err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "clsha", Agent: "test"})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	results := []storage.ChecklistResult{
+		{Index: 0, Item: "Tests added?", Result: storage.ChecklistFail, Note: "none"},
+		{Index: 1, Item: "Docs updated?", Result: storage.ChecklistPass},
+	}
+	if err := db.SaveChecklistResults(job.ID, results); err != nil {
+		t.Fatalf("SaveChecklistResults: %v", err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/checklist"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleChecklist(w, req)
+		return w
+	}
+
+	testutil.AssertStatusCode(t, get(""), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get("?job_id=x"), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get("?repo=/tmp/no-such-repo"), http.StatusNotFound)
+	testutil.AssertStatusCode(t, get("?repo=/tmp/checklist-repo&since=yesterday"), http.StatusBadRequest)
+
+	t.Run("job", func(t *testing.T) {
+		w := get(fmt.Sprintf("?job_id=%d", job.ID))
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var got struct {
+			Results []storage.ChecklistResult `json:"results"`
+		}
+		testutil.DecodeJSON(t, w, &got)
+		if len(got.Results) != 2 || got.Results[0] != results[0] || got.Results[1] != results[1] {
+			t.Errorf("unexpected results %+v", got.Results)
+		}
+	})
+
+	t.Run("repo", func(t *testing.T) {
+		w := get("?repo=/tmp/checklist-repo")
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var got ChecklistSummaryResponse
+		testutil.DecodeJSON(t, w, &got)
+		want := []storage.ChecklistItemSummary{
+			{Item: "Tests added?", Fail: 1},
+			{Item: "Docs updated?", Pass: 1},
+		}
+		if len(got.Items) != 2 || got.Items[0] != want[0] || got.Items[1] != want[1] {
+			t.Errorf("unexpected summary %+v", got.Items)
+		}
+	})
+}
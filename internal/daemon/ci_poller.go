@@ -233,7 +233,7 @@ func (p *CIPoller) processPR(ctx context.Context, ghRepo string, pr ghPR, cfg *c
 	}
 
 	// Find local repo matching this GitHub repo
-	repo, err := p.findLocalRepo(ghRepo)
+	repo, err := p.findLocalRepo(ctx, ghRepo)
 	if err != nil {
 		return fmt.Errorf("find local repo for %s: %w", ghRepo, err)
 	}
@@ -361,7 +361,7 @@ func (p *CIPoller) processPR(ctx context.Context, ghRepo string, pr ghPR, cfg *c
 				return fmt.Errorf("get batch job IDs: %w", err)
 			}
 			for _, jid := range jobIDs {
-				if err := p.db.CancelJob(jid); err != nil {
+				if err := p.db.CancelJob(ctx, jid); err != nil {
 					if errors.Is(err, sql.ErrNoRows) {
 						continue // already terminal
 					}
@@ -392,7 +392,7 @@ func (p *CIPoller) processPR(ctx context.Context, ghRepo string, pr ghPR, cfg *c
 	var createdJobIDs []int64
 	rollback := func() {
 		for _, jid := range createdJobIDs {
-			if err := p.db.CancelJob(jid); err != nil {
+			if err := p.db.CancelJob(ctx, jid); err != nil {
 				log.Printf("CI poller: failed to cancel orphan job %d: %v", jid, err)
 			}
 		}
@@ -428,7 +428,7 @@ func (p *CIPoller) processPR(ctx context.Context, ghRepo string, pr ghPR, cfg *c
 			// Resolve model through workflow config when not explicitly set
 			resolvedModel := config.ResolveModelForWorkflow(cfg.CI.Model, repo.RootPath, cfg, workflow, reasoning)
 
-			job, err := p.db.EnqueueJob(storage.EnqueueOpts{
+			job, err := p.db.EnqueueJob(ctx, storage.EnqueueOpts{
 				RepoID:     repo.ID,
 				GitRef:     gitRef,
 				Agent:      resolvedAgent,
@@ -465,7 +465,7 @@ func (p *CIPoller) processPR(ctx context.Context, ghRepo string, pr ghPR, cfg *c
 // findLocalRepo finds the local repo that corresponds to a GitHub "owner/repo" identifier.
 // It looks for repos whose identity contains the owner/repo pattern.
 // Matching is case-insensitive since GitHub owner/repo names are case-insensitive.
-func (p *CIPoller) findLocalRepo(ghRepo string) (*storage.Repo, error) {
+func (p *CIPoller) findLocalRepo(ctx context.Context, ghRepo string) (*storage.Repo, error) {
 	// Try common identity patterns (case-insensitive via DB query):
 	// - git@github.com:owner/repo.git
 	// - https://github.com/owner/repo.git
@@ -478,7 +478,7 @@ func (p *CIPoller) findLocalRepo(ghRepo string) (*storage.Repo, error) {
 	}
 
 	for _, pattern := range patterns {
-		repo, err := p.db.GetRepoByIdentityCaseInsensitive(pattern)
+		repo, err := p.db.GetRepoByIdentityCaseInsensitive(ctx, pattern)
 		if err != nil {
 			// Propagate ambiguity errors (e.g., multiple repos with same identity)
 			if strings.Contains(err.Error(), "multiple repos") {
@@ -668,7 +668,7 @@ func (p *CIPoller) handleReviewCompleted(event Event) {
 	}
 
 	// Get the full review output
-	review, err := p.db.GetReviewByJobID(event.JobID)
+	review, err := p.db.GetReviewByJobID(context.Background(), event.JobID)
 	if err != nil {
 		log.Printf("CI poller: error getting review for job %d: %v", event.JobID, err)
 		return
@@ -809,7 +809,7 @@ func (p *CIPoller) postBatchResults(batch *storage.CIPRBatch) {
 
 	if batch.TotalJobs == 1 && successCount == 1 {
 		// Single job batch — use legacy format (no synthesis needed)
-		review, err := p.db.GetReviewByJobID(reviews[0].JobID)
+		review, err := p.db.GetReviewByJobID(context.Background(), reviews[0].JobID)
 		if err != nil {
 			log.Printf("CI poller: error getting review for job %d: %v", reviews[0].JobID, err)
 			p.unclaimBatch(batch.ID)
@@ -866,7 +866,7 @@ func (p *CIPoller) resolveRepoForBatch(batch *storage.CIPRBatch) *storage.Repo {
 	if p.db == nil || batch.GithubRepo == "" {
 		return nil
 	}
-	repo, err := p.findLocalRepo(batch.GithubRepo)
+	repo, err := p.findLocalRepo(context.Background(), batch.GithubRepo)
 	if err != nil {
 		log.Printf("CI poller: could not resolve local repo for %s: %v (per-repo overrides will not apply)", batch.GithubRepo, err)
 		return nil
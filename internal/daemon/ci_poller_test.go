@@ -28,7 +28,7 @@ func newCIPollerHarness(t *testing.T, identity string) *ciPollerHarness {
 	t.Helper()
 	db := testutil.OpenTestDB(t)
 	repoPath := t.TempDir()
-	repo, err := db.GetOrCreateRepo(repoPath, identity)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath, identity)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -55,7 +55,7 @@ func (h *ciPollerHarness) seedBatchJob(t *testing.T, ghRepo string, prNum int, h
 	if err != nil {
 		t.Fatalf("CreateCIBatch: %v", err)
 	}
-	job, err := h.DB.EnqueueJob(storage.EnqueueOpts{
+	job, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{
 		RepoID: h.Repo.ID, GitRef: gitRef, Agent: agent, ReviewType: reviewType,
 	})
 	if err != nil {
@@ -349,7 +349,7 @@ func TestCIPollerProcessPR_EnqueuesMatrix(t *testing.T) {
 		t.Fatal("expected CI batch to be created")
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha-999..head-sha-123"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha-999..head-sha-123"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
@@ -443,7 +443,7 @@ func TestCIPollerHandleBatchJobDone_PartialBatchDoesNotPost(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreateCIBatch: %v", err)
 	}
-	job, err := h.DB.EnqueueJob(storage.EnqueueOpts{RepoID: h.Repo.ID, GitRef: "a..b", Agent: "codex", ReviewType: "security"})
+	job, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: h.Repo.ID, GitRef: "a..b", Agent: "codex", ReviewType: "security"})
 	if err != nil {
 		t.Fatalf("EnqueueJob: %v", err)
 	}
@@ -530,7 +530,7 @@ func TestCIPollerReconcileStaleBatches_PostsCanceledJobsAsFailed(t *testing.T) {
 func TestCIPollerHandleReviewCompleted_LegacyCIReviewPostsComment(t *testing.T) {
 	h := newCIPollerHarness(t, "git@github.com:acme/api.git")
 
-	job, err := h.DB.EnqueueJob(storage.EnqueueOpts{
+	job, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{
 		RepoID: h.Repo.ID, GitRef: "a..b", Agent: "codex", ReviewType: "security",
 	})
 	if err != nil {
@@ -586,13 +586,13 @@ func TestCIPollerPostBatchResults_SynthesisPathUsesMock(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreateCIBatch: %v", err)
 	}
-	job1, err := h.DB.EnqueueJob(storage.EnqueueOpts{
+	job1, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{
 		RepoID: h.Repo.ID, GitRef: "a..b", Agent: "codex", ReviewType: "security",
 	})
 	if err != nil {
 		t.Fatalf("EnqueueJob job1: %v", err)
 	}
-	job2, err := h.DB.EnqueueJob(storage.EnqueueOpts{
+	job2, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{
 		RepoID: h.Repo.ID, GitRef: "a..b", Agent: "gemini", ReviewType: "review",
 	})
 	if err != nil {
@@ -647,7 +647,7 @@ func TestCIPollerPostBatchResults_PostFailureUnclaimsBatch(t *testing.T) {
 func TestCIPollerFindLocalRepo_PartialIdentityFallback(t *testing.T) {
 	h := newCIPollerHarness(t, "ssh://git@github.com/acme/api.git")
 
-	found, err := h.Poller.findLocalRepo("acme/api")
+	found, err := h.Poller.findLocalRepo(t.Context(), "acme/api")
 	if err != nil {
 		t.Fatalf("findLocalRepo: %v", err)
 	}
@@ -671,7 +671,7 @@ func TestCIPollerFindLocalRepo_SkipsPlaceholders(t *testing.T) {
 	p := NewCIPoller(db, NewStaticConfig(cfg), nil)
 
 	// With only a placeholder, should get "no local repo found"
-	_, err = p.findLocalRepo("acme/api")
+	_, err = p.findLocalRepo(t.Context(), "acme/api")
 	if err == nil {
 		t.Fatal("expected error when only placeholder exists")
 	}
@@ -681,12 +681,12 @@ func TestCIPollerFindLocalRepo_SkipsPlaceholders(t *testing.T) {
 
 	// Add a real local checkout — should find it and skip the placeholder
 	repoPath := t.TempDir()
-	repo, err := db.GetOrCreateRepo(repoPath, identity)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath, identity)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
 
-	found, err := p.findLocalRepo("acme/api")
+	found, err := p.findLocalRepo(t.Context(), "acme/api")
 	if err != nil {
 		t.Fatalf("findLocalRepo with real repo: %v", err)
 	}
@@ -717,7 +717,7 @@ func TestCIPollerProcessPR_WhitespaceReasoning(t *testing.T) {
 		t.Fatalf("processPR: %v", err)
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..whitespace-reasoning-sha"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..whitespace-reasoning-sha"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
@@ -748,7 +748,7 @@ func TestCIPollerProcessPR_InvalidReasoning(t *testing.T) {
 		t.Fatalf("processPR: %v", err)
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..invalid-reasoning-sha"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..invalid-reasoning-sha"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
@@ -862,7 +862,7 @@ func TestCIPollerProcessPR_DesignReviewType(t *testing.T) {
 		t.Fatalf("processPR: %v", err)
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..design-head"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..design-head"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
@@ -891,7 +891,7 @@ func TestCIPollerProcessPR_AliasDeduplication(t *testing.T) {
 		t.Fatalf("processPR: %v", err)
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..dedup-head"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..dedup-head"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
@@ -930,7 +930,7 @@ func TestCIPollerProcessPR_IncompleteBatchRecovery(t *testing.T) {
 			t.Fatalf("processPR: %v", err)
 		}
 
-		jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..head-sha-50"))
+		jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..head-sha-50"))
 		if err != nil {
 			t.Fatalf("ListJobs: %v", err)
 		}
@@ -966,7 +966,7 @@ func TestCIPollerProcessPR_IncompleteBatchRecovery(t *testing.T) {
 			t.Error("batch with old updated_at should be stale")
 		}
 
-		job, err := h.DB.EnqueueJob(storage.EnqueueOpts{
+		job, err := h.DB.EnqueueJob(t.Context(), storage.EnqueueOpts{
 			RepoID: h.Repo.ID, GitRef: "a..b", Agent: "codex", ReviewType: "security",
 		})
 		if err != nil {
@@ -1015,7 +1015,7 @@ func TestCIPollerProcessPR_IncompleteBatchRecovery(t *testing.T) {
 			t.Fatalf("processPR: %v", err)
 		}
 
-		jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..head-sha-52"))
+		jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..head-sha-52"))
 		if err != nil {
 			t.Fatalf("ListJobs: %v", err)
 		}
@@ -1041,7 +1041,7 @@ func TestCIPollerFindLocalRepo_AmbiguousRepoError(t *testing.T) {
 	cfg := config.DefaultConfig()
 	p := NewCIPoller(db, NewStaticConfig(cfg), nil)
 
-	_, err := p.findLocalRepo("acme/api")
+	_, err := p.findLocalRepo(t.Context(), "acme/api")
 	if err == nil {
 		t.Fatal("expected error for ambiguous repo match")
 	}
@@ -1068,7 +1068,7 @@ func TestCIPollerFindLocalRepo_PartialIdentityAmbiguity(t *testing.T) {
 	cfg := config.DefaultConfig()
 	p := NewCIPoller(db, NewStaticConfig(cfg), nil)
 
-	_, err := p.findLocalRepo("acme/widgets")
+	_, err := p.findLocalRepo(t.Context(), "acme/widgets")
 	if err == nil {
 		t.Fatal("expected error for ambiguous partial repo match")
 	}
@@ -1112,7 +1112,7 @@ func TestCIPollerProcessPR_RepoOverrides(t *testing.T) {
 		t.Fatalf("processPR: %v", err)
 	}
 
-	jobs, err := h.DB.ListJobs("", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..repo-override-sha"))
+	jobs, err := h.DB.ListJobs(t.Context(), "", h.RepoPath, 0, 0, storage.WithGitRef("base-sha..repo-override-sha"))
 	if err != nil {
 		t.Fatalf("ListJobs: %v", err)
 	}
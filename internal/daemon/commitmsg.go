@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// handleGetCommitMessage returns the latest commit message a review
+// proposed for the commit named by the sha parameter
+func (s *Server) handleGetCommitMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sha := r.URL.Query().Get("sha")
+	if sha == "" {
+		writeError(w, http.StatusBadRequest, "sha parameter required")
+		return
+	}
+
+	suggestion, err := s.db.GetCommitMessageSuggestion(sha)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "no commit message suggested")
+		return
+	}
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get commit message suggestion: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, suggestion)
+}
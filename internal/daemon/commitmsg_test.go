@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleGetCommitMessage(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/commitmsg-repo")
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "msgsha", "Author", "wip", time.Now())
+	if err != nil {
+		t.Fatalf("GetOrCreateCommit: %v", err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "msgsha", Agent: "test"})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/commit-message"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleGetCommitMessage(w, req)
+		return w
+	}
+
+	testutil.AssertStatusCode(t, get(""), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get("?sha=msgsha"), http.StatusNotFound)
+
+	if err := db.SaveCommitMessageSuggestion(job.ID, "Add parser for config files"); err != nil {
+		t.Fatalf("SaveCommitMessageSuggestion: %v", err)
+	}
+	w := get("?sha=msgsha")
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var got storage.CommitMessageSuggestion
+	testutil.DecodeJSON(t, w, &got)
+	if got.JobID != job.ID || got.Message != "Add parser for config files" {
+		t.Errorf("unexpected suggestion %+v", got)
+	}
+}
@@ -204,6 +204,8 @@ func (cw *ConfigWatcher) reloadConfig() {
 	// Update global agent settings
 	agent.SetAllowUnsafeAgents(newCfg.AllowUnsafeAgents != nil && *newCfg.AllowUnsafeAgents)
 	agent.SetAnthropicAPIKey(newCfg.AnthropicAPIKey)
+	agent.SetOpenAIConfig(openAIAgentConfig(newCfg))
+	agent.SetExecutor(agentExecutor(newCfg))
 
 	// Log what changed (for debugging)
 	logConfigChanges(oldCfg, newCfg)
@@ -217,6 +219,37 @@ func (cw *ConfigWatcher) reloadConfig() {
 	log.Printf("Config reloaded successfully")
 }
 
+// openAIAgentConfig returns the openai agent's settings from the config
+func openAIAgentConfig(cfg *config.Config) agent.OpenAIConfig {
+	return agent.OpenAIConfig{
+		BaseURL: cfg.OpenAI.BaseURL,
+		APIKey:  cfg.OpenAI.APIKeyExpanded(),
+		Model:   cfg.OpenAI.Model,
+	}
+}
+
+// agentExecutor returns where agent commands run from the config
+func agentExecutor(cfg *config.Config) agent.Executor {
+	e := cfg.Executor
+	switch e.Type {
+	case agent.ExecutorDocker:
+		return &agent.DockerExecutor{Image: e.Image, Env: e.Env, Network: e.Network}
+	case agent.ExecutorKubernetes:
+		return &agent.KubernetesExecutor{
+			Image:          e.Image,
+			Env:            e.Env,
+			Namespace:      e.Namespace,
+			VolumeClaim:    e.VolumeClaim,
+			MountPath:      e.MountPath,
+			ServiceAccount: e.ServiceAccount,
+			Secret:         e.Secret,
+			StartTimeout:   e.StartTimeout,
+		}
+	default:
+		return agent.LocalExecutor{}
+	}
+}
+
 func logConfigChanges(old, new *config.Config) {
 	if old.DefaultAgent != new.DefaultAgent {
 		log.Printf("Config change: default_agent %q -> %q", old.DefaultAgent, new.DefaultAgent)
@@ -232,6 +265,9 @@ func logConfigChanges(old, new *config.Config) {
 	if oldUnsafe != newUnsafe {
 		log.Printf("Config change: allow_unsafe_agents %v -> %v", oldUnsafe, newUnsafe)
 	}
+	if old.Executor.Type != new.Executor.Type || old.Executor.Image != new.Executor.Image {
+		log.Printf("Config change: executor %q (%s) -> %q (%s)", old.Executor.Type, old.Executor.Image, new.Executor.Type, new.Executor.Image)
+	}
 	if old.MaxWorkers != new.MaxWorkers {
 		log.Printf("Config change: max_workers %d -> %d (requires daemon restart to take effect)", old.MaxWorkers, new.MaxWorkers)
 	}
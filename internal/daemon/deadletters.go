@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// DeadLettersResponse is returned by GET /api/dead-letters
+type DeadLettersResponse struct {
+	DeadLetters []storage.DeadLetter `json:"dead_letters"`
+	HasMore     bool                 `json:"has_more"`
+	NextCursor  string               `json:"next_cursor,omitempty"` // Pass as cursor for the next page
+}
+
+// RequeueDeadLettersRequest names the dead letters to requeue: the jobs
+// given, or with All every dead letter matching Repo and ErrorCode
+type RequeueDeadLettersRequest struct {
+	JobIDs    []int64           `json:"job_ids,omitempty"`
+	All       bool              `json:"all,omitempty"`
+	Repo      string            `json:"repo,omitempty"` // Repo root path
+	ErrorCode storage.ErrorCode `json:"error_code,omitempty"`
+}
+
+// RequeueDeadLettersResponse is returned by POST /api/dead-letters/requeue
+type RequeueDeadLettersResponse struct {
+	Requeued []int64 `json:"requeued"`
+	Skipped  []int64 `json:"skipped,omitempty"` // Jobs that are not dead letters
+}
+
+// handleListDeadLetters lists the jobs that failed for good, most recent
+// first, filtered by the repo and error_code parameters, a page at a time
+// like /api/jobs
+func (s *Server) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	filter := storage.DeadLetterFilter{
+		RepoPath:  r.URL.Query().Get("repo"),
+		ErrorCode: storage.ErrorCode(r.URL.Query().Get("error_code")),
+		Limit:     50,
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		jobID, deadAt, err := decodeDeadLetterCursor(cursor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cursor parameter")
+			return
+		}
+		filter.BeforeJobID, filter.BeforeDeadAt = jobID, deadAt
+	}
+
+	// Fetch one extra to tell whether there are more
+	limit := filter.Limit
+	if limit > 0 {
+		filter.Limit++
+	}
+	letters, err := s.db.ListDeadLetters(r.Context(), filter)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("list dead letters: %v", err))
+		return
+	}
+	resp := DeadLettersResponse{DeadLetters: letters}
+	if limit > 0 && len(letters) > limit {
+		resp.DeadLetters, resp.HasMore = letters[:limit], true
+		resp.NextCursor = encodeDeadLetterCursor(letters[limit-1])
+		w.Header().Set("Link", nextPageLink(r, resp.NextCursor))
+	}
+	if resp.DeadLetters == nil {
+		resp.DeadLetters = []storage.DeadLetter{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// encodeDeadLetterCursor returns the cursor of the dead letters listed
+// after d, which are sorted by when they died, then by job ID
+func encodeDeadLetterCursor(d storage.DeadLetter) string {
+	return encodeCursor("dead", strconv.FormatInt(d.JobID, 10)+"@"+d.DeadAt.UTC().Format(time.RFC3339))
+}
+
+// decodeDeadLetterCursor parses a cursor from encodeDeadLetterCursor
+func decodeDeadLetterCursor(cursor string) (int64, time.Time, error) {
+	key, err := decodeCursor("dead", cursor)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	idStr, deadAtStr, _ := strings.Cut(key, "@")
+	jobID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || jobID <= 0 {
+		return 0, time.Time{}, fmt.Errorf("invalid cursor job id %q", idStr)
+	}
+	deadAt, err := time.Parse(time.RFC3339, deadAtStr)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid cursor time: %w", err)
+	}
+	return jobID, deadAt, nil
+}
+
+// handleGetDeadLetter returns the dead letter of the job_id parameter with
+// the error of each of its attempts
+func (s *Server) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	jobID, err := strconv.ParseInt(r.URL.Query().Get("job_id"), 10, 64)
+	if err != nil || jobID <= 0 {
+		writeError(w, http.StatusBadRequest, "job_id is required")
+		return
+	}
+
+	letter, err := s.db.GetDeadLetter(r.Context(), jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "job is not a dead letter")
+		return
+	} else if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get dead letter: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, letter)
+}
+
+// handleRequeueDeadLetters queues dead letters to run afresh, with their
+// retries reset
+func (s *Server) handleRequeueDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req RequeueDeadLettersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.All == (len(req.JobIDs) > 0) {
+		writeError(w, http.StatusBadRequest, "give either job_ids or all")
+		return
+	}
+
+	ids := req.JobIDs
+	if req.All {
+		letters, err := s.db.ListDeadLetters(r.Context(), storage.DeadLetterFilter{RepoPath: req.Repo, ErrorCode: req.ErrorCode})
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("list dead letters: %v", err))
+			return
+		}
+		for _, l := range letters {
+			ids = append(ids, l.JobID)
+		}
+	}
+
+	resp := RequeueDeadLettersResponse{Requeued: []int64{}}
+	for _, id := range ids {
+		if _, err := s.db.GetDeadLetter(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+			resp.Skipped = append(resp.Skipped, id)
+			continue
+		} else if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("get dead letter %d: %v", id, err))
+			return
+		}
+		if err := s.db.ReenqueueJob(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+			resp.Skipped = append(resp.Skipped, id)
+			continue
+		} else if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("requeue job %d: %v", id, err))
+			return
+		}
+		resp.Requeued = append(resp.Requeued, id)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+
+	var jobs []int64
+	for range 3 {
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]any{"repo_path": repoDir, "git_ref": "HEAD", "agent": "test"})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		jobs = append(jobs, job.ID)
+	}
+	// The first two fail for good
+	for i, code := range []storage.ErrorCode{storage.ErrorCodeAgentAuth, storage.ErrorCodeTimeout} {
+		claimed, err := db.ClaimJob(t.Context(), "worker-1")
+		if err != nil || claimed == nil || claimed.ID != jobs[i] {
+			t.Fatalf("ClaimJob: %+v, %v", claimed, err)
+		}
+		if err := db.RecordJobFailure(t.Context(), claimed.ID, code, string(code)); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.FailJobWithCode(t.Context(), claimed.ID, code, string(code)); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.DeadLetterJob(t.Context(), claimed.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dead-letters?error_code=timeout", nil)
+	w := httptest.NewRecorder()
+	server.handleListDeadLetters(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var list DeadLettersResponse
+	testutil.DecodeJSON(t, w, &list)
+	if len(list.DeadLetters) != 1 || list.DeadLetters[0].JobID != jobs[1] {
+		t.Errorf("unexpected dead letters %+v", list.DeadLetters)
+	}
+
+	// Paging lists the most recent first, ties broken by job ID
+	var paged []int64
+	cursor := ""
+	for page := 0; ; page++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/dead-letters?limit=1&cursor="+cursor, nil)
+		w := httptest.NewRecorder()
+		server.handleListDeadLetters(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var list DeadLettersResponse
+		testutil.DecodeJSON(t, w, &list)
+		for _, d := range list.DeadLetters {
+			paged = append(paged, d.JobID)
+		}
+		if !list.HasMore || page > 2 {
+			break
+		}
+		cursor = list.NextCursor
+	}
+	if !slices.Equal(paged, []int64{jobs[1], jobs[0]}) {
+		t.Errorf("paged through %v, want %v", paged, []int64{jobs[1], jobs[0]})
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/dead-letters?cursor=bogus", nil)
+	w = httptest.NewRecorder()
+	server.handleListDeadLetters(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+
+	show := func(id int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/dead-letters/show?job_id=%d", id), nil)
+		w := httptest.NewRecorder()
+		server.handleGetDeadLetter(w, req)
+		return w
+	}
+	w = show(jobs[0])
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var letter storage.DeadLetter
+	testutil.DecodeJSON(t, w, &letter)
+	if len(letter.Failures) != 1 || letter.Failures[0].ErrorCode != storage.ErrorCodeAgentAuth {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+	testutil.AssertStatusCode(t, show(jobs[2]), http.StatusNotFound)
+
+	requeue := func(body RequeueDeadLettersRequest) *httptest.ResponseRecorder {
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/dead-letters/requeue", body)
+		w := httptest.NewRecorder()
+		server.handleRequeueDeadLetters(w, req)
+		return w
+	}
+	testutil.AssertStatusCode(t, requeue(RequeueDeadLettersRequest{}), http.StatusBadRequest)
+
+	w = requeue(RequeueDeadLettersRequest{JobIDs: []int64{jobs[0], jobs[2]}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var resp RequeueDeadLettersResponse
+	testutil.DecodeJSON(t, w, &resp)
+	if !slices.Equal(resp.Requeued, []int64{jobs[0]}) || !slices.Equal(resp.Skipped, []int64{jobs[2]}) {
+		t.Errorf("unexpected requeue %+v", resp)
+	}
+
+	w = requeue(RequeueDeadLettersRequest{All: true, Repo: repoDir})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	resp = RequeueDeadLettersResponse{}
+	testutil.DecodeJSON(t, w, &resp)
+	if !slices.Equal(resp.Requeued, []int64{jobs[1]}) {
+		t.Errorf("unexpected bulk requeue %+v", resp)
+	}
+	for _, id := range jobs[:2] {
+		if job, _ := db.GetJobByID(t.Context(), id); job == nil || job.Status != storage.JobStatusQueued {
+			t.Errorf("job %d not requeued: %+v", id, job)
+		}
+	}
+}
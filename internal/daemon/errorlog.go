@@ -54,7 +54,7 @@ func NewErrorLog(path string) (*ErrorLog, error) {
 
 // DefaultErrorLogPath returns the default path for the error log
 func DefaultErrorLogPath() string {
-	return filepath.Join(config.DataDir(), "errors.log")
+	return filepath.Join(config.LogsDir(), "errors.log")
 }
 
 // Log writes an error entry to both file and in-memory buffer
@@ -0,0 +1,219 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// findingCheck is the result of checking one finding's file references
+type findingCheck struct {
+	Finding storage.Finding
+	Reason  string // Why the finding is invalid, "" if valid
+}
+
+// findingCheckResult summarizes the checks of one review's findings
+type findingCheckResult struct {
+	Checks  []findingCheck
+	Invalid int
+}
+
+// fileIndex knows which files exist in the reviewed code and how many lines
+// they have
+type fileIndex struct {
+	repoPath string
+	// files maps each path to the revision it is read from, or "" for the
+	// working tree
+	files map[string]string
+	lines map[string]int
+}
+
+// newFileIndex lists the files a review of job could legitimately cite: the
+// files at the reviewed revision, plus files that only exist before it so
+// that findings about deletions are not rejected. Dirty reviews use the
+// working tree.
+func newFileIndex(job *storage.ReviewJob) (*fileIndex, error) {
+	idx := &fileIndex{repoPath: job.RepoPath, files: map[string]string{}, lines: map[string]int{}}
+
+	if job.DiffContent != nil {
+		head, err := git.ListFiles(job.RepoPath, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range head {
+			idx.files[f] = ""
+		}
+		for _, f := range diffFiles(*job.DiffContent) {
+			idx.files[f] = ""
+		}
+		return idx, nil
+	}
+
+	start, end, ok := git.ParseRange(job.GitRef)
+	if !ok {
+		start, end = job.GitRef+"^", job.GitRef
+	}
+	endFiles, err := git.ListFiles(job.RepoPath, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range endFiles {
+		idx.files[f] = end
+	}
+	// The start has no tree for root commits; those have nothing deleted
+	if startFiles, err := git.ListFiles(job.RepoPath, start); err == nil {
+		for _, f := range startFiles {
+			if _, ok := idx.files[f]; !ok {
+				idx.files[f] = start
+			}
+		}
+	}
+	return idx, nil
+}
+
+// diffFiles returns the paths of the new side of each file in a unified diff
+func diffFiles(diff string) []string {
+	var files []string
+	for _, line := range strings.Split(diff, "\n") {
+		if name, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			files = append(files, strings.TrimSpace(name))
+		}
+	}
+	return files
+}
+
+// resolve returns the repo paths a reference could mean: the path itself,
+// or any file whose path ends with it
+func (idx *fileIndex) resolve(ref string) []string {
+	ref = strings.TrimPrefix(path.Clean(ref), "./")
+	if _, ok := idx.files[ref]; ok {
+		return []string{ref}
+	}
+	var matches []string
+	for f := range idx.files {
+		if strings.HasSuffix(f, "/"+ref) {
+			matches = append(matches, f)
+		}
+	}
+	return matches
+}
+
+// lineCount returns the number of lines in a file, or -1 if it cannot be
+// read (such as a file deleted from the working tree)
+func (idx *fileIndex) lineCount(file string) int {
+	if n, ok := idx.lines[file]; ok {
+		return n
+	}
+	var data []byte
+	var err error
+	if rev := idx.files[file]; rev == "" {
+		data, err = os.ReadFile(filepath.Join(idx.repoPath, filepath.FromSlash(file)))
+	} else {
+		data, err = git.ReadFile(idx.repoPath, rev, file)
+	}
+	n := -1
+	if err == nil {
+		n = strings.Count(string(data), "\n")
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			n++
+		}
+	}
+	idx.lines[file] = n
+	return n
+}
+
+// check returns why ref does not match the reviewed code, or "" if it does
+func (idx *fileIndex) check(ref storage.FileRef) string {
+	matches := idx.resolve(ref.Path)
+	if len(matches) == 0 {
+		return fmt.Sprintf("%s does not exist", ref.Path)
+	}
+	if ref.StartLine == 0 {
+		return ""
+	}
+	last := max(ref.StartLine, ref.EndLine)
+	longest := 0
+	for _, f := range matches {
+		n := idx.lineCount(f)
+		if n < 0 || last <= n {
+			return ""
+		}
+		longest = max(longest, n)
+	}
+	return fmt.Sprintf("%s has %d lines, not %d", ref.Path, longest, last)
+}
+
+// checkFindings cross-checks the file and line references of each finding
+// in a review's output against the reviewed code. A finding is invalid if
+// any of its references names a file that does not exist or a line past
+// the end of the file.
+func checkFindings(job *storage.ReviewJob, output string) (findingCheckResult, error) {
+	var result findingCheckResult
+	findings := storage.ExtractFindings(output)
+	if len(findings) == 0 {
+		return result, nil
+	}
+	idx, err := newFileIndex(job)
+	if err != nil {
+		return result, err
+	}
+	for _, f := range findings {
+		c := findingCheck{Finding: f}
+		for _, ref := range storage.ExtractFileRefs(f.Text, job.RepoPath) {
+			if reason := idx.check(ref); reason != "" {
+				c.Reason = reason
+				result.Invalid++
+				break
+			}
+		}
+		result.Checks = append(result.Checks, c)
+	}
+	return result, nil
+}
+
+// applyFindingChecks rewrites review output for the finding validation
+// mode: "flag" marks invalid findings as unverified, "drop" removes them
+// and notes how many were removed. Other modes return the output as is.
+func applyFindingChecks(output string, result findingCheckResult, mode string) string {
+	if result.Invalid == 0 || (mode != config.FindingValidationFlag && mode != config.FindingValidationDrop) {
+		return output
+	}
+
+	var sb strings.Builder
+	cursor := 0
+	for _, c := range result.Checks {
+		pos := strings.Index(output[cursor:], c.Finding.Text)
+		if pos < 0 {
+			continue
+		}
+		start := cursor + pos
+		end := start + len(c.Finding.Text)
+		sb.WriteString(output[cursor:start])
+		switch {
+		case c.Reason == "":
+			sb.WriteString(c.Finding.Text)
+		case mode == config.FindingValidationFlag:
+			sb.WriteString(c.Finding.Text)
+			sb.WriteString("\n  [roborev: unverified - " + c.Reason + "]")
+		default:
+			// Drop the finding along with the blank line that follows it
+			if strings.HasPrefix(output[end:], "\n\n") {
+				end++
+			}
+		}
+		cursor = end
+	}
+	sb.WriteString(output[cursor:])
+
+	if mode == config.FindingValidationDrop {
+		return strings.TrimRight(sb.String(), "\n") +
+			fmt.Sprintf("\n\n[roborev: dropped %d finding(s) citing files or lines that do not exist]\n", result.Invalid)
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestCheckFindings(t *testing.T) {
+	repoDir, run := createTestGitRepo(t)
+	if err := os.MkdirAll(filepath.Join(repoDir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "pkg", "util.go"), []byte("package pkg\n\nfunc A() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repoDir, "test.txt")); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "-A")
+	run("commit", "-m", "add util")
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha := strings.TrimSpace(string(out))
+
+	output := "## Findings\n\n" +
+		"- **High**: bad call in pkg/util.go:3\n\n" +
+		"- **Medium**: removed file test.txt:1 is still referenced\n\n" +
+		"- **Low**: unused code in util.go:40\n\n" +
+		"- **Low**: wrong import in pkg/missing.go\n\n" +
+		"Overall the change is small."
+	job := &storage.ReviewJob{ID: 1, RepoPath: repoDir, GitRef: sha}
+
+	result, err := checkFindings(job, output)
+	if err != nil {
+		t.Fatalf("checkFindings: %v", err)
+	}
+	if len(result.Checks) != 4 || result.Invalid != 2 {
+		t.Fatalf("expected 4 checks with 2 invalid, got %+v", result)
+	}
+	reasons := []string{"", "", "util.go has 3 lines, not 40", "pkg/missing.go does not exist"}
+	for i, want := range reasons {
+		if got := result.Checks[i].Reason; got != want {
+			t.Errorf("finding %d: reason %q, want %q", i, got, want)
+		}
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		if got := applyFindingChecks(output, result, config.FindingValidationKeep); got != output {
+			t.Errorf("keep changed output:\n%s", got)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		got := applyFindingChecks(output, result, config.FindingValidationFlag)
+		if !strings.Contains(got, "util.go:40\n  [roborev: unverified - util.go has 3 lines, not 40]\n") {
+			t.Errorf("missing flag on line finding:\n%s", got)
+		}
+		if !strings.Contains(got, "pkg/missing.go\n  [roborev: unverified - pkg/missing.go does not exist]\n") {
+			t.Errorf("missing flag on path finding:\n%s", got)
+		}
+		if strings.Count(got, "[roborev: unverified") != 2 {
+			t.Errorf("expected 2 flags:\n%s", got)
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		got := applyFindingChecks(output, result, config.FindingValidationDrop)
+		if strings.Contains(got, "util.go:40") || strings.Contains(got, "missing.go") {
+			t.Errorf("invalid findings not dropped:\n%s", got)
+		}
+		if !strings.Contains(got, "pkg/util.go:3") || !strings.Contains(got, "Overall the change is small.") {
+			t.Errorf("valid content dropped:\n%s", got)
+		}
+		if !strings.HasSuffix(got, "[roborev: dropped 2 finding(s) citing files or lines that do not exist]\n") {
+			t.Errorf("missing drop note:\n%s", got)
+		}
+		if got := len(storage.ExtractFindings(got)); got != 2 {
+			t.Errorf("expected 2 findings left, got %d", got)
+		}
+	})
+}
+
+func TestCheckFindingsDirty(t *testing.T) {
+	repoDir, _ := createTestGitRepo(t)
+	if err := os.WriteFile(filepath.Join(repoDir, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff := "diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package main\n"
+	job := &storage.ReviewJob{ID: 1, RepoPath: repoDir, GitRef: "dirty", DiffContent: &diff}
+
+	output := "- **High**: problem in new.go:1\n- **Low**: problem in new.go:5\n- **Low**: test.txt:1 is stale"
+	result, err := checkFindings(job, output)
+	if err != nil {
+		t.Fatalf("checkFindings: %v", err)
+	}
+	if result.Invalid != 1 || result.Checks[1].Reason == "" {
+		t.Errorf("expected only the second finding to be invalid, got %+v", result)
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// groupCommit applies the repo's commit grouping to a commit its hook
+// enqueued. When the commit's parent ends a held review on the same branch,
+// and the commits are close enough in time (and by the same author in
+// "author" mode), the held review is extended to cover the commit and
+// returned. Otherwise it returns nil and, with grouping on, how long the
+// commit's own review should be held for commits that follow it.
+func (s *Server) groupCommit(ctx context.Context, repoRoot, gitCwd string, repoID int64, branch, agentName, reviewType string, info *git.CommitInfo) (*storage.ReviewJob, time.Time) {
+	grouping := config.ResolveCommitGrouping(repoRoot, s.configWatcher.Config())
+	if s.db == nil || grouping.Mode == "" || branch == "" {
+		return nil, time.Time{}
+	}
+	window := grouping.WindowDuration()
+	holdUntil := time.Now().Add(window)
+
+	parent, err := git.ResolveSHA(gitCwd, info.SHA+"^")
+	if err != nil {
+		return nil, holdUntil // Root commit
+	}
+	tail, err := s.db.FindGroupTail(repoID, branch, agentName, reviewType, parent)
+	if err != nil {
+		log.Printf("Commit grouping: find group for %s: %v", info.SHA, err)
+		return nil, holdUntil
+	}
+	if tail == nil {
+		return nil, holdUntil
+	}
+
+	parentInfo, err := git.GetCommitInfo(repoRoot, parent)
+	if err != nil {
+		log.Printf("Commit grouping: read %s: %v", parent, err)
+		return nil, holdUntil
+	}
+	if gap := info.Timestamp.Sub(parentInfo.Timestamp); gap > window || gap < -window {
+		return nil, holdUntil
+	}
+	if grouping.Mode == "author" && !sameAuthor(info, parentInfo) {
+		return nil, holdUntil
+	}
+
+	// A grouped single commit becomes the range from its parent
+	start, _, isRange := strings.Cut(tail.GitRef, "..")
+	if !isRange {
+		if start, err = git.ResolveSHA(gitCwd, tail.GitRef+"^"); err != nil {
+			start = git.EmptyTreeSHA
+		}
+	}
+	if err := s.db.ExtendJobGroup(tail.ID, start+".."+info.SHA, holdUntil); err != nil {
+		// Usually the group was claimed between the lookup and the update
+		log.Printf("Commit grouping: extend job %d with %s: %v", tail.ID, info.SHA, err)
+		return nil, holdUntil
+	}
+	job, err := s.db.GetJobByID(ctx, tail.ID)
+	if err != nil {
+		log.Printf("Commit grouping: reload job %d: %v", tail.ID, err)
+		return nil, holdUntil
+	}
+	return job, holdUntil
+}
+
+// sameAuthor reports whether two commits have the same author, matching on
+// email when both have one
+func sameAuthor(a, b *git.CommitInfo) bool {
+	if a.AuthorEmail != "" && b.AuthorEmail != "" {
+		return strings.EqualFold(a.AuthorEmail, b.AuthorEmail)
+	}
+	return a.Author == b.Author
+}
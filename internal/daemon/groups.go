@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// JobGroupsResponse is returned by GET /api/groups
+type JobGroupsResponse struct {
+	Groups     []storage.JobGroup `json:"groups"`
+	HasMore    bool               `json:"has_more"`
+	NextCursor string             `json:"next_cursor,omitempty"` // Pass as cursor for the next page
+}
+
+// CancelGroupRequest names a group by ID or name
+type CancelGroupRequest struct {
+	Group string `json:"group"`
+}
+
+// CancelGroupResponse is returned by POST /api/groups/cancel
+type CancelGroupResponse struct {
+	Canceled []int64 `json:"canceled"`
+}
+
+// findGroup looks a group up by ID, or by name when ref is not a number
+func (s *Server) findGroup(ref string) (*storage.JobGroup, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		group, err := s.db.GetJobGroup(id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return group, err
+		}
+	}
+	return s.db.FindJobGroup(ref)
+}
+
+// addToGroup puts a newly enqueued job in its group. The job is already
+// queued, so a failure is only logged.
+func (s *Server) addToGroup(group *storage.JobGroup, jobID int64) {
+	if group == nil {
+		return
+	}
+	if err := s.db.AddJobToGroup(group.ID, jobID); err != nil {
+		log.Printf("Groups: add job %d to group %s: %v", jobID, group.Name, err)
+	}
+}
+
+// handleListGroups lists the most recent job groups with their progress,
+// a page at a time like /api/jobs
+func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+	var beforeID int64
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		id, err := decodeIDCursor("group", cursor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cursor parameter")
+			return
+		}
+		beforeID = id
+	}
+
+	// Fetch one extra to tell whether there are more
+	groups, err := s.db.ListJobGroups(limit+1, beforeID)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("list groups: %v", err))
+		return
+	}
+	resp := JobGroupsResponse{Groups: groups}
+	if len(groups) > limit {
+		resp.Groups, resp.HasMore = groups[:limit], true
+		resp.NextCursor = encodeCursor("group", strconv.FormatInt(groups[limit-1].ID, 10))
+		w.Header().Set("Link", nextPageLink(r, resp.NextCursor))
+	}
+	if resp.Groups == nil {
+		resp.Groups = []storage.JobGroup{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGroupStatus returns the progress of the group named by the id
+// parameter, a group ID or name
+func (s *Server) handleGroupStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	ref := r.URL.Query().Get("id")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	group, err := s.findGroup(ref)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	} else if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get group: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+// handleCancelGroup cancels every queued, running or blocked job of a group
+func (s *Server) handleCancelGroup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req CancelGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Group == "" {
+		writeError(w, http.StatusBadRequest, "group is required")
+		return
+	}
+
+	group, err := s.findGroup(req.Group)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	} else if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get group: %v", err))
+		return
+	}
+
+	ids, err := s.db.GroupJobIDs(group.ID, storage.JobStatusQueued, storage.JobStatusRunning, storage.JobStatusBlocked)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("list group jobs: %v", err))
+		return
+	}
+	canceled := []int64{}
+	for _, id := range ids {
+		// Canceling a fan-out join cancels its parts, which may come later
+		// in the list and are then no longer cancellable
+		if err := s.cancelJob(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("cancel job %d: %v", id, err))
+			return
+		}
+		canceled = append(canceled, id)
+	}
+	writeJSON(w, http.StatusOK, CancelGroupResponse{Canceled: canceled})
+}
@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestJobGroupEndpoints(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+
+	var jobs []int64
+	for _, ref := range []string{"HEAD", "HEAD", "dirty"} {
+		body := map[string]any{"repo_path": repoDir, "git_ref": ref, "agent": "test", "group": "backfill"}
+		if ref == "dirty" {
+			body["diff_content"] = "diff --git a/x b/x\n"
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", body)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		jobs = append(jobs, job.ID)
+	}
+	claimed, err := db.ClaimJob(t.Context(), "worker-1")
+	if err != nil || claimed == nil {
+		t.Fatalf("ClaimJob: %+v, %v", claimed, err)
+	}
+	if err := db.CompleteJob(t.Context(), claimed.ID, "test", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+
+	status := func(ref string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/groups/status?id="+ref, nil)
+		w := httptest.NewRecorder()
+		server.handleGroupStatus(w, req)
+		return w
+	}
+	w := status("backfill")
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var group storage.JobGroup
+	testutil.DecodeJSON(t, w, &group)
+	if group.Kind != storage.JobGroupReview || group.Total != 3 || group.Passed != 1 || group.ByStatus[storage.JobStatusQueued] != 2 {
+		t.Errorf("unexpected group %+v", group)
+	}
+	testutil.AssertStatusCode(t, status("1"), http.StatusOK)
+	testutil.AssertStatusCode(t, status("missing"), http.StatusNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+	w = httptest.NewRecorder()
+	server.handleListGroups(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var list JobGroupsResponse
+	testutil.DecodeJSON(t, w, &list)
+	if len(list.Groups) != 1 || list.Groups[0].Name != "backfill" {
+		t.Errorf("unexpected groups %+v", list.Groups)
+	}
+
+	// A second group pages the listing
+	if _, err := db.GetOrCreateJobGroup("nightly", storage.JobGroupReview); err != nil {
+		t.Fatalf("GetOrCreateJobGroup: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/groups?limit=1", nil)
+	w = httptest.NewRecorder()
+	server.handleListGroups(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	list = JobGroupsResponse{}
+	testutil.DecodeJSON(t, w, &list)
+	if len(list.Groups) != 1 || list.Groups[0].Name != "nightly" || !list.HasMore || w.Header().Get("Link") == "" {
+		t.Fatalf("unexpected first page %+v", list)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/groups?limit=1&cursor="+list.NextCursor, nil)
+	w = httptest.NewRecorder()
+	server.handleListGroups(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	list = JobGroupsResponse{}
+	testutil.DecodeJSON(t, w, &list)
+	if len(list.Groups) != 1 || list.Groups[0].Name != "backfill" || list.HasMore {
+		t.Errorf("unexpected second page %+v", list)
+	}
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/groups/cancel", CancelGroupRequest{Group: "backfill"})
+	w = httptest.NewRecorder()
+	server.handleCancelGroup(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var canceled CancelGroupResponse
+	testutil.DecodeJSON(t, w, &canceled)
+	want := slices.DeleteFunc(slices.Clone(jobs), func(id int64) bool { return id == claimed.ID })
+	if !slices.Equal(canceled.Canceled, want) {
+		t.Errorf("canceled %v, want %v", canceled.Canceled, want)
+	}
+	for _, id := range want {
+		if job, _ := db.GetJobByID(t.Context(), id); job == nil || job.Status != storage.JobStatusCanceled {
+			t.Errorf("job %d not canceled: %+v", id, job)
+		}
+	}
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/groups/cancel", CancelGroupRequest{Group: "missing"})
+	w = httptest.NewRecorder()
+	server.handleCancelGroup(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+}
@@ -1,11 +1,13 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
@@ -60,8 +62,8 @@ func (hr *HookRunner) Stop() {
 
 // handleEvent checks all configured hooks against the event and fires matches.
 func (hr *HookRunner) handleEvent(event Event) {
-	// Only handle review events
-	if !strings.HasPrefix(event.Type, "review.") {
+	// Only handle review and queue events
+	if !strings.HasPrefix(event.Type, "review.") && !strings.HasPrefix(event.Type, "queue.") {
 		return
 	}
 
@@ -76,6 +78,12 @@ func (hr *HookRunner) handleEvent(event Event) {
 	if event.Repo != "" {
 		if repoCfg, err := config.LoadRepoConfig(event.Repo); err == nil && repoCfg != nil {
 			hooks = append(hooks, repoCfg.Hooks...)
+			// Hooks of the monorepo routes the job matched
+			for _, route := range repoCfg.Routes {
+				if slices.Contains(event.Routes, route.ID()) {
+					hooks = append(hooks, route.Hooks...)
+				}
+			}
 		}
 	}
 
@@ -117,8 +125,11 @@ func matchEvent(pattern, eventType string) bool {
 // resolveCommand builds the shell command for a hook, handling built-in types
 // and template variable interpolation.
 func resolveCommand(hook config.HookConfig, event Event) string {
-	if hook.Type == "beads" {
+	switch hook.Type {
+	case "beads":
 		return beadsCommand(event)
+	case "desktop":
+		return desktopCommand(event, runtime.GOOS)
 	}
 	return interpolate(hook.Command, event)
 }
@@ -150,6 +161,78 @@ func beadsCommand(event Event) string {
 	}
 }
 
+// desktopCommand generates a native notification command for the desktop
+// built-in hook: osascript on macOS, a toast on Windows and notify-send
+// elsewhere. The notification gives the verdict and the review's quick take,
+// or how to view the review when it has none, or why a review is blocked, how long an overdue review has waited, or why
+// the queue is starved.
+func desktopCommand(event Event, goos string) string {
+	repoName := event.RepoName
+	if repoName == "" {
+		repoName = filepath.Base(event.Repo)
+	}
+
+	shortSHA := event.SHA
+	if len(shortSHA) > 8 {
+		shortSHA = shortSHA[:8]
+	}
+
+	var title string
+	body := fmt.Sprintf("Run roborev show %d", event.JobID)
+	switch {
+	case event.Type == "review.failed":
+		title = fmt.Sprintf("Review failed: %s (%s)", repoName, shortSHA)
+	case event.Type == "review.blocked":
+		title = fmt.Sprintf("Review blocked: %s (%s)", repoName, shortSHA)
+		body = event.Error
+	case event.Type == "review.overdue":
+		title = fmt.Sprintf("Review overdue: %s (%s)", repoName, shortSHA)
+		body = fmt.Sprintf("Job %d %s", event.JobID, event.Error)
+	case event.Type == "queue.starved":
+		title = "Review queue starved"
+		body = event.Error
+	case event.Type != "review.completed":
+		return ""
+	case event.Verdict == "F":
+		title = fmt.Sprintf("Review found issues: %s (%s)", repoName, shortSHA)
+	case event.Verdict == "P":
+		title = fmt.Sprintf("Review passed: %s (%s)", repoName, shortSHA)
+	default:
+		title = fmt.Sprintf("Review done: %s (%s)", repoName, shortSHA)
+	}
+	if event.Type == "review.completed" && event.QuickTake != "" {
+		body = event.QuickTake
+	}
+
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title \"roborev\" subtitle %s",
+			appleScriptString(body), appleScriptString(title))
+		return "osascript -e " + shellEscape(script)
+	case "windows":
+		return fmt.Sprintf("[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null; "+
+			"$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02); "+
+			"$x = $t.GetElementsByTagName('text'); "+
+			"$x.Item(0).AppendChild($t.CreateTextNode(%s)) > $null; "+
+			"$x.Item(1).AppendChild($t.CreateTextNode(%s)) > $null; "+
+			"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('roborev').Show([Windows.UI.Notifications.ToastNotification]::new($t))",
+			powerShellString(title), powerShellString(body))
+	default:
+		return fmt.Sprintf("notify-send -a roborev %s %s", shellEscape(title), shellEscape(body))
+	}
+}
+
+// appleScriptString quotes a value as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellString quotes a value as a PowerShell single-quoted string
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // interpolate replaces {var} template variables in a command string.
 // Values are shell-escaped to prevent injection via event fields.
 func interpolate(cmd string, event Event) string {
@@ -165,7 +248,10 @@ func interpolate(cmd string, event Event) string {
 		"{agent}", shellEscape(event.Agent),
 		"{verdict}", shellEscape(event.Verdict),
 		"{findings}", shellEscape(event.Findings),
+		"{quick_take}", shellEscape(event.QuickTake),
 		"{error}", shellEscape(event.Error),
+		"{routes}", shellEscape(strings.Join(event.Routes, ",")),
+		"{owners}", shellEscape(strings.Join(event.Owners, ",")),
 	)
 	return r.Replace(cmd)
 }
@@ -190,15 +276,7 @@ func shellEscape(s string) string {
 // runHook executes a shell command in the given working directory.
 // Errors are logged but never propagated.
 func runHook(command, workDir string) {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		// Use PowerShell for reliable path handling and command execution.
-		// -NoProfile avoids loading user profiles that could slow or alter execution.
-		// -Command takes the rest as a PowerShell script string.
-		cmd = exec.Command("powershell", "-NoProfile", "-Command", command)
-	} else {
-		cmd = exec.Command("sh", "-c", command)
-	}
+	cmd := shellCommand(context.Background(), command)
 	if workDir != "" {
 		cmd.Dir = workDir
 	}
@@ -212,3 +290,15 @@ func runHook(command, workDir string) {
 		log.Printf("Hook output (cmd=%q): %s", command, output)
 	}
 }
+
+// shellCommand returns a command that runs a hook's command line through
+// the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		// Use PowerShell for reliable path handling and command execution.
+		// -NoProfile avoids loading user profiles that could slow or alter execution.
+		// -Command takes the rest as a PowerShell script string.
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
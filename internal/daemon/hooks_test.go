@@ -71,14 +71,17 @@ func TestMatchEvent(t *testing.T) {
 
 func TestInterpolate(t *testing.T) {
 	event := Event{
-		JobID:    42,
-		Repo:     "/home/user/myrepo",
-		RepoName: "myrepo",
-		SHA:      "abc123def456",
-		Agent:    "codex",
-		Verdict:  "F",
-		Findings: "High — missing input validation in handler",
-		Error:    "agent timeout",
+		JobID:     42,
+		Repo:      "/home/user/myrepo",
+		RepoName:  "myrepo",
+		SHA:       "abc123def456",
+		Agent:     "codex",
+		Verdict:   "F",
+		Findings:  "High — missing input validation in handler",
+		QuickTake: "Fails: the handler skips input validation.",
+		Error:     "agent timeout",
+		Routes:    []string{"payments"},
+		Owners:    []string{"alice", "payments-team"},
 	}
 
 	tests := []struct {
@@ -101,6 +104,14 @@ func TestInterpolate(t *testing.T) {
 			"process {findings}",
 			"process " + q("High — missing input validation in handler"),
 		},
+		{
+			"notify {quick_take}",
+			"notify " + q("Fails: the handler skips input validation."),
+		},
+		{
+			"notify {routes} {owners}",
+			"notify " + q("payments") + " " + q("alice,payments-team"),
+		},
 		{
 			"",
 			"",
@@ -297,6 +308,72 @@ func TestBeadsCommandShortSHA(t *testing.T) {
 	}
 }
 
+func TestDesktopCommand(t *testing.T) {
+	event := Event{
+		Type:     "review.completed",
+		JobID:    7,
+		Repo:     "/home/user/myrepo",
+		RepoName: "it's-repo",
+		SHA:      "abcdef1234567890",
+		Verdict:  "F",
+	}
+
+	cmd := desktopCommand(event, "linux")
+	if !strings.HasPrefix(cmd, "notify-send -a roborev ") {
+		t.Errorf("expected notify-send on linux, got %q", cmd)
+	}
+	if !contains(cmd, "Review found issues") || !contains(cmd, "abcdef12") || !contains(cmd, "roborev show 7") {
+		t.Errorf("expected verdict, short SHA and show command, got %q", cmd)
+	}
+	if !contains(cmd, `it'"'"'s-repo`) {
+		t.Errorf("expected repo name shell-escaped, got %q", cmd)
+	}
+
+	cmd = desktopCommand(event, "darwin")
+	if !strings.HasPrefix(cmd, "osascript -e ") || !contains(cmd, "display notification") {
+		t.Errorf("expected osascript on macOS, got %q", cmd)
+	}
+
+	cmd = desktopCommand(event, "windows")
+	if !contains(cmd, "ToastNotificationManager") || !contains(cmd, "'Review found issues: it''s-repo (abcdef12)'") {
+		t.Errorf("expected toast with escaped title on windows, got %q", cmd)
+	}
+
+	event.Verdict = "P"
+	if cmd := desktopCommand(event, "linux"); !contains(cmd, "Review passed") {
+		t.Errorf("expected passing verdict, got %q", cmd)
+	}
+	event.QuickTake = "Passes; the retry loop now backs off."
+	if cmd := desktopCommand(event, "linux"); !contains(cmd, "the retry loop now backs off") || contains(cmd, "roborev show") {
+		t.Errorf("expected quick take as the body, got %q", cmd)
+	}
+	event.QuickTake = ""
+	event.Type = "review.failed"
+	if cmd := desktopCommand(event, "linux"); !contains(cmd, "Review failed") {
+		t.Errorf("expected failure notification, got %q", cmd)
+	}
+	event.Type = "review.overdue"
+	event.Error = "queued for 45m0s, past the 30m0s SLA"
+	if cmd := desktopCommand(event, "linux"); !contains(cmd, "Review overdue") || !contains(cmd, "past the 30m0s SLA") {
+		t.Errorf("expected overdue notification, got %q", cmd)
+	}
+	event.Type = "review.blocked"
+	event.Error = "no review agent available"
+	if cmd := desktopCommand(event, "linux"); !contains(cmd, "Review blocked") || !contains(cmd, "no review agent available") {
+		t.Errorf("expected blocked notification, got %q", cmd)
+	}
+	event.Type = "review.started"
+	if cmd := desktopCommand(event, "linux"); cmd != "" {
+		t.Errorf("expected no notification for %s, got %q", event.Type, cmd)
+	}
+}
+
+func TestAppleScriptString(t *testing.T) {
+	if got := appleScriptString(`say "hi" \ bye`); got != `"say \"hi\" \\ bye"` {
+		t.Errorf("appleScriptString = %s", got)
+	}
+}
+
 func TestResolveCommand(t *testing.T) {
 	event := Event{
 		Type:  "review.failed",
@@ -633,6 +710,54 @@ command = "`+touchCmd(markerFile)+`"
 	assertFileNotCreated(t, markerFile, 500*time.Millisecond, "repo hook fired for a different repo's event")
 }
 
+func TestHookRunnerRouteHooks(t *testing.T) {
+	// Route hooks fire only for jobs that matched the route
+	repoDir := t.TempDir()
+	routed := filepath.Join(repoDir, "routed")
+	other := filepath.Join(repoDir, "other")
+
+	writeRepoConfig(t, repoDir, `
+[[routes]]
+name = "payments"
+paths = ["services/payments/"]
+[[routes.hooks]]
+event = "review.completed"
+command = "`+touchCmd(routed)+`"
+
+[[routes]]
+name = "search"
+paths = ["services/search/"]
+[[routes.hooks]]
+event = "review.completed"
+command = "`+touchCmd(other)+`"
+`)
+
+	broadcaster := NewBroadcaster()
+	hr := NewHookRunner(NewStaticConfig(&config.Config{}), broadcaster)
+	defer hr.Stop()
+
+	broadcaster.Broadcast(Event{
+		Type:   "review.completed",
+		TS:     time.Now(),
+		JobID:  1,
+		Repo:   repoDir,
+		SHA:    "abc",
+		Routes: []string{"payments"},
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(routed); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("route hook did not fire")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assertFileNotCreated(t, other, 300*time.Millisecond, "hook of an unmatched route fired")
+}
+
 func TestHookRunnerStopUnsubscribes(t *testing.T) {
 	broadcaster := NewBroadcaster()
 	cfg := &config.Config{}
@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// defaultHotspotPeriod is the history /api/hotspots covers without since
+const defaultHotspotPeriod = 90 * 24 * time.Hour
+
+// Hotspot is a file ranked by how often it changes times how often reviews
+// flag it
+type Hotspot struct {
+	Path         string `json:"path"`
+	Commits      int    `json:"commits"`
+	LinesChanged int    `json:"lines_changed"`
+	Findings     int    `json:"findings"`
+	Score        int    `json:"score"` // Commits x findings
+}
+
+// HotspotsResponse is returned by GET /api/hotspots
+type HotspotsResponse struct {
+	Repo     string    `json:"repo"`
+	Since    time.Time `json:"since"`
+	Hotspots []Hotspot `json:"hotspots"`
+}
+
+// handleHotspots ranks a repo's files by churn times finding count over a
+// period. Parameters: repo (root path, required), since (RFC3339, default
+// 90 days ago) and limit (default 20, 0 for all).
+func (s *Server) handleHotspots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	repoPath := q.Get("repo")
+	if repoPath == "" {
+		writeError(w, http.StatusBadRequest, "repo is required")
+		return
+	}
+	since := time.Now().Add(-defaultHotspotPeriod)
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since (want RFC3339)")
+			return
+		}
+		since = t
+	}
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	repo, err := s.db.FindRepo(r.Context(), repoPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+	churn, err := git.GetChurn(repo.RootPath, since)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("read churn: %v", err))
+		return
+	}
+	findings, err := s.db.ListFindingsSince(repo.ID, since)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("list findings: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, HotspotsResponse{
+		Repo:     repo.RootPath,
+		Since:    since,
+		Hotspots: rankHotspots(repo.RootPath, churn, findings, limit),
+	})
+}
+
+// rankHotspots attributes each finding to the changed files it references
+// and ranks files by commits x findings, highest first. A reference that
+// matches several files (a bare "config.go") is not counted. Files without
+// findings are left out. limit 0 returns every hotspot.
+func rankHotspots(repoPath string, churn []git.FileChurn, findings []storage.Finding, limit int) []Hotspot {
+	byPath := make(map[string]*Hotspot, len(churn))
+	for _, c := range churn {
+		byPath[c.Path] = &Hotspot{Path: c.Path, Commits: c.Commits, LinesChanged: c.LinesChanged}
+	}
+
+	resolve := func(ref string) *Hotspot {
+		if h, ok := byPath[ref]; ok {
+			return h
+		}
+		var match *Hotspot
+		for p, h := range byPath {
+			if strings.HasSuffix(p, "/"+ref) {
+				if match != nil {
+					return nil
+				}
+				match = h
+			}
+		}
+		return match
+	}
+
+	for _, f := range findings {
+		counted := make(map[*Hotspot]bool)
+		for _, ref := range storage.ExtractFileRefs(f.Text, repoPath) {
+			if h := resolve(ref.Path); h != nil && !counted[h] {
+				h.Findings++
+				counted[h] = true
+			}
+		}
+	}
+
+	hotspots := []Hotspot{}
+	for _, h := range byPath {
+		if h.Findings == 0 {
+			continue
+		}
+		h.Score = h.Commits * h.Findings
+		hotspots = append(hotspots, *h)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return a.Path < b.Path
+	})
+	if limit > 0 && len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+	return hotspots
+}
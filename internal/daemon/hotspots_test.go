@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestRankHotspots(t *testing.T) {
+	churn := []git.FileChurn{
+		{Path: "internal/db/db.go", Commits: 5, LinesChanged: 120},
+		{Path: "internal/config/config.go", Commits: 2, LinesChanged: 30},
+		{Path: "cmd/config.go", Commits: 9, LinesChanged: 10},
+		{Path: "main.go", Commits: 4, LinesChanged: 4},
+		{Path: "README.md", Commits: 8, LinesChanged: 50},
+	}
+	findings := []storage.Finding{
+		{Text: "- High: race in internal/db/db.go:42 and again at db.go:50"},
+		{Text: "- Medium: db.go leaks a handle"},
+		{Text: "- Low: internal/config/config.go:3 typo"},
+		{Text: "- Low: config.go is ambiguous"},
+		{Text: "- Medium: main.go:10 ignores an error"},
+		{Text: "- High: removed.go:1 no longer exists"},
+	}
+
+	got := rankHotspots("/repo", churn, findings, 0)
+	want := []Hotspot{
+		{Path: "internal/db/db.go", Commits: 5, LinesChanged: 120, Findings: 2, Score: 10},
+		{Path: "main.go", Commits: 4, LinesChanged: 4, Findings: 1, Score: 4},
+		{Path: "internal/config/config.go", Commits: 2, LinesChanged: 30, Findings: 1, Score: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rankHotspots() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hotspot %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := rankHotspots("/repo", churn, findings, 1); len(got) != 1 || got[0].Path != "internal/db/db.go" {
+		t.Errorf("expected limit to keep the top hotspot, got %+v", got)
+	}
+}
+
+func TestHandleHotspots(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "hotspotrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", "- High: test.txt:1 is wrong")
+
+	get := func(q url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/hotspots?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+		server.handleHotspots(w, req)
+		return w
+	}
+
+	w := get(url.Values{"repo": {repoDir}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var resp HotspotsResponse
+	testutil.DecodeJSON(t, w, &resp)
+	if len(resp.Hotspots) != 1 || resp.Hotspots[0].Path != "test.txt" || resp.Hotspots[0].Score != 1 {
+		t.Errorf("unexpected hotspots %+v", resp.Hotspots)
+	}
+
+	// Nothing changed in the period
+	w = get(url.Values{"repo": {repoDir}, "since": {time.Now().Add(time.Hour).Format(time.RFC3339)}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	resp = HotspotsResponse{}
+	testutil.DecodeJSON(t, w, &resp)
+	if resp.Hotspots == nil || len(resp.Hotspots) != 0 {
+		t.Errorf("expected no hotspots, got %+v", resp.Hotspots)
+	}
+
+	testutil.AssertStatusCode(t, get(url.Values{}), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get(url.Values{"repo": {repoDir}, "since": {"yesterday"}}), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, get(url.Values{"repo": {"/nonexistent"}}), http.StatusNotFound)
+}
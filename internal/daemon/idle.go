@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// idleMonitor tracks API activity and signals once the daemon has been idle
+// for longer than timeout. The daemon counts as idle when no HTTP requests
+// are in flight (long-lived streams count as in flight), the job queue is
+// empty, and no request has arrived within the timeout window.
+type idleMonitor struct {
+	timeout    time.Duration
+	queueEmpty func() bool
+	now        func() time.Time
+
+	mu         sync.Mutex
+	lastActive time.Time
+	inFlight   int
+
+	idleCh   chan struct{}
+	idleOnce sync.Once
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func newIdleMonitor(timeout time.Duration, queueEmpty func() bool) *idleMonitor {
+	return &idleMonitor{
+		timeout:    timeout,
+		queueEmpty: queueEmpty,
+		now:        time.Now,
+		lastActive: time.Now(),
+		idleCh:     make(chan struct{}),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Wrap returns a handler that records request activity before delegating to h.
+func (m *idleMonitor) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.inFlight++
+		m.lastActive = m.now()
+		m.mu.Unlock()
+
+		defer func() {
+			m.mu.Lock()
+			m.inFlight--
+			m.lastActive = m.now()
+			m.mu.Unlock()
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// check reports whether the idle timeout has elapsed. A non-empty queue
+// counts as activity, so the timeout restarts once the last job finishes.
+func (m *idleMonitor) check() bool {
+	if !m.queueEmpty() {
+		m.mu.Lock()
+		m.lastActive = m.now()
+		m.mu.Unlock()
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight == 0 && m.now().Sub(m.lastActive) >= m.timeout
+}
+
+// Start polls for idleness until the monitor fires or is stopped.
+func (m *idleMonitor) Start() {
+	interval := m.timeout / 4
+	if interval > 30*time.Second {
+		interval = 30 * time.Second
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				if m.check() {
+					m.idleOnce.Do(func() { close(m.idleCh) })
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends polling. Safe to call more than once.
+func (m *idleMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+// Idle returns a channel that is closed when the idle timeout elapses.
+func (m *idleMonitor) Idle() <-chan struct{} {
+	return m.idleCh
+}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIdleMonitorCheck(t *testing.T) {
+	now := time.Unix(1000, 0)
+	empty := true
+	m := newIdleMonitor(10*time.Minute, func() bool { return empty })
+	m.now = func() time.Time { return now }
+	m.lastActive = now
+
+	if m.check() {
+		t.Fatal("expected not idle immediately after start")
+	}
+
+	now = now.Add(11 * time.Minute)
+	if !m.check() {
+		t.Fatal("expected idle after timeout with empty queue")
+	}
+
+	// A non-empty queue counts as activity and restarts the timer
+	empty = false
+	if m.check() {
+		t.Fatal("expected not idle while jobs are queued")
+	}
+	empty = true
+	now = now.Add(5 * time.Minute)
+	if m.check() {
+		t.Fatal("expected timer to restart after queue drained")
+	}
+	now = now.Add(5 * time.Minute)
+	if !m.check() {
+		t.Fatal("expected idle once timeout elapsed after queue drained")
+	}
+}
+
+func TestIdleMonitorInFlightRequests(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := newIdleMonitor(time.Minute, func() bool { return true })
+	m.now = func() time.Time { return now }
+	m.lastActive = now
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/stream/events", nil))
+		close(done)
+	}()
+	<-started
+
+	now = now.Add(2 * time.Minute)
+	if m.check() {
+		t.Fatal("expected not idle while a request is in flight")
+	}
+
+	close(release)
+	<-done
+	if m.check() {
+		t.Fatal("expected request completion to count as activity")
+	}
+	now = now.Add(2 * time.Minute)
+	if !m.check() {
+		t.Fatal("expected idle after timeout following request completion")
+	}
+}
+
+func TestServerIdleShutdownDisabled(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	if server.IdleShutdown() != nil {
+		t.Error("expected nil idle channel when idle shutdown is not configured")
+	}
+}
+
+func TestSocketActivationListenerNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+
+	ln, err := socketActivationListener()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ln != nil {
+		ln.Close()
+		t.Error("expected no listener without LISTEN_FDS")
+	}
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"log"
+	"slices"
+
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// injectionPatterns returns the prompt-injection patterns found in the diff
+// a review prompt embeds. The join of a fanned-out review has no diff of its
+// own and inherits the patterns found in its parts.
+func (wp *WorkerPool) injectionPatterns(reviewPrompt string, parts []storage.JobPart) []string {
+	if len(parts) == 0 {
+		return prompt.ScanUntrusted(reviewPrompt)
+	}
+	var patterns []string
+	for _, p := range parts {
+		risk, err := wp.db.GetInjectionRisk(p.JobID)
+		if err != nil {
+			continue
+		}
+		for _, name := range risk.Patterns {
+			if !slices.Contains(patterns, name) {
+				patterns = append(patterns, name)
+			}
+		}
+	}
+	return patterns
+}
+
+// recordInjectionRisk flags a completed review whose diff matched injection
+// patterns, noting whether the review output looks affected
+func (wp *WorkerPool) recordInjectionRisk(workerID string, job *storage.ReviewJob, patterns []string, output string) {
+	if len(patterns) == 0 {
+		return
+	}
+	risk := storage.InjectionRisk{
+		JobID:      job.ID,
+		Patterns:   patterns,
+		OutputNote: prompt.CheckReviewOutput(output, patterns),
+	}
+	if risk.OutputNote != "" {
+		log.Printf("[%s] Job %d: possible prompt injection (%v): %s", workerID, job.ID, patterns, risk.OutputNote)
+	}
+	if err := wp.db.SaveInjectionRisk(risk); err != nil {
+		log.Printf("[%s] Error saving injection risk for job %d: %v", workerID, job.ID, err)
+	}
+}
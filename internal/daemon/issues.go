@@ -0,0 +1,250 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// issueTimeout bounds the creation of one tracker issue
+const issueTimeout = 60 * time.Second
+
+// trackerIssue is an issue to file for a finding
+type trackerIssue struct {
+	Title string
+	Body  string
+}
+
+// issueFileFunc creates an issue in the configured tracker and returns its URL
+type issueFileFunc func(ctx context.Context, cfg config.AutoIssuesConfig, repoPath string, issue trackerIssue) (string, error)
+
+// IssueFiler listens for completed reviews and files tracker issues for
+// findings at or above the repo's auto_issues severity. A finding whose
+// fingerprint was already filed in the repo gets the existing issue.
+type IssueFiler struct {
+	db          *storage.DB
+	broadcaster Broadcaster
+	subID       int
+	stopCh      chan struct{}
+	file        issueFileFunc
+}
+
+// NewIssueFiler creates an IssueFiler that subscribes to events from the broadcaster.
+func NewIssueFiler(db *storage.DB, broadcaster Broadcaster) *IssueFiler {
+	subID, eventCh := broadcaster.Subscribe("")
+
+	f := &IssueFiler{
+		db:          db,
+		broadcaster: broadcaster,
+		subID:       subID,
+		stopCh:      make(chan struct{}),
+		file:        fileTrackerIssue,
+	}
+
+	go f.listen(eventCh)
+
+	return f
+}
+
+// listen files issues for completed reviews one at a time, so two reviews
+// with the same finding cannot both file it
+func (f *IssueFiler) listen(eventCh <-chan Event) {
+	for {
+		select {
+		case <-f.stopCh:
+			return
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			if event.Type == "review.completed" {
+				f.fileIssues(event.JobID, event.Repo)
+			}
+		}
+	}
+}
+
+// Stop shuts down the issue filer and unsubscribes from the broadcaster.
+func (f *IssueFiler) Stop() {
+	close(f.stopCh)
+	f.broadcaster.Unsubscribe(f.subID)
+}
+
+// fileIssues files issues for the severe findings of a job's review that
+// .roborev-ignore does not suppress. Failures are logged; a finding that
+// failed is retried by the next review that reports it.
+func (f *IssueFiler) fileIssues(jobID int64, repoPath string) {
+	cfg, err := config.ResolveAutoIssues(repoPath)
+	if err != nil {
+		log.Printf("Auto issues: %v", err)
+		return
+	}
+	if cfg.Tracker == "" {
+		return
+	}
+	review, err := f.db.GetReviewByJobID(context.Background(), jobID)
+	if err != nil || review.Job == nil || review.Job.IsTaskJob() {
+		return
+	}
+
+	suppressed, err := f.db.GetFindingSuppressions(review.ID)
+	if err != nil {
+		log.Printf("Auto issues: job %d: %v", jobID, err)
+		return
+	}
+
+	for _, finding := range storage.ExtractFindings(review.Output) {
+		if _, ok := suppressed[finding.Index]; ok || !storage.SeverityAtLeast(finding.Severity, cfg.MinSeverity) {
+			continue
+		}
+		fingerprint := storage.FindingFingerprint(finding.Text)
+		url, err := f.db.GetFindingIssueURL(review.Job.RepoID, fingerprint)
+		if err != nil {
+			log.Printf("Auto issues: job %d: %v", jobID, err)
+			return
+		}
+		if url == "" {
+			ctx, cancel := context.WithTimeout(context.Background(), issueTimeout)
+			url, err = f.file(ctx, cfg, repoPath, findingIssue(review, finding, fingerprint))
+			cancel()
+			if err != nil {
+				log.Printf("Auto issues: job %d finding %d: %v", jobID, finding.Index, err)
+				continue
+			}
+			log.Printf("Auto issues: filed %s for job %d finding %d", url, jobID, finding.Index)
+		}
+		if err := f.db.SaveFindingIssue(review.ID, finding.Index, review.Job.RepoID, fingerprint, url); err != nil {
+			log.Printf("Auto issues: job %d: %v", jobID, err)
+		}
+	}
+}
+
+// findingIssue builds the issue for a finding of a review
+func findingIssue(review *storage.Review, finding storage.Finding, fingerprint string) trackerIssue {
+	summary, _, _ := strings.Cut(finding.Text, "\n")
+	summary = strings.TrimSpace(strings.TrimLeft(summary, "-*•0123456789.) "))
+	summary = strings.NewReplacer("**", "", "`", "").Replace(summary)
+	if len(summary) > 100 {
+		summary = strings.ToValidUTF8(summary[:97], "") + "..."
+	}
+
+	var body strings.Builder
+	body.WriteString(finding.Text)
+	fmt.Fprintf(&body, "\n\n---\nFound by roborev (%s) reviewing %s in %s, job %d.\n",
+		review.Agent, shortRef(review.Job.GitRef), review.Job.RepoName, review.JobID)
+	fmt.Fprintf(&body, "Finding fingerprint: %s\n", fingerprint)
+	return trackerIssue{Title: "[roborev] " + summary, Body: body.String()}
+}
+
+// fileTrackerIssue creates an issue with gh, glab or the Jira REST API
+func fileTrackerIssue(ctx context.Context, cfg config.AutoIssuesConfig, repoPath string, issue trackerIssue) (string, error) {
+	if cfg.Tracker == "jira" {
+		return fileJiraIssue(ctx, http.DefaultClient, cfg, issue,
+			os.Getenv("JIRA_EMAIL"), os.Getenv("JIRA_API_TOKEN"))
+	}
+	args := trackerCommand(cfg, issue)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s issue create: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	url := issueURLFromOutput(string(out))
+	if url == "" {
+		return "", fmt.Errorf("%s issue create printed no URL: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return url, nil
+}
+
+// trackerCommand returns the gh or glab command line creating an issue
+func trackerCommand(cfg config.AutoIssuesConfig, issue trackerIssue) []string {
+	if cfg.Tracker == "gitlab" {
+		args := []string{"glab", "issue", "create", "--yes", "--title", issue.Title, "--description", issue.Body}
+		if len(cfg.Labels) > 0 {
+			args = append(args, "--label", strings.Join(cfg.Labels, ","))
+		}
+		if cfg.Project != "" {
+			args = append(args, "--repo", cfg.Project)
+		}
+		return args
+	}
+	args := []string{"gh", "issue", "create", "--title", issue.Title, "--body", issue.Body}
+	for _, l := range cfg.Labels {
+		args = append(args, "--label", l)
+	}
+	if cfg.Project != "" {
+		args = append(args, "--repo", cfg.Project)
+	}
+	return args
+}
+
+// issueURLFromOutput returns the last URL gh or glab printed
+func issueURLFromOutput(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		for _, field := range strings.Fields(lines[i]) {
+			if strings.HasPrefix(field, "https://") || strings.HasPrefix(field, "http://") {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// fileJiraIssue creates a Jira issue through the REST API and returns its
+// browse URL
+func fileJiraIssue(ctx context.Context, client *http.Client, cfg config.AutoIssuesConfig, issue trackerIssue, email, token string) (string, error) {
+	if email == "" || token == "" {
+		return "", fmt.Errorf("JIRA_EMAIL and JIRA_API_TOKEN must be set for jira auto issues")
+	}
+	fields := map[string]any{
+		"project":     map[string]string{"key": cfg.Project},
+		"summary":     issue.Title,
+		"description": issue.Body,
+		"issuetype":   map[string]string{"name": cfg.JiraIssueType},
+	}
+	if len(cfg.Labels) > 0 {
+		fields["labels"] = cfg.Labels
+	}
+	data, err := json.Marshal(map[string]any{"fields": fields})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.JiraURL+"/rest/api/2/issue", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(email, token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("jira returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("decode jira response: %w", err)
+	}
+	if created.Key == "" {
+		return "", fmt.Errorf("jira response has no issue key")
+	}
+	return cfg.JiraURL + "/browse/" + created.Key, nil
+}
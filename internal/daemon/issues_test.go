@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestIssueFilerFilesSevereFindingsOnce(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, ".roborev.toml"),
+		[]byte("[auto_issues]\ntracker = \"github\"\nmin_severity = \"high\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := db.GetOrCreateRepo(t.Context(), repoPath)
+
+	var filed []trackerIssue
+	f := &IssueFiler{db: db, file: func(ctx context.Context, cfg config.AutoIssuesConfig, dir string, issue trackerIssue) (string, error) {
+		if cfg.Tracker != "github" || dir != repoPath {
+			t.Errorf("unexpected tracker %q in %s", cfg.Tracker, dir)
+		}
+		filed = append(filed, issue)
+		return fmt.Sprintf("https://github.com/o/r/issues/%d", len(filed)), nil
+	}}
+
+	complete := func(sha, output string) int64 {
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"})
+		db.ClaimJob(t.Context(), "w")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatal(err)
+		}
+		return job.ID
+	}
+
+	first := complete("aaa111", "- **Critical**: SQL injection in db.go:42\n\n- Low: typo in comment\n\n- High: unchecked error in main.go:7")
+	f.fileIssues(first, repoPath)
+	if len(filed) != 2 {
+		t.Fatalf("filed %d issues, want critical and high only", len(filed))
+	}
+	if filed[0].Title != "[roborev] Critical: SQL injection in db.go:42" {
+		t.Errorf("unexpected title %q", filed[0].Title)
+	}
+	if !strings.Contains(filed[0].Body, "job ") || !strings.Contains(filed[0].Body, "Finding fingerprint: ") {
+		t.Errorf("unexpected body %q", filed[0].Body)
+	}
+
+	// The same finding at another line is linked to the existing issue
+	second := complete("bbb222", "- Critical: SQL injection in db.go:57")
+	f.fileIssues(second, repoPath)
+	if len(filed) != 2 {
+		t.Errorf("filed %d issues, want the duplicate finding deduplicated", len(filed))
+	}
+	items, _, err := db.ListUntriagedFindings(repo.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.JobID == second && item.Finding.IssueURL != "https://github.com/o/r/issues/1" {
+			t.Errorf("expected the duplicate finding to get issue 1, got %q", item.Finding.IssueURL)
+		}
+	}
+}
+
+func TestTrackerCommand(t *testing.T) {
+	issue := trackerIssue{Title: "t", Body: "b"}
+	gh := trackerCommand(config.AutoIssuesConfig{Tracker: "github", Labels: []string{"security", "roborev"}, Project: "o/r"}, issue)
+	if got := strings.Join(gh, " "); got != "gh issue create --title t --body b --label security --label roborev --repo o/r" {
+		t.Errorf("gh command = %q", got)
+	}
+	glab := trackerCommand(config.AutoIssuesConfig{Tracker: "gitlab", Labels: []string{"security", "roborev"}}, issue)
+	if got := strings.Join(glab, " "); got != "glab issue create --yes --title t --description b --label security,roborev" {
+		t.Errorf("glab command = %q", got)
+	}
+	if url := issueURLFromOutput("Creating issue in o/r\n\nhttps://github.com/o/r/issues/9\n"); url != "https://github.com/o/r/issues/9" {
+		t.Errorf("issueURLFromOutput = %q", url)
+	}
+}
+
+func TestFileJiraIssue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Fields struct {
+				Project   struct{ Key string }  `json:"project"`
+				IssueType struct{ Name string } `json:"issuetype"`
+			} `json:"fields"`
+		}
+		if r.URL.Path != "/rest/api/2/issue" || json.NewDecoder(r.Body).Decode(&req) != nil ||
+			req.Fields.Project.Key != "SEC" || req.Fields.IssueType.Name != "Bug" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"key":"SEC-12"}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.AutoIssuesConfig{Tracker: "jira", JiraURL: srv.URL, Project: "SEC", JiraIssueType: "Bug"}
+	url, err := fileJiraIssue(context.Background(), srv.Client(), cfg, trackerIssue{Title: "t", Body: "b"}, "me@example.com", "secret")
+	if err != nil || url != srv.URL+"/browse/SEC-12" {
+		t.Errorf("fileJiraIssue = %q, %v", url, err)
+	}
+	if _, err := fileJiraIssue(context.Background(), srv.Client(), cfg, trackerIssue{}, "me@example.com", "wrong"); err == nil {
+		t.Error("expected an error for rejected credentials")
+	}
+	if _, err := fileJiraIssue(context.Background(), srv.Client(), cfg, trackerIssue{}, "", ""); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// MirrorRequest asks the daemon to sync its mirror of a hosted repository,
+// typically a bare repository on a self-hosted git server.
+type MirrorRequest struct {
+	Source string `json:"source"` // Absolute path or URL of the hosted repo
+}
+
+// MirrorResponse is returned by POST /api/mirror
+type MirrorResponse struct {
+	Path string `json:"path"` // Mirror to pass as repo_path when enqueueing
+}
+
+// mirrorDir returns where the mirror of source is kept. The directory name
+// combines the repo name with a hash of the source so different hosted
+// repos with the same name do not collide.
+func mirrorDir(source string) string {
+	name := strings.TrimSuffix(filepath.Base(strings.TrimRight(source, "/")), ".git")
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(config.MirrorsDir(), name+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// handleMirror clones the source repo on first use and fetches all of its
+// branches and tags afterwards. Mirrors are clones owned by the daemon with
+// nothing checked out, so reviews can run against repos that have no
+// working tree of their own.
+func (s *Server) handleMirror(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req MirrorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		writeError(w, http.StatusBadRequest, "source is required")
+		return
+	}
+	if !strings.Contains(source, "://") {
+		if !filepath.IsAbs(source) {
+			writeError(w, http.StatusBadRequest, "source must be an absolute path or URL")
+			return
+		}
+		source = filepath.Clean(source)
+	}
+
+	dir := mirrorDir(source)
+
+	s.mirrorMu.Lock()
+	defer s.mirrorMu.Unlock()
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if err := git.FetchAllBranches(dir); err != nil {
+			s.writeInternalError(w, fmt.Sprintf("update mirror: %v", err))
+			return
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			s.writeInternalError(w, fmt.Sprintf("create mirror dir: %v", err))
+			return
+		}
+		// Clear out any partial clone left by an earlier failure
+		os.RemoveAll(dir)
+		if err := git.CloneNoCheckout(source, dir); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("clone %s: %v", source, err))
+			return
+		}
+		// Clones of local repos may only have the default branch locally
+		if err := git.FetchAllBranches(dir); err != nil {
+			s.writeInternalError(w, fmt.Sprintf("update mirror: %v", err))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, MirrorResponse{Path: dir})
+}
@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleMirror(t *testing.T) {
+	server, _, tmpDir := newTestServer(t)
+	t.Setenv("ROBOREV_DATA_DIR", tmpDir)
+
+	workDir, run := createTestGitRepo(t)
+	bareDir := filepath.Join(filepath.Dir(workDir), "hosted.git")
+	run("clone", "--quiet", "--bare", workDir, bareDir)
+
+	mirror := func() string {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/mirror", MirrorRequest{Source: bareDir})
+		w := httptest.NewRecorder()
+		server.handleMirror(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var resp MirrorResponse
+		testutil.DecodeJSON(t, w, &resp)
+		return resp.Path
+	}
+
+	path := mirror()
+	if !strings.HasPrefix(path, filepath.Join(tmpDir, "mirrors", "hosted-")) {
+		t.Errorf("unexpected mirror path %s", path)
+	}
+	if _, err := git.GetRepoRoot(path); err != nil {
+		t.Fatalf("mirror is not a usable repo: %v", err)
+	}
+
+	// Push a new branch to the hosted repo; the next sync must pick it up
+	run("checkout", "--quiet", "-b", "feature")
+	run("commit", "--quiet", "--allow-empty", "-m", "feature work")
+	run("push", "--quiet", bareDir, "feature")
+
+	if again := mirror(); again != path {
+		t.Errorf("mirror path changed: %s != %s", again, path)
+	}
+	out, err := exec.Command("git", "-C", path, "log", "-1", "--format=%s", "feature").Output()
+	if err != nil {
+		t.Fatalf("feature branch missing from mirror: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "feature work" {
+		t.Errorf("mirror feature tip = %q", got)
+	}
+}
+
+func TestHandleMirrorRejectsRelativeSource(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/mirror", MirrorRequest{Source: "repo.git"})
+	w := httptest.NewRecorder()
+	server.handleMirror(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+}
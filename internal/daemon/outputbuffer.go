@@ -204,6 +204,24 @@ func (ob *OutputBuffer) IsActive(jobID int64) bool {
 	return ok
 }
 
+// LastAppend returns when the job's newest output line was written, or the
+// zero time if it has none.
+func (ob *OutputBuffer) LastAppend(jobID int64) time.Time {
+	ob.mu.RLock()
+	jo, ok := ob.buffers[jobID]
+	ob.mu.RUnlock()
+	if !ok {
+		return time.Time{}
+	}
+
+	jo.mu.RLock()
+	defer jo.mu.RUnlock()
+	if len(jo.lines) == 0 {
+		return time.Time{}
+	}
+	return jo.lines[len(jo.lines)-1].Timestamp
+}
+
 // OutputNormalizer converts agent-specific output to normalized OutputLines.
 type OutputNormalizer func(line string) *OutputLine
 
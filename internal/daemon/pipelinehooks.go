@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// maxPipelineHookOutput is the most of a pre_prompt hook's output added to
+// a review prompt
+const maxPipelineHookOutput = 64 << 10
+
+// pipelineHookWaitDelay bounds how long a timed out hook's leftover child
+// processes may keep its output open
+const pipelineHookWaitDelay = 5 * time.Second
+
+// pipelineHookInput is the JSON a pipeline hook receives on stdin
+type pipelineHookInput struct {
+	Stage         string `json:"stage"`
+	JobID         int64  `json:"job_id"`
+	ParentJobID   int64  `json:"parent_job_id,omitempty"` // Join job when the job is part of a fanned-out review
+	Repo          string `json:"repo"`
+	RepoName      string `json:"repo_name"`
+	GitRef        string `json:"git_ref"`
+	Branch        string `json:"branch,omitempty"`
+	CommitSubject string `json:"commit_subject,omitempty"`
+	JobType       string `json:"job_type"`
+	ReviewType    string `json:"review_type,omitempty"`
+	Agent         string `json:"agent"`
+	Model         string `json:"model,omitempty"`
+
+	// Set for post_review
+	Output    string `json:"output,omitempty"`
+	Verdict   string `json:"verdict,omitempty"`
+	QuickTake string `json:"quick_take,omitempty"`
+}
+
+// newPipelineHookInput describes a job to the hooks of a stage
+func newPipelineHookInput(stage string, job *storage.ReviewJob, agentName string) pipelineHookInput {
+	return pipelineHookInput{
+		Stage:         stage,
+		JobID:         job.ID,
+		ParentJobID:   job.ParentJobID,
+		Repo:          job.RepoPath,
+		RepoName:      job.RepoName,
+		GitRef:        job.GitRef,
+		Branch:        job.Branch,
+		CommitSubject: job.CommitSubject,
+		JobType:       job.JobType,
+		ReviewType:    job.ReviewType,
+		Agent:         agentName,
+		Model:         job.Model,
+	}
+}
+
+// runPipelineHook runs a pipeline hook in dir with input as JSON on stdin,
+// and returns what it wrote to stdout. The hook is killed when ctx is done.
+func runPipelineHook(ctx context.Context, hook config.PipelineHookConfig, dir string, input pipelineHookInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	timeout := hook.TimeoutDuration()
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(hookCtx, hook.Command)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.WaitDelay = pipelineHookWaitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("interrupted: %w", ctx.Err())
+		}
+		if hookCtx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %s", timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// prePromptContext runs the pre_prompt pipeline hooks of a job and returns
+// their output as a prompt section. A hook that fails is logged and left
+// out; the review goes ahead without its context. Hooks still running when
+// ctx is done are killed.
+func (wp *WorkerPool) prePromptContext(ctx context.Context, workerID string, job *storage.ReviewJob, cfg *config.Config) string {
+	hooks := config.ResolvePipelineHooks(job.RepoPath, cfg, config.PipelineStagePrePrompt)
+	if len(hooks) == 0 {
+		return ""
+	}
+	input := newPipelineHookInput(config.PipelineStagePrePrompt, job, job.Agent)
+	var outputs []string
+	for _, hook := range hooks {
+		out, err := runPipelineHook(ctx, hook, job.RepoPath, input)
+		if err != nil {
+			log.Printf("[%s] pre_prompt hook %q failed for job %d: %v", workerID, hook.Command, job.ID, err)
+			continue
+		}
+		if len(out) > maxPipelineHookOutput {
+			out = strings.ToValidUTF8(out[:maxPipelineHookOutput], "")
+		}
+		outputs = append(outputs, out)
+	}
+	return prompt.HookContext(outputs)
+}
+
+// startPostReviewHooks runs the post_review pipeline hooks of a finished
+// review in the background. Stop waits for them, killing any still running.
+func (wp *WorkerPool) startPostReviewHooks(job *storage.ReviewJob, cfg *config.Config, agentName, output, verdict, quickTake string) {
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		wp.runPostReviewHooks(job, cfg, agentName, output, verdict, quickTake)
+	}()
+}
+
+// runPostReviewHooks passes a finished review to the post_review pipeline
+// hooks of its job, one after another. Failures are logged, and hooks not
+// yet run when the pool stops are skipped.
+func (wp *WorkerPool) runPostReviewHooks(job *storage.ReviewJob, cfg *config.Config, agentName, output, verdict, quickTake string) {
+	hooks := config.ResolvePipelineHooks(job.RepoPath, cfg, config.PipelineStagePostReview)
+	if len(hooks) == 0 {
+		return
+	}
+	input := newPipelineHookInput(config.PipelineStagePostReview, job, agentName)
+	input.Output = output
+	input.Verdict = verdict
+	input.QuickTake = quickTake
+	for _, hook := range hooks {
+		if wp.stopCtx.Err() != nil {
+			log.Printf("post_review hook %q skipped for job %d: worker pool stopped", hook.Command, job.ID)
+			continue
+		}
+		if _, err := runPipelineHook(wp.stopCtx, hook, job.RepoPath, input); err != nil {
+			log.Printf("post_review hook %q failed for job %d: %v", hook.Command, job.ID, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestRunPipelineHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	job := &storage.ReviewJob{ID: 7, RepoPath: t.TempDir(), RepoName: "repo", GitRef: "abc123", Branch: "main", JobType: storage.JobTypeReview}
+	input := newPipelineHookInput(config.PipelineStagePrePrompt, job, "codex")
+
+	out, err := runPipelineHook(t.Context(), config.PipelineHookConfig{Command: "cat"}, job.RepoPath, input)
+	if err != nil {
+		t.Fatalf("runPipelineHook: %v", err)
+	}
+	var got pipelineHookInput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("hook did not receive JSON: %v\n%s", err, out)
+	}
+	if got != input {
+		t.Errorf("hook received %+v, want %+v", got, input)
+	}
+
+	_, err = runPipelineHook(t.Context(), config.PipelineHookConfig{Command: "echo broken >&2; exit 3"}, job.RepoPath, input)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the failure with its stderr, got %v", err)
+	}
+	_, err = runPipelineHook(t.Context(), config.PipelineHookConfig{Command: "exec sleep 5", Timeout: "100ms"}, job.RepoPath, input)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	_, err = runPipelineHook(ctx, config.PipelineHookConfig{Command: "exec sleep 5"}, job.RepoPath, input)
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("expected the hook to be interrupted, got %v", err)
+	}
+}
+
+func TestPostReviewHooksStopWithPool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	tc := newWorkerTestContext(t, 1)
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "review.json")
+	job := &storage.ReviewJob{ID: 3, RepoPath: dir, GitRef: "abc123", JobType: storage.JobTypeReview, Agent: "test"}
+
+	// The pool waits for hooks running in the background
+	cfg := config.DefaultConfig()
+	cfg.PipelineHooks = []config.PipelineHookConfig{
+		{Stage: config.PipelineStagePostReview, Command: "sleep 0.2; cat > " + outFile},
+	}
+	tc.Pool.startPostReviewHooks(job, cfg, "codex", "No issues found.", "P", "")
+	tc.Pool.wg.Wait()
+	if _, err := os.Stat(outFile); err != nil {
+		t.Fatalf("expected the hook to finish before the wait returned: %v", err)
+	}
+
+	// Stopping the pool kills hooks still running and skips the rest
+	cfg.PipelineHooks = []config.PipelineHookConfig{
+		{Stage: config.PipelineStagePostReview, Command: "exec sleep 30"},
+		{Stage: config.PipelineStagePostReview, Command: "cat > " + outFile + ".2"},
+	}
+	tc.Pool.startPostReviewHooks(job, cfg, "codex", "No issues found.", "P", "")
+	start := time.Now()
+	tc.Pool.Stop()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Stop waited %s for a hook", elapsed)
+	}
+	if _, err := os.Stat(outFile + ".2"); !os.IsNotExist(err) {
+		t.Errorf("expected the hook after the stop to be skipped, got %v", err)
+	}
+}
+
+func TestPipelineHooksInWorker(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	tc := newWorkerTestContext(t, 1)
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "review.json")
+	cfg := config.DefaultConfig()
+	cfg.PipelineHooks = []config.PipelineHookConfig{
+		{Stage: config.PipelineStagePrePrompt, Command: "cat >/dev/null; echo 'Ticket PAY-12: refunds must be idempotent'"},
+		{Stage: config.PipelineStagePrePrompt, Command: "exit 1"},
+		{Stage: config.PipelineStagePostReview, Command: "cat > " + outFile},
+	}
+	job := &storage.ReviewJob{ID: 3, RepoPath: dir, GitRef: "abc123", JobType: storage.JobTypeReview, Agent: "test"}
+
+	// A failing hook is left out of the context
+	extra := tc.Pool.prePromptContext(t.Context(), "w1", job, cfg)
+	if !strings.Contains(extra, "Additional Context") || !strings.Contains(extra, "Ticket PAY-12") {
+		t.Errorf("expected the hook's output as context, got %q", extra)
+	}
+
+	tc.Pool.runPostReviewHooks(job, cfg, "codex", "No issues found.", "P", "Passes.")
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("post_review hook did not run: %v", err)
+	}
+	var got pipelineHookInput
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode hook input: %v", err)
+	}
+	if got.Stage != config.PipelineStagePostReview || got.Agent != "codex" || got.Output != "No issues found." || got.Verdict != "P" || got.QuickTake != "Passes." {
+		t.Errorf("post_review hook received %+v", got)
+	}
+
+	if extra := tc.Pool.prePromptContext(t.Context(), "w1", job, config.DefaultConfig()); extra != "" {
+		t.Errorf("expected no context without hooks, got %q", extra)
+	}
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// preflight returns why a claimed job cannot run as things stand, or "" if
+// nothing is known to be wrong. Such a job would fail the same way on every
+// attempt, so it is blocked rather than retried. repoWide reports whether
+// the cause holds for every job of the repo.
+func (wp *WorkerPool) preflight(job *storage.ReviewJob, cfg *config.Config) (reason string, repoWide bool) {
+	if reason := missingRepoReason(job.RepoPath); reason != "" {
+		return reason, true
+	}
+	if reason := unreachableRepoReason(job.RepoPath); reason != "" {
+		return reason, true
+	}
+	if reason := lowDiskReason(cfg.MinFreeDisk()); reason != "" {
+		return reason, false
+	}
+	if _, err := agent.GetAvailable(job.Agent); err != nil {
+		return fmt.Sprintf("no review agent available: %v", err), false
+	}
+	return "", false
+}
+
+// unreachableRepoReason returns why the repo at path cannot be read although
+// it exists, such as lost permissions or an unmounted volume, or "" if it can
+func unreachableRepoReason(path string) string {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ""
+	}
+	if err == nil {
+		_, err = f.Readdirnames(1)
+		f.Close()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Sprintf("repository path %s is not readable: %v", path, err)
+	}
+	return ""
+}
+
+// lowDiskReason returns why jobs cannot run when the temp directory, where
+// agent output spills, or the data directory, where reviews are stored, has
+// less than min bytes free, or "" if both have enough (or cannot be checked)
+func lowDiskReason(min uint64) string {
+	if min == 0 {
+		return ""
+	}
+	for _, dir := range []string{os.TempDir(), config.DataDir()} {
+		free, err := diskFree(dir)
+		if err != nil {
+			continue
+		}
+		if free < min {
+			return fmt.Sprintf("only %d MB free in %s, below the %d MB needed (min_free_disk_mb)", free>>20, dir, min>>20)
+		}
+	}
+	return ""
+}
+
+// blockUnrunnableJob blocks a claimed job that fails its preflight checks,
+// along with the rest of its repo's queue when the cause is the repo.
+// Reports whether the job was blocked.
+func (wp *WorkerPool) blockUnrunnableJob(workerID string, job *storage.ReviewJob, cfg *config.Config) bool {
+	if wp.db == nil {
+		return false // Only the local database can hold jobs back
+	}
+	reason, repoWide := wp.preflight(job, cfg)
+	if reason == "" {
+		return false
+	}
+	log.Printf("[%s] Blocking job %d: %s", workerID, job.ID, reason)
+	if err := wp.db.BlockJob(job.ID, reason); err != nil {
+		log.Printf("[%s] Error blocking job %d: %v", workerID, job.ID, err)
+	}
+	if repoWide {
+		wp.blockRepo(job.RepoID, job.RepoName, reason)
+	} else if wp.errorLog != nil {
+		wp.errorLog.LogError("worker", fmt.Sprintf("job %d: %s", job.ID, reason), job.ID)
+	}
+	wp.broadcast(job, Event{
+		Type:     "review.blocked",
+		TS:       time.Now(),
+		JobID:    job.ID,
+		Repo:     job.RepoPath,
+		RepoName: job.RepoName,
+		SHA:      job.GitRef,
+		Agent:    job.Agent,
+		Error:    reason,
+	})
+	return true
+}
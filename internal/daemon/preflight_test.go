@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestBlockUnrunnableJobWithoutAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake codex is a shell script")
+	}
+	tc := newWorkerTestContext(t, 1)
+	job := tc.createAndClaimJob(t, "sha1", "worker-0")
+	other := tc.createJob(t, "sha2")
+	if _, err := tc.DB.Exec(`UPDATE review_jobs SET agent = 'codex' WHERE id = ?`, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	job, err := tc.DB.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, events := tc.Broadcaster.Subscribe("")
+
+	// No agent command on PATH and no API settings
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "")
+	cfg := tc.Pool.cfgGetter.Config()
+	if !tc.Pool.blockUnrunnableJob("worker-0", job, cfg) {
+		t.Fatal("expected job without an agent to be blocked")
+	}
+	blocked, err := tc.DB.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocked.Status != storage.JobStatusBlocked || blocked.RetryCount != 0 || !strings.Contains(blocked.Error, "no review agent available") {
+		t.Errorf("expected blocked job with reason and no retry spent, got %s (retries %d) %q", blocked.Status, blocked.RetryCount, blocked.Error)
+	}
+	// Other jobs of the repo use the test agent and are left alone
+	if j, _ := tc.DB.GetJobByID(t.Context(), other.ID); j.Status != storage.JobStatusQueued {
+		t.Errorf("expected other job to stay queued, got %s", j.Status)
+	}
+	select {
+	case ev := <-events:
+		if ev.Type != "review.blocked" || ev.JobID != job.ID || ev.Error != blocked.Error {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a review.blocked event")
+	}
+
+	// Still blocked while nothing changed
+	tc.Pool.checkRepoPaths()
+	if j, _ := tc.DB.GetJobByID(t.Context(), job.ID); j.Status != storage.JobStatusBlocked {
+		t.Errorf("expected job to stay blocked, got %s", j.Status)
+	}
+
+	// Requeued once the agent is installed
+	restore := testutil.MockBinaryInPath(t, "codex", "#!/bin/sh\nexit 0\n")
+	defer restore()
+	tc.Pool.checkRepoPaths()
+	if j, _ := tc.DB.GetJobByID(t.Context(), job.ID); j.Status != storage.JobStatusQueued || j.Error != "" {
+		t.Errorf("expected job to be requeued, got %s %q", j.Status, j.Error)
+	}
+}
+
+func TestBlockUnrunnableJobLowDisk(t *testing.T) {
+	tc := newWorkerTestContext(t, 1)
+	job := tc.createAndClaimJob(t, "sha1", "worker-0")
+	job.RepoPath = tc.TmpDir
+
+	cfg := tc.Pool.cfgGetter.Config()
+	cfg.MinFreeDiskMB = 1 << 30 // A petabyte, more than any disk
+	if !tc.Pool.blockUnrunnableJob("worker-0", job, cfg) {
+		t.Fatal("expected job to be blocked on low disk space")
+	}
+	if j, _ := tc.DB.GetJobByID(t.Context(), job.ID); j.Status != storage.JobStatusBlocked || !strings.Contains(j.Error, "min_free_disk_mb") {
+		t.Errorf("expected blocked job naming the setting, got %s %q", j.Status, j.Error)
+	}
+
+	cfg.MinFreeDiskMB = -1
+	if reason, _ := tc.Pool.preflight(job, cfg); reason != "" {
+		t.Errorf("expected disabled disk check to pass, got %q", reason)
+	}
+}
+
+func TestUnreachableRepoReason(t *testing.T) {
+	dir := t.TempDir()
+	if reason := unreachableRepoReason(dir); reason != "" {
+		t.Errorf("expected readable repo to pass, got %q", reason)
+	}
+	if reason := unreachableRepoReason(filepath.Join(dir, "gone")); reason != "" {
+		t.Errorf("expected missing repo to be left to missingRepoReason, got %q", reason)
+	}
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+	locked := filepath.Join(dir, "locked")
+	if err := os.Mkdir(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0755)
+	if reason := unreachableRepoReason(locked); !strings.Contains(reason, "not readable") {
+		t.Errorf("expected unreadable repo to be reported, got %q", reason)
+	}
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// handlePreReview links the pre-review the post-commit hook ran inline
+// (POST) to the job queued for the full review, and returns it (GET)
+func (s *Server) handlePreReview(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		var jobID int64
+		if _, err := fmt.Sscanf(r.URL.Query().Get("job_id"), "%d", &jobID); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid job_id")
+			return
+		}
+		pre, err := s.db.GetPreReview(jobID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "no pre-review for job")
+			return
+		}
+		writeJSON(w, http.StatusOK, pre)
+
+	case http.MethodPost:
+		var pre storage.PreReview
+		if err := json.NewDecoder(r.Body).Decode(&pre); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if pre.JobID == 0 || pre.Agent == "" {
+			writeError(w, http.StatusBadRequest, "job_id and agent are required")
+			return
+		}
+		switch pre.Status {
+		case storage.PreReviewDone, storage.PreReviewTimeout, storage.PreReviewFailed:
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q", pre.Status))
+			return
+		}
+		if _, err := s.db.GetJobByID(r.Context(), pre.JobID); err != nil {
+			writeError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		pre.CreatedAt = time.Time{}
+		if err := s.db.SavePreReview(pre); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("save pre-review: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
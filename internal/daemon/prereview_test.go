@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandlePreReview(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/pre-review-repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "presha", "test", "Full review output")
+
+	post := func(body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		server.handlePreReview(w, testutil.MakeJSONRequest(t, http.MethodPost, "/api/job/pre-review", body))
+		return w
+	}
+
+	w := post(map[string]any{"job_id": job.ID, "agent": "openai", "status": "pending"})
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	w = post(map[string]any{"job_id": job.ID + 100, "agent": "openai", "status": storage.PreReviewDone})
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+
+	w = post(map[string]any{"job_id": job.ID, "agent": "openai", "model": "qwen2.5-coder:7b",
+		"status": storage.PreReviewDone, "output": "Quick look: fine", "elapsed_ms": 900})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+
+	// The pre-review is returned on its own and with the full review
+	w = httptest.NewRecorder()
+	server.handlePreReview(w, httptest.NewRequest(http.MethodGet, "/api/job/pre-review?job_id="+strconv.FormatInt(job.ID, 10), nil))
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var pre storage.PreReview
+	testutil.DecodeJSON(t, w, &pre)
+	if pre.Output != "Quick look: fine" || pre.ElapsedMS != 900 {
+		t.Errorf("unexpected pre-review %+v", pre)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleGetReview(w, httptest.NewRequest(http.MethodGet, "/api/review?job_id="+strconv.FormatInt(job.ID, 10), nil))
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var review storage.Review
+	testutil.DecodeJSON(t, w, &review)
+	if review.Output != "Full review output" || review.PreReview == nil || review.PreReview.Model != "qwen2.5-coder:7b" {
+		t.Errorf("expected the review to carry its pre-review, got %+v", review)
+	}
+}
@@ -0,0 +1,195 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/agent"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/jj"
+	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/vcs"
+)
+
+// PromptPreviewRequest asks for the prompt a review would be given
+type PromptPreviewRequest struct {
+	RepoPath    string `json:"repo_path"`
+	GitRef      string `json:"git_ref"`                // Commit, range or "dirty"
+	DiffContent string `json:"diff_content,omitempty"` // For dirty reviews
+	Agent       string `json:"agent,omitempty"`
+	ReviewType  string `json:"review_type,omitempty"`
+	Focus       bool   `json:"focus,omitempty"`
+}
+
+// PromptPreviewPart is the prompt of one part of a fanned-out review
+type PromptPreviewPart struct {
+	Paths  []string `json:"paths"`
+	Prompt string   `json:"prompt"`
+}
+
+// PromptPreview is returned by POST /api/prompt/preview: the prompt a
+// review would be given, built as a worker builds it
+type PromptPreview struct {
+	GitRef     string `json:"git_ref"`
+	Agent      string `json:"agent"`
+	ReviewType string `json:"review_type"`
+	Prompt     string `json:"prompt"`
+	// Parts are set instead of Prompt when the review would fan out into
+	// parts reviewed in parallel
+	Parts     []PromptPreviewPart `json:"parts,omitempty"`
+	Injection []string            `json:"injection,omitempty"` // Injection patterns the diff matches
+}
+
+// handlePromptPreview builds the prompt a review of a commit, range or
+// uncommitted changes would be given, without enqueueing anything, so
+// missing context can be debugged before spending tokens. Reviewer
+// rotation is not applied since picking a reviewer advances it.
+func (s *Server) handlePromptPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req PromptPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.RepoPath == "" || req.GitRef == "" {
+		writeError(w, http.StatusBadRequest, "repo_path and git_ref are required")
+		return
+	}
+	if config.IsDefaultReviewType(req.ReviewType) {
+		req.ReviewType = "default"
+	}
+	if req.ReviewType != "default" && req.ReviewType != "security" && req.ReviewType != "design" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid review_type %q (valid: default, security, design)", req.ReviewType))
+		return
+	}
+	isDirty := req.GitRef == "dirty"
+	if isDirty && req.DiffContent == "" {
+		writeError(w, http.StatusBadRequest, "diff_content is required for dirty reviews")
+		return
+	}
+
+	repoVCS := vcs.For(req.RepoPath)
+	gitCwd, err := repoVCS.RepoRoot(req.RepoPath)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a %s repository: %v", repoVCS.Name(), err))
+		return
+	}
+	repoRoot := gitCwd
+	if repoVCS.Name() == "git" {
+		if repoRoot, err = git.GetMainRepoRoot(req.RepoPath); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("not a git repository: %v", err))
+			return
+		}
+	}
+
+	// Resolve the ref to SHAs as an enqueue would
+	gitRef := req.GitRef
+	if !isDirty {
+		if gitRef, err = jj.ResolveRef(gitCwd, gitRef); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid commit: %v", err))
+			return
+		}
+		if strings.Contains(gitRef, "..") {
+			startSHA, endSHA, err := resolveRange(repoVCS, gitCwd, gitRef)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			gitRef = startSHA + ".." + endSHA
+		} else if gitRef, err = repoVCS.ResolveRef(gitCwd, gitRef); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid commit: %v", err))
+			return
+		}
+	}
+
+	cfg := s.configWatcher.Config()
+	enq := EnqueueRequest{Agent: req.Agent, ReviewType: req.ReviewType, Focus: req.Focus}
+	if enq.Focus {
+		focus := config.ResolveFocus(repoRoot, cfg)
+		if strings.TrimSpace(enq.Agent) == "" {
+			enq.Agent = focus.Agent
+		}
+		enq.Reasoning = "thorough"
+	}
+	if !isDirty {
+		applyRoutes(s.matchRoutes(repoRoot, gitCwd, gitRef), &enq)
+	}
+	reasoning, err := config.ResolveReviewReasoning(enq.Reasoning, repoRoot)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	workflow := "review"
+	if !config.IsDefaultReviewType(enq.ReviewType) {
+		workflow = enq.ReviewType
+	}
+	agentName := config.ResolveAgentForWorkflow(enq.Agent, repoRoot, cfg, workflow, reasoning)
+	if resolved, err := agent.GetAvailable(agentName); err == nil {
+		agentName = resolved.Name()
+	}
+
+	// The job a worker would be given
+	job := &storage.ReviewJob{
+		RepoPath:   repoRoot,
+		GitRef:     gitRef,
+		Agent:      agentName,
+		ReviewType: enq.ReviewType,
+		Focus:      enq.Focus,
+	}
+	if repo, err := s.db.GetRepoByPath(r.Context(), repoRoot); err == nil {
+		job.RepoID = repo.ID
+	}
+	if isDirty {
+		job.DiffContent = &req.DiffContent
+	} else if !strings.Contains(gitRef, "..") {
+		var commitID int64 // Single commits are recorded when enqueued
+		job.CommitID = &commitID
+	}
+
+	preview := PromptPreview{GitRef: gitRef, Agent: agentName, ReviewType: enq.ReviewType}
+	builder := reviewPromptBuilder(s.workerPool.promptBuilder, job, cfg)
+
+	// Large commits and ranges fan out into parts, as the worker plans them.
+	// Thorough reviews see the whole change in one prompt.
+	var groups [][]string
+	if !job.Focus && !isDirty {
+		groups = planFanOut(job, cfg)
+	}
+	if groups != nil {
+		for _, paths := range groups {
+			p, err := builder.BuildPart(repoRoot, gitRef, paths, cfg.ReviewContextCount, agentName, job.ReviewType)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("build prompt: %v", err))
+				return
+			}
+			preview.Parts = append(preview.Parts, PromptPreviewPart{Paths: paths, Prompt: p})
+			for _, name := range prompt.ScanUntrusted(p) {
+				if !slices.Contains(preview.Injection, name) {
+					preview.Injection = append(preview.Injection, name)
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, preview)
+		return
+	}
+
+	reviewPrompt, _, err := buildReviewPrompt(builder, job, cfg)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("build prompt: %v", err))
+		return
+	}
+	if checklist := config.ResolveChecklist(repoRoot, cfg); len(checklist) > 0 {
+		reviewPrompt += prompt.ChecklistInstructions(checklist)
+	}
+	preview.Prompt = reviewPrompt
+	preview.Injection = prompt.ScanUntrusted(reviewPrompt)
+	writeJSON(w, http.StatusOK, preview)
+}
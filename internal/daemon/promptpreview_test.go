@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandlePromptPreview(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte(`checklist = ["Tests added?"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sha := testutil.GetHeadSHA(t, repoDir)
+
+	preview := func(body map[string]any) (*httptest.ResponseRecorder, PromptPreview) {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/prompt/preview", body)
+		w := httptest.NewRecorder()
+		server.handlePromptPreview(w, req)
+		var resp PromptPreview
+		if w.Code == http.StatusOK {
+			testutil.DecodeJSON(t, w, &resp)
+		}
+		return w, resp
+	}
+
+	w, resp := preview(map[string]any{"repo_path": repoDir, "git_ref": "HEAD", "agent": "test", "review_type": "security"})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	if resp.GitRef != sha || resp.Agent != "test" || resp.ReviewType != "security" {
+		t.Errorf("unexpected preview %+v", resp)
+	}
+	if !strings.Contains(resp.Prompt, "security code reviewer") || !strings.Contains(resp.Prompt, sha[:7]) {
+		t.Errorf("prompt should be the security review of the commit:\n%s", resp.Prompt)
+	}
+	if !strings.Contains(resp.Prompt, "Tests added?") {
+		t.Error("prompt should include the repo's checklist")
+	}
+
+	// Nothing is enqueued or recorded
+	if _, err := db.GetRepoByPath(t.Context(), repoDir); err == nil {
+		t.Error("previewing should not register the repo")
+	}
+
+	w, _ = preview(map[string]any{"repo_path": repoDir, "git_ref": "no-such-ref"})
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	w, _ = preview(map[string]any{"repo_path": repoDir, "git_ref": "dirty"})
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+const (
+	// queueSampleInterval is how often the queue is sampled
+	queueSampleInterval = time.Minute
+	// queueSampleRetention is how long queue samples are kept
+	queueSampleRetention = 90 * 24 * time.Hour
+)
+
+// queueSampler records queue depth, throughput and latency every minute so
+// worker counts can be sized from history ('roborev stats --queue')
+type queueSampler struct {
+	db      *storage.DB
+	workers func() int
+
+	mu      sync.Mutex
+	started bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func newQueueSampler(db *storage.DB, workers func() int) *queueSampler {
+	return &queueSampler{
+		db:      db,
+		workers: workers,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+}
+
+// Start samples the queue every queueSampleInterval
+func (q *queueSampler) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+
+	go func() {
+		defer close(q.doneCh)
+		ticker := time.NewTicker(queueSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-q.stopCh:
+				return
+			case now := <-ticker.C:
+				q.sample(now)
+			}
+		}
+	}()
+}
+
+// Stop ends sampling. Safe to call more than once or without Start.
+func (q *queueSampler) Stop() {
+	q.mu.Lock()
+	started := q.started
+	select {
+	case <-q.stopCh:
+	default:
+		close(q.stopCh)
+	}
+	q.mu.Unlock()
+	if started {
+		<-q.doneCh
+	}
+}
+
+// sample records one queue sample and drops samples past the retention
+func (q *queueSampler) sample(now time.Time) {
+	if _, err := q.db.RecordQueueSample(now, queueSampleInterval, q.workers()); err != nil {
+		log.Printf("Queue metrics: %v", err)
+		return
+	}
+	if _, err := q.db.PruneQueueSamples(now.Add(-queueSampleRetention)); err != nil {
+		log.Printf("Queue metrics: prune: %v", err)
+	}
+}
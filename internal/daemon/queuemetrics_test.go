@@ -0,0 +1,31 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestQueueSamplerSample(t *testing.T) {
+	db, _ := testutil.OpenTestDBWithDir(t)
+	q := newQueueSampler(db, func() int { return 3 })
+
+	now := time.Now()
+	q.sample(now.Add(-queueSampleRetention - time.Hour))
+	q.sample(now)
+
+	samples, err := db.ListQueueSamples(time.Time{})
+	if err != nil {
+		t.Fatalf("ListQueueSamples: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected samples past the retention to be pruned, got %d", len(samples))
+	}
+	if samples[0].Workers != 3 {
+		t.Errorf("expected pool size 3 to be recorded, got %d", samples[0].Workers)
+	}
+
+	// Stop without Start must not block
+	q.Stop()
+}
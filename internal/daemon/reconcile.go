@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// ReconcileResponse is returned by GET /api/reconcile
+type ReconcileResponse struct {
+	Verdicts       []storage.AgentVerdict  `json:"verdicts"`
+	Reconciliation *storage.Reconciliation `json:"reconciliation,omitempty"`
+}
+
+// ReconcileRequest records the consolidated verdict for a git ref
+type ReconcileRequest struct {
+	RepoPath        string `json:"repo_path"`
+	GitRef          string `json:"git_ref"`
+	Verdict         string `json:"verdict"` // P or F
+	Method          string `json:"method"`  // manual or tiebreaker
+	TiebreakerAgent string `json:"tiebreaker_agent,omitempty"`
+	TiebreakerJobID int64  `json:"tiebreaker_job_id,omitempty"`
+	Note            string `json:"note,omitempty"`
+}
+
+// handleReconcile lists each agent's latest verdict for a git ref along
+// with the reconciliation recorded for it, if any
+func (s *Server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("repo") == "" || q.Get("git_ref") == "" {
+		writeError(w, http.StatusBadRequest, "repo and git_ref are required")
+		return
+	}
+	repo, err := s.db.FindRepo(r.Context(), q.Get("repo"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+
+	verdicts, err := s.db.GetAgentVerdicts(repo.ID, q.Get("git_ref"))
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get verdicts: %v", err))
+		return
+	}
+	rec, err := s.db.GetReconciliation(repo.ID, q.Get("git_ref"))
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get reconciliation: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, ReconcileResponse{Verdicts: verdicts, Reconciliation: rec})
+}
+
+func (s *Server) handleReconcileDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req ReconcileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.RepoPath == "" || req.GitRef == "" {
+		writeError(w, http.StatusBadRequest, "repo_path and git_ref are required")
+		return
+	}
+	repo, err := s.db.FindRepo(r.Context(), req.RepoPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+
+	err = s.db.SaveReconciliation(storage.Reconciliation{
+		RepoID:          repo.ID,
+		GitRef:          req.GitRef,
+		Verdict:         req.Verdict,
+		Method:          req.Method,
+		TiebreakerAgent: req.TiebreakerAgent,
+		TiebreakerJobID: req.TiebreakerJobID,
+		Note:            req.Note,
+	})
+	switch {
+	case errors.Is(err, storage.ErrInvalidReconciliation):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		s.writeInternalError(w, fmt.Sprintf("save reconciliation: %v", err))
+		return
+	}
+
+	rec, err := s.db.GetReconciliation(repo.ID, req.GitRef)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get reconciliation: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleReconcile(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "reconcilerepo")
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", "- High: race in cache")
+	testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "claude-code", "No issues found.")
+
+	get := func() ReconcileResponse {
+		t.Helper()
+		q := url.Values{"repo": {repoDir}, "git_ref": {"abc123"}}
+		req := httptest.NewRequest(http.MethodGet, "/api/reconcile?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+		server.handleReconcile(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var resp ReconcileResponse
+		testutil.DecodeJSON(t, w, &resp)
+		return resp
+	}
+
+	resp := get()
+	if len(resp.Verdicts) != 2 || resp.Verdicts[0].Verdict != "F" || resp.Verdicts[1].Verdict != "P" {
+		t.Fatalf("expected conflicting verdicts, got %+v", resp.Verdicts)
+	}
+	if resp.Reconciliation != nil {
+		t.Errorf("expected no reconciliation yet, got %+v", resp.Reconciliation)
+	}
+
+	decide := func(body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/reconcile/decide", body)
+		w := httptest.NewRecorder()
+		server.handleReconcileDecision(w, req)
+		return w
+	}
+
+	w := decide(map[string]any{"repo_path": repoDir, "git_ref": "abc123", "verdict": "F", "method": "manual", "note": "race is real"})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var rec storage.Reconciliation
+	testutil.DecodeJSON(t, w, &rec)
+	if rec.Verdict != "F" || rec.Note != "race is real" {
+		t.Errorf("unexpected reconciliation %+v", rec)
+	}
+	if resp := get(); resp.Reconciliation == nil || resp.Reconciliation.Verdict != "F" {
+		t.Errorf("expected recorded reconciliation, got %+v", resp.Reconciliation)
+	}
+
+	w = decide(map[string]any{"repo_path": repoDir, "git_ref": "abc123", "verdict": "maybe", "method": "manual"})
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	w = decide(map[string]any{"repo_path": "/nonexistent", "git_ref": "abc123", "verdict": "P", "method": "manual"})
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/reconcile?repo="+repoDir, nil)
+	w = httptest.NewRecorder()
+	server.handleReconcile(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+}
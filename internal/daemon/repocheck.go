@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// repoCheckInterval is how often queued jobs' repos are checked for a
+// missing path, blocked jobs are checked for a fixed cause, and repos are
+// checked for rewritten commits
+const repoCheckInterval = time.Minute
+
+// missingRepoReason returns why jobs of the repo at path cannot run when the
+// path no longer exists, or "" if it does (or cannot be checked)
+func missingRepoReason(path string) string {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("repository path %s no longer exists", path)
+	}
+	return ""
+}
+
+// repoWatcher periodically blocks the queued jobs of repos whose path was
+// deleted, requeues blocked jobs once their cause is fixed, and moves the
+// reviews of rebased commits onto their rewrites
+func (wp *WorkerPool) repoWatcher() {
+	defer wp.wg.Done()
+	wp.checkRepoPaths()
+	wp.detectRewrites(time.Now())
+	ticker := time.NewTicker(repoCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wp.stopCh:
+			return
+		case <-ticker.C:
+			wp.checkRepoPaths()
+			wp.detectRewrites(time.Now())
+		}
+	}
+}
+
+// checkRepoPaths blocks the queued jobs of repos whose path is missing, and
+// requeues blocked jobs that now pass their preflight checks
+func (wp *WorkerPool) checkRepoPaths() {
+	repos, err := wp.db.ListReposWithQueuedJobs()
+	if err != nil {
+		log.Printf("Repo check: error listing repos with queued jobs: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		if reason := missingRepoReason(repo.RootPath); reason != "" {
+			wp.blockRepo(repo.ID, repo.Name, reason)
+		}
+	}
+
+	ids, err := wp.db.ListBlockedJobIDs()
+	if err != nil {
+		log.Printf("Repo check: error listing blocked jobs: %v", err)
+		return
+	}
+	cfg := wp.cfgGetter.Config()
+	for _, id := range ids {
+		job, err := wp.db.GetJobByID(wp.stopCtx, id)
+		if err != nil {
+			log.Printf("Repo check: error loading blocked job %d: %v", id, err)
+			continue
+		}
+		if reason, _ := wp.preflight(job, cfg); reason != "" {
+			continue
+		}
+		if _, err := wp.db.UnblockJob(id); err != nil {
+			log.Printf("Repo check: error unblocking job %d: %v", id, err)
+			continue
+		}
+		log.Printf("Repo check: requeued job %d of %s, which was blocked: %s", id, job.RepoName, job.Error)
+	}
+}
+
+// blockRepo marks a repo's queued jobs as blocked with the given reason
+func (wp *WorkerPool) blockRepo(repoID int64, repoName, reason string) {
+	n, err := wp.db.BlockRepoJobs(repoID, reason)
+	if err != nil {
+		log.Printf("Repo check: error blocking jobs of %s: %v", repoName, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Repo check: blocked %d queued job(s) of %s: %s", n, repoName, reason)
+		if wp.errorLog != nil {
+			wp.errorLog.LogError("worker", fmt.Sprintf("%s: %s", repoName, reason), 0)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestBlockMissingRepoJobs(t *testing.T) {
+	tc := newWorkerTestContext(t, 1)
+	repoDir := filepath.Join(tc.TmpDir, "gone")
+	if err := os.Mkdir(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tc.DB.RelocateRepo(tc.Repo.ID, repoDir); err != nil {
+		t.Fatalf("RelocateRepo: %v", err)
+	}
+	claimed := tc.createAndClaimJob(t, "sha1", "worker-0")
+	queued := tc.createJob(t, "sha2")
+	if err := os.Remove(repoDir); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := tc.DB.GetJobByID(t.Context(), claimed.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if !tc.Pool.blockUnrunnableJob("worker-0", job, tc.Pool.cfgGetter.Config()) {
+		t.Fatal("expected job of deleted repo to be blocked")
+	}
+	for _, id := range []int64{claimed.ID, queued.ID} {
+		job, err := tc.DB.GetJobByID(t.Context(), id)
+		if err != nil {
+			t.Fatalf("GetJobByID: %v", err)
+		}
+		if job.Status != storage.JobStatusBlocked || !strings.Contains(job.Error, "no longer exists") {
+			t.Errorf("job %d: expected blocked with reason, got %s %q", id, job.Status, job.Error)
+		}
+	}
+
+	broken, err := tc.DB.ListBrokenRepos()
+	if err != nil {
+		t.Fatalf("ListBrokenRepos: %v", err)
+	}
+	if len(broken) != 1 || broken[0].RootPath != repoDir || broken[0].BlockedJobs != 2 {
+		t.Errorf("unexpected broken repos %+v", broken)
+	}
+
+	// Jobs are requeued once the repo is back
+	if err := os.Mkdir(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tc.Pool.checkRepoPaths()
+	for _, id := range []int64{claimed.ID, queued.ID} {
+		if job, _ := tc.DB.GetJobByID(t.Context(), id); job.Status != storage.JobStatusQueued || job.Error != "" {
+			t.Errorf("job %d: expected requeued, got %s %q", id, job.Status, job.Error)
+		}
+	}
+
+	// The watcher blocks queued jobs without waiting for a worker to claim them
+	if err := os.Remove(repoDir); err != nil {
+		t.Fatal(err)
+	}
+	tc.Pool.checkRepoPaths()
+	if job, _ := tc.DB.GetJobByID(t.Context(), queued.ID); job.Status != storage.JobStatusBlocked {
+		t.Errorf("expected watcher to block queued job, got %s", job.Status)
+	}
+}
+
+func TestBlockUnrunnableJobKeepsExistingRepos(t *testing.T) {
+	tc := newWorkerTestContext(t, 1)
+	job := tc.createAndClaimJob(t, "sha1", "worker-0")
+	job.RepoPath = tc.TmpDir
+	if tc.Pool.blockUnrunnableJob("worker-0", job, tc.Pool.cfgGetter.Config()) {
+		t.Error("expected job of existing repo not to be blocked")
+	}
+}
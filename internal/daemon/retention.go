@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// pruneInterval is how often the retention policy is applied
+const pruneInterval = time.Hour
+
+// pruner deletes finished jobs past the [retention] policy. Settings are
+// read on every run, so setting a policy takes effect without a restart.
+type pruner struct {
+	db        *storage.DB
+	cfgGetter ConfigGetter
+
+	mu         sync.Mutex
+	started    bool
+	stopCtx    context.Context
+	stopCancel context.CancelFunc
+	doneCh     chan struct{}
+}
+
+func newPruner(db *storage.DB, cfgGetter ConfigGetter) *pruner {
+	stopCtx, stopCancel := context.WithCancel(context.Background())
+	return &pruner{
+		db:         db,
+		cfgGetter:  cfgGetter,
+		stopCtx:    stopCtx,
+		stopCancel: stopCancel,
+		doneCh:     make(chan struct{}),
+	}
+}
+
+// Start applies the policy immediately and then every pruneInterval
+func (p *pruner) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+
+	go func() {
+		defer close(p.doneCh)
+		p.prune(time.Now())
+
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stopCtx.Done():
+				return
+			case now := <-ticker.C:
+				p.prune(now)
+			}
+		}
+	}()
+}
+
+// Stop ends the schedule, waiting for a prune in progress. Safe to call
+// more than once or without Start.
+func (p *pruner) Stop() {
+	p.mu.Lock()
+	started := p.started
+	p.stopCancel()
+	p.mu.Unlock()
+	if started {
+		<-p.doneCh
+	}
+}
+
+// prune applies the retention policy, if one is set. Returns the number of
+// jobs deleted.
+func (p *pruner) prune(now time.Time) int64 {
+	cfg := p.cfgGetter.Config().Retention
+	if !cfg.Enabled() {
+		return 0
+	}
+	opts := storage.PruneOptions{KeepPerRepo: cfg.KeepPerRepo}
+	if maxAge := cfg.MaxAge(); maxAge > 0 {
+		opts.Before = now.Add(-maxAge)
+	}
+	result, err := p.db.Prune(p.stopCtx, opts)
+	if err != nil {
+		log.Printf("Retention: prune: %v", err)
+		return 0
+	}
+	if result.Jobs > 0 {
+		log.Printf("Retention: pruned %d job(s) (%d rows)", result.Jobs, result.Rows)
+	}
+	return result.Jobs
+}
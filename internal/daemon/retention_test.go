@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestPrunerPrune(t *testing.T) {
+	db, tmpDir := testutil.OpenTestDBWithDir(t)
+	repo, err := db.GetOrCreateRepo(t.Context(), tmpDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	old := testutil.CreateCompletedReview(t, db, repo.ID, "old123", "codex", "No issues found.")
+	testutil.CreateCompletedReview(t, db, repo.ID, "new456", "codex", "No issues found.")
+	at := time.Now().Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`UPDATE review_jobs SET finished_at = ? WHERE id = ?`, at, old.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	p := newPruner(db, NewStaticConfig(cfg))
+	if n := p.prune(time.Now()); n != 0 {
+		t.Fatalf("expected nothing pruned without a policy, got %d", n)
+	}
+
+	cfg.Retention.MaxAgeDays = 30
+	if n := p.prune(time.Now()); n != 1 {
+		t.Fatalf("expected the old job pruned, got %d", n)
+	}
+	if _, err := db.GetJobByID(t.Context(), old.ID); err == nil {
+		t.Error("old job still exists")
+	}
+	if n := p.prune(time.Now()); n != 0 {
+		t.Errorf("expected nothing left to prune, got %d", n)
+	}
+}
+
+func TestPrunerStopWithoutStart(t *testing.T) {
+	db, _ := testutil.OpenTestDBWithDir(t)
+	p := newPruner(db, NewStaticConfig(config.DefaultConfig()))
+	p.Stop()
+	p.Stop()
+}
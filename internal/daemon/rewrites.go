@@ -0,0 +1,205 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// rewriteScanLimit caps how many commits new to a repo's HEAD are checked
+// for being rewrites of reviewed commits each time HEAD moves
+const rewriteScanLimit = 100
+
+// pendingRewriteTTL is how long a rewrite whose original is still on a
+// branch is rechecked before it is dropped. The original of a rebased
+// branch that was pushed stays on its remote-tracking branch until the
+// rewrite is force-pushed; the original of a cherry-pick stays for good.
+const pendingRewriteTTL = 24 * time.Hour
+
+// rewriteWatch is what detectRewrites has seen. It is only used from the
+// repo watcher goroutine.
+type rewriteWatch struct {
+	heads   map[int64]string          // Last seen HEAD of each repo
+	pending map[string]pendingRewrite // Keyed by the rewritten commit's SHA
+}
+
+// pendingRewrite is a commit with the same patch as a reviewed commit that
+// was still on a branch when it was found
+type pendingRewrite struct {
+	repo   storage.Repo
+	oldSHA string
+	found  time.Time
+}
+
+// RemapRequest moves the review work of a commit replaced by history
+// rewriting onto its rewritten equivalent
+type RemapRequest struct {
+	RepoPath string `json:"repo_path"`
+	OldSHA   string `json:"old_sha"`
+	NewSHA   string `json:"new_sha"`
+}
+
+// remapCommit records the rewritten commit newSHA and moves the review work
+// of oldSHA onto it
+func remapCommit(ctx context.Context, db *storage.DB, repo *storage.Repo, oldSHA, newSHA string) (*storage.RemapResult, error) {
+	info, err := git.GetCommitInfo(repo.RootPath, newSHA)
+	if err != nil {
+		return nil, fmt.Errorf("get commit %s: %w", newSHA, err)
+	}
+	commit, err := db.GetOrCreateCommit(ctx, repo.ID, info.SHA, info.Author, info.Subject, info.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("record commit %s: %w", newSHA, err)
+	}
+	result, err := db.RemapCommit(repo.ID, oldSHA, commit.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Later rewrites of the commit are detected by its patch-id too
+	if patchID, err := git.PatchID(repo.RootPath, info.SHA); err == nil && patchID != "" {
+		if err := db.SetCommitPatchID(commit.ID, patchID); err != nil {
+			log.Printf("Error recording patch-id of %s: %v", info.SHA, err)
+		}
+	}
+	return result, nil
+}
+
+// handleRemap moves the review work of a commit onto its rewrite, for
+// rewrites the repo watcher cannot detect (such as ones whose patch changed)
+func (s *Server) handleRemap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req RemapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.RepoPath == "" || req.OldSHA == "" || req.NewSHA == "" {
+		writeError(w, http.StatusBadRequest, "repo_path, old_sha and new_sha are required")
+		return
+	}
+	repo, err := s.db.FindRepo(r.Context(), req.RepoPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repo not found")
+		return
+	}
+
+	// The original may have been garbage collected, in which case it must
+	// be given in full
+	oldSHA := req.OldSHA
+	if resolved, err := git.ResolveSHA(repo.RootPath, oldSHA); err == nil {
+		oldSHA = resolved
+	}
+	newSHA, err := git.ResolveSHA(repo.RootPath, req.NewSHA)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("cannot resolve %s: %v", req.NewSHA, err))
+		return
+	}
+	if oldSHA == newSHA {
+		writeError(w, http.StatusBadRequest, "the old and new commits are the same")
+		return
+	}
+	if _, err := s.db.GetCommitByRepoAndSHA(r.Context(), repo.ID, oldSHA); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no reviews of commit %s", req.OldSHA))
+		return
+	}
+
+	result, err := remapCommit(r.Context(), s.db, repo, oldSHA, newSHA)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("remap: %v", err))
+		return
+	}
+	log.Printf("Remapped %d job(s) of %s in %s onto %s", result.Jobs, shortRef(oldSHA), repo.Name, shortRef(newSHA))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// detectRewrites moves the review work of commits that a rebase or amend
+// replaced onto their rewrites. Each time a repo's HEAD moves, the commits
+// new to it are matched by patch-id against reviewed commits that are no
+// longer on any branch.
+func (wp *WorkerPool) detectRewrites(now time.Time) {
+	repos, err := wp.db.ListRepos(wp.stopCtx)
+	if err != nil {
+		log.Printf("Rewrite check: error listing repos: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		head, err := git.ResolveSHA(repo.RootPath, "HEAD")
+		if err != nil {
+			continue // Missing, not a git repo, or no commits yet
+		}
+		last, seen := wp.rewrites.heads[repo.ID]
+		wp.rewrites.heads[repo.ID] = head
+		if !seen || last == head {
+			continue
+		}
+		commits, err := git.GetRecentCommits(repo.RootPath, last+"..HEAD", rewriteScanLimit)
+		if err != nil {
+			continue // The last HEAD may have been garbage collected
+		}
+		for _, sha := range commits {
+			wp.checkRewrite(repo, sha, now)
+		}
+	}
+
+	for newSHA, p := range wp.rewrites.pending {
+		if now.Sub(p.found) > pendingRewriteTTL {
+			delete(wp.rewrites.pending, newSHA)
+			continue
+		}
+		wp.checkRewriteOf(p.repo, p.oldSHA, newSHA, p.found)
+	}
+}
+
+// checkRewrite remaps the reviewed commit that sha has the same patch as
+// onto it, if sha is a rewrite of it
+func (wp *WorkerPool) checkRewrite(repo storage.Repo, sha string, now time.Time) {
+	patchID, err := git.PatchID(repo.RootPath, sha)
+	if err != nil || patchID == "" {
+		return
+	}
+	olds, err := wp.db.FindPatchCommits(repo.ID, patchID, sha)
+	if err != nil {
+		log.Printf("Rewrite check: error finding commits with the patch of %s: %v", shortRef(sha), err)
+		return
+	}
+	if len(olds) == 0 {
+		return
+	}
+	wp.checkRewriteOf(repo, olds[0], sha, now)
+}
+
+// checkRewriteOf remaps oldSHA onto newSHA once oldSHA is no longer on any
+// branch, keeping the pair pending until then
+func (wp *WorkerPool) checkRewriteOf(repo storage.Repo, oldSHA, newSHA string, found time.Time) {
+	onRef, err := git.IsOnAnyRef(repo.RootPath, oldSHA)
+	if err != nil {
+		return // The original was garbage collected
+	}
+	if onRef {
+		wp.rewrites.pending[newSHA] = pendingRewrite{repo: repo, oldSHA: oldSHA, found: found}
+		return
+	}
+	delete(wp.rewrites.pending, newSHA)
+
+	// A rewrite that was itself abandoned, say by resetting the branch,
+	// keeps nothing
+	if onRef, err := git.IsOnAnyRef(repo.RootPath, newSHA); err != nil || !onRef {
+		return
+	}
+	result, err := remapCommit(wp.stopCtx, wp.db, &repo, oldSHA, newSHA)
+	if err != nil {
+		log.Printf("Rewrite check: error remapping %s onto %s: %v", shortRef(oldSHA), shortRef(newSHA), err)
+		return
+	}
+	log.Printf("Rewrite check: remapped %d job(s) of %s in %s onto its rewrite %s",
+		result.Jobs, shortRef(oldSHA), repo.Name, shortRef(newSHA))
+}
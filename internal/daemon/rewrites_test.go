@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+// reviewedCommit commits a change to file in dir and records a completed
+// review of it along with its patch-id, as an enqueue would
+func reviewedCommit(t *testing.T, db *storage.DB, repo *storage.Repo, file, content string) (string, *storage.ReviewJob) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo.RootPath, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitIn(t, repo.RootPath, "add", file)
+	runGitIn(t, repo.RootPath, "commit", "-m", "change "+file)
+	sha := testutil.GetHeadSHA(t, repo.RootPath)
+	job := testutil.CreateCompletedReview(t, db, repo.ID, sha, "test", "Found a bug")
+	patchID, err := git.PatchID(repo.RootPath, sha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetCommitPatchID(*job.CommitID, patchID); err != nil {
+		t.Fatal(err)
+	}
+	return sha, job
+}
+
+func runGitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func TestDetectRewrites(t *testing.T) {
+	db, tmpDir := testutil.OpenTestDBWithDir(t)
+	repoDir := filepath.Join(tmpDir, "repo")
+	testutil.InitTestGitRepo(t, repoDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSHA, job := reviewedCommit(t, db, repo, "a.txt", "a")
+
+	wp := NewWorkerPool(db, NewStaticConfig(config.DefaultConfig()), 1, NewBroadcaster(), nil)
+	now := time.Now()
+	wp.detectRewrites(now) // Records HEAD
+
+	wantRemapped := func(sha string) {
+		t.Helper()
+		got, err := db.GetJobByID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GitRef != sha {
+			t.Errorf("job is on %s, want %s", got.GitRef, sha)
+		}
+	}
+
+	// A rewrite whose original is still on a branch waits for it to go
+	runGitIn(t, repoDir, "branch", "backup")
+	runGitIn(t, repoDir, "commit", "--amend", "-m", "reworded")
+	newSHA := testutil.GetHeadSHA(t, repoDir)
+	wp.detectRewrites(now)
+	wantRemapped(oldSHA)
+	if _, ok := wp.rewrites.pending[newSHA]; !ok {
+		t.Fatal("expected the rewrite to be pending")
+	}
+
+	runGitIn(t, repoDir, "branch", "-D", "backup")
+	wp.detectRewrites(now)
+	wantRemapped(newSHA)
+	if len(wp.rewrites.pending) != 0 {
+		t.Errorf("expected no pending rewrites, got %v", wp.rewrites.pending)
+	}
+
+	// Rewrites are chained through the remapped commit's patch-id
+	runGitIn(t, repoDir, "commit", "--amend", "-m", "reworded again")
+	wp.detectRewrites(now)
+	wantRemapped(testutil.GetHeadSHA(t, repoDir))
+
+	// A cherry-pick whose original stays on its branch is dropped in time
+	cherrySHA, cherryJob := reviewedCommit(t, db, repo, "b.txt", "b")
+	runGitIn(t, repoDir, "checkout", "-q", "-b", "other", "HEAD~1")
+	runGitIn(t, repoDir, "cherry-pick", cherrySHA)
+	wp.detectRewrites(now)
+	wp.detectRewrites(now.Add(pendingRewriteTTL + time.Minute))
+	if len(wp.rewrites.pending) != 0 {
+		t.Errorf("expected the cherry-pick to be dropped, got %v", wp.rewrites.pending)
+	}
+	if got, err := db.GetJobByID(t.Context(), cherryJob.ID); err != nil || got.GitRef != cherrySHA {
+		t.Errorf("expected the cherry-picked original to keep its review, got %+v, %v", got, err)
+	}
+}
+
+func TestHandleRemap(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+	repoDir := filepath.Join(tmpDir, "repo")
+	testutil.InitTestGitRepo(t, repoDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSHA, job := reviewedCommit(t, db, repo, "a.txt", "a")
+
+	// A rewrite that changed the patch is only remapped by hand
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("a, fixed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitIn(t, repoDir, "commit", "-a", "--amend", "-m", "change a.txt, fixed")
+	newSHA := testutil.GetHeadSHA(t, repoDir)
+
+	remap := func(oldRef, newRef string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/remap", RemapRequest{RepoPath: repoDir, OldSHA: oldRef, NewSHA: newRef})
+		w := httptest.NewRecorder()
+		server.handleRemap(w, req)
+		return w
+	}
+
+	w := remap(oldSHA[:8], "HEAD")
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var result storage.RemapResult
+	testutil.DecodeJSON(t, w, &result)
+	if result.Jobs != 1 || result.OldSHA != oldSHA || result.NewSHA != newSHA {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if got, err := db.GetJobByID(t.Context(), job.ID); err != nil || got.GitRef != newSHA {
+		t.Errorf("expected job on %s, got %+v, %v", newSHA, got, err)
+	}
+
+	testutil.AssertStatusCode(t, remap("0000000000000000000000000000000000000000", "HEAD"), http.StatusNotFound)
+	testutil.AssertStatusCode(t, remap("HEAD", "HEAD"), http.StatusBadRequest)
+}
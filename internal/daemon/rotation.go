@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/roborev-dev/roborev/internal/config"
+)
+
+// agentRotator picks review agents according to a RotationConfig.
+// Round-robin position is tracked per repo in memory, so alternation
+// restarts from the first agent when the daemon restarts.
+type agentRotator struct {
+	mu       sync.Mutex
+	counters map[string]int
+	intn     func(n int) int // random source for weighted mode (overridable in tests)
+}
+
+func newAgentRotator() *agentRotator {
+	return &agentRotator{
+		counters: make(map[string]int),
+		intn:     rand.IntN,
+	}
+}
+
+// Pick selects an agent for the given repo. It returns the agent name and a
+// short description of the decision for recording on the job, e.g.
+// "rotation:weighted codex (80/100)" or "rotation:alternate claude-code (2/2)".
+func (r *agentRotator) Pick(repoKey string, cfg config.RotationConfig) (string, string, error) {
+	entries, err := cfg.Entries()
+	if err != nil {
+		return "", "", err
+	}
+	if len(entries) == 0 {
+		return "", "", fmt.Errorf("review_rotation: no agents configured")
+	}
+	mode, err := cfg.NormalizedMode()
+	if err != nil {
+		return "", "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch mode {
+	case "alternate":
+		i := r.counters[repoKey] % len(entries)
+		r.counters[repoKey] = i + 1
+		return entries[i].Agent, fmt.Sprintf("rotation:alternate %s (%d/%d)", entries[i].Agent, i+1, len(entries)), nil
+	default:
+		total := 0
+		for _, e := range entries {
+			total += e.Weight
+		}
+		n := r.intn(total)
+		for _, e := range entries {
+			if n < e.Weight {
+				return e.Agent, fmt.Sprintf("rotation:weighted %s (%d/%d)", e.Agent, e.Weight, total), nil
+			}
+			n -= e.Weight
+		}
+		// Unreachable: n < total by construction
+		last := entries[len(entries)-1]
+		return last.Agent, fmt.Sprintf("rotation:weighted %s (%d/%d)", last.Agent, last.Weight, total), nil
+	}
+}
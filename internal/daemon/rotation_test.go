@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestAgentRotatorAlternate(t *testing.T) {
+	r := newAgentRotator()
+	cfg := config.RotationConfig{Mode: "alternate", Agents: []string{"codex", "claude-code"}}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		agent, _, err := r.Pick("/repo/a", cfg)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		got = append(got, agent)
+	}
+	want := []string{"codex", "claude-code", "codex", "claude-code"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("alternate picks = %v, want %v", got, want)
+	}
+
+	// Other repos rotate independently
+	if agent, _, _ := r.Pick("/repo/b", cfg); agent != "codex" {
+		t.Errorf("expected first pick for new repo to be codex, got %q", agent)
+	}
+}
+
+func TestAgentRotatorWeighted(t *testing.T) {
+	r := newAgentRotator()
+	cfg := config.RotationConfig{Agents: []string{"codex:80", "claude-code:20"}}
+
+	tests := []struct {
+		roll      int
+		wantAgent string
+	}{
+		{0, "codex"},
+		{79, "codex"},
+		{80, "claude-code"},
+		{99, "claude-code"},
+	}
+	for _, tt := range tests {
+		roll := tt.roll
+		r.intn = func(n int) int {
+			if n != 100 {
+				t.Fatalf("expected total weight 100, got %d", n)
+			}
+			return roll
+		}
+		agent, decision, err := r.Pick("/repo", cfg)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		if agent != tt.wantAgent {
+			t.Errorf("roll %d: got %q, want %q", tt.roll, agent, tt.wantAgent)
+		}
+		if !strings.HasPrefix(decision, "rotation:weighted "+tt.wantAgent) {
+			t.Errorf("roll %d: unexpected decision %q", tt.roll, decision)
+		}
+	}
+}
+
+func TestAgentRotatorInvalidConfig(t *testing.T) {
+	r := newAgentRotator()
+	for _, cfg := range []config.RotationConfig{
+		{Agents: []string{"codex:0"}},
+		{Agents: []string{"codex:abc"}},
+		{Agents: []string{":5"}},
+		{Mode: "bogus", Agents: []string{"codex"}},
+	} {
+		if _, _, err := r.Pick("/repo", cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestHandleEnqueueRecordsRotationPolicy(t *testing.T) {
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitTestGitRepo(t, repoDir)
+	headSHA := testutil.GetHeadSHA(t, repoDir)
+
+	db := testutil.OpenTestDB(t)
+	cfg := config.DefaultConfig()
+	cfg.ReviewRotation = config.RotationConfig{Mode: "alternate", Agents: []string{"test", "test"}}
+	server := NewServer(db, cfg, "")
+
+	enqueue := func(agentName string) storage.ReviewJob {
+		t.Helper()
+		reqData := map[string]string{"repo_path": repoDir, "commit_sha": headSHA}
+		if agentName != "" {
+			reqData["agent"] = agentName
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", reqData)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("enqueue: status %d: %s", w.Code, w.Body.String())
+		}
+		var job storage.ReviewJob
+		if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return job
+	}
+
+	first := enqueue("")
+	if first.Agent != "test" || first.AgentPolicy != "rotation:alternate test (1/2)" {
+		t.Errorf("unexpected first job agent=%q policy=%q", first.Agent, first.AgentPolicy)
+	}
+	stored, err := db.GetJobByID(t.Context(), first.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if stored.AgentPolicy != first.AgentPolicy {
+		t.Errorf("stored policy %q, want %q", stored.AgentPolicy, first.AgentPolicy)
+	}
+
+	second := enqueue("")
+	if second.AgentPolicy != "rotation:alternate test (2/2)" {
+		t.Errorf("unexpected second policy %q", second.AgentPolicy)
+	}
+
+	// An explicitly requested agent bypasses rotation
+	explicit := enqueue("test")
+	if explicit.AgentPolicy != "" {
+		t.Errorf("expected no policy for explicit agent, got %q", explicit.AgentPolicy)
+	}
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"log"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// matchRoutes returns the repo's routes matching the files a commit or
+// range touches. Changes whose files cannot be listed match no route.
+func (s *Server) matchRoutes(repoRoot, gitCwd, gitRef string) []config.RouteConfig {
+	repoCfg, err := config.LoadRepoConfig(repoRoot)
+	if err != nil || repoCfg == nil || len(repoCfg.Routes) == 0 {
+		return nil
+	}
+	var files []string
+	if strings.Contains(gitRef, "..") {
+		files, err = git.GetRangeFilesChanged(gitCwd, gitRef)
+	} else {
+		files, err = git.GetFilesChanged(gitCwd, gitRef)
+	}
+	if err != nil {
+		log.Printf("Routes: list files of %s: %v", gitRef, err)
+		return nil
+	}
+	return config.ResolveRoutes(repoRoot, files)
+}
+
+// applyRoutes fills the review settings a request leaves unset from the
+// first matching route that sets each one. A route's model only comes with
+// its agent. Returns the agent policy to record when a route picked the
+// agent.
+func applyRoutes(routes []config.RouteConfig, req *EnqueueRequest) string {
+	var agentPolicy string
+	for _, route := range routes {
+		if config.IsDefaultReviewType(req.ReviewType) && (route.ReviewType == "security" || route.ReviewType == "design") {
+			req.ReviewType = route.ReviewType
+		}
+		if req.Reasoning == "" {
+			req.Reasoning = route.Reasoning
+		}
+		if strings.TrimSpace(req.Agent) == "" && route.Agent != "" {
+			req.Agent = route.Agent
+			if strings.TrimSpace(req.Model) == "" {
+				req.Model = route.Model
+			}
+			agentPolicy = "route " + route.ID()
+		}
+	}
+	return agentPolicy
+}
+
+// recordRoutes stores the routes a job matched, so hooks can find them
+// when the review finishes
+func (s *Server) recordRoutes(jobID int64, routes []config.RouteConfig) {
+	if s.db == nil || len(routes) == 0 {
+		return
+	}
+	ids := make([]string, len(routes))
+	for i, r := range routes {
+		ids[i] = r.ID()
+	}
+	if err := s.db.AddJobRoutes(jobID, ids); err != nil {
+		log.Printf("Routes: record routes of job %d: %v", jobID, err)
+	}
+}
@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleEnqueueRoutes(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	writeRepoConfig(t, repoDir, `
+[[routes]]
+name = "payments"
+paths = ["services/payments/**"]
+review_type = "security"
+agent = "test"
+model = "payments-model"
+reasoning = "thorough"
+owners = ["payments-team"]
+
+[[routes]]
+paths = ["services/"]
+reasoning = "fast"
+owners = ["platform"]
+`)
+
+	commit := func(file string) {
+		t.Helper()
+		path := filepath.Join(repoDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "change " + file}} {
+			if out, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+	}
+	enqueue := func(body map[string]any) *storage.ReviewJob {
+		t.Helper()
+		body["repo_path"] = repoDir
+		body["git_ref"] = "HEAD"
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", body))
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		return &job
+	}
+
+	commit("services/payments/charge.go")
+	job := enqueue(map[string]any{})
+	if job.ReviewType != "security" || job.Agent != "test" || job.Model != "payments-model" || job.Reasoning != "thorough" {
+		t.Errorf("expected the payments route's settings, got %+v", job)
+	}
+	if job.AgentPolicy != "route payments" {
+		t.Errorf("expected the route recorded as the agent policy, got %q", job.AgentPolicy)
+	}
+	if routes, err := db.GetJobRoutes(job.ID); err != nil || !slices.Equal(routes, []string{"payments", "services/"}) {
+		t.Errorf("expected both routes recorded, got %v, %v", routes, err)
+	}
+
+	// Settings in the request win over routes
+	job = enqueue(map[string]any{"reasoning": "standard", "agent": "test"})
+	if job.Reasoning != "standard" || job.Model == "payments-model" || job.AgentPolicy != "" {
+		t.Errorf("expected the request's settings, got %+v", job)
+	}
+
+	commit("services/search/index.go")
+	job = enqueue(map[string]any{"agent": "test"})
+	if !config.IsDefaultReviewType(job.ReviewType) || job.Reasoning != "fast" {
+		t.Errorf("expected only the services route's reasoning, got %+v", job)
+	}
+
+	commit("README.md")
+	job = enqueue(map[string]any{"agent": "test"})
+	if routes, _ := db.GetJobRoutes(job.ID); len(routes) != 0 {
+		t.Errorf("expected no routes for files outside them, got %v", routes)
+	}
+}
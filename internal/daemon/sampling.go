@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// riskyPathWords mark files whose changes deserve review whatever their
+// size: security-sensitive code, schema changes, build and deploy config
+var riskyPathWords = []string{
+	"auth", "security", "crypto", "secret", "password", "token", "permission",
+	"migration", "schema", ".github/workflows", "dockerfile", "deploy", "infra",
+}
+
+// samplingPolicy applies the repo's sampling settings to a commit its hook
+// enqueued. Returns the reason to record when the commit is sampled out.
+// Commits are never sampled out when their risk cannot be read.
+func (s *Server) samplingPolicy(repoRoot string, repoID int64, sha string) string {
+	sampling := config.ResolveSampling(repoRoot, s.configWatcher.Config())
+	switch sampling.Mode {
+	case "every":
+		if s.db == nil {
+			return "" // Counting reviews needs the local database
+		}
+		n, err := s.db.CountCommitReviewJobs(repoID)
+		if err != nil {
+			log.Printf("Sampling: count jobs of %s: %v", repoRoot, err)
+			return ""
+		}
+		if n%sampling.Every == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s (reviewing every %s commit)", storage.SampledOutPrefix, ordinal(sampling.Every))
+	case "risk":
+		churn, err := git.GetCommitChurn(repoRoot, sha)
+		if err != nil {
+			log.Printf("Sampling: read changes of %s: %v", sha, err)
+			return ""
+		}
+		risk := commitRisk(churn)
+		p := sampling.Rate + (1-sampling.Rate)*risk
+		if shaFraction(sha) < p {
+			return ""
+		}
+		return fmt.Sprintf("%s (risk %.2f, review probability %.0f%%)", storage.SampledOutPrefix, risk, 100*p)
+	default:
+		return ""
+	}
+}
+
+// commitRisk scores a commit's changes from 0 to 1: larger changes, changes
+// spread over more files and changes to risky paths score higher
+func commitRisk(churn []git.FileChurn) float64 {
+	lines := 0
+	risky := false
+	for _, c := range churn {
+		lines += c.LinesChanged
+		path := strings.ToLower(c.Path)
+		for _, word := range riskyPathWords {
+			if strings.Contains(path, word) {
+				risky = true
+			}
+		}
+	}
+	risk := 0.5*math.Min(1, float64(lines)/500) + 0.2*math.Min(1, float64(len(churn))/20)
+	if risky {
+		risk += 0.5
+	}
+	return math.Min(1, risk)
+}
+
+// shaFraction maps a commit SHA to [0, 1), so a commit's sampling decision
+// is random across commits but the same each time it is enqueued
+func shaFraction(sha string) float64 {
+	b, err := hex.DecodeString(sha[:min(len(sha), 8)])
+	if err != nil || len(b) < 4 {
+		return 0
+	}
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	return float64(v) / (1 << 32)
+}
+
+// ordinal formats n as "2nd", "3rd", "10th" and so on
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+func TestCommitRisk(t *testing.T) {
+	tests := []struct {
+		name  string
+		churn []git.FileChurn
+		min   float64
+		max   float64
+	}{
+		{"empty", nil, 0, 0},
+		{"small change", []git.FileChurn{{Path: "README.md", LinesChanged: 5}}, 0, 0.1},
+		{"large change", []git.FileChurn{{Path: "main.go", LinesChanged: 1000}}, 0.5, 0.6},
+		{"risky path", []git.FileChurn{{Path: "internal/Auth/login.go", LinesChanged: 2}}, 0.5, 0.6},
+		{"capped", []git.FileChurn{{Path: "db/migrations/001.sql", LinesChanged: 5000}}, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitRisk(tt.churn); got < tt.min || got > tt.max {
+				t.Errorf("commitRisk() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestShaFraction(t *testing.T) {
+	if got := shaFraction("00000000abc"); got != 0 {
+		t.Errorf("shaFraction(zeros) = %v, want 0", got)
+	}
+	if got := shaFraction("80000000abc"); got != 0.5 {
+		t.Errorf("shaFraction(80000000) = %v, want 0.5", got)
+	}
+	if got := shaFraction("ffffffffabc"); got >= 1 {
+		t.Errorf("shaFraction(ffffffff) = %v, want < 1", got)
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 22: "22nd", 101: "101st"} {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// SymbolSearchResponse is returned by GET /api/search
+type SymbolSearchResponse struct {
+	Matches []storage.SymbolMatch `json:"matches"`
+}
+
+// ReviewSearchResponse is returned by GET /api/search/reviews
+type ReviewSearchResponse struct {
+	Matches []storage.ReviewSearchMatch `json:"matches"`
+}
+
+// handleSearch finds jobs whose diff changed a function, method or type.
+// The optional repo parameter is a repo root path.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	symbol := q.Get("symbol")
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	var repoID int64
+	if repoPath := q.Get("repo"); repoPath != "" {
+		repo, err := s.db.FindRepo(r.Context(), repoPath)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repo not found")
+			return
+		}
+		repoID = repo.ID
+	}
+
+	matches, err := s.db.SearchSymbol(symbol, repoID, limit)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("search symbols: %v", err))
+		return
+	}
+	if matches == nil {
+		matches = []storage.SymbolMatch{}
+	}
+	writeJSON(w, http.StatusOK, SymbolSearchResponse{Matches: matches})
+}
+
+// handleSearchReviews finds reviews whose output or prompt contain every
+// term of the q parameter. The optional repo parameter is a repo root path
+// and since is an RFC 3339 time.
+func (s *Server) handleSearchReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := storage.ReviewSearchFilter{Agent: q.Get("agent"), Limit: 50}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: expected RFC 3339 time")
+			return
+		}
+		filter.Since = since
+	}
+	if repoPath := q.Get("repo"); repoPath != "" {
+		repo, err := s.db.FindRepo(r.Context(), repoPath)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repo not found")
+			return
+		}
+		filter.RepoID = repo.ID
+	}
+
+	matches, err := s.db.SearchReviews(r.Context(), q.Get("q"), filter)
+	if errors.Is(err, storage.ErrEmptySearch) {
+		writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("search reviews: %v", err))
+		return
+	}
+	if matches == nil {
+		matches = []storage.ReviewSearchMatch{}
+	}
+	writeJSON(w, http.StatusOK, ReviewSearchResponse{Matches: matches})
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleSearch(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "searchrepo")
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", "- High: nil map write")
+	if err := db.SaveJobSymbols(job.ID, []storage.ChangedSymbol{{Name: "Config.Load", File: "config.go"}}); err != nil {
+		t.Fatalf("SaveJobSymbols: %v", err)
+	}
+
+	search := func(q url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/search?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+		server.handleSearch(w, req)
+		return w
+	}
+
+	w := search(url.Values{"symbol": {"Load"}, "repo": {repoDir}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var resp SymbolSearchResponse
+	testutil.DecodeJSON(t, w, &resp)
+	if len(resp.Matches) != 1 {
+		t.Fatalf("expected 1 match, got %+v", resp.Matches)
+	}
+	if m := resp.Matches[0]; m.JobID != job.ID || m.Name != "Config.Load" || m.Verdict != "F" {
+		t.Errorf("unexpected match %+v", m)
+	}
+
+	w = search(url.Values{"symbol": {"Missing"}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	resp = SymbolSearchResponse{}
+	testutil.DecodeJSON(t, w, &resp)
+	if resp.Matches == nil || len(resp.Matches) != 0 {
+		t.Errorf("expected empty matches, got %+v", resp.Matches)
+	}
+
+	testutil.AssertStatusCode(t, search(url.Values{}), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, search(url.Values{"symbol": {"Load"}, "repo": {"/nonexistent"}}), http.StatusNotFound)
+}
+
+func TestHandleSearchReviews(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "searchrepo")
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", "- High: ParseConfig writes to a nil map")
+
+	search := func(q url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/search/reviews?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+		server.handleSearchReviews(w, req)
+		return w
+	}
+
+	w := search(url.Values{"q": {"parseconfig"}, "repo": {repoDir}})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var resp ReviewSearchResponse
+	testutil.DecodeJSON(t, w, &resp)
+	if len(resp.Matches) != 1 {
+		t.Fatalf("expected 1 match, got %+v", resp.Matches)
+	}
+	if m := resp.Matches[0]; m.JobID != job.ID || m.Verdict != "F" || !strings.Contains(m.Snippet, "[ParseConfig]") {
+		t.Errorf("unexpected match %+v", m)
+	}
+
+	for _, q := range []url.Values{
+		{"q": {"parseconfig"}, "agent": {"claude-code"}},
+		{"q": {"parseconfig"}, "since": {time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}},
+	} {
+		w = search(q)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		resp = ReviewSearchResponse{}
+		testutil.DecodeJSON(t, w, &resp)
+		if resp.Matches == nil || len(resp.Matches) != 0 {
+			t.Errorf("%v: expected empty matches, got %+v", q, resp.Matches)
+		}
+	}
+
+	testutil.AssertStatusCode(t, search(url.Values{}), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, search(url.Values{"q": {"x"}, "since": {"yesterday"}}), http.StatusBadRequest)
+	testutil.AssertStatusCode(t, search(url.Values{"q": {"x"}, "repo": {"/nonexistent"}}), http.StatusNotFound)
+}
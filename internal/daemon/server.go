@@ -3,13 +3,17 @@ package daemon
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,13 +21,16 @@ import (
 	"github.com/roborev-dev/roborev/internal/agent"
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/jj"
 	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/vcs"
 	"github.com/roborev-dev/roborev/internal/version"
 )
 
 // Server is the HTTP API server for the daemon
 type Server struct {
-	db            *storage.DB
+	store         storage.Storage
+	db            *storage.DB // store when it is the local database, nil otherwise
 	configWatcher *ConfigWatcher
 	broadcaster   Broadcaster
 	workerPool    *WorkerPool
@@ -31,19 +38,38 @@ type Server struct {
 	syncWorker    *storage.SyncWorker
 	ciPoller      *CIPoller
 	hookRunner    *HookRunner
+	issueFiler    *IssueFiler
 	errorLog      *ErrorLog
+	rotator       *agentRotator
+	idle          *idleMonitor // nil when idle shutdown is disabled
+	backups       *backupScheduler
+	queueSampler  *queueSampler
+	pruner        *pruner
+	slaMonitor    *slaMonitor
+	starvation    *starvationMonitor
 	startTime     time.Time
 
 	// Cached machine ID to avoid INSERT on every status request
 	machineIDMu sync.Mutex
 	machineID   string
+
+	// Serializes clones and fetches of hosted repo mirrors
+	mirrorMu sync.Mutex
+
+	// Repos whose branches are being fetched from their forge
+	branchRefreshes sync.Map
 }
 
-// NewServer creates a new daemon server
-func NewServer(db *storage.DB, cfg *config.Config, configPath string) *Server {
+// NewServer creates a new daemon server reviewing into store. Features
+// built on tables only the local database has, such as triage, sync, API
+// tokens, backups and retention, are unavailable on other backends; their
+// endpoints answer 501 Not Implemented.
+func NewServer(store storage.Storage, cfg *config.Config, configPath string) *Server {
 	// Always set for deterministic state - default to false (conservative)
 	agent.SetAllowUnsafeAgents(cfg.AllowUnsafeAgents != nil && *cfg.AllowUnsafeAgents)
 	agent.SetAnthropicAPIKey(cfg.AnthropicAPIKey)
+	agent.SetOpenAIConfig(openAIAgentConfig(cfg))
+	agent.SetExecutor(agentExecutor(cfg))
 	broadcaster := NewBroadcaster()
 
 	// Initialize error log
@@ -58,44 +84,108 @@ func NewServer(db *storage.DB, cfg *config.Config, configPath string) *Server {
 	// Create hook runner to fire hooks on review events
 	hookRunner := NewHookRunner(configWatcher, broadcaster)
 
+	db, _ := store.(*storage.DB)
 	s := &Server{
+		store:         store,
 		db:            db,
 		configWatcher: configWatcher,
 		broadcaster:   broadcaster,
-		workerPool:    NewWorkerPool(db, configWatcher, cfg.MaxWorkers, broadcaster, errorLog),
+		workerPool:    NewWorkerPool(store, configWatcher, cfg.MaxWorkers, broadcaster, errorLog),
 		hookRunner:    hookRunner,
 		errorLog:      errorLog,
+		rotator:       newAgentRotator(),
 		startTime:     time.Now(),
 	}
+	if db != nil {
+		s.issueFiler = NewIssueFiler(db, broadcaster)
+		s.backups = newBackupScheduler(db, configWatcher)
+		s.pruner = newPruner(db, configWatcher)
+		s.slaMonitor = newSLAMonitor(db, broadcaster)
+		s.queueSampler = newQueueSampler(db, s.workerPool.MaxWorkers)
+	}
+	s.starvation = newStarvationMonitor(s.workerPool, broadcaster)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/enqueue", s.handleEnqueue)
 	mux.HandleFunc("/api/health", s.handleHealth)
 	mux.HandleFunc("/api/jobs", s.handleListJobs)
+	mux.HandleFunc("/api/mirror", s.handleMirror)
 	mux.HandleFunc("/api/job/cancel", s.handleCancelJob)
+	mux.HandleFunc("/api/job/bump", s.localOnly(s.handleBumpJob))
 	mux.HandleFunc("/api/job/output", s.handleJobOutput)
 	mux.HandleFunc("/api/job/rerun", s.handleRerunJob)
 	mux.HandleFunc("/api/job/update-branch", s.handleUpdateJobBranch)
+	mux.HandleFunc("/api/job/pre-review", s.localOnly(s.handlePreReview))
 	mux.HandleFunc("/api/repos", s.handleListRepos)
 	mux.HandleFunc("/api/repos/register", s.handleRegisterRepo)
-	mux.HandleFunc("/api/branches", s.handleListBranches)
+	mux.HandleFunc("/api/badge", s.localOnly(s.handleBadge))
+	mux.HandleFunc("/api/branches", s.localOnly(s.handleListBranches))
 	mux.HandleFunc("/api/review", s.handleGetReview)
 	mux.HandleFunc("/api/review/address", s.handleAddressReview)
+	mux.HandleFunc("/api/commit-message", s.localOnly(s.handleGetCommitMessage))
+	mux.HandleFunc("/api/checklist", s.localOnly(s.handleChecklist))
+	mux.HandleFunc("/api/prompt/preview", s.localOnly(s.handlePromptPreview))
 	mux.HandleFunc("/api/comment", s.handleAddComment)
 	mux.HandleFunc("/api/comments", s.handleListComments)
+	mux.HandleFunc("/api/triage", s.localOnly(s.handleListTriage))
+	mux.HandleFunc("/api/triage/decide", s.localOnly(s.handleTriageDecision))
+	mux.HandleFunc("/api/reconcile", s.localOnly(s.handleReconcile))
+	mux.HandleFunc("/api/reconcile/decide", s.localOnly(s.handleReconcileDecision))
+	mux.HandleFunc("/api/remap", s.localOnly(s.handleRemap))
+	mux.HandleFunc("/api/hotspots", s.localOnly(s.handleHotspots))
+	mux.HandleFunc("/api/search", s.localOnly(s.handleSearch))
+	mux.HandleFunc("/api/search/reviews", s.localOnly(s.handleSearchReviews))
+	mux.HandleFunc("/api/groups", s.localOnly(s.handleListGroups))
+	mux.HandleFunc("/api/groups/status", s.localOnly(s.handleGroupStatus))
+	mux.HandleFunc("/api/groups/cancel", s.localOnly(s.handleCancelGroup))
+	mux.HandleFunc("/api/dead-letters", s.localOnly(s.handleListDeadLetters))
+	mux.HandleFunc("/api/dead-letters/show", s.localOnly(s.handleGetDeadLetter))
+	mux.HandleFunc("/api/dead-letters/requeue", s.localOnly(s.handleRequeueDeadLetters))
 	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/queue/drain", s.handleQueueDrain)
+	mux.HandleFunc("/api/queue/wake", s.handleQueueWake)
 	mux.HandleFunc("/api/stream/events", s.handleStreamEvents)
-	mux.HandleFunc("/api/sync/now", s.handleSyncNow)
-	mux.HandleFunc("/api/sync/status", s.handleSyncStatus)
+	mux.HandleFunc("/api/ws", s.handleWebSocket)
+	mux.HandleFunc("/api/sync/now", s.localOnly(s.handleSyncNow))
+	mux.HandleFunc("/api/sync/status", s.localOnly(s.handleSyncStatus))
+	mux.HandleFunc("/api/sync/export", s.localOnly(s.handleSyncExport))
+	mux.HandleFunc("/api/tokens", s.localOnly(s.handleTokens))
+	mux.HandleFunc("/api/tokens/revoke", s.localOnly(s.handleRevokeToken))
+	mux.HandleFunc("/api/share", s.localOnly(s.handleShare))
+	mux.HandleFunc("/api/share/revoke", s.localOnly(s.handleRevokeShare))
+	mux.HandleFunc(sharePathPrefix, s.localOnly(s.handleSharedReview))
+
+	var handler http.Handler = s.withAuth(s.withVersionCheck(mux))
+	if cfg.IdleShutdownMinutes > 0 {
+		s.idle = newIdleMonitor(time.Duration(cfg.IdleShutdownMinutes)*time.Minute, s.queueEmpty)
+		handler = s.idle.Wrap(handler)
+	}
 
 	s.httpServer = &http.Server{
 		Addr:    cfg.ServerAddr,
-		Handler: mux,
+		Handler: handler,
 	}
 
 	return s
 }
 
+// queueEmpty reports whether there are no queued or running jobs.
+// Errors are treated as "not empty" so a DB hiccup never triggers shutdown.
+func (s *Server) queueEmpty() bool {
+	counts, err := s.store.CountJobsByStatus(context.Background())
+	return err == nil && counts.Status(storage.JobStatusQueued) == 0 && counts.Status(storage.JobStatusRunning) == 0
+}
+
+// IdleShutdown returns a channel that is closed once the daemon has been idle
+// for the configured idle_shutdown_minutes. Returns nil (blocks forever in a
+// select) when idle shutdown is disabled.
+func (s *Server) IdleShutdown() <-chan struct{} {
+	if s.idle == nil {
+		return nil
+	}
+	return s.idle.Idle()
+}
+
 // Start begins the server and worker pool
 func (s *Server) Start(ctx context.Context) error {
 	// Clean up any zombie daemons first (there can be only one)
@@ -108,23 +198,45 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("daemon already running (pid %d on %s)", info.PID, info.Addr)
 	}
 
-	// Reset stale jobs from previous runs
-	if err := s.db.ResetStaleJobs(); err != nil {
+	// Requeue the jobs this machine was running when the daemon stopped
+	if err := s.store.ResetStaleJobs(ctx); err != nil {
 		log.Printf("Warning: failed to reset stale jobs: %v", err)
 	}
 
+	if s.db != nil {
+		// Permanently delete trashed rows past their retention
+		if err := s.db.PurgeExpiredTrash(ctx, time.Now()); err != nil {
+			log.Printf("Warning: failed to purge expired trash: %v", err)
+		}
+	}
+
 	// Start config watcher for hot-reloading
 	if err := s.configWatcher.Start(ctx); err != nil {
 		log.Printf("Warning: failed to start config watcher: %v", err)
 		// Continue without hot-reloading - not a fatal error
 	}
 
-	// Find available port
-	cfg := s.configWatcher.Config()
-	addr, port, err := FindAvailablePort(cfg.ServerAddr)
+	// Use the socket passed by systemd if socket-activated, otherwise
+	// find an available port
+	listener, err := socketActivationListener()
 	if err != nil {
-		s.configWatcher.Stop()
-		return fmt.Errorf("find available port: %w", err)
+		log.Printf("Warning: %v", err)
+	}
+	var addr string
+	var port int
+	if listener != nil {
+		addr = listener.Addr().String()
+		if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+			port = tcpAddr.Port
+		}
+		log.Printf("Using socket-activated listener on %s", addr)
+	} else {
+		cfg := s.configWatcher.Config()
+		addr, port, err = FindAvailablePort(cfg.ServerAddr)
+		if err != nil {
+			s.configWatcher.Stop()
+			return fmt.Errorf("find available port: %w", err)
+		}
 	}
 	s.httpServer.Addr = addr
 
@@ -136,8 +248,32 @@ func (s *Server) Start(ctx context.Context) error {
 	// Start worker pool
 	s.workerPool.Start()
 
+	if s.idle != nil {
+		log.Printf("Idle shutdown enabled after %s with an empty queue", s.idle.timeout)
+		s.idle.Start()
+	}
+
+	if s.db != nil {
+		// Start scheduled database backups (a no-op until enabled in config)
+		s.backups.Start()
+
+		// Delete jobs past the retention policy (a no-op until one is set)
+		s.pruner.Start()
+
+		// Record queue metrics for 'roborev stats --queue'
+		s.queueSampler.Start()
+
+		// Flag reviews that miss their repo's review SLA
+		s.slaMonitor.Start()
+	} else {
+		log.Printf("Backups, retention, trash, queue metrics and review SLAs are unavailable with a shared database")
+	}
+
+	// Warn when queued jobs sit unclaimed despite idle workers
+	s.starvation.Start()
+
 	// Check for outdated hooks in registered repos
-	if repos, err := s.db.ListRepos(); err == nil {
+	if repos, err := s.store.ListRepos(ctx); err == nil {
 		for _, repo := range repos {
 			if hookNeedsUpgrade(repo.RootPath) {
 				log.Printf("Warning: outdated post-commit hook in %s -- run 'roborev init' to upgrade", repo.RootPath)
@@ -147,7 +283,12 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Start HTTP server
 	log.Printf("Starting HTTP server on %s", addr)
-	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+	if listener != nil {
+		err = s.httpServer.Serve(listener)
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
 		s.configWatcher.Stop()
 		s.workerPool.Stop()
 		return err
@@ -155,6 +296,33 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// getOrCreateRepo registers repoRoot with its sync identity. If the repo was
+// moved since it was last seen, the existing record (and its history) is
+// relocated instead of creating a duplicate.
+func (s *Server) getOrCreateRepo(repoRoot string) (*storage.Repo, error) {
+	identity := config.ResolveRepoIdentity(repoRoot, nil)
+	rootCommit, err := git.GetRootCommit(repoRoot)
+	if err != nil {
+		log.Printf("Warning: could not determine root commit for %s: %v", repoRoot, err)
+	}
+	if s.db == nil {
+		return s.store.GetOrCreateRepo(context.Background(), repoRoot, identity)
+	}
+	return s.db.GetOrCreateRepoFollowMoves(repoRoot, identity, rootCommit)
+}
+
+// localOnly wraps a handler for an endpoint built on tables only the local
+// database has, answering 501 when the daemon runs on another backend.
+func (s *Server) localOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.db == nil {
+			writeError(w, http.StatusNotImplemented, "not supported with the configured database backend")
+			return
+		}
+		h(w, r)
+	}
+}
+
 // hookVersionMarker identifies the current hook version.
 const hookVersionMarker = "post-commit hook v2"
 
@@ -183,6 +351,10 @@ func (s *Server) Stop() error {
 	// Stop config watcher
 	s.configWatcher.Stop()
 
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+
 	// Stop HTTP server
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		log.Printf("HTTP server shutdown error: %v", err)
@@ -196,11 +368,27 @@ func (s *Server) Stop() error {
 	// Stop worker pool
 	s.workerPool.Stop()
 
+	if s.db != nil {
+		// Stop scheduled backups, letting one in progress finish
+		s.backups.Stop()
+		s.pruner.Stop()
+
+		// Stop recording queue metrics
+		s.queueSampler.Stop()
+		s.slaMonitor.Stop()
+	}
+	s.starvation.Stop()
+
 	// Stop hook runner
 	if s.hookRunner != nil {
 		s.hookRunner.Stop()
 	}
 
+	// Stop filing issues for findings
+	if s.issueFiler != nil {
+		s.issueFiler.Stop()
+	}
+
 	// Close error log
 	if s.errorLog != nil {
 		s.errorLog.Close()
@@ -355,12 +543,38 @@ func (s *Server) handleSyncStatus(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleSyncExport returns finished jobs, reviews, and comments changed since
+// the given time, for merging into another instance with 'roborev sync --from'.
+func (s *Server) handleSyncExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: expected RFC3339 timestamp")
+			return
+		}
+		since = t
+	}
+
+	bundle, err := s.db.ExportPeerBundle(r.Context(), since)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("export sync bundle: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, bundle)
+}
+
 // API request/response types
 
 type EnqueueRequest struct {
 	RepoPath     string `json:"repo_path"`
 	CommitSHA    string `json:"commit_sha,omitempty"` // Single commit (for backwards compat)
-	GitRef       string `json:"git_ref,omitempty"`    // Single commit, range like "abc..def", or "dirty"
+	GitRef       string `json:"git_ref,omitempty"`    // Single commit, range like "abc..def", "dirty", or "patch:<name>"
 	Branch       string `json:"branch,omitempty"`     // Branch name at time of job creation
 	Agent        string `json:"agent,omitempty"`
 	Model        string `json:"model,omitempty"`         // Model to use (for opencode: provider/model format)
@@ -370,6 +584,14 @@ type EnqueueRequest struct {
 	CustomPrompt string `json:"custom_prompt,omitempty"` // Custom prompt for ad-hoc agent work
 	Agentic      bool   `json:"agentic,omitempty"`       // Enable agentic mode (allow file edits)
 	OutputPrefix string `json:"output_prefix,omitempty"` // Prefix to prepend to review output
+	Hook         bool   `json:"hook,omitempty"`          // Enqueued by a commit hook: subject to the repo's sampling policy
+	Focus        bool   `json:"focus,omitempty"`         // Thorough review of a high-stakes commit, exempt from skip policies
+	Interactive  bool   `json:"interactive,omitempty"`   // Someone is waiting on the result: queue in the interactive lane
+	Group        string `json:"group,omitempty"`         // Name of the job group to add the job to, created if needed
+	GroupKind    string `json:"group_kind,omitempty"`    // Kind of a group created for the job (default "review")
+
+	// Design docs the review checks the change against, fetched by the client
+	ContextDocs []storage.ContextDoc `json:"context_docs,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -394,6 +616,29 @@ func (s *Server) writeInternalError(w http.ResponseWriter, msg string) {
 	}
 }
 
+// resolveRange resolves both ends of a range in the working tree gitCwd.
+// If the start ref is <sha>^ and sha is the root commit (no parent), the
+// start is the empty tree so the range includes the root commit's changes.
+func resolveRange(repoVCS vcs.VCS, gitCwd, rangeRef string) (string, string, error) {
+	parts := strings.SplitN(rangeRef, "..", 2)
+	startSHA, err := repoVCS.ResolveRef(gitCwd, parts[0])
+	if err != nil {
+		base, isParent := strings.CutSuffix(parts[0], "^")
+		if !isParent {
+			return "", "", fmt.Errorf("invalid start commit: %w", err)
+		}
+		if _, resolveErr := git.ResolveSHA(gitCwd, base+"^{commit}"); resolveErr != nil {
+			return "", "", fmt.Errorf("invalid start commit: %w", err)
+		}
+		startSHA = git.EmptyTreeSHA
+	}
+	endSHA, err := repoVCS.ResolveRef(gitCwd, parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end commit: %w", err)
+	}
+	return startSHA, endSHA, nil
+}
+
 func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -432,6 +677,23 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var group *storage.JobGroup
+	if req.Group != "" {
+		if s.db == nil {
+			writeError(w, http.StatusNotImplemented, "job groups are not supported with the configured database backend")
+			return
+		}
+		kind := req.GroupKind
+		if kind == "" {
+			kind = storage.JobGroupReview
+		}
+		var err error
+		if group, err = s.db.GetOrCreateJobGroup(req.Group, kind); err != nil {
+			s.writeInternalError(w, fmt.Sprintf("get group: %v", err))
+			return
+		}
+	}
+
 	// Validate and normalize review_type
 	if config.IsDefaultReviewType(req.ReviewType) {
 		req.ReviewType = "default"
@@ -443,22 +705,35 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 
 	// Get the working directory root for git commands (may be a worktree)
 	// This is needed to resolve refs like HEAD correctly in the worktree context
-	gitCwd, err := git.GetRepoRoot(req.RepoPath)
+	repoVCS := vcs.For(req.RepoPath)
+	gitCwd, err := repoVCS.RepoRoot(req.RepoPath)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a git repository: %v", err))
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a %s repository: %v", repoVCS.Name(), err))
 		return
 	}
 
 	// Get the main repo root for database storage
 	// This ensures worktrees are associated with their main repository
-	repoRoot, err := git.GetMainRepoRoot(req.RepoPath)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a git repository: %v", err))
+	repoRoot := gitCwd
+	if repoVCS.Name() == "git" {
+		repoRoot, err = git.GetMainRepoRoot(req.RepoPath)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("not a git repository: %v", err))
+			return
+		}
+	}
+	// Only commits and ranges are reviewed outside git
+	if repoVCS.Name() != "git" && (req.CustomPrompt != "" || gitRef == "dirty" || storage.IsPatchRef(gitRef)) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("only commits and ranges can be reviewed in %s repositories", repoVCS.Name()))
+		return
+	}
+	if msg := enqueueTokenError(r, req, repoRoot); msg != "" {
+		writeError(w, http.StatusForbidden, msg)
 		return
 	}
 
 	// Check if branch is excluded from reviews
-	currentBranch := git.GetCurrentBranch(gitCwd)
+	currentBranch := repoVCS.CurrentBranch(gitCwd)
 	if currentBranch != "" && config.IsBranchExcluded(repoRoot, currentBranch) {
 		// Silently skip excluded branches - return 200 OK with skipped flag
 		writeJSON(w, http.StatusOK, map[string]any{
@@ -473,16 +748,72 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		req.Branch = currentBranch
 	}
 
-	// Resolve repo identity for sync
-	repoIdentity := config.ResolveRepoIdentity(repoRoot, nil)
-
 	// Get or create repo with identity
-	repo, err := s.db.GetOrCreateRepo(repoRoot, repoIdentity)
+	repo, err := s.getOrCreateRepo(repoRoot)
 	if err != nil {
 		s.writeInternalError(w, fmt.Sprintf("get repo: %v", err))
 		return
 	}
 
+	// Commit hooks only auto-review the branches the repo selects
+	branches := s.repoBranches(repo)
+	if req.Hook && req.Branch != "" {
+		patterns := config.ResolveAutoReviewBranches(repoRoot, s.configWatcher.Config())
+		if !autoReviewed(patterns, req.Branch, branches) {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"skipped": true,
+				"reason":  fmt.Sprintf("branch %q is not in auto_review_branches", req.Branch),
+			})
+			return
+		}
+	}
+
+	// In jj workspaces, change IDs and other jj revisions name commits too
+	if req.CustomPrompt == "" && gitRef != "dirty" && !storage.IsPatchRef(gitRef) {
+		resolved, err := jj.ResolveRef(gitCwd, gitRef)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid commit: %v", err))
+			return
+		}
+		gitRef = resolved
+	}
+
+	// Thorough reviews run on the premium agent at the thorough level
+	var focusPolicy string
+	if req.CustomPrompt != "" {
+		req.Focus = false
+	}
+	if req.Focus {
+		focus := config.ResolveFocus(repoRoot, s.configWatcher.Config())
+		if strings.TrimSpace(req.Agent) == "" && focus.Agent != "" {
+			req.Agent = focus.Agent
+			focusPolicy = "focus"
+		}
+		if strings.TrimSpace(req.Model) == "" {
+			req.Model = focus.Model
+		}
+		req.Reasoning = "thorough"
+	}
+
+	// Monorepo routes fill in the review settings the request leaves unset
+	// from the paths a commit or range touches
+	var routes []config.RouteConfig
+	var routePolicy string
+	if req.CustomPrompt == "" && gitRef != "dirty" && !storage.IsPatchRef(gitRef) {
+		routes = s.matchRoutes(repoRoot, gitCwd, gitRef)
+		routePolicy = applyRoutes(routes, &req)
+	}
+
+	// Commits by bots are recorded as skipped or reviewed at the fast level
+	var botSkipReason string
+	if !req.Focus && req.CustomPrompt == "" && gitRef != "dirty" && !storage.IsPatchRef(gitRef) && !strings.Contains(gitRef, "..") {
+		var downgrade bool
+		botSkipReason, downgrade = s.botAuthorPolicy(repoRoot, gitCwd, gitRef)
+		if downgrade {
+			req.Reasoning = "fast"
+		}
+	}
+
 	// Resolve reasoning level first (needed for agent/model resolution)
 	reasoning, err := config.ResolveReviewReasoning(req.Reasoning, repoRoot)
 	if err != nil {
@@ -497,8 +828,26 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		workflow = req.ReviewType
 	}
 
+	// Apply reviewer rotation to standard reviews when no agent was requested
+	explicitAgent := req.Agent
+	agentPolicy := routePolicy
+	if focusPolicy != "" {
+		agentPolicy = focusPolicy
+	}
+	if strings.TrimSpace(req.Agent) == "" && workflow == "review" && req.CustomPrompt == "" && !req.Focus {
+		if rotation := config.ResolveReviewRotation(repoRoot, s.configWatcher.Config()); rotation.Enabled() {
+			picked, decision, err := s.rotator.Pick(repoRoot, rotation)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			explicitAgent = picked
+			agentPolicy = decision
+		}
+	}
+
 	// Resolve agent for workflow at this reasoning level
-	agentName := config.ResolveAgentForWorkflow(req.Agent, repoRoot, s.configWatcher.Config(), workflow, reasoning)
+	agentName := config.ResolveAgentForWorkflow(explicitAgent, repoRoot, s.configWatcher.Config(), workflow, reasoning)
 
 	// Resolve to an installed agent: if the configured agent isn't available,
 	// fall back through the chain (codex -> claude-code -> gemini -> ...).
@@ -507,17 +856,28 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("no review agent available: %v", err))
 		return
 	} else {
+		if agentPolicy != "" && resolved.Name() != agentName {
+			agentPolicy += ", fell back to " + resolved.Name()
+		}
 		agentName = resolved.Name()
 	}
 
-	// Resolve model for workflow at this reasoning level
-	model := config.ResolveModelForWorkflow(req.Model, repoRoot, s.configWatcher.Config(), workflow, reasoning)
+	// Resolve model for workflow at this reasoning level. Configured models
+	// are tied to the configured agent, so rotated agents only honor an
+	// explicitly requested model.
+	var model string
+	if agentPolicy != "" {
+		model = strings.TrimSpace(req.Model)
+	} else {
+		model = config.ResolveModelForWorkflow(req.Model, repoRoot, s.configWatcher.Config(), workflow, reasoning)
+	}
 
 	// Check if this is a custom prompt, dirty review, range, or single commit
 	// Note: isPrompt is determined by whether custom_prompt is provided, not git_ref value
 	// This allows reviewing a branch literally named "prompt" without collision
 	isPrompt := req.CustomPrompt != ""
-	isDirty := !isPrompt && gitRef == "dirty"
+	// Patch file reviews carry their diff like dirty reviews do
+	isDirty := !isPrompt && (gitRef == "dirty" || storage.IsPatchRef(gitRef))
 	isRange := !isPrompt && !isDirty && strings.Contains(gitRef, "..")
 
 	// Validate dirty review has diff content
@@ -533,10 +893,15 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	lane := storage.LaneBackground
+	if req.Interactive {
+		lane = storage.LaneInteractive
+	}
+
 	var job *storage.ReviewJob
 	if isPrompt {
 		// Custom prompt job - use provided prompt directly
-		job, err = s.db.EnqueueJob(storage.EnqueueOpts{
+		job, err = s.store.EnqueueJob(r.Context(), storage.EnqueueOpts{
 			RepoID:       repo.ID,
 			Branch:       req.Branch,
 			Agent:        agentName,
@@ -547,6 +912,7 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 			OutputPrefix: req.OutputPrefix,
 			Agentic:      req.Agentic,
 			Label:        gitRef, // Use git_ref as TUI label (run, analyze type, custom)
+			Lane:         lane,
 		})
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("enqueue prompt job: %v", err))
@@ -554,7 +920,7 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		}
 	} else if isDirty {
 		// Dirty review - use pre-captured diff
-		job, err = s.db.EnqueueJob(storage.EnqueueOpts{
+		job, err = s.store.EnqueueJob(r.Context(), storage.EnqueueOpts{
 			RepoID:      repo.ID,
 			GitRef:      gitRef,
 			Branch:      req.Branch,
@@ -563,6 +929,10 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 			Reasoning:   reasoning,
 			ReviewType:  req.ReviewType,
 			DiffContent: req.DiffContent,
+			AgentPolicy: agentPolicy,
+			Focus:       req.Focus,
+			Lane:        lane,
+			ContextDocs: req.ContextDocs,
 		})
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("enqueue dirty job: %v", err))
@@ -571,40 +941,27 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 	} else if isRange {
 		// For ranges, resolve both endpoints and create range job
 		// Use gitCwd to resolve refs correctly in worktree context
-		parts := strings.SplitN(gitRef, "..", 2)
-		startSHA, err := git.ResolveSHA(gitCwd, parts[0])
+		startSHA, endSHA, err := resolveRange(repoVCS, gitCwd, gitRef)
 		if err != nil {
-			// If the start ref is <sha>^ and resolution failed, the commit
-			// may be the root commit (no parent). Use the empty tree SHA so
-			// the range includes the root commit's changes.
-			if strings.HasSuffix(parts[0], "^") {
-				base := strings.TrimSuffix(parts[0], "^")
-				if _, resolveErr := git.ResolveSHA(gitCwd, base+"^{commit}"); resolveErr == nil {
-					startSHA = git.EmptyTreeSHA
-					err = nil
-				}
-			}
-			if err != nil {
-				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid start commit: %v", err))
-				return
-			}
-		}
-		endSHA, err := git.ResolveSHA(gitCwd, parts[1])
-		if err != nil {
-			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid end commit: %v", err))
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
 		// Store as full SHA range
 		fullRef := startSHA + ".." + endSHA
-		job, err = s.db.EnqueueJob(storage.EnqueueOpts{
-			RepoID:     repo.ID,
-			GitRef:     fullRef,
-			Branch:     req.Branch,
-			Agent:      agentName,
-			Model:      model,
-			Reasoning:  reasoning,
-			ReviewType: req.ReviewType,
+		job, err = s.store.EnqueueJob(r.Context(), storage.EnqueueOpts{
+			RepoID:      repo.ID,
+			GitRef:      fullRef,
+			Branch:      req.Branch,
+			Agent:       agentName,
+			Model:       model,
+			Reasoning:   reasoning,
+			ReviewType:  req.ReviewType,
+			AgentPolicy: agentPolicy,
+			Focus:       req.Focus,
+			Lane:        lane,
+			Protected:   onProtectedBranch(gitCwd, req.Branch, endSHA, branches),
+			ContextDocs: req.ContextDocs,
 		})
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("enqueue job: %v", err))
@@ -612,36 +969,75 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// Single commit - use gitCwd to resolve refs correctly in worktree context
-		sha, err := git.ResolveSHA(gitCwd, gitRef)
+		sha, err := repoVCS.ResolveRef(gitCwd, gitRef)
 		if err != nil {
 			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid commit: %v", err))
 			return
 		}
 
 		// Get commit info (SHA is absolute, so main repo root works fine)
-		info, err := git.GetCommitInfo(repoRoot, sha)
+		info, err := repoVCS.CommitInfo(repoRoot, sha)
 		if err != nil {
 			writeError(w, http.StatusBadRequest, fmt.Sprintf("get commit info: %v", err))
 			return
 		}
 
 		// Get or create commit
-		commit, err := s.db.GetOrCreateCommit(repo.ID, sha, info.Author, info.Subject, info.Timestamp)
+		commit, err := s.store.GetOrCreateCommit(r.Context(), repo.ID, sha, info.Author, info.Subject, info.Timestamp)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("get commit: %v", err))
 			return
 		}
 
-		job, err = s.db.EnqueueJob(storage.EnqueueOpts{
-			RepoID:     repo.ID,
-			CommitID:   commit.ID,
-			GitRef:     sha,
-			Branch:     req.Branch,
-			Agent:      agentName,
-			Model:      model,
-			Reasoning:  reasoning,
-			ReviewType: req.ReviewType,
-		})
+		s.recordChangeID(gitCwd, commit.ID, sha)
+
+		skipReason := info.SkipReason() // [skip roborev] / Roborev-Skip: trailer
+		if skipReason == "" {
+			skipReason = botSkipReason
+		}
+		// Cherry-picks of reviewed commits share the original's review, unless
+		// a thorough review was asked for
+		if !req.Focus {
+			patchSkip := s.linkPatchReview(repoRoot, repo.ID, commit.ID, sha)
+			if skipReason == "" {
+				skipReason = patchSkip
+			}
+		}
+		// High-volume repos review only a sample of hook-enqueued commits
+		if skipReason == "" && req.Hook && !req.Focus {
+			skipReason = s.samplingPolicy(repoRoot, repo.ID, sha)
+		}
+		// Runs of small commits fold into one held range review, unless the
+		// commit has design docs of its own
+		var holdUntil time.Time
+		if skipReason == "" && req.Hook && !req.Focus && len(req.ContextDocs) == 0 {
+			var grouped *storage.ReviewJob
+			if grouped, holdUntil = s.groupCommit(r.Context(), repoRoot, gitCwd, repo.ID, req.Branch, agentName, req.ReviewType, info); grouped != nil {
+				s.recordRoutes(grouped.ID, routes)
+				s.addToGroup(group, grouped.ID)
+				writeJSON(w, http.StatusCreated, grouped)
+				return
+			}
+		}
+
+		// The head of a branch is reviewed before backfilled history
+		job, err = s.store.EnqueueJobWithPriority(r.Context(), storage.EnqueueOpts{
+			RepoID:      repo.ID,
+			CommitID:    commit.ID,
+			GitRef:      sha,
+			Branch:      req.Branch,
+			Agent:       agentName,
+			Model:       model,
+			Reasoning:   reasoning,
+			ReviewType:  req.ReviewType,
+			AgentPolicy: agentPolicy,
+			SkipReason:  skipReason,
+			HoldUntil:   holdUntil,
+			Focus:       req.Focus,
+			Lane:        lane,
+			Protected:   onProtectedBranch(gitCwd, req.Branch, sha, branches),
+			ContextDocs: req.ContextDocs,
+		}, commitPriority(gitCwd, req.Branch, sha))
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("enqueue job: %v", err))
 			return
@@ -649,6 +1045,9 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 		job.CommitSubject = commit.Subject
 	}
 
+	s.recordRoutes(job.ID, routes)
+	s.addToGroup(group, job.ID)
+
 	// Fill in joined fields
 	job.RepoPath = repo.RootPath
 	job.RepoName = repo.Name
@@ -656,6 +1055,88 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, job)
 }
 
+// botAuthorPolicy applies the repo's bot author settings to a single
+// commit. Returns the reason to record for commits to skip, or downgrade
+// for commits to review at the fast reasoning level. Commits that cannot be
+// resolved are left to the caller to report.
+func (s *Server) botAuthorPolicy(repoRoot, gitCwd, gitRef string) (skipReason string, downgrade bool) {
+	bots := config.ResolveBotAuthors(repoRoot, s.configWatcher.Config())
+	if bots.Action == "" {
+		return "", false
+	}
+	sha, err := git.ResolveSHA(gitCwd, gitRef)
+	if err != nil {
+		return "", false
+	}
+	info, err := git.GetCommitInfo(repoRoot, sha)
+	if err != nil {
+		return "", false
+	}
+	pattern := bots.MatchAuthor(info.Author, info.AuthorEmail)
+	switch {
+	case pattern == "":
+		return "", false
+	case bots.Action == "downgrade":
+		return "", true
+	default:
+		return fmt.Sprintf("bot author %s (matches %q)", info.Author, pattern), false
+	}
+}
+
+// recordChangeID records the jj change a commit is a version of, so reviews
+// of later versions of the change can build on its review. Commits outside
+// jj workspaces have no change ID.
+func (s *Server) recordChangeID(gitCwd string, commitID int64, sha string) {
+	if s.db == nil || jj.Root(gitCwd) == "" {
+		return
+	}
+	changeID, err := jj.ChangeID(gitCwd, sha)
+	if err != nil {
+		log.Printf("Could not read jj change of %s: %v", sha, err)
+		return
+	}
+	if err := s.db.SetCommitChangeID(commitID, changeID); err != nil {
+		log.Printf("Error recording jj change of %s: %v", sha, err)
+	}
+}
+
+// linkPatchReview records the patch-id of a commit and, when the repo reuses
+// patch reviews, returns the skip reason linking it to the review of another
+// commit with the same patch, such as the original of a cherry-pick.
+func (s *Server) linkPatchReview(repoRoot string, repoID, commitID int64, sha string) string {
+	if s.db == nil {
+		return ""
+	}
+	patchID, err := git.PatchID(repoRoot, sha)
+	if err != nil {
+		log.Printf("Could not compute patch-id of %s: %v", sha, err)
+		return ""
+	}
+	if patchID == "" {
+		return ""
+	}
+	if err := s.db.SetCommitPatchID(commitID, patchID); err != nil {
+		log.Printf("Error recording patch-id of %s: %v", sha, err)
+		return ""
+	}
+	if !config.ResolveReusePatchReviews(repoRoot, s.configWatcher.Config()) {
+		return ""
+	}
+	pj, err := s.db.FindPatchJob(repoID, commitID, patchID)
+	if err != nil {
+		log.Printf("Error finding review of patch %s: %v", patchID, err)
+		return ""
+	}
+	if pj == nil {
+		return ""
+	}
+	short := pj.SHA
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	return fmt.Sprintf("same patch as %s, reviewed in job %d", short, pj.JobID)
+}
+
 func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -669,7 +1150,7 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "invalid id parameter")
 			return
 		}
-		job, err := s.db.GetJobByID(jobID)
+		job, err := s.store.GetJobByID(r.Context(), jobID)
 		if err != nil {
 			// Distinguish "not found" from actual DB errors
 			if errors.Is(err, sql.ErrNoRows) {
@@ -721,6 +1202,18 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
+	// Cursor pagination takes precedence over offset
+	var cursorID int64
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		id, err := decodeJobCursor(cursor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cursor parameter")
+			return
+		}
+		cursorID = id
+		offset = 0
+	}
+
 	// Fetch one extra to determine if there are more results
 	fetchLimit := limit
 	if limit > 0 {
@@ -741,8 +1234,37 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 	if addrStr := r.URL.Query().Get("addressed"); addrStr == "true" || addrStr == "false" {
 		listOpts = append(listOpts, storage.WithAddressed(addrStr == "true"))
 	}
+	if cursorID > 0 {
+		listOpts = append(listOpts, storage.WithBeforeID(cursorID))
+	}
+	if identity := r.URL.Query().Get("repo_identity"); identity != "" {
+		listOpts = append(listOpts, storage.WithRepoIdentity(identity))
+	}
+	author := r.URL.Query().Get("author")
+	if author != "" {
+		listOpts = append(listOpts, storage.WithAuthor(author))
+	}
+	agent := r.URL.Query().Get("agent")
+	if agent != "" {
+		listOpts = append(listOpts, storage.WithAgent(agent))
+	}
+	// since and until bound the enqueue time, as RFC 3339 times
+	var since, until time.Time
+	for param, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := r.URL.Query().Get(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid "+param+" parameter: expected RFC 3339 time")
+				return
+			}
+			*t = parsed
+		}
+	}
+	if !since.IsZero() || !until.IsZero() {
+		listOpts = append(listOpts, storage.WithDateRange(since, until))
+	}
 
-	jobs, err := s.db.ListJobs(status, repo, fetchLimit, offset, listOpts...)
+	jobs, err := s.store.ListJobs(r.Context(), status, repo, fetchLimit, offset, listOpts...)
 	if err != nil {
 		s.writeInternalError(w, fmt.Sprintf("list jobs: %v", err))
 		return
@@ -750,12 +1272,15 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 
 	// Determine if there are more results
 	hasMore := false
+	nextCursor := ""
 	if limit > 0 && len(jobs) > limit {
 		hasMore = true
 		jobs = jobs[:limit] // Trim to requested limit
+		nextCursor = encodeJobCursor(jobs[len(jobs)-1].ID)
+		w.Header().Set("Link", nextPageLink(r, nextCursor))
 	}
 
-	// Compute aggregate stats using same repo/branch filters (ignoring addressed filter and pagination)
+	// Compute aggregate stats using same repo/branch/author/agent/date filters (ignoring addressed filter and pagination)
 	var statsOpts []storage.ListJobsOption
 	if branch := r.URL.Query().Get("branch"); branch != "" {
 		if r.URL.Query().Get("branch_include_empty") == "true" {
@@ -764,18 +1289,82 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 			statsOpts = append(statsOpts, storage.WithBranch(branch))
 		}
 	}
-	stats, statsErr := s.db.CountJobStats(repo, statsOpts...)
+	if author != "" {
+		statsOpts = append(statsOpts, storage.WithAuthor(author))
+	}
+	if agent != "" {
+		statsOpts = append(statsOpts, storage.WithAgent(agent))
+	}
+	if !since.IsZero() || !until.IsZero() {
+		statsOpts = append(statsOpts, storage.WithDateRange(since, until))
+	}
+	stats, statsErr := s.store.CountJobStats(r.Context(), repo, statsOpts...)
 	if statsErr != nil {
 		log.Printf("Warning: failed to count job stats: %v", statsErr)
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"jobs":     jobs,
-		"has_more": hasMore,
-		"stats":    stats,
+		"jobs":        jobs,
+		"has_more":    hasMore,
+		"next_cursor": nextCursor,
+		"stats":       stats,
 	})
 }
 
+// encodeJobCursor returns an opaque pagination cursor pointing just past
+// the job with the given ID in the id-descending listing order.
+func encodeJobCursor(id int64) string {
+	return encodeCursor("job", strconv.FormatInt(id, 10))
+}
+
+// decodeJobCursor parses a cursor produced by encodeJobCursor.
+func decodeJobCursor(cursor string) (int64, error) {
+	return decodeIDCursor("job", cursor)
+}
+
+// encodeCursor returns an opaque pagination cursor for a listing of kind,
+// holding the sort key of the last item returned
+func encodeCursor(kind, key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + key))
+}
+
+// decodeCursor returns the sort key of a cursor that encodeCursor produced
+// for a listing of kind
+func decodeCursor(kind, cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	key, ok := strings.CutPrefix(string(raw), kind+":")
+	if !ok {
+		return "", fmt.Errorf("unrecognized cursor")
+	}
+	return key, nil
+}
+
+// decodeIDCursor parses a cursor whose sort key is a row ID
+func decodeIDCursor(kind, cursor string) (int64, error) {
+	idStr, err := decodeCursor(kind, cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid cursor id %q", idStr)
+	}
+	return id, nil
+}
+
+// nextPageLink builds an RFC 8288 Link header value for the next page,
+// preserving the request's filters and replacing any offset with cursor.
+func nextPageLink(r *http.Request, cursor string) string {
+	q := r.URL.Query()
+	q.Del("offset")
+	q.Set("cursor", cursor)
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"next\"", u.String())
+}
+
 func (s *Server) handleListRepos(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -789,10 +1378,14 @@ func (s *Server) handleListRepos(w http.ResponseWriter, r *http.Request) {
 	var totalCount int
 	var err error
 
-	if branch != "" {
-		repos, totalCount, err = s.db.ListReposWithReviewCountsByBranch(branch)
-	} else {
-		repos, totalCount, err = s.db.ListReposWithReviewCounts()
+	switch {
+	case branch == "":
+		repos, totalCount, err = storage.ListReposWithJobCounts(r.Context(), s.store)
+	case s.db == nil:
+		writeError(w, http.StatusNotImplemented, "filtering repos by branch is not supported with the configured database backend")
+		return
+	default:
+		repos, totalCount, err = s.db.ListReposWithReviewCountsByBranch(r.Context(), branch)
 	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("list repos: %v", err))
@@ -831,11 +1424,8 @@ func (s *Server) handleRegisterRepo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Resolve repo identity for sync
-	repoIdentity := config.ResolveRepoIdentity(repoRoot, nil)
-
 	// Persist (idempotent — UNIQUE on root_path)
-	repo, err := s.db.GetOrCreateRepo(repoRoot, repoIdentity)
+	repo, err := s.getOrCreateRepo(repoRoot)
 	if err != nil {
 		s.writeInternalError(w, fmt.Sprintf("register repo: %v", err))
 		return
@@ -859,7 +1449,7 @@ func (s *Server) handleListBranches(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	result, err := s.db.ListBranchesWithCounts(repoPaths)
+	result, err := s.db.ListBranchesWithCounts(r.Context(), repoPaths)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("list branches: %v", err))
 		return
@@ -893,8 +1483,8 @@ func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cancel in DB first (marks as canceled)
-	if err := s.db.CancelJob(req.JobID); err != nil {
+	// Cancels in the DB, then kills the worker's subprocess if it is running
+	if err := s.cancelJob(r.Context(), req.JobID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "job not found or not cancellable")
 			return
@@ -903,8 +1493,30 @@ func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Also cancel the running worker if job was running (kills subprocess)
-	s.workerPool.CancelJob(req.JobID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+// handleBumpJob moves a queued job to the front of the queue
+func (s *Server) handleBumpJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req CancelJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JobID == 0 {
+		writeError(w, http.StatusBadRequest, "job_id is required")
+		return
+	}
+
+	if err := s.bumpJob(r.Context(), req.JobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "job not found or not queued")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("bump job: %v", err))
+		return
+	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
 }
@@ -936,7 +1548,7 @@ func (s *Server) handleJobOutput(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check job exists
-	job, err := s.db.GetJobByID(jobID)
+	job, err := s.store.GetJobByID(r.Context(), jobID)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "job not found")
 		return
@@ -1011,7 +1623,7 @@ func (s *Server) handleJobOutput(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				// Job finished - channel closed, fetch actual status
 				finalStatus := "done"
-				if finalJob, err := s.db.GetJobByID(jobID); err == nil {
+				if finalJob, err := s.store.GetJobByID(r.Context(), jobID); err == nil {
 					finalStatus = string(finalJob.Status)
 				}
 				encoder.Encode(map[string]interface{}{
@@ -1053,7 +1665,7 @@ func (s *Server) handleRerunJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.db.ReenqueueJob(req.JobID); err != nil {
+	if err := s.store.ReenqueueJob(r.Context(), req.JobID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "job not found or not rerunnable")
 			return
@@ -1089,7 +1701,7 @@ func (s *Server) handleUpdateJobBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rowsAffected, err := s.db.UpdateJobBranch(req.JobID, req.Branch)
+	rowsAffected, err := s.store.UpdateJobBranch(r.Context(), req.JobID, req.Branch)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("update branch: %v", err))
 		return
@@ -1117,9 +1729,18 @@ func (s *Server) handleGetReview(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "invalid job_id")
 			return
 		}
-		review, err = s.db.GetReviewByJobID(jobID)
+		review, err = s.store.GetReviewByJobID(r.Context(), jobID)
 	} else if sha := r.URL.Query().Get("sha"); sha != "" {
-		review, err = s.db.GetReviewByCommitSHA(sha)
+		review, err = s.store.GetReviewByCommitSHA(r.Context(), sha)
+		// Cherry-picks share the review of the commit they were picked
+		// from, as recorded by patch-id in the local database
+		if err != nil && s.db != nil {
+			if canonical, perr := s.db.GetPatchReviewSHA(sha); perr == nil && canonical != "" {
+				if review, err = s.store.GetReviewByCommitSHA(r.Context(), canonical); err == nil {
+					review.CanonicalSHA = canonical
+				}
+			}
+		}
 	} else {
 		writeError(w, http.StatusBadRequest, "job_id or sha parameter required")
 		return
@@ -1129,10 +1750,30 @@ func (s *Server) handleGetReview(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "review not found")
 		return
 	}
+	if s.db != nil {
+		s.addReviewDetails(review)
+	}
 
 	writeJSON(w, http.StatusOK, review)
 }
 
+// addReviewDetails fills in what the local database records about a
+// review's job besides the review itself
+func (s *Server) addReviewDetails(review *storage.Review) {
+	if env, err := s.db.GetJobEnv(review.JobID); err == nil {
+		review.Env = env
+	}
+	if usage, err := s.db.GetJobUsage(review.JobID); err == nil {
+		review.Usage = usage
+	}
+	if pre, err := s.db.GetPreReview(review.JobID); err == nil {
+		review.PreReview = pre
+	}
+	if risk, err := s.db.GetInjectionRisk(review.JobID); err == nil {
+		review.InjectionRisk = risk
+	}
+}
+
 type AddCommentRequest struct {
 	SHA       string `json:"sha,omitempty"`    // Legacy: link to commit by SHA
 	JobID     int64  `json:"job_id,omitempty"` // Preferred: link to job
@@ -1168,7 +1809,7 @@ func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
 
 	if req.JobID != 0 {
 		// Link to job (preferred method)
-		resp, err = s.db.AddCommentToJob(req.JobID, req.Commenter, req.Comment)
+		resp, err = s.store.AddCommentToJob(r.Context(), req.JobID, req.Commenter, req.Comment)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				writeError(w, http.StatusNotFound, "job not found")
@@ -1179,13 +1820,13 @@ func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// Legacy: link to commit by SHA
-		commit, err := s.db.GetCommitBySHA(req.SHA)
+		commit, err := s.store.GetCommitBySHA(r.Context(), req.SHA)
 		if err != nil {
 			writeError(w, http.StatusNotFound, "commit not found")
 			return
 		}
 
-		resp, err = s.db.AddComment(commit.ID, req.Commenter, req.Comment)
+		resp, err = s.store.AddComment(r.Context(), commit.ID, req.Commenter, req.Comment)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("add comment: %v", err))
 			return
@@ -1211,13 +1852,13 @@ func (s *Server) handleListComments(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "invalid job_id")
 			return
 		}
-		responses, err = s.db.GetCommentsForJob(jobID)
+		responses, err = s.store.GetCommentsForJob(r.Context(), jobID)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Sprintf("get responses: %v", err))
 			return
 		}
 	} else if sha := r.URL.Query().Get("sha"); sha != "" {
-		responses, err = s.db.GetCommentsForCommitSHA(sha)
+		responses, err = s.store.GetCommentsForCommitSHA(r.Context(), sha)
 		if err != nil {
 			writeError(w, http.StatusNotFound, "commit not found")
 			return
@@ -1241,23 +1882,42 @@ func (s *Server) getMachineID() string {
 	}
 
 	// Try to fetch - only cache on success
-	if id, err := s.db.GetMachineID(); err == nil && id != "" {
+	store, ok := s.store.(machineIdentifier)
+	if !ok {
+		return ""
+	}
+	if id, err := store.GetMachineID(); err == nil && id != "" {
 		s.machineID = id
 	}
 	return s.machineID
 }
 
+// machineIdentifier is implemented by the stores that know which machine
+// they record changes for
+type machineIdentifier interface {
+	GetMachineID() (string, error)
+}
+
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	queued, running, done, failed, canceled, err := s.db.GetJobCounts()
+	status, err := s.daemonStatus(r.Context())
 	if err != nil {
-		s.writeInternalError(w, fmt.Sprintf("get counts: %v", err))
+		s.writeInternalError(w, err.Error())
 		return
 	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// daemonStatus collects the job counts and worker state reported by /api/status.
+func (s *Server) daemonStatus(ctx context.Context) (storage.DaemonStatus, error) {
+	counts, err := s.store.CountJobsByStatus(ctx)
+	if err != nil {
+		return storage.DaemonStatus{}, fmt.Errorf("get counts: %w", err)
+	}
 
 	// Get config reload time and counter
 	configReloadedAt := ""
@@ -1266,23 +1926,95 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	configReloadCounter := s.configWatcher.ReloadCounter()
 
-	status := storage.DaemonStatus{
+	// Accuracy, the schema, broken repos and SLAs are tracked in the
+	// local database only, and left out on other backends
+	var accuracy []storage.AgentFindingAccuracy
+	var dbSchema, overdue int
+	var broken []storage.BrokenRepo
+	if s.db != nil {
+		if accuracy, err = s.db.GetFindingAccuracy(time.Now().AddDate(0, 0, -30)); err != nil {
+			return storage.DaemonStatus{}, fmt.Errorf("get finding accuracy: %w", err)
+		}
+		if dbSchema, err = s.db.StoredSchemaVersion(); err != nil {
+			return storage.DaemonStatus{}, err
+		}
+		if broken, err = s.db.ListBrokenRepos(); err != nil {
+			return storage.DaemonStatus{}, fmt.Errorf("list broken repos: %w", err)
+		}
+		if overdue, err = s.db.CountOverdueJobs(); err != nil {
+			return storage.DaemonStatus{}, fmt.Errorf("count overdue jobs: %w", err)
+		}
+	}
+
+	starvation, err := s.workerPool.Starvation(time.Now())
+	if err != nil {
+		return storage.DaemonStatus{}, err
+	}
+
+	return storage.DaemonStatus{
 		Version:             version.Version,
-		QueuedJobs:          queued,
-		RunningJobs:         running,
-		CompletedJobs:       done,
-		FailedJobs:          failed,
-		CanceledJobs:        canceled,
+		APIVersion:          APIVersion,
+		MinAPIVersion:       MinAPIVersion,
+		SchemaVersion:       storage.SchemaVersion,
+		DBSchemaVersion:     dbSchema,
+		QueuedJobs:          counts.Status(storage.JobStatusQueued),
+		RunningJobs:         counts.Status(storage.JobStatusRunning),
+		CompletedJobs:       counts.Status(storage.JobStatusDone),
+		FailedJobs:          counts.Status(storage.JobStatusFailed),
+		CanceledJobs:        counts.Status(storage.JobStatusCanceled),
+		OverdueJobs:         overdue,
 		ActiveWorkers:       s.workerPool.ActiveWorkers(),
 		MaxWorkers:          s.workerPool.MaxWorkers(),
+		Draining:            s.workerPool.Draining(),
 		MachineID:           s.getMachineID(),
 		ConfigReloadedAt:    configReloadedAt,
 		ConfigReloadCounter: configReloadCounter,
+		FindingAccuracy:     accuracy,
+		BrokenRepos:         broken,
+		Workers:             s.workerPool.Workers(),
+		Starvation:          starvation,
+	}, nil
+}
+
+type QueueDrainRequest struct {
+	Draining bool `json:"draining"`
+}
+
+// handleQueueDrain stops or resumes claiming of queued jobs. Running jobs
+// keep going; callers poll /api/status until running_jobs reaches zero.
+func (s *Server) handleQueueDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req QueueDrainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
 	}
 
+	s.workerPool.SetDraining(req.Draining)
+	status, err := s.daemonStatus(r.Context())
+	if err != nil {
+		s.writeInternalError(w, err.Error())
+		return
+	}
 	writeJSON(w, http.StatusOK, status)
 }
 
+// handleQueueWake has idle workers look for jobs now. Processes that queue
+// jobs in the database directly call it so the jobs start without waiting
+// for the workers' next poll.
+func (s *Server) handleQueueWake(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.workerPool.Wake()
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
 type AddressReviewRequest struct {
 	JobID     int64 `json:"job_id"`
 	Addressed bool  `json:"addressed"`
@@ -1305,7 +2037,7 @@ func (s *Server) handleAddressReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.db.MarkReviewAddressedByJobID(req.JobID, req.Addressed); err != nil {
+	if err := s.markAddressed(r.Context(), req.JobID, req.Addressed); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "review not found for job")
 			return
@@ -1362,6 +2094,15 @@ func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// pingStore checks that the review database can be reached, for the
+// stores that can tell
+func (s *Server) pingStore(ctx context.Context) error {
+	if p, ok := s.store.(interface{ PingContext(context.Context) error }); ok {
+		return p.PingContext(ctx)
+	}
+	return nil
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -1379,7 +2120,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	// Database health check
 	dbHealthy := true
 	dbMessage := ""
-	if err := s.db.Ping(); err != nil {
+	if err := s.pingStore(r.Context()); err != nil {
 		dbHealthy = false
 		dbMessage = err.Error()
 		allHealthy = false
@@ -1393,7 +2134,11 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	// Worker health check - look for stalled jobs (running > 30 min)
 	workersHealthy := true
 	workersMessage := ""
-	stalledCount, err := s.db.CountStalledJobs(30 * time.Minute)
+	var stalledCount int
+	var err error
+	if s.db != nil {
+		stalledCount, err = s.db.CountStalledJobs(30 * time.Minute)
+	}
 	if err != nil {
 		workersHealthy = false
 		workersMessage = fmt.Sprintf("error checking stalled jobs: %v", err)
@@ -13,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -95,6 +96,72 @@ func newTestServer(t *testing.T) (*Server, *storage.DB, string) {
 	return server, db, tmpDir
 }
 
+// storeOnly hides that a Storage is the local database, like PgStore
+type storeOnly struct{ storage.Storage }
+
+func TestServerOnOtherBackend(t *testing.T) {
+	db, tmpDir := testutil.OpenTestDBWithDir(t)
+	server := NewServer(storeOnly{db}, config.DefaultConfig(), "")
+
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo"))
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo failed: %v", err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, Agent: "test", Prompt: "review this", Label: "run"})
+	if err != nil {
+		t.Fatalf("EnqueueJob failed: %v", err)
+	}
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.RemoteAddr = "127.0.0.1:50000"
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("core endpoints use the store", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/status")
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var status storage.DaemonStatus
+		testutil.DecodeJSON(t, w, &status)
+		if status.QueuedJobs != 1 {
+			t.Errorf("Expected 1 queued job, got %d", status.QueuedJobs)
+		}
+
+		w = serve(http.MethodGet, "/api/jobs")
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var jobs struct {
+			Jobs []storage.ReviewJob `json:"jobs"`
+		}
+		testutil.DecodeJSON(t, w, &jobs)
+		if len(jobs.Jobs) != 1 || jobs.Jobs[0].ID != job.ID {
+			t.Errorf("Expected job %d, got %+v", job.ID, jobs.Jobs)
+		}
+
+		w = serve(http.MethodGet, "/api/repos")
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var repos struct {
+			Repos      []storage.RepoWithCount `json:"repos"`
+			TotalCount int                     `json:"total_count"`
+		}
+		testutil.DecodeJSON(t, w, &repos)
+		if len(repos.Repos) != 1 || repos.TotalCount != 1 {
+			t.Errorf("Expected 1 repo with 1 job, got %+v", repos)
+		}
+
+		testutil.AssertStatusCode(t, serve(http.MethodGet, "/api/health"), http.StatusOK)
+	})
+
+	t.Run("local-only endpoints answer 501", func(t *testing.T) {
+		for _, target := range []string{"/api/triage", "/api/dead-letters", "/api/tokens", "/api/repos?branch=main"} {
+			if w := serve(http.MethodGet, target); w.Code != http.StatusNotImplemented {
+				t.Errorf("GET %s: expected 501, got %d: %s", target, w.Code, w.Body.String())
+			}
+		}
+	})
+}
+
 func TestNewServerAllowUnsafeAgents(t *testing.T) {
 	boolTrue := true
 	boolFalse := false
@@ -185,11 +252,11 @@ func TestHandleListRepos(t *testing.T) {
 	})
 
 	// Create repos and jobs
-	repo1, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo1"))
+	repo1, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo1"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	repo2, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo2"))
+	repo2, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo2"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -197,11 +264,11 @@ func TestHandleListRepos(t *testing.T) {
 	// Add 3 jobs to repo1
 	for i := 0; i < 3; i++ {
 		sha := "repo1sha" + string(rune('a'+i))
-		commit, err := db.GetOrCreateCommit(repo1.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo1.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		if _, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 	}
@@ -209,11 +276,11 @@ func TestHandleListRepos(t *testing.T) {
 	// Add 2 jobs to repo2
 	for i := 0; i < 2; i++ {
 		sha := "repo2sha" + string(rune('a'+i))
-		commit, err := db.GetOrCreateCommit(repo2.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo2.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		if _, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 	}
@@ -272,19 +339,19 @@ func TestHandleListReposWithBranchFilter(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create repos
-	repo1, _ := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo1"))
-	repo2, _ := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo2"))
+	repo1, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo1"))
+	repo2, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo2"))
 
 	// Add jobs to repos
 	for i := 0; i < 3; i++ {
 		sha := "repo1sha" + string(rune('a'+i))
-		commit, _ := db.GetOrCreateCommit(repo1.ID, sha, "Author", "Subject", time.Now())
-		db.EnqueueJob(storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, sha, "Author", "Subject", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
 	}
 	for i := 0; i < 2; i++ {
 		sha := "repo2sha" + string(rune('a'+i))
-		commit, _ := db.GetOrCreateCommit(repo2.ID, sha, "Author", "Subject", time.Now())
-		db.EnqueueJob(storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, sha, "Author", "Subject", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
 	}
 
 	// Set branches: repo1 jobs 1,2 = main, job 3 = feature; repo2 jobs 4,5 = main
@@ -357,19 +424,19 @@ func TestHandleListBranches(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create repos
-	repo1, _ := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo1"))
-	repo2, _ := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo2"))
+	repo1, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo1"))
+	repo2, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo2"))
 
 	// Add jobs to repos
 	for i := 0; i < 3; i++ {
 		sha := "repo1sha" + string(rune('a'+i))
-		commit, _ := db.GetOrCreateCommit(repo1.ID, sha, "Author", "Subject", time.Now())
-		db.EnqueueJob(storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, sha, "Author", "Subject", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
 	}
 	for i := 0; i < 2; i++ {
 		sha := "repo2sha" + string(rune('a'+i))
-		commit, _ := db.GetOrCreateCommit(repo2.ID, sha, "Author", "Subject", time.Now())
-		db.EnqueueJob(storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, sha, "Author", "Subject", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
 	}
 
 	// Set branches: jobs 1,2,4 = main, job 3 = feature, job 5 = no branch
@@ -489,11 +556,11 @@ func TestHandleListJobsWithFilter(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create repos and jobs
-	repo1, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo1"))
+	repo1, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo1"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	repo2, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "repo2"))
+	repo2, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "repo2"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -501,11 +568,11 @@ func TestHandleListJobsWithFilter(t *testing.T) {
 	// Add 3 jobs to repo1
 	for i := 0; i < 3; i++ {
 		sha := "repo1sha" + string(rune('a'+i))
-		commit, err := db.GetOrCreateCommit(repo1.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo1.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		if _, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo1.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 	}
@@ -513,11 +580,11 @@ func TestHandleListJobsWithFilter(t *testing.T) {
 	// Add 2 jobs to repo2
 	for i := 0; i < 2; i++ {
 		sha := "repo2sha" + string(rune('a'+i))
-		commit, err := db.GetOrCreateCommit(repo2.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo2.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		if _, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
+		if _, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo2.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"}); err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 	}
@@ -770,15 +837,15 @@ func TestHandleCancelJob(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create a repo and job
-	repo, err := db.GetOrCreateRepo(tmpDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tmpDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "canceltest", "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "canceltest", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "canceltest", Agent: "test"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "canceltest", Agent: "test"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -793,7 +860,7 @@ func TestHandleCancelJob(t *testing.T) {
 			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		updated, err := db.GetJobByID(job.ID)
+		updated, err := db.GetJobByID(req.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -849,15 +916,15 @@ func TestHandleCancelJob(t *testing.T) {
 
 	t.Run("cancel running job", func(t *testing.T) {
 		// Create a new job and claim it
-		commit2, err := db.GetOrCreateCommit(repo.ID, "cancelrunning", "Author", "Subject", time.Now())
+		commit2, err := db.GetOrCreateCommit(t.Context(), repo.ID, "cancelrunning", "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		job2, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "cancelrunning", Agent: "test"})
+		job2, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "cancelrunning", Agent: "test"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		if _, err := db.ClaimJob("worker-1"); err != nil {
+		if _, err := db.ClaimJob(t.Context(), "worker-1"); err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
 
@@ -870,7 +937,7 @@ func TestHandleCancelJob(t *testing.T) {
 			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		updated, err := db.GetJobByID(job2.ID)
+		updated, err := db.GetJobByID(req.Context(), job2.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -884,18 +951,18 @@ func TestListJobsPagination(t *testing.T) {
 	server, db, _ := newTestServer(t)
 
 	// Create test repo and jobs
-	repo, err := db.GetOrCreateRepo("/test/repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/test/repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
 	// Create 10 jobs
 	for i := 0; i < 10; i++ {
-		commit, err := db.GetOrCreateCommit(repo.ID, fmt.Sprintf("sha%d", i), "author", fmt.Sprintf("subject%d", i), time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, fmt.Sprintf("sha%d", i), "author", fmt.Sprintf("subject%d", i), time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		_, err = db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: fmt.Sprintf("sha%d", i), Agent: "test"})
+		_, err = db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: fmt.Sprintf("sha%d", i), Agent: "test"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
@@ -984,6 +1051,78 @@ func TestListJobsPagination(t *testing.T) {
 		}
 	})
 
+	t.Run("cursor walks all jobs without overlap", func(t *testing.T) {
+		seen := make(map[int64]bool)
+		path := "/api/jobs?limit=4"
+		pages := 0
+		for path != "" {
+			pages++
+			if pages > 5 {
+				t.Fatal("cursor pagination did not terminate")
+			}
+			req := httptest.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			server.handleListJobs(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var result struct {
+				Jobs       []storage.ReviewJob `json:"jobs"`
+				HasMore    bool                `json:"has_more"`
+				NextCursor string              `json:"next_cursor"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			for i, j := range result.Jobs {
+				if seen[j.ID] {
+					t.Errorf("Job %d appears in more than one page", j.ID)
+				}
+				seen[j.ID] = true
+				if i > 0 && j.ID >= result.Jobs[i-1].ID {
+					t.Errorf("Jobs not in descending ID order: %d after %d", j.ID, result.Jobs[i-1].ID)
+				}
+			}
+
+			link := w.Header().Get("Link")
+			if !result.HasMore {
+				if result.NextCursor != "" || link != "" {
+					t.Errorf("Expected no cursor or Link on last page, got %q / %q", result.NextCursor, link)
+				}
+				path = ""
+				continue
+			}
+			if result.NextCursor == "" {
+				t.Fatal("Expected next_cursor when has_more=true")
+			}
+			start := strings.Index(link, "<")
+			end := strings.Index(link, ">")
+			if start < 0 || end < start || !strings.HasSuffix(link, `rel="next"`) {
+				t.Fatalf("Malformed Link header: %q", link)
+			}
+			path = link[start+1 : end]
+			if !strings.Contains(path, "limit=4") {
+				t.Errorf("Link should preserve limit, got %q", path)
+			}
+		}
+		if len(seen) != 10 {
+			t.Errorf("Expected to see 10 jobs across pages, got %d", len(seen))
+		}
+		if pages != 3 {
+			t.Errorf("Expected 3 pages, got %d", pages)
+		}
+	})
+
+	t.Run("invalid cursor rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/jobs?limit=5&cursor=not-a-cursor", nil)
+		w := httptest.NewRecorder()
+		server.handleListJobs(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
 	t.Run("offset ignored when limit=0", func(t *testing.T) {
 		// limit=0 means unlimited, offset should be ignored
 		req := httptest.NewRequest("GET", "/api/jobs?limit=0&offset=5", nil)
@@ -1013,11 +1152,11 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	server, db, _ := newTestServer(t)
 
 	// Create repo and jobs with different git refs
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	refs := []string{"abc123", "def456", "abc123..def456"}
 	for _, ref := range refs {
-		commit, _ := db.GetOrCreateCommit(repo.ID, ref, "A", "S", time.Now())
-		db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: ref, Agent: "codex"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, ref, "A", "S", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: ref, Agent: "codex"})
 	}
 
 	t.Run("git_ref filter returns matching job", func(t *testing.T) {
@@ -1080,17 +1219,17 @@ func TestHandleListJobsAddressedFilter(t *testing.T) {
 	cfg := config.DefaultConfig()
 	server := NewServer(db, cfg, "")
 
-	repo, _ := db.GetOrCreateRepo("/tmp/repo-addr-filter")
-	commit, _ := db.GetOrCreateCommit(repo.ID, "aaa", "A", "S", time.Now())
-	job1, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "aaa", Branch: "main", Agent: "codex"})
-	db.ClaimJob("w")
-	db.CompleteJob(job1.ID, "codex", "", "output1")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo-addr-filter")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "aaa", "A", "S", time.Now())
+	job1, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "aaa", Branch: "main", Agent: "codex"})
+	db.ClaimJob(t.Context(), "w")
+	db.CompleteJob(t.Context(), job1.ID, "codex", "", "output1")
 
-	commit2, _ := db.GetOrCreateCommit(repo.ID, "bbb", "A", "S2", time.Now())
-	job2, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "bbb", Branch: "main", Agent: "codex"})
-	db.ClaimJob("w")
-	db.CompleteJob(job2.ID, "codex", "", "output2")
-	db.MarkReviewAddressedByJobID(job2.ID, true)
+	commit2, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "bbb", "A", "S2", time.Now())
+	job2, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "bbb", Branch: "main", Agent: "codex"})
+	db.ClaimJob(t.Context(), "w")
+	db.CompleteJob(t.Context(), job2.ID, "codex", "", "output2")
+	db.MarkReviewAddressedByJobID(t.Context(), job2.ID, true)
 
 	t.Run("addressed=false", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/jobs?addressed=false", nil)
@@ -1121,6 +1260,80 @@ func TestHandleListJobsAddressedFilter(t *testing.T) {
 	})
 }
 
+func TestHandleListJobsAuthorFilter(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo-author-filter")
+	for _, c := range []struct{ sha, author string }{{"aaa", "Jane Doe"}, {"bbb", "jane.doe@corp"}, {"ccc", "Bob"}} {
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, c.sha, c.author, "S", time.Now())
+		db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: c.sha, Agent: "codex"})
+	}
+	if err := db.SetAuthorAlias("jane.doe@corp", "Jane Doe"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/jobs?author="+url.QueryEscape("Jane Doe"), nil)
+	w := httptest.NewRecorder()
+	server.handleListJobs(w, req)
+
+	var result struct {
+		Jobs []storage.ReviewJob `json:"jobs"`
+	}
+	json.NewDecoder(w.Body).Decode(&result)
+	if len(result.Jobs) != 2 {
+		t.Errorf("Expected 2 jobs by Jane Doe under either name, got %d", len(result.Jobs))
+	}
+}
+
+func TestHandleListJobsAgentAndDateFilters(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo-agent-filter")
+	for _, c := range []struct{ sha, agent, enqueuedAt string }{
+		{"aaa", "codex", "2026-01-05 10:00:00"},
+		{"bbb", "claude-code", "2026-02-05 10:00:00"},
+		{"ccc", "codex", "2026-03-05 10:00:00"},
+	} {
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, c.sha, "Author", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: c.sha, Agent: c.agent})
+		if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE id = ?`, c.enqueuedAt, job.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(query string) []string {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/jobs?"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleListJobs(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var result struct {
+			Jobs []storage.ReviewJob `json:"jobs"`
+		}
+		testutil.DecodeJSON(t, w, &result)
+		var refs []string
+		for _, j := range result.Jobs {
+			refs = append(refs, j.GitRef)
+		}
+		return refs
+	}
+
+	if got := list("agent=codex"); !slices.Equal(got, []string{"ccc", "aaa"}) {
+		t.Errorf("agent=codex: got %v", got)
+	}
+	if got := list("since=2026-02-01T00:00:00Z&until=2026-03-01T00:00:00Z"); !slices.Equal(got, []string{"bbb"}) {
+		t.Errorf("February: got %v", got)
+	}
+	if got := list("agent=codex&since=2026-02-01T00:00:00Z"); !slices.Equal(got, []string{"ccc"}) {
+		t.Errorf("codex since February: got %v", got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/jobs?since=yesterday", nil)
+	w := httptest.NewRecorder()
+	server.handleListJobs(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+}
+
 func TestHandleStreamEvents(t *testing.T) {
 	server, _, _ := newTestServer(t)
 
@@ -1582,16 +1795,16 @@ func TestHandleRerunJob(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create a repo
-	repo, err := db.GetOrCreateRepo(tmpDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tmpDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
 	t.Run("rerun failed job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-failed", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-failed", Agent: "test"})
-		db.ClaimJob("worker-1")
-		db.FailJob(job.ID, "some error")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-failed", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-failed", Agent: "test"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.FailJob(t.Context(), job.ID, "some error")
 
 		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/job/rerun", RerunJobRequest{JobID: job.ID})
 		w := httptest.NewRecorder()
@@ -1602,7 +1815,7 @@ func TestHandleRerunJob(t *testing.T) {
 			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		updated, err := db.GetJobByID(job.ID)
+		updated, err := db.GetJobByID(req.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -1612,9 +1825,9 @@ func TestHandleRerunJob(t *testing.T) {
 	})
 
 	t.Run("rerun canceled job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-canceled", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-canceled", Agent: "test"})
-		db.CancelJob(job.ID)
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-canceled", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-canceled", Agent: "test"})
+		db.CancelJob(t.Context(), job.ID)
 
 		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/job/rerun", RerunJobRequest{JobID: job.ID})
 		w := httptest.NewRecorder()
@@ -1625,7 +1838,7 @@ func TestHandleRerunJob(t *testing.T) {
 			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		updated, err := db.GetJobByID(job.ID)
+		updated, err := db.GetJobByID(req.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -1635,21 +1848,21 @@ func TestHandleRerunJob(t *testing.T) {
 	})
 
 	t.Run("rerun done job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-done", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-done", Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-done", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-done", Agent: "test"})
 		// Claim and complete job
 		var claimed *storage.ReviewJob
 		for {
-			claimed, _ = db.ClaimJob("worker-1")
+			claimed, _ = db.ClaimJob(t.Context(), "worker-1")
 			if claimed == nil {
 				t.Fatal("No job to claim")
 			}
 			if claimed.ID == job.ID {
 				break
 			}
-			db.CompleteJob(claimed.ID, "test", "prompt", "output")
+			db.CompleteJob(t.Context(), claimed.ID, "test", "prompt", "output")
 		}
-		db.CompleteJob(job.ID, "test", "prompt", "output")
+		db.CompleteJob(t.Context(), job.ID, "test", "prompt", "output")
 
 		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/job/rerun", RerunJobRequest{JobID: job.ID})
 		w := httptest.NewRecorder()
@@ -1660,7 +1873,7 @@ func TestHandleRerunJob(t *testing.T) {
 			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		updated, err := db.GetJobByID(job.ID)
+		updated, err := db.GetJobByID(req.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -1670,8 +1883,8 @@ func TestHandleRerunJob(t *testing.T) {
 	})
 
 	t.Run("rerun queued job fails", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-queued", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-queued", Agent: "test"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-queued", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-queued", Agent: "test"})
 
 		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/job/rerun", RerunJobRequest{JobID: job.ID})
 		w := httptest.NewRecorder()
@@ -1784,7 +1997,7 @@ func TestHandleRegisterRepo(t *testing.T) {
 		}
 
 		// Verify repo is in the DB
-		repos, err := db.ListRepos()
+		repos, err := db.ListRepos(req.Context())
 		if err != nil {
 			t.Fatalf("ListRepos: %v", err)
 		}
@@ -1817,7 +2030,7 @@ func TestHandleRegisterRepo(t *testing.T) {
 		}
 
 		// Still only one repo in DB
-		repos, err := db.ListRepos()
+		repos, err := db.ListRepos(t.Context())
 		if err != nil {
 			t.Fatalf("ListRepos: %v", err)
 		}
@@ -1871,8 +2084,8 @@ func TestHandleEnqueueExcludedBranch(t *testing.T) {
 		}
 
 		// Verify no job was created
-		queued, _, _, _, _, _ := db.GetJobCounts()
-		if queued != 0 {
+		counts, _ := db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
+		if queued := counts.Status(storage.JobStatusQueued); queued != 0 {
 			t.Errorf("Expected 0 queued jobs, got %d", queued)
 		}
 	})
@@ -1896,8 +2109,8 @@ func TestHandleEnqueueExcludedBranch(t *testing.T) {
 		}
 
 		// Verify job was created
-		queued, _, _, _, _, _ := db.GetJobCounts()
-		if queued != 1 {
+		counts, _ := db.CountJobs(t.Context(), storage.CountByStatus, storage.JobCountFilter{})
+		if queued := counts.Status(storage.JobStatusQueued); queued != 1 {
 			t.Errorf("Expected 1 queued job, got %d", queued)
 		}
 	})
@@ -1935,7 +2148,7 @@ func TestHandleEnqueueBranchFallback(t *testing.T) {
 	}
 
 	// Verify the job has the detected branch, not empty
-	job, err := db.GetJobByID(respJob.ID)
+	job, err := db.GetJobByID(req.Context(), respJob.ID)
 	if err != nil {
 		t.Fatalf("GetJob: %v", err)
 	}
@@ -1944,6 +2157,401 @@ func TestHandleEnqueueBranchFallback(t *testing.T) {
 	}
 }
 
+func TestHandleEnqueueSkipMarker(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	commitCmd := exec.Command("git", "-C", repoDir, "commit", "--allow-empty", "-m", "Regenerate mocks\n\nRoborev-Skip: generated code")
+	if out, err := commitCmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]string{
+		"repo_path": repoDir,
+		"git_ref":   "HEAD",
+		"agent":     "test",
+	})
+	w := httptest.NewRecorder()
+	server.handleEnqueue(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+
+	var respJob storage.ReviewJob
+	testutil.DecodeJSON(t, w, &respJob)
+	if respJob.Status != storage.JobStatusSkipped {
+		t.Fatalf("expected skipped job, got %s", respJob.Status)
+	}
+
+	job, err := db.GetJobByID(req.Context(), respJob.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != storage.JobStatusSkipped || job.Error != "generated code" {
+		t.Errorf("expected skipped job with reason, got status=%s error=%q", job.Status, job.Error)
+	}
+}
+
+func TestHandleEnqueueBotAuthor(t *testing.T) {
+	enqueueBotCommit := func(t *testing.T, action string) *storage.ReviewJob {
+		t.Helper()
+		server, db, tmpDir := newTestServer(t)
+
+		repoDir := filepath.Join(tmpDir, "testrepo")
+		testutil.InitTestGitRepo(t, repoDir)
+		cfg := "[bot_authors]\naction = \"" + action + "\"\n"
+		if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+		commitCmd := exec.Command("git", "-C", repoDir, "-c", "user.name=dependabot[bot]",
+			"commit", "--allow-empty", "-m", "Bump golang.org/x/net")
+		if out, err := commitCmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, out)
+		}
+
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]string{
+			"repo_path": repoDir,
+			"git_ref":   "HEAD",
+			"agent":     "test",
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+
+		var respJob storage.ReviewJob
+		testutil.DecodeJSON(t, w, &respJob)
+		job, err := db.GetJobByID(req.Context(), respJob.ID)
+		if err != nil {
+			t.Fatalf("GetJobByID: %v", err)
+		}
+		return job
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		job := enqueueBotCommit(t, "skip")
+		if job.Status != storage.JobStatusSkipped {
+			t.Fatalf("expected skipped job, got %s", job.Status)
+		}
+		if !strings.Contains(job.Error, "bot author dependabot[bot]") {
+			t.Errorf("expected bot skip reason, got %q", job.Error)
+		}
+	})
+
+	t.Run("downgrade", func(t *testing.T) {
+		job := enqueueBotCommit(t, "downgrade")
+		if job.Status != storage.JobStatusQueued {
+			t.Fatalf("expected queued job, got %s", job.Status)
+		}
+		if job.Reasoning != "fast" {
+			t.Errorf("expected fast reasoning, got %q", job.Reasoning)
+		}
+	})
+}
+
+func TestHandleEnqueueSampling(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte("[sampling]\nmode = \"every\"\nevery = 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	enqueue := func(hook bool) *storage.ReviewJob {
+		t.Helper()
+		commitCmd := exec.Command("git", "-C", repoDir, "commit", "--allow-empty", "-m", "change")
+		if out, err := commitCmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, out)
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]any{
+			"repo_path": repoDir,
+			"git_ref":   "HEAD",
+			"agent":     "test",
+			"hook":      hook,
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		return &job
+	}
+
+	var statuses []storage.JobStatus
+	for range 4 {
+		statuses = append(statuses, enqueue(true).Status)
+	}
+	want := []storage.JobStatus{storage.JobStatusQueued, storage.JobStatusSkipped, storage.JobStatusSkipped, storage.JobStatusQueued}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", statuses, want)
+		}
+	}
+
+	// Explicit reviews are never sampled out
+	job := enqueue(false)
+	if job.Status != storage.JobStatusQueued {
+		t.Errorf("expected manual enqueue to be queued, got %s", job.Status)
+	}
+
+	stats, err := db.GetRepoStats(t.Context(), job.RepoID)
+	if err != nil {
+		t.Fatalf("GetRepoStats: %v", err)
+	}
+	if stats.SampledOutJobs != 2 {
+		t.Errorf("expected 2 sampled out jobs, got %d", stats.SampledOutJobs)
+	}
+}
+
+func TestHandleEnqueueFocus(t *testing.T) {
+	server, _, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	toml := "[sampling]\nmode = \"every\"\nevery = 3\n\n[focus]\nagent = \"test\"\nmodel = \"premium\"\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	enqueue := func(fields map[string]any) *storage.ReviewJob {
+		t.Helper()
+		commitCmd := exec.Command("git", "-C", repoDir, "commit", "--allow-empty", "-m", "change")
+		if out, err := commitCmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, out)
+		}
+		body := map[string]any{"repo_path": repoDir, "git_ref": "HEAD", "hook": true}
+		for k, v := range fields {
+			body[k] = v
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", body)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		return &job
+	}
+
+	if job := enqueue(map[string]any{"agent": "test"}); job.Status != storage.JobStatusQueued || job.Focus {
+		t.Fatalf("unexpected first job %+v", job)
+	}
+
+	// The next hook commit would be sampled out, but focus reviews never are
+	job := enqueue(map[string]any{"focus": true, "reasoning": "fast"})
+	if job.Status != storage.JobStatusQueued {
+		t.Errorf("expected focus review to be queued, got %s (%s)", job.Status, job.Error)
+	}
+	if !job.Focus || job.Agent != "test" || job.Model != "premium" || job.Reasoning != "thorough" || job.AgentPolicy != "focus" {
+		t.Errorf("unexpected focus job %+v", job)
+	}
+
+	// Focus is ignored for custom prompts
+	job = enqueue(map[string]any{"focus": true, "agent": "test", "custom_prompt": "explain"})
+	if job.Focus {
+		t.Error("expected custom prompt job not to be a focus review")
+	}
+}
+
+func TestHandleEnqueueInteractive(t *testing.T) {
+	server, _, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+
+	for _, tt := range []struct {
+		fields map[string]any
+		want   string
+	}{
+		{map[string]any{}, storage.LaneBackground},
+		{map[string]any{"interactive": true}, storage.LaneInteractive},
+		{map[string]any{"interactive": true, "custom_prompt": "explain"}, storage.LaneInteractive},
+	} {
+		body := map[string]any{"repo_path": repoDir, "git_ref": "HEAD", "agent": "test"}
+		for k, v := range tt.fields {
+			body[k] = v
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", body)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		if job.Lane != tt.want {
+			t.Errorf("enqueue %v: expected lane %q, got %q", tt.fields, tt.want, job.Lane)
+		}
+	}
+}
+
+func TestHandleEnqueueContextDocs(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+
+	docs := []storage.ContextDoc{{Source: "docs/design.md", Content: "Reads go through the cache."}}
+	for _, ref := range []string{"HEAD", "dirty"} {
+		body := map[string]any{"repo_path": repoDir, "git_ref": ref, "agent": "test", "context_docs": docs}
+		if ref == "dirty" {
+			body["diff_content"] = "diff --git a/x b/x\n"
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", body)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+
+		got, err := db.GetJobContextDocs(job.ID)
+		if err != nil {
+			t.Fatalf("GetJobContextDocs: %v", err)
+		}
+		if len(got) != 1 || got[0] != docs[0] {
+			t.Errorf("%s: context docs = %+v, want %+v", ref, got, docs)
+		}
+	}
+}
+
+func TestHandleEnqueueCommitGrouping(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte("[commit_grouping]\nmode = \"author\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	enqueue := func(hook bool, author ...string) *storage.ReviewJob {
+		t.Helper()
+		args := []string{"commit", "--allow-empty", "-m", "change"}
+		if len(author) > 0 {
+			args = append(args, "--author", author[0])
+		}
+		git(args...)
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]any{
+			"repo_path": repoDir,
+			"git_ref":   "HEAD",
+			"agent":     "test",
+			"hook":      hook,
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		return &job
+	}
+
+	base := git("rev-parse", "HEAD")
+	first := enqueue(true)
+	second := enqueue(true)
+	if second.ID != first.ID {
+		t.Fatalf("expected the second commit to join job %d, got job %d", first.ID, second.ID)
+	}
+	if want := base + ".." + git("rev-parse", "HEAD"); second.GitRef != want {
+		t.Errorf("expected the group to review %s, got %s", want, second.GitRef)
+	}
+
+	// Another author starts a new group
+	other := enqueue(true, "Other <other@example.com>")
+	if other.ID == first.ID {
+		t.Error("expected a commit by another author to get its own job")
+	}
+
+	// Groups are held for more commits; explicit reviews are not
+	manual := enqueue(false)
+	claimed, err := db.ClaimJob(t.Context(), "worker-1")
+	if err != nil || claimed == nil || claimed.ID != manual.ID {
+		t.Errorf("expected only the manual review to be claimable, got %+v, %v", claimed, err)
+	}
+}
+
+func TestHandleEnqueueCherryPick(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	base := git("rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fix\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "fix.txt")
+	git("commit", "-m", "Fix crash")
+	fixSHA := git("rev-parse", "HEAD")
+	git("checkout", "-q", "-b", "release", base)
+	git("commit", "--allow-empty", "-m", "Release prep")
+	git("cherry-pick", fixSHA)
+	pickSHA := git("rev-parse", "HEAD")
+
+	enqueue := func(ref string) *storage.ReviewJob {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]string{
+			"repo_path": repoDir,
+			"git_ref":   ref,
+			"agent":     "test",
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusCreated)
+		var job storage.ReviewJob
+		testutil.DecodeJSON(t, w, &job)
+		return &job
+	}
+
+	orig := enqueue(fixSHA)
+	if orig.Status != storage.JobStatusQueued {
+		t.Fatalf("expected original commit queued, got %s", orig.Status)
+	}
+	pick := enqueue(pickSHA)
+	if pick.Status != storage.JobStatusSkipped {
+		t.Fatalf("expected cherry-pick skipped, got %s", pick.Status)
+	}
+	if want := fmt.Sprintf("reviewed in job %d", orig.ID); !strings.Contains(pick.Error, want) {
+		t.Errorf("expected skip reason linking job %d, got %q", orig.ID, pick.Error)
+	}
+
+	claimed, err := db.ClaimJob(t.Context(), "worker-1")
+	if err != nil || claimed == nil || claimed.ID != orig.ID {
+		t.Fatalf("ClaimJob = %+v, %v", claimed, err)
+	}
+	if err := db.CompleteJob(t.Context(), orig.ID, "test", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/review?sha="+pickSHA, nil)
+	w := httptest.NewRecorder()
+	server.handleGetReview(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	var review storage.Review
+	testutil.DecodeJSON(t, w, &review)
+	if review.JobID != orig.ID || review.CanonicalSHA != fixSHA {
+		t.Errorf("expected canonical review of %s from job %d, got job %d canonical %q",
+			fixSHA, orig.ID, review.JobID, review.CanonicalSHA)
+	}
+
+	// With reuse disabled the cherry-pick is reviewed on its own
+	if err := os.WriteFile(filepath.Join(repoDir, ".roborev.toml"), []byte("reuse_patch_reviews = false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if job := enqueue(pickSHA); job.Status != storage.JobStatusQueued {
+		t.Errorf("expected cherry-pick queued with reuse disabled, got %s", job.Status)
+	}
+}
+
 func TestHandleEnqueueBodySizeLimit(t *testing.T) {
 	server, _, tmpDir := newTestServer(t)
 
@@ -2025,6 +2633,61 @@ func TestHandleEnqueueBodySizeLimit(t *testing.T) {
 	})
 }
 
+func TestHandleEnqueuePatch(t *testing.T) {
+	server, _, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "testrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+
+	patch := "diff --git a/foo.go b/foo.go\n+func foo() {}\n"
+	reqData := map[string]string{
+		"repo_path":    repoDir,
+		"git_ref":      "patch:fix.patch@0123abcd",
+		"agent":        "test",
+		"diff_content": patch,
+	}
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", reqData)
+	w := httptest.NewRecorder()
+	server.handleEnqueue(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var respJob storage.ReviewJob
+	json.NewDecoder(w.Body).Decode(&respJob)
+	job, err := server.db.GetJobByID(req.Context(), respJob.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.GitRef != "patch:fix.patch@0123abcd" {
+		t.Errorf("expected synthetic patch ref to be kept, got %q", job.GitRef)
+	}
+	if job.JobType != storage.JobTypeDirty {
+		t.Errorf("expected job type %q, got %q", storage.JobTypeDirty, job.JobType)
+	}
+	var stored string
+	if err := server.db.QueryRow(`SELECT diff_content FROM review_jobs WHERE id = ?`, job.ID).Scan(&stored); err != nil {
+		t.Fatalf("read diff_content: %v", err)
+	}
+	if stored != patch {
+		t.Errorf("expected full patch content to be stored, got %q", stored)
+	}
+
+	t.Run("rejects patch without content", func(t *testing.T) {
+		reqData := map[string]string{
+			"repo_path": repoDir,
+			"git_ref":   "patch:empty.patch@00000000",
+			"agent":     "test",
+		}
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", reqData)
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
 func TestHandleListJobsByID(t *testing.T) {
 	server, _, tmpDir := newTestServer(t)
 
@@ -2380,7 +3043,7 @@ func TestGetMachineID_CachingBehavior(t *testing.T) {
 			t.Fatalf("Failed to reopen DB: %v", err)
 		}
 		t.Cleanup(func() { newDB.Close() })
-		server.db = newDB
+		server.store, server.db = newDB, newDB
 
 		// Second call should succeed and cache
 		id2 := server.getMachineID()
@@ -2410,11 +3073,11 @@ func TestHandleAddCommentToJobStates(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create repo and commit
-	repo, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "test-repo"))
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "test-repo"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Author", "Test commit", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Test commit", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
@@ -2433,7 +3096,7 @@ func TestHandleAddCommentToJobStates(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create a job
-			job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
+			job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
 			if err != nil {
 				t.Fatalf("EnqueueJob failed: %v", err)
 			}
@@ -2499,15 +3162,15 @@ func TestHandleAddCommentWithoutReview(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create repo, commit, and job (but NO review)
-	repo, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "test-repo"))
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "test-repo"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Author", "Test commit", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Test commit", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -2518,7 +3181,7 @@ func TestHandleAddCommentWithoutReview(t *testing.T) {
 	}
 
 	// Verify no review exists
-	if _, err := db.GetReviewByJobID(job.ID); err == nil {
+	if _, err := db.GetReviewByJobID(t.Context(), job.ID); err == nil {
 		t.Fatal("Expected no review to exist for job")
 	}
 
@@ -2538,7 +3201,7 @@ func TestHandleAddCommentWithoutReview(t *testing.T) {
 	}
 
 	// Verify comment was stored
-	comments, err := db.GetCommentsForJob(job.ID)
+	comments, err := db.GetCommentsForJob(req.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetCommentsForJob failed: %v", err)
 	}
@@ -2583,15 +3246,15 @@ func TestHandleJobOutput_PollingRunningJob(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create a running job
-	repo, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "test-repo"))
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "test-repo"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Author", "Test", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Test", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -2640,15 +3303,15 @@ func TestHandleJobOutput_PollingCompletedJob(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create a completed job
-	repo, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "test-repo"))
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "test-repo"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Author", "Test", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Test", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -2690,15 +3353,15 @@ func TestHandleJobOutput_StreamingCompletedJob(t *testing.T) {
 	server, db, tmpDir := newTestServer(t)
 
 	// Create a completed job
-	repo, err := db.GetOrCreateRepo(filepath.Join(tmpDir, "test-repo"))
+	repo, err := db.GetOrCreateRepo(t.Context(), filepath.Join(tmpDir, "test-repo"))
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Author", "Test", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Test", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test-agent"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -3164,3 +3827,157 @@ func TestHandleEnqueueRangeNonCommitObjectRejects(t *testing.T) {
 		t.Errorf("expected 'invalid start commit' error, got: %s", w.Body.String())
 	}
 }
+
+func TestHandleSyncExport(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+	repo, err := db.GetOrCreateRepo(t.Context(), tmpDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "test", "No issues found.")
+
+	t.Run("exports finished jobs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/sync/export", nil)
+		w := httptest.NewRecorder()
+		server.handleSyncExport(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+
+		var bundle storage.PeerBundle
+		testutil.DecodeJSON(t, w, &bundle)
+		if len(bundle.Jobs) != 1 || len(bundle.Reviews) != 1 {
+			t.Errorf("expected 1 job and 1 review, got %d and %d", len(bundle.Jobs), len(bundle.Reviews))
+		}
+	})
+
+	t.Run("since in the future returns nothing", func(t *testing.T) {
+		since := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+		req := httptest.NewRequest(http.MethodGet, "/api/sync/export?since="+since, nil)
+		w := httptest.NewRecorder()
+		server.handleSyncExport(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+
+		var bundle storage.PeerBundle
+		testutil.DecodeJSON(t, w, &bundle)
+		if len(bundle.Jobs) != 0 {
+			t.Errorf("expected no jobs, got %d", len(bundle.Jobs))
+		}
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/sync/export?since=yesterday", nil)
+		w := httptest.NewRecorder()
+		server.handleSyncExport(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	})
+}
+
+func TestHandleQueueDrain(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	drain := func(draining bool) storage.DaemonStatus {
+		t.Helper()
+		body := fmt.Sprintf(`{"draining":%t}`, draining)
+		req := httptest.NewRequest(http.MethodPost, "/api/queue/drain", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.handleQueueDrain(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var status storage.DaemonStatus
+		testutil.DecodeJSON(t, w, &status)
+		return status
+	}
+
+	if status := drain(true); !status.Draining {
+		t.Error("expected status to report draining")
+	}
+	if !server.workerPool.Draining() {
+		t.Error("expected worker pool to stop claiming")
+	}
+	if status := drain(false); status.Draining {
+		t.Error("expected draining to be cleared")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/queue/drain", nil)
+	w := httptest.NewRecorder()
+	server.handleQueueDrain(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusMethodNotAllowed)
+}
+
+func TestHandleQueueWake(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	queueChanged := db.QueueChanged()
+	req := httptest.NewRequest(http.MethodPost, "/api/queue/wake", nil)
+	w := httptest.NewRecorder()
+	server.handleQueueWake(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	select {
+	case <-queueChanged:
+	default:
+		t.Error("expected waiting workers to be woken")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/queue/wake", nil)
+	w = httptest.NewRecorder()
+	server.handleQueueWake(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusMethodNotAllowed)
+}
+
+func TestHandleEnqueueJJChangeID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake jj is a shell script")
+	}
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "jjrepo")
+	testutil.InitTestGitRepo(t, repoDir)
+	if err := os.Mkdir(filepath.Join(repoDir, ".jj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha := strings.TrimSpace(string(out))
+
+	// Fake jj: change "kxqp" is commit HEAD; templates with commit_id resolve
+	// a revision, others read a commit's change
+	restore := testutil.MockBinaryInPath(t, "jj", `#!/bin/sh
+while [ "$1" != "-r" ]; do shift; done
+rev="$2"; template="$4"
+case "$rev" in kxqp|`+sha+`) ;; *) echo "Error: Revision \"$rev\" doesn't exist" >&2; exit 1;; esac
+case "$template" in *commit_id*) echo "`+sha+` kxqpmnwzlrso";; *) echo "kxqpmnwzlrso";; esac
+`)
+	defer restore()
+
+	enqueue := func(ref string) *httptest.ResponseRecorder {
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/enqueue", map[string]string{
+			"repo_path": repoDir,
+			"git_ref":   ref,
+			"agent":     "test",
+		})
+		w := httptest.NewRecorder()
+		server.handleEnqueue(w, req)
+		return w
+	}
+
+	w := enqueue("kxqp")
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+	var job storage.ReviewJob
+	testutil.DecodeJSON(t, w, &job)
+	if job.GitRef != sha {
+		t.Errorf("expected change ID to resolve to %s, got %s", sha, job.GitRef)
+	}
+	if changeID, err := db.GetCommitChangeID(sha); err != nil || changeID != "kxqpmnwzlrso" {
+		t.Errorf("expected change ID recorded, got %q, %v", changeID, err)
+	}
+
+	// Ranges resolve each end; git refs are left to git
+	w = enqueue("kxqp^..HEAD")
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+
+	w = enqueue("nosuchchange")
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "doesn't exist") {
+		t.Errorf("expected jj's error, got %s", w.Body.String())
+	}
+}
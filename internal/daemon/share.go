@@ -0,0 +1,213 @@
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/i18n"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// sharePathPrefix is where share links are served. Requests under it skip
+// API token checks: the link token is the credential.
+const sharePathPrefix = "/r/"
+
+// CreateShareRequest is the body of POST /api/share
+type CreateShareRequest struct {
+	JobID     int64  `json:"job_id"`
+	ExpiresIn string `json:"expires_in,omitempty"` // Go duration such as "72h"; empty never expires
+}
+
+// ShareLinkResponse is a share link with the path it is served at
+type ShareLinkResponse struct {
+	storage.ShareLink
+	Path string `json:"path"`
+}
+
+func shareLinkResponse(link storage.ShareLink) ShareLinkResponse {
+	return ShareLinkResponse{ShareLink: link, Path: sharePathPrefix + link.Token}
+}
+
+// handleShare lists the share links of a review (GET ?job_id=) or creates
+// one (POST)
+func (s *Server) handleShare(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		jobID, err := strconv.ParseInt(r.URL.Query().Get("job_id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "job_id parameter required")
+			return
+		}
+		links, err := s.db.ListShareLinks(jobID)
+		if err != nil {
+			s.writeInternalError(w, fmt.Sprintf("list share links: %v", err))
+			return
+		}
+		resp := []ShareLinkResponse{}
+		for _, l := range links {
+			resp = append(resp, shareLinkResponse(l))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"links": resp})
+
+	case http.MethodPost:
+		var req CreateShareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JobID == 0 {
+			writeError(w, http.StatusBadRequest, "job_id is required")
+			return
+		}
+		var ttl time.Duration
+		if req.ExpiresIn != "" {
+			d, err := time.ParseDuration(req.ExpiresIn)
+			if err != nil || d <= 0 {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid expires_in %q", req.ExpiresIn))
+				return
+			}
+			ttl = d
+		}
+		if n, err := s.db.DeleteExpiredShareLinks(); err != nil {
+			log.Printf("Warning: failed to delete expired share links: %v", err)
+		} else if n > 0 {
+			log.Printf("Deleted %d expired share link(s)", n)
+		}
+		link, err := s.db.CreateShareLink(req.JobID, ttl)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, shareLinkResponse(*link))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleRevokeShare deletes the share link whose token is in the request body
+func (s *Server) handleRevokeShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		writeError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	if err := s.db.DeleteShareLink(req.Token); errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "share link not found")
+		return
+	} else if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("revoke share link: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"revoked": req.Token})
+}
+
+// sharedReviewPage renders a shared review for a browser
+var sharedReviewPage = template.Must(template.New("review").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.meta { color: #666; font-size: 0.9rem; }
+.verdict-pass { color: #2a7a2a; }
+.verdict-fail { color: #b3261e; }
+pre { white-space: pre-wrap; word-wrap: break-word; background: #f6f8fa; padding: 1rem; border-radius: 6px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Reviewed}}{{if .Verdict}} &middot; <span class="{{.VerdictClass}}">{{.Verdict}}</span>{{end}}{{if .Expires}} &middot; {{.Expires}}{{end}}</p>
+<pre>{{.Output}}</pre>
+</body>
+</html>
+`))
+
+// handleSharedReview serves the review behind a share link as a web page
+func (s *Server) handleSharedReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	// The page is in the reader's language when there is a catalog for it
+	lang := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	if lang == "" {
+		lang = i18n.Language()
+	}
+	p := i18n.For(lang)
+
+	token := strings.TrimPrefix(r.URL.Path, sharePathPrefix)
+	link, err := s.db.GetShareLink(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, p.T("This link has expired or does not exist."), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("get share link: %v", err))
+		return
+	}
+	review, err := s.db.GetReviewByJobID(r.Context(), link.JobID)
+	if err != nil {
+		http.Error(w, p.T("This review no longer exists."), http.StatusNotFound)
+		return
+	}
+
+	data := struct {
+		Lang, Title, Reviewed, Verdict, VerdictClass, Expires, Output string
+	}{
+		Lang:     lang,
+		Title:    p.T("Review"),
+		Reviewed: p.T("Reviewed by %s on %s", review.Agent, review.CreatedAt.Format("2006-01-02 15:04 MST")),
+		Output:   review.Output,
+	}
+	if job := review.Job; job != nil {
+		data.Title = p.T("Review of %s", shortRef(job.GitRef))
+		if job.RepoName != "" {
+			data.Title = p.T("Review of %s in %s", shortRef(job.GitRef), job.RepoName)
+		}
+		if job.Verdict != nil {
+			switch *job.Verdict {
+			case "P":
+				data.Verdict, data.VerdictClass = p.T("Passed"), "verdict-pass"
+			case "F":
+				data.Verdict, data.VerdictClass = p.T("Found issues"), "verdict-fail"
+			}
+		}
+	}
+	if link.ExpiresAt != nil {
+		data.Expires = p.T("link expires %s", link.ExpiresAt.Format("2006-01-02 15:04 MST"))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err := sharedReviewPage.Execute(w, data); err != nil {
+		log.Printf("Render shared review: %v", err)
+	}
+}
+
+// shortRef abbreviates the SHAs of a job's git ref for display
+func shortRef(ref string) string {
+	if storage.IsPatchRef(ref) {
+		return ref
+	}
+	parts := strings.Split(ref, "..")
+	for i, p := range parts {
+		if len(p) > 8 {
+			parts[i] = p[:8]
+		}
+	}
+	return strings.Join(parts, "..")
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestShareLinkServesReviewWithoutToken(t *testing.T) {
+	server, db, _ := newTestServer(t)
+
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/share-repo")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "abcdef0123456789", "A", "S", time.Now())
+	job, _ := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: commit.SHA, Agent: "codex"})
+	db.ClaimJob(t.Context(), "w")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "Found a <script> injection"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	// Remote API requests need a token from now on
+	if _, _, err := db.CreateAPIToken("viewer", storage.RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+
+	req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/share", CreateShareRequest{JobID: job.ID, ExpiresIn: "soon"})
+	w := httptest.NewRecorder()
+	server.handleShare(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/share", CreateShareRequest{JobID: job.ID, ExpiresIn: "72h"})
+	w = httptest.NewRecorder()
+	server.handleShare(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusCreated)
+	var link ShareLinkResponse
+	testutil.DecodeJSON(t, w, &link)
+	if link.Path != "/r/"+link.Token || link.ExpiresAt == nil {
+		t.Fatalf("unexpected link %+v", link)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.10:40000"
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w = get(link.Path)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	body := w.Body.String()
+	if !strings.Contains(body, "Review of abcdef01 in share-repo") {
+		t.Errorf("page missing title:\n%s", body)
+	}
+	if !strings.Contains(body, "Found a &lt;script&gt; injection") {
+		t.Errorf("review output not escaped:\n%s", body)
+	}
+	if !strings.Contains(body, `<html lang="en">`) {
+		t.Errorf("page should default to English:\n%s", body)
+	}
+
+	// The page follows the reader's browser language
+	req = httptest.NewRequest(http.MethodGet, link.Path, nil)
+	req.RemoteAddr = "192.0.2.10:40000"
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	if body := w.Body.String(); !strings.Contains(body, `<html lang="de">`) || !strings.Contains(body, "Review von abcdef01 in share-repo") {
+		t.Errorf("page not in German:\n%s", body)
+	}
+
+	testutil.AssertStatusCode(t, get("/api/review?job_id=1"), http.StatusUnauthorized)
+	testutil.AssertStatusCode(t, get("/r/unknown"), http.StatusNotFound)
+
+	req = testutil.MakeJSONRequest(t, http.MethodPost, "/api/share/revoke", map[string]string{"token": link.Token})
+	w = httptest.NewRecorder()
+	server.handleRevokeShare(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	testutil.AssertStatusCode(t, get(link.Path), http.StatusNotFound)
+}
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// slaCheckInterval is how often jobs are checked against their repo's SLA
+const slaCheckInterval = time.Minute
+
+// slaMonitor records reviews that miss their repo's review_sla_minutes and
+// broadcasts a review.overdue event for each, which hooks can notify on
+type slaMonitor struct {
+	db          *storage.DB
+	broadcaster Broadcaster
+
+	mu      sync.Mutex
+	started bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func newSLAMonitor(db *storage.DB, broadcaster Broadcaster) *slaMonitor {
+	return &slaMonitor{
+		db:          db,
+		broadcaster: broadcaster,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// Start checks jobs every slaCheckInterval
+func (m *slaMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+
+	go func() {
+		defer close(m.doneCh)
+		ticker := time.NewTicker(slaCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case now := <-ticker.C:
+				m.check(now)
+			}
+		}
+	}()
+}
+
+// Stop ends checking. Safe to call more than once or without Start.
+func (m *slaMonitor) Stop() {
+	m.mu.Lock()
+	started := m.started
+	select {
+	case <-m.stopCh:
+	default:
+		close(m.stopCh)
+	}
+	m.mu.Unlock()
+	if started {
+		<-m.doneCh
+	}
+}
+
+// check records the breaches of unfinished jobs past their SLA and of jobs
+// that finished late since the previous check
+func (m *slaMonitor) check(now time.Time) {
+	jobs, err := m.db.ListSLAJobs(now.Add(-2 * slaCheckInterval))
+	if err != nil {
+		log.Printf("SLA: %v", err)
+		return
+	}
+	slas := make(map[string]time.Duration)
+	for _, job := range jobs {
+		sla, ok := slas[job.RepoPath]
+		if !ok {
+			sla = config.ResolveReviewSLA(job.RepoPath)
+			slas[job.RepoPath] = sla
+		}
+		if sla == 0 {
+			continue
+		}
+		end := now
+		if job.FinishedAt != nil {
+			end = *job.FinishedAt
+		}
+		waited := end.Sub(job.EnqueuedAt)
+		if waited <= sla {
+			continue
+		}
+		recorded, err := m.db.RecordSLABreach(job.JobID, sla)
+		if err != nil {
+			log.Printf("SLA: job %d: %v", job.JobID, err)
+			continue
+		}
+		if !recorded {
+			continue
+		}
+		msg := fmt.Sprintf("%s for %s, past the %s SLA", job.Status, waited.Round(time.Minute), sla)
+		if job.FinishedAt != nil {
+			msg = fmt.Sprintf("finished after %s, past the %s SLA", waited.Round(time.Minute), sla)
+		}
+		log.Printf("SLA: job %d in %s %s", job.JobID, job.RepoName, msg)
+		m.broadcaster.Broadcast(Event{
+			Type:     "review.overdue",
+			TS:       now,
+			JobID:    job.JobID,
+			Repo:     job.RepoPath,
+			RepoName: job.RepoName,
+			SHA:      job.GitRef,
+			Agent:    job.Agent,
+			Error:    msg,
+		})
+	}
+}
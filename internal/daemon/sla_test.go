@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestSLAMonitorCheck(t *testing.T) {
+	db, tmpDir := testutil.OpenTestDBWithDir(t)
+	broadcaster := NewBroadcaster()
+	_, events := broadcaster.Subscribe("")
+
+	enqueue := func(repoDir, sha string) *storage.ReviewJob {
+		t.Helper()
+		repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Author", "Subject", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+
+	slaRepo := filepath.Join(tmpDir, "sla")
+	otherRepo := filepath.Join(tmpDir, "other")
+	for _, dir := range []string{slaRepo, otherRepo} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(slaRepo, ".roborev.toml"), []byte("review_sla_minutes = 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	late := enqueue(slaRepo, "late")
+	enqueue(slaRepo, "fresh")
+	enqueue(otherRepo, "nosla")
+
+	m := newSLAMonitor(db, broadcaster)
+	// Only the job enqueued 45 minutes before the check is overdue
+	if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE git_ref IN ('late', 'nosla')`,
+		time.Now().Add(-45*time.Minute).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+	m.check(time.Now())
+
+	select {
+	case ev := <-events:
+		if ev.Type != "review.overdue" || ev.JobID != late.ID || ev.SHA != "late" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a review.overdue event")
+	}
+
+	// A breach is notified once
+	m.check(time.Now())
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected second event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if n, err := db.CountOverdueJobs(); err != nil || n != 1 {
+		t.Errorf("CountOverdueJobs() = %d, %v; want 1", n, err)
+	}
+
+	// Stop without Start must not block
+	m.Stop()
+}
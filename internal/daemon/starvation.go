@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// starvationCheckInterval is how often the queue is checked for starvation
+const starvationCheckInterval = 30 * time.Second
+
+// staleWorkerHeartbeat is how long an idle worker can go without a
+// heartbeat before its loop is considered stuck. Idle workers beat at least
+// every idlePollInterval, and a claim blocked on a busy database gives up
+// after 30s.
+const staleWorkerHeartbeat = time.Minute
+
+// Starvation reports whether claimable jobs have waited longer than the
+// configured queue_starvation_minutes while workers sat idle, and why.
+// Returns nil when the queue is moving, when detection is disabled, or
+// without the local database.
+func (wp *WorkerPool) Starvation(now time.Time) (*storage.QueueStarvation, error) {
+	threshold := wp.cfgGetter.Config().QueueStarvationThreshold()
+	if threshold == 0 || wp.db == nil {
+		return nil, nil
+	}
+
+	var idle, stale int
+	for _, w := range wp.Workers() {
+		if w.JobID != 0 {
+			continue
+		}
+		idle++
+		if now.Sub(w.LastHeartbeat) > staleWorkerHeartbeat {
+			stale++
+		}
+	}
+	if idle == 0 {
+		return nil, nil // Every worker is busy; the queue is just long
+	}
+
+	waiting, jobID, since, err := wp.db.OldestClaimableJob(now)
+	if err != nil {
+		return nil, fmt.Errorf("find oldest claimable job: %w", err)
+	}
+	if waiting == 0 || now.Sub(since) < threshold {
+		return nil, nil
+	}
+
+	return &storage.QueueStarvation{
+		OldestJobID: jobID,
+		Since:       since,
+		Waiting:     waiting,
+		IdleWorkers: idle,
+		Cause:       wp.starvationCause(idle, stale),
+	}, nil
+}
+
+// starvationCause diagnoses why idle workers are not claiming jobs
+func (wp *WorkerPool) starvationCause(idle, stale int) string {
+	if wp.Draining() {
+		return "the queue is paused; run 'roborev queue resume'"
+	}
+	if msg := wp.ClaimError(); msg != "" {
+		lower := strings.ToLower(msg)
+		if strings.Contains(lower, "locked") || strings.Contains(lower, "busy") {
+			return "database lock contention, is another process holding the database? (" + msg + ")"
+		}
+		return "claiming jobs fails: " + msg
+	}
+	if stale == idle {
+		return fmt.Sprintf("idle workers stopped heartbeating over %s ago, the daemon may be wedged; run 'roborev daemon restart'", staleWorkerHeartbeat)
+	}
+	return "idle workers are not claiming jobs; check 'roborev status' and the daemon log"
+}
+
+// starvationMonitor warns once per episode when the worker pool reports
+// queue starvation, logging it and broadcasting a queue.starved event that
+// hooks can notify on
+type starvationMonitor struct {
+	workerPool  *WorkerPool
+	broadcaster Broadcaster
+
+	starved bool // Warned about the current episode
+
+	mu      sync.Mutex
+	started bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func newStarvationMonitor(workerPool *WorkerPool, broadcaster Broadcaster) *starvationMonitor {
+	return &starvationMonitor{
+		workerPool:  workerPool,
+		broadcaster: broadcaster,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// Start checks the queue every starvationCheckInterval
+func (m *starvationMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+
+	go func() {
+		defer close(m.doneCh)
+		ticker := time.NewTicker(starvationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case now := <-ticker.C:
+				m.check(now)
+			}
+		}
+	}()
+}
+
+// Stop ends checking. Safe to call more than once or without Start.
+func (m *starvationMonitor) Stop() {
+	m.mu.Lock()
+	started := m.started
+	select {
+	case <-m.stopCh:
+	default:
+		close(m.stopCh)
+	}
+	m.mu.Unlock()
+	if started {
+		<-m.doneCh
+	}
+}
+
+// check warns when the queue starts starving and notes when it recovers
+func (m *starvationMonitor) check(now time.Time) {
+	st, err := m.workerPool.Starvation(now)
+	if err != nil {
+		log.Printf("Queue starvation: %v", err)
+		return
+	}
+	if st == nil {
+		if m.starved {
+			log.Printf("Queue starvation: cleared")
+			m.starved = false
+		}
+		return
+	}
+	if m.starved {
+		return
+	}
+	m.starved = true
+
+	msg := fmt.Sprintf("%d job(s) unclaimed for %s with %d idle worker(s): %s",
+		st.Waiting, now.Sub(st.Since).Round(time.Minute), st.IdleWorkers, st.Cause)
+	log.Printf("Queue starvation: %s", msg)
+	m.broadcaster.Broadcast(Event{
+		Type:  "queue.starved",
+		TS:    now,
+		JobID: st.OldestJobID,
+		Error: msg,
+	})
+}
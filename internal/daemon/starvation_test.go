@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestWorkerPoolStarvation(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Subject", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.QueueStarvationMinutes = 10
+	wp := NewWorkerPool(db, NewStaticConfig(cfg), 2, NewBroadcaster(), nil)
+	wp.beat(0, 0)
+	wp.beat(1, 0)
+
+	now := time.Now()
+	if st, err := wp.Starvation(now); err != nil || st != nil {
+		t.Fatalf("fresh job: Starvation() = %+v, %v; want nil", st, err)
+	}
+
+	later := now.Add(15 * time.Minute)
+	wantCause := func(substr string) {
+		t.Helper()
+		st, err := wp.Starvation(later)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st == nil {
+			t.Fatal("expected starvation")
+		}
+		if st.OldestJobID != job.ID || st.Waiting != 1 || st.IdleWorkers != 2 {
+			t.Errorf("unexpected starvation %+v", st)
+		}
+		if !strings.Contains(st.Cause, substr) {
+			t.Errorf("cause %q does not mention %q", st.Cause, substr)
+		}
+	}
+
+	// Idle workers last beat well before the check
+	wantCause("daemon may be wedged")
+
+	wp.setClaimErr(errors.New("database is locked (5) (SQLITE_BUSY)"))
+	wantCause("lock contention")
+
+	wp.SetDraining(true)
+	wantCause("roborev queue resume")
+	wp.SetDraining(false)
+
+	// A busy pool is not starved, only behind
+	wp.beat(0, 99)
+	wp.beat(1, 98)
+	if st, err := wp.Starvation(later); err != nil || st != nil {
+		t.Errorf("busy workers: Starvation() = %+v, %v; want nil", st, err)
+	}
+
+	cfg.QueueStarvationMinutes = -1
+	wp.beat(0, 0)
+	if st, err := wp.Starvation(later); err != nil || st != nil {
+		t.Errorf("disabled: Starvation() = %+v, %v; want nil", st, err)
+	}
+}
+
+func TestStarvationMonitorCheck(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Author", "Subject", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broadcaster := NewBroadcaster()
+	_, events := broadcaster.Subscribe("")
+	wp := NewWorkerPool(db, NewStaticConfig(config.DefaultConfig()), 1, broadcaster, nil)
+	wp.SetDraining(true)
+	wp.beat(0, 0)
+	m := newStarvationMonitor(wp, broadcaster)
+
+	later := time.Now().Add(time.Hour)
+	m.check(later)
+	select {
+	case ev := <-events:
+		if ev.Type != "queue.starved" || ev.JobID != job.ID || !strings.Contains(ev.Error, "queue resume") {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a queue.starved event")
+	}
+
+	// An episode is notified once
+	m.check(later)
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected second event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once the job is claimed the episode ends, and the next one is notified
+	if _, err := db.Exec(`UPDATE review_jobs SET status = 'running' WHERE id = ?`, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	m.check(later)
+	if m.starved {
+		t.Fatal("expected the episode to end once the job was claimed")
+	}
+	if _, err := db.Exec(`UPDATE review_jobs SET status = 'queued' WHERE id = ?`, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	m.check(later)
+	select {
+	case ev := <-events:
+		if ev.Type != "queue.starved" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a queue.starved event for the new episode")
+	}
+
+	// Stop without Start must not block
+	m.Stop()
+}
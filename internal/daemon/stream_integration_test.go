@@ -120,7 +120,7 @@ func TestBroadcasterIntegrationWithWorker(t *testing.T) {
 	sha := testutil.GetHeadSHA(t, repoDir)
 
 	// Create repo and job
-	repo, err := db.GetOrCreateRepo(repoDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"log"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// recordSuppressions records the findings of a completed review that the
+// repo's .roborev-ignore file suppresses. Invalid entries are logged and
+// skipped; the rest of the file still applies.
+func (wp *WorkerPool) recordSuppressions(workerID string, job *storage.ReviewJob, output string) {
+	if job.IsTaskJob() {
+		return
+	}
+	ignore, err := config.LoadIgnoreFile(job.RepoPath)
+	if err != nil {
+		log.Printf("[%s] Job %d: %v", workerID, job.ID, err)
+	}
+	if ignore == nil || len(ignore.Rules) == 0 {
+		return
+	}
+
+	var suppressions []storage.FindingSuppression
+	for _, f := range storage.ExtractFindings(output) {
+		var files []string
+		for _, ref := range storage.ExtractFileRefs(f.Text, job.RepoPath) {
+			files = append(files, ref.Path)
+		}
+		rule := ignore.Match(storage.FindingFingerprint(f.Text), f.Severity, f.Text, files)
+		if rule == nil {
+			continue
+		}
+		suppressions = append(suppressions, storage.FindingSuppression{
+			FindingIndex:  f.Index,
+			Entry:         rule.String(),
+			Justification: rule.Justification,
+		})
+	}
+	if len(suppressions) == 0 {
+		return
+	}
+	if err := wp.db.SaveFindingSuppressions(job.ID, suppressions); err != nil {
+		log.Printf("[%s] Error saving finding suppressions for job %d: %v", workerID, job.ID, err)
+		return
+	}
+	log.Printf("[%s] Job %d: %d finding(s) suppressed by %s", workerID, job.ID, len(suppressions), config.IgnoreFileName)
+}
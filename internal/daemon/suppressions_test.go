@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestRecordSuppressions(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "suppressrepo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ignore := "# Vendored code is patched upstream\npath:vendor/\n\nrule:low\n"
+	if err := os.WriteFile(filepath.Join(repoDir, config.IgnoreFileName), []byte(ignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	output := "- High: race in main.go:10\n- Medium: unchecked error in vendor/lib/x.go:3\n- Low: typo"
+	created := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", output)
+	job, err := db.GetJobByID(t.Context(), created.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+
+	wp := NewWorkerPool(db, NewStaticConfig(config.DefaultConfig()), 1, NewBroadcaster(), nil)
+	wp.recordSuppressions("test", job, output)
+
+	list := func(query string) TriageListResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/triage"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleListTriage(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var resp TriageListResponse
+		testutil.DecodeJSON(t, w, &resp)
+		return resp
+	}
+
+	// The unjustified rule:low entry is skipped
+	if resp := list(""); resp.Total != 2 || resp.Items[0].Finding.Index != 0 || resp.Items[1].Finding.Index != 2 {
+		t.Errorf("expected the vendored finding to be hidden, got %+v", resp)
+	}
+	resp := list("?suppressed=1")
+	if resp.Total != 1 || resp.Items[0].Finding.Index != 1 || resp.Items[0].Suppression == nil ||
+		resp.Items[0].Suppression.Entry != "path:vendor/" ||
+		resp.Items[0].Suppression.Justification != "Vendored code is patched upstream" {
+		t.Errorf("unexpected suppressed findings %+v", resp)
+	}
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// TriageListResponse is returned by GET /api/triage
+type TriageListResponse struct {
+	Items []storage.TriageItem `json:"items"`
+	Total int                  `json:"total"` // All listed findings, not just those in Items
+}
+
+// TriageDecisionRequest records a decision for one finding
+type TriageDecisionRequest struct {
+	ReviewID     int64  `json:"review_id"`
+	FindingIndex int    `json:"finding_index"`
+	Decision     string `json:"decision"` // accepted, dismissed or assigned
+	Assignee     string `json:"assignee,omitempty"`
+}
+
+// handleListTriage lists untriaged findings, newest first. The optional repo
+// parameter is a repo root path; limit=0 returns only the total. With
+// suppressed=1 it lists the findings .roborev-ignore suppressed instead.
+func (s *Server) handleListTriage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	var repoID int64
+	if repoPath := q.Get("repo"); repoPath != "" {
+		repo, err := s.db.FindRepo(r.Context(), repoPath)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repo not found")
+			return
+		}
+		repoID = repo.ID
+	}
+
+	list := s.db.ListUntriagedFindings
+	if q.Get("suppressed") == "1" {
+		list = s.db.ListSuppressedFindings
+	}
+	items, total, err := list(repoID, limit)
+	if err != nil {
+		s.writeInternalError(w, fmt.Sprintf("list findings: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, TriageListResponse{Items: items, Total: total})
+}
+
+func (s *Server) handleTriageDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req TriageDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ReviewID == 0 {
+		writeError(w, http.StatusBadRequest, "review_id is required")
+		return
+	}
+
+	err := s.db.SetFindingTriage(req.ReviewID, req.FindingIndex, req.Decision, req.Assignee)
+	switch {
+	case errors.Is(err, storage.ErrInvalidTriage):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "review not found")
+		return
+	case err != nil:
+		s.writeInternalError(w, fmt.Sprintf("set triage: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestHandleTriage(t *testing.T) {
+	server, db, tmpDir := newTestServer(t)
+
+	repoDir := filepath.Join(tmpDir, "triagerepo")
+	repo, err := db.GetOrCreateRepo(t.Context(), repoDir)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	job := testutil.CreateCompletedReview(t, db, repo.ID, "abc123", "codex", "- High: first\n- Low: second")
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+
+	list := func(query string) TriageListResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/triage"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleListTriage(w, req)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var resp TriageListResponse
+		testutil.DecodeJSON(t, w, &resp)
+		return resp
+	}
+
+	resp := list("?repo=" + repoDir)
+	if resp.Total != 2 || len(resp.Items) != 2 || resp.Items[0].Finding.Severity != "high" {
+		t.Fatalf("expected 2 findings, got %+v", resp)
+	}
+	if resp := list("?limit=0"); resp.Total != 2 || len(resp.Items) != 0 {
+		t.Errorf("expected count only with limit=0, got %+v", resp)
+	}
+
+	decide := func(body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+		req := testutil.MakeJSONRequest(t, http.MethodPost, "/api/triage/decide", body)
+		w := httptest.NewRecorder()
+		server.handleTriageDecision(w, req)
+		return w
+	}
+
+	w := decide(map[string]any{"review_id": review.ID, "finding_index": 1, "decision": "assigned", "assignee": "bob"})
+	testutil.AssertStatusCode(t, w, http.StatusOK)
+	if resp := list(""); resp.Total != 1 || resp.Items[0].Finding.Index != 0 {
+		t.Errorf("expected only the first finding to remain, got %+v", resp)
+	}
+
+	w = decide(map[string]any{"review_id": review.ID, "finding_index": 0, "decision": "maybe"})
+	testutil.AssertStatusCode(t, w, http.StatusBadRequest)
+	w = decide(map[string]any{"review_id": 9999, "finding_index": 0, "decision": "accepted"})
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/triage?repo=/nonexistent", nil)
+	w = httptest.NewRecorder()
+	server.handleListTriage(w, req)
+	testutil.AssertStatusCode(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+const (
+	// APIVersion is the revision of the HTTP API this daemon speaks. Bump it
+	// when a request or response changes in a way older clients can't use.
+	APIVersion = 1
+	// MinAPIVersion is the oldest client API revision this daemon serves
+	MinAPIVersion = 1
+
+	// APIVersionHeader carries the API revision on responses, and on
+	// requests from clients that send it
+	APIVersionHeader = "X-Roborev-API-Version"
+)
+
+// withVersionCheck stamps responses with the daemon's API revision and
+// refuses requests it can't serve safely: clients older than MinAPIVersion,
+// and writes to a database migrated by a newer roborev. Reads keep working
+// so status and review output stay available while an upgrade is pending.
+func (s *Server) withVersionCheck(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(APIVersionHeader, strconv.Itoa(APIVersion))
+
+		if v := r.Header.Get(APIVersionHeader); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n < MinAPIVersion {
+				writeError(w, http.StatusUpgradeRequired, fmt.Sprintf(
+					"client API v%d is too old for this daemon (minimum v%d); run 'roborev update'", n, MinAPIVersion))
+				return
+			}
+		}
+
+		// Draining must keep working so 'daemon restart --upgrade' can run.
+		// Other backends check their schema when they are opened.
+		if s.db != nil && r.Method != http.MethodGet && r.Method != http.MethodHead && r.URL.Path != "/api/queue/drain" {
+			if err := s.db.CheckSchemaCompatible(); err != nil {
+				if errors.Is(err, storage.ErrSchemaTooNew) {
+					writeError(w, http.StatusConflict, fmt.Sprintf(
+						"%v; restart the daemon with 'roborev daemon restart --upgrade'", err))
+					return
+				}
+				s.writeInternalError(w, err.Error())
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
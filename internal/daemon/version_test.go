@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestVersionCheck(t *testing.T) {
+	server, db, _ := newTestServer(t)
+	handler := server.httpServer.Handler
+
+	serve := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("status reports versions", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/status", "", nil)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		if got := w.Header().Get(APIVersionHeader); got != strconv.Itoa(APIVersion) {
+			t.Errorf("expected %s header %d, got %q", APIVersionHeader, APIVersion, got)
+		}
+		var status storage.DaemonStatus
+		testutil.DecodeJSON(t, w, &status)
+		if status.APIVersion != APIVersion || status.MinAPIVersion != MinAPIVersion ||
+			status.SchemaVersion != storage.SchemaVersion || status.DBSchemaVersion != storage.SchemaVersion {
+			t.Errorf("unexpected versions in status %+v", status)
+		}
+	})
+
+	t.Run("old client refused", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/status", "", map[string]string{APIVersionHeader: strconv.Itoa(MinAPIVersion - 1)})
+		testutil.AssertStatusCode(t, w, http.StatusUpgradeRequired)
+	})
+
+	// A newer roborev migrated the database
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, storage.SchemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("writes refused on newer schema", func(t *testing.T) {
+		w := serve(http.MethodPost, "/api/comment", `{"job_id": 1, "comment": "hi"}`, nil)
+		testutil.AssertStatusCode(t, w, http.StatusConflict)
+		if !strings.Contains(w.Body.String(), "restart --upgrade") {
+			t.Errorf("expected upgrade hint, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("reads and drain allowed on newer schema", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/status", "", nil)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+		var status storage.DaemonStatus
+		testutil.DecodeJSON(t, w, &status)
+		if status.DBSchemaVersion != storage.SchemaVersion+1 {
+			t.Errorf("expected db schema version %d, got %d", storage.SchemaVersion+1, status.DBSchemaVersion)
+		}
+
+		w = serve(http.MethodPost, "/api/queue/drain", `{"draining": true}`, nil)
+		testutil.AssertStatusCode(t, w, http.StatusOK)
+	})
+}
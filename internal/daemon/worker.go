@@ -2,8 +2,11 @@ package daemon
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"log"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -11,13 +14,18 @@ import (
 
 	"github.com/roborev-dev/roborev/internal/agent"
 	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/prompt"
+	"github.com/roborev-dev/roborev/internal/signing"
 	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/telemetry"
+	"github.com/roborev-dev/roborev/internal/version"
 )
 
 // WorkerPool manages a pool of review workers
 type WorkerPool struct {
-	db            *storage.DB
+	store         storage.Storage
+	db            *storage.DB // store when it is the local database, nil otherwise
 	cfgGetter     ConfigGetter
 	promptBuilder *prompt.Builder
 	broadcaster   Broadcaster
@@ -25,7 +33,11 @@ type WorkerPool struct {
 
 	numWorkers    int
 	activeWorkers atomic.Int32
+	draining      atomic.Bool // Stop claiming new jobs (queue drain)
+	schemaWarned  atomic.Bool // Logged that the database schema is too new
 	stopCh        chan struct{}
+	stopCtx       context.Context // Canceled by Stop to interrupt queue queries
+	stopCancel    context.CancelFunc
 	wg            sync.WaitGroup
 
 	// Track running jobs for cancellation
@@ -33,26 +45,60 @@ type WorkerPool struct {
 	pendingCancels map[int64]bool // Jobs canceled before registered
 	runningJobsMu  sync.Mutex
 
+	// Last heartbeat of each worker, indexed by worker number
+	heartbeats   []storage.WorkerStatus
+	heartbeatsMu sync.Mutex
+
+	// Why the last attempt to claim a job failed, "" after a successful one
+	claimErr   string
+	claimErrMu sync.Mutex
+
 	// Output capture for tail command
 	outputBuffers *OutputBuffer
 
+	// Repo HEADs and candidate rewrites seen by the repo watcher
+	rewrites rewriteWatch
+
+	// Opt-in usage telemetry queue (only written when telemetry.enabled)
+	telemetry *telemetry.Queue
+
+	// Review signing key, loaded on first use when sign_reviews is enabled
+	signingKeyPath string
+	signingKey     ed25519.PrivateKey
+	signingMu      sync.Mutex
+
 	// Test hooks for deterministic synchronization (nil in production)
 	testHookAfterSecondCheck func() // Called after second runningJobs check, before second DB lookup
 }
 
-// NewWorkerPool creates a new worker pool
-func NewWorkerPool(db *storage.DB, cfgGetter ConfigGetter, numWorkers int, broadcaster Broadcaster, errorLog *ErrorLog) *WorkerPool {
+// NewWorkerPool creates a new worker pool running the jobs queued in store.
+// Fan-out, dead letters, blocking unrunnable jobs and the details recorded
+// alongside reviews (usage, symbols, injection risks and so on) need the
+// local database and are skipped on other backends.
+func NewWorkerPool(store storage.Storage, cfgGetter ConfigGetter, numWorkers int, broadcaster Broadcaster, errorLog *ErrorLog) *WorkerPool {
+	stopCtx, stopCancel := context.WithCancel(context.Background())
+	db, _ := store.(*storage.DB)
 	return &WorkerPool{
+		store:          store,
 		db:             db,
 		cfgGetter:      cfgGetter,
-		promptBuilder:  prompt.NewBuilder(db),
+		promptBuilder:  prompt.NewBuilder(store),
 		broadcaster:    broadcaster,
 		errorLog:       errorLog,
 		numWorkers:     numWorkers,
 		stopCh:         make(chan struct{}),
+		stopCtx:        stopCtx,
+		stopCancel:     stopCancel,
 		runningJobs:    make(map[int64]context.CancelFunc),
 		pendingCancels: make(map[int64]bool),
+		heartbeats:     make([]storage.WorkerStatus, numWorkers),
 		outputBuffers:  NewOutputBuffer(512*1024, 4*1024*1024), // 512KB/job, 4MB total
+		telemetry:      telemetry.NewQueue(telemetry.DefaultQueuePath()),
+		signingKeyPath: signing.DefaultKeyPath(),
+		rewrites: rewriteWatch{
+			heads:   make(map[int64]string),
+			pending: make(map[string]pendingRewrite),
+		},
 	}
 }
 
@@ -64,12 +110,21 @@ func (wp *WorkerPool) Start() {
 		wp.wg.Add(1)
 		go wp.worker(i)
 	}
+
+	wp.wg.Add(1)
+	go wp.telemetryUploader()
+
+	if wp.db != nil {
+		wp.wg.Add(1)
+		go wp.repoWatcher()
+	}
 }
 
 // Stop gracefully shuts down the worker pool
 func (wp *WorkerPool) Stop() {
 	log.Println("Stopping worker pool...")
 	close(wp.stopCh)
+	wp.stopCancel() // Interrupt claims waiting on a database lock
 	wp.wg.Wait()
 	log.Println("Worker pool stopped")
 }
@@ -84,6 +139,77 @@ func (wp *WorkerPool) MaxWorkers() int {
 	return wp.numWorkers
 }
 
+// Workers returns each worker's current job and last heartbeat. A busy
+// worker's heartbeat is the later of its claim and its agent's last output.
+func (wp *WorkerPool) Workers() []storage.WorkerStatus {
+	wp.heartbeatsMu.Lock()
+	var workers []storage.WorkerStatus
+	for _, w := range wp.heartbeats {
+		if w.ID != "" { // Skip workers that have not started yet
+			workers = append(workers, w)
+		}
+	}
+	wp.heartbeatsMu.Unlock()
+
+	for i := range workers {
+		if workers[i].JobID == 0 {
+			continue
+		}
+		if t := wp.outputBuffers.LastAppend(workers[i].JobID); t.After(workers[i].LastHeartbeat) {
+			workers[i].LastHeartbeat = t
+		}
+	}
+	return workers
+}
+
+// beat records that a worker is alive and which job, if any, it is running
+func (wp *WorkerPool) beat(id int, jobID int64) {
+	wp.heartbeatsMu.Lock()
+	defer wp.heartbeatsMu.Unlock()
+	wp.heartbeats[id] = storage.WorkerStatus{
+		ID:            fmt.Sprintf("worker-%d", id),
+		JobID:         jobID,
+		LastHeartbeat: time.Now(),
+	}
+}
+
+// setClaimErr records the outcome of a worker's attempt to claim a job
+func (wp *WorkerPool) setClaimErr(err error) {
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	wp.claimErrMu.Lock()
+	wp.claimErr = msg
+	wp.claimErrMu.Unlock()
+}
+
+// ClaimError returns why the last attempt to claim a job failed, or "" if
+// it succeeded
+func (wp *WorkerPool) ClaimError() string {
+	wp.claimErrMu.Lock()
+	defer wp.claimErrMu.Unlock()
+	return wp.claimErr
+}
+
+// SetDraining stops (or resumes) claiming queued jobs. Jobs that are already
+// running are not affected.
+func (wp *WorkerPool) SetDraining(draining bool) {
+	if wp.draining.Swap(draining) != draining {
+		if draining {
+			log.Println("Queue draining: no new jobs will be claimed")
+		} else {
+			log.Println("Queue resumed")
+			wp.Wake()
+		}
+	}
+}
+
+// Draining reports whether the pool has stopped claiming new jobs.
+func (wp *WorkerPool) Draining() bool {
+	return wp.draining.Load()
+}
+
 // GetJobOutput returns the current output lines for a job.
 func (wp *WorkerPool) GetJobOutput(jobID int64) []OutputLine {
 	return wp.outputBuffers.GetLines(jobID)
@@ -117,7 +243,7 @@ func (wp *WorkerPool) CancelJob(jobID int64) bool {
 	// Job not registered yet - check if it's a valid job before marking pending
 	// This prevents unbounded growth of pendingCancels for invalid/finished job IDs
 	// Note: we release the lock before the DB call to avoid blocking other operations
-	job, err := wp.db.GetJobByID(jobID)
+	job, err := wp.store.GetJobByID(wp.stopCtx, jobID)
 	if err != nil {
 		// DB error - but job may have registered while we were trying to read
 		// Re-check runningJobs before giving up
@@ -157,7 +283,7 @@ func (wp *WorkerPool) CancelJob(jobID int64) bool {
 	// Re-verify job is still cancellable before adding to pendingCancels
 	// The job may have registered and finished during our DB lookup window
 	// Do this outside the lock to avoid blocking other operations
-	job, err = wp.db.GetJobByID(jobID)
+	job, err = wp.store.GetJobByID(wp.stopCtx, jobID)
 	if err != nil || !wp.isJobCancellable(job) {
 		// Job finished or became non-cancellable - don't add stale entry
 		return false
@@ -235,9 +361,38 @@ func (wp *WorkerPool) worker(id int) {
 			return
 		default:
 		}
+		wp.beat(id, 0)
+
+		// Taken before claiming, so a job queued meanwhile wakes us
+		queueChanged := wp.queueChanged()
+
+		if wp.draining.Load() {
+			wp.waitForJobs(queueChanged, idlePollInterval)
+			continue
+		}
+
+		// A newer roborev migrated the database; leave the queue to it
+		if err := wp.checkSchemaCompatible(); err != nil {
+			if !wp.schemaWarned.Swap(true) {
+				log.Printf("[%s] Not claiming jobs: %v", workerID, err)
+			}
+			wp.setClaimErr(err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
 
-		// Try to claim a job
-		job, err := wp.db.ClaimJob(workerID)
+		// Try to claim a job. The lowest-numbered workers are reserved for
+		// interactive jobs, so those never wait behind a backfill.
+		lane := ""
+		if id < wp.cfgGetter.Config().InteractiveWorkers(wp.numWorkers) {
+			lane = storage.LaneInteractive
+		}
+		job, err := wp.store.ClaimJobInLane(wp.stopCtx, workerID, lane)
+		if err != nil && wp.stopCtx.Err() != nil {
+			log.Printf("[%s] Shutting down", workerID)
+			return
+		}
+		wp.setClaimErr(err)
 		if err != nil {
 			log.Printf("[%s] Error claiming job: %v", workerID, err)
 			if wp.errorLog != nil {
@@ -248,33 +403,106 @@ func (wp *WorkerPool) worker(id int) {
 		}
 
 		if job == nil {
-			// No jobs available, wait and retry
-			time.Sleep(2 * time.Second)
+			// No jobs available, wait for one to be queued
+			wp.waitForJobs(queueChanged, wp.idleWait())
 			continue
 		}
 
 		// Process the job
+		wp.beat(id, job.ID)
 		wp.activeWorkers.Add(1)
 		wp.processJob(workerID, job)
 		wp.activeWorkers.Add(-1)
+
+		// Finishing a job can release the jobs that depend on it
+		wp.Wake()
 	}
 }
 
+// idlePollInterval is the longest an idle worker waits before trying to
+// claim a job again. Workers wake as soon as jobs are queued through the
+// daemon, so this only catches changes made behind its back, and keeps
+// idle heartbeats fresh.
+const idlePollInterval = 15 * time.Second
+
+// idleWait returns how long an idle worker waits for jobs: until the
+// earliest hold on a queued job runs out, at most idlePollInterval
+func (wp *WorkerPool) idleWait() time.Duration {
+	if wp.db == nil {
+		return idlePollInterval
+	}
+	holdExpiry, err := wp.db.NextHoldExpiry(wp.stopCtx)
+	if err != nil || holdExpiry.IsZero() {
+		return idlePollInterval
+	}
+	return min(max(time.Until(holdExpiry), 0)+time.Second, idlePollInterval)
+}
+
+// waitForJobs blocks until queueChanged fires, d passes or the pool stops
+func (wp *WorkerPool) waitForJobs(queueChanged <-chan struct{}, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-queueChanged:
+	case <-timer.C:
+	case <-wp.stopCh:
+	}
+}
+
+// Wake has idle workers try to claim a job now, as after jobs were queued
+// by another process sharing the database
+func (wp *WorkerPool) Wake() {
+	if n, ok := wp.store.(storage.QueueNotifier); ok {
+		n.NotifyQueueChanged()
+	}
+}
+
+// queueChanged returns a channel closed once jobs may have become
+// claimable, or nil if the store cannot tell and idle workers poll
+func (wp *WorkerPool) queueChanged() <-chan struct{} {
+	if n, ok := wp.store.(storage.QueueNotifier); ok {
+		return n.QueueChanged()
+	}
+	return nil
+}
+
+// checkSchemaCompatible reports whether a newer roborev migrated the local
+// database. Other backends check their schema when they are opened.
+func (wp *WorkerPool) checkSchemaCompatible() error {
+	if wp.db == nil {
+		return nil
+	}
+	return wp.db.CheckSchemaCompatible()
+}
+
 // maxRetries is the number of retry attempts allowed after initial failure.
 // With maxRetries=3, a job can run up to 4 times total (1 initial + 3 retries).
 const maxRetries = 3
 
+// rateLimitRetryDelay is how long a rate limited job waits before its first
+// retry, doubling for each retry after
+const rateLimitRetryDelay = 30 * time.Second
+
 func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 	log.Printf("[%s] Processing job %d for ref %s in %s", workerID, job.ID, job.GitRef, job.RepoName)
+	start := time.Now()
 
 	// Snapshot config once to ensure consistent settings throughout the job.
 	// This prevents mixed settings if config reloads mid-job.
 	cfg := wp.cfgGetter.Config()
 
+	// Jobs that cannot run here, such as those of deleted repos or with no
+	// agent installed, wait for the cause to be fixed instead of failing
+	if wp.blockUnrunnableJob(workerID, job, cfg) {
+		return
+	}
+
 	// Get timeout from config (per-repo or global, default 30 minutes)
 	timeoutMinutes := config.ResolveJobTimeout(job.RepoPath, cfg)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMinutes)*time.Minute)
 	defer cancel()
+	// The outcome of the job is recorded even once it timed out or was canceled
+	recordCtx := context.WithoutCancel(ctx)
 
 	// Register for cancellation tracking
 	wp.registerRunningJob(job.ID, cancel)
@@ -283,6 +511,40 @@ func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 	// Build the prompt (or use pre-stored prompt for task jobs)
 	var reviewPrompt string
 	var err error
+	suggestMessage := false // Review also proposes a commit message
+	quickTake := false      // Review opens with a short summary of itself
+	var checklist []string  // Checklist items the review must answer
+	builder := reviewPromptBuilder(wp.promptBuilder, job, cfg)
+	if !job.IsTaskJob() && wp.db != nil {
+		// Parts of a fanned-out review check their files against the design
+		// docs linked to the whole review
+		docsJobID := job.ID
+		if job.ParentJobID != 0 {
+			docsJobID = job.ParentJobID
+		}
+		if docs, err := wp.db.GetJobContextDocs(docsJobID); err != nil {
+			log.Printf("[%s] Error loading design docs of job %d: %v", workerID, job.ID, err)
+		} else if len(docs) > 0 {
+			builder = builder.WithContextDocs(docs)
+		}
+	}
+
+	// Reviews of large commits and ranges fan out into parts reviewed in
+	// parallel. The job is claimed again as the join once they finish.
+	// Thorough reviews see the whole change in one prompt instead.
+	var parts []storage.JobPart
+	if !job.IsTaskJob() && job.DiffContent == nil && job.ParentJobID == 0 && wp.db != nil {
+		parts, err = wp.db.GetJobParts(job.ID)
+		if err != nil {
+			log.Printf("[%s] Error loading parts of job %d: %v", workerID, job.ID, err)
+			wp.failOrRetry(recordCtx, workerID, job, job.Agent, fmt.Sprintf("load parts: %v", err))
+			return
+		}
+		if len(parts) == 0 && !job.Focus && wp.fanOut(ctx, workerID, job, cfg) {
+			return
+		}
+	}
+
 	if job.IsTaskJob() && job.Prompt != "" {
 		// Task job (run, analyze, custom) - prepend agent-specific preamble if available
 		preamble := prompt.GetSystemPrompt(job.Agent, "run")
@@ -296,29 +558,61 @@ func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 		// the prompt wasn't stored or loaded. Fail with a clear error instead of
 		// trying to git log on an analysis type name like "complexity".
 		err = fmt.Errorf("task job %d has no stored prompt (git_ref=%q); restart the daemon with 'roborev daemon restart'", job.ID, job.GitRef)
-	} else if job.DiffContent != nil {
-		// Dirty job - use pre-captured diff
-		reviewPrompt, err = wp.promptBuilder.BuildDirty(job.RepoPath, *job.DiffContent, job.RepoID, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+	} else if job.ParentJobID != 0 {
+		// Part of a fanned-out review - review only its files
+		var part *storage.JobPart
+		if part, err = wp.db.GetJobPart(job.ID); err == nil {
+			reviewPrompt, err = builder.BuildPart(job.RepoPath, job.GitRef, part.Paths, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+		}
+	} else if len(parts) > 0 {
+		// Join of a fanned-out review - merge the reviews of its parts
+		if errorMsg := failedPartsError(parts); errorMsg != "" {
+			log.Printf("[%s] Job %d: %s", workerID, job.ID, errorMsg)
+			wp.failOrRetryWithCode(recordCtx, workerID, job, job.Agent, storage.ErrorCodePartFailed, errorMsg)
+			return
+		}
+		reviewPrompt = prompt.BuildJoin(job.GitRef, parts)
 	} else {
-		// Normal job - build prompt from git ref
-		reviewPrompt, err = wp.promptBuilder.Build(job.RepoPath, job.GitRef, job.RepoID, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+		reviewPrompt, suggestMessage, err = buildReviewPrompt(builder, job, cfg)
 	}
 	if err != nil {
 		log.Printf("[%s] Error building prompt: %v", workerID, err)
-		wp.failOrRetry(workerID, job, job.Agent, fmt.Sprintf("build prompt: %v", err))
+		wp.failOrRetry(recordCtx, workerID, job, job.Agent, fmt.Sprintf("build prompt: %v", err))
 		return
 	}
+	if !job.IsTaskJob() && len(parts) == 0 {
+		reviewPrompt += wp.prePromptContext(ctx, workerID, job, cfg)
+	}
+	if !job.IsTaskJob() && job.ParentJobID == 0 {
+		if checklist = config.ResolveChecklist(job.RepoPath, cfg); len(checklist) > 0 {
+			reviewPrompt += prompt.ChecklistInstructions(checklist)
+		}
+		if quickTake = config.ResolveQuickTake(job.RepoPath, cfg); quickTake {
+			reviewPrompt += prompt.QuickTakeInstructions
+		}
+	}
+
+	// Flag diffs that look like they try to steer the reviewer
+	var injection []string
+	if !job.IsTaskJob() {
+		injection = wp.injectionPatterns(reviewPrompt, parts)
+	}
 
 	// Save the prompt so it can be viewed while job is running
-	if err := wp.db.SaveJobPrompt(job.ID, reviewPrompt); err != nil {
+	if err := wp.store.SaveJobPrompt(ctx, job.ID, reviewPrompt); err != nil {
 		log.Printf("[%s] Error saving prompt: %v", workerID, err)
 	}
 
+	// Record the changed symbols of commit and range reviews for searching
+	if !job.IsTaskJob() && job.DiffContent == nil && job.ParentJobID == 0 {
+		wp.recordChangedSymbols(job)
+	}
+
 	// Get the agent (falls back to available agent if preferred not installed)
 	baseAgent, err := agent.GetAvailable(job.Agent)
 	if err != nil {
 		log.Printf("[%s] Error getting agent: %v", workerID, err)
-		wp.failOrRetry(workerID, job, job.Agent, fmt.Sprintf("get agent: %v", err))
+		wp.failOrRetry(recordCtx, workerID, job, job.Agent, fmt.Sprintf("get agent: %v", err))
 		return
 	}
 
@@ -337,8 +631,10 @@ func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 		log.Printf("[%s] Agent %s not available, using %s", workerID, job.Agent, agentName)
 	}
 
+	wp.captureJobEnv(job, a)
+
 	// Broadcast started event
-	wp.broadcaster.Broadcast(Event{
+	wp.broadcast(job, Event{
 		Type:     "review.started",
 		TS:       time.Now(),
 		JobID:    job.ID,
@@ -356,15 +652,17 @@ func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 		wp.outputBuffers.CloseJob(job.ID)
 	}()
 
-	// Run the review
+	// Run the review. Agents that call model APIs directly report usage.
 	log.Printf("[%s] Running %s review...", workerID, agentName)
-	output, err := a.Review(ctx, job.RepoPath, job.GitRef, reviewPrompt, outputWriter)
+	var usage *agent.Usage
+	reviewCtx := agent.WithUsageRecorder(ctx, func(u agent.Usage) { usage = &u })
+	output, err := a.Review(reviewCtx, job.RepoPath, job.GitRef, reviewPrompt, outputWriter)
 	if err != nil {
 		// Check if this was a cancellation
 		if ctx.Err() == context.Canceled {
 			log.Printf("[%s] Job %d was canceled", workerID, job.ID)
 			// Broadcast cancellation event
-			wp.broadcaster.Broadcast(Event{
+			wp.broadcast(job, Event{
 				Type:     "review.canceled",
 				TS:       time.Now(),
 				JobID:    job.ID,
@@ -376,62 +674,336 @@ func (wp *WorkerPool) processJob(workerID string, job *storage.ReviewJob) {
 			return // Job already marked as canceled in DB, nothing more to do
 		}
 		log.Printf("[%s] Agent error: %v", workerID, err)
-		wp.failOrRetry(workerID, job, agentName, fmt.Sprintf("agent: %v", err))
+		if agent.IsContextLengthError(err) && wp.retryTruncated(recordCtx, workerID, job, len(parts) > 0) {
+			return
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			wp.failOrRetry(recordCtx, workerID, job, agentName, fmt.Sprintf("agent: timed out after %d minutes: %v", timeoutMinutes, err))
+			return
+		}
+		wp.failOrRetry(recordCtx, workerID, job, agentName, fmt.Sprintf("agent: %v", err))
 		return
 	}
 
-	// Store the result (use actual agent name, not requested)
-	if err := wp.db.CompleteJob(job.ID, agentName, reviewPrompt, output); err != nil {
+	var summary string
+	if quickTake {
+		output, summary = prompt.ExtractQuickTake(output)
+	}
+	var commitMessage string
+	if suggestMessage {
+		output, commitMessage = prompt.ExtractCommitMessage(output)
+	}
+	var checklistResults []storage.ChecklistResult
+	if len(checklist) > 0 {
+		checklistResults = prompt.ParseChecklist(output, checklist)
+	}
+	if !job.IsTaskJob() {
+		output = wp.validateFindings(job, agentName, output)
+	}
+
+	// Store the result (use actual agent name, not requested). The review
+	// is kept even if the job timed out after the agent returned.
+	if err := wp.store.CompleteJob(context.WithoutCancel(ctx), job.ID, agentName, reviewPrompt, output); err != nil {
 		log.Printf("[%s] Error storing review: %v", workerID, err)
 		return
 	}
 
 	log.Printf("[%s] Completed job %d", workerID, job.ID)
+	if wp.db != nil {
+		wp.saveReviewDetails(workerID, job, usage, injection, output, summary, commitMessage, checklistResults)
+	}
+	if cfg.SignReviews {
+		if err := wp.signReview(recordCtx, job.ID); err != nil {
+			log.Printf("[%s] Error signing review for job %d: %v", workerID, job.ID, err)
+		}
+	}
+	wp.recordTelemetry(telemetry.Event{
+		Kind:       telemetry.KindJobCompleted,
+		Agent:      agentName,
+		JobType:    job.JobType,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
 
 	// Broadcast completion event
 	verdict := storage.ParseVerdict(output)
-	wp.broadcaster.Broadcast(Event{
-		Type:     "review.completed",
-		TS:       time.Now(),
-		JobID:    job.ID,
-		Repo:     job.RepoPath,
-		RepoName: job.RepoName,
-		SHA:      job.GitRef,
-		Agent:    agentName,
-		Verdict:  verdict,
-		Findings: output,
+	wp.broadcast(job, Event{
+		Type:      "review.completed",
+		TS:        time.Now(),
+		JobID:     job.ID,
+		Repo:      job.RepoPath,
+		RepoName:  job.RepoName,
+		SHA:       job.GitRef,
+		Agent:     agentName,
+		Verdict:   verdict,
+		Findings:  output,
+		QuickTake: summary,
 	})
+
+	// Parts are passed on through the review of their join
+	if job.ParentJobID == 0 {
+		wp.startPostReviewHooks(job, cfg, agentName, output, verdict, summary)
+	}
 }
 
-// failOrRetry attempts to retry the job, or marks it as failed if max retries reached
-func (wp *WorkerPool) failOrRetry(workerID string, job *storage.ReviewJob, agentName string, errorMsg string) {
-	retried, err := wp.db.RetryJob(job.ID, maxRetries)
+// saveReviewDetails records what a completed review found beyond its
+// output: token usage, injection risks, suppressed findings, the quick take,
+// the suggested commit message and checklist answers. Failures are logged.
+func (wp *WorkerPool) saveReviewDetails(workerID string, job *storage.ReviewJob, usage *agent.Usage, injection []string, output, summary, commitMessage string, checklistResults []storage.ChecklistResult) {
+	if usage != nil {
+		if err := wp.db.SaveJobUsage(storage.JobUsage{
+			JobID:            job.ID,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+		}); err != nil {
+			log.Printf("[%s] Error saving token usage for job %d: %v", workerID, job.ID, err)
+		}
+	}
+	wp.recordInjectionRisk(workerID, job, injection, output)
+	wp.recordSuppressions(workerID, job, output)
+	if summary != "" {
+		if err := wp.db.SetReviewQuickTake(job.ID, summary); err != nil {
+			log.Printf("[%s] Error saving quick take for job %d: %v", workerID, job.ID, err)
+		}
+	}
+	if commitMessage != "" {
+		if err := wp.db.SaveCommitMessageSuggestion(job.ID, commitMessage); err != nil {
+			log.Printf("[%s] Error saving commit message suggestion for job %d: %v", workerID, job.ID, err)
+		}
+	}
+	if len(checklistResults) > 0 {
+		if err := wp.db.SaveChecklistResults(job.ID, checklistResults); err != nil {
+			log.Printf("[%s] Error saving checklist results for job %d: %v", workerID, job.ID, err)
+		}
+	}
+}
+
+// failOrRetry attempts to retry the job, or marks it as failed if max retries
+// reached. The error code of the failure sets the policy: failures that
+// recur until someone steps in are not retried, and rate limited jobs are
+// held back for longer before each retry. Every attempt's failure is
+// recorded, and a job failed for good becomes a dead letter. Without the
+// local database only the retry limit applies.
+func (wp *WorkerPool) failOrRetry(ctx context.Context, workerID string, job *storage.ReviewJob, agentName string, errorMsg string) {
+	wp.failOrRetryWithCode(ctx, workerID, job, agentName, storage.ClassifyError(errorMsg), errorMsg)
+}
+
+// failOrRetryWithCode is failOrRetry for failures whose cause is known
+// rather than read from errorMsg
+func (wp *WorkerPool) failOrRetryWithCode(ctx context.Context, workerID string, job *storage.ReviewJob, agentName string, code storage.ErrorCode, errorMsg string) {
+	if wp.db != nil {
+		if err := wp.db.RecordJobFailure(ctx, job.ID, code, errorMsg); err != nil {
+			log.Printf("[%s] Error recording failure of job %d: %v", workerID, job.ID, err)
+		}
+	}
+	if !code.Retryable() {
+		log.Printf("[%s] Job %d failed (%s), not retrying", workerID, job.ID, code)
+		wp.deadLetter(ctx, workerID, job, agentName, code, errorMsg, fmt.Sprintf("job %d failed (%s): %s", job.ID, code, errorMsg))
+		return
+	}
+
+	var retried bool
+	var err error
+	if code == storage.ErrorCodeAgentRateLimit && wp.db != nil {
+		retryCount, _ := wp.store.GetJobRetryCount(ctx, job.ID)
+		retried, err = wp.db.RetryJobAfter(ctx, job.ID, maxRetries, rateLimitRetryDelay<<retryCount)
+	} else {
+		retried, err = wp.store.RetryJob(ctx, job.ID, maxRetries)
+	}
 	if err != nil {
 		log.Printf("[%s] Error retrying job: %v", workerID, err)
-		wp.db.FailJob(job.ID, errorMsg)
-		wp.broadcastFailed(job, agentName, errorMsg)
-		if wp.errorLog != nil {
-			wp.errorLog.LogError("worker", fmt.Sprintf("job %d failed: %s", job.ID, errorMsg), job.ID)
-		}
+		wp.failJob(ctx, job, agentName, code, errorMsg, fmt.Sprintf("job %d failed: %s", job.ID, errorMsg))
 		return
 	}
 
 	if retried {
-		retryCount, _ := wp.db.GetJobRetryCount(job.ID)
+		retryCount, _ := wp.store.GetJobRetryCount(ctx, job.ID)
 		log.Printf("[%s] Job %d queued for retry (%d/%d)", workerID, job.ID, retryCount, maxRetries)
 	} else {
 		log.Printf("[%s] Job %d failed after %d retries", workerID, job.ID, maxRetries)
-		wp.db.FailJob(job.ID, errorMsg)
-		wp.broadcastFailed(job, agentName, errorMsg)
-		if wp.errorLog != nil {
-			wp.errorLog.LogError("worker", fmt.Sprintf("job %d failed after %d retries: %s", job.ID, maxRetries, errorMsg), job.ID)
+		wp.deadLetter(ctx, workerID, job, agentName, code, errorMsg, fmt.Sprintf("job %d failed after %d retries: %s", job.ID, maxRetries, errorMsg))
+	}
+}
+
+// deadLetter fails a job for good and moves it to the dead letters, to be
+// inspected and requeued from there. Parts of a fanned-out review are left
+// out: their join fails with them and is requeued in their place.
+func (wp *WorkerPool) deadLetter(ctx context.Context, workerID string, job *storage.ReviewJob, agentName string, code storage.ErrorCode, errorMsg, logMsg string) {
+	wp.failJob(ctx, job, agentName, code, errorMsg, logMsg)
+	if job.ParentJobID != 0 || wp.db == nil {
+		return
+	}
+	if err := wp.db.DeadLetterJob(ctx, job.ID); err != nil {
+		log.Printf("[%s] Error moving job %d to the dead letters: %v", workerID, job.ID, err)
+	}
+}
+
+// failJob marks a job as failed with its error code, reports the failure
+// and logs logMsg to the error log
+func (wp *WorkerPool) failJob(ctx context.Context, job *storage.ReviewJob, agentName string, code storage.ErrorCode, errorMsg, logMsg string) {
+	if wp.db != nil {
+		wp.db.FailJobWithCode(ctx, job.ID, code, errorMsg)
+	} else {
+		wp.store.FailJob(ctx, job.ID, errorMsg)
+	}
+	wp.broadcastFailed(job, agentName, errorMsg)
+	wp.recordFailureTelemetry(job, agentName, code)
+	if wp.errorLog != nil {
+		wp.errorLog.LogError("worker", logMsg, job.ID)
+	}
+}
+
+// retryTruncated requeues a review whose prompt was too long for its agent,
+// to be rebuilt at the next truncation level. Returns false, leaving the
+// failure to failOrRetry, for task jobs and joins, whose prompts cannot be
+// rebuilt smaller, and for reviews already at MaxTruncationLevel.
+func (wp *WorkerPool) retryTruncated(ctx context.Context, workerID string, job *storage.ReviewJob, isJoin bool) bool {
+	if job.IsTaskJob() || isJoin || job.TruncationLevel >= prompt.MaxTruncationLevel || wp.db == nil {
+		return false
+	}
+	level := job.TruncationLevel + 1
+	requeued, err := wp.db.RetryJobTruncated(ctx, job.ID, level)
+	if err != nil {
+		log.Printf("[%s] Error requeueing job %d with a truncated prompt: %v", workerID, job.ID, err)
+		return false
+	}
+	if requeued {
+		log.Printf("[%s] Job %d prompt too long, queued for retry at truncation level %d/%d",
+			workerID, job.ID, level, prompt.MaxTruncationLevel)
+	}
+	return requeued
+}
+
+// broadcast sends an event for a job. Parts of a fanned-out review are
+// reported through their join job only.
+func (wp *WorkerPool) broadcast(job *storage.ReviewJob, event Event) {
+	if job.ParentJobID != 0 {
+		return
+	}
+	event.Routes, event.Owners = wp.jobRoutes(job)
+	wp.broadcaster.Broadcast(event)
+}
+
+// jobRoutes returns the monorepo routes recorded for a job and the owners
+// the repo's config gives them
+func (wp *WorkerPool) jobRoutes(job *storage.ReviewJob) (routes, owners []string) {
+	if wp.db == nil {
+		return nil, nil
+	}
+	routes, err := wp.db.GetJobRoutes(job.ID)
+	if err != nil || len(routes) == 0 {
+		return nil, nil
+	}
+	repoCfg, err := config.LoadRepoConfig(job.RepoPath)
+	if err != nil || repoCfg == nil {
+		return routes, nil
+	}
+	seen := make(map[string]bool)
+	for _, route := range repoCfg.Routes {
+		if !slices.Contains(routes, route.ID()) {
+			continue
+		}
+		for _, o := range route.Owners {
+			if !seen[o] {
+				seen[o] = true
+				owners = append(owners, o)
+			}
 		}
 	}
+	return routes, owners
+}
+
+// fanOut splits the review of a commit or range whose diff exceeds the
+// repo's fan-out threshold into parts, and requeues the job to join them.
+// Returns false, leaving the job to run whole, if it was not fanned out.
+func (wp *WorkerPool) fanOut(ctx context.Context, workerID string, job *storage.ReviewJob, cfg *config.Config) bool {
+	groups := planFanOut(job, cfg)
+	if groups == nil {
+		return false
+	}
+	ids, err := wp.db.FanOutJob(ctx, job.ID, groups)
+	if err != nil {
+		log.Printf("[%s] Error fanning out job %d: %v", workerID, job.ID, err)
+		return false
+	}
+	log.Printf("[%s] Job %d fanned out into %d parts (jobs %d-%d)", workerID, job.ID, len(ids), ids[0], ids[len(ids)-1])
+	return true
+}
+
+// reviewPromptBuilder returns the prompt builder for a job's review
+func reviewPromptBuilder(base *prompt.Builder, job *storage.ReviewJob, cfg *config.Config) *prompt.Builder {
+	builder := base.WithRelatedReviews(cfg.RelatedReviewsCount)
+	if job.Focus {
+		builder = builder.Focus()
+	}
+	if job.TruncationLevel > 0 {
+		builder = builder.WithTruncation(job.TruncationLevel)
+	}
+	return builder
+}
+
+// buildReviewPrompt builds the prompt of a patch, dirty, commit or range
+// review, reporting whether it asks for a commit message. Parts and joins
+// of fanned-out reviews and the checklist are handled by the caller.
+func buildReviewPrompt(builder *prompt.Builder, job *storage.ReviewJob, cfg *config.Config) (string, bool, error) {
+	switch {
+	case job.DiffContent != nil && storage.IsPatchRef(job.GitRef):
+		// Patch file review - the patch is not in git
+		name := strings.TrimPrefix(job.GitRef, storage.PatchRefPrefix)
+		p, err := builder.BuildPatch(job.RepoPath, *job.DiffContent, name, job.RepoID, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+		return p, false, err
+	case job.DiffContent != nil:
+		// Dirty job - use pre-captured diff
+		p, err := builder.BuildDirty(job.RepoPath, *job.DiffContent, job.RepoID, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+		return p, false, err
+	}
+	// Commit or range - build prompt from git ref
+	p, err := builder.Build(job.RepoPath, job.GitRef, job.RepoID, cfg.ReviewContextCount, job.Agent, job.ReviewType)
+	if err != nil {
+		return "", false, err
+	}
+	if job.CommitID != nil && config.ResolveSuggestCommitMessage(job.RepoPath, cfg) {
+		return p + prompt.CommitMessageInstructions, true, nil
+	}
+	return p, false, nil
+}
+
+// planFanOut returns the groups of files a commit or range review fans out
+// into, or nil if it is reviewed in one prompt
+func planFanOut(job *storage.ReviewJob, cfg *config.Config) [][]string {
+	threshold := config.ResolveFanOutDiffSize(job.RepoPath, cfg)
+	if threshold == 0 {
+		return nil
+	}
+	diff, err := prompt.RefDiff(job.RepoPath, job.GitRef)
+	if err != nil {
+		return nil // Reported when the prompt is built
+	}
+	return prompt.PlanFanOut(diff, threshold)
+}
+
+// failedPartsError describes the parts of a fanned-out review that did not
+// complete, or returns "" if all of them did
+func failedPartsError(parts []storage.JobPart) string {
+	var failed []string
+	for _, p := range parts {
+		if p.Status == storage.JobStatusDone {
+			continue
+		}
+		msg := fmt.Sprintf("part %d (%s) %s", p.JobID, strings.Join(p.Paths, ", "), p.Status)
+		if p.Error != "" {
+			msg += ": " + p.Error
+		}
+		failed = append(failed, msg)
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return "fan-out " + strings.Join(failed, "; ")
 }
 
 // broadcastFailed sends a review.failed event for a job
 func (wp *WorkerPool) broadcastFailed(job *storage.ReviewJob, agentName, errorMsg string) {
-	wp.broadcaster.Broadcast(Event{
+	wp.broadcast(job, Event{
 		Type:     "review.failed",
 		TS:       time.Now(),
 		JobID:    job.ID,
@@ -442,3 +1014,164 @@ func (wp *WorkerPool) broadcastFailed(job *storage.ReviewJob, agentName, errorMs
 		Error:    errorMsg,
 	})
 }
+
+// reviewSigner is implemented by the stores that keep review signatures
+type reviewSigner interface {
+	SetReviewSignature(reviewID int64, signature, publicKey string) error
+}
+
+// signReview signs the stored review for jobID with the local signing key.
+func (wp *WorkerPool) signReview(ctx context.Context, jobID int64) error {
+	wp.signingMu.Lock()
+	if wp.signingKey == nil {
+		key, err := signing.LoadOrCreateKey(wp.signingKeyPath)
+		if err != nil {
+			wp.signingMu.Unlock()
+			return fmt.Errorf("load signing key: %w", err)
+		}
+		wp.signingKey = key
+	}
+	key := wp.signingKey
+	wp.signingMu.Unlock()
+
+	signer, ok := wp.store.(reviewSigner)
+	if !ok {
+		return fmt.Errorf("the configured database cannot store review signatures")
+	}
+	review, err := wp.store.GetReviewByJobID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	rec := signing.ReviewRecord{
+		ReviewID:   review.ID,
+		ReviewUUID: review.UUID,
+		JobID:      review.JobID,
+		GitRef:     review.Job.GitRef,
+		Agent:      review.Agent,
+		Prompt:     review.Prompt,
+		Output:     review.Output,
+	}
+	return signer.SetReviewSignature(review.ID, signing.Sign(key, rec.Payload()), signing.EncodePublicKey(key))
+}
+
+// validateFindings checks the file and line references in a review's
+// findings, records how many were invalid, and flags or drops them as
+// configured. Failures are logged and leave the output unchanged.
+func (wp *WorkerPool) validateFindings(job *storage.ReviewJob, agentName, output string) string {
+	result, err := checkFindings(job, output)
+	if err != nil {
+		log.Printf("Error checking findings for job %d: %v", job.ID, err)
+		return output
+	}
+	if len(result.Checks) == 0 {
+		return output
+	}
+	if wp.db != nil {
+		if err := wp.db.SaveFindingCheck(job.ID, agentName, len(result.Checks), result.Invalid); err != nil {
+			log.Printf("Error saving finding check for job %d: %v", job.ID, err)
+		}
+	}
+	mode := config.ResolveFindingValidation(job.RepoPath, wp.cfgGetter.Config())
+	return applyFindingChecks(output, result, mode)
+}
+
+// captureJobEnv records the versions, model and prompt template a job runs
+// with. Failures are logged and do not affect the job.
+func (wp *WorkerPool) captureJobEnv(job *storage.ReviewJob, a agent.Agent) {
+	if wp.db == nil {
+		return
+	}
+	var promptHash string
+	switch {
+	case job.IsTaskJob():
+		promptHash = prompt.TemplateHash(job.Agent, "run")
+	case job.DiffContent != nil:
+		promptHash = prompt.TemplateHash(job.Agent, prompt.SystemPromptType("dirty", job.ReviewType))
+	case git.IsRange(job.GitRef):
+		promptHash = prompt.TemplateHash(job.Agent, prompt.SystemPromptType("range", job.ReviewType))
+	default:
+		promptHash = prompt.TemplateHash(job.Agent, prompt.SystemPromptType("review", job.ReviewType))
+	}
+
+	env := storage.JobEnv{
+		JobID:          job.ID,
+		RoborevVersion: version.Version,
+		Agent:          a.Name(),
+		AgentVersion:   agent.CommandVersion(a),
+		Model:          job.Model,
+		OS:             runtime.GOOS + "/" + runtime.GOARCH,
+		PromptHash:     promptHash,
+	}
+	if err := wp.db.SaveJobEnv(env); err != nil {
+		log.Printf("Error saving environment for job %d: %v", job.ID, err)
+	}
+}
+
+// recordChangedSymbols stores the functions and types a job's diff changes
+// ('roborev search --symbol'). Failures are logged and do not affect the job.
+func (wp *WorkerPool) recordChangedSymbols(job *storage.ReviewJob) {
+	if wp.db == nil {
+		return
+	}
+	symbols, err := prompt.RefChangedSymbols(job.RepoPath, job.GitRef)
+	if err != nil {
+		log.Printf("Error finding changed symbols for job %d: %v", job.ID, err)
+		return
+	}
+	if err := wp.db.SaveJobSymbols(job.ID, symbols); err != nil {
+		log.Printf("Error saving changed symbols for job %d: %v", job.ID, err)
+	}
+}
+
+// telemetryUploadInterval is how often queued telemetry events are uploaded.
+const telemetryUploadInterval = time.Hour
+
+// telemetryUploader periodically flushes the telemetry queue to the
+// configured endpoint. It does nothing unless telemetry is enabled and an
+// endpoint is set; events stay queued locally otherwise.
+func (wp *WorkerPool) telemetryUploader() {
+	defer wp.wg.Done()
+	ticker := time.NewTicker(telemetryUploadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wp.stopCh:
+			return
+		case <-ticker.C:
+			cfg := wp.cfgGetter.Config()
+			if wp.telemetry == nil || !cfg.Telemetry.Enabled || cfg.Telemetry.Endpoint == "" {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, err := wp.telemetry.Flush(ctx, cfg.Telemetry.Endpoint); err != nil {
+				log.Printf("Telemetry: upload failed, will retry: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// recordFailureTelemetry queues a job.failed event classed by the failure's
+// error code, so the message itself is never recorded.
+func (wp *WorkerPool) recordFailureTelemetry(job *storage.ReviewJob, agentName string, code storage.ErrorCode) {
+	class := string(code)
+	if class == "" {
+		class = "other"
+	}
+	wp.recordTelemetry(telemetry.Event{
+		Kind:       telemetry.KindJobFailed,
+		Agent:      agentName,
+		JobType:    job.JobType,
+		ErrorClass: class,
+	})
+}
+
+// recordTelemetry queues an anonymized usage event if the user opted in.
+func (wp *WorkerPool) recordTelemetry(e telemetry.Event) {
+	if wp.telemetry == nil || !wp.cfgGetter.Config().Telemetry.Enabled {
+		return
+	}
+	if err := wp.telemetry.Record(e); err != nil {
+		log.Printf("Telemetry: failed to record event: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"golang.org/x/net/websocket"
+)
+
+// wsActionRoles are the actions a WebSocket client can send and the role
+// each needs: the same as the matching HTTP endpoint
+var wsActionRoles = map[string]string{
+	"cancel":  storage.RoleAdmin,    // /api/job/cancel
+	"bump":    storage.RoleAdmin,    // /api/job/bump
+	"approve": storage.RoleReviewer, // /api/review/address
+}
+
+// WSAction is a message from a WebSocket client asking to act on a job
+type WSAction struct {
+	ID     int64  `json:"id"` // Echoed in the result so clients can match replies
+	Action string `json:"action"`
+	JobID  int64  `json:"job_id"`
+}
+
+// WSResult answers a WSAction. Events are sent on the same connection, so
+// results are marked with type "result".
+type WSResult struct {
+	Type  string `json:"type"`
+	ID    int64  `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// sameOrigin reports whether a WebSocket handshake came from a page served
+// by this daemon, or from a client that is not a browser. Browsers let any
+// page open WebSockets to localhost, and local clients are trusted as
+// admins, so cross-origin pages must be refused.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// handleWebSocket streams the same events as /api/stream/events over a
+// WebSocket and accepts WSActions from the client, each checked against
+// the role of the connection's token
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, "cross-origin WebSocket connections are not allowed")
+		return
+	}
+	token := requestToken(r)
+	repoFilter := r.URL.Query().Get("repo")
+
+	ws := websocket.Server{
+		// The origin was checked above; x/net rejects handshakes without one
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			s.serveWebSocket(conn, token, repoFilter)
+		},
+	}
+	ws.ServeHTTP(w, r)
+}
+
+func (s *Server) serveWebSocket(conn *websocket.Conn, token *storage.APIToken, repoFilter string) {
+	defer conn.Close()
+
+	subID, eventCh := s.broadcaster.Subscribe(repoFilter)
+	defer s.broadcaster.Unsubscribe(subID)
+
+	actions := make(chan WSAction)
+	closed := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(closed)
+		for {
+			var action WSAction
+			if err := websocket.JSON.Receive(conn, &action); err != nil {
+				return
+			}
+			select {
+			case actions <- action:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(conn, event); err != nil {
+				return
+			}
+		case action := <-actions:
+			result := WSResult{Type: "result", ID: action.ID, OK: true}
+			if err := s.runWSAction(conn.Request().Context(), token, action); err != nil {
+				result.OK, result.Error = false, err.Error()
+			}
+			if err := websocket.JSON.Send(conn, result); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// runWSAction authorizes and runs one client action
+func (s *Server) runWSAction(ctx context.Context, token *storage.APIToken, action WSAction) error {
+	required, ok := wsActionRoles[action.Action]
+	if !ok {
+		return fmt.Errorf("unknown action %q (valid: cancel, bump, approve)", action.Action)
+	}
+	if !tokenAllows(token, required) {
+		return fmt.Errorf("token %q has role %s; %s needs %s", token.Name, token.Role, action.Action, required)
+	}
+	if action.JobID == 0 {
+		return fmt.Errorf("job_id is required")
+	}
+
+	var err error
+	var notFound string
+	switch action.Action {
+	case "cancel":
+		err, notFound = s.cancelJob(ctx, action.JobID), "job not found or not cancellable"
+	case "bump":
+		err, notFound = s.bumpJob(ctx, action.JobID), "job not found or not queued"
+	case "approve":
+		err, notFound = s.markAddressed(ctx, action.JobID, true), "review not found for job"
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New(notFound)
+	}
+	return err
+}
+
+// cancelJob cancels a queued or running job. Running jobs announce their
+// cancellation from the worker; others are announced here.
+func (s *Server) cancelJob(ctx context.Context, jobID int64) error {
+	job, err := s.store.GetJobByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.CancelJob(ctx, jobID); err != nil {
+		return err
+	}
+	s.workerPool.CancelJob(jobID)
+	if job.Status != storage.JobStatusRunning {
+		s.broadcastJobEvent("review.canceled", job)
+	}
+	return nil
+}
+
+// bumpJob moves a queued job to the front of the queue
+func (s *Server) bumpJob(ctx context.Context, jobID int64) error {
+	if s.db == nil {
+		return errors.New("bump is not supported with the configured database backend")
+	}
+	if err := s.db.BumpJob(ctx, jobID); err != nil {
+		return err
+	}
+	if job, err := s.store.GetJobByID(ctx, jobID); err == nil {
+		s.broadcastJobEvent("review.bumped", job)
+	}
+	return nil
+}
+
+// markAddressed marks a job's review addressed or not
+func (s *Server) markAddressed(ctx context.Context, jobID int64, addressed bool) error {
+	if err := s.store.MarkReviewAddressedByJobID(ctx, jobID, addressed); err != nil {
+		return err
+	}
+	eventType := "review.addressed"
+	if !addressed {
+		eventType = "review.unaddressed"
+	}
+	if job, err := s.store.GetJobByID(ctx, jobID); err == nil {
+		s.broadcastJobEvent(eventType, job)
+	}
+	return nil
+}
+
+// broadcastJobEvent tells stream and WebSocket clients a job changed
+func (s *Server) broadcastJobEvent(eventType string, job *storage.ReviewJob) {
+	s.broadcaster.Broadcast(Event{
+		Type:     eventType,
+		TS:       time.Now(),
+		JobID:    job.ID,
+		Repo:     job.RepoPath,
+		RepoName: job.RepoName,
+		SHA:      job.GitRef,
+		Agent:    job.Agent,
+	})
+}
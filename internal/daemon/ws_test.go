@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+	"golang.org/x/net/websocket"
+)
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://127.0.0.1:7373", true},
+		{"http://localhost:7373", false},
+		{"https://evil.example", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7373/api/ws", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if got := sameOrigin(req); got != tt.want {
+			t.Errorf("sameOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestWebSocketActions(t *testing.T) {
+	server, db, _ := newTestServer(t)
+	ts := httptest.NewServer(server.httpServer.Handler)
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/ws"
+
+	if _, err := websocket.Dial(wsURL, "", "https://evil.example"); err == nil {
+		t.Fatal("expected a cross-origin connection to be refused")
+	}
+
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/ws-repo")
+	var jobs []*storage.ReviewJob
+	for _, sha := range []string{"ws-a", "ws-b"} {
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "A", "S", time.Now())
+		job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"})
+		if err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	initialCount := server.broadcaster.SubscriberCount()
+	conn, err := websocket.Dial(wsURL, "", ts.URL)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	if !waitForSubscriberIncrease(server.broadcaster, initialCount, time.Second) {
+		t.Fatal("Timed out waiting for subscriber")
+	}
+
+	// act sends an action and returns its result, collecting the types of
+	// events received before it
+	var events []string
+	act := func(id int64, action string, jobID int64) WSResult {
+		t.Helper()
+		if err := websocket.JSON.Send(conn, WSAction{ID: id, Action: action, JobID: jobID}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			var msg struct {
+				WSResult
+				JobID int64 `json:"job_id"`
+			}
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				t.Fatalf("Receive: %v", err)
+			}
+			if msg.Type == "result" {
+				return msg.WSResult
+			}
+			events = append(events, msg.Type)
+		}
+	}
+
+	if res := act(1, "bump", jobs[1].ID); !res.OK || res.ID != 1 {
+		t.Errorf("unexpected bump result %+v", res)
+	}
+	if claimed, _ := db.ClaimJob(t.Context(), "worker-1"); claimed == nil || claimed.ID != jobs[1].ID {
+		t.Errorf("expected the bumped job to be claimed first, got %+v", claimed)
+	}
+	if res := act(2, "approve", jobs[0].ID); res.OK || res.Error != "review not found for job" {
+		t.Errorf("unexpected approve result %+v", res)
+	}
+	if res := act(3, "cancel", jobs[0].ID); !res.OK {
+		t.Errorf("unexpected cancel result %+v", res)
+	}
+	if res := act(4, "merge", jobs[0].ID); res.OK || !strings.Contains(res.Error, "unknown action") {
+		t.Errorf("unexpected result for an unknown action %+v", res)
+	}
+
+	// Events may trail their results; the bump's must have arrived by now
+	if len(events) == 0 || events[0] != "review.bumped" {
+		t.Errorf("expected a review.bumped event, got %v", events)
+	}
+}
+
+func TestRunWSActionRoles(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	viewer := &storage.APIToken{Name: "dash", Role: storage.RoleViewer}
+	reviewer := &storage.APIToken{Name: "alice", Role: storage.RoleReviewer}
+	tests := []struct {
+		token     *storage.APIToken
+		action    string
+		forbidden bool
+	}{
+		{viewer, "approve", true},
+		{viewer, "cancel", true},
+		{reviewer, "approve", false},
+		{reviewer, "bump", true},
+		{nil, "bump", false},
+	}
+	for _, tt := range tests {
+		err := server.runWSAction(t.Context(), tt.token, WSAction{Action: tt.action, JobID: 999})
+		if err == nil {
+			t.Fatalf("expected an error acting on a missing job")
+		}
+		if got := strings.Contains(err.Error(), "needs"); got != tt.forbidden {
+			t.Errorf("%s by %+v: got error %q, forbidden = %v", tt.action, tt.token, err, tt.forbidden)
+		}
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/roborev-dev/roborev/internal/cliprof"
 )
 
 // normalizeMSYSPath converts MSYS-style paths (e.g., /c/Users/...) to Windows paths (C:\Users\...).
@@ -30,27 +35,55 @@ func normalizeMSYSPath(path string) string {
 
 // CommitInfo holds metadata about a commit
 type CommitInfo struct {
-	SHA       string
-	Author    string
-	Subject   string
-	Body      string // Full commit message body (excluding subject)
-	Timestamp time.Time
+	SHA         string
+	Author      string
+	AuthorEmail string
+	Subject     string
+	Body        string // Full commit message body (excluding subject)
+	Timestamp   time.Time
+}
+
+// skipTrailer is the commit message trailer that opts a commit out of review.
+const skipTrailer = "roborev-skip:"
+
+// SkipReason returns why the commit opted out of review, or "" if it did not.
+// A commit opts out with "[skip roborev]" (or "[roborev skip]") anywhere in
+// its message, or with a "Roborev-Skip: <reason>" trailer.
+func (c *CommitInfo) SkipReason() string {
+	for _, line := range strings.Split(c.Body, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) >= len(skipTrailer) && strings.EqualFold(line[:len(skipTrailer)], skipTrailer) {
+			if reason := strings.TrimSpace(line[len(skipTrailer):]); reason != "" {
+				return reason
+			}
+			return "Roborev-Skip trailer"
+		}
+	}
+	msg := strings.ToLower(c.Subject + "\n" + c.Body)
+	for _, marker := range []string{"[skip roborev]", "[roborev skip]"} {
+		if strings.Contains(msg, marker) {
+			return "commit message contains " + marker
+		}
+	}
+	return ""
 }
 
-// GetCommitInfo retrieves commit metadata
+// GetCommitInfo retrieves commit metadata. The author's name and email are
+// mapped through the repo's .mailmap, so one person reads the same across
+// the names and addresses they have committed under.
 func GetCommitInfo(repoPath, sha string) (*CommitInfo, error) {
 	// Use record separator (ASCII 30) to delimit fields - won't appear in commit messages
 	const rs = "\x1e"
-	cmd := exec.Command("git", "log", "-1", "--format=%H"+rs+"%an"+rs+"%s"+rs+"%aI"+rs+"%b", sha)
+	cmd := exec.Command("git", "log", "-1", "--format=%H"+rs+"%aN"+rs+"%s"+rs+"%aI"+rs+"%aE"+rs+"%b", sha)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("git log: %w", err)
 	}
 
-	parts := strings.SplitN(strings.TrimSuffix(string(out), "\n"), rs, 5)
-	if len(parts) < 4 {
+	parts := strings.SplitN(strings.TrimSuffix(string(out), "\n"), rs, 6)
+	if len(parts) < 5 {
 		return nil, fmt.Errorf("unexpected git log output: %s", out)
 	}
 
@@ -60,25 +93,54 @@ func GetCommitInfo(repoPath, sha string) (*CommitInfo, error) {
 	}
 
 	var body string
-	if len(parts) >= 5 {
-		body = strings.TrimSpace(parts[4])
+	if len(parts) >= 6 {
+		body = strings.TrimSpace(parts[5])
 	}
 
 	return &CommitInfo{
-		SHA:       parts[0],
-		Author:    parts[1],
-		Subject:   parts[2],
-		Body:      body,
-		Timestamp: ts,
+		SHA:         parts[0],
+		Author:      parts[1],
+		AuthorEmail: parts[4],
+		Subject:     parts[2],
+		Body:        body,
+		Timestamp:   ts,
 	}, nil
 }
 
+// GetUserIdentity returns the name and email the user commits under in a
+// repo, mapped through the repo's .mailmap like commit authors are
+func GetUserIdentity(repoPath string) (name, email string, err error) {
+	cmd := exec.Command("git", "var", "GIT_AUTHOR_IDENT")
+	cmd.Dir = repoPath
+	out, err := output(cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("git var: %w", err)
+	}
+	// "Name <email> timestamp zone"
+	ident := strings.TrimSpace(string(out))
+	if end := strings.LastIndex(ident, ">"); end >= 0 {
+		ident = ident[:end+1]
+	}
+
+	cmd = exec.Command("git", "check-mailmap", ident)
+	cmd.Dir = repoPath
+	if mapped, err := output(cmd); err == nil {
+		ident = strings.TrimSpace(string(mapped))
+	}
+
+	start := strings.Index(ident, "<")
+	if start < 0 || !strings.HasSuffix(ident, ">") {
+		return "", "", fmt.Errorf("unexpected git identity %q", ident)
+	}
+	return strings.TrimSpace(ident[:start]), ident[start+1 : len(ident)-1], nil
+}
+
 // GetCurrentBranch returns the current branch name, or empty string if detached HEAD
 func GetCurrentBranch(repoPath string) string {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return ""
 	}
@@ -100,14 +162,10 @@ func LocalBranchName(branch string) string {
 
 // GetDiff returns the full diff for a commit, excluding generated files like lock files
 func GetDiff(repoPath, sha string) (string, error) {
-	args := []string{"show", sha, "--format=", "--"}
-	args = append(args, ".")
-	args = append(args, excludedPathPatterns...)
-
-	cmd := exec.Command("git", args...)
+	cmd := exec.Command("git", diffArgs("show", sha, "--format=")...)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git show: %w", err)
 	}
@@ -115,12 +173,83 @@ func GetDiff(repoPath, sha string) (string, error) {
 	return string(out), nil
 }
 
+// GetDiffLimited returns at most limit bytes of a commit's diff, and whether
+// the diff was longer. The rest is discarded as git writes it, so a
+// multi-megabyte commit never has to fit in memory.
+func GetDiffLimited(repoPath, sha string, limit int) (string, bool, error) {
+	cmd := exec.Command("git", diffArgs("show", sha, "--format=")...)
+	cmd.Dir = repoPath
+
+	out, truncated, err := outputLimited(cmd, limit)
+	if err != nil {
+		return "", false, fmt.Errorf("git show: %w", err)
+	}
+	return out, truncated, nil
+}
+
+// diffArgs appends the pathspec that excludes generated files to a diff
+// command
+func diffArgs(baseArgs ...string) []string {
+	args := append(baseArgs, "--", ".")
+	return append(args, excludedPathPatterns...)
+}
+
+// run, output and combinedOutput run a git command like the exec.Cmd
+// methods of the same names, timing it for 'roborev --profile-cli'
+func run(cmd *exec.Cmd) error {
+	defer cliprof.Span(cliprof.PhaseGit)()
+	return cmd.Run()
+}
+
+func output(cmd *exec.Cmd) ([]byte, error) {
+	defer cliprof.Span(cliprof.PhaseGit)()
+	return cmd.Output()
+}
+
+func combinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	defer cliprof.Span(cliprof.PhaseGit)()
+	return cmd.CombinedOutput()
+}
+
+// outputLimited runs cmd and returns the first limit bytes of its stdout,
+// and whether there was more. Output past the limit is read and dropped so
+// the command can finish.
+func outputLimited(cmd *exec.Cmd, limit int) (string, bool, error) {
+	defer cliprof.Span(cliprof.PhaseGit)()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", false, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return "", false, err
+	}
+
+	out, readErr := io.ReadAll(io.LimitReader(stdout, int64(limit)+1))
+	truncated := len(out) > limit
+	if truncated {
+		out = out[:limit]
+		_, readErr = io.Copy(io.Discard, stdout)
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", false, fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", false, err
+	}
+	if readErr != nil {
+		return "", false, readErr
+	}
+	return string(out), truncated, nil
+}
+
 // GetFilesChanged returns the list of files changed in a commit
 func GetFilesChanged(repoPath, sha string) ([]string, error) {
 	cmd := exec.Command("git", "diff-tree", "--no-commit-id", "--name-only", "-r", sha)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("git diff-tree: %w", err)
 	}
@@ -141,7 +270,7 @@ func GetStat(repoPath, sha string) (string, error) {
 	cmd := exec.Command("git", "show", "--stat", sha, "--format=")
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git show --stat: %w", err)
 	}
@@ -157,7 +286,7 @@ func IsUnbornHead(repoPath string) bool {
 	// Step 1: HEAD must be a symbolic ref (e.g., refs/heads/main)
 	cmd := exec.Command("git", "symbolic-ref", "-q", "HEAD")
 	cmd.Dir = repoPath
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return false // not a symbolic ref or not a git repo
 	}
@@ -170,7 +299,7 @@ func IsUnbornHead(repoPath string) bool {
 	// missing object), rev-parse still succeeds and returns the raw SHA.
 	cmd = exec.Command("git", "rev-parse", "--verify", ref)
 	cmd.Dir = repoPath
-	return cmd.Run() != nil
+	return run(cmd) != nil
 }
 
 // ResolveSHA resolves a ref (like HEAD) to a full SHA
@@ -178,7 +307,7 @@ func ResolveSHA(repoPath, ref string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", ref)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse: %w", err)
 	}
@@ -193,7 +322,7 @@ func ResolveSHA(repoPath, ref string) (string, error) {
 func IsAncestor(repoPath, ancestor, descendant string) (bool, error) {
 	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
 	cmd.Dir = repoPath
-	err := cmd.Run()
+	err := run(cmd)
 	if err == nil {
 		return true, nil
 	}
@@ -210,7 +339,7 @@ func GetRepoRoot(path string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
 	cmd.Dir = path
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse --show-toplevel: %w", err)
 	}
@@ -230,7 +359,7 @@ func GetMainRepoRoot(path string) (string, error) {
 	// For worktrees: --git-dir returns worktree-specific dir, --git-common-dir returns main repo's .git
 	gitDirCmd := exec.Command("git", "rev-parse", "--git-dir")
 	gitDirCmd.Dir = path
-	gitDirOut, err := gitDirCmd.Output()
+	gitDirOut, err := output(gitDirCmd)
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse --git-dir: %w", err)
 	}
@@ -238,7 +367,7 @@ func GetMainRepoRoot(path string) (string, error) {
 
 	commonDirCmd := exec.Command("git", "rev-parse", "--git-common-dir")
 	commonDirCmd.Dir = path
-	commonDirOut, err := commonDirCmd.Output()
+	commonDirOut, err := output(commonDirCmd)
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse --git-common-dir: %w", err)
 	}
@@ -267,7 +396,7 @@ func GetMainRepoRoot(path string) (string, error) {
 
 		// Submodule worktree - read core.worktree from config
 		cmd := exec.Command("git", "config", "--file", filepath.Join(commonDir, "config"), "core.worktree")
-		out, err := cmd.Output()
+		out, err := output(cmd)
 		if err != nil {
 			return "", fmt.Errorf("git config core.worktree for submodule worktree: %w", err)
 		}
@@ -291,13 +420,35 @@ func ReadFile(repoPath, sha, filePath string) ([]byte, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := run(cmd); err != nil {
 		return nil, fmt.Errorf("git show %s:%s: %s", sha, filePath, stderr.String())
 	}
 
 	return stdout.Bytes(), nil
 }
 
+// ListFiles returns the paths of all files in the tree of a revision
+func ListFiles(repoPath, ref string) ([]string, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "--name-only", "-z", ref)
+	cmd.Dir = repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := run(cmd); err != nil {
+		return nil, fmt.Errorf("git ls-tree %s: %s", ref, stderr.String())
+	}
+
+	var files []string
+	for _, f := range strings.Split(stdout.String(), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
 // GetParentCommits returns the N commits before the given commit (not including it)
 // Returns commits in reverse chronological order (most recent parent first)
 func GetParentCommits(repoPath, sha string, count int) ([]string, error) {
@@ -305,7 +456,7 @@ func GetParentCommits(repoPath, sha string, count int) ([]string, error) {
 	cmd := exec.Command("git", "log", "--format=%H", "-n", fmt.Sprintf("%d", count), "--skip=1", sha)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("git log: %w", err)
 	}
@@ -321,6 +472,20 @@ func GetParentCommits(repoPath, sha string, count int) ([]string, error) {
 	return commits, nil
 }
 
+// GetRecentCommits returns up to count commits reachable from ref, most
+// recent first.
+func GetRecentCommits(repoPath, ref string, count int) ([]string, error) {
+	cmd := exec.Command("git", "log", "--format=%H", "-n", fmt.Sprintf("%d", count), ref)
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
 // IsRange returns true if the ref is a range (contains "..")
 func IsRange(ref string) bool {
 	return strings.Contains(ref, "..")
@@ -340,7 +505,7 @@ func GetRangeCommits(repoPath, rangeRef string) ([]string, error) {
 	cmd := exec.Command("git", "log", "--format=%H", "--reverse", rangeRef)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("git log range: %w", err)
 	}
@@ -358,14 +523,10 @@ func GetRangeCommits(repoPath, rangeRef string) ([]string, error) {
 
 // GetRangeDiff returns the combined diff for a range, excluding generated files like lock files
 func GetRangeDiff(repoPath, rangeRef string) (string, error) {
-	args := []string{"diff", rangeRef, "--"}
-	args = append(args, ".")
-	args = append(args, excludedPathPatterns...)
-
-	cmd := exec.Command("git", args...)
+	cmd := exec.Command("git", diffArgs("diff", rangeRef)...)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git diff range: %w", err)
 	}
@@ -373,13 +534,26 @@ func GetRangeDiff(repoPath, rangeRef string) (string, error) {
 	return string(out), nil
 }
 
+// GetRangeDiffLimited returns at most limit bytes of a range's combined
+// diff, and whether the diff was longer
+func GetRangeDiffLimited(repoPath, rangeRef string, limit int) (string, bool, error) {
+	cmd := exec.Command("git", diffArgs("diff", rangeRef)...)
+	cmd.Dir = repoPath
+
+	out, truncated, err := outputLimited(cmd, limit)
+	if err != nil {
+		return "", false, fmt.Errorf("git diff range: %w", err)
+	}
+	return out, truncated, nil
+}
+
 // HasUncommittedChanges returns true if there are uncommitted changes (staged, unstaged, or untracked files)
 func HasUncommittedChanges(repoPath string) (bool, error) {
 	// Check for staged or unstaged changes to tracked files
 	cmd := exec.Command("git", "status", "--porcelain")
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return false, fmt.Errorf("git status: %w", err)
 	}
@@ -397,19 +571,11 @@ const EmptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
 func GetDirtyDiff(repoPath string) (string, error) {
 	var result strings.Builder
 
-	// Build diff args with exclusions
-	diffArgs := func(baseArgs ...string) []string {
-		args := append(baseArgs, "--")
-		args = append(args, ".")
-		args = append(args, excludedPathPatterns...)
-		return args
-	}
-
 	// 1. Get diff of tracked files (staged + unstaged)
 	cmd := exec.Command("git", diffArgs("diff", "HEAD")...)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		// If HEAD doesn't exist (no commits yet), we need to combine:
 		// - git diff --cached <empty-tree>: shows staged files (index vs empty)
@@ -419,7 +585,7 @@ func GetDirtyDiff(repoPath string) (string, error) {
 		// Get staged changes vs empty tree
 		cmd = exec.Command("git", diffArgs("diff", "--cached", EmptyTreeSHA)...)
 		cmd.Dir = repoPath
-		stagedOut, err := cmd.Output()
+		stagedOut, err := output(cmd)
 		if err != nil {
 			return "", fmt.Errorf("git diff --cached: %w", err)
 		}
@@ -430,7 +596,7 @@ func GetDirtyDiff(repoPath string) (string, error) {
 		// Get unstaged changes (working tree vs index)
 		cmd = exec.Command("git", diffArgs("diff")...)
 		cmd.Dir = repoPath
-		unstagedOut, err := cmd.Output()
+		unstagedOut, err := output(cmd)
 		if err != nil {
 			return "", fmt.Errorf("git diff: %w", err)
 		}
@@ -447,7 +613,7 @@ func GetDirtyDiff(repoPath string) (string, error) {
 	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard")
 	cmd.Dir = repoPath
 
-	untrackedOut, err := cmd.Output()
+	untrackedOut, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git ls-files: %w", err)
 	}
@@ -528,6 +694,16 @@ var excludedPathPatterns = []string{
 	":(exclude).cache",   // Generic cache directory (pip, pre-commit, etc.)
 }
 
+// ExcludedPaths returns the repo-relative paths of the generated files and
+// directories left out of diffs, for other tools to exclude them too
+func ExcludedPaths() []string {
+	paths := make([]string, len(excludedPathPatterns))
+	for i, pattern := range excludedPathPatterns {
+		paths[i] = strings.TrimPrefix(pattern, ":(exclude)")
+	}
+	return paths
+}
+
 var excludedDirPatterns = map[string]struct{}{
 	".beads":   {},
 	".gocache": {},
@@ -578,7 +754,7 @@ func GetRangeFilesChanged(repoPath, rangeRef string) ([]string, error) {
 	cmd := exec.Command("git", "diff", "--name-only", rangeRef)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("git diff --name-only: %w", err)
 	}
@@ -615,7 +791,7 @@ func IsRebaseInProgress(repoPath string) bool {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return false
 	}
@@ -646,7 +822,7 @@ func GetBranchName(repoPath, sha string) string {
 	cmd := exec.CommandContext(ctx, "git", "name-rev", "--name-only", "--refs=refs/heads/*", sha)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return ""
 	}
@@ -671,7 +847,7 @@ func GetHooksPath(repoPath string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse --git-path hooks: %w", err)
 	}
@@ -691,7 +867,7 @@ func GetDefaultBranch(repoPath string) (string, error) {
 	// Prefer origin/HEAD as the authoritative source for the default branch
 	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
 	cmd.Dir = repoPath
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err == nil {
 		// Returns refs/remotes/origin/main -> extract "main"
 		ref := strings.TrimSpace(string(out))
@@ -700,13 +876,13 @@ func GetDefaultBranch(repoPath string) (string, error) {
 			// Verify the remote-tracking ref exists before using it
 			checkCmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branchName)
 			checkCmd.Dir = repoPath
-			if checkCmd.Run() == nil {
+			if run(checkCmd) == nil {
 				return "origin/" + branchName, nil
 			}
 			// Remote-tracking ref doesn't exist, fall back to local branch
 			checkCmd = exec.Command("git", "rev-parse", "--verify", "--quiet", branchName)
 			checkCmd.Dir = repoPath
-			if checkCmd.Run() == nil {
+			if run(checkCmd) == nil {
 				return branchName, nil
 			}
 		}
@@ -716,7 +892,7 @@ func GetDefaultBranch(repoPath string) (string, error) {
 	for _, branch := range []string{"main", "master"} {
 		cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", branch)
 		cmd.Dir = repoPath
-		if err := cmd.Run(); err == nil {
+		if err := run(cmd); err == nil {
 			return branch, nil
 		}
 	}
@@ -729,7 +905,7 @@ func GetMergeBase(repoPath, ref1, ref2 string) (string, error) {
 	cmd := exec.Command("git", "merge-base", ref1, ref2)
 	cmd.Dir = repoPath
 
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git merge-base: %w", err)
 	}
@@ -737,6 +913,61 @@ func GetMergeBase(repoPath, ref1, ref2 string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetPreviousTag returns the most recent tag reachable from ref's first
+// parent, i.e. the tag preceding ref. Returns an empty string (and no error)
+// when ref has no earlier tag in its history.
+func GetPreviousTag(repoPath, ref string) (string, error) {
+	sha, err := ResolveSHA(repoPath, ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	// A root commit has no parent and therefore no earlier tag
+	if _, err := ResolveSHA(repoPath, sha+"^"); err != nil {
+		return "", nil
+	}
+
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0", sha+"^")
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := output(cmd)
+	if err != nil {
+		msg := stderr.String()
+		if strings.Contains(msg, "No names found") || strings.Contains(msg, "No tags can describe") {
+			return "", nil
+		}
+		return "", fmt.Errorf("git describe: %w: %s", err, strings.TrimSpace(msg))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetRootCommit returns the SHA of the oldest root commit reachable from
+// HEAD. It identifies a repository independent of where it is checked out.
+// Returns an empty string (and no error) for a repo with no commits.
+func GetRootCommit(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-list", "--max-parents=0", "HEAD")
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := output(cmd)
+	if err != nil {
+		if _, headErr := ResolveSHA(repoPath, "HEAD"); headErr != nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("git rev-list: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// rev-list lists newest first; the last root is the original one
+	roots := strings.Fields(string(out))
+	if len(roots) == 0 {
+		return "", nil
+	}
+	return roots[len(roots)-1], nil
+}
+
 // GetCommitsSince returns all commits from mergeBase to HEAD (exclusive of mergeBase)
 // Returns commits in chronological order (oldest first)
 func GetCommitsSince(repoPath, mergeBase string) ([]string, error) {
@@ -752,7 +983,7 @@ func CreateCommit(repoPath, message string) (string, error) {
 	cmd.Dir = repoPath
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := run(cmd); err != nil {
 		return "", fmt.Errorf("git add: %w: %s", err, stderr.String())
 	}
 
@@ -761,7 +992,7 @@ func CreateCommit(repoPath, message string) (string, error) {
 	cmd.Dir = repoPath
 	stderr.Reset()
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := run(cmd); err != nil {
 		return "", fmt.Errorf("git commit: %w: %s", err, stderr.String())
 	}
 
@@ -774,10 +1005,24 @@ func CreateCommit(repoPath, message string) (string, error) {
 	return sha, nil
 }
 
+// AmendCommitMessage replaces the message of the HEAD commit. Only the
+// message changes: staged changes are left out of the amended commit.
+func AmendCommitMessage(repoPath, message string) error {
+	cmd := exec.Command("git", "commit", "--amend", "--only", "--allow-empty", "-F", "-")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(message)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("git commit --amend: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // IsWorkingTreeClean returns true if the working tree has no uncommitted or untracked changes
 func IsWorkingTreeClean(repoPath string) bool {
 	cmd := exec.Command("git", "-C", repoPath, "status", "--porcelain")
-	output, err := cmd.Output()
+	output, err := output(cmd)
 	if err != nil {
 		return false // Assume dirty if we can't check
 	}
@@ -788,12 +1033,12 @@ func IsWorkingTreeClean(repoPath string) bool {
 func ResetWorkingTree(repoPath string) error {
 	// Reset staged changes
 	resetCmd := exec.Command("git", "-C", repoPath, "reset", "--hard", "HEAD")
-	if err := resetCmd.Run(); err != nil {
+	if err := run(resetCmd); err != nil {
 		return fmt.Errorf("git reset --hard: %w", err)
 	}
 	// Clean untracked files
 	cleanCmd := exec.Command("git", "-C", repoPath, "clean", "-fd")
-	if err := cleanCmd.Run(); err != nil {
+	if err := run(cleanCmd); err != nil {
 		return fmt.Errorf("git clean: %w", err)
 	}
 	return nil
@@ -818,7 +1063,7 @@ func GetRemoteURL(repoPath, remoteName string) string {
 func getRemoteURLByName(repoPath, name string) string {
 	cmd := exec.Command("git", "remote", "get-url", name)
 	cmd.Dir = repoPath
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return ""
 	}
@@ -829,7 +1074,7 @@ func getAnyRemoteURL(repoPath string) string {
 	// List all remotes
 	cmd := exec.Command("git", "remote")
 	cmd.Dir = repoPath
-	out, err := cmd.Output()
+	out, err := output(cmd)
 	if err != nil {
 		return ""
 	}
@@ -846,3 +1091,283 @@ func getAnyRemoteURL(repoPath string) string {
 	}
 	return ""
 }
+
+// CloneNoCheckout clones source into dest without checking out any files.
+// The clone has a working directory so review commands that resolve the
+// repository root still work, but the daemon never populates it.
+func CloneNoCheckout(source, dest string) error {
+	cmd := exec.Command("git", "clone", "--quiet", "--no-checkout", source, dest)
+	if out, err := combinedOutput(cmd); err != nil {
+		return fmt.Errorf("git clone: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// FetchAllBranches updates every local branch and tag of a clone made by
+// CloneNoCheckout to match its origin.
+func FetchAllBranches(repoPath string) error {
+	cmd := exec.Command("git", "fetch", "--quiet", "--prune", "--force", "--update-head-ok",
+		"origin", "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+	cmd.Dir = repoPath
+	if out, err := combinedOutput(cmd); err != nil {
+		return fmt.Errorf("git fetch: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// GetNewBranchCommits returns the commits reachable from sha that are not on
+// any branch other than ref (oldest first). Used to find what a push of a
+// new branch introduces.
+func GetNewBranchCommits(repoPath, sha, ref string) ([]string, error) {
+	// --exclude patterns for --branches are relative to refs/heads/
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	cmd := exec.Command("git", "rev-list", "--reverse", sha, "--not", "--exclude="+branch, "--branches")
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// IsOnAnyRef reports whether sha is reachable from a local or
+// remote-tracking branch or a tag. Commits replaced by a rebase are not,
+// once their branch has moved on.
+func IsOnAnyRef(repoPath, sha string) (bool, error) {
+	cmd := exec.Command("git", "for-each-ref", "--count=1", "--format=%(refname)",
+		"--contains", sha, "refs/heads", "refs/remotes", "refs/tags")
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return false, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// GetAbsoluteGitDir returns the absolute path of the git directory for path.
+// For a bare repository this is the repository itself.
+func GetAbsoluteGitDir(path string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = path
+
+	out, err := output(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --absolute-git-dir: %w", err)
+	}
+	return normalizeMSYSPath(string(out)), nil
+}
+
+// GetCommitsAfter returns the non-merge commits reachable from ref that were
+// committed after since (oldest first)
+func GetCommitsAfter(repoPath, ref string, since time.Time) ([]string, error) {
+	cmd := exec.Command("git", "log", "--format=%H", "--reverse", "--no-merges",
+		"--since="+since.Format(time.RFC3339), ref, "--")
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git log --since: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// FileChurn is how often a file changed in a period of history
+type FileChurn struct {
+	Path         string
+	Commits      int // Non-merge commits touching the file
+	LinesChanged int // Lines added plus lines deleted; 0 for binary files
+}
+
+// GetChurn returns the churn of each file changed by non-merge commits
+// reachable from HEAD since the given time, most commits first. Lock files
+// and other generated paths excluded from diffs are left out.
+func GetChurn(repoPath string, since time.Time) ([]FileChurn, error) {
+	args := []string{"-c", "core.quotePath=false", "log", "--no-merges", "--no-renames", "--numstat", "--format=",
+		"--since=" + since.Format(time.RFC3339), "HEAD", "--", "."}
+	args = append(args, excludedPathPatterns...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git log --numstat: %w", err)
+	}
+	return parseNumstat(string(out)), nil
+}
+
+// GetCommitChurn returns the lines changed in each file of a commit, most
+// lines first, leaving out the paths excluded from diffs like GetChurn
+func GetCommitChurn(repoPath, sha string) ([]FileChurn, error) {
+	args := []string{"-c", "core.quotePath=false", "show", "--no-renames", "--numstat", "--format=", sha, "--", "."}
+	args = append(args, excludedPathPatterns...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+
+	out, err := output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git show --numstat: %w", err)
+	}
+	churn := parseNumstat(string(out))
+	sort.SliceStable(churn, func(i, j int) bool { return churn[i].LinesChanged > churn[j].LinesChanged })
+	return churn, nil
+}
+
+// parseNumstat totals --numstat output by path, most commits first
+func parseNumstat(out string) []FileChurn {
+	byPath := make(map[string]*FileChurn)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		c := byPath[fields[2]]
+		if c == nil {
+			c = &FileChurn{Path: fields[2]}
+			byPath[fields[2]] = c
+		}
+		c.Commits++
+		added, _ := strconv.Atoi(fields[0]) // "-" for binary files
+		deleted, _ := strconv.Atoi(fields[1])
+		c.LinesChanged += added + deleted
+	}
+
+	churn := make([]FileChurn, 0, len(byPath))
+	for _, c := range byPath {
+		churn = append(churn, *c)
+	}
+	sort.Slice(churn, func(i, j int) bool {
+		if churn[i].Commits != churn[j].Commits {
+			return churn[i].Commits > churn[j].Commits
+		}
+		return churn[i].Path < churn[j].Path
+	})
+	return churn
+}
+
+// GetMainRepoRootAndHead returns the main repository root (resolving
+// worktrees like GetMainRepoRoot) and the HEAD commit using a single git
+// invocation, for latency-sensitive callers such as shell prompts.
+func GetMainRepoRootAndHead(path string) (string, string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir", "HEAD")
+	cmd.Dir = path
+
+	out, err := output(cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 4 {
+		return "", "", fmt.Errorf("unexpected git rev-parse output: %q", out)
+	}
+	root := normalizeMSYSPath(lines[0])
+	gitDir, commonDir := strings.TrimSpace(lines[1]), strings.TrimSpace(lines[2])
+	head := strings.TrimSpace(lines[3])
+
+	// A linked worktree has its own git dir; its main repo owns the common
+	// dir. Submodule worktrees are left to GetMainRepoRoot.
+	if gitDir != commonDir {
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(path, commonDir)
+		}
+		commonDir = filepath.Clean(commonDir)
+		if filepath.Base(commonDir) != ".git" {
+			root, err = GetMainRepoRoot(path)
+			return root, head, err
+		}
+		root = filepath.Dir(commonDir)
+	}
+	return root, head, nil
+}
+
+// PatchID returns the stable patch-id of a commit's diff against its first
+// parent, which is the same for cherry-picks of the commit onto other
+// branches. Returns "" for commits with no textual changes, such as merges.
+func PatchID(repoPath, sha string) (string, error) {
+	diffCmd := exec.Command("git", "diff-tree", "-p", "--no-color", "--root", sha)
+	diffCmd.Dir = repoPath
+	diff, err := output(diffCmd)
+	if err != nil {
+		return "", fmt.Errorf("git diff-tree: %w", err)
+	}
+
+	cmd := exec.Command("git", "patch-id", "--stable")
+	cmd.Dir = repoPath
+	cmd.Stdin = bytes.NewReader(diff)
+	out, err := output(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git patch-id: %w", err)
+	}
+	// Output is "<patch-id> <commit-id>", or nothing for an empty diff
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], nil
+}
+
+// BlameCommit is a commit that last touched some lines of a file
+type BlameCommit struct {
+	SHA     string
+	Author  string
+	Time    time.Time
+	Summary string
+	Lines   int // Lines of the blamed ranges it last touched
+}
+
+// BlameLines returns the commits that last touched the given 1-based,
+// inclusive line ranges of a file at rev, in order of first appearance
+func BlameLines(repoPath, rev, filePath string, ranges [][2]int) ([]BlameCommit, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+	args := []string{"blame", "--porcelain"}
+	for _, r := range ranges {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", r[0], r[1]))
+	}
+	args = append(args, rev, "--", filePath)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := run(cmd); err != nil {
+		return nil, fmt.Errorf("git blame %s: %s", filePath, strings.TrimSpace(stderr.String()))
+	}
+
+	// Porcelain output gives a header line per blamed line, "<sha> <orig>
+	// <final> [<count>]", followed by the commit's details the first time
+	// it appears and the line itself after a tab
+	var commits []BlameCommit
+	index := make(map[string]int)
+	cur := -1
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+		case cur >= 0 && strings.HasPrefix(line, "author "):
+			commits[cur].Author = strings.TrimPrefix(line, "author ")
+		case cur >= 0 && strings.HasPrefix(line, "author-time "):
+			if secs, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				commits[cur].Time = time.Unix(secs, 0)
+			}
+		case cur >= 0 && strings.HasPrefix(line, "summary "):
+			commits[cur].Summary = strings.TrimPrefix(line, "summary ")
+		default:
+			fields := strings.Fields(line)
+			if len(fields) < 3 || len(fields[0]) < 40 {
+				continue
+			}
+			i, ok := index[fields[0]]
+			if !ok {
+				i = len(commits)
+				index[fields[0]] = i
+				commits = append(commits, BlameCommit{SHA: fields[0]})
+			}
+			commits[i].Lines++
+			cur = i
+		}
+	}
+	return commits, nil
+}
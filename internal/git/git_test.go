@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestRepo wraps a temporary git repository for testing.
@@ -668,6 +669,9 @@ func TestGetCommitInfo(t *testing.T) {
 		if info.Author != "Test Author" {
 			t.Errorf("expected author 'Test Author', got '%s'", info.Author)
 		}
+		if info.AuthorEmail != "test@test.com" {
+			t.Errorf("expected author email 'test@test.com', got '%s'", info.AuthorEmail)
+		}
 	})
 
 	t.Run("commit with subject and body", func(t *testing.T) {
@@ -724,6 +728,67 @@ func TestGetCommitInfo(t *testing.T) {
 	})
 }
 
+func TestGetCommitInfoMailmap(t *testing.T) {
+	repo := NewTestRepoWithAuthor(t, "Jane D")
+	repo.WriteFile(".mailmap", "Jane Doe <jane.doe@corp.example> Jane D <test@test.com>\n")
+	repo.Run("add", ".")
+	repo.Run("commit", "-m", "Add mailmap")
+
+	info, err := GetCommitInfo(repo.Dir, repo.HeadSHA())
+	if err != nil {
+		t.Fatalf("GetCommitInfo failed: %v", err)
+	}
+	if info.Author != "Jane Doe" || info.AuthorEmail != "jane.doe@corp.example" {
+		t.Errorf("expected mailmapped author, got %q <%s>", info.Author, info.AuthorEmail)
+	}
+}
+
+func TestGetUserIdentity(t *testing.T) {
+	repo := NewTestRepoWithAuthor(t, "Jane D")
+
+	name, email, err := GetUserIdentity(repo.Dir)
+	if err != nil {
+		t.Fatalf("GetUserIdentity failed: %v", err)
+	}
+	if name != "Jane D" || email != "test@test.com" {
+		t.Errorf("expected Jane D <test@test.com>, got %q <%s>", name, email)
+	}
+
+	repo.WriteFile(".mailmap", "Jane Doe <jane.doe@corp.example> Jane D <test@test.com>\n")
+	name, email, err = GetUserIdentity(repo.Dir)
+	if err != nil {
+		t.Fatalf("GetUserIdentity failed: %v", err)
+	}
+	if name != "Jane Doe" || email != "jane.doe@corp.example" {
+		t.Errorf("expected mailmapped identity, got %q <%s>", name, email)
+	}
+}
+
+func TestCommitInfoSkipReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		want    string
+	}{
+		{"no marker", "Fix parser", "Handles empty input.", ""},
+		{"subject marker", "Bump deps [skip roborev]", "", "commit message contains [skip roborev]"},
+		{"body marker mixed case", "Regenerate mocks", "[Skip Roborev]", "commit message contains [skip roborev]"},
+		{"alternate order", "Rename package [roborev skip]", "", "commit message contains [roborev skip]"},
+		{"trailer with reason", "Rename package", "Mass rename only.\n\nRoborev-Skip: generated code", "generated code"},
+		{"trailer without reason", "Rename package", "roborev-skip:", "Roborev-Skip trailer"},
+		{"marker in prose", "Document skip roborev behavior", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &CommitInfo{Subject: tt.subject, Body: tt.body}
+			if got := info.SkipReason(); got != tt.want {
+				t.Errorf("SkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetBranchName(t *testing.T) {
 	repo := NewTestRepo(t)
 	repo.CommitFile("file.txt", "content", "initial")
@@ -1088,6 +1153,54 @@ func TestGetDiffExcludesGeneratedFiles(t *testing.T) {
 	})
 }
 
+func TestGetDiffLimited(t *testing.T) {
+	repo := NewTestRepo(t)
+	repo.CommitFile("initial.txt", "initial content", "initial")
+	repo.CommitFile("big.txt", strings.Repeat("line of a large file\n", 5000), "add big file")
+	sha := repo.HeadSHA()
+
+	full, err := GetDiff(repo.Dir, sha)
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		diff, truncated, err := GetDiffLimited(repo.Dir, sha, len(full))
+		if err != nil {
+			t.Fatalf("GetDiffLimited failed: %v", err)
+		}
+		if truncated || diff != full {
+			t.Errorf("expected the full diff, got %d bytes (truncated=%v)", len(diff), truncated)
+		}
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		diff, truncated, err := GetDiffLimited(repo.Dir, sha, 1000)
+		if err != nil {
+			t.Fatalf("GetDiffLimited failed: %v", err)
+		}
+		if !truncated || diff != full[:1000] {
+			t.Errorf("expected the first 1000 bytes, got %d bytes (truncated=%v)", len(diff), truncated)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		diff, truncated, err := GetRangeDiffLimited(repo.Dir, "HEAD~1..HEAD", 1000)
+		if err != nil {
+			t.Fatalf("GetRangeDiffLimited failed: %v", err)
+		}
+		if !truncated || len(diff) != 1000 {
+			t.Errorf("expected 1000 bytes, got %d (truncated=%v)", len(diff), truncated)
+		}
+	})
+
+	t.Run("bad ref", func(t *testing.T) {
+		if _, _, err := GetDiffLimited(repo.Dir, "nonexistent", 1000); err == nil {
+			t.Error("expected error for unknown ref")
+		}
+	})
+}
+
 func TestIsWorkingTreeClean(t *testing.T) {
 	t.Run("clean tree returns true", func(t *testing.T) {
 		repo := NewTestRepo(t)
@@ -1121,6 +1234,23 @@ func TestIsWorkingTreeClean(t *testing.T) {
 	})
 }
 
+func TestAmendCommitMessage(t *testing.T) {
+	repo := NewTestRepo(t)
+	repo.CommitFile("a.txt", "a", "wip")
+	repo.WriteFile("a.txt", "staged change")
+	repo.Run("add", "a.txt")
+
+	if err := AmendCommitMessage(repo.Dir, "Add a.txt\n\nExplain why."); err != nil {
+		t.Fatalf("AmendCommitMessage: %v", err)
+	}
+	if msg := repo.Run("log", "-1", "--format=%B"); msg != "Add a.txt\n\nExplain why." {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if staged := repo.Run("diff", "--cached", "--name-only"); staged != "a.txt" {
+		t.Errorf("expected the staged change to stay staged, got %q", staged)
+	}
+}
+
 func TestResetWorkingTree(t *testing.T) {
 	t.Run("resets modified files", func(t *testing.T) {
 		repo := NewTestRepo(t)
@@ -1339,3 +1469,224 @@ func TestIsAncestor(t *testing.T) {
 		}
 	})
 }
+
+func TestGetPreviousTag(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CommitFile("a.txt", "a", "first")
+
+	t.Run("no earlier tag", func(t *testing.T) {
+		r.Run("tag", "v1.0.0")
+		prev, err := GetPreviousTag(r.Dir, "v1.0.0")
+		if err != nil {
+			t.Fatalf("GetPreviousTag: %v", err)
+		}
+		if prev != "" {
+			t.Errorf("expected no previous tag, got %q", prev)
+		}
+	})
+
+	t.Run("finds preceding tag", func(t *testing.T) {
+		r.CommitFile("b.txt", "b", "second")
+		r.CommitFile("c.txt", "c", "third")
+		r.Run("tag", "v1.1.0")
+		prev, err := GetPreviousTag(r.Dir, "v1.1.0")
+		if err != nil {
+			t.Fatalf("GetPreviousTag: %v", err)
+		}
+		if prev != "v1.0.0" {
+			t.Errorf("expected v1.0.0, got %q", prev)
+		}
+	})
+}
+
+func TestGetRootCommit(t *testing.T) {
+	r := NewTestRepo(t)
+
+	root, err := GetRootCommit(r.Dir)
+	if err != nil {
+		t.Fatalf("GetRootCommit on empty repo: %v", err)
+	}
+	if root != "" {
+		t.Errorf("expected empty root for repo with no commits, got %q", root)
+	}
+
+	r.CommitFile("a.txt", "a", "first")
+	first := r.HeadSHA()
+	r.CommitFile("b.txt", "b", "second")
+
+	root, err = GetRootCommit(r.Dir)
+	if err != nil {
+		t.Fatalf("GetRootCommit: %v", err)
+	}
+	if root != first {
+		t.Errorf("expected root %s, got %s", first, root)
+	}
+}
+
+func TestGetMainRepoRootAndHead(t *testing.T) {
+	repo := NewTestRepo(t)
+	repo.CommitFile("file.txt", "content", "initial")
+	head := repo.HeadSHA()
+
+	check := func(t *testing.T, dir, wantHead string) {
+		t.Helper()
+		root, got, err := GetMainRepoRootAndHead(dir)
+		if err != nil {
+			t.Fatalf("GetMainRepoRootAndHead failed: %v", err)
+		}
+		wantRoot, err := GetMainRepoRoot(dir)
+		if err != nil {
+			t.Fatalf("GetMainRepoRoot failed: %v", err)
+		}
+		if root != wantRoot {
+			t.Errorf("root = %s, want %s", root, wantRoot)
+		}
+		if got != wantHead {
+			t.Errorf("head = %s, want %s", got, wantHead)
+		}
+	}
+
+	t.Run("repo root", func(t *testing.T) {
+		check(t, repo.Dir, head)
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		sub := filepath.Join(repo.Dir, "sub")
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		check(t, sub, head)
+	})
+
+	t.Run("worktree", func(t *testing.T) {
+		wt := repo.AddWorktree("statusline-branch")
+		wt.CommitFile("wt.txt", "wt", "worktree commit")
+		check(t, wt.Dir, wt.HeadSHA())
+	})
+
+	t.Run("not a repo", func(t *testing.T) {
+		if _, _, err := GetMainRepoRootAndHead(t.TempDir()); err == nil {
+			t.Error("expected error outside a repository")
+		}
+	})
+}
+
+func TestListFiles(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CommitFile("a.txt", "a", "first")
+	first := r.HeadSHA()
+	r.CommitFile("dir/b file.go", "package b", "second")
+
+	files, err := ListFiles(r.Dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(files) != 2 || files[0] != "a.txt" || files[1] != "dir/b file.go" {
+		t.Errorf("unexpected files at HEAD: %q", files)
+	}
+
+	files, err = ListFiles(r.Dir, first)
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(files) != 1 || files[0] != "a.txt" {
+		t.Errorf("unexpected files at first commit: %q", files)
+	}
+
+	if _, err := ListFiles(r.Dir, "no-such-ref"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
+
+func TestGetChurn(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CommitFile("a.go", "one\n", "first")
+	r.CommitFile("a.go", "one\ntwo\n", "second")
+	r.CommitFile("dir/b file.go", "b\n", "third")
+	r.CommitFile("go.sum", "sum\n", "lock file")
+
+	churn, err := GetChurn(r.Dir, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetChurn: %v", err)
+	}
+	want := []FileChurn{
+		{Path: "a.go", Commits: 2, LinesChanged: 2},
+		{Path: "dir/b file.go", Commits: 1, LinesChanged: 1},
+	}
+	if len(churn) != len(want) {
+		t.Fatalf("GetChurn() = %+v, want %+v", churn, want)
+	}
+	for i := range want {
+		if churn[i] != want[i] {
+			t.Errorf("churn[%d] = %+v, want %+v", i, churn[i], want[i])
+		}
+	}
+
+	churn, err = GetChurn(r.Dir, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetChurn: %v", err)
+	}
+	if len(churn) != 0 {
+		t.Errorf("expected no churn in the future, got %+v", churn)
+	}
+}
+
+func TestGetCommitChurn(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CommitFile("a.go", "one\n", "first")
+	r.WriteFile("a.go", "one\ntwo\nthree\n")
+	r.WriteFile("b.go", "b\n")
+	r.WriteFile("go.sum", "sum\n")
+	r.CommitAll("second")
+
+	churn, err := GetCommitChurn(r.Dir, r.HeadSHA())
+	if err != nil {
+		t.Fatalf("GetCommitChurn: %v", err)
+	}
+	want := []FileChurn{
+		{Path: "a.go", Commits: 1, LinesChanged: 2},
+		{Path: "b.go", Commits: 1, LinesChanged: 1},
+	}
+	if len(churn) != len(want) {
+		t.Fatalf("GetCommitChurn() = %+v, want %+v", churn, want)
+	}
+	for i := range want {
+		if churn[i] != want[i] {
+			t.Errorf("churn[%d] = %+v, want %+v", i, churn[i], want[i])
+		}
+	}
+}
+
+func TestPatchID(t *testing.T) {
+	r := NewTestRepo(t)
+	r.Run("symbolic-ref", "HEAD", "refs/heads/main")
+	r.CommitFile("base.txt", "base\n", "base")
+	baseSHA := r.HeadSHA()
+	r.CommitFile("fix.txt", "fix\n", "fix")
+	fixSHA := r.HeadSHA()
+
+	r.Run("checkout", "-b", "release", baseSHA)
+	r.CommitFile("other.txt", "other\n", "other")
+	r.Run("cherry-pick", fixSHA)
+	pickSHA := r.HeadSHA()
+
+	fixID, err := PatchID(r.Dir, fixSHA)
+	if err != nil || fixID == "" {
+		t.Fatalf("PatchID(fix) = %q, %v", fixID, err)
+	}
+	pickID, err := PatchID(r.Dir, pickSHA)
+	if err != nil {
+		t.Fatalf("PatchID(pick): %v", err)
+	}
+	if pickID != fixID {
+		t.Errorf("cherry-pick patch-id %q, want %q", pickID, fixID)
+	}
+	if baseID, _ := PatchID(r.Dir, baseSHA); baseID == fixID {
+		t.Error("expected different commits to have different patch-ids")
+	}
+
+	r.Run("commit", "--allow-empty", "-m", "empty")
+	if id, err := PatchID(r.Dir, r.HeadSHA()); err != nil || id != "" {
+		t.Errorf("PatchID(empty) = %q, %v; want empty", id, err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package i18n translates roborev's own messages in the CLI, the TUI and
+// shared review pages. Messages are written in English in the code, which
+// is also the lookup key in each language's catalog, so an untranslated
+// message falls back to English.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultLanguage is the language messages are written in
+const DefaultLanguage = "en"
+
+//go:embed locales/*.toml
+var localesFS embed.FS
+
+var (
+	catalogs     map[string]map[string]string
+	catalogsOnce sync.Once
+
+	mu       sync.RWMutex
+	language = DefaultLanguage
+)
+
+// loadCatalogs parses the embedded catalogs, one per language, named
+// after the language's ISO 639-1 code
+func loadCatalogs() map[string]map[string]string {
+	catalogsOnce.Do(func() {
+		catalogs = make(map[string]map[string]string)
+		entries, err := localesFS.ReadDir("locales")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: read catalogs: %v", err))
+		}
+		for _, e := range entries {
+			data, err := localesFS.ReadFile(path.Join("locales", e.Name()))
+			if err != nil {
+				panic(fmt.Sprintf("i18n: read %s: %v", e.Name(), err))
+			}
+			var messages map[string]string
+			if err := toml.Unmarshal(data, &messages); err != nil {
+				panic(fmt.Sprintf("i18n: parse %s: %v", e.Name(), err))
+			}
+			catalogs[strings.TrimSuffix(e.Name(), ".toml")] = messages
+		}
+	})
+	return catalogs
+}
+
+// Languages returns the languages messages can be shown in, English first
+func Languages() []string {
+	langs := []string{DefaultLanguage}
+	for lang := range loadCatalogs() {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// Supported returns the supported language a locale or language tag such
+// as "de_DE.UTF-8" or "pt-BR" stands for, or "" if there is none
+func Supported(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "_-.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == DefaultLanguage {
+		return tag
+	}
+	if _, ok := loadCatalogs()[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+// Detect picks the language of messages: the --lang flag, then the
+// ROBOREV_LANG environment variable, then the configured language, then
+// the locale (LC_ALL, LC_MESSAGES, LANG). Languages without a catalog are
+// skipped, and English is the default.
+func Detect(flag, configured string) string {
+	candidates := []string{flag, os.Getenv("ROBOREV_LANG"), configured,
+		os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, c := range candidates {
+		if lang := Supported(c); lang != "" {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// FromAcceptLanguage returns the first supported language of an HTTP
+// Accept-Language header, or "" if none is. Quality values are ignored:
+// browsers list languages in order of preference.
+func FromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if lang := Supported(tag); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// SetLanguage sets the language T translates messages into
+func SetLanguage(lang string) {
+	if lang = Supported(lang); lang == "" {
+		lang = DefaultLanguage
+	}
+	mu.Lock()
+	language = lang
+	mu.Unlock()
+}
+
+// Language returns the language T translates messages into
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T translates an English message into the current language, formatting
+// it with args like fmt.Sprintf when there are any
+func T(msg string, args ...any) string {
+	return For(Language()).T(msg, args...)
+}
+
+// Printer translates messages into one language, for output whose reader
+// may not share the process's language, such as a web page
+type Printer string
+
+// For returns the printer of a language
+func For(lang string) Printer {
+	return Printer(lang)
+}
+
+// T translates an English message, formatting it with args like
+// fmt.Sprintf when there are any
+func (p Printer) T(msg string, args ...any) string {
+	if translated, ok := loadCatalogs()[string(p)][msg]; ok && translated != "" {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,162 @@
+package i18n
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSupported(t *testing.T) {
+	tests := map[string]string{
+		"de":          "de",
+		"de_DE.UTF-8": "de",
+		"es-MX":       "es",
+		"EN_us":       "en",
+		"C":           "",
+		"xx":          "",
+		"":            "",
+	}
+	for tag, want := range tests {
+		if got := Supported(tag); got != want {
+			t.Errorf("Supported(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, v := range []string{"ROBOREV_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(v, "")
+	}
+	if got := Detect("", ""); got != DefaultLanguage {
+		t.Errorf("Detect with nothing set = %q, want %q", got, DefaultLanguage)
+	}
+
+	t.Setenv("LANG", "es_ES.UTF-8")
+	if got := Detect("", ""); got != "es" {
+		t.Errorf("Detect from LANG = %q, want es", got)
+	}
+	if got := Detect("", "de"); got != "de" {
+		t.Errorf("configured language should win over the locale, got %q", got)
+	}
+	t.Setenv("ROBOREV_LANG", "en")
+	if got := Detect("", "de"); got != "en" {
+		t.Errorf("ROBOREV_LANG should win over config, got %q", got)
+	}
+	if got := Detect("de", "es"); got != "de" {
+		t.Errorf("--lang should win over everything, got %q", got)
+	}
+	if got := Detect("xx", ""); got != "en" {
+		t.Errorf("unsupported --lang should be skipped, got %q", got)
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"de-DE,de;q=0.9,en;q=0.8": "de",
+		"fr-FR, es;q=0.7":         "es",
+		"fr, it":                  "",
+		"":                        "",
+	}
+	for header, want := range tests {
+		if got := FromAcceptLanguage(header); got != want {
+			t.Errorf("FromAcceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestPrinterT(t *testing.T) {
+	if got := For("de").T("Review of %s", "abc123"); got != "Review von abc123" {
+		t.Errorf("German translation = %q", got)
+	}
+	if got := For("de").T("No such message %d", 3); got != "No such message 3" {
+		t.Errorf("untranslated message should fall back to English, got %q", got)
+	}
+	if got := For("en").T("Loading..."); got != "Loading..." {
+		t.Errorf("English = %q", got)
+	}
+	// Messages without args are not run through Sprintf
+	if got := For("en").T("100%"); got != "100%" {
+		t.Errorf("message without args = %q", got)
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	t.Cleanup(func() { SetLanguage(DefaultLanguage) })
+
+	SetLanguage("es_AR")
+	if Language() != "es" {
+		t.Errorf("Language() = %q, want es", Language())
+	}
+	if got := T("Passed"); got != "Aprobado" {
+		t.Errorf("T(Passed) = %q", got)
+	}
+	SetLanguage("xx")
+	if Language() != DefaultLanguage {
+		t.Errorf("unsupported language should reset to English, got %q", Language())
+	}
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for lang, messages := range loadCatalogs() {
+		for msg, translated := range messages {
+			want := verbRe.FindAllString(msg, -1)
+			got := verbRe.FindAllString(translated, -1)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q has verbs %v, its translation %q has %v", lang, msg, want, translated, got)
+			}
+		}
+	}
+}
+
+// messageRe matches the literal message of a call to T
+var messageRe = regexp.MustCompile(`\b(?:i18n|p)\.T\(("(?:[^"\\]|\\.)*")`)
+
+// TestCatalogsComplete checks that every message passed as a literal to
+// T in the code has a translation in every catalog
+func TestCatalogsComplete(t *testing.T) {
+	messages := map[string]string{} // Message -> where it was found
+	for _, root := range []string{"../../cmd", "../../internal"} {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, m := range messageRe.FindAllStringSubmatch(string(src), -1) {
+				msg, err := strconv.Unquote(m[1])
+				if err != nil {
+					t.Errorf("%s: cannot unquote %s: %v", path, m[1], err)
+					continue
+				}
+				messages[msg] = path
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk %s: %v", root, err)
+		}
+	}
+	if len(messages) == 0 {
+		t.Fatal("found no translatable messages")
+	}
+
+	for lang, catalog := range loadCatalogs() {
+		for msg, path := range messages {
+			if catalog[msg] == "" {
+				t.Errorf("%s: no translation of %q (%s)", lang, msg, path)
+			}
+		}
+	}
+}
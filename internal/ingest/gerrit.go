@@ -0,0 +1,314 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// Gerrit fetches the reviews of a project's changes from a Gerrit server's
+// REST API. With a User, requests are authenticated with the HTTP password.
+type Gerrit struct {
+	BaseURL  string
+	Project  string
+	User     string
+	Password string
+	Dir      string // Local clone, for commit subjects and authors
+	Client   *http.Client
+}
+
+// GerritOptions selects the changes whose reviews are fetched
+type GerritOptions struct {
+	Changes []string  // Change numbers or IDs; default the most recently updated
+	Limit   int       // How many recent changes to fetch without Changes (default 50)
+	Since   time.Time // Skip reviews submitted before this
+}
+
+// gerritTime is the timestamp format of the Gerrit REST API
+type gerritTime struct{ time.Time }
+
+func (t *gerritTime) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	parsed, err := time.Parse("2006-01-02 15:04:05.000000000", s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+type gerritAccount struct {
+	ID       int64    `json:"_account_id"`
+	Name     string   `json:"name"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Tags     []string `json:"tags"`
+}
+
+func (a gerritAccount) display() string {
+	switch {
+	case a.Username != "":
+		return a.Username
+	case a.Name != "":
+		return a.Name
+	case a.Email != "":
+		return a.Email
+	}
+	return strconv.FormatInt(a.ID, 10)
+}
+
+type gerritChange struct {
+	ID        string                    `json:"id"`
+	Number    int                       `json:"_number"`
+	Owner     gerritAccount             `json:"owner"`
+	Messages  []gerritMessage           `json:"messages"`
+	Revisions map[string]gerritRevision `json:"revisions"`
+}
+
+type gerritRevision struct {
+	Number int `json:"_number"`
+}
+
+type gerritMessage struct {
+	ID       string        `json:"id"`
+	Author   gerritAccount `json:"author"`
+	Date     gerritTime    `json:"date"`
+	Message  string        `json:"message"`
+	Revision int           `json:"_revision_number"`
+}
+
+type gerritComment struct {
+	ID        string        `json:"id"`
+	Author    gerritAccount `json:"author"`
+	PatchSet  int           `json:"patch_set"`
+	Line      int           `json:"line"`
+	Message   string        `json:"message"`
+	Updated   gerritTime    `json:"updated"`
+	InReplyTo string        `json:"in_reply_to"`
+	path      string
+}
+
+// codeReviewVote matches the Code-Review vote in a change message such as
+// "Patch Set 2: Code-Review-1"
+var codeReviewVote = regexp.MustCompile(`Code-Review([+-]\d)`)
+
+// commentCount matches the "(3 comments)" line of a change message
+var commentCount = regexp.MustCompile(`^\(\d+ comments?\)$`)
+
+// Reviews returns the human reviews of the selected changes, oldest first.
+// Each message by someone other than the change owner that votes
+// Code-Review or publishes comments is a review; its comments are those the
+// reviewer published with it. Replies join the review that started their
+// thread.
+func (g *Gerrit) Reviews(ctx context.Context, opts GerritOptions) ([]storage.HumanReview, error) {
+	if g.BaseURL == "" {
+		return nil, fmt.Errorf("gerrit URL is required")
+	}
+	var changes []gerritChange
+	if len(opts.Changes) > 0 {
+		for _, id := range opts.Changes {
+			var c gerritChange
+			if err := g.get(ctx, "changes/"+url.PathEscape(id)+"?o=MESSAGES&o=ALL_REVISIONS", &c); err != nil {
+				return nil, err
+			}
+			changes = append(changes, c)
+		}
+	} else {
+		if g.Project == "" {
+			return nil, fmt.Errorf("gerrit project is required without changes")
+		}
+		limit := opts.Limit
+		if limit <= 0 {
+			limit = 50
+		}
+		q := url.Values{"q": {"project:" + g.Project}, "n": {strconv.Itoa(limit)}, "o": {"MESSAGES", "ALL_REVISIONS"}}
+		if err := g.get(ctx, "changes/?"+q.Encode(), &changes); err != nil {
+			return nil, err
+		}
+	}
+
+	var all []storage.HumanReview
+	for _, c := range changes {
+		reviews, err := g.changeReviews(ctx, c, opts.Since)
+		if err != nil {
+			return nil, fmt.Errorf("change %d: %w", c.Number, err)
+		}
+		all = append(all, reviews...)
+	}
+	sortReviews(all)
+	return all, nil
+}
+
+func (g *Gerrit) changeReviews(ctx context.Context, c gerritChange, since time.Time) ([]storage.HumanReview, error) {
+	var byPath map[string][]gerritComment
+	if err := g.get(ctx, "changes/"+url.PathEscape(c.ID)+"/comments", &byPath); err != nil {
+		return nil, err
+	}
+	shas := make(map[int]string)
+	for sha, rev := range c.Revisions {
+		shas[rev.Number] = sha
+	}
+
+	// Comments are published with a message of the same author and time
+	type publish struct {
+		author int64
+		at     time.Time
+	}
+	published := make(map[publish][]gerritComment)
+	var replies []gerritComment
+	for path, comments := range byPath {
+		for _, cm := range comments {
+			cm.path = path
+			if cm.InReplyTo != "" {
+				replies = append(replies, cm)
+				continue
+			}
+			key := publish{cm.Author.ID, cm.Updated.Time}
+			published[key] = append(published[key], cm)
+		}
+	}
+
+	var reviews []*storage.HumanReview
+	threadReview := make(map[string]*storage.HumanReview)
+	for _, m := range c.Messages {
+		if m.Author.ID == 0 || m.Author.ID == c.Owner.ID || isGerritBot(m.Author) {
+			continue
+		}
+		comments := published[publish{m.Author.ID, m.Date.Time}]
+		vote := codeReviewVote.FindStringSubmatch(m.Message)
+		if vote == nil && len(comments) == 0 {
+			continue
+		}
+		state := storage.HumanCommented
+		if vote != nil {
+			if n, _ := strconv.Atoi(vote[1]); n > 0 {
+				state = storage.HumanApproved
+			} else if n < 0 {
+				state = storage.HumanChangesRequested
+			}
+		}
+		r := &storage.HumanReview{
+			Source:      "gerrit",
+			ExternalID:  c.ID + "/" + m.ID,
+			URL:         fmt.Sprintf("%s/c/%d/%d", strings.TrimRight(g.BaseURL, "/"), c.Number, m.Revision),
+			CommitSHA:   shas[m.Revision],
+			Reviewer:    m.Author.display(),
+			State:       state,
+			Body:        gerritMessageBody(m.Message),
+			SubmittedAt: m.Date.Time,
+		}
+		for _, cm := range comments {
+			r.Comments = append(r.Comments, storage.HumanComment{
+				ExternalID: cm.ID, Author: cm.Author.display(),
+				Path: cm.path, Line: cm.Line, Body: cm.Message, CreatedAt: cm.Updated.Time,
+			})
+			threadReview[cm.ID] = r
+		}
+		reviews = append(reviews, r)
+	}
+
+	// Follow each reply up its thread to the review that started it
+	parent := make(map[string]string)
+	for _, cm := range replies {
+		parent[cm.ID] = cm.InReplyTo
+	}
+	for _, cm := range replies {
+		root := cm.InReplyTo
+		for i := 0; i < len(parent) && parent[root] != ""; i++ {
+			root = parent[root]
+		}
+		if r, ok := threadReview[root]; ok && !isGerritBot(cm.Author) {
+			r.Replies = append(r.Replies, storage.HumanComment{
+				ExternalID: cm.ID, Author: cm.Author.display(),
+				Path: cm.path, Body: cm.Message, CreatedAt: cm.Updated.Time,
+			})
+		}
+	}
+
+	var out []storage.HumanReview
+	for _, r := range reviews {
+		if r.SubmittedAt.Before(since) || r.CommitSHA == "" {
+			continue
+		}
+		fillCommit(g.Dir, r)
+		out = append(out, *r)
+	}
+	return out, nil
+}
+
+// gerritMessageBody drops the "Patch Set N: <votes>" and "(N comments)"
+// lines Gerrit adds to a review message
+func gerritMessageBody(message string) string {
+	var lines []string
+	for i, line := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && strings.HasPrefix(trimmed, "Patch Set ") {
+			continue
+		}
+		if commentCount.MatchString(trimmed) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isGerritBot reports whether an account is a service user such as CI
+func isGerritBot(a gerritAccount) bool {
+	for _, tag := range a.Tags {
+		if tag == "SERVICE_USER" {
+			return true
+		}
+	}
+	return false
+}
+
+// get fetches a REST API endpoint into v, stripping the prefix Gerrit adds
+// to JSON responses against cross-site script inclusion
+func (g *Gerrit) get(ctx context.Context, endpoint string, v any) error {
+	base := strings.TrimRight(g.BaseURL, "/") + "/"
+	if g.User != "" {
+		base += "a/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if g.User != "" {
+		req.SetBasicAuth(g.User, g.Password)
+	}
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gerrit %s: %s: %s", endpoint, resp.Status, strings.TrimSpace(string(body[:min(len(body), 512)])))
+	}
+	body = bytes.TrimPrefix(body, []byte(")]}'"))
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode gerrit %s: %w", endpoint, err)
+	}
+	return nil
+}
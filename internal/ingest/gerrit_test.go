@@ -0,0 +1,69 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestGerritReviews(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(")]}'\n"))
+		switch r.URL.Path {
+		case "/a/changes/":
+			if r.URL.Query().Get("q") != "project:tools" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{
+				"id": "tools~main~I1", "_number": 42, "owner": {"_account_id": 1, "username": "owner"},
+				"revisions": {"aaa111": {"_number": 1}, "bbb222": {"_number": 2}},
+				"messages": [
+					{"id": "m1", "author": {"_account_id": 1, "username": "owner"}, "date": "2026-03-01 09:00:00.000000000", "message": "Uploaded patch set 1.", "_revision_number": 1},
+					{"id": "m2", "author": {"_account_id": 2, "username": "carol"}, "date": "2026-03-01 10:00:00.000000000", "message": "Patch Set 1: Code-Review-1\n\n(1 comment)\n\nPlease handle errors", "_revision_number": 1},
+					{"id": "m3", "author": {"_account_id": 3, "username": "jenkins", "tags": ["SERVICE_USER"]}, "date": "2026-03-01 10:30:00.000000000", "message": "Patch Set 1: Verified+1", "_revision_number": 1},
+					{"id": "m4", "author": {"_account_id": 2, "username": "carol"}, "date": "2026-03-02 10:00:00.000000000", "message": "Patch Set 2: Code-Review+2", "_revision_number": 2}
+				]
+			}]`))
+		case "/a/changes/tools~main~I1/comments":
+			w.Write([]byte(`{"lib/io.go": [
+				{"id": "c1", "author": {"_account_id": 2, "username": "carol"}, "patch_set": 1, "line": 8, "message": "Unchecked error", "updated": "2026-03-01 10:00:00.000000000"},
+				{"id": "c2", "author": {"_account_id": 1, "username": "owner"}, "patch_set": 1, "line": 8, "message": "Done", "updated": "2026-03-01 11:00:00.000000000", "in_reply_to": "c1"}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g := &Gerrit{BaseURL: srv.URL, Project: "tools", User: "me", Password: "secret"}
+	reviews, err := g.Reviews(context.Background(), GerritOptions{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("expected carol's two reviews, got %+v", reviews)
+	}
+	r := reviews[0]
+	if r.Source != "gerrit" || r.Reviewer != "carol" || r.State != storage.HumanChangesRequested || r.CommitSHA != "aaa111" {
+		t.Errorf("unexpected review %+v", r)
+	}
+	if r.Body != "Please handle errors" {
+		t.Errorf("expected the vote and comment count lines dropped, got %q", r.Body)
+	}
+	if len(r.Comments) != 1 || r.Comments[0].Path != "lib/io.go" || r.Comments[0].Line != 8 {
+		t.Errorf("unexpected comments %+v", r.Comments)
+	}
+	if len(r.Replies) != 1 || r.Replies[0].Author != "owner" {
+		t.Errorf("expected the owner's reply, got %+v", r.Replies)
+	}
+	if reviews[1].State != storage.HumanApproved || reviews[1].CommitSHA != "bbb222" {
+		t.Errorf("unexpected approval %+v", reviews[1])
+	}
+}
@@ -0,0 +1,242 @@
+// Package ingest fetches historical human code reviews from GitHub pull
+// requests and Gerrit changes, to be imported next to agent reviews.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// GHRunner runs gh with args in dir and returns its standard output
+type GHRunner func(ctx context.Context, dir string, args ...string) ([]byte, error)
+
+// GitHub fetches pull request reviews of the repo cloned at Dir with the gh
+// CLI, which resolves the GitHub repo from the clone's remote
+type GitHub struct {
+	Dir string
+	Run GHRunner // Defaults to running the gh CLI
+}
+
+// GitHubOptions selects the pull requests whose reviews are fetched
+type GitHubOptions struct {
+	PRs   []int     // Pull requests to fetch; default the most recently updated
+	Limit int       // How many recent pull requests to fetch without PRs (default 50)
+	Since time.Time // Skip reviews submitted before this
+}
+
+type ghUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+type ghReview struct {
+	ID          int64     `json:"id"`
+	User        ghUser    `json:"user"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	CommitID    string    `json:"commit_id"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	HTMLURL     string    `json:"html_url"`
+}
+
+type ghComment struct {
+	ID           int64     `json:"id"`
+	ReviewID     int64     `json:"pull_request_review_id"`
+	InReplyToID  int64     `json:"in_reply_to_id"`
+	User         ghUser    `json:"user"`
+	Path         string    `json:"path"`
+	Line         int       `json:"line"`
+	OriginalLine int       `json:"original_line"`
+	Body         string    `json:"body"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Reviews returns the human reviews of the selected pull requests, oldest
+// first. Reviews by bots, pending reviews and reviews that only hold
+// replies to other reviews' comments are left out; replies are attached to
+// the review that started the thread.
+func (g *GitHub) Reviews(ctx context.Context, opts GitHubOptions) ([]storage.HumanReview, error) {
+	prs := opts.PRs
+	if len(prs) == 0 {
+		limit := opts.Limit
+		if limit <= 0 {
+			limit = 50
+		}
+		out, err := g.gh(ctx, "pr", "list", "--state", "all", "--limit", strconv.Itoa(limit), "--json", "number", "--jq", ".[].number")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range strings.Fields(string(out)) {
+			if n, err := strconv.Atoi(f); err == nil {
+				prs = append(prs, n)
+			}
+		}
+	}
+
+	var all []storage.HumanReview
+	for _, pr := range prs {
+		reviews, err := g.prReviews(ctx, pr, opts.Since)
+		if err != nil {
+			return nil, fmt.Errorf("pull request #%d: %w", pr, err)
+		}
+		all = append(all, reviews...)
+	}
+	sortReviews(all)
+	return all, nil
+}
+
+func (g *GitHub) prReviews(ctx context.Context, pr int, since time.Time) ([]storage.HumanReview, error) {
+	var reviews []ghReview
+	if err := g.list(ctx, fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/reviews?per_page=100", pr), &reviews); err != nil {
+		return nil, err
+	}
+	var comments []ghComment
+	if err := g.list(ctx, fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/comments?per_page=100", pr), &comments); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*storage.HumanReview)
+	var order []int64
+	for _, r := range reviews {
+		if r.User.Type == "Bot" || r.State == "PENDING" {
+			continue
+		}
+		state := storage.HumanCommented
+		switch r.State {
+		case "APPROVED":
+			state = storage.HumanApproved
+		case "CHANGES_REQUESTED":
+			state = storage.HumanChangesRequested
+		}
+		byID[r.ID] = &storage.HumanReview{
+			Source:      "github",
+			ExternalID:  strconv.FormatInt(r.ID, 10),
+			URL:         r.HTMLURL,
+			CommitSHA:   r.CommitID,
+			Reviewer:    r.User.Login,
+			State:       state,
+			Body:        r.Body,
+			SubmittedAt: r.SubmittedAt,
+		}
+		order = append(order, r.ID)
+	}
+
+	// Thread roots are line comments of their review; replies join the
+	// review of their thread's root
+	threadReview := make(map[int64]int64)
+	for _, c := range comments {
+		if c.InReplyToID != 0 {
+			continue
+		}
+		threadReview[c.ID] = c.ReviewID
+		if r, ok := byID[c.ReviewID]; ok {
+			line := c.Line
+			if line == 0 {
+				line = c.OriginalLine
+			}
+			r.Comments = append(r.Comments, storage.HumanComment{
+				ExternalID: strconv.FormatInt(c.ID, 10), Author: c.User.Login,
+				Path: c.Path, Line: line, Body: c.Body, CreatedAt: c.CreatedAt,
+			})
+		}
+	}
+	for _, c := range comments {
+		if c.InReplyToID == 0 || c.User.Type == "Bot" {
+			continue
+		}
+		if r, ok := byID[threadReview[c.InReplyToID]]; ok {
+			r.Replies = append(r.Replies, storage.HumanComment{
+				ExternalID: strconv.FormatInt(c.ID, 10), Author: c.User.Login,
+				Path: c.Path, Body: c.Body, CreatedAt: c.CreatedAt,
+			})
+		}
+	}
+
+	var out []storage.HumanReview
+	for _, id := range order {
+		r := byID[id]
+		if r.SubmittedAt.Before(since) || r.CommitSHA == "" {
+			continue
+		}
+		if r.State == storage.HumanCommented && strings.TrimSpace(r.Body) == "" && len(r.Comments) == 0 {
+			continue // Holds only replies, which joined their thread's review
+		}
+		fillCommit(g.Dir, r)
+		out = append(out, *r)
+	}
+	return out, nil
+}
+
+// list fetches every page of a gh api endpoint returning an array into v
+func (g *GitHub) list(ctx context.Context, endpoint string, v any) error {
+	out, err := g.gh(ctx, "api", endpoint, "--paginate", "--jq", ".[]")
+	if err != nil {
+		return err
+	}
+	var items []json.RawMessage
+	dec := json.NewDecoder(strings.NewReader(string(out)))
+	for {
+		var item json.RawMessage
+		if err := dec.Decode(&item); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("decode %s: %w", endpoint, err)
+		}
+		items = append(items, item)
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (g *GitHub) gh(ctx context.Context, args ...string) ([]byte, error) {
+	run := g.Run
+	if run == nil {
+		run = runGH
+	}
+	return run(ctx, g.Dir, args...)
+}
+
+func runGH(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("gh %s: %s", strings.Join(args[:min(2, len(args))], " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("gh: %w", err)
+	}
+	return out, nil
+}
+
+// fillCommit sets the subject and author of a review's commit when the
+// commit is in the local clone
+func fillCommit(dir string, r *storage.HumanReview) {
+	if dir == "" {
+		return
+	}
+	if info, err := git.GetCommitInfo(dir, r.CommitSHA); err == nil {
+		r.Subject, r.Author = info.Subject, info.Author
+	}
+}
+
+// sortReviews orders reviews by when they were submitted
+func sortReviews(reviews []storage.HumanReview) {
+	slices.SortStableFunc(reviews, func(a, b storage.HumanReview) int {
+		return a.SubmittedAt.Compare(b.SubmittedAt)
+	})
+}
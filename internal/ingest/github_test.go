@@ -0,0 +1,65 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestGitHubReviews(t *testing.T) {
+	responses := map[string]string{
+		"pr list": "7\n",
+		"api repos/{owner}/{repo}/pulls/7/reviews?per_page=100": `
+{"id": 1, "user": {"login": "alice", "type": "User"}, "body": "Needs work", "state": "CHANGES_REQUESTED", "commit_id": "abc123", "submitted_at": "2026-03-01T10:00:00Z", "html_url": "https://github.com/o/r/pull/7#pullrequestreview-1"}
+{"id": 2, "user": {"login": "bob", "type": "User"}, "body": "", "state": "COMMENTED", "commit_id": "abc123", "submitted_at": "2026-03-01T11:00:00Z"}
+{"id": 3, "user": {"login": "ci-bot", "type": "Bot"}, "body": "lgtm", "state": "APPROVED", "commit_id": "abc123", "submitted_at": "2026-03-01T12:00:00Z"}
+{"id": 4, "user": {"login": "alice", "type": "User"}, "body": "", "state": "APPROVED", "commit_id": "def456", "submitted_at": "2026-03-02T10:00:00Z"}`,
+		"api repos/{owner}/{repo}/pulls/7/comments?per_page=100": `
+{"id": 10, "pull_request_review_id": 1, "user": {"login": "alice", "type": "User"}, "path": "main.go", "line": 12, "body": "This leaks the file handle", "created_at": "2026-03-01T10:00:00Z"}
+{"id": 11, "pull_request_review_id": 2, "in_reply_to_id": 10, "user": {"login": "bob", "type": "User"}, "path": "main.go", "body": "Fixed in def456", "created_at": "2026-03-01T11:00:00Z"}`,
+	}
+	var calls []string
+	gh := &GitHub{Run: func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+		key := strings.Join(args[:2], " ")
+		if args[0] == "api" {
+			key = "api " + args[1]
+		}
+		calls = append(calls, key)
+		out, ok := responses[key]
+		if !ok {
+			return nil, fmt.Errorf("unexpected gh %v", args)
+		}
+		return []byte(out), nil
+	}}
+
+	reviews, err := gh.Reviews(context.Background(), GitHubOptions{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("expected alice's two reviews (bob's reply-only and the bot's left out), got %+v", reviews)
+	}
+	r := reviews[0]
+	if r.Source != "github" || r.ExternalID != "1" || r.Reviewer != "alice" || r.State != storage.HumanChangesRequested || r.CommitSHA != "abc123" {
+		t.Errorf("unexpected review %+v", r)
+	}
+	if len(r.Comments) != 1 || r.Comments[0].Path != "main.go" || r.Comments[0].Line != 12 {
+		t.Errorf("expected the line comment, got %+v", r.Comments)
+	}
+	if len(r.Replies) != 1 || r.Replies[0].Author != "bob" || r.Replies[0].ExternalID != "11" {
+		t.Errorf("expected bob's reply to join alice's review, got %+v", r.Replies)
+	}
+	if reviews[1].State != storage.HumanApproved || reviews[1].CommitSHA != "def456" {
+		t.Errorf("unexpected approval %+v", reviews[1])
+	}
+
+	// Reviews before Since are skipped
+	reviews, err = gh.Reviews(context.Background(), GitHubOptions{PRs: []int{7}, Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)})
+	if err != nil || len(reviews) != 1 || reviews[0].ExternalID != "4" {
+		t.Errorf("expected only the later approval, got %+v, %v", reviews, err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package jj supports repositories managed by Jujutsu (jj), which stores
+// its commits in git. Reviews stay keyed by git commit; jj adds change IDs,
+// which survive rewrites (amend, rebase, squash) and so tie the reviews of
+// every version of a change together.
+package jj
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// Revision is a commit as jj sees it
+type Revision struct {
+	CommitID string // Git commit SHA
+	ChangeID string // Stable across rewrites of the change
+}
+
+// Root returns the root of the jj workspace containing path, or "" if path
+// is not in one
+func Root(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".jj")); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// IsColocated reports whether the jj workspace at root has a git working
+// copy alongside it, which roborev needs to run git commands
+func IsColocated(root string) bool {
+	_, err := os.Stat(filepath.Join(root, ".git"))
+	return err == nil
+}
+
+// NotColocatedError explains that the jj workspace at root cannot be reviewed
+func NotColocatedError(root string) error {
+	return fmt.Errorf("jj workspace %s is not colocated with git; roborev reviews colocated workspaces (jj git init --colocate)", root)
+}
+
+// Resolve resolves a jj revision (a change ID, commit ID, bookmark or
+// revset naming one commit) in the workspace at repoPath. A change ID
+// resolves to the change's current commit, however often it was rewritten.
+// The working copy is not snapshotted, so resolving never creates commits.
+func Resolve(repoPath, rev string) (Revision, error) {
+	out, err := jjLog(repoPath, rev, `commit_id ++ " " ++ change_id ++ "\n"`)
+	if err != nil {
+		return Revision{}, err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1 || lines[0] == "" {
+		// A divergent change has several visible commits
+		return Revision{}, fmt.Errorf("jj revision %q resolves to %d commits", rev, len(lines))
+	}
+	commitID, changeID, ok := strings.Cut(lines[0], " ")
+	if !ok {
+		return Revision{}, fmt.Errorf("unexpected jj log output %q", lines[0])
+	}
+	return Revision{CommitID: commitID, ChangeID: changeID}, nil
+}
+
+// ChangeID returns the change ID of a git commit, including commits hidden
+// by a later rewrite of their change
+func ChangeID(repoPath, sha string) (string, error) {
+	out, err := jjLog(repoPath, sha, `change_id ++ "\n"`)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(out)
+	if id == "" || strings.Contains(id, "\n") {
+		return "", fmt.Errorf("no single change for commit %s", sha)
+	}
+	return id, nil
+}
+
+// ResolveRef rewrites the jj revisions in a git ref (a commit, or a
+// "start..end" range whose start may end in "^") to git commit SHAs. Parts
+// git resolves itself are left as they are, as are refs outside jj
+// workspaces.
+func ResolveRef(repoPath, ref string) (string, error) {
+	if Root(repoPath) == "" {
+		return ref, nil
+	}
+	parts := strings.SplitN(ref, "..", 2)
+	for i, part := range parts {
+		base := strings.TrimSuffix(part, "^")
+		if base == "" {
+			continue
+		}
+		if _, err := git.ResolveSHA(repoPath, base); err == nil {
+			continue
+		}
+		rev, err := Resolve(repoPath, base)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = rev.CommitID + strings.TrimPrefix(part, base)
+	}
+	return strings.Join(parts, ".."), nil
+}
+
+// jjLog prints rev with template, one line per commit. The working copy
+// is not snapshotted, so this never changes the repository.
+func jjLog(repoPath, rev, template string) (string, error) {
+	cmd := exec.Command("jj", "--ignore-working-copy", "--no-pager", "--color=never",
+		"log", "--no-graph", "-r", rev, "-T", template)
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("jj log: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("jj log: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,108 @@
+package jj
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestRoot(t *testing.T) {
+	dir := t.TempDir()
+	if got := Root(dir); got != "" {
+		t.Errorf("expected no workspace, got %q", got)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".jj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := Root(sub); got != dir {
+		t.Errorf("Root(%q) = %q, want %q", sub, got, dir)
+	}
+	if IsColocated(dir) {
+		t.Error("expected workspace without .git not to be colocated")
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !IsColocated(dir) {
+		t.Error("expected workspace with .git to be colocated")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake jj is a shell script")
+	}
+	// Fake jj prints a commit per line of $JJ_OUT and records its arguments
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	restore := testutil.MockBinaryInPath(t, "jj", "#!/bin/sh\necho \"$@\" > "+argsFile+"\nprintf '%b' \"$JJ_OUT\"\n")
+	defer restore()
+
+	t.Setenv("JJ_OUT", "abc123 kxqpmnwz\\n")
+	rev, err := Resolve(dir, "kxqp")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if rev.CommitID != "abc123" || rev.ChangeID != "kxqpmnwz" {
+		t.Errorf("unexpected revision %+v", rev)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--ignore-working-copy") {
+		t.Errorf("expected jj not to snapshot the working copy, ran jj %s", args)
+	}
+
+	t.Setenv("JJ_OUT", "abc123 kxqpmnwz\\ndef456 kxqpmnwz\\n")
+	if _, err := Resolve(dir, "kxqp"); err == nil || !strings.Contains(err.Error(), "2 commits") {
+		t.Errorf("expected divergent change to fail, got %v", err)
+	}
+
+	t.Setenv("JJ_OUT", "kxqpmnwz\\n")
+	if id, err := ChangeID(dir, "abc123"); err != nil || id != "kxqpmnwz" {
+		t.Errorf("ChangeID = %q, %v", id, err)
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake jj is a shell script")
+	}
+	repo := testutil.NewTestRepoWithCommit(t)
+	head, err := git.ResolveSHA(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside jj workspaces refs are untouched
+	if got, err := ResolveRef(repo.Root, "kxqp"); err != nil || got != "kxqp" {
+		t.Errorf("ResolveRef outside jj = %q, %v", got, err)
+	}
+
+	if err := os.Mkdir(filepath.Join(repo.Root, ".jj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	restore := testutil.MockBinaryInPath(t, "jj", "#!/bin/sh\necho 'fedcba kxqpmnwz'\n")
+	defer restore()
+
+	tests := []struct {
+		ref, want string
+	}{
+		{"kxqp", "fedcba"},
+		{"HEAD", "HEAD"},
+		{"kxqp^..HEAD", "fedcba^..HEAD"},
+		{head + "..kxqp", head + "..fedcba"},
+	}
+	for _, tt := range tests {
+		if got, err := ResolveRef(repo.Root, tt.ref); err != nil || got != tt.want {
+			t.Errorf("ResolveRef(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
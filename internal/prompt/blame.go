@@ -0,0 +1,128 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// BlameHeader introduces the history of the lines a change modifies in the
+// prompts of thorough reviews
+const BlameHeader = `
+### History of Changed Lines
+
+The following commits last touched the lines this change modifies or
+removes, so that the intent behind the old code can be taken into account.
+`
+
+// blameMaxBytes caps the history section of a thorough review's prompt
+const blameMaxBytes = 16 * 1024
+
+// writeBlame appends, for each file diff modifies, the commits that last
+// touched the lines it modifies or removes, as of base (the revision the
+// diff applies to). Files that cannot be blamed, such as new files or the
+// changes of a root commit, are left out.
+func (b *Builder) writeBlame(sb *strings.Builder, repoPath, base, diff string) {
+	if !b.includeFileContext() {
+		return
+	}
+	budget := min(blameMaxBytes, b.maxPromptSize()-sb.Len()-len(BlameHeader))
+	if budget <= 0 {
+		return
+	}
+
+	var section strings.Builder
+	for _, file := range parseDiffOldLines(diff) {
+		commits, err := git.BlameLines(repoPath, base, file.Path, lineRanges(file.Lines))
+		if err != nil || len(commits) == 0 {
+			continue
+		}
+		entry := fmt.Sprintf("\n#### %s\n\n", file.Path)
+		for _, c := range commits {
+			entry += fmt.Sprintf("- %s %s %s: %s (%d line(s))\n",
+				shortID(c.SHA), c.Time.Format("2006-01-02"), c.Author, c.Summary, c.Lines)
+		}
+		if section.Len()+len(entry) > budget {
+			continue
+		}
+		section.WriteString(entry)
+	}
+
+	if section.Len() > 0 {
+		sb.WriteString(BlameHeader)
+		sb.WriteString(section.String())
+	}
+}
+
+// parseDiffOldLines lists the files a unified diff modifies or deletes, in
+// diff order, with the old-side line numbers it modifies or removes
+func parseDiffOldLines(diff string) []diffFile {
+	var files []diffFile
+	var cur *diffFile
+	oldLine := 0
+	inHunk := false // A removed "-- x" line looks like a "--- " header
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			cur, inHunk = nil, false
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			path := strings.TrimPrefix(line, "--- ")
+			if path == "/dev/null" {
+				cur = nil // Added file
+				continue
+			}
+			files = append(files, diffFile{Path: strings.TrimPrefix(path, "a/")})
+			cur = &files[len(files)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "@@ "):
+			oldLine, inHunk = hunkOldStart(line), true
+		case !inHunk:
+		case strings.HasPrefix(line, "-"):
+			cur.Lines = append(cur.Lines, oldLine)
+			oldLine++
+		case strings.HasPrefix(line, " "):
+			oldLine++
+		}
+	}
+
+	// Files with only added lines have nothing to blame
+	kept := files[:0]
+	for _, f := range files {
+		if len(f.Lines) > 0 {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// hunkOldStart returns the old-side start line of a "@@ -a,b +c,d @@" header
+func hunkOldStart(header string) int {
+	for _, field := range strings.Fields(header) {
+		if rest, ok := strings.CutPrefix(field, "-"); ok {
+			start, _, _ := strings.Cut(rest, ",")
+			if n, err := strconv.Atoi(start); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// lineRanges groups ascending line numbers into inclusive ranges of
+// consecutive lines
+func lineRanges(lines []int) [][2]int {
+	var ranges [][2]int
+	for _, n := range lines {
+		if n < 1 {
+			continue
+		}
+		if last := len(ranges) - 1; last >= 0 && n == ranges[last][1]+1 {
+			ranges[last][1] = n
+			continue
+		}
+		ranges = append(ranges, [2]int{n, n})
+	}
+	return ranges
+}
@@ -0,0 +1,68 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiffOldLines(t *testing.T) {
+	diff := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,5 +3,4 @@ func a() {
+ keep
+-old one
+-old two
+ keep
+--- removed line that looks like a header
+diff --git a/new.go b/new.go
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++x
++y
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -10,2 +10,3 @@
+ keep
++added only
+ keep
+`
+	got := parseDiffOldLines(diff)
+	want := []diffFile{{Path: "a.go", Lines: []int{4, 5, 7}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDiffOldLines = %+v, want %+v", got, want)
+	}
+
+	if got := lineRanges([]int{4, 5, 7, 8, 9, 12}); !reflect.DeepEqual(got, [][2]int{{4, 5}, {7, 9}, {12, 12}}) {
+		t.Errorf("lineRanges = %v", got)
+	}
+}
+
+func TestBuildFocusPrompt(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	targetSHA := commits[len(commits)-1]
+
+	plain, err := NewBuilder(nil).Build(repoPath, targetSHA, 0, 0, "test", "")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if strings.Contains(plain, "History of Changed Lines") || strings.Contains(plain, "Related File Context") {
+		t.Error("standard reviews should have no history or file context by default")
+	}
+
+	focused, err := NewBuilder(nil).Focus().Build(repoPath, targetSHA, 0, 0, "test", "")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(focused, "### Related File Context") {
+		t.Errorf("expected full file context in focus prompt, got:\n%s", focused)
+	}
+	// The changed line was last touched by the previous commit
+	if !strings.Contains(focused, BlameHeader) || !strings.Contains(focused, "#### file.txt") ||
+		!strings.Contains(focused, "Test: commit 5 (1 line(s))") {
+		t.Errorf("expected the history of the changed line in focus prompt, got:\n%s", focused)
+	}
+}
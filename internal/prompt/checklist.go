@@ -0,0 +1,94 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// ChecklistHeader heads the section in which a review answers the reviewer
+// checklist
+const ChecklistHeader = "## Checklist"
+
+// ChecklistInstructions asks a review to answer each checklist item
+// explicitly. ParseChecklist reads the answers back.
+func ChecklistInstructions(items []string) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Reviewer Checklist\n\n")
+	sb.WriteString("After your review, answer every item of this checklist for the change:\n\n")
+	for i, item := range items {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, item)
+	}
+	sb.WriteString(`
+Add a section headed exactly "` + ChecklistHeader + `" with one line per item,
+in order, formatted as "<number>. PASS|FAIL|N/A: <short reason>". Use FAIL
+when the change does not satisfy the item and N/A when the item does not
+apply to it. Answer every item; do not skip or merge items.
+`)
+	return sb.String()
+}
+
+// checklistAnswer matches "3. PASS: reason", "- 3: **FAIL** - reason" and
+// similar list lines
+var checklistAnswer = regexp.MustCompile(`(?i)^[-*\s]*(\d+)[.):]?\s*[-:]?\s*\**\s*(PASS|FAIL|N/?A)\b\s*\**\s*[-:.]?\s*(.*)$`)
+
+// ParseChecklist reads a review's answers to the checklist items. Items the
+// review did not answer are reported as unanswered.
+func ParseChecklist(output string, items []string) []storage.ChecklistResult {
+	results := make([]storage.ChecklistResult, len(items))
+	for i, item := range items {
+		results[i] = storage.ChecklistResult{Index: i, Item: item, Result: storage.ChecklistUnanswered}
+	}
+
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if isChecklistHeader(lines[i]) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return results
+	}
+
+	for _, line := range lines[start+1:] {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		m := checklistAnswer.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > len(items) || results[n-1].Result != storage.ChecklistUnanswered {
+			continue
+		}
+		result := storage.ChecklistNA
+		switch strings.ToUpper(m[2]) {
+		case "PASS":
+			result = storage.ChecklistPass
+		case "FAIL":
+			result = storage.ChecklistFail
+		}
+		results[n-1].Result = result
+		results[n-1].Note = strings.TrimSpace(m[3])
+	}
+	return results
+}
+
+// isChecklistHeader matches the section heading at any level, in any case
+// and with optional bold markers
+func isChecklistHeader(line string) bool {
+	s := strings.TrimSpace(line)
+	if !strings.HasPrefix(s, "#") && !strings.HasPrefix(s, "**") {
+		return false
+	}
+	s = strings.TrimLeft(s, "#")
+	s = strings.Trim(strings.TrimSpace(s), "*:")
+	return strings.EqualFold(strings.TrimSpace(s), strings.TrimPrefix(ChecklistHeader, "## "))
+}
@@ -0,0 +1,68 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestChecklistInstructions(t *testing.T) {
+	got := ChecklistInstructions([]string{"Tests added?", "Docs updated?"})
+	for _, want := range []string{"1. Tests added?\n", "2. Docs updated?\n", `"` + ChecklistHeader + `"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseChecklist(t *testing.T) {
+	items := []string{"Tests added?", "Docs updated?", "No new dependencies?"}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []storage.ChecklistResult
+	}{
+		{
+			name:   "no section",
+			output: "No issues found.",
+			want: []storage.ChecklistResult{
+				{Index: 0, Item: items[0], Result: storage.ChecklistUnanswered},
+				{Index: 1, Item: items[1], Result: storage.ChecklistUnanswered},
+				{Index: 2, Item: items[2], Result: storage.ChecklistUnanswered},
+			},
+		},
+		{
+			name:   "all answered",
+			output: "Review\n\n## Checklist\n1. PASS: parser_test.go covers it\n2. N/A: internal change\n3. FAIL: adds golang.org/x/text\n",
+			want: []storage.ChecklistResult{
+				{Index: 0, Item: items[0], Result: storage.ChecklistPass, Note: "parser_test.go covers it"},
+				{Index: 1, Item: items[1], Result: storage.ChecklistNA, Note: "internal change"},
+				{Index: 2, Item: items[2], Result: storage.ChecklistFail, Note: "adds golang.org/x/text"},
+			},
+		},
+		{
+			name:   "loose formatting, skipped item and a section after",
+			output: "### **Checklist**\n- 1: **fail** - no tests\n3) NA\n4. PASS: not an item\n\n## Notes\n2. PASS: outside the section",
+			want: []storage.ChecklistResult{
+				{Index: 0, Item: items[0], Result: storage.ChecklistFail, Note: "no tests"},
+				{Index: 1, Item: items[1], Result: storage.ChecklistUnanswered},
+				{Index: 2, Item: items[2], Result: storage.ChecklistNA},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseChecklist(tt.output, items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("result %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
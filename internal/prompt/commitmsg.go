@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// CommitMessageHeader heads the section in which a review proposes an
+// improved commit message
+const CommitMessageHeader = "## Suggested Commit Message"
+
+// CommitMessageInstructions asks a single-commit review to end with a
+// proposed commit message. ExtractCommitMessage reads the answer back.
+const CommitMessageInstructions = `
+## Commit Message
+
+After your review, judge the commit message above against the diff. End your
+response with a section headed exactly "` + CommitMessageHeader + `". If the
+message is accurate and clear, write "No change suggested." under it.
+Otherwise put a complete replacement message in a fenced text block: a subject
+line of at most 72 characters in the imperative mood, a blank line, then a
+body explaining what changed and why. Keep trailers (such as Signed-off-by)
+from the original message. Do not mention this review in the message.
+`
+
+// ExtractCommitMessage splits the suggested commit message section off a
+// review's output. It returns the review without the section and the
+// suggested message, which is "" when the section is missing or keeps the
+// current message.
+func ExtractCommitMessage(output string) (review, message string) {
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if isCommitMessageHeader(lines[i]) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return output, ""
+	}
+
+	// The section runs to the next heading outside a fenced block
+	end := len(lines)
+	inFence := false
+	var body []string
+	fenced := false
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") {
+			if !inFence && !fenced {
+				inFence, fenced = true, true
+				continue
+			}
+			if inFence {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			body = append(body, lines[i])
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			end = i
+			break
+		}
+	}
+
+	rest := append(lines[:start:start], lines[end:]...)
+	review = strings.TrimRight(strings.Join(rest, "\n"), "\n \t")
+	return review, strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+// isCommitMessageHeader matches the section heading at any level, in any
+// case and with optional bold markers
+func isCommitMessageHeader(line string) bool {
+	s := strings.TrimSpace(line)
+	s = strings.TrimLeft(s, "#")
+	s = strings.Trim(strings.TrimSpace(s), "*:")
+	return strings.EqualFold(strings.TrimSpace(s), strings.TrimPrefix(CommitMessageHeader, "## "))
+}
@@ -0,0 +1,48 @@
+package prompt
+
+import "testing"
+
+func TestExtractCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantReview  string
+		wantMessage string
+	}{
+		{
+			name:        "no section",
+			output:      "No issues found.",
+			wantReview:  "No issues found.",
+			wantMessage: "",
+		},
+		{
+			name:        "suggestion",
+			output:      "- Medium - parser.go:10 off by one\n\n## Suggested Commit Message\n\n```text\nFix bounds check in parser\n\nThe loop read one byte past the buffer.\n```\n",
+			wantReview:  "- Medium - parser.go:10 off by one",
+			wantMessage: "Fix bounds check in parser\n\nThe loop read one byte past the buffer.",
+		},
+		{
+			name:        "no change suggested",
+			output:      "No issues found.\n\n### **Suggested commit message:**\nNo change suggested.",
+			wantReview:  "No issues found.",
+			wantMessage: "",
+		},
+		{
+			name:        "heading inside the message and a section after it",
+			output:      "Review\n\n## Suggested Commit Message\n```\nAdd parser\n\n# not a heading\n```\n\n## Notes\nmore",
+			wantReview:  "Review\n\n## Notes\nmore",
+			wantMessage: "Add parser\n\n# not a heading",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, message := ExtractCommitMessage(tt.output)
+			if review != tt.wantReview {
+				t.Errorf("review = %q, want %q", review, tt.wantReview)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// FileContextHeader introduces touched files' content in a review prompt
+const FileContextHeader = `
+### Related File Context
+
+The following is the content of files touched by this change, as of the
+reviewed revision, so that surrounding logic can be taken into account.
+Review the diff above; use this section only as context.
+`
+
+// bytesPerToken is the rough size of a token used for context budgets
+const bytesPerToken = 4
+
+// writeFileContext appends the content of files touched by diff, read at
+// ref, when the repo enables context_files. Content is limited to the
+// configured token budget and the room left under the prompt size budget.
+// Thorough reviews include whole files, with at least FocusContextTokens.
+func (b *Builder) writeFileContext(sb *strings.Builder, repoPath, ref, diff string) {
+	if !b.includeFileContext() {
+		return
+	}
+	cfg := config.ResolveContextFiles(repoPath)
+	if b.focus {
+		cfg.Mode = "full"
+		cfg.MaxTokens = max(cfg.MaxTokens, FocusContextTokens)
+	}
+	if cfg.Mode == "" {
+		return
+	}
+
+	budget := cfg.MaxTokens * bytesPerToken
+	if room := b.maxPromptSize() - sb.Len() - len(FileContextHeader); room < budget {
+		budget = room
+	}
+	if budget <= 0 {
+		return
+	}
+
+	var section strings.Builder
+	for _, file := range parseDiffFiles(diff) {
+		content, err := git.ReadFile(repoPath, ref, file.Path)
+		if err != nil || isBinary(content) {
+			continue
+		}
+
+		text := string(content)
+		label := file.Path
+		if cfg.Mode == "symbols" || len(text) > budget-section.Len() {
+			text = changedBlocks(text, file.Lines)
+			label += " (changed blocks)"
+		}
+		entry := fmt.Sprintf("\n#### %s\n\n```\n%s", label, text)
+		if !strings.HasSuffix(entry, "\n") {
+			entry += "\n"
+		}
+		entry += "```\n"
+		if text == "" || section.Len()+len(entry) > budget {
+			continue
+		}
+		section.WriteString(entry)
+	}
+
+	if section.Len() > 0 {
+		sb.WriteString(FileContextHeader)
+		sb.WriteString(section.String())
+	}
+}
+
+// diffFile is a file in a unified diff and the new-side lines it changes
+type diffFile struct {
+	Path  string
+	Lines []int
+}
+
+// parseDiffFiles lists the files a unified diff adds or modifies, in diff
+// order, with the new-side line numbers of each change. A deletion is
+// recorded at the line that follows it so its enclosing block is found.
+func parseDiffFiles(diff string) []diffFile {
+	var files []diffFile
+	var cur *diffFile
+	newLine := 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			cur = nil
+		case strings.HasPrefix(line, "+++ "):
+			path := strings.TrimPrefix(line, "+++ ")
+			if path == "/dev/null" {
+				cur = nil // Deleted file
+				continue
+			}
+			files = append(files, diffFile{Path: strings.TrimPrefix(path, "b/")})
+			cur = &files[len(files)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "@@ "):
+			newLine = hunkNewStart(line)
+		case strings.HasPrefix(line, "+"):
+			cur.Lines = append(cur.Lines, newLine)
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			cur.Lines = append(cur.Lines, max(newLine, 1))
+		case strings.HasPrefix(line, " "):
+			newLine++
+		}
+	}
+	return files
+}
+
+// hunkNewStart returns the new-side start line of a "@@ -a,b +c,d @@" header
+func hunkNewStart(header string) int {
+	for _, field := range strings.Fields(header) {
+		if rest, ok := strings.CutPrefix(field, "+"); ok {
+			start, _, _ := strings.Cut(rest, ",")
+			if n, err := strconv.Atoi(start); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// changedBlocks returns the top-level blocks of content that contain any of
+// the given 1-based lines, separated by "..." where blocks are skipped. A
+// block starts at an unindented line that follows a blank line, which
+// matches functions and type declarations (with their doc comments) in most
+// languages without needing a parser.
+func changedBlocks(content string, lines []int) string {
+	src := strings.Split(content, "\n")
+	if len(src) == 0 || len(lines) == 0 {
+		return ""
+	}
+
+	// blockOf[i] is the index of the block start containing line i
+	blockOf := make([]int, len(src))
+	start := 0
+	for i, line := range src {
+		if i > 0 && isBlockStart(line) && strings.TrimSpace(src[i-1]) == "" {
+			start = i
+		}
+		blockOf[i] = start
+	}
+
+	want := make(map[int]bool)
+	for _, n := range lines {
+		if n >= 1 && n <= len(src) {
+			want[blockOf[n-1]] = true
+		}
+	}
+
+	var out strings.Builder
+	lastEnd := 0
+	for i := 0; i < len(src); {
+		j := i + 1
+		for j < len(src) && blockOf[j] == i {
+			j++
+		}
+		if want[i] {
+			if i > lastEnd {
+				out.WriteString("...\n")
+			}
+			out.WriteString(strings.TrimRight(strings.Join(src[i:j], "\n"), "\n"))
+			out.WriteString("\n")
+			lastEnd = j
+		}
+		i = j
+	}
+	if out.Len() > 0 && lastEnd < len(src) && strings.TrimSpace(strings.Join(src[lastEnd:], "")) != "" {
+		out.WriteString("...\n")
+	}
+	return out.String()
+}
+
+// isBlockStart reports whether line begins a top-level declaration
+func isBlockStart(line string) bool {
+	if line == "" {
+		return false
+	}
+	switch line[0] {
+	case ' ', '\t', '}', ')', ']':
+		return false
+	}
+	return true
+}
+
+// isBinary reports whether content has a NUL byte in its first 8KB
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8192)], 0) >= 0
+}
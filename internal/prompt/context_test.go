@@ -0,0 +1,105 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiffFiles(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,5 @@ package main
+ func a() {
+-	old()
++	new()
++	more()
+ }
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+`
+	got := parseDiffFiles(diff)
+	want := []diffFile{{Path: "main.go", Lines: []int{4, 4, 5}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDiffFiles() = %+v, want %+v", got, want)
+	}
+}
+
+func TestChangedBlocks(t *testing.T) {
+	content := `package main
+
+// a does a thing
+func a() {
+	x := 1
+
+	_ = x
+}
+
+func b() {
+	y()
+}
+
+func c() {}
+`
+	got := changedBlocks(content, []int{11})
+	want := "...\nfunc b() {\n\ty()\n}\n...\n"
+	if got != want {
+		t.Errorf("changedBlocks() = %q, want %q", got, want)
+	}
+
+	// A change inside a function keeps its doc comment and blank interior lines
+	got = changedBlocks(content, []int{7})
+	if !strings.Contains(got, "// a does a thing\nfunc a() {\n\tx := 1\n\n\t_ = x\n}") {
+		t.Errorf("expected whole function a with doc comment, got %q", got)
+	}
+	if strings.Contains(got, "func b") {
+		t.Errorf("did not expect unchanged function b, got %q", got)
+	}
+}
+
+func TestBuildPromptWithFileContext(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	targetSHA := commits[len(commits)-1]
+
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, ".roborev.toml"), []byte(content), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+
+	prompt, err := BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if strings.Contains(prompt, "Related File Context") {
+		t.Error("file context should be off by default")
+	}
+
+	writeConfig("[context_files]\nmode = \"full\"\n")
+	prompt, err = BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if !strings.Contains(prompt, "### Related File Context") || !strings.Contains(prompt, "#### file.txt\n\n```\nxxxxxx\n```") {
+		t.Errorf("expected full file context in prompt, got:\n%s", prompt)
+	}
+
+	// A budget too small for any file leaves the section out
+	writeConfig("[context_files]\nmode = \"full\"\nmax_tokens = 1\n")
+	prompt, err = BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if strings.Contains(prompt, "Related File Context") {
+		t.Error("expected no file context when nothing fits the budget")
+	}
+}
@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func TestBuildIncludesContextDocs(t *testing.T) {
+	diff := "diff --git a/f.go b/f.go\n+func f() {}\n"
+	docs := []storage.ContextDoc{
+		{Source: "docs/design.md", Content: "Reads go through the cache.\n```\nexample\n```"},
+		{Source: "https://example.com/rfc", Content: "The RFC.", Truncated: true},
+	}
+
+	p, err := NewBuilder(nil).WithContextDocs(docs).BuildDirty(t.TempDir(), diff, 0, 0, "test", "")
+	if err != nil {
+		t.Fatalf("BuildDirty: %v", err)
+	}
+	for _, want := range []string{"## Design Documents", "### docs/design.md", "Reads go through the cache.", "### https://example.com/rfc", "The RFC.\n... (truncated)"} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	// The docs are fenced so their own code blocks cannot end the section
+	if !strings.Contains(p, "````\nReads go through") {
+		t.Error("doc with a code block is not fenced with a longer fence")
+	}
+	if strings.Index(p, "## Design Documents") > strings.Index(p, "## Uncommitted Changes") {
+		t.Error("design docs should come before the change")
+	}
+
+	p, err = NewBuilder(nil).BuildDirty(t.TempDir(), diff, 0, 0, "test", "")
+	if err != nil {
+		t.Fatalf("BuildDirty: %v", err)
+	}
+	if strings.Contains(p, "## Design Documents") {
+		t.Error("prompt without docs has a design docs section")
+	}
+}
+
+func TestContextDocsFitBudget(t *testing.T) {
+	docs := []storage.ContextDoc{{Source: "big.md", Content: strings.Repeat("design ", MaxPromptSize)}}
+	var sb strings.Builder
+	NewBuilder(nil).WithContextDocs(docs).writeContextDocs(&sb)
+	if sb.Len() > MaxPromptSize/4+100 {
+		t.Errorf("design docs section is %d bytes, over a quarter of the prompt budget", sb.Len())
+	}
+	if !strings.Contains(sb.String(), "... (truncated)") {
+		t.Error("oversized doc is not marked truncated")
+	}
+}
@@ -0,0 +1,118 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+)
+
+// ProjectDocsHeader introduces the repo's convention documents
+const ProjectDocsHeader = `
+## Project Documentation
+
+The following documents describe this project's architecture and conventions.
+Use them to judge whether the change fits the way the project is built.
+`
+
+// cachedDoc is a project document as read at a given size and mtime
+type cachedDoc struct {
+	size    int64
+	modTime time.Time
+	content string
+}
+
+// docCache holds project documents by absolute path, so the same documents
+// are not re-read for every review of a repo
+var docCache = struct {
+	sync.Mutex
+	docs map[string]cachedDoc
+}{docs: make(map[string]cachedDoc)}
+
+// readProjectDoc returns the content of a document in the repo's working
+// tree, from the cache when the file has not changed since it was read
+func readProjectDoc(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+
+	docCache.Lock()
+	doc, ok := docCache.docs[path]
+	docCache.Unlock()
+	if ok && doc.size == info.Size() && doc.modTime.Equal(info.ModTime()) {
+		return doc.content, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if isBinary(content) {
+		return "", fmt.Errorf("%s is binary", path)
+	}
+	docCache.Lock()
+	docCache.docs[path] = cachedDoc{size: info.Size(), modTime: info.ModTime(), content: string(content)}
+	docCache.Unlock()
+	return string(content), nil
+}
+
+// writeProjectDocs appends the documents listed in the repo's project_docs,
+// read from the working tree. Paths outside the repo and symlinks are
+// ignored. Content is limited to the configured token budget and half of
+// MaxPromptSize; a document that does not fit is truncated.
+func (b *Builder) writeProjectDocs(sb *strings.Builder, repoPath string) {
+	if !b.includeHistory() {
+		return
+	}
+	cfg := config.ResolveProjectDocs(repoPath)
+	if len(cfg.Paths) == 0 {
+		return
+	}
+
+	budget := cfg.MaxTokens * bytesPerToken
+	// Keep at least half the prompt for the change itself
+	if room := MaxPromptSize/2 - sb.Len() - len(ProjectDocsHeader); room < budget {
+		budget = room
+	}
+
+	var section strings.Builder
+	for _, rel := range cfg.Paths {
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+		text, err := readProjectDoc(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		text = strings.TrimSpace(text)
+		heading := fmt.Sprintf("\n### %s\n\n", rel)
+		room := budget - section.Len() - len(heading) - 1
+		if text == "" || room <= 0 {
+			continue
+		}
+		if len(text) > room {
+			const note = "\n... (truncated)"
+			if room <= len(note) {
+				continue
+			}
+			text = strings.ToValidUTF8(text[:room-len(note)], "") + note
+		}
+		section.WriteString(heading)
+		section.WriteString(text)
+		section.WriteString("\n")
+	}
+
+	if section.Len() > 0 {
+		sb.WriteString(ProjectDocsHeader)
+		sb.WriteString(section.String())
+		sb.WriteString("\n")
+	}
+}
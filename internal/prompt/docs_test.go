@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildPromptWithProjectDocs(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	targetSHA := commits[len(commits)-1]
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	writeFile("ARCHITECTURE.md", "Handlers never touch the database directly.\n")
+	writeFile("STYLE.md", strings.Repeat("s", 1000))
+
+	prompt, err := BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if strings.Contains(prompt, "Project Documentation") {
+		t.Error("project docs should be off by default")
+	}
+
+	writeFile(".roborev.toml", "[project_docs]\npaths = [\"ARCHITECTURE.md\", \"../outside.md\", \"missing.md\", \"STYLE.md\"]\nmax_tokens = 100\n")
+	prompt, err = BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if !strings.Contains(prompt, "## Project Documentation") ||
+		!strings.Contains(prompt, "### ARCHITECTURE.md\n\nHandlers never touch the database directly.\n") {
+		t.Errorf("expected architecture doc in prompt, got:\n%s", prompt)
+	}
+	// The style guide is cut to what is left of the 400 byte budget
+	if !strings.Contains(prompt, "### STYLE.md\n\nsss") || !strings.Contains(prompt, "... (truncated)") {
+		t.Errorf("expected truncated style guide in prompt, got:\n%s", prompt)
+	}
+	_, style, _ := strings.Cut(prompt, "### STYLE.md\n\n")
+	if n := len(style) - len(strings.TrimLeft(style, "s")); n >= 400 {
+		t.Errorf("expected style guide limited to the budget, got %d bytes of it", n)
+	}
+
+	// Edits are picked up despite the cache
+	writeFile("ARCHITECTURE.md", "Handlers call services, which own the database.\n")
+	prompt, err = BuildSimple(repoPath, targetSHA, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if !strings.Contains(prompt, "Handlers call services") {
+		t.Errorf("expected updated architecture doc in prompt, got:\n%s", prompt)
+	}
+}
+
+func TestReadProjectDocSkipsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "GUIDE.md")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readProjectDoc(link); err == nil {
+		t.Error("expected symlinked doc to be refused")
+	}
+}
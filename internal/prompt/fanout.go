@@ -0,0 +1,203 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/vcs"
+)
+
+// MaxFanOutParts caps how many parts a fanned-out review is split into.
+// Files are grouped so that no commit queues more parts than this.
+const MaxFanOutParts = 16
+
+// maxJoinPartOutput truncates each part's review in the join prompt
+const maxJoinPartOutput = 20000
+
+// FileDiff is the diff of one file within a larger diff
+type FileDiff struct {
+	Path string
+	Diff string
+}
+
+// RefDiff returns the diff of a commit or range, as reviewed
+func RefDiff(repoPath, gitRef string) (string, error) {
+	return vcs.For(repoPath).Diff(repoPath, gitRef)
+}
+
+// SplitDiff splits a unified diff at its "diff --git" headers, in diff
+// order. Deleted files are named by their old path.
+func SplitDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var cur strings.Builder
+	path := ""
+	flush := func() {
+		if cur.Len() > 0 && path != "" {
+			files = append(files, FileDiff{Path: path, Diff: cur.String()})
+		}
+		cur.Reset()
+		path = ""
+	}
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(trimmed, "diff --git "):
+			flush()
+			// Fallback for binary files, which have no ---/+++ lines
+			if i := strings.LastIndex(trimmed, " b/"); i >= 0 {
+				path = trimmed[i+3:]
+			}
+		case strings.HasPrefix(trimmed, "+++ b/"):
+			path = strings.TrimPrefix(trimmed, "+++ b/")
+		case strings.HasPrefix(trimmed, "--- a/") && path == "":
+			path = strings.TrimPrefix(trimmed, "--- a/")
+		}
+		cur.WriteString(line)
+	}
+	flush()
+	return files
+}
+
+// PlanFanOut groups the files of a diff larger than threshold bytes into
+// parts to review separately. Consecutive files share a part until it
+// reaches threshold bytes, or the size that keeps the parts within
+// MaxFanOutParts if that is larger. Returns nil if the diff is within
+// threshold, threshold is 0, or the diff touches only one file.
+func PlanFanOut(diff string, threshold int) [][]string {
+	if threshold <= 0 || len(diff) <= threshold {
+		return nil
+	}
+	files := SplitDiff(diff)
+	if len(files) < 2 {
+		return nil
+	}
+
+	// Every closed part reaches target, so at most MaxFanOutParts-1 of them
+	// plus a final partial one
+	target := max(threshold, (len(diff)+MaxFanOutParts-2)/(MaxFanOutParts-1))
+	var groups [][]string
+	var group []string
+	size := 0
+	for _, f := range files {
+		group = append(group, f.Path)
+		size += len(f.Diff)
+		if size >= target {
+			groups = append(groups, group)
+			group, size = nil, 0
+		}
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	if len(groups) < 2 {
+		return nil
+	}
+	return groups
+}
+
+// BuildPart constructs the prompt for one part of a fanned-out commit or
+// range review: the review of the given files of gitRef's diff.
+func (b *Builder) BuildPart(repoPath, gitRef string, paths []string, contextCount int, agentName, reviewType string) (string, error) {
+	diff, err := RefDiff(repoPath, gitRef)
+	if err != nil {
+		return "", fmt.Errorf("get diff: %w", err)
+	}
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[p] = true
+	}
+	var partDiff strings.Builder
+	for _, f := range SplitDiff(diff) {
+		if want[f.Path] {
+			partDiff.WriteString(f.Diff)
+		}
+	}
+	if partDiff.Len() == 0 {
+		return "", fmt.Errorf("no changes to %s in %s", strings.Join(paths, ", "), gitRef)
+	}
+
+	promptType, ref := "review", gitRef
+	if _, end, ok := git.ParseRange(gitRef); ok {
+		promptType, ref = "range", end
+	}
+
+	var sb strings.Builder
+	sb.WriteString(GetSystemPrompt(agentName, SystemPromptType(promptType, reviewType)))
+	sb.WriteString("\n")
+	if repoCfg, err := config.LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		b.writeProjectGuidelines(&sb, repoCfg.ReviewGuidelines)
+	}
+	b.writeContextDocs(&sb)
+	if contextCount > 0 && b.store != nil && promptType == "review" {
+		if contexts, err := b.getPreviousReviewContexts(repoPath, gitRef, contextCount); err == nil && len(contexts) > 0 {
+			b.writePreviousReviews(&sb, contexts)
+		}
+	}
+
+	sb.WriteString("## Part of a Larger Change\n\n")
+	if promptType == "range" {
+		sb.WriteString(fmt.Sprintf("**Range:** %s\n", gitRef))
+	} else {
+		info, err := git.GetCommitInfo(repoPath, gitRef)
+		if err != nil {
+			return "", fmt.Errorf("get commit info: %w", err)
+		}
+		short := gitRef
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		sb.WriteString(fmt.Sprintf("**Commit:** %s\n", short))
+		sb.WriteString(fmt.Sprintf("**Subject:** %s\n", info.Subject))
+	}
+	sb.WriteString(fmt.Sprintf("**Files:** %s\n\n", strings.Join(paths, ", ")))
+	sb.WriteString("This change is too large to review at once. Review only the files above; " +
+		"the other files are reviewed separately and the results merged. " +
+		"Read other files from the repository when you need their context.\n\n")
+
+	writeChangedSymbols(&sb, ChangedSymbols(repoPath, ref, partDiff.String()))
+
+	sb.WriteString("### Diff\n\n")
+	if sb.Len()+partDiff.Len() > b.maxPromptSize() {
+		sb.WriteString("(Diff too large to include - please review the files directly)\n")
+		if promptType == "range" {
+			sb.WriteString(fmt.Sprintf("View with: git diff %s -- %s\n", gitRef, strings.Join(paths, " ")))
+		} else {
+			sb.WriteString(fmt.Sprintf("View with: git show %s -- %s\n", gitRef, strings.Join(paths, " ")))
+		}
+		return sb.String(), nil
+	}
+	writeUntrustedDiff(&sb, partDiff.String())
+	b.writeFileContext(&sb, repoPath, ref, partDiff.String())
+	return sb.String(), nil
+}
+
+// BuildJoin constructs the prompt that merges the reviews of the parts of a
+// fanned-out review into one review of the whole commit or range
+func BuildJoin(gitRef string, parts []storage.JobPart) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`You are merging the reviews of %d parts of one change (%s) into a single code review.
+Each part reviewed some of the changed files.
+Rules:
+- Deduplicate findings reported by more than one part
+- Keep every finding's severity and file/line references
+- Flag issues that only show when the parts are read together, such as a caller and callee changed inconsistently
+- Order findings by severity (Critical > High > Medium > Low)
+- If no part found issues, reply with "No issues found." and a one-line summary of the change
+- No preamble about yourself or the parts
+
+`, len(parts), gitRef))
+
+	for i, p := range parts {
+		sb.WriteString(fmt.Sprintf("---\n### Part %d: %s\n", i+1, strings.Join(p.Paths, ", ")))
+		output := p.Output
+		if len(output) > maxJoinPartOutput {
+			output = output[:maxJoinPartOutput] + "\n\n...(truncated)"
+		}
+		sb.WriteString(output)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+func fileDiff(path string, lines int) string {
+	return "diff --git a/" + path + " b/" + path + "\n--- a/" + path + "\n+++ b/" + path + "\n@@ -1 +1 @@\n" +
+		strings.Repeat("+x\n", lines)
+}
+
+func TestSplitDiff(t *testing.T) {
+	deleted := "diff --git a/old.go b/old.go\ndeleted file mode 100644\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n"
+	binary := "diff --git a/img.png b/img.png\nBinary files differ\n"
+	diff := fileDiff("a.go", 1) + deleted + binary + fileDiff("dir/b.go", 2)
+
+	files := SplitDiff(diff)
+	var paths []string
+	var joined strings.Builder
+	for _, f := range files {
+		paths = append(paths, f.Path)
+		joined.WriteString(f.Diff)
+	}
+	if want := []string{"a.go", "old.go", "img.png", "dir/b.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if joined.String() != diff {
+		t.Error("file diffs do not add up to the whole diff")
+	}
+}
+
+func TestPlanFanOut(t *testing.T) {
+	small := fileDiff("a.go", 10) + fileDiff("b.go", 10)
+	if groups := PlanFanOut(small, 0); groups != nil {
+		t.Errorf("expected no fan-out when disabled, got %v", groups)
+	}
+	if groups := PlanFanOut(small, len(small)); groups != nil {
+		t.Errorf("expected no fan-out within threshold, got %v", groups)
+	}
+	if groups := PlanFanOut(fileDiff("big.go", 1000), 100); groups != nil {
+		t.Errorf("expected no fan-out of a single file, got %v", groups)
+	}
+
+	// Small files share a part until it reaches the threshold
+	diff := fileDiff("a.go", 100) + fileDiff("b.go", 10) + fileDiff("c.go", 10) + fileDiff("d.go", 100)
+	groups := PlanFanOut(diff, 400)
+	want := [][]string{{"a.go", "b.go"}, {"c.go", "d.go"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %v, want %v", groups, want)
+	}
+
+	// Parts are capped
+	var many strings.Builder
+	for i := 0; i < 3*MaxFanOutParts; i++ {
+		many.WriteString(fileDiff(strings.Repeat("f", i+1)+".go", 10))
+	}
+	if groups := PlanFanOut(many.String(), 10); len(groups) > MaxFanOutParts {
+		t.Errorf("expected at most %d parts, got %d", MaxFanOutParts, len(groups))
+	}
+}
+
+func TestBuildJoin(t *testing.T) {
+	parts := []storage.JobPart{
+		{Paths: []string{"a.go", "b.go"}, Output: "- High: a.go:3 leaks"},
+		{Paths: []string{"c.go"}, Output: strings.Repeat("y", maxJoinPartOutput+10)},
+	}
+	got := BuildJoin("abc1234", parts)
+	for _, want := range []string{"2 parts of one change (abc1234)", "### Part 1: a.go, b.go", "a.go:3 leaks", "### Part 2: c.go", "...(truncated)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in join prompt", want)
+		}
+	}
+}
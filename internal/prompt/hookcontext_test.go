@@ -0,0 +1,24 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookContext(t *testing.T) {
+	if got := HookContext([]string{"", " \n"}); got != "" {
+		t.Errorf("expected no section without output, got %q", got)
+	}
+
+	got := HookContext([]string{"Ticket PAY-12: refunds must be idempotent\n", "uses ```go fences```"})
+	if !strings.HasPrefix(got, HookContextHeader) {
+		t.Errorf("expected the section header, got %q", got)
+	}
+	if !strings.Contains(got, "```\nTicket PAY-12: refunds must be idempotent\n```") {
+		t.Errorf("expected the first output fenced, got %q", got)
+	}
+	// Backticks in an output get a longer fence so they cannot close it
+	if !strings.Contains(got, "````\nuses ```go fences```\n````") {
+		t.Errorf("expected a longer fence around backticks, got %q", got)
+	}
+}
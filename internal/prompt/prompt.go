@@ -1,18 +1,28 @@
 package prompt
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/roborev-dev/roborev/internal/config"
 	"github.com/roborev-dev/roborev/internal/git"
 	"github.com/roborev-dev/roborev/internal/storage"
+	"github.com/roborev-dev/roborev/internal/vcs"
 )
 
 // MaxPromptSize is the maximum size of a prompt in bytes (250KB)
 // If the prompt with diffs exceeds this, we fall back to just commit info
 const MaxPromptSize = 250 * 1024
 
+// FocusMaxPromptSize is the maximum size of a thorough review's prompt
+const FocusMaxPromptSize = 2 * MaxPromptSize
+
+// FocusContextTokens is the least budget for the related file context of a
+// thorough review, which includes whole files even when the repo does not
+// enable context_files
+const FocusContextTokens = 32000
+
 // SystemPromptSingle is the base instruction for single commit reviews
 const SystemPromptSingle = `You are a code reviewer. Review the git commit shown below for:
 
@@ -105,6 +115,18 @@ responses from developers. Use this context to:
 - Consider developer responses about why certain patterns exist
 `
 
+// PreviousVersionsHeader introduces reviews of earlier versions of a
+// rewritten change (commits sharing a jj change ID)
+const PreviousVersionsHeader = `
+## Reviews of Earlier Versions
+
+This commit is a rewritten version of a change that was reviewed before (amended, rebased
+or squashed into). The following are reviews of its earlier versions and any responses from
+developers. Use this context to:
+- Check whether issues found in earlier versions were fixed
+- Avoid repeating issues that have been marked as known/acceptable
+`
+
 // ReviewContext holds a commit SHA and its associated review (if any) plus responses
 type ReviewContext struct {
 	SHA       string
@@ -114,17 +136,45 @@ type ReviewContext struct {
 
 // Builder constructs review prompts
 type Builder struct {
-	db *storage.DB
+	store          storage.Storage // Source of earlier reviews; nil leaves them out
+	db             *storage.DB     // store when it is the local database, for change and related reviews
+	focus          bool
+	relatedReviews int                  // Related reviews to summarize; see WithRelatedReviews
+	truncation     int                  // Truncation level; see WithTruncation
+	contextDocs    []storage.ContextDoc // Design docs linked to the job; see WithContextDocs
+}
+
+// NewBuilder creates a new prompt builder that looks up earlier reviews in
+// store, which may be nil
+func NewBuilder(store storage.Storage) *Builder {
+	db, _ := store.(*storage.DB)
+	return &Builder{store: store, db: db}
 }
 
-// NewBuilder creates a new prompt builder
-func NewBuilder(db *storage.DB) *Builder {
-	return &Builder{db: db}
+// Focus returns a builder for thorough reviews, whose prompts have a larger
+// size budget and, for commits and ranges, the full content of touched
+// files and the history of the lines the change modifies
+func (b *Builder) Focus() *Builder {
+	focused := *b
+	focused.focus = true
+	return &focused
+}
+
+// maxPromptSize returns the size budget of the builder's prompts
+func (b *Builder) maxPromptSize() int {
+	size := MaxPromptSize
+	if b.focus {
+		size = FocusMaxPromptSize
+	}
+	return size >> b.truncation
 }
 
 // Build constructs a review prompt for a commit or range with context from previous reviews.
 // reviewType selects the system prompt variant (e.g., "security"); any default alias (see config.IsDefaultReviewType) uses the standard prompt.
 func (b *Builder) Build(repoPath, gitRef string, repoID int64, contextCount int, agentName, reviewType string) (string, error) {
+	if v := vcs.For(repoPath); v.Name() != "git" {
+		return b.buildVCSPrompt(v, repoPath, gitRef, agentName, reviewType)
+	}
 	if git.IsRange(gitRef) {
 		return b.buildRangePrompt(repoPath, gitRef, repoID, contextCount, agentName, reviewType)
 	}
@@ -135,26 +185,38 @@ func (b *Builder) Build(repoPath, gitRef string, repoID int64, contextCount int,
 // The diff is provided directly since it was captured at enqueue time.
 // reviewType selects the system prompt variant (e.g., "security"); any default alias (see config.IsDefaultReviewType) uses the standard prompt.
 func (b *Builder) BuildDirty(repoPath, diff string, repoID int64, contextCount int, agentName, reviewType string) (string, error) {
+	return b.buildDiffPrompt(repoPath, diff, "## Uncommitted Changes\n\nThe following changes have not yet been committed.\n\n", repoID, contextCount, agentName, reviewType)
+}
+
+// BuildPatch constructs a review prompt for a patch file that is not in git,
+// such as an emailed patch or a git format-patch series. name identifies the
+// patch in the prompt. The patch has not been applied, so the working tree
+// shows the code as it was before the change.
+func (b *Builder) BuildPatch(repoPath, patch, name string, repoID int64, contextCount int, agentName, reviewType string) (string, error) {
+	header := fmt.Sprintf("## Patch: %s\n\n", name) +
+		"The following patch has not been applied to this repository. " +
+		"Files in the working tree show the code before the patch.\n\n"
+	return b.buildDiffPrompt(repoPath, patch, header, repoID, contextCount, agentName, reviewType)
+}
+
+// buildDiffPrompt constructs a prompt for a diff captured at enqueue time,
+// introduced by header
+func (b *Builder) buildDiffPrompt(repoPath, diff, header string, repoID int64, contextCount int, agentName, reviewType string) (string, error) {
 	var sb strings.Builder
 
 	// Start with system prompt for dirty changes
-	promptType := "dirty"
-	if !config.IsDefaultReviewType(reviewType) {
-		promptType = reviewType
-	}
-	if promptType == "design" {
-		promptType = "design-review"
-	}
-	sb.WriteString(GetSystemPrompt(agentName, promptType))
+	sb.WriteString(GetSystemPrompt(agentName, SystemPromptType("dirty", reviewType)))
 	sb.WriteString("\n")
 
 	// Add project-specific guidelines if configured
 	if repoCfg, err := config.LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
 		b.writeProjectGuidelines(&sb, repoCfg.ReviewGuidelines)
 	}
+	b.writeProjectDocs(&sb, repoPath)
+	b.writeContextDocs(&sb)
 
 	// Get previous reviews for context (use HEAD as reference point)
-	if contextCount > 0 && b.db != nil {
+	if contextCount > 0 && b.store != nil {
 		headSHA, err := git.ResolveSHA(repoPath, "HEAD")
 		if err == nil {
 			contexts, err := b.getPreviousReviewContexts(repoPath, headSHA, contextCount)
@@ -163,34 +225,31 @@ func (b *Builder) BuildDirty(repoPath, diff string, repoID int64, contextCount i
 			}
 		}
 	}
+	if b.relatedReviews > 0 {
+		var files []string
+		for _, f := range parseDiffFiles(diff) {
+			files = append(files, f.Path)
+		}
+		b.writeRelatedReviews(&sb, repoID, "", files)
+	}
 
-	// Uncommitted changes section
-	sb.WriteString("## Uncommitted Changes\n\n")
-	sb.WriteString("The following changes have not yet been committed.\n\n")
+	sb.WriteString(header)
 
 	// Build diff section
 	var diffSection strings.Builder
 	diffSection.WriteString("### Diff\n\n")
-	diffSection.WriteString("```diff\n")
-	diffSection.WriteString(diff)
-	if !strings.HasSuffix(diff, "\n") {
-		diffSection.WriteString("\n")
-	}
-	diffSection.WriteString("```\n")
+	writeUntrustedDiff(&diffSection, diff)
 
 	// Check if adding the diff would exceed max prompt size
-	if sb.Len()+diffSection.Len() > MaxPromptSize {
+	if sb.Len()+diffSection.Len() > b.maxPromptSize() {
 		// For dirty changes, we can't tell them to "use git diff" because
 		// the working tree may have changed. Just truncate with a note.
 		sb.WriteString("### Diff\n\n")
 		sb.WriteString("(Diff too large to include in full)\n")
 		// Include truncated diff
-		maxDiffLen := MaxPromptSize - sb.Len() - 100 // Leave room for closing markers
+		maxDiffLen := b.maxPromptSize() - sb.Len() - 100 // Leave room for closing markers
 		if maxDiffLen > 1000 {
-			sb.WriteString("```diff\n")
-			sb.WriteString(diff[:maxDiffLen])
-			sb.WriteString("\n... (truncated)\n")
-			sb.WriteString("```\n")
+			writeUntrustedDiff(&sb, diff[:maxDiffLen]+"\n... (truncated)\n")
 		}
 	} else {
 		sb.WriteString(diffSection.String())
@@ -204,23 +263,18 @@ func (b *Builder) buildSinglePrompt(repoPath, sha string, repoID int64, contextC
 	var sb strings.Builder
 
 	// Start with system prompt
-	promptType := "review"
-	if !config.IsDefaultReviewType(reviewType) {
-		promptType = reviewType
-	}
-	if promptType == "design" {
-		promptType = "design-review"
-	}
-	sb.WriteString(GetSystemPrompt(agentName, promptType))
+	sb.WriteString(GetSystemPrompt(agentName, SystemPromptType("review", reviewType)))
 	sb.WriteString("\n")
 
 	// Add project-specific guidelines if configured
 	if repoCfg, err := config.LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
 		b.writeProjectGuidelines(&sb, repoCfg.ReviewGuidelines)
 	}
+	b.writeProjectDocs(&sb, repoPath)
+	b.writeContextDocs(&sb)
 
 	// Get previous reviews if requested
-	if contextCount > 0 && b.db != nil {
+	if contextCount > 0 && b.store != nil {
 		contexts, err := b.getPreviousReviewContexts(repoPath, sha, contextCount)
 		if err != nil {
 			// Log but don't fail - previous reviews are nice-to-have context
@@ -231,7 +285,13 @@ func (b *Builder) buildSinglePrompt(repoPath, sha string, repoID int64, contextC
 	}
 
 	// Include previous review attempts for this same commit (for re-reviews)
+	// and for earlier versions of its change
 	b.writePreviousAttemptsForGitRef(&sb, sha)
+	b.writePreviousVersions(&sb, sha)
+	if b.relatedReviews > 0 {
+		files, _ := git.GetFilesChanged(repoPath, sha)
+		b.writeRelatedReviews(&sb, repoID, sha, files)
+	}
 
 	// Current commit section
 	shortSHA := sha
@@ -254,30 +314,32 @@ func (b *Builder) buildSinglePrompt(repoPath, sha string, repoID int64, contextC
 	}
 	sb.WriteString("\n")
 
-	// Get and include the diff
-	diff, err := git.GetDiff(repoPath, sha)
+	// Get and include the diff. Reading stops past what fits in a prompt, so
+	// a huge commit is not held in memory; its changed symbols then come
+	// from the part that was read.
+	diff, truncated, err := git.GetDiffLimited(repoPath, sha, b.maxPromptSize())
 	if err != nil {
 		return "", fmt.Errorf("get diff: %w", err)
 	}
+	writeChangedSymbols(&sb, ChangedSymbols(repoPath, sha, diff))
 
 	// Build diff section
 	var diffSection strings.Builder
 	diffSection.WriteString("### Diff\n\n")
-	diffSection.WriteString("```diff\n")
-	diffSection.WriteString(diff)
-	if !strings.HasSuffix(diff, "\n") {
-		diffSection.WriteString("\n")
-	}
-	diffSection.WriteString("```\n")
+	writeUntrustedDiff(&diffSection, diff)
 
 	// Check if adding the diff would exceed max prompt size
-	if sb.Len()+diffSection.Len() > MaxPromptSize {
+	if truncated || sb.Len()+diffSection.Len() > b.maxPromptSize() {
 		// Fall back to just commit info without diff
 		sb.WriteString("### Diff\n\n")
 		sb.WriteString("(Diff too large to include - please review the commit directly)\n")
 		sb.WriteString(fmt.Sprintf("View with: git show %s\n", sha))
 	} else {
 		sb.WriteString(diffSection.String())
+		b.writeFileContext(&sb, repoPath, sha, diff)
+		if b.focus {
+			b.writeBlame(&sb, repoPath, sha+"^", diff)
+		}
 	}
 
 	return sb.String(), nil
@@ -288,23 +350,18 @@ func (b *Builder) buildRangePrompt(repoPath, rangeRef string, repoID int64, cont
 	var sb strings.Builder
 
 	// Start with system prompt for ranges
-	promptType := "range"
-	if !config.IsDefaultReviewType(reviewType) {
-		promptType = reviewType
-	}
-	if promptType == "design" {
-		promptType = "design-review"
-	}
-	sb.WriteString(GetSystemPrompt(agentName, promptType))
+	sb.WriteString(GetSystemPrompt(agentName, SystemPromptType("range", reviewType)))
 	sb.WriteString("\n")
 
 	// Add project-specific guidelines if configured
 	if repoCfg, err := config.LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
 		b.writeProjectGuidelines(&sb, repoCfg.ReviewGuidelines)
 	}
+	b.writeProjectDocs(&sb, repoPath)
+	b.writeContextDocs(&sb)
 
 	// Get previous reviews from before the range start
-	if contextCount > 0 && b.db != nil {
+	if contextCount > 0 && b.store != nil {
 		startSHA, err := git.GetRangeStart(repoPath, rangeRef)
 		if err == nil {
 			contexts, err := b.getPreviousReviewContexts(repoPath, startSHA, contextCount)
@@ -316,6 +373,10 @@ func (b *Builder) buildRangePrompt(repoPath, rangeRef string, repoID int64, cont
 
 	// Include previous review attempts for this same range (for re-reviews)
 	b.writePreviousAttemptsForGitRef(&sb, rangeRef)
+	if b.relatedReviews > 0 {
+		files, _ := git.GetRangeFilesChanged(repoPath, rangeRef)
+		b.writeRelatedReviews(&sb, repoID, rangeRef, files)
+	}
 
 	// Get commits in range
 	commits, err := git.GetRangeCommits(repoPath, rangeRef)
@@ -341,30 +402,34 @@ func (b *Builder) buildRangePrompt(repoPath, rangeRef string, repoID int64, cont
 	}
 	sb.WriteString("\n")
 
-	// Get and include the combined diff for the range
-	diff, err := git.GetRangeDiff(repoPath, rangeRef)
+	// Get and include the combined diff for the range, read as for a commit
+	diff, truncated, err := git.GetRangeDiffLimited(repoPath, rangeRef, b.maxPromptSize())
 	if err != nil {
 		return "", fmt.Errorf("get range diff: %w", err)
 	}
+	if _, end, ok := git.ParseRange(rangeRef); ok {
+		writeChangedSymbols(&sb, ChangedSymbols(repoPath, end, diff))
+	}
 
 	// Build diff section
 	var diffSection strings.Builder
 	diffSection.WriteString("### Combined Diff\n\n")
-	diffSection.WriteString("```diff\n")
-	diffSection.WriteString(diff)
-	if !strings.HasSuffix(diff, "\n") {
-		diffSection.WriteString("\n")
-	}
-	diffSection.WriteString("```\n")
+	writeUntrustedDiff(&diffSection, diff)
 
 	// Check if adding the diff would exceed max prompt size
-	if sb.Len()+diffSection.Len() > MaxPromptSize {
+	if truncated || sb.Len()+diffSection.Len() > b.maxPromptSize() {
 		// Fall back to just commit info without diff
 		sb.WriteString("### Combined Diff\n\n")
 		sb.WriteString("(Diff too large to include - please review the commits directly)\n")
 		sb.WriteString(fmt.Sprintf("View with: git diff %s\n", rangeRef))
 	} else {
 		sb.WriteString(diffSection.String())
+		if start, end, ok := git.ParseRange(rangeRef); ok {
+			b.writeFileContext(&sb, repoPath, end, diff)
+			if b.focus {
+				b.writeBlame(&sb, repoPath, start, diff)
+			}
+		}
 	}
 
 	return sb.String(), nil
@@ -372,6 +437,9 @@ func (b *Builder) buildRangePrompt(repoPath, rangeRef string, repoID int64, cont
 
 // writePreviousReviews writes the previous reviews section to the builder
 func (b *Builder) writePreviousReviews(sb *strings.Builder, contexts []ReviewContext) {
+	if !b.includeHistory() {
+		return
+	}
 	sb.WriteString(PreviousReviewsHeader)
 	sb.WriteString("\n")
 
@@ -416,11 +484,11 @@ func (b *Builder) writeProjectGuidelines(sb *strings.Builder, guidelines string)
 
 // writePreviousAttemptsForGitRef writes previous review attempts for the same git ref (commit or range)
 func (b *Builder) writePreviousAttemptsForGitRef(sb *strings.Builder, gitRef string) {
-	if b.db == nil {
+	if b.store == nil || !b.includeHistory() {
 		return
 	}
 
-	reviews, err := b.db.GetAllReviewsForGitRef(gitRef)
+	reviews, err := b.store.GetAllReviewsForGitRef(context.Background(), gitRef)
 	if err != nil || len(reviews) == 0 {
 		return
 	}
@@ -436,7 +504,7 @@ func (b *Builder) writePreviousAttemptsForGitRef(sb *strings.Builder, gitRef str
 
 		// Fetch and include comments for this review
 		if review.JobID > 0 {
-			responses, err := b.db.GetCommentsForJob(review.JobID)
+			responses, err := b.store.GetCommentsForJob(context.Background(), review.JobID)
 			if err == nil && len(responses) > 0 {
 				sb.WriteString("\nComments on this review:\n")
 				for _, resp := range responses {
@@ -448,6 +516,42 @@ func (b *Builder) writePreviousAttemptsForGitRef(sb *strings.Builder, gitRef str
 	}
 }
 
+// writePreviousVersions writes the reviews of earlier versions of the
+// change sha belongs to, when its change ID was recorded
+func (b *Builder) writePreviousVersions(sb *strings.Builder, sha string) {
+	if b.db == nil || !b.includeHistory() {
+		return
+	}
+	changeID, err := b.db.GetCommitChangeID(sha)
+	if err != nil || changeID == "" {
+		return
+	}
+	reviews, err := b.db.GetChangeReviews(changeID, sha)
+	if err != nil || len(reviews) == 0 {
+		return
+	}
+
+	sb.WriteString(PreviousVersionsHeader)
+	sb.WriteString("\n")
+	for _, review := range reviews {
+		shortSHA := review.SHA
+		if len(shortSHA) > 7 {
+			shortSHA = shortSHA[:7]
+		}
+		sb.WriteString(fmt.Sprintf("--- Version %s: %s (%s, %s) ---\n",
+			shortSHA, review.Subject, review.Agent, review.CreatedAt.Format("2006-01-02 15:04")))
+		sb.WriteString(review.Output)
+		sb.WriteString("\n")
+		if responses, err := b.store.GetCommentsForJob(context.Background(), review.JobID); err == nil && len(responses) > 0 {
+			sb.WriteString("\nComments on this review:\n")
+			for _, resp := range responses {
+				sb.WriteString(fmt.Sprintf("- %s: %q\n", resp.Responder, resp.Response))
+			}
+		}
+		sb.WriteString("\n")
+	}
+}
+
 // getPreviousReviewContexts gets the N commits before the target and looks up their reviews and responses
 func (b *Builder) getPreviousReviewContexts(repoPath, sha string, count int) ([]ReviewContext, error) {
 	// Get parent commits from git
@@ -461,13 +565,13 @@ func (b *Builder) getPreviousReviewContexts(repoPath, sha string, count int) ([]
 		ctx := ReviewContext{SHA: parentSHA}
 
 		// Try to look up review for this commit
-		review, err := b.db.GetReviewByCommitSHA(parentSHA)
+		review, err := b.store.GetReviewByCommitSHA(context.Background(), parentSHA)
 		if err == nil {
 			ctx.Review = review
 
 			// Also fetch comments for this review's job
 			if review.JobID > 0 {
-				responses, err := b.db.GetCommentsForJob(review.JobID)
+				responses, err := b.store.GetCommentsForJob(context.Background(), review.JobID)
 				if err == nil {
 					ctx.Responses = responses
 				}
@@ -608,15 +712,10 @@ func (b *Builder) BuildAddressPrompt(repoPath string, review *storage.Review, pr
 
 	// Include the original diff for context if we have job info
 	if review.Job != nil && review.Job.GitRef != "" && review.Job.GitRef != "dirty" {
-		diff, err := git.GetDiff(repoPath, review.Job.GitRef)
-		if err == nil && len(diff) > 0 && len(diff) < MaxPromptSize/2 {
+		diff, truncated, err := git.GetDiffLimited(repoPath, review.Job.GitRef, MaxPromptSize/2)
+		if err == nil && !truncated && len(diff) > 0 && len(diff) < MaxPromptSize/2 {
 			sb.WriteString("## Original Commit Diff (for context)\n\n")
-			sb.WriteString("```diff\n")
-			sb.WriteString(diff)
-			if !strings.HasSuffix(diff, "\n") {
-				sb.WriteString("\n")
-			}
-			sb.WriteString("```\n")
+			writeUntrustedDiff(&sb, diff)
 		}
 	}
 
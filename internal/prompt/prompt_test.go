@@ -101,7 +101,7 @@ func TestBuildPromptWithPreviousReviews(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 
 	// Create repo and commits in DB
-	repo, err := db.GetOrCreateRepo(repoPath)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -115,7 +115,7 @@ func TestBuildPromptWithPreviousReviews(t *testing.T) {
 
 	for i, sha := range commits[:5] { // First 5 commits (parents of commit 6)
 		// Ensure commit exists in DB
-		if _, err := db.GetOrCreateCommit(repo.ID, sha, "Test", "commit message", time.Now()); err != nil {
+		if _, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Test", "commit message", time.Now()); err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
 
@@ -126,7 +126,7 @@ func TestBuildPromptWithPreviousReviews(t *testing.T) {
 	}
 
 	// Also add commit 6 to DB (the target commit)
-	_, err = db.GetOrCreateCommit(repo.ID, commits[5], "Test", "commit message", time.Now())
+	_, err = db.GetOrCreateCommit(t.Context(), repo.ID, commits[5], "Test", "commit message", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
@@ -177,7 +177,7 @@ func TestBuildPromptWithPreviousReviewsAndResponses(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 
 	// Create repo
-	repo, err := db.GetOrCreateRepo(repoPath)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -193,7 +193,7 @@ func TestBuildPromptWithPreviousReviewsAndResponses(t *testing.T) {
 
 	// Also add commits 4 and 5 to DB
 	for _, sha := range commits[3:5] {
-		db.GetOrCreateCommit(repo.ID, sha, "Test", "commit", time.Now())
+		db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Test", "commit", time.Now())
 	}
 
 	// Build prompt for commit 6 with context from previous 5 commits
@@ -294,7 +294,7 @@ func TestPromptContainsExpectedFormat(t *testing.T) {
 
 	db := testutil.OpenTestDB(t)
 
-	repo, _ := db.GetOrCreateRepo(repoPath)
+	repo, _ := db.GetOrCreateRepo(t.Context(), repoPath)
 	testutil.CreateCompletedReview(t, db, repo.ID, commits[4], "test", "Found 1 issue:\n1. pkg/cache/store.go:112 - Race condition")
 
 	builder := NewBuilder(db)
@@ -454,7 +454,7 @@ func TestBuildPromptWithPreviousAttempts(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 
 	// Create repo and commit in DB
-	repo, err := db.GetOrCreateRepo(repoPath)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -497,13 +497,59 @@ func TestBuildPromptWithPreviousAttempts(t *testing.T) {
 	}
 }
 
+func TestBuildPromptWithPreviousVersions(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	earlierSHA, targetSHA := commits[4], commits[5]
+
+	db := testutil.OpenTestDB(t)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo failed: %v", err)
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, earlierSHA, "test", "Earlier version: missing nil check")
+
+	builder := NewBuilder(db)
+	prompt, err := builder.Build(repoPath, targetSHA, repo.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if strings.Contains(prompt, "## Reviews of Earlier Versions") {
+		t.Error("Prompt should not link commits without a recorded change ID")
+	}
+
+	// Record both commits as versions of one change
+	for _, sha := range []string{earlierSHA, targetSHA} {
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Author", "Subject", time.Now())
+		if err != nil {
+			t.Fatalf("GetOrCreateCommit failed: %v", err)
+		}
+		if err := db.SetCommitChangeID(commit.ID, "kxqpmnwz"); err != nil {
+			t.Fatalf("SetCommitChangeID failed: %v", err)
+		}
+	}
+
+	prompt, err = builder.Build(repoPath, targetSHA, repo.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(prompt, "## Reviews of Earlier Versions") {
+		t.Error("Prompt should contain reviews of earlier versions")
+	}
+	if !strings.Contains(prompt, "Earlier version: missing nil check") {
+		t.Error("Prompt should contain the earlier version's review")
+	}
+	if !strings.Contains(prompt, "--- Version "+earlierSHA[:7]) {
+		t.Error("Prompt should name the earlier version's commit")
+	}
+}
+
 func TestBuildPromptWithPreviousAttemptsAndResponses(t *testing.T) {
 	repoPath, commits := setupTestRepo(t)
 	targetSHA := commits[5]
 
 	db := testutil.OpenTestDB(t)
 
-	repo, _ := db.GetOrCreateRepo(repoPath)
+	repo, _ := db.GetOrCreateRepo(t.Context(), repoPath)
 
 	// Create a previous review with a comment
 	testutil.CreateReviewWithComments(t, db, repo.ID, targetSHA,
@@ -617,6 +663,29 @@ func TestBuildDirtyWithReviewAlias(t *testing.T) {
 	}
 }
 
+func TestBuildPatch(t *testing.T) {
+	patch := "diff --git a/foo.go b/foo.go\n+func foo() {}\n"
+	b := NewBuilder(nil)
+	repoPath := t.TempDir()
+
+	prompt, err := b.BuildPatch(repoPath, patch, "fix.patch", 0, 0, "test", "")
+	if err != nil {
+		t.Fatalf("BuildPatch failed: %v", err)
+	}
+	if !strings.Contains(prompt, "## Patch: fix.patch") {
+		t.Error("Expected patch heading with the patch name")
+	}
+	if !strings.Contains(prompt, "has not been applied") {
+		t.Error("Expected note that the patch is not applied")
+	}
+	if strings.Contains(prompt, "## Uncommitted Changes") {
+		t.Error("Should not describe the patch as uncommitted changes")
+	}
+	if !strings.Contains(prompt, "+func foo() {}") {
+		t.Error("Expected patch content in prompt")
+	}
+}
+
 func TestBuildRangeWithReviewAlias(t *testing.T) {
 	repoPath, commits := setupTestRepo(t)
 	// Use a two-commit range
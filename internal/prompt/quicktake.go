@@ -0,0 +1,73 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// QuickTakeHeader heads the section in which a review sums itself up for
+// list views, notifications and the statusline
+const QuickTakeHeader = "## Quick Take"
+
+// MaxQuickTakeLen is the most of a quick take kept; longer ones are cut
+const MaxQuickTakeLen = 400
+
+// QuickTakeInstructions asks a review to open with a short summary of
+// itself, so no second pass is needed to summarize it. ExtractQuickTake
+// reads the answer back.
+const QuickTakeInstructions = `
+## Quick Take
+
+Begin your response with a section headed exactly "` + QuickTakeHeader + `": one
+paragraph of at most three sentences giving the overall verdict and the most
+important finding, if any, for a reader skimming a list of reviews. Then write
+the full review as usual under its own headings; do not shorten it because of
+the quick take.
+`
+
+// ExtractQuickTake splits the quick take section off a review's output. It
+// returns the review without the section and the quick take, which is ""
+// when the section is missing. The section ends at the next heading or
+// after its first paragraph.
+func ExtractQuickTake(output string) (review, quickTake string) {
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i, line := range lines {
+		if isQuickTakeHeader(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return output, ""
+	}
+
+	end := len(lines)
+	var body []string
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "#") || (trimmed == "" && len(body) > 0) {
+			end = i
+			break
+		}
+		if trimmed != "" {
+			body = append(body, trimmed)
+		}
+	}
+
+	rest := append(lines[:start:start], lines[end:]...)
+	review = strings.TrimSpace(strings.Join(rest, "\n"))
+	quickTake = strings.Join(body, " ")
+	if len(quickTake) > MaxQuickTakeLen {
+		quickTake = strings.ToValidUTF8(quickTake[:MaxQuickTakeLen-3], "") + "..."
+	}
+	return review, quickTake
+}
+
+// isQuickTakeHeader matches the section heading at any level, in any case
+// and with optional bold markers
+func isQuickTakeHeader(line string) bool {
+	s := strings.TrimSpace(line)
+	s = strings.TrimLeft(s, "#")
+	s = strings.Trim(strings.TrimSpace(s), "*:")
+	return strings.EqualFold(strings.TrimSpace(s), strings.TrimPrefix(QuickTakeHeader, "## "))
+}
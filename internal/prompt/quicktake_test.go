@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractQuickTake(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		wantReview    string
+		wantQuickTake string
+	}{
+		{
+			name:          "no section",
+			output:        "No issues found.",
+			wantReview:    "No issues found.",
+			wantQuickTake: "",
+		},
+		{
+			name:          "section before the review",
+			output:        "## Quick Take\n\nFails: the parser reads past\nthe buffer.\n\n## Findings\n\n- High - parser.go:10 off by one",
+			wantReview:    "## Findings\n\n- High - parser.go:10 off by one",
+			wantQuickTake: "Fails: the parser reads past the buffer.",
+		},
+		{
+			name:          "review follows without a heading",
+			output:        "**Quick take:**\nPasses; no issues.\n\nNo issues found.",
+			wantReview:    "No issues found.",
+			wantQuickTake: "Passes; no issues.",
+		},
+		{
+			name:          "preamble before the section",
+			output:        "Reviewing the diff.\n\n### Quick Take\nPasses.\n## Summary\nFine.",
+			wantReview:    "Reviewing the diff.\n\n## Summary\nFine.",
+			wantQuickTake: "Passes.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, quickTake := ExtractQuickTake(tt.output)
+			if review != tt.wantReview {
+				t.Errorf("review = %q, want %q", review, tt.wantReview)
+			}
+			if quickTake != tt.wantQuickTake {
+				t.Errorf("quick take = %q, want %q", quickTake, tt.wantQuickTake)
+			}
+		})
+	}
+}
+
+func TestExtractQuickTakeCutsLongSummary(t *testing.T) {
+	_, quickTake := ExtractQuickTake("## Quick Take\n" + strings.Repeat("word ", 200))
+	if len(quickTake) != MaxQuickTakeLen || !strings.HasSuffix(quickTake, "...") {
+		t.Errorf("quick take of %d bytes, want %d ending in ...", len(quickTake), MaxQuickTakeLen)
+	}
+}
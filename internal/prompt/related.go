@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RelatedReviewsHeader introduces earlier reviews with findings in the
+// files a change touches
+const RelatedReviewsHeader = `
+## Related Reviews
+
+The following are recent reviews of other changes in this repository with findings in
+files this change touches, newest first. Use them for continuity: check whether the
+change fixes, repeats or makes worse an issue raised before. Findings of addressed
+reviews were probably fixed already.
+`
+
+// maxRelatedFindingLen caps the length of each finding quoted in the
+// related reviews section
+const maxRelatedFindingLen = 400
+
+// WithRelatedReviews returns a builder whose prompts summarize up to n
+// recent reviews with findings in the files a change touches
+func (b *Builder) WithRelatedReviews(n int) *Builder {
+	related := *b
+	related.relatedReviews = n
+	return &related
+}
+
+// writeRelatedReviews writes summaries of recent reviews of the repo with
+// findings in files, other than reviews of gitRef itself
+func (b *Builder) writeRelatedReviews(sb *strings.Builder, repoID int64, gitRef string, files []string) {
+	if b.db == nil || repoID == 0 || b.relatedReviews <= 0 || len(files) == 0 || !b.includeHistory() {
+		return
+	}
+	reviews, err := b.db.ListRelatedReviews(repoID, files, gitRef, b.relatedReviews)
+	if err != nil || len(reviews) == 0 {
+		return
+	}
+
+	sb.WriteString(RelatedReviewsHeader)
+	sb.WriteString("\n")
+	for _, r := range reviews {
+		ref := r.GitRef
+		if len(ref) == 40 {
+			ref = ref[:7]
+		}
+		if r.Subject != "" {
+			ref += " " + r.Subject
+		}
+		var status []string
+		if r.Verdict != "" {
+			status = append(status, verdictWord(r.Verdict))
+		}
+		if r.Addressed {
+			status = append(status, "addressed")
+		}
+		status = append(status, r.CreatedAt.Format("2006-01-02"))
+		sb.WriteString(fmt.Sprintf("--- %s (%s) ---\n", ref, strings.Join(status, ", ")))
+		for _, f := range r.Findings {
+			text := strings.TrimLeft(strings.Join(strings.Fields(f.Text), " "), "-*• ")
+			if runes := []rune(text); len(runes) > maxRelatedFindingLen {
+				text = string(runes[:maxRelatedFindingLen]) + "..."
+			}
+			sb.WriteString("- " + text + "\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// verdictWord spells out a stored P/F verdict
+func verdictWord(verdict string) string {
+	switch verdict {
+	case "P":
+		return "passed"
+	case "F":
+		return "failed"
+	}
+	return verdict
+}
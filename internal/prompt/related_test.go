@@ -0,0 +1,52 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestBuildPromptWithRelatedReviews(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	db := testutil.OpenTestDB(t)
+
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo failed: %v", err)
+	}
+	for _, sha := range commits {
+		if _, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Test", "commit message", time.Now()); err != nil {
+			t.Fatalf("GetOrCreateCommit failed: %v", err)
+		}
+	}
+	testutil.CreateCompletedReview(t, db, repo.ID, commits[1], "test",
+		"Summary\n\n- High: unresolved race condition in file.txt:1\n- Low: style nit in other.go")
+
+	// Off by default
+	prompt, err := NewBuilder(db).Build(repoPath, commits[5], repo.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if strings.Contains(prompt, "## Related Reviews") {
+		t.Error("related reviews should only be included when enabled")
+	}
+
+	prompt, err = NewBuilder(db).WithRelatedReviews(3).Build(repoPath, commits[5], repo.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(prompt, "## Related Reviews") {
+		t.Fatal("prompt should contain the related reviews section")
+	}
+	if !strings.Contains(prompt, "--- "+commits[1][:7]+" commit message (failed, ") {
+		t.Errorf("related review should be introduced by its commit and verdict:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "\n- High: unresolved race condition in file.txt:1") {
+		t.Errorf("prompt should quote the finding in the touched file:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "style nit") {
+		t.Error("findings in other files should be left out")
+	}
+}
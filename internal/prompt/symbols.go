@@ -0,0 +1,221 @@
+package prompt
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// ChangedSymbolsHeader introduces the changed symbols in a review prompt
+const ChangedSymbolsHeader = `### Changed Symbols
+
+The diff below changes these functions, methods and types. Check how their
+callers and implementations are affected.
+
+`
+
+// maxPromptSymbols caps the changed symbols listed in a prompt
+const maxPromptSymbols = 50
+
+// RefChangedSymbols returns the symbols changed by a commit or range
+func RefChangedSymbols(repoPath, gitRef string) ([]storage.ChangedSymbol, error) {
+	diff, err := RefDiff(repoPath, gitRef)
+	if err != nil {
+		return nil, fmt.Errorf("get diff: %w", err)
+	}
+	rev := gitRef
+	if _, end, ok := git.ParseRange(gitRef); ok {
+		rev = end
+	}
+	return ChangedSymbols(repoPath, rev, diff), nil
+}
+
+// ChangedSymbols lists the functions, methods and types whose declarations
+// contain a line changed by diff, reading files as of ref. Go files are
+// parsed; other languages are matched on definition keywords (def, fn,
+// function, class, ...). Symbols are in diff order.
+func ChangedSymbols(repoPath, ref, diff string) []storage.ChangedSymbol {
+	var symbols []storage.ChangedSymbol
+	for _, file := range parseDiffFiles(diff) {
+		var names []string
+		switch {
+		case strings.HasSuffix(file.Path, ".go"):
+			content, err := git.ReadFile(repoPath, ref, file.Path)
+			if err != nil {
+				continue
+			}
+			names = goChangedSymbols(content, file.Lines)
+		case definitionLanguages[filepath.Ext(file.Path)]:
+			content, err := git.ReadFile(repoPath, ref, file.Path)
+			if err != nil || isBinary(content) {
+				continue
+			}
+			names = definitionChangedSymbols(string(content), file.Lines)
+		}
+		for _, name := range names {
+			symbols = append(symbols, storage.ChangedSymbol{Name: name, File: file.Path})
+		}
+	}
+	return symbols
+}
+
+// goChangedSymbols returns the top-level Go functions, methods and types
+// containing any of the given 1-based lines, in source order
+func goChangedSymbols(content []byte, lines []int) []string {
+	fset := token.NewFileSet()
+	// A partial AST is still useful for files that don't parse
+	f, _ := parser.ParseFile(fset, "", content, parser.ParseComments|parser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+
+	touched := func(from, to token.Pos) bool {
+		start, end := fset.Position(from).Line, fset.Position(to).Line
+		for _, n := range lines {
+			if n >= start && n <= end {
+				return true
+			}
+		}
+		return false
+	}
+
+	var names []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			from := d.Pos()
+			if d.Doc != nil {
+				from = d.Doc.Pos()
+			}
+			if !touched(from, d.End()) {
+				continue
+			}
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				if recv := receiverName(d.Recv.List[0].Type); recv != "" {
+					name = recv + "." + name
+				}
+			}
+			names = append(names, name)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				from, to := ts.Pos(), ts.End()
+				if len(d.Specs) == 1 {
+					// Include the doc comment and "type" keyword
+					from, to = d.Pos(), d.End()
+					if d.Doc != nil {
+						from = d.Doc.Pos()
+					}
+				}
+				if touched(from, to) {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// receiverName returns the type name of a method receiver, without pointer
+// or type parameters
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// definitionLanguages are the file extensions whose definitions start with
+// a keyword definitionLine recognizes
+var definitionLanguages = map[string]bool{
+	".py": true, ".rb": true, ".rs": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+	".ts": true, ".tsx": true, ".kt": true, ".kts": true, ".swift": true, ".php": true, ".scala": true,
+}
+
+// definitionLine matches a line defining a named function, class or type,
+// allowing modifiers such as export, pub or async before the keyword
+var definitionLine = regexp.MustCompile(`^(\s*)(?:[a-z]+(?:\([a-z]+\))?\s+)*(?:def|fn|func|fun|function|class|struct|enum|trait|interface|module|object)\s+([A-Za-z_$][\w$]*)`)
+
+// definitionChangedSymbols returns the definitions enclosing any of the
+// given 1-based lines. A line belongs to the nearest definition above it
+// that is indented less, so nested functions report the innermost name.
+// Symbols are returned in source order.
+func definitionChangedSymbols(content string, lines []int) []string {
+	src := strings.Split(content, "\n")
+	seen := make(map[int]bool)
+	for _, n := range lines {
+		if n < 1 || n > len(src) {
+			continue
+		}
+		indent := indentWidth(src[n-1])
+		if strings.TrimSpace(src[n-1]) == "" {
+			indent = -1 // Blank lines belong to the enclosing block
+		}
+		for i := n - 1; i >= 0; i-- {
+			m := definitionLine.FindStringSubmatch(src[i])
+			if m == nil {
+				continue
+			}
+			if i == n-1 || len(m[1]) < indent || indent < 0 {
+				seen[i] = true
+				break
+			}
+		}
+	}
+
+	defs := make([]int, 0, len(seen))
+	for i := range seen {
+		defs = append(defs, i)
+	}
+	sort.Ints(defs)
+	names := make([]string, 0, len(defs))
+	for _, i := range defs {
+		names = append(names, definitionLine.FindStringSubmatch(src[i])[2])
+	}
+	return names
+}
+
+// indentWidth counts leading spaces and tabs
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// writeChangedSymbols lists the symbols a diff changes, by name and file
+func writeChangedSymbols(sb *strings.Builder, symbols []storage.ChangedSymbol) {
+	if len(symbols) == 0 {
+		return
+	}
+	sb.WriteString(ChangedSymbolsHeader)
+	for i, s := range symbols {
+		if i == maxPromptSymbols {
+			fmt.Fprintf(sb, "- ... and %d more\n", len(symbols)-maxPromptSymbols)
+			break
+		}
+		fmt.Fprintf(sb, "- `%s` (%s)\n", s.Name, s.File)
+	}
+	sb.WriteString("\n")
+}
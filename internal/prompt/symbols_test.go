@@ -0,0 +1,156 @@
+package prompt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+const symbolsGoSource = `package config
+
+// Config holds settings
+type Config struct {
+	Path string
+}
+
+type (
+	A int
+	B int
+)
+
+// ParseConfig reads a config
+func ParseConfig(path string) *Config {
+	return &Config{Path: path}
+}
+
+func (c *Config) Load() error {
+	return nil
+}
+
+func (l loader[T]) Run() {}
+`
+
+func TestGoChangedSymbols(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []int
+		want  []string
+	}{
+		{"function body", []int{15}, []string{"ParseConfig"}},
+		{"doc comment", []int{13}, []string{"ParseConfig"}},
+		{"pointer method", []int{19}, []string{"Config.Load"}},
+		{"generic receiver", []int{22}, []string{"loader.Run"}},
+		{"type", []int{5}, []string{"Config"}},
+		{"grouped type", []int{10}, []string{"B"}},
+		{"several in source order", []int{19, 4}, []string{"Config", "Config.Load"}},
+		{"between declarations", []int{17}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := goChangedSymbols([]byte(symbolsGoSource), tt.lines)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("goChangedSymbols() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinitionChangedSymbols(t *testing.T) {
+	python := `import os
+
+class Loader:
+    def load(self):
+        def helper():
+            return 1
+        return helper()
+
+    async def close(self):
+        pass
+
+x = 1
+`
+	tests := []struct {
+		name    string
+		content string
+		lines   []int
+		want    []string
+	}{
+		{"nested function", python, []int{6}, []string{"helper"}},
+		{"method after nested function", python, []int{7}, []string{"load"}},
+		{"async method", python, []int{10}, []string{"close"}},
+		{"definition line", python, []int{3}, []string{"Loader"}},
+		{"top-level statement", python, []int{12}, nil},
+		{"rust modifiers", "pub(crate) fn parse() {\n    todo!()\n}\n", []int{2}, []string{"parse"}},
+		{"exported js function", "export default async function run() {\n  go();\n}\n", []int{2}, []string{"run"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := definitionChangedSymbols(tt.content, tt.lines)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("definitionChangedSymbols() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedSymbolsInPrompt(t *testing.T) {
+	repoPath, _ := setupTestRepo(t)
+	runGit := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	path := filepath.Join(repoPath, "config.go")
+	if err := os.WriteFile(path, []byte(symbolsGoSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit("add", "config.go")
+	runGit("commit", "-m", "add config")
+
+	changed := strings.Replace(symbolsGoSource, "return nil", "return os.ErrNotExist", 1)
+	if err := os.WriteFile(path, []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit("commit", "-am", "fail to load")
+	sha := runGit("rev-parse", "HEAD")
+
+	symbols, err := RefChangedSymbols(repoPath, sha)
+	if err != nil {
+		t.Fatalf("RefChangedSymbols: %v", err)
+	}
+	want := []storage.ChangedSymbol{{Name: "Config.Load", File: "config.go"}}
+	if !reflect.DeepEqual(symbols, want) {
+		t.Errorf("RefChangedSymbols() = %+v, want %+v", symbols, want)
+	}
+
+	prompt, err := BuildSimple(repoPath, sha, "")
+	if err != nil {
+		t.Fatalf("BuildSimple: %v", err)
+	}
+	if !strings.Contains(prompt, "### Changed Symbols") || !strings.Contains(prompt, "- `Config.Load` (config.go)") {
+		t.Errorf("expected changed symbols in prompt, got:\n%s", prompt)
+	}
+
+	// Ranges list the symbols changed across all commits
+	symbols, err = RefChangedSymbols(repoPath, sha+"~2.."+sha)
+	if err != nil {
+		t.Fatalf("RefChangedSymbols(range): %v", err)
+	}
+	if len(symbols) != 6 {
+		t.Errorf("expected every declaration of the new file, got %+v", symbols)
+	}
+}
@@ -1,10 +1,14 @@
 package prompt
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
 )
 
 //go:embed templates/*.tmpl
@@ -15,6 +19,41 @@ var templateFS embed.FS
 // Otherwise, it falls back to the default constant.
 // Supported prompt types: review, range, dirty, address, design-review, run, security
 func GetSystemPrompt(agentName string, promptType string) string {
+	tmpl := systemPromptTemplate(agentName, promptType)
+	if tmpl == "" {
+		return ""
+	}
+	return appendDateLine(tmpl)
+}
+
+// TemplateHash returns a short hash of the system prompt template used for
+// the agent and prompt type, or "" if there is none. The date line is
+// excluded so the hash only changes when the template itself does.
+func TemplateHash(agentName, promptType string) string {
+	tmpl := systemPromptTemplate(agentName, promptType)
+	if tmpl == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(tmpl))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// SystemPromptType returns the prompt type for a review of the given kind
+// (review, range or dirty), applying a non-default review type override.
+func SystemPromptType(kind, reviewType string) string {
+	promptType := kind
+	if !config.IsDefaultReviewType(reviewType) {
+		promptType = reviewType
+	}
+	if promptType == "design" {
+		promptType = "design-review"
+	}
+	return promptType
+}
+
+// systemPromptTemplate returns the system prompt template for the agent and
+// prompt type, without the date line.
+func systemPromptTemplate(agentName string, promptType string) string {
 	// Normalize agent name
 	agentName = strings.ToLower(agentName)
 	if agentName == "claude" {
@@ -32,7 +71,7 @@ func GetSystemPrompt(agentName string, promptType string) string {
 	tmplName := fmt.Sprintf("templates/%s_%s.tmpl", agentName, templateType)
 	content, err := templateFS.ReadFile(tmplName)
 	if err == nil {
-		return appendDateLine(string(content))
+		return string(content)
 	}
 
 	// Fallback to default constants
@@ -56,7 +95,7 @@ func GetSystemPrompt(agentName string, promptType string) string {
 	default:
 		base = SystemPromptSingle
 	}
-	return appendDateLine(base)
+	return base
 }
 
 // nowFunc is the time source for date lines in prompts. Override in tests.
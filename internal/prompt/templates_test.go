@@ -88,3 +88,38 @@ func TestNonGeminiRunReturnsEmpty(t *testing.T) {
 		}
 	}
 }
+
+func TestTemplateHash(t *testing.T) {
+	mockNow(t, time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC))
+	hash := TemplateHash("codex", "review")
+	if len(hash) != 12 {
+		t.Fatalf("expected 12-character hash, got %q", hash)
+	}
+
+	// The date line must not affect the hash
+	mockNow(t, time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC))
+	if got := TemplateHash("codex", "review"); got != hash {
+		t.Errorf("hash changed with date: %q != %q", got, hash)
+	}
+
+	if TemplateHash("codex", "security") == hash {
+		t.Error("expected different templates to hash differently")
+	}
+	if got := TemplateHash("codex", "run"); got != "" {
+		t.Errorf("expected no hash without a run template, got %q", got)
+	}
+}
+
+func TestSystemPromptType(t *testing.T) {
+	tests := []struct{ kind, reviewType, want string }{
+		{"review", "", "review"},
+		{"range", "default", "range"},
+		{"dirty", "security", "security"},
+		{"review", "design", "design-review"},
+	}
+	for _, tt := range tests {
+		if got := SystemPromptType(tt.kind, tt.reviewType); got != tt.want {
+			t.Errorf("SystemPromptType(%q, %q) = %q, want %q", tt.kind, tt.reviewType, got, tt.want)
+		}
+	}
+}
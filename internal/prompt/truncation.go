@@ -0,0 +1,28 @@
+package prompt
+
+// MaxTruncationLevel is the most aggressive truncation level a builder
+// supports; see WithTruncation
+const MaxTruncationLevel = 3
+
+// WithTruncation returns a builder whose prompts fit a smaller context
+// window, for retrying a review an agent rejected as too long. Each level
+// halves the size budget. Level 1 also leaves out project docs and the
+// history of earlier and related reviews; level 2 also leaves out file
+// context and blame, so what remains is the change itself.
+func (b *Builder) WithTruncation(level int) *Builder {
+	truncated := *b
+	truncated.truncation = min(max(level, 0), MaxTruncationLevel)
+	return &truncated
+}
+
+// includeHistory reports whether prompts include project docs and earlier
+// and related reviews
+func (b *Builder) includeHistory() bool {
+	return b.truncation < 1
+}
+
+// includeFileContext reports whether prompts include the content and blame
+// of the files a change touches
+func (b *Builder) includeFileContext() bool {
+	return b.truncation < 2
+}
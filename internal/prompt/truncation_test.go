@@ -0,0 +1,55 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithTruncation(t *testing.T) {
+	repoPath, commits := setupTestRepo(t)
+	targetSHA := commits[len(commits)-1]
+
+	if err := os.WriteFile(filepath.Join(repoPath, "ARCHITECTURE.md"), []byte("Keep handlers thin.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "[project_docs]\npaths = [\"ARCHITECTURE.md\"]\n\n[context_files]\nmode = \"full\"\n"
+	if err := os.WriteFile(filepath.Join(repoPath, ".roborev.toml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		level       int
+		wantDocs    bool
+		wantContext bool
+	}{
+		{0, true, true},
+		{1, false, true},
+		{2, false, false},
+		{MaxTruncationLevel + 5, false, false},
+	}
+	for _, tt := range tests {
+		b := NewBuilder(nil).WithTruncation(tt.level)
+		prompt, err := b.Build(repoPath, targetSHA, 0, 0, "", "")
+		if err != nil {
+			t.Fatalf("level %d: Build: %v", tt.level, err)
+		}
+		if got := strings.Contains(prompt, "## Project Documentation"); got != tt.wantDocs {
+			t.Errorf("level %d: project docs included = %v, want %v", tt.level, got, tt.wantDocs)
+		}
+		if got := strings.Contains(prompt, "### Related File Context"); got != tt.wantContext {
+			t.Errorf("level %d: file context included = %v, want %v", tt.level, got, tt.wantContext)
+		}
+		if !strings.Contains(prompt, "### Diff") {
+			t.Errorf("level %d: expected the diff in prompt", tt.level)
+		}
+	}
+
+	if got, want := NewBuilder(nil).WithTruncation(2).maxPromptSize(), MaxPromptSize/4; got != want {
+		t.Errorf("maxPromptSize() at level 2 = %d, want %d", got, want)
+	}
+	if got, want := NewBuilder(nil).Focus().WithTruncation(MaxTruncationLevel).maxPromptSize(), FocusMaxPromptSize/8; got != want {
+		t.Errorf("focused maxPromptSize() at max level = %d, want %d", got, want)
+	}
+}
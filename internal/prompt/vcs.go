@@ -0,0 +1,94 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/git"
+	"github.com/roborev-dev/roborev/internal/vcs"
+)
+
+// buildVCSPrompt constructs a prompt for a commit or range in a repository
+// that is not managed by git. Context that needs git, such as previous
+// reviews of ancestors, changed symbols and file context, is left out.
+func (b *Builder) buildVCSPrompt(v vcs.VCS, repoPath, ref, agentName, reviewType string) (string, error) {
+	start, end, isRange := git.ParseRange(ref)
+	var sb strings.Builder
+
+	kind := "review"
+	if isRange {
+		kind = "range"
+	}
+	sb.WriteString(GetSystemPrompt(agentName, SystemPromptType(kind, reviewType)))
+	sb.WriteString("\n")
+
+	// Add project-specific guidelines if configured
+	if repoCfg, err := config.LoadRepoConfig(repoPath); err == nil && repoCfg != nil {
+		b.writeProjectGuidelines(&sb, repoCfg.ReviewGuidelines)
+	}
+	b.writeProjectDocs(&sb, repoPath)
+	b.writeContextDocs(&sb)
+
+	// Include previous review attempts for this same ref (for re-reviews)
+	b.writePreviousAttemptsForGitRef(&sb, ref)
+
+	diffHeader := "### Diff\n\n"
+	viewCmd := fmt.Sprintf("%s diff -c %s", v.Name(), ref)
+	if isRange {
+		commits, err := v.NewCommits(repoPath, start, end)
+		if err != nil {
+			return "", fmt.Errorf("get range commits: %w", err)
+		}
+		sb.WriteString("## Commit Range\n\n")
+		sb.WriteString(fmt.Sprintf("Reviewing %d commits:\n\n", len(commits)))
+		for _, id := range commits {
+			if info, err := v.CommitInfo(repoPath, id); err == nil {
+				sb.WriteString(fmt.Sprintf("- %s %s\n", shortID(id), info.Subject))
+			} else {
+				sb.WriteString(fmt.Sprintf("- %s\n", shortID(id)))
+			}
+		}
+		sb.WriteString("\n")
+		diffHeader = "### Combined Diff\n\n"
+		viewCmd = fmt.Sprintf("%s diff -r %s -r %s", v.Name(), start, end)
+	} else {
+		info, err := v.CommitInfo(repoPath, ref)
+		if err != nil {
+			return "", fmt.Errorf("get commit info: %w", err)
+		}
+		sb.WriteString("## Current Commit\n\n")
+		sb.WriteString(fmt.Sprintf("**Commit:** %s\n", shortID(ref)))
+		sb.WriteString(fmt.Sprintf("**Author:** %s\n", info.Author))
+		sb.WriteString(fmt.Sprintf("**Subject:** %s\n", info.Subject))
+		if info.Body != "" {
+			sb.WriteString(fmt.Sprintf("\n**Message:**\n%s\n", info.Body))
+		}
+		sb.WriteString("\n")
+	}
+
+	diff, err := v.Diff(repoPath, ref)
+	if err != nil {
+		return "", fmt.Errorf("get diff: %w", err)
+	}
+	var diffSection strings.Builder
+	diffSection.WriteString(diffHeader)
+	writeUntrustedDiff(&diffSection, diff)
+
+	if sb.Len()+diffSection.Len() > b.maxPromptSize() {
+		sb.WriteString(diffHeader)
+		sb.WriteString("(Diff too large to include - please review the changes directly)\n")
+		sb.WriteString(fmt.Sprintf("View with: %s\n", viewCmd))
+	} else {
+		sb.WriteString(diffSection.String())
+	}
+	return sb.String(), nil
+}
+
+// shortID abbreviates a commit ID as the prompts show it
+func shortID(id string) string {
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
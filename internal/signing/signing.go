@@ -0,0 +1,109 @@
+// Package signing manages the local ed25519 key used to sign release
+// attestations and review records, and defines the signed review payload.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roborev-dev/roborev/internal/config"
+)
+
+// DefaultKeyPath returns the location of the local signing key.
+func DefaultKeyPath() string {
+	return filepath.Join(config.DataDir(), "attestation_ed25519")
+}
+
+// LoadOrCreateKey reads a hex-encoded ed25519 seed from path, generating and
+// persisting a new one (mode 0600) if the file is missing.
+func LoadOrCreateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("malformed key file %s", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(seed)+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// EncodePublicKey returns the base64 encoding of key's public half.
+func EncodePublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// Fingerprint returns a short, stable identifier for a base64-encoded
+// public key, or "unknown" if it can't be decoded.
+func Fingerprint(publicKey string) string {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "unknown"
+	}
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Verify checks a base64 signature over payload against a base64 public key.
+func Verify(publicKey, signature string, payload []byte) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), payload, sig) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Sign returns the base64 signature of payload.
+func Sign(key ed25519.PrivateKey, payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+}
+
+// ReviewRecord holds the review fields covered by a review signature.
+type ReviewRecord struct {
+	ReviewID   int64
+	ReviewUUID string
+	JobID      int64
+	GitRef     string
+	Agent      string
+	Prompt     string
+	Output     string
+}
+
+// Payload returns the canonical bytes that are signed for a review. The
+// prompt and output are included as SHA-256 digests so the payload stays
+// small while still covering their full content.
+func (r ReviewRecord) Payload() []byte {
+	promptSum := sha256.Sum256([]byte(r.Prompt))
+	outputSum := sha256.Sum256([]byte(r.Output))
+	return []byte(fmt.Sprintf("roborev-review-v1\nreview:%d\nreview_uuid:%s\njob:%d\ngit_ref:%s\nagent:%s\nprompt_sha256:%s\noutput_sha256:%s\n",
+		r.ReviewID, r.ReviewUUID, r.JobID, r.GitRef, r.Agent,
+		hex.EncodeToString(promptSum[:]), hex.EncodeToString(outputSum[:])))
+}
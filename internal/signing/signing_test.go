@@ -0,0 +1,70 @@
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "key")
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+	again, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("reload key: %v", err)
+	}
+	if !key.Equal(again) {
+		t.Error("expected persisted key to be reloaded")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat key: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected key mode 0600, got %o", info.Mode().Perm())
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("not hex"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKey(path); err == nil {
+		t.Error("expected error for malformed key file")
+	}
+}
+
+func TestReviewRecordSignature(t *testing.T) {
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+	rec := ReviewRecord{ReviewID: 1, ReviewUUID: "u1", JobID: 2, GitRef: "abc", Agent: "codex", Prompt: "p", Output: "No issues found."}
+	pub := EncodePublicKey(key)
+	sig := Sign(key, rec.Payload())
+
+	if err := Verify(pub, sig, rec.Payload()); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	tampered := rec
+	tampered.Output = "No issues found!"
+	if err := Verify(pub, sig, tampered.Payload()); err == nil {
+		t.Error("expected altered output to fail verification")
+	}
+	tampered = rec
+	tampered.JobID = 3
+	if err := Verify(pub, sig, tampered.Payload()); err == nil {
+		t.Error("expected altered job to fail verification")
+	}
+	if err := Verify("bogus", sig, rec.Payload()); err == nil {
+		t.Error("expected invalid public key to fail")
+	}
+	if fp := Fingerprint(pub); len(fp) != 16 {
+		t.Errorf("unexpected fingerprint %q", fp)
+	}
+}
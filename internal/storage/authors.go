@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AuthorAlias maps a name or email commits were authored under to the
+// author it stands for, on top of what each repo's .mailmap already maps
+type AuthorAlias struct {
+	Alias     string    `json:"alias"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// canonicalAuthor is the SQL expression for the author of commit c after
+// aliasing. Aliases match case-insensitively.
+const canonicalAuthor = `COALESCE((SELECT aa.author FROM author_aliases aa WHERE aa.alias = c.author), c.author)`
+
+// canonicalAuthorArg is the SQL expression for the author a ? argument
+// stands for, so filters match an author by any of their aliases
+const canonicalAuthorArg = `COALESCE((SELECT aa.author FROM author_aliases aa WHERE aa.alias = ?), ?)`
+
+// SetAuthorAlias makes alias stand for author. Aliases do not chain: an
+// author that is itself an alias is resolved first, and aliases of alias
+// are moved over to its new author.
+func (db *DB) SetAuthorAlias(alias, author string) error {
+	alias, author = strings.TrimSpace(alias), strings.TrimSpace(author)
+	if alias == "" || author == "" {
+		return errors.New("alias and author are required")
+	}
+	canonical, err := db.CanonicalAuthor(author)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(canonical, alias) {
+		return errors.New("an author cannot be an alias of itself")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO author_aliases (alias, author, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(alias) DO UPDATE SET author = excluded.author, created_at = excluded.created_at
+	`, alias, canonical, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE author_aliases SET author = ? WHERE author = ? COLLATE NOCASE`, canonical, alias); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteAuthorAlias removes an alias, reporting whether it existed
+func (db *DB) DeleteAuthorAlias(alias string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM author_aliases WHERE alias = ?`, strings.TrimSpace(alias))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListAuthorAliases returns every alias, grouped by the author they stand for
+func (db *DB) ListAuthorAliases() ([]AuthorAlias, error) {
+	rows, err := db.Query(`SELECT alias, author, created_at FROM author_aliases ORDER BY author, alias`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []AuthorAlias
+	for rows.Next() {
+		var a AuthorAlias
+		var createdAt string
+		if err := rows.Scan(&a.Alias, &a.Author, &createdAt); err != nil {
+			return nil, err
+		}
+		a.CreatedAt = parseSQLiteTime(createdAt)
+		aliases = append(aliases, a)
+	}
+	return aliases, rows.Err()
+}
+
+// CanonicalAuthor returns the author that name stands for, or name itself
+// when it is not an alias
+func (db *DB) CanonicalAuthor(name string) (string, error) {
+	var author string
+	err := db.QueryRow(`SELECT `+canonicalAuthorArg, name, name).Scan(&author)
+	return author, err
+}
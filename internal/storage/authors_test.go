@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthorAliases(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/authors-repo")
+	for i, author := range []string{"Jane Doe", "Jane D", "jane.doe@corp", "Bob"} {
+		sha := string(rune('a' + i))
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, author, "Subject", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.SetAuthorAlias("Jane D", "Jane Doe"); err != nil {
+		t.Fatalf("SetAuthorAlias: %v", err)
+	}
+	// Aliasing to an alias resolves to the author it stands for
+	if err := db.SetAuthorAlias("JANE.DOE@corp", "jane d"); err != nil {
+		t.Fatalf("SetAuthorAlias: %v", err)
+	}
+	if err := db.SetAuthorAlias("Jane Doe", "Jane D"); err == nil {
+		t.Error("expected an error aliasing an author to itself")
+	}
+
+	aliases, err := db.ListAuthorAliases()
+	if err != nil {
+		t.Fatalf("ListAuthorAliases: %v", err)
+	}
+	if len(aliases) != 2 || aliases[0].Author != "Jane Doe" || aliases[1].Author != "Jane Doe" {
+		t.Errorf("unexpected aliases %+v", aliases)
+	}
+
+	counts, err := db.CountJobs(t.Context(), CountByAuthor, JobCountFilter{})
+	if err != nil {
+		t.Fatalf("CountJobs: %v", err)
+	}
+	if counts["Jane Doe"] != 3 || counts["Bob"] != 1 || len(counts) != 2 {
+		t.Errorf("unexpected counts by author %v", counts)
+	}
+
+	// Filters match an author under any of their names
+	for _, name := range []string{"Jane Doe", "jane.doe@corp"} {
+		jobs, err := db.ListJobs(t.Context(), "", "", 0, 0, WithAuthor(name))
+		if err != nil {
+			t.Fatalf("ListJobs: %v", err)
+		}
+		if len(jobs) != 3 {
+			t.Errorf("WithAuthor(%q): expected 3 jobs, got %d", name, len(jobs))
+		}
+		counts, err := db.CountJobs(t.Context(), CountTotal, JobCountFilter{Author: name})
+		if err != nil {
+			t.Fatalf("CountJobs: %v", err)
+		}
+		if counts.Total() != 3 {
+			t.Errorf("Author %q: expected 3 jobs, got %d", name, counts.Total())
+		}
+	}
+
+	// Re-aliasing the author moves its aliases along, so they never chain
+	if err := db.SetAuthorAlias("Jane Doe", "J. Doe"); err != nil {
+		t.Fatalf("SetAuthorAlias: %v", err)
+	}
+	if got, err := db.CanonicalAuthor("Jane D"); err != nil || got != "J. Doe" {
+		t.Errorf("CanonicalAuthor(Jane D) = %q, %v; want J. Doe", got, err)
+	}
+
+	if deleted, err := db.DeleteAuthorAlias("jane d"); err != nil || !deleted {
+		t.Errorf("DeleteAuthorAlias = %v, %v; want true", deleted, err)
+	}
+	if deleted, err := db.DeleteAuthorAlias("nobody"); err != nil || deleted {
+		t.Errorf("DeleteAuthorAlias(nobody) = %v, %v; want false", deleted, err)
+	}
+	if got, err := db.CanonicalAuthor("Jane D"); err != nil || got != "Jane D" {
+		t.Errorf("CanonicalAuthor after delete = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"modernc.org/sqlite"
+)
+
+// sqliteBackuper is implemented by the driver connections of modernc.org/sqlite
+type sqliteBackuper interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// backupPagesPerStep is how many pages an online backup or restore copies
+// at a time, so writers are not locked out for the whole copy
+const backupPagesPerStep = 1024
+
+// BackupTo writes a consistent copy of the database to path using SQLite's
+// online backup API, which is safe while the daemon is writing. An existing
+// file at path is overwritten. Cancelling ctx abandons the copy between
+// steps.
+func (db *DB) BackupTo(ctx context.Context, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove old backup: %w", err)
+	}
+	return runBackup(ctx, db.DB, func(b sqliteBackuper) (*sqlite.Backup, error) {
+		return b.NewBackup(path)
+	})
+}
+
+// RestoreFrom replaces the contents of the database at dbPath with the
+// SQLite database at srcPath. Nothing else may have the database open.
+func RestoreFrom(dbPath, srcPath string) error {
+	if _, err := os.Stat(srcPath); err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	srcDSN := (&url.URL{Scheme: "file", Path: filepath.ToSlash(srcPath), RawQuery: "mode=ro"}).String()
+	src, err := sql.Open("sqlite", srcDSN)
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	var check string
+	err = src.QueryRow(`PRAGMA quick_check`).Scan(&check)
+	src.Close()
+	if err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("backup is corrupt: %s", check)
+	}
+
+	dst, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(30000)")
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dst.Close()
+	return runBackup(context.Background(), dst, func(b sqliteBackuper) (*sqlite.Backup, error) {
+		return b.NewRestore(srcPath)
+	})
+}
+
+// runBackup copies every page of a backup or restore started by start on
+// one of sqlDB's connections
+func runBackup(ctx context.Context, sqlDB *sql.DB, start func(sqliteBackuper) (*sqlite.Backup, error)) error {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		b, ok := driverConn.(sqliteBackuper)
+		if !ok {
+			return fmt.Errorf("database driver does not support online backup")
+		}
+		backup, err := start(b)
+		if err != nil {
+			return err
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				backup.Finish()
+				return err
+			}
+			more, err := backup.Step(backupPagesPerStep)
+			if err != nil {
+				backup.Finish()
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+		return backup.Finish()
+	})
+}
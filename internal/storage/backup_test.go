@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "reviews.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := createRepo(t, db, "/tmp/backup-repo")
+	commit := createCommit(t, db, repo.ID, "bk1")
+	enqueueJob(t, db, repo.ID, commit.ID, "bk1")
+
+	backupPath := filepath.Join(dir, "backup.db")
+	// Backing up to an existing file replaces it
+	for range 2 {
+		if err := db.BackupTo(t.Context(), backupPath); err != nil {
+			t.Fatalf("BackupTo: %v", err)
+		}
+	}
+
+	// Jobs added after the backup are gone once it is restored
+	commit2 := createCommit(t, db, repo.ID, "bk2")
+	enqueueJob(t, db, repo.ID, commit2.ID, "bk2")
+	db.Close()
+
+	if err := RestoreFrom(dbPath, backupPath); err != nil {
+		t.Fatalf("RestoreFrom: %v", err)
+	}
+
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("open restored db: %v", err)
+	}
+	defer db.Close()
+	jobs, err := db.ListJobs(t.Context(), "", "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].GitRef != "bk1" {
+		t.Errorf("expected only the backed up job, got %+v", jobs)
+	}
+
+	if err := RestoreFrom(dbPath, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected error restoring a missing backup")
+	}
+}
+
+func TestBackupToCanceled(t *testing.T) {
+	db := openTestDB(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := db.BackupTo(ctx, filepath.Join(t.TempDir(), "backup.db")); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
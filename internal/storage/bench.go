@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"time"
+)
+
+// BenchRun is one agent's run over a benchmark suite of known-buggy diffs
+type BenchRun struct {
+	ID             int64         `json:"id"`
+	Suite          string        `json:"suite"` // Absolute path of the suite directory
+	Agent          string        `json:"agent"`
+	Model          string        `json:"model,omitempty"`
+	PromptHash     string        `json:"prompt_hash,omitempty"` // Hash of the system prompt template used
+	RoborevVersion string        `json:"roborev_version"`
+	Cases          int           `json:"cases"`
+	Expected       int           `json:"expected"`     // Expected findings over all cases
+	Detected       int           `json:"detected"`     // Expected findings the agent reported
+	Extra          int           `json:"extra"`        // Reported findings that matched nothing expected
+	FalseAlarms    int           `json:"false_alarms"` // Clean cases the agent failed
+	Errors         int           `json:"errors"`       // Cases the agent could not review
+	DurationMs     int64         `json:"duration_ms"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Results        []BenchResult `json:"results,omitempty"`
+}
+
+// BenchResult is the score of one case of a benchmark run
+type BenchResult struct {
+	Case       string `json:"case"`
+	Expected   int    `json:"expected"`
+	Detected   int    `json:"detected"`
+	Extra      int    `json:"extra"`
+	Verdict    string `json:"verdict,omitempty"` // "P" or "F"; empty if the review failed
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Recall is the share of expected findings detected, or 1 when the suite
+// expects none
+func (r BenchRun) Recall() float64 {
+	if r.Expected == 0 {
+		return 1
+	}
+	return float64(r.Detected) / float64(r.Expected)
+}
+
+// SaveBenchRun stores a benchmark run and its results, setting run.ID
+func (db *DB) SaveBenchRun(run *BenchRun) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+	result, err := tx.Exec(`
+		INSERT INTO bench_runs (suite, agent, model, prompt_hash, roborev_version, cases, expected, detected,
+			extra, false_alarms, errors, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.Suite, run.Agent, run.Model, run.PromptHash, run.RoborevVersion, run.Cases, run.Expected, run.Detected,
+		run.Extra, run.FalseAlarms, run.Errors, run.DurationMs, run.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, r := range run.Results {
+		if _, err := tx.Exec(`
+			INSERT INTO bench_results (run_id, case_name, expected, detected, extra, verdict, error, duration_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, r.Case, r.Expected, r.Detected, r.Extra, r.Verdict, r.Error, r.DurationMs); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	run.ID = id
+	return nil
+}
+
+const benchRunColumns = `id, suite, agent, model, prompt_hash, roborev_version, cases, expected, detected,
+	extra, false_alarms, errors, duration_ms, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBenchRun(row rowScanner) (BenchRun, error) {
+	var run BenchRun
+	var createdAt string
+	err := row.Scan(&run.ID, &run.Suite, &run.Agent, &run.Model, &run.PromptHash, &run.RoborevVersion,
+		&run.Cases, &run.Expected, &run.Detected, &run.Extra, &run.FalseAlarms, &run.Errors, &run.DurationMs,
+		&createdAt)
+	run.CreatedAt = parseSQLiteTime(createdAt)
+	return run, err
+}
+
+// ListBenchRuns returns benchmark runs newest first, limited to a suite
+// when suite is not empty. limit <= 0 returns all runs.
+func (db *DB) ListBenchRuns(suite string, limit int) ([]BenchRun, error) {
+	query := `SELECT ` + benchRunColumns + ` FROM bench_runs`
+	var args []any
+	if suite != "" {
+		query += ` WHERE suite = ?`
+		args = append(args, suite)
+	}
+	query += ` ORDER BY id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []BenchRun
+	for rows.Next() {
+		run, err := scanBenchRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetBenchRun returns a benchmark run with its results in case order.
+// Returns sql.ErrNoRows if there is no such run.
+func (db *DB) GetBenchRun(id int64) (*BenchRun, error) {
+	run, err := scanBenchRun(db.QueryRow(`SELECT `+benchRunColumns+` FROM bench_runs WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT case_name, expected, detected, extra, verdict, error, duration_ms
+		FROM bench_results WHERE run_id = ? ORDER BY case_name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r BenchResult
+		if err := rows.Scan(&r.Case, &r.Expected, &r.Detected, &r.Extra, &r.Verdict, &r.Error, &r.DurationMs); err != nil {
+			return nil, err
+		}
+		run.Results = append(run.Results, r)
+	}
+	return &run, rows.Err()
+}
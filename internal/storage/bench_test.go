@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestBenchRuns(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	first := BenchRun{
+		Suite: "/suites/a", Agent: "codex", RoborevVersion: "v1.0.0", PromptHash: "abc",
+		Cases: 2, Expected: 3, Detected: 1, Extra: 2, DurationMs: 1500,
+		Results: []BenchResult{
+			{Case: "z-case", Expected: 2, Detected: 1, Extra: 2, Verdict: "F", DurationMs: 1000},
+			{Case: "a-case", Expected: 1, Error: "timeout", DurationMs: 500},
+		},
+	}
+	if err := db.SaveBenchRun(&first); err != nil {
+		t.Fatalf("SaveBenchRun: %v", err)
+	}
+	if first.ID == 0 {
+		t.Fatal("SaveBenchRun did not set the ID")
+	}
+	second := BenchRun{Suite: "/suites/b", Agent: "claude-code", Model: "opus", RoborevVersion: "v1.0.0", Cases: 1}
+	if err := db.SaveBenchRun(&second); err != nil {
+		t.Fatalf("SaveBenchRun: %v", err)
+	}
+
+	runs, err := db.ListBenchRuns("", 0)
+	if err != nil {
+		t.Fatalf("ListBenchRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != second.ID || runs[1].ID != first.ID {
+		t.Fatalf("expected newest first, got %+v", runs)
+	}
+	runs, err = db.ListBenchRuns("/suites/a", 10)
+	if err != nil {
+		t.Fatalf("ListBenchRuns: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Agent != "codex" || runs[0].Detected != 1 || runs[0].Results != nil {
+		t.Errorf("unexpected runs for suite: %+v", runs)
+	}
+
+	got, err := db.GetBenchRun(first.ID)
+	if err != nil {
+		t.Fatalf("GetBenchRun: %v", err)
+	}
+	if got.PromptHash != "abc" || got.Expected != 3 || got.CreatedAt.IsZero() || len(got.Results) != 2 {
+		t.Fatalf("unexpected run: %+v", got)
+	}
+	if got.Results[0].Case != "a-case" || got.Results[0].Error != "timeout" || got.Results[1].Verdict != "F" {
+		t.Errorf("unexpected results: %+v", got.Results)
+	}
+	if r := got.Recall(); r < 0.33 || r > 0.34 {
+		t.Errorf("Recall() = %v, want 1/3", r)
+	}
+
+	if _, err := db.GetBenchRun(9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing run, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/blobstore"
+)
+
+// blobRefPrefix marks a prompt or output column whose content was offloaded
+// to the blob store. Output references also carry the review's verdict so
+// job lists can show it without fetching the blob:
+//
+//	roborev-blob:sha256/<hex>
+//	roborev-blob:sha256/<hex>?verdict=F
+const blobRefPrefix = "roborev-blob:"
+
+// blobCacheSize is how many loaded blobs are kept in memory
+const blobCacheSize = 32
+
+type blobOffload struct {
+	store     blobstore.Store
+	threshold int
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// SetBlobStore enables offloading of review prompts and outputs larger than
+// threshold bytes to store. Offloaded values are replaced in the database by
+// references, which are resolved transparently on read. Call before the DB
+// is shared; references written earlier stay readable only while the same
+// store is configured.
+func (db *DB) SetBlobStore(store blobstore.Store, threshold int) {
+	if store == nil {
+		db.blobs = nil
+		return
+	}
+	db.blobs = &blobOffload{store: store, threshold: threshold, cache: make(map[string]string)}
+}
+
+// IsBlobRef reports whether a stored prompt or output is a blob reference
+func IsBlobRef(s string) bool {
+	return strings.HasPrefix(s, blobRefPrefix)
+}
+
+// parseBlobRef splits a reference into its blob key and recorded verdict
+func parseBlobRef(ref string) (key, verdict string) {
+	key, query, _ := strings.Cut(strings.TrimPrefix(ref, blobRefPrefix), "?")
+	verdict, _ = strings.CutPrefix(query, "verdict=")
+	return key, verdict
+}
+
+// offloadBlob stores s in the blob store when it exceeds the threshold and
+// returns the reference to save in its place. verdict is recorded in the
+// reference when non-empty. Values are returned unchanged when offloading
+// is disabled, below the threshold, or the upload fails.
+func (db *DB) offloadBlob(s, verdict string) string {
+	b := db.blobs
+	if b == nil || len(s) <= b.threshold {
+		return s
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	hash := hex.EncodeToString(sum[:])
+	key := "sha256/" + hash[:2] + "/" + hash
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := b.store.Put(ctx, key, []byte(s)); err != nil {
+		log.Printf("Blob store: keeping %d bytes in database: %v", len(s), err)
+		return s
+	}
+	b.remember(key, s)
+
+	ref := blobRefPrefix + key
+	if verdict != "" {
+		ref += "?verdict=" + verdict
+	}
+	return ref
+}
+
+// loadBlob returns the content for a blob reference, or s itself if it is
+// not a reference. A reference that cannot be loaded is returned as-is so
+// it is never replaced by a placeholder when copied elsewhere.
+func (db *DB) loadBlob(s string) string {
+	if !IsBlobRef(s) {
+		return s
+	}
+	b := db.blobs
+	if b == nil {
+		return s
+	}
+
+	key, _ := parseBlobRef(s)
+	b.mu.Lock()
+	content, ok := b.cache[key]
+	b.mu.Unlock()
+	if ok {
+		return content
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	data, err := b.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("Blob store: load %s: %v", key, err)
+		return s
+	}
+	content = string(data)
+	b.remember(key, content)
+	return content
+}
+
+// loadReviewBlobs resolves offloaded prompt and output of a review
+func (db *DB) loadReviewBlobs(r *Review) {
+	r.Prompt = db.loadBlob(r.Prompt)
+	r.Output = db.loadBlob(r.Output)
+}
+
+// outputVerdict returns the verdict for a review output, using the verdict
+// recorded in a blob reference instead of fetching the blob.
+func (db *DB) outputVerdict(output string) string {
+	if IsBlobRef(output) {
+		if _, verdict := parseBlobRef(output); verdict != "" {
+			return verdict
+		}
+	}
+	return ParseVerdict(db.loadBlob(output))
+}
+
+func (b *blobOffload) remember(key, content string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.cache) >= blobCacheSize {
+		for k := range b.cache {
+			delete(b.cache, k)
+			break
+		}
+	}
+	b.cache[key] = content
+}
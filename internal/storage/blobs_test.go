@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/blobstore"
+)
+
+// countingStore wraps a store and counts Get calls
+type countingStore struct {
+	blobstore.Store
+	gets atomic.Int32
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.gets.Add(1)
+	return s.Store.Get(ctx, key)
+}
+
+func TestBlobOffload(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	store := &countingStore{Store: blobstore.NewFileStore(t.TempDir())}
+	db.SetBlobStore(store, 100)
+
+	repo := createRepo(t, db, "/tmp/blob-repo")
+	commit := createCommit(t, db, repo.ID, "blobsha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "blobsha")
+	claimJob(t, db, "worker")
+
+	prompt := strings.Repeat("diff line\n", 50)
+	output := "- High: leak\n" + strings.Repeat("details\n", 50)
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", prompt, output); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+
+	var storedPrompt, storedOutput, jobPrompt string
+	if err := db.QueryRow(`SELECT prompt, output FROM reviews WHERE job_id = ?`, job.ID).Scan(&storedPrompt, &storedOutput); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT prompt FROM review_jobs WHERE id = ?`, job.ID).Scan(&jobPrompt); err != nil {
+		t.Fatal(err)
+	}
+	if !IsBlobRef(storedPrompt) || !IsBlobRef(storedOutput) || jobPrompt != storedPrompt {
+		t.Fatalf("expected references in the database, got prompt=%q output=%q job prompt=%q",
+			storedPrompt, storedOutput, jobPrompt)
+	}
+	if !strings.HasSuffix(storedOutput, "?verdict=F") {
+		t.Errorf("expected output reference to record the verdict, got %q", storedOutput)
+	}
+
+	// Listing jobs uses the recorded verdict without fetching blobs
+	db.blobs.cache = make(map[string]string)
+	jobs, err := db.ListJobs(t.Context(), "", "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Verdict == nil || *jobs[0].Verdict != "F" || jobs[0].Prompt != "" {
+		t.Errorf("unexpected listed job: %+v", jobs)
+	}
+	if n := store.gets.Load(); n != 0 {
+		t.Errorf("expected no blob fetches for listing, got %d", n)
+	}
+
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	if review.Prompt != prompt || review.Output != output {
+		t.Error("expected offloaded prompt and output to be loaded transparently")
+	}
+	got, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil || got.Prompt != prompt {
+		t.Errorf("expected job prompt to be loaded, got %v", err)
+	}
+
+	// Without the store, references are returned unchanged
+	db.SetBlobStore(nil, 0)
+	review, err = db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil || review.Output != storedOutput {
+		t.Errorf("expected raw reference without a store, got %q (%v)", review.Output, err)
+	}
+}
+
+func TestBlobOffloadBelowThreshold(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	db.SetBlobStore(blobstore.NewFileStore(t.TempDir()), 1000)
+
+	repo := createRepo(t, db, "/tmp/blob-small")
+	commit := createCommit(t, db, repo.ID, "smallsha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "smallsha")
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "short prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+
+	var storedOutput string
+	if err := db.QueryRow(`SELECT output FROM reviews WHERE job_id = ?`, job.ID).Scan(&storedOutput); err != nil {
+		t.Fatal(err)
+	}
+	if storedOutput != "No issues found." {
+		t.Errorf("expected small output to stay in the database, got %q", storedOutput)
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import "time"
+
+// BrokenRepo is a registered repo with jobs blocked until the cause, such as
+// its path no longer existing, is fixed
+type BrokenRepo struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	RootPath    string `json:"root_path"`
+	BlockedJobs int    `json:"blocked_jobs"`
+	Reason      string `json:"reason"`
+}
+
+// ListReposWithQueuedJobs returns the repos that have jobs waiting to run
+func (db *DB) ListReposWithQueuedJobs() ([]Repo, error) {
+	rows, err := db.Query(`
+		SELECT id, root_path, name, created_at FROM repos
+		WHERE id IN (SELECT repo_id FROM review_jobs WHERE status = 'queued')
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repo
+	for rows.Next() {
+		var r Repo
+		var createdAt string
+		if err := rows.Scan(&r.ID, &r.RootPath, &r.Name, &createdAt); err != nil {
+			return nil, err
+		}
+		r.CreatedAt = parseSQLiteTime(createdAt)
+		repos = append(repos, r)
+	}
+	return repos, rows.Err()
+}
+
+// BlockRepoJobs marks a repo's queued jobs as blocked with the given reason,
+// returning how many were blocked
+func (db *DB) BlockRepoJobs(repoID int64, reason string) (int64, error) {
+	now := time.Now().Format(time.RFC3339)
+	result, err := db.Exec(`
+		UPDATE review_jobs SET status = 'blocked', error = ?, updated_at = ?
+		WHERE repo_id = ? AND status = 'queued'
+	`, reason, now, repoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// BlockJob marks a claimed job as blocked with the given reason
+func (db *DB) BlockJob(jobID int64, reason string) error {
+	now := time.Now().Format(time.RFC3339)
+	_, err := db.Exec(`
+		UPDATE review_jobs SET status = 'blocked', error = ?, worker_id = NULL, started_at = NULL, updated_at = ?
+		WHERE id = ? AND status = 'running'
+	`, reason, now, jobID)
+	return err
+}
+
+// ListBlockedJobIDs returns the IDs of blocked jobs, oldest first
+func (db *DB) ListBlockedJobIDs() ([]int64, error) {
+	rows, err := db.Query(`SELECT id FROM review_jobs WHERE status = 'blocked' ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UnblockJob returns a blocked job to the queue, reporting whether it was
+// blocked
+func (db *DB) UnblockJob(jobID int64) (bool, error) {
+	now := time.Now().Format(time.RFC3339)
+	result, err := db.Exec(`
+		UPDATE review_jobs SET status = 'queued', error = NULL, updated_at = ?
+		WHERE id = ? AND status = 'blocked'
+	`, now, jobID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		db.NotifyQueueChanged()
+	}
+	return n > 0, nil
+}
+
+// ListBrokenRepos returns the repos with blocked jobs, by name
+func (db *DB) ListBrokenRepos() ([]BrokenRepo, error) {
+	rows, err := db.Query(`
+		SELECT r.id, r.name, r.root_path, COUNT(*), MAX(COALESCE(j.error, ''))
+		FROM review_jobs j
+		JOIN repos r ON r.id = j.repo_id
+		WHERE j.status = 'blocked'
+		GROUP BY r.id
+		ORDER BY r.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []BrokenRepo
+	for rows.Next() {
+		var br BrokenRepo
+		if err := rows.Scan(&br.ID, &br.Name, &br.RootPath, &br.BlockedJobs, &br.Reason); err != nil {
+			return nil, err
+		}
+		repos = append(repos, br)
+	}
+	return repos, rows.Err()
+}
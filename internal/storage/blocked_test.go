@@ -0,0 +1,37 @@
+package storage
+
+import "testing"
+
+func TestBlockRepoJobs(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/blocked-repo")
+	other := createRepo(t, db, "/tmp/other-repo")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "aaa").ID, "aaa")
+	enqueueJob(t, db, other.ID, createCommit(t, db, other.ID, "bbb").ID, "bbb")
+
+	repos, err := db.ListReposWithQueuedJobs()
+	if err != nil || len(repos) != 2 {
+		t.Fatalf("ListReposWithQueuedJobs = %+v, %v", repos, err)
+	}
+
+	if n, err := db.BlockRepoJobs(repo.ID, "repository path /tmp/blocked-repo no longer exists"); err != nil || n != 1 {
+		t.Fatalf("BlockRepoJobs = %d, %v", n, err)
+	}
+	if claimed, err := db.ClaimJob(t.Context(), "worker-1"); err != nil || claimed == nil || claimed.GitRef != "bbb" {
+		t.Fatalf("expected only the other repo's job to be claimable, got %+v, %v", claimed, err)
+	}
+	repos, _ = db.ListReposWithQueuedJobs()
+	if len(repos) != 0 {
+		t.Errorf("expected no repos with queued jobs, got %+v", repos)
+	}
+
+	// Blocked jobs can be canceled
+	if err := db.CancelJob(t.Context(), job.ID); err != nil {
+		t.Errorf("CancelJob of blocked job: %v", err)
+	}
+	if broken, _ := db.ListBrokenRepos(); len(broken) != 0 {
+		t.Errorf("expected no broken repos after cancel, got %+v", broken)
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Sources of a repo's tracked branches
+const (
+	BranchSourceConfig = "config" // The repo's .roborev.toml
+	BranchSourceGitHub = "github" // The GitHub API
+	BranchSourceGit    = "git"    // Detected from the local clone
+)
+
+// RepoBranches are a repo's default and protected branches as last
+// resolved by the daemon
+type RepoBranches struct {
+	DefaultBranch string    `json:"default_branch"`
+	Protected     []string  `json:"protected_branches"` // Names or glob patterns
+	Source        string    `json:"source"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SetRepoBranches records a repo's default and protected branches
+func (db *DB) SetRepoBranches(repoID int64, b RepoBranches) error {
+	protected := b.Protected
+	if protected == nil {
+		protected = []string{}
+	}
+	data, err := json.Marshal(protected)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO repo_branches (repo_id, default_branch, protected_branches, source, updated_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
+		ON CONFLICT(repo_id) DO UPDATE SET default_branch = excluded.default_branch,
+			protected_branches = excluded.protected_branches, source = excluded.source,
+			updated_at = excluded.updated_at
+	`, repoID, b.DefaultBranch, string(data), b.Source)
+	return err
+}
+
+// GetRepoBranches returns a repo's recorded branches, or nil if none were
+// recorded
+func (db *DB) GetRepoBranches(repoID int64) (*RepoBranches, error) {
+	var b RepoBranches
+	var protected, updatedAt string
+	err := db.QueryRow(`
+		SELECT default_branch, protected_branches, source, updated_at FROM repo_branches WHERE repo_id = ?
+	`, repoID).Scan(&b.DefaultBranch, &protected, &b.Source, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(protected), &b.Protected); err != nil {
+		return nil, err
+	}
+	b.UpdatedAt = parseSQLiteTime(updatedAt)
+	return &b, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRepoBranches(t *testing.T) {
+	db := openTestDB(t)
+	repo := createRepo(t, db, "/tmp/branches-repo")
+
+	if b, err := db.GetRepoBranches(repo.ID); err != nil || b != nil {
+		t.Fatalf("expected no branches recorded, got %+v, %v", b, err)
+	}
+
+	if err := db.SetRepoBranches(repo.ID, RepoBranches{DefaultBranch: "main", Source: BranchSourceGit}); err != nil {
+		t.Fatalf("SetRepoBranches: %v", err)
+	}
+	b, err := db.GetRepoBranches(repo.ID)
+	if err != nil {
+		t.Fatalf("GetRepoBranches: %v", err)
+	}
+	if b.DefaultBranch != "main" || b.Source != BranchSourceGit || len(b.Protected) != 0 || b.UpdatedAt.IsZero() {
+		t.Errorf("unexpected branches %+v", b)
+	}
+
+	// A later fetch replaces them
+	if err := db.SetRepoBranches(repo.ID, RepoBranches{
+		DefaultBranch: "trunk", Protected: []string{"trunk", "release/*"}, Source: BranchSourceGitHub,
+	}); err != nil {
+		t.Fatalf("SetRepoBranches: %v", err)
+	}
+	b, _ = db.GetRepoBranches(repo.ID)
+	if b.DefaultBranch != "trunk" || b.Source != BranchSourceGitHub || !slices.Equal(b.Protected, []string{"trunk", "release/*"}) {
+		t.Errorf("unexpected branches after update %+v", b)
+	}
+
+	// Deleting the repo deletes its branches
+	if err := db.DeleteRepo(t.Context(), repo.ID, true); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	if b, _ := db.GetRepoBranches(repo.ID); b != nil {
+		t.Errorf("branches should be deleted with the repo, got %+v", b)
+	}
+}
+
+func TestJobProtectedBranch(t *testing.T) {
+	db := openTestDB(t)
+	repo := createRepo(t, db, "/tmp/protected-repo")
+	commit := createCommit(t, db, repo.ID, "abc123")
+
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "codex", Protected: true})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	if !job.Protected {
+		t.Error("enqueued job should be marked protected")
+	}
+	got, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if !got.Protected {
+		t.Error("protected flag should be stored")
+	}
+	jobs, err := db.ListJobs(t.Context(), "", "", 10, 0)
+	if err != nil || len(jobs) != 1 || !jobs[0].Protected {
+		t.Errorf("ListJobs should report the protected flag: %+v, %v", jobs, err)
+	}
+	st, err := db.GetCommitStatus(t.Context(), repo.RootPath, "abc123")
+	if err != nil || !st.Protected {
+		t.Errorf("commit status should report the protected flag: %+v, %v", st, err)
+	}
+}
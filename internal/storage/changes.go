@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ChangeReview is a review of one version of a change: a commit that
+// shares its change ID (as recorded by jj) with the commit being reviewed
+type ChangeReview struct {
+	SHA     string `json:"sha"`
+	Subject string `json:"subject"`
+	Review
+}
+
+// SetCommitChangeID records the change a commit is a version of
+func (db *DB) SetCommitChangeID(commitID int64, changeID string) error {
+	_, err := db.Exec(`
+		INSERT INTO commit_changes (commit_id, change_id) VALUES (?, ?)
+		ON CONFLICT(commit_id) DO UPDATE SET change_id = excluded.change_id
+	`, commitID, changeID)
+	return err
+}
+
+// GetCommitChangeID returns the change ID recorded for a commit, or "" if
+// none was
+func (db *DB) GetCommitChangeID(sha string) (string, error) {
+	var changeID string
+	err := db.QueryRow(`
+		SELECT cc.change_id FROM commit_changes cc
+		JOIN commits c ON c.id = cc.commit_id
+		WHERE c.sha = ?
+	`, sha).Scan(&changeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return changeID, err
+}
+
+// GetChangeReviews returns the reviews of the other versions of a change
+// than the commit excludeSHA, oldest first
+func (db *DB) GetChangeReviews(changeID, excludeSHA string) ([]ChangeReview, error) {
+	rows, err := db.Query(`
+		SELECT c.sha, c.subject, rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN commits c ON c.id = j.commit_id
+		JOIN commit_changes cc ON cc.commit_id = c.id
+		WHERE cc.change_id = ? AND c.sha != ?
+		ORDER BY rv.created_at ASC, rv.id ASC
+	`, changeID, excludeSHA)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []ChangeReview
+	for rows.Next() {
+		var cr ChangeReview
+		var createdAt string
+		var addressed int
+		if err := rows.Scan(&cr.SHA, &cr.Subject, &cr.ID, &cr.JobID, &cr.Agent, &cr.Prompt, &cr.Output, &createdAt, &addressed); err != nil {
+			return nil, err
+		}
+		db.loadReviewBlobs(&cr.Review)
+		cr.CreatedAt = parseSQLiteTime(createdAt)
+		cr.Addressed = addressed != 0
+		reviews = append(reviews, cr)
+	}
+	return reviews, rows.Err()
+}
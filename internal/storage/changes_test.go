@@ -0,0 +1,41 @@
+package storage
+
+import "testing"
+
+func TestChangeReviews(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/change-repo")
+	v1 := createCommit(t, db, repo.ID, "v1sha")
+	v2 := createCommit(t, db, repo.ID, "v2sha")
+	other := createCommit(t, db, repo.ID, "othersha")
+
+	if id, err := db.GetCommitChangeID("v1sha"); err != nil || id != "" {
+		t.Fatalf("expected no change ID yet, got %q, %v", id, err)
+	}
+	for commitID, changeID := range map[int64]string{v1.ID: "kxqpmn", v2.ID: "kxqpmn", other.ID: "zzyywx"} {
+		if err := db.SetCommitChangeID(commitID, changeID); err != nil {
+			t.Fatalf("SetCommitChangeID: %v", err)
+		}
+	}
+	if id, err := db.GetCommitChangeID("v2sha"); err != nil || id != "kxqpmn" {
+		t.Fatalf("GetCommitChangeID = %q, %v", id, err)
+	}
+
+	for _, c := range []*Commit{v1, v2, other} {
+		enqueueJob(t, db, repo.ID, c.ID, c.SHA)
+		job := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "review of "+c.SHA); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+	}
+
+	reviews, err := db.GetChangeReviews("kxqpmn", "v2sha")
+	if err != nil {
+		t.Fatalf("GetChangeReviews: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].SHA != "v1sha" || reviews[0].Output != "review of v1sha" {
+		t.Errorf("unexpected change reviews %+v", reviews)
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"time"
+)
+
+// Checklist results for one item of a review
+const (
+	ChecklistPass       = "pass"
+	ChecklistFail       = "fail"
+	ChecklistNA         = "na"         // Item does not apply to the change
+	ChecklistUnanswered = "unanswered" // The review skipped the item
+)
+
+// ChecklistResult is a review's answer to one checklist item
+type ChecklistResult struct {
+	Index  int    `json:"index"`
+	Item   string `json:"item"`
+	Result string `json:"result"`
+	Note   string `json:"note,omitempty"`
+}
+
+// ChecklistItemSummary tallies the answers to one checklist item across a
+// repo's reviews
+type ChecklistItemSummary struct {
+	Item       string `json:"item"`
+	Pass       int    `json:"pass"`
+	Fail       int    `json:"fail"`
+	NA         int    `json:"na"`
+	Unanswered int    `json:"unanswered"`
+}
+
+// SaveChecklistResults records a job's checklist answers, replacing those
+// recorded by an earlier run of the same job
+func (db *DB) SaveChecklistResults(jobID int64, results []ChecklistResult) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM checklist_results WHERE job_id = ?`, jobID); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if _, err := tx.Exec(`INSERT INTO checklist_results (job_id, item_index, item, result, note) VALUES (?, ?, ?, ?, ?)`,
+			jobID, r.Index, r.Item, r.Result, r.Note); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetChecklistResults returns a job's checklist answers in checklist order
+func (db *DB) GetChecklistResults(jobID int64) ([]ChecklistResult, error) {
+	rows, err := db.Query(`
+		SELECT item_index, item, result, note FROM checklist_results
+		WHERE job_id = ? ORDER BY item_index
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ChecklistResult
+	for rows.Next() {
+		var r ChecklistResult
+		if err := rows.Scan(&r.Index, &r.Item, &r.Result, &r.Note); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// GetChecklistSummary tallies the checklist answers of a repo's reviews
+// enqueued since the given time, per item, most failed first
+func (db *DB) GetChecklistSummary(repoID int64, since time.Time) ([]ChecklistItemSummary, error) {
+	rows, err := db.Query(`
+		SELECT c.item,
+			SUM(c.result = 'pass'), SUM(c.result = 'fail'),
+			SUM(c.result = 'na'), SUM(c.result = 'unanswered')
+		FROM checklist_results c
+		JOIN review_jobs j ON j.id = c.job_id
+		WHERE j.repo_id = ? AND julianday(j.enqueued_at) >= julianday(?)
+		GROUP BY c.item
+		ORDER BY SUM(c.result = 'fail') DESC, MIN(c.item_index), c.item
+	`, repoID, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summary []ChecklistItemSummary
+	for rows.Next() {
+		var s ChecklistItemSummary
+		if err := rows.Scan(&s.Item, &s.Pass, &s.Fail, &s.NA, &s.Unanswered); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+	return summary, rows.Err()
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChecklistResults(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/checklist-repo")
+	var jobIDs []int64
+	for _, sha := range []string{"cl1", "cl2"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		jobIDs = append(jobIDs, enqueueJob(t, db, repo.ID, commit.ID, sha).ID)
+	}
+
+	first := []ChecklistResult{
+		{Index: 0, Item: "Tests added?", Result: ChecklistFail, Note: "no test for the parser"},
+		{Index: 1, Item: "Docs updated?", Result: ChecklistNA},
+	}
+	if err := db.SaveChecklistResults(jobIDs[0], first); err != nil {
+		t.Fatalf("SaveChecklistResults: %v", err)
+	}
+	second := []ChecklistResult{
+		{Index: 0, Item: "Tests added?", Result: ChecklistPass},
+		{Index: 1, Item: "Docs updated?", Result: ChecklistUnanswered},
+	}
+	if err := db.SaveChecklistResults(jobIDs[1], second); err != nil {
+		t.Fatalf("SaveChecklistResults: %v", err)
+	}
+
+	got, err := db.GetChecklistResults(jobIDs[0])
+	if err != nil {
+		t.Fatalf("GetChecklistResults: %v", err)
+	}
+	if len(got) != 2 || got[0] != first[0] || got[1] != first[1] {
+		t.Errorf("GetChecklistResults = %+v, want %+v", got, first)
+	}
+
+	// A rerun replaces the job's answers
+	if err := db.SaveChecklistResults(jobIDs[0], first[:1]); err != nil {
+		t.Fatalf("SaveChecklistResults: %v", err)
+	}
+	if got, _ := db.GetChecklistResults(jobIDs[0]); len(got) != 1 {
+		t.Errorf("expected rerun to replace answers, got %+v", got)
+	}
+
+	summary, err := db.GetChecklistSummary(repo.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetChecklistSummary: %v", err)
+	}
+	want := []ChecklistItemSummary{
+		{Item: "Tests added?", Pass: 1, Fail: 1},
+		{Item: "Docs updated?", Unanswered: 1},
+	}
+	if len(summary) != len(want) || summary[0] != want[0] || summary[1] != want[1] {
+		t.Errorf("GetChecklistSummary = %+v, want %+v", summary, want)
+	}
+
+	if summary, err := db.GetChecklistSummary(repo.ID, time.Now().Add(time.Hour)); err != nil || len(summary) != 0 {
+		t.Errorf("expected nothing after since, got %+v, %v", summary, err)
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -241,6 +242,7 @@ func (db *DB) GetBatchReviews(batchID int64) ([]BatchReviewResult, error) {
 		if err := rows.Scan(&r.JobID, &r.Agent, &r.ReviewType, &r.Output, &r.Status, &r.Error); err != nil {
 			return nil, err
 		}
+		r.Output = db.loadBlob(r.Output)
 		results = append(results, r)
 	}
 	return results, rows.Err()
@@ -341,7 +343,7 @@ func (db *DB) CancelSupersededBatches(githubRepo string, prNumber int, newHeadSH
 			return canceledIDs, fmt.Errorf("get jobs for batch %d: %w", batchID, err)
 		}
 		for _, jid := range jobIDs {
-			if err := db.CancelJob(jid); err != nil {
+			if err := db.CancelJob(context.Background(), jid); err != nil {
 				if err == sql.ErrNoRows {
 					continue // already terminal
 				}
@@ -19,7 +19,7 @@ func mustCreateCIBatch(t *testing.T, db *DB, ghRepo string, prNum int, headSHA s
 // mustEnqueueReviewJob enqueues a review job, failing the test on error.
 func mustEnqueueReviewJob(t *testing.T, db *DB, repoID int64, gitRef, agent, reviewType string) *ReviewJob {
 	t.Helper()
-	job, err := db.EnqueueJob(EnqueueOpts{
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{
 		RepoID: repoID, GitRef: gitRef, Agent: agent, ReviewType: reviewType,
 	})
 	if err != nil {
@@ -113,7 +113,7 @@ func TestHasCIBatch(t *testing.T) {
 		t.Error("expected false before creation")
 	}
 
-	repo, err := db.GetOrCreateRepo("/tmp/test-repo-hasbatch")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo-hasbatch")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -154,7 +154,7 @@ func TestRecordBatchJob(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -278,7 +278,7 @@ func TestGetBatchReviews(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -331,7 +331,7 @@ func TestGetCIBatchByJobID(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	batch, job := mustCreateLinkedBatchJob(t, db, repo.ID, "myorg/myrepo", 1, "sha1", "abc..def", "codex", "security")
 
 	found, err := db.GetCIBatchByJobID(job.ID)
@@ -399,7 +399,7 @@ func TestFinalizeBatch_PreventsStaleRepost(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -471,7 +471,7 @@ func TestGetStaleBatches_StaleClaim(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -514,7 +514,7 @@ func TestDeleteEmptyBatches(t *testing.T) {
 	mustCreateCIBatch(t, db, "myorg/myrepo", 2, "sha-recent", 1)
 
 	// Create a non-empty batch that's old (should NOT be deleted)
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -566,7 +566,7 @@ func TestCancelJob_ReturnsErrNoRowsForTerminalJobs(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test-cancel")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-cancel")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -578,7 +578,7 @@ func TestCancelJob_ReturnsErrNoRowsForTerminalJobs(t *testing.T) {
 	}
 
 	// CancelJob on a terminal job should return sql.ErrNoRows
-	err = db.CancelJob(job.ID)
+	err = db.CancelJob(t.Context(), job.ID)
 	if err == nil {
 		t.Fatal("expected error for terminal job")
 	}
@@ -588,7 +588,7 @@ func TestCancelJob_ReturnsErrNoRowsForTerminalJobs(t *testing.T) {
 
 	// CancelJob on a queued job should succeed
 	job2 := mustEnqueueReviewJob(t, db, repo.ID, "c..d", "test", "security")
-	if err := db.CancelJob(job2.ID); err != nil {
+	if err := db.CancelJob(t.Context(), job2.ID); err != nil {
 		t.Fatalf("CancelJob on queued job: %v", err)
 	}
 
@@ -606,7 +606,7 @@ func TestCancelSupersededBatches(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/test-supersede")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-supersede")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo: %v", err)
 	}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"time"
+)
+
+// CommitMessageSuggestion is an improved commit message proposed by a review
+type CommitMessageSuggestion struct {
+	JobID     int64     `json:"job_id"`
+	SHA       string    `json:"sha"`
+	Agent     string    `json:"agent"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaveCommitMessageSuggestion records the commit message a review job
+// proposed, replacing one from an earlier run of the same job
+func (db *DB) SaveCommitMessageSuggestion(jobID int64, message string) error {
+	_, err := db.Exec(`
+		INSERT INTO commit_message_suggestions (job_id, message, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			message = excluded.message,
+			created_at = excluded.created_at
+	`, jobID, message, time.Now().Format(time.RFC3339))
+	return err
+}
+
+// GetCommitMessageSuggestion returns the most recent commit message proposed
+// for a commit. Returns sql.ErrNoRows if no review proposed one.
+func (db *DB) GetCommitMessageSuggestion(sha string) (*CommitMessageSuggestion, error) {
+	var s CommitMessageSuggestion
+	var createdAt string
+	err := db.QueryRow(`
+		SELECT s.job_id, c.sha, j.agent, s.message, s.created_at
+		FROM commit_message_suggestions s
+		JOIN review_jobs j ON j.id = s.job_id
+		JOIN commits c ON c.id = j.commit_id
+		WHERE c.sha = ?
+		ORDER BY s.created_at DESC, s.job_id DESC
+		LIMIT 1
+	`, sha).Scan(&s.JobID, &s.SHA, &s.Agent, &s.Message, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	s.CreatedAt = parseSQLiteTime(createdAt)
+	return &s, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestCommitMessageSuggestion(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/commitmsg-repo")
+	commit := createCommit(t, db, repo.ID, "msgsha")
+
+	if _, err := db.GetCommitMessageSuggestion("msgsha"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before any suggestion, got %v", err)
+	}
+
+	enqueueJob(t, db, repo.ID, commit.ID, commit.SHA)
+	first := claimJob(t, db, "worker-1")
+	if err := db.SaveCommitMessageSuggestion(first.ID, "Fix parser\n\nOld body"); err != nil {
+		t.Fatalf("SaveCommitMessageSuggestion: %v", err)
+	}
+	enqueueJob(t, db, repo.ID, commit.ID, commit.SHA)
+	second := claimJob(t, db, "worker-1")
+	if err := db.SaveCommitMessageSuggestion(second.ID, "Fix off-by-one in parser"); err != nil {
+		t.Fatalf("SaveCommitMessageSuggestion: %v", err)
+	}
+
+	s, err := db.GetCommitMessageSuggestion("msgsha")
+	if err != nil {
+		t.Fatalf("GetCommitMessageSuggestion: %v", err)
+	}
+	if s.JobID != second.ID || s.SHA != "msgsha" || s.Message != "Fix off-by-one in parser" {
+		t.Errorf("expected the latest suggestion, got %+v", s)
+	}
+
+	// A rerun of the same job replaces its suggestion
+	if err := db.SaveCommitMessageSuggestion(second.ID, "Fix parser bounds check"); err != nil {
+		t.Fatalf("SaveCommitMessageSuggestion: %v", err)
+	}
+	if s, err := db.GetCommitMessageSuggestion("msgsha"); err != nil || s.Message != "Fix parser bounds check" {
+		t.Errorf("expected replaced suggestion, got %+v, %v", s, err)
+	}
+}
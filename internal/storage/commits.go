@@ -1,17 +1,18 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"time"
 )
 
 // GetOrCreateCommit finds or creates a commit record.
 // Lookups are by (repo_id, sha) to handle the same SHA in different repos.
-func (db *DB) GetOrCreateCommit(repoID int64, sha, author, subject string, timestamp time.Time) (*Commit, error) {
+func (db *DB) GetOrCreateCommit(ctx context.Context, repoID int64, sha, author, subject string, timestamp time.Time) (*Commit, error) {
 	// Try to find existing by (repo_id, sha)
 	var commit Commit
 	var ts, createdAt string
-	err := db.QueryRow(`SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).
+	err := db.QueryRowContext(ctx, `SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).
 		Scan(&commit.ID, &commit.RepoID, &commit.SHA, &commit.Author, &commit.Subject, &ts, &createdAt)
 	if err == nil {
 		commit.Timestamp = parseSQLiteTime(ts)
@@ -23,7 +24,7 @@ func (db *DB) GetOrCreateCommit(repoID int64, sha, author, subject string, times
 	}
 
 	// Create new
-	result, err := db.Exec(`INSERT INTO commits (repo_id, sha, author, subject, timestamp) VALUES (?, ?, ?, ?, ?)`,
+	result, err := db.ExecContext(ctx, `INSERT INTO commits (repo_id, sha, author, subject, timestamp) VALUES (?, ?, ?, ?, ?)`,
 		repoID, sha, author, subject, timestamp.Format(time.RFC3339))
 	if err != nil {
 		return nil, err
@@ -48,10 +49,10 @@ var ErrAmbiguousCommit = sql.ErrNoRows // Use sql.ErrNoRows for API compatibilit
 // DEPRECATED: This is a legacy API that doesn't handle the same SHA in different repos.
 // Returns sql.ErrNoRows if no commit found, or if multiple repos have this SHA (ambiguous).
 // Prefer using GetCommitByRepoAndSHA or job-based lookups instead.
-func (db *DB) GetCommitBySHA(sha string) (*Commit, error) {
+func (db *DB) GetCommitBySHA(ctx context.Context, sha string) (*Commit, error) {
 	// Check for ambiguity first
 	var count int
-	if err := db.QueryRow(`SELECT COUNT(DISTINCT repo_id) FROM commits WHERE sha = ?`, sha).Scan(&count); err != nil {
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(DISTINCT repo_id) FROM commits WHERE sha = ?`, sha).Scan(&count); err != nil {
 		return nil, err
 	}
 	if count > 1 {
@@ -60,7 +61,7 @@ func (db *DB) GetCommitBySHA(sha string) (*Commit, error) {
 
 	var commit Commit
 	var ts, createdAt string
-	err := db.QueryRow(`SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE sha = ?`, sha).
+	err := db.QueryRowContext(ctx, `SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE sha = ?`, sha).
 		Scan(&commit.ID, &commit.RepoID, &commit.SHA, &commit.Author, &commit.Subject, &ts, &createdAt)
 	if err != nil {
 		return nil, err
@@ -71,10 +72,10 @@ func (db *DB) GetCommitBySHA(sha string) (*Commit, error) {
 }
 
 // GetCommitByRepoAndSHA returns a commit by repo ID and SHA
-func (db *DB) GetCommitByRepoAndSHA(repoID int64, sha string) (*Commit, error) {
+func (db *DB) GetCommitByRepoAndSHA(ctx context.Context, repoID int64, sha string) (*Commit, error) {
 	var commit Commit
 	var ts, createdAt string
-	err := db.QueryRow(`SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).
+	err := db.QueryRowContext(ctx, `SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).
 		Scan(&commit.ID, &commit.RepoID, &commit.SHA, &commit.Author, &commit.Subject, &ts, &createdAt)
 	if err != nil {
 		return nil, err
@@ -85,10 +86,10 @@ func (db *DB) GetCommitByRepoAndSHA(repoID int64, sha string) (*Commit, error) {
 }
 
 // GetCommitByID returns a commit by its ID
-func (db *DB) GetCommitByID(id int64) (*Commit, error) {
+func (db *DB) GetCommitByID(ctx context.Context, id int64) (*Commit, error) {
 	var commit Commit
 	var ts, createdAt string
-	err := db.QueryRow(`SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE id = ?`, id).
+	err := db.QueryRowContext(ctx, `SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE id = ?`, id).
 		Scan(&commit.ID, &commit.RepoID, &commit.SHA, &commit.Author, &commit.Subject, &ts, &createdAt)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,38 @@
+package storage
+
+// RecentReview is a finished review as offered by shell completion
+type RecentReview struct {
+	JobID     int64
+	GitRef    string
+	CommitSHA string // Empty for ranges, dirty reviews and patches
+	RepoName  string
+	Subject   string
+}
+
+// ListRecentReviews returns the most recent jobs that have a review,
+// newest first
+func (db *DB) ListRecentReviews(limit int) ([]RecentReview, error) {
+	rows, err := db.Query(`
+		SELECT j.id, j.git_ref, COALESCE(c.sha, ''), r.name, COALESCE(c.subject, '')
+		FROM review_jobs j
+		JOIN reviews rv ON rv.job_id = j.id
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id
+		ORDER BY j.id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []RecentReview
+	for rows.Next() {
+		var rr RecentReview
+		if err := rows.Scan(&rr.JobID, &rr.GitRef, &rr.CommitSHA, &rr.RepoName, &rr.Subject); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rr)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// MaxContextDocSize is the most of a context doc's content kept with a job
+const MaxContextDocSize = 64 << 10
+
+// ContextDoc is a document linked to a job as the design or intent the
+// change implements, such as a design doc, for the review to check the
+// change against
+type ContextDoc struct {
+	Source    string `json:"source"` // URL or file path the content was read from
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"` // Content was cut to MaxContextDocSize
+}
+
+// insertContextDocs adds the context docs of a job in order, cutting
+// content beyond MaxContextDocSize. EnqueueJob saves them with the job.
+func insertContextDocs(tx *sql.Tx, jobID int64, docs []ContextDoc) error {
+	for i, doc := range docs {
+		if len(doc.Content) > MaxContextDocSize {
+			doc.Content = strings.ToValidUTF8(doc.Content[:MaxContextDocSize], "")
+			doc.Truncated = true
+		}
+		_, err := tx.Exec(`
+			INSERT INTO job_context_docs (job_id, position, source, content, truncated)
+			VALUES (?, ?, ?, ?, ?)
+		`, jobID, i, doc.Source, doc.Content, doc.Truncated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetJobContextDocs returns the context docs of a job in the order they
+// were given
+func (db *DB) GetJobContextDocs(jobID int64) ([]ContextDoc, error) {
+	rows, err := db.Query(`
+		SELECT source, content, truncated FROM job_context_docs
+		WHERE job_id = ? ORDER BY position
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var docs []ContextDoc
+	for rows.Next() {
+		var doc ContextDoc
+		if err := rows.Scan(&doc.Source, &doc.Content, &doc.Truncated); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnqueueJobSavesContextDocs(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := createRepo(t, db, "/tmp/repo")
+	commit := createCommit(t, db, repo.ID, "aaa")
+
+	long := strings.Repeat("x", MaxContextDocSize+10)
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{
+		RepoID:   repo.ID,
+		CommitID: commit.ID,
+		GitRef:   "aaa",
+		Agent:    "codex",
+		ContextDocs: []ContextDoc{
+			{Source: "docs/design.md", Content: "# Design\n\nCache reads."},
+			{Source: "https://example.com/rfc", Content: long},
+		},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+
+	docs, err := db.GetJobContextDocs(job.ID)
+	if err != nil {
+		t.Fatalf("GetJobContextDocs: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d docs, want 2", len(docs))
+	}
+	if docs[0].Source != "docs/design.md" || docs[0].Content != "# Design\n\nCache reads." || docs[0].Truncated {
+		t.Errorf("first doc = %+v", docs[0])
+	}
+	if docs[1].Source != "https://example.com/rfc" || len(docs[1].Content) != MaxContextDocSize || !docs[1].Truncated {
+		t.Errorf("second doc: source %q, %d bytes, truncated %v; want cut to %d bytes",
+			docs[1].Source, len(docs[1].Content), docs[1].Truncated, MaxContextDocSize)
+	}
+
+	other := enqueueJob(t, db, repo.ID, commit.ID, "aaa")
+	if docs, err := db.GetJobContextDocs(other.ID); err != nil || len(docs) != 0 {
+		t.Errorf("job without docs: got %v, %v", docs, err)
+	}
+}
@@ -4,15 +4,36 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/roborev-dev/roborev/internal/cliprof"
 	"github.com/roborev-dev/roborev/internal/config"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
+// profiledDriver is the SQLite driver with its queries timed for
+// 'roborev --profile-cli'
+const profiledDriver = "sqlite-profiled"
+
+func init() {
+	sql.Register(profiledDriver, cliprof.Driver(&sqlite.Driver{}))
+}
+
+// driverName returns the SQLite driver to open databases with, the timed
+// one while a CLI command is being profiled
+func driverName() string {
+	if cliprof.Enabled() {
+		return profiledDriver
+	}
+	return "sqlite"
+}
+
 const schema = `
 CREATE TABLE IF NOT EXISTS repos (
   id INTEGER PRIMARY KEY,
@@ -40,7 +61,7 @@ CREATE TABLE IF NOT EXISTS review_jobs (
   agent TEXT NOT NULL DEFAULT 'codex',
   model TEXT,
   reasoning TEXT NOT NULL DEFAULT 'thorough',
-  status TEXT NOT NULL CHECK(status IN ('queued','running','done','failed','canceled')) DEFAULT 'queued',
+  status TEXT NOT NULL CHECK(status IN ('queued','running','done','failed','canceled','skipped','blocked')) DEFAULT 'queued',
   enqueued_at TEXT NOT NULL DEFAULT (datetime('now')),
   started_at TEXT,
   finished_at TEXT,
@@ -77,7 +98,7 @@ CREATE TABLE IF NOT EXISTS ci_pr_reviews (
   github_repo TEXT NOT NULL,
   pr_number INTEGER NOT NULL,
   head_sha TEXT NOT NULL,
-  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  job_id INTEGER NOT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   UNIQUE(github_repo, pr_number, head_sha)
 );
@@ -104,16 +125,300 @@ CREATE TABLE IF NOT EXISTS ci_pr_batch_jobs (
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS finding_triage (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  review_id INTEGER NOT NULL REFERENCES reviews(id),
+  finding_index INTEGER NOT NULL,
+  decision TEXT NOT NULL CHECK(decision IN ('accepted', 'dismissed', 'assigned')),
+  assignee TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  UNIQUE(review_id, finding_index)
+);
+
+CREATE TABLE IF NOT EXISTS job_env (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  roborev_version TEXT NOT NULL,
+  agent TEXT NOT NULL,
+  agent_version TEXT,
+  model TEXT,
+  os TEXT NOT NULL,
+  prompt_hash TEXT,
+  captured_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS job_usage (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  prompt_tokens INTEGER NOT NULL,
+  completion_tokens INTEGER NOT NULL,
+  captured_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS pre_reviews (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  agent TEXT NOT NULL,
+  model TEXT,
+  status TEXT NOT NULL,
+  output TEXT,
+  error TEXT,
+  elapsed_ms INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS author_aliases (
+  alias TEXT PRIMARY KEY COLLATE NOCASE,
+  author TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS finding_checks (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  agent TEXT NOT NULL,
+  findings INTEGER NOT NULL,
+  invalid INTEGER NOT NULL,
+  checked_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS job_symbols (
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  name TEXT NOT NULL,
+  file TEXT NOT NULL,
+  PRIMARY KEY (job_id, name, file)
+);
+
+CREATE TABLE IF NOT EXISTS commit_changes (
+  commit_id INTEGER PRIMARY KEY REFERENCES commits(id),
+  change_id TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS commit_message_suggestions (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  message TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS checklist_results (
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  item_index INTEGER NOT NULL,
+  item TEXT NOT NULL,
+  result TEXT NOT NULL CHECK(result IN ('pass', 'fail', 'na', 'unanswered')),
+  note TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (job_id, item_index)
+);
+
+CREATE TABLE IF NOT EXISTS commit_patches (
+  commit_id INTEGER PRIMARY KEY REFERENCES commits(id),
+  patch_id TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_deps (
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  depends_on INTEGER NOT NULL REFERENCES review_jobs(id),
+  PRIMARY KEY (job_id, depends_on)
+);
+
+CREATE TABLE IF NOT EXISTS job_parts (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  parent_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  paths TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_groups (
+  id INTEGER PRIMARY KEY,
+  name TEXT UNIQUE NOT NULL,
+  kind TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS job_context_docs (
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  position INTEGER NOT NULL,
+  source TEXT NOT NULL,
+  content TEXT NOT NULL,
+  truncated INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (job_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS job_group_jobs (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  group_id INTEGER NOT NULL REFERENCES job_groups(id)
+);
+
+CREATE TABLE IF NOT EXISTS verdict_reconciliations (
+  repo_id INTEGER NOT NULL REFERENCES repos(id),
+  git_ref TEXT NOT NULL,
+  verdict TEXT NOT NULL CHECK(verdict IN ('P', 'F')),
+  method TEXT NOT NULL CHECK(method IN ('manual', 'tiebreaker')),
+  tiebreaker_agent TEXT,
+  tiebreaker_job_id INTEGER,
+  note TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (repo_id, git_ref)
+);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id INTEGER PRIMARY KEY,
+  name TEXT UNIQUE NOT NULL,
+  token_hash TEXT UNIQUE NOT NULL,
+  role TEXT NOT NULL CHECK(role IN ('viewer', 'reviewer', 'admin', 'enqueue')),
+  repo TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  last_used_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS finding_issues (
+  review_id INTEGER NOT NULL,
+  finding_index INTEGER NOT NULL,
+  repo_id INTEGER NOT NULL REFERENCES repos(id),
+  fingerprint TEXT NOT NULL,
+  url TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (review_id, finding_index)
+);
+
+CREATE TABLE IF NOT EXISTS finding_suppressions (
+  review_id INTEGER NOT NULL REFERENCES reviews(id),
+  finding_index INTEGER NOT NULL,
+  entry TEXT NOT NULL,
+  justification TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (review_id, finding_index)
+);
+
+CREATE TABLE IF NOT EXISTS repo_branches (
+  repo_id INTEGER PRIMARY KEY REFERENCES repos(id),
+  default_branch TEXT NOT NULL,
+  protected_branches TEXT NOT NULL,
+  source TEXT NOT NULL,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS human_review_imports (
+  source TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (source, external_id)
+);
+
+CREATE TABLE IF NOT EXISTS share_links (
+  id INTEGER PRIMARY KEY,
+  token TEXT UNIQUE NOT NULL,
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  expires_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS sla_breaches (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  sla_minutes INTEGER NOT NULL,
+  detected_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS bench_runs (
+  id INTEGER PRIMARY KEY,
+  suite TEXT NOT NULL,
+  agent TEXT NOT NULL,
+  model TEXT NOT NULL DEFAULT '',
+  prompt_hash TEXT NOT NULL DEFAULT '',
+  roborev_version TEXT NOT NULL,
+  cases INTEGER NOT NULL,
+  expected INTEGER NOT NULL,
+  detected INTEGER NOT NULL,
+  extra INTEGER NOT NULL,
+  false_alarms INTEGER NOT NULL,
+  errors INTEGER NOT NULL,
+  duration_ms INTEGER NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS bench_results (
+  run_id INTEGER NOT NULL REFERENCES bench_runs(id),
+  case_name TEXT NOT NULL,
+  expected INTEGER NOT NULL,
+  detected INTEGER NOT NULL,
+  extra INTEGER NOT NULL,
+  verdict TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL,
+  PRIMARY KEY (run_id, case_name)
+);
+
+CREATE TABLE IF NOT EXISTS injection_risks (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  patterns TEXT NOT NULL,
+  output_note TEXT NOT NULL DEFAULT '',
+  detected_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS queue_metrics (
+  id INTEGER PRIMARY KEY,
+  sampled_at TEXT NOT NULL,
+  queued INTEGER NOT NULL,
+  running INTEGER NOT NULL,
+  workers INTEGER NOT NULL,
+  finished INTEGER NOT NULL,
+  avg_latency_seconds REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS job_routes (
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  route TEXT NOT NULL,
+  PRIMARY KEY (job_id, route)
+);
+
+CREATE TABLE IF NOT EXISTS trash_operations (
+  id TEXT PRIMARY KEY,
+  operation TEXT NOT NULL,
+  summary TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  expires_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS trash_rows (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  operation_id TEXT NOT NULL REFERENCES trash_operations(id),
+  table_name TEXT NOT NULL,
+  data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_failures (
+  id INTEGER PRIMARY KEY,
+  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+  attempt INTEGER NOT NULL,
+  error_code TEXT,
+  error TEXT NOT NULL,
+  failed_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+  job_id INTEGER PRIMARY KEY REFERENCES review_jobs(id),
+  dead_at TEXT NOT NULL
+);
+
 CREATE INDEX IF NOT EXISTS idx_review_jobs_status ON review_jobs(status);
 CREATE INDEX IF NOT EXISTS idx_review_jobs_repo ON review_jobs(repo_id);
 CREATE INDEX IF NOT EXISTS idx_review_jobs_git_ref ON review_jobs(git_ref);
 CREATE INDEX IF NOT EXISTS idx_commits_sha ON commits(sha);
 CREATE INDEX IF NOT EXISTS idx_ci_pr_batch_jobs_batch ON ci_pr_batch_jobs(batch_id);
+CREATE INDEX IF NOT EXISTS idx_queue_metrics_sampled_at ON queue_metrics(sampled_at);
 CREATE INDEX IF NOT EXISTS idx_ci_pr_batch_jobs_job ON ci_pr_batch_jobs(job_id);
+CREATE INDEX IF NOT EXISTS idx_job_symbols_name ON job_symbols(name);
+CREATE INDEX IF NOT EXISTS idx_job_deps_depends_on ON job_deps(depends_on);
+CREATE INDEX IF NOT EXISTS idx_job_parts_parent ON job_parts(parent_id);
+CREATE INDEX IF NOT EXISTS idx_job_group_jobs_group ON job_group_jobs(group_id);
+CREATE INDEX IF NOT EXISTS idx_commit_changes_change ON commit_changes(change_id);
+CREATE INDEX IF NOT EXISTS idx_commit_patches_patch ON commit_patches(patch_id);
+CREATE INDEX IF NOT EXISTS idx_share_links_job ON share_links(job_id);
+CREATE INDEX IF NOT EXISTS idx_finding_issues_fingerprint ON finding_issues(repo_id, fingerprint);
+CREATE INDEX IF NOT EXISTS idx_trash_rows_operation ON trash_rows(operation_id);
+CREATE INDEX IF NOT EXISTS idx_job_failures_job ON job_failures(job_id);
 `
 
 type DB struct {
 	*sql.DB
+
+	blobs  *blobOffload // Set by SetBlobStore; nil keeps everything in the database
+	queued queueSignal  // Wakes workers waiting for claimable jobs
 }
 
 // DefaultDBPath returns the default database path
@@ -132,28 +437,88 @@ func Open(dbPath string) (*DB, error) {
 	// Open with WAL mode and busy timeout.
 	// 30s busy_timeout gives enough headroom for concurrent writers
 	// (worker pool + sync worker) to wait for locks rather than failing.
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(30000)")
+	db, err := sql.Open(driverName(), dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(30000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return initDB(db)
+}
+
+// memoryDBSeq numbers in-memory databases so each OpenMemory call gets its
+// own
+var memoryDBSeq atomic.Int64
+
+// OpenMemory opens a new, empty database held in memory, with the same
+// schema as Open. Nothing touches the filesystem, and each call returns a
+// separate database, so tests using it can run in parallel. The database
+// is shared by all connections of the returned DB and is gone once it is
+// closed.
+func OpenMemory() (*DB, error) {
+	// The memdb VFS shares one in-memory database between the pool's
+	// connections with normal file locking, unlike ":memory:", which gives
+	// every connection its own database, or shared cache, whose table locks
+	// fail instead of waiting on busy_timeout. It has no WAL, so the default
+	// rollback journal is kept.
+	name := fmt.Sprintf("/roborev-%d", memoryDBSeq.Add(1))
+	db, err := sql.Open(driverName(), "file:"+name+"?vfs=memdb&_pragma=busy_timeout(30000)&_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
+	return initDB(db)
+}
 
-	wrapped := &DB{db}
+// initDB creates the schema of a freshly opened database and migrates it
+// to the current version
+func initDB(db *sql.DB) (*DB, error) {
+	wrapped := &DB{DB: db}
 
-	// Initialize schema (CREATE IF NOT EXISTS is idempotent)
-	if _, err := db.Exec(schema); err != nil {
+	// Initialize schema (CREATE IF NOT EXISTS is idempotent). This first
+	// statement also opens the database file.
+	end := cliprof.Span(cliprof.PhaseDBOpen)
+	_, err := db.Exec(schema)
+	end()
+	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("initialize schema: %w", err)
 	}
 
 	// Run migrations for existing databases
+	defer cliprof.Span(cliprof.PhaseMigrate)()
 	if err := wrapped.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("migrate: %w", err)
 	}
 
+	if err := wrapped.recordSchemaVersion(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return wrapped, nil
 }
 
+// OpenReadOnly opens an existing database for reading without creating it
+// or running migrations, so short-lived commands (such as shell prompt
+// integrations) can query it cheaply.
+func OpenReadOnly(dbPath string) (*DB, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+	dsn := (&url.URL{Scheme: "file", Path: filepath.ToSlash(dbPath), RawQuery: "mode=ro&_pragma=busy_timeout(1000)"}).String()
+	db, err := sql.Open(driverName(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	end := cliprof.Span(cliprof.PhaseDBOpen)
+	err = db.Ping()
+	end()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return &DB{DB: db}, nil
+}
+
 // migrate runs any needed migrations for existing databases
 func (db *DB) migrate() error {
 	// Migration: add prompt column to review_jobs if missing
@@ -535,6 +900,13 @@ func (db *DB) migrate() error {
 		}
 	}
 
+	// Migration: index responses by commit for comments on a commit. This
+	// must be after the responses table recreation above.
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_responses_commit_id ON responses(commit_id)`)
+	if err != nil {
+		return fmt.Errorf("create idx_responses_commit_id: %w", err)
+	}
+
 	// Migration: add job_type column to review_jobs if missing
 	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'job_type'`).Scan(&count)
 	if err != nil {
@@ -572,6 +944,146 @@ func (db *DB) migrate() error {
 		}
 	}
 
+	// Migration: add agent_policy column to review_jobs if missing
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'agent_policy'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check agent_policy column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN agent_policy TEXT`)
+		if err != nil {
+			return fmt.Errorf("add agent_policy column: %w", err)
+		}
+	}
+
+	// Migration: add priority column to review_jobs (bumped jobs are claimed first)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'priority'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check priority column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 0`)
+		if err != nil {
+			return fmt.Errorf("add priority column: %w", err)
+		}
+	}
+
+	// Migration: add hold_until column to review_jobs (grouped commits wait for more)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'hold_until'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check hold_until column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN hold_until TEXT`)
+		if err != nil {
+			return fmt.Errorf("add hold_until column: %w", err)
+		}
+	}
+
+	// Migration: add focus column to review_jobs (thorough reviews of high-stakes commits)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'focus'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check focus column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN focus INTEGER NOT NULL DEFAULT 0`)
+		if err != nil {
+			return fmt.Errorf("add focus column: %w", err)
+		}
+	}
+
+	// Migration: add protected_branch column to review_jobs (commit is on a protected branch)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'protected_branch'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check protected_branch column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN protected_branch INTEGER NOT NULL DEFAULT 0`)
+		if err != nil {
+			return fmt.Errorf("add protected_branch column: %w", err)
+		}
+	}
+
+	// Migration: add lane column to review_jobs (interactive jobs are claimed first)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'lane'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check lane column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN lane TEXT NOT NULL DEFAULT 'background'`)
+		if err != nil {
+			return fmt.Errorf("add lane column: %w", err)
+		}
+	}
+
+	// Migration: add truncation_level column to review_jobs (prompt shrunk
+	// after a context length error)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'truncation_level'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check truncation_level column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN truncation_level INTEGER NOT NULL DEFAULT 0`)
+		if err != nil {
+			return fmt.Errorf("add truncation_level column: %w", err)
+		}
+	}
+
+	// Migration: add error_code column to review_jobs (typed cause of a
+	// failure). Failures recorded before it are classified by their message.
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('review_jobs') WHERE name = 'error_code'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check error_code column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE review_jobs ADD COLUMN error_code TEXT`)
+		if err != nil {
+			return fmt.Errorf("add error_code column: %w", err)
+		}
+		if err := db.backfillErrorCodes(); err != nil {
+			return fmt.Errorf("backfill error codes: %w", err)
+		}
+	}
+
+	// Migration: add root_commit column to repos (used to follow moved repos)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('repos') WHERE name = 'root_commit'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check root_commit column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE repos ADD COLUMN root_commit TEXT`)
+		if err != nil {
+			return fmt.Errorf("add root_commit column: %w", err)
+		}
+	}
+
+	// Migration: add signature columns to reviews (optional review signing)
+	for _, col := range []string{"signature", "signing_key"} {
+		err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('reviews') WHERE name = ?`, col).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check %s column: %w", col, err)
+		}
+		if count == 0 {
+			_, err = db.Exec(`ALTER TABLE reviews ADD COLUMN ` + col + ` TEXT`)
+			if err != nil {
+				return fmt.Errorf("add %s column: %w", col, err)
+			}
+		}
+	}
+
+	// Migration: add quick_take column to reviews (short summary a review
+	// opens with when quick_take is enabled)
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('reviews') WHERE name = 'quick_take'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check quick_take column: %w", err)
+	}
+	if count == 0 {
+		_, err = db.Exec(`ALTER TABLE reviews ADD COLUMN quick_take TEXT`)
+		if err != nil {
+			return fmt.Errorf("add quick_take column: %w", err)
+		}
+	}
+
 	// Migration: add index on reviews.addressed for server-side filtering
 	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_reviews_addressed ON reviews(addressed)`)
 	if err != nil {
@@ -583,9 +1095,274 @@ func (db *DB) migrate() error {
 		return err
 	}
 
+	if err := db.migrateAPITokenScope(); err != nil {
+		return err
+	}
+
+	if err := db.migrateReviewSearch(); err != nil {
+		return err
+	}
+
+	if err := db.migrateRetainedRecords(); err != nil {
+		return err
+	}
+
+	return db.migrateStatusCheck()
+}
+
+// migrateReviewSearch creates the FTS5 index of review outputs and prompts
+// that SearchReviews queries, with triggers keeping it in step with the
+// reviews table, and indexes the reviews already there
+func (db *DB) migrateReviewSearch() error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'reviews_fts'`).Scan(&count); err != nil {
+		return fmt.Errorf("check reviews_fts table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		`CREATE VIRTUAL TABLE reviews_fts USING fts5(output, prompt, content='reviews', content_rowid='id')`,
+		`CREATE TRIGGER reviews_fts_insert AFTER INSERT ON reviews BEGIN
+			INSERT INTO reviews_fts(rowid, output, prompt) VALUES (new.id, new.output, new.prompt);
+		END`,
+		`CREATE TRIGGER reviews_fts_delete AFTER DELETE ON reviews BEGIN
+			INSERT INTO reviews_fts(reviews_fts, rowid, output, prompt) VALUES ('delete', old.id, old.output, old.prompt);
+		END`,
+		`CREATE TRIGGER reviews_fts_update AFTER UPDATE OF output, prompt ON reviews BEGIN
+			INSERT INTO reviews_fts(reviews_fts, rowid, output, prompt) VALUES ('delete', old.id, old.output, old.prompt);
+			INSERT INTO reviews_fts(rowid, output, prompt) VALUES (new.id, new.output, new.prompt);
+		END`,
+		`INSERT INTO reviews_fts(reviews_fts) VALUES ('rebuild')`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("create review search index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// statusCheck is the review_jobs status CHECK constraint of the current schema
+const statusCheck = "CHECK(status IN ('queued','running','done','failed','canceled','skipped','blocked'))"
+
+// oldStatusChecks are earlier status CHECK constraints that migrateStatusCheck
+// widens to statusCheck
+var oldStatusChecks = []string{
+	"CHECK(status IN ('queued','running','done','failed','canceled'))",
+	"CHECK(status IN ('queued','running','done','failed','canceled','skipped'))",
+}
+
+// migrateStatusCheck widens the review_jobs status CHECK constraint to allow
+// 'skipped' and 'blocked'. SQLite cannot alter a CHECK constraint, so the
+// table is rebuilt from its current definition, which keeps every column
+// added by earlier migrations in place.
+func (db *DB) migrateStatusCheck() error {
+	var tableSql string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='review_jobs'`).Scan(&tableSql); err != nil {
+		return fmt.Errorf("check review_jobs schema: %w", err)
+	}
+	oldCheck := ""
+	for _, check := range oldStatusChecks {
+		if strings.Contains(tableSql, check) {
+			oldCheck = check
+		}
+	}
+	if oldCheck == "" {
+		return nil
+	}
+	createSql := strings.Replace(tableSql, oldCheck, statusCheck, 1)
+	createSql = reviewJobsTableName.ReplaceAllString(createSql, "CREATE TABLE review_jobs_new")
+
+	// Same connection-scoped foreign key handling as the 'canceled' migration
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection for migration: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return fmt.Errorf("disable foreign keys: %w", err)
+	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Indexes are dropped with the table, so capture them first
+	var indexes []string
+	rows, err := tx.Query(`SELECT sql FROM sqlite_master WHERE type='index' AND tbl_name='review_jobs' AND sql IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("list review_jobs indexes: %w", err)
+	}
+	for rows.Next() {
+		var idx string
+		if err := rows.Scan(&idx); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan review_jobs index: %w", err)
+		}
+		indexes = append(indexes, idx)
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(createSql); err != nil {
+		return fmt.Errorf("create new review_jobs table: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO review_jobs_new SELECT * FROM review_jobs`); err != nil {
+		return fmt.Errorf("copy review_jobs data: %w", err)
+	}
+	if _, err := tx.Exec(`DROP TABLE review_jobs`); err != nil {
+		return fmt.Errorf("drop old review_jobs table: %w", err)
+	}
+	if _, err := tx.Exec(`ALTER TABLE review_jobs_new RENAME TO review_jobs`); err != nil {
+		return fmt.Errorf("rename review_jobs table: %w", err)
+	}
+	for _, idx := range indexes {
+		if _, err := tx.Exec(idx); err != nil {
+			return fmt.Errorf("recreate review_jobs index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration transaction: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
+		return fmt.Errorf("re-enable foreign keys: %w", err)
+	}
+	return nil
+}
+
+// migrateAPITokenScope adds the enqueue role and repo column to api_tokens.
+// Nothing references the table, so it is simply rebuilt.
+func (db *DB) migrateAPITokenScope() error {
+	var tableSql string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='api_tokens'`).Scan(&tableSql); err != nil {
+		return fmt.Errorf("check api_tokens schema: %w", err)
+	}
+	if strings.Contains(tableSql, "'enqueue'") {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		`ALTER TABLE api_tokens RENAME TO api_tokens_old`,
+		`CREATE TABLE api_tokens (
+		  id INTEGER PRIMARY KEY,
+		  name TEXT UNIQUE NOT NULL,
+		  token_hash TEXT UNIQUE NOT NULL,
+		  role TEXT NOT NULL CHECK(role IN ('viewer', 'reviewer', 'admin', 'enqueue')),
+		  repo TEXT,
+		  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+		  last_used_at TEXT
+		)`,
+		`INSERT INTO api_tokens (id, name, token_hash, role, created_at, last_used_at)
+		 SELECT id, name, token_hash, role, created_at, last_used_at FROM api_tokens_old`,
+		`DROP TABLE api_tokens_old`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate api_tokens: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration transaction: %w", err)
+	}
+	return nil
+}
+
+// retainedRecordRefs matches the foreign keys of records kept after the
+// job or review they name is deleted: the reviewed PR heads, so a pruned PR
+// review is not posted again, and filed issues, so a finding is not filed
+// twice. With foreign keys enforced they would block the delete.
+var retainedRecordRefs = []struct {
+	table string
+	ref   *regexp.Regexp
+}{
+	{"ci_pr_reviews", regexp.MustCompile(`(job_id\s+INTEGER\s+NOT\s+NULL)\s+REFERENCES\s+review_jobs\s*\(\s*id\s*\)`)},
+	{"finding_issues", regexp.MustCompile(`(review_id\s+INTEGER\s+NOT\s+NULL)\s+REFERENCES\s+reviews\s*\(\s*id\s*\)`)},
+}
+
+// migrateRetainedRecords drops the foreign keys in retainedRecordRefs
+func (db *DB) migrateRetainedRecords() error {
+	for _, r := range retainedRecordRefs {
+		if err := db.dropForeignKey(r.table, r.ref); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// dropForeignKey rebuilds table from its current definition with the
+// column definition ref matches cut to its first group, leaving out the
+// REFERENCES clause. Nothing may reference the table.
+func (db *DB) dropForeignKey(table string, ref *regexp.Regexp) error {
+	var tableSql string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name=?`, table).Scan(&tableSql); err != nil {
+		return fmt.Errorf("check %s schema: %w", table, err)
+	}
+	if !ref.MatchString(tableSql) {
+		return nil
+	}
+	createSql := ref.ReplaceAllString(tableSql, "$1")
+	createSql = regexp.MustCompile(`^CREATE TABLE\s+"?`+table+`"?`).ReplaceAllString(createSql, "CREATE TABLE "+table+"_new")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Indexes are dropped with the table, so capture them first
+	var indexes []string
+	rows, err := tx.Query(`SELECT sql FROM sqlite_master WHERE type='index' AND tbl_name=? AND sql IS NOT NULL`, table)
+	if err != nil {
+		return fmt.Errorf("list %s indexes: %w", table, err)
+	}
+	for rows.Next() {
+		var idx string
+		if err := rows.Scan(&idx); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan %s index: %w", table, err)
+		}
+		indexes = append(indexes, idx)
+	}
+	rows.Close()
+
+	stmts := []string{
+		createSql,
+		`INSERT INTO ` + table + `_new SELECT * FROM ` + table,
+		`DROP TABLE ` + table,
+		`ALTER TABLE ` + table + `_new RENAME TO ` + table,
+	}
+	for _, stmt := range append(stmts, indexes...) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration transaction: %w", err)
+	}
+	return nil
+}
+
+// reviewJobsTableName matches the table name in review_jobs' stored CREATE
+// statement, which SQLite quotes after a rename.
+var reviewJobsTableName = regexp.MustCompile(`^CREATE TABLE\s+"?review_jobs"?`)
+
 // hasUniqueIndexOnShaOnly checks if commits table has a unique constraint on just sha
 // (not the composite repo_id, sha constraint). Uses PRAGMA index_list/index_info for robustness.
 func (db *DB) hasUniqueIndexOnShaOnly() (bool, error) {
@@ -922,8 +1699,8 @@ func (db *DB) migrateSyncColumns() error {
 }
 
 // ResetStaleJobs marks all running jobs as queued (for daemon restart)
-func (db *DB) ResetStaleJobs() error {
-	_, err := db.Exec(`
+func (db *DB) ResetStaleJobs(ctx context.Context) error {
+	_, err := db.ExecContext(ctx, `
 		UPDATE review_jobs
 		SET status = 'queued', worker_id = NULL, started_at = NULL
 		WHERE status = 'running'
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -27,12 +30,58 @@ func TestOpenAndClose(t *testing.T) {
 	}
 }
 
+func TestOpenMemory(t *testing.T) {
+	t.Parallel()
+
+	first, err := OpenMemory()
+	if err != nil {
+		t.Fatalf("OpenMemory failed: %v", err)
+	}
+	defer first.Close()
+	second, err := OpenMemory()
+	if err != nil {
+		t.Fatalf("OpenMemory failed: %v", err)
+	}
+	defer second.Close()
+
+	repo := createRepo(t, first, "/tmp/memory-repo")
+	if repos, _, err := second.ListReposWithReviewCounts(t.Context()); err != nil || len(repos) != 0 {
+		t.Fatalf("expected the second database to be empty, got %v, %v", repos, err)
+	}
+
+	// Concurrent writers on separate connections see one database
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := first.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, GitRef: fmt.Sprintf("sha%d", i), Agent: "test"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("EnqueueJob failed: %v", err)
+		}
+	}
+	jobs, err := first.ListJobs(t.Context(), "", "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 8 {
+		t.Errorf("expected 8 jobs, got %d", len(jobs))
+	}
+}
+
 func TestRepoOperations(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
 	// Create repo
-	repo, err := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -45,7 +94,7 @@ func TestRepoOperations(t *testing.T) {
 	}
 
 	// Get same repo again (should return existing)
-	repo2, err := db.GetOrCreateRepo("/tmp/test-repo")
+	repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo (second call) failed: %v", err)
 	}
@@ -61,7 +110,7 @@ func TestCommitOperations(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/test-repo")
 
 	// Create commit
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123def456", "Test Author", "Test commit", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123def456", "Test Author", "Test commit", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
@@ -74,7 +123,7 @@ func TestCommitOperations(t *testing.T) {
 	}
 
 	// Get by SHA
-	found, err := db.GetCommitBySHA("abc123def456")
+	found, err := db.GetCommitBySHA(t.Context(), "abc123def456")
 	if err != nil {
 		t.Fatalf("GetCommitBySHA failed: %v", err)
 	}
@@ -103,7 +152,7 @@ func TestJobLifecycle(t *testing.T) {
 	}
 
 	// Claim again should return nil (no more jobs)
-	claimed2, err := db.ClaimJob("worker-2")
+	claimed2, err := db.ClaimJob(t.Context(), "worker-2")
 	if err != nil {
 		t.Fatalf("ClaimJob (second) failed: %v", err)
 	}
@@ -112,13 +161,13 @@ func TestJobLifecycle(t *testing.T) {
 	}
 
 	// Complete job
-	err = db.CompleteJob(job.ID, "codex", "test prompt", "test output")
+	err = db.CompleteJob(t.Context(), job.ID, "codex", "test prompt", "test output")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Verify job status
-	updatedJob, err := db.GetJobByID(job.ID)
+	updatedJob, err := db.GetJobByID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetJobByID failed: %v", err)
 	}
@@ -135,7 +184,7 @@ func TestBranchPersistence(t *testing.T) {
 	commit := createCommit(t, db, repo.ID, "branch123")
 
 	t.Run("EnqueueJob stores branch", func(t *testing.T) {
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch123", Branch: "feature/test-branch", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch123", Branch: "feature/test-branch", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
@@ -145,11 +194,11 @@ func TestBranchPersistence(t *testing.T) {
 	})
 
 	t.Run("GetJobByID returns branch", func(t *testing.T) {
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch456", Branch: "main", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch456", Branch: "main", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		fetched, err := db.GetJobByID(job.ID)
+		fetched, err := db.GetJobByID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -159,11 +208,11 @@ func TestBranchPersistence(t *testing.T) {
 	})
 
 	t.Run("ListJobs returns branch", func(t *testing.T) {
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch789", Branch: "develop", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branch789", Branch: "develop", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		jobs, err := db.ListJobs("", "", 100, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 100, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -185,18 +234,18 @@ func TestBranchPersistence(t *testing.T) {
 	t.Run("ClaimJob returns branch", func(t *testing.T) {
 		// Drain existing jobs
 		for {
-			j, _ := db.ClaimJob("drain")
+			j, _ := db.ClaimJob(t.Context(), "drain")
 			if j == nil {
 				break
 			}
-			db.CompleteJob(j.ID, "codex", "p", "o")
+			db.CompleteJob(t.Context(), j.ID, "codex", "p", "o")
 		}
 
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branchclaim", Branch: "release/v1", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "branchclaim", Branch: "release/v1", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		claimed, err := db.ClaimJob("test-worker")
+		claimed, err := db.ClaimJob(t.Context(), "test-worker")
 		if err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
@@ -209,7 +258,7 @@ func TestBranchPersistence(t *testing.T) {
 	})
 
 	t.Run("empty branch is allowed", func(t *testing.T) {
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "nobranch", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "nobranch", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob with empty branch failed: %v", err)
 		}
@@ -220,7 +269,7 @@ func TestBranchPersistence(t *testing.T) {
 
 	t.Run("UpdateJobBranch backfills empty branch", func(t *testing.T) {
 		// Create job with empty branch
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "updatebranch", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "updatebranch", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
@@ -229,7 +278,7 @@ func TestBranchPersistence(t *testing.T) {
 		}
 
 		// Update the branch
-		rowsAffected, err := db.UpdateJobBranch(job.ID, "feature/backfilled")
+		rowsAffected, err := db.UpdateJobBranch(t.Context(), job.ID, "feature/backfilled")
 		if err != nil {
 			t.Fatalf("UpdateJobBranch failed: %v", err)
 		}
@@ -238,7 +287,7 @@ func TestBranchPersistence(t *testing.T) {
 		}
 
 		// Verify the branch was updated
-		fetched, err := db.GetJobByID(job.ID)
+		fetched, err := db.GetJobByID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -249,13 +298,13 @@ func TestBranchPersistence(t *testing.T) {
 
 	t.Run("UpdateJobBranch does not overwrite existing branch", func(t *testing.T) {
 		// Create job with existing branch
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "nooverwrite", Branch: "original-branch", Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "nooverwrite", Branch: "original-branch", Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 
 		// Try to update - should not change existing branch
-		rowsAffected, err := db.UpdateJobBranch(job.ID, "new-branch")
+		rowsAffected, err := db.UpdateJobBranch(t.Context(), job.ID, "new-branch")
 		if err != nil {
 			t.Fatalf("UpdateJobBranch failed: %v", err)
 		}
@@ -264,7 +313,7 @@ func TestBranchPersistence(t *testing.T) {
 		}
 
 		// Verify the branch was NOT changed
-		fetched, err := db.GetJobByID(job.ID)
+		fetched, err := db.GetJobByID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -282,12 +331,12 @@ func TestJobFailure(t *testing.T) {
 	claimJob(t, db, "worker-1")
 
 	// Fail the job
-	err := db.FailJob(job.ID, "test error message")
+	err := db.FailJob(t.Context(), job.ID, "test error message")
 	if err != nil {
 		t.Fatalf("FailJob failed: %v", err)
 	}
 
-	updatedJob, err := db.GetJobByID(job.ID)
+	updatedJob, err := db.GetJobByID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetJobByID failed: %v", err)
 	}
@@ -305,12 +354,12 @@ func TestReviewOperations(t *testing.T) {
 
 	_, _, job := createJobChain(t, db, "/tmp/test-repo", "rev123")
 	claimJob(t, db, "worker-1")
-	if err := db.CompleteJob(job.ID, "codex", "the prompt", "the review output"); err != nil {
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "the prompt", "the review output"); err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Get review by commit SHA
-	review, err := db.GetReviewByCommitSHA("rev123")
+	review, err := db.GetReviewByCommitSHA(t.Context(), "rev123")
 	if err != nil {
 		t.Fatalf("GetReviewByCommitSHA failed: %v", err)
 	}
@@ -327,15 +376,15 @@ func TestReviewVerdictComputation(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 
 	t.Run("verdict populated when output exists and no error", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "verdict-pass", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-pass", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CompleteJob(job.ID, "codex", "the prompt", "No issues found. The code looks good.")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "verdict-pass", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-pass", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CompleteJob(t.Context(), job.ID, "codex", "the prompt", "No issues found. The code looks good.")
 
-		review, err := db.GetReviewByJobID(job.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -348,12 +397,12 @@ func TestReviewVerdictComputation(t *testing.T) {
 	})
 
 	t.Run("verdict nil when output is empty", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "verdict-empty", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-empty", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CompleteJob(job.ID, "codex", "the prompt", "") // empty output
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "verdict-empty", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-empty", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CompleteJob(t.Context(), job.ID, "codex", "the prompt", "") // empty output
 
-		review, err := db.GetReviewByJobID(job.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -363,10 +412,10 @@ func TestReviewVerdictComputation(t *testing.T) {
 	})
 
 	t.Run("verdict nil when job has error", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "verdict-error", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-error", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.FailJob(job.ID, "API rate limit exceeded")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "verdict-error", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-error", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.FailJob(t.Context(), job.ID, "API rate limit exceeded")
 
 		// Manually insert a review to simulate edge case
 		_, err := db.Exec(`INSERT INTO reviews (job_id, agent, prompt, output) VALUES (?, 'codex', 'prompt', 'No issues found.')`, job.ID)
@@ -374,7 +423,7 @@ func TestReviewVerdictComputation(t *testing.T) {
 			t.Fatalf("Failed to insert review: %v", err)
 		}
 
-		review, err := db.GetReviewByJobID(job.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -384,12 +433,12 @@ func TestReviewVerdictComputation(t *testing.T) {
 	})
 
 	t.Run("GetReviewByCommitSHA also respects verdict guard", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "verdict-sha", "Author", "Subject", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-sha", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CompleteJob(job.ID, "codex", "the prompt", "No issues found.")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "verdict-sha", "Author", "Subject", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "verdict-sha", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CompleteJob(t.Context(), job.ID, "codex", "the prompt", "No issues found.")
 
-		review, err := db.GetReviewByCommitSHA("verdict-sha")
+		review, err := db.GetReviewByCommitSHA(t.Context(), "verdict-sha")
 		if err != nil {
 			t.Fatalf("GetReviewByCommitSHA failed: %v", err)
 		}
@@ -406,11 +455,11 @@ func TestResponseOperations(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
-	commit, _ := db.GetOrCreateCommit(repo.ID, "resp123", "Author", "Subject", time.Now())
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "resp123", "Author", "Subject", time.Now())
 
 	// Add comment
-	resp, err := db.AddComment(commit.ID, "test-user", "LGTM!")
+	resp, err := db.AddComment(t.Context(), commit.ID, "test-user", "LGTM!")
 	if err != nil {
 		t.Fatalf("AddComment failed: %v", err)
 	}
@@ -420,7 +469,7 @@ func TestResponseOperations(t *testing.T) {
 	}
 
 	// Get comments
-	comments, err := db.GetCommentsForCommit(commit.ID)
+	comments, err := db.GetCommentsForCommit(t.Context(), commit.ID)
 	if err != nil {
 		t.Fatalf("GetCommentsForCommit failed: %v", err)
 	}
@@ -434,14 +483,14 @@ func TestMarkReviewAddressed(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
-	commit, _ := db.GetOrCreateCommit(repo.ID, "addr123", "Author", "Subject", time.Now())
-	job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "addr123", Agent: "codex"})
-	db.ClaimJob("worker-1")
-	db.CompleteJob(job.ID, "codex", "prompt", "output")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "addr123", "Author", "Subject", time.Now())
+	job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "addr123", Agent: "codex"})
+	db.ClaimJob(t.Context(), "worker-1")
+	db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
 	// Get the review
-	review, err := db.GetReviewByJobID(job.ID)
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
@@ -452,24 +501,24 @@ func TestMarkReviewAddressed(t *testing.T) {
 	}
 
 	// Mark as addressed
-	err = db.MarkReviewAddressed(review.ID, true)
+	err = db.MarkReviewAddressed(t.Context(), review.ID, true)
 	if err != nil {
 		t.Fatalf("MarkReviewAddressed failed: %v", err)
 	}
 
 	// Verify it's addressed
-	updated, _ := db.GetReviewByID(review.ID)
+	updated, _ := db.GetReviewByID(t.Context(), review.ID)
 	if !updated.Addressed {
 		t.Error("Review should be addressed after MarkReviewAddressed(true)")
 	}
 
 	// Mark as unaddressed
-	err = db.MarkReviewAddressed(review.ID, false)
+	err = db.MarkReviewAddressed(t.Context(), review.ID, false)
 	if err != nil {
 		t.Fatalf("MarkReviewAddressed(false) failed: %v", err)
 	}
 
-	updated2, _ := db.GetReviewByID(review.ID)
+	updated2, _ := db.GetReviewByID(t.Context(), review.ID)
 	if updated2.Addressed {
 		t.Error("Review should not be addressed after MarkReviewAddressed(false)")
 	}
@@ -480,7 +529,7 @@ func TestMarkReviewAddressedNotFound(t *testing.T) {
 	defer db.Close()
 
 	// Try to mark a non-existent review
-	err := db.MarkReviewAddressed(999999, true)
+	err := db.MarkReviewAddressed(t.Context(), 999999, true)
 	if err == nil {
 		t.Fatal("Expected error for non-existent review")
 	}
@@ -495,14 +544,14 @@ func TestMarkReviewAddressedByJobID(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
-	commit, _ := db.GetOrCreateCommit(repo.ID, "jobaddr123", "Author", "Subject", time.Now())
-	job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "jobaddr123", Agent: "codex"})
-	db.ClaimJob("worker-1")
-	db.CompleteJob(job.ID, "codex", "prompt", "output")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "jobaddr123", "Author", "Subject", time.Now())
+	job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "jobaddr123", Agent: "codex"})
+	db.ClaimJob(t.Context(), "worker-1")
+	db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
 	// Get the review to verify initial state
-	review, err := db.GetReviewByJobID(job.ID)
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
@@ -513,24 +562,24 @@ func TestMarkReviewAddressedByJobID(t *testing.T) {
 	}
 
 	// Mark as addressed using job ID
-	err = db.MarkReviewAddressedByJobID(job.ID, true)
+	err = db.MarkReviewAddressedByJobID(t.Context(), job.ID, true)
 	if err != nil {
 		t.Fatalf("MarkReviewAddressedByJobID failed: %v", err)
 	}
 
 	// Verify it's addressed
-	updated, _ := db.GetReviewByJobID(job.ID)
+	updated, _ := db.GetReviewByJobID(t.Context(), job.ID)
 	if !updated.Addressed {
 		t.Error("Review should be addressed after MarkReviewAddressedByJobID(true)")
 	}
 
 	// Mark as unaddressed using job ID
-	err = db.MarkReviewAddressedByJobID(job.ID, false)
+	err = db.MarkReviewAddressedByJobID(t.Context(), job.ID, false)
 	if err != nil {
 		t.Fatalf("MarkReviewAddressedByJobID(false) failed: %v", err)
 	}
 
-	updated2, _ := db.GetReviewByJobID(job.ID)
+	updated2, _ := db.GetReviewByJobID(t.Context(), job.ID)
 	if updated2.Addressed {
 		t.Error("Review should not be addressed after MarkReviewAddressedByJobID(false)")
 	}
@@ -541,7 +590,7 @@ func TestMarkReviewAddressedByJobIDNotFound(t *testing.T) {
 	defer db.Close()
 
 	// Try to mark a non-existent job
-	err := db.MarkReviewAddressedByJobID(999999, true)
+	err := db.MarkReviewAddressedByJobID(t.Context(), 999999, true)
 	if err == nil {
 		t.Fatal("Expected error for non-existent job")
 	}
@@ -556,38 +605,39 @@ func TestJobCounts(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 
 	// Create 3 jobs that will stay queued
 	for i := 0; i < 3; i++ {
 		sha := fmt.Sprintf("queued%d", i)
-		commit, _ := db.GetOrCreateCommit(repo.ID, sha, "A", "S", time.Now())
-		db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "A", "S", time.Now())
+		db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex"})
 	}
 
 	// Create a job, claim it, and complete it
-	commit, _ := db.GetOrCreateCommit(repo.ID, "done1", "A", "S", time.Now())
-	job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "done1", Agent: "codex"})
-	_, _ = db.ClaimJob("w1")        // Claims oldest queued job (one of queued0-2)
-	_, _ = db.ClaimJob("w1")        // Claims next
-	_, _ = db.ClaimJob("w1")        // Claims next
-	claimed, _ := db.ClaimJob("w1") // Should claim "done1" job now
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "done1", "A", "S", time.Now())
+	job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "done1", Agent: "codex"})
+	_, _ = db.ClaimJob(t.Context(), "w1")        // Claims oldest queued job (one of queued0-2)
+	_, _ = db.ClaimJob(t.Context(), "w1")        // Claims next
+	_, _ = db.ClaimJob(t.Context(), "w1")        // Claims next
+	claimed, _ := db.ClaimJob(t.Context(), "w1") // Should claim "done1" job now
 	if claimed != nil {
-		db.CompleteJob(claimed.ID, "codex", "p", "o")
+		db.CompleteJob(t.Context(), claimed.ID, "codex", "p", "o")
 	}
 
 	// Create a job, claim it, and fail it
-	commit2, _ := db.GetOrCreateCommit(repo.ID, "fail1", "A", "S", time.Now())
-	_, _ = db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "fail1", Agent: "codex"})
-	claimed2, _ := db.ClaimJob("w2")
+	commit2, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "fail1", "A", "S", time.Now())
+	_, _ = db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "fail1", Agent: "codex"})
+	claimed2, _ := db.ClaimJob(t.Context(), "w2")
 	if claimed2 != nil {
-		db.FailJob(claimed2.ID, "err")
+		db.FailJob(t.Context(), claimed2.ID, "err")
 	}
 
-	queued, _, done, failed, _, err := db.GetJobCounts()
+	counts, err := db.CountJobs(t.Context(), CountByStatus, JobCountFilter{})
 	if err != nil {
-		t.Fatalf("GetJobCounts failed: %v", err)
+		t.Fatalf("CountJobs failed: %v", err)
 	}
+	done, failed := counts.Status(JobStatusDone), counts.Status(JobStatusFailed)
 
 	// We expect: 0 queued (all were claimed), 1 done, 1 failed, 3 running
 	// Actually let's just verify done and failed are correct
@@ -597,20 +647,87 @@ func TestJobCounts(t *testing.T) {
 	if failed != 1 {
 		t.Errorf("Expected 1 failed, got %d", failed)
 	}
-	_ = queued
 	_ = job
 }
 
+func TestCountJobs(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repoA := createRepo(t, db, "/tmp/count-a")
+	repoB := createRepo(t, db, "/tmp/count-b")
+	enqueue := func(repoID int64, sha, agent, enqueuedAt string) *ReviewJob {
+		commit := createCommit(t, db, repoID, sha)
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repoID, CommitID: commit.ID, GitRef: sha, Agent: agent})
+		if err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE id = ?`, enqueuedAt, job.ID); err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+	enqueue(repoA.ID, "c1", "codex", "2026-03-01 09:00:00")
+	enqueue(repoA.ID, "c2", "claude-code", "2026-03-01 18:00:00")
+	failed := enqueue(repoB.ID, "c3", "codex", "2026-03-02 10:00:00")
+	if _, err := db.Exec(`UPDATE review_jobs SET status = 'failed' WHERE id = ?`, failed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		groupBy JobCountGroup
+		filter  JobCountFilter
+		want    JobCounts
+	}{
+		{"total", CountTotal, JobCountFilter{}, JobCounts{"": 3}},
+		{"status", CountByStatus, JobCountFilter{}, JobCounts{"queued": 2, "failed": 1}},
+		{"agent", CountByAgent, JobCountFilter{}, JobCounts{"codex": 2, "claude-code": 1}},
+		{"repo", CountByRepo, JobCountFilter{}, JobCounts{"/tmp/count-a": 2, "/tmp/count-b": 1}},
+		{"day", CountByDay, JobCountFilter{}, JobCounts{"2026-03-01": 2, "2026-03-02": 1}},
+		{"repo filter", CountByStatus, JobCountFilter{RepoID: repoA.ID}, JobCounts{"queued": 2}},
+		{"status and agent filter", CountByRepo, JobCountFilter{Status: JobStatusQueued, Agent: "codex"}, JobCounts{"/tmp/count-a": 1}},
+		{"since filter", CountByAgent, JobCountFilter{Since: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}, JobCounts{"codex": 1, "claude-code": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.CountJobs(t.Context(), tt.groupBy, tt.filter)
+			if err != nil {
+				t.Fatalf("CountJobs: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("CountJobs = %v, want %v", got, tt.want)
+			}
+			for k, n := range tt.want {
+				if got[k] != n {
+					t.Errorf("CountJobs[%q] = %d, want %d (all: %v)", k, got[k], n, got)
+				}
+			}
+		})
+	}
+
+	counts, err := db.CountJobs(t.Context(), CountByStatus, JobCountFilter{})
+	if err != nil {
+		t.Fatalf("CountJobs: %v", err)
+	}
+	if counts.Total() != 3 || counts.Status(JobStatusQueued) != 2 || counts.Status(JobStatusDone) != 0 {
+		t.Errorf("unexpected accessors on %v", counts)
+	}
+	if _, err := db.CountJobs(t.Context(), "branch", JobCountFilter{}); err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+}
+
 func TestCountStalledJobs(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 
 	// Create a job and claim it (makes it running with current timestamp)
-	commit1, _ := db.GetOrCreateCommit(repo.ID, "recent1", "A", "S", time.Now())
-	db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit1.ID, GitRef: "recent1", Agent: "codex"})
-	_, _ = db.ClaimJob("worker-1")
+	commit1, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "recent1", "A", "S", time.Now())
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit1.ID, GitRef: "recent1", Agent: "codex"})
+	_, _ = db.ClaimJob(t.Context(), "worker-1")
 
 	// No stalled jobs yet (just started)
 	count, err := db.CountStalledJobs(30 * time.Minute)
@@ -623,8 +740,8 @@ func TestCountStalledJobs(t *testing.T) {
 
 	// Create a job and manually set started_at to 1 hour ago (simulating stalled job)
 	// Use UTC format (ends with Z) to test basic case
-	commit2, _ := db.GetOrCreateCommit(repo.ID, "stalled1", "A", "S", time.Now())
-	job2, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "stalled1", Agent: "codex"})
+	commit2, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "stalled1", "A", "S", time.Now())
+	job2, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "stalled1", Agent: "codex"})
 	oldTimeUTC := time.Now().Add(-1 * time.Hour).UTC().Format(time.RFC3339)
 	_, err = db.Exec(`UPDATE review_jobs SET status = 'running', started_at = ? WHERE id = ?`, oldTimeUTC, job2.ID)
 	if err != nil {
@@ -642,8 +759,8 @@ func TestCountStalledJobs(t *testing.T) {
 
 	// Create another stalled job with a non-UTC timezone offset to verify datetime() handles offsets
 	// This exercises the fix for RFC3339 timestamps with timezone offsets like "-07:00"
-	commit3, _ := db.GetOrCreateCommit(repo.ID, "stalled2", "A", "S", time.Now())
-	job3, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit3.ID, GitRef: "stalled2", Agent: "codex"})
+	commit3, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "stalled2", "A", "S", time.Now())
+	job3, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit3.ID, GitRef: "stalled2", Agent: "codex"})
 	// Use a fixed timezone offset (e.g., UTC-7) instead of UTC
 	tzMinus7 := time.FixedZone("UTC-7", -7*60*60)
 	oldTimeWithOffset := time.Now().Add(-1 * time.Hour).In(tzMinus7).Format(time.RFC3339)
@@ -681,7 +798,7 @@ func TestRetryJob(t *testing.T) {
 	claimJob(t, db, "worker-1")
 
 	// Retry should succeed (retry_count: 0 -> 1)
-	retried, err := db.RetryJob(job.ID, 3)
+	retried, err := db.RetryJob(t.Context(), job.ID, 3)
 	if err != nil {
 		t.Fatalf("RetryJob failed: %v", err)
 	}
@@ -690,29 +807,29 @@ func TestRetryJob(t *testing.T) {
 	}
 
 	// Verify job is queued with retry_count=1
-	updatedJob, _ := db.GetJobByID(job.ID)
+	updatedJob, _ := db.GetJobByID(t.Context(), job.ID)
 	if updatedJob.Status != JobStatusQueued {
 		t.Errorf("Expected status 'queued', got '%s'", updatedJob.Status)
 	}
-	count, _ := db.GetJobRetryCount(job.ID)
+	count, _ := db.GetJobRetryCount(t.Context(), job.ID)
 	if count != 1 {
 		t.Errorf("Expected retry_count=1, got %d", count)
 	}
 
 	// Claim again and retry twice more (retry_count: 1->2, 2->3)
-	_, _ = db.ClaimJob("worker-1")
-	db.RetryJob(job.ID, 3) // retry_count becomes 2
-	_, _ = db.ClaimJob("worker-1")
-	db.RetryJob(job.ID, 3) // retry_count becomes 3
+	_, _ = db.ClaimJob(t.Context(), "worker-1")
+	db.RetryJob(t.Context(), job.ID, 3) // retry_count becomes 2
+	_, _ = db.ClaimJob(t.Context(), "worker-1")
+	db.RetryJob(t.Context(), job.ID, 3) // retry_count becomes 3
 
-	count, _ = db.GetJobRetryCount(job.ID)
+	count, _ = db.GetJobRetryCount(t.Context(), job.ID)
 	if count != 3 {
 		t.Errorf("Expected retry_count=3, got %d", count)
 	}
 
 	// Claim again - next retry should fail (at max)
-	_, _ = db.ClaimJob("worker-1")
-	retried, err = db.RetryJob(job.ID, 3)
+	_, _ = db.ClaimJob(t.Context(), "worker-1")
+	retried, err = db.RetryJob(t.Context(), job.ID, 3)
 	if err != nil {
 		t.Fatalf("RetryJob at max failed: %v", err)
 	}
@@ -721,7 +838,7 @@ func TestRetryJob(t *testing.T) {
 	}
 
 	// Job should still be running (retry didn't happen)
-	updatedJob, _ = db.GetJobByID(job.ID)
+	updatedJob, _ = db.GetJobByID(t.Context(), job.ID)
 	if updatedJob.Status != JobStatusRunning {
 		t.Errorf("Expected status 'running' after failed retry, got '%s'", updatedJob.Status)
 	}
@@ -734,7 +851,7 @@ func TestRetryJobOnlyWorksForRunning(t *testing.T) {
 	_, _, job := createJobChain(t, db, "/tmp/test-repo", "retry-status")
 
 	// Try to retry a queued job (should fail - not running)
-	retried, err := db.RetryJob(job.ID, 3)
+	retried, err := db.RetryJob(t.Context(), job.ID, 3)
 	if err != nil {
 		t.Fatalf("RetryJob on queued job failed: %v", err)
 	}
@@ -743,10 +860,10 @@ func TestRetryJobOnlyWorksForRunning(t *testing.T) {
 	}
 
 	// Claim, complete, then try retry (should fail - job is done)
-	_, _ = db.ClaimJob("worker-1")
-	db.CompleteJob(job.ID, "codex", "p", "o")
+	_, _ = db.ClaimJob(t.Context(), "worker-1")
+	db.CompleteJob(t.Context(), job.ID, "codex", "p", "o")
 
-	retried, err = db.RetryJob(job.ID, 3)
+	retried, err = db.RetryJob(t.Context(), job.ID, 3)
 	if err != nil {
 		t.Fatalf("RetryJob on done job failed: %v", err)
 	}
@@ -764,8 +881,8 @@ func TestRetryJobAtomic(t *testing.T) {
 
 	// Simulate two concurrent retries - only first should succeed
 	// (In practice this tests the atomic update)
-	retried1, _ := db.RetryJob(job.ID, 3)
-	retried2, _ := db.RetryJob(job.ID, 3) // Job is now queued, not running
+	retried1, _ := db.RetryJob(t.Context(), job.ID, 3)
+	retried2, _ := db.RetryJob(t.Context(), job.ID, 3) // Job is now queued, not running
 
 	if !retried1 {
 		t.Error("First retry should succeed")
@@ -775,89 +892,144 @@ func TestRetryJobAtomic(t *testing.T) {
 	}
 
 	// Verify retry_count is 1, not 2
-	count, _ := db.GetJobRetryCount(job.ID)
+	count, _ := db.GetJobRetryCount(t.Context(), job.ID)
 	if count != 1 {
 		t.Errorf("Expected retry_count=1 (atomic), got %d", count)
 	}
 }
 
+func TestBumpJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/test-repo")
+	var jobs []*ReviewJob
+	for _, sha := range []string{"bump-a", "bump-b", "bump-c"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		jobs = append(jobs, enqueueJob(t, db, repo.ID, commit.ID, sha))
+	}
+
+	// Bumped jobs are claimed first, the latest bump first
+	if err := db.BumpJob(t.Context(), jobs[1].ID); err != nil {
+		t.Fatalf("BumpJob: %v", err)
+	}
+	if err := db.BumpJob(t.Context(), jobs[2].ID); err != nil {
+		t.Fatalf("BumpJob: %v", err)
+	}
+	for _, want := range []int64{jobs[2].ID, jobs[1].ID, jobs[0].ID} {
+		if got := claimJob(t, db, "worker-1"); got.ID != want {
+			t.Errorf("claimed job %d, want %d", got.ID, want)
+		}
+	}
+
+	if err := db.BumpJob(t.Context(), jobs[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows bumping a running job, got %v", err)
+	}
+}
+
+func TestClaimJobByPriority(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/test-repo")
+	var jobs []*ReviewJob
+	for i, priority := range []int{PriorityLow, PriorityNormal, PriorityHigh, PriorityNormal} {
+		sha := fmt.Sprintf("prio-%d", i)
+		commit := createCommit(t, db, repo.ID, sha)
+		job, err := db.EnqueueJobWithPriority(t.Context(), EnqueueOpts{
+			RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex",
+		}, priority)
+		if err != nil {
+			t.Fatalf("EnqueueJobWithPriority: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	// Highest priority first, then in enqueue order
+	for i, want := range []int64{jobs[2].ID, jobs[1].ID, jobs[3].ID, jobs[0].ID} {
+		if got := claimJob(t, db, fmt.Sprintf("worker-%d", i)); got.ID != want {
+			t.Errorf("claimed job %d, want %d", got.ID, want)
+		}
+	}
+}
+
 func TestCancelJob(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 
 	t.Run("cancel queued job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "cancel-queued", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-queued", Agent: "codex"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "cancel-queued", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-queued", Agent: "codex"})
 
-		err := db.CancelJob(job.ID)
+		err := db.CancelJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("CancelJob failed: %v", err)
 		}
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusCanceled {
 			t.Errorf("Expected status 'canceled', got '%s'", updated.Status)
 		}
 	})
 
 	t.Run("cancel running job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "cancel-running", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-running", Agent: "codex"})
-		db.ClaimJob("worker-1")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "cancel-running", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-running", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
 
-		err := db.CancelJob(job.ID)
+		err := db.CancelJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("CancelJob failed: %v", err)
 		}
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusCanceled {
 			t.Errorf("Expected status 'canceled', got '%s'", updated.Status)
 		}
 	})
 
 	t.Run("cancel done job fails", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "cancel-done", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-done", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CompleteJob(job.ID, "codex", "prompt", "output")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "cancel-done", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-done", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
-		err := db.CancelJob(job.ID)
+		err := db.CancelJob(t.Context(), job.ID)
 		if err == nil {
 			t.Error("CancelJob should fail for done jobs")
 		}
 	})
 
 	t.Run("cancel failed job fails", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "cancel-failed", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-failed", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.FailJob(job.ID, "some error")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "cancel-failed", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-failed", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.FailJob(t.Context(), job.ID, "some error")
 
-		err := db.CancelJob(job.ID)
+		err := db.CancelJob(t.Context(), job.ID)
 		if err == nil {
 			t.Error("CancelJob should fail for failed jobs")
 		}
 	})
 
 	t.Run("complete respects canceled status", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "complete-canceled", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "complete-canceled", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CancelJob(job.ID)
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "complete-canceled", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "complete-canceled", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CancelJob(t.Context(), job.ID)
 
 		// CompleteJob should not overwrite canceled status
-		db.CompleteJob(job.ID, "codex", "prompt", "output")
+		db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusCanceled {
 			t.Errorf("CompleteJob should not overwrite canceled status, got '%s'", updated.Status)
 		}
 
 		// Verify no review was inserted (should get sql.ErrNoRows)
-		_, err := db.GetReviewByJobID(job.ID)
+		_, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err == nil {
 			t.Error("No review should be inserted for canceled job")
 		} else if !errors.Is(err, sql.ErrNoRows) {
@@ -866,15 +1038,15 @@ func TestCancelJob(t *testing.T) {
 	})
 
 	t.Run("fail respects canceled status", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "fail-canceled", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "fail-canceled", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.CancelJob(job.ID)
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "fail-canceled", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "fail-canceled", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.CancelJob(t.Context(), job.ID)
 
 		// FailJob should not overwrite canceled status
-		db.FailJob(job.ID, "some error")
+		db.FailJob(t.Context(), job.ID, "some error")
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusCanceled {
 			t.Errorf("FailJob should not overwrite canceled status, got '%s'", updated.Status)
 		}
@@ -882,15 +1054,15 @@ func TestCancelJob(t *testing.T) {
 
 	t.Run("canceled jobs counted correctly", func(t *testing.T) {
 		// Create and cancel a new job
-		commit, _ := db.GetOrCreateCommit(repo.ID, "cancel-count", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-count", Agent: "codex"})
-		db.CancelJob(job.ID)
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "cancel-count", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "cancel-count", Agent: "codex"})
+		db.CancelJob(t.Context(), job.ID)
 
-		_, _, _, _, canceled, err := db.GetJobCounts()
+		counts, err := db.CountJobs(t.Context(), CountByStatus, JobCountFilter{})
 		if err != nil {
-			t.Fatalf("GetJobCounts failed: %v", err)
+			t.Fatalf("CountJobs failed: %v", err)
 		}
-		if canceled < 1 {
+		if canceled := counts.Status(JobStatusCanceled); canceled < 1 {
 			t.Errorf("Expected at least 1 canceled job, got %d", canceled)
 		}
 	})
@@ -986,7 +1158,7 @@ func TestMigrationFromOldSchema(t *testing.T) {
 	defer db.Close()
 
 	// Verify the old data is preserved
-	review, err := db.GetReviewByJobID(1)
+	review, err := db.GetReviewByJobID(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
@@ -997,8 +1169,8 @@ func TestMigrationFromOldSchema(t *testing.T) {
 	// Verify the new constraint allows 'canceled' status
 	// Use raw SQL to insert a job, since the migration test's schema may not
 	// have all columns that the high-level EnqueueJob function requires.
-	repo, _ := db.GetOrCreateRepo("/tmp/test2")
-	commit, _ := db.GetOrCreateCommit(repo.ID, "def456", "A", "S", time.Now())
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test2")
+	commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "def456", "A", "S", time.Now())
 	result, err := db.Exec(`INSERT INTO review_jobs (repo_id, commit_id, git_ref, agent, status) VALUES (?, ?, 'def456', 'codex', 'queued')`,
 		repo.ID, commit.ID)
 	if err != nil {
@@ -1028,17 +1200,22 @@ func TestMigrationFromOldSchema(t *testing.T) {
 		t.Errorf("Expected status 'canceled', got '%s'", status)
 	}
 
+	// The later 'skipped' and 'blocked' migration rebuilds the table again
+	for _, status := range []string{"skipped", "blocked"} {
+		if _, err := db.Exec(`UPDATE review_jobs SET status = ? WHERE id = ?`, status, jobID); err != nil {
+			t.Fatalf("Setting %s status failed after migration: %v", status, err)
+		}
+	}
+
 	// Verify constraint still rejects invalid status
 	_, err = db.Exec(`UPDATE review_jobs SET status = 'invalid' WHERE id = ?`, jobID)
 	if err == nil {
 		t.Error("Expected constraint violation for invalid status")
 	}
 
-	// Verify FK enforcement works after migration
-	// Note: SQLite FKs are OFF by default per-connection, so we can't test that the migration
-	// "left FKs enabled" in the pool. What we CAN verify is:
-	// 1. The migration succeeded (which includes the FK check at the end)
-	// 2. FK enforcement works when enabled on a connection
+	// Verify FK enforcement works after migration. Open enables foreign
+	// keys on every connection, and the migration turns them back on for
+	// the one it used.
 	ctx := context.Background()
 	conn, err := db.Conn(ctx)
 	if err != nil {
@@ -1046,24 +1223,56 @@ func TestMigrationFromOldSchema(t *testing.T) {
 	}
 	defer conn.Close()
 
-	// Check FK pragma value on this connection before we modify it
-	// This is informational - FKs are OFF by default in SQLite
 	var fkEnabled int
 	if err := conn.QueryRowContext(ctx, `PRAGMA foreign_keys`).Scan(&fkEnabled); err != nil {
 		t.Fatalf("Failed to check foreign_keys pragma: %v", err)
 	}
-	t.Logf("foreign_keys pragma on pooled connection: %d", fkEnabled)
-
-	// Enable FK enforcement and verify it works (proves schema is correct for FKs)
-	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
-		t.Fatalf("Failed to enable foreign keys: %v", err)
+	if fkEnabled != 1 {
+		t.Errorf("foreign_keys pragma on pooled connection = %d, want 1", fkEnabled)
 	}
+
 	_, err = conn.ExecContext(ctx, `INSERT INTO reviews (job_id, agent, prompt, output) VALUES (99999, 'test', 'p', 'o')`)
 	if err == nil {
 		t.Error("Expected foreign key violation for invalid job_id - FKs may not be working")
 	}
 }
 
+func TestEnqueueSkippedJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/skip-repo")
+	commit := createCommit(t, db, repo.ID, "skip123")
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "skip123", Agent: "codex", SkipReason: "generated code"})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	if job.Status != JobStatusSkipped || job.Error != "generated code" || job.FinishedAt == nil {
+		t.Errorf("unexpected returned job: status=%s error=%q finished=%v", job.Status, job.Error, job.FinishedAt)
+	}
+
+	if claimed, err := db.ClaimJob(t.Context(), "worker-1"); err != nil || claimed != nil {
+		t.Fatalf("expected skipped job not to be claimable, got %v (err %v)", claimed, err)
+	}
+
+	stored, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if stored.Status != JobStatusSkipped || stored.Error != "generated code" {
+		t.Errorf("unexpected stored job: status=%s error=%q", stored.Status, stored.Error)
+	}
+
+	// Skipped jobs can be rerun to review the commit anyway
+	if err := db.ReenqueueJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	stored, _ = db.GetJobByID(t.Context(), job.ID)
+	if stored.Status != JobStatusQueued || stored.Error != "" {
+		t.Errorf("expected rerun to queue the job, got status=%s error=%q", stored.Status, stored.Error)
+	}
+}
+
 func TestMigrationWithAlterTableColumnOrder(t *testing.T) {
 	// Test that migration works when columns were added via ALTER TABLE,
 	// which puts them at the end of the table (different from CREATE TABLE order)
@@ -1167,7 +1376,7 @@ func TestMigrationWithAlterTableColumnOrder(t *testing.T) {
 	defer db.Close()
 
 	// Verify job data is preserved with correct values
-	job, err := db.GetJobByID(1)
+	job, err := db.GetJobByID(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("GetJobByID failed: %v", err)
 	}
@@ -1199,7 +1408,7 @@ func TestMigrationWithAlterTableColumnOrder(t *testing.T) {
 	}
 
 	// Verify review data is preserved
-	review, err := db.GetReviewByJobID(1)
+	review, err := db.GetReviewByJobID(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
@@ -1210,11 +1419,11 @@ func TestMigrationWithAlterTableColumnOrder(t *testing.T) {
 	// Verify new constraint works by creating and canceling a job.
 	// Use raw SQL to insert/update the job, since the migration test's schema may
 	// not have all columns that the high-level EnqueueJob function requires.
-	repo2, err := db.GetOrCreateRepo("/tmp/test2")
+	repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/test2")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit2, err := db.GetOrCreateCommit(repo2.ID, "newsha", "A", "S", time.Now())
+	commit2, err := db.GetOrCreateCommit(t.Context(), repo2.ID, "newsha", "A", "S", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
@@ -1402,7 +1611,7 @@ func TestListReposWithReviewCounts(t *testing.T) {
 	defer db.Close()
 
 	t.Run("empty database", func(t *testing.T) {
-		repos, totalCount, err := db.ListReposWithReviewCounts()
+		repos, totalCount, err := db.ListReposWithReviewCounts(t.Context())
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCounts failed: %v", err)
 		}
@@ -1434,7 +1643,7 @@ func TestListReposWithReviewCounts(t *testing.T) {
 	}
 
 	t.Run("repos with varying job counts", func(t *testing.T) {
-		repos, totalCount, err := db.ListReposWithReviewCounts()
+		repos, totalCount, err := db.ListReposWithReviewCounts(t.Context())
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCounts failed: %v", err)
 		}
@@ -1468,19 +1677,19 @@ func TestListReposWithReviewCounts(t *testing.T) {
 
 	t.Run("counts include all job statuses", func(t *testing.T) {
 		// Claim and complete one job in repo1
-		claimed, _ := db.ClaimJob("worker-1")
+		claimed, _ := db.ClaimJob(t.Context(), "worker-1")
 		if claimed != nil {
-			db.CompleteJob(claimed.ID, "codex", "prompt", "output")
+			db.CompleteJob(t.Context(), claimed.ID, "codex", "prompt", "output")
 		}
 
 		// Claim and fail another job
-		claimed2, _ := db.ClaimJob("worker-1")
+		claimed2, _ := db.ClaimJob(t.Context(), "worker-1")
 		if claimed2 != nil {
-			db.FailJob(claimed2.ID, "test error")
+			db.FailJob(t.Context(), claimed2.ID, "test error")
 		}
 
 		// Counts should still be the same (counts all jobs, not just completed)
-		repos, totalCount, err := db.ListReposWithReviewCounts()
+		repos, totalCount, err := db.ListReposWithReviewCounts(t.Context())
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCounts failed: %v", err)
 		}
@@ -1523,7 +1732,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 	}
 
 	t.Run("no filter returns all jobs", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1534,7 +1743,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 
 	t.Run("repo filter returns only matching jobs", func(t *testing.T) {
 		// Filter by root_path (not name) since repos with same name could exist at different paths
-		jobs, err := db.ListJobs("", repo1.RootPath, 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "", repo1.RootPath, 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1549,7 +1758,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 	})
 
 	t.Run("limit parameter works", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 2, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 2, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1559,7 +1768,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 	})
 
 	t.Run("limit=0 returns all jobs", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 0, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 0, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1569,7 +1778,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 	})
 
 	t.Run("repo filter with limit", func(t *testing.T) {
-		jobs, err := db.ListJobs("", repo1.RootPath, 2, 0)
+		jobs, err := db.ListJobs(t.Context(), "", repo1.RootPath, 2, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1585,16 +1794,16 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 
 	t.Run("status and repo filter combined", func(t *testing.T) {
 		// Complete one job from repo1
-		claimed, err := db.ClaimJob("worker-1")
+		claimed, err := db.ClaimJob(t.Context(), "worker-1")
 		if err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
-		if err := db.CompleteJob(claimed.ID, "codex", "prompt", "output"); err != nil {
+		if err := db.CompleteJob(t.Context(), claimed.ID, "codex", "prompt", "output"); err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
 
 		// Query for done jobs in repo1
-		jobs, err := db.ListJobs("done", repo1.RootPath, 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "done", repo1.RootPath, 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1608,7 +1817,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 
 	t.Run("offset pagination", func(t *testing.T) {
 		// Get first 2 jobs
-		jobs1, err := db.ListJobs("", "", 2, 0)
+		jobs1, err := db.ListJobs(t.Context(), "", "", 2, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1617,7 +1826,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 		}
 
 		// Get next 2 jobs with offset
-		jobs2, err := db.ListJobs("", "", 2, 2)
+		jobs2, err := db.ListJobs(t.Context(), "", "", 2, 2)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1635,7 +1844,7 @@ func TestListJobsWithRepoFilter(t *testing.T) {
 		}
 
 		// Get remaining job with offset=4
-		jobs3, err := db.ListJobs("", "", 2, 4)
+		jobs3, err := db.ListJobs(t.Context(), "", "", 2, 4)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1660,7 +1869,7 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	}
 
 	t.Run("git_ref filter returns matching job", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithGitRef("abc123"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithGitRef("abc123"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1673,7 +1882,7 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	})
 
 	t.Run("git_ref filter with range ref", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithGitRef("abc123..def456"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithGitRef("abc123..def456"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1686,7 +1895,7 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	})
 
 	t.Run("git_ref filter with no match returns empty", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithGitRef("nonexistent"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithGitRef("nonexistent"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1696,7 +1905,7 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	})
 
 	t.Run("empty git_ref filter returns all jobs", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1706,7 +1915,7 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	})
 
 	t.Run("git_ref filter combined with repo filter", func(t *testing.T) {
-		jobs, err := db.ListJobs("", repo.RootPath, 50, 0, WithGitRef("def456"))
+		jobs, err := db.ListJobs(t.Context(), "", repo.RootPath, 50, 0, WithGitRef("def456"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1716,11 +1925,180 @@ func TestListJobsWithGitRefFilter(t *testing.T) {
 	})
 }
 
+func TestListJobsWithRepoIdentityFilter(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	// Two clones of the same repo share an identity
+	for _, path := range []string{"/tmp/clone-a", "/tmp/clone-b", "/tmp/other"} {
+		identity := "https://github.com/acme/app"
+		if path == "/tmp/other" {
+			identity = "https://github.com/acme/other"
+		}
+		repo, err := db.GetOrCreateRepo(t.Context(), path, identity)
+		if err != nil {
+			t.Fatalf("GetOrCreateRepo: %v", err)
+		}
+		commit := createCommit(t, db, repo.ID, "sha-"+filepath.Base(path))
+		enqueueJob(t, db, repo.ID, commit.ID, commit.SHA)
+	}
+
+	jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithRepoIdentity("https://github.com/acme/app"))
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs for identity, got %d", len(jobs))
+	}
+	for _, j := range jobs {
+		if j.RepoPath == "/tmp/other" {
+			t.Errorf("unexpected job from other repo: %+v", j)
+		}
+	}
+}
+
+func TestListJobsWithAgentAndDateRange(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/repo-agent-range")
+	now := time.Now().UTC()
+	var ids []int64
+	for i, agent := range []string{"codex", "claude-code", "codex"} {
+		commit := createCommit(t, db, repo.ID, fmt.Sprintf("range%d", i))
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: commit.SHA, Agent: agent})
+		if err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		// Enqueued 20, 10 and 0 days ago
+		enqueuedAt := now.AddDate(0, 0, 10*i-20).Format("2006-01-02 15:04:05")
+		if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE id = ?`, enqueuedAt, job.ID); err != nil {
+			t.Fatalf("set enqueued_at: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	jobIDs := func(opts ...ListJobsOption) []int64 {
+		t.Helper()
+		jobs, err := db.ListJobs(t.Context(), "", repo.RootPath, 0, 0, opts...)
+		if err != nil {
+			t.Fatalf("ListJobs: %v", err)
+		}
+		var got []int64
+		for _, j := range jobs {
+			got = append(got, j.ID)
+		}
+		return got
+	}
+
+	if got := jobIDs(WithAgent("codex")); !slices.Equal(got, []int64{ids[2], ids[0]}) {
+		t.Errorf("WithAgent(codex) = %v, want %v", got, []int64{ids[2], ids[0]})
+	}
+	if got := jobIDs(WithDateRange(now.AddDate(0, 0, -15), time.Time{})); !slices.Equal(got, []int64{ids[2], ids[1]}) {
+		t.Errorf("since 15 days ago = %v, want %v", got, []int64{ids[2], ids[1]})
+	}
+	if got := jobIDs(WithDateRange(now.AddDate(0, 0, -15), now.AddDate(0, 0, -5))); !slices.Equal(got, []int64{ids[1]}) {
+		t.Errorf("between 15 and 5 days ago = %v, want %v", got, []int64{ids[1]})
+	}
+	if got := jobIDs(WithAgent("codex"), WithDateRange(time.Time{}, now.AddDate(0, 0, -5))); !slices.Equal(got, []int64{ids[0]}) {
+		t.Errorf("codex before 5 days ago = %v, want %v", got, []int64{ids[0]})
+	}
+
+	stats, err := db.CountJobStats(t.Context(), repo.RootPath, WithAgent("codex"))
+	if err != nil {
+		t.Fatalf("CountJobStats: %v", err)
+	}
+	if stats.Done != 0 {
+		t.Errorf("CountJobStats done = %d, want 0", stats.Done)
+	}
+}
+
+func TestListReviews(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/repo-list-reviews")
+	other := createRepo(t, db, "/tmp/repo-list-reviews-other")
+	var reviewIDs []int64
+	for i := range 5 {
+		r := repo
+		if i == 4 {
+			r = other
+		}
+		commit := createCommit(t, db, r.ID, fmt.Sprintf("lr%d", i))
+		job := enqueueJob(t, db, r.ID, commit.ID, commit.SHA)
+		claimJob(t, db, "worker")
+		agent := "codex"
+		if i == 1 {
+			agent = "claude-code"
+		}
+		if err := db.CompleteJob(t.Context(), job.ID, agent, "prompt", fmt.Sprintf("review %d", i)); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		reviewIDs = append(reviewIDs, review.ID)
+	}
+	if _, err := db.Exec(`UPDATE reviews SET created_at = datetime('now', '-30 days') WHERE id = ?`, reviewIDs[0]); err != nil {
+		t.Fatalf("set created_at: %v", err)
+	}
+	if err := db.MarkReviewAddressed(t.Context(), reviewIDs[2], true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+
+	reviewIDsOf := func(limit, offset int, opts ...ListJobsOption) []int64 {
+		t.Helper()
+		reviews, err := db.ListReviews(t.Context(), repo.RootPath, limit, offset, opts...)
+		if err != nil {
+			t.Fatalf("ListReviews: %v", err)
+		}
+		var got []int64
+		for _, r := range reviews {
+			got = append(got, r.ID)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		opts          []ListJobsOption
+		want          []int64
+	}{
+		{"all", 0, 0, nil, []int64{reviewIDs[3], reviewIDs[2], reviewIDs[1], reviewIDs[0]}},
+		{"first page", 2, 0, nil, []int64{reviewIDs[3], reviewIDs[2]}},
+		{"offset page", 2, 2, nil, []int64{reviewIDs[1], reviewIDs[0]}},
+		{"cursor page", 2, 0, []ListJobsOption{WithBeforeID(reviewIDs[2])}, []int64{reviewIDs[1], reviewIDs[0]}},
+		{"agent", 0, 0, []ListJobsOption{WithAgent("claude-code")}, []int64{reviewIDs[1]}},
+		{"addressed", 0, 0, []ListJobsOption{WithAddressed(true)}, []int64{reviewIDs[2]}},
+		{"since", 0, 0, []ListJobsOption{WithDateRange(time.Now().AddDate(0, 0, -7), time.Time{})}, []int64{reviewIDs[3], reviewIDs[2], reviewIDs[1]}},
+		{"until", 0, 0, []ListJobsOption{WithDateRange(time.Time{}, time.Now().AddDate(0, 0, -7))}, []int64{reviewIDs[0]}},
+		{"git ref", 0, 0, []ListJobsOption{WithGitRef("lr3")}, []int64{reviewIDs[3]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reviewIDsOf(tt.limit, tt.offset, tt.opts...); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	all, err := db.ListReviews(t.Context(), "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListReviews across repos: %v", err)
+	}
+	if len(all) != 5 || all[0].ID != reviewIDs[4] || all[0].Output != "review 4" {
+		t.Errorf("ListReviews across repos = %+v", all)
+	}
+}
+
 func TestListJobsWithBranchAndAddressedFilters(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/repo-branch-addr")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/repo-branch-addr")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -1729,26 +2107,26 @@ func TestListJobsWithBranchAndAddressedFilters(t *testing.T) {
 	branches := []string{"main", "main", "feature"}
 	for i, br := range branches {
 		sha := fmt.Sprintf("sha%d", i)
-		commit, err := db.GetOrCreateCommit(repo.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Branch: br, Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Branch: br, Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 		// Complete the job so it has a review
-		db.ClaimJob("w")
-		db.CompleteJob(job.ID, "codex", "", fmt.Sprintf("output %d", i))
+		db.ClaimJob(t.Context(), "w")
+		db.CompleteJob(t.Context(), job.ID, "codex", "", fmt.Sprintf("output %d", i))
 
 		// Mark first job as addressed
 		if i == 0 {
-			db.MarkReviewAddressedByJobID(job.ID, true)
+			db.MarkReviewAddressedByJobID(t.Context(), job.ID, true)
 		}
 	}
 
 	t.Run("branch filter", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithBranch("main"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithBranch("main"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1758,7 +2136,7 @@ func TestListJobsWithBranchAndAddressedFilters(t *testing.T) {
 	})
 
 	t.Run("addressed=false filter", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithAddressed(false))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithAddressed(false))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1768,7 +2146,7 @@ func TestListJobsWithBranchAndAddressedFilters(t *testing.T) {
 	})
 
 	t.Run("addressed=true filter", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithAddressed(true))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithAddressed(true))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1778,7 +2156,7 @@ func TestListJobsWithBranchAndAddressedFilters(t *testing.T) {
 	})
 
 	t.Run("branch + addressed combined", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithBranch("main"), WithAddressed(false))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithBranch("main"), WithAddressed(false))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1792,7 +2170,7 @@ func TestWithBranchOrEmpty(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, err := db.GetOrCreateRepo("/tmp/repo-branch-empty")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/repo-branch-empty")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -1800,20 +2178,20 @@ func TestWithBranchOrEmpty(t *testing.T) {
 	// Create jobs: one on "main", one on "feature", one branchless
 	for i, br := range []string{"main", "feature", ""} {
 		sha := fmt.Sprintf("sha-be-%d", i)
-		commit, err := db.GetOrCreateCommit(repo.ID, sha, "Author", "Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Branch: br, Agent: "codex"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Branch: br, Agent: "codex"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		db.ClaimJob("w")
-		db.CompleteJob(job.ID, "codex", "", fmt.Sprintf("output %d", i))
+		db.ClaimJob(t.Context(), "w")
+		db.CompleteJob(t.Context(), job.ID, "codex", "", fmt.Sprintf("output %d", i))
 	}
 
 	t.Run("WithBranch strict excludes branchless", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithBranch("main"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithBranch("main"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1823,7 +2201,7 @@ func TestWithBranchOrEmpty(t *testing.T) {
 	})
 
 	t.Run("WithBranchOrEmpty includes branchless", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0, WithBranchOrEmpty("main"))
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0, WithBranchOrEmpty("main"))
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -1837,20 +2215,20 @@ func TestReenqueueJob(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	repo, _ := db.GetOrCreateRepo("/tmp/test-repo")
+	repo, _ := db.GetOrCreateRepo(t.Context(), "/tmp/test-repo")
 
 	t.Run("rerun failed job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-failed", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-failed", Agent: "codex"})
-		db.ClaimJob("worker-1")
-		db.FailJob(job.ID, "some error")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-failed", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-failed", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
+		db.FailJob(t.Context(), job.ID, "some error")
 
-		err := db.ReenqueueJob(job.ID)
+		err := db.ReenqueueJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("ReenqueueJob failed: %v", err)
 		}
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusQueued {
 			t.Errorf("Expected status 'queued', got '%s'", updated.Status)
 		}
@@ -1866,28 +2244,28 @@ func TestReenqueueJob(t *testing.T) {
 	})
 
 	t.Run("rerun canceled job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-canceled", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-canceled", Agent: "codex"})
-		db.CancelJob(job.ID)
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-canceled", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-canceled", Agent: "codex"})
+		db.CancelJob(t.Context(), job.ID)
 
-		err := db.ReenqueueJob(job.ID)
+		err := db.ReenqueueJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("ReenqueueJob failed: %v", err)
 		}
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusQueued {
 			t.Errorf("Expected status 'queued', got '%s'", updated.Status)
 		}
 	})
 
 	t.Run("rerun done job", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-done", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-done", Agent: "codex"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-done", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-done", Agent: "codex"})
 		// ClaimJob returns the claimed job; keep claiming until we get ours
 		var claimed *ReviewJob
 		for {
-			claimed, _ = db.ClaimJob("worker-1")
+			claimed, _ = db.ClaimJob(t.Context(), "worker-1")
 			if claimed == nil {
 				t.Fatal("No job to claim")
 			}
@@ -1895,44 +2273,44 @@ func TestReenqueueJob(t *testing.T) {
 				break
 			}
 			// Complete other jobs to clear them
-			db.CompleteJob(claimed.ID, "codex", "prompt", "output")
+			db.CompleteJob(t.Context(), claimed.ID, "codex", "prompt", "output")
 		}
-		db.CompleteJob(job.ID, "codex", "prompt", "output")
+		db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
-		err := db.ReenqueueJob(job.ID)
+		err := db.ReenqueueJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("ReenqueueJob failed: %v", err)
 		}
 
-		updated, _ := db.GetJobByID(job.ID)
+		updated, _ := db.GetJobByID(t.Context(), job.ID)
 		if updated.Status != JobStatusQueued {
 			t.Errorf("Expected status 'queued', got '%s'", updated.Status)
 		}
 	})
 
 	t.Run("rerun queued job fails", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-queued", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-queued", Agent: "codex"})
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-queued", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-queued", Agent: "codex"})
 
-		err := db.ReenqueueJob(job.ID)
+		err := db.ReenqueueJob(t.Context(), job.ID)
 		if err == nil {
 			t.Error("ReenqueueJob should fail for queued jobs")
 		}
 	})
 
 	t.Run("rerun running job fails", func(t *testing.T) {
-		commit, _ := db.GetOrCreateCommit(repo.ID, "rerun-running", "A", "S", time.Now())
-		job, _ := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-running", Agent: "codex"})
-		db.ClaimJob("worker-1")
+		commit, _ := db.GetOrCreateCommit(t.Context(), repo.ID, "rerun-running", "A", "S", time.Now())
+		job, _ := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "rerun-running", Agent: "codex"})
+		db.ClaimJob(t.Context(), "worker-1")
 
-		err := db.ReenqueueJob(job.ID)
+		err := db.ReenqueueJob(t.Context(), job.ID)
 		if err == nil {
 			t.Error("ReenqueueJob should fail for running jobs")
 		}
 	})
 
 	t.Run("rerun nonexistent job fails", func(t *testing.T) {
-		err := db.ReenqueueJob(99999)
+		err := db.ReenqueueJob(t.Context(), 99999)
 		if err == nil {
 			t.Error("ReenqueueJob should fail for nonexistent jobs")
 		}
@@ -1943,22 +2321,22 @@ func TestReenqueueJob(t *testing.T) {
 		isolatedDB := openTestDB(t)
 		defer isolatedDB.Close()
 
-		isolatedRepo, _ := isolatedDB.GetOrCreateRepo("/tmp/isolated-repo")
-		commit, _ := isolatedDB.GetOrCreateCommit(isolatedRepo.ID, "rerun-complete-cycle", "A", "S", time.Now())
-		job, _ := isolatedDB.EnqueueJob(EnqueueOpts{RepoID: isolatedRepo.ID, CommitID: commit.ID, GitRef: "rerun-complete-cycle", Agent: "codex"})
+		isolatedRepo, _ := isolatedDB.GetOrCreateRepo(t.Context(), "/tmp/isolated-repo")
+		commit, _ := isolatedDB.GetOrCreateCommit(t.Context(), isolatedRepo.ID, "rerun-complete-cycle", "A", "S", time.Now())
+		job, _ := isolatedDB.EnqueueJob(t.Context(), EnqueueOpts{RepoID: isolatedRepo.ID, CommitID: commit.ID, GitRef: "rerun-complete-cycle", Agent: "codex"})
 
 		// First completion cycle
-		claimed, _ := isolatedDB.ClaimJob("worker-1")
+		claimed, _ := isolatedDB.ClaimJob(t.Context(), "worker-1")
 		if claimed == nil || claimed.ID != job.ID {
 			t.Fatal("Failed to claim the expected job")
 		}
-		err := isolatedDB.CompleteJob(job.ID, "codex", "first prompt", "first output")
+		err := isolatedDB.CompleteJob(t.Context(), job.ID, "codex", "first prompt", "first output")
 		if err != nil {
 			t.Fatalf("First CompleteJob failed: %v", err)
 		}
 
 		// Verify first review exists
-		review1, err := isolatedDB.GetReviewByJobID(job.ID)
+		review1, err := isolatedDB.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed after first complete: %v", err)
 		}
@@ -1967,29 +2345,29 @@ func TestReenqueueJob(t *testing.T) {
 		}
 
 		// Re-enqueue the done job
-		err = isolatedDB.ReenqueueJob(job.ID)
+		err = isolatedDB.ReenqueueJob(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("ReenqueueJob failed: %v", err)
 		}
 
 		// Verify review was deleted
-		_, err = isolatedDB.GetReviewByJobID(job.ID)
+		_, err = isolatedDB.GetReviewByJobID(t.Context(), job.ID)
 		if err == nil {
 			t.Error("Expected GetReviewByJobID to fail after re-enqueue (review should be deleted)")
 		}
 
 		// Second completion cycle
-		claimed, _ = isolatedDB.ClaimJob("worker-1")
+		claimed, _ = isolatedDB.ClaimJob(t.Context(), "worker-1")
 		if claimed == nil || claimed.ID != job.ID {
 			t.Fatal("Failed to claim the expected job for second cycle")
 		}
-		err = isolatedDB.CompleteJob(job.ID, "codex", "second prompt", "second output")
+		err = isolatedDB.CompleteJob(t.Context(), job.ID, "codex", "second prompt", "second output")
 		if err != nil {
 			t.Fatalf("Second CompleteJob failed: %v", err)
 		}
 
 		// Verify second review exists with new content
-		review2, err := isolatedDB.GetReviewByJobID(job.ID)
+		review2, err := isolatedDB.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed after second complete: %v", err)
 		}
@@ -2005,13 +2383,13 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 	defer db.Close()
 
 	repoPath := filepath.Join(t.TempDir(), "agentic-test-repo")
-	repo, err := db.GetOrCreateRepo(repoPath)
+	repo, err := db.GetOrCreateRepo(t.Context(), repoPath)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
 	// Enqueue a prompt job with agentic=true
-	job, err := db.EnqueueJob(EnqueueOpts{
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{
 		RepoID:  repo.ID,
 		Agent:   "test-agent",
 		Prompt:  "Review this code",
@@ -2027,7 +2405,7 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 	}
 
 	t.Run("ListJobs returns agentic field", func(t *testing.T) {
-		jobs, err := db.ListJobs("", "", 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -2052,7 +2430,7 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 	})
 
 	t.Run("GetJobByID returns agentic field", func(t *testing.T) {
-		fetchedJob, err := db.GetJobByID(job.ID)
+		fetchedJob, err := db.GetJobByID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -2063,7 +2441,7 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 
 	// Also test with agentic=false to ensure we're not just always returning true
 	t.Run("non-agentic job returns Agentic=false", func(t *testing.T) {
-		nonAgenticJob, err := db.EnqueueJob(EnqueueOpts{
+		nonAgenticJob, err := db.EnqueueJob(t.Context(), EnqueueOpts{
 			RepoID: repo.ID,
 			Agent:  "test-agent",
 			Prompt: "Another review",
@@ -2073,7 +2451,7 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 		}
 
 		// Check via GetJobByID
-		fetchedJob, err := db.GetJobByID(nonAgenticJob.ID)
+		fetchedJob, err := db.GetJobByID(t.Context(), nonAgenticJob.ID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -2082,7 +2460,7 @@ func TestListJobsAndGetJobByIDReturnAgentic(t *testing.T) {
 		}
 
 		// Check via ListJobs
-		jobs, err := db.ListJobs("", "", 50, 0)
+		jobs, err := db.ListJobs(t.Context(), "", "", 50, 0)
 		if err != nil {
 			t.Fatalf("ListJobs failed: %v", err)
 		}
@@ -2107,7 +2485,7 @@ func TestRepoIdentity(t *testing.T) {
 		db := openTestDB(t)
 		defer db.Close()
 
-		repo, err := db.GetOrCreateRepo("/tmp/identity-test", "git@github.com:foo/bar.git")
+		repo, err := db.GetOrCreateRepo(t.Context(), "/tmp/identity-test", "git@github.com:foo/bar.git")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
@@ -2117,7 +2495,7 @@ func TestRepoIdentity(t *testing.T) {
 		}
 
 		// Verify it persists
-		repo2, err := db.GetOrCreateRepo("/tmp/identity-test")
+		repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/identity-test")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo (second call) failed: %v", err)
 		}
@@ -2131,7 +2509,7 @@ func TestRepoIdentity(t *testing.T) {
 		defer db.Close()
 
 		// Create repo without identity
-		repo1, err := db.GetOrCreateRepo("/tmp/backfill-test")
+		repo1, err := db.GetOrCreateRepo(t.Context(), "/tmp/backfill-test")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
@@ -2140,7 +2518,7 @@ func TestRepoIdentity(t *testing.T) {
 		}
 
 		// Call again with identity - should backfill
-		repo2, err := db.GetOrCreateRepo("/tmp/backfill-test", "git@github.com:test/backfill.git")
+		repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/backfill-test", "git@github.com:test/backfill.git")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo with identity failed: %v", err)
 		}
@@ -2154,13 +2532,13 @@ func TestRepoIdentity(t *testing.T) {
 		defer db.Close()
 
 		// Create repo with identity
-		_, err := db.GetOrCreateRepo("/tmp/no-overwrite-test", "original-identity")
+		_, err := db.GetOrCreateRepo(t.Context(), "/tmp/no-overwrite-test", "original-identity")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
 
 		// Call again with different identity - should NOT overwrite
-		repo2, err := db.GetOrCreateRepo("/tmp/no-overwrite-test", "new-identity")
+		repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/no-overwrite-test", "new-identity")
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo with new identity failed: %v", err)
 		}
@@ -2179,7 +2557,7 @@ func TestRepoIdentity(t *testing.T) {
 		sharedIdentity := "git@github.com:org/shared-repo.git"
 
 		// Create first clone
-		repo1, err := db.GetOrCreateRepo("/tmp/clone-1", sharedIdentity)
+		repo1, err := db.GetOrCreateRepo(t.Context(), "/tmp/clone-1", sharedIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo for clone-1 failed: %v", err)
 		}
@@ -2188,7 +2566,7 @@ func TestRepoIdentity(t *testing.T) {
 		}
 
 		// Create second clone with same identity - should succeed (was failing before fix)
-		repo2, err := db.GetOrCreateRepo("/tmp/clone-2", sharedIdentity)
+		repo2, err := db.GetOrCreateRepo(t.Context(), "/tmp/clone-2", sharedIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo for clone-2 failed: %v (multiple clones with same identity should be allowed)", err)
 		}
@@ -2205,7 +2583,7 @@ func TestRepoIdentity(t *testing.T) {
 		}
 
 		// Verify both repos exist and can be retrieved
-		repos, err := db.ListRepos()
+		repos, err := db.ListRepos(t.Context())
 		if err != nil {
 			t.Fatalf("ListRepos failed: %v", err)
 		}
@@ -2231,16 +2609,16 @@ func TestDuplicateSHAHandling(t *testing.T) {
 		defer db.Close()
 
 		// Create two repos
-		repo1, _ := db.GetOrCreateRepo("/tmp/sha-test-1")
-		repo2, _ := db.GetOrCreateRepo("/tmp/sha-test-2")
+		repo1, _ := db.GetOrCreateRepo(t.Context(), "/tmp/sha-test-1")
+		repo2, _ := db.GetOrCreateRepo(t.Context(), "/tmp/sha-test-2")
 
 		// Create commits with same SHA in different repos
-		commit1, err := db.GetOrCreateCommit(repo1.ID, "abc123", "Author1", "Subject1", time.Now())
+		commit1, err := db.GetOrCreateCommit(t.Context(), repo1.ID, "abc123", "Author1", "Subject1", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit for repo1 failed: %v", err)
 		}
 
-		commit2, err := db.GetOrCreateCommit(repo2.ID, "abc123", "Author2", "Subject2", time.Now())
+		commit2, err := db.GetOrCreateCommit(t.Context(), repo2.ID, "abc123", "Author2", "Subject2", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit for repo2 failed: %v", err)
 		}
@@ -2262,14 +2640,14 @@ func TestDuplicateSHAHandling(t *testing.T) {
 		defer db.Close()
 
 		// Create two repos with same SHA
-		repo1, _ := db.GetOrCreateRepo("/tmp/ambiguous-1")
-		repo2, _ := db.GetOrCreateRepo("/tmp/ambiguous-2")
+		repo1, _ := db.GetOrCreateRepo(t.Context(), "/tmp/ambiguous-1")
+		repo2, _ := db.GetOrCreateRepo(t.Context(), "/tmp/ambiguous-2")
 
-		db.GetOrCreateCommit(repo1.ID, "ambiguous-sha", "Author", "Subject", time.Now())
-		db.GetOrCreateCommit(repo2.ID, "ambiguous-sha", "Author", "Subject", time.Now())
+		db.GetOrCreateCommit(t.Context(), repo1.ID, "ambiguous-sha", "Author", "Subject", time.Now())
+		db.GetOrCreateCommit(t.Context(), repo2.ID, "ambiguous-sha", "Author", "Subject", time.Now())
 
 		// GetCommitBySHA should fail when ambiguous
-		_, err := db.GetCommitBySHA("ambiguous-sha")
+		_, err := db.GetCommitBySHA(t.Context(), "ambiguous-sha")
 		if err == nil {
 			t.Error("Expected error when SHA is ambiguous across repos")
 		}
@@ -2280,14 +2658,14 @@ func TestDuplicateSHAHandling(t *testing.T) {
 		defer db.Close()
 
 		// Create two repos with same SHA
-		repo1, _ := db.GetOrCreateRepo("/tmp/repo-and-sha-1")
-		repo2, _ := db.GetOrCreateRepo("/tmp/repo-and-sha-2")
+		repo1, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo-and-sha-1")
+		repo2, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo-and-sha-2")
 
-		commit1, _ := db.GetOrCreateCommit(repo1.ID, "same-sha", "Author1", "Subject1", time.Now())
-		commit2, _ := db.GetOrCreateCommit(repo2.ID, "same-sha", "Author2", "Subject2", time.Now())
+		commit1, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "same-sha", "Author1", "Subject1", time.Now())
+		commit2, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, "same-sha", "Author2", "Subject2", time.Now())
 
 		// GetCommitByRepoAndSHA should return correct commit for each repo
-		found1, err := db.GetCommitByRepoAndSHA(repo1.ID, "same-sha")
+		found1, err := db.GetCommitByRepoAndSHA(t.Context(), repo1.ID, "same-sha")
 		if err != nil {
 			t.Fatalf("GetCommitByRepoAndSHA for repo1 failed: %v", err)
 		}
@@ -2295,7 +2673,7 @@ func TestDuplicateSHAHandling(t *testing.T) {
 			t.Error("GetCommitByRepoAndSHA returned wrong commit for repo1")
 		}
 
-		found2, err := db.GetCommitByRepoAndSHA(repo2.ID, "same-sha")
+		found2, err := db.GetCommitByRepoAndSHA(t.Context(), repo2.ID, "same-sha")
 		if err != nil {
 			t.Fatalf("GetCommitByRepoAndSHA for repo2 failed: %v", err)
 		}
@@ -2310,17 +2688,17 @@ func TestListReposWithReviewCountsByBranch(t *testing.T) {
 	defer db.Close()
 
 	// Create repos
-	repo1, _ := db.GetOrCreateRepo("/tmp/repo1")
-	repo2, _ := db.GetOrCreateRepo("/tmp/repo2")
+	repo1, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo1")
+	repo2, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo2")
 
 	// Create commits and jobs with different branches
-	commit1, _ := db.GetOrCreateCommit(repo1.ID, "abc123", "Author", "Subject", time.Now())
-	commit2, _ := db.GetOrCreateCommit(repo1.ID, "def456", "Author", "Subject", time.Now())
-	commit3, _ := db.GetOrCreateCommit(repo2.ID, "ghi789", "Author", "Subject", time.Now())
+	commit1, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "abc123", "Author", "Subject", time.Now())
+	commit2, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "def456", "Author", "Subject", time.Now())
+	commit3, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, "ghi789", "Author", "Subject", time.Now())
 
-	db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit1.ID, GitRef: "abc123", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit2.ID, GitRef: "def456", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo2.ID, CommitID: commit3.ID, GitRef: "ghi789", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit1.ID, GitRef: "abc123", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit2.ID, GitRef: "def456", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo2.ID, CommitID: commit3.ID, GitRef: "ghi789", Agent: "claude"})
 
 	// Update some jobs with branches
 	db.Exec("UPDATE review_jobs SET branch = 'main' WHERE id = 1")
@@ -2328,7 +2706,7 @@ func TestListReposWithReviewCountsByBranch(t *testing.T) {
 	db.Exec("UPDATE review_jobs SET branch = 'feature' WHERE id = 2")
 
 	t.Run("filter by main branch", func(t *testing.T) {
-		repos, totalCount, err := db.ListReposWithReviewCountsByBranch("main")
+		repos, totalCount, err := db.ListReposWithReviewCountsByBranch(t.Context(), "main")
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCountsByBranch failed: %v", err)
 		}
@@ -2341,7 +2719,7 @@ func TestListReposWithReviewCountsByBranch(t *testing.T) {
 	})
 
 	t.Run("filter by feature branch", func(t *testing.T) {
-		repos, totalCount, err := db.ListReposWithReviewCountsByBranch("feature")
+		repos, totalCount, err := db.ListReposWithReviewCountsByBranch(t.Context(), "feature")
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCountsByBranch failed: %v", err)
 		}
@@ -2355,10 +2733,10 @@ func TestListReposWithReviewCountsByBranch(t *testing.T) {
 
 	t.Run("filter by (none) branch", func(t *testing.T) {
 		// Add a job with no branch
-		commit4, _ := db.GetOrCreateCommit(repo1.ID, "jkl012", "Author", "Subject", time.Now())
-		db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit4.ID, GitRef: "jkl012", Agent: "claude"})
+		commit4, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "jkl012", "Author", "Subject", time.Now())
+		db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit4.ID, GitRef: "jkl012", Agent: "claude"})
 
-		repos, totalCount, err := db.ListReposWithReviewCountsByBranch("(none)")
+		repos, totalCount, err := db.ListReposWithReviewCountsByBranch(t.Context(), "(none)")
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCountsByBranch failed: %v", err)
 		}
@@ -2371,7 +2749,7 @@ func TestListReposWithReviewCountsByBranch(t *testing.T) {
 	})
 
 	t.Run("empty filter returns all", func(t *testing.T) {
-		repos, _, err := db.ListReposWithReviewCountsByBranch("")
+		repos, _, err := db.ListReposWithReviewCountsByBranch(t.Context(), "")
 		if err != nil {
 			t.Fatalf("ListReposWithReviewCountsByBranch failed: %v", err)
 		}
@@ -2386,21 +2764,21 @@ func TestListBranchesWithCounts(t *testing.T) {
 	defer db.Close()
 
 	// Create repos
-	repo1, _ := db.GetOrCreateRepo("/tmp/repo1")
-	repo2, _ := db.GetOrCreateRepo("/tmp/repo2")
+	repo1, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo1")
+	repo2, _ := db.GetOrCreateRepo(t.Context(), "/tmp/repo2")
 
 	// Create commits and jobs with different branches
-	commit1, _ := db.GetOrCreateCommit(repo1.ID, "abc123", "Author", "Subject", time.Now())
-	commit2, _ := db.GetOrCreateCommit(repo1.ID, "def456", "Author", "Subject", time.Now())
-	commit3, _ := db.GetOrCreateCommit(repo1.ID, "ghi789", "Author", "Subject", time.Now())
-	commit4, _ := db.GetOrCreateCommit(repo2.ID, "jkl012", "Author", "Subject", time.Now())
-	commit5, _ := db.GetOrCreateCommit(repo2.ID, "mno345", "Author", "Subject", time.Now())
-
-	db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit1.ID, GitRef: "abc123", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit2.ID, GitRef: "def456", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo1.ID, CommitID: commit3.ID, GitRef: "ghi789", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo2.ID, CommitID: commit4.ID, GitRef: "jkl012", Agent: "claude"})
-	db.EnqueueJob(EnqueueOpts{RepoID: repo2.ID, CommitID: commit5.ID, GitRef: "mno345", Agent: "claude"})
+	commit1, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "abc123", "Author", "Subject", time.Now())
+	commit2, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "def456", "Author", "Subject", time.Now())
+	commit3, _ := db.GetOrCreateCommit(t.Context(), repo1.ID, "ghi789", "Author", "Subject", time.Now())
+	commit4, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, "jkl012", "Author", "Subject", time.Now())
+	commit5, _ := db.GetOrCreateCommit(t.Context(), repo2.ID, "mno345", "Author", "Subject", time.Now())
+
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit1.ID, GitRef: "abc123", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit2.ID, GitRef: "def456", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo1.ID, CommitID: commit3.ID, GitRef: "ghi789", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo2.ID, CommitID: commit4.ID, GitRef: "jkl012", Agent: "claude"})
+	db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo2.ID, CommitID: commit5.ID, GitRef: "mno345", Agent: "claude"})
 
 	// Update branches
 	db.Exec("UPDATE review_jobs SET branch = 'main' WHERE id IN (1, 2, 4)")
@@ -2408,7 +2786,7 @@ func TestListBranchesWithCounts(t *testing.T) {
 	// job 5 has no branch (NULL)
 
 	t.Run("list all branches", func(t *testing.T) {
-		result, err := db.ListBranchesWithCounts(nil)
+		result, err := db.ListBranchesWithCounts(t.Context(), nil)
 		if err != nil {
 			t.Fatalf("ListBranchesWithCounts failed: %v", err)
 		}
@@ -2425,7 +2803,7 @@ func TestListBranchesWithCounts(t *testing.T) {
 
 	t.Run("filter by single repo", func(t *testing.T) {
 		// Use repo1.RootPath which is the normalized path stored in the DB
-		result, err := db.ListBranchesWithCounts([]string{repo1.RootPath})
+		result, err := db.ListBranchesWithCounts(t.Context(), []string{repo1.RootPath})
 		if err != nil {
 			t.Fatalf("ListBranchesWithCounts failed: %v", err)
 		}
@@ -2439,7 +2817,7 @@ func TestListBranchesWithCounts(t *testing.T) {
 
 	t.Run("filter by multiple repos", func(t *testing.T) {
 		// Use repo RootPath values which are the normalized paths stored in the DB
-		result, err := db.ListBranchesWithCounts([]string{repo1.RootPath, repo2.RootPath})
+		result, err := db.ListBranchesWithCounts(t.Context(), []string{repo1.RootPath, repo2.RootPath})
 		if err != nil {
 			t.Fatalf("ListBranchesWithCounts failed: %v", err)
 		}
@@ -2453,7 +2831,7 @@ func TestListBranchesWithCounts(t *testing.T) {
 
 	t.Run("no nulls when all have branches", func(t *testing.T) {
 		db.Exec("UPDATE review_jobs SET branch = 'develop' WHERE id = 5")
-		result, err := db.ListBranchesWithCounts(nil)
+		result, err := db.ListBranchesWithCounts(t.Context(), nil)
 		if err != nil {
 			t.Fatalf("ListBranchesWithCounts failed: %v", err)
 		}
@@ -2477,7 +2855,7 @@ func openTestDB(t *testing.T) *DB {
 
 func createRepo(t *testing.T, db *DB, path string) *Repo {
 	t.Helper()
-	repo, err := db.GetOrCreateRepo(path)
+	repo, err := db.GetOrCreateRepo(t.Context(), path)
 	if err != nil {
 		t.Fatalf("Failed to create repo: %v", err)
 	}
@@ -2486,7 +2864,7 @@ func createRepo(t *testing.T, db *DB, path string) *Repo {
 
 func createCommit(t *testing.T, db *DB, repoID int64, sha string) *Commit {
 	t.Helper()
-	commit, err := db.GetOrCreateCommit(repoID, sha, "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repoID, sha, "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("Failed to create commit: %v", err)
 	}
@@ -2495,7 +2873,7 @@ func createCommit(t *testing.T, db *DB, repoID int64, sha string) *Commit {
 
 func enqueueJob(t *testing.T, db *DB, repoID, commitID int64, sha string) *ReviewJob {
 	t.Helper()
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repoID, CommitID: commitID, GitRef: sha, Agent: "codex"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repoID, CommitID: commitID, GitRef: sha, Agent: "codex"})
 	if err != nil {
 		t.Fatalf("Failed to enqueue job: %v", err)
 	}
@@ -2504,7 +2882,7 @@ func enqueueJob(t *testing.T, db *DB, repoID, commitID int64, sha string) *Revie
 
 func claimJob(t *testing.T, db *DB, workerID string) *ReviewJob {
 	t.Helper()
-	job, err := db.ClaimJob(workerID)
+	job, err := db.ClaimJob(t.Context(), workerID)
 	if err != nil {
 		t.Fatalf("Failed to claim job: %v", err)
 	}
@@ -2516,7 +2894,7 @@ func claimJob(t *testing.T, db *DB, workerID string) *ReviewJob {
 
 func mustEnqueuePromptJob(t *testing.T, db *DB, opts EnqueueOpts) *ReviewJob {
 	t.Helper()
-	job, err := db.EnqueueJob(opts)
+	job, err := db.EnqueueJob(t.Context(), opts)
 	if err != nil {
 		t.Fatalf("Failed to enqueue prompt job: %v", err)
 	}
@@ -2555,22 +2933,22 @@ func TestListJobsVerdictForBranchRangeReview(t *testing.T) {
 
 	repo := createRepo(t, db, filepath.Join(t.TempDir(), "range-verdict-repo"))
 
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, GitRef: "abc123..def456", Agent: "codex"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, GitRef: "abc123..def456", Agent: "codex"})
 	if err != nil {
 		t.Fatalf("EnqueueRangeJob failed: %v", err)
 	}
 
 	// Claim and complete with findings output
-	_, err = db.ClaimJob("worker-0")
+	_, err = db.ClaimJob(t.Context(), "worker-0")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	err = db.CompleteJob(job.ID, "codex", "review prompt", "- Medium — Bug in line 42\nSummary: found issues.")
+	err = db.CompleteJob(t.Context(), job.ID, "codex", "review prompt", "- Medium — Bug in line 42\nSummary: found issues.")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
-	jobs, err := db.ListJobs("", "", 50, 0)
+	jobs, err := db.ListJobs(t.Context(), "", "", 50, 0)
 	if err != nil {
 		t.Fatalf("ListJobs failed: %v", err)
 	}
@@ -2691,3 +3069,156 @@ func createJobChain(t *testing.T, db *DB, repoPath, sha string) (*Repo, *Commit,
 	job := enqueueJob(t, db, repo.ID, commit.ID, sha)
 	return repo, commit, job
 }
+
+func TestGetAgentReviewStats(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/stats-repo")
+	other := createRepo(t, db, "/tmp/stats-other")
+
+	complete := func(repoID int64, sha, prompt string, seconds int) {
+		t.Helper()
+		commit := createCommit(t, db, repoID, sha)
+		job := enqueueJob(t, db, repoID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", prompt, "No issues found."); err != nil {
+			t.Fatal(err)
+		}
+		start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+		if _, err := db.Exec(`UPDATE review_jobs SET started_at = ?, finished_at = ? WHERE id = ?`,
+			start.Format(time.RFC3339), start.Add(time.Duration(seconds)*time.Second).Format(time.RFC3339), job.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	complete(repo.ID, "s1", strings.Repeat("a", 1000), 60)
+	complete(repo.ID, "s2", strings.Repeat("b", 3000), 120)
+	complete(repo.ID, "s3", "x", 30)
+	complete(other.ID, "s4", "y", 600)
+
+	// An offloaded prompt has unknown size
+	if _, err := db.Exec(`UPDATE reviews SET prompt = 'roborev-blob:sha256/ab/abc' WHERE prompt = 'x'`); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := db.GetAgentReviewStats(repo.ID)
+	if err != nil {
+		t.Fatalf("GetAgentReviewStats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 agent, got %+v", stats)
+	}
+	st := stats[0]
+	if st.Agent != "codex" || st.Jobs != 3 || st.TotalSeconds != 210 {
+		t.Errorf("unexpected totals: %+v", st)
+	}
+	if st.SizedJobs != 2 || st.PromptBytes != 4000 || st.SizedSeconds != 180 {
+		t.Errorf("unexpected sized totals: %+v", st)
+	}
+
+	all, err := db.GetAgentReviewStats(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Jobs != 4 {
+		t.Errorf("all repos: got %+v", all)
+	}
+}
+
+func TestFocusJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/focus-repo")
+	commit := createCommit(t, db, repo.ID, "focus1")
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "focus1", Agent: "codex", Focus: true})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	if !job.Focus {
+		t.Error("expected enqueued job to be a focus review")
+	}
+
+	got, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if !got.Focus {
+		t.Error("expected GetJobByID to load focus")
+	}
+	claimed, err := db.ClaimJob(t.Context(), "worker-1")
+	if err != nil {
+		t.Fatalf("ClaimJob: %v", err)
+	}
+	if claimed.ID != job.ID || !claimed.Focus {
+		t.Errorf("expected claimed focus job %d, got %+v", job.ID, claimed)
+	}
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	if !review.Job.Focus {
+		t.Error("expected review's job to be a focus review")
+	}
+}
+
+func TestRetryJobTruncated(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	_, _, job := createJobChain(t, db, "/tmp/test-repo", "truncate123")
+
+	// Only running jobs are requeued
+	if requeued, err := db.RetryJobTruncated(t.Context(), job.ID, 1); err != nil || requeued {
+		t.Fatalf("RetryJobTruncated on queued job = %v, %v; want false, nil", requeued, err)
+	}
+
+	claimJob(t, db, "worker-1")
+	requeued, err := db.RetryJobTruncated(t.Context(), job.ID, 1)
+	if err != nil || !requeued {
+		t.Fatalf("RetryJobTruncated = %v, %v; want true, nil", requeued, err)
+	}
+
+	claimed := claimJob(t, db, "worker-1")
+	if claimed.ID != job.ID || claimed.TruncationLevel != 1 {
+		t.Errorf("claimed job %d at truncation level %d, want job %d at level 1", claimed.ID, claimed.TruncationLevel, job.ID)
+	}
+	if count, _ := db.GetJobRetryCount(t.Context(), job.ID); count != 0 {
+		t.Errorf("expected truncated retry not to count as a retry, got retry_count=%d", count)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	_, _, job := createJobChain(t, db, "/tmp/test-repo", "canceled123")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, err := db.ClaimJob(ctx, "worker-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ClaimJob with canceled context: err = %v, want context.Canceled", err)
+	}
+	if _, err := db.GetJobByID(ctx, job.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetJobByID with canceled context: err = %v, want context.Canceled", err)
+	}
+	if _, err := db.ExportPeerBundle(ctx, time.Time{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExportPeerBundle with canceled context: err = %v, want context.Canceled", err)
+	}
+	if _, _, err := db.ListReposWithReviewCounts(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListReposWithReviewCounts with canceled context: err = %v, want context.Canceled", err)
+	}
+	if _, err := db.ListBranchesWithCounts(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListBranchesWithCounts with canceled context: err = %v, want context.Canceled", err)
+	}
+
+	// The job is still queued for a claim that isn't canceled
+	claimed := claimJob(t, db, "worker-1")
+	if claimed.ID != job.ID {
+		t.Errorf("claimed job %d, want %d", claimed.ID, job.ID)
+	}
+}
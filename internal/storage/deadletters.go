@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// JobFailure is one failed attempt of a job. Retrying a job clears its
+// error, so the failures of a job are its only record of earlier attempts.
+type JobFailure struct {
+	Attempt   int       `json:"attempt"` // 1 for the first run
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// DeadLetter is a job that failed for good: its retries ran out, or it
+// failed in a way retrying cannot fix. It stays failed in the queue until
+// it is requeued.
+type DeadLetter struct {
+	JobID     int64        `json:"job_id"`
+	RepoPath  string       `json:"repo_path"`
+	RepoName  string       `json:"repo_name"`
+	GitRef    string       `json:"git_ref"`
+	JobType   string       `json:"job_type"`
+	Agent     string       `json:"agent"`
+	ErrorCode ErrorCode    `json:"error_code,omitempty"` // Of the last attempt
+	Error     string       `json:"error"`                // Of the last attempt
+	Attempts  int          `json:"attempts"`
+	DeadAt    time.Time    `json:"dead_at"`
+	Failures  []JobFailure `json:"failures,omitempty"` // Every attempt, oldest first; set by GetDeadLetter
+}
+
+// RecordJobFailure records a failed attempt of a running job, numbered by
+// the retries it has had so far. Call it before retrying or failing the job.
+func (db *DB) RecordJobFailure(ctx context.Context, jobID int64, code ErrorCode, errorMsg string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO job_failures (job_id, attempt, error_code, error, failed_at)
+		SELECT id, retry_count + 1, ?, ?, ? FROM review_jobs WHERE id = ?
+	`, nullString(string(code)), errorMsg, time.Now().UTC().Format(time.RFC3339), jobID)
+	return err
+}
+
+// DeadLetterJob moves a failed job to the dead letters
+func (db *DB) DeadLetterJob(ctx context.Context, jobID int64) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO dead_letters (job_id, dead_at) VALUES (?, ?)
+	`, jobID, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// DeadLetterFilter selects dead letters; zero fields match all
+type DeadLetterFilter struct {
+	RepoPath  string    // Repo root path
+	ErrorCode ErrorCode // Error code of the last attempt
+	Limit     int       // Most recent first; 0 for no limit
+
+	// Keyset pagination: only letters after this one in listing order,
+	// those that died earlier or at the same time with a lower job ID
+	BeforeDeadAt time.Time
+	BeforeJobID  int64
+}
+
+// ListDeadLetters returns the dead letters matching filter, most recent
+// first, without their failures
+func (db *DB) ListDeadLetters(ctx context.Context, filter DeadLetterFilter) ([]DeadLetter, error) {
+	query := `
+		SELECT d.job_id, r.root_path, r.name, j.git_ref, j.job_type, j.agent,
+			COALESCE(j.error_code, ''), COALESCE(j.error, ''), d.dead_at,
+			(SELECT COUNT(*) FROM job_failures f WHERE f.job_id = d.job_id)
+		FROM dead_letters d
+		JOIN review_jobs j ON j.id = d.job_id
+		JOIN repos r ON r.id = j.repo_id`
+	var conditions []string
+	var args []any
+	if filter.RepoPath != "" {
+		conditions = append(conditions, "r.root_path = ?")
+		args = append(args, filter.RepoPath)
+	}
+	if filter.ErrorCode != "" {
+		conditions = append(conditions, "j.error_code = ?")
+		args = append(args, filter.ErrorCode)
+	}
+	if filter.BeforeJobID > 0 {
+		before := filter.BeforeDeadAt.UTC().Format(time.RFC3339)
+		conditions = append(conditions, "(d.dead_at < ? OR (d.dead_at = ? AND d.job_id < ?))")
+		args = append(args, before, before, filter.BeforeJobID)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY d.dead_at DESC, d.job_id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var letters []DeadLetter
+	for rows.Next() {
+		var d DeadLetter
+		var deadAt string
+		if err := rows.Scan(&d.JobID, &d.RepoPath, &d.RepoName, &d.GitRef, &d.JobType, &d.Agent,
+			&d.ErrorCode, &d.Error, &deadAt, &d.Attempts); err != nil {
+			return nil, err
+		}
+		d.DeadAt = parseSQLiteTime(deadAt)
+		letters = append(letters, d)
+	}
+	return letters, rows.Err()
+}
+
+// GetDeadLetter returns the dead letter of a job with the failure of each
+// of its attempts. Returns sql.ErrNoRows if the job is not a dead letter.
+func (db *DB) GetDeadLetter(ctx context.Context, jobID int64) (*DeadLetter, error) {
+	var d DeadLetter
+	var deadAt string
+	err := db.QueryRowContext(ctx, `
+		SELECT d.job_id, r.root_path, r.name, j.git_ref, j.job_type, j.agent,
+			COALESCE(j.error_code, ''), COALESCE(j.error, ''), d.dead_at
+		FROM dead_letters d
+		JOIN review_jobs j ON j.id = d.job_id
+		JOIN repos r ON r.id = j.repo_id
+		WHERE d.job_id = ?
+	`, jobID).Scan(&d.JobID, &d.RepoPath, &d.RepoName, &d.GitRef, &d.JobType, &d.Agent,
+		&d.ErrorCode, &d.Error, &deadAt)
+	if err != nil {
+		return nil, err
+	}
+	d.DeadAt = parseSQLiteTime(deadAt)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT attempt, COALESCE(error_code, ''), error, failed_at
+		FROM job_failures WHERE job_id = ? ORDER BY id
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f JobFailure
+		var failedAt string
+		if err := rows.Scan(&f.Attempt, &f.ErrorCode, &f.Error, &failedAt); err != nil {
+			return nil, err
+		}
+		f.FailedAt = parseSQLiteTime(failedAt)
+		d.Failures = append(d.Failures, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	d.Attempts = len(d.Failures)
+	return &d, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestDeadLetters(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := t.Context()
+
+	repo := createRepo(t, db, "/tmp/dead-letters")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "dead-sha").ID, "dead-sha")
+	other := createRepo(t, db, "/tmp/dead-letters-other")
+	otherJob := enqueueJob(t, db, other.ID, createCommit(t, db, other.ID, "other-sha").ID, "other-sha")
+
+	// Two attempts: the first is retried, the second fails for good
+	claimJob(t, db, "w1")
+	if err := db.RecordJobFailure(ctx, job.ID, ErrorCodeTimeout, "agent timed out"); err != nil {
+		t.Fatal(err)
+	}
+	if retried, err := db.RetryJob(ctx, job.ID, 1); err != nil || !retried {
+		t.Fatalf("RetryJob: %v, %v", retried, err)
+	}
+	claimJob(t, db, "w2")
+	if err := db.RecordJobFailure(ctx, job.ID, ErrorCodeAgentAuth, "401 unauthorized"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.FailJobWithCode(ctx, job.ID, ErrorCodeAgentAuth, "401 unauthorized"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeadLetterJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	claimJob(t, db, "w3")
+	if err := db.FailJobWithCode(ctx, otherJob.ID, ErrorCodeGitMissingObject, "bad object"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeadLetterJob(ctx, otherJob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := db.ListDeadLetters(t.Context(), DeadLetterFilter{})
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d dead letters, want 2", len(all))
+	}
+	byCode, err := db.ListDeadLetters(t.Context(), DeadLetterFilter{ErrorCode: ErrorCodeAgentAuth})
+	if err != nil || len(byCode) != 1 || byCode[0].JobID != job.ID || byCode[0].Attempts != 2 {
+		t.Errorf("unexpected dead letters by code %+v, %v", byCode, err)
+	}
+	byRepo, err := db.ListDeadLetters(t.Context(), DeadLetterFilter{RepoPath: other.RootPath})
+	if err != nil || len(byRepo) != 1 || byRepo[0].JobID != otherJob.ID {
+		t.Errorf("unexpected dead letters by repo %+v, %v", byRepo, err)
+	}
+
+	letter, err := db.GetDeadLetter(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetDeadLetter: %v", err)
+	}
+	if letter.ErrorCode != ErrorCodeAgentAuth || len(letter.Failures) != 2 ||
+		letter.Failures[0].Attempt != 1 || letter.Failures[0].ErrorCode != ErrorCodeTimeout ||
+		letter.Failures[1].Attempt != 2 || letter.Failures[1].Error != "401 unauthorized" {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+
+	// Requeueing starts afresh and leaves the dead letters
+	if err := db.ReenqueueJob(ctx, job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if _, err := db.GetDeadLetter(t.Context(), job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected a requeued job to leave the dead letters, got %v", err)
+	}
+	var failures int
+	db.QueryRow(`SELECT COUNT(*) FROM job_failures WHERE job_id = ?`, job.ID).Scan(&failures)
+	if failures != 0 {
+		t.Errorf("%d failures kept after requeue", failures)
+	}
+}
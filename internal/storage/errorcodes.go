@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrorCode is the typed cause of a job failure, recorded with its message
+// so retry policies and stats need not parse free text. The empty code is a
+// failure of no known kind.
+type ErrorCode string
+
+const (
+	ErrorCodeAgentAuth        ErrorCode = "agent_auth"         // The agent is not logged in or its API key is rejected
+	ErrorCodeAgentRateLimit   ErrorCode = "agent_rate_limit"   // The agent's API is rate limited or out of quota
+	ErrorCodeGitMissingObject ErrorCode = "git_missing_object" // The commit or range is not in the repo
+	ErrorCodeTimeout          ErrorCode = "timeout"            // The job ran past its timeout
+	ErrorCodeParseFailure     ErrorCode = "parse_failure"      // The agent's output could not be parsed
+	ErrorCodeSandboxDenied    ErrorCode = "sandbox_denied"     // The agent's sandbox refused an operation
+	ErrorCodePartFailed       ErrorCode = "part_failed"        // A part of a fanned-out review failed, so it cannot be joined
+)
+
+// ErrorCodes lists the known error codes
+var ErrorCodes = []ErrorCode{
+	ErrorCodeAgentAuth,
+	ErrorCodeAgentRateLimit,
+	ErrorCodeGitMissingObject,
+	ErrorCodeTimeout,
+	ErrorCodeParseFailure,
+	ErrorCodeSandboxDenied,
+	ErrorCodePartFailed,
+}
+
+// Retryable reports whether running the job again as is may succeed. Jobs
+// failing for want of credentials, sandbox permissions or git objects fail
+// the same way until someone steps in, and joins fail as long as their
+// failed parts do.
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorCodeAgentAuth, ErrorCodeSandboxDenied, ErrorCodeGitMissingObject, ErrorCodePartFailed:
+		return false
+	}
+	return true
+}
+
+// errorCodeMarkers are phrases in job error messages, lowercased, that give
+// away their cause, checked in order. Git and timeout markers go first, as
+// an agent's output quoted in its error may mention anything.
+var errorCodeMarkers = []struct {
+	code    ErrorCode
+	markers []string
+}{
+	{ErrorCodeGitMissingObject, []string{"bad object", "bad revision", "unknown revision", "not a valid object name", "invalid object name"}},
+	{ErrorCodeTimeout, []string{"deadline exceeded", "timed out", "timeout"}},
+	{ErrorCodeAgentRateLimit, []string{"rate limit", "rate_limit", "too many requests", "quota", "overloaded"}},
+	{ErrorCodeAgentAuth, []string{"unauthorized", "authentication", "api key", "api_key", "not logged in"}},
+	{ErrorCodeSandboxDenied, []string{"sandbox", "operation not permitted", "permission denied"}},
+	{ErrorCodeParseFailure, []string{"failed to parse", "parse error", "unmarshal", "invalid json", "invalid character"}},
+}
+
+// httpStatusCodes maps HTTP statuses in error messages to codes. They must
+// stand alone, so the digits of a commit SHA do not match.
+var httpStatusCodes = regexp.MustCompile(`\b(401|403|429)\b`)
+
+// ClassifyError returns the error code of a job error message, or the empty
+// code when nothing in it gives its cause away
+func ClassifyError(msg string) ErrorCode {
+	m := strings.ToLower(msg)
+	for _, c := range errorCodeMarkers {
+		for _, marker := range c.markers {
+			if strings.Contains(m, marker) {
+				return c.code
+			}
+		}
+	}
+	switch httpStatusCodes.FindString(m) {
+	case "401", "403":
+		return ErrorCodeAgentAuth
+	case "429":
+		return ErrorCodeAgentRateLimit
+	}
+	return ""
+}
+
+// FailJobWithCode marks a job as failed with an error code and message.
+// Only updates if job is still in 'running' state (respects cancellation).
+func (db *DB) FailJobWithCode(ctx context.Context, jobID int64, code ErrorCode, errorMsg string) error {
+	now := time.Now().Format(time.RFC3339)
+	_, err := db.ExecContext(ctx, `
+		UPDATE review_jobs SET status = 'failed', finished_at = ?, error = ?, error_code = ?, updated_at = ?
+		WHERE id = ? AND status = 'running'
+	`, now, errorMsg, nullString(string(code)), now, jobID)
+	return err
+}
+
+// RetryJobAfter requeues a running job for retry like RetryJob, holding it
+// back from workers for delay, as after hitting a rate limit
+func (db *DB) RetryJobAfter(ctx context.Context, jobID int64, maxRetries int, delay time.Duration) (bool, error) {
+	result, err := db.ExecContext(ctx, `
+		UPDATE review_jobs
+		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL,
+			error_code = NULL, retry_count = retry_count + 1, hold_until = ?
+		WHERE id = ? AND retry_count < ? AND status = 'running'
+	`, time.Now().Add(delay).UTC().Format(time.RFC3339), jobID, maxRetries)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	db.NotifyQueueChanged()
+	return true, nil
+}
+
+// backfillErrorCodes classifies the failures recorded before error codes
+func (db *DB) backfillErrorCodes() error {
+	rows, err := db.Query(`SELECT id, error FROM review_jobs WHERE status = 'failed' AND error IS NOT NULL AND error_code IS NULL`)
+	if err != nil {
+		return err
+	}
+	codes := make(map[int64]ErrorCode)
+	for rows.Next() {
+		var id int64
+		var msg string
+		if err := rows.Scan(&id, &msg); err != nil {
+			rows.Close()
+			return err
+		}
+		if code := ClassifyError(msg); code != "" {
+			codes[id] = code
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, code := range codes {
+		if _, err := db.Exec(`UPDATE review_jobs SET error_code = ? WHERE id = ?`, code, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want ErrorCode
+	}{
+		{"agent: exit status 1: Error: 401 Unauthorized", ErrorCodeAgentAuth},
+		{"agent: Invalid API key provided", ErrorCodeAgentAuth},
+		{"agent: 429 Too Many Requests", ErrorCodeAgentRateLimit},
+		{"agent: You exceeded your current quota", ErrorCodeAgentRateLimit},
+		{"build prompt: git show: fatal: bad object 3f2a9c1", ErrorCodeGitMissingObject},
+		{"build prompt: fatal: ambiguous argument 'abc..def': unknown revision", ErrorCodeGitMissingObject},
+		{"agent: timed out after 30 minutes: signal: killed", ErrorCodeTimeout},
+		{"agent: context deadline exceeded", ErrorCodeTimeout},
+		{"agent: failed to parse stream: invalid character 'x'", ErrorCodeParseFailure},
+		{"agent: sandbox: write to /etc/hosts blocked", ErrorCodeSandboxDenied},
+		{"agent: open /repo/.git/index.lock: operation not permitted", ErrorCodeSandboxDenied},
+		// Status codes inside a SHA are not HTTP statuses
+		{"agent: exit status 2 reviewing ab4291cd", ""},
+		{"agent: exit status 1", ""},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.msg); got != tt.want {
+			t.Errorf("ClassifyError(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestErrorCodeRetryable(t *testing.T) {
+	for _, code := range []ErrorCode{"", ErrorCodeAgentRateLimit, ErrorCodeTimeout, ErrorCodeParseFailure} {
+		if !code.Retryable() {
+			t.Errorf("%q should be retryable", code)
+		}
+	}
+	for _, code := range []ErrorCode{ErrorCodeAgentAuth, ErrorCodeSandboxDenied, ErrorCodeGitMissingObject, ErrorCodePartFailed} {
+		if code.Retryable() {
+			t.Errorf("%q should not be retryable", code)
+		}
+	}
+}
+
+func TestFailJobRecordsErrorCode(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := createRepo(t, db, "/tmp/repo")
+
+	commit := createCommit(t, db, repo.ID, "aaa")
+	enqueueJob(t, db, repo.ID, commit.ID, "aaa")
+	job := claimJob(t, db, "w1")
+	if err := db.FailJob(t.Context(), job.ID, "agent: 401 Unauthorized"); err != nil {
+		t.Fatalf("FailJob: %v", err)
+	}
+	got, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if got.ErrorCode != ErrorCodeAgentAuth {
+		t.Errorf("error code = %q, want %q", got.ErrorCode, ErrorCodeAgentAuth)
+	}
+
+	// A rerun clears the code with the message
+	if err := db.ReenqueueJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if got, _ := db.GetJobByID(t.Context(), job.ID); got.ErrorCode != "" {
+		t.Errorf("error code after rerun = %q, want none", got.ErrorCode)
+	}
+	job = claimJob(t, db, "w1")
+	if err := db.FailJobWithCode(t.Context(), job.ID, ErrorCodeTimeout, "agent: killed"); err != nil {
+		t.Fatalf("FailJobWithCode: %v", err)
+	}
+
+	commit = createCommit(t, db, repo.ID, "bbb")
+	enqueueJob(t, db, repo.ID, commit.ID, "bbb")
+	job = claimJob(t, db, "w1")
+	if err := db.FailJob(t.Context(), job.ID, "agent: exit status 1"); err != nil {
+		t.Fatalf("FailJob: %v", err)
+	}
+
+	jobs, err := db.ListJobs(t.Context(), "failed", "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	codes := map[string]ErrorCode{}
+	for _, j := range jobs {
+		codes[j.GitRef] = j.ErrorCode
+	}
+	if codes["aaa"] != ErrorCodeTimeout || codes["bbb"] != "" {
+		t.Errorf("listed error codes = %v, want aaa: timeout, bbb: none", codes)
+	}
+
+	counts, err := db.CountJobs(t.Context(), CountByErrorCode, JobCountFilter{Status: JobStatusFailed})
+	if err != nil {
+		t.Fatalf("CountJobs: %v", err)
+	}
+	if counts[string(ErrorCodeTimeout)] != 1 || counts[""] != 1 || len(counts) != 2 {
+		t.Errorf("counts by error code = %v", counts)
+	}
+}
+
+func TestRetryJobAfterHoldsJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := createRepo(t, db, "/tmp/repo")
+	commit := createCommit(t, db, repo.ID, "aaa")
+	enqueueJob(t, db, repo.ID, commit.ID, "aaa")
+	job := claimJob(t, db, "w1")
+
+	retried, err := db.RetryJobAfter(t.Context(), job.ID, 3, time.Hour)
+	if err != nil || !retried {
+		t.Fatalf("RetryJobAfter = %v, %v; want true", retried, err)
+	}
+	if n, _ := db.GetJobRetryCount(t.Context(), job.ID); n != 1 {
+		t.Errorf("retry count = %d, want 1", n)
+	}
+	claimed, err := db.ClaimJob(t.Context(), "w1")
+	if err != nil {
+		t.Fatalf("ClaimJob: %v", err)
+	}
+	if claimed != nil {
+		t.Errorf("claimed job %d during its hold", claimed.ID)
+	}
+	if err := db.BumpJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("BumpJob: %v", err)
+	}
+	claimJob(t, db, "w1")
+}
+
+func TestMigrationBackfillsErrorCodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	repo := createRepo(t, db, "/tmp/repo")
+	commit := createCommit(t, db, repo.ID, "aaa")
+	enqueueJob(t, db, repo.ID, commit.ID, "aaa")
+	job := claimJob(t, db, "w1")
+	if err := db.FailJob(t.Context(), job.ID, "agent: rate limit reached"); err != nil {
+		t.Fatalf("FailJob: %v", err)
+	}
+
+	// Drop the column, as in a database from before error codes
+	if _, err := db.Exec(`ALTER TABLE review_jobs DROP COLUMN error_code`); err != nil {
+		t.Fatalf("drop column: %v", err)
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	got, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if got.ErrorCode != ErrorCodeAgentRateLimit {
+		t.Errorf("backfilled error code = %q, want %q", got.ErrorCode, ErrorCodeAgentRateLimit)
+	}
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JobPart is one part of a fanned-out review: a job that reviews some of the
+// files of its parent's commit or range. The parent runs as the join job
+// once every part has finished and merges their reviews into its own.
+type JobPart struct {
+	JobID    int64     `json:"job_id"`
+	ParentID int64     `json:"parent_id"`
+	Paths    []string  `json:"paths"`
+	Status   JobStatus `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Agent    string    `json:"agent"`
+	Output   string    `json:"output,omitempty"` // Review output once done
+}
+
+// AddJobDependency makes jobID wait until dependsOn has finished: ClaimJob
+// passes over it while dependsOn is queued or running.
+func (db *DB) AddJobDependency(jobID, dependsOn int64) error {
+	_, err := db.Exec(`INSERT OR IGNORE INTO job_deps (job_id, depends_on) VALUES (?, ?)`, jobID, dependsOn)
+	return err
+}
+
+// FanOutJob splits a running commit or range job into one part per group of
+// paths. The parts are queued with the job's ref, agent and settings, and the
+// job goes back to the queue depending on all of them, to be claimed again as
+// the join. The job and its parts are tracked as a group named after the
+// job, unless the job is already in a group, which the parts then join.
+// Returns the IDs of the parts.
+func (db *DB) FanOutJob(ctx context.Context, jobID int64, groups [][]string) ([]int64, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("fan out job %d: no parts", jobID)
+	}
+	now := time.Now().Format(time.RFC3339)
+	machineID, _ := db.GetMachineID()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+	}()
+
+	// Requeue the job first so a canceled job is not fanned out
+	result, err := conn.ExecContext(ctx, `
+		UPDATE review_jobs
+		SET status = 'queued', worker_id = NULL, started_at = NULL, updated_at = ?
+		WHERE id = ? AND status = 'running'
+	`, now, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rows == 0 {
+		return nil, fmt.Errorf("fan out job %d: job is not running", jobID)
+	}
+
+	var groupID int64
+	err = conn.QueryRowContext(ctx, `SELECT group_id FROM job_group_jobs WHERE job_id = ?`, jobID).Scan(&groupID)
+	if err == sql.ErrNoRows {
+		name := fmt.Sprintf("fanout-%d", jobID)
+		if _, err := conn.ExecContext(ctx, `INSERT OR IGNORE INTO job_groups (name, kind) VALUES (?, ?)`,
+			name, JobGroupFanOut); err != nil {
+			return nil, err
+		}
+		if err := conn.QueryRowContext(ctx, `SELECT id FROM job_groups WHERE name = ?`, name).Scan(&groupID); err != nil {
+			return nil, err
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO job_group_jobs (job_id, group_id) VALUES (?, ?)`,
+			jobID, groupID); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(groups))
+	for _, paths := range groups {
+		result, err := conn.ExecContext(ctx, `
+			INSERT INTO review_jobs (repo_id, commit_id, git_ref, branch, agent, model, reasoning,
+				status, job_type, review_type, agentic, uuid, source_machine_id, updated_at, agent_policy)
+			SELECT repo_id, commit_id, git_ref, branch, agent, model, reasoning,
+				'queued', job_type, review_type, agentic, ?, ?, ?, agent_policy
+			FROM review_jobs WHERE id = ?
+		`, GenerateUUID(), machineID, now, jobID)
+		if err != nil {
+			return nil, err
+		}
+		partID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO job_parts (job_id, parent_id, paths) VALUES (?, ?, ?)`,
+			partID, jobID, strings.Join(paths, "\n")); err != nil {
+			return nil, err
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO job_deps (job_id, depends_on) VALUES (?, ?)`,
+			jobID, partID); err != nil {
+			return nil, err
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO job_group_jobs (job_id, group_id) VALUES (?, ?)`,
+			partID, groupID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, partID)
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, err
+	}
+	committed = true
+	db.NotifyQueueChanged()
+	return ids, nil
+}
+
+// GetJobPart returns the part a job reviews. Returns sql.ErrNoRows for jobs
+// that are not part of a fanned-out review.
+func (db *DB) GetJobPart(jobID int64) (*JobPart, error) {
+	var p JobPart
+	var paths string
+	err := db.QueryRow(`
+		SELECT p.job_id, p.parent_id, p.paths, j.status, j.agent
+		FROM job_parts p
+		JOIN review_jobs j ON j.id = p.job_id
+		WHERE p.job_id = ?
+	`, jobID).Scan(&p.JobID, &p.ParentID, &paths, &p.Status, &p.Agent)
+	if err != nil {
+		return nil, err
+	}
+	p.Paths = strings.Split(paths, "\n")
+	return &p, nil
+}
+
+// GetJobParts returns the parts the join job jobID waits on, with the review
+// output of those that are done. A job that has not been fanned out has no
+// parts.
+func (db *DB) GetJobParts(jobID int64) ([]JobPart, error) {
+	rows, err := db.Query(`
+		SELECT p.job_id, p.parent_id, p.paths, j.status, j.error, COALESCE(rv.agent, j.agent), rv.output
+		FROM job_deps d
+		JOIN job_parts p ON p.job_id = d.depends_on
+		JOIN review_jobs j ON j.id = p.job_id
+		LEFT JOIN reviews rv ON rv.job_id = p.job_id
+		WHERE d.job_id = ?
+		ORDER BY p.job_id
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []JobPart
+	for rows.Next() {
+		var p JobPart
+		var paths string
+		var errMsg, output sql.NullString
+		if err := rows.Scan(&p.JobID, &p.ParentID, &paths, &p.Status, &errMsg, &p.Agent, &output); err != nil {
+			return nil, err
+		}
+		p.Paths = strings.Split(paths, "\n")
+		p.Error = errMsg.String
+		if output.Valid {
+			p.Output = db.loadBlob(output.String)
+		}
+		parts = append(parts, p)
+	}
+	return parts, rows.Err()
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestFanOutJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/fanout-repo")
+	commit := createCommit(t, db, repo.ID, "fan1")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "fan1")
+	claimJob(t, db, "worker-1")
+
+	parts, err := db.FanOutJob(t.Context(), job.ID, [][]string{{"a.go", "b.go"}, {"c.go"}})
+	if err != nil {
+		t.Fatalf("FanOutJob: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %v", parts)
+	}
+
+	// The join waits for its parts, which copy its ref and agent
+	first := claimJob(t, db, "worker-1")
+	second := claimJob(t, db, "worker-2")
+	if first.ID != parts[0] || second.ID != parts[1] {
+		t.Fatalf("expected parts %v to be claimed first, got %d and %d", parts, first.ID, second.ID)
+	}
+	if first.ParentJobID != job.ID || first.GitRef != "fan1" || first.CommitID == nil || *first.CommitID != commit.ID || first.Agent != "codex" {
+		t.Errorf("part does not match its parent: %+v", first)
+	}
+	if next, err := db.ClaimJob(t.Context(), "worker-3"); err != nil || next != nil {
+		t.Fatalf("expected join to wait for running parts, got %+v, %v", next, err)
+	}
+
+	part, err := db.GetJobPart(second.ID)
+	if err != nil {
+		t.Fatalf("GetJobPart: %v", err)
+	}
+	if part.ParentID != job.ID || len(part.Paths) != 1 || part.Paths[0] != "c.go" {
+		t.Errorf("unexpected part %+v", part)
+	}
+	if _, err := db.GetJobPart(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected parent not to be a part, got %v", err)
+	}
+
+	if err := db.CompleteJob(t.Context(), first.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if err := db.FailJob(t.Context(), second.ID, "agent crashed"); err != nil {
+		t.Fatalf("FailJob: %v", err)
+	}
+
+	join := claimJob(t, db, "worker-1")
+	if join.ID != job.ID {
+		t.Fatalf("expected join job %d, got %d", job.ID, join.ID)
+	}
+	got, err := db.GetJobParts(job.ID)
+	if err != nil {
+		t.Fatalf("GetJobParts: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 parts, got %+v", got)
+	}
+	if got[0].Status != JobStatusDone || got[0].Output != "No issues found." || len(got[0].Paths) != 2 {
+		t.Errorf("unexpected first part %+v", got[0])
+	}
+	if got[1].Status != JobStatusFailed || got[1].Error != "agent crashed" {
+		t.Errorf("unexpected second part %+v", got[1])
+	}
+
+	// Parts are listed through the join only
+	jobs, err := db.ListJobs(t.Context(), "", "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("expected only the join job listed, got %d jobs", len(jobs))
+	}
+
+	// A rerun fans out afresh
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "merged"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if err := db.ReenqueueJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if got, err := db.GetJobParts(job.ID); err != nil || len(got) != 0 {
+		t.Errorf("expected no parts after rerun, got %+v, %v", got, err)
+	}
+}
+
+func TestFanOutJobCancel(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/fanout-cancel")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "fan2").ID, "fan2")
+
+	if _, err := db.FanOutJob(t.Context(), job.ID, [][]string{{"a.go"}}); err == nil {
+		t.Fatal("expected fanning out a queued job to fail")
+	}
+
+	claimJob(t, db, "worker-1")
+	parts, err := db.FanOutJob(t.Context(), job.ID, [][]string{{"a.go"}, {"b.go"}})
+	if err != nil {
+		t.Fatalf("FanOutJob: %v", err)
+	}
+	if err := db.CancelJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("CancelJob: %v", err)
+	}
+	for _, id := range parts {
+		part, err := db.GetJobByID(t.Context(), id)
+		if err != nil {
+			t.Fatalf("GetJobByID: %v", err)
+		}
+		if part.Status != JobStatusCanceled {
+			t.Errorf("expected part %d canceled with its parent, got %s", id, part.Status)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fileRefPattern matches file references such as "internal/db.go",
+// "main.go:42" or "src/app.ts:10-20" in review output
+var fileRefPattern = regexp.MustCompile(`((?:[\w.-]+/)*[\w-][\w.-]*\.[A-Za-z][A-Za-z0-9]*)(?::(\d+)(?:-(\d+))?)?`)
+
+// sourceExtensions are checked even when a reference has no line number.
+// Other dotted words without one ("fmt.Errorf", "e.g.") are ignored.
+var sourceExtensions = map[string]bool{
+	"c": true, "cc": true, "cpp": true, "cs": true, "css": true, "go": true,
+	"h": true, "hpp": true, "html": true, "java": true, "js": true, "json": true,
+	"jsx": true, "kt": true, "md": true, "mod": true, "php": true, "py": true,
+	"rb": true, "rs": true, "scss": true, "sh": true, "sql": true, "swift": true,
+	"toml": true, "ts": true, "tsx": true, "vue": true, "yaml": true, "yml": true,
+}
+
+// FileRef is a file reference found in a finding
+type FileRef struct {
+	Path      string
+	StartLine int // 0 if no line was given
+	EndLine   int
+}
+
+// ExtractFileRefs finds the file references in a finding's text. Absolute
+// paths inside the repo are made relative; URLs and other absolute paths
+// are ignored.
+func ExtractFileRefs(text, repoPath string) []FileRef {
+	if repoPath != "" {
+		text = strings.ReplaceAll(text, filepath.ToSlash(repoPath)+"/", "")
+	}
+	var refs []FileRef
+	for _, m := range fileRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > 0 && strings.ContainsRune("/:@\\", rune(text[m[0]-1])) {
+			continue
+		}
+		ref := FileRef{Path: text[m[2]:m[3]]}
+		if m[4] >= 0 {
+			ref.StartLine, _ = strconv.Atoi(text[m[4]:m[5]])
+		}
+		if m[6] >= 0 {
+			ref.EndLine, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		ext := strings.ToLower(path.Ext(ref.Path)[1:])
+		if ref.StartLine == 0 && !sourceExtensions[ext] {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
@@ -0,0 +1,22 @@
+package storage
+
+import "testing"
+
+func TestExtractFileRefs(t *testing.T) {
+	text := "- **High**: nil deref in internal/db.go:42 and main.go:10-20, " +
+		"see fmt.Errorf, e.g. https://example.com/x/y.go and /repo/cmd/run.go:7"
+	refs := ExtractFileRefs(text, "/repo")
+	want := []FileRef{
+		{Path: "internal/db.go", StartLine: 42},
+		{Path: "main.go", StartLine: 10, EndLine: 20},
+		{Path: "cmd/run.go", StartLine: 7},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got refs %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ref %d: got %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
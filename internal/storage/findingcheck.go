@@ -0,0 +1,62 @@
+package storage
+
+import "time"
+
+// AgentFindingAccuracy summarizes how often an agent's findings cite files
+// or lines that do not exist in the reviewed code
+type AgentFindingAccuracy struct {
+	Agent    string `json:"agent"`
+	Reviews  int    `json:"reviews"`
+	Findings int    `json:"findings"`
+	Invalid  int    `json:"invalid"`
+}
+
+// InvalidRate returns the fraction of findings that were invalid
+func (a AgentFindingAccuracy) InvalidRate() float64 {
+	if a.Findings == 0 {
+		return 0
+	}
+	return float64(a.Invalid) / float64(a.Findings)
+}
+
+// SaveFindingCheck records how many of a review's findings were checked
+// and how many cited files or lines that do not exist, replacing the result
+// of an earlier run of the same job.
+func (db *DB) SaveFindingCheck(jobID int64, agent string, findings, invalid int) error {
+	_, err := db.Exec(`
+		INSERT INTO finding_checks (job_id, agent, findings, invalid, checked_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			agent = excluded.agent,
+			findings = excluded.findings,
+			invalid = excluded.invalid,
+			checked_at = excluded.checked_at
+	`, jobID, agent, findings, invalid, time.Now().Format(time.RFC3339))
+	return err
+}
+
+// GetFindingAccuracy returns finding check totals per agent for reviews
+// checked since the given time, most reviews first
+func (db *DB) GetFindingAccuracy(since time.Time) ([]AgentFindingAccuracy, error) {
+	rows, err := db.Query(`
+		SELECT agent, COUNT(*), SUM(findings), SUM(invalid)
+		FROM finding_checks
+		WHERE datetime(checked_at) >= datetime(?)
+		GROUP BY agent
+		ORDER BY COUNT(*) DESC, agent
+	`, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []AgentFindingAccuracy
+	for rows.Next() {
+		var a AgentFindingAccuracy
+		if err := rows.Scan(&a.Agent, &a.Reviews, &a.Findings, &a.Invalid); err != nil {
+			return nil, err
+		}
+		stats = append(stats, a)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindingAccuracy(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/accuracy-repo")
+	var jobs []*ReviewJob
+	for _, sha := range []string{"acc1", "acc2", "acc3"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		jobs = append(jobs, enqueueJob(t, db, repo.ID, commit.ID, sha))
+	}
+
+	checks := []struct {
+		jobID             int64
+		agent             string
+		findings, invalid int
+	}{
+		{jobs[0].ID, "codex", 4, 1},
+		{jobs[1].ID, "codex", 2, 0},
+		{jobs[2].ID, "claude-code", 3, 3},
+		// A rerun replaces the earlier check
+		{jobs[2].ID, "claude-code", 5, 1},
+	}
+	for _, c := range checks {
+		if err := db.SaveFindingCheck(c.jobID, c.agent, c.findings, c.invalid); err != nil {
+			t.Fatalf("SaveFindingCheck: %v", err)
+		}
+	}
+
+	stats, err := db.GetFindingAccuracy(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetFindingAccuracy: %v", err)
+	}
+	want := []AgentFindingAccuracy{
+		{Agent: "codex", Reviews: 2, Findings: 6, Invalid: 1},
+		{Agent: "claude-code", Reviews: 1, Findings: 5, Invalid: 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("got %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+	if rate := stats[1].InvalidRate(); rate != 0.2 {
+		t.Errorf("InvalidRate() = %v, want 0.2", rate)
+	}
+
+	stats, err = db.GetFindingAccuracy(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetFindingAccuracy: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("expected no stats after the cutoff, got %+v", stats)
+	}
+
+	// Rerunning a job clears its check
+	claimed := claimJob(t, db, "w1")
+	if err := db.CompleteJob(t.Context(), claimed.ID, "codex", "p", "out"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ReenqueueJob(t.Context(), claimed.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM finding_checks WHERE job_id = ?`, claimed.ID).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("expected finding check removed on rerun, got %d", n)
+	}
+}
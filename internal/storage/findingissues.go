@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// severityRanks orders finding severities from least to most severe
+var severityRanks = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// SeverityAtLeast reports whether severity is min or more severe
+func SeverityAtLeast(severity, min string) bool {
+	rank, ok := severityRanks[severity]
+	return ok && rank >= severityRanks[min]
+}
+
+// FindingFingerprint identifies a finding across reviews: its text
+// lowercased, with numbers (line numbers shift between commits) and
+// punctuation dropped and whitespace collapsed
+func FindingFingerprint(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GetFindingIssueURL returns the issue filed for a finding with the given
+// fingerprint in the repo, or "" if none has been
+func (db *DB) GetFindingIssueURL(repoID int64, fingerprint string) (string, error) {
+	var url string
+	err := db.QueryRow(`
+		SELECT url FROM finding_issues WHERE repo_id = ? AND fingerprint = ?
+		ORDER BY created_at LIMIT 1
+	`, repoID, fingerprint).Scan(&url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return url, err
+}
+
+// SaveFindingIssue records the issue filed for a finding of a review
+func (db *DB) SaveFindingIssue(reviewID int64, findingIndex int, repoID int64, fingerprint, url string) error {
+	_, err := db.Exec(`
+		INSERT INTO finding_issues (review_id, finding_index, repo_id, fingerprint, url)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(review_id, finding_index) DO UPDATE SET url = excluded.url
+	`, reviewID, findingIndex, repoID, fingerprint, url)
+	return err
+}
+
+// findingIssueURLs returns the issue URLs recorded for findings, by review
+// and finding index
+func (db *DB) findingIssueURLs() (map[triageKey]string, error) {
+	rows, err := db.Query(`SELECT review_id, finding_index, url FROM finding_issues`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := make(map[triageKey]string)
+	for rows.Next() {
+		var key triageKey
+		var url string
+		if err := rows.Scan(&key.reviewID, &key.index, &url); err != nil {
+			return nil, err
+		}
+		urls[key] = url
+	}
+	return urls, rows.Err()
+}
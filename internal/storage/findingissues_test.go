@@ -0,0 +1,62 @@
+package storage
+
+import "testing"
+
+func TestFindingFingerprint(t *testing.T) {
+	a := FindingFingerprint("- **Critical**: SQL injection in db.go:42")
+	b := FindingFingerprint("* Critical:  SQL injection in db.go:57")
+	if a != b {
+		t.Errorf("fingerprints differ across line numbers and formatting: %s, %s", a, b)
+	}
+	if c := FindingFingerprint("- Critical: path traversal in db.go:42"); c == a {
+		t.Error("expected different findings to have different fingerprints")
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity, min string
+		want          bool
+	}{
+		{"critical", "critical", true},
+		{"high", "critical", false},
+		{"critical", "high", true},
+		{"low", "medium", false},
+		{"bogus", "low", false},
+	}
+	for _, tt := range tests {
+		if got := SeverityAtLeast(tt.severity, tt.min); got != tt.want {
+			t.Errorf("SeverityAtLeast(%q, %q) = %v, want %v", tt.severity, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestFindingIssues(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	job := completePeerJob(t, db, t.TempDir(), "abc123", "- Critical: SQL injection in db.go:42")
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := FindingFingerprint("- Critical: SQL injection in db.go:42")
+
+	if url, err := db.GetFindingIssueURL(job.RepoID, fp); err != nil || url != "" {
+		t.Fatalf("GetFindingIssueURL = %q, %v; want none", url, err)
+	}
+	if err := db.SaveFindingIssue(review.ID, 0, job.RepoID, fp, "https://github.com/o/r/issues/1"); err != nil {
+		t.Fatalf("SaveFindingIssue: %v", err)
+	}
+	if url, err := db.GetFindingIssueURL(job.RepoID, fp); err != nil || url != "https://github.com/o/r/issues/1" {
+		t.Errorf("GetFindingIssueURL = %q, %v", url, err)
+	}
+	if url, _ := db.GetFindingIssueURL(job.RepoID+1, fp); url != "" {
+		t.Errorf("expected issues to be per repo, got %q", url)
+	}
+
+	items, _, err := db.ListUntriagedFindings(0, 10)
+	if err != nil || len(items) != 1 || items[0].Finding.IssueURL != "https://github.com/o/r/issues/1" {
+		t.Errorf("ListUntriagedFindings = %+v, %v; want the issue URL on the finding", items, err)
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Repairs Fsck makes to a row with a dangling reference
+const (
+	FsckDelete = "delete" // The row means nothing without what it references
+	FsckUnlink = "unlink" // The reference is cleared and the row kept
+)
+
+// fsckUnlinkColumns are the references Fsck clears instead of deleting the
+// row: a job whose commit row is gone keeps its review, and its git_ref
+// still names the commit
+var fsckUnlinkColumns = map[string]bool{
+	"review_jobs.commit_id": true,
+}
+
+// fsckMaxPasses bounds the repair passes. Each pass repairs the rows left
+// dangling by the last one's deletes, such as the triage decisions of a
+// deleted review, so passes follow the depth of the schema's references.
+const fsckMaxPasses = 10
+
+// IntegrityProblem is a row referencing a row that does not exist, such as
+// a review of a deleted job or a job of a deleted repo
+type IntegrityProblem struct {
+	Table  string `json:"table"`
+	RowID  int64  `json:"rowid"`
+	Column string `json:"column"`
+	Parent string `json:"parent"` // Table the missing row belongs in
+	Ref    int64  `json:"ref"`    // ID of the missing row
+	Repair string `json:"repair"` // FsckDelete or FsckUnlink
+}
+
+// Fsck checks every foreign key in the database and returns the rows whose
+// reference dangles, with the repair for each. With repair, the repairs are
+// made; rows left dangling by a delete are repaired in turn and returned
+// too. Without it nothing changes, but the problems returned are the same.
+//
+// Foreign keys are enforced on every connection, so new problems come only
+// from databases written before they were.
+func (db *DB) Fsck(ctx context.Context, repair bool) ([]IntegrityProblem, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Deletes run with foreign keys off, so a dangling row referenced by
+	// others is deleted and they show up as dangling in the next pass
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return nil, fmt.Errorf("disable foreign keys: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = ON`)
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+	}()
+
+	var all []IntegrityProblem
+	for pass := 0; ; pass++ {
+		problems, err := checkForeignKeys(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(problems) == 0 {
+			break
+		}
+		if pass == fsckMaxPasses {
+			return nil, fmt.Errorf("%d problems left after %d repair passes", len(problems), pass)
+		}
+		for _, p := range problems {
+			if err := repairProblem(ctx, conn, p); err != nil {
+				return nil, fmt.Errorf("repair %s row %d: %w", p.Table, p.RowID, err)
+			}
+		}
+		all = append(all, problems...)
+	}
+	if !repair || len(all) == 0 {
+		return all, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, err
+	}
+	committed = true
+	return all, nil
+}
+
+// checkForeignKeys lists the dangling references PRAGMA foreign_key_check
+// reports. A row with any reference it cannot do without is deleted, so
+// all its problems are repaired that way.
+func checkForeignKeys(ctx context.Context, conn *sql.Conn) ([]IntegrityProblem, error) {
+	rows, err := conn.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, fmt.Errorf("foreign key check: %w", err)
+	}
+	type violation struct {
+		table, parent string
+		rowID, fkID   int64
+	}
+	var violations []violation
+	for rows.Next() {
+		var v violation
+		var rowID sql.NullInt64
+		if err := rows.Scan(&v.table, &rowID, &v.parent, &v.fkID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		v.rowID = rowID.Int64
+		violations = append(violations, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	problems := make([]IntegrityProblem, 0, len(violations))
+	deleted := make(map[string]bool)
+	for _, v := range violations {
+		p := IntegrityProblem{Table: v.table, RowID: v.rowID, Parent: v.parent, Repair: FsckDelete}
+		err := conn.QueryRowContext(ctx, `
+			SELECT "from" FROM pragma_foreign_key_list(?) WHERE id = ?
+		`, v.table, v.fkID).Scan(&p.Column)
+		if err != nil {
+			return nil, fmt.Errorf("foreign key %d of %s: %w", v.fkID, v.table, err)
+		}
+		err = conn.QueryRowContext(ctx, `SELECT "`+p.Column+`" FROM `+v.table+` WHERE rowid = ?`, v.rowID).Scan(&p.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("read %s row %d: %w", v.table, v.rowID, err)
+		}
+		if fsckUnlinkColumns[p.Table+"."+p.Column] {
+			p.Repair = FsckUnlink
+		} else {
+			deleted[fmt.Sprintf("%s/%d", p.Table, p.RowID)] = true
+		}
+		problems = append(problems, p)
+	}
+	for i, p := range problems {
+		if deleted[fmt.Sprintf("%s/%d", p.Table, p.RowID)] {
+			problems[i].Repair = FsckDelete
+		}
+	}
+	return problems, nil
+}
+
+// repairProblem deletes the dangling row or clears its reference
+func repairProblem(ctx context.Context, conn *sql.Conn, p IntegrityProblem) error {
+	var err error
+	if p.Repair == FsckUnlink {
+		_, err = conn.ExecContext(ctx, `UPDATE `+p.Table+` SET "`+p.Column+`" = NULL WHERE rowid = ?`, p.RowID)
+	} else {
+		_, err = conn.ExecContext(ctx, `DELETE FROM `+p.Table+` WHERE rowid = ?`, p.RowID)
+	}
+	return err
+}
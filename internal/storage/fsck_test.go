@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestFsck(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/fsck-repo")
+	kept := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "kept-sha").ID, "kept-sha")
+	claimJob(t, db, "w1")
+	if err := db.CompleteJob(t.Context(), kept.ID, "codex", "prompt", "output"); err != nil {
+		t.Fatal(err)
+	}
+	gone := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "gone-sha").ID, "gone-sha")
+	claimJob(t, db, "w2")
+	if err := db.CompleteJob(t.Context(), gone.ID, "codex", "prompt", "output"); err != nil {
+		t.Fatal(err)
+	}
+	goneReview, err := db.GetReviewByJobID(t.Context(), gone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetFindingTriage(goneReview.ID, 0, TriageAccepted, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AddCommentToJob(t.Context(), gone.ID, "user", "comment"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Foreign keys are enforced, so orphans come only from databases
+	// written without them
+	if _, err := db.Exec(`DELETE FROM review_jobs WHERE id = ?`, gone.ID); err == nil {
+		t.Fatal("expected deleting a reviewed job to violate a foreign key")
+	}
+	conn, err := db.Conn(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`PRAGMA foreign_keys = OFF`,
+		`DELETE FROM review_jobs WHERE git_ref = 'gone-sha'`,
+		`DELETE FROM commits WHERE sha = 'kept-sha'`,
+		`PRAGMA foreign_keys = ON`,
+	} {
+		if _, err := conn.ExecContext(t.Context(), stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Close()
+
+	problems, err := db.Fsck(t.Context(), false)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	want := map[string]string{
+		"review_jobs.commit_id":    FsckUnlink,
+		"reviews.job_id":           FsckDelete,
+		"responses.job_id":         FsckDelete,
+		"finding_triage.review_id": FsckDelete,
+	}
+	got := make(map[string]string)
+	for _, p := range problems {
+		got[p.Table+"."+p.Column] = p.Repair
+	}
+	if len(problems) != len(want) || len(got) != len(want) {
+		t.Fatalf("problems = %+v, want one each of %v", problems, want)
+	}
+	for k, repair := range want {
+		if got[k] != repair {
+			t.Errorf("%s repaired by %q, want %q", k, got[k], repair)
+		}
+	}
+
+	// Without repair nothing changes
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM reviews WHERE job_id = ?`, gone.ID).Scan(&n)
+	if n != 1 {
+		t.Error("check alone deleted the orphaned review")
+	}
+
+	repaired, err := db.Fsck(t.Context(), true)
+	if err != nil {
+		t.Fatalf("Fsck repair: %v", err)
+	}
+	if len(repaired) != len(problems) {
+		t.Errorf("repaired %d problems, found %d", len(repaired), len(problems))
+	}
+	if problems, err := db.Fsck(t.Context(), false); err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems after repair, got %+v, %v", problems, err)
+	}
+	job, err := db.GetJobByID(t.Context(), kept.ID)
+	if err != nil {
+		t.Fatalf("job with a missing commit was deleted: %v", err)
+	}
+	if job.CommitID != nil {
+		t.Errorf("commit_id = %v, want it cleared", *job.CommitID)
+	}
+	if _, err := db.GetReviewByJobID(t.Context(), kept.ID); err != nil {
+		t.Errorf("review of the unlinked job was lost: %v", err)
+	}
+}
+
+// Records kept after their job is pruned must not hold foreign keys, or
+// enforcing them would block the prune
+func TestRetainedRecordsHaveNoForeignKeys(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	for _, r := range retainedRecordRefs {
+		var sql string
+		if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE name = ?`, r.table).Scan(&sql); err != nil {
+			t.Fatal(err)
+		}
+		if r.ref.MatchString(sql) {
+			t.Errorf("%s still references the rows it outlives", r.table)
+		}
+	}
+
+	// An existing database is migrated
+	if _, err := db.Exec(`DROP TABLE ci_pr_reviews`); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.Exec(`
+		CREATE TABLE ci_pr_reviews (
+		  id INTEGER PRIMARY KEY AUTOINCREMENT,
+		  github_repo TEXT NOT NULL,
+		  pr_number INTEGER NOT NULL,
+		  head_sha TEXT NOT NULL,
+		  job_id INTEGER NOT NULL REFERENCES review_jobs(id),
+		  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		  UNIQUE(github_repo, pr_number, head_sha)
+		)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.migrateRetainedRecords(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO ci_pr_reviews (github_repo, pr_number, head_sha, job_id) VALUES ('o/r', 1, 'abc', 999)`); err != nil {
+		t.Errorf("expected a PR review record of a pruned job to be kept: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// FindGroupTail returns the held review that a commit whose parent is
+// parentSHA can join: a queued job on the same branch, for the same agent
+// and review type, ending at parentSHA. Returns nil if there is none.
+func (db *DB) FindGroupTail(repoID int64, branch, agent, reviewType, parentSHA string) (*ReviewJob, error) {
+	var id int64
+	err := db.QueryRow(`
+		SELECT id FROM review_jobs
+		WHERE repo_id = ? AND status = 'queued' AND hold_until IS NOT NULL
+		  AND COALESCE(branch, '') = ? AND agent = ? AND review_type = ?
+		  AND job_type IN ('review', 'range')
+		  AND (git_ref = ? OR git_ref LIKE '%..' || ?)
+		ORDER BY id DESC
+		LIMIT 1
+	`, repoID, branch, agent, reviewType, parentSHA, parentSHA).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db.GetJobByID(context.Background(), id)
+}
+
+// ExtendJobGroup turns a held job into a review of the range gitRef and
+// holds it until holdUntil for further commits. Returns sql.ErrNoRows if
+// the job was claimed or canceled in the meantime.
+func (db *DB) ExtendJobGroup(jobID int64, gitRef string, holdUntil time.Time) error {
+	result, err := db.Exec(`
+		UPDATE review_jobs
+		SET git_ref = ?, commit_id = NULL, job_type = 'range', hold_until = ?, updated_at = ?
+		WHERE id = ? AND status = 'queued'
+	`, gitRef, holdUntil.UTC().Format(time.RFC3339), time.Now().Format(time.RFC3339), jobID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
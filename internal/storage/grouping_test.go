@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCommitGrouping(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/grouping-repo")
+	commit := createCommit(t, db, repo.ID, "sha1")
+	held, err := db.EnqueueJob(t.Context(), EnqueueOpts{
+		RepoID: repo.ID, CommitID: commit.ID, GitRef: "sha1", Branch: "main",
+		Agent: "codex", ReviewType: "default", HoldUntil: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+
+	if job, err := db.ClaimJob(t.Context(), "worker-1"); err != nil || job != nil {
+		t.Fatalf("expected the held job to be passed over, got %+v, %v", job, err)
+	}
+
+	// Only a commit on the same branch, for the same agent, can join
+	if tail, err := db.FindGroupTail(repo.ID, "feature", "codex", "default", "sha1"); err != nil || tail != nil {
+		t.Errorf("expected no tail on another branch, got %+v, %v", tail, err)
+	}
+	tail, err := db.FindGroupTail(repo.ID, "main", "codex", "default", "sha1")
+	if err != nil || tail == nil || tail.ID != held.ID {
+		t.Fatalf("expected the held job as the tail, got %+v, %v", tail, err)
+	}
+
+	if err := db.ExtendJobGroup(held.ID, "base..sha2", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ExtendJobGroup: %v", err)
+	}
+	tail, err = db.FindGroupTail(repo.ID, "main", "codex", "default", "sha2")
+	if err != nil || tail == nil || tail.ID != held.ID {
+		t.Fatalf("expected the extended job as the tail, got %+v, %v", tail, err)
+	}
+	if tail.GitRef != "base..sha2" || tail.JobType != JobTypeRange || tail.CommitID != nil {
+		t.Errorf("expected a range job, got %+v", tail)
+	}
+
+	// Bumping releases the hold
+	if err := db.BumpJob(t.Context(), held.ID); err != nil {
+		t.Fatalf("BumpJob: %v", err)
+	}
+	if job := claimJob(t, db, "worker-1"); job.ID != held.ID {
+		t.Errorf("expected the released job to be claimed, got %d", job.ID)
+	}
+	if err := db.ExtendJobGroup(held.ID, "base..sha3", time.Now()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected a running job not to be extended, got %v", err)
+	}
+}
+
+func TestClaimJobAfterHoldExpires(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/grouping-repo")
+	commit := createCommit(t, db, repo.ID, "sha1")
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{
+		RepoID: repo.ID, CommitID: commit.ID, GitRef: "sha1", Agent: "codex",
+		HoldUntil: time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	if claimed := claimJob(t, db, "worker-1"); claimed.ID != job.ID {
+		t.Errorf("expected job %d, got %d", job.ID, claimed.ID)
+	}
+}
+
+func TestOldestClaimableJob(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	if n, _, _, err := db.OldestClaimableJob(now); err != nil || n != 0 {
+		t.Fatalf("empty queue: got %d, %v", n, err)
+	}
+
+	repo := createRepo(t, db, "/tmp/starved-repo")
+	var jobs []*ReviewJob
+	for i, hold := range []time.Time{{}, {}, now.Add(time.Hour)} {
+		sha := fmt.Sprintf("sha%d", i)
+		commit := createCommit(t, db, repo.ID, sha)
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex", HoldUntil: hold})
+		if err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	// The first job has been claimed and the last is held for grouping
+	if _, err := db.Exec(`UPDATE review_jobs SET status = 'running' WHERE id = ?`, jobs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	n, jobID, enqueuedAt, err := db.OldestClaimableJob(now)
+	if err != nil {
+		t.Fatalf("OldestClaimableJob: %v", err)
+	}
+	if n != 1 || jobID != jobs[1].ID || enqueuedAt.IsZero() {
+		t.Errorf("got %d, job %d at %v; want 1, job %d", n, jobID, enqueuedAt, jobs[1].ID)
+	}
+
+	// Once the hold expires both queued jobs are claimable
+	if n, jobID, _, err := db.OldestClaimableJob(now.Add(2 * time.Hour)); err != nil || n != 2 || jobID != jobs[1].ID {
+		t.Errorf("after hold: got %d, job %d, %v; want 2, job %d", n, jobID, err, jobs[1].ID)
+	}
+}
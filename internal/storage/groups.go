@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of job groups
+const (
+	JobGroupReview  = "review"  // Reviews enqueued with 'roborev review --group'
+	JobGroupAnalyze = "analyze" // Jobs of a per-file analysis
+	JobGroupFanOut  = "fanout"  // A fanned-out review: the join job and its parts
+)
+
+// JobGroup is a named set of jobs enqueued together, such as the commits of
+// a backfill or the parts of a fanned-out review, tracked and canceled as
+// one. A job belongs to at most one group.
+type JobGroup struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Progress of the group's jobs
+	Total    int               `json:"total"`
+	ByStatus map[JobStatus]int `json:"by_status"`
+	Passed   int               `json:"passed"` // Done jobs whose review passed
+	Failed   int               `json:"failed"` // Done jobs whose review found issues
+}
+
+// Finished returns the number of the group's jobs that will not run again.
+// Blocked jobs are unfinished: they run once what blocks them is fixed.
+func (g *JobGroup) Finished() int {
+	return g.Total - g.ByStatus[JobStatusQueued] - g.ByStatus[JobStatusRunning] - g.ByStatus[JobStatusBlocked]
+}
+
+// GetOrCreateJobGroup returns the group with a name, creating it with kind
+// if there is none
+func (db *DB) GetOrCreateJobGroup(name, kind string) (*JobGroup, error) {
+	if name == "" {
+		return nil, errors.New("job group name is empty")
+	}
+	if _, err := db.Exec(`INSERT OR IGNORE INTO job_groups (name, kind) VALUES (?, ?)`, name, kind); err != nil {
+		return nil, err
+	}
+	return db.FindJobGroup(name)
+}
+
+// AddJobToGroup puts a job in a group, moving it out of any other
+func (db *DB) AddJobToGroup(groupID, jobID int64) error {
+	_, err := db.Exec(`INSERT OR REPLACE INTO job_group_jobs (job_id, group_id) VALUES (?, ?)`, jobID, groupID)
+	return err
+}
+
+// GetJobGroup returns a group with its progress. Returns sql.ErrNoRows for
+// an unknown ID.
+func (db *DB) GetJobGroup(id int64) (*JobGroup, error) {
+	return db.getJobGroup(`id = ?`, id)
+}
+
+// FindJobGroup returns the group with a name, with its progress. Returns
+// sql.ErrNoRows when there is none.
+func (db *DB) FindJobGroup(name string) (*JobGroup, error) {
+	return db.getJobGroup(`name = ?`, name)
+}
+
+func (db *DB) getJobGroup(where string, arg any) (*JobGroup, error) {
+	var g JobGroup
+	var createdAt string
+	err := db.QueryRow(`SELECT id, name, kind, created_at FROM job_groups WHERE `+where, arg).
+		Scan(&g.ID, &g.Name, &g.Kind, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	g.CreatedAt = parseSQLiteTime(createdAt)
+	if err := db.loadGroupProgress(&g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// ListJobGroups returns the most recently created groups first, with their
+// progress. A beforeID above 0 starts the listing after that group.
+func (db *DB) ListJobGroups(limit int, beforeID int64) ([]JobGroup, error) {
+	query := `SELECT id, name, kind, created_at FROM job_groups`
+	var args []any
+	if beforeID > 0 {
+		query += ` WHERE id < ?`
+		args = append(args, beforeID)
+	}
+	rows, err := db.Query(query+` ORDER BY id DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	var groups []JobGroup
+	for rows.Next() {
+		var g JobGroup
+		var createdAt string
+		if err := rows.Scan(&g.ID, &g.Name, &g.Kind, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		g.CreatedAt = parseSQLiteTime(createdAt)
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if err := db.loadGroupProgress(&groups[i]); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// loadGroupProgress counts the jobs of a group by status and verdict
+func (db *DB) loadGroupProgress(g *JobGroup) error {
+	rows, err := db.Query(`
+		SELECT j.status, j.job_type, j.git_ref, j.commit_id IS NULL, rv.output
+		FROM job_group_jobs gj
+		JOIN review_jobs j ON j.id = gj.job_id
+		LEFT JOIN reviews rv ON rv.job_id = j.id
+		WHERE gj.group_id = ?
+	`, g.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	g.Total = 0
+	g.ByStatus = make(map[JobStatus]int)
+	g.Passed, g.Failed = 0, 0
+	for rows.Next() {
+		var j ReviewJob
+		var noCommit bool
+		var output sql.NullString
+		if err := rows.Scan(&j.Status, &j.JobType, &j.GitRef, &noCommit, &output); err != nil {
+			return err
+		}
+		g.Total++
+		g.ByStatus[j.Status]++
+		if !noCommit {
+			j.CommitID = new(int64)
+		}
+		// Task jobs have no verdict
+		if j.Status != JobStatusDone || !output.Valid || j.IsTaskJob() {
+			continue
+		}
+		if db.outputVerdict(output.String) == "P" {
+			g.Passed++
+		} else {
+			g.Failed++
+		}
+	}
+	return rows.Err()
+}
+
+// GroupJobIDs returns the IDs of a group's jobs with any of the given
+// statuses, or all of them when none are given
+func (db *DB) GroupJobIDs(groupID int64, statuses ...JobStatus) ([]int64, error) {
+	query := `SELECT gj.job_id FROM job_group_jobs gj JOIN review_jobs j ON j.id = gj.job_id WHERE gj.group_id = ?`
+	args := []any{groupID}
+	if len(statuses) > 0 {
+		placeholders := make([]string, len(statuses))
+		for i, s := range statuses {
+			placeholders[i] = "?"
+			args = append(args, s)
+		}
+		query += ` AND j.status IN (` + strings.Join(placeholders, ",") + `)`
+	}
+	query += ` ORDER BY gj.job_id`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan group job: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
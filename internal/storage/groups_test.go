@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestJobGroupProgress(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/group-repo")
+	group, err := db.GetOrCreateJobGroup("backfill", JobGroupReview)
+	if err != nil {
+		t.Fatalf("GetOrCreateJobGroup: %v", err)
+	}
+	if again, err := db.GetOrCreateJobGroup("backfill", JobGroupAnalyze); err != nil || again.ID != group.ID || again.Kind != JobGroupReview {
+		t.Fatalf("expected the existing group, got %+v, %v", again, err)
+	}
+
+	var jobs []int64
+	for _, sha := range []string{"g1", "g2", "g3", "g4"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		job := enqueueJob(t, db, repo.ID, commit.ID, sha)
+		if err := db.AddJobToGroup(group.ID, job.ID); err != nil {
+			t.Fatalf("AddJobToGroup: %v", err)
+		}
+		jobs = append(jobs, job.ID)
+	}
+	// A job outside the group
+	enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "g5").ID, "g5")
+
+	for _, output := range []string{"No issues found.", "- High: nil dereference"} {
+		job := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+	}
+	running := claimJob(t, db, "worker-1")
+
+	got, err := db.GetJobGroup(group.ID)
+	if err != nil {
+		t.Fatalf("GetJobGroup: %v", err)
+	}
+	if got.Name != "backfill" || got.Total != 4 || got.Passed != 1 || got.Failed != 1 {
+		t.Errorf("unexpected group %+v", got)
+	}
+	if got.ByStatus[JobStatusDone] != 2 || got.ByStatus[JobStatusRunning] != 1 || got.ByStatus[JobStatusQueued] != 1 {
+		t.Errorf("unexpected counts by status %v", got.ByStatus)
+	}
+	if got.Finished() != 2 {
+		t.Errorf("Finished() = %d, want 2", got.Finished())
+	}
+
+	unfinished, err := db.GroupJobIDs(group.ID, JobStatusQueued, JobStatusRunning)
+	if err != nil {
+		t.Fatalf("GroupJobIDs: %v", err)
+	}
+	if !slices.Equal(unfinished, []int64{running.ID, jobs[3]}) {
+		t.Errorf("unfinished jobs = %v, want %v", unfinished, []int64{running.ID, jobs[3]})
+	}
+	if all, _ := db.GroupJobIDs(group.ID); !slices.Equal(all, jobs) {
+		t.Errorf("group jobs = %v, want %v", all, jobs)
+	}
+
+	if _, err := db.FindJobGroup("missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for an unknown group, got %v", err)
+	}
+	groups, err := db.ListJobGroups(10, 0)
+	if err != nil || len(groups) != 1 || groups[0].Total != 4 {
+		t.Errorf("unexpected groups %+v, %v", groups, err)
+	}
+	if groups, err := db.ListJobGroups(10, group.ID); err != nil || len(groups) != 0 {
+		t.Errorf("expected no groups before the first, got %+v, %v", groups, err)
+	}
+}
+
+func TestFanOutJobGroup(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/fanout-group-repo")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "fg1").ID, "fg1")
+	grouped := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "fg2").ID, "fg2")
+	branch, err := db.GetOrCreateJobGroup("branch", JobGroupReview)
+	if err != nil {
+		t.Fatalf("GetOrCreateJobGroup: %v", err)
+	}
+	if err := db.AddJobToGroup(branch.ID, grouped.ID); err != nil {
+		t.Fatalf("AddJobToGroup: %v", err)
+	}
+	claimJob(t, db, "worker-1")
+	claimJob(t, db, "worker-2")
+
+	if _, err := db.FanOutJob(t.Context(), job.ID, [][]string{{"a.go"}, {"b.go"}}); err != nil {
+		t.Fatalf("FanOutJob: %v", err)
+	}
+	group, err := db.FindJobGroup(fmt.Sprintf("fanout-%d", job.ID))
+	if err != nil {
+		t.Fatalf("FindJobGroup: %v", err)
+	}
+	if group.Kind != JobGroupFanOut || group.Total != 3 {
+		t.Errorf("unexpected group %+v", group)
+	}
+
+	// Parts of a job already in a group join it
+	parts, err := db.FanOutJob(t.Context(), grouped.ID, [][]string{{"c.go"}})
+	if err != nil {
+		t.Fatalf("FanOutJob: %v", err)
+	}
+	ids, _ := db.GroupJobIDs(branch.ID)
+	if want := []int64{grouped.ID, parts[0]}; !slices.Equal(ids, want) {
+		t.Errorf("branch group jobs = %v, want %v", ids, want)
+	}
+	if _, err := db.FindJobGroup(fmt.Sprintf("fanout-%d", grouped.ID)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no group of its own for a grouped job, got %v", err)
+	}
+}
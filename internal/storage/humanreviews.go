@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AgentHuman is the agent recorded on reviews imported from code review
+// tools, so human reviews show up next to agent ones in lists, search and
+// stats
+const AgentHuman = "human"
+
+// States of an imported human review
+const (
+	HumanApproved         = "approved"
+	HumanChangesRequested = "changes_requested"
+	HumanCommented        = "commented"
+)
+
+// HumanReview is a review a person left in a code review tool, such as a
+// GitHub pull request review or a Gerrit vote with its comments
+type HumanReview struct {
+	Source      string // "github" or "gerrit"
+	ExternalID  string // ID of the review in the source, unique within it
+	URL         string
+	CommitSHA   string // Commit the review was left on
+	Subject     string // Subject and author of the commit, if known
+	Author      string
+	Reviewer    string
+	State       string // HumanApproved, HumanChangesRequested or HumanCommented
+	Body        string
+	SubmittedAt time.Time
+	Comments    []HumanComment // Comments on lines, recorded as findings
+	Replies     []HumanComment // Discussion of the review, recorded as comments
+}
+
+// HumanComment is a comment on a human review
+type HumanComment struct {
+	ExternalID string
+	Author     string
+	Path       string
+	Line       int
+	Body       string
+	CreatedAt  time.Time
+}
+
+// humanCommentKey is the imported key of a reply, kept apart from the keys
+// of reviews from the same source
+func humanCommentKey(id string) string {
+	return "comment:" + id
+}
+
+// FormatHumanReview renders a human review as review output: the review
+// body, then each line comment as a finding, led by a severity label so it
+// is counted, triaged and searched like an agent's finding. Comments of
+// reviews requesting changes are medium severity, others low.
+func FormatHumanReview(r HumanReview) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Human review by %s (%s)", r.Reviewer, strings.ReplaceAll(r.State, "_", " "))
+	if r.URL != "" {
+		fmt.Fprintf(&sb, ": %s", r.URL)
+	}
+	sb.WriteString("\n\n")
+	if body := strings.TrimSpace(r.Body); body != "" {
+		sb.WriteString(body + "\n\n")
+	}
+
+	if len(r.Comments) == 0 {
+		if r.State == HumanApproved {
+			sb.WriteString("No issues found.\n")
+		}
+		return sb.String()
+	}
+	severity := "Low"
+	if r.State == HumanChangesRequested {
+		severity = "Medium"
+	}
+	sb.WriteString("## Findings\n\n")
+	for _, c := range r.Comments {
+		loc := c.Path
+		if c.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", c.Path, c.Line)
+		}
+		// A blank line ends a finding, so paragraphs are joined
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+			if line = strings.TrimRight(line, " \t\r"); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		fmt.Fprintf(&sb, "- %s — %s: %s\n\n", severity, loc, strings.Join(lines, "\n  "))
+	}
+	return sb.String()
+}
+
+// ImportHumanReview records a human review of a repo's commit as a completed
+// job by AgentHuman, with its line comments as findings and its replies as
+// comments. Importing a review again only adds replies not imported
+// before. Returns the job and whether the review itself was new.
+func (db *DB) ImportHumanReview(repoID int64, r HumanReview) (int64, bool, error) {
+	if r.Source == "" || r.ExternalID == "" || r.CommitSHA == "" {
+		return 0, false, fmt.Errorf("human review needs a source, ID and commit")
+	}
+	machineID, err := db.GetMachineID()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var jobID int64
+	err = db.QueryRow(`SELECT job_id FROM human_review_imports WHERE source = ? AND external_id = ?`,
+		r.Source, r.ExternalID).Scan(&jobID)
+	isNew := errors.Is(err, sql.ErrNoRows)
+	if err != nil && !isNew {
+		return 0, false, err
+	}
+
+	submitted := r.SubmittedAt
+	if submitted.IsZero() {
+		submitted = time.Now()
+	}
+	submittedStr := submitted.UTC().Format(time.RFC3339)
+
+	var commitID int64
+	if isNew {
+		commit, err := db.GetOrCreateCommit(context.Background(), repoID, r.CommitSHA, r.Author, r.Subject, submitted)
+		if err != nil {
+			return 0, false, fmt.Errorf("record commit %s: %w", r.CommitSHA, err)
+		}
+		commitID = commit.ID
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	if isNew {
+		res, err := tx.Exec(`
+			INSERT INTO review_jobs (repo_id, commit_id, git_ref, agent, reasoning, status, job_type, review_type,
+				enqueued_at, started_at, finished_at, updated_at, uuid, source_machine_id)
+			VALUES (?, ?, ?, ?, '', 'done', 'review', 'default', ?, ?, ?, ?, ?, ?)
+		`, repoID, commitID, r.CommitSHA, AgentHuman, submittedStr, submittedStr, submittedStr, submittedStr,
+			GenerateUUID(), machineID)
+		if err != nil {
+			return 0, false, fmt.Errorf("insert job: %w", err)
+		}
+		if jobID, err = res.LastInsertId(); err != nil {
+			return 0, false, err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO reviews (job_id, agent, prompt, output, created_at, uuid, updated_by_machine_id, updated_at)
+			VALUES (?, ?, '', ?, ?, ?, ?, ?)
+		`, jobID, AgentHuman, FormatHumanReview(r), submittedStr, GenerateUUID(), machineID, submittedStr); err != nil {
+			return 0, false, fmt.Errorf("insert review: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO human_review_imports (source, external_id, job_id) VALUES (?, ?, ?)`,
+			r.Source, r.ExternalID, jobID); err != nil {
+			return 0, false, err
+		}
+	}
+
+	for _, c := range r.Replies {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO human_review_imports (source, external_id, job_id) VALUES (?, ?, ?)`,
+			r.Source, humanCommentKey(c.ExternalID), jobID)
+		if err != nil {
+			return 0, false, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		created := c.CreatedAt
+		if created.IsZero() {
+			created = submitted
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO responses (job_id, responder, response, uuid, source_machine_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, jobID, c.Author, strings.TrimSpace(c.Body), GenerateUUID(), machineID, created.UTC().Format(time.RFC3339)); err != nil {
+			return 0, false, fmt.Errorf("insert reply: %w", err)
+		}
+	}
+	return jobID, isNew, tx.Commit()
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportHumanReview(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := createRepo(t, db, "/tmp/human-repo")
+
+	r := HumanReview{
+		Source: "github", ExternalID: "1", CommitSHA: "abc123", Subject: "Add parser", Author: "dev",
+		Reviewer: "alice", State: HumanChangesRequested, Body: "Needs work",
+		SubmittedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		Comments:    []HumanComment{{ExternalID: "10", Path: "main.go", Line: 12, Body: "Leaks the file handle\n\nClose it"}},
+		Replies:     []HumanComment{{ExternalID: "11", Author: "bob", Body: "Fixed"}},
+	}
+	jobID, isNew, err := db.ImportHumanReview(repo.ID, r)
+	if err != nil || !isNew {
+		t.Fatalf("ImportHumanReview = %d, %v, %v", jobID, isNew, err)
+	}
+
+	job, err := db.GetJobByID(t.Context(), jobID)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Agent != AgentHuman || job.Status != JobStatusDone || job.GitRef != "abc123" || job.CommitSubject != "Add parser" {
+		t.Errorf("unexpected job %+v", job)
+	}
+	review, err := db.GetReviewByJobID(t.Context(), jobID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	findings := ExtractFindings(review.Output)
+	if len(findings) != 1 || findings[0].Severity != "medium" || !strings.Contains(findings[0].Text, "main.go:12") || !strings.Contains(findings[0].Text, "Close it") {
+		t.Errorf("expected the line comment as one finding, got %+v in %q", findings, review.Output)
+	}
+	if ParseVerdict(review.Output) != "F" {
+		t.Error("expected a failing verdict for requested changes")
+	}
+
+	// Importing again adds only new replies
+	r.Replies = append(r.Replies, HumanComment{ExternalID: "12", Author: "alice", Body: "Thanks"})
+	again, isNew, err := db.ImportHumanReview(repo.ID, r)
+	if err != nil || isNew || again != jobID {
+		t.Fatalf("reimport = %d, %v, %v", again, isNew, err)
+	}
+	comments, err := db.GetCommentsForJob(t.Context(), jobID)
+	if err != nil || len(comments) != 2 {
+		t.Errorf("expected two replies as comments, got %+v, %v", comments, err)
+	}
+
+	// An approval without comments passes
+	jobID, _, err = db.ImportHumanReview(repo.ID, HumanReview{
+		Source: "github", ExternalID: "2", CommitSHA: "abc123", Reviewer: "carol", State: HumanApproved,
+	})
+	if err != nil {
+		t.Fatalf("ImportHumanReview: %v", err)
+	}
+	if review, _ := db.GetReviewByJobID(t.Context(), jobID); ParseVerdict(review.Output) != "P" {
+		t.Errorf("expected a passing verdict, got output %q", review.Output)
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"strings"
+	"time"
+)
+
+// InjectionRisk flags a review whose diff contained text that looked like
+// an attempt to instruct the reviewing agent
+type InjectionRisk struct {
+	JobID      int64     `json:"job_id"`
+	Patterns   []string  `json:"patterns"`              // Names of the patterns that matched
+	OutputNote string    `json:"output_note,omitempty"` // Why the review itself looks affected, if it does
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SaveInjectionRisk records the injection risk of a job, replacing the one
+// recorded by an earlier run of the same job.
+func (db *DB) SaveInjectionRisk(risk InjectionRisk) error {
+	if risk.DetectedAt.IsZero() {
+		risk.DetectedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO injection_risks (job_id, patterns, output_note, detected_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			patterns = excluded.patterns,
+			output_note = excluded.output_note,
+			detected_at = excluded.detected_at
+	`, risk.JobID, strings.Join(risk.Patterns, ","), risk.OutputNote, risk.DetectedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// GetInjectionRisk returns the injection risk recorded for a job. Returns
+// sql.ErrNoRows if none was.
+func (db *DB) GetInjectionRisk(jobID int64) (*InjectionRisk, error) {
+	risk := InjectionRisk{JobID: jobID}
+	var patterns, detectedAt string
+	err := db.QueryRow(`
+		SELECT patterns, output_note, detected_at FROM injection_risks WHERE job_id = ?
+	`, jobID).Scan(&patterns, &risk.OutputNote, &detectedAt)
+	if err != nil {
+		return nil, err
+	}
+	if patterns != "" {
+		risk.Patterns = strings.Split(patterns, ",")
+	}
+	risk.DetectedAt = parseSQLiteTime(detectedAt)
+	return &risk, nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestInjectionRisk(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/injection-repo")
+	commit := createCommit(t, db, repo.ID, "injsha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "injsha")
+
+	if _, err := db.GetInjectionRisk(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before detection, got %v", err)
+	}
+
+	risk := InjectionRisk{JobID: job.ID, Patterns: []string{"ignore-instructions"}}
+	if err := db.SaveInjectionRisk(risk); err != nil {
+		t.Fatalf("SaveInjectionRisk: %v", err)
+	}
+
+	// A rerun replaces the earlier flag
+	risk.Patterns = []string{"ignore-instructions", "verdict-steering"}
+	risk.OutputNote = "review passed without mentioning it"
+	if err := db.SaveInjectionRisk(risk); err != nil {
+		t.Fatalf("SaveInjectionRisk: %v", err)
+	}
+
+	got, err := db.GetInjectionRisk(job.ID)
+	if err != nil {
+		t.Fatalf("GetInjectionRisk: %v", err)
+	}
+	if !slices.Equal(got.Patterns, risk.Patterns) || got.OutputNote != risk.OutputNote || got.DetectedAt.IsZero() {
+		t.Errorf("unexpected risk: %+v", got)
+	}
+
+	if err := db.DeleteRepo(t.Context(), repo.ID, true); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	if _, err := db.GetInjectionRisk(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected risk to be deleted with the repo, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// JobEnv records the environment a job ran in, so differences in review
+// quality can be attributed to tool or template changes.
+type JobEnv struct {
+	JobID          int64     `json:"job_id"`
+	RoborevVersion string    `json:"roborev_version"`
+	Agent          string    `json:"agent"`
+	AgentVersion   string    `json:"agent_version,omitempty"` // First line of the agent CLI's --version output
+	Model          string    `json:"model,omitempty"`         // Empty when the agent's default model was used
+	OS             string    `json:"os"`                      // GOOS/GOARCH of the daemon
+	PromptHash     string    `json:"prompt_hash,omitempty"`   // Hash of the system prompt template
+	CapturedAt     time.Time `json:"captured_at"`
+}
+
+// SaveJobEnv stores the environment for a job, replacing the one captured
+// by an earlier run of the same job.
+func (db *DB) SaveJobEnv(env JobEnv) error {
+	if env.CapturedAt.IsZero() {
+		env.CapturedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO job_env (job_id, roborev_version, agent, agent_version, model, os, prompt_hash, captured_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			roborev_version = excluded.roborev_version,
+			agent = excluded.agent,
+			agent_version = excluded.agent_version,
+			model = excluded.model,
+			os = excluded.os,
+			prompt_hash = excluded.prompt_hash,
+			captured_at = excluded.captured_at
+	`, env.JobID, env.RoborevVersion, env.Agent, env.AgentVersion, env.Model, env.OS, env.PromptHash,
+		env.CapturedAt.Format(time.RFC3339))
+	return err
+}
+
+// GetJobEnv returns the environment captured for a job. Returns
+// sql.ErrNoRows for jobs that have not run since capture was added.
+func (db *DB) GetJobEnv(jobID int64) (*JobEnv, error) {
+	var env JobEnv
+	var agentVersion, model, promptHash sql.NullString
+	var capturedAt string
+	err := db.QueryRow(`
+		SELECT job_id, roborev_version, agent, agent_version, model, os, prompt_hash, captured_at
+		FROM job_env WHERE job_id = ?
+	`, jobID).Scan(&env.JobID, &env.RoborevVersion, &env.Agent, &agentVersion, &model, &env.OS,
+		&promptHash, &capturedAt)
+	if err != nil {
+		return nil, err
+	}
+	env.AgentVersion = agentVersion.String
+	env.Model = model.String
+	env.PromptHash = promptHash.String
+	env.CapturedAt = parseSQLiteTime(capturedAt)
+	return &env, nil
+}
+
+// JobUsage records the tokens a job's agent reported using. Only agents
+// that call model APIs directly report usage.
+type JobUsage struct {
+	JobID            int64     `json:"job_id"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CapturedAt       time.Time `json:"captured_at"`
+}
+
+// SaveJobUsage stores a job's token usage, replacing that of an earlier run
+func (db *DB) SaveJobUsage(u JobUsage) error {
+	if u.CapturedAt.IsZero() {
+		u.CapturedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO job_usage (job_id, prompt_tokens, completion_tokens, captured_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			prompt_tokens = excluded.prompt_tokens,
+			completion_tokens = excluded.completion_tokens,
+			captured_at = excluded.captured_at
+	`, u.JobID, u.PromptTokens, u.CompletionTokens, u.CapturedAt.Format(time.RFC3339))
+	return err
+}
+
+// GetJobUsage returns a job's token usage. Returns sql.ErrNoRows when its
+// agent reported none.
+func (db *DB) GetJobUsage(jobID int64) (*JobUsage, error) {
+	var u JobUsage
+	var capturedAt string
+	err := db.QueryRow(`
+		SELECT job_id, prompt_tokens, completion_tokens, captured_at FROM job_usage WHERE job_id = ?
+	`, jobID).Scan(&u.JobID, &u.PromptTokens, &u.CompletionTokens, &capturedAt)
+	if err != nil {
+		return nil, err
+	}
+	u.CapturedAt = parseSQLiteTime(capturedAt)
+	return &u, nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestJobEnv(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/env-repo")
+	commit := createCommit(t, db, repo.ID, "envsha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "envsha")
+
+	if _, err := db.GetJobEnv(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before capture, got %v", err)
+	}
+
+	env := JobEnv{
+		JobID:          job.ID,
+		RoborevVersion: "v1.2.3",
+		Agent:          "codex",
+		AgentVersion:   "codex-cli 0.40.0",
+		OS:             "linux/amd64",
+		PromptHash:     "abc123def456",
+	}
+	if err := db.SaveJobEnv(env); err != nil {
+		t.Fatalf("SaveJobEnv: %v", err)
+	}
+
+	// A rerun replaces the earlier environment
+	env.AgentVersion = "codex-cli 0.41.0"
+	env.Model = "o3"
+	if err := db.SaveJobEnv(env); err != nil {
+		t.Fatalf("SaveJobEnv: %v", err)
+	}
+
+	got, err := db.GetJobEnv(job.ID)
+	if err != nil {
+		t.Fatalf("GetJobEnv: %v", err)
+	}
+	if got.AgentVersion != "codex-cli 0.41.0" || got.Model != "o3" || got.RoborevVersion != "v1.2.3" ||
+		got.OS != "linux/amd64" || got.PromptHash != "abc123def456" || got.CapturedAt.IsZero() {
+		t.Errorf("unexpected env: %+v", got)
+	}
+}
+
+func TestJobUsage(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/usage-repo")
+	commit := createCommit(t, db, repo.ID, "usagesha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "usagesha")
+
+	if _, err := db.GetJobUsage(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before any usage, got %v", err)
+	}
+	for _, tokens := range []int{100, 250} {
+		if err := db.SaveJobUsage(JobUsage{JobID: job.ID, PromptTokens: tokens, CompletionTokens: 10}); err != nil {
+			t.Fatalf("SaveJobUsage: %v", err)
+		}
+	}
+	got, err := db.GetJobUsage(job.ID)
+	if err != nil {
+		t.Fatalf("GetJobUsage: %v", err)
+	}
+	if got.PromptTokens != 250 || got.CompletionTokens != 10 || got.CapturedAt.IsZero() {
+		t.Errorf("expected the rerun's usage, got %+v", got)
+	}
+
+	if err := db.DeleteRepo(t.Context(), repo.ID, true); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	if _, err := db.GetJobUsage(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected usage deleted with the repo, got %v", err)
+	}
+}
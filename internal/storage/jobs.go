@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -118,97 +119,116 @@ func stripFieldLabel(s string) string {
 // Requires separators to be followed by space to avoid "High-level overview".
 // Skips lines that appear to be part of a severity legend/rubric.
 func hasSeverityLabel(output string) bool {
-	lc := strings.ToLower(output)
-	severities := []string{"critical", "high", "medium", "low"}
-	lines := strings.Split(lc, "\n")
+	return hasSeverityLabelIn(output, []string{"critical", "high", "medium", "low"})
+}
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if len(trimmed) == 0 {
-			continue
+// HasCriticalFinding reports whether the review output contains at least one
+// finding labeled with critical severity, using the same label detection as
+// verdict parsing.
+func HasCriticalFinding(output string) bool {
+	return hasSeverityLabelIn(output, []string{"critical"})
+}
+
+// hasSeverityLabelIn implements hasSeverityLabel for a given set of
+// lowercase severity words.
+func hasSeverityLabelIn(output string, severities []string) bool {
+	lines := strings.Split(strings.ToLower(output), "\n")
+	for i := range lines {
+		if lineSeverity(lines, i, severities) != "" {
+			return true
 		}
+	}
+	return false
+}
 
-		// Check if line starts with bullet/number - if so, strip it
-		first := trimmed[0]
-		hasBullet := first == '-' || first == '*' || (first >= '0' && first <= '9') ||
-			strings.HasPrefix(trimmed, "•")
+// lineSeverity returns the severity word that labels lines[i] as a finding,
+// or "" if the line is not a finding. lines must be lowercase.
+func lineSeverity(lines []string, i int, severities []string) string {
+	trimmed := strings.TrimSpace(lines[i])
+	if len(trimmed) == 0 {
+		return ""
+	}
 
-		checkText := trimmed
-		if hasBullet {
-			// Strip leading bullets/asterisks/numbers
-			checkText = strings.TrimLeft(trimmed, "-*•0123456789.) ")
-			checkText = strings.TrimSpace(checkText)
-		}
+	// Check if line starts with bullet/number - if so, strip it
+	first := trimmed[0]
+	hasBullet := first == '-' || first == '*' || (first >= '0' && first <= '9') ||
+		strings.HasPrefix(trimmed, "•")
 
-		// Strip markdown formatting (bold, headers) before checking
-		checkText = stripMarkdown(checkText)
+	checkText := trimmed
+	if hasBullet {
+		// Strip leading bullets/asterisks/numbers
+		checkText = strings.TrimLeft(trimmed, "-*•0123456789.) ")
+		checkText = strings.TrimSpace(checkText)
+	}
 
-		// Check if text starts with a severity word
-		for _, sev := range severities {
-			if !strings.HasPrefix(checkText, sev) {
-				continue
-			}
+	// Strip markdown formatting (bold, headers) before checking
+	checkText = stripMarkdown(checkText)
 
-			// Check if followed by separator (dash, em-dash, colon, pipe)
-			rest := checkText[len(sev):]
-			rest = strings.TrimSpace(rest)
-			if len(rest) == 0 {
-				continue
-			}
+	// Check if text starts with a severity word
+	for _, sev := range severities {
+		if !strings.HasPrefix(checkText, sev) {
+			continue
+		}
 
-			// Check for valid separator
-			hasValidSep := false
-			// Check for em-dash or en-dash (these are unambiguous)
-			if strings.HasPrefix(rest, "—") || strings.HasPrefix(rest, "–") {
-				hasValidSep = true
-			}
-			// Check for colon or pipe (unambiguous separators)
-			if rest[0] == ':' || rest[0] == '|' {
-				hasValidSep = true
-			}
-			// For hyphen, require space after to avoid "High-level"
-			if rest[0] == '-' && len(rest) > 1 && rest[1] == ' ' {
-				hasValidSep = true
-			}
+		// Check if followed by separator (dash, em-dash, colon, pipe)
+		rest := checkText[len(sev):]
+		rest = strings.TrimSpace(rest)
+		if len(rest) == 0 {
+			continue
+		}
 
-			if !hasValidSep {
-				continue
-			}
+		// Check for valid separator
+		hasValidSep := false
+		// Check for em-dash or en-dash (these are unambiguous)
+		if strings.HasPrefix(rest, "—") || strings.HasPrefix(rest, "–") {
+			hasValidSep = true
+		}
+		// Check for colon or pipe (unambiguous separators)
+		if rest[0] == ':' || rest[0] == '|' {
+			hasValidSep = true
+		}
+		// For hyphen, require space after to avoid "High-level"
+		if rest[0] == '-' && len(rest) > 1 && rest[1] == ' ' {
+			hasValidSep = true
+		}
 
-			// Skip if this looks like a legend/rubric entry
-			// Check if previous non-empty line is a legend header
-			if isLegendEntry(lines, i) {
-				continue
-			}
+		if !hasValidSep {
+			continue
+		}
 
-			return true
+		// Skip if this looks like a legend/rubric entry
+		// Check if previous non-empty line is a legend header
+		if isLegendEntry(lines, i) {
+			continue
 		}
 
-		// Check for "severity: <level>" pattern (e.g., "**Severity**: High")
-		if strings.HasPrefix(checkText, "severity") {
-			rest := checkText[len("severity"):]
+		return sev
+	}
+
+	// Check for "severity: <level>" pattern (e.g., "**Severity**: High")
+	if strings.HasPrefix(checkText, "severity") {
+		rest := checkText[len("severity"):]
+		rest = strings.TrimSpace(rest)
+		hasSep := len(rest) > 0 && (rest[0] == ':' || rest[0] == '|' ||
+			strings.HasPrefix(rest, "—") || strings.HasPrefix(rest, "–"))
+		// Accept hyphen-minus when followed by space (mirrors the severity-word branch)
+		if !hasSep && len(rest) > 1 && rest[0] == '-' && rest[1] == ' ' {
+			hasSep = true
+		}
+		if hasSep {
+			// Skip separator and whitespace
+			rest = strings.TrimLeft(rest, ":-–—| ")
 			rest = strings.TrimSpace(rest)
-			hasSep := len(rest) > 0 && (rest[0] == ':' || rest[0] == '|' ||
-				strings.HasPrefix(rest, "—") || strings.HasPrefix(rest, "–"))
-			// Accept hyphen-minus when followed by space (mirrors the severity-word branch)
-			if !hasSep && len(rest) > 1 && rest[0] == '-' && rest[1] == ' ' {
-				hasSep = true
-			}
-			if hasSep {
-				// Skip separator and whitespace
-				rest = strings.TrimLeft(rest, ":-–—| ")
-				rest = strings.TrimSpace(rest)
-				for _, sev := range severities {
-					if strings.HasPrefix(rest, sev) {
-						if !isLegendEntry(lines, i) {
-							return true
-						}
+			for _, sev := range severities {
+				if strings.HasPrefix(rest, sev) {
+					if !isLegendEntry(lines, i) {
+						return sev
 					}
 				}
 			}
 		}
 	}
-	return false
+	return ""
 }
 
 // isLegendEntry checks if a line at index i appears to be part of a severity legend/rubric
@@ -736,23 +756,28 @@ type EnqueueOpts struct {
 	Agent        string
 	Model        string
 	Reasoning    string
-	ReviewType   string // e.g. "security" — changes which system prompt is used
-	DiffContent  string // For dirty reviews (captured at enqueue time)
-	Prompt       string // For task jobs (pre-stored prompt)
-	OutputPrefix string // Prefix to prepend to review output
-	Agentic      bool   // Allow file edits and command execution
-	Label        string // Display label in TUI for task jobs (default: "prompt")
+	ReviewType   string    // e.g. "security" — changes which system prompt is used
+	DiffContent  string    // For dirty reviews (captured at enqueue time)
+	Prompt       string    // For task jobs (pre-stored prompt)
+	OutputPrefix string    // Prefix to prepend to review output
+	Agentic      bool      // Allow file edits and command execution
+	Label        string    // Display label in TUI for task jobs (default: "prompt")
+	AgentPolicy  string    // Record of the policy decision that selected Agent (e.g. "rotation:weighted 80/100")
+	SkipReason   string    // Record the job as skipped with this reason instead of queueing it
+	HoldUntil    time.Time // Keep the job from being claimed until then (zero = claim at once)
+	Focus        bool      // Thorough review: premium agent, larger prompt budget, full file context and blame
+	Protected    bool      // The commit is on one of the repo's protected branches
+	Lane         string    // LaneInteractive for jobs someone is waiting on; default LaneBackground
+	Priority     int       // PriorityHigh, PriorityNormal (default) or PriorityLow
+
+	// Design docs for the review to check the change against, saved with the
+	// job so no worker claims it without them (DB only)
+	ContextDocs []ContextDoc
 }
 
-// EnqueueJob creates a new review job. The job type is inferred from opts.
-func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
-	reasoning := opts.Reasoning
-	if reasoning == "" {
-		reasoning = "thorough"
-	}
-
-	// Determine job type from fields
-	var jobType string
+// jobTypeAndRef infers the type of the job opts describe, and the git ref
+// it is recorded under: task jobs show their label instead of a ref
+func (opts EnqueueOpts) jobTypeAndRef() (jobType, gitRef string) {
 	switch {
 	case opts.Prompt != "":
 		jobType = JobTypeTask
@@ -764,8 +789,7 @@ func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
 		jobType = JobTypeRange
 	}
 
-	// For task jobs, use Label as git_ref display value
-	gitRef := opts.GitRef
+	gitRef = opts.GitRef
 	if jobType == JobTypeTask {
 		if opts.Label != "" {
 			gitRef = opts.Label
@@ -773,11 +797,47 @@ func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
 			gitRef = "prompt"
 		}
 	}
+	return jobType, gitRef
+}
+
+// Priority levels of queued jobs. Within a lane, jobs are claimed highest
+// priority first, then in enqueue order; BumpJob lifts a job above them all.
+const (
+	PriorityLow    = -1 // Backfilled history: commits behind their branch's head
+	PriorityNormal = 0
+	PriorityHigh   = 1 // The commit at the head of its branch
+)
+
+// EnqueueJobWithPriority is EnqueueJob with the job queued at priority
+func (db *DB) EnqueueJobWithPriority(ctx context.Context, opts EnqueueOpts, priority int) (*ReviewJob, error) {
+	opts.Priority = priority
+	return db.EnqueueJob(ctx, opts)
+}
+
+// EnqueueJob creates a new review job. The job type is inferred from opts.
+func (db *DB) EnqueueJob(ctx context.Context, opts EnqueueOpts) (*ReviewJob, error) {
+	reasoning := opts.Reasoning
+	if reasoning == "" {
+		reasoning = "thorough"
+	}
+	jobType, gitRef := opts.jobTypeAndRef()
 
 	agenticInt := 0
 	if opts.Agentic {
 		agenticInt = 1
 	}
+	focusInt := 0
+	if opts.Focus {
+		focusInt = 1
+	}
+	protectedInt := 0
+	if opts.Protected {
+		protectedInt = 1
+	}
+	lane := opts.Lane
+	if lane == "" {
+		lane = LaneBackground
+	}
 
 	uid := GenerateUUID()
 	machineID, _ := db.GetMachineID()
@@ -790,22 +850,54 @@ func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
 		commitIDParam = opts.CommitID
 	}
 
-	result, err := db.Exec(`
+	// Skipped jobs are finished on creation so no worker ever claims them
+	status := JobStatusQueued
+	var finishedAt *time.Time
+	var finishedAtParam interface{}
+	if opts.SkipReason != "" {
+		status = JobStatusSkipped
+		finishedAt = &now
+		finishedAtParam = nowStr
+	}
+
+	var holdUntilParam interface{}
+	if !opts.HoldUntil.IsZero() && status == JobStatusQueued {
+		holdUntilParam = opts.HoldUntil.UTC().Format(time.RFC3339)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO review_jobs (repo_id, commit_id, git_ref, branch, agent, model, reasoning,
 			status, job_type, review_type, diff_content, prompt, agentic, output_prefix,
-			uuid, source_machine_id, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 'queued', ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			uuid, source_machine_id, updated_at, agent_policy, error, finished_at, hold_until, focus,
+			protected_branch, lane, priority)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		opts.RepoID, commitIDParam, gitRef, nullString(opts.Branch),
 		opts.Agent, nullString(opts.Model), reasoning,
-		jobType, opts.ReviewType,
+		status, jobType, opts.ReviewType,
 		nullString(opts.DiffContent), nullString(opts.Prompt), agenticInt,
 		nullString(opts.OutputPrefix),
-		uid, machineID, nowStr)
+		uid, machineID, nowStr, nullString(opts.AgentPolicy),
+		nullString(opts.SkipReason), finishedAtParam, holdUntilParam, focusInt, protectedInt, lane, opts.Priority)
 	if err != nil {
 		return nil, err
 	}
 
 	id, _ := result.LastInsertId()
+	if err := insertContextDocs(tx, id, opts.ContextDocs); err != nil {
+		return nil, fmt.Errorf("save context docs: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if status == JobStatusQueued {
+		db.NotifyQueueChanged()
+	}
 	job := &ReviewJob{
 		ID:              id,
 		RepoID:          opts.RepoID,
@@ -816,11 +908,17 @@ func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
 		Reasoning:       reasoning,
 		JobType:         jobType,
 		ReviewType:      opts.ReviewType,
-		Status:          JobStatusQueued,
+		Status:          status,
 		EnqueuedAt:      now,
+		FinishedAt:      finishedAt,
+		Error:           opts.SkipReason,
 		Prompt:          opts.Prompt,
 		Agentic:         opts.Agentic,
 		OutputPrefix:    opts.OutputPrefix,
+		AgentPolicy:     opts.AgentPolicy,
+		Focus:           opts.Focus,
+		Protected:       opts.Protected,
+		Lane:            lane,
 		UUID:            uid,
 		SourceMachineID: machineID,
 		UpdatedAt:       &now,
@@ -834,23 +932,58 @@ func (db *DB) EnqueueJob(opts EnqueueOpts) (*ReviewJob, error) {
 	return job, nil
 }
 
-// ClaimJob atomically claims the next queued job for a worker
-func (db *DB) ClaimJob(workerID string) (*ReviewJob, error) {
+// claimableCondition selects the queued jobs of review_jobs q that workers
+// may claim: jobs that depend on queued or running jobs, or are held for
+// grouping, are passed over until those finish or the hold expires. Its one
+// argument is the current time.
+const claimableCondition = `q.status = 'queued'
+	AND NOT EXISTS (
+		SELECT 1 FROM job_deps d
+		JOIN review_jobs dep ON dep.id = d.depends_on
+		WHERE d.job_id = q.id AND dep.status IN ('queued', 'running')
+	)
+	AND (q.hold_until IS NULL OR datetime(q.hold_until) <= datetime(?))`
+
+// Lanes of the queue. Interactive jobs are ones someone is waiting on, such
+// as reviews enqueued with --wait; they are claimed before background jobs
+// and by the workers reserved for them.
+const (
+	LaneBackground  = "background"
+	LaneInteractive = "interactive"
+)
+
+// ClaimJob atomically claims the next queued job for a worker, interactive
+// jobs first, then by priority. Jobs that depend on queued or running jobs, or are held for
+// grouping, are passed over until those finish or the hold expires.
+func (db *DB) ClaimJob(ctx context.Context, workerID string) (*ReviewJob, error) {
+	return db.ClaimJobInLane(ctx, workerID, "")
+}
+
+// ClaimJobInLane is ClaimJob limited to one lane's jobs, or any lane's if
+// lane is ""
+func (db *DB) ClaimJobInLane(ctx context.Context, workerID, lane string) (*ReviewJob, error) {
 	now := time.Now()
 	nowStr := now.Format(time.RFC3339)
 
+	laneCondition := ""
+	args := []any{workerID, nowStr, nowStr, now.UTC().Format(time.RFC3339)}
+	if lane != "" {
+		laneCondition = " AND q.lane = ?"
+		args = append(args, lane)
+	}
+
 	// Atomically claim a job by updating it in a single statement
 	// This prevents race conditions where two workers select the same job
-	result, err := db.Exec(`
+	result, err := db.ExecContext(ctx, `
 		UPDATE review_jobs
 		SET status = 'running', worker_id = ?, started_at = ?, updated_at = ?
 		WHERE id = (
-			SELECT id FROM review_jobs
-			WHERE status = 'queued'
-			ORDER BY enqueued_at
+			SELECT q.id FROM review_jobs q
+			WHERE `+claimableCondition+laneCondition+`
+			ORDER BY q.lane = 'interactive' DESC, q.priority DESC, q.enqueued_at, q.id
 			LIMIT 1
 		)
-	`, workerID, nowStr, nowStr)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -875,17 +1008,20 @@ func (db *DB) ClaimJob(workerID string) (*ReviewJob, error) {
 	var agenticInt int
 	var jobType sql.NullString
 	var reviewType sql.NullString
-	err = db.QueryRow(`
+	err = db.QueryRowContext(ctx, `
 		SELECT j.id, j.repo_id, j.commit_id, j.git_ref, j.branch, j.agent, j.model, j.reasoning, j.status, j.enqueued_at,
-		       r.root_path, r.name, c.subject, j.diff_content, j.prompt, COALESCE(j.agentic, 0), j.job_type, j.review_type
+		       r.root_path, r.name, c.subject, j.diff_content, j.prompt, COALESCE(j.agentic, 0), j.job_type, j.review_type,
+		       COALESCE(jp.parent_id, 0), j.focus, j.protected_branch, j.lane, j.truncation_level
 		FROM review_jobs j
 		JOIN repos r ON r.id = j.repo_id
 		LEFT JOIN commits c ON c.id = j.commit_id
+		LEFT JOIN job_parts jp ON jp.job_id = j.id
 		WHERE j.worker_id = ? AND j.status = 'running'
 		ORDER BY j.started_at DESC
 		LIMIT 1
 	`, workerID).Scan(&job.ID, &job.RepoID, &commitID, &job.GitRef, &branch, &job.Agent, &model, &job.Reasoning, &job.Status, &enqueuedAt,
-		&job.RepoPath, &job.RepoName, &commitSubject, &diffContent, &prompt, &agenticInt, &jobType, &reviewType)
+		&job.RepoPath, &job.RepoName, &commitSubject, &diffContent, &prompt, &agenticInt, &jobType, &reviewType,
+		&job.ParentJobID, &job.Focus, &job.Protected, &job.Lane, &job.TruncationLevel)
 	if err != nil {
 		return nil, err
 	}
@@ -900,7 +1036,7 @@ func (db *DB) ClaimJob(workerID string) (*ReviewJob, error) {
 		job.DiffContent = &diffContent.String
 	}
 	if prompt.Valid {
-		job.Prompt = prompt.String
+		job.Prompt = db.loadBlob(prompt.String)
 	}
 	if model.Valid {
 		job.Model = model.String
@@ -922,25 +1058,67 @@ func (db *DB) ClaimJob(workerID string) (*ReviewJob, error) {
 	return &job, nil
 }
 
+// OldestClaimableJob returns the oldest queued job a worker could claim at
+// now, and how many claimable jobs there are. Returns a zero count when
+// nothing is claimable.
+func (db *DB) OldestClaimableJob(now time.Time) (count int, jobID int64, enqueuedAt time.Time, err error) {
+	var at string
+	err = db.QueryRow(`
+		SELECT q.id, q.enqueued_at, COUNT(*) OVER ()
+		FROM review_jobs q
+		WHERE `+claimableCondition+`
+		ORDER BY q.enqueued_at, q.id
+		LIMIT 1
+	`, now.UTC().Format(time.RFC3339)).Scan(&jobID, &at, &count)
+	if err == sql.ErrNoRows {
+		return 0, 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	return count, jobID, parseSQLiteTime(at), nil
+}
+
 // SaveJobPrompt stores the prompt for a running job
-func (db *DB) SaveJobPrompt(jobID int64, prompt string) error {
-	_, err := db.Exec(`UPDATE review_jobs SET prompt = ? WHERE id = ?`, prompt, jobID)
+func (db *DB) SaveJobPrompt(ctx context.Context, jobID int64, prompt string) error {
+	_, err := db.ExecContext(ctx, `UPDATE review_jobs SET prompt = ? WHERE id = ?`, prompt, jobID)
 	return err
 }
 
 // CompleteJob marks a job as done and stores the review.
 // Only updates if job is still in 'running' state (respects cancellation).
 // If the job has an output_prefix, it will be prepended to the output.
-func (db *DB) CompleteJob(jobID int64, agent, prompt, output string) error {
+func (db *DB) CompleteJob(ctx context.Context, jobID int64, agent, prompt, output string) error {
 	// Get machine ID and generate UUIDs before starting transaction
 	// to avoid potential lock conflicts with GetMachineID's writes
 	now := time.Now().Format(time.RFC3339)
 	machineID, _ := db.GetMachineID()
 	reviewUUID := GenerateUUID()
 
+	// Fetch output_prefix from job (if any). It is fixed at enqueue time, so
+	// it is read before the transaction along with any blob uploads.
+	var outputPrefix sql.NullString
+	err := db.QueryRowContext(ctx, `SELECT output_prefix FROM review_jobs WHERE id = ?`, jobID).Scan(&outputPrefix)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	// Prepend output_prefix if present
+	finalOutput := output
+	if outputPrefix.Valid && outputPrefix.String != "" {
+		finalOutput = outputPrefix.String + output
+	}
+
+	// Offload large values; the job's copy of the prompt shares the reference
+	storedPrompt := db.offloadBlob(prompt, "")
+	storedOutput := db.offloadBlob(finalOutput, ParseVerdict(finalOutput))
+	var jobPromptRef sql.NullString
+	if IsBlobRef(storedPrompt) {
+		jobPromptRef = sql.NullString{String: storedPrompt, Valid: true}
+	}
+
 	// Use BEGIN IMMEDIATE to acquire write lock upfront, avoiding deadlocks
 	// when concurrent goroutines (workers, sync) try to upgrade from read to write.
-	ctx := context.Background()
 	conn, err := db.Conn(ctx)
 	if err != nil {
 		return err
@@ -953,25 +1131,12 @@ func (db *DB) CompleteJob(jobID int64, agent, prompt, output string) error {
 	committed := false
 	defer func() {
 		if !committed {
-			conn.ExecContext(ctx, "ROLLBACK")
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
 		}
 	}()
 
-	// Fetch output_prefix from job (if any)
-	var outputPrefix sql.NullString
-	err = conn.QueryRowContext(ctx, `SELECT output_prefix FROM review_jobs WHERE id = ?`, jobID).Scan(&outputPrefix)
-	if err != nil && err != sql.ErrNoRows {
-		return err
-	}
-
-	// Prepend output_prefix if present
-	finalOutput := output
-	if outputPrefix.Valid && outputPrefix.String != "" {
-		finalOutput = outputPrefix.String + output
-	}
-
 	// Update job status only if still running (not canceled)
-	result, err := conn.ExecContext(ctx, `UPDATE review_jobs SET status = 'done', finished_at = ?, updated_at = ? WHERE id = ? AND status = 'running'`, now, now, jobID)
+	result, err := conn.ExecContext(ctx, `UPDATE review_jobs SET status = 'done', finished_at = ?, updated_at = ?, prompt = COALESCE(?, prompt) WHERE id = ? AND status = 'running'`, now, now, jobPromptRef, jobID)
 	if err != nil {
 		return err
 	}
@@ -988,7 +1153,7 @@ func (db *DB) CompleteJob(jobID int64, agent, prompt, output string) error {
 
 	// Insert review with sync columns
 	_, err = conn.ExecContext(ctx, `INSERT INTO reviews (job_id, agent, prompt, output, uuid, updated_by_machine_id, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		jobID, agent, prompt, finalOutput, reviewUUID, machineID, now)
+		jobID, agent, storedPrompt, storedOutput, reviewUUID, machineID, now)
 	if err != nil {
 		return err
 	}
@@ -1001,22 +1166,44 @@ func (db *DB) CompleteJob(jobID int64, agent, prompt, output string) error {
 	return nil
 }
 
-// FailJob marks a job as failed with an error message.
+// FailJob marks a job as failed with an error message, recording the error
+// code ClassifyError finds in it.
 // Only updates if job is still in 'running' state (respects cancellation).
-func (db *DB) FailJob(jobID int64, errorMsg string) error {
-	now := time.Now().Format(time.RFC3339)
-	_, err := db.Exec(`UPDATE review_jobs SET status = 'failed', finished_at = ?, error = ?, updated_at = ? WHERE id = ? AND status = 'running'`,
-		now, errorMsg, now, jobID)
-	return err
+func (db *DB) FailJob(ctx context.Context, jobID int64, errorMsg string) error {
+	return db.FailJobWithCode(ctx, jobID, ClassifyError(errorMsg), errorMsg)
+}
+
+// BumpJob moves a queued job to the front of the queue, ahead of jobs
+// bumped before it, and releases any grouping hold on it. Returns
+// sql.ErrNoRows if the job is not queued.
+func (db *DB) BumpJob(ctx context.Context, jobID int64) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE review_jobs
+		SET priority = (SELECT COALESCE(MAX(priority), 0) + 1 FROM review_jobs WHERE status = 'queued'),
+			hold_until = NULL, updated_at = ?
+		WHERE id = ? AND status = 'queued'
+	`, time.Now().Format(time.RFC3339), jobID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	db.NotifyQueueChanged()
+	return nil
 }
 
-// CancelJob marks a running or queued job as canceled
-func (db *DB) CancelJob(jobID int64) error {
+// CancelJob marks a running, queued or blocked job as canceled
+func (db *DB) CancelJob(ctx context.Context, jobID int64) error {
 	now := time.Now().Format(time.RFC3339)
-	result, err := db.Exec(`
+	result, err := db.ExecContext(ctx, `
 		UPDATE review_jobs
 		SET status = 'canceled', finished_at = ?, updated_at = ?
-		WHERE id = ? AND status IN ('queued', 'running')
+		WHERE id = ? AND status IN ('queued', 'running', 'blocked')
 	`, now, now, jobID)
 	if err != nil {
 		return err
@@ -1028,14 +1215,20 @@ func (db *DB) CancelJob(jobID int64) error {
 	if rows == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+
+	// Parts of a fanned-out review are canceled with it
+	_, err = db.ExecContext(ctx, `
+		UPDATE review_jobs
+		SET status = 'canceled', finished_at = ?, updated_at = ?
+		WHERE id IN (SELECT job_id FROM job_parts WHERE parent_id = ?) AND status IN ('queued', 'running')
+	`, now, now, jobID)
+	return err
 }
 
-// ReenqueueJob resets a completed, failed, or canceled job back to queued status.
+// ReenqueueJob resets a completed, failed, canceled, skipped or blocked job back to queued status.
 // This allows manual re-running of jobs to get a fresh review.
 // For done jobs, the existing review is deleted to avoid unique constraint violations.
-func (db *DB) ReenqueueJob(jobID int64) error {
-	ctx := context.Background()
+func (db *DB) ReenqueueJob(ctx context.Context, jobID int64) error {
 	conn, err := db.Conn(ctx)
 	if err != nil {
 		return err
@@ -1048,21 +1241,52 @@ func (db *DB) ReenqueueJob(jobID int64) error {
 	committed := false
 	defer func() {
 		if !committed {
-			conn.ExecContext(ctx, "ROLLBACK")
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
 		}
 	}()
 
-	// Delete any existing review for this job (for done jobs being rerun)
+	// Delete any existing review for this job (for done jobs being rerun),
+	// along with triage decisions, suppressions and checks of its findings
+	_, err = conn.ExecContext(ctx, `
+		DELETE FROM finding_triage WHERE review_id IN (SELECT id FROM reviews WHERE job_id = ?)
+	`, jobID)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `
+		DELETE FROM finding_suppressions WHERE review_id IN (SELECT id FROM reviews WHERE job_id = ?)
+	`, jobID)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `DELETE FROM finding_checks WHERE job_id = ?`, jobID)
+	if err != nil {
+		return err
+	}
 	_, err = conn.ExecContext(ctx, `DELETE FROM reviews WHERE job_id = ?`, jobID)
 	if err != nil {
 		return err
 	}
 
+	// A rerun of a fanned-out review fans out afresh instead of merging the
+	// old parts again
+	_, err = conn.ExecContext(ctx, `DELETE FROM job_deps WHERE job_id = ?`, jobID)
+	if err != nil {
+		return err
+	}
+
+	// A rerun starts its attempts afresh, and leaves the dead letters
+	for _, table := range []string{"job_failures", "dead_letters"} {
+		if _, err = conn.ExecContext(ctx, `DELETE FROM `+table+` WHERE job_id = ?`, jobID); err != nil {
+			return err
+		}
+	}
+
 	// Reset job status
 	result, err := conn.ExecContext(ctx, `
 		UPDATE review_jobs
-		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, retry_count = 0
-		WHERE id = ? AND status IN ('done', 'failed', 'canceled')
+		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, error_code = NULL, retry_count = 0
+		WHERE id = ? AND status IN ('done', 'failed', 'canceled', 'skipped', 'blocked')
 	`, jobID)
 	if err != nil {
 		return err
@@ -1080,18 +1304,19 @@ func (db *DB) ReenqueueJob(jobID int64) error {
 		return err
 	}
 	committed = true
+	db.NotifyQueueChanged()
 	return nil
 }
 
 // RetryJob atomically resets a running job to queued for retry.
 // Returns false if max retries reached or job is not in running state.
 // maxRetries is the number of retries allowed (e.g., 3 means up to 4 total attempts).
-func (db *DB) RetryJob(jobID int64, maxRetries int) (bool, error) {
+func (db *DB) RetryJob(ctx context.Context, jobID int64, maxRetries int) (bool, error) {
 	// Atomically update only if retry_count < maxRetries and status is running
 	// This prevents race conditions with multiple workers
-	result, err := db.Exec(`
+	result, err := db.ExecContext(ctx, `
 		UPDATE review_jobs
-		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, retry_count = retry_count + 1
+		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, error_code = NULL, retry_count = retry_count + 1
 		WHERE id = ? AND retry_count < ? AND status = 'running'
 	`, jobID, maxRetries)
 	if err != nil {
@@ -1103,13 +1328,40 @@ func (db *DB) RetryJob(jobID int64, maxRetries int) (bool, error) {
 		return false, err
 	}
 
-	return rows > 0, nil
+	if rows == 0 {
+		return false, nil
+	}
+	db.NotifyQueueChanged()
+	return true, nil
+}
+
+// RetryJobTruncated requeues a running job whose prompt was too large for
+// its agent, to be rebuilt at the given truncation level. It does not count
+// as a retry. Returns false if the job is no longer running.
+func (db *DB) RetryJobTruncated(ctx context.Context, jobID int64, level int) (bool, error) {
+	result, err := db.ExecContext(ctx, `
+		UPDATE review_jobs
+		SET status = 'queued', worker_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, error_code = NULL, truncation_level = ?
+		WHERE id = ? AND status = 'running'
+	`, level, jobID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	db.NotifyQueueChanged()
+	return true, nil
 }
 
 // GetJobRetryCount returns the retry count for a job
-func (db *DB) GetJobRetryCount(jobID int64) (int, error) {
+func (db *DB) GetJobRetryCount(ctx context.Context, jobID int64) (int, error) {
 	var count int
-	err := db.QueryRow(`SELECT retry_count FROM review_jobs WHERE id = ?`, jobID).Scan(&count)
+	err := db.QueryRowContext(ctx, `SELECT retry_count FROM review_jobs WHERE id = ?`, jobID).Scan(&count)
 	return count, err
 }
 
@@ -1121,6 +1373,11 @@ type listJobsOptions struct {
 	branch             string
 	branchIncludeEmpty bool
 	addressed          *bool
+	beforeID           int64
+	repoIdentity       string
+	author             string
+	agent              string
+	since, until       time.Time
 }
 
 // WithGitRef filters jobs by git ref.
@@ -1147,14 +1404,63 @@ func WithAddressed(addressed bool) ListJobsOption {
 	return func(o *listJobsOptions) { o.addressed = &addressed }
 }
 
+// WithBeforeID restricts results to jobs (or reviews, in ListReviews) with
+// an ID lower than id. Used for keyset (cursor) pagination, which stays
+// fast on deep pages where OFFSET would have to scan every skipped row.
+func WithBeforeID(id int64) ListJobsOption {
+	return func(o *listJobsOptions) { o.beforeID = id }
+}
+
+// WithRepoIdentity filters jobs by the identity of their repo (usually the
+// remote URL), matching every clone of the repo regardless of its path.
+func WithRepoIdentity(identity string) ListJobsOption {
+	return func(o *listJobsOptions) { o.repoIdentity = identity }
+}
+
+// WithAuthor filters jobs by the author of their commit, matching the author
+// under any of their aliases.
+func WithAuthor(author string) ListJobsOption {
+	return func(o *listJobsOptions) { o.author = author }
+}
+
+// WithAgent filters jobs by the agent they were enqueued for, and reviews
+// by the agent that wrote them.
+func WithAgent(agent string) ListJobsOption {
+	return func(o *listJobsOptions) { o.agent = agent }
+}
+
+// WithDateRange filters jobs enqueued, or reviews created, at or after
+// since and before until. A zero time leaves that end of the range open.
+func WithDateRange(since, until time.Time) ListJobsOption {
+	return func(o *listJobsOptions) { o.since, o.until = since, until }
+}
+
+// timeRangeConditions returns the conditions restricting a timestamp
+// column to [since, until), skipping zero ends
+func timeRangeConditions(column string, since, until time.Time) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if !since.IsZero() {
+		conditions = append(conditions, "julianday("+column+") >= julianday(?)")
+		args = append(args, since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		conditions = append(conditions, "julianday("+column+") < julianday(?)")
+		args = append(args, until.UTC().Format(time.RFC3339))
+	}
+	return conditions, args
+}
+
 // ListJobs returns jobs with optional status, repo, branch, and addressed filters.
 // addressedFilter: nil = no filter, non-nil bool = filter by addressed state.
-func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]ReviewJob, error) {
+func (db *DB) ListJobs(ctx context.Context, statusFilter string, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]ReviewJob, error) {
 	query := `
 		SELECT j.id, j.repo_id, j.commit_id, j.git_ref, j.branch, j.agent, j.reasoning, j.status, j.enqueued_at,
-		       j.started_at, j.finished_at, j.worker_id, j.error, j.prompt, j.retry_count,
+		       j.started_at, j.finished_at, j.worker_id, j.error, COALESCE(j.error_code, ''), j.prompt, j.retry_count,
 		       COALESCE(j.agentic, 0), r.root_path, r.name, c.subject, rv.addressed, rv.output,
-		       j.source_machine_id, j.uuid, j.model, j.job_type, j.review_type
+		       j.source_machine_id, j.uuid, j.model, j.job_type, j.review_type, j.agent_policy,
+		       EXISTS (SELECT 1 FROM sla_breaches b WHERE b.job_id = j.id), j.focus, j.protected_branch, j.lane, j.truncation_level,
+		       COALESCE(rv.quick_take, '')
 		FROM review_jobs j
 		JOIN repos r ON r.id = j.repo_id
 		LEFT JOIN commits c ON c.id = j.commit_id
@@ -1194,11 +1500,33 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 			conditions = append(conditions, "(rv.addressed IS NULL OR rv.addressed = 0)")
 		}
 	}
-
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if o.beforeID > 0 {
+		conditions = append(conditions, "j.id < ?")
+		args = append(args, o.beforeID)
+	}
+	if o.repoIdentity != "" {
+		conditions = append(conditions, "r.identity = ?")
+		args = append(args, o.repoIdentity)
+	}
+	if o.author != "" {
+		conditions = append(conditions, canonicalAuthor+" = "+canonicalAuthorArg)
+		args = append(args, o.author, o.author)
+	}
+	if o.agent != "" {
+		conditions = append(conditions, "j.agent = ?")
+		args = append(args, o.agent)
 	}
+	rangeConds, rangeArgs := timeRangeConditions("j.enqueued_at", o.since, o.until)
+	conditions = append(conditions, rangeConds...)
+	args = append(args, rangeArgs...)
+	// Parts of a fanned-out review are shown through their join job
+	conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)")
 
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	// Order by primary key: IDs are unique and assigned in enqueue order,
+	// so the ordering is total and stable across pages (unlike enqueued_at,
+	// which can collide and is arbitrary for jobs pulled via sync).
 	query += " ORDER BY j.id DESC"
 
 	if limit > 0 {
@@ -1211,7 +1539,7 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 		}
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1221,16 +1549,17 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 	for rows.Next() {
 		var j ReviewJob
 		var enqueuedAt string
-		var startedAt, finishedAt, workerID, errMsg, prompt, output, sourceMachineID, jobUUID, model, branch, jobTypeStr, reviewTypeStr sql.NullString
+		var startedAt, finishedAt, workerID, errMsg, prompt, output, sourceMachineID, jobUUID, model, branch, jobTypeStr, reviewTypeStr, agentPolicy sql.NullString
 		var commitID sql.NullInt64
 		var commitSubject sql.NullString
 		var addressed sql.NullInt64
 		var agentic int
 
 		err := rows.Scan(&j.ID, &j.RepoID, &commitID, &j.GitRef, &branch, &j.Agent, &j.Reasoning, &j.Status, &enqueuedAt,
-			&startedAt, &finishedAt, &workerID, &errMsg, &prompt, &j.RetryCount,
+			&startedAt, &finishedAt, &workerID, &errMsg, &j.ErrorCode, &prompt, &j.RetryCount,
 			&agentic, &j.RepoPath, &j.RepoName, &commitSubject, &addressed, &output,
-			&sourceMachineID, &jobUUID, &model, &jobTypeStr, &reviewTypeStr)
+			&sourceMachineID, &jobUUID, &model, &jobTypeStr, &reviewTypeStr, &agentPolicy, &j.Overdue, &j.Focus, &j.Protected, &j.Lane, &j.TruncationLevel,
+			&j.QuickTake)
 		if err != nil {
 			return nil, err
 		}
@@ -1260,7 +1589,9 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 		if errMsg.Valid {
 			j.Error = errMsg.String
 		}
-		if prompt.Valid {
+		// Offloaded prompts belong to finished jobs and are not fetched for
+		// listings; callers load them through the job or its review.
+		if prompt.Valid && !IsBlobRef(prompt.String) {
 			j.Prompt = prompt.String
 		}
 		if sourceMachineID.Valid {
@@ -1278,6 +1609,9 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 		if branch.Valid {
 			j.Branch = branch.String
 		}
+		if agentPolicy.Valid {
+			j.AgentPolicy = agentPolicy.String
+		}
 		if addressed.Valid {
 			val := addressed.Int64 != 0
 			j.Addressed = &val
@@ -1285,7 +1619,7 @@ func (db *DB) ListJobs(statusFilter string, repoFilter string, limit, offset int
 		// Compute verdict only for non-task jobs (task jobs don't have PASS/FAIL verdicts)
 		// Task jobs (run, analyze, custom) are identified by having no commit_id and not being dirty
 		if output.Valid && !j.IsTaskJob() {
-			verdict := ParseVerdict(output.String)
+			verdict := db.outputVerdict(output.String)
 			j.Verdict = &verdict
 		}
 
@@ -1304,8 +1638,9 @@ type JobStats struct {
 }
 
 // CountJobStats returns aggregate done/addressed/unaddressed counts
-// using the same filter logic as ListJobs (repo, branch, addressed).
-func (db *DB) CountJobStats(repoFilter string, opts ...ListJobsOption) (JobStats, error) {
+// using the same filter logic as ListJobs (repo, branch, author, agent and
+// date range).
+func (db *DB) CountJobStats(ctx context.Context, repoFilter string, opts ...ListJobsOption) (JobStats, error) {
 	query := `
 		SELECT
 			COALESCE(SUM(CASE WHEN j.status = 'done' THEN 1 ELSE 0 END), 0),
@@ -1313,6 +1648,7 @@ func (db *DB) CountJobStats(repoFilter string, opts ...ListJobsOption) (JobStats
 			COALESCE(SUM(CASE WHEN j.status = 'done' AND (rv.addressed IS NULL OR rv.addressed = 0) THEN 1 ELSE 0 END), 0)
 		FROM review_jobs j
 		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id
 		LEFT JOIN reviews rv ON rv.job_id = j.id
 	`
 	var args []interface{}
@@ -1334,17 +1670,82 @@ func (db *DB) CountJobStats(repoFilter string, opts ...ListJobsOption) (JobStats
 		}
 		args = append(args, o.branch)
 	}
+	if o.author != "" {
+		conditions = append(conditions, canonicalAuthor+" = "+canonicalAuthorArg)
+		args = append(args, o.author, o.author)
+	}
+	if o.agent != "" {
+		conditions = append(conditions, "j.agent = ?")
+		args = append(args, o.agent)
+	}
+	rangeConds, rangeArgs := timeRangeConditions("j.enqueued_at", o.since, o.until)
+	conditions = append(conditions, rangeConds...)
+	args = append(args, rangeArgs...)
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	var stats JobStats
-	err := db.QueryRow(query, args...).Scan(&stats.Done, &stats.Addressed, &stats.Unaddressed)
+	err := db.QueryRowContext(ctx, query, args...).Scan(&stats.Done, &stats.Addressed, &stats.Unaddressed)
 	return stats, err
 }
 
-func (db *DB) GetJobByID(id int64) (*ReviewJob, error) {
+// AgentReviewStats summarizes the completed review jobs of one agent
+type AgentReviewStats struct {
+	Agent        string  `json:"agent"`
+	Jobs         int     `json:"jobs"`
+	TotalSeconds float64 `json:"total_seconds"`
+	// Jobs whose prompt is stored in the database, with their combined
+	// prompt size and duration. Offloaded prompts are not counted.
+	SizedJobs    int     `json:"sized_jobs"`
+	PromptBytes  int64   `json:"prompt_bytes"`
+	SizedSeconds float64 `json:"sized_seconds"`
+}
+
+// GetAgentReviewStats returns duration and prompt size totals of completed
+// commit and range reviews per agent, for repoID or all repos when 0.
+func (db *DB) GetAgentReviewStats(repoID int64) ([]AgentReviewStats, error) {
+	where := "j.status = 'done' AND j.job_type IN ('review', 'range') AND j.started_at IS NOT NULL AND j.finished_at IS NOT NULL"
+	var args []interface{}
+	if repoID != 0 {
+		where += " AND j.repo_id = ?"
+		args = append(args, repoID)
+	}
+	query := `
+		SELECT agent, COUNT(*), SUM(seconds), SUM(sized), SUM(sized * bytes), SUM(sized * seconds)
+		FROM (
+			SELECT j.agent,
+				CAST(strftime('%s', j.finished_at) AS INTEGER) - CAST(strftime('%s', j.started_at) AS INTEGER) AS seconds,
+				CASE WHEN rv.prompt LIKE 'roborev-blob:%' THEN 0 ELSE 1 END AS sized,
+				LENGTH(CAST(rv.prompt AS BLOB)) AS bytes
+			FROM review_jobs j
+			JOIN reviews rv ON rv.job_id = j.id
+			WHERE ` + where + `
+		)
+		WHERE seconds >= 0
+		GROUP BY agent
+		ORDER BY COUNT(*) DESC, agent
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []AgentReviewStats
+	for rows.Next() {
+		var st AgentReviewStats
+		if err := rows.Scan(&st.Agent, &st.Jobs, &st.TotalSeconds, &st.SizedJobs, &st.PromptBytes, &st.SizedSeconds); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
+
+func (db *DB) GetJobByID(ctx context.Context, id int64) (*ReviewJob, error) {
 	var j ReviewJob
 	var enqueuedAt string
 	var startedAt, finishedAt, workerID, errMsg, prompt sql.NullString
@@ -1352,18 +1753,21 @@ func (db *DB) GetJobByID(id int64) (*ReviewJob, error) {
 	var commitSubject sql.NullString
 	var agentic int
 
-	var model, branch, jobTypeStr, reviewTypeStr sql.NullString
-	err := db.QueryRow(`
+	var model, branch, jobTypeStr, reviewTypeStr, agentPolicy sql.NullString
+	err := db.QueryRowContext(ctx, `
 		SELECT j.id, j.repo_id, j.commit_id, j.git_ref, j.branch, j.agent, j.reasoning, j.status, j.enqueued_at,
-		       j.started_at, j.finished_at, j.worker_id, j.error, j.prompt, COALESCE(j.agentic, 0),
-		       r.root_path, r.name, c.subject, j.model, j.job_type, j.review_type
+		       j.started_at, j.finished_at, j.worker_id, j.error, COALESCE(j.error_code, ''), j.prompt, COALESCE(j.agentic, 0),
+		       r.root_path, r.name, c.subject, j.model, j.job_type, j.review_type, j.agent_policy,
+		       COALESCE(jp.parent_id, 0), j.focus, j.protected_branch, j.lane, j.truncation_level
 		FROM review_jobs j
 		JOIN repos r ON r.id = j.repo_id
 		LEFT JOIN commits c ON c.id = j.commit_id
+		LEFT JOIN job_parts jp ON jp.job_id = j.id
 		WHERE j.id = ?
 	`, id).Scan(&j.ID, &j.RepoID, &commitID, &j.GitRef, &branch, &j.Agent, &j.Reasoning, &j.Status, &enqueuedAt,
-		&startedAt, &finishedAt, &workerID, &errMsg, &prompt, &agentic,
-		&j.RepoPath, &j.RepoName, &commitSubject, &model, &jobTypeStr, &reviewTypeStr)
+		&startedAt, &finishedAt, &workerID, &errMsg, &j.ErrorCode, &prompt, &agentic,
+		&j.RepoPath, &j.RepoName, &commitSubject, &model, &jobTypeStr, &reviewTypeStr, &agentPolicy,
+		&j.ParentJobID, &j.Focus, &j.Protected, &j.Lane, &j.TruncationLevel)
 	if err != nil {
 		return nil, err
 	}
@@ -1391,7 +1795,7 @@ func (db *DB) GetJobByID(id int64) (*ReviewJob, error) {
 		j.Error = errMsg.String
 	}
 	if prompt.Valid {
-		j.Prompt = prompt.String
+		j.Prompt = db.loadBlob(prompt.String)
 	}
 	if model.Valid {
 		j.Model = model.String
@@ -1405,47 +1809,136 @@ func (db *DB) GetJobByID(id int64) (*ReviewJob, error) {
 	if branch.Valid {
 		j.Branch = branch.String
 	}
+	if agentPolicy.Valid {
+		j.AgentPolicy = agentPolicy.String
+	}
 
 	return &j, nil
 }
 
-// GetJobCounts returns counts of jobs by status
-func (db *DB) GetJobCounts() (queued, running, done, failed, canceled int, err error) {
-	rows, err := db.Query(`SELECT status, COUNT(*) FROM review_jobs GROUP BY status`)
+// JobCountGroup is what CountJobs groups jobs by
+type JobCountGroup string
+
+const (
+	CountTotal    JobCountGroup = ""       // One group, keyed ""
+	CountByStatus JobCountGroup = "status" // Keyed by JobStatus
+	CountByAgent  JobCountGroup = "agent"  // Keyed by agent name
+	CountByRepo   JobCountGroup = "repo"   // Keyed by repo root path
+	CountByDay    JobCountGroup = "day"    // Keyed by UTC enqueue date, YYYY-MM-DD
+	CountByAuthor JobCountGroup = "author" // Keyed by commit author after aliasing, "" for jobs without a commit
+
+	CountByErrorCode JobCountGroup = "error_code" // Keyed by ErrorCode, "" for jobs without one
+)
+
+// JobCountFilter narrows the jobs CountJobs counts. Zero fields match all jobs.
+type JobCountFilter struct {
+	RepoID int64
+	Status JobStatus
+	Agent  string
+	Author string    // Commit author, matched under any of their aliases
+	Since  time.Time // Enqueued at or after
+}
+
+// JobCounts maps each group key to its number of jobs. Groups without jobs
+// are absent, so a missing key reads as 0.
+type JobCounts map[string]int
+
+// Status returns the count for a status, when grouped by CountByStatus
+func (c JobCounts) Status(status JobStatus) int {
+	return c[string(status)]
+}
+
+// Total returns the count across all groups
+func (c JobCounts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// CountJobsByStatus counts all jobs by status
+func (db *DB) CountJobsByStatus(ctx context.Context) (JobCounts, error) {
+	return db.CountJobs(ctx, CountByStatus, JobCountFilter{})
+}
+
+// CountJobs counts the jobs matching filter, grouped by groupBy
+func (db *DB) CountJobs(ctx context.Context, groupBy JobCountGroup, filter JobCountFilter) (JobCounts, error) {
+	var key, join string
+	switch groupBy {
+	case CountTotal:
+		key = "''"
+	case CountByStatus:
+		key = "j.status"
+	case CountByAgent:
+		key = "j.agent"
+	case CountByRepo:
+		key = "r.root_path"
+		join = "JOIN repos r ON r.id = j.repo_id"
+	case CountByDay:
+		key = "substr(j.enqueued_at, 1, 10)"
+	case CountByAuthor:
+		key = "COALESCE(" + canonicalAuthor + ", '')"
+	case CountByErrorCode:
+		key = "COALESCE(j.error_code, '')"
+	default:
+		return nil, fmt.Errorf("unknown job count grouping %q", groupBy)
+	}
+
+	var conds []string
+	var args []any
+	if filter.RepoID != 0 {
+		conds = append(conds, "j.repo_id = ?")
+		args = append(args, filter.RepoID)
+	}
+	if filter.Status != "" {
+		conds = append(conds, "j.status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.Agent != "" {
+		conds = append(conds, "j.agent = ?")
+		args = append(args, filter.Agent)
+	}
+	if filter.Author != "" {
+		conds = append(conds, canonicalAuthor+" = "+canonicalAuthorArg)
+		args = append(args, filter.Author, filter.Author)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "julianday(j.enqueued_at) >= julianday(?)")
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	if groupBy == CountByAuthor || filter.Author != "" {
+		join += " LEFT JOIN commits c ON c.id = j.commit_id"
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT %s, COUNT(*) FROM review_jobs j %s %s GROUP BY 1`, key, join, where), args...)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
+	counts := JobCounts{}
 	for rows.Next() {
-		var status string
-		var count int
-		if err = rows.Scan(&status, &count); err != nil {
-			return
-		}
-		switch JobStatus(status) {
-		case JobStatusQueued:
-			queued = count
-		case JobStatusRunning:
-			running = count
-		case JobStatusDone:
-			done = count
-		case JobStatusFailed:
-			failed = count
-		case JobStatusCanceled:
-			canceled = count
-		}
-	}
-	err = rows.Err()
-	return
+		var group string
+		var n int
+		if err := rows.Scan(&group, &n); err != nil {
+			return nil, err
+		}
+		counts[group] = n
+	}
+	return counts, rows.Err()
 }
 
 // UpdateJobBranch sets the branch field for a job that doesn't have one.
 // This is used to backfill the branch when it's derived from git.
 // Only updates if the current branch is NULL or empty.
 // Returns the number of rows affected (0 if branch was already set or job not found, 1 if updated).
-func (db *DB) UpdateJobBranch(jobID int64, branch string) (int64, error) {
-	result, err := db.Exec(`UPDATE review_jobs SET branch = ? WHERE id = ? AND (branch IS NULL OR branch = '')`, branch, jobID)
+func (db *DB) UpdateJobBranch(ctx context.Context, jobID int64, branch string) (int64, error) {
+	result, err := db.ExecContext(ctx, `UPDATE review_jobs SET branch = ? WHERE id = ? AND (branch IS NULL OR branch = '')`, branch, jobID)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,42 @@
+package storage
+
+import "testing"
+
+func TestClaimJobLanes(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/lanes-repo")
+	enqueue := func(sha, lane string) *ReviewJob {
+		t.Helper()
+		commit := createCommit(t, db, repo.ID, sha)
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "codex", Lane: lane})
+		if err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		return job
+	}
+
+	backfill := enqueue("sha1", "")
+	if backfill.Lane != LaneBackground {
+		t.Errorf("expected the background lane by default, got %q", backfill.Lane)
+	}
+
+	// A reserved worker passes over background jobs
+	if job, err := db.ClaimJobInLane(t.Context(), "worker-0", LaneInteractive); err != nil || job != nil {
+		t.Fatalf("expected no interactive job, got %+v, %v", job, err)
+	}
+
+	interactive := enqueue("sha2", LaneInteractive)
+	if got, err := db.GetJobByID(t.Context(), interactive.ID); err != nil || got.Lane != LaneInteractive {
+		t.Fatalf("expected the lane to be stored, got %+v, %v", got, err)
+	}
+
+	// Interactive jobs are claimed before older background jobs
+	if job := claimJob(t, db, "worker-1"); job.ID != interactive.ID || job.Lane != LaneInteractive {
+		t.Errorf("expected the interactive job to be claimed first, got %+v", job)
+	}
+	if job := claimJob(t, db, "worker-1"); job.ID != backfill.ID {
+		t.Errorf("expected the background job next, got %d", job.ID)
+	}
+}
@@ -31,6 +31,8 @@ const (
 	JobStatusDone     JobStatus = "done"
 	JobStatusFailed   JobStatus = "failed"
 	JobStatusCanceled JobStatus = "canceled"
+	JobStatusSkipped  JobStatus = "skipped" // Commit opted out of review; Error holds the reason
+	JobStatusBlocked  JobStatus = "blocked" // Failed preflight checks, e.g. repo path missing; Error holds the reason
 )
 
 // JobType classifies what kind of work a review job represents.
@@ -41,6 +43,15 @@ const (
 	JobTypeTask   = "task"   // Run/analyze/design/custom prompt
 )
 
+// PatchRefPrefix starts the synthetic git ref of a review of a patch file
+// that is not in git. The diff content holds the full patch.
+const PatchRefPrefix = "patch:"
+
+// IsPatchRef reports whether ref is the synthetic ref of a patch review
+func IsPatchRef(ref string) bool {
+	return strings.HasPrefix(ref, PatchRefPrefix)
+}
+
 type ReviewJob struct {
 	ID           int64      `json:"id"`
 	RepoID       int64      `json:"repo_id"`
@@ -57,12 +68,21 @@ type ReviewJob struct {
 	FinishedAt   *time.Time `json:"finished_at,omitempty"`
 	WorkerID     string     `json:"worker_id,omitempty"`
 	Error        string     `json:"error,omitempty"`
+	ErrorCode    ErrorCode  `json:"error_code,omitempty"` // Typed cause of a failure, empty when unknown
 	Prompt       string     `json:"prompt,omitempty"`
 	RetryCount   int        `json:"retry_count"`
 	DiffContent  *string    `json:"diff_content,omitempty"`  // For dirty reviews (uncommitted changes)
 	Agentic      bool       `json:"agentic"`                 // Enable agentic mode (allow file edits)
 	ReviewType   string     `json:"review_type,omitempty"`   // Review type (e.g., "security") - changes system prompt
 	OutputPrefix string     `json:"output_prefix,omitempty"` // Prefix to prepend to review output
+	AgentPolicy  string     `json:"agent_policy,omitempty"`  // How the agent was chosen when a policy (e.g. rotation) applied
+	ParentJobID  int64      `json:"parent_job_id,omitempty"` // Join job of a fanned-out review this job is a part of
+	Focus        bool       `json:"focus,omitempty"`         // Thorough review of a high-stakes commit (roborev review --thorough)
+	Protected    bool       `json:"protected,omitempty"`     // Commit is on one of the repo's protected branches
+	Lane         string     `json:"lane,omitempty"`          // Queue lane: LaneBackground or LaneInteractive
+	// TruncationLevel is how far the prompt was shrunk after the agent
+	// rejected it as too long (0 = full prompt)
+	TruncationLevel int `json:"truncation_level,omitempty"`
 
 	// Sync fields
 	UUID            string     `json:"uuid,omitempty"`              // Globally unique identifier for sync
@@ -76,6 +96,8 @@ type ReviewJob struct {
 	CommitSubject string  `json:"commit_subject,omitempty"` // empty for ranges
 	Addressed     *bool   `json:"addressed,omitempty"`      // nil if no review yet
 	Verdict       *string `json:"verdict,omitempty"`        // P/F parsed from review output
+	Overdue       bool    `json:"overdue,omitempty"`        // Missed its repo's review SLA (set by ListJobs)
+	QuickTake     string  `json:"quick_take,omitempty"`     // Review's short summary of itself (set by ListJobs)
 }
 
 // IsDirtyJob returns true if this is a dirty review (uncommitted changes).
@@ -127,8 +149,24 @@ type Review struct {
 	UpdatedByMachineID string     `json:"updated_by_machine_id,omitempty"` // Machine that last modified this review
 	SyncedAt           *time.Time `json:"synced_at,omitempty"`             // Last sync time
 
+	// Signing fields (set when sign_reviews is enabled)
+	Signature  string `json:"signature,omitempty"`   // base64 ed25519 signature of the review payload
+	SigningKey string `json:"signing_key,omitempty"` // base64 public key that produced Signature
+
 	// Joined fields
-	Job *ReviewJob `json:"job,omitempty"`
+	Job   *ReviewJob `json:"job,omitempty"`
+	Env   *JobEnv    `json:"env,omitempty"`   // Environment the job ran in, when captured
+	Usage *JobUsage  `json:"usage,omitempty"` // Tokens used, when the agent reported them
+
+	// The quick review run inline at commit time, when the hook ran one
+	PreReview *PreReview `json:"pre_review,omitempty"`
+
+	// Set when the reviewed diff looked like it tried to steer the reviewer
+	InjectionRisk *InjectionRisk `json:"injection_risk,omitempty"`
+
+	// Set when this review is shown for a commit with the same patch as the
+	// reviewed one (e.g. a cherry-pick): the SHA of the reviewed commit
+	CanonicalSHA string `json:"canonical_sha,omitempty"`
 }
 
 type Response struct {
@@ -147,16 +185,54 @@ type Response struct {
 
 type DaemonStatus struct {
 	Version             string `json:"version"`
+	APIVersion          int    `json:"api_version,omitempty"`       // API revision the daemon speaks
+	MinAPIVersion       int    `json:"min_api_version,omitempty"`   // Oldest client API revision the daemon serves
+	SchemaVersion       int    `json:"schema_version,omitempty"`    // Database schema version the daemon supports
+	DBSchemaVersion     int    `json:"db_schema_version,omitempty"` // Schema version recorded in the database
 	QueuedJobs          int    `json:"queued_jobs"`
 	RunningJobs         int    `json:"running_jobs"`
 	CompletedJobs       int    `json:"completed_jobs"`
 	FailedJobs          int    `json:"failed_jobs"`
 	CanceledJobs        int    `json:"canceled_jobs"`
+	OverdueJobs         int    `json:"overdue_jobs,omitempty"` // Queued or running jobs past their repo's review SLA
 	ActiveWorkers       int    `json:"active_workers"`
 	MaxWorkers          int    `json:"max_workers"`
+	Draining            bool   `json:"draining,omitempty"`              // Workers have stopped claiming new jobs
 	MachineID           string `json:"machine_id,omitempty"`            // Local machine ID for remote job detection
 	ConfigReloadedAt    string `json:"config_reloaded_at,omitempty"`    // Last config reload timestamp (RFC3339Nano)
 	ConfigReloadCounter uint64 `json:"config_reload_counter,omitempty"` // Monotonic reload counter (for sub-second detection)
+
+	// Per-agent share of findings citing nonexistent files or lines (last 30 days)
+	FindingAccuracy []AgentFindingAccuracy `json:"finding_accuracy,omitempty"`
+
+	// Repos with jobs blocked until the cause, such as a missing path, is fixed
+	BrokenRepos []BrokenRepo `json:"broken_repos,omitempty"`
+
+	// Per-worker state, in worker order
+	Workers []WorkerStatus `json:"workers,omitempty"`
+
+	// Set when claimable jobs have waited past queue_starvation_minutes
+	// while workers sat idle
+	Starvation *QueueStarvation `json:"starvation,omitempty"`
+}
+
+// QueueStarvation describes queued jobs that idle workers are not claiming,
+// with the likely cause
+type QueueStarvation struct {
+	OldestJobID int64     `json:"oldest_job_id"`
+	Since       time.Time `json:"since"`        // When the oldest job was enqueued
+	Waiting     int       `json:"waiting"`      // Claimable queued jobs
+	IdleWorkers int       `json:"idle_workers"` // Workers not running a job
+	Cause       string    `json:"cause"`
+}
+
+// WorkerStatus is a daemon worker's current job and its last sign of life.
+// Idle workers beat every few seconds; busy ones beat when their agent
+// writes output, so a stale heartbeat on a busy worker means a silent agent.
+type WorkerStatus struct {
+	ID            string    `json:"id"`
+	JobID         int64     `json:"job_id,omitempty"` // 0 when idle
+	LastHeartbeat time.Time `json:"last_heartbeat"`
 }
 
 // HealthStatus represents the overall daemon health
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// PatchJob is a review job of a commit with the same patch as another,
+// such as the original of a cherry-pick
+type PatchJob struct {
+	JobID int64  `json:"job_id"`
+	SHA   string `json:"sha"`
+}
+
+// SetCommitPatchID records the patch-id of a commit's diff
+func (db *DB) SetCommitPatchID(commitID int64, patchID string) error {
+	_, err := db.Exec(`
+		INSERT INTO commit_patches (commit_id, patch_id) VALUES (?, ?)
+		ON CONFLICT(commit_id) DO UPDATE SET patch_id = excluded.patch_id
+	`, commitID, patchID)
+	return err
+}
+
+// FindPatchJob returns the earliest queued, running or completed review of
+// another commit in the repo with the given patch-id, or nil if there is none
+func (db *DB) FindPatchJob(repoID, commitID int64, patchID string) (*PatchJob, error) {
+	var pj PatchJob
+	err := db.QueryRow(`
+		SELECT j.id, c.sha
+		FROM review_jobs j
+		JOIN commits c ON c.id = j.commit_id
+		JOIN commit_patches cp ON cp.commit_id = c.id
+		WHERE j.repo_id = ? AND cp.patch_id = ? AND c.id != ?
+		  AND j.job_type = 'review' AND j.status IN ('queued', 'running', 'done')
+		ORDER BY j.id ASC
+		LIMIT 1
+	`, repoID, patchID, commitID).Scan(&pj.JobID, &pj.SHA)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pj, nil
+}
+
+// GetPatchReviewSHA returns the most recently reviewed commit in the same
+// repo with the same patch-id as the commit sha, or "" if there is none
+func (db *DB) GetPatchReviewSHA(sha string) (string, error) {
+	var canonical string
+	err := db.QueryRow(`
+		SELECT oc.sha
+		FROM commits c
+		JOIN commit_patches cp ON cp.commit_id = c.id
+		JOIN commit_patches ocp ON ocp.patch_id = cp.patch_id AND ocp.commit_id != c.id
+		JOIN commits oc ON oc.id = ocp.commit_id AND oc.repo_id = c.repo_id
+		JOIN review_jobs j ON j.commit_id = oc.id
+		JOIN reviews rv ON rv.job_id = j.id
+		WHERE c.sha = ?
+		ORDER BY rv.created_at DESC, rv.id DESC
+		LIMIT 1
+	`, sha).Scan(&canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return canonical, err
+}
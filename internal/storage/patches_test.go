@@ -0,0 +1,48 @@
+package storage
+
+import "testing"
+
+func TestPatchJobs(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/patch-repo")
+	orig := createCommit(t, db, repo.ID, "origsha")
+	pick := createCommit(t, db, repo.ID, "picksha")
+	other := createCommit(t, db, repo.ID, "othersha")
+	for commitID, patchID := range map[int64]string{orig.ID: "p1", pick.ID: "p1", other.ID: "p2"} {
+		if err := db.SetCommitPatchID(commitID, patchID); err != nil {
+			t.Fatalf("SetCommitPatchID: %v", err)
+		}
+	}
+
+	if pj, err := db.FindPatchJob(repo.ID, pick.ID, "p1"); err != nil || pj != nil {
+		t.Fatalf("expected no patch job before any review, got %+v, %v", pj, err)
+	}
+	if sha, err := db.GetPatchReviewSHA("picksha"); err != nil || sha != "" {
+		t.Fatalf("expected no patch review yet, got %q, %v", sha, err)
+	}
+
+	job := enqueueJob(t, db, repo.ID, orig.ID, "origsha")
+	pj, err := db.FindPatchJob(repo.ID, pick.ID, "p1")
+	if err != nil {
+		t.Fatalf("FindPatchJob: %v", err)
+	}
+	if pj == nil || pj.JobID != job.ID || pj.SHA != "origsha" {
+		t.Errorf("FindPatchJob = %+v, want job %d of origsha", pj, job.ID)
+	}
+	if pj, _ := db.FindPatchJob(repo.ID, other.ID, "p2"); pj != nil {
+		t.Errorf("expected no patch job for a different patch, got %+v", pj)
+	}
+
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "review of origsha"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if sha, err := db.GetPatchReviewSHA("picksha"); err != nil || sha != "origsha" {
+		t.Errorf("GetPatchReviewSHA = %q, %v; want origsha", sha, err)
+	}
+	if sha, _ := db.GetPatchReviewSHA("othersha"); sha != "" {
+		t.Errorf("expected no patch review for othersha, got %q", sha)
+	}
+}
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PeerBundle is a delta of finished jobs, reviews, and comments exported by
+// one roborev instance for merging into another (roborev sync --from).
+// Rows keep their UUIDs and originating machine IDs, so provenance survives
+// the merge and repeated syncs in either direction converge.
+type PeerBundle struct {
+	MachineID string           `json:"machine_id"`
+	Since     time.Time        `json:"since"`
+	Cursor    time.Time        `json:"cursor"` // pass as Since on the next sync
+	Jobs      []PulledJob      `json:"jobs"`
+	Reviews   []PulledReview   `json:"reviews"`
+	Responses []PulledResponse `json:"responses"`
+}
+
+// PeerMergeStats summarizes the result of ImportPeerBundle.
+type PeerMergeStats struct {
+	JobsInserted      int `json:"jobs_inserted"`
+	JobsUpdated       int `json:"jobs_updated"`
+	ReviewsInserted   int `json:"reviews_inserted"`
+	ReviewsUpdated    int `json:"reviews_updated"`
+	ResponsesInserted int `json:"responses_inserted"`
+	Skipped           int `json:"skipped"` // local copy was newer or identical
+}
+
+// peerSyncStateKey returns the sync_state key holding the delta cursor for
+// a peer source (database path or daemon URL).
+func peerSyncStateKey(source string) string {
+	return "peer_cursor:" + source
+}
+
+// GetPeerCursor returns the time of the last merge from source, or the zero
+// time if source has never been synced.
+func (db *DB) GetPeerCursor(ctx context.Context, source string) (time.Time, error) {
+	v, err := db.GetSyncState(ctx, peerSyncStateKey(source))
+	if err != nil || v == "" {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return t, nil
+}
+
+// SetPeerCursor records the delta cursor for source.
+func (db *DB) SetPeerCursor(ctx context.Context, source string, cursor time.Time) error {
+	return db.SetSyncState(ctx, peerSyncStateKey(source), cursor.UTC().Format(time.RFC3339Nano))
+}
+
+// utcDatetimeSQL normalizes a stored timestamp column (RFC3339 with offset,
+// RFC3339 Z, or bare SQLite datetime) to a comparable UTC datetime.
+func utcDatetimeSQL(col string) string {
+	return `datetime(CASE WHEN ` + col + ` GLOB '*[+-][0-9][0-9]:[0-9][0-9]' OR ` + col + ` LIKE '%Z'
+		THEN ` + col + ` ELSE ` + col + ` || 'Z' END)`
+}
+
+// ExportPeerBundle returns finished jobs, their reviews, and comments that
+// changed at or after since. Queued and running jobs are never exported so
+// the receiving daemon doesn't pick them up and run them a second time.
+// Comparison is at second granularity; the importer dedupes by UUID.
+func (db *DB) ExportPeerBundle(ctx context.Context, since time.Time) (*PeerBundle, error) {
+	machineID, err := db.GetSyncState(ctx, SyncStateMachineID)
+	if err != nil {
+		return nil, err
+	}
+	sinceStr := since.UTC().Format("2006-01-02 15:04:05")
+	bundle := &PeerBundle{
+		MachineID: machineID,
+		Since:     since,
+		Cursor:    since,
+		Jobs:      []PulledJob{},
+		Reviews:   []PulledReview{},
+		Responses: []PulledResponse{},
+	}
+	track := func(t time.Time) {
+		if t.After(bundle.Cursor) {
+			bundle.Cursor = t
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			j.uuid, COALESCE(NULLIF(r.identity, ''), r.root_path),
+			COALESCE(c.sha, ''), COALESCE(c.author, ''), COALESCE(c.subject, ''), COALESCE(c.timestamp, ''),
+			j.git_ref, j.agent, COALESCE(j.model, ''), COALESCE(j.reasoning, ''), COALESCE(j.job_type, 'review'), COALESCE(j.review_type, ''), j.status, j.agentic,
+			j.enqueued_at, COALESCE(j.started_at, ''), COALESCE(j.finished_at, ''),
+			COALESCE(j.prompt, ''), j.diff_content, COALESCE(j.error, ''),
+			COALESCE(j.source_machine_id, ?), COALESCE(j.updated_at, j.enqueued_at)
+		FROM review_jobs j
+		JOIN repos r ON j.repo_id = r.id
+		LEFT JOIN commits c ON j.commit_id = c.id
+		WHERE j.status IN ('done', 'failed', 'canceled')
+		AND j.uuid IS NOT NULL
+		AND `+utcDatetimeSQL("COALESCE(j.updated_at, j.enqueued_at)")+` >= datetime(?)
+		ORDER BY j.id
+	`, machineID, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("query peer jobs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var j PulledJob
+		var enqueuedAt, startedAt, finishedAt, commitTimestamp, updatedAt string
+		var diffContent sql.NullString
+		if err := rows.Scan(
+			&j.UUID, &j.RepoIdentity,
+			&j.CommitSHA, &j.CommitAuthor, &j.CommitSubject, &commitTimestamp,
+			&j.GitRef, &j.Agent, &j.Model, &j.Reasoning, &j.JobType, &j.ReviewType, &j.Status, &j.Agentic,
+			&enqueuedAt, &startedAt, &finishedAt,
+			&j.Prompt, &diffContent, &j.Error,
+			&j.SourceMachineID, &updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan peer job: %w", err)
+		}
+		if diffContent.Valid {
+			j.DiffContent = &diffContent.String
+		}
+		j.EnqueuedAt = parseSQLiteTime(enqueuedAt)
+		if t := parseSQLiteTime(startedAt); !t.IsZero() {
+			j.StartedAt = &t
+		}
+		if t := parseSQLiteTime(finishedAt); !t.IsZero() {
+			j.FinishedAt = &t
+		}
+		j.CommitTimestamp = parseSQLiteTime(commitTimestamp)
+		j.UpdatedAt = parseSQLiteTime(updatedAt)
+		track(j.UpdatedAt)
+		bundle.Jobs = append(bundle.Jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate peer jobs: %w", err)
+	}
+
+	revRows, err := db.QueryContext(ctx, `
+		SELECT
+			rv.uuid, j.uuid, rv.agent, rv.prompt, rv.output, rv.addressed,
+			COALESCE(rv.updated_by_machine_id, ?), rv.created_at, COALESCE(rv.updated_at, rv.created_at)
+		FROM reviews rv
+		JOIN review_jobs j ON rv.job_id = j.id
+		WHERE rv.uuid IS NOT NULL AND j.uuid IS NOT NULL
+		AND `+utcDatetimeSQL("COALESCE(rv.updated_at, rv.created_at)")+` >= datetime(?)
+		ORDER BY rv.id
+	`, machineID, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("query peer reviews: %w", err)
+	}
+	defer revRows.Close()
+	for revRows.Next() {
+		var r PulledReview
+		var createdAt, updatedAt string
+		if err := revRows.Scan(
+			&r.UUID, &r.JobUUID, &r.Agent, &r.Prompt, &r.Output, &r.Addressed,
+			&r.UpdatedByMachineID, &createdAt, &updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan peer review: %w", err)
+		}
+		r.CreatedAt = parseSQLiteTime(createdAt)
+		r.UpdatedAt = parseSQLiteTime(updatedAt)
+		track(r.UpdatedAt)
+		bundle.Reviews = append(bundle.Reviews, r)
+	}
+	if err := revRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate peer reviews: %w", err)
+	}
+
+	// Legacy commit-level comments (no job_id) have no stable parent to
+	// attach to on the other side and are skipped.
+	respRows, err := db.QueryContext(ctx, `
+		SELECT r.uuid, j.uuid, r.responder, r.response, COALESCE(r.source_machine_id, ?), r.created_at
+		FROM responses r
+		JOIN review_jobs j ON r.job_id = j.id
+		WHERE r.uuid IS NOT NULL AND j.uuid IS NOT NULL
+		AND `+utcDatetimeSQL("r.created_at")+` >= datetime(?)
+		ORDER BY r.id
+	`, machineID, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("query peer responses: %w", err)
+	}
+	defer respRows.Close()
+	for respRows.Next() {
+		var r PulledResponse
+		var createdAt string
+		if err := respRows.Scan(&r.UUID, &r.JobUUID, &r.Responder, &r.Response, &r.SourceMachineID, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan peer response: %w", err)
+		}
+		r.CreatedAt = parseSQLiteTime(createdAt)
+		track(r.CreatedAt)
+		bundle.Responses = append(bundle.Responses, r)
+	}
+	if err := respRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate peer responses: %w", err)
+	}
+
+	return bundle, nil
+}
+
+// ImportPeerBundle merges a bundle exported by another instance. New rows
+// are inserted as-is; for rows that exist on both sides the one with the
+// later updated_at wins. Originating machine IDs are preserved.
+func (db *DB) ImportPeerBundle(ctx context.Context, b *PeerBundle) (PeerMergeStats, error) {
+	var stats PeerMergeStats
+
+	for _, j := range b.Jobs {
+		var localUpdated string
+		err := db.QueryRowContext(ctx, `SELECT COALESCE(updated_at, enqueued_at) FROM review_jobs WHERE uuid = ?`, j.UUID).Scan(&localUpdated)
+		switch {
+		case err == sql.ErrNoRows:
+			repoID, err := db.resolvePeerRepo(ctx, j.RepoIdentity)
+			if err != nil {
+				return stats, err
+			}
+			var commitID *int64
+			if j.CommitSHA != "" {
+				id, err := db.GetOrCreateCommitByRepoAndSHA(ctx, repoID, j.CommitSHA, j.CommitAuthor, j.CommitSubject, j.CommitTimestamp)
+				if err != nil {
+					return stats, err
+				}
+				commitID = &id
+			}
+			if err := db.UpsertPulledJob(ctx, j, repoID, commitID); err != nil {
+				return stats, fmt.Errorf("insert job %s: %w", j.UUID, err)
+			}
+			stats.JobsInserted++
+		case err != nil:
+			return stats, fmt.Errorf("look up job %s: %w", j.UUID, err)
+		case j.UpdatedAt.After(parseSQLiteTime(localUpdated)):
+			_, err := db.ExecContext(ctx, `
+				UPDATE review_jobs SET status = ?, finished_at = ?, error = ?,
+					model = COALESCE(?, model), updated_at = ?
+				WHERE uuid = ?
+			`, j.Status, nullTimeStr(j.FinishedAt), nullStr(j.Error), nullStr(j.Model),
+				j.UpdatedAt.UTC().Format(time.RFC3339), j.UUID)
+			if err != nil {
+				return stats, fmt.Errorf("update job %s: %w", j.UUID, err)
+			}
+			stats.JobsUpdated++
+		default:
+			stats.Skipped++
+		}
+	}
+	// Jobs finished elsewhere may release local jobs waiting on them
+	if stats.JobsUpdated > 0 {
+		db.NotifyQueueChanged()
+	}
+
+	for _, r := range b.Reviews {
+		var localUpdated string
+		err := db.QueryRowContext(ctx, `SELECT COALESCE(updated_at, created_at) FROM reviews WHERE uuid = ?`, r.UUID).Scan(&localUpdated)
+		switch {
+		case err == sql.ErrNoRows:
+			var exists bool
+			if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM review_jobs WHERE uuid = ?)`, r.JobUUID).Scan(&exists); err != nil {
+				return stats, fmt.Errorf("look up job for review %s: %w", r.UUID, err)
+			}
+			if !exists {
+				stats.Skipped++
+				continue
+			}
+			if err := db.UpsertPulledReview(ctx, r); err != nil {
+				return stats, fmt.Errorf("insert review %s: %w", r.UUID, err)
+			}
+			stats.ReviewsInserted++
+		case err != nil:
+			return stats, fmt.Errorf("look up review %s: %w", r.UUID, err)
+		case r.UpdatedAt.After(parseSQLiteTime(localUpdated)):
+			_, err := db.ExecContext(ctx, `
+				UPDATE reviews SET addressed = ?, updated_by_machine_id = ?, updated_at = ?
+				WHERE uuid = ?
+			`, r.Addressed, r.UpdatedByMachineID, r.UpdatedAt.UTC().Format(time.RFC3339), r.UUID)
+			if err != nil {
+				return stats, fmt.Errorf("update review %s: %w", r.UUID, err)
+			}
+			stats.ReviewsUpdated++
+		default:
+			stats.Skipped++
+		}
+	}
+
+	for _, r := range b.Responses {
+		result, err := db.ExecContext(ctx, `
+			INSERT INTO responses (uuid, job_id, responder, response, source_machine_id, created_at)
+			SELECT ?, id, ?, ?, ?, ? FROM review_jobs WHERE uuid = ?
+			ON CONFLICT(uuid) DO NOTHING
+		`, r.UUID, r.Responder, r.Response, r.SourceMachineID, r.CreatedAt.UTC().Format(time.RFC3339), r.JobUUID)
+		if err != nil {
+			return stats, fmt.Errorf("insert response %s: %w", r.UUID, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			stats.ResponsesInserted++
+		} else {
+			stats.Skipped++
+		}
+	}
+
+	return stats, nil
+}
+
+// resolvePeerRepo maps a peer's repo identity to a local repo. Peers that
+// never computed an identity export their root path instead, which matches
+// directly when both machines keep the checkout at the same path.
+func (db *DB) resolvePeerRepo(ctx context.Context, identity string) (int64, error) {
+	var id int64
+	err := db.QueryRowContext(ctx, `SELECT id FROM repos WHERE root_path = ?`, identity).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("find repo by path: %w", err)
+	}
+	return db.GetOrCreateRepoByIdentity(ctx, identity)
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+// completePeerJob creates a finished review for sha in db and returns the job.
+func completePeerJob(t *testing.T, db *DB, repoPath, sha, output string) *ReviewJob {
+	t.Helper()
+	_, _, job := createJobChain(t, db, repoPath, sha)
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	return job
+}
+
+func TestPeerBundleRoundTrip(t *testing.T) {
+	laptop := openTestDB(t)
+	defer laptop.Close()
+	desktop := openTestDB(t)
+	defer desktop.Close()
+	repoPath := t.TempDir()
+
+	job := completePeerJob(t, laptop, repoPath, "abc123", "No issues found.")
+	if _, err := laptop.AddCommentToJob(t.Context(), job.ID, "alice", "looks good"); err != nil {
+		t.Fatalf("AddCommentToJob: %v", err)
+	}
+	// Queued jobs must never be exported, or the other daemon would run them
+	repo := createRepo(t, laptop, repoPath)
+	commit := createCommit(t, laptop, repo.ID, "def456")
+	enqueueJob(t, laptop, repo.ID, commit.ID, "def456")
+
+	bundle, err := laptop.ExportPeerBundle(t.Context(), time.Time{})
+	if err != nil {
+		t.Fatalf("ExportPeerBundle: %v", err)
+	}
+	if len(bundle.Jobs) != 1 || len(bundle.Reviews) != 1 || len(bundle.Responses) != 1 {
+		t.Fatalf("expected 1 job/review/response, got %d/%d/%d", len(bundle.Jobs), len(bundle.Reviews), len(bundle.Responses))
+	}
+	if bundle.Cursor.IsZero() {
+		t.Error("expected cursor to advance")
+	}
+
+	stats, err := desktop.ImportPeerBundle(t.Context(), bundle)
+	if err != nil {
+		t.Fatalf("ImportPeerBundle: %v", err)
+	}
+	if stats.JobsInserted != 1 || stats.ReviewsInserted != 1 || stats.ResponsesInserted != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	review, err := desktop.GetReviewByCommitSHA(t.Context(), "abc123")
+	if err != nil {
+		t.Fatalf("GetReviewByCommitSHA on desktop: %v", err)
+	}
+	if review.Output != "No issues found." {
+		t.Errorf("unexpected output %q", review.Output)
+	}
+	if review.Job.RepoPath != repoPath {
+		t.Errorf("expected job attached to local repo %s, got %s", repoPath, review.Job.RepoPath)
+	}
+	laptopMachine, _ := laptop.GetMachineID()
+	var source string
+	if err := desktop.QueryRow(`SELECT source_machine_id FROM review_jobs WHERE uuid = ?`, bundle.Jobs[0].UUID).Scan(&source); err != nil {
+		t.Fatalf("query source_machine_id: %v", err)
+	}
+	if source != laptopMachine {
+		t.Errorf("expected provenance %s, got %s", laptopMachine, source)
+	}
+
+	// Importing the same bundle again is a no-op
+	stats, err = desktop.ImportPeerBundle(t.Context(), bundle)
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if stats.JobsInserted+stats.JobsUpdated+stats.ReviewsInserted+stats.ReviewsUpdated+stats.ResponsesInserted != 0 {
+		t.Errorf("expected re-import to change nothing, got %+v", stats)
+	}
+}
+
+func TestPeerBundleLatestUpdateWins(t *testing.T) {
+	laptop := openTestDB(t)
+	defer laptop.Close()
+	desktop := openTestDB(t)
+	defer desktop.Close()
+	repoPath := t.TempDir()
+
+	completePeerJob(t, laptop, repoPath, "abc123", "- High: bug")
+	bundle, err := laptop.ExportPeerBundle(t.Context(), time.Time{})
+	if err != nil {
+		t.Fatalf("ExportPeerBundle: %v", err)
+	}
+	if _, err := desktop.ImportPeerBundle(t.Context(), bundle); err != nil {
+		t.Fatalf("ImportPeerBundle: %v", err)
+	}
+	cursor := bundle.Cursor
+
+	// Desktop addresses the review later than the laptop's copy
+	review, err := desktop.GetReviewByCommitSHA(t.Context(), "abc123")
+	if err != nil {
+		t.Fatalf("GetReviewByCommitSHA: %v", err)
+	}
+	if err := desktop.MarkReviewAddressed(t.Context(), review.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+	later := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if _, err := desktop.Exec(`UPDATE reviews SET updated_at = ? WHERE id = ?`, later, review.ID); err != nil {
+		t.Fatalf("bump updated_at: %v", err)
+	}
+
+	back, err := desktop.ExportPeerBundle(t.Context(), cursor)
+	if err != nil {
+		t.Fatalf("export from desktop: %v", err)
+	}
+	stats, err := laptop.ImportPeerBundle(t.Context(), back)
+	if err != nil {
+		t.Fatalf("import into laptop: %v", err)
+	}
+	if stats.ReviewsUpdated != 1 {
+		t.Errorf("expected laptop review to be updated, got %+v", stats)
+	}
+	got, err := laptop.GetReviewByCommitSHA(t.Context(), "abc123")
+	if err != nil {
+		t.Fatalf("GetReviewByCommitSHA on laptop: %v", err)
+	}
+	if !got.Addressed {
+		t.Error("expected newer addressed state to win on laptop")
+	}
+
+	// The stale laptop copy from the first bundle must not overwrite it
+	if _, err := laptop.ImportPeerBundle(t.Context(), bundle); err != nil {
+		t.Fatalf("re-import stale bundle: %v", err)
+	}
+	got, _ = laptop.GetReviewByCommitSHA(t.Context(), "abc123")
+	if !got.Addressed {
+		t.Error("stale bundle overwrote newer local state")
+	}
+}
+
+func TestPeerCursor(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	got, err := db.GetPeerCursor(t.Context(), "/other/reviews.db")
+	if err != nil || !got.IsZero() {
+		t.Fatalf("expected zero cursor, got %v (err %v)", got, err)
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if err := db.SetPeerCursor(t.Context(), "/other/reviews.db", now); err != nil {
+		t.Fatalf("SetPeerCursor: %v", err)
+	}
+	got, err = db.GetPeerCursor(t.Context(), "/other/reviews.db")
+	if err != nil {
+		t.Fatalf("GetPeerCursor: %v", err)
+	}
+	if !got.Equal(now) {
+		t.Errorf("expected %v, got %v", now, got)
+	}
+}
@@ -0,0 +1,1177 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgStore schema version - increment when its schema changes
+const pgStoreSchemaVersion = 3
+
+// pgStoreSchemaName is the PostgreSQL schema holding PgStore's tables. It is
+// separate from pgSchemaName, whose tables mirror local databases for sync.
+const pgStoreSchemaName = "roborev_store"
+
+//go:embed schemas/pgstore_v1.sql
+var pgStoreSchemaSQL string
+
+// PgStore is a Storage kept in PostgreSQL, so that several machines can
+// share one review database and job queue. A machine's workers claim only
+// the jobs it queued, whose repos are checked out on it, and claim them
+// with row locks, so each job runs once. Large prompts and outputs are
+// stored inline; blob offloading is a feature of DB only.
+type PgStore struct {
+	pool      *pgxpool.Pool
+	machineID string
+	queued    queueSignal
+}
+
+// OpenPgStore connects to the PostgreSQL database at connString and creates
+// or checks PgStore's schema. machineID is recorded as the source of the
+// jobs, reviews and comments this machine adds.
+func OpenPgStore(ctx context.Context, connString, machineID string) (*PgStore, error) {
+	pool, err := newPgxPool(ctx, connString, DefaultPgPoolConfig(), pgStoreSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	s := &PgStore{pool: pool, machineID: machineID}
+	if err := s.EnsureSchema(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// EnsureSchema creates PgStore's tables if they don't exist and checks that
+// the database is not from a newer version
+func (s *PgStore) EnsureSchema(ctx context.Context) error {
+	// Execute each schema statement individually since pgx prepared
+	// statement mode doesn't support multi-statement execution
+	for _, stmt := range splitPgStatements(pgStoreSchemaSQL) {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create schema: %w", err)
+		}
+	}
+
+	var currentVersion int
+	err := s.pool.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&currentVersion)
+	if err != nil {
+		return fmt.Errorf("check schema version: %w", err)
+	}
+	if currentVersion > pgStoreSchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", currentVersion, pgStoreSchemaVersion)
+	}
+	if currentVersion == 1 {
+		// Version 2 records the error codes of failed jobs
+		_, err = s.pool.Exec(ctx, `ALTER TABLE review_jobs ADD COLUMN IF NOT EXISTS error_code TEXT`)
+		if err != nil {
+			return fmt.Errorf("migrate to v2 (add error_code column): %w", err)
+		}
+	}
+	if currentVersion >= 1 && currentVersion < 3 {
+		// Version 3 records which machine claimed each running job
+		_, err = s.pool.Exec(ctx, `ALTER TABLE review_jobs ADD COLUMN IF NOT EXISTS claimed_machine_id TEXT`)
+		if err != nil {
+			return fmt.Errorf("migrate to v3 (add claimed_machine_id column): %w", err)
+		}
+	}
+	if currentVersion < pgStoreSchemaVersion {
+		_, err = s.pool.Exec(ctx, `INSERT INTO schema_version (version) VALUES ($1) ON CONFLICT (version) DO NOTHING`, pgStoreSchemaVersion)
+		if err != nil {
+			return fmt.Errorf("update schema version: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (s *PgStore) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+// PingContext checks that the database can be reached
+func (s *PgStore) PingContext(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// GetMachineID returns the ID of the machine the store was opened for
+func (s *PgStore) GetMachineID() (string, error) {
+	return s.machineID, nil
+}
+
+// pgStoreErr maps pgx's not-found error to sql.ErrNoRows, which callers of
+// Storage check for
+func pgStoreErr(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// pgScanner is a row or rows to scan a result from
+type pgScanner interface {
+	Scan(dest ...any) error
+}
+
+// pgArgs collects the arguments of a query built at runtime
+type pgArgs []any
+
+// add appends v and returns its placeholder
+func (a *pgArgs) add(v any) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// GetOrCreateRepo finds or creates a repo by its root path.
+// If identity is provided, it is stored when the repo has none yet.
+func (s *PgStore) GetOrCreateRepo(ctx context.Context, rootPath string, identity ...string) (*Repo, error) {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	var repoIdentity string
+	if len(identity) > 0 {
+		repoIdentity = identity[0]
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO repos (root_path, name, identity) VALUES ($1, $2, $3)
+		ON CONFLICT (root_path) DO NOTHING
+	`, absPath, filepath.Base(absPath), nullString(repoIdentity))
+	if err != nil {
+		return nil, err
+	}
+	if repoIdentity != "" {
+		_, err = s.pool.Exec(ctx, `UPDATE repos SET identity = $1 WHERE root_path = $2 AND identity IS NULL`, repoIdentity, absPath)
+		if err != nil {
+			return nil, fmt.Errorf("update identity: %w", err)
+		}
+	}
+	return s.getRepo(ctx, `root_path = $1`, absPath)
+}
+
+// getRepo returns the repo matching a condition on the repos table
+func (s *PgStore) getRepo(ctx context.Context, where string, args ...any) (*Repo, error) {
+	var r Repo
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, root_path, name, COALESCE(identity, ''), created_at FROM repos WHERE `+where,
+		args...).Scan(&r.ID, &r.RootPath, &r.Name, &r.Identity, &r.CreatedAt)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	return &r, nil
+}
+
+// GetRepoByPath returns a repo by its path
+func (s *PgStore) GetRepoByPath(ctx context.Context, rootPath string) (*Repo, error) {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRepo(ctx, `root_path = $1`, absPath)
+}
+
+// GetRepoByID returns a repo by its ID
+func (s *PgStore) GetRepoByID(ctx context.Context, id int64) (*Repo, error) {
+	return s.getRepo(ctx, `id = $1`, id)
+}
+
+// GetRepoByName returns a repo by its display name
+func (s *PgStore) GetRepoByName(ctx context.Context, name string) (*Repo, error) {
+	return s.getRepo(ctx, `name = $1 ORDER BY id LIMIT 1`, name)
+}
+
+// FindRepo finds a repo by path or name (tries path first, then name)
+func (s *PgStore) FindRepo(ctx context.Context, identifier string) (*Repo, error) {
+	repo, err := s.GetRepoByPath(ctx, identifier)
+	if err == nil {
+		return repo, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	return s.GetRepoByName(ctx, identifier)
+}
+
+// ListRepos returns all repos in the database
+func (s *PgStore) ListRepos(ctx context.Context) ([]Repo, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, root_path, name, COALESCE(identity, ''), created_at FROM repos ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repo
+	for rows.Next() {
+		var r Repo
+		if err := rows.Scan(&r.ID, &r.RootPath, &r.Name, &r.Identity, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		repos = append(repos, r)
+	}
+	return repos, rows.Err()
+}
+
+// GetRepoStats returns detailed statistics for a repo
+func (s *PgStore) GetRepoStats(ctx context.Context, repoID int64) (*RepoStats, error) {
+	repo, err := s.GetRepoByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &RepoStats{Repo: repo}
+
+	rows, err := s.pool.Query(ctx, `SELECT status, COUNT(*) FROM review_jobs WHERE repo_id = $1 GROUP BY status`, repoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		stats.TotalJobs += n
+		switch JobStatus(status) {
+		case JobStatusQueued:
+			stats.QueuedJobs = n
+		case JobStatusRunning:
+			stats.RunningJobs = n
+		case JobStatusDone:
+			stats.CompletedJobs = n
+		case JobStatusFailed:
+			stats.FailedJobs = n
+		case JobStatusSkipped:
+			stats.SkippedJobs = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM review_jobs
+		WHERE repo_id = $1 AND status = 'skipped' AND error LIKE $2::text || '%'
+	`, repoID, SampledOutPrefix).Scan(&stats.SampledOutJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exclude prompt jobs from verdict stats, as DB.GetRepoStats does
+	err = s.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE r.output LIKE '%Verdict: PASS%'),
+			COUNT(*) FILTER (WHERE r.output LIKE '%Verdict: FAIL%'),
+			COUNT(*) FILTER (WHERE r.addressed),
+			COUNT(*) FILTER (WHERE NOT r.addressed)
+		FROM reviews r
+		JOIN review_jobs rj ON r.job_id = rj.id
+		WHERE rj.repo_id = $1
+		  AND NOT (rj.commit_id IS NULL AND rj.git_ref = 'prompt')
+	`, repoID).Scan(&stats.PassedReviews, &stats.FailedReviews, &stats.AddressedReviews, &stats.UnaddressedReviews)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// RenameRepo updates the display name of a repo identified by its path or current name
+func (s *PgStore) RenameRepo(ctx context.Context, identifier, newName string) (int64, error) {
+	absPath, _ := filepath.Abs(identifier)
+	tag, err := s.pool.Exec(ctx, `UPDATE repos SET name = $1 WHERE root_path = $2`, newName, absPath)
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() > 0 {
+		return tag.RowsAffected(), nil
+	}
+	tag, err = s.pool.Exec(ctx, `UPDATE repos SET name = $1 WHERE name = $2`, newName, identifier)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteRepo deletes a repo. If cascade is true, its commits, jobs, reviews
+// and comments are deleted with it; otherwise a repo with jobs is kept and
+// ErrRepoHasJobs returned. Unlike DB.DeleteRepo, nothing is kept in a trash.
+func (s *PgStore) DeleteRepo(ctx context.Context, repoID int64, cascade bool) error {
+	return s.tx(ctx, func(tx pgx.Tx) error {
+		// Lock the repo so no job is enqueued between the count and delete
+		var id int64
+		if err := tx.QueryRow(ctx, `SELECT id FROM repos WHERE id = $1 FOR UPDATE`, repoID).Scan(&id); err != nil {
+			return pgStoreErr(err)
+		}
+		var jobs int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM review_jobs WHERE repo_id = $1`, repoID).Scan(&jobs); err != nil {
+			return err
+		}
+		if jobs > 0 && !cascade {
+			return ErrRepoHasJobs
+		}
+
+		// In order due to foreign keys
+		for _, stmt := range []string{
+			`DELETE FROM responses WHERE job_id IN (SELECT id FROM review_jobs WHERE repo_id = $1)`,
+			`DELETE FROM responses WHERE commit_id IN (SELECT id FROM commits WHERE repo_id = $1)`,
+			`DELETE FROM reviews WHERE job_id IN (SELECT id FROM review_jobs WHERE repo_id = $1)`,
+			`DELETE FROM review_jobs WHERE repo_id = $1`,
+			`DELETE FROM commits WHERE repo_id = $1`,
+			`DELETE FROM repos WHERE id = $1`,
+		} {
+			if _, err := tx.Exec(ctx, stmt, repoID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tx runs fn in a transaction, committing it if fn succeeds
+func (s *PgStore) tx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetOrCreateCommit finds or creates a commit record.
+// Lookups are by (repo_id, sha) to handle the same SHA in different repos.
+func (s *PgStore) GetOrCreateCommit(ctx context.Context, repoID int64, sha, author, subject string, timestamp time.Time) (*Commit, error) {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO commits (repo_id, sha, author, subject, timestamp) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (repo_id, sha) DO NOTHING
+	`, repoID, sha, author, subject, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCommitByRepoAndSHA(ctx, repoID, sha)
+}
+
+// getCommit returns the commit matching a condition on the commits table
+func (s *PgStore) getCommit(ctx context.Context, where string, args ...any) (*Commit, error) {
+	var c Commit
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, repo_id, sha, author, subject, timestamp, created_at FROM commits WHERE `+where,
+		args...).Scan(&c.ID, &c.RepoID, &c.SHA, &c.Author, &c.Subject, &c.Timestamp, &c.CreatedAt)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	return &c, nil
+}
+
+// GetCommitByID returns a commit by its ID
+func (s *PgStore) GetCommitByID(ctx context.Context, id int64) (*Commit, error) {
+	return s.getCommit(ctx, `id = $1`, id)
+}
+
+// GetCommitBySHA returns a commit by its SHA. Returns sql.ErrNoRows if no
+// commit is found, or if several repos have this SHA (ambiguous).
+func (s *PgStore) GetCommitBySHA(ctx context.Context, sha string) (*Commit, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `SELECT COUNT(DISTINCT repo_id) FROM commits WHERE sha = $1`, sha).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count > 1 {
+		return nil, sql.ErrNoRows // Ambiguous - multiple repos have this SHA
+	}
+	return s.getCommit(ctx, `sha = $1`, sha)
+}
+
+// GetCommitByRepoAndSHA returns a commit by repo ID and SHA
+func (s *PgStore) GetCommitByRepoAndSHA(ctx context.Context, repoID int64, sha string) (*Commit, error) {
+	return s.getCommit(ctx, `repo_id = $1 AND sha = $2`, repoID, sha)
+}
+
+// EnqueueJob creates a new review job. The job type is inferred from opts.
+func (s *PgStore) EnqueueJob(ctx context.Context, opts EnqueueOpts) (*ReviewJob, error) {
+	reasoning := opts.Reasoning
+	if reasoning == "" {
+		reasoning = "thorough"
+	}
+	jobType, gitRef := opts.jobTypeAndRef()
+	lane := opts.Lane
+	if lane == "" {
+		lane = LaneBackground
+	}
+	var commitID *int64
+	if opts.CommitID > 0 {
+		commitID = &opts.CommitID
+	}
+
+	// Skipped jobs are finished on creation so no worker ever claims them
+	status := JobStatusQueued
+	var finishedAt *time.Time
+	var holdUntil *time.Time
+	now := time.Now()
+	if opts.SkipReason != "" {
+		status = JobStatusSkipped
+		finishedAt = &now
+	} else if !opts.HoldUntil.IsZero() {
+		holdUntil = &opts.HoldUntil
+	}
+
+	job := &ReviewJob{
+		RepoID:          opts.RepoID,
+		CommitID:        commitID,
+		GitRef:          gitRef,
+		Branch:          opts.Branch,
+		Agent:           opts.Agent,
+		Model:           opts.Model,
+		Reasoning:       reasoning,
+		JobType:         jobType,
+		ReviewType:      opts.ReviewType,
+		Status:          status,
+		FinishedAt:      finishedAt,
+		Error:           opts.SkipReason,
+		Prompt:          opts.Prompt,
+		Agentic:         opts.Agentic,
+		OutputPrefix:    opts.OutputPrefix,
+		AgentPolicy:     opts.AgentPolicy,
+		Focus:           opts.Focus,
+		Protected:       opts.Protected,
+		Lane:            lane,
+		UUID:            GenerateUUID(),
+		SourceMachineID: s.machineID,
+	}
+	if opts.DiffContent != "" {
+		job.DiffContent = &opts.DiffContent
+	}
+
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO review_jobs (repo_id, commit_id, git_ref, branch, agent, model, reasoning,
+			status, job_type, review_type, diff_content, prompt, agentic, output_prefix,
+			uuid, source_machine_id, agent_policy, error, finished_at, hold_until, focus,
+			protected_branch, lane, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+		RETURNING id, enqueued_at, updated_at`,
+		opts.RepoID, commitID, gitRef, nullString(opts.Branch), opts.Agent, nullString(opts.Model), reasoning,
+		string(status), jobType, opts.ReviewType, nullString(opts.DiffContent), nullString(opts.Prompt), opts.Agentic,
+		nullString(opts.OutputPrefix), job.UUID, s.machineID, nullString(opts.AgentPolicy),
+		nullString(opts.SkipReason), finishedAt, holdUntil, opts.Focus, opts.Protected, lane, opts.Priority,
+	).Scan(&job.ID, &job.EnqueuedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.UpdatedAt = &updatedAt
+	if status == JobStatusQueued {
+		s.queued.notify()
+	}
+	return job, nil
+}
+
+// EnqueueJobWithPriority is EnqueueJob with the job queued at priority
+func (s *PgStore) EnqueueJobWithPriority(ctx context.Context, opts EnqueueOpts, priority int) (*ReviewJob, error) {
+	opts.Priority = priority
+	return s.EnqueueJob(ctx, opts)
+}
+
+// ClaimJob atomically claims the next queued job for a worker, interactive
+// jobs first, then by priority. Held jobs are passed over until the hold expires.
+func (s *PgStore) ClaimJob(ctx context.Context, workerID string) (*ReviewJob, error) {
+	return s.ClaimJobInLane(ctx, workerID, "")
+}
+
+// ClaimJobInLane is ClaimJob limited to one lane's jobs, or any lane's if
+// lane is "". Only jobs this machine queued are claimed: another machine's
+// repo paths need not exist here. Rows other workers are claiming are
+// skipped rather than waited on.
+func (s *PgStore) ClaimJobInLane(ctx context.Context, workerID, lane string) (*ReviewJob, error) {
+	args := pgArgs{workerID, s.machineID}
+	laneCondition := ""
+	if lane != "" {
+		laneCondition = " AND q.lane = " + args.add(lane)
+	}
+
+	var id int64
+	err := s.pool.QueryRow(ctx, `
+		UPDATE review_jobs
+		SET status = 'running', worker_id = $1, claimed_machine_id = $2, started_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT q.id FROM review_jobs q
+			WHERE q.status = 'queued' AND q.source_machine_id = $2
+				AND (q.hold_until IS NULL OR q.hold_until <= NOW())`+laneCondition+`
+			ORDER BY q.lane = 'interactive' DESC, q.priority DESC, q.enqueued_at, q.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`, args...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil // No jobs available
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetJobByID(ctx, id)
+}
+
+// SaveJobPrompt stores the prompt for a running job
+func (s *PgStore) SaveJobPrompt(ctx context.Context, jobID int64, prompt string) error {
+	_, err := s.pool.Exec(ctx, `UPDATE review_jobs SET prompt = $1 WHERE id = $2`, prompt, jobID)
+	return err
+}
+
+// CompleteJob marks a job as done and stores the review.
+// Only updates if job is still in 'running' state (respects cancellation).
+// If the job has an output_prefix, it will be prepended to the output.
+func (s *PgStore) CompleteJob(ctx context.Context, jobID int64, agent, prompt, output string) error {
+	return s.tx(ctx, func(tx pgx.Tx) error {
+		var outputPrefix string
+		err := tx.QueryRow(ctx, `
+			UPDATE review_jobs SET status = 'done', finished_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND status = 'running'
+			RETURNING COALESCE(output_prefix, '')
+		`, jobID).Scan(&outputPrefix)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Job was canceled or in unexpected state, don't store review
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO reviews (job_id, agent, prompt, output, uuid, updated_by_machine_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, jobID, agent, prompt, outputPrefix+output, GenerateUUID(), s.machineID)
+		return err
+	})
+}
+
+// FailJob marks a job as failed with an error message, recording the error
+// code ClassifyError finds in it.
+// Only updates if job is still in 'running' state (respects cancellation).
+func (s *PgStore) FailJob(ctx context.Context, jobID int64, errorMsg string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE review_jobs SET status = 'failed', finished_at = NOW(), error = $1, error_code = $2, updated_at = NOW()
+		WHERE id = $3 AND status = 'running'
+	`, errorMsg, nullString(string(ClassifyError(errorMsg))), jobID)
+	return err
+}
+
+// CancelJob marks a running, queued or blocked job as canceled
+func (s *PgStore) CancelJob(ctx context.Context, jobID int64) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE review_jobs SET status = 'canceled', finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status IN ('queued', 'running', 'blocked')
+	`, jobID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReenqueueJob resets a completed, failed, canceled, skipped or blocked job
+// back to queued status, deleting the review of a done job
+func (s *PgStore) ReenqueueJob(ctx context.Context, jobID int64) error {
+	err := s.tx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM reviews WHERE job_id = $1`, jobID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE review_jobs
+			SET status = 'queued', worker_id = NULL, claimed_machine_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, error_code = NULL,
+				retry_count = 0, updated_at = NOW()
+			WHERE id = $1 AND status IN ('done', 'failed', 'canceled', 'skipped', 'blocked')
+		`, jobID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if err == nil {
+		s.queued.notify()
+	}
+	return err
+}
+
+// RetryJob atomically resets a running job to queued for retry.
+// Returns false if max retries reached or job is not in running state.
+func (s *PgStore) RetryJob(ctx context.Context, jobID int64, maxRetries int) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE review_jobs
+		SET status = 'queued', worker_id = NULL, claimed_machine_id = NULL, started_at = NULL, finished_at = NULL, error = NULL, error_code = NULL,
+			retry_count = retry_count + 1, updated_at = NOW()
+		WHERE id = $1 AND retry_count < $2 AND status = 'running'
+	`, jobID, maxRetries)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	s.queued.notify()
+	return true, nil
+}
+
+// ResetStaleJobs requeues the jobs this machine's workers were running
+// when its daemon stopped. Other machines' running jobs are left alone.
+func (s *PgStore) ResetStaleJobs(ctx context.Context) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE review_jobs
+		SET status = 'queued', worker_id = NULL, claimed_machine_id = NULL, started_at = NULL, updated_at = NOW()
+		WHERE status = 'running' AND claimed_machine_id = $1
+	`, s.machineID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		s.queued.notify()
+	}
+	return nil
+}
+
+// GetJobRetryCount returns the retry count for a job
+func (s *PgStore) GetJobRetryCount(ctx context.Context, jobID int64) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `SELECT retry_count FROM review_jobs WHERE id = $1`, jobID).Scan(&count)
+	return count, pgStoreErr(err)
+}
+
+// QueueChanged returns a channel that is closed the next time jobs queued
+// through this PgStore may have become claimable
+func (s *PgStore) QueueChanged() <-chan struct{} {
+	return s.queued.wait()
+}
+
+// NotifyQueueChanged wakes those waiting on QueueChanged
+func (s *PgStore) NotifyQueueChanged() {
+	s.queued.notify()
+}
+
+// pgStoreJobColumns are the columns scanJob reads, from review_jobs j
+// joined with repos r and commits c
+const pgStoreJobColumns = `j.id, j.repo_id, j.commit_id, j.git_ref, COALESCE(j.branch, ''), j.agent,
+	COALESCE(j.model, ''), j.reasoning, j.job_type, j.review_type, j.status, j.enqueued_at,
+	j.started_at, j.finished_at, COALESCE(j.worker_id, ''), COALESCE(j.error, ''), COALESCE(j.error_code, ''),
+	COALESCE(j.prompt, ''), j.retry_count, j.diff_content, j.agentic, COALESCE(j.output_prefix, ''),
+	COALESCE(j.agent_policy, ''), j.focus, j.protected_branch, j.lane, j.truncation_level,
+	j.uuid, j.source_machine_id, j.updated_at, r.root_path, r.name, COALESCE(c.subject, '')`
+
+// pgStoreJobFrom joins the tables pgStoreJobColumns reads
+const pgStoreJobFrom = `review_jobs j
+	JOIN repos r ON r.id = j.repo_id
+	LEFT JOIN commits c ON c.id = j.commit_id`
+
+// scanJob scans pgStoreJobColumns, followed by extra columns into extra
+func scanJob(row pgScanner, extra ...any) (*ReviewJob, error) {
+	var j ReviewJob
+	var status, errorCode string
+	var updatedAt time.Time
+	dest := []any{&j.ID, &j.RepoID, &j.CommitID, &j.GitRef, &j.Branch, &j.Agent,
+		&j.Model, &j.Reasoning, &j.JobType, &j.ReviewType, &status, &j.EnqueuedAt,
+		&j.StartedAt, &j.FinishedAt, &j.WorkerID, &j.Error, &errorCode,
+		&j.Prompt, &j.RetryCount, &j.DiffContent, &j.Agentic, &j.OutputPrefix,
+		&j.AgentPolicy, &j.Focus, &j.Protected, &j.Lane, &j.TruncationLevel,
+		&j.UUID, &j.SourceMachineID, &updatedAt, &j.RepoPath, &j.RepoName, &j.CommitSubject}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	j.Status = JobStatus(status)
+	j.ErrorCode = ErrorCode(errorCode)
+	j.UpdatedAt = &updatedAt
+	return &j, nil
+}
+
+// GetJobByID returns a job by ID with joined fields
+func (s *PgStore) GetJobByID(ctx context.Context, id int64) (*ReviewJob, error) {
+	row := s.pool.QueryRow(ctx, `SELECT `+pgStoreJobColumns+` FROM `+pgStoreJobFrom+` WHERE j.id = $1`, id)
+	job, err := scanJob(row)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	return job, nil
+}
+
+// jobConditions returns the conditions ListJobs and CountJobStats filter
+// jobs by, adding their arguments to args
+func jobConditions(args *pgArgs, repoFilter string, o listJobsOptions) []string {
+	var conditions []string
+	if repoFilter != "" {
+		conditions = append(conditions, "r.root_path = "+args.add(repoFilter))
+	}
+	if o.branch != "" {
+		if o.branchIncludeEmpty {
+			conditions = append(conditions, "(j.branch = "+args.add(o.branch)+" OR j.branch = '' OR j.branch IS NULL)")
+		} else {
+			conditions = append(conditions, "j.branch = "+args.add(o.branch))
+		}
+	}
+	if o.author != "" {
+		// There are no author aliases here; authors match by name
+		conditions = append(conditions, "c.author = "+args.add(o.author))
+	}
+	if o.agent != "" {
+		conditions = append(conditions, "j.agent = "+args.add(o.agent))
+	}
+	return append(conditions, pgTimeRangeConditions(args, "j.enqueued_at", o.since, o.until)...)
+}
+
+// pgTimeRangeConditions returns the conditions restricting a timestamp
+// column to [since, until), skipping zero ends
+func pgTimeRangeConditions(args *pgArgs, column string, since, until time.Time) []string {
+	var conditions []string
+	if !since.IsZero() {
+		conditions = append(conditions, column+" >= "+args.add(since))
+	}
+	if !until.IsZero() {
+		conditions = append(conditions, column+" < "+args.add(until))
+	}
+	return conditions
+}
+
+// ListJobs returns jobs with optional status, repo, branch, and addressed filters
+func (s *PgStore) ListJobs(ctx context.Context, statusFilter string, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]ReviewJob, error) {
+	var o listJobsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var args pgArgs
+	conditions := jobConditions(&args, repoFilter, o)
+	if statusFilter != "" {
+		conditions = append(conditions, "j.status = "+args.add(statusFilter))
+	}
+	if o.gitRef != "" {
+		conditions = append(conditions, "j.git_ref = "+args.add(o.gitRef))
+	}
+	if o.addressed != nil {
+		if *o.addressed {
+			conditions = append(conditions, "rv.addressed")
+		} else {
+			conditions = append(conditions, "(rv.addressed IS NULL OR NOT rv.addressed)")
+		}
+	}
+	if o.beforeID > 0 {
+		conditions = append(conditions, "j.id < "+args.add(o.beforeID))
+	}
+	if o.repoIdentity != "" {
+		conditions = append(conditions, "r.identity = "+args.add(o.repoIdentity))
+	}
+
+	query := `SELECT ` + pgStoreJobColumns + `, rv.addressed, rv.output
+		FROM ` + pgStoreJobFrom + `
+		LEFT JOIN reviews rv ON rv.job_id = j.id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY j.id DESC"
+	if limit > 0 {
+		query += " LIMIT " + args.add(limit)
+		if offset > 0 {
+			query += " OFFSET " + args.add(offset)
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []ReviewJob
+	for rows.Next() {
+		var addressed *bool
+		var output *string
+		j, err := scanJob(rows, &addressed, &output)
+		if err != nil {
+			return nil, err
+		}
+		j.Addressed = addressed
+		// Task jobs don't have PASS/FAIL verdicts
+		if output != nil && !j.IsTaskJob() {
+			verdict := ParseVerdict(*output)
+			j.Verdict = &verdict
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// CountJobStats returns aggregate done/addressed/unaddressed counts
+// using the same filter logic as ListJobs (repo, branch, author)
+func (s *PgStore) CountJobStats(ctx context.Context, repoFilter string, opts ...ListJobsOption) (JobStats, error) {
+	var o listJobsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var args pgArgs
+	conditions := jobConditions(&args, repoFilter, o)
+
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE j.status = 'done'),
+			COUNT(*) FILTER (WHERE j.status = 'done' AND rv.addressed),
+			COUNT(*) FILTER (WHERE j.status = 'done' AND (rv.addressed IS NULL OR NOT rv.addressed))
+		FROM ` + pgStoreJobFrom + `
+		LEFT JOIN reviews rv ON rv.job_id = j.id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var stats JobStats
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&stats.Done, &stats.Addressed, &stats.Unaddressed)
+	return stats, err
+}
+
+// UpdateJobBranch sets the branch of a job that doesn't have one.
+// Returns the number of rows affected (0 if branch was already set or job not found).
+func (s *PgStore) UpdateJobBranch(ctx context.Context, jobID int64, branch string) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE review_jobs SET branch = $1 WHERE id = $2 AND (branch IS NULL OR branch = '')
+	`, branch, jobID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountJobsByStatus counts all jobs by status
+func (s *PgStore) CountJobsByStatus(ctx context.Context) (JobCounts, error) {
+	rows, err := s.pool.Query(ctx, `SELECT status, COUNT(*) FROM review_jobs GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := JobCounts{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// pgStoreReviewColumns are the columns scanReview reads, from reviews rv
+const pgStoreReviewColumns = `rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed,
+	rv.uuid, COALESCE(rv.signature, ''), COALESCE(rv.signing_key, ''), rv.updated_at, rv.updated_by_machine_id`
+
+// scanReview scans pgStoreReviewColumns, followed by extra columns into extra
+func scanReview(row pgScanner, extra ...any) (*Review, error) {
+	var r Review
+	var updatedAt time.Time
+	dest := []any{&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &r.CreatedAt, &r.Addressed,
+		&r.UUID, &r.Signature, &r.SigningKey, &updatedAt, &r.UpdatedByMachineID}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	r.UpdatedAt = &updatedAt
+	return &r, nil
+}
+
+// getReviewWithJob returns the first review matching a condition on reviews
+// rv and its job j, with the job attached
+func (s *PgStore) getReviewWithJob(ctx context.Context, where string, args ...any) (*Review, error) {
+	row := s.pool.QueryRow(ctx, `
+		SELECT `+pgStoreJobColumns+`, `+pgStoreReviewColumns+`
+		FROM reviews rv
+		JOIN `+pgStoreJobFrom+` ON j.id = rv.job_id
+		WHERE `+where, args...)
+
+	var r Review
+	var updatedAt time.Time
+	job, err := scanJob(row, &r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &r.CreatedAt, &r.Addressed,
+		&r.UUID, &r.Signature, &r.SigningKey, &updatedAt, &r.UpdatedByMachineID)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	r.UpdatedAt = &updatedAt
+
+	// Task jobs (run, analyze, custom) don't have PASS/FAIL verdicts
+	if r.Output != "" && job.Error == "" && !job.IsTaskJob() {
+		verdict := ParseVerdict(r.Output)
+		job.Verdict = &verdict
+	}
+	job.Addressed = &r.Addressed
+	r.Job = job
+	return &r, nil
+}
+
+// GetReviewByID finds a review by its ID
+func (s *PgStore) GetReviewByID(ctx context.Context, reviewID int64) (*Review, error) {
+	row := s.pool.QueryRow(ctx, `SELECT `+pgStoreReviewColumns+` FROM reviews rv WHERE rv.id = $1`, reviewID)
+	r, err := scanReview(row)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	return r, nil
+}
+
+// GetReviewByJobID finds a review by its job ID, with the job attached
+func (s *PgStore) GetReviewByJobID(ctx context.Context, jobID int64) (*Review, error) {
+	return s.getReviewWithJob(ctx, `rv.job_id = $1`, jobID)
+}
+
+// GetReviewByCommitSHA finds the most recent review of a commit SHA, with
+// its job attached
+func (s *PgStore) GetReviewByCommitSHA(ctx context.Context, sha string) (*Review, error) {
+	return s.getReviewWithJob(ctx, `j.git_ref = $1 ORDER BY rv.created_at DESC, rv.id DESC LIMIT 1`, sha)
+}
+
+// listReviews returns the reviews a query on reviews rv selects
+func (s *PgStore) listReviews(ctx context.Context, query string, args ...any) ([]Review, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *r)
+	}
+	return reviews, rows.Err()
+}
+
+// ListReviews returns reviews newest first, a page at a time, filtered like
+// DB.ListReviews
+func (s *PgStore) ListReviews(ctx context.Context, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]Review, error) {
+	var o listJobsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// The agent and dates of a review are its own, not its job's
+	jobOpts := o
+	jobOpts.agent, jobOpts.since, jobOpts.until = "", time.Time{}, time.Time{}
+	var args pgArgs
+	conditions := jobConditions(&args, repoFilter, jobOpts)
+	if o.gitRef != "" {
+		conditions = append(conditions, "j.git_ref = "+args.add(o.gitRef))
+	}
+	if o.addressed != nil {
+		conditions = append(conditions, "rv.addressed = "+args.add(*o.addressed))
+	}
+	if o.beforeID > 0 {
+		conditions = append(conditions, "rv.id < "+args.add(o.beforeID))
+	}
+	if o.repoIdentity != "" {
+		conditions = append(conditions, "r.identity = "+args.add(o.repoIdentity))
+	}
+	if o.agent != "" {
+		conditions = append(conditions, "rv.agent = "+args.add(o.agent))
+	}
+	conditions = append(conditions, pgTimeRangeConditions(&args, "rv.created_at", o.since, o.until)...)
+
+	query := `SELECT ` + pgStoreReviewColumns + `
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY rv.id DESC"
+	if limit > 0 {
+		query += " LIMIT " + args.add(limit)
+		if offset > 0 {
+			query += " OFFSET " + args.add(offset)
+		}
+	}
+	return s.listReviews(ctx, query, args...)
+}
+
+// GetAllReviewsForGitRef returns all reviews for a git ref (commit SHA or range), oldest first
+func (s *PgStore) GetAllReviewsForGitRef(ctx context.Context, gitRef string) ([]Review, error) {
+	return s.listReviews(ctx, `
+		SELECT `+pgStoreReviewColumns+`
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		WHERE j.git_ref = $1
+		ORDER BY rv.created_at, rv.id
+	`, gitRef)
+}
+
+// GetRecentReviewsForRepo returns the N most recent reviews for a repo
+func (s *PgStore) GetRecentReviewsForRepo(ctx context.Context, repoID int64, limit int) ([]Review, error) {
+	return s.listReviews(ctx, `
+		SELECT `+pgStoreReviewColumns+`
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		WHERE j.repo_id = $1
+		ORDER BY rv.created_at DESC, rv.id DESC
+		LIMIT $2
+	`, repoID, limit)
+}
+
+// GetCommitStatus returns the state of the latest standard review of sha in
+// the repo at repoRoot. There is no triage here, so every finding of an
+// unaddressed review counts as open.
+func (s *PgStore) GetCommitStatus(ctx context.Context, repoRoot, sha string) (CommitStatus, error) {
+	var st CommitStatus
+	var status string
+	var output *string
+	var addressed *bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT j.status, rv.output, rv.addressed, j.protected_branch
+		FROM review_jobs j
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN reviews rv ON rv.job_id = j.id
+		WHERE r.root_path = $1 AND j.git_ref = $2 AND j.job_type = 'review'
+		  AND j.review_type IN ('', 'default')
+		ORDER BY j.id DESC
+		LIMIT 1
+	`, repoRoot, sha).Scan(&status, &output, &addressed, &st.Protected)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+
+	st.Status = JobStatus(status)
+	if output == nil {
+		return st, nil
+	}
+	st.Addressed = addressed != nil && *addressed
+	st.Verdict = ParseVerdict(*output)
+	if !st.Addressed {
+		st.OpenFindings = len(ExtractFindings(*output))
+	}
+	return st, nil
+}
+
+// MarkReviewAddressed marks a review as addressed (or unaddressed) by review ID
+func (s *PgStore) MarkReviewAddressed(ctx context.Context, reviewID int64, addressed bool) error {
+	return s.markAddressed(ctx, `id = $3`, reviewID, addressed)
+}
+
+// MarkReviewAddressedByJobID marks a review as addressed (or unaddressed) by job ID
+func (s *PgStore) MarkReviewAddressedByJobID(ctx context.Context, jobID int64, addressed bool) error {
+	return s.markAddressed(ctx, `job_id = $3`, jobID, addressed)
+}
+
+// markAddressed sets addressed on the review matching where, whose one
+// argument is id
+func (s *PgStore) markAddressed(ctx context.Context, where string, id int64, addressed bool) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE reviews SET addressed = $1, updated_by_machine_id = $2, updated_at = NOW() WHERE `+where,
+		addressed, s.machineID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetReviewSignature stores the signature and signing public key for a review
+func (s *PgStore) SetReviewSignature(reviewID int64, signature, publicKey string) error {
+	_, err := s.pool.Exec(context.Background(), `UPDATE reviews SET signature = $1, signing_key = $2 WHERE id = $3`,
+		signature, publicKey, reviewID)
+	return err
+}
+
+// addResponse inserts a comment on a commit or a job
+func (s *PgStore) addResponse(ctx context.Context, commitID, jobID *int64, responder, response string) (*Response, error) {
+	r := &Response{
+		CommitID:        commitID,
+		JobID:           jobID,
+		Responder:       responder,
+		Response:        response,
+		UUID:            GenerateUUID(),
+		SourceMachineID: s.machineID,
+	}
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO responses (commit_id, job_id, responder, response, uuid, source_machine_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, commitID, jobID, responder, response, r.UUID, s.machineID).Scan(&r.ID, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddComment adds a comment to a commit (legacy - use AddCommentToJob for new code)
+func (s *PgStore) AddComment(ctx context.Context, commitID int64, responder, response string) (*Response, error) {
+	return s.addResponse(ctx, &commitID, nil, responder, response)
+}
+
+// AddCommentToJob adds a comment linked to a job/review. Returns
+// sql.ErrNoRows if the job does not exist.
+func (s *PgStore) AddCommentToJob(ctx context.Context, jobID int64, responder, response string) (*Response, error) {
+	var exists int
+	err := s.pool.QueryRow(ctx, `SELECT 1 FROM review_jobs WHERE id = $1`, jobID).Scan(&exists)
+	if err != nil {
+		return nil, pgStoreErr(err)
+	}
+	return s.addResponse(ctx, nil, &jobID, responder, response)
+}
+
+// listResponses returns the comments matching a condition on responses,
+// oldest first
+func (s *PgStore) listResponses(ctx context.Context, where string, args ...any) ([]Response, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, commit_id, job_id, responder, response, created_at, uuid, source_machine_id
+		FROM responses
+		WHERE `+where+`
+		ORDER BY created_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var responses []Response
+	for rows.Next() {
+		var r Response
+		if err := rows.Scan(&r.ID, &r.CommitID, &r.JobID, &r.Responder, &r.Response, &r.CreatedAt,
+			&r.UUID, &r.SourceMachineID); err != nil {
+			return nil, err
+		}
+		responses = append(responses, r)
+	}
+	return responses, rows.Err()
+}
+
+// GetCommentsForCommit returns all comments for a commit
+func (s *PgStore) GetCommentsForCommit(ctx context.Context, commitID int64) ([]Response, error) {
+	return s.listResponses(ctx, `commit_id = $1`, commitID)
+}
+
+// GetCommentsForJob returns all comments linked to a job
+func (s *PgStore) GetCommentsForJob(ctx context.Context, jobID int64) ([]Response, error) {
+	return s.listResponses(ctx, `job_id = $1`, jobID)
+}
+
+// GetCommentsForCommitSHA returns all comments for a commit by SHA
+func (s *PgStore) GetCommentsForCommitSHA(ctx context.Context, sha string) ([]Response, error) {
+	commit, err := s.GetCommitBySHA(ctx, sha)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCommentsForCommit(ctx, commit.ID)
+}
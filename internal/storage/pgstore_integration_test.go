@@ -0,0 +1,187 @@
+//go:build postgres
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// openIntegrationPgStore opens a PgStore on a freshly created schema
+func openIntegrationPgStore(t *testing.T) *PgStore {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool, err := newPgxPool(ctx, getIntegrationPostgresURL(), DefaultPgPoolConfig(), pgStoreSchemaName)
+	if err != nil {
+		t.Fatalf("Failed to connect to postgres: %v (is docker-compose running?)", err)
+	}
+	_, _ = pool.Exec(ctx, "DROP SCHEMA IF EXISTS "+pgStoreSchemaName+" CASCADE")
+	pool.Close()
+
+	s, err := OpenPgStore(ctx, getIntegrationPostgresURL(), GenerateUUID())
+	if err != nil {
+		t.Fatalf("OpenPgStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestIntegration_PgStoreJobLifecycle(t *testing.T) {
+	s := openIntegrationPgStore(t)
+
+	repo, err := s.GetOrCreateRepo(t.Context(), t.TempDir(), "git@example.com:team/app.git")
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	again, err := s.GetOrCreateRepo(t.Context(), repo.RootPath)
+	if err != nil || again.ID != repo.ID || again.Identity != "git@example.com:team/app.git" {
+		t.Fatalf("GetOrCreateRepo again = %+v, %v; want repo %d with its identity", again, err, repo.ID)
+	}
+	commit, err := s.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Ann", "Add thing", time.Now())
+	if err != nil {
+		t.Fatalf("GetOrCreateCommit: %v", err)
+	}
+
+	background, err := s.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "codex"})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	interactive, err := s.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "codex", Lane: LaneInteractive})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+
+	// Another machine's workers leave the jobs to the machine that queued them
+	elsewhere, err := OpenPgStore(t.Context(), getIntegrationPostgresURL(), GenerateUUID())
+	if err != nil {
+		t.Fatalf("OpenPgStore: %v", err)
+	}
+	defer elsewhere.Close()
+	if job, err := elsewhere.ClaimJob(t.Context(), "remote-worker"); err != nil || job != nil {
+		t.Fatalf("ClaimJob on another machine = %+v, %v; want nil, nil", job, err)
+	}
+
+	// Interactive jobs are claimed first, and each job once
+	claimed, err := s.ClaimJob(t.Context(), "worker-1")
+	if err != nil || claimed == nil || claimed.ID != interactive.ID {
+		t.Fatalf("ClaimJob = %+v, %v; want interactive job %d", claimed, err, interactive.ID)
+	}
+	if claimed.Status != JobStatusRunning || claimed.RepoPath != repo.RootPath || claimed.CommitSubject != "Add thing" {
+		t.Errorf("claimed job = %+v", claimed)
+	}
+	other, err := s.ClaimJob(t.Context(), "worker-2")
+	if err != nil || other == nil || other.ID != background.ID {
+		t.Fatalf("ClaimJob = %+v, %v; want background job %d", other, err, background.ID)
+	}
+	if none, err := s.ClaimJob(t.Context(), "worker-3"); err != nil || none != nil {
+		t.Fatalf("ClaimJob on empty queue = %+v, %v; want nil, nil", none, err)
+	}
+
+	if err := s.CompleteJob(t.Context(), interactive.ID, "codex", "prompt", "No issues found.\n\n**Verdict: PASS**"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if err := s.FailJob(t.Context(), background.ID, "agent crashed"); err != nil {
+		t.Fatalf("FailJob: %v", err)
+	}
+
+	review, err := s.GetReviewByJobID(t.Context(), interactive.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	if review.Job == nil || review.Job.Status != JobStatusDone || review.Job.Verdict == nil || *review.Job.Verdict != "P" {
+		t.Errorf("review job = %+v, want done with verdict P", review.Job)
+	}
+	if err := s.MarkReviewAddressed(t.Context(), review.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+
+	jobs, err := s.ListJobs(t.Context(), "", repo.RootPath, 0, 0, WithAddressed(true))
+	if err != nil || len(jobs) != 1 || jobs[0].ID != interactive.ID {
+		t.Fatalf("ListJobs addressed = %+v, %v; want job %d", jobs, err, interactive.ID)
+	}
+	stats, err := s.CountJobStats(t.Context(), repo.RootPath)
+	if err != nil || stats != (JobStats{Done: 1, Addressed: 1}) {
+		t.Errorf("CountJobStats = %+v, %v", stats, err)
+	}
+	reviews, err := s.ListReviews(t.Context(), repo.RootPath, 10, 0, WithAgent("codex"), WithAddressed(true),
+		WithDateRange(time.Now().Add(-time.Hour), time.Time{}))
+	if err != nil || len(reviews) != 1 || reviews[0].ID != review.ID {
+		t.Errorf("ListReviews = %+v, %v; want review %d", reviews, err, review.ID)
+	}
+	if reviews, err := s.ListReviews(t.Context(), repo.RootPath, 10, 0, WithBeforeID(review.ID)); err != nil || len(reviews) != 0 {
+		t.Errorf("ListReviews before review = %+v, %v; want none", reviews, err)
+	}
+
+	if _, err := s.AddCommentToJob(t.Context(), interactive.ID, "ann", "Thanks"); err != nil {
+		t.Fatalf("AddCommentToJob: %v", err)
+	}
+	if _, err := s.AddCommentToJob(t.Context(), 999999, "ann", "Lost"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("AddCommentToJob on missing job = %v, want sql.ErrNoRows", err)
+	}
+	comments, err := s.GetCommentsForJob(t.Context(), interactive.ID)
+	if err != nil || len(comments) != 1 || comments[0].Response != "Thanks" {
+		t.Errorf("GetCommentsForJob = %+v, %v", comments, err)
+	}
+
+	// A rerun drops the old review
+	if err := s.ReenqueueJob(t.Context(), interactive.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if _, err := s.GetReviewByJobID(t.Context(), interactive.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetReviewByJobID after rerun = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := s.DeleteRepo(t.Context(), repo.ID, false); !errors.Is(err, ErrRepoHasJobs) {
+		t.Errorf("DeleteRepo without cascade = %v, want ErrRepoHasJobs", err)
+	}
+	if err := s.DeleteRepo(t.Context(), repo.ID, true); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	if _, err := s.GetRepoByID(t.Context(), repo.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetRepoByID after delete = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestIntegration_PgStoreResetStaleJobs(t *testing.T) {
+	s := openIntegrationPgStore(t)
+	elsewhere, err := OpenPgStore(t.Context(), getIntegrationPostgresURL(), GenerateUUID())
+	if err != nil {
+		t.Fatalf("OpenPgStore: %v", err)
+	}
+	defer elsewhere.Close()
+
+	repo, err := s.GetOrCreateRepo(t.Context(), t.TempDir())
+	if err != nil {
+		t.Fatalf("GetOrCreateRepo: %v", err)
+	}
+	claim := func(store *PgStore) *ReviewJob {
+		t.Helper()
+		if _, err := store.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, Agent: "codex", Prompt: "review this", Label: "run"}); err != nil {
+			t.Fatalf("EnqueueJob: %v", err)
+		}
+		job, err := store.ClaimJob(t.Context(), "worker-1")
+		if err != nil || job == nil {
+			t.Fatalf("ClaimJob = %+v, %v", job, err)
+		}
+		return job
+	}
+	mine, theirs := claim(s), claim(elsewhere)
+
+	if err := s.ResetStaleJobs(t.Context()); err != nil {
+		t.Fatalf("ResetStaleJobs: %v", err)
+	}
+	for _, tt := range []struct {
+		job  *ReviewJob
+		want JobStatus
+	}{{mine, JobStatusQueued}, {theirs, JobStatusRunning}} {
+		got, err := s.GetJobByID(t.Context(), tt.job.ID)
+		if err != nil || got.Status != tt.want {
+			t.Errorf("job %d = %+v, %v; want %s", tt.job.ID, got, err, tt.want)
+		}
+	}
+}
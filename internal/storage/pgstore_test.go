@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPgStoreSchemaStatements(t *testing.T) {
+	stmts := splitPgStatements(pgStoreSchemaSQL)
+	all := strings.Join(stmts, "\n")
+
+	for _, required := range []string{
+		"CREATE SCHEMA IF NOT EXISTS " + pgStoreSchemaName,
+		"CREATE TABLE IF NOT EXISTS roborev_store.schema_version",
+		"CREATE TABLE IF NOT EXISTS roborev_store.repos",
+		"CREATE TABLE IF NOT EXISTS roborev_store.commits",
+		"CREATE TABLE IF NOT EXISTS roborev_store.review_jobs",
+		"CREATE TABLE IF NOT EXISTS roborev_store.reviews",
+		"CREATE TABLE IF NOT EXISTS roborev_store.responses",
+		"idx_store_jobs_claim",
+	} {
+		if !strings.Contains(all, required) {
+			t.Errorf("schema missing: %s", required)
+		}
+	}
+	// Every table lives in the store's schema, apart from the sync mirror
+	for _, stmt := range stmts {
+		if strings.Contains(stmt, " roborev.") {
+			t.Errorf("statement touches the sync schema: %s", stmt)
+		}
+	}
+}
+
+func TestPgArgs(t *testing.T) {
+	var args pgArgs
+	if got := args.add("a"); got != "$1" {
+		t.Errorf("first placeholder = %q, want $1", got)
+	}
+	if got := args.add(2); got != "$2" {
+		t.Errorf("second placeholder = %q, want $2", got)
+	}
+	if len(args) != 2 || args[0] != "a" || args[1] != 2 {
+		t.Errorf("args = %v, want [a 2]", args)
+	}
+}
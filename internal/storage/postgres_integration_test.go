@@ -136,26 +136,26 @@ func (e *integrationEnv) pgQueryString(query string, args ...interface{}) string
 // tryCreateCompletedReview creates a repo, commit, enqueues a job, marks it running, and completes it.
 // Returns the job, review, and any error. Safe to call from goroutines (does not call t.Fatalf).
 func tryCreateCompletedReview(db *DB, repoID int64, sha, author, subject, prompt, output string) (*ReviewJob, *Review, error) {
-	commit, err := db.GetOrCreateCommit(repoID, sha, author, subject, time.Now())
+	commit, err := db.GetOrCreateCommit(context.Background(), repoID, sha, author, subject, time.Now())
 	if err != nil {
 		return nil, nil, fmt.Errorf("GetOrCreateCommit failed: %w", err)
 	}
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repoID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
+	job, err := db.EnqueueJob(context.Background(), EnqueueOpts{RepoID: repoID, CommitID: commit.ID, GitRef: sha, Agent: "test"})
 	if err != nil {
 		return nil, nil, fmt.Errorf("EnqueueJob failed: %w", err)
 	}
 	if _, err := db.Exec(`UPDATE review_jobs SET status = 'running', started_at = datetime('now') WHERE id = ?`, job.ID); err != nil {
 		return nil, nil, fmt.Errorf("failed to set job running: %w", err)
 	}
-	if err := db.CompleteJob(job.ID, "test", prompt, output); err != nil {
+	if err := db.CompleteJob(context.Background(), job.ID, "test", prompt, output); err != nil {
 		return nil, nil, fmt.Errorf("CompleteJob failed: %w", err)
 	}
-	review, err := db.GetReviewByJobID(job.ID)
+	review, err := db.GetReviewByJobID(context.Background(), job.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("GetReviewByJobID failed: %w", err)
 	}
 	// Re-fetch job to get UUID
-	jobs, err := db.ListJobs("", "", 1000, 0)
+	jobs, err := db.ListJobs(context.Background(), "", "", 1000, 0)
 	if err != nil {
 		return nil, nil, fmt.Errorf("ListJobs failed: %w", err)
 	}
@@ -227,7 +227,7 @@ func pollForJobCount(db *DB, expected int, timeout time.Duration) error {
 	var lastErr error
 	var lastCount int
 	for time.Now().Before(deadline) {
-		jobs, err := db.ListJobs("", "", 1000, 0)
+		jobs, err := db.ListJobs(context.Background(), "", "", 1000, 0)
 		if err != nil {
 			lastErr = err
 			time.Sleep(50 * time.Millisecond)
@@ -250,7 +250,7 @@ func TestIntegration_SyncFullCycle(t *testing.T) {
 	env := newIntegrationEnv(t, 30*time.Second)
 	db := env.openDB("test.db")
 
-	repo, err := db.GetOrCreateRepo(env.TmpDir, "git@github.com:test/integration.git")
+	repo, err := db.GetOrCreateRepo(t.Context(), env.TmpDir, "git@github.com:test/integration.git")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -274,8 +274,8 @@ func TestIntegration_SyncMultipleRepos(t *testing.T) {
 	env := newIntegrationEnv(t, 30*time.Second)
 	db := env.openDB("test.db")
 
-	repo1, _ := db.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo1"), "git@github.com:test/repo1.git")
-	repo2, _ := db.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo2"), "git@github.com:test/repo2.git")
+	repo1, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo1"), "git@github.com:test/repo1.git")
+	repo2, _ := db.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo2"), "git@github.com:test/repo2.git")
 
 	sameSHA := "deadbeef12345678"
 	createCompletedReview(t, db, repo1.ID, sameSHA, "Author", "Subject", "prompt", "output")
@@ -327,7 +327,7 @@ func TestIntegration_PullFromRemote(t *testing.T) {
 		t.Fatalf("SyncNow failed: %v", err)
 	}
 
-	jobs, err := db.ListJobs("", "", 100, 0)
+	jobs, err := db.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("ListJobs failed: %v", err)
 	}
@@ -343,17 +343,17 @@ func TestIntegration_FinalPush(t *testing.T) {
 	env := newIntegrationEnv(t, 30*time.Second)
 	db := env.openDB("test.db")
 
-	repo, _ := db.GetOrCreateRepo(env.TmpDir, "git@github.com:test/finalpush.git")
+	repo, _ := db.GetOrCreateRepo(t.Context(), env.TmpDir, "git@github.com:test/finalpush.git")
 
 	for i := 0; i < 150; i++ {
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, GitRef: "HEAD", Agent: "test"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, GitRef: "HEAD", Agent: "test"})
 		if err != nil {
 			t.Fatalf("EnqueueRangeJob %d failed: %v", i, err)
 		}
 		if _, err = db.Exec(`UPDATE review_jobs SET status = 'running', started_at = datetime('now') WHERE id = ?`, job.ID); err != nil {
 			t.Fatalf("Update job %d to running failed: %v", i, err)
 		}
-		if err = db.CompleteJob(job.ID, "test", "prompt", fmt.Sprintf("output %d", i)); err != nil {
+		if err = db.CompleteJob(t.Context(), job.ID, "test", "prompt", fmt.Sprintf("output %d", i)); err != nil {
 			t.Fatalf("CompleteJob %d failed: %v", i, err)
 		}
 	}
@@ -396,13 +396,13 @@ func TestIntegration_Multiplayer(t *testing.T) {
 
 	sharedRepoIdentity := "git@github.com:test/multiplayer-repo.git"
 
-	repoA, err := dbA.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
+	repoA, err := dbA.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine A: GetOrCreateRepo failed: %v", err)
 	}
 	jobA, reviewA := createCompletedReview(t, dbA, repoA.ID, "aaaa1111", "Alice", "Feature A", "prompt A", "Review from Machine A")
 
-	repoB, err := dbB.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
+	repoB, err := dbB.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine B: GetOrCreateRepo failed: %v", err)
 	}
@@ -436,7 +436,7 @@ func TestIntegration_Multiplayer(t *testing.T) {
 	env.assertPgCount("reviews", 2)
 
 	// Verify Machine A can see Machine B's review
-	jobsA, err := dbA.ListJobs("", "", 100, 0)
+	jobsA, err := dbA.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("Machine A: ListJobs failed: %v", err)
 	}
@@ -456,7 +456,7 @@ func TestIntegration_Multiplayer(t *testing.T) {
 	}
 
 	// Verify Machine B can see Machine A's review
-	jobsB, err := dbB.ListJobs("", "", 100, 0)
+	jobsB, err := dbB.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("Machine B: ListJobs failed: %v", err)
 	}
@@ -476,7 +476,7 @@ func TestIntegration_Multiplayer(t *testing.T) {
 	}
 
 	// Verify review content was pulled correctly
-	reviewBinA, err := dbA.GetReviewByCommitSHA("bbbb2222")
+	reviewBinA, err := dbA.GetReviewByCommitSHA(t.Context(), "bbbb2222")
 	if err != nil {
 		t.Fatalf("Machine A: GetReviewByCommitSHA for B's commit failed: %v", err)
 	}
@@ -484,7 +484,7 @@ func TestIntegration_Multiplayer(t *testing.T) {
 		t.Errorf("Machine A: pulled review UUID mismatch: got %s, want %s", reviewBinA.UUID, reviewB.UUID)
 	}
 
-	reviewAinB, err := dbB.GetReviewByCommitSHA("aaaa1111")
+	reviewAinB, err := dbB.GetReviewByCommitSHA(t.Context(), "aaaa1111")
 	if err != nil {
 		t.Fatalf("Machine B: GetReviewByCommitSHA for A's commit failed: %v", err)
 	}
@@ -504,13 +504,13 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 	sharedRepoIdentity := "git@github.com:test/same-commit-repo.git"
 	sharedCommitSHA := "cccc3333"
 
-	repoA, err := dbA.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
+	repoA, err := dbA.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine A: GetOrCreateRepo failed: %v", err)
 	}
 	jobA, reviewA := createCompletedReview(t, dbA, repoA.ID, sharedCommitSHA, "Charlie", "Shared commit", "prompt", "Machine A's review of shared commit")
 
-	repoB, err := dbB.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
+	repoB, err := dbB.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine B: GetOrCreateRepo failed: %v", err)
 	}
@@ -548,11 +548,11 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 	env.assertPgCount("reviews", 2)
 
 	// Both machines should now have both jobs
-	jobsA, err := dbA.ListJobs("", "", 100, 0)
+	jobsA, err := dbA.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("Machine A: ListJobs failed: %v", err)
 	}
-	jobsB, err := dbB.ListJobs("", "", 100, 0)
+	jobsB, err := dbB.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("Machine B: ListJobs failed: %v", err)
 	}
@@ -582,7 +582,7 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 	}
 
 	// Verify reviews are present on both machines
-	reviewAonA, err := dbA.GetReviewByJobID(jobA.ID)
+	reviewAonA, err := dbA.GetReviewByJobID(t.Context(), jobA.ID)
 	if err != nil {
 		t.Logf("Machine A: local review A query by job ID: %v (expected for pulled jobs)", err)
 	} else if reviewAonA.UUID != reviewA.UUID {
@@ -599,7 +599,7 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 	if jobBIDonA == 0 {
 		t.Error("Machine A: could not find job B by UUID")
 	} else {
-		reviewBonA, err := dbA.GetReviewByJobID(jobBIDonA)
+		reviewBonA, err := dbA.GetReviewByJobID(t.Context(), jobBIDonA)
 		if err != nil {
 			t.Errorf("Machine A: failed to get review B: %v", err)
 		} else if reviewBonA.UUID != reviewB.UUID {
@@ -617,7 +617,7 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 	if jobAIDonB == 0 {
 		t.Error("Machine B: could not find job A by UUID")
 	} else {
-		reviewAonB, err := dbB.GetReviewByJobID(jobAIDonB)
+		reviewAonB, err := dbB.GetReviewByJobID(t.Context(), jobAIDonB)
 		if err != nil {
 			t.Errorf("Machine B: failed to get review A: %v", err)
 		} else if reviewAonB.UUID != reviewA.UUID {
@@ -625,7 +625,7 @@ func TestIntegration_MultiplayerSameCommit(t *testing.T) {
 		}
 	}
 
-	reviewBonB, err := dbB.GetReviewByJobID(jobB.ID)
+	reviewBonB, err := dbB.GetReviewByJobID(t.Context(), jobB.ID)
 	if err != nil {
 		t.Logf("Machine B: local review B query by job ID: %v (expected for pulled jobs)", err)
 	} else if reviewBonB.UUID != reviewB.UUID {
@@ -644,15 +644,15 @@ func TestIntegration_MultiplayerRealistic(t *testing.T) {
 
 	sharedRepoIdentity := "git@github.com:team/shared-project.git"
 
-	repoA, err := dbA.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
+	repoA, err := dbA.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_a"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine A: GetOrCreateRepo failed: %v", err)
 	}
-	repoB, err := dbB.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
+	repoB, err := dbB.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_b"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine B: GetOrCreateRepo failed: %v", err)
 	}
-	repoC, err := dbC.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_c"), sharedRepoIdentity)
+	repoC, err := dbC.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_c"), sharedRepoIdentity)
 	if err != nil {
 		t.Fatalf("Machine C: GetOrCreateRepo failed: %v", err)
 	}
@@ -884,15 +884,15 @@ func TestIntegration_MultiplayerRealistic(t *testing.T) {
 	env.assertPgCount("review_jobs", expectedTotal)
 
 	// Verify each machine can see all jobs
-	jobsA, err := dbA.ListJobs("", "", 1000, 0)
+	jobsA, err := dbA.ListJobs(t.Context(), "", "", 1000, 0)
 	if err != nil {
 		t.Fatalf("Machine A: ListJobs failed: %v", err)
 	}
-	jobsB, err := dbB.ListJobs("", "", 1000, 0)
+	jobsB, err := dbB.ListJobs(t.Context(), "", "", 1000, 0)
 	if err != nil {
 		t.Fatalf("Machine B: ListJobs failed: %v", err)
 	}
-	jobsC, err := dbC.ListJobs("", "", 1000, 0)
+	jobsC, err := dbC.ListJobs(t.Context(), "", "", 1000, 0)
 	if err != nil {
 		t.Fatalf("Machine C: ListJobs failed: %v", err)
 	}
@@ -962,7 +962,7 @@ func TestIntegration_MultiplayerOfflineReconnect(t *testing.T) {
 
 	dbA := env.openDB("machine_a.db")
 
-	repoA, err := dbA.GetOrCreateRepo(filepath.Join(env.TmpDir, "repo_a"), "git@github.com:test/offline-repo.git")
+	repoA, err := dbA.GetOrCreateRepo(t.Context(), filepath.Join(env.TmpDir, "repo_a"), "git@github.com:test/offline-repo.git")
 	if err != nil {
 		t.Fatalf("Machine A: GetOrCreateRepo failed: %v", err)
 	}
@@ -1015,7 +1015,7 @@ func TestIntegration_MultiplayerOfflineReconnect(t *testing.T) {
 		t.Fatalf("Machine B: %v", err)
 	}
 
-	jobsB, err := dbB.ListJobs("", "", 100, 0)
+	jobsB, err := dbB.ListJobs(t.Context(), "", "", 100, 0)
 	if err != nil {
 		t.Fatalf("Machine B: ListJobs failed: %v", err)
 	}
@@ -1033,7 +1033,7 @@ func TestIntegration_SyncNowPushesAllBatches(t *testing.T) {
 	// Start sync worker FIRST, before creating jobs
 	worker := startSyncWorker(t, db, env.pgURL, "", "1h")
 
-	repo, err := db.GetOrCreateRepo("/test/batch-sync-repo", "batch-sync-test-identity")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/test/batch-sync-repo", "batch-sync-test-identity")
 	if err != nil {
 		t.Fatalf("Failed to create repo: %v", err)
 	}
@@ -1041,18 +1041,18 @@ func TestIntegration_SyncNowPushesAllBatches(t *testing.T) {
 	numJobs := 80
 	t.Logf("Creating %d jobs to test batch syncing", numJobs)
 	for i := 0; i < numJobs; i++ {
-		commit, err := db.GetOrCreateCommit(repo.ID, fmt.Sprintf("commit%03d", i), fmt.Sprintf("Author %d", i), fmt.Sprintf("Message %d", i), time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, fmt.Sprintf("commit%03d", i), fmt.Sprintf("Author %d", i), fmt.Sprintf("Message %d", i), time.Now())
 		if err != nil {
 			t.Fatalf("Failed to create commit %d: %v", i, err)
 		}
-		job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: fmt.Sprintf("commit%03d", i), Agent: "test"})
+		job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: fmt.Sprintf("commit%03d", i), Agent: "test"})
 		if err != nil {
 			t.Fatalf("Failed to enqueue job %d: %v", i, err)
 		}
 		if _, err := db.Exec(`UPDATE review_jobs SET status = 'running', started_at = datetime('now') WHERE id = ?`, job.ID); err != nil {
 			t.Fatalf("Failed to update job status %d: %v", i, err)
 		}
-		if err := db.CompleteJob(job.ID, "test", "test prompt", fmt.Sprintf("Review output %d", i)); err != nil {
+		if err := db.CompleteJob(t.Context(), job.ID, "test", "test prompt", fmt.Sprintf("Review output %d", i)); err != nil {
 			t.Fatalf("Failed to complete job %d: %v", i, err)
 		}
 	}
@@ -1061,7 +1061,7 @@ func TestIntegration_SyncNowPushesAllBatches(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to get machine ID: %v", err)
 	}
-	pendingJobs, err := db.GetJobsToSync(machineID, 1000)
+	pendingJobs, err := db.GetJobsToSync(t.Context(), machineID, 1000)
 	if err != nil {
 		t.Fatalf("Failed to get pending jobs: %v", err)
 	}
@@ -1102,7 +1102,7 @@ func TestIntegration_SyncNowPushesAllBatches(t *testing.T) {
 		t.Errorf("Expected %d reviews in postgres, got %d", numJobs, pgReviewCount)
 	}
 
-	pendingJobsAfter, err := db.GetJobsToSync(machineID, 1000)
+	pendingJobsAfter, err := db.GetJobsToSync(t.Context(), machineID, 1000)
 	if err != nil {
 		t.Fatalf("Failed to get pending jobs after sync: %v", err)
 	}
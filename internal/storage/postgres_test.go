@@ -833,51 +833,51 @@ func TestIntegration_NewDatabaseClearsSyncedAt(t *testing.T) {
 	defer sqliteDB.Close()
 
 	// Create test data with synced_at already set
-	repo, err := sqliteDB.GetOrCreateRepo(t.TempDir())
+	repo, err := sqliteDB.GetOrCreateRepo(ctx, t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := sqliteDB.GetOrCreateCommit(repo.ID, "test-sha", "Author", "Subject", time.Now())
+	commit, err := sqliteDB.GetOrCreateCommit(ctx, repo.ID, "test-sha", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := sqliteDB.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "test-sha", Agent: "test", Reasoning: "thorough"})
+	job, err := sqliteDB.EnqueueJob(ctx, EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "test-sha", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
-	_, err = sqliteDB.ClaimJob("worker")
+	_, err = sqliteDB.ClaimJob(ctx, "worker")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	err = sqliteDB.CompleteJob(job.ID, "test", "prompt", "output")
+	err = sqliteDB.CompleteJob(ctx, job.ID, "test", "prompt", "output")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Mark everything as synced to simulate previous sync
-	err = sqliteDB.MarkJobSynced(job.ID)
+	err = sqliteDB.MarkJobSynced(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("MarkJobSynced failed: %v", err)
 	}
-	review, err := sqliteDB.GetReviewByJobID(job.ID)
+	review, err := sqliteDB.GetReviewByJobID(ctx, job.ID)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
-	err = sqliteDB.MarkReviewSynced(review.ID)
+	err = sqliteDB.MarkReviewSynced(t.Context(), review.ID)
 	if err != nil {
 		t.Fatalf("MarkReviewSynced failed: %v", err)
 	}
 
 	// Set a fake old sync target ID (simulating we synced to a different database before)
 	oldTargetID := "old-database-" + uuid.NewString()
-	err = sqliteDB.SetSyncState(SyncStateSyncTargetID, oldTargetID)
+	err = sqliteDB.SetSyncState(t.Context(), SyncStateSyncTargetID, oldTargetID)
 	if err != nil {
 		t.Fatalf("SetSyncState failed: %v", err)
 	}
 
 	// Verify job is currently synced
 	machineID, _ := sqliteDB.GetMachineID()
-	jobsToSync, _ := sqliteDB.GetJobsToSync(machineID, 100)
+	jobsToSync, _ := sqliteDB.GetJobsToSync(t.Context(), machineID, 100)
 	if len(jobsToSync) != 0 {
 		t.Errorf("Expected 0 jobs to sync (all synced), got %d", len(jobsToSync))
 	}
@@ -889,22 +889,22 @@ func TestIntegration_NewDatabaseClearsSyncedAt(t *testing.T) {
 	}
 
 	// Simulate what connect() does: detect new database and clear synced_at
-	lastTargetID, _ := sqliteDB.GetSyncState(SyncStateSyncTargetID)
+	lastTargetID, _ := sqliteDB.GetSyncState(t.Context(), SyncStateSyncTargetID)
 	if lastTargetID != "" && lastTargetID != dbID {
 		// This is what the sync worker does
 		t.Logf("Detected new database (was %s..., now %s...), clearing synced_at", lastTargetID[:8], dbID[:8])
-		err = sqliteDB.ClearAllSyncedAt()
+		err = sqliteDB.ClearAllSyncedAt(t.Context())
 		if err != nil {
 			t.Fatalf("ClearAllSyncedAt failed: %v", err)
 		}
 	}
-	err = sqliteDB.SetSyncState(SyncStateSyncTargetID, dbID)
+	err = sqliteDB.SetSyncState(t.Context(), SyncStateSyncTargetID, dbID)
 	if err != nil {
 		t.Fatalf("SetSyncState (new target) failed: %v", err)
 	}
 
 	// Now the job should be returned for sync again
-	jobsToSync, err = sqliteDB.GetJobsToSync(machineID, 100)
+	jobsToSync, err = sqliteDB.GetJobsToSync(t.Context(), machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -913,7 +913,7 @@ func TestIntegration_NewDatabaseClearsSyncedAt(t *testing.T) {
 	}
 
 	// Verify sync target was updated
-	newTargetID, _ := sqliteDB.GetSyncState(SyncStateSyncTargetID)
+	newTargetID, _ := sqliteDB.GetSyncState(t.Context(), SyncStateSyncTargetID)
 	if newTargetID != dbID {
 		t.Errorf("Expected sync target ID to be %s, got %s", dbID, newTargetID)
 	}
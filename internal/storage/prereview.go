@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Pre-review statuses
+const (
+	PreReviewDone    = "done"
+	PreReviewTimeout = "timeout" // The time budget ran out first
+	PreReviewFailed  = "failed"
+)
+
+// PreReview is the quick review the post-commit hook ran inline for a
+// commit, linked to the job queued for the commit's full review
+type PreReview struct {
+	JobID     int64     `json:"job_id"`
+	Agent     string    `json:"agent"`
+	Model     string    `json:"model,omitempty"`
+	Status    string    `json:"status"`
+	Output    string    `json:"output,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	ElapsedMS int64     `json:"elapsed_ms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SavePreReview stores the pre-review of a job, replacing an earlier one
+func (db *DB) SavePreReview(p PreReview) error {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO pre_reviews (job_id, agent, model, status, output, error, elapsed_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET
+			agent = excluded.agent,
+			model = excluded.model,
+			status = excluded.status,
+			output = excluded.output,
+			error = excluded.error,
+			elapsed_ms = excluded.elapsed_ms,
+			created_at = excluded.created_at
+	`, p.JobID, p.Agent, nullString(p.Model), p.Status, nullString(p.Output), nullString(p.Error), p.ElapsedMS,
+		p.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// GetPreReview returns the pre-review of a job. Returns sql.ErrNoRows when
+// none was run for it.
+func (db *DB) GetPreReview(jobID int64) (*PreReview, error) {
+	var p PreReview
+	var model, output, errMsg sql.NullString
+	var createdAt string
+	err := db.QueryRow(`
+		SELECT job_id, agent, model, status, output, error, elapsed_ms, created_at
+		FROM pre_reviews WHERE job_id = ?
+	`, jobID).Scan(&p.JobID, &p.Agent, &model, &p.Status, &output, &errMsg, &p.ElapsedMS, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	p.Model = model.String
+	p.Output = output.String
+	p.Error = errMsg.String
+	p.CreatedAt = parseSQLiteTime(createdAt)
+	return &p, nil
+}
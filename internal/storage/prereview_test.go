@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestPreReview(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/pre-review-repo")
+	commit := createCommit(t, db, repo.ID, "presha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "presha")
+
+	if _, err := db.GetPreReview(job.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before a pre-review, got %v", err)
+	}
+
+	pre := PreReview{JobID: job.ID, Agent: "openai", Status: PreReviewTimeout, Error: "no result within 5s", ElapsedMS: 5000}
+	if err := db.SavePreReview(pre); err != nil {
+		t.Fatalf("SavePreReview: %v", err)
+	}
+
+	// Running the hook again for the commit replaces the pre-review
+	pre = PreReview{JobID: job.ID, Agent: "openai", Model: "qwen2.5-coder:7b", Status: PreReviewDone, Output: "Looks fine.", ElapsedMS: 1200}
+	if err := db.SavePreReview(pre); err != nil {
+		t.Fatalf("SavePreReview: %v", err)
+	}
+
+	got, err := db.GetPreReview(job.ID)
+	if err != nil {
+		t.Fatalf("GetPreReview: %v", err)
+	}
+	if got.Status != PreReviewDone || got.Output != "Looks fine." || got.Error != "" || got.Model != "qwen2.5-coder:7b" || got.ElapsedMS != 1200 {
+		t.Errorf("unexpected pre-review %+v", got)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be set")
+	}
+}
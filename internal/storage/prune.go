@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PruneOptions is a retention policy: the finished jobs Prune deletes. Each
+// limit applies on its own; a job past either is pruned.
+type PruneOptions struct {
+	Before      time.Time // Prune jobs finished before this; zero for no age limit
+	KeepPerRepo int       // Keep this many newest jobs per repo; 0 for no limit
+	DryRun      bool      // Count the jobs to prune without deleting them
+
+	// Trash keeps the deleted rows in the trash this long, where UndoTrash
+	// can restore them; 0 deletes them permanently
+	Trash time.Duration
+}
+
+// PruneResult reports what Prune deleted, or would delete in a dry run
+type PruneResult struct {
+	Jobs int64 `json:"jobs"` // Jobs, counting the parts of fanned-out reviews
+	Rows int64 `json:"rows"` // Rows across all tables, including the jobs
+
+	// Trash is the operation holding the deleted rows, if they were trashed
+	Trash *TrashOperation `json:"trash,omitempty"`
+}
+
+// jobPruneSteps lists the tables Prune deletes a job's rows from, in order
+// due to foreign keys. Conditions select rows of the jobs in prune_jobs.
+// Records of reviewed PR heads and filed issues are kept, so that neither is
+// reviewed or filed again.
+var jobPruneSteps = []trashStep{
+	{"responses", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"finding_triage", `review_id IN (SELECT rv.id FROM reviews rv JOIN prune_jobs p ON p.id = rv.job_id)`},
+	{"finding_suppressions", `review_id IN (SELECT rv.id FROM reviews rv JOIN prune_jobs p ON p.id = rv.job_id)`},
+	{"reviews", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_env", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_usage", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"pre_reviews", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_symbols", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"finding_checks", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"commit_message_suggestions", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"checklist_results", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_deps", `job_id IN (SELECT id FROM prune_jobs) OR depends_on IN (SELECT id FROM prune_jobs)`},
+	{"share_links", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"sla_breaches", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"injection_risks", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_parts", `job_id IN (SELECT id FROM prune_jobs) OR parent_id IN (SELECT id FROM prune_jobs)`},
+	{"job_group_jobs", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_routes", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"ci_pr_batch_jobs", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"human_review_imports", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_context_docs", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"job_failures", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"dead_letters", `job_id IN (SELECT id FROM prune_jobs)`},
+	{"review_jobs", `id IN (SELECT id FROM prune_jobs)`},
+}
+
+// Prune deletes finished jobs past a retention policy, with their reviews,
+// comments and everything else recorded about them, permanently or into
+// the trash when opts.Trash is set. The parts of a fanned-out review go
+// with the join job; queued and running jobs are never pruned. Commits and
+// content offloaded to a blob store are kept.
+//
+// The database file does not shrink: SQLite reuses the freed pages for new
+// reviews until it is vacuumed. Trashed rows take up space until their
+// retention passes.
+func (db *DB) Prune(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	var result PruneResult
+	if opts.Before.IsZero() && opts.KeepPerRepo <= 0 {
+		return result, nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return result, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+		conn.ExecContext(context.WithoutCancel(ctx), "DROP TABLE IF EXISTS temp.prune_jobs")
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TEMP TABLE prune_jobs (id INTEGER PRIMARY KEY)`); err != nil {
+		return result, err
+	}
+
+	before := ""
+	if !opts.Before.IsZero() {
+		before = opts.Before.UTC().Format(time.RFC3339)
+	}
+	// Jobs are ranked among the top-level jobs of their repo, so the parts
+	// of fanned-out reviews do not count towards KeepPerRepo
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO prune_jobs (id)
+		SELECT id FROM (
+			SELECT j.id, j.status, COALESCE(j.finished_at, j.enqueued_at) AS at,
+				ROW_NUMBER() OVER (PARTITION BY j.repo_id ORDER BY j.id DESC) AS n
+			FROM review_jobs j
+			WHERE NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+		)
+		WHERE status IN ('done', 'failed', 'canceled', 'skipped')
+		  AND ((? != '' AND julianday(at) < julianday(?)) OR (? > 0 AND n > ?))
+	`, before, before, opts.KeepPerRepo, opts.KeepPerRepo)
+	if err != nil {
+		return result, fmt.Errorf("select jobs to prune: %w", err)
+	}
+	_, err = conn.ExecContext(ctx, `
+		INSERT OR IGNORE INTO prune_jobs (id)
+		SELECT job_id FROM job_parts WHERE parent_id IN (SELECT id FROM prune_jobs)
+	`)
+	if err != nil {
+		return result, fmt.Errorf("select parts to prune: %w", err)
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM prune_jobs`).Scan(&result.Jobs); err != nil {
+		return result, err
+	}
+	if result.Jobs == 0 {
+		return result, nil
+	}
+
+	if opts.Trash > 0 && !opts.DryRun {
+		result.Trash, err = beginTrash(ctx, conn, "prune", fmt.Sprintf("%d pruned job(s)", result.Jobs), opts.Trash)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, step := range jobPruneSteps {
+		if opts.DryRun {
+			var n int64
+			if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+step.table+` WHERE `+step.where).Scan(&n); err != nil {
+				return result, fmt.Errorf("count %s: %w", step.table, err)
+			}
+			result.Rows += n
+			continue
+		}
+		if result.Trash != nil {
+			n, err := moveToTrash(ctx, conn, result.Trash.ID, step)
+			if err != nil {
+				return result, fmt.Errorf("delete from %s: %w", step.table, err)
+			}
+			result.Trash.Rows += n
+			result.Rows += n
+			continue
+		}
+		res, err := conn.ExecContext(ctx, `DELETE FROM `+step.table+` WHERE `+step.where)
+		if err != nil {
+			return result, fmt.Errorf("delete from %s: %w", step.table, err)
+		}
+		n, _ := res.RowsAffected()
+		result.Rows += n
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return result, err
+	}
+	committed = true
+	return result, nil
+}
+
+// Vacuum rebuilds the database file, returning the pages freed by deletes
+// such as Prune to the file system. It needs free disk space of about the
+// size of the database and blocks other writers while it runs.
+func (db *DB) Vacuum(ctx context.Context) error {
+	_, err := db.ExecContext(ctx, `VACUUM`)
+	return err
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/prune-repo")
+	other := createRepo(t, db, "/tmp/prune-other")
+	finish := func(repoID int64, sha string, age time.Duration) int64 {
+		t.Helper()
+		job := enqueueJob(t, db, repoID, createCommit(t, db, repoID, sha).ID, sha)
+		claimed := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), claimed.ID, "codex", "prompt", "- High: bug in "+sha); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		at := time.Now().Add(-age).UTC().Format(time.RFC3339)
+		if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ?, finished_at = ? WHERE id = ?`, at, at, job.ID); err != nil {
+			t.Fatal(err)
+		}
+		return job.ID
+	}
+
+	old := finish(repo.ID, "p1", 100*24*time.Hour)
+	if _, err := db.AddCommentToJob(t.Context(), old, "alice", "noted"); err != nil {
+		t.Fatalf("AddCommentToJob: %v", err)
+	}
+	recent := finish(repo.ID, "p2", 24*time.Hour)
+	otherOld := finish(other.ID, "p3", 100*24*time.Hour)
+	queued := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "p4").ID, "p4")
+	if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE id = ?`,
+		time.Now().Add(-200*24*time.Hour).UTC().Format(time.RFC3339), queued.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := func() []int64 {
+		t.Helper()
+		rows, err := db.Query(`SELECT id FROM review_jobs ORDER BY id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			rows.Scan(&id)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	before := time.Now().Add(-90 * 24 * time.Hour)
+	dry, err := db.Prune(t.Context(), PruneOptions{Before: before, DryRun: true})
+	if err != nil {
+		t.Fatalf("Prune dry run: %v", err)
+	}
+	if dry.Jobs != 2 || dry.Rows < 5 {
+		t.Errorf("unexpected dry run %+v", dry)
+	}
+	if got := remaining(); len(got) != 4 {
+		t.Fatalf("dry run deleted jobs, %v remain", got)
+	}
+
+	result, err := db.Prune(t.Context(), PruneOptions{Before: before})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result != dry {
+		t.Errorf("Prune = %+v, dry run = %+v", result, dry)
+	}
+	// Queued jobs are kept however old
+	if got, want := remaining(), []int64{recent, queued.ID}; !slices.Equal(got, want) {
+		t.Errorf("remaining jobs = %v, want %v", got, want)
+	}
+	if _, err := db.GetReviewByJobID(t.Context(), old); err == nil {
+		t.Error("review of a pruned job was kept")
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM responses WHERE job_id IN (?, ?)`, old, otherOld).Scan(&n)
+	if n != 0 {
+		t.Errorf("%d comments of pruned jobs were kept", n)
+	}
+
+	// Keeping one job per repo counts the queued job as the newest
+	result, err = db.Prune(t.Context(), PruneOptions{KeepPerRepo: 1})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if got, want := remaining(), []int64{queued.ID}; result.Jobs != 1 || !slices.Equal(got, want) {
+		t.Errorf("remaining jobs = %v (pruned %d), want %v", got, result.Jobs, want)
+	}
+
+	if result, err := db.Prune(t.Context(), PruneOptions{}); err != nil || result.Jobs != 0 {
+		t.Errorf("expected an empty policy to prune nothing, got %+v, %v", result, err)
+	}
+}
+
+func TestPruneFannedOutReview(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/prune-fanout")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "pf1").ID, "pf1")
+	claimJob(t, db, "worker-1")
+	parts, err := db.FanOutJob(t.Context(), job.ID, [][]string{{"a.go"}, {"b.go"}})
+	if err != nil {
+		t.Fatalf("FanOutJob: %v", err)
+	}
+	for range parts {
+		part := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), part.ID, "codex", "prompt", "No issues found."); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+	}
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "merged"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	newer := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "pf2").ID, "pf2")
+
+	// The parts do not count as newer jobs of the repo, and go with the join
+	result, err := db.Prune(t.Context(), PruneOptions{KeepPerRepo: 1})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result.Jobs != 3 {
+		t.Errorf("pruned %d jobs, want the join and its 2 parts", result.Jobs)
+	}
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM review_jobs`).Scan(&count)
+	if count != 1 {
+		t.Errorf("%d jobs remain, want only job %d", count, newer.ID)
+	}
+	for _, table := range []string{"job_parts", "job_deps", "job_group_jobs"} {
+		db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&count)
+		if count != 0 {
+			t.Errorf("%d rows left in %s", count, table)
+		}
+	}
+}
+
+func TestPruneIntoTrash(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/prune-trash")
+	job := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "pt1").ID, "pt1")
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "- High: bug in pt1"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if _, err := db.AddCommentToJob(t.Context(), job.ID, "alice", "noted"); err != nil {
+		t.Fatalf("AddCommentToJob: %v", err)
+	}
+	newer := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "pt2").ID, "pt2")
+
+	result, err := db.Prune(t.Context(), PruneOptions{KeepPerRepo: 1, Trash: time.Hour})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result.Jobs != 1 || result.Trash == nil || result.Trash.Rows != result.Rows {
+		t.Fatalf("Prune = %+v, want 1 job moved to the trash", result)
+	}
+	if _, err := db.GetJobByID(t.Context(), job.ID); err == nil {
+		t.Fatal("pruned job was kept")
+	}
+
+	if _, err := db.UndoTrash(t.Context(), result.Trash.ID); err != nil {
+		t.Fatalf("UndoTrash: %v", err)
+	}
+	restored, err := db.GetJobByID(t.Context(), job.ID)
+	if err != nil || restored.Status != JobStatusDone {
+		t.Fatalf("restored job = %+v, %v; want done", restored, err)
+	}
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil || review.Output != "- High: bug in pt1" {
+		t.Errorf("restored review = %+v, %v", review, err)
+	}
+	if comments, err := db.GetCommentsForJob(t.Context(), job.ID); err != nil || len(comments) != 1 {
+		t.Errorf("restored comments = %+v, %v; want 1", comments, err)
+	}
+	if _, err := db.GetJobByID(t.Context(), newer.ID); err != nil {
+		t.Errorf("newer job: %v", err)
+	}
+}
+
+// Tables referencing jobs or reviews must be pruned with them, or kept on
+// purpose
+func TestJobPruneStepsCoverForeignKeys(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	kept := []string{"trash_rows"}
+	rows, err := db.Query(`
+		SELECT DISTINCT m.name FROM sqlite_master m, pragma_foreign_key_list(m.name) f
+		WHERE m.type = 'table' AND f."table" IN ('review_jobs', 'reviews')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatal(err)
+		}
+		covered := slices.ContainsFunc(jobPruneSteps, func(s trashStep) bool { return s.table == table })
+		if !covered && !slices.Contains(kept, table) {
+			t.Errorf("table %s references jobs or reviews but is not in jobPruneSteps", table)
+		}
+	}
+}
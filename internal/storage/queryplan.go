@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HotQuery is a query the daemon or TUI runs often enough that a full
+// table scan in its plan slows roborev down as the database grows. The SQL
+// mirrors the query in the function named by Source.
+type HotQuery struct {
+	Name   string
+	Source string
+	SQL    string
+	Args   []any
+
+	// AllowScans lists tables the query may scan in full, such as a table
+	// walked in rowid order under a LIMIT
+	AllowScans []string
+
+	// Index is the index that should serve the query, created by the schema
+	Index string
+}
+
+// PlanStep is one line of an EXPLAIN QUERY PLAN
+type PlanStep struct {
+	ID     int
+	Parent int
+	Detail string
+}
+
+// HotQueries are checked by the query plan tests and by 'roborev db analyze'
+var HotQueries = []HotQuery{
+	{
+		Name:   "claim next job",
+		Source: "ClaimJob",
+		SQL: `SELECT q.id FROM review_jobs q WHERE ` + claimableCondition + `
+			ORDER BY q.priority DESC, q.enqueued_at, q.id LIMIT 1`,
+		Args:  []any{"2026-01-01T00:00:00Z"},
+		Index: "idx_review_jobs_status",
+	},
+	{
+		Name:   "running job of a worker",
+		Source: "ClaimJob",
+		SQL: `SELECT j.id FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN commits c ON c.id = j.commit_id
+			LEFT JOIN job_parts jp ON jp.job_id = j.id
+			WHERE j.worker_id = ? AND j.status = 'running'
+			ORDER BY j.started_at DESC LIMIT 1`,
+		Args:  []any{"worker-1"},
+		Index: "idx_review_jobs_status",
+	},
+	{
+		Name:   "job by ID",
+		Source: "GetJobByID",
+		SQL: `SELECT j.id FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN commits c ON c.id = j.commit_id
+			LEFT JOIN job_parts jp ON jp.job_id = j.id
+			WHERE j.id = ?`,
+		Args: []any{1},
+	},
+	{
+		Name:   "newest jobs",
+		Source: "ListJobs",
+		SQL: `SELECT j.id, EXISTS (SELECT 1 FROM sla_breaches b WHERE b.job_id = j.id)
+			FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN commits c ON c.id = j.commit_id
+			LEFT JOIN reviews rv ON rv.job_id = j.id
+			WHERE NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+			ORDER BY j.id DESC LIMIT ?`,
+		Args: []any{50},
+		// The queue page walks jobs newest first and stops at the limit
+		AllowScans: []string{"review_jobs"},
+	},
+	{
+		Name:   "jobs by status",
+		Source: "ListJobs",
+		SQL: `SELECT j.id FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN reviews rv ON rv.job_id = j.id
+			WHERE j.status = ? AND NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+			ORDER BY j.id DESC LIMIT ?`,
+		Args:  []any{"queued", 50},
+		Index: "idx_review_jobs_status",
+	},
+	{
+		Name:   "jobs of a repo",
+		Source: "ListJobs",
+		SQL: `SELECT j.id FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN reviews rv ON rv.job_id = j.id
+			WHERE r.root_path = ? AND NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+			ORDER BY j.id DESC LIMIT ?`,
+		Args:  []any{"/src/repo-1", 50},
+		Index: "idx_review_jobs_repo",
+	},
+	{
+		Name:   "jobs of a ref",
+		Source: "ListJobs",
+		SQL: `SELECT j.id FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN reviews rv ON rv.job_id = j.id
+			WHERE j.git_ref = ? AND NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+			ORDER BY j.id DESC LIMIT ?`,
+		Args:  []any{"sha-1", 50},
+		Index: "idx_review_jobs_git_ref",
+	},
+	{
+		Name:   "job stats of a repo",
+		Source: "CountJobStats",
+		SQL: `SELECT COUNT(*) FROM review_jobs j
+			JOIN repos r ON r.id = j.repo_id
+			LEFT JOIN reviews rv ON rv.job_id = j.id
+			WHERE r.root_path = ?`,
+		Args:  []any{"/src/repo-1"},
+		Index: "idx_review_jobs_repo",
+	},
+	{
+		Name:   "review of a job",
+		Source: "GetReviewByJobID",
+		SQL: `SELECT rv.id FROM reviews rv
+			JOIN review_jobs j ON j.id = rv.job_id
+			JOIN repos rp ON rp.id = j.repo_id
+			LEFT JOIN commits c ON c.id = j.commit_id
+			WHERE rv.job_id = ?`,
+		Args: []any{1},
+	},
+	{
+		Name:   "review of a commit",
+		Source: "GetReviewByCommitSHA",
+		SQL: `SELECT rv.id FROM reviews rv
+			JOIN review_jobs j ON j.id = rv.job_id
+			JOIN repos rp ON rp.id = j.repo_id
+			LEFT JOIN commits c ON c.id = j.commit_id
+			WHERE j.git_ref = ?
+			ORDER BY rv.created_at DESC LIMIT 1`,
+		Args:  []any{"sha-1"},
+		Index: "idx_review_jobs_git_ref",
+	},
+	{
+		Name:   "comments on a job",
+		Source: "GetCommentsForJob",
+		SQL:    `SELECT id FROM responses WHERE job_id = ? ORDER BY created_at ASC`,
+		Args:   []any{1},
+		Index:  "idx_responses_job_id",
+	},
+	{
+		Name:   "comments on a commit",
+		Source: "GetCommentsForCommit",
+		SQL:    `SELECT id FROM responses WHERE commit_id = ? ORDER BY created_at ASC`,
+		Args:   []any{1},
+		Index:  "idx_responses_commit_id",
+	},
+	{
+		Name:   "commit by SHA",
+		Source: "GetCommitByRepoAndSHA",
+		SQL:    `SELECT id FROM commits WHERE repo_id = ? AND sha = ?`,
+		Args:   []any{1, "sha-1"},
+	},
+	{
+		Name:   "repo by path",
+		Source: "GetOrCreateRepo",
+		SQL:    `SELECT id FROM repos WHERE root_path = ?`,
+		Args:   []any{"/src/repo-1"},
+	},
+	{
+		Name:   "parts of a fanned-out job",
+		Source: "GetJobParts",
+		SQL:    `SELECT job_id FROM job_parts WHERE parent_id = ?`,
+		Args:   []any{1},
+		Index:  "idx_job_parts_parent",
+	},
+	{
+		Name:   "triage of a review",
+		Source: "GetCommitStatus",
+		SQL:    `SELECT finding_index FROM finding_triage WHERE review_id = ?`,
+		Args:   []any{1},
+	},
+}
+
+// hotQueryIndexes are the statements creating the indexes hot queries
+// rely on, for advising when one is missing
+var hotQueryIndexes = map[string]string{
+	"idx_review_jobs_status":  `CREATE INDEX IF NOT EXISTS idx_review_jobs_status ON review_jobs(status)`,
+	"idx_review_jobs_repo":    `CREATE INDEX IF NOT EXISTS idx_review_jobs_repo ON review_jobs(repo_id)`,
+	"idx_review_jobs_git_ref": `CREATE INDEX IF NOT EXISTS idx_review_jobs_git_ref ON review_jobs(git_ref)`,
+	"idx_responses_job_id":    `CREATE INDEX IF NOT EXISTS idx_responses_job_id ON responses(job_id)`,
+	"idx_responses_commit_id": `CREATE INDEX IF NOT EXISTS idx_responses_commit_id ON responses(commit_id)`,
+	"idx_job_parts_parent":    `CREATE INDEX IF NOT EXISTS idx_job_parts_parent ON job_parts(parent_id)`,
+}
+
+// ExplainQueryPlan returns the query plan SQLite picks for a query
+func (db *DB) ExplainQueryPlan(ctx context.Context, query string, args ...any) ([]PlanStep, error) {
+	rows, err := db.QueryContext(ctx, `EXPLAIN QUERY PLAN `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plan []PlanStep
+	for rows.Next() {
+		var step PlanStep
+		var unused int
+		if err := rows.Scan(&step.ID, &step.Parent, &unused, &step.Detail); err != nil {
+			return nil, err
+		}
+		plan = append(plan, step)
+	}
+	return plan, rows.Err()
+}
+
+// fullScanPattern matches plan steps that read a whole table, directly
+// ("SCAN j") or through an index ("SCAN j USING INDEX ..."), but not scans
+// of a subquery or constant row
+var fullScanPattern = regexp.MustCompile(`^SCAN (\w+)(?: |$)`)
+
+// planAliases maps the table aliases hot queries use to their tables
+var planAliases = map[string]string{
+	"j": "review_jobs", "q": "review_jobs", "dep": "review_jobs",
+	"r": "repos", "rp": "repos", "c": "commits", "rv": "reviews",
+	"jp": "job_parts", "d": "job_deps", "b": "sla_breaches",
+}
+
+// FullScans returns the tables a query plan reads in full
+func FullScans(plan []PlanStep) []string {
+	var tables []string
+	for _, step := range plan {
+		m := fullScanPattern.FindStringSubmatch(strings.TrimSpace(step.Detail))
+		if m == nil || m[1] == "CONSTANT" {
+			continue
+		}
+		table := m[1]
+		if t, ok := planAliases[table]; ok {
+			table = t
+		}
+		if !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// QueryPlanReport is the result of checking one hot query's plan
+type QueryPlanReport struct {
+	Query HotQuery
+	Plan  []PlanStep
+	Scans []string // Tables scanned in full that the query may not scan
+}
+
+// CheckHotQueries explains every hot query and reports the full table
+// scans each does beyond those it allows
+func (db *DB) CheckHotQueries(ctx context.Context) ([]QueryPlanReport, error) {
+	var reports []QueryPlanReport
+	for _, q := range HotQueries {
+		plan, err := db.ExplainQueryPlan(ctx, q.SQL, q.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("explain %s: %w", q.Name, err)
+		}
+		report := QueryPlanReport{Query: q, Plan: plan}
+		for _, table := range FullScans(plan) {
+			if !slices.Contains(q.AllowScans, table) {
+				report.Scans = append(report.Scans, table)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// IndexAdvice suggests how to stop a hot query from scanning a table
+type IndexAdvice struct {
+	Query     string
+	Table     string
+	Reason    string
+	Statement string // SQL to run, if there is one
+}
+
+// AdviseIndexes suggests fixes for the full scans in reports: creating the
+// index a query relies on when it is missing, or refreshing the planner's
+// statistics when the index exists but is not used
+func (db *DB) AdviseIndexes(ctx context.Context, reports []QueryPlanReport) ([]IndexAdvice, error) {
+	var advice []IndexAdvice
+	for _, r := range reports {
+		for _, table := range r.Scans {
+			a := IndexAdvice{Query: r.Query.Name, Table: table}
+			switch {
+			case r.Query.Index == "":
+				a.Reason = "no index covers the query's filter on " + table
+			default:
+				var exists bool
+				if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'index' AND name = ?)`,
+					r.Query.Index).Scan(&exists); err != nil {
+					return nil, err
+				}
+				if exists {
+					a.Reason = fmt.Sprintf("index %s exists but the planner does not use it; its statistics may be stale", r.Query.Index)
+					a.Statement = "ANALYZE"
+				} else {
+					a.Reason = fmt.Sprintf("index %s is missing", r.Query.Index)
+					a.Statement = hotQueryIndexes[r.Query.Index]
+				}
+			}
+			advice = append(advice, a)
+		}
+	}
+	return advice, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// largeDBJobs is how many jobs the query plan tests' database holds, enough
+// for planner statistics to reflect a long-lived install
+const largeDBJobs = 100000
+
+// seedLargeDB fills db with synthetic repos, commits, jobs, reviews and
+// responses, then gathers planner statistics
+func seedLargeDB(t *testing.T, db *DB) {
+	t.Helper()
+	start := time.Now()
+	steps := []string{
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 20)
+		 INSERT INTO repos (id, root_path, name) SELECT i, '/src/repo-' || i, 'repo-' || i FROM n`,
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < ?)
+		 INSERT INTO commits (id, repo_id, sha, author, subject, timestamp)
+		 SELECT i, i % 20 + 1, 'sha-' || i, 'author-' || (i % 50), 'subject', '2026-01-01T00:00:00Z' FROM n`,
+		// Almost every job is finished; a few are queued, running or failed
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < ?)
+		 INSERT INTO review_jobs (id, repo_id, commit_id, git_ref, branch, status, worker_id, enqueued_at, started_at, finished_at)
+		 SELECT i, i % 20 + 1, i, 'sha-' || i, 'main',
+		        CASE WHEN i % 1000 = 0 THEN 'queued' WHEN i % 1000 = 1 THEN 'running' WHEN i % 100 = 2 THEN 'failed' ELSE 'done' END,
+		        'worker-' || (i % 4), '2026-01-01T00:00:00Z', '2026-01-01T00:00:01Z', '2026-01-01T00:01:00Z'
+		 FROM n`,
+		`INSERT INTO reviews (job_id, agent, prompt, output, addressed)
+		 SELECT id, 'codex', '', 'No issues found.', id % 3 = 0 FROM review_jobs WHERE status = 'done'`,
+		`INSERT INTO responses (commit_id, job_id, responder, response)
+		 SELECT commit_id, id, 'dev', 'Fixed' FROM review_jobs WHERE id % 10 = 0`,
+		`INSERT INTO job_parts (job_id, parent_id, paths)
+		 SELECT id, id - 1, 'a/' FROM review_jobs WHERE id % 500 = 0`,
+		`ANALYZE`,
+	}
+	for _, step := range steps {
+		var args []any
+		if strings.Contains(step, "?") {
+			args = append(args, largeDBJobs)
+		}
+		if _, err := db.Exec(step, args...); err != nil {
+			t.Fatalf("seed: %v\n%s", err, step)
+		}
+	}
+	t.Logf("seeded %d jobs in %s", largeDBJobs, time.Since(start).Round(time.Millisecond))
+}
+
+func TestHotQueryPlans(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a database of 100k jobs")
+	}
+	db := openTestDB(t)
+	defer db.Close()
+	seedLargeDB(t, db)
+
+	reports, err := db.CheckHotQueries(t.Context())
+	if err != nil {
+		t.Fatalf("CheckHotQueries: %v", err)
+	}
+	for _, r := range reports {
+		if len(r.Scans) == 0 {
+			continue
+		}
+		var plan string
+		for _, step := range r.Plan {
+			plan += "\n  " + step.Detail
+		}
+		t.Errorf("%s (%s) scans %v in full:%s", r.Query.Name, r.Query.Source, r.Scans, plan)
+	}
+}
+
+func TestFullScans(t *testing.T) {
+	plan := []PlanStep{
+		{Detail: "SCAN j"},
+		{Detail: "SEARCH r USING INTEGER PRIMARY KEY (rowid=?)"},
+		{Detail: "SCAN responses USING INDEX idx_responses_sync"},
+		{Detail: "SCAN CONSTANT ROW"},
+		{Detail: "SCAN (subquery-1)"},
+		{Detail: "SCAN q"},
+	}
+	if got := FullScans(plan); !slices.Equal(got, []string{"review_jobs", "responses"}) {
+		t.Errorf("FullScans = %v", got)
+	}
+}
+
+func TestAdviseIndexes(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`DROP INDEX idx_responses_commit_id`); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := db.CheckHotQueries(t.Context())
+	if err != nil {
+		t.Fatalf("CheckHotQueries: %v", err)
+	}
+	advice, err := db.AdviseIndexes(t.Context(), reports)
+	if err != nil {
+		t.Fatalf("AdviseIndexes: %v", err)
+	}
+	if len(advice) != 1 || advice[0].Table != "responses" ||
+		advice[0].Statement != "CREATE INDEX IF NOT EXISTS idx_responses_commit_id ON responses(commit_id)" {
+		t.Fatalf("unexpected advice %+v", advice)
+	}
+
+	if _, err := db.Exec(advice[0].Statement); err != nil {
+		t.Fatalf("apply advice: %v", err)
+	}
+	reports, _ = db.CheckHotQueries(t.Context())
+	if advice, _ := db.AdviseIndexes(t.Context(), reports); len(advice) != 0 {
+		t.Errorf("expected no advice once the index exists, got %+v", advice)
+	}
+}
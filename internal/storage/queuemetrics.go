@@ -0,0 +1,93 @@
+package storage
+
+import "time"
+
+// QueueSample is a snapshot of the job queue, recorded by the daemon every
+// minute for capacity planning
+type QueueSample struct {
+	SampledAt time.Time `json:"sampled_at"`
+	Queued    int       `json:"queued"`
+	Running   int       `json:"running"`
+	Workers   int       `json:"workers"`  // Size of the worker pool
+	Finished  int       `json:"finished"` // Jobs finished since the previous sample
+	// Mean time from enqueue to finish of the jobs finished since the
+	// previous sample
+	AvgLatencySeconds float64 `json:"avg_latency_seconds"`
+}
+
+// Saturated reports whether jobs were waiting while every worker was busy
+func (s QueueSample) Saturated() bool {
+	return s.Queued > 0 && s.Workers > 0 && s.Running >= s.Workers
+}
+
+// RecordQueueSample counts the queued and running jobs and the jobs that
+// finished in the window before now, and stores the result
+func (db *DB) RecordQueueSample(now time.Time, window time.Duration, workers int) (QueueSample, error) {
+	sample := QueueSample{SampledAt: now.UTC().Truncate(time.Second), Workers: workers}
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'queued' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END), 0)
+		FROM review_jobs
+		WHERE status IN ('queued', 'running')
+	`).Scan(&sample.Queued, &sample.Running)
+	if err != nil {
+		return sample, err
+	}
+
+	err = db.QueryRow(`
+		SELECT COUNT(*), COALESCE(AVG(
+			CAST(strftime('%s', finished_at) AS INTEGER) - CAST(strftime('%s', enqueued_at) AS INTEGER)
+		), 0)
+		FROM review_jobs
+		WHERE status IN ('done', 'failed') AND finished_at IS NOT NULL
+		  AND datetime(finished_at) > datetime(?) AND datetime(finished_at) <= datetime(?)
+	`, now.Add(-window).UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)).
+		Scan(&sample.Finished, &sample.AvgLatencySeconds)
+	if err != nil {
+		return sample, err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO queue_metrics (sampled_at, queued, running, workers, finished, avg_latency_seconds)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sample.SampledAt.Format(time.RFC3339), sample.Queued, sample.Running, sample.Workers,
+		sample.Finished, sample.AvgLatencySeconds)
+	return sample, err
+}
+
+// ListQueueSamples returns the queue samples recorded since the given time,
+// oldest first
+func (db *DB) ListQueueSamples(since time.Time) ([]QueueSample, error) {
+	rows, err := db.Query(`
+		SELECT sampled_at, queued, running, workers, finished, avg_latency_seconds
+		FROM queue_metrics
+		WHERE sampled_at >= ?
+		ORDER BY sampled_at, id
+	`, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []QueueSample
+	for rows.Next() {
+		var s QueueSample
+		var sampledAt string
+		if err := rows.Scan(&sampledAt, &s.Queued, &s.Running, &s.Workers, &s.Finished, &s.AvgLatencySeconds); err != nil {
+			return nil, err
+		}
+		s.SampledAt, _ = time.Parse(time.RFC3339, sampledAt)
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// PruneQueueSamples deletes queue samples recorded before the given time
+func (db *DB) PruneQueueSamples(before time.Time) (int64, error) {
+	result, err := db.Exec(`DELETE FROM queue_metrics WHERE sampled_at < ?`, before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueSamples(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/queue-metrics-repo")
+	for _, sha := range []string{"qm1", "qm2", "qm3"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		enqueueJob(t, db, repo.ID, commit.ID, sha)
+	}
+	done := claimJob(t, db, "worker-1")
+	claimJob(t, db, "worker-2")
+	if err := db.CompleteJob(t.Context(), done.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	// Enqueued two minutes before it finished
+	enqueued := time.Now().Add(-2 * time.Minute).Format(time.RFC3339)
+	if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ? WHERE id = ?`, enqueued, done.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	sample, err := db.RecordQueueSample(now, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("RecordQueueSample: %v", err)
+	}
+	if sample.Queued != 1 || sample.Running != 1 || sample.Workers != 2 || sample.Finished != 1 {
+		t.Errorf("unexpected sample %+v", sample)
+	}
+	if sample.AvgLatencySeconds < 115 || sample.AvgLatencySeconds > 125 {
+		t.Errorf("expected about 120s latency, got %v", sample.AvgLatencySeconds)
+	}
+	if sample.Saturated() {
+		t.Error("one of two workers idle should not be saturated")
+	}
+
+	// The finished job falls outside the window of a later sample
+	later, err := db.RecordQueueSample(now.Add(5*time.Minute), time.Minute, 1)
+	if err != nil {
+		t.Fatalf("RecordQueueSample: %v", err)
+	}
+	if later.Finished != 0 || later.AvgLatencySeconds != 0 {
+		t.Errorf("expected no finished jobs in later window, got %+v", later)
+	}
+	if !later.Saturated() {
+		t.Error("queued jobs with the only worker busy should be saturated")
+	}
+
+	samples, err := db.ListQueueSamples(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListQueueSamples: %v", err)
+	}
+	if len(samples) != 2 || samples[0] != sample || samples[1] != later {
+		t.Errorf("got %+v, want [%+v %+v]", samples, sample, later)
+	}
+
+	pruned, err := db.PruneQueueSamples(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PruneQueueSamples: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 sample pruned, got %d", pruned)
+	}
+	samples, _ = db.ListQueueSamples(time.Time{})
+	if len(samples) != 1 || samples[0] != later {
+		t.Errorf("expected only the later sample to remain, got %+v", samples)
+	}
+}
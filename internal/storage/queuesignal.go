@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// queueSignal wakes everyone waiting on it at once: each wait is on a
+// channel that the next notification closes.
+type queueSignal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func (s *queueSignal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+	return s.ch
+}
+
+func (s *queueSignal) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+}
+
+// QueueChanged returns a channel that is closed the next time jobs may have
+// become claimable. Take it before claiming, so a job queued while the claim
+// runs is not missed.
+func (db *DB) QueueChanged() <-chan struct{} {
+	return db.queued.wait()
+}
+
+// NotifyQueueChanged wakes those waiting on QueueChanged. Queueing, retrying
+// and requeueing jobs through this DB notify on their own; call it for
+// changes made elsewhere, such as by another process.
+func (db *DB) NotifyQueueChanged() {
+	db.queued.notify()
+}
+
+// NextHoldExpiry returns when the earliest hold on a queued job runs out, or
+// the zero time if no queued job is held
+func (db *DB) NextHoldExpiry(ctx context.Context) (time.Time, error) {
+	var holdUntil sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT MIN(datetime(hold_until)) FROM review_jobs
+		WHERE status = 'queued' AND hold_until IS NOT NULL
+	`).Scan(&holdUntil)
+	if err != nil || !holdUntil.Valid {
+		return time.Time{}, err
+	}
+	return parseSQLiteTime(holdUntil.String), nil
+}
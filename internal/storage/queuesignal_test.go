@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueChanged(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := createRepo(t, db, "/tmp/queue-signal")
+	commit := createCommit(t, db, repo.ID, "aaa")
+
+	notified := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	ch := db.QueueChanged()
+	if notified(ch) {
+		t.Fatal("queue reported changed before any job was queued")
+	}
+	job := enqueueJob(t, db, repo.ID, commit.ID, "aaa")
+	if !notified(ch) {
+		t.Error("enqueueing a job did not notify")
+	}
+
+	ch = db.QueueChanged()
+	claimJob(t, db, "w1")
+	if notified(ch) {
+		t.Error("claiming a job notified")
+	}
+	if retried, err := db.RetryJob(t.Context(), job.ID, 3); err != nil || !retried {
+		t.Fatalf("RetryJob: %v, %v", retried, err)
+	}
+	if !notified(ch) {
+		t.Error("retrying a job did not notify")
+	}
+
+	expiry, err := db.NextHoldExpiry(t.Context())
+	if err != nil || !expiry.IsZero() {
+		t.Errorf("expected no held jobs, got %v, %v", expiry, err)
+	}
+	holdUntil := time.Now().Add(time.Hour).Truncate(time.Second)
+	held, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, GitRef: "bbb", Agent: "test", HoldUntil: holdUntil})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	if expiry, err := db.NextHoldExpiry(t.Context()); err != nil || !expiry.Equal(holdUntil) {
+		t.Errorf("NextHoldExpiry = %v, %v, want %v", expiry, err, holdUntil)
+	}
+
+	// Bumping releases the hold
+	ch = db.QueueChanged()
+	if err := db.BumpJob(t.Context(), held.ID); err != nil {
+		t.Fatalf("BumpJob: %v", err)
+	}
+	if !notified(ch) {
+		t.Error("bumping a job did not notify")
+	}
+
+	// The bumped job is claimed first
+	if claimed := claimJob(t, db, "w1"); claimed.ID != held.ID {
+		t.Fatalf("claimed job %d, want %d", claimed.ID, held.ID)
+	}
+	if err := db.BlockJob(held.ID, "repo missing"); err != nil {
+		t.Fatalf("BlockJob: %v", err)
+	}
+	ch = db.QueueChanged()
+	if unblocked, err := db.UnblockJob(held.ID); err != nil || !unblocked {
+		t.Fatalf("UnblockJob: %v, %v", unblocked, err)
+	}
+	if !notified(ch) {
+		t.Error("unblocking a job did not notify")
+	}
+}
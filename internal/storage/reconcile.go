@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How a consolidated verdict was reached
+const (
+	ReconcileManual     = "manual"     // Chosen by a person
+	ReconcileTiebreaker = "tiebreaker" // Decided by a tie-breaker agent
+)
+
+// ErrInvalidReconciliation is returned for an unknown verdict or method
+var ErrInvalidReconciliation = errors.New("invalid reconciliation")
+
+// AgentVerdict is the verdict of an agent's latest completed standard
+// review of a git ref
+type AgentVerdict struct {
+	JobID      int64     `json:"job_id"`
+	Agent      string    `json:"agent"`
+	Model      string    `json:"model,omitempty"`
+	Verdict    string    `json:"verdict"` // "P" or "F"
+	Output     string    `json:"output"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Reconciliation is the consolidated verdict recorded for a git ref that
+// agents disagreed on. It takes precedence over the verdicts of individual
+// reviews.
+type Reconciliation struct {
+	RepoID          int64     `json:"repo_id"`
+	GitRef          string    `json:"git_ref"`
+	Verdict         string    `json:"verdict"` // "P" or "F"
+	Method          string    `json:"method"`  // manual or tiebreaker
+	TiebreakerAgent string    `json:"tiebreaker_agent,omitempty"`
+	TiebreakerJobID int64     `json:"tiebreaker_job_id,omitempty"`
+	Note            string    `json:"note,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// GetAgentVerdicts returns the verdict of each agent's latest completed
+// standard review of gitRef in a repo, in order of the agents' first review
+func (db *DB) GetAgentVerdicts(repoID int64, gitRef string) ([]AgentVerdict, error) {
+	rows, err := db.Query(`
+		SELECT j.id, j.agent, COALESCE(j.model, ''), rv.output, COALESCE(j.finished_at, rv.created_at)
+		FROM review_jobs j
+		JOIN reviews rv ON rv.job_id = j.id
+		WHERE j.repo_id = ? AND j.git_ref = ? AND j.status = 'done'
+		  AND j.job_type IN ('review', 'range')
+		  AND j.review_type IN ('', 'default')
+		ORDER BY j.id
+	`, repoID, gitRef)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var verdicts []AgentVerdict
+	latest := make(map[string]int) // agent -> index in verdicts
+	for rows.Next() {
+		var v AgentVerdict
+		var finishedAt string
+		if err := rows.Scan(&v.JobID, &v.Agent, &v.Model, &v.Output, &finishedAt); err != nil {
+			return nil, err
+		}
+		v.Verdict = db.outputVerdict(v.Output)
+		v.Output = db.loadBlob(v.Output)
+		v.FinishedAt = parseSQLiteTime(finishedAt)
+		if i, ok := latest[v.Agent]; ok {
+			verdicts[i] = v
+			continue
+		}
+		latest[v.Agent] = len(verdicts)
+		verdicts = append(verdicts, v)
+	}
+	return verdicts, rows.Err()
+}
+
+// SaveReconciliation records the consolidated verdict for a git ref,
+// replacing an earlier one
+func (db *DB) SaveReconciliation(r Reconciliation) error {
+	if r.Verdict != "P" && r.Verdict != "F" {
+		return fmt.Errorf("%w: verdict %q (valid: P, F)", ErrInvalidReconciliation, r.Verdict)
+	}
+	switch r.Method {
+	case ReconcileManual:
+		r.TiebreakerAgent, r.TiebreakerJobID = "", 0
+	case ReconcileTiebreaker:
+		if r.TiebreakerAgent == "" {
+			return fmt.Errorf("%w: tie-breaker agent is required", ErrInvalidReconciliation)
+		}
+	default:
+		return fmt.Errorf("%w: method %q (valid: %s, %s)", ErrInvalidReconciliation, r.Method,
+			ReconcileManual, ReconcileTiebreaker)
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO verdict_reconciliations (repo_id, git_ref, verdict, method, tiebreaker_agent, tiebreaker_job_id, note, created_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, ''), ?)
+		ON CONFLICT(repo_id, git_ref) DO UPDATE SET
+			verdict = excluded.verdict,
+			method = excluded.method,
+			tiebreaker_agent = excluded.tiebreaker_agent,
+			tiebreaker_job_id = excluded.tiebreaker_job_id,
+			note = excluded.note,
+			created_at = excluded.created_at
+	`, r.RepoID, r.GitRef, r.Verdict, r.Method, r.TiebreakerAgent, r.TiebreakerJobID,
+		strings.TrimSpace(r.Note), time.Now().Format(time.RFC3339))
+	return err
+}
+
+// GetReconciliation returns the consolidated verdict recorded for a git ref,
+// or nil if there is none
+func (db *DB) GetReconciliation(repoID int64, gitRef string) (*Reconciliation, error) {
+	r := Reconciliation{RepoID: repoID, GitRef: gitRef}
+	var agent, note sql.NullString
+	var jobID sql.NullInt64
+	var createdAt string
+	err := db.QueryRow(`
+		SELECT verdict, method, tiebreaker_agent, tiebreaker_job_id, note, created_at
+		FROM verdict_reconciliations
+		WHERE repo_id = ? AND git_ref = ?
+	`, repoID, gitRef).Scan(&r.Verdict, &r.Method, &agent, &jobID, &note, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.TiebreakerAgent = agent.String
+	r.TiebreakerJobID = jobID.Int64
+	r.Note = note.String
+	r.CreatedAt = parseSQLiteTime(createdAt)
+	return &r, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func completeReview(t *testing.T, db *DB, repoID, commitID int64, sha, agent, output string) *ReviewJob {
+	t.Helper()
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repoID, CommitID: commitID, GitRef: sha, Agent: agent})
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, agent, "prompt", output); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	return job
+}
+
+func TestAgentVerdictsAndReconciliation(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/reconcile-repo")
+	commit := createCommit(t, db, repo.ID, "rec1")
+	completeReview(t, db, repo.ID, commit.ID, "rec1", "codex", "- High: nil dereference")
+	completeReview(t, db, repo.ID, commit.ID, "rec1", "claude-code", "No issues found.")
+	// A rerun replaces the agent's earlier verdict
+	rerun := completeReview(t, db, repo.ID, commit.ID, "rec1", "codex", "- Low: naming")
+
+	verdicts, err := db.GetAgentVerdicts(repo.ID, "rec1")
+	if err != nil {
+		t.Fatalf("GetAgentVerdicts: %v", err)
+	}
+	if len(verdicts) != 2 {
+		t.Fatalf("expected one verdict per agent, got %+v", verdicts)
+	}
+	if verdicts[0].Agent != "codex" || verdicts[0].JobID != rerun.ID || verdicts[0].Verdict != "F" {
+		t.Errorf("unexpected codex verdict %+v", verdicts[0])
+	}
+	if verdicts[1].Agent != "claude-code" || verdicts[1].Verdict != "P" {
+		t.Errorf("unexpected claude-code verdict %+v", verdicts[1])
+	}
+
+	if r, err := db.GetReconciliation(repo.ID, "rec1"); err != nil || r != nil {
+		t.Fatalf("expected no reconciliation yet, got %+v, %v", r, err)
+	}
+	if v, _ := db.GetCommitVerdicts(repo.ID, []string{"rec1"}); v["rec1"].Verdict != "F" {
+		t.Errorf("expected latest review verdict before reconciling, got %+v", v["rec1"])
+	}
+
+	bad := []Reconciliation{
+		{RepoID: repo.ID, GitRef: "rec1", Verdict: "X", Method: ReconcileManual},
+		{RepoID: repo.ID, GitRef: "rec1", Verdict: "P", Method: "coin-flip"},
+		{RepoID: repo.ID, GitRef: "rec1", Verdict: "P", Method: ReconcileTiebreaker},
+	}
+	for _, r := range bad {
+		if err := db.SaveReconciliation(r); !errors.Is(err, ErrInvalidReconciliation) {
+			t.Errorf("SaveReconciliation(%+v) = %v, want ErrInvalidReconciliation", r, err)
+		}
+	}
+
+	err = db.SaveReconciliation(Reconciliation{
+		RepoID: repo.ID, GitRef: "rec1", Verdict: "P", Method: ReconcileTiebreaker,
+		TiebreakerAgent: "gemini", TiebreakerJobID: 42, Note: " naming nit only ",
+	})
+	if err != nil {
+		t.Fatalf("SaveReconciliation: %v", err)
+	}
+	r, err := db.GetReconciliation(repo.ID, "rec1")
+	if err != nil || r == nil {
+		t.Fatalf("GetReconciliation: %+v, %v", r, err)
+	}
+	if r.Verdict != "P" || r.Method != ReconcileTiebreaker || r.TiebreakerAgent != "gemini" ||
+		r.TiebreakerJobID != 42 || r.Note != "naming nit only" {
+		t.Errorf("unexpected reconciliation %+v", r)
+	}
+
+	if v, _ := db.GetCommitVerdicts(repo.ID, []string{"rec1"}); v["rec1"].Verdict != "P" {
+		t.Errorf("expected reconciled verdict to take precedence, got %+v", v["rec1"])
+	}
+	if st, _ := db.GetCommitStatus(t.Context(), "/tmp/reconcile-repo", "rec1"); st.Verdict != "P" {
+		t.Errorf("expected reconciled verdict in commit status, got %+v", st)
+	}
+
+	// Reconciling again replaces the earlier decision
+	if err := db.SaveReconciliation(Reconciliation{RepoID: repo.ID, GitRef: "rec1", Verdict: "F", Method: ReconcileManual}); err != nil {
+		t.Fatalf("SaveReconciliation: %v", err)
+	}
+	r, _ = db.GetReconciliation(repo.ID, "rec1")
+	if r.Verdict != "F" || r.Method != ReconcileManual || r.TiebreakerAgent != "" || r.Note != "" {
+		t.Errorf("expected manual reconciliation to replace the earlier one, got %+v", r)
+	}
+
+	if err := db.DeleteRepo(t.Context(), repo.ID, true); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM verdict_reconciliations`).Scan(&n)
+	if n != 0 {
+		t.Errorf("expected reconciliations to be deleted with the repo, got %d", n)
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"strings"
+	"time"
+)
+
+// relatedReviewScanLimit caps how many of a repo's most recent reviews are
+// searched for related findings
+const relatedReviewScanLimit = 200
+
+// RelatedReview is an earlier review with findings in files a new change
+// touches
+type RelatedReview struct {
+	ReviewID  int64     `json:"review_id"`
+	JobID     int64     `json:"job_id"`
+	GitRef    string    `json:"git_ref"`
+	Subject   string    `json:"subject,omitempty"`
+	Agent     string    `json:"agent"`
+	Verdict   string    `json:"verdict,omitempty"`
+	Addressed bool      `json:"addressed"`
+	CreatedAt time.Time `json:"created_at"`
+	Findings  []Finding `json:"findings"` // Only the findings in the touched files
+}
+
+// ListRelatedReviews returns up to limit of a repo's recent completed
+// reviews, newest first, whose findings refer to any of files. Findings
+// triaged as dismissed and suppressed ones are left out, as are reviews of
+// excludeGitRef (the change being reviewed) and task jobs.
+func (db *DB) ListRelatedReviews(repoID int64, files []string, excludeGitRef string, limit int) ([]RelatedReview, error) {
+	if len(files) == 0 || limit <= 0 {
+		return nil, nil
+	}
+	dismissed, err := db.triagedFindings(TriageDismissed)
+	if err != nil {
+		return nil, err
+	}
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT rv.id, rv.job_id, j.git_ref, COALESCE(c.subject, ''), rv.agent, rv.output, rv.addressed, rv.created_at,
+		       rp.root_path
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos rp ON rp.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id
+		WHERE j.repo_id = ? AND j.status = 'done' AND COALESCE(j.job_type, '') != 'task' AND j.git_ref != ?
+		ORDER BY rv.id DESC
+		LIMIT ?
+	`, repoID, excludeGitRef, relatedReviewScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var related []RelatedReview
+	for rows.Next() && len(related) < limit {
+		var r RelatedReview
+		var output, createdAt, repoPath string
+		var addressed int
+		if err := rows.Scan(&r.ReviewID, &r.JobID, &r.GitRef, &r.Subject, &r.Agent, &output, &addressed, &createdAt,
+			&repoPath); err != nil {
+			return nil, err
+		}
+		output = db.loadBlob(output)
+		for _, f := range ExtractFindings(output) {
+			key := triageKey{r.ReviewID, f.Index}
+			if _, ok := suppressed[key]; ok || dismissed[key] {
+				continue
+			}
+			if findingTouches(f, repoPath, files) {
+				r.Findings = append(r.Findings, f)
+			}
+		}
+		if len(r.Findings) == 0 {
+			continue
+		}
+		r.Verdict = ParseVerdict(output)
+		r.Addressed = addressed != 0
+		r.CreatedAt = parseSQLiteTime(createdAt)
+		related = append(related, r)
+	}
+	return related, rows.Err()
+}
+
+// findingTouches reports whether a finding refers to any of files. A
+// reference matches a file by its path or, since agents often name files
+// by their base name alone, by a trailing part of it.
+func findingTouches(f Finding, repoPath string, files []string) bool {
+	for _, ref := range ExtractFileRefs(f.Text, repoPath) {
+		for _, file := range files {
+			if ref.Path == file || strings.HasSuffix(file, "/"+ref.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
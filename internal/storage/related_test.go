@@ -0,0 +1,57 @@
+package storage
+
+import "testing"
+
+func TestListRelatedReviews(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/related-repo")
+	other := createRepo(t, db, "/tmp/related-other")
+	complete := func(repoID int64, sha, output string) *Review {
+		t.Helper()
+		commit := createCommit(t, db, repoID, sha)
+		job := enqueueJob(t, db, repoID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		return review
+	}
+
+	older := complete(repo.ID, "aaa", "- High: race condition in internal/db/pool.go:42")
+	dismissed := complete(repo.ID, "bbb", "- Medium: leaked handle in pool.go")
+	complete(repo.ID, "ccc", "- Low: typo in README.md")
+	complete(other.ID, "ddd", "- High: bug in internal/db/pool.go")
+	newest := complete(repo.ID, "eee", "- Low: naming in pool.go\n- High: unrelated issue in main.go")
+	if err := db.SetFindingTriage(dismissed.ID, 0, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+
+	related, err := db.ListRelatedReviews(repo.ID, []string{"internal/db/pool.go"}, "fff", 5)
+	if err != nil {
+		t.Fatalf("ListRelatedReviews: %v", err)
+	}
+	if len(related) != 2 || related[0].ReviewID != newest.ID || related[1].ReviewID != older.ID {
+		t.Fatalf("expected the newest and oldest reviews of the repo, got %+v", related)
+	}
+	if len(related[0].Findings) != 1 || related[0].Findings[0].Severity != "low" {
+		t.Errorf("only findings in the touched files should be kept, got %+v", related[0].Findings)
+	}
+	if related[1].GitRef != "aaa" || related[1].Verdict != "F" {
+		t.Errorf("unexpected related review %+v", related[1])
+	}
+
+	// The change being reviewed and the limit
+	related, _ = db.ListRelatedReviews(repo.ID, []string{"internal/db/pool.go"}, "eee", 1)
+	if len(related) != 1 || related[0].ReviewID != older.ID {
+		t.Errorf("expected only the older review, got %+v", related)
+	}
+	if related, _ := db.ListRelatedReviews(repo.ID, []string{"cmd/main_test.go"}, "", 5); len(related) != 0 {
+		t.Errorf("expected no reviews of untouched files, got %+v", related)
+	}
+}
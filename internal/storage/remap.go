@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RemapResult counts what RemapCommit moved to the rewritten commit
+type RemapResult struct {
+	OldSHA    string `json:"old_sha"`
+	NewSHA    string `json:"new_sha"`
+	Jobs      int    `json:"jobs"`
+	Responses int    `json:"responses"`
+}
+
+// RemapCommit moves the review work recorded for a commit that history
+// rewriting (such as an interactive rebase) replaced onto its rewritten
+// equivalent: its jobs with their reviews and findings, the responses to
+// it and its verdict reconciliation. The rewritten commit must already be
+// recorded, e.g. by GetOrCreateCommit.
+func (db *DB) RemapCommit(repoID int64, oldSHA string, newCommitID int64) (*RemapResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var oldID int64
+	err = tx.QueryRow(`SELECT id FROM commits WHERE repo_id = ? AND sha = ?`, repoID, oldSHA).Scan(&oldID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no reviews of commit %s", oldSHA)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &RemapResult{OldSHA: oldSHA}
+	if err := tx.QueryRow(`SELECT sha FROM commits WHERE id = ? AND repo_id = ?`, newCommitID, repoID).Scan(&result.NewSHA); err != nil {
+		return nil, fmt.Errorf("load rewritten commit: %w", err)
+	}
+	if oldID == newCommitID {
+		return nil, errors.New("a commit cannot be remapped onto itself")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := tx.Exec(`
+		UPDATE review_jobs
+		SET commit_id = ?, git_ref = CASE WHEN git_ref = ? THEN ? ELSE git_ref END, updated_at = ?
+		WHERE commit_id = ?
+	`, newCommitID, oldSHA, result.NewSHA, now, oldID)
+	if err != nil {
+		return nil, fmt.Errorf("move jobs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	result.Jobs = int(n)
+
+	res, err = tx.Exec(`UPDATE responses SET commit_id = ? WHERE commit_id = ?`, newCommitID, oldID)
+	if err != nil {
+		return nil, fmt.Errorf("move responses: %w", err)
+	}
+	n, _ = res.RowsAffected()
+	result.Responses = int(n)
+
+	// A reconciled verdict follows the reviews it reconciled, unless the
+	// rewritten commit has one of its own
+	if _, err := tx.Exec(`
+		UPDATE OR IGNORE verdict_reconciliations SET git_ref = ? WHERE repo_id = ? AND git_ref = ?
+	`, result.NewSHA, repoID, oldSHA); err != nil {
+		return nil, fmt.Errorf("move verdict reconciliation: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO commit_changes (commit_id, change_id)
+		SELECT ?, change_id FROM commit_changes WHERE commit_id = ?
+	`, newCommitID, oldID); err != nil {
+		return nil, fmt.Errorf("copy change ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindPatchCommits returns the other commits of a repo with the given
+// patch-id that have review jobs, most recently reviewed first
+func (db *DB) FindPatchCommits(repoID int64, patchID, excludeSHA string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT c.sha
+		FROM commits c
+		JOIN commit_patches cp ON cp.commit_id = c.id
+		JOIN review_jobs j ON j.commit_id = c.id
+		WHERE c.repo_id = ? AND cp.patch_id = ? AND c.sha != ?
+		GROUP BY c.id
+		ORDER BY MAX(j.id) DESC
+	`, repoID, patchID, excludeSHA)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shas []string
+	for rows.Next() {
+		var sha string
+		if err := rows.Scan(&sha); err != nil {
+			return nil, err
+		}
+		shas = append(shas, sha)
+	}
+	return shas, rows.Err()
+}
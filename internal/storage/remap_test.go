@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemapCommit(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/remap-repo")
+	old := createCommit(t, db, repo.ID, "old111")
+	job := enqueueJob(t, db, repo.ID, old.ID, "old111")
+	if _, err := db.ClaimJob(t.Context(), "worker-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "Found a bug"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AddComment(t.Context(), old.ID, "alice", "fixed in the next commit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetCommitPatchID(old.ID, "patch1"); err != nil {
+		t.Fatal(err)
+	}
+
+	rewritten, err := db.GetOrCreateCommit(t.Context(), repo.ID, "new222", "Author", "Subject", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shas, err := db.FindPatchCommits(repo.ID, "patch1", "new222"); err != nil || len(shas) != 1 || shas[0] != "old111" {
+		t.Fatalf("FindPatchCommits = %v, %v; want [old111]", shas, err)
+	}
+
+	result, err := db.RemapCommit(repo.ID, "old111", rewritten.ID)
+	if err != nil {
+		t.Fatalf("RemapCommit: %v", err)
+	}
+	if result.Jobs != 1 || result.Responses != 1 || result.NewSHA != "new222" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	review, err := db.GetReviewByCommitSHA(t.Context(), "new222")
+	if err != nil {
+		t.Fatalf("GetReviewByCommitSHA: %v", err)
+	}
+	if review.JobID != job.ID || review.Job.GitRef != "new222" {
+		t.Errorf("expected job %d on new222, got job %d on %s", job.ID, review.JobID, review.Job.GitRef)
+	}
+	responses, err := db.GetCommentsForCommit(t.Context(), rewritten.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 {
+		t.Errorf("expected the response to move, got %d", len(responses))
+	}
+
+	// The original has nothing left to remap or match
+	if shas, err := db.FindPatchCommits(repo.ID, "patch1", "new222"); err != nil || len(shas) != 0 {
+		t.Errorf("FindPatchCommits after remap = %v, %v; want none", shas, err)
+	}
+	if _, err := db.RemapCommit(repo.ID, "missing", rewritten.ID); err == nil {
+		t.Error("expected an error remapping an unknown commit")
+	}
+}
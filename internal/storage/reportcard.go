@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Finding categories of a report card, in the order they are matched
+const (
+	CategorySecurity      = "security"
+	CategoryConcurrency   = "concurrency"
+	CategoryResources     = "resource handling"
+	CategoryErrorHandling = "error handling"
+	CategoryPerformance   = "performance"
+	CategoryTesting       = "testing"
+	CategoryDocs          = "documentation"
+	CategoryOther         = "other"
+)
+
+// findingCategories are the keywords that put a finding in a category. A
+// finding goes in the first category with a keyword in its text.
+var findingCategories = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{CategorySecurity, regexp.MustCompile(`(?i)\b(security|injection|xss|csrf|ssrf|vulnerab\w*|secrets?|credentials?|passwords?|auth\w*|sanitiz\w*|traversal|privilege\w*)\b`)},
+	{CategoryConcurrency, regexp.MustCompile(`(?i)\b(race|races|racy|deadlock\w*|mutex\w*|locks?|locking|goroutines?|concurren\w*|threads?|atomic\w*|synchroniz\w*)\b`)},
+	{CategoryResources, regexp.MustCompile(`(?i)\b(leaks?|leaked|leaking|unclosed|(never|not) (closed|released)|file handles?|descriptors?)\b`)},
+	{CategoryErrorHandling, regexp.MustCompile(`(?i)\b(errors?|err|panics?|exceptions?|unchecked|swallow\w*|ignored|nil pointer|null pointer)\b`)},
+	{CategoryPerformance, regexp.MustCompile(`(?i)\b(performance|slow|inefficien\w*|quadratic|allocat\w*|n\+1|cach\w*|latency)\b`)},
+	{CategoryTesting, regexp.MustCompile(`(?i)\b(tests?|testing|coverage|assertions?)\b`)},
+	{CategoryDocs, regexp.MustCompile(`(?i)\b(comments?|docs?|documentation|readme|typos?)\b`)},
+}
+
+// FindingCategory returns the category of a finding's text, or
+// CategoryOther when no category's keywords appear in it
+func FindingCategory(text string) string {
+	for _, c := range findingCategories {
+		if c.pattern.MatchString(text) {
+			return c.name
+		}
+	}
+	return CategoryOther
+}
+
+// ReportCard summarizes the findings of the reviews of one author's commits
+type ReportCard struct {
+	Author     string          `json:"author"`
+	Since      time.Time       `json:"since"`
+	Commits    int             `json:"commits"`              // Commits with a completed review
+	Passed     int             `json:"passed"`               // Of those, commits whose review passed
+	Categories []CategoryCount `json:"categories,omitempty"` // Most findings first
+}
+
+// CategoryCount is the number of findings of a category, by severity
+type CategoryCount struct {
+	Category   string         `json:"category"`
+	Findings   int            `json:"findings"`
+	Severities map[string]int `json:"severities"`
+}
+
+// GetReportCard summarizes the reviews of the commits of an author, matched
+// under any of their aliases, that finished since the given time. Each
+// commit counts once, by its latest review. Findings dismissed in triage or
+// suppressed by .roborev-ignore are left out since they were judged not to
+// be problems.
+func (db *DB) GetReportCard(author string, since time.Time) (*ReportCard, error) {
+	dismissed, err := db.triagedFindings(TriageDismissed)
+	if err != nil {
+		return nil, err
+	}
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT rv.id, rv.output
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN commits c ON c.id = j.commit_id
+		WHERE j.status = 'done' AND COALESCE(j.job_type, '') != 'task'
+		  AND `+canonicalAuthor+` = `+canonicalAuthorArg+`
+		  AND julianday(rv.created_at) >= julianday(?)
+		  AND rv.id = (
+			SELECT MAX(rv2.id) FROM reviews rv2
+			JOIN review_jobs j2 ON j2.id = rv2.job_id
+			WHERE j2.commit_id = j.commit_id AND j2.status = 'done'
+		  )
+	`, author, author, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	card := &ReportCard{Author: author, Since: since}
+	counts := make(map[string]*CategoryCount)
+	for rows.Next() {
+		var reviewID int64
+		var output string
+		if err := rows.Scan(&reviewID, &output); err != nil {
+			return nil, err
+		}
+		output = db.loadBlob(output)
+		card.Commits++
+		if ParseVerdict(output) == "P" {
+			card.Passed++
+		}
+		for _, f := range ExtractFindings(output) {
+			key := triageKey{reviewID, f.Index}
+			if _, ok := suppressed[key]; ok || dismissed[key] {
+				continue
+			}
+			category := FindingCategory(f.Text)
+			c := counts[category]
+			if c == nil {
+				c = &CategoryCount{Category: category, Severities: make(map[string]int)}
+				counts[category] = c
+			}
+			c.Findings++
+			c.Severities[f.Severity]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, c := range counts {
+		card.Categories = append(card.Categories, *c)
+	}
+	sort.Slice(card.Categories, func(i, j int) bool {
+		a, b := card.Categories[i], card.Categories[j]
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return a.Category < b.Category
+	})
+	return card, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindingCategory(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"- High: SQL injection through the name parameter", CategorySecurity},
+		{"- Medium: Data race on the cache map between goroutines", CategoryConcurrency},
+		{"- Medium: File handle leaked when parsing fails", CategoryResources},
+		{"- Low: Error from Write is ignored", CategoryErrorHandling},
+		{"- Low: Quadratic loop over the commits", CategoryPerformance},
+		{"- Low: No tests for the new flag", CategoryTesting},
+		{"- Low: Typo in the README", CategoryDocs},
+		{"- Medium: Off-by-one in the page count", CategoryOther},
+		{"- Low: The clock is read twice", CategoryOther}, // "lock" only as a word
+	}
+	for _, tt := range tests {
+		if got := FindingCategory(tt.text); got != tt.want {
+			t.Errorf("FindingCategory(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGetReportCard(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/report-repo")
+	review := func(sha, author, output string) int64 {
+		t.Helper()
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, author, "Subject", time.Now())
+		if err != nil {
+			t.Fatalf("GetOrCreateCommit: %v", err)
+		}
+		enqueueJob(t, db, repo.ID, commit.ID, sha)
+		job := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		rv, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		return rv.ID
+	}
+
+	review("aaa111", "Jane Doe", "No issues found.")
+	dismissedID := review("bbb222", "Jane D", "- High: SQL injection in the query\n\n- Low: Error from Close is ignored")
+	review("ccc333", "Jane Doe", "- Medium: Error wrapped twice")
+	review("ddd444", "John Roe", "- Critical: Password logged in plain text")
+	if err := db.SetAuthorAlias("Jane D", "Jane Doe"); err != nil {
+		t.Fatalf("SetAuthorAlias: %v", err)
+	}
+	if err := db.SetFindingTriage(dismissedID, 0, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+
+	card, err := db.GetReportCard("Jane Doe", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetReportCard: %v", err)
+	}
+	if card.Commits != 3 || card.Passed != 1 {
+		t.Errorf("expected 3 commits with 1 passed, got %d with %d passed", card.Commits, card.Passed)
+	}
+	// The dismissed injection finding and John's findings are left out
+	if len(card.Categories) != 1 {
+		t.Fatalf("expected only error handling findings, got %+v", card.Categories)
+	}
+	c := card.Categories[0]
+	if c.Category != CategoryErrorHandling || c.Findings != 2 || c.Severities["low"] != 1 || c.Severities["medium"] != 1 {
+		t.Errorf("unexpected category count %+v", c)
+	}
+
+	card, err = db.GetReportCard("Jane Doe", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetReportCard: %v", err)
+	}
+	if card.Commits != 0 || len(card.Categories) != 0 {
+		t.Errorf("expected an empty card for a future start, got %+v", card)
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
 )
 
 // GetOrCreateRepo finds or creates a repo by its root path.
 // If identity is provided, it will be stored; otherwise the identity field remains NULL.
-func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error) {
+func (db *DB) GetOrCreateRepo(ctx context.Context, rootPath string, identity ...string) (*Repo, error) {
 	// Normalize path
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
@@ -28,7 +33,7 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 	var repo Repo
 	var createdAt string
 	var identityNullable sql.NullString
-	err = db.QueryRow(`SELECT id, root_path, name, identity, created_at FROM repos WHERE root_path = ?`, absPath).
+	err = db.QueryRowContext(ctx, `SELECT id, root_path, name, identity, created_at FROM repos WHERE root_path = ?`, absPath).
 		Scan(&repo.ID, &repo.RootPath, &repo.Name, &identityNullable, &createdAt)
 	if err == nil {
 		repo.Identity = identityNullable.String
@@ -36,7 +41,7 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 
 		// Update identity if provided and not already set
 		if repoIdentity != "" && repo.Identity == "" {
-			_, err = db.Exec(`UPDATE repos SET identity = ? WHERE id = ?`, repoIdentity, repo.ID)
+			_, err = db.ExecContext(ctx, `UPDATE repos SET identity = ? WHERE id = ?`, repoIdentity, repo.ID)
 			if err != nil {
 				return nil, fmt.Errorf("update identity: %w", err)
 			}
@@ -52,9 +57,9 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 	// same root_path (UNIQUE constraint). If the row already exists, re-read it.
 	name := filepath.Base(absPath)
 	if repoIdentity != "" {
-		_, err = db.Exec(`INSERT OR IGNORE INTO repos (root_path, name, identity) VALUES (?, ?, ?)`, absPath, name, repoIdentity)
+		_, err = db.ExecContext(ctx, `INSERT OR IGNORE INTO repos (root_path, name, identity) VALUES (?, ?, ?)`, absPath, name, repoIdentity)
 	} else {
-		_, err = db.Exec(`INSERT OR IGNORE INTO repos (root_path, name) VALUES (?, ?)`, absPath, name)
+		_, err = db.ExecContext(ctx, `INSERT OR IGNORE INTO repos (root_path, name) VALUES (?, ?)`, absPath, name)
 	}
 	if err != nil {
 		return nil, err
@@ -65,7 +70,7 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 	var created Repo
 	var createdAtStr string
 	var idNullable sql.NullString
-	err = db.QueryRow(`SELECT id, root_path, name, identity, created_at FROM repos WHERE root_path = ?`, absPath).
+	err = db.QueryRowContext(ctx, `SELECT id, root_path, name, identity, created_at FROM repos WHERE root_path = ?`, absPath).
 		Scan(&created.ID, &created.RootPath, &created.Name, &idNullable, &createdAtStr)
 	if err != nil {
 		return nil, fmt.Errorf("re-read repo after insert: %w", err)
@@ -75,7 +80,7 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 
 	// Update identity if provided and not already set
 	if repoIdentity != "" && created.Identity == "" {
-		_, err = db.Exec(`UPDATE repos SET identity = ? WHERE id = ?`, repoIdentity, created.ID)
+		_, err = db.ExecContext(ctx, `UPDATE repos SET identity = ? WHERE id = ?`, repoIdentity, created.ID)
 		if err != nil {
 			return nil, fmt.Errorf("update identity: %w", err)
 		}
@@ -85,8 +90,153 @@ func (db *DB) GetOrCreateRepo(rootPath string, identity ...string) (*Repo, error
 	return &created, nil
 }
 
+// GetOrCreateRepoFollowMoves is like GetOrCreateRepo, but when no repo is
+// registered at rootPath it first looks for a repo whose directory no longer
+// exists and that has the same identity or root commit. If exactly one is
+// found it is relocated to rootPath instead of creating a duplicate.
+// rootCommit is recorded on the repo so later moves can be detected even for
+// repos without a remote.
+func (db *DB) GetOrCreateRepoFollowMoves(rootPath, identity, rootCommit string) (*Repo, error) {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM repos WHERE root_path = ?)`, absPath).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		moved, err := db.FindMovedRepo(identity, rootCommit)
+		if err != nil {
+			return nil, err
+		}
+		if moved != nil {
+			log.Printf("storage: repo %q moved from %s to %s", moved.Name, moved.RootPath, absPath)
+			if err := db.RelocateRepo(moved.ID, absPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	repo, err := db.GetOrCreateRepo(context.Background(), absPath, identity)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.SetRepoRootCommit(repo.ID, rootCommit); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// SetRepoRootCommit records a repo's root commit if it isn't already known.
+func (db *DB) SetRepoRootCommit(repoID int64, rootCommit string) error {
+	if rootCommit == "" {
+		return nil
+	}
+	if _, err := db.Exec(`UPDATE repos SET root_commit = ? WHERE id = ? AND root_commit IS NULL`, rootCommit, repoID); err != nil {
+		return fmt.Errorf("set root commit: %w", err)
+	}
+	return nil
+}
+
+// FindMovedRepo returns the single repo whose root_path no longer exists on
+// disk and that matches either identity (when it's a remote URL or
+// .roborev-id, not a local:// path) or rootCommit. A root commit match is
+// ignored if both sides have different remote identities, since forks share
+// root commits. Returns nil if there is no match or the match is ambiguous.
+func (db *DB) FindMovedRepo(identity, rootCommit string) (*Repo, error) {
+	portable := identity != "" && !strings.HasPrefix(identity, "local://")
+	if !portable && rootCommit == "" {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT id, root_path, name, COALESCE(identity, ''), COALESCE(root_commit, ''), created_at
+		FROM repos
+		WHERE root_path != COALESCE(identity, '')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []Repo
+	for rows.Next() {
+		var r Repo
+		var repoRootCommit, createdAt string
+		if err := rows.Scan(&r.ID, &r.RootPath, &r.Name, &r.Identity, &repoRootCommit, &createdAt); err != nil {
+			return nil, err
+		}
+		r.CreatedAt = parseSQLiteTime(createdAt)
+
+		candidatePortable := r.Identity != "" && !strings.HasPrefix(r.Identity, "local://")
+		identityMatch := portable && r.Identity == identity
+		rootMatch := rootCommit != "" && repoRootCommit == rootCommit &&
+			!(portable && candidatePortable && r.Identity != identity)
+		if !identityMatch && !rootMatch {
+			continue
+		}
+		if _, err := os.Stat(r.RootPath); !os.IsNotExist(err) {
+			continue // still present (another clone), not a move
+		}
+		matches = append(matches, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(matches) != 1 {
+		return nil, nil
+	}
+	return &matches[0], nil
+}
+
+// RelocateRepo points a repo at a new root path, e.g. after the directory
+// was moved. If another repo is already registered at newPath (typically a
+// duplicate created after the move), its jobs are merged into repoID first.
+// A display name that was derived from the old directory name is updated,
+// as is a local:// identity.
+func (db *DB) RelocateRepo(repoID int64, newPath string) error {
+	absPath, err := filepath.Abs(newPath)
+	if err != nil {
+		return err
+	}
+	repo, err := db.GetRepoByID(context.Background(), repoID)
+	if err != nil {
+		return err
+	}
+	if repo.RootPath == absPath {
+		return nil
+	}
+
+	var dupID int64
+	err = db.QueryRow(`SELECT id FROM repos WHERE root_path = ?`, absPath).Scan(&dupID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		if _, err := db.MergeRepos(context.Background(), dupID, repoID); err != nil {
+			return fmt.Errorf("merge duplicate repo at %s: %w", absPath, err)
+		}
+	}
+
+	name := repo.Name
+	if name == filepath.Base(repo.RootPath) {
+		name = filepath.Base(absPath)
+	}
+	_, err = db.Exec(`
+		UPDATE repos SET root_path = ?, name = ?,
+			identity = CASE WHEN identity = ? THEN ? ELSE identity END
+		WHERE id = ?
+	`, absPath, name, "local://"+repo.RootPath, "local://"+absPath, repoID)
+	if err != nil {
+		return fmt.Errorf("relocate repo: %w", err)
+	}
+	return nil
+}
+
 // GetRepoByPath returns a repo by its path
-func (db *DB) GetRepoByPath(rootPath string) (*Repo, error) {
+func (db *DB) GetRepoByPath(ctx context.Context, rootPath string) (*Repo, error) {
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, err
@@ -94,7 +244,7 @@ func (db *DB) GetRepoByPath(rootPath string) (*Repo, error) {
 
 	var repo Repo
 	var createdAt string
-	err = db.QueryRow(`SELECT id, root_path, name, created_at FROM repos WHERE root_path = ?`, absPath).
+	err = db.QueryRowContext(ctx, `SELECT id, root_path, name, created_at FROM repos WHERE root_path = ?`, absPath).
 		Scan(&repo.ID, &repo.RootPath, &repo.Name, &createdAt)
 	if err != nil {
 		return nil, err
@@ -110,10 +260,33 @@ type RepoWithCount struct {
 	Count    int    `json:"count"`
 }
 
+// ListReposWithJobCounts returns all repos of store with their total job
+// counts, in one query on a DB and through GetRepoStats otherwise
+func ListReposWithJobCounts(ctx context.Context, store Storage) ([]RepoWithCount, int, error) {
+	if db, ok := store.(*DB); ok {
+		return db.ListReposWithReviewCounts(ctx)
+	}
+	list, err := store.ListRepos(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	repos := make([]RepoWithCount, 0, len(list))
+	total := 0
+	for _, repo := range list {
+		stats, err := store.GetRepoStats(ctx, repo.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		repos = append(repos, RepoWithCount{Name: repo.Name, RootPath: repo.RootPath, Count: stats.TotalJobs})
+		total += stats.TotalJobs
+	}
+	return repos, total, nil
+}
+
 // ListReposWithReviewCounts returns all repos with their total job counts
-func (db *DB) ListReposWithReviewCounts() ([]RepoWithCount, int, error) {
+func (db *DB) ListReposWithReviewCounts(ctx context.Context) ([]RepoWithCount, int, error) {
 	// Query repos with their job counts (includes queued/running, not just completed reviews)
-	rows, err := db.Query(`
+	rows, err := db.QueryContext(ctx, `
 		SELECT r.name, r.root_path, COUNT(rj.id) as job_count
 		FROM repos r
 		LEFT JOIN review_jobs rj ON rj.repo_id = r.id
@@ -140,13 +313,13 @@ func (db *DB) ListReposWithReviewCounts() ([]RepoWithCount, int, error) {
 
 // ListReposWithReviewCountsByBranch returns repos filtered by branch with their job counts
 // If branch is empty, returns all repos. Use "(none)" to filter for jobs without a branch.
-func (db *DB) ListReposWithReviewCountsByBranch(branch string) ([]RepoWithCount, int, error) {
+func (db *DB) ListReposWithReviewCountsByBranch(ctx context.Context, branch string) ([]RepoWithCount, int, error) {
 	var rows *sql.Rows
 	var err error
 
 	if branch == "" {
 		// No filter - return all repos
-		return db.ListReposWithReviewCounts()
+		return db.ListReposWithReviewCounts(ctx)
 	}
 
 	// Filter by branch (handle "(none)" as NULL/empty branch)
@@ -155,7 +328,7 @@ func (db *DB) ListReposWithReviewCountsByBranch(branch string) ([]RepoWithCount,
 		branchFilter = ""
 	}
 
-	rows, err = db.Query(`
+	rows, err = db.QueryContext(ctx, `
 		SELECT r.name, r.root_path, COUNT(rj.id) as job_count
 		FROM repos r
 		INNER JOIN review_jobs rj ON rj.repo_id = r.id
@@ -197,13 +370,13 @@ type BranchListResult struct {
 
 // ListBranchesWithCounts returns all branches with their job counts
 // If repoPaths is non-empty, filters to jobs in those repos only
-func (db *DB) ListBranchesWithCounts(repoPaths []string) (*BranchListResult, error) {
+func (db *DB) ListBranchesWithCounts(ctx context.Context, repoPaths []string) (*BranchListResult, error) {
 	var rows *sql.Rows
 	var err error
 
 	if len(repoPaths) == 0 {
 		// No repo filter - count branches across all repos
-		rows, err = db.Query(`
+		rows, err = db.QueryContext(ctx, `
 			SELECT COALESCE(NULLIF(branch, ''), '(none)') as branch_name, COUNT(*) as job_count
 			FROM review_jobs
 			GROUP BY branch_name
@@ -211,7 +384,7 @@ func (db *DB) ListBranchesWithCounts(repoPaths []string) (*BranchListResult, err
 		`)
 	} else if len(repoPaths) == 1 {
 		// Single repo filter
-		rows, err = db.Query(`
+		rows, err = db.QueryContext(ctx, `
 			SELECT COALESCE(NULLIF(rj.branch, ''), '(none)') as branch_name, COUNT(*) as job_count
 			FROM review_jobs rj
 			INNER JOIN repos r ON rj.repo_id = r.id
@@ -235,7 +408,7 @@ func (db *DB) ListBranchesWithCounts(repoPaths []string) (*BranchListResult, err
 			GROUP BY branch_name
 			ORDER BY job_count DESC, branch_name
 		`, strings.Join(placeholders, ","))
-		rows, err = db.Query(query, args...)
+		rows, err = db.QueryContext(ctx, query, args...)
 	}
 	if err != nil {
 		return nil, err
@@ -256,7 +429,7 @@ func (db *DB) ListBranchesWithCounts(repoPaths []string) (*BranchListResult, err
 	}
 
 	// Count actual NULL branches (not empty string or "(none)" sentinel)
-	if err := db.QueryRow("SELECT COUNT(*) FROM review_jobs WHERE branch IS NULL").Scan(&result.NullsRemaining); err != nil {
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM review_jobs WHERE branch IS NULL").Scan(&result.NullsRemaining); err != nil {
 		return nil, err
 	}
 
@@ -264,12 +437,12 @@ func (db *DB) ListBranchesWithCounts(repoPaths []string) (*BranchListResult, err
 }
 
 // RenameRepo updates the display name of a repo identified by its path or current name
-func (db *DB) RenameRepo(identifier, newName string) (int64, error) {
+func (db *DB) RenameRepo(ctx context.Context, identifier, newName string) (int64, error) {
 	// Try to match by root_path first (absolute or relative), then by name
 	absPath, _ := filepath.Abs(identifier)
 
 	// Try path match first
-	result, err := db.Exec(`UPDATE repos SET name = ? WHERE root_path = ?`, newName, absPath)
+	result, err := db.ExecContext(ctx, `UPDATE repos SET name = ? WHERE root_path = ?`, newName, absPath)
 	if err != nil {
 		return 0, err
 	}
@@ -279,7 +452,7 @@ func (db *DB) RenameRepo(identifier, newName string) (int64, error) {
 	}
 
 	// Try name match
-	result, err = db.Exec(`UPDATE repos SET name = ? WHERE name = ?`, newName, identifier)
+	result, err = db.ExecContext(ctx, `UPDATE repos SET name = ? WHERE name = ?`, newName, identifier)
 	if err != nil {
 		return 0, err
 	}
@@ -288,8 +461,8 @@ func (db *DB) RenameRepo(identifier, newName string) (int64, error) {
 }
 
 // ListRepos returns all repos in the database
-func (db *DB) ListRepos() ([]Repo, error) {
-	rows, err := db.Query(`SELECT id, root_path, name, created_at FROM repos ORDER BY name`)
+func (db *DB) ListRepos(ctx context.Context) ([]Repo, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, root_path, name, created_at FROM repos ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -309,10 +482,10 @@ func (db *DB) ListRepos() ([]Repo, error) {
 }
 
 // GetRepoByID returns a repo by its ID
-func (db *DB) GetRepoByID(id int64) (*Repo, error) {
+func (db *DB) GetRepoByID(ctx context.Context, id int64) (*Repo, error) {
 	var repo Repo
 	var createdAt string
-	err := db.QueryRow(`SELECT id, root_path, name, created_at FROM repos WHERE id = ?`, id).
+	err := db.QueryRowContext(ctx, `SELECT id, root_path, name, created_at FROM repos WHERE id = ?`, id).
 		Scan(&repo.ID, &repo.RootPath, &repo.Name, &createdAt)
 	if err != nil {
 		return nil, err
@@ -322,10 +495,10 @@ func (db *DB) GetRepoByID(id int64) (*Repo, error) {
 }
 
 // GetRepoByName returns a repo by its display name
-func (db *DB) GetRepoByName(name string) (*Repo, error) {
+func (db *DB) GetRepoByName(ctx context.Context, name string) (*Repo, error) {
 	var repo Repo
 	var createdAt string
-	err := db.QueryRow(`SELECT id, root_path, name, created_at FROM repos WHERE name = ?`, name).
+	err := db.QueryRowContext(ctx, `SELECT id, root_path, name, created_at FROM repos WHERE name = ?`, name).
 		Scan(&repo.ID, &repo.RootPath, &repo.Name, &createdAt)
 	if err != nil {
 		return nil, err
@@ -335,10 +508,10 @@ func (db *DB) GetRepoByName(name string) (*Repo, error) {
 }
 
 // FindRepo finds a repo by path or name (tries path first, then name)
-func (db *DB) FindRepo(identifier string) (*Repo, error) {
+func (db *DB) FindRepo(ctx context.Context, identifier string) (*Repo, error) {
 	// Try by path first
 	absPath, _ := filepath.Abs(identifier)
-	repo, err := db.GetRepoByPath(absPath)
+	repo, err := db.GetRepoByPath(ctx, absPath)
 	if err == nil {
 		return repo, nil
 	}
@@ -347,7 +520,7 @@ func (db *DB) FindRepo(identifier string) (*Repo, error) {
 	}
 
 	// Try by name
-	repo, err = db.GetRepoByName(identifier)
+	repo, err = db.GetRepoByName(ctx, identifier)
 	if err != nil {
 		return nil, err
 	}
@@ -362,6 +535,8 @@ type RepoStats struct {
 	RunningJobs        int
 	CompletedJobs      int
 	FailedJobs         int
+	SkippedJobs        int // Commits intentionally not reviewed (skip marker, bot author or sampling)
+	SampledOutJobs     int // Skipped commits left unreviewed by the repo's sampling policy
 	PassedReviews      int
 	FailedReviews      int
 	AddressedReviews   int
@@ -369,8 +544,8 @@ type RepoStats struct {
 }
 
 // GetRepoStats returns detailed statistics for a repo
-func (db *DB) GetRepoStats(repoID int64) (*RepoStats, error) {
-	repo, err := db.GetRepoByID(repoID)
+func (db *DB) GetRepoStats(ctx context.Context, repoID int64) (*RepoStats, error) {
+	repo, err := db.GetRepoByID(ctx, repoID)
 	if err != nil {
 		return nil, err
 	}
@@ -378,36 +553,25 @@ func (db *DB) GetRepoStats(repoID int64) (*RepoStats, error) {
 	stats := &RepoStats{Repo: repo}
 
 	// Get job counts by status
-	rows, err := db.Query(`
-		SELECT status, COUNT(*) FROM review_jobs WHERE repo_id = ? GROUP BY status
-	`, repoID)
+	counts, err := db.CountJobs(ctx, CountByStatus, JobCountFilter{RepoID: repoID})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var status string
-		var count int
-		if err := rows.Scan(&status, &count); err != nil {
+	stats.TotalJobs = counts.Total()
+	stats.QueuedJobs = counts.Status(JobStatusQueued)
+	stats.RunningJobs = counts.Status(JobStatusRunning)
+	stats.CompletedJobs = counts.Status(JobStatusDone)
+	stats.FailedJobs = counts.Status(JobStatusFailed)
+	stats.SkippedJobs = counts.Status(JobStatusSkipped)
+	if stats.SkippedJobs > 0 {
+		if stats.SampledOutJobs, err = db.countSampledOutJobs(repoID); err != nil {
 			return nil, err
 		}
-		stats.TotalJobs += count
-		switch JobStatus(status) {
-		case JobStatusQueued:
-			stats.QueuedJobs = count
-		case JobStatusRunning:
-			stats.RunningJobs = count
-		case JobStatusDone:
-			stats.CompletedJobs = count
-		case JobStatusFailed:
-			stats.FailedJobs = count
-		}
 	}
 
 	// Get review verdict counts (P/F from output)
 	// Exclude prompt jobs (commit_id IS NULL AND git_ref = 'prompt') from verdict stats
-	err = db.QueryRow(`
+	err = db.QueryRowContext(ctx, `
 		SELECT
 			COALESCE(SUM(CASE WHEN r.output LIKE '%**Verdict: PASS%' OR r.output LIKE '%Verdict: PASS%' THEN 1 ELSE 0 END), 0),
 			COALESCE(SUM(CASE WHEN r.output LIKE '%**Verdict: FAIL%' OR r.output LIKE '%Verdict: FAIL%' THEN 1 ELSE 0 END), 0),
@@ -431,110 +595,136 @@ var ErrRepoHasJobs = errors.New("repository has existing jobs; use cascade to de
 // DeleteRepo deletes a repo and optionally its associated data
 // If cascade is true, also deletes all jobs, reviews, and responses for the repo
 // If cascade is false and jobs exist, returns ErrRepoHasJobs
-func (db *DB) DeleteRepo(repoID int64, cascade bool) error {
+// The deleted rows are kept in the trash for the default retention window.
+func (db *DB) DeleteRepo(ctx context.Context, repoID int64, cascade bool) error {
+	_, err := db.TrashRepo(ctx, repoID, cascade, config.DefaultTrashRetention)
+	return err
+}
+
+// repoDeleteSteps lists the tables a cascading repo delete removes rows
+// from, in order due to foreign keys. Each condition takes the repo ID.
+var repoDeleteSteps = []trashStep{
+	// 1. Responses for jobs in this repo, then legacy commit-based ones
+	{"responses", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"responses", `commit_id IN (SELECT id FROM commits WHERE repo_id = ?)`},
+
+	// 2. Triage decisions, suppressions, filed issues and reviews
+	{"finding_triage", `review_id IN (
+		SELECT rv.id FROM reviews rv JOIN review_jobs j ON j.id = rv.job_id WHERE j.repo_id = ?
+	)`},
+	{"finding_suppressions", `review_id IN (
+		SELECT rv.id FROM reviews rv JOIN review_jobs j ON j.id = rv.job_id WHERE j.repo_id = ?
+	)`},
+	{"finding_issues", `repo_id = ?`},
+	{"reviews", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+
+	// 3. Captured environments, token usage, pre-reviews, changed symbols,
+	// finding checks, commit message suggestions, checklist results, share
+	// links, SLA breaches, fan-out links, group memberships, routes, human
+	// review imports, design docs, CI batch links, failed attempts, dead
+	// letters and the jobs themselves
+	{"job_env", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_usage", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"pre_reviews", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_symbols", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"finding_checks", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"commit_message_suggestions", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"checklist_results", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_deps", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"share_links", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"sla_breaches", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"injection_risks", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_parts", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_group_jobs", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_routes", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"human_review_imports", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_context_docs", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"ci_pr_batch_jobs", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"job_failures", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"dead_letters", `job_id IN (SELECT id FROM review_jobs WHERE repo_id = ?)`},
+	{"review_jobs", `repo_id = ?`},
+
+	// 4. Commits, their change and patch IDs, reconciled verdicts and tracked
+	// branches
+	{"commit_changes", `commit_id IN (SELECT id FROM commits WHERE repo_id = ?)`},
+	{"commit_patches", `commit_id IN (SELECT id FROM commits WHERE repo_id = ?)`},
+	{"commits", `repo_id = ?`},
+	{"verdict_reconciliations", `repo_id = ?`},
+	{"repo_branches", `repo_id = ?`},
+}
+
+// TrashRepo deletes a repo like DeleteRepo and moves the deleted rows to
+// the trash, where UndoTrash can restore them until retention passes.
+// Returns the trash operation recording the delete.
+func (db *DB) TrashRepo(ctx context.Context, repoID int64, cascade bool, retention time.Duration) (*TrashOperation, error) {
 	// Use a dedicated connection with BEGIN IMMEDIATE for proper locking
 	// This ensures no job can be enqueued between the count check and delete
-	ctx := context.Background()
 	conn, err := db.Conn(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer conn.Close()
 
 	// BEGIN IMMEDIATE acquires a write lock immediately, preventing races
 	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Ensure rollback on error
 	committed := false
 	defer func() {
 		if !committed {
-			conn.ExecContext(ctx, "ROLLBACK")
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
 		}
 	}()
 
+	var name string
+	err = conn.QueryRowContext(ctx, `SELECT name FROM repos WHERE id = ?`, repoID).Scan(&name)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check for existing jobs (within transaction for consistency)
 	var jobCount int
 	err = conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM review_jobs WHERE repo_id = ?`, repoID).Scan(&jobCount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !cascade && jobCount > 0 {
-		return ErrRepoHasJobs
+		return nil, ErrRepoHasJobs
 	}
 
-	if cascade {
-		// Delete in correct order due to foreign keys
-		// 1a. Delete responses for jobs in this repo (job_id based)
-		_, err := conn.ExecContext(ctx, `
-			DELETE FROM responses WHERE job_id IN (
-				SELECT id FROM review_jobs WHERE repo_id = ?
-			)
-		`, repoID)
-		if err != nil {
-			return err
-		}
-
-		// 1b. Delete responses for commits in this repo (legacy commit_id based)
-		_, err = conn.ExecContext(ctx, `
-			DELETE FROM responses WHERE commit_id IN (
-				SELECT id FROM commits WHERE repo_id = ?
-			)
-		`, repoID)
-		if err != nil {
-			return err
-		}
-
-		// 2. Delete reviews for jobs in this repo
-		_, err = conn.ExecContext(ctx, `
-			DELETE FROM reviews WHERE job_id IN (
-				SELECT id FROM review_jobs WHERE repo_id = ?
-			)
-		`, repoID)
-		if err != nil {
-			return err
-		}
-
-		// 3. Delete jobs for this repo
-		_, err = conn.ExecContext(ctx, `DELETE FROM review_jobs WHERE repo_id = ?`, repoID)
-		if err != nil {
-			return err
-		}
+	op, err := beginTrash(ctx, conn, "repo delete", fmt.Sprintf("repository %q with %d job(s)", name, jobCount), retention)
+	if err != nil {
+		return nil, err
+	}
 
-		// 4. Delete commits for this repo
-		_, err = conn.ExecContext(ctx, `DELETE FROM commits WHERE repo_id = ?`, repoID)
+	// Without jobs, the job steps find nothing; the repo's commits and
+	// branches still go with it
+	steps := append(repoDeleteSteps[:len(repoDeleteSteps):len(repoDeleteSteps)], trashStep{"repos", `id = ?`})
+	for _, step := range steps {
+		n, err := moveToTrash(ctx, conn, op.ID, step, repoID)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("delete from %s: %w", step.table, err)
 		}
-	}
-
-	// Delete the repo itself
-	result, err := conn.ExecContext(ctx, `DELETE FROM repos WHERE id = ?`, repoID)
-	if err != nil {
-		return err
-	}
-	affected, _ := result.RowsAffected()
-	if affected == 0 {
-		return sql.ErrNoRows
+		op.Rows += n
 	}
 
 	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
-		return err
+		return nil, err
 	}
 	committed = true
-	return nil
+	return op, nil
 }
 
 // MergeRepos moves all jobs and commits from sourceRepoID to targetRepoID, then deletes the source repo
-func (db *DB) MergeRepos(sourceRepoID, targetRepoID int64) (int64, error) {
+func (db *DB) MergeRepos(ctx context.Context, sourceRepoID, targetRepoID int64) (int64, error) {
 	if sourceRepoID == targetRepoID {
 		return 0, nil
 	}
 
 	// Use a dedicated connection with BEGIN IMMEDIATE for proper locking
-	ctx := context.Background()
 	conn, err := db.Conn(ctx)
 	if err != nil {
 		return 0, err
@@ -548,13 +738,48 @@ func (db *DB) MergeRepos(sourceRepoID, targetRepoID int64) (int64, error) {
 	committed := false
 	defer func() {
 		if !committed {
-			conn.ExecContext(ctx, "ROLLBACK")
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
 		}
 	}()
 
-	// Move all commits from source to target
-	// Note: commits.sha is UNIQUE, so this will fail if both repos have
-	// commits with the same SHA (which shouldn't happen for the same git repo)
+	// Commits are UNIQUE(repo_id, sha). Where both repos already have a row
+	// for the same SHA (e.g. a duplicate repo created after a directory move
+	// re-reviewed an old commit), repoint jobs and legacy responses to the
+	// target's row and drop the source's copy before moving the rest.
+	for _, table := range []string{"review_jobs", "responses"} {
+		_, err = conn.ExecContext(ctx, `
+			UPDATE `+table+` SET commit_id = (
+				SELECT t.id FROM commits s JOIN commits t ON t.sha = s.sha AND t.repo_id = ?
+				WHERE s.id = `+table+`.commit_id
+			)
+			WHERE commit_id IN (
+				SELECT s.id FROM commits s JOIN commits t ON t.sha = s.sha AND t.repo_id = ?
+				WHERE s.repo_id = ?
+			)
+		`, targetRepoID, targetRepoID, sourceRepoID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, table := range []string{"commit_changes", "commit_patches"} {
+		_, err = conn.ExecContext(ctx, `
+			DELETE FROM `+table+` WHERE commit_id IN (
+				SELECT s.id FROM commits s JOIN commits t ON t.sha = s.sha AND t.repo_id = ?
+				WHERE s.repo_id = ?
+			)
+		`, targetRepoID, sourceRepoID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err = conn.ExecContext(ctx, `
+		DELETE FROM commits WHERE repo_id = ? AND sha IN (SELECT sha FROM commits WHERE repo_id = ?)
+	`, sourceRepoID, targetRepoID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Move the remaining commits from source to target
 	// Commit-based responses (legacy) are tied to commit_id which remains valid
 	_, err = conn.ExecContext(ctx, `UPDATE commits SET repo_id = ? WHERE repo_id = ?`, targetRepoID, sourceRepoID)
 	if err != nil {
@@ -568,7 +793,23 @@ func (db *DB) MergeRepos(sourceRepoID, targetRepoID int64) (int64, error) {
 	}
 	affected, _ := result.RowsAffected()
 
-	// Delete the source repo (now empty)
+	// Filed issues and reconciled verdicts move too; where the target has
+	// already reconciled the same ref, its verdict stands
+	if _, err = conn.ExecContext(ctx, `UPDATE finding_issues SET repo_id = ? WHERE repo_id = ?`, targetRepoID, sourceRepoID); err != nil {
+		return 0, err
+	}
+	if _, err = conn.ExecContext(ctx, `UPDATE OR IGNORE verdict_reconciliations SET repo_id = ? WHERE repo_id = ?`, targetRepoID, sourceRepoID); err != nil {
+		return 0, err
+	}
+	if _, err = conn.ExecContext(ctx, `DELETE FROM verdict_reconciliations WHERE repo_id = ?`, sourceRepoID); err != nil {
+		return 0, err
+	}
+
+	// Delete the source repo (now empty). Its tracked branches describe the
+	// same repo as the target's, so they are dropped.
+	if _, err = conn.ExecContext(ctx, `DELETE FROM repo_branches WHERE repo_id = ?`, sourceRepoID); err != nil {
+		return 0, err
+	}
 	_, err = conn.ExecContext(ctx, `DELETE FROM repos WHERE id = ?`, sourceRepoID)
 	if err != nil {
 		return 0, err
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -53,7 +54,7 @@ func TestEnqueuePromptJob(t *testing.T) {
 	t.Run("claimed job has prompt loaded", func(t *testing.T) {
 		// Drain any existing queued jobs first
 		for {
-			job, err := db.ClaimJob("drain-worker")
+			job, err := db.ClaimJob(t.Context(), "drain-worker")
 			if err != nil {
 				t.Fatalf("ClaimJob failed during drain: %v", err)
 			}
@@ -160,13 +161,13 @@ func TestEnqueuePromptJob(t *testing.T) {
 		claimJob(t, db, "test-worker")
 
 		agentOutput := "No issues found."
-		err := db.CompleteJob(job.ID, "test", "Test prompt", agentOutput)
+		err := db.CompleteJob(t.Context(), job.ID, "test", "Test prompt", agentOutput)
 		if err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
 
 		// Fetch the review and verify prefix was prepended
-		review, err := db.GetReviewByJobID(job.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -194,13 +195,13 @@ func TestEnqueuePromptJob(t *testing.T) {
 		claimJob(t, db, "test-worker")
 
 		agentOutput := "Analysis complete."
-		err := db.CompleteJob(job.ID, "test", "Test prompt", agentOutput)
+		err := db.CompleteJob(t.Context(), job.ID, "test", "Test prompt", agentOutput)
 		if err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
 
 		// Fetch the review and verify output is unchanged
-		review, err := db.GetReviewByJobID(job.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -288,7 +289,7 @@ func TestRenameRepo(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/rename-test")
 
 	t.Run("rename by path", func(t *testing.T) {
-		affected, err := db.RenameRepo("/tmp/rename-test", "new-name")
+		affected, err := db.RenameRepo(t.Context(), "/tmp/rename-test", "new-name")
 		if err != nil {
 			t.Fatalf("RenameRepo failed: %v", err)
 		}
@@ -297,7 +298,7 @@ func TestRenameRepo(t *testing.T) {
 		}
 
 		// Verify the rename
-		updated, err := db.GetRepoByID(repo.ID)
+		updated, err := db.GetRepoByID(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoByID failed: %v", err)
 		}
@@ -307,7 +308,7 @@ func TestRenameRepo(t *testing.T) {
 	})
 
 	t.Run("rename by name", func(t *testing.T) {
-		affected, err := db.RenameRepo("new-name", "another-name")
+		affected, err := db.RenameRepo(t.Context(), "new-name", "another-name")
 		if err != nil {
 			t.Fatalf("RenameRepo failed: %v", err)
 		}
@@ -315,7 +316,7 @@ func TestRenameRepo(t *testing.T) {
 			t.Errorf("Expected 1 affected row, got %d", affected)
 		}
 
-		updated, err := db.GetRepoByID(repo.ID)
+		updated, err := db.GetRepoByID(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoByID failed: %v", err)
 		}
@@ -325,7 +326,7 @@ func TestRenameRepo(t *testing.T) {
 	})
 
 	t.Run("rename nonexistent repo returns 0", func(t *testing.T) {
-		affected, err := db.RenameRepo("nonexistent", "something")
+		affected, err := db.RenameRepo(t.Context(), "nonexistent", "something")
 		if err != nil {
 			t.Fatalf("RenameRepo failed: %v", err)
 		}
@@ -340,7 +341,7 @@ func TestListRepos(t *testing.T) {
 	defer db.Close()
 
 	t.Run("empty database", func(t *testing.T) {
-		repos, err := db.ListRepos()
+		repos, err := db.ListRepos(t.Context())
 		if err != nil {
 			t.Fatalf("ListRepos failed: %v", err)
 		}
@@ -354,7 +355,7 @@ func TestListRepos(t *testing.T) {
 	createRepo(t, db, "/tmp/repo-b")
 
 	t.Run("lists repos in order", func(t *testing.T) {
-		repos, err := db.ListRepos()
+		repos, err := db.ListRepos(t.Context())
 		if err != nil {
 			t.Fatalf("ListRepos failed: %v", err)
 		}
@@ -375,7 +376,7 @@ func TestGetRepoByID(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/getbyid-test")
 
 	t.Run("found", func(t *testing.T) {
-		found, err := db.GetRepoByID(repo.ID)
+		found, err := db.GetRepoByID(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoByID failed: %v", err)
 		}
@@ -388,7 +389,7 @@ func TestGetRepoByID(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := db.GetRepoByID(99999)
+		_, err := db.GetRepoByID(t.Context(), 99999)
 		if err == nil {
 			t.Error("Expected error for nonexistent ID")
 		}
@@ -405,7 +406,7 @@ func TestGetRepoByName(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/getbyname-test")
 
 	t.Run("found", func(t *testing.T) {
-		found, err := db.GetRepoByName("getbyname-test")
+		found, err := db.GetRepoByName(t.Context(), "getbyname-test")
 		if err != nil {
 			t.Fatalf("GetRepoByName failed: %v", err)
 		}
@@ -415,7 +416,7 @@ func TestGetRepoByName(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := db.GetRepoByName("nonexistent")
+		_, err := db.GetRepoByName(t.Context(), "nonexistent")
 		if err == nil {
 			t.Error("Expected error for nonexistent name")
 		}
@@ -429,7 +430,7 @@ func TestFindRepo(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/findrepo-test")
 
 	t.Run("find by path", func(t *testing.T) {
-		found, err := db.FindRepo("/tmp/findrepo-test")
+		found, err := db.FindRepo(t.Context(), "/tmp/findrepo-test")
 		if err != nil {
 			t.Fatalf("FindRepo by path failed: %v", err)
 		}
@@ -439,7 +440,7 @@ func TestFindRepo(t *testing.T) {
 	})
 
 	t.Run("find by name", func(t *testing.T) {
-		found, err := db.FindRepo("findrepo-test")
+		found, err := db.FindRepo(t.Context(), "findrepo-test")
 		if err != nil {
 			t.Fatalf("FindRepo by name failed: %v", err)
 		}
@@ -449,7 +450,7 @@ func TestFindRepo(t *testing.T) {
 	})
 
 	t.Run("created_at is populated", func(t *testing.T) {
-		found, err := db.FindRepo("/tmp/findrepo-test")
+		found, err := db.FindRepo(t.Context(), "/tmp/findrepo-test")
 		if err != nil {
 			t.Fatalf("FindRepo failed: %v", err)
 		}
@@ -463,7 +464,7 @@ func TestFindRepo(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := db.FindRepo("nonexistent")
+		_, err := db.FindRepo(t.Context(), "nonexistent")
 		if err == nil {
 			t.Error("Expected error for nonexistent repo")
 		}
@@ -477,7 +478,7 @@ func TestGetRepoStats(t *testing.T) {
 	repo := createRepo(t, db, "/tmp/stats-test")
 
 	t.Run("empty repo", func(t *testing.T) {
-		stats, err := db.GetRepoStats(repo.ID)
+		stats, err := db.GetRepoStats(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoStats failed: %v", err)
 		}
@@ -501,24 +502,24 @@ func TestGetRepoStats(t *testing.T) {
 
 	// Complete job1 with PASS verdict
 	claimJob(t, db, "worker-1")
-	if err := db.CompleteJob(job1.ID, "codex", "prompt", "**Verdict: PASS**\nLooks good!"); err != nil {
+	if err := db.CompleteJob(t.Context(), job1.ID, "codex", "prompt", "**Verdict: PASS**\nLooks good!"); err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Complete job2 with FAIL verdict
 	claimJob(t, db, "worker-1")
-	if err := db.CompleteJob(job2.ID, "codex", "prompt", "**Verdict: FAIL**\nIssues found."); err != nil {
+	if err := db.CompleteJob(t.Context(), job2.ID, "codex", "prompt", "**Verdict: FAIL**\nIssues found."); err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Fail job3
 	claimJob(t, db, "worker-1")
-	if err := db.FailJob(job3.ID, "agent error"); err != nil {
+	if err := db.FailJob(t.Context(), job3.ID, "agent error"); err != nil {
 		t.Fatalf("FailJob failed: %v", err)
 	}
 
 	t.Run("stats with jobs", func(t *testing.T) {
-		stats, err := db.GetRepoStats(repo.ID)
+		stats, err := db.GetRepoStats(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoStats failed: %v", err)
 		}
@@ -548,15 +549,15 @@ func TestGetRepoStats(t *testing.T) {
 
 	t.Run("addressed reviews counted", func(t *testing.T) {
 		// Mark job1's review as addressed
-		review, err := db.GetReviewByJobID(job1.ID)
+		review, err := db.GetReviewByJobID(t.Context(), job1.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
-		if err := db.MarkReviewAddressed(review.ID, true); err != nil {
+		if err := db.MarkReviewAddressed(t.Context(), review.ID, true); err != nil {
 			t.Fatalf("MarkReviewAddressed failed: %v", err)
 		}
 
-		stats, err := db.GetRepoStats(repo.ID)
+		stats, err := db.GetRepoStats(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoStats failed: %v", err)
 		}
@@ -569,7 +570,7 @@ func TestGetRepoStats(t *testing.T) {
 	})
 
 	t.Run("nonexistent repo", func(t *testing.T) {
-		_, err := db.GetRepoStats(99999)
+		_, err := db.GetRepoStats(t.Context(), 99999)
 		if err == nil {
 			t.Error("Expected error for nonexistent repo ID")
 		}
@@ -585,16 +586,16 @@ func TestGetRepoStats(t *testing.T) {
 		commit := createCommit(t, db, repo.ID, "stats-prompt-sha1")
 		job1 := enqueueJob(t, db, repo.ID, commit.ID, "stats-prompt-sha1")
 		claimJob(t, db, "worker-1")
-		db.CompleteJob(job1.ID, "codex", "prompt", "**Verdict: PASS**\nLooks good!")
+		db.CompleteJob(t.Context(), job1.ID, "codex", "prompt", "**Verdict: PASS**\nLooks good!")
 
 		// Create a prompt job with output that contains verdict-like text
 		promptJob := mustEnqueuePromptJob(t, db, EnqueueOpts{RepoID: repo.ID, Agent: "codex", Prompt: "Test prompt"})
 		claimJob(t, db, "worker-1")
 		// This has FAIL verdict text but should NOT count toward failed reviews
-		db.CompleteJob(promptJob.ID, "codex", "prompt", "**Verdict: FAIL**\nSome issues found")
+		db.CompleteJob(t.Context(), promptJob.ID, "codex", "prompt", "**Verdict: FAIL**\nSome issues found")
 
 		// Get stats - prompt job should be excluded from verdict counts
-		stats, err := db.GetRepoStats(repo.ID)
+		stats, err := db.GetRepoStats(t.Context(), repo.ID)
 		if err != nil {
 			t.Fatalf("GetRepoStats failed: %v", err)
 		}
@@ -624,13 +625,13 @@ func TestDeleteRepo(t *testing.T) {
 
 		repo := createRepo(t, db, "/tmp/delete-empty")
 
-		err := db.DeleteRepo(repo.ID, false)
+		err := db.DeleteRepo(t.Context(), repo.ID, false)
 		if err != nil {
 			t.Fatalf("DeleteRepo failed: %v", err)
 		}
 
 		// Verify deleted
-		_, err = db.GetRepoByID(repo.ID)
+		_, err = db.GetRepoByID(t.Context(), repo.ID)
 		if err == nil {
 			t.Error("Repo should be deleted")
 		}
@@ -645,7 +646,7 @@ func TestDeleteRepo(t *testing.T) {
 		enqueueJob(t, db, repo.ID, commit.ID, "delete-sha")
 
 		// Without cascade, delete should return ErrRepoHasJobs
-		err := db.DeleteRepo(repo.ID, false)
+		err := db.DeleteRepo(t.Context(), repo.ID, false)
 		if err == nil {
 			t.Error("Expected error when deleting repo with jobs without cascade")
 		}
@@ -654,7 +655,7 @@ func TestDeleteRepo(t *testing.T) {
 		}
 
 		// Verify repo still exists
-		_, err = db.GetRepoByID(repo.ID)
+		_, err = db.GetRepoByID(t.Context(), repo.ID)
 		if err != nil {
 			t.Error("Repo should still exist after failed delete")
 		}
@@ -668,24 +669,24 @@ func TestDeleteRepo(t *testing.T) {
 		commit := createCommit(t, db, repo.ID, "cascade-sha")
 		job := enqueueJob(t, db, repo.ID, commit.ID, "cascade-sha")
 		claimJob(t, db, "worker-1")
-		db.CompleteJob(job.ID, "codex", "prompt", "output")
+		db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output")
 
 		// Add a comment
-		db.AddCommentToJob(job.ID, "user", "comment")
+		db.AddCommentToJob(t.Context(), job.ID, "user", "comment")
 
-		err := db.DeleteRepo(repo.ID, true)
+		err := db.DeleteRepo(t.Context(), repo.ID, true)
 		if err != nil {
 			t.Fatalf("DeleteRepo with cascade failed: %v", err)
 		}
 
 		// Verify repo deleted
-		_, err = db.GetRepoByID(repo.ID)
+		_, err = db.GetRepoByID(t.Context(), repo.ID)
 		if err == nil {
 			t.Error("Repo should be deleted")
 		}
 
 		// Verify jobs deleted
-		jobs, _ := db.ListJobs("", "", 100, 0)
+		jobs, _ := db.ListJobs(t.Context(), "", "", 100, 0)
 		for _, j := range jobs {
 			if j.RepoID == repo.ID {
 				t.Error("Jobs should be deleted")
@@ -697,7 +698,7 @@ func TestDeleteRepo(t *testing.T) {
 		db := openTestDB(t)
 		defer db.Close()
 
-		err := db.DeleteRepo(99999, false)
+		err := db.DeleteRepo(t.Context(), 99999, false)
 		if err == nil {
 			t.Error("Expected error for nonexistent repo")
 		}
@@ -725,7 +726,7 @@ func TestMergeRepos(t *testing.T) {
 		commit3 := createCommit(t, db, target.ID, "merge-sha3")
 		enqueueJob(t, db, target.ID, commit3.ID, "merge-sha3")
 
-		moved, err := db.MergeRepos(source.ID, target.ID)
+		moved, err := db.MergeRepos(t.Context(), source.ID, target.ID)
 		if err != nil {
 			t.Fatalf("MergeRepos failed: %v", err)
 		}
@@ -734,13 +735,13 @@ func TestMergeRepos(t *testing.T) {
 		}
 
 		// Verify source is deleted
-		_, err = db.GetRepoByID(source.ID)
+		_, err = db.GetRepoByID(t.Context(), source.ID)
 		if err == nil {
 			t.Error("Source repo should be deleted")
 		}
 
 		// Verify all jobs now belong to target
-		jobs, _ := db.ListJobs("", target.RootPath, 100, 0)
+		jobs, _ := db.ListJobs(t.Context(), "", target.RootPath, 100, 0)
 		if len(jobs) != 3 {
 			t.Errorf("Expected 3 jobs in target, got %d", len(jobs))
 		}
@@ -752,7 +753,7 @@ func TestMergeRepos(t *testing.T) {
 
 		repo := createRepo(t, db, "/tmp/merge-same")
 
-		moved, err := db.MergeRepos(repo.ID, repo.ID)
+		moved, err := db.MergeRepos(t.Context(), repo.ID, repo.ID)
 		if err != nil {
 			t.Fatalf("MergeRepos failed: %v", err)
 		}
@@ -761,7 +762,7 @@ func TestMergeRepos(t *testing.T) {
 		}
 
 		// Verify repo still exists
-		_, err = db.GetRepoByID(repo.ID)
+		_, err = db.GetRepoByID(t.Context(), repo.ID)
 		if err != nil {
 			t.Error("Repo should still exist after self-merge")
 		}
@@ -774,7 +775,7 @@ func TestMergeRepos(t *testing.T) {
 		source := createRepo(t, db, "/tmp/merge-empty-source")
 		target := createRepo(t, db, "/tmp/merge-empty-target")
 
-		moved, err := db.MergeRepos(source.ID, target.ID)
+		moved, err := db.MergeRepos(t.Context(), source.ID, target.ID)
 		if err != nil {
 			t.Fatalf("MergeRepos failed: %v", err)
 		}
@@ -783,7 +784,7 @@ func TestMergeRepos(t *testing.T) {
 		}
 
 		// Source should be deleted even if empty
-		_, err = db.GetRepoByID(source.ID)
+		_, err = db.GetRepoByID(t.Context(), source.ID)
 		if err == nil {
 			t.Error("Source repo should be deleted even when empty")
 		}
@@ -809,7 +810,7 @@ func TestMergeRepos(t *testing.T) {
 			t.Fatalf("Expected 2 commits in source, got %d", sourceCommitCount)
 		}
 
-		_, err := db.MergeRepos(source.ID, target.ID)
+		_, err := db.MergeRepos(t.Context(), source.ID, target.ID)
 		if err != nil {
 			t.Fatalf("MergeRepos failed: %v", err)
 		}
@@ -847,7 +848,7 @@ func TestDeleteRepoCascadeDeletesCommits(t *testing.T) {
 		t.Fatalf("Expected 2 commits before delete, got %d", beforeCount)
 	}
 
-	err := db.DeleteRepo(repo.ID, true)
+	err := db.DeleteRepo(t.Context(), repo.ID, true)
 	if err != nil {
 		t.Fatalf("DeleteRepo with cascade failed: %v", err)
 	}
@@ -868,7 +869,7 @@ func TestDeleteRepoCascadeDeletesLegacyCommitResponses(t *testing.T) {
 	commit := createCommit(t, db, repo.ID, "legacy-resp-commit")
 
 	// Add legacy commit-based comment (not job-based)
-	_, err := db.AddComment(commit.ID, "reviewer", "Legacy comment on commit")
+	_, err := db.AddComment(t.Context(), commit.ID, "reviewer", "Legacy comment on commit")
 	if err != nil {
 		t.Fatalf("AddComment failed: %v", err)
 	}
@@ -880,7 +881,7 @@ func TestDeleteRepoCascadeDeletesLegacyCommitResponses(t *testing.T) {
 		t.Fatalf("Expected 1 legacy response before delete, got %d", beforeCount)
 	}
 
-	err = db.DeleteRepo(repo.ID, true)
+	err = db.DeleteRepo(t.Context(), repo.ID, true)
 	if err != nil {
 		t.Fatalf("DeleteRepo with cascade failed: %v", err)
 	}
@@ -904,10 +905,10 @@ func TestVerdictSuppressionForPromptJobs(t *testing.T) {
 		promptJob := mustEnqueuePromptJob(t, db, EnqueueOpts{RepoID: repo.ID, Agent: "codex", Prompt: "Test prompt"})
 		claimJob(t, db, "worker-1")
 		// Output that would normally be parsed as FAIL
-		db.CompleteJob(promptJob.ID, "codex", "prompt", "Found issues:\n1. Problem A")
+		db.CompleteJob(t.Context(), promptJob.ID, "codex", "prompt", "Found issues:\n1. Problem A")
 
 		// Fetch via ListJobs and check verdict is nil
-		jobs, _ := db.ListJobs("", repo.RootPath, 100, 0)
+		jobs, _ := db.ListJobs(t.Context(), "", repo.RootPath, 100, 0)
 		var found *ReviewJob
 		for i := range jobs {
 			if jobs[i].ID == promptJob.ID {
@@ -936,10 +937,10 @@ func TestVerdictSuppressionForPromptJobs(t *testing.T) {
 		job := enqueueJob(t, db, repo.ID, commit.ID, "verdict-sha")
 		claimJob(t, db, "worker-1")
 		// Output that should be parsed as PASS
-		db.CompleteJob(job.ID, "codex", "prompt", "No issues found in this commit.")
+		db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "No issues found in this commit.")
 
 		// Fetch via ListJobs and check verdict is set
-		jobs, _ := db.ListJobs("", repo.RootPath, 100, 0)
+		jobs, _ := db.ListJobs(t.Context(), "", repo.RootPath, 100, 0)
 		var found *ReviewJob
 		for i := range jobs {
 			if jobs[i].ID == job.ID {
@@ -974,10 +975,10 @@ func TestVerdictSuppressionForPromptJobs(t *testing.T) {
 
 		claimJob(t, db, "worker-1")
 		// Output that should be parsed as FAIL
-		db.CompleteJob(jobID, "codex", "prompt", "Found issues:\n1. Bug found")
+		db.CompleteJob(t.Context(), jobID, "codex", "prompt", "Found issues:\n1. Bug found")
 
 		// Fetch via ListJobs and check verdict IS computed (because commit_id is not NULL)
-		jobs, _ := db.ListJobs("", repo.RootPath, 100, 0)
+		jobs, _ := db.ListJobs(t.Context(), "", repo.RootPath, 100, 0)
 		var found *ReviewJob
 		for i := range jobs {
 			if jobs[i].ID == jobID {
@@ -998,3 +999,118 @@ func TestVerdictSuppressionForPromptJobs(t *testing.T) {
 		}
 	})
 }
+
+func TestGetOrCreateRepoFollowMoves(t *testing.T) {
+	t.Run("relocates by remote identity", func(t *testing.T) {
+		db := openTestDB(t)
+		defer db.Close()
+		base := t.TempDir()
+		oldPath := filepath.Join(base, "old-project")
+		newPath := filepath.Join(base, "new-project")
+
+		orig, err := db.GetOrCreateRepoFollowMoves(oldPath, "git@github.com:org/project.git", "root1")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		// oldPath never existed on disk, so it looks moved
+		moved, err := db.GetOrCreateRepoFollowMoves(newPath, "git@github.com:org/project.git", "root1")
+		if err != nil {
+			t.Fatalf("follow move: %v", err)
+		}
+		if moved.ID != orig.ID {
+			t.Fatalf("expected repo %d to be relocated, got new repo %d", orig.ID, moved.ID)
+		}
+		if moved.RootPath != newPath || moved.Name != "new-project" {
+			t.Errorf("unexpected relocated repo: %+v", moved)
+		}
+	})
+
+	t.Run("relocates local-only repo by root commit", func(t *testing.T) {
+		db := openTestDB(t)
+		defer db.Close()
+		base := t.TempDir()
+		oldPath := filepath.Join(base, "a")
+		newPath := filepath.Join(base, "b")
+
+		orig, _ := db.GetOrCreateRepoFollowMoves(oldPath, "local://"+oldPath, "root1")
+		moved, err := db.GetOrCreateRepoFollowMoves(newPath, "local://"+newPath, "root1")
+		if err != nil {
+			t.Fatalf("follow move: %v", err)
+		}
+		if moved.ID != orig.ID {
+			t.Fatalf("expected relocation by root commit")
+		}
+		if moved.Identity != "local://"+newPath {
+			t.Errorf("expected local identity to follow the move, got %q", moved.Identity)
+		}
+	})
+
+	t.Run("does not steal an existing clone", func(t *testing.T) {
+		db := openTestDB(t)
+		defer db.Close()
+		existing := t.TempDir() // exists on disk
+		other := filepath.Join(t.TempDir(), "second-clone")
+
+		orig, _ := db.GetOrCreateRepoFollowMoves(existing, "git@github.com:org/project.git", "root1")
+		second, err := db.GetOrCreateRepoFollowMoves(other, "git@github.com:org/project.git", "root1")
+		if err != nil {
+			t.Fatalf("create second clone: %v", err)
+		}
+		if second.ID == orig.ID {
+			t.Error("expected a separate repo for a second clone")
+		}
+	})
+
+	t.Run("forks with different remotes are not moves", func(t *testing.T) {
+		db := openTestDB(t)
+		defer db.Close()
+		base := t.TempDir()
+
+		orig, _ := db.GetOrCreateRepoFollowMoves(filepath.Join(base, "upstream"), "git@github.com:org/project.git", "root1")
+		fork, err := db.GetOrCreateRepoFollowMoves(filepath.Join(base, "fork"), "git@github.com:me/project.git", "root1")
+		if err != nil {
+			t.Fatalf("create fork: %v", err)
+		}
+		if fork.ID == orig.ID {
+			t.Error("fork should not be treated as a move")
+		}
+	})
+}
+
+func TestRelocateRepoMergesDuplicate(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	base := t.TempDir()
+	oldPath := filepath.Join(base, "old")
+	newPath := filepath.Join(base, "new")
+
+	oldRepo, _, oldJob := createJobChain(t, db, oldPath, "shared-sha")
+	// Duplicate created after the move, with a review of the same commit
+	dup := createRepo(t, db, newPath)
+	dupCommit := createCommit(t, db, dup.ID, "shared-sha")
+	dupJob := enqueueJob(t, db, dup.ID, dupCommit.ID, "shared-sha")
+
+	if err := db.RelocateRepo(oldRepo.ID, newPath); err != nil {
+		t.Fatalf("RelocateRepo: %v", err)
+	}
+
+	repo, err := db.GetRepoByPath(t.Context(), newPath)
+	if err != nil {
+		t.Fatalf("GetRepoByPath: %v", err)
+	}
+	if repo.ID != oldRepo.ID {
+		t.Errorf("expected original repo %d at new path, got %d", oldRepo.ID, repo.ID)
+	}
+	if _, err := db.GetRepoByID(t.Context(), dup.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected duplicate repo to be removed, got %v", err)
+	}
+	for _, id := range []int64{oldJob.ID, dupJob.ID} {
+		job, err := db.GetJobByID(t.Context(), id)
+		if err != nil {
+			t.Fatalf("GetJobByID(%d): %v", id, err)
+		}
+		if job.RepoID != oldRepo.ID {
+			t.Errorf("job %d still attached to repo %d", id, job.RepoID)
+		}
+	}
+}
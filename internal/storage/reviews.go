@@ -1,12 +1,15 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 )
 
 // GetReviewByJobID finds a review by its job ID
-func (db *DB) GetReviewByJobID(jobID int64) (*Review, error) {
+func (db *DB) GetReviewByJobID(ctx context.Context, jobID int64) (*Review, error) {
 	var r Review
 	var createdAt string
 	var addressed int
@@ -16,11 +19,11 @@ func (db *DB) GetReviewByJobID(jobID int64) (*Review, error) {
 	var commitID sql.NullInt64
 	var commitSubject sql.NullString
 
-	err := db.QueryRow(`
+	err := db.QueryRowContext(ctx, `
 		SELECT rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed, rv.uuid,
 		       j.id, j.repo_id, j.commit_id, j.git_ref, j.agent, j.reasoning, j.status, j.enqueued_at,
 		       j.started_at, j.finished_at, j.worker_id, j.error, j.model, j.job_type, j.review_type,
-		       rp.root_path, rp.name, c.subject
+		       j.focus, j.protected_branch, j.truncation_level, rp.root_path, rp.name, c.subject
 		FROM reviews rv
 		JOIN review_jobs j ON j.id = rv.job_id
 		JOIN repos rp ON rp.id = j.repo_id
@@ -29,10 +32,11 @@ func (db *DB) GetReviewByJobID(jobID int64) (*Review, error) {
 	`, jobID).Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed, &reviewUUID,
 		&job.ID, &job.RepoID, &commitID, &job.GitRef, &job.Agent, &job.Reasoning, &job.Status, &enqueuedAt,
 		&startedAt, &finishedAt, &workerID, &errMsg, &model, &jobTypeStr, &reviewTypeStr,
-		&job.RepoPath, &job.RepoName, &commitSubject)
+		&job.Focus, &job.Protected, &job.TruncationLevel, &job.RepoPath, &job.RepoName, &commitSubject)
 	if err != nil {
 		return nil, err
 	}
+	db.loadReviewBlobs(&r)
 	r.Addressed = addressed != 0
 	if reviewUUID.Valid {
 		r.UUID = reviewUUID.String
@@ -83,7 +87,7 @@ func (db *DB) GetReviewByJobID(jobID int64) (*Review, error) {
 }
 
 // GetReviewByCommitSHA finds the most recent review by commit SHA (searches git_ref field)
-func (db *DB) GetReviewByCommitSHA(sha string) (*Review, error) {
+func (db *DB) GetReviewByCommitSHA(ctx context.Context, sha string) (*Review, error) {
 	var r Review
 	var createdAt string
 	var addressed int
@@ -94,11 +98,11 @@ func (db *DB) GetReviewByCommitSHA(sha string) (*Review, error) {
 	var commitSubject sql.NullString
 
 	// Search by git_ref which contains the SHA for single commits
-	err := db.QueryRow(`
+	err := db.QueryRowContext(ctx, `
 		SELECT rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed, rv.uuid,
 		       j.id, j.repo_id, j.commit_id, j.git_ref, j.agent, j.reasoning, j.status, j.enqueued_at,
 		       j.started_at, j.finished_at, j.worker_id, j.error, j.model, j.job_type, j.review_type,
-		       rp.root_path, rp.name, c.subject
+		       j.focus, j.protected_branch, j.truncation_level, rp.root_path, rp.name, c.subject
 		FROM reviews rv
 		JOIN review_jobs j ON j.id = rv.job_id
 		JOIN repos rp ON rp.id = j.repo_id
@@ -109,10 +113,11 @@ func (db *DB) GetReviewByCommitSHA(sha string) (*Review, error) {
 	`, sha).Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed, &reviewUUID,
 		&job.ID, &job.RepoID, &commitID, &job.GitRef, &job.Agent, &job.Reasoning, &job.Status, &enqueuedAt,
 		&startedAt, &finishedAt, &workerID, &errMsg, &model, &jobTypeStr, &reviewTypeStr,
-		&job.RepoPath, &job.RepoName, &commitSubject)
+		&job.Focus, &job.Protected, &job.TruncationLevel, &job.RepoPath, &job.RepoName, &commitSubject)
 	if err != nil {
 		return nil, err
 	}
+	db.loadReviewBlobs(&r)
 	r.Addressed = addressed != 0
 	if reviewUUID.Valid {
 		r.UUID = reviewUUID.String
@@ -164,8 +169,8 @@ func (db *DB) GetReviewByCommitSHA(sha string) (*Review, error) {
 }
 
 // GetAllReviewsForGitRef returns all reviews for a git ref (commit SHA or range) for re-review context
-func (db *DB) GetAllReviewsForGitRef(gitRef string) ([]Review, error) {
-	rows, err := db.Query(`
+func (db *DB) GetAllReviewsForGitRef(ctx context.Context, gitRef string) ([]Review, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed
 		FROM reviews rv
 		JOIN review_jobs j ON j.id = rv.job_id
@@ -185,6 +190,7 @@ func (db *DB) GetAllReviewsForGitRef(gitRef string) ([]Review, error) {
 		if err := rows.Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed); err != nil {
 			return nil, err
 		}
+		db.loadReviewBlobs(&r)
 		r.CreatedAt = parseSQLiteTime(createdAt)
 		r.Addressed = addressed != 0
 		reviews = append(reviews, r)
@@ -194,8 +200,8 @@ func (db *DB) GetAllReviewsForGitRef(gitRef string) ([]Review, error) {
 }
 
 // GetRecentReviewsForRepo returns the N most recent reviews for a repo
-func (db *DB) GetRecentReviewsForRepo(repoID int64, limit int) ([]Review, error) {
-	rows, err := db.Query(`
+func (db *DB) GetRecentReviewsForRepo(ctx context.Context, repoID int64, limit int) ([]Review, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed
 		FROM reviews rv
 		JOIN review_jobs j ON j.id = rv.job_id
@@ -216,6 +222,7 @@ func (db *DB) GetRecentReviewsForRepo(repoID int64, limit int) ([]Review, error)
 		if err := rows.Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed); err != nil {
 			return nil, err
 		}
+		db.loadReviewBlobs(&r)
 		r.CreatedAt = parseSQLiteTime(createdAt)
 		r.Addressed = addressed != 0
 		reviews = append(reviews, r)
@@ -224,8 +231,246 @@ func (db *DB) GetRecentReviewsForRepo(repoID int64, limit int) ([]Review, error)
 	return reviews, rows.Err()
 }
 
+// ListReviews returns reviews newest first, a page at a time: at most limit
+// reviews (all when 0) after skipping offset, or those before a cursor with
+// WithBeforeID. Reviews are filtered by the repo root path and by the
+// repo, ref, branch, addressed, author, agent and date range options.
+func (db *DB) ListReviews(ctx context.Context, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]Review, error) {
+	var o listJobsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var conditions []string
+	var args []any
+	if repoFilter != "" {
+		conditions = append(conditions, "r.root_path = ?")
+		args = append(args, repoFilter)
+	}
+	if o.gitRef != "" {
+		conditions = append(conditions, "j.git_ref = ?")
+		args = append(args, o.gitRef)
+	}
+	if o.branch != "" {
+		if o.branchIncludeEmpty {
+			conditions = append(conditions, "(j.branch = ? OR j.branch = '' OR j.branch IS NULL)")
+		} else {
+			conditions = append(conditions, "j.branch = ?")
+		}
+		args = append(args, o.branch)
+	}
+	if o.addressed != nil {
+		if *o.addressed {
+			conditions = append(conditions, "rv.addressed = 1")
+		} else {
+			conditions = append(conditions, "rv.addressed = 0")
+		}
+	}
+	if o.beforeID > 0 {
+		conditions = append(conditions, "rv.id < ?")
+		args = append(args, o.beforeID)
+	}
+	if o.repoIdentity != "" {
+		conditions = append(conditions, "r.identity = ?")
+		args = append(args, o.repoIdentity)
+	}
+	if o.author != "" {
+		conditions = append(conditions, canonicalAuthor+" = "+canonicalAuthorArg)
+		args = append(args, o.author, o.author)
+	}
+	if o.agent != "" {
+		conditions = append(conditions, "rv.agent = ?")
+		args = append(args, o.agent)
+	}
+	rangeConds, rangeArgs := timeRangeConditions("rv.created_at", o.since, o.until)
+	conditions = append(conditions, rangeConds...)
+	args = append(args, rangeArgs...)
+
+	query := `
+		SELECT rv.id, rv.job_id, rv.agent, rv.prompt, rv.output, rv.created_at, rv.addressed
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// By ID, like ListJobs, so pages are stable
+	query += " ORDER BY rv.id DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+		if offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		var r Review
+		var createdAt string
+		var addressed int
+		if err := rows.Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed); err != nil {
+			return nil, err
+		}
+		db.loadReviewBlobs(&r)
+		r.CreatedAt = parseSQLiteTime(createdAt)
+		r.Addressed = addressed != 0
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+// CommitVerdict is the outcome of the latest standard review of a commit.
+type CommitVerdict struct {
+	Verdict   string // "P" or "F"; empty for skipped commits
+	Addressed bool
+	Skipped   bool // Commit was skipped on purpose (skip marker or bot author)
+}
+
+// GetCommitVerdicts returns the latest completed or skipped standard review
+// for each of the given commit SHAs in a repo, with the reconciled verdict
+// when one was recorded. SHAs that have not been reviewed are absent from
+// the result.
+func (db *DB) GetCommitVerdicts(repoID int64, shas []string) (map[string]CommitVerdict, error) {
+	verdicts := make(map[string]CommitVerdict)
+	if len(shas) == 0 {
+		return verdicts, nil
+	}
+
+	placeholders := make([]string, len(shas))
+	args := make([]interface{}, 0, len(shas)+1)
+	args = append(args, repoID)
+	for i, sha := range shas {
+		placeholders[i] = "?"
+		args = append(args, sha)
+	}
+
+	rows, err := db.Query(`
+		SELECT j.git_ref, j.status, COALESCE(rv.output, ''), COALESCE(rv.addressed, 0), COALESCE(vr.verdict, '')
+		FROM review_jobs j
+		LEFT JOIN reviews rv ON rv.job_id = j.id
+		LEFT JOIN verdict_reconciliations vr ON vr.repo_id = j.repo_id AND vr.git_ref = j.git_ref
+		WHERE j.repo_id = ? AND j.job_type = 'review'
+		  AND j.review_type IN ('', 'default')
+		  AND j.status IN ('done', 'skipped')
+		  AND j.git_ref IN (`+strings.Join(placeholders, ",")+`)
+		ORDER BY j.id DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sha, status, output, reconciled string
+		var addressed int
+		if err := rows.Scan(&sha, &status, &output, &addressed, &reconciled); err != nil {
+			return nil, err
+		}
+		if _, seen := verdicts[sha]; seen {
+			continue // Rows are newest first
+		}
+		if JobStatus(status) == JobStatusSkipped {
+			verdicts[sha] = CommitVerdict{Skipped: true}
+			continue
+		}
+		verdict := reconciled
+		if verdict == "" {
+			verdict = db.outputVerdict(output)
+		}
+		verdicts[sha] = CommitVerdict{Verdict: verdict, Addressed: addressed != 0}
+	}
+
+	return verdicts, rows.Err()
+}
+
+// CommitStatus is the review state of a single commit
+type CommitStatus struct {
+	Status       JobStatus // Status of the latest review job; empty if never enqueued
+	Verdict      string    // "P" or "F" once the review is done; a reconciled verdict wins
+	Addressed    bool
+	OpenFindings int    // Untriaged, unsuppressed findings of an unaddressed review
+	Protected    bool   // The commit was on a protected branch when it was reviewed
+	QuickTake    string // Short summary the review opened with, if any
+}
+
+// GetCommitStatus returns the state of the latest standard review of sha in
+// the repo at repoRoot. Offloaded outputs are not fetched, so their findings
+// are not counted.
+func (db *DB) GetCommitStatus(ctx context.Context, repoRoot, sha string) (CommitStatus, error) {
+	var st CommitStatus
+	var status, output, reconciled string
+	var reviewID sql.NullInt64
+	var addressed int
+	err := db.QueryRowContext(ctx, `
+		SELECT j.status, rv.id, COALESCE(rv.output, ''), COALESCE(rv.addressed, 0), COALESCE(vr.verdict, ''),
+		       j.protected_branch, COALESCE(rv.quick_take, '')
+		FROM review_jobs j
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN reviews rv ON rv.job_id = j.id
+		LEFT JOIN verdict_reconciliations vr ON vr.repo_id = j.repo_id AND vr.git_ref = j.git_ref
+		WHERE r.root_path = ? AND j.git_ref = ? AND j.job_type = 'review'
+		  AND j.review_type IN ('', 'default')
+		ORDER BY j.id DESC
+		LIMIT 1
+	`, repoRoot, sha).Scan(&status, &reviewID, &output, &addressed, &reconciled, &st.Protected, &st.QuickTake)
+	if errors.Is(err, sql.ErrNoRows) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+
+	st.Status = JobStatus(status)
+	st.Addressed = addressed != 0
+	if !reviewID.Valid {
+		return st, nil
+	}
+	st.Verdict = reconciled
+	if st.Verdict == "" {
+		st.Verdict = db.outputVerdict(output)
+	}
+	if st.Addressed || IsBlobRef(output) {
+		return st, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT finding_index FROM finding_triage WHERE review_id = ?
+		UNION SELECT finding_index FROM finding_suppressions WHERE review_id = ?
+	`, reviewID.Int64, reviewID.Int64)
+	if err != nil {
+		return st, err
+	}
+	defer rows.Close()
+	triaged := make(map[int]bool)
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return st, err
+		}
+		triaged[idx] = true
+	}
+	if err := rows.Err(); err != nil {
+		return st, err
+	}
+	for _, f := range ExtractFindings(output) {
+		if !triaged[f.Index] {
+			st.OpenFindings++
+		}
+	}
+	return st, nil
+}
+
 // MarkReviewAddressed marks a review as addressed (or unaddressed) by review ID
-func (db *DB) MarkReviewAddressed(reviewID int64, addressed bool) error {
+func (db *DB) MarkReviewAddressed(ctx context.Context, reviewID int64, addressed bool) error {
 	val := 0
 	if addressed {
 		val = 1
@@ -233,7 +478,7 @@ func (db *DB) MarkReviewAddressed(reviewID int64, addressed bool) error {
 	now := time.Now().Format(time.RFC3339)
 	machineID, _ := db.GetMachineID()
 
-	result, err := db.Exec(`UPDATE reviews SET addressed = ?, updated_by_machine_id = ?, updated_at = ? WHERE id = ?`, val, machineID, now, reviewID)
+	result, err := db.ExecContext(ctx, `UPDATE reviews SET addressed = ?, updated_by_machine_id = ?, updated_at = ? WHERE id = ?`, val, machineID, now, reviewID)
 	if err != nil {
 		return err
 	}
@@ -248,7 +493,7 @@ func (db *DB) MarkReviewAddressed(reviewID int64, addressed bool) error {
 }
 
 // MarkReviewAddressedByJobID marks a review as addressed (or unaddressed) by job ID
-func (db *DB) MarkReviewAddressedByJobID(jobID int64, addressed bool) error {
+func (db *DB) MarkReviewAddressedByJobID(ctx context.Context, jobID int64, addressed bool) error {
 	val := 0
 	if addressed {
 		val = 1
@@ -256,7 +501,7 @@ func (db *DB) MarkReviewAddressedByJobID(jobID int64, addressed bool) error {
 	now := time.Now().Format(time.RFC3339)
 	machineID, _ := db.GetMachineID()
 
-	result, err := db.Exec(`UPDATE reviews SET addressed = ?, updated_by_machine_id = ?, updated_at = ? WHERE job_id = ?`, val, machineID, now, jobID)
+	result, err := db.ExecContext(ctx, `UPDATE reviews SET addressed = ?, updated_by_machine_id = ?, updated_at = ? WHERE job_id = ?`, val, machineID, now, jobID)
 	if err != nil {
 		return err
 	}
@@ -271,32 +516,47 @@ func (db *DB) MarkReviewAddressedByJobID(jobID int64, addressed bool) error {
 }
 
 // GetReviewByID finds a review by its ID
-func (db *DB) GetReviewByID(reviewID int64) (*Review, error) {
+func (db *DB) GetReviewByID(ctx context.Context, reviewID int64) (*Review, error) {
 	var r Review
 	var createdAt string
 	var addressed int
 
-	err := db.QueryRow(`
-		SELECT id, job_id, agent, prompt, output, created_at, addressed
+	err := db.QueryRowContext(ctx, `
+		SELECT id, job_id, agent, prompt, output, created_at, addressed,
+		       COALESCE(uuid, ''), COALESCE(signature, ''), COALESCE(signing_key, '')
 		FROM reviews WHERE id = ?
-	`, reviewID).Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed)
+	`, reviewID).Scan(&r.ID, &r.JobID, &r.Agent, &r.Prompt, &r.Output, &createdAt, &addressed,
+		&r.UUID, &r.Signature, &r.SigningKey)
 	if err != nil {
 		return nil, err
 	}
+	db.loadReviewBlobs(&r)
 	r.CreatedAt = parseSQLiteTime(createdAt)
 	r.Addressed = addressed != 0
 
 	return &r, nil
 }
 
+// SetReviewSignature stores the signature and signing public key for a review.
+func (db *DB) SetReviewSignature(reviewID int64, signature, publicKey string) error {
+	_, err := db.Exec(`UPDATE reviews SET signature = ?, signing_key = ? WHERE id = ?`, signature, publicKey, reviewID)
+	return err
+}
+
+// SetReviewQuickTake stores the quick take the review of a job opened with
+func (db *DB) SetReviewQuickTake(jobID int64, quickTake string) error {
+	_, err := db.Exec(`UPDATE reviews SET quick_take = ? WHERE job_id = ?`, quickTake, jobID)
+	return err
+}
+
 // AddComment adds a comment to a commit (legacy - use AddCommentToJob for new code)
-func (db *DB) AddComment(commitID int64, responder, response string) (*Response, error) {
+func (db *DB) AddComment(ctx context.Context, commitID int64, responder, response string) (*Response, error) {
 	uuid := GenerateUUID()
 	machineID, _ := db.GetMachineID()
 	now := time.Now()
 	nowStr := now.Format(time.RFC3339)
 
-	result, err := db.Exec(`INSERT INTO responses (commit_id, responder, response, uuid, source_machine_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	result, err := db.ExecContext(ctx, `INSERT INTO responses (commit_id, responder, response, uuid, source_machine_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		commitID, responder, response, uuid, machineID, nowStr)
 	if err != nil {
 		return nil, err
@@ -315,10 +575,10 @@ func (db *DB) AddComment(commitID int64, responder, response string) (*Response,
 }
 
 // AddCommentToJob adds a comment linked to a job/review
-func (db *DB) AddCommentToJob(jobID int64, responder, response string) (*Response, error) {
+func (db *DB) AddCommentToJob(ctx context.Context, jobID int64, responder, response string) (*Response, error) {
 	// Verify job exists first to return proper 404 instead of FK violation or orphaned row
 	var exists int
-	err := db.QueryRow(`SELECT 1 FROM review_jobs WHERE id = ?`, jobID).Scan(&exists)
+	err := db.QueryRowContext(ctx, `SELECT 1 FROM review_jobs WHERE id = ?`, jobID).Scan(&exists)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows // Job not found
@@ -331,7 +591,7 @@ func (db *DB) AddCommentToJob(jobID int64, responder, response string) (*Respons
 	now := time.Now()
 	nowStr := now.Format(time.RFC3339)
 
-	result, err := db.Exec(`INSERT INTO responses (job_id, responder, response, uuid, source_machine_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	result, err := db.ExecContext(ctx, `INSERT INTO responses (job_id, responder, response, uuid, source_machine_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		jobID, responder, response, uuid, machineID, nowStr)
 	if err != nil {
 		return nil, err
@@ -350,8 +610,8 @@ func (db *DB) AddCommentToJob(jobID int64, responder, response string) (*Respons
 }
 
 // GetCommentsForCommit returns all comments for a commit
-func (db *DB) GetCommentsForCommit(commitID int64) ([]Response, error) {
-	rows, err := db.Query(`
+func (db *DB) GetCommentsForCommit(ctx context.Context, commitID int64) ([]Response, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT id, commit_id, job_id, responder, response, created_at
 		FROM responses
 		WHERE commit_id = ?
@@ -384,8 +644,8 @@ func (db *DB) GetCommentsForCommit(commitID int64) ([]Response, error) {
 }
 
 // GetCommentsForJob returns all comments linked to a job
-func (db *DB) GetCommentsForJob(jobID int64) ([]Response, error) {
-	rows, err := db.Query(`
+func (db *DB) GetCommentsForJob(ctx context.Context, jobID int64) ([]Response, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT id, commit_id, job_id, responder, response, created_at
 		FROM responses
 		WHERE job_id = ?
@@ -418,10 +678,10 @@ func (db *DB) GetCommentsForJob(jobID int64) ([]Response, error) {
 }
 
 // GetCommentsForCommitSHA returns all comments for a commit by SHA
-func (db *DB) GetCommentsForCommitSHA(sha string) ([]Response, error) {
-	commit, err := db.GetCommitBySHA(sha)
+func (db *DB) GetCommentsForCommitSHA(ctx context.Context, sha string) ([]Response, error) {
+	commit, err := db.GetCommitBySHA(ctx, sha)
 	if err != nil {
 		return nil, err
 	}
-	return db.GetCommentsForCommit(commit.ID)
+	return db.GetCommentsForCommit(ctx, commit.ID)
 }
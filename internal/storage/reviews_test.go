@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"path/filepath"
 	"testing"
 )
 
@@ -42,7 +43,7 @@ func TestAddCommentToJobAllStates(t *testing.T) {
 
 			// Add a comment to the job
 			comment := "Test comment for " + tc.name
-			resp, err := db.AddCommentToJob(job.ID, "test-user", comment)
+			resp, err := db.AddCommentToJob(t.Context(), job.ID, "test-user", comment)
 			if err != nil {
 				t.Fatalf("AddCommentToJob failed for %s: %v", tc.name, err)
 			}
@@ -62,7 +63,7 @@ func TestAddCommentToJobAllStates(t *testing.T) {
 			}
 
 			// Verify we can retrieve the comment
-			comments, err := db.GetCommentsForJob(job.ID)
+			comments, err := db.GetCommentsForJob(t.Context(), job.ID)
 			if err != nil {
 				t.Fatalf("GetCommentsForJob failed: %v", err)
 			}
@@ -83,7 +84,7 @@ func TestAddCommentToJobNonExistent(t *testing.T) {
 	defer db.Close()
 
 	// Try to add a comment to a job that doesn't exist
-	_, err := db.AddCommentToJob(99999, "test-user", "This should fail")
+	_, err := db.AddCommentToJob(t.Context(), 99999, "test-user", "This should fail")
 	if err == nil {
 		t.Fatal("Expected error when adding comment to non-existent job")
 	}
@@ -112,14 +113,14 @@ func TestAddCommentToJobMultipleComments(t *testing.T) {
 	}
 
 	for _, c := range comments {
-		_, err := db.AddCommentToJob(job.ID, c.user, c.message)
+		_, err := db.AddCommentToJob(t.Context(), job.ID, c.user, c.message)
 		if err != nil {
 			t.Fatalf("AddCommentToJob failed for %s: %v", c.user, err)
 		}
 	}
 
 	// Verify all comments were added
-	retrieved, err := db.GetCommentsForJob(job.ID)
+	retrieved, err := db.GetCommentsForJob(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetCommentsForJob failed: %v", err)
 	}
@@ -147,13 +148,13 @@ func TestAddCommentToJobWithNoReview(t *testing.T) {
 	_, _, job := createJobChain(t, db, "/tmp/test-repo", "abc123")
 
 	// Verify no review exists for this job
-	_, err := db.GetReviewByJobID(job.ID)
+	_, err := db.GetReviewByJobID(t.Context(), job.ID)
 	if err == nil {
 		t.Fatal("Expected error getting review for job with no review")
 	}
 
 	// Add a comment to the job (should succeed even without a review)
-	resp, err := db.AddCommentToJob(job.ID, "test-user", "Comment on job without review")
+	resp, err := db.AddCommentToJob(t.Context(), job.ID, "test-user", "Comment on job without review")
 	if err != nil {
 		t.Fatalf("AddCommentToJob failed: %v", err)
 	}
@@ -183,19 +184,19 @@ func TestGetReviewByJobIDIncludesModel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, GitRef: tt.gitRef, Agent: "codex", Model: tt.model, Reasoning: "thorough"})
+			job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, GitRef: tt.gitRef, Agent: "codex", Model: tt.model, Reasoning: "thorough"})
 			if err != nil {
 				t.Fatalf("EnqueueRangeJob failed: %v", err)
 			}
 
 			// Claim job to move to running, then complete it
-			db.ClaimJob("test-worker")
-			err = db.CompleteJob(job.ID, "codex", "test prompt", "Test review output\n\n## Verdict: PASS")
+			db.ClaimJob(t.Context(), "test-worker")
+			err = db.CompleteJob(t.Context(), job.ID, "codex", "test prompt", "Test review output\n\n## Verdict: PASS")
 			if err != nil {
 				t.Fatalf("CompleteJob failed: %v", err)
 			}
 
-			review, err := db.GetReviewByJobID(job.ID)
+			review, err := db.GetReviewByJobID(t.Context(), job.ID)
 			if err != nil {
 				t.Fatalf("GetReviewByJobID failed: %v", err)
 			}
@@ -209,3 +210,160 @@ func TestGetReviewByJobIDIncludesModel(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommitVerdicts(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/verdict-repo")
+	complete := func(sha, output string) {
+		t.Helper()
+		commit := createCommit(t, db, repo.ID, sha)
+		job := enqueueJob(t, db, repo.ID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+	}
+
+	complete("aaa", "- High: bug")
+	complete("aaa", "No issues found.") // A rerun replaces the earlier verdict
+	complete("bbb", "- Medium: leak")
+	skipCommit := createCommit(t, db, repo.ID, "ccc")
+	if _, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: skipCommit.ID, GitRef: "ccc", Agent: "codex", SkipReason: "bot"}); err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+
+	verdicts, err := db.GetCommitVerdicts(repo.ID, []string{"aaa", "bbb", "ccc", "ddd"})
+	if err != nil {
+		t.Fatalf("GetCommitVerdicts: %v", err)
+	}
+	if len(verdicts) != 3 {
+		t.Fatalf("expected 3 verdicts, got %v", verdicts)
+	}
+	if verdicts["aaa"].Verdict != "P" {
+		t.Errorf("expected latest review of aaa to pass, got %+v", verdicts["aaa"])
+	}
+	if verdicts["bbb"].Verdict != "F" {
+		t.Errorf("expected bbb to fail, got %+v", verdicts["bbb"])
+	}
+	if !verdicts["ccc"].Skipped {
+		t.Errorf("expected ccc to be skipped, got %+v", verdicts["ccc"])
+	}
+}
+
+func TestGetCommitStatus(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/status-repo")
+
+	st, err := db.GetCommitStatus(t.Context(), repo.RootPath, "none")
+	if err != nil {
+		t.Fatalf("GetCommitStatus: %v", err)
+	}
+	if st != (CommitStatus{}) {
+		t.Errorf("expected empty status for unknown commit, got %+v", st)
+	}
+
+	commit := createCommit(t, db, repo.ID, "abc")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "abc")
+	if st, _ = db.GetCommitStatus(t.Context(), repo.RootPath, "abc"); st.Status != JobStatusQueued || st.Verdict != "" {
+		t.Errorf("expected queued status, got %+v", st)
+	}
+
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "- High: first\n- Low: second"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	st, err = db.GetCommitStatus(t.Context(), repo.RootPath, "abc")
+	if err != nil {
+		t.Fatalf("GetCommitStatus: %v", err)
+	}
+	if st.Status != JobStatusDone || st.Verdict != "F" || st.OpenFindings != 2 {
+		t.Errorf("expected failed review with 2 findings, got %+v", st)
+	}
+
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+	if err := db.SetFindingTriage(review.ID, 0, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+	if st, _ = db.GetCommitStatus(t.Context(), repo.RootPath, "abc"); st.OpenFindings != 1 {
+		t.Errorf("expected 1 open finding after triage, got %+v", st)
+	}
+
+	if err := db.MarkReviewAddressed(t.Context(), review.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+	if st, _ = db.GetCommitStatus(t.Context(), repo.RootPath, "abc"); !st.Addressed || st.OpenFindings != 0 {
+		t.Errorf("expected addressed review without open findings, got %+v", st)
+	}
+}
+
+func TestSetReviewQuickTake(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/quick-take-repo")
+	commit := createCommit(t, db, repo.ID, "abc")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "abc")
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	if err := db.SetReviewQuickTake(job.ID, "Passes; nothing to fix."); err != nil {
+		t.Fatalf("SetReviewQuickTake: %v", err)
+	}
+
+	jobs, err := db.ListJobs(t.Context(), "", repo.RootPath, 10, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].QuickTake != "Passes; nothing to fix." {
+		t.Errorf("expected listed quick take, got %+v", jobs)
+	}
+	st, err := db.GetCommitStatus(t.Context(), repo.RootPath, "abc")
+	if err != nil {
+		t.Fatalf("GetCommitStatus: %v", err)
+	}
+	if st.QuickTake != "Passes; nothing to fix." {
+		t.Errorf("status quick take = %q", st.QuickTake)
+	}
+
+	// A rerun drops the review and its quick take
+	if err := db.ReenqueueJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if jobs, _ = db.ListJobs(t.Context(), "", repo.RootPath, 10, 0); len(jobs) != 1 || jobs[0].QuickTake != "" {
+		t.Errorf("expected no quick take after rerun, got %+v", jobs)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	if _, err := OpenReadOnly(dbPath); err == nil {
+		t.Fatal("expected error for missing database")
+	}
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	createRepo(t, db, "/tmp/readonly-repo")
+	db.Close()
+
+	ro, err := OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	defer ro.Close()
+	if _, err := ro.GetRepoByPath(t.Context(), "/tmp/readonly-repo"); err != nil {
+		t.Errorf("read failed: %v", err)
+	}
+	if _, err := ro.Exec(`DELETE FROM repos`); err == nil {
+		t.Error("expected write to fail on read-only database")
+	}
+}
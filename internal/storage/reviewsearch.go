@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrEmptySearch is returned by SearchReviews for a query without terms
+var ErrEmptySearch = errors.New("search query is empty")
+
+// ReviewSearchFilter narrows the reviews SearchReviews matches. Zero fields
+// match all reviews.
+type ReviewSearchFilter struct {
+	RepoID int64
+	Agent  string
+	Since  time.Time // Reviews created at or after
+	Limit  int       // Caps the results when positive
+}
+
+// ReviewSearchMatch is a review whose output or prompt matched a search
+type ReviewSearchMatch struct {
+	ReviewID      int64     `json:"review_id"`
+	JobID         int64     `json:"job_id"`
+	RepoName      string    `json:"repo_name"`
+	GitRef        string    `json:"git_ref"`
+	Agent         string    `json:"agent"`
+	CommitSubject string    `json:"commit_subject,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	Verdict       string    `json:"verdict,omitempty"`
+	Snippet       string    `json:"snippet"` // Matched text, with terms in [brackets]
+}
+
+// searchTermPattern splits a search query into "quoted phrases" and words
+var searchTermPattern = regexp.MustCompile(`"[^"]*"\*?|\S+`)
+
+// ftsQuery turns a search query into an FTS5 query matching reviews with
+// every term. Words and "quoted phrases" are matched as phrases, so
+// punctuation such as the dot in Config.Load is not FTS5 syntax; a
+// trailing * matches any word with that prefix.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, term := range searchTermPattern.FindAllString(query, -1) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimSuffix(term, "*")
+		if len(term) >= 2 && strings.HasPrefix(term, `"`) && strings.HasSuffix(term, `"`) {
+			term = term[1 : len(term)-1]
+		}
+		term = strings.TrimSpace(strings.ReplaceAll(term, `"`, ""))
+		if term == "" {
+			continue
+		}
+		phrase := `"` + term + `"`
+		if prefix {
+			phrase += "*"
+		}
+		terms = append(terms, phrase)
+	}
+	return strings.Join(terms, " ")
+}
+
+// SearchReviews returns the reviews whose output or prompt contain every
+// term of a query, best match first. Words and "quoted phrases" match
+// case-insensitively as whole words, and a trailing * matches a prefix
+// (Parse* finds ParseConfig). Reviews offloaded to a blob store are only
+// searchable by their reference, so they do not match.
+func (db *DB) SearchReviews(ctx context.Context, query string, filter ReviewSearchFilter) ([]ReviewSearchMatch, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, ErrEmptySearch
+	}
+
+	q := `
+		SELECT rv.id, rv.job_id, r.name, j.git_ref, rv.agent, COALESCE(c.subject, ''), rv.created_at, rv.output,
+		       snippet(reviews_fts, -1, '[', ']', '...', 16)
+		FROM reviews_fts
+		JOIN reviews rv ON rv.id = reviews_fts.rowid
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id
+		WHERE reviews_fts MATCH ?`
+	args := []any{match}
+	if filter.RepoID != 0 {
+		q += ` AND j.repo_id = ?`
+		args = append(args, filter.RepoID)
+	}
+	if filter.Agent != "" {
+		q += ` AND rv.agent = ?`
+		args = append(args, filter.Agent)
+	}
+	if !filter.Since.IsZero() {
+		q += ` AND julianday(rv.created_at) >= julianday(?)`
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	q += ` ORDER BY reviews_fts.rank, rv.id DESC`
+	if filter.Limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []ReviewSearchMatch
+	for rows.Next() {
+		var m ReviewSearchMatch
+		var createdAt, output string
+		if err := rows.Scan(&m.ReviewID, &m.JobID, &m.RepoName, &m.GitRef, &m.Agent, &m.CommitSubject,
+			&createdAt, &output, &m.Snippet); err != nil {
+			return nil, err
+		}
+		m.CreatedAt = parseSQLiteTime(createdAt)
+		m.Verdict = db.outputVerdict(output)
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"ParseConfig", `"ParseConfig"`},
+		{"Config.Load nil", `"Config.Load" "nil"`},
+		{`"race condition" cache`, `"race condition" "cache"`},
+		{"Parse*", `"Parse"*`},
+		{`say"what`, `"saywhat"`},
+		{`  "" `, ``},
+	}
+	for _, tt := range tests {
+		if got := ftsQuery(tt.query); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchReviews(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/search-repo")
+	complete := func(sha, output string) *ReviewJob {
+		t.Helper()
+		commit := createCommit(t, db, repo.ID, sha)
+		enqueueJob(t, db, repo.ID, commit.ID, sha)
+		job := claimJob(t, db, "worker-1")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "Review the change to "+sha, output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		return job
+	}
+	first := complete("aaa111", "- High: ParseConfig ignores the error from os.ReadFile")
+	second := complete("bbb222", "- Medium: Config.Load races with ParseConfig on the cache")
+	complete("ccc333", "No issues found.")
+
+	matches, err := db.SearchReviews(t.Context(), "parseconfig", ReviewSearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchReviews: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+	if !strings.Contains(matches[0].Snippet, "[ParseConfig]") {
+		t.Errorf("expected the term highlighted in the snippet, got %q", matches[0].Snippet)
+	}
+
+	matches, err = db.SearchReviews(t.Context(), "Config.Load cache", ReviewSearchFilter{})
+	if err != nil || len(matches) != 1 || matches[0].JobID != second.ID || matches[0].Verdict != "F" {
+		t.Errorf("expected only job %d for Config.Load, got %+v, %v", second.ID, matches, err)
+	}
+
+	// The prompt is searched too
+	matches, err = db.SearchReviews(t.Context(), "ccc*", ReviewSearchFilter{})
+	if err != nil || len(matches) != 1 || matches[0].GitRef != "ccc333" {
+		t.Errorf("expected a prefix match on the prompt, got %+v, %v", matches, err)
+	}
+
+	// Filters
+	if matches, _ := db.SearchReviews(t.Context(), "ParseConfig", ReviewSearchFilter{Agent: "claude-code"}); len(matches) != 0 {
+		t.Errorf("expected no matches for another agent, got %+v", matches)
+	}
+	if matches, _ := db.SearchReviews(t.Context(), "ParseConfig", ReviewSearchFilter{Since: time.Now().Add(time.Hour)}); len(matches) != 0 {
+		t.Errorf("expected no matches for a future start, got %+v", matches)
+	}
+	if matches, _ := db.SearchReviews(t.Context(), "ParseConfig", ReviewSearchFilter{Limit: 1}); len(matches) != 1 {
+		t.Errorf("expected the limit to cap matches, got %+v", matches)
+	}
+
+	// Changing or deleting a review updates the index
+	if _, err := db.Exec(`UPDATE reviews SET output = 'Nothing to see' WHERE job_id = ?`, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM reviews WHERE job_id = ?`, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if matches, err := db.SearchReviews(t.Context(), "ParseConfig", ReviewSearchFilter{}); err != nil || len(matches) != 0 {
+		t.Errorf("expected no matches after the reviews changed, got %+v, %v", matches, err)
+	}
+
+	if _, err := db.SearchReviews(t.Context(), ` "" `, ReviewSearchFilter{}); !errors.Is(err, ErrEmptySearch) {
+		t.Errorf("expected ErrEmptySearch, got %v", err)
+	}
+}
+
+func TestReviewSearchMigration(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, job := createJobChain(t, db, "/tmp/search-repo", "aaa111")
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "- Low: Unused helper formatWidget"); err != nil {
+		t.Fatal(err)
+	}
+	// A database from before the search index
+	for _, stmt := range []string{
+		`DROP TRIGGER reviews_fts_insert`, `DROP TRIGGER reviews_fts_delete`,
+		`DROP TRIGGER reviews_fts_update`, `DROP TABLE reviews_fts`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	matches, err := db.SearchReviews(t.Context(), "formatWidget", ReviewSearchFilter{})
+	if err != nil || len(matches) != 1 {
+		t.Errorf("expected the existing review to be indexed, got %+v, %v", matches, err)
+	}
+}
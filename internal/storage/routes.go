@@ -0,0 +1,38 @@
+package storage
+
+// AddJobRoutes records the monorepo routes a job's changes matched. Routes
+// already recorded for the job are kept.
+func (db *DB) AddJobRoutes(jobID int64, routes []string) error {
+	if len(routes) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, route := range routes {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO job_routes (job_id, route) VALUES (?, ?)`, jobID, route); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetJobRoutes returns the monorepo routes recorded for a job, sorted by name
+func (db *DB) GetJobRoutes(jobID int64) ([]string, error) {
+	rows, err := db.Query(`SELECT route FROM job_routes WHERE job_id = ? ORDER BY route`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var routes []string
+	for rows.Next() {
+		var route string
+		if err := rows.Scan(&route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
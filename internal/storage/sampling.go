@@ -0,0 +1,27 @@
+package storage
+
+// SampledOutPrefix starts the skip reason of commits a repo's sampling
+// policy left unreviewed, so coverage reports can tell them apart from
+// commits skipped on purpose
+const SampledOutPrefix = "sampled out"
+
+// CountCommitReviewJobs returns how many single-commit review jobs a repo
+// has, including skipped ones
+func (db *DB) CountCommitReviewJobs(repoID int64) (int, error) {
+	var n int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM review_jobs
+		WHERE repo_id = ? AND job_type = 'review' AND commit_id IS NOT NULL
+	`, repoID).Scan(&n)
+	return n, err
+}
+
+// countSampledOutJobs returns how many of a repo's commits were sampled out
+func (db *DB) countSampledOutJobs(repoID int64) (int, error) {
+	var n int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM review_jobs
+		WHERE repo_id = ? AND status = 'skipped' AND error LIKE ? || '%'
+	`, repoID, SampledOutPrefix).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,101 @@
+-- PostgreSQL storage backend schema version 1
+-- Holds the review database itself (repos, commits, the job queue, reviews
+-- and comments) for machines that share one database. It is separate from
+-- the roborev schema, which mirrors local databases for sync.
+-- Note: Version is managed by PgStore.EnsureSchema(), not this file.
+
+CREATE SCHEMA IF NOT EXISTS roborev_store;
+
+CREATE TABLE IF NOT EXISTS roborev_store.schema_version (
+  version INTEGER PRIMARY KEY,
+  applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS roborev_store.repos (
+  id BIGSERIAL PRIMARY KEY,
+  root_path TEXT UNIQUE NOT NULL,
+  name TEXT NOT NULL,
+  identity TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS roborev_store.commits (
+  id BIGSERIAL PRIMARY KEY,
+  repo_id BIGINT NOT NULL REFERENCES roborev_store.repos(id),
+  sha TEXT NOT NULL,
+  author TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  UNIQUE(repo_id, sha)
+);
+
+CREATE TABLE IF NOT EXISTS roborev_store.review_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  uuid TEXT UNIQUE NOT NULL,
+  repo_id BIGINT NOT NULL REFERENCES roborev_store.repos(id),
+  commit_id BIGINT REFERENCES roborev_store.commits(id),
+  git_ref TEXT NOT NULL,
+  branch TEXT,
+  agent TEXT NOT NULL,
+  model TEXT,
+  reasoning TEXT NOT NULL DEFAULT 'thorough',
+  job_type TEXT NOT NULL DEFAULT 'review',
+  review_type TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'queued'
+    CHECK(status IN ('queued', 'running', 'done', 'failed', 'canceled', 'skipped', 'blocked')),
+  lane TEXT NOT NULL DEFAULT 'background',
+  priority INTEGER NOT NULL DEFAULT 0,
+  hold_until TIMESTAMP WITH TIME ZONE,
+  agentic BOOLEAN NOT NULL DEFAULT FALSE,
+  focus BOOLEAN NOT NULL DEFAULT FALSE,
+  protected_branch BOOLEAN NOT NULL DEFAULT FALSE,
+  truncation_level INTEGER NOT NULL DEFAULT 0,
+  enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  started_at TIMESTAMP WITH TIME ZONE,
+  finished_at TIMESTAMP WITH TIME ZONE,
+  worker_id TEXT,
+  claimed_machine_id TEXT,
+  error TEXT,
+  error_code TEXT,
+  prompt TEXT,
+  diff_content TEXT,
+  output_prefix TEXT,
+  agent_policy TEXT,
+  retry_count INTEGER NOT NULL DEFAULT 0,
+  source_machine_id TEXT NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS roborev_store.reviews (
+  id BIGSERIAL PRIMARY KEY,
+  job_id BIGINT UNIQUE NOT NULL REFERENCES roborev_store.review_jobs(id),
+  agent TEXT NOT NULL,
+  prompt TEXT NOT NULL,
+  output TEXT NOT NULL,
+  addressed BOOLEAN NOT NULL DEFAULT FALSE,
+  uuid TEXT UNIQUE NOT NULL,
+  signature TEXT,
+  signing_key TEXT,
+  updated_by_machine_id TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS roborev_store.responses (
+  id BIGSERIAL PRIMARY KEY,
+  commit_id BIGINT REFERENCES roborev_store.commits(id),
+  job_id BIGINT REFERENCES roborev_store.review_jobs(id),
+  responder TEXT NOT NULL,
+  response TEXT NOT NULL,
+  uuid TEXT UNIQUE NOT NULL,
+  source_machine_id TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_jobs_claim ON roborev_store.review_jobs(status, lane, priority, enqueued_at);
+CREATE INDEX IF NOT EXISTS idx_store_jobs_repo ON roborev_store.review_jobs(repo_id);
+CREATE INDEX IF NOT EXISTS idx_store_jobs_git_ref ON roborev_store.review_jobs(git_ref);
+CREATE INDEX IF NOT EXISTS idx_store_commits_sha ON roborev_store.commits(sha);
+CREATE INDEX IF NOT EXISTS idx_store_responses_job ON roborev_store.responses(job_id);
+CREATE INDEX IF NOT EXISTS idx_store_responses_commit ON roborev_store.responses(commit_id);
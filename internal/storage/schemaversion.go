@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the SQLite schema version this binary migrates to. It is
+// stored in PRAGMA user_version so a binary sharing the database with a newer
+// one (an old daemon after the CLI was upgraded, or the reverse) can tell it
+// is behind. Bump it whenever migrate gains a step.
+const SchemaVersion = 14
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer
+// roborev than the one running
+var ErrSchemaTooNew = errors.New("database schema is newer than this roborev supports")
+
+// StoredSchemaVersion returns the schema version recorded in the database
+func (db *DB) StoredSchemaVersion() (int, error) {
+	var v int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&v); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return v, nil
+}
+
+// CheckSchemaCompatible returns ErrSchemaTooNew if the database was migrated
+// past SchemaVersion. Writers should check it first: columns and constraints
+// added by the newer binary are unknown to this one.
+func (db *DB) CheckSchemaCompatible() error {
+	v, err := db.StoredSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if v > SchemaVersion {
+		return fmt.Errorf("%w (database v%d, supported v%d)", ErrSchemaTooNew, v, SchemaVersion)
+	}
+	return nil
+}
+
+// recordSchemaVersion stores SchemaVersion after migrations ran. A higher
+// version left by a newer binary is kept.
+func (db *DB) recordSchemaVersion() error {
+	v, err := db.StoredSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if v >= SchemaVersion {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion)); err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	v, err := db.StoredSchemaVersion()
+	if err != nil {
+		t.Fatalf("StoredSchemaVersion: %v", err)
+	}
+	if v != SchemaVersion {
+		t.Errorf("expected schema version %d after Open, got %d", SchemaVersion, v)
+	}
+	if err := db.CheckSchemaCompatible(); err != nil {
+		t.Errorf("expected compatible schema, got %v", err)
+	}
+
+	// A newer binary migrated the database
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CheckSchemaCompatible(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+	db.Close()
+
+	// Reopening with this binary must not downgrade the recorded version
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	if v, _ := db.StoredSchemaVersion(); v != SchemaVersion+1 {
+		t.Errorf("expected schema version %d to be kept, got %d", SchemaVersion+1, v)
+	}
+}
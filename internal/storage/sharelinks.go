@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// ShareLink gives anyone holding its token read access to one review,
+// until it expires or is revoked
+type ShareLink struct {
+	ID        int64      `json:"id"`
+	Token     string     `json:"token"`
+	JobID     int64      `json:"job_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the link's expiry has passed at now
+func (l *ShareLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// CreateShareLink creates a link to the review of a job. A ttl of zero
+// creates a link that does not expire.
+func (db *DB) CreateShareLink(jobID int64, ttl time.Duration) (*ShareLink, error) {
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM reviews WHERE job_id = ?`, jobID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, fmt.Errorf("job %d has no review", jobID)
+	}
+
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate token: %w", err)
+	}
+	link := ShareLink{
+		Token:     base64.RawURLEncoding.EncodeToString(b),
+		JobID:     jobID,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	var expiresAt sql.NullString
+	if ttl > 0 {
+		exp := link.CreatedAt.Add(ttl)
+		link.ExpiresAt = &exp
+		expiresAt = sql.NullString{String: exp.Format(time.RFC3339), Valid: true}
+	}
+
+	result, err := db.Exec(`INSERT INTO share_links (token, job_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		link.Token, jobID, link.CreatedAt.Format(time.RFC3339), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if link.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetShareLink returns the link with the given token. Returns sql.ErrNoRows
+// if there is none or it has expired.
+func (db *DB) GetShareLink(token string) (*ShareLink, error) {
+	links, err := db.queryShareLinks(`WHERE token = ?`, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 || links[0].Expired(time.Now()) {
+		return nil, sql.ErrNoRows
+	}
+	return &links[0], nil
+}
+
+// ListShareLinks returns the unexpired links to a job's review, newest first
+func (db *DB) ListShareLinks(jobID int64) ([]ShareLink, error) {
+	links, err := db.queryShareLinks(`WHERE job_id = ? ORDER BY id DESC`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	live := links[:0]
+	for _, l := range links {
+		if !l.Expired(now) {
+			live = append(live, l)
+		}
+	}
+	return live, nil
+}
+
+// DeleteShareLink revokes the link with the given token. Returns
+// sql.ErrNoRows if there is none.
+func (db *DB) DeleteShareLink(token string) error {
+	result, err := db.Exec(`DELETE FROM share_links WHERE token = ?`, token)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteExpiredShareLinks removes links whose expiry has passed and returns
+// how many there were
+func (db *DB) DeleteExpiredShareLinks() (int64, error) {
+	result, err := db.Exec(`DELETE FROM share_links WHERE expires_at IS NOT NULL AND expires_at <= ?`,
+		time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (db *DB) queryShareLinks(where string, args ...any) ([]ShareLink, error) {
+	rows, err := db.Query(`SELECT id, token, job_id, created_at, expires_at FROM share_links `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []ShareLink
+	for rows.Next() {
+		var l ShareLink
+		var createdAt string
+		var expiresAt sql.NullString
+		if err := rows.Scan(&l.ID, &l.Token, &l.JobID, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		l.CreatedAt = parseSQLiteTime(createdAt)
+		if expiresAt.Valid {
+			exp := parseSQLiteTime(expiresAt.String)
+			l.ExpiresAt = &exp
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShareLinks(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	_, _, queued := createJobChain(t, db, t.TempDir(), "queued")
+	if _, err := db.CreateShareLink(queued.ID, 0); err == nil {
+		t.Error("expected an error sharing a job without a review")
+	}
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), queued.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+
+	forever, err := db.CreateShareLink(queued.ID, 0)
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	if forever.ExpiresAt != nil || len(forever.Token) < 12 {
+		t.Errorf("unexpected link %+v", forever)
+	}
+	got, err := db.GetShareLink(forever.Token)
+	if err != nil || got.JobID != queued.ID {
+		t.Fatalf("GetShareLink = %+v, %v", got, err)
+	}
+
+	daily, err := db.CreateShareLink(queued.ID, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	if daily.ExpiresAt == nil || daily.Token == forever.Token {
+		t.Errorf("unexpected link %+v", daily)
+	}
+
+	// Backdate the daily link past its expiry
+	if _, err := db.Exec(`UPDATE share_links SET expires_at = ? WHERE token = ?`,
+		time.Now().Add(-time.Minute).UTC().Format(time.RFC3339), daily.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetShareLink(daily.Token); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for an expired link, got %v", err)
+	}
+	links, err := db.ListShareLinks(queued.ID)
+	if err != nil || len(links) != 1 || links[0].Token != forever.Token {
+		t.Errorf("ListShareLinks = %+v, %v; want only the unexpired link", links, err)
+	}
+	if n, err := db.DeleteExpiredShareLinks(); err != nil || n != 1 {
+		t.Errorf("DeleteExpiredShareLinks = %d, %v; want 1", n, err)
+	}
+
+	if err := db.DeleteShareLink(forever.Token); err != nil {
+		t.Fatalf("DeleteShareLink: %v", err)
+	}
+	if err := db.DeleteShareLink(forever.Token); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+	if _, err := db.GetShareLink(forever.Token); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a revoked link, got %v", err)
+	}
+}
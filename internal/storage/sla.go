@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SLAJob is a review job checked against its repo's review SLA
+type SLAJob struct {
+	JobID      int64
+	RepoPath   string
+	RepoName   string
+	GitRef     string
+	Agent      string
+	Status     JobStatus
+	EnqueuedAt time.Time
+	FinishedAt *time.Time
+}
+
+// RepoSLAStats summarizes how a repo's finished reviews met its SLA
+type RepoSLAStats struct {
+	RepoPath       string  `json:"repo_path"`
+	RepoName       string  `json:"repo_name"`
+	Reviews        int     `json:"reviews"`          // Reviews finished
+	Breaches       int     `json:"breaches"`         // Reviews that finished after the SLA
+	AvgWaitSeconds float64 `json:"avg_wait_seconds"` // Mean time from enqueue to finish
+}
+
+// ListSLAJobs returns the review jobs with no recorded SLA breach that are
+// queued or running, or finished after finishedSince. Task jobs and parts of
+// fanned-out reviews are left out.
+func (db *DB) ListSLAJobs(finishedSince time.Time) ([]SLAJob, error) {
+	rows, err := db.Query(`
+		SELECT j.id, r.root_path, r.name, j.git_ref, j.agent, j.status, j.enqueued_at, j.finished_at
+		FROM review_jobs j
+		JOIN repos r ON r.id = j.repo_id
+		WHERE j.job_type != 'task'
+		  AND (j.status IN ('queued', 'running')
+		       OR (j.status IN ('done', 'failed') AND j.finished_at IS NOT NULL
+		           AND datetime(j.finished_at) > datetime(?)))
+		  AND NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+		  AND NOT EXISTS (SELECT 1 FROM sla_breaches b WHERE b.job_id = j.id)
+		ORDER BY j.id
+	`, finishedSince.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []SLAJob
+	for rows.Next() {
+		var j SLAJob
+		var enqueuedAt string
+		var finishedAt sql.NullString
+		if err := rows.Scan(&j.JobID, &j.RepoPath, &j.RepoName, &j.GitRef, &j.Agent, &j.Status, &enqueuedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		j.EnqueuedAt = parseSQLiteTime(enqueuedAt)
+		if finishedAt.Valid {
+			t := parseSQLiteTime(finishedAt.String)
+			j.FinishedAt = &t
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// RecordSLABreach records that a job missed its repo's SLA. Returns false if
+// the breach was already recorded.
+func (db *DB) RecordSLABreach(jobID int64, sla time.Duration) (bool, error) {
+	result, err := db.Exec(`INSERT OR IGNORE INTO sla_breaches (job_id, sla_minutes) VALUES (?, ?)`,
+		jobID, int(sla/time.Minute))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// CountOverdueJobs returns how many queued or running jobs are past their
+// repo's SLA
+func (db *DB) CountOverdueJobs() (int, error) {
+	var n int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM review_jobs j
+		JOIN sla_breaches b ON b.job_id = j.id
+		WHERE j.status IN ('queued', 'running')
+	`).Scan(&n)
+	return n, err
+}
+
+// GetSLAStats returns, per repo, the reviews enqueued since the given time
+// that have finished and how many of them breached the repo's SLA
+func (db *DB) GetSLAStats(since time.Time) ([]RepoSLAStats, error) {
+	rows, err := db.Query(`
+		SELECT r.root_path, r.name, COUNT(*), COUNT(b.job_id), COALESCE(AVG(
+			CAST(strftime('%s', j.finished_at) AS INTEGER) - CAST(strftime('%s', j.enqueued_at) AS INTEGER)
+		), 0)
+		FROM review_jobs j
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN sla_breaches b ON b.job_id = j.id
+		WHERE j.job_type != 'task' AND j.status IN ('done', 'failed') AND j.finished_at IS NOT NULL
+		  AND datetime(j.enqueued_at) >= datetime(?)
+		  AND NOT EXISTS (SELECT 1 FROM job_parts jp WHERE jp.job_id = j.id)
+		GROUP BY r.id
+		ORDER BY r.name
+	`, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []RepoSLAStats
+	for rows.Next() {
+		var s RepoSLAStats
+		if err := rows.Scan(&s.RepoPath, &s.RepoName, &s.Reviews, &s.Breaches, &s.AvgWaitSeconds); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLABreaches(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/sla-repo")
+	var jobs []*ReviewJob
+	for _, sha := range []string{"sla1", "sla2", "sla3"} {
+		commit := createCommit(t, db, repo.ID, sha)
+		jobs = append(jobs, enqueueJob(t, db, repo.ID, commit.ID, sha))
+	}
+	done := claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), done.ID, "codex", "prompt", "No issues found."); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	enqueued := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`UPDATE review_jobs SET enqueued_at = ?`, enqueued); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := db.ListSLAJobs(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("ListSLAJobs: %v", err)
+	}
+	if len(listed) != 3 {
+		t.Fatalf("expected 3 jobs to check, got %+v", listed)
+	}
+	if listed[0].FinishedAt == nil || listed[1].FinishedAt != nil || listed[0].RepoPath != "/tmp/sla-repo" {
+		t.Errorf("unexpected jobs %+v", listed)
+	}
+
+	// Jobs that finished before the window are not checked again
+	if listed, err := db.ListSLAJobs(time.Now().Add(time.Minute)); err != nil || len(listed) != 2 {
+		t.Errorf("expected 2 unfinished jobs, got %+v (err %v)", listed, err)
+	}
+
+	for _, j := range []*ReviewJob{done, jobs[1]} {
+		recorded, err := db.RecordSLABreach(j.ID, 30*time.Minute)
+		if err != nil || !recorded {
+			t.Fatalf("RecordSLABreach(%d) = %v, %v", j.ID, recorded, err)
+		}
+	}
+	if recorded, err := db.RecordSLABreach(done.ID, 30*time.Minute); err != nil || recorded {
+		t.Errorf("expected repeated breach to be ignored, got %v, %v", recorded, err)
+	}
+
+	listed, err = db.ListSLAJobs(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("ListSLAJobs: %v", err)
+	}
+	if len(listed) != 1 || listed[0].JobID != jobs[2].ID {
+		t.Errorf("expected only the unbreached job, got %+v", listed)
+	}
+
+	if n, err := db.CountOverdueJobs(); err != nil || n != 1 {
+		t.Errorf("CountOverdueJobs() = %d, %v; want 1", n, err)
+	}
+
+	listJobs, err := db.ListJobs(t.Context(), "", "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	for _, j := range listJobs {
+		if want := j.ID != jobs[2].ID; j.Overdue != want {
+			t.Errorf("job %d: Overdue = %v, want %v", j.ID, j.Overdue, want)
+		}
+	}
+
+	stats, err := db.GetSLAStats(time.Now().Add(-2 * time.Hour))
+	if err != nil {
+		t.Fatalf("GetSLAStats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Reviews != 1 || stats[0].Breaches != 1 || stats[0].RepoName != repo.Name {
+		t.Errorf("unexpected SLA stats %+v", stats)
+	}
+	if stats[0].AvgWaitSeconds < 3500 {
+		t.Errorf("expected about an hour of wait, got %vs", stats[0].AvgWaitSeconds)
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Storage is the part of the review database a backend must provide:
+// repos, commits, jobs, reviews and comments. DB, the SQLite database, is
+// the built-in implementation; code that only needs these operations can
+// accept a Storage so another backend can be plugged in. Features built on
+// the other tables (triage, SLAs, sync and so on) still need a DB.
+//
+// Every method takes a context whose cancellation interrupts its queries,
+// including one waiting on a locked database.
+type Storage interface {
+	RepoStore
+	CommitStore
+	JobStore
+	ReviewStore
+	CommentStore
+
+	Close() error
+}
+
+// RepoStore stores the repositories reviews belong to
+type RepoStore interface {
+	GetOrCreateRepo(ctx context.Context, rootPath string, identity ...string) (*Repo, error)
+	GetRepoByPath(ctx context.Context, rootPath string) (*Repo, error)
+	GetRepoByID(ctx context.Context, id int64) (*Repo, error)
+	GetRepoByName(ctx context.Context, name string) (*Repo, error)
+	FindRepo(ctx context.Context, identifier string) (*Repo, error)
+	ListRepos(ctx context.Context) ([]Repo, error)
+	GetRepoStats(ctx context.Context, repoID int64) (*RepoStats, error)
+	RenameRepo(ctx context.Context, identifier, newName string) (int64, error)
+	DeleteRepo(ctx context.Context, repoID int64, cascade bool) error
+}
+
+// CommitStore stores the commits of repos
+type CommitStore interface {
+	GetOrCreateCommit(ctx context.Context, repoID int64, sha, author, subject string, timestamp time.Time) (*Commit, error)
+	GetCommitByID(ctx context.Context, id int64) (*Commit, error)
+	GetCommitBySHA(ctx context.Context, sha string) (*Commit, error)
+	GetCommitByRepoAndSHA(ctx context.Context, repoID int64, sha string) (*Commit, error)
+}
+
+// JobStore is the job queue
+type JobStore interface {
+	EnqueueJob(ctx context.Context, opts EnqueueOpts) (*ReviewJob, error)
+	EnqueueJobWithPriority(ctx context.Context, opts EnqueueOpts, priority int) (*ReviewJob, error)
+	ClaimJob(ctx context.Context, workerID string) (*ReviewJob, error)
+	ClaimJobInLane(ctx context.Context, workerID, lane string) (*ReviewJob, error)
+	SaveJobPrompt(ctx context.Context, jobID int64, prompt string) error
+	CompleteJob(ctx context.Context, jobID int64, agent, prompt, output string) error
+	FailJob(ctx context.Context, jobID int64, errorMsg string) error
+	CancelJob(ctx context.Context, jobID int64) error
+	ReenqueueJob(ctx context.Context, jobID int64) error
+	RetryJob(ctx context.Context, jobID int64, maxRetries int) (bool, error)
+	GetJobRetryCount(ctx context.Context, jobID int64) (int, error)
+	ResetStaleJobs(ctx context.Context) error
+	GetJobByID(ctx context.Context, id int64) (*ReviewJob, error)
+	ListJobs(ctx context.Context, statusFilter string, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]ReviewJob, error)
+	CountJobStats(ctx context.Context, repoFilter string, opts ...ListJobsOption) (JobStats, error)
+	UpdateJobBranch(ctx context.Context, jobID int64, branch string) (int64, error)
+	CountJobsByStatus(ctx context.Context) (JobCounts, error)
+}
+
+// ReviewStore stores the reviews completed jobs produce
+type ReviewStore interface {
+	GetReviewByID(ctx context.Context, reviewID int64) (*Review, error)
+	GetReviewByJobID(ctx context.Context, jobID int64) (*Review, error)
+	GetReviewByCommitSHA(ctx context.Context, sha string) (*Review, error)
+	GetAllReviewsForGitRef(ctx context.Context, gitRef string) ([]Review, error)
+	GetRecentReviewsForRepo(ctx context.Context, repoID int64, limit int) ([]Review, error)
+	ListReviews(ctx context.Context, repoFilter string, limit, offset int, opts ...ListJobsOption) ([]Review, error)
+	GetCommitStatus(ctx context.Context, repoRoot, sha string) (CommitStatus, error)
+	MarkReviewAddressed(ctx context.Context, reviewID int64, addressed bool) error
+	MarkReviewAddressedByJobID(ctx context.Context, jobID int64, addressed bool) error
+}
+
+// CommentStore stores comments on reviews
+type CommentStore interface {
+	AddComment(ctx context.Context, commitID int64, responder, response string) (*Response, error)
+	AddCommentToJob(ctx context.Context, jobID int64, responder, response string) (*Response, error)
+	GetCommentsForCommit(ctx context.Context, commitID int64) ([]Response, error)
+	GetCommentsForJob(ctx context.Context, jobID int64) ([]Response, error)
+	GetCommentsForCommitSHA(ctx context.Context, sha string) ([]Response, error)
+}
+
+// QueueNotifier is implemented by backends that can tell workers when jobs
+// may have become claimable, so idle workers need not poll. Only changes
+// made through the same process are seen; workers still poll now and then
+// for jobs queued elsewhere.
+type QueueNotifier interface {
+	QueueChanged() <-chan struct{}
+	NotifyQueueChanged()
+}
+
+var (
+	_ Storage = (*DB)(nil)
+	_ Storage = (*PgStore)(nil)
+
+	_ QueueNotifier = (*DB)(nil)
+	_ QueueNotifier = (*PgStore)(nil)
+)
@@ -0,0 +1,184 @@
+package storage
+
+import "fmt"
+
+// FindingSuppression records that an entry of the repo's .roborev-ignore
+// file matched a finding when its review completed. Suppressed findings
+// stay in the review's output but are left out of triage, open findings
+// and hotspots.
+type FindingSuppression struct {
+	FindingIndex  int    `json:"finding_index"`
+	Entry         string `json:"entry"` // The matching entry as written in the file
+	Justification string `json:"justification"`
+}
+
+// FindingCounts counts the findings of completed reviews
+type FindingCounts struct {
+	Reported   int `json:"reported"`   // Not suppressed
+	Suppressed int `json:"suppressed"` // Matched by .roborev-ignore
+}
+
+// SaveFindingSuppressions records the suppressed findings of a job's
+// review, replacing those recorded for an earlier run
+func (db *DB) SaveFindingSuppressions(jobID int64, suppressions []FindingSuppression) error {
+	var reviewID int64
+	if err := db.QueryRow(`SELECT id FROM reviews WHERE job_id = ?`, jobID).Scan(&reviewID); err != nil {
+		return fmt.Errorf("load review: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM finding_suppressions WHERE review_id = ?`, reviewID); err != nil {
+		return err
+	}
+	for _, s := range suppressions {
+		if _, err := tx.Exec(`
+			INSERT INTO finding_suppressions (review_id, finding_index, entry, justification)
+			VALUES (?, ?, ?, ?)
+		`, reviewID, s.FindingIndex, s.Entry, s.Justification); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFindingSuppressions returns the suppressed findings of a review,
+// keyed by finding index
+func (db *DB) GetFindingSuppressions(reviewID int64) (map[int]FindingSuppression, error) {
+	rows, err := db.Query(`
+		SELECT finding_index, entry, justification FROM finding_suppressions WHERE review_id = ?
+	`, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppressions := make(map[int]FindingSuppression)
+	for rows.Next() {
+		var s FindingSuppression
+		if err := rows.Scan(&s.FindingIndex, &s.Entry, &s.Justification); err != nil {
+			return nil, err
+		}
+		suppressions[s.FindingIndex] = s
+	}
+	return suppressions, rows.Err()
+}
+
+// suppressedFindings returns the suppressions of all findings, keyed by
+// review and finding index
+func (db *DB) suppressedFindings() (map[triageKey]FindingSuppression, error) {
+	rows, err := db.Query(`SELECT review_id, finding_index, entry, justification FROM finding_suppressions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppressed := make(map[triageKey]FindingSuppression)
+	for rows.Next() {
+		var key triageKey
+		var s FindingSuppression
+		if err := rows.Scan(&key.reviewID, &key.index, &s.Entry, &s.Justification); err != nil {
+			return nil, err
+		}
+		s.FindingIndex = key.index
+		suppressed[key] = s
+	}
+	return suppressed, rows.Err()
+}
+
+// ListSuppressedFindings returns the suppressed findings of completed
+// reviews, newest review first, along with their total. repoID 0 includes
+// all repos.
+func (db *DB) ListSuppressedFindings(repoID int64, limit int) ([]TriageItem, int, error) {
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT rv.id, rv.job_id, rv.agent, rv.output, rv.created_at, j.git_ref, rp.name
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos rp ON rp.id = j.repo_id
+		WHERE j.status = 'done' AND rv.id IN (SELECT review_id FROM finding_suppressions)`
+	var args []any
+	if repoID != 0 {
+		query += ` AND j.repo_id = ?`
+		args = append(args, repoID)
+	}
+	query += ` ORDER BY rv.created_at DESC, rv.id DESC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []TriageItem{}
+	total := 0
+	for rows.Next() {
+		var item TriageItem
+		var output, createdAt string
+		if err := rows.Scan(&item.ReviewID, &item.JobID, &item.Agent, &output, &createdAt,
+			&item.GitRef, &item.RepoName); err != nil {
+			return nil, 0, err
+		}
+		item.CreatedAt = parseSQLiteTime(createdAt)
+
+		for _, f := range ExtractFindings(db.loadBlob(output)) {
+			s, ok := suppressed[triageKey{item.ReviewID, f.Index}]
+			if !ok {
+				continue
+			}
+			total++
+			if len(items) < limit {
+				item.Finding = f
+				item.Suppression = &s
+				items = append(items, item)
+			}
+		}
+	}
+	return items, total, rows.Err()
+}
+
+// CountFindings counts the findings of completed reviews, with the
+// suppressed ones counted separately. Task jobs are excluded since their
+// output is not a review.
+func (db *DB) CountFindings() (FindingCounts, error) {
+	var counts FindingCounts
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return counts, err
+	}
+
+	rows, err := db.Query(`
+		SELECT rv.id, rv.output
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		WHERE j.status = 'done' AND COALESCE(j.job_type, '') != 'task'
+	`)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reviewID int64
+		var output string
+		if err := rows.Scan(&reviewID, &output); err != nil {
+			return counts, err
+		}
+		for _, f := range ExtractFindings(db.loadBlob(output)) {
+			if _, ok := suppressed[triageKey{reviewID, f.Index}]; ok {
+				counts.Suppressed++
+			} else {
+				counts.Reported++
+			}
+		}
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindingSuppressions(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/suppress-repo")
+	commit := createCommit(t, db, repo.ID, "supsha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "supsha")
+	claimJob(t, db, "worker")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "- High: real bug in main.go\n- Low: nit in vendor/x.go"); err != nil {
+		t.Fatalf("CompleteJob: %v", err)
+	}
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil {
+		t.Fatalf("GetReviewByJobID: %v", err)
+	}
+
+	sup := FindingSuppression{FindingIndex: 1, Entry: "path:vendor/", Justification: "Vendored code"}
+	if err := db.SaveFindingSuppressions(job.ID, []FindingSuppression{sup}); err != nil {
+		t.Fatalf("SaveFindingSuppressions: %v", err)
+	}
+
+	// Suppressed findings are hidden from triage, open findings and hotspots
+	items, total, err := db.ListUntriagedFindings(repo.ID, 10)
+	if err != nil || total != 1 || items[0].Finding.Index != 0 {
+		t.Errorf("expected only the unsuppressed finding untriaged, got %+v (total %d, %v)", items, total, err)
+	}
+	if open, err := db.ListOpenFindings(repo.ID, time.Time{}); err != nil || len(open) != 1 {
+		t.Errorf("expected 1 open finding, got %+v, %v", open, err)
+	}
+	if findings, err := db.ListFindingsSince(repo.ID, time.Time{}); err != nil || len(findings) != 1 {
+		t.Errorf("expected 1 finding for hotspots, got %+v, %v", findings, err)
+	}
+	if st, err := db.GetCommitStatus(t.Context(), "/tmp/suppress-repo", "supsha"); err != nil || st.OpenFindings != 1 {
+		t.Errorf("expected 1 open finding in commit status, got %+v, %v", st, err)
+	}
+
+	// But are still stored and listed on request
+	items, total, err = db.ListSuppressedFindings(0, 10)
+	if err != nil || total != 1 || items[0].ReviewID != review.ID || items[0].Suppression == nil ||
+		*items[0].Suppression != sup || items[0].Finding.Text != "- Low: nit in vendor/x.go" {
+		t.Errorf("unexpected suppressed findings %+v (total %d, %v)", items, total, err)
+	}
+	got, err := db.GetFindingSuppressions(review.ID)
+	if err != nil || got[1] != sup {
+		t.Errorf("GetFindingSuppressions = %+v, %v", got, err)
+	}
+	counts, err := db.CountFindings()
+	if err != nil || counts != (FindingCounts{Reported: 1, Suppressed: 1}) {
+		t.Errorf("CountFindings = %+v, %v", counts, err)
+	}
+
+	// Saving again replaces the earlier suppressions
+	if err := db.SaveFindingSuppressions(job.ID, nil); err != nil {
+		t.Fatalf("SaveFindingSuppressions: %v", err)
+	}
+	if counts, _ := db.CountFindings(); counts != (FindingCounts{Reported: 2}) {
+		t.Errorf("expected suppressions to be replaced, got %+v", counts)
+	}
+
+	// A rerun drops them with the review
+	if err := db.SaveFindingSuppressions(job.ID, []FindingSuppression{sup}); err != nil {
+		t.Fatalf("SaveFindingSuppressions: %v", err)
+	}
+	if err := db.ReenqueueJob(t.Context(), job.ID); err != nil {
+		t.Fatalf("ReenqueueJob: %v", err)
+	}
+	if _, total, _ := db.ListSuppressedFindings(0, 10); total != 0 {
+		t.Errorf("expected suppressions to be dropped on rerun, got %d", total)
+	}
+}
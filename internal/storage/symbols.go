@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChangedSymbol is a function, method or type a reviewed diff touches.
+// Methods are named "Type.Method".
+type ChangedSymbol struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// SymbolMatch is a job whose diff touched a searched symbol
+type SymbolMatch struct {
+	ChangedSymbol
+	JobID         int64     `json:"job_id"`
+	RepoName      string    `json:"repo_name"`
+	GitRef        string    `json:"git_ref"`
+	Agent         string    `json:"agent"`
+	Status        JobStatus `json:"status"`
+	CommitSubject string    `json:"commit_subject,omitempty"`
+	EnqueuedAt    time.Time `json:"enqueued_at"`
+	Verdict       string    `json:"verdict,omitempty"` // P/F once the review is done
+}
+
+// SaveJobSymbols records the symbols a job's diff changes, replacing those
+// recorded by an earlier run of the same job.
+func (db *DB) SaveJobSymbols(jobID int64, symbols []ChangedSymbol) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM job_symbols WHERE job_id = ?`, jobID); err != nil {
+		return err
+	}
+	for _, s := range symbols {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO job_symbols (job_id, name, file) VALUES (?, ?, ?)`,
+			jobID, s.Name, s.File); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetJobSymbols returns the symbols recorded for a job, by file and name
+func (db *DB) GetJobSymbols(jobID int64) ([]ChangedSymbol, error) {
+	rows, err := db.Query(`SELECT name, file FROM job_symbols WHERE job_id = ? ORDER BY file, name`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []ChangedSymbol
+	for rows.Next() {
+		var s ChangedSymbol
+		if err := rows.Scan(&s.Name, &s.File); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+// SearchSymbol returns the jobs whose diff changed a symbol, newest first.
+// A plain name also matches methods of that name ("Load" finds
+// "Config.Load"). A non-zero repoID limits the search to one repo; limit
+// caps the results when positive.
+func (db *DB) SearchSymbol(symbol string, repoID int64, limit int) ([]SymbolMatch, error) {
+	query := `
+		SELECT s.name, s.file, j.id, r.name, j.git_ref, j.agent, j.status, j.enqueued_at,
+		       COALESCE(c.subject, ''), rv.output
+		FROM job_symbols s
+		JOIN review_jobs j ON j.id = s.job_id
+		JOIN repos r ON r.id = j.repo_id
+		LEFT JOIN commits c ON c.id = j.commit_id
+		LEFT JOIN reviews rv ON rv.job_id = j.id
+		WHERE (s.name = ? OR substr(s.name, -(length(?) + 1)) = '.' || ?)
+	`
+	args := []any{symbol, symbol, symbol}
+	if repoID != 0 {
+		query += ` AND j.repo_id = ?`
+		args = append(args, repoID)
+	}
+	query += ` ORDER BY j.id DESC, s.file, s.name`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []SymbolMatch
+	for rows.Next() {
+		var m SymbolMatch
+		var enqueuedAt string
+		var output sql.NullString
+		if err := rows.Scan(&m.Name, &m.File, &m.JobID, &m.RepoName, &m.GitRef, &m.Agent, &m.Status,
+			&enqueuedAt, &m.CommitSubject, &output); err != nil {
+			return nil, err
+		}
+		m.EnqueuedAt = parseSQLiteTime(enqueuedAt)
+		if output.Valid {
+			m.Verdict = db.outputVerdict(output.String)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
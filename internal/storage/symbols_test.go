@@ -0,0 +1,76 @@
+package storage
+
+import "testing"
+
+func TestJobSymbols(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/symbols-repo")
+	other := createRepo(t, db, "/tmp/symbols-other")
+	first := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "sym1").ID, "sym1")
+	second := enqueueJob(t, db, repo.ID, createCommit(t, db, repo.ID, "sym2").ID, "sym2")
+	third := enqueueJob(t, db, other.ID, createCommit(t, db, other.ID, "sym3").ID, "sym3")
+
+	save := func(jobID int64, symbols ...ChangedSymbol) {
+		t.Helper()
+		if err := db.SaveJobSymbols(jobID, symbols); err != nil {
+			t.Fatalf("SaveJobSymbols: %v", err)
+		}
+	}
+	save(first.ID, ChangedSymbol{Name: "Stale", File: "old.go"})
+	// A rerun replaces the symbols
+	save(first.ID, ChangedSymbol{Name: "ParseConfig", File: "config.go"}, ChangedSymbol{Name: "Config.Load", File: "config.go"})
+	save(second.ID, ChangedSymbol{Name: "ParseConfigFile", File: "config.go"}, ChangedSymbol{Name: "Loader.Load", File: "load.go"})
+	save(third.ID, ChangedSymbol{Name: "ParseConfig", File: "cfg/parse.go"})
+
+	symbols, err := db.GetJobSymbols(first.ID)
+	if err != nil {
+		t.Fatalf("GetJobSymbols: %v", err)
+	}
+	if len(symbols) != 2 || symbols[0].Name != "Config.Load" || symbols[1].Name != "ParseConfig" {
+		t.Errorf("unexpected symbols %+v", symbols)
+	}
+
+	tests := []struct {
+		name   string
+		symbol string
+		repo   int64
+		want   []int64
+	}{
+		{"exact name across repos", "ParseConfig", 0, []int64{third.ID, first.ID}},
+		{"limited to repo", "ParseConfig", repo.ID, []int64{first.ID}},
+		{"method name matches methods", "Load", 0, []int64{second.ID, first.ID}},
+		{"qualified method", "Config.Load", 0, []int64{first.ID}},
+		{"no partial names", "Parse", 0, nil},
+		{"replaced symbols gone", "Stale", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := db.SearchSymbol(tt.symbol, tt.repo, 0)
+			if err != nil {
+				t.Fatalf("SearchSymbol: %v", err)
+			}
+			var got []int64
+			for _, m := range matches {
+				got = append(got, m.JobID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected jobs %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected jobs %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	matches, err := db.SearchSymbol("ParseConfig", 0, 1)
+	if err != nil {
+		t.Fatalf("SearchSymbol: %v", err)
+	}
+	if len(matches) != 1 || matches[0].RepoName != other.Name || matches[0].File != "cfg/parse.go" || matches[0].CommitSubject != "Subject" {
+		t.Errorf("unexpected limited match %+v", matches)
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -20,9 +21,9 @@ const (
 
 // GetSyncState retrieves a value from the sync_state table.
 // Returns empty string if key doesn't exist.
-func (db *DB) GetSyncState(key string) (string, error) {
+func (db *DB) GetSyncState(ctx context.Context, key string) (string, error) {
 	var value string
-	err := db.QueryRow(`SELECT value FROM sync_state WHERE key = ?`, key).Scan(&value)
+	err := db.QueryRowContext(ctx, `SELECT value FROM sync_state WHERE key = ?`, key).Scan(&value)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -33,8 +34,8 @@ func (db *DB) GetSyncState(key string) (string, error) {
 }
 
 // SetSyncState sets a value in the sync_state table (upsert).
-func (db *DB) SetSyncState(key, value string) error {
-	_, err := db.Exec(`
+func (db *DB) SetSyncState(ctx context.Context, key, value string) error {
+	_, err := db.ExecContext(ctx, `
 		INSERT INTO sync_state (key, value) VALUES (?, ?)
 		ON CONFLICT(key) DO UPDATE SET value = excluded.value
 	`, key, value)
@@ -77,26 +78,26 @@ func (db *DB) GetMachineID() (string, error) {
 
 // BackfillSourceMachineID sets source_machine_id on existing rows that don't have one.
 // This should be called when sync is first enabled.
-func (db *DB) BackfillSourceMachineID() error {
+func (db *DB) BackfillSourceMachineID(ctx context.Context) error {
 	machineID, err := db.GetMachineID()
 	if err != nil {
 		return err
 	}
 
 	// Backfill review_jobs
-	_, err = db.Exec(`UPDATE review_jobs SET source_machine_id = ? WHERE source_machine_id IS NULL`, machineID)
+	_, err = db.ExecContext(ctx, `UPDATE review_jobs SET source_machine_id = ? WHERE source_machine_id IS NULL`, machineID)
 	if err != nil {
 		return fmt.Errorf("backfill review_jobs source_machine_id: %w", err)
 	}
 
 	// Backfill reviews (updated_by_machine_id)
-	_, err = db.Exec(`UPDATE reviews SET updated_by_machine_id = ? WHERE updated_by_machine_id IS NULL`, machineID)
+	_, err = db.ExecContext(ctx, `UPDATE reviews SET updated_by_machine_id = ? WHERE updated_by_machine_id IS NULL`, machineID)
 	if err != nil {
 		return fmt.Errorf("backfill reviews updated_by_machine_id: %w", err)
 	}
 
 	// Backfill responses
-	_, err = db.Exec(`UPDATE responses SET source_machine_id = ? WHERE source_machine_id IS NULL`, machineID)
+	_, err = db.ExecContext(ctx, `UPDATE responses SET source_machine_id = ? WHERE source_machine_id IS NULL`, machineID)
 	if err != nil {
 		return fmt.Errorf("backfill responses source_machine_id: %w", err)
 	}
@@ -107,17 +108,17 @@ func (db *DB) BackfillSourceMachineID() error {
 // ClearAllSyncedAt clears all synced_at timestamps in the database.
 // This is used when syncing to a new Postgres database to ensure
 // all data gets re-synced.
-func (db *DB) ClearAllSyncedAt() error {
+func (db *DB) ClearAllSyncedAt(ctx context.Context) error {
 	// Clear synced_at on review_jobs
-	if _, err := db.Exec(`UPDATE review_jobs SET synced_at = NULL`); err != nil {
+	if _, err := db.ExecContext(ctx, `UPDATE review_jobs SET synced_at = NULL`); err != nil {
 		return fmt.Errorf("clear review_jobs synced_at: %w", err)
 	}
 	// Clear synced_at on reviews
-	if _, err := db.Exec(`UPDATE reviews SET synced_at = NULL`); err != nil {
+	if _, err := db.ExecContext(ctx, `UPDATE reviews SET synced_at = NULL`); err != nil {
 		return fmt.Errorf("clear reviews synced_at: %w", err)
 	}
 	// Clear synced_at on responses
-	if _, err := db.Exec(`UPDATE responses SET synced_at = NULL`); err != nil {
+	if _, err := db.ExecContext(ctx, `UPDATE responses SET synced_at = NULL`); err != nil {
 		return fmt.Errorf("clear responses synced_at: %w", err)
 	}
 	return nil
@@ -126,9 +127,9 @@ func (db *DB) ClearAllSyncedAt() error {
 // BackfillRepoIdentities computes and sets identity for repos that don't have one.
 // Uses config.ResolveRepoIdentity to ensure consistency with new repo creation.
 // Returns the number of repos backfilled.
-func (db *DB) BackfillRepoIdentities() (int, error) {
+func (db *DB) BackfillRepoIdentities(ctx context.Context) (int, error) {
 	// Get repos without identity
-	rows, err := db.Query(`SELECT id, root_path FROM repos WHERE identity IS NULL OR identity = ''`)
+	rows, err := db.QueryContext(ctx, `SELECT id, root_path FROM repos WHERE identity IS NULL OR identity = ''`)
 	if err != nil {
 		return 0, fmt.Errorf("query repos without identity: %w", err)
 	}
@@ -160,7 +161,7 @@ func (db *DB) BackfillRepoIdentities() (int, error) {
 			continue
 		}
 
-		if err := db.SetRepoIdentity(r.id, identity); err != nil {
+		if err := db.SetRepoIdentity(ctx, r.id, identity); err != nil {
 			// May fail due to duplicate identity - skip
 			continue
 		}
@@ -171,8 +172,8 @@ func (db *DB) BackfillRepoIdentities() (int, error) {
 }
 
 // SetRepoIdentity sets the identity for a repo.
-func (db *DB) SetRepoIdentity(repoID int64, identity string) error {
-	_, err := db.Exec(`UPDATE repos SET identity = ? WHERE id = ?`, identity, repoID)
+func (db *DB) SetRepoIdentity(ctx context.Context, repoID int64, identity string) error {
+	_, err := db.ExecContext(ctx, `UPDATE repos SET identity = ? WHERE id = ?`, identity, repoID)
 	if err != nil {
 		return fmt.Errorf("set repo identity: %w", err)
 	}
@@ -181,8 +182,8 @@ func (db *DB) SetRepoIdentity(repoID int64, identity string) error {
 
 // GetRepoByIdentity finds a repo by its identity.
 // Returns nil if not found, error if duplicates exist.
-func (db *DB) GetRepoByIdentity(identity string) (*Repo, error) {
-	rows, err := db.Query(`
+func (db *DB) GetRepoByIdentity(ctx context.Context, identity string) (*Repo, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT id, root_path, name, created_at, identity
 		FROM repos WHERE identity = ?
 	`, identity)
@@ -222,8 +223,8 @@ func (db *DB) GetRepoByIdentity(identity string) (*Repo, error) {
 // owner/repo names are case-insensitive.
 // Excludes sync placeholders (root_path == identity) which don't have
 // a real local checkout.
-func (db *DB) GetRepoByIdentityCaseInsensitive(identity string) (*Repo, error) {
-	rows, err := db.Query(`
+func (db *DB) GetRepoByIdentityCaseInsensitive(ctx context.Context, identity string) (*Repo, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT id, root_path, name, created_at, identity
 		FROM repos WHERE LOWER(identity) = LOWER(?) AND root_path != identity
 	`, identity)
@@ -289,8 +290,8 @@ type SyncableJob struct {
 
 // GetJobsToSync returns terminal jobs that need to be pushed to PostgreSQL.
 // These are jobs created locally that haven't been synced or were updated since last sync.
-func (db *DB) GetJobsToSync(machineID string, limit int) ([]SyncableJob, error) {
-	rows, err := db.Query(`
+func (db *DB) GetJobsToSync(ctx context.Context, machineID string, limit int) ([]SyncableJob, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT
 			j.id, j.uuid, j.repo_id, COALESCE(r.identity, ''),
 			j.commit_id, COALESCE(c.sha, ''), COALESCE(c.author, ''), COALESCE(c.subject, ''), COALESCE(c.timestamp, ''),
@@ -368,14 +369,14 @@ func (db *DB) GetJobsToSync(machineID string, limit int) ([]SyncableJob, error)
 }
 
 // MarkJobSynced updates the synced_at timestamp for a job
-func (db *DB) MarkJobSynced(jobID int64) error {
+func (db *DB) MarkJobSynced(ctx context.Context, jobID int64) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := db.Exec(`UPDATE review_jobs SET synced_at = ? WHERE id = ?`, now, jobID)
+	_, err := db.ExecContext(ctx, `UPDATE review_jobs SET synced_at = ? WHERE id = ?`, now, jobID)
 	return err
 }
 
 // MarkJobsSynced updates the synced_at timestamp for multiple jobs
-func (db *DB) MarkJobsSynced(jobIDs []int64) error {
+func (db *DB) MarkJobsSynced(ctx context.Context, jobIDs []int64) error {
 	if len(jobIDs) == 0 {
 		return nil
 	}
@@ -389,7 +390,7 @@ func (db *DB) MarkJobsSynced(jobIDs []int64) error {
 	}
 	query := fmt.Sprintf(`UPDATE review_jobs SET synced_at = ? WHERE id IN (%s)`,
 		strings.Join(placeholders, ","))
-	_, err := db.Exec(query, args...)
+	_, err := db.ExecContext(ctx, query, args...)
 	return err
 }
 
@@ -410,8 +411,8 @@ type SyncableReview struct {
 
 // GetReviewsToSync returns reviews modified locally that need to be pushed.
 // Only returns reviews whose parent job has already been synced.
-func (db *DB) GetReviewsToSync(machineID string, limit int) ([]SyncableReview, error) {
-	rows, err := db.Query(`
+func (db *DB) GetReviewsToSync(ctx context.Context, machineID string, limit int) ([]SyncableReview, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT
 			r.id, r.uuid, r.job_id, j.uuid,
 			r.agent, r.prompt, r.output, r.addressed,
@@ -459,14 +460,14 @@ func (db *DB) GetReviewsToSync(machineID string, limit int) ([]SyncableReview, e
 }
 
 // MarkReviewSynced updates the synced_at timestamp for a review
-func (db *DB) MarkReviewSynced(reviewID int64) error {
+func (db *DB) MarkReviewSynced(ctx context.Context, reviewID int64) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := db.Exec(`UPDATE reviews SET synced_at = ? WHERE id = ?`, now, reviewID)
+	_, err := db.ExecContext(ctx, `UPDATE reviews SET synced_at = ? WHERE id = ?`, now, reviewID)
 	return err
 }
 
 // MarkReviewsSynced updates the synced_at timestamp for multiple reviews
-func (db *DB) MarkReviewsSynced(reviewIDs []int64) error {
+func (db *DB) MarkReviewsSynced(ctx context.Context, reviewIDs []int64) error {
 	if len(reviewIDs) == 0 {
 		return nil
 	}
@@ -480,7 +481,7 @@ func (db *DB) MarkReviewsSynced(reviewIDs []int64) error {
 	}
 	query := fmt.Sprintf(`UPDATE reviews SET synced_at = ? WHERE id IN (%s)`,
 		strings.Join(placeholders, ","))
-	_, err := db.Exec(query, args...)
+	_, err := db.ExecContext(ctx, query, args...)
 	return err
 }
 
@@ -498,8 +499,8 @@ type SyncableResponse struct {
 
 // GetCommentsToSync returns comments created locally that need to be pushed.
 // Only returns comments whose parent job has already been synced.
-func (db *DB) GetCommentsToSync(machineID string, limit int) ([]SyncableResponse, error) {
-	rows, err := db.Query(`
+func (db *DB) GetCommentsToSync(ctx context.Context, machineID string, limit int) ([]SyncableResponse, error) {
+	rows, err := db.QueryContext(ctx, `
 		SELECT
 			r.id, r.uuid, r.job_id, j.uuid,
 			r.responder, r.response, r.source_machine_id, r.created_at
@@ -542,14 +543,14 @@ func (db *DB) GetCommentsToSync(machineID string, limit int) ([]SyncableResponse
 }
 
 // MarkCommentSynced updates the synced_at timestamp for a comment
-func (db *DB) MarkCommentSynced(responseID int64) error {
+func (db *DB) MarkCommentSynced(ctx context.Context, responseID int64) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := db.Exec(`UPDATE responses SET synced_at = ? WHERE id = ?`, now, responseID)
+	_, err := db.ExecContext(ctx, `UPDATE responses SET synced_at = ? WHERE id = ?`, now, responseID)
 	return err
 }
 
 // MarkCommentsSynced updates the synced_at timestamp for multiple comments
-func (db *DB) MarkCommentsSynced(responseIDs []int64) error {
+func (db *DB) MarkCommentsSynced(ctx context.Context, responseIDs []int64) error {
 	if len(responseIDs) == 0 {
 		return nil
 	}
@@ -563,15 +564,15 @@ func (db *DB) MarkCommentsSynced(responseIDs []int64) error {
 	}
 	query := fmt.Sprintf(`UPDATE responses SET synced_at = ? WHERE id IN (%s)`,
 		strings.Join(placeholders, ","))
-	_, err := db.Exec(query, args...)
+	_, err := db.ExecContext(ctx, query, args...)
 	return err
 }
 
 // UpsertPulledJob inserts or updates a job from PostgreSQL into SQLite.
 // Sets synced_at to prevent re-pushing. Requires repo to exist.
-func (db *DB) UpsertPulledJob(j PulledJob, repoID int64, commitID *int64) error {
+func (db *DB) UpsertPulledJob(ctx context.Context, j PulledJob, repoID int64, commitID *int64) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := db.Exec(`
+	_, err := db.ExecContext(ctx, `
 		INSERT INTO review_jobs (
 			uuid, repo_id, commit_id, git_ref, agent, model, reasoning, job_type, review_type, status, agentic,
 			enqueued_at, started_at, finished_at, prompt, diff_content, error,
@@ -593,10 +594,10 @@ func (db *DB) UpsertPulledJob(j PulledJob, repoID int64, commitID *int64) error
 }
 
 // UpsertPulledReview inserts or updates a review from PostgreSQL into SQLite.
-func (db *DB) UpsertPulledReview(r PulledReview) error {
+func (db *DB) UpsertPulledReview(ctx context.Context, r PulledReview) error {
 	// First, find the job_id by uuid
 	var jobID int64
-	err := db.QueryRow(`SELECT id FROM review_jobs WHERE uuid = ?`, r.JobUUID).Scan(&jobID)
+	err := db.QueryRowContext(ctx, `SELECT id FROM review_jobs WHERE uuid = ?`, r.JobUUID).Scan(&jobID)
 	if err == sql.ErrNoRows {
 		// Job doesn't exist locally - skip this review (orphaned)
 		return nil
@@ -606,7 +607,7 @@ func (db *DB) UpsertPulledReview(r PulledReview) error {
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err = db.Exec(`
+	_, err = db.ExecContext(ctx, `
 		INSERT INTO reviews (
 			uuid, job_id, agent, prompt, output, addressed,
 			updated_by_machine_id, created_at, updated_at, synced_at
@@ -622,10 +623,10 @@ func (db *DB) UpsertPulledReview(r PulledReview) error {
 }
 
 // UpsertPulledResponse inserts a response from PostgreSQL into SQLite.
-func (db *DB) UpsertPulledResponse(r PulledResponse) error {
+func (db *DB) UpsertPulledResponse(ctx context.Context, r PulledResponse) error {
 	// First, find the job_id by uuid
 	var jobID int64
-	err := db.QueryRow(`SELECT id FROM review_jobs WHERE uuid = ?`, r.JobUUID).Scan(&jobID)
+	err := db.QueryRowContext(ctx, `SELECT id FROM review_jobs WHERE uuid = ?`, r.JobUUID).Scan(&jobID)
 	if err == sql.ErrNoRows {
 		// Job doesn't exist locally - skip this response (orphaned)
 		return nil
@@ -635,7 +636,7 @@ func (db *DB) UpsertPulledResponse(r PulledResponse) error {
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err = db.Exec(`
+	_, err = db.ExecContext(ctx, `
 		INSERT INTO responses (
 			uuid, job_id, responder, response, source_machine_id, created_at, synced_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?)
@@ -646,8 +647,8 @@ func (db *DB) UpsertPulledResponse(r PulledResponse) error {
 
 // GetKnownJobUUIDs returns UUIDs of all jobs that have a UUID.
 // Used to filter reviews when pulling from PostgreSQL.
-func (db *DB) GetKnownJobUUIDs() ([]string, error) {
-	rows, err := db.Query(`SELECT uuid FROM review_jobs WHERE uuid IS NOT NULL`)
+func (db *DB) GetKnownJobUUIDs(ctx context.Context) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT uuid FROM review_jobs WHERE uuid IS NOT NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("query job UUIDs: %w", err)
 	}
@@ -677,10 +678,10 @@ func (db *DB) GetKnownJobUUIDs() ([]string, error) {
 //
 // Note: Single local repos are always preferred, even if a placeholder exists
 // from a previous sync (e.g., when there were 0 or 2+ clones before).
-func (db *DB) GetOrCreateRepoByIdentity(identity string) (int64, error) {
+func (db *DB) GetOrCreateRepoByIdentity(ctx context.Context, identity string) (int64, error) {
 	// First, check for local repos with this identity
 	// (excluding placeholders where root_path == identity)
-	rows, err := db.Query(`SELECT id FROM repos WHERE identity = ? AND root_path != ?`, identity, identity)
+	rows, err := db.QueryContext(ctx, `SELECT id FROM repos WHERE identity = ? AND root_path != ?`, identity, identity)
 	if err != nil {
 		return 0, fmt.Errorf("find repos by identity: %w", err)
 	}
@@ -705,7 +706,7 @@ func (db *DB) GetOrCreateRepoByIdentity(identity string) (int64, error) {
 
 	// 0 or 2+ local repos - look for existing placeholder
 	var placeholderID int64
-	err = db.QueryRow(`SELECT id FROM repos WHERE root_path = ? AND identity = ?`, identity, identity).Scan(&placeholderID)
+	err = db.QueryRowContext(ctx, `SELECT id FROM repos WHERE root_path = ? AND identity = ?`, identity, identity).Scan(&placeholderID)
 	if err == nil {
 		return placeholderID, nil
 	}
@@ -716,7 +717,7 @@ func (db *DB) GetOrCreateRepoByIdentity(identity string) (int64, error) {
 	// No placeholder exists - create one
 	// Use extracted repo name for display, but root_path stays as identity to mark it as a placeholder
 	displayName := ExtractRepoNameFromIdentity(identity)
-	result, err := db.Exec(`
+	result, err := db.ExecContext(ctx, `
 		INSERT INTO repos (root_path, name, identity)
 		VALUES (?, ?, ?)
 	`, identity, displayName, identity)
@@ -763,10 +764,10 @@ func ExtractRepoNameFromIdentity(identity string) string {
 }
 
 // GetOrCreateCommitByRepoAndSHA finds or creates a commit.
-func (db *DB) GetOrCreateCommitByRepoAndSHA(repoID int64, sha, author, subject string, timestamp time.Time) (int64, error) {
+func (db *DB) GetOrCreateCommitByRepoAndSHA(ctx context.Context, repoID int64, sha, author, subject string, timestamp time.Time) (int64, error) {
 	// Try to find existing
 	var id int64
-	err := db.QueryRow(`SELECT id FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).Scan(&id)
+	err := db.QueryRowContext(ctx, `SELECT id FROM commits WHERE repo_id = ? AND sha = ?`, repoID, sha).Scan(&id)
 	if err == nil {
 		return id, nil
 	}
@@ -775,7 +776,7 @@ func (db *DB) GetOrCreateCommitByRepoAndSHA(repoID int64, sha, author, subject s
 	}
 
 	// Create
-	result, err := db.Exec(`
+	result, err := db.ExecContext(ctx, `
 		INSERT INTO commits (repo_id, sha, author, subject, timestamp)
 		VALUES (?, ?, ?, ?, ?)
 	`, repoID, sha, author, subject, timestamp.Format(time.RFC3339))
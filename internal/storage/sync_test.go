@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os/exec"
@@ -19,7 +20,7 @@ func TestSyncState(t *testing.T) {
 	defer db.Close()
 
 	t.Run("get nonexistent key returns empty", func(t *testing.T) {
-		val, err := db.GetSyncState("nonexistent")
+		val, err := db.GetSyncState(t.Context(), "nonexistent")
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -29,12 +30,12 @@ func TestSyncState(t *testing.T) {
 	})
 
 	t.Run("set and get", func(t *testing.T) {
-		err := db.SetSyncState("test_key", "test_value")
+		err := db.SetSyncState(t.Context(), "test_key", "test_value")
 		if err != nil {
 			t.Fatalf("SetSyncState failed: %v", err)
 		}
 
-		val, err := db.GetSyncState("test_key")
+		val, err := db.GetSyncState(t.Context(), "test_key")
 		if err != nil {
 			t.Fatalf("GetSyncState failed: %v", err)
 		}
@@ -44,17 +45,17 @@ func TestSyncState(t *testing.T) {
 	})
 
 	t.Run("upsert overwrites", func(t *testing.T) {
-		err := db.SetSyncState("upsert_key", "first")
+		err := db.SetSyncState(t.Context(), "upsert_key", "first")
 		if err != nil {
 			t.Fatalf("SetSyncState failed: %v", err)
 		}
 
-		err = db.SetSyncState("upsert_key", "second")
+		err = db.SetSyncState(t.Context(), "upsert_key", "second")
 		if err != nil {
 			t.Fatalf("SetSyncState upsert failed: %v", err)
 		}
 
-		val, err := db.GetSyncState("upsert_key")
+		val, err := db.GetSyncState(t.Context(), "upsert_key")
 		if err != nil {
 			t.Fatalf("GetSyncState failed: %v", err)
 		}
@@ -103,18 +104,18 @@ func TestBackfillSourceMachineID(t *testing.T) {
 	defer db.Close()
 
 	// Create test data
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
 	// Create a commit for the job
-	commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Test Author", "Test Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Test Author", "Test Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
 
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test", Reasoning: "thorough"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -133,7 +134,7 @@ func TestBackfillSourceMachineID(t *testing.T) {
 	}
 
 	// Run backfill
-	err = db.BackfillSourceMachineID()
+	err = db.BackfillSourceMachineID(t.Context())
 	if err != nil {
 		t.Fatalf("BackfillSourceMachineID failed: %v", err)
 	}
@@ -162,7 +163,7 @@ func TestBackfillRepoIdentities_LocalRepoFallback(t *testing.T) {
 		t.Skipf("git init failed (git not available?): %v", err)
 	}
 
-	repo, err := db.GetOrCreateRepo(tempDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tempDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -174,7 +175,7 @@ func TestBackfillRepoIdentities_LocalRepoFallback(t *testing.T) {
 	}
 
 	// Backfill should use local: prefix with repo name (git repo, no remote)
-	count, err := db.BackfillRepoIdentities()
+	count, err := db.BackfillRepoIdentities(t.Context())
 	if err != nil {
 		t.Fatalf("BackfillRepoIdentities failed: %v", err)
 	}
@@ -206,7 +207,7 @@ func TestBackfillRepoIdentities_SkipsNonGitRepos(t *testing.T) {
 
 	// Create a repo pointing to a directory that is NOT a git repository
 	tempDir := t.TempDir() // Just a plain directory, no git init
-	repo, err := db.GetOrCreateRepo(tempDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tempDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -218,7 +219,7 @@ func TestBackfillRepoIdentities_SkipsNonGitRepos(t *testing.T) {
 	}
 
 	// Backfill should set a local:// identity for non-git repos
-	count, err := db.BackfillRepoIdentities()
+	count, err := db.BackfillRepoIdentities(t.Context())
 	if err != nil {
 		t.Fatalf("BackfillRepoIdentities failed: %v", err)
 	}
@@ -243,17 +244,17 @@ func TestBackfillRepoIdentities_SkipsReposWithIdentity(t *testing.T) {
 
 	// Create a repo and set its identity
 	tempDir := t.TempDir()
-	repo, err := db.GetOrCreateRepo(tempDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tempDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	err = db.SetRepoIdentity(repo.ID, "https://github.com/user/existing.git")
+	err = db.SetRepoIdentity(t.Context(), repo.ID, "https://github.com/user/existing.git")
 	if err != nil {
 		t.Fatalf("SetRepoIdentity failed: %v", err)
 	}
 
 	// Backfill should not change existing identity
-	count, err := db.BackfillRepoIdentities()
+	count, err := db.BackfillRepoIdentities(t.Context())
 	if err != nil {
 		t.Fatalf("BackfillRepoIdentities failed: %v", err)
 	}
@@ -293,7 +294,7 @@ func TestBackfillRepoIdentities_SkipsMissingPaths(t *testing.T) {
 
 	// Backfill should still set a local:// identity for missing paths
 	// (better to have an identity than none, even for stale entries)
-	count, err := db.BackfillRepoIdentities()
+	count, err := db.BackfillRepoIdentities(t.Context())
 	if err != nil {
 		t.Fatalf("BackfillRepoIdentities failed: %v", err)
 	}
@@ -320,7 +321,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 		localIdentity := "local:my-local-project"
 
 		// Create repo by identity
-		repoID, err := db.GetOrCreateRepoByIdentity(localIdentity)
+		repoID, err := db.GetOrCreateRepoByIdentity(t.Context(), localIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -349,12 +350,12 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 	t.Run("returns same ID on subsequent calls", func(t *testing.T) {
 		localIdentity := "local:another-project"
 
-		id1, err := db.GetOrCreateRepoByIdentity(localIdentity)
+		id1, err := db.GetOrCreateRepoByIdentity(t.Context(), localIdentity)
 		if err != nil {
 			t.Fatalf("First GetOrCreateRepoByIdentity failed: %v", err)
 		}
 
-		id2, err := db.GetOrCreateRepoByIdentity(localIdentity)
+		id2, err := db.GetOrCreateRepoByIdentity(t.Context(), localIdentity)
 		if err != nil {
 			t.Fatalf("Second GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -365,12 +366,12 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 	})
 
 	t.Run("creates different repos for different identities", func(t *testing.T) {
-		id1, err := db.GetOrCreateRepoByIdentity("local:project-a")
+		id1, err := db.GetOrCreateRepoByIdentity(t.Context(), "local:project-a")
 		if err != nil {
 			t.Fatalf("First GetOrCreateRepoByIdentity failed: %v", err)
 		}
 
-		id2, err := db.GetOrCreateRepoByIdentity("local:project-b")
+		id2, err := db.GetOrCreateRepoByIdentity(t.Context(), "local:project-b")
 		if err != nil {
 			t.Fatalf("Second GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -383,7 +384,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 	t.Run("works with git URL identities too", func(t *testing.T) {
 		gitIdentity := "https://github.com/user/repo.git"
 
-		repoID, err := db.GetOrCreateRepoByIdentity(gitIdentity)
+		repoID, err := db.GetOrCreateRepoByIdentity(t.Context(), gitIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -415,7 +416,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 		localRepoID, _ := result.LastInsertId()
 
 		// GetOrCreateRepoByIdentity should return the existing local repo, not create a placeholder
-		gotID, err := db.GetOrCreateRepoByIdentity(singleIdentity)
+		gotID, err := db.GetOrCreateRepoByIdentity(t.Context(), singleIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -451,7 +452,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 		}
 
 		// GetOrCreateRepoByIdentity should create a placeholder
-		placeholderID, err := db.GetOrCreateRepoByIdentity(sharedIdentity)
+		placeholderID, err := db.GetOrCreateRepoByIdentity(t.Context(), sharedIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepoByIdentity should succeed with duplicates, got: %v", err)
 		}
@@ -470,7 +471,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 		}
 
 		// Subsequent calls should return the same placeholder
-		placeholderID2, err := db.GetOrCreateRepoByIdentity(sharedIdentity)
+		placeholderID2, err := db.GetOrCreateRepoByIdentity(t.Context(), sharedIdentity)
 		if err != nil {
 			t.Fatalf("Second GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -501,7 +502,7 @@ func TestGetOrCreateRepoByIdentity(t *testing.T) {
 		localRepoID, _ := result.LastInsertId()
 
 		// GetOrCreateRepoByIdentity should return the local repo, not the placeholder
-		gotID, err := db.GetOrCreateRepoByIdentity(placeholderIdentity)
+		gotID, err := db.GetOrCreateRepoByIdentity(t.Context(), placeholderIdentity)
 		if err != nil {
 			t.Fatalf("GetOrCreateRepoByIdentity failed: %v", err)
 		}
@@ -551,19 +552,19 @@ func TestSetRepoIdentity(t *testing.T) {
 	defer db.Close()
 
 	// Create test repo
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
 
 	// Set identity
-	err = db.SetRepoIdentity(repo.ID, "https://github.com/user/repo.git")
+	err = db.SetRepoIdentity(t.Context(), repo.ID, "https://github.com/user/repo.git")
 	if err != nil {
 		t.Fatalf("SetRepoIdentity failed: %v", err)
 	}
 
 	// Verify via GetRepoByIdentity
-	found, err := db.GetRepoByIdentity("https://github.com/user/repo.git")
+	found, err := db.GetRepoByIdentity(t.Context(), "https://github.com/user/repo.git")
 	if err != nil {
 		t.Fatalf("GetRepoByIdentity failed: %v", err)
 	}
@@ -582,7 +583,7 @@ func TestGetRepoByIdentity_NotFound(t *testing.T) {
 	db := openTestDB(t)
 	defer db.Close()
 
-	found, err := db.GetRepoByIdentity("nonexistent")
+	found, err := db.GetRepoByIdentity(t.Context(), "nonexistent")
 	if err != nil {
 		t.Fatalf("GetRepoByIdentity failed: %v", err)
 	}
@@ -596,7 +597,7 @@ func TestGetKnownJobUUIDs(t *testing.T) {
 	defer db.Close()
 
 	t.Run("returns empty when no jobs exist", func(t *testing.T) {
-		uuids, err := db.GetKnownJobUUIDs()
+		uuids, err := db.GetKnownJobUUIDs(t.Context())
 		if err != nil {
 			t.Fatalf("GetKnownJobUUIDs failed: %v", err)
 		}
@@ -606,27 +607,27 @@ func TestGetKnownJobUUIDs(t *testing.T) {
 	})
 
 	t.Run("returns UUIDs of jobs with UUIDs", func(t *testing.T) {
-		repo, err := db.GetOrCreateRepo(t.TempDir())
+		repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
 
-		commit, err := db.GetOrCreateCommit(repo.ID, "abc123", "Test Author", "Test Subject", time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "abc123", "Test Author", "Test Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
 
 		// Create two jobs with UUIDs
-		job1, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test", Reasoning: "thorough"})
+		job1, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "abc123", Agent: "test", Reasoning: "thorough"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		job2, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "def456", Agent: "test", Reasoning: "quick"})
+		job2, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "def456", Agent: "test", Reasoning: "quick"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
 
-		uuids, err := db.GetKnownJobUUIDs()
+		uuids, err := db.GetKnownJobUUIDs(t.Context())
 		if err != nil {
 			t.Fatalf("GetKnownJobUUIDs failed: %v", err)
 		}
@@ -877,7 +878,7 @@ func TestDuplicateRepoIdentity_MigrationSuccess(t *testing.T) {
 	defer db.Close()
 
 	// Verify both repos exist
-	repos, err := db.ListRepos()
+	repos, err := db.ListRepos(t.Context())
 	if err != nil {
 		t.Fatalf("ListRepos failed: %v", err)
 	}
@@ -1003,7 +1004,7 @@ func TestGetRepoByIdentity_DuplicateError(t *testing.T) {
 	}
 
 	// GetRepoByIdentity should return error for duplicates
-	_, err = db.GetRepoByIdentity("same-id")
+	_, err = db.GetRepoByIdentity(t.Context(), "same-id")
 	if err == nil {
 		t.Fatal("Expected error for duplicate identities, but got nil")
 	}
@@ -1194,31 +1195,31 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 	}
 
 	// Create a repo and commit
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "sync-test-sha", "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "sync-test-sha", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
 
 	// Create a job and complete it
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "sync-test-sha", Agent: "test", Reasoning: "thorough"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "sync-test-sha", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
-	_, err = db.ClaimJob("worker-1")
+	_, err = db.ClaimJob(t.Context(), "worker-1")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	err = db.CompleteJob(job.ID, "test", "prompt", "output")
+	err = db.CompleteJob(t.Context(), job.ID, "test", "prompt", "output")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	t.Run("job with null synced_at is returned", func(t *testing.T) {
-		jobs, err := db.GetJobsToSync(machineID, 10)
+		jobs, err := db.GetJobsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1235,12 +1236,12 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 	})
 
 	t.Run("job after MarkJobSynced is not returned", func(t *testing.T) {
-		err := db.MarkJobSynced(job.ID)
+		err := db.MarkJobSynced(t.Context(), job.ID)
 		if err != nil {
 			t.Fatalf("MarkJobSynced failed: %v", err)
 		}
 
-		jobs, err := db.GetJobsToSync(machineID, 10)
+		jobs, err := db.GetJobsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1259,7 +1260,7 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update updated_at: %v", err)
 		}
 
-		jobs, err := db.GetJobsToSync(machineID, 10)
+		jobs, err := db.GetJobsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1277,19 +1278,19 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 
 	t.Run("mixed format timestamps compare correctly", func(t *testing.T) {
 		// Create a new job for this subtest
-		commit2, err := db.GetOrCreateCommit(repo.ID, "mixed-format-sha", "Author", "Subject", time.Now())
+		commit2, err := db.GetOrCreateCommit(t.Context(), repo.ID, "mixed-format-sha", "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		job2, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "mixed-format-sha", Agent: "test", Reasoning: "thorough"})
+		job2, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit2.ID, GitRef: "mixed-format-sha", Agent: "test", Reasoning: "thorough"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		_, err = db.ClaimJob("worker-2")
+		_, err = db.ClaimJob(t.Context(), "worker-2")
 		if err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
-		err = db.CompleteJob(job2.ID, "test", "prompt", "output")
+		err = db.CompleteJob(t.Context(), job2.ID, "test", "prompt", "output")
 		if err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
@@ -1306,7 +1307,7 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to set updated_at: %v", err)
 		}
 
-		jobs, err := db.GetJobsToSync(machineID, 10)
+		jobs, err := db.GetJobsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1329,7 +1330,7 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update timestamps: %v", err)
 		}
 
-		jobs, err = db.GetJobsToSync(machineID, 10)
+		jobs, err = db.GetJobsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1359,23 +1360,23 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 		}
 
 		// Create test data in the new DB
-		tzRepo, err := tzDB.GetOrCreateRepo(t.TempDir())
+		tzRepo, err := tzDB.GetOrCreateRepo(t.Context(), t.TempDir())
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
-		commit3, err := tzDB.GetOrCreateCommit(tzRepo.ID, "tz-test-sha", "Author", "Subject", time.Now())
+		commit3, err := tzDB.GetOrCreateCommit(t.Context(), tzRepo.ID, "tz-test-sha", "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		job3, err := tzDB.EnqueueJob(EnqueueOpts{RepoID: tzRepo.ID, CommitID: commit3.ID, GitRef: "tz-test-sha", Agent: "test", Reasoning: "thorough"})
+		job3, err := tzDB.EnqueueJob(t.Context(), EnqueueOpts{RepoID: tzRepo.ID, CommitID: commit3.ID, GitRef: "tz-test-sha", Agent: "test", Reasoning: "thorough"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		_, err = tzDB.ClaimJob("worker-3")
+		_, err = tzDB.ClaimJob(t.Context(), "worker-3")
 		if err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
-		err = tzDB.CompleteJob(job3.ID, "test", "prompt", "output")
+		err = tzDB.CompleteJob(t.Context(), job3.ID, "test", "prompt", "output")
 		if err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
@@ -1387,7 +1388,7 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to set timestamps: %v", err)
 		}
 
-		jobs, err := tzDB.GetJobsToSync(tzMachineID, 10)
+		jobs, err := tzDB.GetJobsToSync(t.Context(), tzMachineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1409,7 +1410,7 @@ func TestGetJobsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update timestamps: %v", err)
 		}
 
-		jobs, err = tzDB.GetJobsToSync(tzMachineID, 10)
+		jobs, err = tzDB.GetJobsToSync(t.Context(), tzMachineID, 10)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1432,41 +1433,41 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 	}
 
 	// Create a repo, commit, and job
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "review-sync-sha", "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "review-sync-sha", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "review-sync-sha", Agent: "test", Reasoning: "thorough"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "review-sync-sha", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
-	_, err = db.ClaimJob("worker-1")
+	_, err = db.ClaimJob(t.Context(), "worker-1")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	err = db.CompleteJob(job.ID, "test", "prompt", "output")
+	err = db.CompleteJob(t.Context(), job.ID, "test", "prompt", "output")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Mark job as synced (required before reviews can sync due to FK ordering)
-	err = db.MarkJobSynced(job.ID)
+	err = db.MarkJobSynced(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("MarkJobSynced failed: %v", err)
 	}
 
 	// Get the review ID
-	review, err := db.GetReviewByJobID(job.ID)
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
 
 	t.Run("review with null synced_at is returned", func(t *testing.T) {
-		reviews, err := db.GetReviewsToSync(machineID, 10)
+		reviews, err := db.GetReviewsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1483,12 +1484,12 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 	})
 
 	t.Run("review after MarkReviewSynced is not returned", func(t *testing.T) {
-		err := db.MarkReviewSynced(review.ID)
+		err := db.MarkReviewSynced(t.Context(), review.ID)
 		if err != nil {
 			t.Fatalf("MarkReviewSynced failed: %v", err)
 		}
 
-		reviews, err := db.GetReviewsToSync(machineID, 10)
+		reviews, err := db.GetReviewsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1507,7 +1508,7 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update updated_at: %v", err)
 		}
 
-		reviews, err := db.GetReviewsToSync(machineID, 10)
+		reviews, err := db.GetReviewsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1536,7 +1537,7 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to set updated_at: %v", err)
 		}
 
-		reviews, err := db.GetReviewsToSync(machineID, 10)
+		reviews, err := db.GetReviewsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1557,7 +1558,7 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update timestamps: %v", err)
 		}
 
-		reviews, err = db.GetReviewsToSync(machineID, 10)
+		reviews, err = db.GetReviewsToSync(t.Context(), machineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1587,35 +1588,35 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 		}
 
 		// Create test data in the new DB
-		tzRepo, err := tzDB.GetOrCreateRepo(t.TempDir())
+		tzRepo, err := tzDB.GetOrCreateRepo(t.Context(), t.TempDir())
 		if err != nil {
 			t.Fatalf("GetOrCreateRepo failed: %v", err)
 		}
-		tzCommit, err := tzDB.GetOrCreateCommit(tzRepo.ID, "tz-review-sha", "Author", "Subject", time.Now())
+		tzCommit, err := tzDB.GetOrCreateCommit(t.Context(), tzRepo.ID, "tz-review-sha", "Author", "Subject", time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
-		tzJob, err := tzDB.EnqueueJob(EnqueueOpts{RepoID: tzRepo.ID, CommitID: tzCommit.ID, GitRef: "tz-review-sha", Agent: "test", Reasoning: "thorough"})
+		tzJob, err := tzDB.EnqueueJob(t.Context(), EnqueueOpts{RepoID: tzRepo.ID, CommitID: tzCommit.ID, GitRef: "tz-review-sha", Agent: "test", Reasoning: "thorough"})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
-		_, err = tzDB.ClaimJob("worker-tz")
+		_, err = tzDB.ClaimJob(t.Context(), "worker-tz")
 		if err != nil {
 			t.Fatalf("ClaimJob failed: %v", err)
 		}
-		err = tzDB.CompleteJob(tzJob.ID, "test", "prompt", "output")
+		err = tzDB.CompleteJob(t.Context(), tzJob.ID, "test", "prompt", "output")
 		if err != nil {
 			t.Fatalf("CompleteJob failed: %v", err)
 		}
 
 		// Mark job as synced (required before reviews can sync due to FK ordering)
-		err = tzDB.MarkJobSynced(tzJob.ID)
+		err = tzDB.MarkJobSynced(t.Context(), tzJob.ID)
 		if err != nil {
 			t.Fatalf("MarkJobSynced failed: %v", err)
 		}
 
 		// Get the review ID
-		tzReview, err := tzDB.GetReviewByJobID(tzJob.ID)
+		tzReview, err := tzDB.GetReviewByJobID(t.Context(), tzJob.ID)
 		if err != nil {
 			t.Fatalf("GetReviewByJobID failed: %v", err)
 		}
@@ -1627,7 +1628,7 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to set timestamps: %v", err)
 		}
 
-		reviews, err := tzDB.GetReviewsToSync(tzMachineID, 10)
+		reviews, err := tzDB.GetReviewsToSync(t.Context(), tzMachineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1649,7 +1650,7 @@ func TestGetReviewsToSync_TimestampComparison(t *testing.T) {
 			t.Fatalf("Failed to update timestamps: %v", err)
 		}
 
-		reviews, err = tzDB.GetReviewsToSync(tzMachineID, 10)
+		reviews, err = tzDB.GetReviewsToSync(t.Context(), tzMachineID, 10)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1673,36 +1674,36 @@ func TestGetCommentsToSync_LegacyCommentsExcluded(t *testing.T) {
 	}
 
 	// Create a repo and commit
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "legacy-resp-sha", "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "legacy-resp-sha", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
 
 	// Create a job-based response (should be synced)
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "legacy-resp-sha", Agent: "test", Reasoning: "thorough"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "legacy-resp-sha", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
-	_, err = db.ClaimJob("worker-1")
+	_, err = db.ClaimJob(t.Context(), "worker-1")
 	if err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	err = db.CompleteJob(job.ID, "test", "prompt", "output")
+	err = db.CompleteJob(t.Context(), job.ID, "test", "prompt", "output")
 	if err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
 	// Mark job as synced (required before responses can sync due to FK ordering)
-	err = db.MarkJobSynced(job.ID)
+	err = db.MarkJobSynced(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("MarkJobSynced failed: %v", err)
 	}
 
-	jobResp, err := db.AddCommentToJob(job.ID, "human", "This is a job response")
+	jobResp, err := db.AddCommentToJob(t.Context(), job.ID, "human", "This is a job response")
 	if err != nil {
 		t.Fatalf("AddCommentToJob failed: %v", err)
 	}
@@ -1718,7 +1719,7 @@ func TestGetCommentsToSync_LegacyCommentsExcluded(t *testing.T) {
 	legacyRespID, _ := result.LastInsertId()
 
 	// Get responses to sync - should only include the job-based response
-	responses, err := db.GetCommentsToSync(machineID, 100)
+	responses, err := db.GetCommentsToSync(t.Context(), machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -1760,7 +1761,7 @@ func TestUpsertPulledResponse_MissingParentJob(t *testing.T) {
 	}
 
 	// Should return nil (not error) for missing parent job
-	err := db.UpsertPulledResponse(response)
+	err := db.UpsertPulledResponse(t.Context(), response)
 	if err != nil {
 		t.Errorf("Expected nil error for missing parent job, got: %v", err)
 	}
@@ -1782,15 +1783,15 @@ func TestUpsertPulledResponse_WithParentJob(t *testing.T) {
 	defer db.Close()
 
 	// Create a repo and job
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
-	commit, err := db.GetOrCreateCommit(repo.ID, "parent-job-sha", "Author", "Subject", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, "parent-job-sha", "Author", "Subject", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
-	job, err := db.EnqueueJob(EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "parent-job-sha", Agent: "test", Reasoning: "thorough"})
+	job, err := db.EnqueueJob(t.Context(), EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: "parent-job-sha", Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -1805,7 +1806,7 @@ func TestUpsertPulledResponse_WithParentJob(t *testing.T) {
 		CreatedAt:       time.Now(),
 	}
 
-	err = db.UpsertPulledResponse(response)
+	err = db.UpsertPulledResponse(t.Context(), response)
 	if err != nil {
 		t.Fatalf("UpsertPulledResponse failed: %v", err)
 	}
@@ -1875,40 +1876,40 @@ func TestClearAllSyncedAt(t *testing.T) {
 	job := h.createCompletedJob("clear-test-sha")
 
 	// Add a response
-	_, err := h.db.AddCommentToJob(job.ID, "user", "test response")
+	_, err := h.db.AddCommentToJob(t.Context(), job.ID, "user", "test response")
 	if err != nil {
 		t.Fatalf("AddCommentToJob failed: %v", err)
 	}
 
 	// Mark everything as synced
-	if err := h.db.MarkJobSynced(job.ID); err != nil {
+	if err := h.db.MarkJobSynced(t.Context(), job.ID); err != nil {
 		t.Fatalf("MarkJobSynced failed: %v", err)
 	}
-	review, err := h.db.GetReviewByJobID(job.ID)
+	review, err := h.db.GetReviewByJobID(t.Context(), job.ID)
 	if err != nil {
 		t.Fatalf("GetReviewByJobID failed: %v", err)
 	}
-	if err := h.db.MarkReviewSynced(review.ID); err != nil {
+	if err := h.db.MarkReviewSynced(t.Context(), review.ID); err != nil {
 		t.Fatalf("MarkReviewSynced failed: %v", err)
 	}
 
 	// Verify nothing needs to sync
-	jobs, _ := h.db.GetJobsToSync(h.machineID, 100)
+	jobs, _ := h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if len(jobs) != 0 {
 		t.Errorf("Expected 0 jobs to sync before clear, got %d", len(jobs))
 	}
-	reviews, _ := h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, _ := h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if len(reviews) != 0 {
 		t.Errorf("Expected 0 reviews to sync before clear, got %d", len(reviews))
 	}
 
 	// Clear all synced_at
-	if err := h.db.ClearAllSyncedAt(); err != nil {
+	if err := h.db.ClearAllSyncedAt(t.Context()); err != nil {
 		t.Fatalf("ClearAllSyncedAt failed: %v", err)
 	}
 
 	// Now everything should need to sync again
-	jobs, err = h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err = h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -1917,11 +1918,11 @@ func TestClearAllSyncedAt(t *testing.T) {
 	}
 
 	// Mark job synced so reviews become available
-	if err := h.db.MarkJobSynced(job.ID); err != nil {
+	if err := h.db.MarkJobSynced(t.Context(), job.ID); err != nil {
 		t.Fatalf("MarkJobSynced failed: %v", err)
 	}
 
-	reviews, err = h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err = h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -1929,7 +1930,7 @@ func TestClearAllSyncedAt(t *testing.T) {
 		t.Errorf("Expected 1 review to sync after clear, got %d", len(reviews))
 	}
 
-	responses, err := h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err := h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -1947,7 +1948,7 @@ func TestBatchMarkSynced(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		job := h.createCompletedJob(fmt.Sprintf("batch-test-sha-%d", i))
 		jobs = append(jobs, job)
-		_, err := h.db.AddCommentToJob(job.ID, "user", fmt.Sprintf("response %d", i))
+		_, err := h.db.AddCommentToJob(t.Context(), job.ID, "user", fmt.Sprintf("response %d", i))
 		if err != nil {
 			t.Fatalf("AddCommentToJob failed: %v", err)
 		}
@@ -1955,7 +1956,7 @@ func TestBatchMarkSynced(t *testing.T) {
 
 	t.Run("MarkJobsSynced marks multiple jobs", func(t *testing.T) {
 		// Get jobs to sync before
-		toSync, err := h.db.GetJobsToSync(h.machineID, 100)
+		toSync, err := h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1965,12 +1966,12 @@ func TestBatchMarkSynced(t *testing.T) {
 
 		// Mark first 3 as synced
 		jobIDs := []int64{jobs[0].ID, jobs[1].ID, jobs[2].ID}
-		if err := h.db.MarkJobsSynced(jobIDs); err != nil {
+		if err := h.db.MarkJobsSynced(t.Context(), jobIDs); err != nil {
 			t.Fatalf("MarkJobsSynced failed: %v", err)
 		}
 
 		// Verify only 2 jobs left to sync
-		toSync, err = h.db.GetJobsToSync(h.machineID, 100)
+		toSync, err = h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetJobsToSync failed: %v", err)
 		}
@@ -1981,7 +1982,7 @@ func TestBatchMarkSynced(t *testing.T) {
 
 	t.Run("MarkReviewsSynced marks multiple reviews", func(t *testing.T) {
 		// Get reviews for synced jobs
-		reviews, err := h.db.GetReviewsToSync(h.machineID, 100)
+		reviews, err := h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -1994,12 +1995,12 @@ func TestBatchMarkSynced(t *testing.T) {
 		for i, r := range reviews {
 			reviewIDs[i] = r.ID
 		}
-		if err := h.db.MarkReviewsSynced(reviewIDs); err != nil {
+		if err := h.db.MarkReviewsSynced(t.Context(), reviewIDs); err != nil {
 			t.Fatalf("MarkReviewsSynced failed: %v", err)
 		}
 
 		// Verify no reviews left to sync (for synced jobs)
-		reviews, err = h.db.GetReviewsToSync(h.machineID, 100)
+		reviews, err = h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetReviewsToSync failed: %v", err)
 		}
@@ -2010,7 +2011,7 @@ func TestBatchMarkSynced(t *testing.T) {
 
 	t.Run("MarkCommentsSynced marks multiple comments", func(t *testing.T) {
 		// Get responses for synced jobs
-		responses, err := h.db.GetCommentsToSync(h.machineID, 100)
+		responses, err := h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetCommentsToSync failed: %v", err)
 		}
@@ -2023,12 +2024,12 @@ func TestBatchMarkSynced(t *testing.T) {
 		for i, r := range responses {
 			responseIDs[i] = r.ID
 		}
-		if err := h.db.MarkCommentsSynced(responseIDs); err != nil {
+		if err := h.db.MarkCommentsSynced(t.Context(), responseIDs); err != nil {
 			t.Fatalf("MarkCommentsSynced failed: %v", err)
 		}
 
 		// Verify no responses left to sync (for synced jobs)
-		responses, err = h.db.GetCommentsToSync(h.machineID, 100)
+		responses, err = h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 		if err != nil {
 			t.Fatalf("GetCommentsToSync failed: %v", err)
 		}
@@ -2039,13 +2040,13 @@ func TestBatchMarkSynced(t *testing.T) {
 
 	t.Run("empty slice is no-op", func(t *testing.T) {
 		// Empty slices should not error
-		if err := h.db.MarkJobsSynced([]int64{}); err != nil {
+		if err := h.db.MarkJobsSynced(t.Context(), []int64{}); err != nil {
 			t.Errorf("MarkJobsSynced with empty slice failed: %v", err)
 		}
-		if err := h.db.MarkReviewsSynced([]int64{}); err != nil {
+		if err := h.db.MarkReviewsSynced(t.Context(), []int64{}); err != nil {
 			t.Errorf("MarkReviewsSynced with empty slice failed: %v", err)
 		}
-		if err := h.db.MarkCommentsSynced([]int64{}); err != nil {
+		if err := h.db.MarkCommentsSynced(t.Context(), []int64{}); err != nil {
 			t.Errorf("MarkCommentsSynced with empty slice failed: %v", err)
 		}
 	})
@@ -2068,7 +2069,7 @@ func newSyncTestHelper(t *testing.T) *syncTestHelper {
 		t.Fatalf("Failed to get machine ID: %v", err)
 	}
 
-	repo, err := db.GetOrCreateRepo(t.TempDir())
+	repo, err := db.GetOrCreateRepo(t.Context(), t.TempDir())
 	if err != nil {
 		t.Fatalf("Failed to create repo: %v", err)
 	}
@@ -2078,19 +2079,19 @@ func newSyncTestHelper(t *testing.T) *syncTestHelper {
 
 // createCompletedJob creates a job, marks it done, and creates a review.
 func (h *syncTestHelper) createCompletedJob(sha string) *ReviewJob {
-	commit, err := h.db.GetOrCreateCommit(h.repo.ID, sha, "Author", "Subject", time.Now())
+	commit, err := h.db.GetOrCreateCommit(context.Background(), h.repo.ID, sha, "Author", "Subject", time.Now())
 	if err != nil {
 		h.t.Fatalf("Failed to create commit: %v", err)
 	}
-	job, err := h.db.EnqueueJob(EnqueueOpts{RepoID: h.repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "test", Reasoning: "thorough"})
+	job, err := h.db.EnqueueJob(context.Background(), EnqueueOpts{RepoID: h.repo.ID, CommitID: commit.ID, GitRef: sha, Agent: "test", Reasoning: "thorough"})
 	if err != nil {
 		h.t.Fatalf("Failed to enqueue job: %v", err)
 	}
-	_, err = h.db.ClaimJob("worker")
+	_, err = h.db.ClaimJob(context.Background(), "worker")
 	if err != nil {
 		h.t.Fatalf("Failed to claim job: %v", err)
 	}
-	err = h.db.CompleteJob(job.ID, "test", "prompt", "output")
+	err = h.db.CompleteJob(context.Background(), job.ID, "test", "prompt", "output")
 	if err != nil {
 		h.t.Fatalf("Failed to complete job: %v", err)
 	}
@@ -2106,7 +2107,7 @@ func TestGetReviewsToSync_RequiresJobSynced(t *testing.T) {
 	job := h.createCompletedJob("sync-order-sha")
 
 	// Before job is synced, GetReviewsToSync should return nothing
-	reviews, err := h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err := h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -2115,12 +2116,12 @@ func TestGetReviewsToSync_RequiresJobSynced(t *testing.T) {
 	}
 
 	// Mark job as synced
-	if err := h.db.MarkJobSynced(job.ID); err != nil {
+	if err := h.db.MarkJobSynced(t.Context(), job.ID); err != nil {
 		t.Fatalf("Failed to mark job synced: %v", err)
 	}
 
 	// Now GetReviewsToSync should return the review
-	reviews, err = h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err = h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -2138,13 +2139,13 @@ func TestGetCommentsToSync_RequiresJobSynced(t *testing.T) {
 	job := h.createCompletedJob("response-sync-sha")
 
 	// Add a response to the job
-	_, err := h.db.AddCommentToJob(job.ID, "test-user", "test response")
+	_, err := h.db.AddCommentToJob(t.Context(), job.ID, "test-user", "test response")
 	if err != nil {
 		t.Fatalf("Failed to add response: %v", err)
 	}
 
 	// Before job is synced, GetResponsesToSync should return nothing
-	responses, err := h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err := h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -2153,12 +2154,12 @@ func TestGetCommentsToSync_RequiresJobSynced(t *testing.T) {
 	}
 
 	// Mark job as synced
-	if err := h.db.MarkJobSynced(job.ID); err != nil {
+	if err := h.db.MarkJobSynced(t.Context(), job.ID); err != nil {
 		t.Fatalf("Failed to mark job synced: %v", err)
 	}
 
 	// Now GetResponsesToSync should return the response
-	responses, err = h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err = h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -2186,7 +2187,7 @@ func TestGetJobsToSync_RequiresRepoIdentity(t *testing.T) {
 	}
 
 	// GetJobsToSync should return the job (with empty identity)
-	jobs, err := h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err := h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -2198,12 +2199,12 @@ func TestGetJobsToSync_RequiresRepoIdentity(t *testing.T) {
 	}
 
 	// Now set the repo identity
-	if err := h.db.SetRepoIdentity(h.repo.ID, "git@github.com:test/repo.git"); err != nil {
+	if err := h.db.SetRepoIdentity(t.Context(), h.repo.ID, "git@github.com:test/repo.git"); err != nil {
 		t.Fatalf("Failed to set repo identity: %v", err)
 	}
 
 	// GetJobsToSync should now return the job with identity
-	jobs, err = h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err = h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -2223,7 +2224,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 	h := newSyncTestHelper(t)
 
 	// Set repo identity
-	if err := h.db.SetRepoIdentity(h.repo.ID, "git@github.com:test/workflow.git"); err != nil {
+	if err := h.db.SetRepoIdentity(t.Context(), h.repo.ID, "git@github.com:test/workflow.git"); err != nil {
 		t.Fatalf("Failed to set repo identity: %v", err)
 	}
 
@@ -2233,14 +2234,14 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		job := h.createCompletedJob("workflow-sha-" + string(rune('a'+i)))
 		createdJobs = append(createdJobs, job)
 		// Add a response
-		_, err := h.db.AddCommentToJob(job.ID, "user", "response")
+		_, err := h.db.AddCommentToJob(t.Context(), job.ID, "user", "response")
 		if err != nil {
 			t.Fatalf("Failed to add response %d: %v", i, err)
 		}
 	}
 
 	// Initial state: 3 jobs to sync, 0 reviews (jobs not synced), 0 responses (jobs not synced)
-	jobs, err := h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err := h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -2248,7 +2249,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 3 jobs to sync, got %d", len(jobs))
 	}
 
-	reviews, err := h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err := h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -2256,7 +2257,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 0 reviews to sync (jobs not synced), got %d", len(reviews))
 	}
 
-	responses, err := h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err := h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -2265,12 +2266,12 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 	}
 
 	// Sync first job
-	if err := h.db.MarkJobSynced(createdJobs[0].ID); err != nil {
+	if err := h.db.MarkJobSynced(t.Context(), createdJobs[0].ID); err != nil {
 		t.Fatalf("Failed to mark job synced: %v", err)
 	}
 
 	// Now: 2 jobs to sync, 1 review (first job synced), 1 response (first job synced)
-	jobs, err = h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err = h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -2278,7 +2279,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 2 jobs to sync, got %d", len(jobs))
 	}
 
-	reviews, err = h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err = h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -2286,7 +2287,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 1 review to sync, got %d", len(reviews))
 	}
 
-	responses, err = h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err = h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -2296,13 +2297,13 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 
 	// Sync remaining jobs
 	for _, j := range createdJobs[1:] {
-		if err := h.db.MarkJobSynced(j.ID); err != nil {
+		if err := h.db.MarkJobSynced(t.Context(), j.ID); err != nil {
 			t.Fatalf("Failed to mark job synced: %v", err)
 		}
 	}
 
 	// Now: 0 jobs to sync, 3 reviews, 3 responses
-	jobs, err = h.db.GetJobsToSync(h.machineID, 100)
+	jobs, err = h.db.GetJobsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetJobsToSync failed: %v", err)
 	}
@@ -2310,7 +2311,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 0 jobs to sync, got %d", len(jobs))
 	}
 
-	reviews, err = h.db.GetReviewsToSync(h.machineID, 100)
+	reviews, err = h.db.GetReviewsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetReviewsToSync failed: %v", err)
 	}
@@ -2318,7 +2319,7 @@ func TestSyncOrder_FullWorkflow(t *testing.T) {
 		t.Errorf("Expected 3 reviews to sync, got %d", len(reviews))
 	}
 
-	responses, err = h.db.GetCommentsToSync(h.machineID, 100)
+	responses, err = h.db.GetCommentsToSync(t.Context(), h.machineID, 100)
 	if err != nil {
 		t.Fatalf("GetCommentsToSync failed: %v", err)
 	}
@@ -2334,7 +2335,7 @@ func TestUpsertPulledJob_BackfillsModel(t *testing.T) {
 	defer db.Close()
 
 	// Create a repo
-	repo, err := db.GetOrCreateRepo("/test/repo")
+	repo, err := db.GetOrCreateRepo(t.Context(), "/test/repo")
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -2372,7 +2373,7 @@ func TestUpsertPulledJob_BackfillsModel(t *testing.T) {
 		EnqueuedAt:      time.Now(),
 		UpdatedAt:       time.Now(),
 	}
-	err = db.UpsertPulledJob(pulledJob, repo.ID, nil)
+	err = db.UpsertPulledJob(t.Context(), pulledJob, repo.ID, nil)
 	if err != nil {
 		t.Fatalf("UpsertPulledJob failed: %v", err)
 	}
@@ -2391,7 +2392,7 @@ func TestUpsertPulledJob_BackfillsModel(t *testing.T) {
 
 	// Also verify that upserting with empty model doesn't clear existing model
 	pulledJob.Model = "" // Empty model
-	err = db.UpsertPulledJob(pulledJob, repo.ID, nil)
+	err = db.UpsertPulledJob(t.Context(), pulledJob, repo.ID, nil)
 	if err != nil {
 		t.Fatalf("UpsertPulledJob (empty model) failed: %v", err)
 	}
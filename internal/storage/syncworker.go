@@ -396,7 +396,7 @@ func (w *SyncWorker) connect(timeout time.Duration) (bool, error) {
 		return false, fmt.Errorf("get database ID: %w", err)
 	}
 
-	lastTargetID, err := w.db.GetSyncState(SyncStateSyncTargetID)
+	lastTargetID, err := w.db.GetSyncState(ctx, SyncStateSyncTargetID)
 	if err != nil {
 		pool.Close()
 		return false, fmt.Errorf("get sync target ID: %w", err)
@@ -412,13 +412,13 @@ func (w *SyncWorker) connect(timeout time.Duration) (bool, error) {
 			newID = newID[:8]
 		}
 		log.Printf("Sync: detected new Postgres database (was %s, now %s), clearing sync state for full re-sync", oldID, newID)
-		if err := w.db.ClearAllSyncedAt(); err != nil {
+		if err := w.db.ClearAllSyncedAt(ctx); err != nil {
 			pool.Close()
 			return false, fmt.Errorf("clear synced_at: %w", err)
 		}
 		// Also clear pull cursors so we pull all data from the new database
 		for _, key := range []string{SyncStateLastJobCursor, SyncStateLastReviewCursor, SyncStateLastResponseID} {
-			if err := w.db.SetSyncState(key, ""); err != nil {
+			if err := w.db.SetSyncState(ctx, key, ""); err != nil {
 				pool.Close()
 				return false, fmt.Errorf("clear %s: %w", key, err)
 			}
@@ -426,7 +426,7 @@ func (w *SyncWorker) connect(timeout time.Duration) (bool, error) {
 	}
 
 	// Update the sync target ID
-	if err := w.db.SetSyncState(SyncStateSyncTargetID, dbID); err != nil {
+	if err := w.db.SetSyncState(ctx, SyncStateSyncTargetID, dbID); err != nil {
 		pool.Close()
 		return false, fmt.Errorf("set sync target ID: %w", err)
 	}
@@ -550,7 +550,7 @@ func (w *SyncWorker) pushChangesWithStats(ctx context.Context, pool *PgPool) (pu
 	}
 
 	// Push jobs - need to resolve repo/commit IDs first, then batch insert
-	jobs, err := w.db.GetJobsToSync(machineID, syncBatchSize)
+	jobs, err := w.db.GetJobsToSync(ctx, machineID, syncBatchSize)
 	if err != nil {
 		return stats, fmt.Errorf("get jobs to sync: %w", err)
 	}
@@ -605,7 +605,7 @@ func (w *SyncWorker) pushChangesWithStats(ctx context.Context, pool *PgPool) (pu
 				}
 			}
 			if len(syncedJobIDs) > 0 {
-				if err := w.db.MarkJobsSynced(syncedJobIDs); err != nil {
+				if err := w.db.MarkJobsSynced(ctx, syncedJobIDs); err != nil {
 					log.Printf("Sync: failed to mark jobs synced: %v", err)
 				}
 			}
@@ -613,7 +613,7 @@ func (w *SyncWorker) pushChangesWithStats(ctx context.Context, pool *PgPool) (pu
 	}
 
 	// Push reviews - batch operation
-	reviews, err := w.db.GetReviewsToSync(machineID, syncBatchSize)
+	reviews, err := w.db.GetReviewsToSync(ctx, machineID, syncBatchSize)
 	if err != nil {
 		return stats, fmt.Errorf("get reviews to sync: %w", err)
 	}
@@ -633,14 +633,14 @@ func (w *SyncWorker) pushChangesWithStats(ctx context.Context, pool *PgPool) (pu
 			}
 		}
 		if len(syncedReviewIDs) > 0 {
-			if err := w.db.MarkReviewsSynced(syncedReviewIDs); err != nil {
+			if err := w.db.MarkReviewsSynced(ctx, syncedReviewIDs); err != nil {
 				log.Printf("Sync: failed to mark reviews synced: %v", err)
 			}
 		}
 	}
 
 	// Push comments - batch operation
-	responses, err := w.db.GetCommentsToSync(machineID, syncBatchSize)
+	responses, err := w.db.GetCommentsToSync(ctx, machineID, syncBatchSize)
 	if err != nil {
 		return stats, fmt.Errorf("get comments to sync: %w", err)
 	}
@@ -660,7 +660,7 @@ func (w *SyncWorker) pushChangesWithStats(ctx context.Context, pool *PgPool) (pu
 			}
 		}
 		if len(syncedResponseIDs) > 0 {
-			if err := w.db.MarkCommentsSynced(syncedResponseIDs); err != nil {
+			if err := w.db.MarkCommentsSynced(ctx, syncedResponseIDs); err != nil {
 				log.Printf("Sync: failed to mark comments synced: %v", err)
 			}
 		}
@@ -715,7 +715,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 	}
 
 	// Pull jobs
-	jobCursor, err := w.db.GetSyncState(SyncStateLastJobCursor)
+	jobCursor, err := w.db.GetSyncState(ctx, SyncStateLastJobCursor)
 	if err != nil {
 		return stats, fmt.Errorf("get job cursor: %w", err)
 	}
@@ -730,7 +730,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 		}
 
 		for _, j := range jobs {
-			if err := w.pullJob(j); err != nil {
+			if err := w.pullJob(ctx, j); err != nil {
 				// Don't advance cursor if any upsert fails - we'll retry next sync
 				return stats, fmt.Errorf("pull job %s: %w", j.UUID, err)
 			}
@@ -738,7 +738,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 		}
 
 		jobCursor = newCursor
-		if err := w.db.SetSyncState(SyncStateLastJobCursor, jobCursor); err != nil {
+		if err := w.db.SetSyncState(ctx, SyncStateLastJobCursor, jobCursor); err != nil {
 			return stats, fmt.Errorf("save job cursor: %w", err)
 		}
 
@@ -750,12 +750,12 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 	// Pull reviews - only for jobs we have locally.
 	// Note: knownJobUUIDs is fetched AFTER pulling all jobs above, so it includes
 	// any jobs we just pulled in this sync cycle.
-	reviewCursor, err := w.db.GetSyncState(SyncStateLastReviewCursor)
+	reviewCursor, err := w.db.GetSyncState(ctx, SyncStateLastReviewCursor)
 	if err != nil {
 		return stats, fmt.Errorf("get review cursor: %w", err)
 	}
 
-	knownJobUUIDs, err := w.db.GetKnownJobUUIDs()
+	knownJobUUIDs, err := w.db.GetKnownJobUUIDs(ctx)
 	if err != nil {
 		return stats, fmt.Errorf("get known job UUIDs: %w", err)
 	}
@@ -781,7 +781,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 				CreatedAt:          r.CreatedAt,
 				UpdatedAt:          r.UpdatedAt,
 			}
-			if err := w.db.UpsertPulledReview(pr); err != nil {
+			if err := w.db.UpsertPulledReview(ctx, pr); err != nil {
 				// Don't advance cursor if any upsert fails - we'll retry next sync
 				return stats, fmt.Errorf("pull review %s: %w", r.UUID, err)
 			}
@@ -789,7 +789,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 		}
 
 		reviewCursor = newCursor
-		if err := w.db.SetSyncState(SyncStateLastReviewCursor, reviewCursor); err != nil {
+		if err := w.db.SetSyncState(ctx, SyncStateLastReviewCursor, reviewCursor); err != nil {
 			return stats, fmt.Errorf("save review cursor: %w", err)
 		}
 
@@ -799,7 +799,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 	}
 
 	// Pull responses
-	responseIDStr, err := w.db.GetSyncState(SyncStateLastResponseID)
+	responseIDStr, err := w.db.GetSyncState(ctx, SyncStateLastResponseID)
 	if err != nil {
 		return stats, fmt.Errorf("get response cursor: %w", err)
 	}
@@ -826,7 +826,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 				SourceMachineID: r.SourceMachineID,
 				CreatedAt:       r.CreatedAt,
 			}
-			if err := w.db.UpsertPulledResponse(pr); err != nil {
+			if err := w.db.UpsertPulledResponse(ctx, pr); err != nil {
 				// Don't advance cursor if any upsert fails - we'll retry next sync
 				return stats, fmt.Errorf("pull response %s: %w", r.UUID, err)
 			}
@@ -834,7 +834,7 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 		}
 
 		responseID = newID
-		if err := w.db.SetSyncState(SyncStateLastResponseID, fmt.Sprintf("%d", responseID)); err != nil {
+		if err := w.db.SetSyncState(ctx, SyncStateLastResponseID, fmt.Sprintf("%d", responseID)); err != nil {
 			return stats, fmt.Errorf("save response cursor: %w", err)
 		}
 
@@ -851,9 +851,9 @@ func (w *SyncWorker) pullChangesWithStats(ctx context.Context, pool *PgPool) (pu
 }
 
 // pullJob inserts a pulled job into SQLite, creating repo/commit as needed
-func (w *SyncWorker) pullJob(j PulledJob) error {
+func (w *SyncWorker) pullJob(ctx context.Context, j PulledJob) error {
 	// Get or create repo by identity
-	repoID, err := w.db.GetOrCreateRepoByIdentity(j.RepoIdentity)
+	repoID, err := w.db.GetOrCreateRepoByIdentity(ctx, j.RepoIdentity)
 	if err != nil {
 		return fmt.Errorf("get or create repo: %w", err)
 	}
@@ -861,14 +861,14 @@ func (w *SyncWorker) pullJob(j PulledJob) error {
 	// Get or create commit if we have one
 	var commitID *int64
 	if j.CommitSHA != "" {
-		id, err := w.db.GetOrCreateCommitByRepoAndSHA(repoID, j.CommitSHA, j.CommitAuthor, j.CommitSubject, j.CommitTimestamp)
+		id, err := w.db.GetOrCreateCommitByRepoAndSHA(ctx, repoID, j.CommitSHA, j.CommitAuthor, j.CommitSubject, j.CommitTimestamp)
 		if err != nil {
 			return fmt.Errorf("get or create commit: %w", err)
 		}
 		commitID = &id
 	}
 
-	return w.db.UpsertPulledJob(j, repoID, commitID)
+	return w.db.UpsertPulledJob(ctx, j, repoID, commitID)
 }
 
 func min(a, b time.Duration) time.Duration {
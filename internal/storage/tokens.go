@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Roles an API token grants, in increasing order of access
+const (
+	RoleViewer   = "viewer"   // Read reviews, jobs and status
+	RoleReviewer = "reviewer" // Also comment on, address and triage reviews
+	RoleAdmin    = "admin"    // Also manage the queue, repos, sync and tokens
+
+	// RoleEnqueue is outside the hierarchy: it only enqueues reviews of the
+	// token's repo, for CI pipelines
+	RoleEnqueue = "enqueue"
+)
+
+// apiTokenPrefix marks roborev API tokens so they are recognizable in
+// config files and secret scanners
+const apiTokenPrefix = "rbv_"
+
+// ErrInvalidRole is returned for a role other than viewer, reviewer, admin
+// or enqueue
+var ErrInvalidRole = errors.New("invalid role (valid: viewer, reviewer, admin, enqueue)")
+
+// RoleAllows reports whether role grants the access of required
+func RoleAllows(role, required string) bool {
+	return roleRank(role) >= roleRank(required) && roleRank(required) > 0
+}
+
+func roleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleReviewer:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// APIToken is a named credential for remote access to the daemon API.
+// Only a hash of the token is stored.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Repo       string     `json:"repo,omitempty"` // Main repo root an enqueue token is limited to
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken creates a token with the given name and role and returns
+// its value, which cannot be retrieved later. Enqueue tokens need a repo;
+// use CreateEnqueueToken.
+func (db *DB) CreateAPIToken(name, role string) (string, *APIToken, error) {
+	if role == RoleEnqueue {
+		return "", nil, fmt.Errorf("enqueue tokens need a repo")
+	}
+	if roleRank(role) == 0 {
+		return "", nil, ErrInvalidRole
+	}
+	return db.createAPIToken(name, role, "")
+}
+
+// CreateEnqueueToken creates a token that can only enqueue reviews of the
+// repo at repoRoot and returns its value, which cannot be retrieved later
+func (db *DB) CreateEnqueueToken(name, repoRoot string) (string, *APIToken, error) {
+	if repoRoot == "" {
+		return "", nil, fmt.Errorf("enqueue tokens need a repo")
+	}
+	return db.createAPIToken(name, RoleEnqueue, repoRoot)
+}
+
+func (db *DB) createAPIToken(name, role, repo string) (string, *APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("token name is required")
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generate token: %w", err)
+	}
+	token := apiTokenPrefix + hex.EncodeToString(b)
+
+	now := time.Now()
+	result, err := db.Exec(`INSERT INTO api_tokens (name, token_hash, role, repo, created_at) VALUES (?, ?, ?, NULLIF(?, ''), ?)`,
+		name, hashAPIToken(token), role, repo, now.Format(time.RFC3339))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return "", nil, fmt.Errorf("a token named %q already exists", name)
+		}
+		return "", nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &APIToken{ID: id, Name: name, Role: role, Repo: repo, CreatedAt: now}, nil
+}
+
+// AuthenticateAPIToken returns the token with the given value and records
+// its use. Returns sql.ErrNoRows if there is none.
+func (db *DB) AuthenticateAPIToken(token string) (*APIToken, error) {
+	hash := hashAPIToken(token)
+	var t APIToken
+	var createdAt string
+	var lastUsedAt sql.NullString
+	err := db.QueryRow(`SELECT id, name, role, COALESCE(repo, ''), created_at, last_used_at FROM api_tokens WHERE token_hash = ?`, hash).
+		Scan(&t.ID, &t.Name, &t.Role, &t.Repo, &createdAt, &lastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.CreatedAt = parseSQLiteTime(createdAt)
+	now := time.Now()
+	if _, err := db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now.Format(time.RFC3339), t.ID); err != nil {
+		return nil, err
+	}
+	t.LastUsedAt = &now
+	return &t, nil
+}
+
+// HasAPITokens reports whether any API token exists
+func (db *DB) HasAPITokens() (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM api_tokens`).Scan(&n)
+	return n > 0, err
+}
+
+// ListAPITokens returns all API tokens, by name
+func (db *DB) ListAPITokens() ([]APIToken, error) {
+	rows, err := db.Query(`SELECT id, name, role, COALESCE(repo, ''), created_at, last_used_at FROM api_tokens ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		var createdAt string
+		var lastUsedAt sql.NullString
+		if err := rows.Scan(&t.ID, &t.Name, &t.Role, &t.Repo, &createdAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = parseSQLiteTime(createdAt)
+		if lastUsedAt.Valid {
+			used := parseSQLiteTime(lastUsedAt.String)
+			t.LastUsedAt = &used
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteAPIToken revokes the token with the given name. Returns
+// sql.ErrNoRows if there is none.
+func (db *DB) DeleteAPIToken(name string) error {
+	result, err := db.Exec(`DELETE FROM api_tokens WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAPITokens(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if has, err := db.HasAPITokens(); err != nil || has {
+		t.Fatalf("expected no tokens, got %v, %v", has, err)
+	}
+	if _, _, err := db.CreateAPIToken("ci", "owner"); !errors.Is(err, ErrInvalidRole) {
+		t.Errorf("expected ErrInvalidRole, got %v", err)
+	}
+
+	token, created, err := db.CreateAPIToken("ci", RoleReviewer)
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	if !strings.HasPrefix(token, apiTokenPrefix) || created.Role != RoleReviewer {
+		t.Errorf("unexpected token %q, %+v", token, created)
+	}
+	if _, _, err := db.CreateAPIToken("ci", RoleViewer); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if has, err := db.HasAPITokens(); err != nil || !has {
+		t.Fatalf("expected tokens, got %v, %v", has, err)
+	}
+
+	got, err := db.AuthenticateAPIToken(token)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken: %v", err)
+	}
+	if got.Name != "ci" || got.Role != RoleReviewer {
+		t.Errorf("unexpected token %+v", got)
+	}
+	if _, err := db.AuthenticateAPIToken(token + "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for an unknown token, got %v", err)
+	}
+
+	tokens, err := db.ListAPITokens()
+	if err != nil {
+		t.Fatalf("ListAPITokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Errorf("expected one used token, got %+v", tokens)
+	}
+
+	if err := db.DeleteAPIToken("ci"); err != nil {
+		t.Fatalf("DeleteAPIToken: %v", err)
+	}
+	if err := db.DeleteAPIToken("ci"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+	if _, err := db.AuthenticateAPIToken(token); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected a revoked token to fail, got %v", err)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role, required string
+		want           bool
+	}{
+		{RoleAdmin, RoleViewer, true},
+		{RoleAdmin, RoleAdmin, true},
+		{RoleReviewer, RoleViewer, true},
+		{RoleReviewer, RoleAdmin, false},
+		{RoleViewer, RoleReviewer, false},
+		{"", RoleViewer, false},
+		{RoleAdmin, "", false},
+		{RoleEnqueue, RoleViewer, false},
+		{RoleAdmin, RoleEnqueue, false},
+	}
+	for _, tt := range tests {
+		if got := RoleAllows(tt.role, tt.required); got != tt.want {
+			t.Errorf("RoleAllows(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestEnqueueTokens(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if _, _, err := db.CreateAPIToken("ci", RoleEnqueue); err == nil {
+		t.Error("expected an enqueue token without a repo to fail")
+	}
+	if _, _, err := db.CreateEnqueueToken("ci", ""); err == nil {
+		t.Error("expected an enqueue token without a repo to fail")
+	}
+
+	token, created, err := db.CreateEnqueueToken("ci", "/src/app")
+	if err != nil {
+		t.Fatalf("CreateEnqueueToken: %v", err)
+	}
+	if created.Role != RoleEnqueue || created.Repo != "/src/app" {
+		t.Errorf("unexpected created token %+v", created)
+	}
+	got, err := db.AuthenticateAPIToken(token)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken: %v", err)
+	}
+	if got.Role != RoleEnqueue || got.Repo != "/src/app" {
+		t.Errorf("unexpected authenticated token %+v", got)
+	}
+
+	if _, _, err := db.CreateAPIToken("dashboard", RoleViewer); err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	tokens, err := db.ListAPITokens()
+	if err != nil {
+		t.Fatalf("ListAPITokens: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].Repo != "/src/app" || tokens[1].Repo != "" {
+		t.Errorf("unexpected tokens %+v", tokens)
+	}
+}
+
+func TestMigrateAPITokenScope(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tokens.db")
+	rawDB, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw DB: %v", err)
+	}
+	_, err = rawDB.Exec(`
+		CREATE TABLE api_tokens (
+		  id INTEGER PRIMARY KEY,
+		  name TEXT UNIQUE NOT NULL,
+		  token_hash TEXT UNIQUE NOT NULL,
+		  role TEXT NOT NULL CHECK(role IN ('viewer', 'reviewer', 'admin')),
+		  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+		  last_used_at TEXT
+		);
+		INSERT INTO api_tokens (name, token_hash, role) VALUES ('old', ?, 'admin');
+	`, hashAPIToken("rbv_old"))
+	rawDB.Close()
+	if err != nil {
+		t.Fatalf("create old api_tokens: %v", err)
+	}
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	got, err := db.AuthenticateAPIToken("rbv_old")
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken: %v", err)
+	}
+	if got.Name != "old" || got.Role != RoleAdmin {
+		t.Errorf("unexpected migrated token %+v", got)
+	}
+	if _, _, err := db.CreateEnqueueToken("ci", "/src/app"); err != nil {
+		t.Errorf("CreateEnqueueToken after migration: %v", err)
+	}
+}
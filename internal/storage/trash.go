@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrashOperation is a destructive operation whose deleted rows are kept in
+// the trash so it can be undone until ExpiresAt
+type TrashOperation struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"` // e.g. "repo delete"
+	Summary   string    `json:"summary"`   // What was deleted, for listings
+	Rows      int64     `json:"rows"`      // Number of rows in the trash
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ErrUndoConflict is returned when restoring a trashed row would clash with
+// a row created since, such as a repository added again at the same path
+var ErrUndoConflict = errors.New("rows created since the operation conflict with the trashed rows")
+
+// trashStep is one table a destructive operation deletes rows from, with
+// the SQL condition selecting them
+type trashStep struct {
+	table string
+	where string
+}
+
+// beginTrash records a new trash operation on conn, which must be inside a
+// transaction, after purging operations past their retention
+func beginTrash(ctx context.Context, conn *sql.Conn, operation, summary string, retention time.Duration) (*TrashOperation, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := purgeExpiredTrash(ctx, conn, now); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate operation ID: %w", err)
+	}
+	op := &TrashOperation{
+		ID:        hex.EncodeToString(b),
+		Operation: operation,
+		Summary:   summary,
+		CreatedAt: now,
+		ExpiresAt: now.Add(retention),
+	}
+	_, err := conn.ExecContext(ctx, `
+		INSERT INTO trash_operations (id, operation, summary, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, op.ID, op.Operation, op.Summary, op.CreatedAt.Format(time.RFC3339), op.ExpiresAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// tableColumns returns the column names of table
+func tableColumns(ctx context.Context, conn *sql.Conn, table string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("no such table: %s", table)
+	}
+	return cols, nil
+}
+
+// moveToTrash copies the rows selected by step into the trash of operation
+// opID as JSON objects, then deletes them. Returns the number of rows moved.
+func moveToTrash(ctx context.Context, conn *sql.Conn, opID string, step trashStep, args ...any) (int64, error) {
+	cols, err := tableColumns(ctx, conn, step.table)
+	if err != nil {
+		return 0, err
+	}
+	pairs := make([]string, len(cols))
+	for i, c := range cols {
+		pairs[i] = fmt.Sprintf(`'%s', "%s"`, c, c)
+	}
+
+	copyArgs := append([]any{opID, step.table}, args...)
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO trash_rows (operation_id, table_name, data)
+		SELECT ?, ?, json_object(`+strings.Join(pairs, ", ")+`)
+		FROM `+step.table+` WHERE `+step.where, copyArgs...)
+	if err != nil {
+		return 0, err
+	}
+	result, err := conn.ExecContext(ctx, `DELETE FROM `+step.table+` WHERE `+step.where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// purgeExpiredTrash permanently deletes the rows of operations whose
+// retention has passed
+func purgeExpiredTrash(ctx context.Context, conn *sql.Conn, now time.Time) error {
+	expired := now.UTC().Format(time.RFC3339)
+	_, err := conn.ExecContext(ctx, `
+		DELETE FROM trash_rows WHERE operation_id IN (
+			SELECT id FROM trash_operations WHERE expires_at <= ?
+		)
+	`, expired)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `DELETE FROM trash_operations WHERE expires_at <= ?`, expired)
+	return err
+}
+
+// PurgeExpiredTrash permanently deletes the rows of operations whose
+// retention has passed by now
+func (db *DB) PurgeExpiredTrash(ctx context.Context, now time.Time) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return purgeExpiredTrash(ctx, conn, now)
+}
+
+// ListTrash returns the operations that can still be undone, newest first
+func (db *DB) ListTrash() ([]TrashOperation, error) {
+	rows, err := db.Query(`
+		SELECT o.id, o.operation, o.summary, o.created_at, o.expires_at,
+			(SELECT COUNT(*) FROM trash_rows r WHERE r.operation_id = o.id)
+		FROM trash_operations o
+		WHERE o.expires_at > ?
+		ORDER BY o.created_at DESC, o.rowid DESC
+	`, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ops []TrashOperation
+	for rows.Next() {
+		var op TrashOperation
+		var createdAt, expiresAt string
+		if err := rows.Scan(&op.ID, &op.Operation, &op.Summary, &createdAt, &expiresAt, &op.Rows); err != nil {
+			return nil, err
+		}
+		op.CreatedAt = parseSQLiteTime(createdAt)
+		op.ExpiresAt = parseSQLiteTime(expiresAt)
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+// UndoTrash restores the rows deleted by a trash operation and removes the
+// operation from the trash. Returns sql.ErrNoRows if the operation does not
+// exist or its retention has passed, and ErrUndoConflict if a restored row
+// clashes with one created since.
+func (db *DB) UndoTrash(ctx context.Context, opID string) (*TrashOperation, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+	}()
+
+	var op TrashOperation
+	var createdAt, expiresAt string
+	err = conn.QueryRowContext(ctx, `
+		SELECT id, operation, summary, created_at, expires_at FROM trash_operations
+		WHERE id = ? AND expires_at > ?
+	`, opID, time.Now().UTC().Format(time.RFC3339)).Scan(&op.ID, &op.Operation, &op.Summary, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	op.CreatedAt = parseSQLiteTime(createdAt)
+	op.ExpiresAt = parseSQLiteTime(expiresAt)
+
+	// Rows are restored in reverse order of deletion, so parents come back
+	// before the rows that reference them
+	type trashedRow struct {
+		table string
+		data  string
+	}
+	rows, err := conn.QueryContext(ctx, `
+		SELECT table_name, data FROM trash_rows WHERE operation_id = ? ORDER BY id DESC
+	`, op.ID)
+	if err != nil {
+		return nil, err
+	}
+	var trashed []trashedRow
+	for rows.Next() {
+		var r trashedRow
+		if err := rows.Scan(&r.table, &r.data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		trashed = append(trashed, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Columns dropped since the delete are skipped; columns added since get
+	// their defaults
+	columns := make(map[string]map[string]bool)
+	for _, r := range trashed {
+		if columns[r.table] == nil {
+			cols, err := tableColumns(ctx, conn, r.table)
+			if err != nil {
+				return nil, err
+			}
+			columns[r.table] = make(map[string]bool, len(cols))
+			for _, c := range cols {
+				columns[r.table][c] = true
+			}
+		}
+
+		dec := json.NewDecoder(strings.NewReader(r.data))
+		dec.UseNumber()
+		var values map[string]any
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("decode trashed %s row: %w", r.table, err)
+		}
+		var names, marks []string
+		var args []any
+		for name, v := range values {
+			if !columns[r.table][name] {
+				continue
+			}
+			if n, ok := v.(json.Number); ok {
+				if i, err := n.Int64(); err == nil {
+					v = i
+				} else if f, err := n.Float64(); err == nil {
+					v = f
+				}
+			}
+			names = append(names, `"`+name+`"`)
+			marks = append(marks, "?")
+			args = append(args, v)
+		}
+		_, err := conn.ExecContext(ctx, `INSERT INTO `+r.table+` (`+strings.Join(names, ", ")+`) VALUES (`+strings.Join(marks, ", ")+`)`, args...)
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return nil, fmt.Errorf("%w: %v", ErrUndoConflict, err)
+			}
+			return nil, fmt.Errorf("restore %s row: %w", r.table, err)
+		}
+		op.Rows++
+	}
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM trash_rows WHERE operation_id = ?`, op.ID); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM trash_operations WHERE id = ?`, op.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, err
+	}
+	committed = true
+	return &op, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrashRepoUndo(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/trash-repo")
+	commit := createCommit(t, db, repo.ID, "trash-sha")
+	job := enqueueJob(t, db, repo.ID, commit.ID, "trash-sha")
+	claimJob(t, db, "worker-1")
+	if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", "output"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AddCommentToJob(t.Context(), job.ID, "user", "keep this"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveJobUsage(JobUsage{JobID: job.ID, PromptTokens: 100, CompletionTokens: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := db.TrashRepo(t.Context(), repo.ID, true, time.Hour)
+	if err != nil {
+		t.Fatalf("TrashRepo: %v", err)
+	}
+	if op.Operation != "repo delete" || op.Rows < 6 {
+		t.Errorf("unexpected operation %+v", op)
+	}
+	if _, err := db.GetRepoByID(t.Context(), repo.ID); err == nil {
+		t.Fatal("expected the repo to be deleted")
+	}
+	ops, err := db.ListTrash()
+	if err != nil || len(ops) != 1 || ops[0].ID != op.ID || ops[0].Rows != op.Rows {
+		t.Fatalf("expected the operation in the trash, got %+v, %v", ops, err)
+	}
+
+	restored, err := db.UndoTrash(t.Context(), op.ID)
+	if err != nil {
+		t.Fatalf("UndoTrash: %v", err)
+	}
+	if restored.Rows != op.Rows {
+		t.Errorf("restored %d rows, trashed %d", restored.Rows, op.Rows)
+	}
+	if got, err := db.GetRepoByID(t.Context(), repo.ID); err != nil || got.RootPath != repo.RootPath {
+		t.Errorf("expected the repo back, got %+v, %v", got, err)
+	}
+	review, err := db.GetReviewByJobID(t.Context(), job.ID)
+	if err != nil || review.Output != "output" || review.Job.Status != JobStatusDone {
+		t.Errorf("expected the review back, got %+v, %v", review, err)
+	}
+	if comments, err := db.GetCommentsForJob(t.Context(), job.ID); err != nil || len(comments) != 1 || comments[0].Response != "keep this" {
+		t.Errorf("expected the comment back, got %+v, %v", comments, err)
+	}
+	if usage, err := db.GetJobUsage(job.ID); err != nil || usage == nil || usage.PromptTokens != 100 {
+		t.Errorf("expected the token usage back, got %+v, %v", usage, err)
+	}
+
+	if _, err := db.UndoTrash(t.Context(), op.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected an undone operation to be gone, got %v", err)
+	}
+}
+
+func TestUndoTrashConflict(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/trash-conflict")
+	op, err := db.TrashRepo(t.Context(), repo.ID, false, time.Hour)
+	if err != nil {
+		t.Fatalf("TrashRepo: %v", err)
+	}
+	createRepo(t, db, "/tmp/trash-conflict")
+
+	if _, err := db.UndoTrash(t.Context(), op.ID); !errors.Is(err, ErrUndoConflict) {
+		t.Fatalf("expected ErrUndoConflict, got %v", err)
+	}
+	if ops, _ := db.ListTrash(); len(ops) != 1 {
+		t.Error("expected a failed undo to keep the operation in the trash")
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	op, err := db.TrashRepo(t.Context(), createRepo(t, db, "/tmp/trash-expired").ID, false, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.PurgeExpiredTrash(t.Context(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if ops, _ := db.ListTrash(); len(ops) != 1 {
+		t.Fatal("expected an operation within its retention to be kept")
+	}
+
+	if err := db.PurgeExpiredTrash(t.Context(), time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.UndoTrash(t.Context(), op.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected an expired operation to be purged, got %v", err)
+	}
+	var rows int
+	db.QueryRow(`SELECT COUNT(*) FROM trash_rows`).Scan(&rows)
+	if rows != 0 {
+		t.Errorf("expected purged rows to be deleted, %d left", rows)
+	}
+}
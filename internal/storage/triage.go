@@ -0,0 +1,321 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Triage decisions for a finding
+const (
+	TriageAccepted  = "accepted"
+	TriageDismissed = "dismissed"
+	TriageAssigned  = "assigned"
+)
+
+// ErrInvalidTriage is returned for an unknown decision or missing assignee
+var ErrInvalidTriage = errors.New("invalid triage decision")
+
+// Finding is a single severity-labeled finding within a review's output
+type Finding struct {
+	Index    int    `json:"index"`    // Position of the finding within the review, from 0
+	Severity string `json:"severity"` // critical, high, medium or low
+	Text     string `json:"text"`
+	IssueURL string `json:"issue_url,omitempty"` // Tracker issue filed for the finding, if any
+}
+
+// TriageItem is an untriaged finding along with the review it came from
+type TriageItem struct {
+	ReviewID  int64     `json:"review_id"`
+	JobID     int64     `json:"job_id"`
+	GitRef    string    `json:"git_ref"`
+	RepoName  string    `json:"repo_name"`
+	Agent     string    `json:"agent"`
+	CreatedAt time.Time `json:"created_at"`
+	Finding   Finding   `json:"finding"`
+
+	Suppression *FindingSuppression `json:"suppression,omitempty"` // Set when listing suppressed findings
+}
+
+// ExtractFindings splits review output into its severity-labeled findings,
+// using the same label detection as verdict parsing. Each finding runs from
+// its labeled line to the next blank line or finding. When the label is a
+// "Severity: High" field, the finding also includes the lines above it in
+// the same paragraph, which usually hold its title.
+func ExtractFindings(output string) []Finding {
+	lines := strings.Split(output, "\n")
+	lower := strings.Split(strings.ToLower(output), "\n")
+	severities := []string{"critical", "high", "medium", "low"}
+
+	var findings []Finding
+	prevEnd := 0
+	for i := 0; i < len(lines); i++ {
+		sev := lineSeverity(lower, i, severities)
+		if sev == "" {
+			continue
+		}
+
+		start := i
+		if isSeverityField(lower[i]) {
+			for start > prevEnd && strings.TrimSpace(lines[start-1]) != "" {
+				start--
+			}
+		}
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" && lineSeverity(lower, end, severities) == "" {
+			end++
+		}
+
+		findings = append(findings, Finding{
+			Index:    len(findings),
+			Severity: sev,
+			Text:     strings.TrimSpace(strings.Join(lines[start:end], "\n")),
+		})
+		prevEnd = end
+		i = end - 1
+	}
+	return findings
+}
+
+// isSeverityField reports whether a lowercase finding line is a
+// "Severity: <level>" field rather than a line led by the severity word.
+func isSeverityField(line string) bool {
+	text := strings.TrimLeft(strings.TrimSpace(line), "-*•0123456789.) ")
+	return strings.HasPrefix(stripMarkdown(text), "severity")
+}
+
+// SetFindingTriage records a triage decision for a finding, replacing any
+// earlier decision. The assignee is required for TriageAssigned and ignored
+// otherwise. Returns sql.ErrNoRows if the review does not exist.
+func (db *DB) SetFindingTriage(reviewID int64, findingIndex int, decision, assignee string) error {
+	switch decision {
+	case TriageAccepted, TriageDismissed:
+		assignee = ""
+	case TriageAssigned:
+		if strings.TrimSpace(assignee) == "" {
+			return fmt.Errorf("%w: assignee is required", ErrInvalidTriage)
+		}
+	default:
+		return fmt.Errorf("%w %q (valid: %s, %s, %s)", ErrInvalidTriage, decision,
+			TriageAccepted, TriageDismissed, TriageAssigned)
+	}
+
+	var exists int
+	if err := db.QueryRow(`SELECT 1 FROM reviews WHERE id = ?`, reviewID).Scan(&exists); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO finding_triage (review_id, finding_index, decision, assignee, created_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?)
+		ON CONFLICT(review_id, finding_index) DO UPDATE SET
+			decision = excluded.decision,
+			assignee = excluded.assignee,
+			created_at = excluded.created_at
+	`, reviewID, findingIndex, decision, strings.TrimSpace(assignee), time.Now().Format(time.RFC3339))
+	return err
+}
+
+// ListUntriagedFindings returns findings from unaddressed reviews that have
+// no triage decision yet and are not suppressed, newest review first, along
+// with the total number of untriaged findings. repoID 0 includes all repos; limit 0 returns only
+// the total. Task jobs are excluded since their output is not a review.
+func (db *DB) ListUntriagedFindings(repoID int64, limit int) ([]TriageItem, int, error) {
+	query := `
+		SELECT rv.id, rv.job_id, rv.agent, rv.output, rv.created_at, j.git_ref, rp.name
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos rp ON rp.id = j.repo_id
+		WHERE rv.addressed = 0 AND j.status = 'done' AND COALESCE(j.job_type, '') != 'task'`
+	var args []any
+	if repoID != 0 {
+		query += ` AND j.repo_id = ?`
+		args = append(args, repoID)
+	}
+	query += ` ORDER BY rv.created_at DESC, rv.id DESC`
+
+	triaged, err := db.triagedFindings()
+	if err != nil {
+		return nil, 0, err
+	}
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, 0, err
+	}
+	issues, err := db.findingIssueURLs()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []TriageItem{}
+	total := 0
+	for rows.Next() {
+		var item TriageItem
+		var output, createdAt string
+		if err := rows.Scan(&item.ReviewID, &item.JobID, &item.Agent, &output, &createdAt,
+			&item.GitRef, &item.RepoName); err != nil {
+			return nil, 0, err
+		}
+		item.CreatedAt = parseSQLiteTime(createdAt)
+
+		for _, f := range ExtractFindings(db.loadBlob(output)) {
+			key := triageKey{item.ReviewID, f.Index}
+			if _, ok := suppressed[key]; ok || triaged[key] {
+				continue
+			}
+			total++
+			if len(items) < limit {
+				f.IssueURL = issues[triageKey{item.ReviewID, f.Index}]
+				item.Finding = f
+				items = append(items, item)
+			}
+		}
+	}
+	return items, total, rows.Err()
+}
+
+// ListFindingsSince returns the findings of a repo's completed reviews
+// created since the given time, leaving out findings triaged as dismissed
+// and suppressed ones. Task jobs are excluded since their output is not a
+// review.
+func (db *DB) ListFindingsSince(repoID int64, since time.Time) ([]Finding, error) {
+	dismissed, err := db.triagedFindings(TriageDismissed)
+	if err != nil {
+		return nil, err
+	}
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT rv.id, rv.output
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		WHERE j.repo_id = ? AND j.status = 'done' AND COALESCE(j.job_type, '') != 'task'
+		  AND datetime(rv.created_at) >= datetime(?)
+		ORDER BY rv.id
+	`, repoID, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []Finding
+	for rows.Next() {
+		var reviewID int64
+		var output string
+		if err := rows.Scan(&reviewID, &output); err != nil {
+			return nil, err
+		}
+		for _, f := range ExtractFindings(db.loadBlob(output)) {
+			key := triageKey{reviewID, f.Index}
+			if _, ok := suppressed[key]; !ok && !dismissed[key] {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings, rows.Err()
+}
+
+// ListOpenFindings returns the findings of a repo's unaddressed reviews
+// created since the given time that are still open: not dismissed,
+// assigned to someone or suppressed. Oldest review first. Task jobs are
+// excluded since their output is not a review.
+func (db *DB) ListOpenFindings(repoID int64, since time.Time) ([]TriageItem, error) {
+	closed, err := db.triagedFindings(TriageDismissed, TriageAssigned)
+	if err != nil {
+		return nil, err
+	}
+	suppressed, err := db.suppressedFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT rv.id, rv.job_id, rv.agent, rv.output, rv.created_at, j.git_ref, rp.name
+		FROM reviews rv
+		JOIN review_jobs j ON j.id = rv.job_id
+		JOIN repos rp ON rp.id = j.repo_id
+		WHERE j.repo_id = ? AND rv.addressed = 0 AND j.status = 'done' AND COALESCE(j.job_type, '') != 'task'
+		  AND datetime(rv.created_at) >= datetime(?)
+		ORDER BY rv.id
+	`, repoID, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []TriageItem
+	for rows.Next() {
+		var item TriageItem
+		var output, createdAt string
+		if err := rows.Scan(&item.ReviewID, &item.JobID, &item.Agent, &output, &createdAt,
+			&item.GitRef, &item.RepoName); err != nil {
+			return nil, err
+		}
+		item.CreatedAt = parseSQLiteTime(createdAt)
+		for _, f := range ExtractFindings(db.loadBlob(output)) {
+			key := triageKey{item.ReviewID, f.Index}
+			if _, ok := suppressed[key]; !ok && !closed[key] {
+				item.Finding = f
+				items = append(items, item)
+			}
+		}
+	}
+	return items, rows.Err()
+}
+
+type triageKey struct {
+	reviewID int64
+	index    int
+}
+
+// triagedFindings returns the set of findings that have a triage decision,
+// limited to the given decisions if any
+func (db *DB) triagedFindings(decisions ...string) (map[triageKey]bool, error) {
+	query := `SELECT review_id, finding_index FROM finding_triage`
+	var args []any
+	if len(decisions) > 0 {
+		query += ` WHERE decision IN (?` + strings.Repeat(", ?", len(decisions)-1) + `)`
+		for _, d := range decisions {
+			args = append(args, d)
+		}
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	triaged := make(map[triageKey]bool)
+	for rows.Next() {
+		var key triageKey
+		if err := rows.Scan(&key.reviewID, &key.index); err != nil {
+			return nil, err
+		}
+		triaged[key] = true
+	}
+	return triaged, rows.Err()
+}
+
+// GetFindingTriage returns the decision and assignee recorded for a finding.
+// Returns sql.ErrNoRows if the finding has not been triaged.
+func (db *DB) GetFindingTriage(reviewID int64, findingIndex int) (string, string, error) {
+	var decision string
+	var assignee sql.NullString
+	err := db.QueryRow(`SELECT decision, assignee FROM finding_triage WHERE review_id = ? AND finding_index = ?`,
+		reviewID, findingIndex).Scan(&decision, &assignee)
+	if err != nil {
+		return "", "", err
+	}
+	return decision, assignee.String, nil
+}
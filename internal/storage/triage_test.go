@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExtractFindings(t *testing.T) {
+	output := `## Review
+
+Findings:
+- **High**: Nil pointer dereference in Load
+  when the config file is empty.
+- Low: Typo in log message
+
+### Missing bounds check
+File: parse.go:42
+**Severity**: Medium
+Index can exceed slice length.
+
+No other issues.`
+
+	findings := ExtractFindings(output)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d: %+v", len(findings), findings)
+	}
+
+	want := []struct {
+		severity string
+		text     string
+	}{
+		{"high", "- **High**: Nil pointer dereference in Load\n  when the config file is empty."},
+		{"low", "- Low: Typo in log message"},
+		{"medium", "### Missing bounds check\nFile: parse.go:42\n**Severity**: Medium\nIndex can exceed slice length."},
+	}
+	for i, w := range want {
+		if findings[i].Index != i || findings[i].Severity != w.severity || findings[i].Text != w.text {
+			t.Errorf("finding %d = %+v, want severity %q text %q", i, findings[i], w.severity, w.text)
+		}
+	}
+
+	if got := ExtractFindings("No issues found."); len(got) != 0 {
+		t.Errorf("expected no findings for a passing review, got %+v", got)
+	}
+	if got := ExtractFindings("Severity levels:\n- High: must fix\n- Low: nice to have"); len(got) != 0 {
+		t.Errorf("expected legend entries to be ignored, got %+v", got)
+	}
+}
+
+func TestFindingTriage(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/triage-repo")
+	other := createRepo(t, db, "/tmp/triage-other")
+	complete := func(repoID int64, sha, output string) *Review {
+		t.Helper()
+		commit := createCommit(t, db, repoID, sha)
+		job := enqueueJob(t, db, repoID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		return review
+	}
+
+	older := complete(repo.ID, "aaa", "- High: first\n- Low: second")
+	newer := complete(repo.ID, "bbb", "- Medium: third")
+	complete(repo.ID, "ccc", "No issues found.")
+	complete(other.ID, "ddd", "- Critical: elsewhere")
+
+	items, total, err := db.ListUntriagedFindings(repo.ID, 10)
+	if err != nil {
+		t.Fatalf("ListUntriagedFindings: %v", err)
+	}
+	if total != 3 || len(items) != 3 {
+		t.Fatalf("expected 3 untriaged findings, got total=%d items=%d", total, len(items))
+	}
+	if items[0].ReviewID != newer.ID || items[1].ReviewID != older.ID || items[2].Finding.Index != 1 {
+		t.Errorf("expected newest review first, got %+v", items)
+	}
+
+	if _, total, _ := db.ListUntriagedFindings(0, 0); total != 4 {
+		t.Errorf("expected 4 untriaged findings across repos, got %d", total)
+	}
+
+	if err := db.SetFindingTriage(older.ID, 0, TriageAssigned, "alice"); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+	if err := db.SetFindingTriage(newer.ID, 0, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+	decision, assignee, err := db.GetFindingTriage(older.ID, 0)
+	if err != nil || decision != TriageAssigned || assignee != "alice" {
+		t.Errorf("GetFindingTriage = %q %q %v, want assigned alice", decision, assignee, err)
+	}
+
+	// A later decision replaces the earlier one
+	if err := db.SetFindingTriage(older.ID, 0, TriageAccepted, "ignored"); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+	decision, assignee, _ = db.GetFindingTriage(older.ID, 0)
+	if decision != TriageAccepted || assignee != "" {
+		t.Errorf("GetFindingTriage = %q %q, want accepted with no assignee", decision, assignee)
+	}
+
+	items, total, err = db.ListUntriagedFindings(repo.ID, 10)
+	if err != nil {
+		t.Fatalf("ListUntriagedFindings: %v", err)
+	}
+	if total != 1 || items[0].ReviewID != older.ID || items[0].Finding.Index != 1 {
+		t.Errorf("expected only the second finding of the older review, got total=%d %+v", total, items)
+	}
+
+	// Addressed reviews drop out of the queue
+	if err := db.MarkReviewAddressed(t.Context(), older.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+	if _, total, _ := db.ListUntriagedFindings(repo.ID, 10); total != 0 {
+		t.Errorf("expected no untriaged findings after addressing, got %d", total)
+	}
+}
+
+func TestSetFindingTriageErrors(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if err := db.SetFindingTriage(999, 0, TriageAccepted, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for missing review, got %v", err)
+	}
+	if err := db.SetFindingTriage(1, 0, "maybe", ""); !errors.Is(err, ErrInvalidTriage) {
+		t.Errorf("expected ErrInvalidTriage for unknown decision, got %v", err)
+	}
+	if err := db.SetFindingTriage(1, 0, TriageAssigned, " "); !errors.Is(err, ErrInvalidTriage) {
+		t.Errorf("expected ErrInvalidTriage for missing assignee, got %v", err)
+	}
+}
+
+func TestListFindingsSince(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/findings-repo")
+	other := createRepo(t, db, "/tmp/findings-other")
+	complete := func(repoID int64, sha, output string) *Review {
+		t.Helper()
+		commit := createCommit(t, db, repoID, sha)
+		job := enqueueJob(t, db, repoID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		return review
+	}
+
+	old := complete(repo.ID, "aaa", "- High: stale in old.go")
+	if _, err := db.Exec(`UPDATE reviews SET created_at = ? WHERE id = ?`,
+		time.Now().Add(-48*time.Hour).Format(time.RFC3339), old.ID); err != nil {
+		t.Fatal(err)
+	}
+	recent := complete(repo.ID, "bbb", "- High: race in db.go\n- Low: typo in main.go")
+	complete(other.ID, "ccc", "- Critical: elsewhere.go")
+	if err := db.SetFindingTriage(recent.ID, 1, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+
+	findings, err := db.ListFindingsSince(repo.ID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListFindingsSince: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Text != "- High: race in db.go" {
+		t.Errorf("expected only the undismissed recent finding, got %+v", findings)
+	}
+
+	findings, err = db.ListFindingsSince(repo.ID, time.Now().Add(-72*time.Hour))
+	if err != nil {
+		t.Fatalf("ListFindingsSince: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Errorf("expected 2 findings over three days, got %+v", findings)
+	}
+}
+
+func TestListOpenFindings(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	repo := createRepo(t, db, "/tmp/open-findings-repo")
+	complete := func(sha, output string) *Review {
+		t.Helper()
+		commit := createCommit(t, db, repo.ID, sha)
+		job := enqueueJob(t, db, repo.ID, commit.ID, sha)
+		claimJob(t, db, "worker")
+		if err := db.CompleteJob(t.Context(), job.ID, "codex", "prompt", output); err != nil {
+			t.Fatalf("CompleteJob: %v", err)
+		}
+		review, err := db.GetReviewByJobID(t.Context(), job.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByJobID: %v", err)
+		}
+		return review
+	}
+
+	open := complete("aaa", "- Low: typo in main.go\n- Medium: unchecked error in db.go\n- Low: unused import in x.go")
+	addressed := complete("bbb", "- Low: shadowed variable in y.go")
+	if err := db.MarkReviewAddressed(t.Context(), addressed.ID, true); err != nil {
+		t.Fatalf("MarkReviewAddressed: %v", err)
+	}
+	if err := db.SetFindingTriage(open.ID, 1, TriageDismissed, ""); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+	if err := db.SetFindingTriage(open.ID, 2, TriageAssigned, "alice"); err != nil {
+		t.Fatalf("SetFindingTriage: %v", err)
+	}
+
+	items, err := db.ListOpenFindings(repo.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListOpenFindings: %v", err)
+	}
+	if len(items) != 1 || items[0].ReviewID != open.ID || items[0].GitRef != "aaa" ||
+		items[0].Finding.Text != "- Low: typo in main.go" {
+		t.Errorf("expected only the open finding of the unaddressed review, got %+v", items)
+	}
+}
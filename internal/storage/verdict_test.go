@@ -941,3 +941,26 @@ func TestParseVerdict(t *testing.T) {
 		})
 	}
 }
+
+func TestHasCriticalFinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{"no findings", "No issues found.", false},
+		{"only high", "- High: SQL injection in handler", false},
+		{"critical bullet", "- Critical: data loss on restart", true},
+		{"critical bold", "1. **Critical** — auth bypass", true},
+		{"severity field", "Severity: Critical\nLocation: db.go", true},
+		{"critical in prose", "This is critical code but looks fine.", false},
+		{"legend only", "No issues found.\n\nSeverity levels:\n- Critical: must fix\n- Low: nit", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasCriticalFinding(tt.output); got != tt.want {
+				t.Errorf("HasCriticalFinding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package teamsync shares review history among a team through a remote
+// store, with no central daemon. Each instance pushes its finished jobs,
+// reviews and comments as bundles encrypted with the team's passphrase,
+// and pulls and merges the bundles pushed by everyone else.
+package teamsync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/blobstore"
+	"github.com/roborev-dev/roborev/internal/config"
+	"github.com/roborev-dev/roborev/internal/storage"
+)
+
+// Bundles are stored as bundles/<machine ID>/<push time>.bundle, so a
+// listing sorts each machine's bundles in the order they were pushed
+const bundlePrefix = "bundles/"
+
+// Encrypted bundles start with magic, then the key derivation salt and the
+// AES-GCM nonce
+const (
+	magic         = "roborev-sync-v1\n"
+	saltSize      = 16
+	kdfIterations = 200_000
+)
+
+// ErrDecrypt is returned when a bundle can't be decrypted, usually because
+// it was pushed with another passphrase
+var ErrDecrypt = errors.New("cannot decrypt bundle (wrong sync key?)")
+
+// Store is a remote store whose keys can be listed
+type Store interface {
+	blobstore.Store
+	blobstore.Lister
+}
+
+// Remote is a team's shared sync remote
+type Remote struct {
+	name       string
+	store      Store
+	passphrase string
+	now        func() time.Time
+}
+
+// New opens the remote configured by cfg
+func New(cfg config.SyncRemoteConfig) (*Remote, error) {
+	if strings.TrimSpace(cfg.Backend) == "" {
+		return nil, fmt.Errorf("no sync remote configured (set [sync.remote] in config.toml)")
+	}
+	if (cfg.Backend == "filesystem" || cfg.Backend == "file") && cfg.Path == "" {
+		return nil, fmt.Errorf("sync.remote.path is required for the filesystem backend")
+	}
+	store, err := blobstore.New(cfg.StoreConfig())
+	if err != nil {
+		return nil, fmt.Errorf("sync remote: %w", err)
+	}
+	lister, ok := store.(Store)
+	if !ok {
+		return nil, fmt.Errorf("sync remote backend %q cannot list bundles", cfg.Backend)
+	}
+	return NewRemote(cfg.Name(), lister, cfg.KeyExpanded())
+}
+
+// NewRemote creates a remote on store, identified by name. Bundles are
+// encrypted with passphrase.
+func NewRemote(name string, store Store, passphrase string) (*Remote, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("no sync key set (set sync.remote.key or ROBOREV_SYNC_KEY)")
+	}
+	return &Remote{name: name, store: store, passphrase: passphrase, now: time.Now}, nil
+}
+
+// Name identifies the remote
+func (r *Remote) Name() string {
+	return r.name
+}
+
+// PushResult describes a pushed bundle. Key is empty when there was
+// nothing new to push.
+type PushResult struct {
+	Key       string `json:"key,omitempty"`
+	Jobs      int    `json:"jobs"`
+	Reviews   int    `json:"reviews"`
+	Responses int    `json:"responses"`
+}
+
+// PullResult sums up the bundles merged by a pull
+type PullResult struct {
+	Bundles int                    `json:"bundles"`
+	Stats   storage.PeerMergeStats `json:"stats"`
+}
+
+func (r *Remote) pushCursorSource() string {
+	return "push:" + r.name
+}
+
+func (r *Remote) pullStateKey(machineID string) string {
+	return "remote_pull:" + r.name + ":" + machineID
+}
+
+// Push uploads the jobs, reviews and comments that changed since the last
+// push to the remote, or all of them if full is set
+func (r *Remote) Push(ctx context.Context, db *storage.DB, full bool) (*PushResult, error) {
+	machineID, err := db.GetMachineID()
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if !full {
+		if since, err = db.GetPeerCursor(ctx, r.pushCursorSource()); err != nil {
+			return nil, err
+		}
+	}
+	bundle, err := db.ExportPeerBundle(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	result := &PushResult{Jobs: len(bundle.Jobs), Reviews: len(bundle.Reviews), Responses: len(bundle.Responses)}
+	// The export includes rows changed at the cursor itself, which the
+	// previous push already uploaded
+	if result.Jobs+result.Reviews+result.Responses == 0 || (!since.IsZero() && !bundle.Cursor.After(since)) {
+		return &PushResult{}, nil
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s%s/%020d.bundle", bundlePrefix, machineID, r.now().UnixNano())
+	sealed, err := seal(r.passphrase, key, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.Put(ctx, key, sealed); err != nil {
+		return nil, fmt.Errorf("upload bundle: %w", err)
+	}
+	if err := db.SetPeerCursor(ctx, r.pushCursorSource(), bundle.Cursor); err != nil {
+		return nil, err
+	}
+	result.Key = key
+	return result, nil
+}
+
+// Pull merges the bundles other machines pushed since the last pull. Each
+// machine's bundles are merged in the order they were pushed, and a
+// machine is resumed after the last bundle merged from it.
+func (r *Remote) Pull(ctx context.Context, db *storage.DB) (*PullResult, error) {
+	machineID, err := db.GetMachineID()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := r.store.List(ctx, bundlePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list bundles: %w", err)
+	}
+
+	result := &PullResult{}
+	applied := make(map[string]string)
+	for _, key := range keys {
+		peer, name, ok := strings.Cut(strings.TrimPrefix(key, bundlePrefix), "/")
+		if !ok || peer == machineID || !strings.HasSuffix(name, ".bundle") {
+			continue
+		}
+		last, seen := applied[peer]
+		if !seen {
+			if last, err = db.GetSyncState(ctx, r.pullStateKey(peer)); err != nil {
+				return nil, err
+			}
+			applied[peer] = last
+		}
+		if key <= last {
+			continue
+		}
+
+		sealed, err := r.store.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("download %s: %w", key, err)
+		}
+		data, err := open(r.passphrase, key, sealed)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		var bundle storage.PeerBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", key, err)
+		}
+		if bundle.MachineID != peer {
+			return nil, fmt.Errorf("%s was pushed by machine %s, not %s", key, bundle.MachineID, peer)
+		}
+		stats, err := db.ImportPeerBundle(ctx, &bundle)
+		if err != nil {
+			return nil, fmt.Errorf("merge %s: %w", key, err)
+		}
+		if err := db.SetSyncState(ctx, r.pullStateKey(peer), key); err != nil {
+			return nil, err
+		}
+		applied[peer] = key
+		result.Bundles++
+		addStats(&result.Stats, stats)
+	}
+	return result, nil
+}
+
+func addStats(sum *storage.PeerMergeStats, s storage.PeerMergeStats) {
+	sum.JobsInserted += s.JobsInserted
+	sum.JobsUpdated += s.JobsUpdated
+	sum.ReviewsInserted += s.ReviewsInserted
+	sum.ReviewsUpdated += s.ReviewsUpdated
+	sum.ResponsesInserted += s.ResponsesInserted
+	sum.Skipped += s.Skipped
+}
+
+// seal compresses and encrypts data with AES-256-GCM under a key derived
+// from passphrase. The bundle's remote key is authenticated along with it,
+// so a bundle can't be passed off as another machine's.
+func seal(passphrase, key string, data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(magic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, compressed.Bytes(), []byte(key)), nil
+}
+
+// open reverses seal
+func open(passphrase, key string, sealed []byte) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, []byte(magic)) {
+		return nil, fmt.Errorf("not a roborev sync bundle")
+	}
+	sealed = sealed[len(magic):]
+	if len(sealed) < saltSize {
+		return nil, ErrDecrypt
+	}
+	gcm, err := newGCM(passphrase, sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[saltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	compressed, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(key))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	k, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package teamsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/blobstore"
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestSealOpen(t *testing.T) {
+	sealed, err := seal("team secret", "bundles/m1/1.bundle", []byte(`{"jobs":[]}`))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("jobs")) {
+		t.Error("sealed bundle should not contain the plaintext")
+	}
+	data, err := open("team secret", "bundles/m1/1.bundle", sealed)
+	if err != nil || string(data) != `{"jobs":[]}` {
+		t.Fatalf("open = %q, %v", data, err)
+	}
+	if _, err := open("wrong secret", "bundles/m1/1.bundle", sealed); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt with the wrong passphrase, got %v", err)
+	}
+	// A bundle copied under another machine's key doesn't authenticate
+	if _, err := open("team secret", "bundles/m2/1.bundle", sealed); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt under another key, got %v", err)
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	ctx := context.Background()
+	store := blobstore.NewFileStore(t.TempDir())
+	remote, err := NewRemote("test", store, "team secret")
+	if err != nil {
+		t.Fatalf("NewRemote: %v", err)
+	}
+	if _, err := NewRemote("test", store, ""); err == nil {
+		t.Error("expected an error without a passphrase")
+	}
+
+	alice := testutil.OpenTestDB(t)
+	bob := testutil.OpenTestDB(t)
+	repo := testutil.CreateTestRepo(t, alice)
+	testutil.CreateCompletedReview(t, alice, repo.ID, "abc123", "codex", "No issues found.")
+
+	pushed, err := remote.Push(ctx, alice, false)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if pushed.Key == "" || pushed.Jobs != 1 || pushed.Reviews != 1 {
+		t.Fatalf("unexpected push %+v", pushed)
+	}
+	aliceID, _ := alice.GetMachineID()
+	if !strings.HasPrefix(pushed.Key, bundlePrefix+aliceID+"/") {
+		t.Errorf("expected the bundle under alice's machine ID, got %s", pushed.Key)
+	}
+	if again, err := remote.Push(ctx, alice, false); err != nil || again.Key != "" {
+		t.Errorf("expected nothing new to push, got %+v, %v", again, err)
+	}
+
+	// Alice skips her own bundles
+	if pulled, err := remote.Pull(ctx, alice); err != nil || pulled.Bundles != 0 {
+		t.Errorf("expected alice to pull nothing, got %+v, %v", pulled, err)
+	}
+
+	pulled, err := remote.Pull(ctx, bob)
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if pulled.Bundles != 1 || pulled.Stats.JobsInserted != 1 || pulled.Stats.ReviewsInserted != 1 {
+		t.Errorf("unexpected pull %+v", pulled)
+	}
+	review, err := bob.GetReviewByCommitSHA(ctx, "abc123")
+	if err != nil || review.Output != "No issues found." {
+		t.Fatalf("expected alice's review on bob's side, got %+v, %v", review, err)
+	}
+	if again, err := remote.Pull(ctx, bob); err != nil || again.Bundles != 0 {
+		t.Errorf("expected the bundle to be pulled once, got %+v, %v", again, err)
+	}
+
+	// A teammate with another passphrase can't read the bundles
+	stranger, _ := NewRemote("test", store, "other secret")
+	if _, err := stranger.Pull(ctx, testutil.OpenTestDB(t)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Package telemetry implements opt-in, anonymized usage telemetry.
+//
+// Events only carry coarse counters: the agent name, job type, duration,
+// and a fixed error class. They never include code, prompts, review output,
+// repo names or paths, commit SHAs, or raw error messages. Events are queued
+// in a local JSONL file so users can inspect exactly what would be sent with
+// 'roborev telemetry show' before anything leaves the machine.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/config"
+)
+
+// maxQueuedEvents caps the local queue so an unreachable endpoint (or no
+// endpoint at all) can't grow the file without bound. Oldest events are
+// dropped first.
+const maxQueuedEvents = 10000
+
+// Event kinds
+const (
+	KindJobCompleted = "job.completed"
+	KindJobFailed    = "job.failed"
+)
+
+// Event is a single anonymized usage record.
+type Event struct {
+	Kind       string `json:"kind"`
+	Day        string `json:"day"` // YYYY-MM-DD; no finer timestamps are recorded
+	Agent      string `json:"agent,omitempty"`
+	JobType    string `json:"job_type,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+// DefaultQueuePath returns the default location of the local event queue.
+func DefaultQueuePath() string {
+	return filepath.Join(config.DataDir(), "telemetry.jsonl")
+}
+
+// Queue is an append-only local event queue backed by a JSONL file.
+type Queue struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewQueue returns a queue stored at path. The file is created lazily.
+func NewQueue(path string) *Queue {
+	return &Queue{path: path}
+}
+
+// Record appends an event to the queue.
+func (q *Queue) Record(e Event) error {
+	if e.Day == "" {
+		e.Day = time.Now().UTC().Format("2006-01-02")
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return q.trimLocked()
+}
+
+// Pending returns all queued events, oldest first.
+func (q *Queue) Pending() ([]Event, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.readLocked()
+}
+
+// Clear removes all queued events.
+func (q *Queue) Clear() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := os.Remove(q.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Flush posts all queued events to endpoint as a JSON array and clears the
+// queue on success. Returns the number of events sent.
+func (q *Queue) Flush(ctx context.Context, endpoint string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events, err := q.readLocked()
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	body, err := json.Marshal(map[string]any{"events": events})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return 0, fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+
+	if err := os.Remove(q.path); err != nil && !os.IsNotExist(err) {
+		return len(events), err
+	}
+	return len(events), nil
+}
+
+func (q *Queue) readLocked() ([]Event, error) {
+	f, err := os.Open(q.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// trimLocked rewrites the queue keeping only the newest maxQueuedEvents.
+func (q *Queue) trimLocked() error {
+	events, err := q.readLocked()
+	if err != nil || len(events) <= maxQueuedEvents {
+		return err
+	}
+	events = events[len(events)-maxQueuedEvents:]
+
+	var buf bytes.Buffer
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+// AgentSummary aggregates queued events for one agent.
+type AgentSummary struct {
+	Agent         string
+	Completed     int
+	Failed        int
+	AvgDurationMs int64
+	ErrorClasses  map[string]int
+}
+
+// Summarize aggregates events per agent, sorted by agent name.
+func Summarize(events []Event) []AgentSummary {
+	byAgent := make(map[string]*AgentSummary)
+	durations := make(map[string]int64)
+	for _, e := range events {
+		name := e.Agent
+		if name == "" {
+			name = "unknown"
+		}
+		s := byAgent[name]
+		if s == nil {
+			s = &AgentSummary{Agent: name, ErrorClasses: make(map[string]int)}
+			byAgent[name] = s
+		}
+		switch e.Kind {
+		case KindJobCompleted:
+			s.Completed++
+			durations[name] += e.DurationMs
+		case KindJobFailed:
+			s.Failed++
+			s.ErrorClasses[e.ErrorClass]++
+		}
+	}
+
+	result := make([]AgentSummary, 0, len(byAgent))
+	for name, s := range byAgent {
+		if s.Completed > 0 {
+			s.AvgDurationMs = durations[name] / int64(s.Completed)
+		}
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Agent < result[j].Agent })
+	return result
+}
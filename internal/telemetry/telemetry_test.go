@@ -0,0 +1,109 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueueRecordAndFlush(t *testing.T) {
+	q := NewQueue(filepath.Join(t.TempDir(), "telemetry.jsonl"))
+
+	if err := q.Record(Event{Kind: KindJobCompleted, Agent: "codex", DurationMs: 1200}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := q.Record(Event{Kind: KindJobFailed, Agent: "codex", ErrorClass: "timeout"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	events, err := q.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 pending events, got %d", len(events))
+	}
+	if events[0].Day == "" {
+		t.Error("expected Day to be filled in")
+	}
+
+	var received []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []Event `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		received = body.Events
+	}))
+	defer srv.Close()
+
+	n, err := q.Flush(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n != 2 || len(received) != 2 {
+		t.Errorf("expected 2 events sent, got n=%d received=%d", n, len(received))
+	}
+	if events, _ := q.Pending(); len(events) != 0 {
+		t.Errorf("expected queue to be empty after flush, got %d", len(events))
+	}
+}
+
+func TestQueueFlushKeepsEventsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	q := NewQueue(path)
+	if err := q.Record(Event{Kind: KindJobCompleted, Agent: "codex"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if _, err := q.Flush(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected error from failing endpoint")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected queue file to be kept: %v", err)
+	}
+}
+
+func TestQueueClear(t *testing.T) {
+	q := NewQueue(filepath.Join(t.TempDir(), "telemetry.jsonl"))
+	if err := q.Clear(); err != nil {
+		t.Fatalf("Clear on missing file: %v", err)
+	}
+	q.Record(Event{Kind: KindJobCompleted})
+	if err := q.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if events, _ := q.Pending(); len(events) != 0 {
+		t.Errorf("expected no events after clear, got %d", len(events))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summaries := Summarize([]Event{
+		{Kind: KindJobCompleted, Agent: "codex", DurationMs: 1000},
+		{Kind: KindJobCompleted, Agent: "codex", DurationMs: 3000},
+		{Kind: KindJobFailed, Agent: "codex", ErrorClass: "timeout"},
+		{Kind: KindJobFailed, Agent: "claude-code", ErrorClass: "auth"},
+	})
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(summaries))
+	}
+	if summaries[0].Agent != "claude-code" || summaries[0].Failed != 1 {
+		t.Errorf("unexpected claude-code summary: %+v", summaries[0])
+	}
+	codex := summaries[1]
+	if codex.Completed != 2 || codex.Failed != 1 || codex.AvgDurationMs != 2000 || codex.ErrorClasses["timeout"] != 1 {
+		t.Errorf("unexpected codex summary: %+v", codex)
+	}
+}
@@ -130,12 +130,21 @@ func MockBinaryInPath(t *testing.T, binName, scriptContent string) func() {
 	}
 }
 
-// OpenTestDB creates a test database in a temporary directory.
+// OpenTestDB creates a test database held in memory, so tests using it can
+// run in parallel without touching the filesystem.
 // The database is automatically closed when the test completes.
 func OpenTestDB(t *testing.T) *storage.DB {
 	t.Helper()
 
-	db, _ := OpenTestDBWithDir(t)
+	db, err := storage.OpenMemory()
+	if err != nil {
+		t.Fatalf("Failed to open test DB: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
 	return db
 }
 
@@ -177,7 +186,7 @@ func CreateTestRepo(t *testing.T, db *storage.DB) *storage.Repo {
 	t.Helper()
 
 	tmpDir := t.TempDir()
-	repo, err := db.GetOrCreateRepo(tmpDir)
+	repo, err := db.GetOrCreateRepo(t.Context(), tmpDir)
 	if err != nil {
 		t.Fatalf("GetOrCreateRepo failed: %v", err)
 	}
@@ -195,12 +204,12 @@ func CreateTestJobs(t *testing.T, db *storage.DB, repo *storage.Repo, count int,
 
 	for i := 0; i < count; i++ {
 		sha := fmt.Sprintf("sha%d", i)
-		commit, err := db.GetOrCreateCommit(repo.ID, sha, "Test Author", fmt.Sprintf("Test commit %d", i), time.Now())
+		commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Test Author", fmt.Sprintf("Test commit %d", i), time.Now())
 		if err != nil {
 			t.Fatalf("GetOrCreateCommit failed: %v", err)
 		}
 
-		job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: agent})
+		job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: agent})
 		if err != nil {
 			t.Fatalf("EnqueueJob failed: %v", err)
 		}
@@ -215,12 +224,12 @@ func CreateTestJobs(t *testing.T, db *storage.DB, repo *storage.Repo, count int,
 func CreateTestJobWithSHA(t *testing.T, db *storage.DB, repo *storage.Repo, sha, agent string) *storage.ReviewJob {
 	t.Helper()
 
-	commit, err := db.GetOrCreateCommit(repo.ID, sha, "Test Author", "Test commit", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repo.ID, sha, "Test Author", "Test commit", time.Now())
 	if err != nil {
 		t.Fatalf("GetOrCreateCommit failed: %v", err)
 	}
 
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: agent})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repo.ID, CommitID: commit.ID, GitRef: sha, Agent: agent})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
@@ -308,7 +317,7 @@ func WaitForJobStatus(t *testing.T, db *storage.DB, jobID int64, timeout time.Du
 	t.Helper()
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
-		job, err := db.GetJobByID(jobID)
+		job, err := db.GetJobByID(t.Context(), jobID)
 		if err != nil {
 			t.Fatalf("GetJobByID failed: %v", err)
 		}
@@ -331,19 +340,19 @@ func WaitForJobStatus(t *testing.T, db *storage.DB, jobID int64, timeout time.Du
 func CreateCompletedReview(t *testing.T, db *storage.DB, repoID int64, sha, agent, reviewText string) *storage.ReviewJob {
 	t.Helper()
 
-	commit, err := db.GetOrCreateCommit(repoID, sha, "Test", "test commit", time.Now())
+	commit, err := db.GetOrCreateCommit(t.Context(), repoID, sha, "Test", "test commit", time.Now())
 	if err != nil {
 		t.Fatalf("Failed to create commit: %v", err)
 	}
 
-	job, err := db.EnqueueJob(storage.EnqueueOpts{RepoID: repoID, CommitID: commit.ID, GitRef: sha, Agent: agent})
+	job, err := db.EnqueueJob(t.Context(), storage.EnqueueOpts{RepoID: repoID, CommitID: commit.ID, GitRef: sha, Agent: agent})
 	if err != nil {
 		t.Fatalf("EnqueueJob failed: %v", err)
 	}
-	if _, err := db.ClaimJob("test-worker"); err != nil {
+	if _, err := db.ClaimJob(t.Context(), "test-worker"); err != nil {
 		t.Fatalf("ClaimJob failed: %v", err)
 	}
-	if err := db.CompleteJob(job.ID, "test-worker", "prompt", reviewText); err != nil {
+	if err := db.CompleteJob(t.Context(), job.ID, "test-worker", "prompt", reviewText); err != nil {
 		t.Fatalf("CompleteJob failed: %v", err)
 	}
 
@@ -363,7 +372,7 @@ func CreateReviewWithComments(t *testing.T, db *storage.DB, repoID int64, sha, r
 
 	job := CreateCompletedReview(t, db, repoID, sha, "test", reviewText)
 	for _, c := range comments {
-		if _, err := db.AddCommentToJob(job.ID, c.User, c.Text); err != nil {
+		if _, err := db.AddCommentToJob(t.Context(), job.ID, c.User, c.Text); err != nil {
 			t.Fatalf("AddCommentToJob failed: %v", err)
 		}
 	}
@@ -3,7 +3,9 @@ package update
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -27,6 +29,16 @@ const (
 	devCacheDuration = 15 * time.Minute // Shorter cache for dev builds
 )
 
+// releasePublicKey is the base64 ed25519 key that release checksum files are
+// signed with. It is set at build time:
+//
+//	-ldflags "-X github.com/roborev-dev/roborev/internal/update.releasePublicKey=<key>"
+//
+// When set, updates require a valid <checksums>.sig asset and take the
+// binary's checksum only from the signed file. Release builds without it
+// refuse to update themselves, as they could not tell a tampered release.
+var releasePublicKey string
+
 // Release represents a GitHub release
 type Release struct {
 	TagName string  `json:"tag_name"`
@@ -49,6 +61,7 @@ type UpdateInfo struct {
 	AssetName      string
 	Size           int64
 	Checksum       string // SHA256 if available
+	Signed         bool   // Checksum came from a signature-verified checksums file
 	IsDevBuild     bool   // True if running a dev build (hash version)
 }
 
@@ -130,14 +143,25 @@ func CheckForUpdate(forceCheck bool) (*UpdateInfo, error) {
 		return nil, fmt.Errorf("no release asset found for %s/%s", runtime.GOOS, runtime.GOARCH)
 	}
 
-	// Get checksum - first try checksums file, then release body
+	// Get checksum. Signed builds only trust the signed checksums file;
+	// otherwise try the checksums file, then the release body.
 	var checksum string
-	if checksumsAsset != nil {
-		checksum, _ = fetchChecksumFromFile(checksumsAsset.BrowserDownloadURL, assetName)
-	}
-	if checksum == "" {
-		// Fall back to release body
-		checksum = extractChecksum(release.Body, assetName)
+	signed := releasePublicKey != ""
+	if signed {
+		checksum, err = fetchSignedChecksum(release.Assets, checksumsAsset, assetName, releasePublicKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if checksumsAsset != nil {
+			if body, err := fetchText(checksumsAsset.BrowserDownloadURL); err == nil {
+				checksum = extractChecksum(body, assetName)
+			}
+		}
+		if checksum == "" {
+			// Fall back to release body
+			checksum = extractChecksum(release.Body, assetName)
+		}
 	}
 
 	return &UpdateInfo{
@@ -147,12 +171,18 @@ func CheckForUpdate(forceCheck bool) (*UpdateInfo, error) {
 		AssetName:      asset.Name,
 		Size:           asset.Size,
 		Checksum:       checksum,
+		Signed:         signed,
 		IsDevBuild:     isDevBuild,
 	}, nil
 }
 
 // PerformUpdate downloads and installs the update
 func PerformUpdate(info *UpdateInfo, progressFn func(downloaded, total int64)) error {
+	// Security: a release build must verify the release's signature
+	if !info.Signed && !info.IsDevBuild {
+		return fmt.Errorf("this build of roborev has no release signing key, so %s cannot be verified - refusing to install; reinstall roborev with the install script or your package manager", info.AssetName)
+	}
+
 	// Security: require checksum verification
 	if info.Checksum == "" {
 		return fmt.Errorf("no checksum available for %s - refusing to install unverified binary", info.AssetName)
@@ -207,7 +237,6 @@ func PerformUpdate(info *UpdateInfo, progressFn func(downloaded, total int64)) e
 	for _, binary := range binaries {
 		srcPath := filepath.Join(extractDir, binary)
 		dstPath := filepath.Join(binDir, binary)
-		backupPath := dstPath + ".old"
 
 		// Check if source exists
 		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
@@ -215,42 +244,54 @@ func PerformUpdate(info *UpdateInfo, progressFn func(downloaded, total int64)) e
 		}
 
 		fmt.Printf("Installing %s... ", binary)
-
-		// Clean up any old backup from previous update
-		os.Remove(backupPath)
-
-		// Backup existing
-		if _, err := os.Stat(dstPath); err == nil {
-			if err := os.Rename(dstPath, backupPath); err != nil {
-				// On Windows, renaming a running executable may fail
-				if runtime.GOOS == "windows" {
-					return fmt.Errorf("cannot update %s while it is running - please stop the daemon and try again: %w", binary, err)
-				}
-				return fmt.Errorf("backup %s: %w", binary, err)
-			}
-		}
-
-		// Copy new binary
-		if err := copyFile(srcPath, dstPath); err != nil {
-			// Try to restore backup
-			os.Rename(backupPath, dstPath)
+		if err := installBinary(srcPath, dstPath); err != nil {
+			fmt.Println("FAILED")
 			return fmt.Errorf("install %s: %w", binary, err)
 		}
+		fmt.Println("OK")
+	}
 
-		// Set executable permission (no-op on Windows)
-		if runtime.GOOS != "windows" {
-			if err := os.Chmod(dstPath, 0755); err != nil {
-				return fmt.Errorf("chmod %s: %w", binary, err)
-			}
-		}
+	return nil
+}
 
-		// Try to remove backup (may fail on Windows if daemon was running)
-		// The .old file will be cleaned up on next update
-		os.Remove(backupPath)
+// installBinary swaps src in for dst. The new binary is staged next to dst
+// and renamed over it, so dst is always either the old or the new binary,
+// never a partial copy. Windows can't replace a running executable, but can
+// rename it, so there the old binary is moved aside to dst.old first; it is
+// removed on the next update.
+func installBinary(src, dst string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".new-*")
+	if err != nil {
+		return fmt.Errorf("stage binary: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath) // No-op once renamed
 
-		fmt.Println("OK")
+	if err := copyFile(src, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		return os.Rename(tmpPath, dst)
 	}
 
+	backupPath := dst + ".old"
+	os.Remove(backupPath)
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Rename(dst, backupPath); err != nil {
+			return fmt.Errorf("move running binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Rename(backupPath, dst)
+		return err
+	}
+	// Fails while the old binary is still running
+	os.Remove(backupPath)
 	return nil
 }
 
@@ -473,8 +514,8 @@ func copyFile(src, dst string) error {
 	return out.Close()
 }
 
-// fetchChecksumFromFile downloads a checksums file and extracts the checksum for assetName
-func fetchChecksumFromFile(url, assetName string) (string, error) {
+// fetchText downloads a small text asset such as a checksums file
+func fetchText(url string) (string, error) {
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Get(url)
 	if err != nil {
@@ -483,15 +524,66 @@ func fetchChecksumFromFile(url, assetName string) (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch checksums: %s", resp.Status)
+		return "", fmt.Errorf("fetch %s: %s", url, resp.Status)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
 	}
+	return string(body), nil
+}
 
-	return extractChecksum(string(body), assetName), nil
+// fetchSignedChecksum downloads the checksums file and its .sig, verifies
+// the signature and returns the checksum for assetName
+func fetchSignedChecksum(assets []Asset, checksumsAsset *Asset, assetName, publicKey string) (string, error) {
+	if checksumsAsset == nil {
+		return "", fmt.Errorf("release has no checksums file - refusing to install unsigned binary")
+	}
+	var sigAsset *Asset
+	for i := range assets {
+		if assets[i].Name == checksumsAsset.Name+".sig" {
+			sigAsset = &assets[i]
+		}
+	}
+	if sigAsset == nil {
+		return "", fmt.Errorf("release has no %s.sig - refusing to install unsigned binary", checksumsAsset.Name)
+	}
+
+	checksums, err := fetchText(checksumsAsset.BrowserDownloadURL)
+	if err != nil {
+		return "", err
+	}
+	sig, err := fetchText(sigAsset.BrowserDownloadURL)
+	if err != nil {
+		return "", err
+	}
+	if err := verifySignature(publicKey, []byte(checksums), sig); err != nil {
+		return "", fmt.Errorf("verify %s: %w", checksumsAsset.Name, err)
+	}
+
+	checksum := extractChecksum(checksums, assetName)
+	if checksum == "" {
+		return "", fmt.Errorf("no checksum for %s in %s", assetName, checksumsAsset.Name)
+	}
+	return checksum, nil
+}
+
+// verifySignature checks a base64 ed25519 signature of data against a
+// base64 public key
+func verifySignature(publicKey string, data []byte, sig string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release public key")
+	}
+	rawSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sig))
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, rawSig) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
 }
 
 func extractChecksum(releaseBody, assetName string) string {
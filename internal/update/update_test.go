@@ -3,9 +3,14 @@ package update
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -455,3 +460,158 @@ func TestFindAssetsNoChecksums(t *testing.T) {
 		t.Errorf("expected checksums to be nil, got %+v", checksums)
 	}
 }
+
+func TestInstallBinary(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "new")
+	dst := filepath.Join(dir, "roborev")
+	if err := os.WriteFile(src, []byte("new binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installBinary(src, dst); err != nil {
+		t.Fatalf("installBinary: %v", err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new binary" {
+		t.Errorf("dst = %q, want new binary", got)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0100 == 0 {
+			t.Errorf("dst mode = %v, want executable", info.Mode())
+		}
+	}
+
+	// Nothing staged is left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("dir contains %v, want only new and roborev", names)
+	}
+}
+
+func TestInstallBinaryMissingSourceKeepsOld(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "roborev")
+	if err := os.WriteFile(dst, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installBinary(filepath.Join(dir, "missing"), dst); err == nil {
+		t.Fatal("expected error for missing source")
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old binary" {
+		t.Errorf("dst = %q, want old binary untouched", got)
+	}
+}
+
+func TestFetchSignedChecksum(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	sums := "abc123abc123abc123abc123abc123abc123abc123abc123abc123abc123abcd  roborev_1.0.0_linux_amd64.tar.gz\n"
+	goodSig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(sums)))
+	badSig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("something else")))
+
+	files := map[string]string{
+		"/SHA256SUMS":         sums,
+		"/SHA256SUMS.sig":     goodSig,
+		"/bad/SHA256SUMS.sig": badSig,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	sumsAsset := Asset{Name: "SHA256SUMS", BrowserDownloadURL: srv.URL + "/SHA256SUMS"}
+	tests := []struct {
+		name    string
+		assets  []Asset
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "valid signature",
+			assets: []Asset{sumsAsset, {Name: "SHA256SUMS.sig", BrowserDownloadURL: srv.URL + "/SHA256SUMS.sig"}},
+			key:    key,
+			want:   "abc123abc123abc123abc123abc123abc123abc123abc123abc123abc123abcd",
+		},
+		{
+			name:    "signature over other data",
+			assets:  []Asset{sumsAsset, {Name: "SHA256SUMS.sig", BrowserDownloadURL: srv.URL + "/bad/SHA256SUMS.sig"}},
+			key:     key,
+			wantErr: true,
+		},
+		{
+			name:    "no signature asset",
+			assets:  []Asset{sumsAsset},
+			key:     key,
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			assets:  []Asset{sumsAsset, {Name: "SHA256SUMS.sig", BrowserDownloadURL: srv.URL + "/SHA256SUMS.sig"}},
+			key:     "not-a-key",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchSignedChecksum(tt.assets, &tt.assets[0], "roborev_1.0.0_linux_amd64.tar.gz", tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchSignedChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fetchSignedChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := fetchSignedChecksum(nil, nil, "roborev_1.0.0_linux_amd64.tar.gz", key); err == nil {
+		t.Error("expected error without a checksums file")
+	}
+}
+
+func TestPerformUpdateRefusesUnsignedReleaseBuild(t *testing.T) {
+	info := &UpdateInfo{
+		CurrentVersion: "v1.0.0",
+		LatestVersion:  "v1.1.0",
+		DownloadURL:    "http://127.0.0.1:0/roborev_1.1.0_linux_amd64.tar.gz",
+		AssetName:      "roborev_1.1.0_linux_amd64.tar.gz",
+		Checksum:       strings.Repeat("a", 64),
+	}
+	err := PerformUpdate(info, nil)
+	if err == nil || !strings.Contains(err.Error(), "no release signing key") {
+		t.Errorf("expected an unsigned release build to refuse, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package vcs
+
+import "github.com/roborev-dev/roborev/internal/git"
+
+// Git is the VCS of git repositories, backed by package git
+type Git struct{}
+
+func (Git) Name() string { return "git" }
+
+func (Git) RepoRoot(path string) (string, error) { return git.GetRepoRoot(path) }
+
+func (Git) ResolveRef(repoPath, rev string) (string, error) { return git.ResolveSHA(repoPath, rev) }
+
+func (Git) CurrentBranch(repoPath string) string { return git.GetCurrentBranch(repoPath) }
+
+func (Git) CommitInfo(repoPath, rev string) (*CommitInfo, error) {
+	return git.GetCommitInfo(repoPath, rev)
+}
+
+func (Git) Diff(repoPath, ref string) (string, error) {
+	if git.IsRange(ref) {
+		return git.GetRangeDiff(repoPath, ref)
+	}
+	return git.GetDiff(repoPath, ref)
+}
+
+func (Git) NewCommits(repoPath, since, until string) ([]string, error) {
+	return git.GetRangeCommits(repoPath, since+".."+until)
+}
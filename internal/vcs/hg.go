@@ -0,0 +1,127 @@
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// Hg is the VCS of Mercurial repositories, run through the hg command.
+// Git-style refs work too: HEAD names the working copy's parent, and a
+// trailing ^ or ~n picks an ancestor as in git.
+type Hg struct{}
+
+func (Hg) Name() string { return "hg" }
+
+func (Hg) RepoRoot(path string) (string, error) {
+	out, err := hg(path, "root")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (Hg) ResolveRef(repoPath, rev string) (string, error) {
+	nodes, err := hgNodes(repoPath, hgRev(rev))
+	if err != nil {
+		return "", err
+	}
+	if len(nodes) != 1 {
+		return "", fmt.Errorf("hg revision %q resolves to %d commits", rev, len(nodes))
+	}
+	return nodes[0], nil
+}
+
+// CurrentBranch returns the active bookmark, which hg users branch with
+// like git branches, or else the named branch of the working copy
+func (Hg) CurrentBranch(repoPath string) string {
+	out, err := hg(repoPath, "log", "-r", ".", "-T", "{if(activebookmark, activebookmark, branch)}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+func (Hg) CommitInfo(repoPath, rev string) (*CommitInfo, error) {
+	// Use record separator (ASCII 30) to delimit fields, as for git
+	const rs = "\x1e"
+	out, err := hg(repoPath, "log", "-r", hgRev(rev), "-l", "1", "-T",
+		"{node}"+rs+"{person(author)}"+rs+"{date|rfc3339date}"+rs+"{email(author)}"+rs+"{desc}")
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(out, rs, 5)
+	if len(parts) < 5 {
+		return nil, fmt.Errorf("unexpected hg log output: %s", out)
+	}
+	ts, err := time.Parse(time.RFC3339, parts[2])
+	if err != nil {
+		ts = time.Now() // Fallback
+	}
+	subject, body, _ := strings.Cut(strings.TrimSpace(parts[4]), "\n")
+	return &CommitInfo{
+		SHA:         parts[0],
+		Author:      parts[1],
+		AuthorEmail: parts[3],
+		Subject:     strings.TrimSpace(subject),
+		Body:        strings.TrimSpace(body),
+		Timestamp:   ts,
+	}, nil
+}
+
+func (Hg) Diff(repoPath, ref string) (string, error) {
+	args := []string{"diff", "--git"}
+	if start, end, ok := git.ParseRange(ref); ok {
+		args = append(args, "-r", hgRev(start), "-r", hgRev(end))
+	} else {
+		args = append(args, "-c", hgRev(ref))
+	}
+	for _, path := range git.ExcludedPaths() {
+		args = append(args, "-X", "path:"+path)
+	}
+	return hg(repoPath, args...)
+}
+
+func (Hg) NewCommits(repoPath, since, until string) ([]string, error) {
+	return hgNodes(repoPath, fmt.Sprintf("sort(only(%s, %s), rev)", hgRev(until), hgRev(since)))
+}
+
+// hgRev translates git's name for the checked-out commit, HEAD, to hg's
+func hgRev(rev string) string {
+	if rest, ok := strings.CutPrefix(rev, "HEAD"); ok && (rest == "" || rest[0] == '^' || rest[0] == '~') {
+		return "." + rest
+	}
+	return rev
+}
+
+// hgNodes returns the full commit IDs of the revisions in revset, in order
+func hgNodes(repoPath, revset string) ([]string, error) {
+	out, err := hg(repoPath, "log", "-r", revset, "-T", "{node}\n")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// hg runs an hg command in repoPath. HGPLAIN keeps user configuration such
+// as aliases and localization from changing its output.
+func hg(repoPath string, args ...string) (string, error) {
+	cmd := exec.Command("hg", args...)
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("hg %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("hg %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,70 @@
+// Package vcs abstracts the version control operations the review queue
+// needs: resolving refs, reading commit metadata and diffs, and listing new
+// commits. Repositories managed by git or by Mercurial then share the same
+// queue, storage and agents. Features that only make sense for git (dirty
+// and branch reviews, hooks, worktrees, fix commits) use package git
+// directly.
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/roborev-dev/roborev/internal/git"
+)
+
+// CommitInfo is a commit's metadata
+type CommitInfo = git.CommitInfo
+
+// VCS is a version control system whose commits roborev can review. Refs
+// are commits, or "start..end" ranges of the commits after start up to
+// end, as in git.
+type VCS interface {
+	// Name is the system's command name, "git" or "hg"
+	Name() string
+	// RepoRoot returns the root of the repository containing path
+	RepoRoot(path string) (string, error)
+	// ResolveRef resolves a revision to its full commit ID
+	ResolveRef(repoPath, rev string) (string, error)
+	// CurrentBranch returns the branch of the working copy, or "" if none
+	CurrentBranch(repoPath string) string
+	// CommitInfo returns the metadata of a commit
+	CommitInfo(repoPath, rev string) (*CommitInfo, error)
+	// Diff returns the diff of a commit or range in git's unified format
+	Diff(repoPath, ref string) (string, error)
+	// NewCommits lists the commits after since up to until, oldest first
+	NewCommits(repoPath, since, until string) ([]string, error)
+}
+
+// Detect returns the VCS of the repository containing path: the nearest
+// directory with a .git entry (including worktrees and colocated jj
+// workspaces) or a .hg directory
+func Detect(path string) (VCS, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return Git{}, nil
+		}
+		if info, err := os.Stat(filepath.Join(dir, ".hg")); err == nil && info.IsDir() {
+			return Hg{}, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("%s is not in a git or Mercurial repository", path)
+		}
+		dir = parent
+	}
+}
+
+// For returns the VCS of the repository containing path, defaulting to git
+// so that callers outside any repository keep reporting git's errors
+func For(path string) VCS {
+	if v, err := Detect(path); err == nil {
+		return v
+	}
+	return Git{}
+}
@@ -0,0 +1,129 @@
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := Detect(dir); err == nil {
+		t.Errorf("expected no VCS, got %s", got.Name())
+	}
+	if got := For(dir).Name(); got != "git" {
+		t.Errorf("For() defaults to %q, want git", got)
+	}
+
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".hg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Detect(sub); err != nil || got.Name() != "hg" {
+		t.Errorf("expected hg below a .hg directory, got %v, %v", got, err)
+	}
+
+	// A .git file, as in worktrees, marks a git repo too
+	if err := os.WriteFile(filepath.Join(dir, "a", ".git"), []byte("gitdir: elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Detect(sub); err != nil || got.Name() != "git" {
+		t.Errorf("expected the nearest repo, git, got %v, %v", got, err)
+	}
+}
+
+func TestGit(t *testing.T) {
+	repo := testutil.NewTestRepoWithCommit(t)
+	var v VCS = Git{}
+
+	sha, err := v.ResolveRef(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRef: %v", err)
+	}
+	info, err := v.CommitInfo(repo.Root, sha)
+	if err != nil {
+		t.Fatalf("CommitInfo: %v", err)
+	}
+	if info.SHA != sha {
+		t.Errorf("CommitInfo SHA = %q, want %q", info.SHA, sha)
+	}
+	if _, err := v.Diff(repo.Root, sha); err != nil {
+		t.Errorf("Diff: %v", err)
+	}
+	commits, err := v.NewCommits(repo.Root, sha, "HEAD")
+	if err != nil || len(commits) != 0 {
+		t.Errorf("NewCommits = %v, %v; want none", commits, err)
+	}
+}
+
+func TestHg(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake hg is a shell script")
+	}
+	// Fake hg prints $HG_OUT and records its arguments and HGPLAIN
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	restore := testutil.MockBinaryInPath(t, "hg", "#!/bin/sh\necho \"$HGPLAIN $@\" > "+argsFile+"\nprintf '%b' \"$HG_OUT\"\n")
+	defer restore()
+	ranWith := func() string {
+		t.Helper()
+		args, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(args))
+	}
+	var v VCS = Hg{}
+
+	t.Setenv("HG_OUT", "0123abcd\\n")
+	node, err := v.ResolveRef(dir, "HEAD~2")
+	if err != nil || node != "0123abcd" {
+		t.Errorf("ResolveRef = %q, %v", node, err)
+	}
+	if got := ranWith(); got != "1 log -r .~2 -T {node}" {
+		t.Errorf("ran hg %s", got)
+	}
+
+	t.Setenv("HG_OUT", "aaaa\\nbbbb\\n")
+	if _, err := v.ResolveRef(dir, "draft()"); err == nil {
+		t.Error("expected an error for a revset of several commits")
+	}
+	commits, err := v.NewCommits(dir, "aaaa", "HEAD")
+	if err != nil || len(commits) != 2 {
+		t.Errorf("NewCommits = %v, %v", commits, err)
+	}
+	if got := ranWith(); !strings.Contains(got, "sort(only(., aaaa), rev)") {
+		t.Errorf("ran hg %s", got)
+	}
+
+	t.Setenv("HG_OUT", "abcd\\0036Jane Doe\\00362026-01-02T03:04:05+01:00\\0036jane@example.com\\0036Fix the parser\\n\\nIt dropped tokens.")
+	info, err := v.CommitInfo(dir, "abcd")
+	if err != nil {
+		t.Fatalf("CommitInfo: %v", err)
+	}
+	if info.SHA != "abcd" || info.Author != "Jane Doe" || info.AuthorEmail != "jane@example.com" ||
+		info.Subject != "Fix the parser" || info.Body != "It dropped tokens." || info.Timestamp.Hour() != 3 {
+		t.Errorf("unexpected commit info %+v", info)
+	}
+
+	t.Setenv("HG_OUT", "diff --git a/x b/x\\n")
+	if _, err := v.Diff(dir, "aaaa..HEAD"); err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if got := ranWith(); !strings.HasPrefix(got, "1 diff --git -r aaaa -r . -X path:") {
+		t.Errorf("ran hg %s", got)
+	}
+	if _, err := v.Diff(dir, "aaaa"); err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if got := ranWith(); !strings.HasPrefix(got, "1 diff --git -c aaaa") {
+		t.Errorf("ran hg %s", got)
+	}
+}
@@ -0,0 +1,296 @@
+// Package worktree keeps git worktrees between agent runs. Creating a
+// worktree checks out every file of the repo, which is slow for huge repos;
+// a pooled worktree is only reset to the next commit, touching the files
+// that differ.
+//
+// Worktrees live under the pool directory in a subdirectory per repo, in
+// numbered slots. A slot is in use while its lock file exists, so several
+// processes can share a pool.
+package worktree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockStaleAfter is the age after which a slot's lock is taken to be left
+// by a process that died, well past the longest agent run
+const lockStaleAfter = 3 * time.Hour
+
+// repoFile records the repo a pool subdirectory holds worktrees of
+const repoFile = "repo"
+
+// Pool hands out worktrees of repos, reusing those kept in Dir
+type Pool struct {
+	Dir     string // Where the worktrees are kept
+	PerRepo int    // Most worktrees kept per repo
+	Total   int    // Most idle worktrees kept across repos
+
+	// Setup, when set, prepares a worktree after it is created or reset,
+	// such as checking out submodules
+	Setup func(dir string) error
+}
+
+// Worktree is a detached checkout handed out by a Pool
+type Worktree struct {
+	Path string
+
+	pool     *Pool
+	repoPath string
+	lockPath string // Empty for a worktree the pool does not keep
+}
+
+// Acquire returns a worktree of the repo at repoPath checked out to ref,
+// with no other changes. It reuses an idle worktree of the repo when there
+// is one and creates one otherwise; when every slot of the repo is in use,
+// the worktree is temporary. Release it when done.
+func (p *Pool) Acquire(repoPath, ref string) (*Worktree, error) {
+	sha, err := gitOutput(repoPath, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", ref, err)
+	}
+
+	repoDir := filepath.Join(p.Dir, repoKey(repoPath))
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, repoFile), []byte(repoPath), 0644); err != nil {
+		return nil, err
+	}
+
+	for i := range p.PerRepo {
+		slot := filepath.Join(repoDir, strconv.Itoa(i))
+		lock := slot + ".lock"
+		if !tryLock(lock) {
+			continue
+		}
+		wt := &Worktree{Path: slot, pool: p, repoPath: repoPath, lockPath: lock}
+		if _, err := os.Stat(filepath.Join(slot, ".git")); err == nil {
+			if p.reset(slot, sha) == nil {
+				return wt, nil
+			}
+			// A checkout that cannot be reset is replaced
+			removeWorktree(repoPath, slot)
+		}
+		if err := p.create(repoPath, slot, sha); err != nil {
+			os.Remove(lock)
+			return nil, err
+		}
+		return wt, nil
+	}
+
+	dir, err := os.MkdirTemp("", "roborev-worktree-")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.create(repoPath, dir, sha); err != nil {
+		return nil, err
+	}
+	return &Worktree{Path: dir, pool: p, repoPath: repoPath}, nil
+}
+
+// Release hands the worktree back to the pool, which resets it on its next
+// use, and removes the idle worktrees beyond the pool's limits. Temporary
+// worktrees are removed.
+func (w *Worktree) Release() {
+	if w.lockPath == "" {
+		removeWorktree(w.repoPath, w.Path)
+		return
+	}
+	touch(usedPath(w.Path))
+	os.Remove(w.lockPath)
+	w.pool.evict()
+}
+
+// create adds a worktree of the repo at dir, replacing whatever is there
+func (p *Pool) create(repoPath, dir, sha string) error {
+	os.RemoveAll(dir)
+	// Forget worktrees whose directory is gone, including any left at dir
+	_ = gitRun(repoPath, "worktree", "prune")
+	if err := gitRun(repoPath, "worktree", "add", "--detach", dir, sha); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("git worktree add: %w", err)
+	}
+	if p.Setup != nil {
+		if err := p.Setup(dir); err != nil {
+			removeWorktree(repoPath, dir)
+			return err
+		}
+	}
+	return nil
+}
+
+// reset checks out sha in an existing worktree and drops every change left
+// by its last use, including untracked and ignored files
+func (p *Pool) reset(dir, sha string) error {
+	if err := gitRun(dir, "checkout", "--quiet", "--detach", "--force", sha); err != nil {
+		return err
+	}
+	if err := gitRun(dir, "clean", "-ffdxq"); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); err == nil {
+		_ = gitRun(dir, "submodule", "foreach", "--quiet", "--recursive", "git reset --hard --quiet && git clean -ffdxq")
+	}
+	if p.Setup != nil {
+		return p.Setup(dir)
+	}
+	return nil
+}
+
+// idleSlot is a pooled worktree not in use
+type idleSlot struct {
+	repoPath string
+	path     string
+	used     time.Time
+}
+
+// evict removes the idle worktrees of repos that no longer exist, those in
+// slots beyond PerRepo, and the least recently used beyond Total
+func (p *Pool) evict() {
+	repoDirs, err := os.ReadDir(p.Dir)
+	if err != nil {
+		return
+	}
+	var idle []idleSlot
+	for _, rd := range repoDirs {
+		repoDir := filepath.Join(p.Dir, rd.Name())
+		data, err := os.ReadFile(filepath.Join(repoDir, repoFile))
+		if err != nil {
+			continue
+		}
+		repoPath := string(data)
+		if _, err := os.Stat(repoPath); errors.Is(err, os.ErrNotExist) {
+			// The worktrees went with the repo's metadata
+			os.RemoveAll(repoDir)
+			continue
+		}
+
+		entries, err := os.ReadDir(repoDir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			n, err := strconv.Atoi(e.Name())
+			if err != nil || !e.IsDir() {
+				continue
+			}
+			slot := filepath.Join(repoDir, e.Name())
+			if _, err := os.Stat(slot + ".lock"); err == nil {
+				continue
+			}
+			if n >= p.PerRepo {
+				p.remove(repoPath, slot)
+				continue
+			}
+			var used time.Time
+			if info, err := os.Stat(usedPath(slot)); err == nil {
+				used = info.ModTime()
+			}
+			idle = append(idle, idleSlot{repoPath: repoPath, path: slot, used: used})
+		}
+	}
+
+	if len(idle) <= p.Total {
+		return
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].used.After(idle[j].used) })
+	for _, s := range idle[p.Total:] {
+		p.remove(s.repoPath, s.path)
+	}
+}
+
+// remove deletes an idle pooled worktree, unless another process started
+// using it meanwhile
+func (p *Pool) remove(repoPath, slot string) {
+	lock := slot + ".lock"
+	if !tryLock(lock) {
+		return
+	}
+	removeWorktree(repoPath, slot)
+	os.Remove(usedPath(slot))
+	os.Remove(lock)
+}
+
+// tryLock creates the lock file at path, reporting whether it did. A lock
+// older than lockStaleAfter is taken over.
+func tryLock(path string) bool {
+	if createLock(path) {
+		return true
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) < lockStaleAfter {
+		return false
+	}
+	// Move the stale lock aside first, so of two processes taking it over
+	// only the one whose rename succeeds goes on
+	aside := fmt.Sprintf("%s.stale-%d", path, os.Getpid())
+	if os.Rename(path, aside) != nil {
+		return false
+	}
+	if info, err := os.Stat(aside); err == nil && time.Since(info.ModTime()) < lockStaleAfter {
+		// Another process took it over between the checks; give it back
+		os.Rename(aside, path)
+		return false
+	}
+	os.Remove(aside)
+	return createLock(path)
+}
+
+func createLock(path string) bool {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	f.Close()
+	return true
+}
+
+// removeWorktree removes a worktree and its metadata in the repo
+func removeWorktree(repoPath, dir string) {
+	_ = gitRun(repoPath, "worktree", "remove", "--force", dir)
+	os.RemoveAll(dir)
+	_ = gitRun(repoPath, "worktree", "prune")
+}
+
+// repoKey names the pool subdirectory of a repo: its base name, for
+// people looking in the pool, and a hash of its path
+func repoKey(repoPath string) string {
+	sum := sha256.Sum256([]byte(repoPath))
+	return filepath.Base(repoPath) + "-" + hex.EncodeToString(sum[:6])
+}
+
+func usedPath(slot string) string {
+	return slot + ".used"
+}
+
+func touch(path string) {
+	now := time.Now()
+	if os.Chtimes(path, now, now) != nil {
+		os.WriteFile(path, nil, 0644)
+	}
+}
+
+func gitRun(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
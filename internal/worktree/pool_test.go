@@ -0,0 +1,173 @@
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roborev-dev/roborev/internal/testutil"
+)
+
+// commitFile commits a file to the repo and returns the new HEAD
+func commitFile(t *testing.T, repo *testutil.TestRepo, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo.Root, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-q", "-m", "change " + name}} {
+		if out, err := exec.Command("git", append([]string{"-C", repo.Root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	sha, err := gitOutput(repo.Root, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sha
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestPoolReusesResetWorktree(t *testing.T) {
+	repo := testutil.NewTestRepoWithCommit(t)
+	first := commitFile(t, repo, "a.txt", "one\n")
+	second := commitFile(t, repo, "a.txt", "two\n")
+
+	var setups int
+	pool := &Pool{Dir: t.TempDir(), PerRepo: 2, Total: 4, Setup: func(string) error { setups++; return nil }}
+
+	wt, err := pool.Acquire(repo.Root, first)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if !strings.HasPrefix(wt.Path, pool.Dir) {
+		t.Errorf("worktree %s is not in the pool", wt.Path)
+	}
+	if got := readFile(t, filepath.Join(wt.Path, "a.txt")); got != "one\n" {
+		t.Errorf("a.txt = %q, want one", got)
+	}
+	// Leave changes behind, as an agent would
+	os.WriteFile(filepath.Join(wt.Path, "a.txt"), []byte("edited\n"), 0644)
+	os.WriteFile(filepath.Join(wt.Path, "scratch.txt"), []byte("x"), 0644)
+	path := wt.Path
+	wt.Release()
+
+	wt, err = pool.Acquire(repo.Root, second)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer wt.Release()
+	if wt.Path != path {
+		t.Errorf("expected worktree %s to be reused, got %s", path, wt.Path)
+	}
+	if got := readFile(t, filepath.Join(wt.Path, "a.txt")); got != "two\n" {
+		t.Errorf("a.txt = %q, want two", got)
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, "scratch.txt")); !os.IsNotExist(err) {
+		t.Errorf("untracked file survived the reset: %v", err)
+	}
+	if head, _ := gitOutput(wt.Path, "rev-parse", "HEAD"); head != second {
+		t.Errorf("HEAD = %s, want %s", head, second)
+	}
+	if setups != 2 {
+		t.Errorf("Setup ran %d times, want 2", setups)
+	}
+}
+
+func TestPoolBusySlotsUseTemporaryWorktree(t *testing.T) {
+	repo := testutil.NewTestRepoWithCommit(t)
+	pool := &Pool{Dir: t.TempDir(), PerRepo: 1, Total: 4}
+
+	held, err := pool.Acquire(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer held.Release()
+
+	temp, err := pool.Acquire(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if strings.HasPrefix(temp.Path, pool.Dir) || temp.Path == held.Path {
+		t.Fatalf("expected a temporary worktree outside the pool, got %s", temp.Path)
+	}
+	temp.Release()
+	if _, err := os.Stat(temp.Path); !os.IsNotExist(err) {
+		t.Errorf("temporary worktree not removed: %v", err)
+	}
+	list, _ := gitOutput(repo.Root, "worktree", "list")
+	if strings.Contains(list, temp.Path) {
+		t.Errorf("temporary worktree still registered:\n%s", list)
+	}
+}
+
+func TestPoolEvictsLeastRecentlyUsed(t *testing.T) {
+	repoA := testutil.NewTestRepoWithCommit(t)
+	repoB := testutil.NewTestRepoWithCommit(t)
+	pool := &Pool{Dir: t.TempDir(), PerRepo: 2, Total: 1}
+
+	a, err := pool.Acquire(repoA.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	a.Release()
+	// Make sure b is used later than a, whatever the clock resolution
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(usedPath(a.Path), old, old)
+
+	b, err := pool.Acquire(repoB.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	b.Release()
+
+	if _, err := os.Stat(a.Path); !os.IsNotExist(err) {
+		t.Errorf("least recently used worktree %s was kept", a.Path)
+	}
+	if _, err := os.Stat(b.Path); err != nil {
+		t.Errorf("most recently used worktree was removed: %v", err)
+	}
+	if list, _ := gitOutput(repoA.Root, "worktree", "list"); strings.Contains(list, a.Path) {
+		t.Errorf("evicted worktree still registered:\n%s", list)
+	}
+}
+
+func TestPoolTakesOverStaleLock(t *testing.T) {
+	repo := testutil.NewTestRepoWithCommit(t)
+	pool := &Pool{Dir: t.TempDir(), PerRepo: 1, Total: 4}
+
+	repoDir := filepath.Join(pool.Dir, repoKey(repo.Root))
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lock := filepath.Join(repoDir, "0.lock")
+	if err := os.WriteFile(lock, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * lockStaleAfter)
+	os.Chtimes(lock, old, old)
+
+	wt, err := pool.Acquire(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer wt.Release()
+	if wt.Path != filepath.Join(repoDir, "0") {
+		t.Errorf("expected the stale slot to be taken over, got %s", wt.Path)
+	}
+
+	// A fresh lock is respected
+	if tryLock(lock) {
+		t.Error("took a lock held by a live acquisition")
+	}
+}